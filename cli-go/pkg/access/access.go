@@ -0,0 +1,291 @@
+// Package access manages who may use the local server's API, and with what
+// role.
+//
+// With no users, the server answers whoever can reach it, as it always has.
+// Once a user is added, every API request must carry a user's token, and
+// the user's role decides what the request may do: readers read, moderators
+// also decide on blessing requests, editors also write and publish, and
+// admins also manage settings, keys, and the site's registration. While
+// there are users, at least one of them is an admin.
+package access
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Filename holds the users, relative to .polis/.
+const Filename = "api-users.json"
+
+// Role is what a user may do. Each role may do everything the roles before
+// it in Roles may.
+type Role string
+
+// Roles.
+const (
+	Reader    Role = "reader"    // Read the dashboard and feed
+	Moderator Role = "moderator" // Also grant, deny, and manage blessings
+	Editor    Role = "editor"    // Also write, publish, comment, and follow
+	Admin     Role = "admin"     // Also settings, keys, automations, registration
+)
+
+// Roles lists every role, least privileged first.
+var Roles = []Role{Reader, Moderator, Editor, Admin}
+
+var (
+	// ErrNotFound is returned for a user that doesn't exist.
+	ErrNotFound = errors.New("no such user")
+
+	// ErrExists is returned when adding a user whose name is taken.
+	ErrExists = errors.New("user already exists")
+
+	// ErrLastAdmin is returned for a change that would leave users without
+	// an admin.
+	ErrLastAdmin = errors.New("at least one user must be an admin")
+)
+
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+
+// mu serializes read-modify-write of the users file.
+var mu sync.Mutex
+
+// ParseRole returns the role named s.
+func ParseRole(s string) (Role, error) {
+	for _, r := range Roles {
+		if string(r) == strings.ToLower(s) {
+			return r, nil
+		}
+	}
+	names := make([]string, len(Roles))
+	for i, r := range Roles {
+		names[i] = string(r)
+	}
+	return "", fmt.Errorf("unknown role %q (expected %s)", s, strings.Join(names, ", "))
+}
+
+func (r Role) rank() int {
+	for i, role := range Roles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Allows reports whether role r may do what needs role need.
+func (r Role) Allows(need Role) bool {
+	return r.rank() >= 0 && r.rank() >= need.rank()
+}
+
+// User is a user of the API. Only the SHA-256 of their token is stored.
+type User struct {
+	Name      string `json:"name"`
+	Role      Role   `json:"role"`
+	Hash      string `json:"hash"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Path returns the users file of the site in dataDir.
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", Filename)
+}
+
+// Load reads the users. A site without a users file has none.
+func Load(dataDir string) ([]User, error) {
+	data, err := os.ReadFile(Path(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read API users: %w", err)
+	}
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse API users: %w", err)
+	}
+	return users, nil
+}
+
+func save(dataDir string, users []User) error {
+	if users == nil {
+		users = []User{}
+	}
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal API users: %w", err)
+	}
+	path := Path(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .polis directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API users: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether the API requires a token: whether there are users.
+func Enabled(dataDir string) bool {
+	users, err := Load(dataDir)
+	return err != nil || len(users) > 0 // An unreadable file fails closed
+}
+
+// TokenUserName is the name of the admin the admin_token setting signs in as.
+const TokenUserName = "admin-token"
+
+// TokenUser returns the admin that token signs in as, for a server set up
+// from its environment (a container), where nobody runs polis access add.
+// The token comes from the admin_token setting; nil if it is empty.
+func TokenUser(token string) *User {
+	if token == "" {
+		return nil
+	}
+	return &User{Name: TokenUserName, Role: Admin, Hash: hashToken(token)}
+}
+
+// Authenticate returns the user token belongs to, or nil.
+func Authenticate(users []User, token string) *User {
+	if token == "" {
+		return nil
+	}
+	hash := hashToken(token)
+	for i := range users {
+		if subtle.ConstantTimeCompare([]byte(users[i].Hash), []byte(hash)) == 1 {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// Add creates a user and returns their token, which is shown only once.
+func Add(dataDir, name string, role Role) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid user name %q (use lowercase letters, digits, '.', '-', '_')", name)
+	}
+	if role.rank() < 0 {
+		return "", fmt.Errorf("unknown role %q", role)
+	}
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	return token, update(dataDir, func(users []User) ([]User, error) {
+		if find(users, name) != nil {
+			return nil, fmt.Errorf("%w: %s", ErrExists, name)
+		}
+		return append(users, User{
+			Name:      name,
+			Role:      role,
+			Hash:      hashToken(token),
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		}), nil
+	})
+}
+
+// Remove deletes a user; their token stops working.
+func Remove(dataDir, name string) error {
+	return update(dataDir, func(users []User) ([]User, error) {
+		if find(users, name) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		kept := users[:0]
+		for _, u := range users {
+			if u.Name != name {
+				kept = append(kept, u)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// SetRole changes a user's role.
+func SetRole(dataDir, name string, role Role) error {
+	if role.rank() < 0 {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	return update(dataDir, func(users []User) ([]User, error) {
+		u := find(users, name)
+		if u == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		u.Role = role
+		return users, nil
+	})
+}
+
+// Rotate replaces a user's token and returns the new one.
+func Rotate(dataDir, name string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	return token, update(dataDir, func(users []User) ([]User, error) {
+		u := find(users, name)
+		if u == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		u.Hash = hashToken(token)
+		return users, nil
+	})
+}
+
+// update applies change to the users and saves them, refusing to leave
+// users without an admin.
+func update(dataDir string, change func([]User) ([]User, error)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	users, err := Load(dataDir)
+	if err != nil {
+		return err
+	}
+	users, err = change(users)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		hasAdmin := false
+		for _, u := range users {
+			hasAdmin = hasAdmin || u.Role == Admin
+		}
+		if !hasAdmin {
+			return ErrLastAdmin
+		}
+	}
+	return save(dataDir, users)
+}
+
+func find(users []User, name string) *User {
+	for i := range users {
+		if users[i].Name == name {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
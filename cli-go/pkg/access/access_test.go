@@ -0,0 +1,111 @@
+package access
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddAuthenticate(t *testing.T) {
+	dir := t.TempDir()
+	if Enabled(dir) {
+		t.Fatal("a site without users requires tokens")
+	}
+
+	if _, err := Add(dir, "sam", Editor); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("first user as editor = %v, want ErrLastAdmin", err)
+	}
+	adminToken, err := Add(dir, "alex", Admin)
+	if err != nil {
+		t.Fatalf("Add admin: %v", err)
+	}
+	editorToken, err := Add(dir, "sam", Editor)
+	if err != nil {
+		t.Fatalf("Add editor: %v", err)
+	}
+	if _, err := Add(dir, "sam", Reader); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate Add = %v, want ErrExists", err)
+	}
+	if !Enabled(dir) {
+		t.Error("a site with users doesn't require tokens")
+	}
+
+	users, _ := Load(dir)
+	if u := Authenticate(users, editorToken); u == nil || u.Name != "sam" || u.Role != Editor {
+		t.Errorf("Authenticate(editor token) = %+v", u)
+	}
+	if u := Authenticate(users, adminToken); u == nil || u.Name != "alex" {
+		t.Errorf("Authenticate(admin token) = %+v", u)
+	}
+	if Authenticate(users, "nope") != nil || Authenticate(users, "") != nil {
+		t.Error("Authenticate accepted a bad token")
+	}
+
+	newToken, err := Rotate(dir, "sam")
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	users, _ = Load(dir)
+	if Authenticate(users, editorToken) != nil || Authenticate(users, newToken) == nil {
+		t.Error("Rotate didn't replace the token")
+	}
+}
+
+func TestTokenUser(t *testing.T) {
+	if TokenUser("") != nil {
+		t.Fatal("an empty admin_token signs in")
+	}
+	users := []User{*TokenUser("from-the-environment")}
+	u := Authenticate(users, "from-the-environment")
+	if u == nil || u.Name != TokenUserName || u.Role != Admin {
+		t.Errorf("Authenticate(admin token) = %+v", u)
+	}
+	if Authenticate(users, "other") != nil {
+		t.Error("Authenticate accepted a bad token")
+	}
+}
+
+func TestLastAdmin(t *testing.T) {
+	dir := t.TempDir()
+	Add(dir, "alex", Admin)
+	Add(dir, "sam", Reader)
+
+	if err := SetRole(dir, "alex", Editor); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("demoting the last admin = %v, want ErrLastAdmin", err)
+	}
+	if err := Remove(dir, "alex"); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("removing the last admin = %v, want ErrLastAdmin", err)
+	}
+	if err := SetRole(dir, "sam", Admin); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if err := Remove(dir, "alex"); err != nil {
+		t.Errorf("Remove with another admin: %v", err)
+	}
+	if err := Remove(dir, "sam"); err != nil {
+		t.Errorf("removing the only user: %v", err)
+	}
+	if Enabled(dir) {
+		t.Error("tokens still required with no users")
+	}
+	if err := Remove(dir, "sam"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Remove missing = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !Admin.Allows(Editor) || !Editor.Allows(Moderator) || !Moderator.Allows(Reader) {
+		t.Error("a role doesn't allow what the roles below it may do")
+	}
+	if Reader.Allows(Moderator) || Moderator.Allows(Editor) || Editor.Allows(Admin) {
+		t.Error("a role allows more than it should")
+	}
+	if Role("owner").Allows(Reader) {
+		t.Error("an unknown role allows something")
+	}
+	if r, err := ParseRole("Editor"); err != nil || r != Editor {
+		t.Errorf("ParseRole(Editor) = %q, %v", r, err)
+	}
+	if _, err := ParseRole("owner"); err == nil {
+		t.Error("ParseRole accepted an unknown role")
+	}
+}
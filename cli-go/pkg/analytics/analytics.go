@@ -0,0 +1,194 @@
+// Package analytics counts post views for the site's own author. A small
+// script injected into rendered posts reports each view to the author's
+// polis serve process, which adds it to a daily count per post. No third
+// party is involved, and nothing about the reader (address, user agent,
+// referrer, cookies) is sent or stored: only the post and the day.
+//
+// Counts are kept in .polis/analytics.json.
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// Filename is the view counts file in the .polis directory.
+const Filename = "analytics.json"
+
+// name is the view counts file's name in site storage.
+const name = ".polis/" + Filename
+
+// dayFormat names the days counts are kept for, in UTC.
+const dayFormat = "2006-01-02"
+
+// ErrUnknownPost is returned by Record for a path that is not a published
+// post.
+var ErrUnknownPost = errors.New("not a published post")
+
+// Views is the analytics.json file structure.
+type Views struct {
+	Posts map[string]map[string]int `json:"posts"` // post path -> day -> views
+}
+
+// DayCount is the views of one day ("2006-01-02").
+type DayCount struct {
+	Day   string `json:"day"`
+	Views int    `json:"views"`
+}
+
+// PostViews is the views of one post over a report's period.
+type PostViews struct {
+	Path  string     `json:"path"`
+	Title string     `json:"title,omitempty"`
+	Views int        `json:"views"`
+	Days  []DayCount `json:"days"` // Days with views, oldest first
+}
+
+// Report summarizes the views over a period.
+type Report struct {
+	Since string      `json:"since,omitempty"` // First day counted; empty for all time
+	Views int         `json:"views"`
+	Days  []DayCount  `json:"days"`  // Views of all posts per day, oldest first
+	Posts []PostViews `json:"posts"` // Most viewed first
+}
+
+// Load reads the view counts. A missing file yields no views.
+func Load(siteDir string) (*Views, error) {
+	return load(storage.For(siteDir))
+}
+
+func load(fsys storage.FS) (*Views, error) {
+	v := &Views{}
+	data, err := fsys.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", Filename, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", Filename, err)
+		}
+	}
+	if v.Posts == nil {
+		v.Posts = map[string]map[string]int{}
+	}
+	return v, nil
+}
+
+// PostPath maps a path reported by a page (with .md or .html, with or
+// without a leading slash) to the post path used in the index.
+func PostPath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if p, ok := strings.CutSuffix(path, ".html"); ok {
+		path = p + ".md"
+	}
+	return path
+}
+
+// Record adds a view of the post at postPath on the day of at. Posts must
+// be in the public or unlisted index.
+func Record(siteDir, postPath string, at time.Time) error {
+	postPath = PostPath(postPath)
+	if !isPost(siteDir, postPath) {
+		return ErrUnknownPost
+	}
+	day := at.UTC().Format(dayFormat)
+
+	fsys := storage.For(siteDir)
+	return storage.With(fsys, name, func() error {
+		v, err := load(fsys)
+		if err != nil {
+			return err
+		}
+		if v.Posts[postPath] == nil {
+			v.Posts[postPath] = map[string]int{}
+		}
+		v.Posts[postPath][day]++
+
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", Filename, err)
+		}
+		return fsys.WriteFile(name, append(data, '\n'), 0644)
+	})
+}
+
+// isPost reports whether postPath is a published post.
+func isPost(siteDir, postPath string) bool {
+	entries, _ := metadata.GetPostEntries(siteDir)
+	unlisted, _ := metadata.LoadUnlistedIndex(siteDir)
+	for _, e := range append(entries, unlisted...) {
+		if e.Path == postPath {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize reports the views of the last days days up to now, or of all
+// time if days is 0. Titles are filled in from the public and unlisted
+// indexes.
+func Summarize(siteDir string, days int, now time.Time) (*Report, error) {
+	v, err := Load(siteDir)
+	if err != nil {
+		return nil, err
+	}
+	report := v.Report(days, now)
+
+	titles := map[string]string{}
+	entries, _ := metadata.GetPostEntries(siteDir)
+	unlisted, _ := metadata.LoadUnlistedIndex(siteDir)
+	for _, e := range append(entries, unlisted...) {
+		titles[e.Path] = e.Title
+	}
+	for i := range report.Posts {
+		report.Posts[i].Title = titles[report.Posts[i].Path]
+	}
+	return report, nil
+}
+
+// Report summarizes the views of the last days days up to now, or of all
+// time if days is 0.
+func (v *Views) Report(days int, now time.Time) *Report {
+	report := &Report{Days: []DayCount{}, Posts: []PostViews{}}
+	if days > 0 {
+		report.Since = now.UTC().AddDate(0, 0, 1-days).Format(dayFormat)
+	}
+
+	totals := map[string]int{}
+	for path, counts := range v.Posts {
+		pv := PostViews{Path: path, Days: []DayCount{}}
+		for day, n := range counts {
+			if day < report.Since {
+				continue
+			}
+			pv.Views += n
+			pv.Days = append(pv.Days, DayCount{Day: day, Views: n})
+			totals[day] += n
+		}
+		if pv.Views == 0 {
+			continue
+		}
+		sort.Slice(pv.Days, func(i, j int) bool { return pv.Days[i].Day < pv.Days[j].Day })
+		report.Views += pv.Views
+		report.Posts = append(report.Posts, pv)
+	}
+	for day, n := range totals {
+		report.Days = append(report.Days, DayCount{Day: day, Views: n})
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Day < report.Days[j].Day })
+	sort.Slice(report.Posts, func(i, j int) bool {
+		if report.Posts[i].Views != report.Posts[j].Views {
+			return report.Posts[i].Views > report.Posts[j].Views
+		}
+		return report.Posts[i].Path < report.Posts[j].Path
+	})
+	return report
+}
@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupSite(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "metadata"), 0755)
+	os.WriteFile(filepath.Join(dir, "metadata", "public.jsonl"), []byte(
+		`{"type":"post","path":"posts/20260101/a.md","title":"A"}`+"\n"+
+			`{"type":"post","path":"posts/20260102/b.md","title":"B"}`+"\n"+
+			`{"type":"comment","path":"comments/20260103/c.md","title":"C"}`+"\n"), 0644)
+	return dir
+}
+
+func TestRecordAndSummarize(t *testing.T) {
+	dir := setupSite(t)
+	day1 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	for _, v := range []struct {
+		path string
+		at   time.Time
+	}{
+		{"posts/20260101/a.md", day1},
+		{"/posts/20260101/a.html", day2},
+		{"posts/20260101/a.md", day2},
+		{"posts/20260102/b.md", day2},
+	} {
+		if err := Record(dir, v.path, v.at); err != nil {
+			t.Fatalf("Record(%s) failed: %v", v.path, err)
+		}
+	}
+	for _, path := range []string{"comments/20260103/c.md", "posts/missing.md", ""} {
+		if err := Record(dir, path, day1); !errors.Is(err, ErrUnknownPost) {
+			t.Errorf("Record(%q) = %v, want ErrUnknownPost", path, err)
+		}
+	}
+
+	all, err := Summarize(dir, 0, day2)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if all.Views != 4 || len(all.Posts) != 2 || len(all.Days) != 2 {
+		t.Fatalf("unexpected report: %+v", all)
+	}
+	if p := all.Posts[0]; p.Path != "posts/20260101/a.md" || p.Title != "A" || p.Views != 3 || len(p.Days) != 2 {
+		t.Errorf("unexpected top post: %+v", p)
+	}
+	if d := all.Days[1]; d.Day != "2026-10-02" || d.Views != 3 {
+		t.Errorf("unexpected last day: %+v", d)
+	}
+
+	lastDay, _ := Summarize(dir, 1, day2)
+	if lastDay.Since != "2026-10-02" || lastDay.Views != 3 {
+		t.Errorf("unexpected one-day report: %+v", lastDay)
+	}
+}
@@ -0,0 +1,248 @@
+// Package author gathers what this site knows about another polis author:
+// follow state in both directions, comments and blessings exchanged, their
+// items in the feed cache, and their published identity.
+package author
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// domainPattern matches a bare hostname (no scheme, port, or path).
+var domainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// ValidDomain reports whether domain is a bare lowercase hostname.
+func ValidDomain(domain string) bool {
+	return domainPattern.MatchString(domain)
+}
+
+// Comment is a comment exchanged with a domain, in either direction.
+type Comment struct {
+	URL       string `json:"url"`
+	TargetURL string `json:"target_url"`
+	Status    string `json:"status"`
+	At        string `json:"at,omitempty"`
+}
+
+// BlessingEvent is a single blessing decision between us and a domain.
+// Direction is "given" (we decided on their comment) or "received" (they
+// decided on ours).
+type BlessingEvent struct {
+	Direction  string `json:"direction"`
+	CommentURL string `json:"comment_url"`
+	Status     string `json:"status"`
+	At         string `json:"at,omitempty"`
+}
+
+// FollowState is the follow relationship with a domain in both directions.
+type FollowState struct {
+	Following   bool   `json:"following"`
+	FollowedBy  bool   `json:"followed_by"`
+	FollowingAt string `json:"following_since,omitempty"`
+	AuthorURL   string `json:"author_url,omitempty"`
+	SiteTitle   string `json:"site_title,omitempty"`
+	AuthorName  string `json:"author_name,omitempty"`
+}
+
+// FeedCounts tallies a domain's items in the feed cache.
+type FeedCounts struct {
+	Posts    int `json:"posts"`
+	Comments int `json:"comments"`
+	Unread   int `json:"unread"`
+}
+
+// Summary is everything known locally about one domain.
+type Summary struct {
+	Domain          string          `json:"domain"`
+	Follow          FollowState     `json:"follow"`
+	TheirComments   []Comment       `json:"their_comments"`
+	MyComments      []Comment       `json:"my_comments"`
+	BlessingHistory []BlessingEvent `json:"blessing_history"`
+	Feed            FeedCounts      `json:"feed"`
+	TrustScore      int             `json:"trust_score"`
+	LastActivity    string          `json:"last_activity,omitempty"`
+}
+
+// Summarize collects the local record for domain: follow status in both
+// directions, comments exchanged, blessing history, feed activity, a trust
+// score, and the most recent activity timestamp. All data comes from local
+// cached state; nothing is fetched.
+//
+// The trust score is a simple tally: +2 per comment of theirs we blessed,
+// -3 per comment of theirs we denied, +1/-1 per comment of ours they blessed
+// or denied, and +1 each for following them and being followed by them.
+func Summarize(dataDir, discoveryDomain, domain string) *Summary {
+	resp := &Summary{
+		Domain:          domain,
+		TheirComments:   []Comment{},
+		MyComments:      []Comment{},
+		BlessingHistory: []BlessingEvent{},
+	}
+	lastActivity := ""
+	touch := func(ts string) {
+		if ts > lastActivity {
+			lastActivity = ts
+		}
+	}
+
+	// Follow status: who we follow is local, who follows us is stream state
+	if f, err := following.Load(following.DefaultPath(dataDir)); err == nil {
+		for _, entry := range f.All() {
+			if discovery.ExtractDomainFromURL(entry.URL) == domain {
+				resp.Follow.Following = true
+				resp.Follow.FollowingAt = entry.AddedAt
+				resp.Follow.AuthorURL = entry.URL
+				resp.Follow.SiteTitle = entry.SiteTitle
+				resp.Follow.AuthorName = entry.AuthorName
+				break
+			}
+		}
+	}
+
+	store := stream.NewStore(dataDir, discoveryDomain)
+	var followerState stream.FollowerState
+	_ = store.LoadState("polis.follow", &followerState)
+	for _, f := range followerState.Followers {
+		if strings.ToLower(f) == domain {
+			resp.Follow.FollowedBy = true
+			break
+		}
+	}
+
+	// Their comments on our posts: stream blessing state, plus the local
+	// blessed index for grants that predate the cached state
+	seen := make(map[string]bool)
+	var blessingState stream.BlessingState
+	_ = store.LoadState("polis.blessing", &blessingState)
+	for _, b := range blessingState.Blessings {
+		if discovery.ExtractDomainFromURL(b.SourceURL) != domain {
+			continue
+		}
+		seen[b.SourceURL] = true
+		resp.TheirComments = append(resp.TheirComments, Comment{
+			URL:       b.SourceURL,
+			TargetURL: b.TargetURL,
+			Status:    b.Status,
+			At:        b.UpdatedAt,
+		})
+	}
+	if bc, err := metadata.LoadBlessedComments(dataDir); err == nil {
+		for _, pc := range bc.Comments {
+			for _, c := range pc.Blessed {
+				if seen[c.URL] || discovery.ExtractDomainFromURL(c.URL) != domain {
+					continue
+				}
+				seen[c.URL] = true
+				resp.TheirComments = append(resp.TheirComments, Comment{
+					URL:       c.URL,
+					TargetURL: pc.Post,
+					Status:    "granted",
+					At:        c.BlessedAt,
+				})
+			}
+		}
+	}
+
+	// Our comments on their posts, across every status
+	for _, status := range []string{comment.StatusPending, comment.StatusBlessed, comment.StatusDenied} {
+		metas, err := comment.ListComments(dataDir, status)
+		if err != nil {
+			continue
+		}
+		for _, m := range metas {
+			if discovery.ExtractDomainFromURL(m.InReplyTo) != domain {
+				continue
+			}
+			resp.MyComments = append(resp.MyComments, Comment{
+				URL:       m.CommentURL,
+				TargetURL: m.InReplyTo,
+				Status:    status,
+				At:        m.Timestamp,
+			})
+		}
+	}
+
+	// Blessing history and trust score
+	for _, c := range resp.TheirComments {
+		touch(c.At)
+		switch c.Status {
+		case "granted":
+			resp.TrustScore += 2
+		case "denied":
+			resp.TrustScore -= 3
+		default:
+			continue
+		}
+		resp.BlessingHistory = append(resp.BlessingHistory, BlessingEvent{
+			Direction: "given", CommentURL: c.URL, Status: c.Status, At: c.At,
+		})
+	}
+	for _, c := range resp.MyComments {
+		touch(c.At)
+		var status string
+		switch c.Status {
+		case comment.StatusBlessed:
+			status = "granted"
+			resp.TrustScore++
+		case comment.StatusDenied:
+			status = "denied"
+			resp.TrustScore--
+		default:
+			continue
+		}
+		resp.BlessingHistory = append(resp.BlessingHistory, BlessingEvent{
+			Direction: "received", CommentURL: c.URL, Status: status, At: c.At,
+		})
+	}
+	if resp.Follow.Following {
+		resp.TrustScore++
+	}
+	if resp.Follow.FollowedBy {
+		resp.TrustScore++
+	}
+
+	sort.Slice(resp.TheirComments, func(i, j int) bool { return resp.TheirComments[i].At > resp.TheirComments[j].At })
+	sort.Slice(resp.MyComments, func(i, j int) bool { return resp.MyComments[i].At > resp.MyComments[j].At })
+	sort.Slice(resp.BlessingHistory, func(i, j int) bool { return resp.BlessingHistory[i].At > resp.BlessingHistory[j].At })
+
+	// Feed activity authored by the domain
+	for _, item := range feedItems(dataDir, discoveryDomain, domain) {
+		switch item.Type {
+		case "post":
+			resp.Feed.Posts++
+		case "comment":
+			resp.Feed.Comments++
+		}
+		if item.ReadAt == "" {
+			resp.Feed.Unread++
+		}
+		touch(item.Published)
+	}
+
+	resp.LastActivity = lastActivity
+	return resp
+}
+
+// feedItems returns domain's items in the feed cache, newest first.
+func feedItems(dataDir, discoveryDomain, domain string) []feed.CachedFeedItem {
+	items, err := feed.NewCacheManager(dataDir, discoveryDomain).List()
+	if err != nil {
+		return nil
+	}
+	var out []feed.CachedFeedItem
+	for _, item := range items {
+		if strings.ToLower(item.AuthorDomain) == domain {
+			out = append(out, item)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Published > out[j].Published })
+	return out
+}
@@ -0,0 +1,123 @@
+package author
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+func TestValidDomain(t *testing.T) {
+	for domain, want := range map[string]bool{
+		"alice.polis.pub": true,
+		"localhost":       true,
+		"":                false,
+		"a.com:8080":      false,
+		"a.com/posts":     false,
+		"Alice.com":       false,
+		"-a.com":          false,
+	} {
+		if got := ValidDomain(domain); got != want {
+			t.Errorf("ValidDomain(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"author":"Alice","public_key":"ssh-ed25519 AAAA","site_title":"Alice Writes"}`))
+	}))
+	defer srv.Close()
+	domain := discovery.ExtractDomainFromURL(srv.URL)
+
+	following.Save(following.DefaultPath(dir), &following.FollowingFile{Following: []following.FollowingEntry{
+		{URL: srv.URL, AddedAt: now.Format(time.RFC3339), SiteTitle: "Cached title"},
+	}})
+	stream.NewStore(dir, "ds.example.com").SaveState("polis.follow", stream.FollowerState{Followers: []string{domain}})
+
+	cacheFile := feed.CacheFile(dir, "ds.example.com")
+	os.MkdirAll(filepath.Dir(cacheFile), 0755)
+	var lines []byte
+	for i, id := range []string{"a", "b", "c"} {
+		data, _ := json.Marshal(feed.CachedFeedItem{
+			ID: id, Type: "post", Title: id, AuthorDomain: domain,
+			Published: now.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+		})
+		lines = append(append(lines, data...), '\n')
+	}
+	os.WriteFile(cacheFile, lines, 0644)
+
+	p := LoadProfile(dir, "ds.example.com", domain, ProfileOptions{Client: remote.NewClient(), Limit: 2})
+	if p.SiteTitle != "Alice Writes" || p.Author != "Alice" || p.PublicKey != "ssh-ed25519 AAAA" {
+		t.Errorf("identity = %q/%q/%q, want values from .well-known/polis", p.SiteTitle, p.Author, p.PublicKey)
+	}
+	if p.URL != srv.URL {
+		t.Errorf("URL = %q, want the followed URL", p.URL)
+	}
+	if !p.Follow.Following || !p.Follow.FollowedBy || p.TrustScore != 2 {
+		t.Errorf("follow = %+v trust = %d, want mutual follow scoring 2", p.Follow, p.TrustScore)
+	}
+	if len(p.Items) != 2 || p.Items[0].ID != "c" {
+		t.Errorf("items = %+v, want 2 newest first", p.Items)
+	}
+	if p.Feed.Posts != 3 {
+		t.Errorf("feed posts = %d, want all 3 counted", p.Feed.Posts)
+	}
+
+	offline := LoadProfile(dir, "ds.example.com", domain, ProfileOptions{})
+	if offline.SiteTitle != "Cached title" || offline.PublicKey != "" || offline.WellKnownError != "" {
+		t.Errorf("offline profile = %+v, want following-entry title and no fetch", offline)
+	}
+}
+
+func TestListFollowers(t *testing.T) {
+	dir := t.TempDir()
+	store := stream.NewStore(dir, "ds.example.com")
+	store.SaveState("polis.follow", stream.FollowerState{
+		Followers: []string{"old.example.com", "bob.example.com", "new.example.com"},
+		Since: map[string]string{
+			"bob.example.com": "2026-03-01T00:00:00Z",
+			"new.example.com": "2026-03-05T00:00:00Z",
+		},
+	})
+	following.Save(following.DefaultPath(dir), &following.FollowingFile{Following: []following.FollowingEntry{
+		{URL: "https://bob.example.com"},
+	}})
+	now := time.Now().UTC().Format(time.RFC3339)
+	store.SaveState(FollowerProfilesState, FollowerProfiles{
+		"bob.example.com": {SiteTitle: "Bob's Log", FetchedAt: now},
+		"new.example.com": {FetchedAt: now, Error: "connection refused"},
+	})
+
+	followers := ListFollowers(dir, "ds.example.com")
+	var order []string
+	for _, f := range followers {
+		order = append(order, f.Domain)
+	}
+	if strings.Join(order, ",") != "new.example.com,bob.example.com,old.example.com" {
+		t.Fatalf("order = %v, want newest first with undated last", order)
+	}
+	if bob := followers[1]; bob.SiteTitle != "Bob's Log" || !bob.FollowingBack || bob.FollowedAt == "" {
+		t.Errorf("bob = %+v, want cached title, following back, and follow time", bob)
+	}
+
+	// Cached profiles (including recent failures) aren't fetched again; a
+	// nil client would panic if they were
+	profiles := FetchFollowerProfiles(dir, "ds.example.com", []string{"bob.example.com", "new.example.com"}, nil, 3)
+	if profiles.SiteTitles()["bob.example.com"] != "Bob's Log" || len(profiles.SiteTitles()) != 1 {
+		t.Errorf("titles = %v", profiles.SiteTitles())
+	}
+}
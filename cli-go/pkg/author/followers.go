@@ -0,0 +1,142 @@
+package author
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// FollowerProfilesState is the stream state file caching followers' site
+// titles and author names (state/follower-profiles.json).
+const FollowerProfilesState = "follower-profiles"
+
+// profileRetry is how long to wait before fetching a follower's
+// .well-known/polis again after a failed attempt.
+const profileRetry = 24 * time.Hour
+
+// Follower is one site that follows us.
+type Follower struct {
+	Domain     string `json:"domain"`
+	URL        string `json:"url"`
+	SiteTitle  string `json:"site_title,omitempty"`
+	Author     string `json:"author,omitempty"`
+	FollowedAt string `json:"followed_at,omitempty"`
+	// FollowingBack is set when we follow them too.
+	FollowingBack bool `json:"following_back"`
+}
+
+// FollowerProfile is the cached identity of a follower's site.
+type FollowerProfile struct {
+	SiteTitle string `json:"site_title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	FetchedAt string `json:"fetched_at"`
+	Error     string `json:"error,omitempty"`
+}
+
+// FollowerProfiles maps follower domains to their cached profiles.
+type FollowerProfiles map[string]FollowerProfile
+
+// LoadFollowerProfiles reads the follower profile cache.
+func LoadFollowerProfiles(dataDir, discoveryDomain string) FollowerProfiles {
+	profiles := FollowerProfiles{}
+	_ = stream.NewStore(dataDir, discoveryDomain).LoadState(FollowerProfilesState, &profiles)
+	return profiles
+}
+
+// SiteTitles returns the cached site title of each follower that has one.
+func (p FollowerProfiles) SiteTitles() map[string]string {
+	titles := make(map[string]string, len(p))
+	for domain, profile := range p {
+		if profile.SiteTitle != "" {
+			titles[domain] = profile.SiteTitle
+		}
+	}
+	return titles
+}
+
+// ListFollowers returns everyone who follows us, from the follow stream
+// state, with cached profiles and whether we follow them back. Newest
+// followers come first; followers without a follow time are last, by domain.
+func ListFollowers(dataDir, discoveryDomain string) []Follower {
+	var state stream.FollowerState
+	_ = stream.NewStore(dataDir, discoveryDomain).LoadState("polis.follow", &state)
+	profiles := LoadFollowerProfiles(dataDir, discoveryDomain)
+
+	followed := make(map[string]bool)
+	if f, err := following.Load(following.DefaultPath(dataDir)); err == nil {
+		for _, entry := range f.All() {
+			followed[discovery.ExtractDomainFromURL(entry.URL)] = true
+		}
+	}
+
+	followers := make([]Follower, 0, len(state.Followers))
+	for _, domain := range state.Followers {
+		domain = strings.ToLower(domain)
+		profile := profiles[domain]
+		followers = append(followers, Follower{
+			Domain:        domain,
+			URL:           "https://" + domain,
+			SiteTitle:     profile.SiteTitle,
+			Author:        profile.Author,
+			FollowedAt:    state.Since[domain],
+			FollowingBack: followed[domain],
+		})
+	}
+	sort.SliceStable(followers, func(i, j int) bool {
+		if followers[i].FollowedAt != followers[j].FollowedAt {
+			return followers[i].FollowedAt > followers[j].FollowedAt
+		}
+		return followers[i].Domain < followers[j].Domain
+	})
+	return followers
+}
+
+// FetchFollowerProfiles fetches the .well-known/polis of up to max of the
+// given domains that have no cached profile (or whose last fetch failed a
+// day or more ago), and saves the results to the profile cache. Returns
+// the updated cache.
+func FetchFollowerProfiles(dataDir, discoveryDomain string, domains []string, client *remote.Client, max int) FollowerProfiles {
+	profiles := LoadFollowerProfiles(dataDir, discoveryDomain)
+	now := time.Now().UTC()
+	fetched := 0
+	for _, domain := range domains {
+		if fetched >= max {
+			break
+		}
+		if !ValidDomain(domain) {
+			continue
+		}
+		if p, ok := profiles[domain]; ok {
+			last, _ := time.Parse(time.RFC3339, p.FetchedAt)
+			if p.Error == "" || now.Sub(last) < profileRetry {
+				continue
+			}
+		}
+		fetched++
+		profile := FollowerProfile{FetchedAt: now.Format(time.RFC3339)}
+		if wk, err := client.FetchWellKnown("https://" + domain); err != nil {
+			profile.Error = err.Error()
+		} else {
+			profile.SiteTitle, profile.Author = wk.SiteTitle, wk.Author
+		}
+		profiles[domain] = profile
+	}
+	if fetched > 0 {
+		_ = stream.NewStore(dataDir, discoveryDomain).SaveState(FollowerProfilesState, profiles)
+	}
+	return profiles
+}
+
+// ApplyProfiles fills in site titles and author names from profiles.
+func ApplyProfiles(followers []Follower, profiles FollowerProfiles) {
+	for i := range followers {
+		if p, ok := profiles[followers[i].Domain]; ok {
+			followers[i].SiteTitle, followers[i].Author = p.SiteTitle, p.Author
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package author
+
+import (
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// DefaultItemLimit caps Profile.Items when no limit is given.
+const DefaultItemLimit = 50
+
+// Profile is an author's public identity plus the local Summary and their
+// cached feed items.
+type Profile struct {
+	*Summary
+	URL       string `json:"url"`
+	SiteTitle string `json:"site_title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+	Created   string `json:"created,omitempty"`
+	// WellKnownError is set when the author's .well-known/polis could not
+	// be fetched; identity fields then fall back to the following entry.
+	WellKnownError string                `json:"well_known_error,omitempty"`
+	Items          []feed.CachedFeedItem `json:"items"`
+}
+
+// ProfileOptions controls LoadProfile.
+type ProfileOptions struct {
+	// Client fetches the author's .well-known/polis. Nil skips the fetch.
+	Client *remote.Client
+	// Limit caps the number of feed items (0 = DefaultItemLimit).
+	Limit int
+}
+
+// LoadProfile builds the profile for domain. The site URL is the followed
+// URL when we follow the author, otherwise https://<domain>.
+func LoadProfile(dataDir, discoveryDomain, domain string, opts ProfileOptions) *Profile {
+	summary := Summarize(dataDir, discoveryDomain, domain)
+	p := &Profile{
+		Summary:   summary,
+		URL:       "https://" + domain,
+		SiteTitle: summary.Follow.SiteTitle,
+		Author:    summary.Follow.AuthorName,
+		Items:     []feed.CachedFeedItem{},
+	}
+	if summary.Follow.AuthorURL != "" {
+		p.URL = summary.Follow.AuthorURL
+	}
+
+	if opts.Client != nil {
+		wk, err := opts.Client.FetchWellKnown(p.URL)
+		if err != nil {
+			p.WellKnownError = err.Error()
+		} else {
+			if wk.SiteTitle != "" {
+				p.SiteTitle = wk.SiteTitle
+			}
+			if wk.Author != "" {
+				p.Author = wk.Author
+			}
+			p.PublicKey = wk.PublicKey
+			p.Created = wk.Created
+		}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultItemLimit
+	}
+	items := feedItems(dataDir, discoveryDomain, domain)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	p.Items = append(p.Items, items...)
+	return p
+}
@@ -0,0 +1,158 @@
+// Package backup writes compressed archives of a polis data directory.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
+)
+
+// DefaultKeep is how many archives Prune retains when keep is not positive.
+const DefaultKeep = 7
+
+const (
+	archivePrefix = "polis-backup-"
+	archiveSuffix = ".tar.gz"
+)
+
+// Dir returns the default archive directory, .polis/backups.
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "backups")
+}
+
+// excluded lists data-dir paths (slash-separated, relative) that are never
+// archived: previous archives, logs, and scratch space.
+var excluded = map[string]bool{
+	".polis/backups": true,
+	".polis/tmp":     true,
+	"logs":           true,
+}
+
+// Create archives dataDir into outDir as polis-backup-<timestamp>.tar.gz
+// and returns the archive path. The archive includes the site keys, so it
+// should be stored as carefully as the data directory itself. Sockets,
+// in-progress lock directories, and symlinked directories are skipped.
+func Create(dataDir, outDir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(outDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	name := archivePrefix + now.UTC().Format("20060102T150405Z") + archiveSuffix
+	path := filepath.Join(outDir, name)
+
+	f, err := os.OpenFile(path+".partial", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	err = writeArchive(f, dataDir, outDir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".partial")
+		return "", err
+	}
+	if err := os.Rename(path+".partial", path); err != nil {
+		return "", fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return path, nil
+}
+
+func writeArchive(w io.Writer, dataDir, outDir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	absOut, _ := filepath.Abs(outDir)
+	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil // removed mid-walk
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			abs, _ := filepath.Abs(path)
+			if excluded[rel] || abs == absOut || strings.HasSuffix(rel, lockfile.Suffix) {
+				return filepath.SkipDir
+			}
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if info.IsDir() {
+			hdr.Name += "/"
+			return tw.WriteHeader(hdr)
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		defer src.Close()
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = io.CopyN(tw, src, hdr.Size)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", dataDir, err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// Prune deletes all but the newest keep archives in outDir and returns how
+// many were removed.
+func Prune(outDir string, keep int) (int, error) {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var archives []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), archivePrefix) && strings.HasSuffix(e.Name(), archiveSuffix) {
+			archives = append(archives, e.Name())
+		}
+	}
+	// Timestamped names sort chronologically.
+	sort.Sort(sort.Reverse(sort.StringSlice(archives)))
+
+	removed := 0
+	for _, name := range archives[min(keep, len(archives)):] {
+		if err := os.Remove(filepath.Join(outDir, name)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func archiveNames(t *testing.T, path string) map[string]bool {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names[hdr.Name] = true
+	}
+	return names
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "posts/20260101/hello.md", "hi")
+	writeFile(t, dir, ".polis/keys/id_ed25519", "secret")
+	writeFile(t, dir, "logs/polis.log", "log")
+	writeFile(t, dir, ".polis/backups/old.tar.gz", "old")
+	writeFile(t, dir, "metadata/public.jsonl.lock/owner.json", "{}")
+
+	path, err := Create(dir, Dir(dir), time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if filepath.Base(path) != "polis-backup-20260304T100000Z.tar.gz" {
+		t.Errorf("archive name = %s", filepath.Base(path))
+	}
+
+	names := archiveNames(t, path)
+	for _, want := range []string{"posts/20260101/hello.md", ".polis/keys/id_ed25519"} {
+		if !names[want] {
+			t.Errorf("%s missing from archive", want)
+		}
+	}
+	for _, unwanted := range []string{"logs/polis.log", ".polis/backups/old.tar.gz", "metadata/public.jsonl.lock/owner.json"} {
+		if names[unwanted] {
+			t.Errorf("%s must not be archived", unwanted)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"polis-backup-20260101T000000Z.tar.gz",
+		"polis-backup-20260102T000000Z.tar.gz",
+		"polis-backup-20260103T000000Z.tar.gz",
+		"notes.txt",
+	} {
+		writeFile(t, dir, name, "x")
+	}
+
+	removed, err := Prune(dir, 2)
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune = %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "polis-backup-20260101T000000Z.tar.gz")); !os.IsNotExist(err) {
+		t.Error("oldest archive was kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("unrelated file was removed")
+	}
+}
@@ -0,0 +1,108 @@
+package blessing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+)
+
+// Bulk moderation actions.
+const (
+	ActionGrant = "grant"
+	ActionDeny  = "deny"
+)
+
+// BulkAction is one moderation decision in a bulk request.
+type BulkAction struct {
+	CommentVersion string `json:"comment_version"`
+	Action         string `json:"action"`
+}
+
+// BulkItemResult is the outcome of one BulkAction.
+type BulkItemResult struct {
+	CommentVersion string `json:"comment_version"`
+	CommentURL     string `json:"comment_url,omitempty"`
+	InReplyTo      string `json:"in_reply_to,omitempty"`
+	Action         string `json:"action"`
+	Success        bool   `json:"success"`
+	Error          string `json:"error,omitempty"`
+}
+
+// BulkResult contains the results of a bulk moderation run.
+type BulkResult struct {
+	Granted int              `json:"granted"`
+	Denied  int              `json:"denied"`
+	Failed  int              `json:"failed"`
+	Results []BulkItemResult `json:"results"`
+}
+
+// Bulk applies many grant/deny decisions against the pending requests
+// fetched once by the caller (FetchPendingRequests), so the only discovery
+// calls are the signed relationship updates themselves. The discovery
+// service has no batch endpoint, so each decision is still its own signed
+// update. A failed item doesn't stop the rest; it is counted in Failed.
+// Callers re-render once afterwards.
+func Bulk(siteDir string, pending []IncomingRequest, actions []BulkAction, client *discovery.Client, hookConfig *hooks.HookConfig, privateKey []byte) *BulkResult {
+	result := &BulkResult{Results: []BulkItemResult{}}
+	for _, a := range actions {
+		item := BulkItemResult{CommentVersion: a.CommentVersion, Action: a.Action}
+		req := findPendingRequest(pending, a.CommentVersion)
+		switch {
+		case a.Action != ActionGrant && a.Action != ActionDeny:
+			item.Error = fmt.Sprintf("unknown action %q (expected grant or deny)", a.Action)
+		case req == nil:
+			item.Error = "no pending blessing request for this comment"
+		default:
+			item.CommentURL, item.InReplyTo = req.CommentURL, req.InReplyTo
+			var err error
+			if a.Action == ActionGrant {
+				_, err = Grant(siteDir, req, client, hookConfig, privateKey)
+			} else {
+				_, err = DenyRequest(req, client, privateKey)
+			}
+			if err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Success = true
+			}
+		}
+
+		switch {
+		case !item.Success:
+			result.Failed++
+		case a.Action == ActionGrant:
+			result.Granted++
+		default:
+			result.Denied++
+		}
+		result.Results = append(result.Results, item)
+	}
+	return result
+}
+
+// FilterByAuthor returns the requests whose commenter is domain.
+func FilterByAuthor(requests []IncomingRequest, domain string) []IncomingRequest {
+	domain = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://"), "/")
+	var result []IncomingRequest
+	for _, r := range requests {
+		if strings.EqualFold(r.Author, domain) || domainMatches(r.CommentURL, domain) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// findPendingRequest matches a comment version (or comment URL) against pending requests.
+func findPendingRequest(pending []IncomingRequest, commentVersion string) *IncomingRequest {
+	if commentVersion == "" {
+		return nil
+	}
+	for i := range pending {
+		if pending[i].CommentVersion == commentVersion || pending[i].CommentURL == commentVersion {
+			return &pending[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package blessing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestBulk(t *testing.T) {
+	var mu sync.Mutex
+	var updates []discovery.RelationshipUpdateRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req discovery.RelationshipUpdateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.SourceURL == "https://carol.polis.pub/comments/20260301/c.md" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		updates = append(updates, req)
+		mu.Unlock()
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer ts.Close()
+
+	privKey, _, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := []IncomingRequest{
+		{CommentVersion: "sha256:a", CommentURL: "https://bob.polis.pub/comments/20260301/a.md", InReplyTo: "https://alice.polis.pub/posts/20260301/p.md", Author: "bob.polis.pub"},
+		{CommentVersion: "sha256:b", CommentURL: "https://bob.polis.pub/comments/20260301/b.md", InReplyTo: "https://alice.polis.pub/posts/20260301/p.md", Author: "bob.polis.pub"},
+		{CommentVersion: "sha256:c", CommentURL: "https://carol.polis.pub/comments/20260301/c.md", InReplyTo: "https://alice.polis.pub/posts/20260301/p.md", Author: "carol.polis.pub"},
+	}
+
+	result := Bulk(t.TempDir(), pending, []BulkAction{
+		{CommentVersion: "sha256:a", Action: ActionGrant},
+		{CommentVersion: "sha256:b", Action: ActionDeny},
+		{CommentVersion: "sha256:c", Action: ActionGrant},       // discovery error
+		{CommentVersion: "sha256:missing", Action: ActionGrant}, // not pending
+		{CommentVersion: "sha256:a", Action: "approve"},         // unknown action
+	}, discovery.NewClient(ts.URL, "key"), nil, privKey)
+
+	if result.Granted != 1 || result.Denied != 1 || result.Failed != 3 || len(result.Results) != 5 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	if len(updates) != 2 || updates[0].Action != "grant" || updates[1].Action != "deny" {
+		t.Errorf("unexpected relationship updates: %+v", updates)
+	}
+	for _, r := range result.Results[2:] {
+		if r.Success || r.Error == "" {
+			t.Errorf("expected failure with error: %+v", r)
+		}
+	}
+}
+
+func TestFilterByAuthor(t *testing.T) {
+	requests := []IncomingRequest{
+		{CommentURL: "https://bob.polis.pub/comments/20260301/a.md", Author: "bob.polis.pub"},
+		{CommentURL: "https://bob.polis.pub/comments/20260301/b.md"},
+		{CommentURL: "https://carol.polis.pub/comments/20260301/c.md", Author: "carol.polis.pub"},
+	}
+	for _, domain := range []string{"bob.polis.pub", "https://bob.polis.pub/"} {
+		if got := FilterByAuthor(requests, domain); len(got) != 2 {
+			t.Errorf("FilterByAuthor(%q) returned %d requests, want 2", domain, len(got))
+		}
+	}
+}
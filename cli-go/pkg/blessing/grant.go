@@ -6,6 +6,7 @@ import (
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 )
 
@@ -41,7 +42,7 @@ func Grant(siteDir string, request *IncomingRequest, client *discovery.Client, h
 	if err := metadata.AddBlessedComment(siteDir, postPath, blessedComment); err != nil {
 		// Log warning but don't fail - the blessing was granted on discovery service
 		// The local index is a convenience, not the source of truth
-		fmt.Printf("[warning] Failed to update blessed-comments.json: %v\n", err)
+		logging.Warn("Failed to update blessed-comments.json", "comment", request.CommentURL, "err", err)
 	}
 
 	// Run post-comment hook if configured
@@ -55,7 +56,7 @@ func Grant(siteDir string, request *IncomingRequest, client *discovery.Client, h
 		}
 		if _, err := hooks.RunHook(siteDir, hookConfig, payload); err != nil {
 			// Log warning but don't fail
-			fmt.Printf("[warning] post-comment hook failed: %v\n", err)
+			logging.Warn("post-comment hook failed", "err", err)
 		}
 	}
 
@@ -0,0 +1,75 @@
+package blessing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
+)
+
+// EventRetracted matches comment.EventRetracted.
+const EventRetracted = "polis.comment.retracted"
+
+// VerifyRetraction checks that a retraction event was published by the
+// comment's own author: the actor must be the comment URL's domain and the
+// event signature must verify against publicKey, the actor's key from their
+// .well-known/polis.
+func VerifyRetraction(evt discovery.StreamEvent, publicKey string) error {
+	if evt.Type != EventRetracted {
+		return fmt.Errorf("not a retraction event: %s", evt.Type)
+	}
+	sourceURL, _ := evt.Payload["source_url"].(string)
+	if sourceURL == "" {
+		return fmt.Errorf("retraction has no source_url")
+	}
+	if domain := discovery.ExtractDomainFromURL(sourceURL); domain == "" || domain != evt.Actor {
+		return fmt.Errorf("retraction of %s was published by %s", sourceURL, evt.Actor)
+	}
+	if publicKey == "" || evt.Signature == "" {
+		return fmt.Errorf("retraction is unsigned or %s has no public key", evt.Actor)
+	}
+	canonical, err := discovery.MakeStreamCanonicalJSON(evt.Type, evt.Payload)
+	if err != nil {
+		return fmt.Errorf("canonical JSON: %w", err)
+	}
+	ok, err := signing.VerifySignature(canonical, []byte(publicKey), evt.Signature)
+	if err != nil {
+		return fmt.Errorf("verify signature: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("retraction signature does not match %s's key", evt.Actor)
+	}
+	return nil
+}
+
+// HonorRetraction verifies a retraction event against the commenter's
+// published key and removes the comment from blessed-comments.json.
+// Returns the normalized comment URL. Callers re-render afterwards.
+func HonorRetraction(siteDir string, evt discovery.StreamEvent, client *remote.Client) (string, error) {
+	// Reject retractions of other people's comments before fetching anything
+	if discovery.ExtractDomainFromURL(firstString(evt.Payload, "source_url")) != evt.Actor {
+		return "", VerifyRetraction(evt, "")
+	}
+	publicKey, err := client.FetchPublicKey("https://" + evt.Actor)
+	if err != nil {
+		return "", fmt.Errorf("fetch public key for %s: %w", evt.Actor, err)
+	}
+	if err := VerifyRetraction(evt, publicKey); err != nil {
+		return "", err
+	}
+	commentURL := polisurl.NormalizeToMD(firstString(evt.Payload, "source_url"))
+	if err := metadata.RemoveBlessedComment(siteDir, commentURL); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("remove blessed comment: %w", err)
+	}
+	return commentURL, nil
+}
+
+func firstString(payload map[string]interface{}, key string) string {
+	v, _ := payload[key].(string)
+	return v
+}
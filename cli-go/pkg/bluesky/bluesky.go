@@ -0,0 +1,221 @@
+// Package bluesky cross-posts published posts to Bluesky.
+//
+// When a Bluesky handle and app password are configured, each newly
+// published public post gets a link post on the account: the title, the
+// summary, and the post's URL, with a link card. Unlisted and
+// followers-only posts are never cross-posted, and a post opts out with
+// "crosspost: false" in its source frontmatter. The copy's bsky.app URL and
+// at:// URI are recorded in metadata/syndication.json, so a post is only
+// cross-posted once.
+package bluesky
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// Service is the service name recorded in syndication.json.
+const Service = "bluesky"
+
+// PostCollection is the record type of a Bluesky post.
+const PostCollection = "app.bsky.feed.post"
+
+// MaxPostLength is Bluesky's post length limit. It counts graphemes; runes
+// are counted here, which is never less.
+const MaxPostLength = 300
+
+var (
+	// ErrAlreadyPosted is returned for a post cross-posted before.
+	ErrAlreadyPosted = errors.New("already cross-posted to Bluesky")
+
+	// ErrNotPublic is returned for unlisted and followers-only posts.
+	ErrNotPublic = errors.New("only public posts are cross-posted")
+)
+
+// Config is the account to cross-post to.
+type Config struct {
+	Handle      string // e.g. alice.bsky.social
+	AppPassword string // An app password, not the account password
+	PDS         string // DefaultPDS if empty
+}
+
+// Enabled reports whether cross-posting is configured.
+func (c Config) Enabled() bool {
+	return c.Handle != "" && c.AppPassword != ""
+}
+
+// Post is an app.bsky.feed.post record.
+type Post struct {
+	Type      string   `json:"$type"`
+	Text      string   `json:"text"`
+	CreatedAt string   `json:"createdAt"`
+	Langs     []string `json:"langs,omitempty"`
+	Facets    []Facet  `json:"facets,omitempty"`
+	Embed     *Embed   `json:"embed,omitempty"`
+}
+
+// Facet marks a range of the text, by UTF-8 byte offsets, as a link.
+type Facet struct {
+	Index    ByteSlice      `json:"index"`
+	Features []FacetFeature `json:"features"`
+}
+
+// ByteSlice is a facet's byte range.
+type ByteSlice struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+// FacetFeature is a facet's link target.
+type FacetFeature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri"`
+}
+
+// Embed is an app.bsky.embed.external link card.
+type Embed struct {
+	Type     string   `json:"$type"`
+	External External `json:"external"`
+}
+
+// External is the linked page shown in the card.
+type External struct {
+	URI         string `json:"uri"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// BuildPost builds the link post for a title, summary, and URL. The
+// summary is shortened to keep the text within MaxPostLength; the title
+// too, if it has to be.
+func BuildPost(title, summary, postURL, lang string, now time.Time) *Post {
+	budget := MaxPostLength - runeLen(postURL) - 2 // blank line before the URL
+	title = truncate(title, budget)
+	head := title
+	if summary != "" {
+		if room := budget - runeLen(title) - 2; room > 10 {
+			head += "\n\n" + truncate(summary, room)
+		}
+	}
+	text := head + "\n\n" + postURL
+	start := len(head) + 2
+
+	post := &Post{
+		Type:      PostCollection,
+		Text:      text,
+		CreatedAt: now.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Facets: []Facet{{
+			Index:    ByteSlice{ByteStart: start, ByteEnd: start + len(postURL)},
+			Features: []FacetFeature{{Type: "app.bsky.richtext.facet#link", URI: postURL}},
+		}},
+		Embed: &Embed{
+			Type:     "app.bsky.embed.external",
+			External: External{URI: postURL, Title: title, Description: summary},
+		},
+	}
+	if lang != "" {
+		post.Langs = []string{lang}
+	}
+	return post
+}
+
+// PostURL returns the bsky.app URL of a post record.
+func PostURL(handle, uri string) string {
+	rkey := uri[strings.LastIndex(uri, "/")+1:]
+	return "https://bsky.app/profile/" + handle + "/post/" + rkey
+}
+
+// Crosspost creates a link post on Bluesky for a published post and
+// records it in metadata/syndication.json. baseURL is the site's public
+// URL.
+func Crosspost(dataDir, baseURL string, cfg Config, postPath string) (*metadata.SyndicationLink, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("Bluesky is not configured (set bluesky.handle and BLUESKY_APP_PASSWORD)")
+	}
+	if baseURL == "" {
+		return nil, errors.New("the site has no base URL (set POLIS_BASE_URL)")
+	}
+	postPath = filepath.ToSlash(postPath)
+	if publish.IsProtectedPath(postPath) {
+		return nil, ErrNotPublic
+	}
+
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := synd.Link(postPath, Service); ok {
+		return nil, ErrAlreadyPosted
+	}
+
+	content, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(postPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post: %w", err)
+	}
+	fm := publish.ParseFrontmatterFields(string(content))
+	if fm.Visibility != publish.VisibilityPublic {
+		return nil, ErrNotPublic
+	}
+	title := fm.Title
+	if title == "" {
+		title = publish.ExtractTitle(publish.StripFrontmatter(string(content)))
+	}
+	summary := fm.Summary
+	if summary == "" {
+		summary = publish.Summarize(string(content))
+	}
+	postURL := publish.PageURL(baseURL, postPath)
+
+	client := NewClient(cfg.PDS)
+	sess, err := client.CreateSession(cfg.Handle, cfg.AppPassword)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := client.CreatePost(sess, BuildPost(title, summary, postURL, fm.Lang, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	link := metadata.SyndicationLink{
+		Service: Service,
+		URL:     PostURL(sess.Handle, ref.URI),
+		ID:      ref.URI,
+		CID:     ref.CID,
+		Posted:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	synd.Posts[postPath] = append(synd.Posts[postPath], link)
+	if err := metadata.SaveSyndication(dataDir, synd); err != nil {
+		return &link, fmt.Errorf("cross-posted, but failed to save %s: %w", metadata.SyndicationFilename, err)
+	}
+	return &link, nil
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+// truncate cuts s to at most n runes at a word boundary, adding an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	cut := string(r[:n-1])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
@@ -0,0 +1,126 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestBuildPost(t *testing.T) {
+	url := "https://example.com/posts/20260102/hello.html"
+	post := BuildPost("Héllo", "A short summary.", url, "fr", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	if post.Text != "Héllo\n\nA short summary.\n\n"+url {
+		t.Errorf("text = %q", post.Text)
+	}
+	idx := post.Facets[0].Index
+	if post.Text[idx.ByteStart:idx.ByteEnd] != url {
+		t.Errorf("facet covers %q", post.Text[idx.ByteStart:idx.ByteEnd])
+	}
+	if post.Embed.External.URI != url || post.Langs[0] != "fr" || post.CreatedAt != "2026-01-02T03:04:05.000Z" {
+		t.Errorf("post = %+v", post)
+	}
+
+	long := BuildPost("Title", strings.Repeat("word ", 100), url, "", time.Now())
+	if n := runeLen(long.Text); n > MaxPostLength {
+		t.Errorf("text is %d runes, want at most %d", n, MaxPostLength)
+	}
+	if !strings.Contains(long.Text, "…\n\n"+url) {
+		t.Errorf("summary not shortened: %q", long.Text)
+	}
+}
+
+func TestPostURL(t *testing.T) {
+	got := PostURL("alice.bsky.social", "at://did:plc:abc/app.bsky.feed.post/3kxyz")
+	if got != "https://bsky.app/profile/alice.bsky.social/post/3kxyz" {
+		t.Errorf("PostURL = %q", got)
+	}
+}
+
+// fakePDS serves createSession and createRecord, recording created posts.
+func fakePDS(t *testing.T, posts *[]Post) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xrpc/com.atproto.server.createSession":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["password"] != "app-pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"AuthenticationRequired","message":"Invalid identifier or password"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"accessJwt": "jwt", "handle": req["identifier"], "did": "did:plc:abc"})
+		case "/xrpc/com.atproto.repo.createRecord":
+			if r.Header.Get("Authorization") != "Bearer jwt" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req struct {
+				Repo   string `json:"repo"`
+				Record Post   `json:"record"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			*posts = append(*posts, req.Record)
+			json.NewEncoder(w).Encode(map[string]string{"uri": "at://" + req.Repo + "/app.bsky.feed.post/3kxyz", "cid": "bafy"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCrosspost(t *testing.T) {
+	var posts []Post
+	srv := fakePDS(t, &posts)
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	result, err := publish.PublishPostWithOptions(dataDir, "# Hello\n\nFirst paragraph here.\n", publish.PublishOptions{Slug: "hello"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Handle: "alice.bsky.social", AppPassword: "wrong", PDS: srv.URL}
+	if _, err := Crosspost(dataDir, "https://example.com", cfg, result.Path); err == nil || !strings.Contains(err.Error(), "Invalid identifier") {
+		t.Fatalf("bad password: err = %v", err)
+	}
+
+	cfg.AppPassword = "app-pass"
+	link, err := Crosspost(dataDir, "https://example.com/", cfg, result.Path)
+	if err != nil {
+		t.Fatalf("Crosspost failed: %v", err)
+	}
+	if link.URL != "https://bsky.app/profile/alice.bsky.social/post/3kxyz" || link.ID != "at://did:plc:abc/app.bsky.feed.post/3kxyz" {
+		t.Errorf("link = %+v", link)
+	}
+	wantURL := "https://example.com/" + strings.TrimSuffix(result.Path, ".md") + ".html"
+	if len(posts) != 1 || posts[0].Text != "Hello\n\nFirst paragraph here.\n\n"+wantURL {
+		t.Fatalf("posts = %+v", posts)
+	}
+
+	synd, _ := metadata.LoadSyndication(dataDir)
+	if got, ok := synd.Link(result.Path, Service); !ok || got.URL != link.URL {
+		t.Errorf("syndication.json = %+v", synd.Posts)
+	}
+	if _, err := Crosspost(dataDir, "https://example.com", cfg, result.Path); !errors.Is(err, ErrAlreadyPosted) {
+		t.Errorf("second crosspost: err = %v", err)
+	}
+
+	unlisted, _ := publish.PublishPostWithOptions(dataDir, "# Quiet\n\nShh.\n", publish.PublishOptions{Unlisted: true}, privKey)
+	if _, err := Crosspost(dataDir, "https://example.com", cfg, unlisted.Path); !errors.Is(err, ErrNotPublic) {
+		t.Errorf("unlisted post: err = %v", err)
+	}
+	if len(posts) != 1 {
+		t.Errorf("created %d posts, want 1", len(posts))
+	}
+}
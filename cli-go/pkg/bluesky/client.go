@@ -0,0 +1,126 @@
+package bluesky
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
+)
+
+// DefaultPDS is the personal data server used when bluesky.pds is unset.
+const DefaultPDS = "https://bsky.social"
+
+// Client talks to an AT Protocol personal data server (PDS).
+type Client struct {
+	PDS        string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the given PDS (DefaultPDS if empty).
+func NewClient(pds string) *Client {
+	if pds == "" {
+		pds = DefaultPDS
+	}
+	return &Client{
+		PDS:        strings.TrimSuffix(pds, "/"),
+		HTTPClient: safehttp.NewClient(safehttp.Options{Timeout: 30 * time.Second}),
+	}
+}
+
+// Session is an authenticated session from com.atproto.server.createSession.
+type Session struct {
+	AccessJwt string `json:"accessJwt"`
+	Handle    string `json:"handle"`
+	DID       string `json:"did"`
+}
+
+// StrongRef identifies a created record.
+type StrongRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// apiError is the error body XRPC endpoints return.
+type apiError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// CreateSession logs in with a handle (or DID) and an app password.
+func (c *Client) CreateSession(identifier, password string) (*Session, error) {
+	var sess Session
+	err := c.call("com.atproto.server.createSession", "", map[string]string{
+		"identifier": identifier,
+		"password":   password,
+	}, &sess)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	if sess.AccessJwt == "" || sess.DID == "" {
+		return nil, fmt.Errorf("login failed: incomplete session in response")
+	}
+	return &sess, nil
+}
+
+// CreatePost creates an app.bsky.feed.post record in the session's repo.
+func (c *Client) CreatePost(sess *Session, post *Post) (*StrongRef, error) {
+	var ref StrongRef
+	err := c.call("com.atproto.repo.createRecord", sess.AccessJwt, map[string]interface{}{
+		"repo":       sess.DID,
+		"collection": PostCollection,
+		"record":     post,
+	}, &ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	if ref.URI == "" {
+		return nil, fmt.Errorf("failed to create post: no URI in response")
+	}
+	return &ref, nil
+}
+
+// call POSTs a JSON body to an XRPC procedure and decodes the response.
+func (c *Client) call(method, token string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest("POST", c.PDS+"/xrpc/"+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		var e apiError
+		if json.Unmarshal(respBody, &e) == nil && e.Error != "" {
+			if e.Message != "" {
+				return fmt.Errorf("%s: %s", e.Error, e.Message)
+			}
+			return fmt.Errorf("%s", e.Error)
+		}
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Package book compiles published posts into a single book: an EPUB, or
+// one print-ready HTML file to save as PDF from a browser. Posts are
+// selected from the public index by tag, series, or year, and appear
+// oldest first. Cover metadata (title, author, language) comes from
+// .well-known/polis.
+package book
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// Export formats.
+const (
+	FormatEPUB = "epub"
+	FormatPDF  = "pdf" // Print-ready HTML; saved as PDF from a browser
+)
+
+// Options selects the posts in a book and overrides its cover metadata.
+// Tag and Series are matched case-insensitively; empty fields select
+// everything.
+type Options struct {
+	Tag    string
+	Series string // The series: frontmatter field
+	Year   string // Published year, e.g. "2025"
+	Title  string // Book title (default: the series, else the site title)
+
+	// MarkdownRenderer renders post bodies to HTML. Its output should be
+	// XHTML (void elements closed), as EPUB requires.
+	MarkdownRenderer func(string) (string, error)
+}
+
+// Meta is a book's cover metadata.
+type Meta struct {
+	Title      string `json:"title"`
+	Subtitle   string `json:"subtitle,omitempty"` // Describes the selection
+	Author     string `json:"author"`
+	Lang       string `json:"lang"`
+	SiteURL    string `json:"site_url,omitempty"`
+	Identifier string `json:"identifier"` // Stable for the same site and selection
+}
+
+// Chapter is one post in a book.
+type Chapter struct {
+	Path      string `json:"path"`
+	Title     string `json:"title"`
+	Published string `json:"published"`
+	HTML      string `json:"-"`
+}
+
+// Book is a compiled selection of posts.
+type Book struct {
+	Meta     Meta      `json:"meta"`
+	Chapters []Chapter `json:"chapters"`
+
+	dataDir string
+}
+
+// Load selects and renders the posts for a book. It is an error for the
+// selection to be empty.
+func Load(dataDir string, opts Options) (*Book, error) {
+	if opts.MarkdownRenderer == nil {
+		return nil, fmt.Errorf("no markdown renderer")
+	}
+	entries, err := metadata.GetPostEntries(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public index: %w", err)
+	}
+
+	b := &Book{Meta: loadMeta(dataDir, opts), dataDir: dataDir}
+	for _, e := range entries {
+		if opts.Year != "" && !strings.HasPrefix(e.Published, opts.Year) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(e.Path)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Path, err)
+		}
+		fm := publish.ParseFrontmatterFields(string(content))
+		if fm.Visibility == publish.VisibilityFollowers {
+			continue // Books are for sharing
+		}
+		if opts.Tag != "" && !hasTag(fm.Tags, opts.Tag) {
+			continue
+		}
+		if opts.Series != "" {
+			series := seriesOf(string(content))
+			if !strings.EqualFold(series, opts.Series) {
+				continue
+			}
+			if opts.Title == "" {
+				b.Meta.Title = series // As the posts spell it
+			}
+		}
+
+		body, err := opts.MarkdownRenderer(publish.StripFrontmatter(string(content)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", e.Path, err)
+		}
+		title := fm.Title
+		if title == "" {
+			title = e.Title
+		}
+		b.Chapters = append(b.Chapters, Chapter{
+			Path:      e.Path,
+			Title:     title,
+			Published: e.Published,
+			HTML:      xmlEntities(body),
+		})
+	}
+	if len(b.Chapters) == 0 {
+		return nil, fmt.Errorf("no published posts match the selection")
+	}
+	sort.SliceStable(b.Chapters, func(i, j int) bool { return b.Chapters[i].Published < b.Chapters[j].Published })
+	return b, nil
+}
+
+// hasTag reports whether tags contains tag, ignoring case.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesOf returns a post's series: frontmatter field.
+func seriesOf(content string) string {
+	s := strings.TrimSpace(publish.ParseFrontmatter(content)["series"])
+	return strings.Trim(s, `"'`)
+}
+
+// loadMeta builds the cover metadata from .well-known/polis and opts.
+func loadMeta(dataDir string, opts Options) Meta {
+	var wk struct {
+		SiteTitle  string `json:"site_title"`
+		Author     string `json:"author"`
+		AuthorName string `json:"author_name"`
+		Domain     string `json:"domain"`
+		BaseURL    string `json:"base_url"`
+		Lang       string `json:"lang"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dataDir, ".well-known", "polis")); err == nil {
+		json.Unmarshal(data, &wk)
+	}
+
+	m := Meta{Title: opts.Title, Author: wk.AuthorName, Lang: wk.Lang, SiteURL: wk.BaseURL}
+	if m.Author == "" {
+		m.Author = wk.Author
+	}
+	if m.Author == "" {
+		m.Author = wk.Domain
+	}
+	if m.Lang == "" {
+		m.Lang = "en"
+	}
+
+	var about []string
+	switch {
+	case opts.Series != "":
+		if m.Title == "" {
+			m.Title = opts.Series
+		}
+	case opts.Tag != "":
+		about = append(about, "Posts tagged "+opts.Tag)
+	}
+	if opts.Year != "" {
+		about = append(about, opts.Year)
+	}
+	m.Subtitle = strings.Join(about, ", ")
+	if m.Title == "" {
+		m.Title = wk.SiteTitle
+	}
+	if m.Title == "" {
+		m.Title = wk.Domain
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{wk.BaseURL, wk.Domain, opts.Tag, opts.Series, opts.Year}, "\x00")))
+	h := fmt.Sprintf("%x", sum[:16])
+	m.Identifier = fmt.Sprintf("urn:uuid:%s-%s-5%s-8%s-%s", h[0:8], h[8:12], h[13:16], h[17:20], h[20:32])
+	return m
+}
+
+// xmlEntity matches a named character reference.
+var xmlEntity = regexp.MustCompile(`&[a-zA-Z][a-zA-Z0-9]*;`)
+
+// xmlEntities replaces HTML named character references other than the five
+// XML defines (the typographer emits &rsquo; and friends) with the
+// characters themselves, so chapters are well-formed XHTML.
+func xmlEntities(s string) string {
+	return xmlEntity.ReplaceAllStringFunc(s, func(ref string) string {
+		switch ref {
+		case "&amp;", "&lt;", "&gt;", "&quot;", "&apos;":
+			return ref
+		}
+		if c := html.UnescapeString(ref); c != ref {
+			return html.EscapeString(c)
+		}
+		return "&amp;" + ref[1:]
+	})
+}
@@ -0,0 +1,145 @@
+package book
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderMarkdown stands in for render.MarkdownToHTML, which imports too
+// much to use here.
+func renderMarkdown(md string) (string, error) {
+	return "<p>" + strings.TrimSpace(md) + "</p>", nil
+}
+
+func setupSite(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".well-known"), 0755)
+	os.WriteFile(filepath.Join(dir, ".well-known", "polis"),
+		[]byte(`{"site_title":"Field Notes","author_name":"Ada","base_url":"https://example.com","lang":"en"}`), 0644)
+	os.MkdirAll(filepath.Join(dir, "metadata"), 0755)
+	os.WriteFile(filepath.Join(dir, "metadata", "public.jsonl"), []byte(
+		`{"type":"post","path":"posts/20250301/b.md","title":"B","published":"2025-03-01T00:00:00Z"}`+"\n"+
+			`{"type":"post","path":"posts/20250101/a.md","title":"A","published":"2025-01-01T00:00:00Z"}`+"\n"+
+			`{"type":"post","path":"posts/20240601/c.md","title":"C","published":"2024-06-01T00:00:00Z"}`+"\n"), 0644)
+	posts := map[string]string{
+		"posts/20250101/a.md": "---\ntitle: A\ntags: [travel]\nseries: Letters\n---\nIt&rsquo;s <img src=\"photo.png\" /> here.\n",
+		"posts/20250301/b.md": "---\ntitle: B\ntags: [food]\n---\nSecond.\n",
+		"posts/20240601/c.md": "---\ntitle: C\ntags: [Travel]\n---\nThird.\n",
+	}
+	for path, content := range posts {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	os.WriteFile(filepath.Join(dir, "posts", "20250101", "photo.png"), []byte("\x89PNG"), 0644)
+	return dir
+}
+
+func TestLoad_Selection(t *testing.T) {
+	dir := setupSite(t)
+	tests := []struct {
+		name  string
+		opts  Options
+		want  []string
+		title string
+	}{
+		{"all", Options{}, []string{"C", "A", "B"}, "Field Notes"},
+		{"tag", Options{Tag: "travel"}, []string{"C", "A"}, "Field Notes"},
+		{"series", Options{Series: "letters"}, []string{"A"}, "Letters"},
+		{"year", Options{Year: "2025"}, []string{"A", "B"}, "Field Notes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.MarkdownRenderer = renderMarkdown
+			b, err := Load(dir, tt.opts)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			var got []string
+			for _, ch := range b.Chapters {
+				got = append(got, ch.Title)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("chapters = %v, want %v", got, tt.want)
+			}
+			if b.Meta.Title != tt.title || b.Meta.Author != "Ada" {
+				t.Errorf("unexpected meta: %+v", b.Meta)
+			}
+		})
+	}
+
+	if _, err := Load(dir, Options{Tag: "none", MarkdownRenderer: renderMarkdown}); err == nil {
+		t.Error("expected an error for an empty selection")
+	}
+}
+
+func TestWriteEPUB(t *testing.T) {
+	b, err := Load(setupSite(t), Options{Series: "Letters", MarkdownRenderer: renderMarkdown})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := b.WriteEPUB(&buf); err != nil {
+		t.Fatalf("WriteEPUB failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	if f := zr.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want stored mimetype", f.Name, f.Method)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/chapter-1.xhtml", "OEBPS/images/1.png"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	chapter := files["OEBPS/chapter-1.xhtml"]
+	if !strings.Contains(chapter, "It’s") || !strings.Contains(chapter, `src="images/1.png"`) {
+		t.Errorf("unexpected chapter:\n%s", chapter)
+	}
+	for _, name := range []string{"OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/chapter-1.xhtml"} {
+		d := xml.NewDecoder(strings.NewReader(files[name]))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v", name, err)
+				break
+			}
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	b, err := Load(setupSite(t), Options{Year: "2025", MarkdownRenderer: renderMarkdown})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := b.WriteHTML(&buf); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`<p class="subtitle">2025</p>`, `<a href="#chapter-2">B</a>`, `src="data:image/png;base64,`, "@page"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
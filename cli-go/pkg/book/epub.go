@@ -0,0 +1,219 @@
+package book
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// imgSrc matches the src attribute of an <img> tag.
+var imgSrc = regexp.MustCompile(`(<img\b[^>]*?\bsrc=")([^"]+)(")`)
+
+// bookCSS styles chapters in both the EPUB and the print HTML.
+const bookCSS = `body { font-family: Georgia, serif; line-height: 1.5; }
+h1 { font-size: 1.6em; margin: 0 0 0.2em; }
+.published { color: #666; font-style: italic; margin: 0 0 2em; }
+img { max-width: 100%; height: auto; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+blockquote { margin: 1em 1.5em; font-style: italic; }
+.cover { text-align: center; margin-top: 30%; }
+.cover h1 { font-size: 2.2em; }
+.cover .subtitle { font-size: 1.2em; }
+.cover .author { font-size: 1.3em; margin-top: 2em; }
+`
+
+// epubImage is a site image copied into the EPUB.
+type epubImage struct {
+	name      string // Name inside OEBPS/
+	source    string // Path on disk
+	mediaType string
+}
+
+// WriteEPUB writes the book as an EPUB 3 file. Images stored on the site
+// are embedded; remote images are left as links, which most readers
+// don't load.
+func (b *Book) WriteEPUB(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first, uncompressed
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	io.WriteString(mw, "application/epub+zip")
+
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`,
+		"OEBPS/style.css":   bookCSS,
+		"OEBPS/cover.xhtml": b.xhtmlPage(b.Meta.Title, b.coverHTML()),
+		"OEBPS/nav.xhtml":   b.navXHTML(),
+	}
+	order := []string{"META-INF/container.xml", "OEBPS/style.css", "OEBPS/cover.xhtml", "OEBPS/nav.xhtml"}
+
+	var images []epubImage
+	for i, ch := range b.Chapters {
+		body := imgSrc.ReplaceAllStringFunc(ch.HTML, func(tag string) string {
+			m := imgSrc.FindStringSubmatch(tag)
+			src := b.localImage(ch.Path, html.UnescapeString(m[2]))
+			if src == "" {
+				return tag
+			}
+			name := fmt.Sprintf("images/%d%s", len(images)+1, strings.ToLower(filepath.Ext(src)))
+			images = append(images, epubImage{name: name, source: src, mediaType: mime.TypeByExtension(filepath.Ext(src))})
+			return m[1] + name + m[3]
+		})
+		name := fmt.Sprintf("OEBPS/chapter-%d.xhtml", i+1)
+		files[name] = b.xhtmlPage(ch.Title, chapterHTML(ch, body))
+		order = append(order, name)
+	}
+	files["OEBPS/content.opf"] = b.packageOPF(images)
+	order = append(order, "OEBPS/content.opf")
+
+	for _, name := range order {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, files[name]); err != nil {
+			return err
+		}
+	}
+	for _, img := range images {
+		data, err := os.ReadFile(img.source)
+		if err != nil {
+			return fmt.Errorf("failed to read image: %w", err)
+		}
+		fw, err := zw.Create("OEBPS/" + img.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// localImage returns the file on disk an image src in the post at
+// postPath refers to, or "" for remote images and missing files.
+func (b *Book) localImage(postPath, src string) string {
+	if src == "" || strings.Contains(src, ":") || strings.HasPrefix(src, "//") {
+		return ""
+	}
+	src, _, _ = strings.Cut(src, "?")
+	var rel string
+	if strings.HasPrefix(src, "/") {
+		rel = path.Clean(strings.TrimPrefix(src, "/"))
+	} else {
+		rel = path.Join(path.Dir(postPath), src)
+	}
+	if rel == ".." || strings.HasPrefix(rel, "../") || mime.TypeByExtension(path.Ext(rel)) == "" {
+		return ""
+	}
+	full := filepath.Join(b.dataDir, filepath.FromSlash(rel))
+	if info, err := os.Stat(full); err != nil || info.IsDir() {
+		return ""
+	}
+	return full
+}
+
+// xhtmlPage wraps body in an XHTML document.
+func (b *Book) xhtmlPage(title, body string) string {
+	lang := html.EscapeString(b.Meta.Lang)
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + lang + `" lang="` + lang + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + html.EscapeString(title) + `</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+// coverHTML is the title page.
+func (b *Book) coverHTML() string {
+	var s strings.Builder
+	s.WriteString(`<section class="cover">`)
+	fmt.Fprintf(&s, `<h1>%s</h1>`, html.EscapeString(b.Meta.Title))
+	if b.Meta.Subtitle != "" {
+		fmt.Fprintf(&s, `<p class="subtitle">%s</p>`, html.EscapeString(b.Meta.Subtitle))
+	}
+	fmt.Fprintf(&s, `<p class="author">%s</p>`, html.EscapeString(b.Meta.Author))
+	if b.Meta.SiteURL != "" {
+		fmt.Fprintf(&s, `<p class="site">%s</p>`, html.EscapeString(b.Meta.SiteURL))
+	}
+	s.WriteString(`</section>`)
+	return s.String()
+}
+
+// chapterHTML is a chapter's heading, date, and body.
+func chapterHTML(ch Chapter, body string) string {
+	s := `<h1>` + html.EscapeString(ch.Title) + `</h1>`
+	if t, err := time.Parse(time.RFC3339, ch.Published); err == nil {
+		s += `<p class="published">` + t.Format("January 2, 2006") + `</p>`
+	}
+	return s + "\n" + body
+}
+
+// navXHTML is the EPUB 3 table of contents.
+func (b *Book) navXHTML() string {
+	var s strings.Builder
+	s.WriteString(`<nav epub:type="toc" id="toc"><h1>Contents</h1><ol>`)
+	for i, ch := range b.Chapters {
+		fmt.Fprintf(&s, `<li><a href="chapter-%d.xhtml">%s</a></li>`, i+1, html.EscapeString(ch.Title))
+	}
+	s.WriteString(`</ol></nav>`)
+	return b.xhtmlPage("Contents", s.String())
+}
+
+// packageOPF is the EPUB package document.
+func (b *Book) packageOPF(images []epubImage) string {
+	var manifest, spine strings.Builder
+	for i := range b.Chapters {
+		fmt.Fprintf(&manifest, "    <item id=\"chapter-%d\" href=\"chapter-%d.xhtml\" media-type=\"application/xhtml+xml\"/>\n", i+1, i+1)
+		fmt.Fprintf(&spine, "    <itemref idref=\"chapter-%d\"/>\n", i+1)
+	}
+	for i, img := range images {
+		fmt.Fprintf(&manifest, "    <item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, img.name, html.EscapeString(img.mediaType))
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + html.EscapeString(b.Meta.Lang) + `">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">` + html.EscapeString(b.Meta.Identifier) + `</dc:identifier>
+    <dc:title>` + html.EscapeString(b.Meta.Title) + `</dc:title>
+    <dc:creator>` + html.EscapeString(b.Meta.Author) + `</dc:creator>
+    <dc:language>` + html.EscapeString(b.Meta.Lang) + `</dc:language>
+    <meta property="dcterms:modified">` + time.Now().UTC().Format("2006-01-02T15:04:05Z") + `</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+` + manifest.String() + `  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="nav"/>
+` + spine.String() + `  </spine>
+</package>
+`
+}
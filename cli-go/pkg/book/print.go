@@ -0,0 +1,64 @@
+package book
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// printCSS lays the book out for paper: a cover page, then each chapter on
+// a new page.
+const printCSS = `@page { size: A5; margin: 20mm 18mm; }
+body { font-size: 11pt; max-width: 40em; margin: 0 auto; }
+.cover, .toc, .chapter { page-break-after: always; break-after: page; }
+.toc ol { padding-left: 1.2em; }
+.toc a { color: inherit; text-decoration: none; }
+a { color: inherit; }
+h1, h2, h3 { page-break-after: avoid; break-after: avoid; }
+img, pre, blockquote, table { page-break-inside: avoid; break-inside: avoid; }
+`
+
+// WriteHTML writes the book as one self-contained HTML file laid out for
+// printing; print it to PDF from a browser. Images stored on the site are
+// inlined.
+func (b *Book) WriteHTML(w io.Writer) error {
+	var s strings.Builder
+	lang := html.EscapeString(b.Meta.Lang)
+	fmt.Fprintf(&s, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"UTF-8\">\n", lang)
+	fmt.Fprintf(&s, "<title>%s</title>\n", html.EscapeString(b.Meta.Title))
+	fmt.Fprintf(&s, "<meta name=\"author\" content=\"%s\">\n", html.EscapeString(b.Meta.Author))
+	s.WriteString("<style>\n" + bookCSS + printCSS + "</style>\n</head>\n<body>\n")
+
+	s.WriteString(b.coverHTML() + "\n")
+	s.WriteString(`<nav class="toc"><h1>Contents</h1><ol>`)
+	for i, ch := range b.Chapters {
+		fmt.Fprintf(&s, `<li><a href="#chapter-%d">%s</a></li>`, i+1, html.EscapeString(ch.Title))
+	}
+	s.WriteString("</ol></nav>\n")
+
+	for i, ch := range b.Chapters {
+		body := imgSrc.ReplaceAllStringFunc(ch.HTML, func(tag string) string {
+			m := imgSrc.FindStringSubmatch(tag)
+			src := b.localImage(ch.Path, html.UnescapeString(m[2]))
+			if src == "" {
+				return tag
+			}
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return tag
+			}
+			uri := "data:" + mime.TypeByExtension(filepath.Ext(src)) + ";base64," + base64.StdEncoding.EncodeToString(data)
+			return m[1] + uri + m[3]
+		})
+		fmt.Fprintf(&s, "<section class=\"chapter\" id=\"chapter-%d\">\n%s\n</section>\n", i+1, chapterHTML(ch, body))
+	}
+	s.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, s.String())
+	return err
+}
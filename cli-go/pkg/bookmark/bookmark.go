@@ -0,0 +1,304 @@
+// Package bookmark manages the read-later list in metadata/bookmarks.json.
+//
+// Each bookmark keeps a snapshot of the remote post's content in
+// .polis/bookmarks/<id>.md, so bookmarked posts stay readable after they
+// age out of the feed cache or disappear from the author's site. The list
+// is published with the site like following.json; snapshots are not.
+package bookmark
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// Version is set at init time by cmd package.
+var Version = "dev"
+
+// GetGenerator returns the generator identifier for metadata files.
+func GetGenerator() string {
+	return "polis-cli-go/" + Version
+}
+
+// ErrInsecureURL is returned when a bookmark URL is not HTTPS.
+var ErrInsecureURL = errors.New("bookmark URL must use HTTPS")
+
+// File represents the bookmarks.json structure.
+type File struct {
+	Version   string     `json:"version"`
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+// Bookmark is a saved remote post.
+type Bookmark struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	Title        string `json:"title,omitempty"`
+	AuthorURL    string `json:"author_url,omitempty"`
+	AuthorDomain string `json:"author_domain,omitempty"`
+	Published    string `json:"published,omitempty"`
+	Note         string `json:"note,omitempty"`
+	AddedAt      string `json:"added_at"`
+	SnapshotAt   string `json:"snapshot_at,omitempty"`  // When the content was last fetched
+	SnapshotURL  string `json:"snapshot_url,omitempty"` // URL the snapshot came from (may differ in extension)
+}
+
+// ComputeID returns a bookmark's deterministic ID: the first 16 hex chars
+// of sha256(url), matching the length of feed item IDs.
+func ComputeID(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return fmt.Sprintf("%x", h[:8])
+}
+
+// DefaultPath returns the default path to bookmarks.json.
+func DefaultPath(dataDir string) string {
+	return filepath.Join(dataDir, "metadata", "bookmarks.json")
+}
+
+// SnapshotPath returns the path of a bookmark's content snapshot.
+func SnapshotPath(dataDir, id string) string {
+	return filepath.Join(dataDir, ".polis", "bookmarks", id+".md")
+}
+
+// Load loads bookmarks.json from the given path. A missing file is an
+// empty list.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{Version: GetGenerator(), Bookmarks: []Bookmark{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read bookmarks.json: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks.json: %w", err)
+	}
+	if f.Bookmarks == nil {
+		f.Bookmarks = []Bookmark{}
+	}
+	return &f, nil
+}
+
+// Save saves bookmarks.json to the given path.
+func Save(path string, f *File) error {
+	f.Version = GetGenerator()
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bookmarks.json: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write bookmarks.json: %w", err)
+	}
+	return nil
+}
+
+// Get returns the bookmark with the given ID or URL, or nil.
+func (f *File) Get(idOrURL string) *Bookmark {
+	for i := range f.Bookmarks {
+		if f.Bookmarks[i].ID == idOrURL || f.Bookmarks[i].URL == idOrURL {
+			return &f.Bookmarks[i]
+		}
+	}
+	return nil
+}
+
+// Add appends b, newest first, unless its URL is already bookmarked.
+// Returns false if it was already present.
+func (f *File) Add(b Bookmark) bool {
+	if f.Get(b.URL) != nil {
+		return false
+	}
+	f.Bookmarks = append([]Bookmark{b}, f.Bookmarks...)
+	return true
+}
+
+// Remove deletes the bookmark with the given ID or URL and returns it,
+// or nil if there was none.
+func (f *File) Remove(idOrURL string) *Bookmark {
+	for i, b := range f.Bookmarks {
+		if b.ID == idOrURL || b.URL == idOrURL {
+			f.Bookmarks = append(f.Bookmarks[:i], f.Bookmarks[i+1:]...)
+			return &b
+		}
+	}
+	return nil
+}
+
+// Count returns the number of bookmarks.
+func (f *File) Count() int {
+	return len(f.Bookmarks)
+}
+
+// Options describes a bookmark to add. Only URL is required; the other
+// fields fill in what the snapshot's frontmatter does not provide.
+type Options struct {
+	URL          string
+	Title        string
+	AuthorURL    string
+	AuthorDomain string
+	Published    string
+	Note         string
+	// Client fetches the snapshot. Nil skips it (the bookmark is saved
+	// without content).
+	Client *remote.Client
+}
+
+// FromFeedItem returns Options describing a cached feed item.
+func FromFeedItem(item feed.CachedFeedItem) Options {
+	return Options{
+		URL:          item.URL,
+		Title:        item.Title,
+		AuthorURL:    item.AuthorURL,
+		AuthorDomain: item.AuthorDomain,
+		Published:    item.Published,
+	}
+}
+
+// FindFeedItem looks up a feed cache item by ID or URL, so bookmarks made
+// from the feed carry its title and author.
+func FindFeedItem(dataDir, discoveryDomain, idOrURL string) *feed.CachedFeedItem {
+	items, err := feed.NewCacheManager(dataDir, discoveryDomain).List()
+	if err != nil {
+		return nil
+	}
+	for i := range items {
+		if items[i].ID == idOrURL || items[i].URL == idOrURL {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// Add bookmarks opts.URL in dataDir's bookmarks.json and saves a snapshot
+// of its content. Bookmarking a URL twice refreshes its snapshot and note
+// and returns added=false. A failed fetch still saves the bookmark and
+// is returned as snapErr.
+func Add(dataDir string, opts Options) (b *Bookmark, added bool, snapErr error, err error) {
+	if !strings.HasPrefix(opts.URL, "https://") {
+		return nil, false, nil, ErrInsecureURL
+	}
+
+	var snap *Snapshot
+	if opts.Client != nil {
+		snap, snapErr = FetchSnapshot(opts.Client, opts.URL)
+	}
+
+	path := DefaultPath(dataDir)
+	err = lockfile.With(path, func() error {
+		f, err := Load(path)
+		if err != nil {
+			return err
+		}
+		existing := f.Get(opts.URL)
+		if existing == nil {
+			f.Add(Bookmark{
+				ID:           ComputeID(opts.URL),
+				URL:          opts.URL,
+				Title:        opts.Title,
+				AuthorURL:    opts.AuthorURL,
+				AuthorDomain: opts.AuthorDomain,
+				Published:    opts.Published,
+				AddedAt:      time.Now().UTC().Format(time.RFC3339),
+			})
+			existing = f.Get(opts.URL)
+			added = true
+		}
+		if opts.Note != "" {
+			existing.Note = opts.Note
+		}
+		if snap != nil {
+			if err := writeSnapshot(dataDir, existing.ID, snap.Content); err != nil {
+				return err
+			}
+			existing.SnapshotAt = time.Now().UTC().Format(time.RFC3339)
+			existing.SnapshotURL = snap.URL
+			if existing.Title == "" {
+				existing.Title = snap.Title
+			}
+			if existing.Published == "" {
+				existing.Published = snap.Published
+			}
+		}
+		if existing.Title == "" {
+			existing.Title = titleFromURL(opts.URL)
+		}
+		copied := *existing
+		b = &copied
+		return Save(path, f)
+	})
+	if err != nil {
+		return nil, false, snapErr, err
+	}
+	return b, added, snapErr, nil
+}
+
+// Remove deletes a bookmark and its snapshot. Returns the removed
+// bookmark, or nil if none matched idOrURL.
+func Remove(dataDir, idOrURL string) (*Bookmark, error) {
+	path := DefaultPath(dataDir)
+	var removed *Bookmark
+	err := lockfile.With(path, func() error {
+		f, err := Load(path)
+		if err != nil {
+			return err
+		}
+		if removed = f.Remove(idOrURL); removed == nil {
+			return nil
+		}
+		return Save(path, f)
+	})
+	if err != nil || removed == nil {
+		return nil, err
+	}
+	os.Remove(SnapshotPath(dataDir, removed.ID))
+	return removed, nil
+}
+
+// ReadSnapshot returns the saved content of bookmark id, or "" if it has
+// none.
+func ReadSnapshot(dataDir, id string) (string, error) {
+	data, err := os.ReadFile(SnapshotPath(dataDir, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func writeSnapshot(dataDir, id, content string) error {
+	path := SnapshotPath(dataDir, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// titleFromURL derives a fallback title from the last path segment.
+func titleFromURL(url string) string {
+	base := url[strings.LastIndex(url, "/")+1:]
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".md"), ".html")
+	if base == "" {
+		return url
+	}
+	return strings.ReplaceAll(base, "-", " ")
+}
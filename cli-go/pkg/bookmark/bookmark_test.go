@@ -0,0 +1,87 @@
+package bookmark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+func TestAddSnapshotAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts/hello.html":
+			w.Write([]byte("<!DOCTYPE html><html><body>rendered</body></html>"))
+		case "/posts/hello.md":
+			w.Write([]byte("---\ntitle: Hello World\npublished: 2026-01-02T00:00:00Z\n---\n\nBody text\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client := &remote.Client{HTTPClient: srv.Client()}
+	url := srv.URL + "/posts/hello.html"
+
+	b, added, snapErr, err := Add(dir, Options{URL: url, Note: "read later", Client: client})
+	if err != nil || snapErr != nil {
+		t.Fatalf("Add: err=%v snapErr=%v", err, snapErr)
+	}
+	if !added || b.ID != ComputeID(url) || len(b.ID) != 16 {
+		t.Errorf("bookmark = %+v added=%v, want new bookmark with 16-char ID", b, added)
+	}
+	if b.Title != "Hello World" || b.Published != "2026-01-02T00:00:00Z" || b.Note != "read later" {
+		t.Errorf("bookmark = %+v, want title/published from frontmatter and note kept", b)
+	}
+	if b.SnapshotURL != srv.URL+"/posts/hello.md" {
+		t.Errorf("SnapshotURL = %q, want the markdown source", b.SnapshotURL)
+	}
+	content, _ := ReadSnapshot(dir, b.ID)
+	if !strings.Contains(content, "Body text") {
+		t.Errorf("snapshot = %q, want fetched markdown", content)
+	}
+
+	// Re-adding refreshes rather than duplicating
+	if _, added, _, err := Add(dir, Options{URL: url, Client: client}); err != nil || added {
+		t.Errorf("re-Add: added=%v err=%v, want existing bookmark", added, err)
+	}
+	f, _ := Load(DefaultPath(dir))
+	if f.Count() != 1 || f.Bookmarks[0].Note != "read later" {
+		t.Errorf("bookmarks = %+v, want one entry with note preserved", f.Bookmarks)
+	}
+
+	removed, err := Remove(dir, b.ID)
+	if err != nil || removed == nil || removed.URL != url {
+		t.Fatalf("Remove = %+v, %v", removed, err)
+	}
+	if _, err := os.Stat(SnapshotPath(dir, b.ID)); !os.IsNotExist(err) {
+		t.Error("snapshot not removed with bookmark")
+	}
+	if removed, _ := Remove(dir, url); removed != nil {
+		t.Error("second Remove returned a bookmark")
+	}
+}
+
+func TestAddFetchFailureKeepsBookmark(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	url := srv.URL + "/posts/gone-post.md"
+
+	b, added, snapErr, err := Add(dir, Options{URL: url, Client: &remote.Client{HTTPClient: srv.Client()}})
+	if err != nil || snapErr == nil {
+		t.Fatalf("Add: err=%v snapErr=%v, want saved bookmark with snapshot error", err, snapErr)
+	}
+	if !added || b.SnapshotAt != "" || b.Title != "gone post" {
+		t.Errorf("bookmark = %+v, want no snapshot and title from URL", b)
+	}
+}
+
+func TestAddRejectsHTTP(t *testing.T) {
+	if _, _, _, err := Add(t.TempDir(), Options{URL: "http://example.com/post.md"}); err == nil {
+		t.Error("Add accepted a non-HTTPS URL")
+	}
+}
@@ -0,0 +1,32 @@
+package bookmark
+
+import (
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// Snapshot is the fetched content of a bookmarked post.
+type Snapshot struct {
+	URL       string // URL the content was fetched from
+	Content   string // Raw content, frontmatter included
+	Title     string
+	Published string
+}
+
+// FetchSnapshot fetches url's content, preferring the markdown source as
+// the remote post viewer does.
+func FetchSnapshot(client *remote.Client, url string) (*Snapshot, error) {
+	content, fetchedURL, err := client.FetchSource(url)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{URL: fetchedURL, Content: content}
+	if !remote.LooksLikeHTML(snap.Content) {
+		fm := publish.ParseFrontmatter(snap.Content)
+		snap.Title = strings.Trim(fm["title"], `"'`)
+		snap.Published = fm["published"]
+	}
+	return snap, nil
+}
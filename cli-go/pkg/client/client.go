@@ -0,0 +1,524 @@
+// Package client is a Go client for the JSON API of a running polis serve
+// instance, so tools and tests can drive the local server without writing
+// HTTP calls by hand.
+//
+// Typed methods cover the common workflows (status, drafts, publishing,
+// the feed, blessings, following, notifications, and settings). Every other
+// endpoint listed in /api/openapi.json can be called with Do.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/blessing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// APIPrefix is the versioned path prefix the client calls.
+const APIPrefix = "/api/v1"
+
+// Errors matched by APIError, for use with errors.Is.
+var (
+	ErrBadRequest       = errors.New("bad request")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrNotFound         = errors.New("not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUnavailable      = errors.New("server unavailable")
+)
+
+// APIError is returned for non-2xx responses. Message is the server's
+// error text.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// Unwrap maps the status code to one of the Err* sentinels.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrMethodNotAllowed
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Client calls the API of one serve instance.
+type Client struct {
+	BaseURL    string // e.g. "http://localhost:53123"
+	Token      string // API user token, for servers with users (polis access)
+	HTTPClient *http.Client
+}
+
+// New returns a client for the server at baseURL.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		// Publishing re-renders the site, so allow slow responses; callers
+		// bound individual calls with their context
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Do sends a request to path (relative to APIPrefix, e.g. "/feed/counts"),
+// encoding body as JSON when it is non-nil and decoding the response into
+// out when it is non-nil.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+APIPrefix+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Path:       path,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// withQuery appends non-empty query parameters to path.
+func withQuery(path string, params url.Values) string {
+	for k, v := range params {
+		if len(v) == 0 || v[0] == "" {
+			delete(params, k)
+		}
+	}
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
+
+// ============================================================================
+// Site
+// ============================================================================
+
+// Status is the response from GET /status.
+type Status struct {
+	Configured bool   `json:"configured"`
+	SiteTitle  string `json:"site_title"`
+	BaseURL    string `json:"base_url"`
+	Validation struct {
+		Status site.ValidationStatus  `json:"status"`
+		Errors []site.ValidationError `json:"errors"`
+	} `json:"validation"`
+	SiteInfo        *site.SiteInfo `json:"site_info,omitempty"`
+	ShowFrontmatter bool           `json:"show_frontmatter"`
+}
+
+// Status returns the site's status and identity.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var st Status
+	if err := c.Do(ctx, http.MethodGet, "/status", nil, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Me is the response from GET /me.
+type Me struct {
+	Auth bool   `json:"auth"` // False when the server has no API users
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// Me returns the API user the client's token belongs to.
+func (c *Client) Me(ctx context.Context) (*Me, error) {
+	var me Me
+	if err := c.Do(ctx, http.MethodGet, "/me", nil, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+// Settings returns the site info and webapp settings. The shape follows the
+// web UI's settings screen and is returned as generic JSON.
+func (c *Client) Settings(ctx context.Context) (map[string]interface{}, error) {
+	var settings map[string]interface{}
+	if err := c.Do(ctx, http.MethodGet, "/settings", nil, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ============================================================================
+// Drafts and posts
+// ============================================================================
+
+// DraftInfo is an entry in the draft list.
+type DraftInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Modified string `json:"modified"`
+}
+
+// Draft is a draft's content.
+type Draft struct {
+	ID       string `json:"id"`
+	Markdown string `json:"markdown"`
+}
+
+// Drafts lists the post drafts.
+func (c *Client) Drafts(ctx context.Context) ([]DraftInfo, error) {
+	var resp struct {
+		Drafts []DraftInfo `json:"drafts"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/drafts", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Drafts, nil
+}
+
+// Draft returns one draft.
+func (c *Client) Draft(ctx context.Context, id string) (*Draft, error) {
+	var d Draft
+	if err := c.Do(ctx, http.MethodGet, "/drafts/"+url.PathEscape(id), nil, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// SaveDraft creates or overwrites a draft and returns its ID. An empty id
+// creates a new draft.
+func (c *Client) SaveDraft(ctx context.Context, id, markdown string) (string, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/drafts", Draft{ID: id, Markdown: markdown}, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// DeleteDraft moves a draft to the trash and returns the trash item ID.
+func (c *Client) DeleteDraft(ctx context.Context, id string) (string, error) {
+	var resp struct {
+		TrashID string `json:"trash_id"`
+	}
+	if err := c.Do(ctx, http.MethodDelete, "/drafts/"+url.PathEscape(id), nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.TrashID, nil
+}
+
+// PublishRequest is the body of POST /publish.
+type PublishRequest struct {
+	Markdown      string `json:"markdown"`
+	Filename      string `json:"filename,omitempty"`
+	DraftID       string `json:"draft_id,omitempty"`   // Draft deleted after publishing
+	KeepDraft     bool   `json:"keep_draft,omitempty"` // Keep the draft instead
+	Unlisted      bool   `json:"unlisted,omitempty"`
+	FollowersOnly bool   `json:"followers_only,omitempty"`
+	As            string `json:"as,omitempty"` // Co-author to sign as
+}
+
+// Publish signs and publishes a post.
+func (c *Client) Publish(ctx context.Context, req PublishRequest) (*publish.PublishResult, error) {
+	var result publish.PublishResult
+	if err := c.Do(ctx, http.MethodPost, "/publish", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Republish replaces the content of a published post.
+func (c *Client) Republish(ctx context.Context, path, markdown string) (*publish.PublishResult, error) {
+	body := map[string]string{"path": path, "markdown": markdown}
+	var result publish.PublishResult
+	if err := c.Do(ctx, http.MethodPost, "/republish", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Post is an entry in the published post list.
+type Post struct {
+	Path           string `json:"path"`
+	Title          string `json:"title"`
+	Published      string `json:"published"`
+	CurrentVersion string `json:"current_version"`
+	Summary        string `json:"summary,omitempty"`
+	Lang           string `json:"lang,omitempty"`
+	TranslationOf  string `json:"translation_of,omitempty"`
+	Visibility     string `json:"visibility,omitempty"` // "unlisted" or "followers"; empty for public posts
+}
+
+// Posts lists published posts, newest first. A non-empty lang keeps only
+// posts in that language.
+func (c *Client) Posts(ctx context.Context, lang string) ([]Post, error) {
+	var resp struct {
+		Posts []Post `json:"posts"`
+	}
+	if err := c.Do(ctx, http.MethodGet, withQuery("/posts", url.Values{"lang": {lang}}), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Posts, nil
+}
+
+// ============================================================================
+// Feed
+// ============================================================================
+
+// FeedOptions filter the feed. Empty fields don't filter.
+type FeedOptions struct {
+	Type   string // "post" or "comment"
+	Status string // "unread" or "read"
+}
+
+// Feed is the response from GET /feed and POST /feed/refresh.
+type Feed struct {
+	Items       []feed.CachedFeedItem `json:"items"`
+	Total       int                   `json:"total"`
+	Unread      int                   `json:"unread"`
+	NewItems    int                   `json:"new_items,omitempty"` // Refresh only
+	Stale       bool                  `json:"stale"`
+	LastRefresh string                `json:"last_refresh"`
+}
+
+// FeedCounts are the feed's unread and total counts.
+type FeedCounts struct {
+	Total  int  `json:"total"`
+	Unread int  `json:"unread"`
+	Stale  bool `json:"stale"`
+}
+
+// FeedReadRequest is the body of POST /feed/read. Set one of: ID to mark an
+// item read (or unread, with Unread), All to mark everything read, or FromID
+// to mark that item and everything after it unread.
+type FeedReadRequest struct {
+	ID     string `json:"id,omitempty"`
+	Unread bool   `json:"unread,omitempty"`
+	All    bool   `json:"all,omitempty"`
+	FromID string `json:"from_id,omitempty"`
+}
+
+// Feed returns the cached feed from followed sites.
+func (c *Client) Feed(ctx context.Context, opts FeedOptions) (*Feed, error) {
+	path := withQuery("/feed", url.Values{"type": {opts.Type}, "status": {opts.Status}})
+	var f Feed
+	if err := c.Do(ctx, http.MethodGet, path, nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// RefreshFeed fetches new items from followed sites and returns the feed.
+func (c *Client) RefreshFeed(ctx context.Context) (*Feed, error) {
+	var f Feed
+	if err := c.Do(ctx, http.MethodPost, "/feed/refresh", nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// FeedCounts returns the feed's counts.
+func (c *Client) FeedCounts(ctx context.Context) (*FeedCounts, error) {
+	var counts FeedCounts
+	if err := c.Do(ctx, http.MethodGet, "/feed/counts", nil, &counts); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// MarkFeedRead updates read state for feed items.
+func (c *Client) MarkFeedRead(ctx context.Context, req FeedReadRequest) error {
+	return c.Do(ctx, http.MethodPost, "/feed/read", req, nil)
+}
+
+// ============================================================================
+// Blessings
+// ============================================================================
+
+// BlessingRequests lists pending blessing requests on this site's posts.
+func (c *Client) BlessingRequests(ctx context.Context) ([]blessing.IncomingRequest, error) {
+	var resp struct {
+		Requests []blessing.IncomingRequest `json:"requests"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/blessing/requests", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Requests, nil
+}
+
+// GrantBlessing blesses a comment. commentVersion may be empty; the server
+// then looks it up from the discovery service.
+func (c *Client) GrantBlessing(ctx context.Context, commentURL, commentVersion, inReplyTo string) (*blessing.GrantResult, error) {
+	body := map[string]string{"comment_version": commentVersion, "comment_url": commentURL, "in_reply_to": inReplyTo}
+	var result blessing.GrantResult
+	if err := c.Do(ctx, http.MethodPost, "/blessing/grant", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DenyBlessing denies a comment's blessing request.
+func (c *Client) DenyBlessing(ctx context.Context, commentURL, inReplyTo string) (*blessing.DenyResult, error) {
+	body := map[string]string{"comment_url": commentURL, "in_reply_to": inReplyTo}
+	var result blessing.DenyResult
+	if err := c.Do(ctx, http.MethodPost, "/blessing/deny", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ============================================================================
+// Following
+// ============================================================================
+
+// Following lists followed sites.
+func (c *Client) Following(ctx context.Context) ([]following.FollowingEntry, error) {
+	var resp struct {
+		Following []following.FollowingEntry `json:"following"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/following", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Following, nil
+}
+
+// Follow follows the site at authorURL, blessing its pending comments.
+func (c *Client) Follow(ctx context.Context, authorURL string) (*following.FollowResult, error) {
+	var resp struct {
+		Data following.FollowResult `json:"data"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/following", map[string]string{"url": authorURL}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Unfollow stops following the site at authorURL, denying its pending
+// comments.
+func (c *Client) Unfollow(ctx context.Context, authorURL string) (*following.UnfollowResult, error) {
+	var resp struct {
+		Data following.UnfollowResult `json:"data"`
+	}
+	if err := c.Do(ctx, http.MethodDelete, "/following", map[string]string{"url": authorURL}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ============================================================================
+// Notifications
+// ============================================================================
+
+// NotificationPage is a page of notifications, newest first.
+type NotificationPage struct {
+	Notifications []notification.StateEntry `json:"notifications"`
+	Total         int                       `json:"total"`
+	Offset        int                       `json:"offset"`
+	Limit         int                       `json:"limit"`
+}
+
+// Notifications returns a page of notifications. A zero limit uses the
+// server default.
+func (c *Client) Notifications(ctx context.Context, offset, limit int, includeRead bool) (*NotificationPage, error) {
+	params := url.Values{}
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	if includeRead {
+		params.Set("include_read", "true")
+	}
+	var page NotificationPage
+	if err := c.Do(ctx, http.MethodGet, withQuery("/notifications", params), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UnreadNotifications returns the unread notification count.
+func (c *Client) UnreadNotifications(ctx context.Context) (int, error) {
+	var resp struct {
+		Unread int `json:"unread"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/notifications/count", nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Unread, nil
+}
+
+// MarkNotificationsRead marks the given notifications read, or all of them
+// when ids is empty. Returns the number marked.
+func (c *Client) MarkNotificationsRead(ctx context.Context, ids []string) (int, error) {
+	body := map[string]interface{}{"ids": ids}
+	if len(ids) == 0 {
+		body = map[string]interface{}{"all": true}
+	}
+	var resp struct {
+		Marked int `json:"marked"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/notifications/read", body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Marked, nil
+}
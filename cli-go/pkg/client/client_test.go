@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Drafts(t *testing.T) {
+	drafts := map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/drafts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			var list []DraftInfo
+			for id := range drafts {
+				list = append(list, DraftInfo{ID: id, Name: id + ".md"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"drafts": list})
+		case http.MethodPost:
+			var d Draft
+			json.NewDecoder(r.Body).Decode(&d)
+			if d.ID == "" {
+				d.ID = "draft-1"
+			}
+			drafts[d.ID] = d.Markdown
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "id": d.ID})
+		}
+	})
+	mux.HandleFunc("/api/v1/drafts/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/api/v1/drafts/"):]
+		md, ok := drafts[id]
+		if !ok {
+			http.Error(w, "Draft not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(Draft{ID: id, Markdown: md})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL + "/")
+	ctx := context.Background()
+
+	id, err := c.SaveDraft(ctx, "", "# Hello")
+	if err != nil || id != "draft-1" {
+		t.Fatalf("SaveDraft = %q, %v", id, err)
+	}
+	list, err := c.Drafts(ctx)
+	if err != nil || len(list) != 1 || list[0].ID != "draft-1" {
+		t.Fatalf("Drafts = %+v, %v", list, err)
+	}
+	d, err := c.Draft(ctx, "draft-1")
+	if err != nil || d.Markdown != "# Hello" {
+		t.Fatalf("Draft = %+v, %v", d, err)
+	}
+
+	_, err = c.Draft(ctx, "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Draft not found" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing draft error = %v", err)
+	}
+}
+
+func TestClient_Query(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}, "unread": 2})
+	}))
+	defer srv.Close()
+
+	f, err := New(srv.URL).Feed(context.Background(), FeedOptions{Status: "unread"})
+	if err != nil || f.Unread != 2 {
+		t.Fatalf("Feed = %+v, %v", f, err)
+	}
+	if got != "/api/v1/feed?status=unread" {
+		t.Errorf("request URI = %q", got)
+	}
+}
+
+func TestClient_Token(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Sign in with an API token", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(Me{Auth: true, Name: "alex", Role: "admin"})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	if _, err := c.Me(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("without a token: %v, want ErrUnauthorized", err)
+	}
+	c.Token = "secret"
+	me, err := c.Me(context.Background())
+	if err != nil || me.Name != "alex" || me.Role != "admin" {
+		t.Errorf("Me = %+v, %v", me, err)
+	}
+}
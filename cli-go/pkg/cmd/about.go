@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/dnskey"
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
 )
 
@@ -87,6 +88,12 @@ func handleAbout(args []string) {
 		domain = extractDomain(baseURL)
 	}
 
+	// Key published in DNS, to guard against a host swapping .well-known/polis
+	var keyCheck dnskey.Result
+	if domain != "" {
+		keyCheck = dnskey.Check(domain, wk.PublicKey)
+	}
+
 	if jsonOutput {
 		siteData := map[string]interface{}{
 			"author":          wk.Author,
@@ -103,6 +110,13 @@ func handleAbout(args []string) {
 		if wk.Email != "" {
 			siteData["email"] = wk.Email
 		}
+		if domain != "" {
+			siteData["dns_key"] = map[string]interface{}{
+				"name":   dnskey.Name(domain),
+				"record": dnskey.Record(wk.PublicKey),
+				"status": keyCheck.Status,
+			}
+		}
 		outputJSON(map[string]interface{}{
 			"status":  "success",
 			"command": "about",
@@ -150,6 +164,17 @@ func handleAbout(args []string) {
 
 		fmt.Println("=== Public Key ===")
 		fmt.Printf("  %s\n", wk.PublicKey)
+		if domain != "" {
+			switch keyCheck.Status {
+			case dnskey.StatusMatch:
+				fmt.Printf("  [✓] Published in DNS at %s\n", dnskey.Name(domain))
+			case dnskey.StatusMismatch:
+				fmt.Printf("  [x] DNS at %s publishes a DIFFERENT key\n", dnskey.Name(domain))
+			default:
+				fmt.Println("  [i] Not in DNS. Publishing it lets readers detect a swapped key:")
+				fmt.Printf("      %s  TXT  \"%s\"\n", dnskey.Name(domain), dnskey.Record(wk.PublicKey))
+			}
+		}
 		fmt.Println()
 
 		fmt.Println("=== Discovery Service ===")
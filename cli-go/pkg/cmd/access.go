@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/access"
+)
+
+func handleAccess(args []string) {
+	if len(args) < 1 {
+		accessList()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		accessList()
+	case "add":
+		accessAdd(args[1:])
+	case "role":
+		accessRole(args[1:])
+	case "token":
+		accessToken(args[1:])
+	case "remove":
+		accessRemove(args[1:])
+	default:
+		exitError("Unknown access subcommand. Use: polis access [list|add|role|token|remove]")
+	}
+}
+
+// accessDir returns the site directory, which must be a polis site.
+func accessDir() string {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	return dir
+}
+
+func accessAdd(args []string) {
+	fs := flag.NewFlagSet("access add", flag.ExitOnError)
+	roleName := fs.String("role", string(access.Reader), "What the user may do")
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") && len(positional) < 1 {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	fs.Parse(args)
+	if len(positional) < 1 {
+		exitError("Usage: polis access add <name> [--role reader|moderator|editor|admin]")
+	}
+	role, err := access.ParseRole(*roleName)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	dir := accessDir()
+	first := !access.Enabled(dir)
+	token, err := access.Add(dir, positional[0], role)
+	if errors.Is(err, access.ErrLastAdmin) {
+		exitError("The first user must be an admin: polis access add <name> --role admin")
+	}
+	if err != nil {
+		exitError("Failed to add user: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "access",
+			"data":    map[string]interface{}{"name": positional[0], "role": role, "token": token},
+		})
+		return
+	}
+	fmt.Printf("[✓] Added %s (%s)\n", positional[0], role)
+	fmt.Printf("[i] Token (shown only once): %s\n", token)
+	if first {
+		fmt.Println("[i] The server now asks every API request for a user's token; no restart needed.")
+	}
+}
+
+func accessRole(args []string) {
+	if len(args) != 2 {
+		exitError("Usage: polis access role <name> <reader|moderator|editor|admin>")
+	}
+	role, err := access.ParseRole(args[1])
+	if err != nil {
+		exitError("%v", err)
+	}
+	if err := access.SetRole(accessDir(), args[0], role); err != nil {
+		exitError("Failed to change role: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "access",
+			"data":    map[string]interface{}{"name": args[0], "role": role},
+		})
+		return
+	}
+	fmt.Printf("[✓] %s is now %s\n", args[0], role)
+}
+
+// accessToken replaces a user's token, for one that was lost or leaked.
+func accessToken(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis access token <name>")
+	}
+	token, err := access.Rotate(accessDir(), args[0])
+	if err != nil {
+		exitError("Failed to replace token: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "access",
+			"data":    map[string]interface{}{"name": args[0], "token": token},
+		})
+		return
+	}
+	fmt.Printf("[✓] New token for %s (the old one no longer works)\n", args[0])
+	fmt.Printf("[i] Token (shown only once): %s\n", token)
+}
+
+func accessRemove(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis access remove <name>")
+	}
+	dir := accessDir()
+	if err := access.Remove(dir, args[0]); err != nil {
+		exitError("Failed to remove user: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "access",
+			"data":    map[string]interface{}{"removed": args[0]},
+		})
+		return
+	}
+	fmt.Printf("[✓] Removed %s\n", args[0])
+	if !access.Enabled(dir) {
+		fmt.Println("[i] No users left: the server API is open to whoever can reach it again.")
+	}
+}
+
+func accessList() {
+	users, err := access.Load(accessDir())
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		type entry struct {
+			Name      string      `json:"name"`
+			Role      access.Role `json:"role"`
+			CreatedAt string      `json:"created_at"`
+		}
+		entries := []entry{}
+		for _, u := range users {
+			entries = append(entries, entry{u.Name, u.Role, u.CreatedAt})
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "access",
+			"data":    map[string]interface{}{"users": entries},
+		})
+		return
+	}
+	if len(users) == 0 {
+		fmt.Println("[i] No API users; the server API is open to whoever can reach it")
+		fmt.Println("[i] Add an admin first: polis access add <name> --role admin")
+		return
+	}
+	for _, u := range users {
+		fmt.Printf("  %-16s %-10s since %s\n", u.Name, u.Role, u.CreatedAt)
+	}
+}
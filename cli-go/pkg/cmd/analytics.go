@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/analytics"
+)
+
+func handleAnalytics(args []string) {
+	fs := flag.NewFlagSet("analytics", flag.ExitOnError)
+	days := fs.Int("days", 30, "Number of recent days to count (0 for all time)")
+	top := fs.Int("top", 10, "Number of posts to list (0 for all)")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	report, err := analytics.Summarize(dir, *days, time.Now())
+	if err != nil {
+		exitError("Failed to read analytics: %v", err)
+	}
+	enabled := loadConfig().Bool("analytics.enabled")
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "analytics",
+			"data": map[string]interface{}{
+				"enabled": enabled,
+				"report":  report,
+			},
+		})
+		return
+	}
+
+	if !enabled {
+		fmt.Println("Analytics is off (polis config set analytics.enabled true to turn it on).")
+	}
+	period := "all time"
+	if report.Since != "" {
+		period = "since " + report.Since
+	}
+	fmt.Printf("Views (%s): %d\n", period, report.Views)
+	if len(report.Posts) == 0 {
+		return
+	}
+
+	posts := report.Posts
+	if *top > 0 && len(posts) > *top {
+		posts = posts[:*top]
+	}
+	fmt.Println()
+	for _, p := range posts {
+		title := p.Title
+		if title == "" {
+			title = p.Path
+		}
+		fmt.Printf("%7d  %s\n", p.Views, title)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+func handleAuthor(args []string) {
+	fs := flag.NewFlagSet("author", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Skip fetching the author's .well-known/polis")
+	limit := fs.Int("limit", 10, "Number of recent feed items to show")
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis author <domain> [--offline] [--limit N]")
+	}
+	fs.Parse(args[1:])
+
+	domain := strings.ToLower(args[0])
+	if strings.Contains(domain, "://") {
+		domain = discovery.ExtractDomainFromURL(domain)
+	}
+	if !author.ValidDomain(domain) {
+		exitError("Invalid domain: %s", args[0])
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+
+	opts := author.ProfileOptions{Limit: *limit}
+	if !*offline {
+		opts.Client = remote.NewClient()
+	}
+	p := author.LoadProfile(dir, discoveryDomain, domain, opts)
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "author",
+			"data":    p,
+		})
+		return
+	}
+
+	title := p.SiteTitle
+	if title == "" {
+		title = p.Domain
+	}
+	fmt.Printf("%s\n", title)
+	if p.Author != "" {
+		fmt.Printf("  Author:     %s\n", p.Author)
+	}
+	fmt.Printf("  URL:        %s\n", p.URL)
+	if len(p.PublicKey) > 50 {
+		fmt.Printf("  Public key: %s\n", p.PublicKey[:50]+"...")
+	} else if p.PublicKey != "" {
+		fmt.Printf("  Public key: %s\n", p.PublicKey)
+	}
+	if p.WellKnownError != "" {
+		fmt.Printf("[!] Could not fetch .well-known/polis: %s\n", p.WellKnownError)
+	}
+
+	fmt.Println()
+	switch {
+	case p.Follow.Following && p.Follow.FollowedBy:
+		fmt.Println("[✓] You follow each other")
+	case p.Follow.Following:
+		fmt.Println("[✓] You follow this author")
+	case p.Follow.FollowedBy:
+		fmt.Println("[i] Follows you (you don't follow back)")
+	default:
+		fmt.Printf("[i] Not following. Use 'polis follow %s' to follow.\n", p.URL)
+	}
+	fmt.Printf("[i] Their comments on your posts: %d   Your comments on theirs: %d   Trust score: %d\n",
+		len(p.TheirComments), len(p.MyComments), p.TrustScore)
+	if p.LastActivity != "" {
+		fmt.Printf("[i] Last activity: %s\n", p.LastActivity)
+	}
+
+	if len(p.Items) == 0 {
+		fmt.Println("\nNo items from this author in your feed cache.")
+		return
+	}
+	fmt.Printf("\nRecent items (%d posts, %d comments, %d unread):\n", p.Feed.Posts, p.Feed.Comments, p.Feed.Unread)
+	for _, item := range p.Items {
+		marker := " "
+		if item.ReadAt == "" {
+			marker = "*"
+		}
+		date := item.Published
+		if len(date) >= 10 {
+			date = date[:10]
+		}
+		fmt.Printf("  %s %s  %-7s  %s\n", marker, date, item.Type, item.Title)
+	}
+}
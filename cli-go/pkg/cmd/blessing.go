@@ -3,16 +3,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/blessing"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 )
 
 func handleBlessing(args []string) {
 	if len(args) < 1 {
 		printBlessingUsage()
-		os.Exit(1)
+		exit(1)
 	}
 
 	subcommand := args[0]
@@ -29,12 +31,19 @@ func handleBlessing(args []string) {
 		handleBlessingBeseech(subArgs)
 	case "sync":
 		handleBlessingSync(subArgs)
+	case "feature", "unfeature":
+		handleBlessingFeature(subcommand == "feature", subArgs)
+	case "sort":
+		handleBlessingSort(subArgs)
+	case "--all-from":
+		// polis bless --all-from <domain>
+		handleBlessingBulk(blessing.ActionGrant, args)
 	case "help", "--help", "-h":
 		printBlessingUsage()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown blessing subcommand: %s\n", subcommand)
 		printBlessingUsage()
-		os.Exit(1)
+		exit(1)
 	}
 }
 
@@ -44,16 +53,25 @@ func printBlessingUsage() {
 Subcommands:
   requests              List pending blessing requests on your posts
   grant <version>       Grant a blessing to a comment
+  grant --all-from <d>  Grant every pending request from author domain <d>
   deny <version>        Deny a blessing request
+  deny --all-from <d>   Deny every pending request from author domain <d>
   beseech <version>     Re-request blessing by content hash
   sync                  Sync auto-blessed comments from discovery service
+  feature <url>         Mark a blessed comment as featured
+  unfeature <url>       Remove the featured mark from a blessed comment
+  sort <post> <order>   Set a post's comment order (chronological, newest-first, featured-first)
 
 Examples:
   polis blessing requests
   polis blessing grant sha256:abc123...
   polis blessing deny sha256:abc123...
+  polis blessing grant --all-from bob.polis.pub
+  polis bless --all-from bob.polis.pub
   polis blessing beseech sha256:abc123...
   polis blessing sync
+  polis blessing feature https://bob.polis.pub/comments/20260106/reply.md
+  polis blessing sort posts/20260106/hello.md featured-first
 `)
 }
 
@@ -112,6 +130,10 @@ func handleBlessingGrant(args []string) {
 	if len(args) < 1 {
 		exitError("Usage: polis blessing grant <comment-version>")
 	}
+	if args[0] == "--all-from" {
+		handleBlessingBulk(blessing.ActionGrant, args)
+		return
+	}
 
 	commentVersion := args[0]
 	dir := getDataDir()
@@ -155,6 +177,10 @@ func handleBlessingDeny(args []string) {
 	if len(args) < 1 {
 		exitError("Usage: polis blessing deny <comment-version>")
 	}
+	if args[0] == "--all-from" {
+		handleBlessingBulk(blessing.ActionDeny, args)
+		return
+	}
 
 	commentVersion := args[0]
 	dir := getDataDir()
@@ -216,6 +242,76 @@ func handleBlessingDeny(args []string) {
 	}
 }
 
+// handleBlessingBulk grants or denies every pending request from one author.
+// args is "--all-from <domain>".
+func handleBlessingBulk(action string, args []string) {
+	if len(args) < 2 || args[1] == "" {
+		exitError("Usage: polis blessing %s --all-from <author-domain>", action)
+	}
+	author := args[1]
+	dir := getDataDir()
+
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+
+	// Load discovery config from env
+	discoveryURL := os.Getenv("DISCOVERY_SERVICE_URL")
+	discoveryKey := os.Getenv("DISCOVERY_SERVICE_KEY")
+	if discoveryURL == "" {
+		discoveryURL = "https://ltfpezriiaqvjupxbttw.supabase.co/functions/v1"
+	}
+
+	baseURL := os.Getenv("POLIS_BASE_URL")
+	if baseURL == "" {
+		exitError("POLIS_BASE_URL not set")
+	}
+	domain := polisurl.ExtractDomain(baseURL)
+
+	client := discovery.NewAuthenticatedClient(discoveryURL, discoveryKey, domain, privKey)
+
+	requests, err := blessing.FetchPendingRequests(client, domain)
+	if err != nil {
+		exitError("Failed to fetch pending requests: %v", err)
+	}
+
+	var actions []blessing.BulkAction
+	for _, req := range blessing.FilterByAuthor(requests, author) {
+		version := req.CommentVersion
+		if version == "" {
+			version = req.CommentURL
+		}
+		actions = append(actions, blessing.BulkAction{CommentVersion: version, Action: action})
+	}
+
+	result := blessing.Bulk(dir, requests, actions, client, nil, privKey)
+
+	if jsonOutput {
+		outputJSON(result)
+		return
+	}
+	if len(actions) == 0 {
+		fmt.Printf("No pending blessing requests from %s.\n", author)
+		return
+	}
+	for _, r := range result.Results {
+		if r.Success {
+			fmt.Printf("[✓] %s: %s\n", r.Action, r.CommentURL)
+		} else {
+			fmt.Printf("[x] %s: %s: %s\n", r.Action, r.CommentURL, r.Error)
+		}
+	}
+	fmt.Printf("Granted %d, denied %d, failed %d.\n", result.Granted, result.Denied, result.Failed)
+	if result.Failed > 0 {
+		exit(1)
+	}
+}
+
 func handleBlessingBeseech(args []string) {
 	if len(args) < 1 {
 		exitError("Usage: polis blessing beseech <comment-version>")
@@ -324,3 +420,74 @@ func handleBlessingSync(args []string) {
 		}
 	}
 }
+
+func handleBlessingFeature(featured bool, args []string) {
+	action := "unfeature"
+	if featured {
+		action = "feature"
+	}
+	if len(args) < 1 {
+		exitError("Usage: polis blessing %s <comment-url>", action)
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	commentURL := polisurl.NormalizeToMD(args[0])
+	if err := metadata.SetCommentFeatured(dir, commentURL, featured); err != nil {
+		exitError("Failed to %s comment: %v", action, err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "blessing-" + action,
+			"data": map[string]interface{}{
+				"comment_url": commentURL,
+				"featured":    featured,
+			},
+		})
+		return
+	}
+	if featured {
+		fmt.Printf("[✓] Featured: %s\n", commentURL)
+	} else {
+		fmt.Printf("[✓] No longer featured: %s\n", commentURL)
+	}
+	fmt.Println("[i] Run 'polis render' to update the post page.")
+}
+
+func handleBlessingSort(args []string) {
+	if len(args) < 2 {
+		exitError("Usage: polis blessing sort <post> <%s>", strings.Join(metadata.SortOrders, "|"))
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	postPath, order := args[0], args[1]
+	if order == metadata.SortChronological {
+		order = "" // The default; don't store it
+	}
+	if err := metadata.SetPostCommentSort(dir, postPath, order); err != nil {
+		exitError("Failed to set comment order: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "blessing-sort",
+			"data": map[string]interface{}{
+				"post": postPath,
+				"sort": args[1],
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Comments on %s are now shown %s\n", postPath, args[1])
+	fmt.Println("[i] Run 'polis render' to update the post page.")
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/bookmark"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+func handleBookmark(args []string) {
+	if len(args) < 1 {
+		printBookmarkUsage()
+		exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		handleBookmarkList(args[1:])
+	case "show":
+		handleBookmarkShow(args[1:])
+	case "remove", "rm":
+		handleBookmarkRemove(args[1:])
+	case "help", "--help", "-h":
+		printBookmarkUsage()
+	default:
+		if strings.HasPrefix(args[0], "-") {
+			printBookmarkUsage()
+			exit(1)
+		}
+		handleBookmarkAdd(args)
+	}
+}
+
+func printBookmarkUsage() {
+	fmt.Print(`Usage: polis bookmark <url> [--note <text>] [--no-snapshot]
+       polis bookmark <subcommand> [args]
+
+Subcommands:
+  list                  List bookmarks, newest first
+  show <id|url>         Print a bookmark's saved snapshot
+  remove <id|url>       Remove a bookmark and its snapshot
+
+Examples:
+  polis bookmark https://alice.polis.pub/posts/20260101/hello.md
+  polis bookmark https://alice.polis.pub/posts/20260101/hello.md --note "reply to this"
+  polis bookmark list
+  polis bookmark show 1a2b3c4d5e6f7a8b
+`)
+}
+
+func handleBookmarkAdd(args []string) {
+	fs := flag.NewFlagSet("bookmark", flag.ExitOnError)
+	note := fs.String("note", "", "Note to keep with the bookmark")
+	noSnapshot := fs.Bool("no-snapshot", false, "Save the bookmark without fetching its content")
+	fs.Parse(args[1:])
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+
+	opts := bookmark.Options{URL: args[0]}
+	if item := bookmark.FindFeedItem(dir, discoveryDomain, args[0]); item != nil {
+		opts = bookmark.FromFeedItem(*item)
+	}
+	opts.Note = *note
+	if !*noSnapshot {
+		opts.Client = remote.NewClient()
+	}
+
+	b, added, snapErr, err := bookmark.Add(dir, opts)
+	if err != nil {
+		exitError("Failed to bookmark: %v", err)
+	}
+
+	if jsonOutput {
+		data := map[string]interface{}{
+			"bookmark": b,
+			"added":    added,
+		}
+		if snapErr != nil {
+			data["snapshot_error"] = snapErr.Error()
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bookmark",
+			"data":    data,
+		})
+		return
+	}
+
+	if added {
+		fmt.Printf("[✓] Bookmarked: %s\n", b.Title)
+	} else {
+		fmt.Printf("[✓] Updated bookmark: %s\n", b.Title)
+	}
+	fmt.Printf("[i] ID: %s\n", b.ID)
+	if snapErr != nil {
+		fmt.Printf("[!] Could not save a snapshot: %v\n", snapErr)
+	} else if b.SnapshotAt != "" {
+		fmt.Printf("[i] Snapshot saved from %s\n", b.SnapshotURL)
+	}
+}
+
+func handleBookmarkList(args []string) {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	f, err := bookmark.Load(bookmark.DefaultPath(dir))
+	if err != nil {
+		exitError("Failed to load bookmarks: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bookmark",
+			"data": map[string]interface{}{
+				"count":     f.Count(),
+				"bookmarks": f.Bookmarks,
+			},
+		})
+		return
+	}
+
+	if f.Count() == 0 {
+		fmt.Println("No bookmarks. Use 'polis bookmark <url>' to save a post for later.")
+		return
+	}
+	fmt.Printf("Bookmarks (%d):\n", f.Count())
+	for _, b := range f.Bookmarks {
+		marker := " "
+		if b.SnapshotAt == "" {
+			marker = "!"
+		}
+		date := b.AddedAt
+		if len(date) >= 10 {
+			date = date[:10]
+		}
+		fmt.Printf("  %s %s  %s  %s\n", marker, b.ID, date, b.Title)
+		fmt.Printf("      %s\n", b.URL)
+		if b.Note != "" {
+			fmt.Printf("      Note: %s\n", b.Note)
+		}
+	}
+}
+
+func handleBookmarkShow(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis bookmark show <id|url>")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	f, err := bookmark.Load(bookmark.DefaultPath(dir))
+	if err != nil {
+		exitError("Failed to load bookmarks: %v", err)
+	}
+	b := f.Get(args[0])
+	if b == nil {
+		exitError("No bookmark matches: %s", args[0])
+	}
+	content, err := bookmark.ReadSnapshot(dir, b.ID)
+	if err != nil {
+		exitError("Failed to read snapshot: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bookmark",
+			"data": map[string]interface{}{
+				"bookmark": b,
+				"content":  content,
+			},
+		})
+		return
+	}
+
+	if content == "" {
+		fmt.Printf("[!] No snapshot saved for %s. Run 'polis bookmark %s' to fetch one.\n", b.ID, b.URL)
+		return
+	}
+	fmt.Print(content)
+	if !strings.HasSuffix(content, "\n") {
+		fmt.Println()
+	}
+}
+
+func handleBookmarkRemove(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis bookmark remove <id|url>")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	removed, err := bookmark.Remove(dir, args[0])
+	if err != nil {
+		exitError("Failed to remove bookmark: %v", err)
+	}
+	if removed == nil {
+		exitError("No bookmark matches: %s", args[0])
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bookmark",
+			"data":    map[string]interface{}{"removed": removed},
+		})
+		return
+	}
+	fmt.Printf("[✓] Removed bookmark: %s\n", removed.Title)
+}
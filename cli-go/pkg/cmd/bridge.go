@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/nostr"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+func handleBridge(args []string) {
+	if len(args) < 2 || args[0] != "nostr" {
+		exitError("Usage: polis bridge nostr [enable|disable|status|sync|publish]")
+	}
+
+	switch args[1] {
+	case "enable":
+		bridgeNostrEnable(args[2:])
+	case "disable":
+		bridgeNostrDisable()
+	case "status":
+		bridgeNostrStatus()
+	case "sync":
+		bridgeNostrSync(args[2:])
+	case "publish":
+		bridgeNostrPublish(args[2:])
+	default:
+		exitError("Unknown bridge subcommand. Use: polis bridge nostr [enable|disable|status|sync|publish]")
+	}
+}
+
+// nostrSetup checks the site and loads the derived nostr key and relays.
+func nostrSetup() (dir string, key *nostr.Key, relays []string) {
+	dir = getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	key, err = nostr.DeriveKey(privKey)
+	if err != nil {
+		exitError("Failed to derive nostr key: %v", err)
+	}
+	return dir, key, nostr.ParseRelays(loadConfig().Get("nostr.relays"))
+}
+
+func bridgeNostrEnable(args []string) {
+	fs := flag.NewFlagSet("bridge nostr enable", flag.ExitOnError)
+	relays := fs.String("relays", "", "Comma-separated relays to mirror to")
+	fs.Parse(args)
+
+	dir, key, _ := nostrSetup()
+	if *relays != "" {
+		for _, r := range nostr.ParseRelays(*relays) {
+			if !strings.HasPrefix(r, "wss://") && !strings.HasPrefix(r, "ws://") {
+				exitError("Not a relay URL: %s", r)
+			}
+		}
+		if _, err := config.Set(dir, "nostr.relays", strings.Join(nostr.ParseRelays(*relays), ",")); err != nil {
+			exitError("Failed to save relays: %v", err)
+		}
+	}
+	if _, err := config.Set(dir, "nostr.enabled", "true"); err != nil {
+		exitError("Failed to enable the nostr bridge: %v", err)
+	}
+	relayList := nostr.ParseRelays(loadConfig().Get("nostr.relays"))
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bridge",
+			"data": map[string]interface{}{
+				"enabled": true,
+				"npub":    key.Npub(),
+				"pubkey":  key.PubKey,
+				"relays":  relayList,
+			},
+		})
+		return
+	}
+	fmt.Println("[✓] Nostr bridge enabled (experimental)")
+	fmt.Printf("    Identity: %s\n", key.Npub())
+	fmt.Printf("    Relays:   %s\n", strings.Join(relayList, ", "))
+	fmt.Println("[i] New public posts are mirrored when published. Mirror existing posts with: polis bridge nostr sync --all")
+}
+
+func bridgeNostrDisable() {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if _, err := config.Set(dir, "nostr.enabled", ""); err != nil {
+		exitError("Failed to disable the nostr bridge: %v", err)
+	}
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bridge",
+			"data":    map[string]interface{}{"enabled": false},
+		})
+		return
+	}
+	fmt.Println("[✓] Nostr bridge disabled. Events already on relays stay there.")
+}
+
+func bridgeNostrStatus() {
+	dir, key, relays := nostrSetup()
+	enabled := loadConfig().Bool("nostr.enabled")
+	synd, err := metadata.LoadSyndication(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+	mirrored := 0
+	for path := range synd.Posts {
+		if _, ok := synd.Link(path, nostr.Service); ok {
+			mirrored++
+		}
+	}
+	stale, err := nostr.Stale(dir, false)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bridge",
+			"data": map[string]interface{}{
+				"enabled":  enabled,
+				"npub":     key.Npub(),
+				"pubkey":   key.PubKey,
+				"relays":   relays,
+				"mirrored": mirrored,
+				"stale":    len(stale),
+			},
+		})
+		return
+	}
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	fmt.Printf("Nostr bridge: %s (experimental)\n", state)
+	fmt.Printf("Identity:     %s\n", key.Npub())
+	fmt.Printf("Relays:       %s\n", strings.Join(relays, ", "))
+	fmt.Printf("Mirrored:     %d post(s), %d out of date\n", mirrored, len(stale))
+}
+
+// bridgeNostrSync re-mirrors posts republished since they were mirrored
+// and, with --all, mirrors public posts never mirrored.
+func bridgeNostrSync(args []string) {
+	fs := flag.NewFlagSet("bridge nostr sync", flag.ExitOnError)
+	all := fs.Bool("all", false, "Also mirror public posts not mirrored yet")
+	fs.Parse(args)
+
+	dir, key, relays := nostrSetup()
+	stale, err := nostr.Stale(dir, *all)
+	if err != nil {
+		exitError("%v", err)
+	}
+	sort.Strings(stale)
+
+	var mirrored []string
+	failed := make(map[string]string)
+	for _, path := range stale {
+		if _, err := mirrorPost(dir, key, relays, path); err != nil {
+			if !errors.Is(err, nostr.ErrNotPublic) {
+				failed[path] = err.Error()
+			}
+			continue
+		}
+		mirrored = append(mirrored, path)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bridge",
+			"data": map[string]interface{}{
+				"mirrored": mirrored,
+				"failed":   failed,
+			},
+		})
+		return
+	}
+	if len(mirrored) == 0 && len(failed) == 0 {
+		fmt.Println("[i] Nostr mirrors are up to date")
+		return
+	}
+	fmt.Printf("[✓] Mirrored %d post(s) to nostr\n", len(mirrored))
+	for path, msg := range failed {
+		fmt.Fprintf(os.Stderr, "[!] %s: %s\n", path, msg)
+	}
+}
+
+func bridgeNostrPublish(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis bridge nostr publish <posts/YYYYMMDD/post.md>")
+	}
+	dir, key, relays := nostrSetup()
+
+	postPath := fsutil.SiteRelPath(dir, args[0])
+
+	res, err := mirrorPost(dir, key, relays, postPath)
+	if err != nil && (res == nil || res.Link == nil) {
+		if errors.Is(err, os.ErrNotExist) {
+			exitError("No published post at %s", postPath)
+		}
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "bridge",
+			"data": map[string]interface{}{
+				"path":     postPath,
+				"url":      res.Link.URL,
+				"id":       res.Link.ID,
+				"accepted": res.Accepted,
+				"failed":   res.Failed,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Mirrored to nostr: %s\n", res.Link.URL)
+	for relay, msg := range res.Failed {
+		fmt.Fprintf(os.Stderr, "[!] %s: %s\n", relay, msg)
+	}
+}
+
+// mirrorPost mirrors one post, reporting relays that refused it.
+func mirrorPost(dir string, key *nostr.Key, relays []string, postPath string) (*nostr.Result, error) {
+	res, err := nostr.Mirror(dir, siteBaseURL(dir), relays, key, postPath)
+	if err != nil && res != nil && len(res.Failed) > 0 && len(res.Accepted) == 0 {
+		var msgs []string
+		for relay, msg := range res.Failed {
+			msgs = append(msgs, relay+": "+msg)
+		}
+		sort.Strings(msgs)
+		return res, fmt.Errorf("%v (%s)", err, strings.Join(msgs, "; "))
+	}
+	return res, err
+}
+
+// mirrorPublished mirrors a published post to nostr when the bridge is
+// enabled: new public posts, and republished posts mirrored before.
+// Failures are reported but don't fail the publish.
+func mirrorPublished(dir string, result *publish.PublishResult, republished bool) {
+	cfg := loadConfig()
+	if !cfg.Bool("nostr.enabled") || !result.Crosspostable() {
+		return
+	}
+	if republished && !nostr.Mirrored(dir, result.Path) {
+		return
+	}
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		return
+	}
+	key, err := nostr.DeriveKey(privKey)
+	if err != nil {
+		return
+	}
+	res, err := mirrorPost(dir, key, nostr.ParseRelays(cfg.Get("nostr.relays")), result.Path)
+	if jsonOutput {
+		return
+	}
+	if res != nil && res.Link != nil {
+		fmt.Printf("[✓] Mirrored to nostr (%d relay(s))\n", len(res.Accepted))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Nostr mirror failed: %v (retry with: polis bridge nostr publish %s)\n", err, result.Path)
+	}
+}
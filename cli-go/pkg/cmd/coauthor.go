@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleCoauthor(args []string) {
+	if len(args) < 1 {
+		coauthorList()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		coauthorList()
+	case "init":
+		coauthorInit(args[1:])
+	case "add":
+		coauthorAdd(args[1:])
+	case "remove":
+		coauthorRemove(args[1:])
+	default:
+		exitError("Unknown coauthor subcommand. Use: polis coauthor [list|init|add|remove]")
+	}
+}
+
+// coauthorInit generates a co-author's key on their own machine, to be
+// listed by whoever holds the site key.
+func coauthorInit(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis coauthor init <name>")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	pub, err := coauthor.Generate(dir, args[0])
+	if err != nil {
+		exitError("Failed to generate author key: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "coauthor",
+			"data":    map[string]interface{}{"name": args[0], "public_key": pub},
+		})
+		return
+	}
+	fmt.Printf("[✓] Author key for %s in %s\n", args[0], coauthor.Dir)
+	fmt.Println()
+	fmt.Println("[i] On the machine with the site key, run:")
+	fmt.Printf("    polis coauthor add %s \"%s\"\n", args[0], pub)
+	fmt.Printf("[i] Then sign as %s with --as %s, or: polis config set sign_as %s\n", args[0], args[0], args[0])
+}
+
+// coauthorAdd lists a co-author in .well-known/polis, generating their key
+// here unless one is given.
+func coauthorAdd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		exitError("Usage: polis coauthor add <name> [public key | key file]")
+	}
+	name := args[0]
+
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if !holdsSiteKey(dir) {
+		exitError("Only the machine with the site key can add authors")
+	}
+
+	// Names stay with their listing after removal, so what a removed author
+	// signed still verifies
+	if existing := coauthor.Find(wk.Authors, name); existing != nil {
+		if existing.Removed != "" {
+			exitError("%s was removed on %s; add them back under another name", name, existing.Removed)
+		}
+		exitError("%s is already an author", name)
+	}
+
+	var pub string
+	generated := false
+	if len(args) == 2 {
+		pub = args[1]
+		if data, err := os.ReadFile(pub); err == nil {
+			pub = strings.TrimSpace(string(data))
+		}
+	} else {
+		if pub, err = coauthor.Generate(dir, name); err != nil {
+			exitError("Failed to generate author key: %v", err)
+		}
+		generated = true
+	}
+	author, err := coauthor.New(name, pub)
+	if err != nil {
+		exitError("%v", err)
+	}
+	for _, a := range wk.Authors {
+		if a.Key == author.Key {
+			exitError("That key already belongs to %s", a.Name)
+		}
+	}
+	wk.Authors = append(wk.Authors, *author)
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		exitError("Failed to save .well-known/polis: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "coauthor",
+			"data":    map[string]interface{}{"author": author, "key_generated": generated},
+		})
+		return
+	}
+	fmt.Printf("[✓] Added %s as an author of the site\n", name)
+	if generated {
+		fmt.Printf("[i] Their key is in %s/%s; sign as them with --as %s\n", coauthor.Dir, name, name)
+	}
+	fmt.Println("[i] Deploy your site so readers can verify what they sign")
+}
+
+// coauthorRemove marks an author removed. Content they signed before stays
+// valid; readers reject anything signed after.
+func coauthorRemove(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis coauthor remove <name>")
+	}
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if !holdsSiteKey(dir) {
+		exitError("Only the machine with the site key can remove authors")
+	}
+	author := coauthor.Find(wk.Authors, args[0])
+	if author == nil {
+		exitError("No author named %q", args[0])
+	}
+	if author.Removed == "" {
+		author.Removed = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		exitError("Failed to save .well-known/polis: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "coauthor",
+			"data":    map[string]interface{}{"removed": args[0]},
+		})
+		return
+	}
+	fmt.Printf("[✓] Removed %s as of %s\n", args[0], author.Removed)
+	fmt.Println("[i] Deploy your site; readers will then reject anything they sign from now on.")
+	fmt.Println("[i] What they signed before stays valid.")
+}
+
+func coauthorList() {
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	local := make(map[string]bool)
+	for _, name := range coauthor.Local(dir) {
+		local[name] = true
+	}
+
+	if jsonOutput {
+		type entry struct {
+			coauthor.Author
+			KeyHere bool `json:"key_here"`
+		}
+		authors := []entry{}
+		for _, a := range wk.Authors {
+			authors = append(authors, entry{a, local[a.Name]})
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "coauthor",
+			"data":    map[string]interface{}{"authors": authors, "sign_as": loadConfig().Get("sign_as")},
+		})
+		return
+	}
+	if len(wk.Authors) == 0 {
+		fmt.Println("[i] No co-authors (add one with: polis coauthor add <name>)")
+		return
+	}
+	signAs := loadConfig().Get("sign_as")
+	for _, a := range wk.Authors {
+		status := "since " + a.Added
+		if a.Removed != "" {
+			status = "removed " + a.Removed
+		}
+		var marks []string
+		if local[a.Name] {
+			marks = append(marks, "key here")
+		}
+		if a.Name == signAs {
+			marks = append(marks, "signs on this machine")
+		}
+		marker := ""
+		if len(marks) > 0 {
+			marker = "  (" + strings.Join(marks, ", ") + ")"
+		}
+		fmt.Printf("  %-16s %s%s\n", a.Name, status, marker)
+	}
+}
+
+// loadSigningKey returns the key posts and comments are signed with: the
+// key of co-author as, or of sign_as when as is empty, or else the site's
+// key.
+func loadSigningKey(dir, as string) ([]byte, error) {
+	if as == "" {
+		as = loadConfig().Get("sign_as")
+	}
+	if as == "" {
+		return loadPrivateKey(dir)
+	}
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		return nil, err
+	}
+	author := coauthor.Find(wk.Authors, as)
+	if author == nil {
+		return nil, fmt.Errorf("%q is not an author of this site (add them with: polis coauthor add %s)", as, as)
+	}
+	if author.Removed != "" {
+		return nil, fmt.Errorf("%q was removed from the site on %s", as, author.Removed)
+	}
+	return coauthor.LoadKey(dir, as)
+}
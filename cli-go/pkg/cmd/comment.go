@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
@@ -13,12 +15,17 @@ import (
 func handleComment(args []string) {
 	if len(args) < 1 {
 		printCommentUsage()
-		os.Exit(1)
+		exit(1)
 	}
 
 	subcommand := args[0]
 	subArgs := args[1:]
 
+	// Re-key drafts created before comment IDs were ULID-based
+	if dir := getDataDir(); isPolisSite(dir) {
+		comment.MigrateDraftIDs(dir)
+	}
+
 	switch subcommand {
 	case "draft":
 		handleCommentDraft(subArgs)
@@ -28,12 +35,14 @@ func handleComment(args []string) {
 		handleCommentList(subArgs)
 	case "sync":
 		handleCommentSync(subArgs)
+	case "retract":
+		handleCommentRetract(subArgs)
 	case "help", "--help", "-h":
 		printCommentUsage()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown comment subcommand: %s\n", subcommand)
 		printCommentUsage()
-		os.Exit(1)
+		exit(1)
 	}
 }
 
@@ -42,15 +51,18 @@ func printCommentUsage() {
 
 Subcommands:
   draft <url>        Create a comment draft replying to <url>
-  sign <id>          Sign a draft comment (moves to pending)
+  sign <id>          Sign a draft comment (moves to pending); --as <author>
+                     signs as a co-author of the site
   list [status]      List comments (drafts, pending, blessed, denied)
   sync               Sync pending comments with discovery service
+  retract <id>       Withdraw a signed comment and ask the post's author to drop it
 
 Examples:
   polis comment draft https://alice.polis.pub/posts/20260201/hello.md
   polis comment sign abc123
   polis comment list drafts
   polis comment sync
+  polis comment retract abc123
 `)
 }
 
@@ -91,9 +103,12 @@ func handleCommentDraft(args []string) {
 }
 
 func handleCommentSign(args []string) {
-	if len(args) < 1 {
-		exitError("Usage: polis comment sign <draft-id>")
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis comment sign <draft-id> [--as <author>]")
 	}
+	fs := flag.NewFlagSet("comment sign", flag.ExitOnError)
+	as := fs.String("as", "", "Sign as this co-author")
+	fs.Parse(args[1:])
 
 	draftID := args[0]
 	dir := getDataDir()
@@ -109,7 +124,7 @@ func handleCommentSign(args []string) {
 	}
 
 	// Load private key
-	privKey, err := loadPrivateKey(dir)
+	privKey, err := loadSigningKey(dir, *as)
 	if err != nil {
 		exitError("Failed to load private key: %v", err)
 	}
@@ -297,3 +312,40 @@ func handleCommentSync(args []string) {
 			len(result.Blessed), len(result.Denied), len(result.StillPending))
 	}
 }
+
+func handleCommentRetract(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis comment retract <comment-id>")
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+
+	result, err := comment.RetractComment(dir, args[0], privKey)
+	if err != nil {
+		exitError("Failed to retract comment: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"success": true,
+			"data":    result,
+		})
+		return
+	}
+
+	fmt.Printf("[✓] Retracted comment: %s (was %s)\n", result.ID, result.PreviousStatus)
+	if result.EventPublished {
+		fmt.Println("[i] Retraction announced; the post's author will drop the comment on their next sync.")
+	} else {
+		fmt.Printf("[!] Retraction not announced: %s\n", result.EventError)
+	}
+	fmt.Println("[i] Run 'polis render' and deploy to remove the comment from your live site.")
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/secrets"
+	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+)
+
+func handleConfig(args []string) {
+	if len(args) == 0 {
+		exitError("Usage: polis config <get|set|list|secure> [args]")
+	}
+
+	switch args[0] {
+	case "get":
+		handleConfigGet(args[1:])
+	case "set":
+		handleConfigSet(args[1:])
+	case "list", "ls":
+		handleConfigList(args[1:])
+	case "secure":
+		handleConfigSecure(args[1:])
+	default:
+		exitError("Unknown config subcommand: %s (expected get, set, list, or secure)", args[0])
+	}
+}
+
+func loadConfig() *config.Config {
+	cfg, err := config.Load(config.Options{DataDir: getDataDir(), Flags: flagOverrides})
+	if err != nil {
+		exitError("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// applyPublishConfig sets the publish package's site settings: the post
+// directory layout, and the accessibility check if audit_block_publish is on.
+func applyPublishConfig(cfg *config.Config) {
+	publish.PostPathFormat = cfg.Get("post_path")
+	publish.ContentCheck = nil
+	if cfg.Bool("audit_block_publish") {
+		publish.ContentCheck = render.CheckPublish
+	}
+}
+
+// applyRenderConfig sets which special pages render generates from the
+// pages.* settings, and the view beacon posts get if analytics is on.
+func applyRenderConfig(cfg *config.Config) {
+	render.SpecialPages = render.SpecialPageOptions{
+		Archive:  cfg.Bool("pages.archive"),
+		NotFound: cfg.Bool("pages.not_found"),
+		About:    cfg.Bool("pages.about"),
+	}
+	render.AnalyticsBeacon = ""
+	if cfg.Bool("analytics.enabled") {
+		render.AnalyticsBeacon = cfg.Get("analytics.beacon_url")
+	}
+}
+
+func handleConfigGet(args []string) {
+	fs := flag.NewFlagSet("config get", flag.ExitOnError)
+	showSecrets := fs.Bool("show-secrets", false, "Print secret values unmasked")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		exitError("Usage: polis config get <key>")
+	}
+	key := fs.Arg(0)
+	if _, ok := config.Lookup(key); !ok {
+		exitError("Unknown setting: %s (known: %s)", key, strings.Join(config.Keys(), ", "))
+	}
+
+	v, _ := loadConfig().Value(key)
+	value := v.Masked()
+	if *showSecrets {
+		value = v.Value
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "config",
+			"data": map[string]interface{}{
+				"key":    v.Key,
+				"value":  value,
+				"source": v.Source,
+			},
+		})
+		return
+	}
+	fmt.Println(value)
+}
+
+func handleConfigSet(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		exitError("Usage: polis config set <key> [value]")
+	}
+	key := args[0]
+	value := ""
+	if len(args) == 2 {
+		value = strings.TrimSpace(args[1])
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	if key == "theme" && value != "" {
+		themes, err := theme.ListThemes(dir, findCLIThemesDir())
+		if err != nil {
+			exitError("Failed to list themes: %v", err)
+		}
+		found := false
+		for _, t := range themes {
+			if t == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			exitError("Unknown theme: %s (available: %s)", value, strings.Join(themes, ", "))
+		}
+	}
+
+	setting, err := config.Set(dir, key, value)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	// A higher layer (environment or --set) still wins over what we just wrote
+	var shadowedBy string
+	v, ok := loadConfig().Value(key)
+	if ok && v.Source > config.LayerSite {
+		shadowedBy = v.Source.String()
+		if v.Source == config.LayerEnvironment {
+			shadowedBy += " (" + setting.Env + ")"
+		}
+	}
+	keychain, _ := secrets.Available()
+	inKeychain := value != "" && ok && v.Source == config.LayerKeychain
+
+	if jsonOutput {
+		data := map[string]interface{}{
+			"key":   key,
+			"value": value,
+		}
+		if shadowedBy != "" {
+			data["shadowed_by"] = shadowedBy
+		}
+		if inKeychain {
+			data["store"] = "keychain"
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "config",
+			"data":    data,
+		})
+		return
+	}
+
+	if value == "" {
+		fmt.Printf("[✓] Unset %s\n", key)
+	} else if inKeychain {
+		fmt.Printf("[✓] Set %s (stored in %s)\n", key, keychain)
+	} else {
+		fmt.Printf("[✓] Set %s\n", key)
+	}
+	if shadowedBy != "" {
+		fmt.Printf("[!] %s is still overridden by %s\n", key, shadowedBy)
+	}
+	if key == "theme" {
+		fmt.Println("[i] Run 'polis render --force' to apply the new theme")
+	}
+}
+
+func handleConfigList(args []string) {
+	fs := flag.NewFlagSet("config list", flag.ExitOnError)
+	showSecrets := fs.Bool("show-secrets", false, "Print secret values unmasked")
+	fs.Parse(args)
+
+	cfg := loadConfig()
+	values := cfg.All()
+	if !*showSecrets {
+		for i := range values {
+			values[i].Value = values[i].Masked()
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "config",
+			"data": map[string]interface{}{
+				"env_file": cfg.EnvFile,
+				"settings": values,
+			},
+		})
+		return
+	}
+
+	if cfg.EnvFile != "" {
+		fmt.Printf("[i] Loaded .env from %s\n", cfg.EnvFile)
+	}
+	for _, v := range values {
+		value := v.Value
+		if value == "" {
+			value = "(unset)"
+		}
+		fmt.Printf("  %-22s %-40s [%s]\n", v.Key, value, v.Source)
+	}
+}
+
+// handleConfigSecure moves the secrets in .env into the OS keychain.
+func handleConfigSecure(args []string) {
+	if len(args) != 0 {
+		exitError("Usage: polis config secure")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	keychain, ok := secrets.Available()
+	if !ok {
+		exitError("No OS keychain available; secrets stay in .env")
+	}
+
+	moved, err := config.MoveSecrets(dir)
+	if err != nil {
+		exitError("Failed to move secrets to %s: %v", keychain, err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "config",
+			"data": map[string]interface{}{
+				"keychain": keychain,
+				"moved":    moved,
+			},
+		})
+		return
+	}
+	if len(moved) == 0 {
+		fmt.Println("[i] No secrets in .env to move")
+		return
+	}
+	for _, key := range moved {
+		fmt.Printf("[✓] Moved %s to %s\n", key, keychain)
+	}
+}
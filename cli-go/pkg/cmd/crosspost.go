@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/bluesky"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// blueskyConfig returns the Bluesky account from the bluesky.* settings.
+func blueskyConfig(cfg *config.Config) bluesky.Config {
+	return bluesky.Config{
+		Handle:      cfg.Get("bluesky.handle"),
+		AppPassword: cfg.Get("bluesky_app_password"),
+		PDS:         cfg.Get("bluesky.pds"),
+	}
+}
+
+// siteBaseURL returns POLIS_BASE_URL, or the base URL in .well-known/polis.
+func siteBaseURL(dir string) string {
+	if baseURL != "" {
+		return baseURL
+	}
+	return getBaseURLFromSite(dir)
+}
+
+// crosspostPublished cross-posts a newly published post when Bluesky is
+// configured, mirrors it when the nostr bridge is enabled, and pins it when
+// an IPFS node is configured. Failures are reported but don't fail the
+// publish.
+func crosspostPublished(dir string, result *publish.PublishResult) {
+	mirrorPublished(dir, result, false)
+	pinPublished(dir, result)
+	cfg := blueskyConfig(loadConfig())
+	if !cfg.Enabled() || !result.Crosspostable() {
+		return
+	}
+	link, err := bluesky.Crosspost(dir, siteBaseURL(dir), cfg, result.Path)
+	if jsonOutput {
+		return
+	}
+	if link != nil {
+		fmt.Printf("[✓] Cross-posted to Bluesky: %s\n", link.URL)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Bluesky cross-post failed: %v (retry with: polis crosspost %s)\n", err, result.Path)
+	}
+}
+
+// handleCrosspost cross-posts an already published post to Bluesky.
+func handleCrosspost(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis crosspost <posts/YYYYMMDD/post.md>")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	postPath := fsutil.SiteRelPath(dir, args[0])
+
+	link, err := bluesky.Crosspost(dir, siteBaseURL(dir), blueskyConfig(loadConfig()), postPath)
+	if err != nil && link == nil {
+		if errors.Is(err, os.ErrNotExist) {
+			exitError("No published post at %s", postPath)
+		}
+		exitError("%v", err)
+	}
+	if err != nil && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "[!] %v\n", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "crosspost",
+			"data": map[string]interface{}{
+				"path":    postPath,
+				"service": link.Service,
+				"url":     link.URL,
+				"uri":     link.ID,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Cross-posted to Bluesky: %s\n", link.URL)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/daemon"
+)
+
+// DaemonOptions are passed to DaemonHandler when the daemon is started.
+type DaemonOptions struct {
+	DataDir  string
+	LogLevel string // --log-level (empty = use the log_level setting)
+}
+
+// DaemonHandler runs the daemon in the foreground until it is stopped.
+// In the CLI-only binary, this prints a message directing users to the bundled binary.
+// In the bundled binary, this is overridden with the webapp's sync service.
+var DaemonHandler func(opts DaemonOptions) = defaultDaemonHandler
+
+func defaultDaemonHandler(opts DaemonOptions) {
+	fmt.Fprintln(os.Stderr, "The daemon command requires the bundled binary (polis-full).")
+	fmt.Fprintln(os.Stderr, "Download from: https://github.com/vdibart/polis-cli/releases")
+	os.Exit(1)
+}
+
+func handleDaemon(args []string) {
+	sub := "start"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+
+	switch sub {
+	case "start":
+		DaemonHandler(DaemonOptions{DataDir: getDataDir(), LogLevel: logLevel})
+	case "status":
+		daemonStatus()
+	case "sync":
+		daemonRequest("sync", (*daemon.Client).Sync, "Sync requested")
+	case "stop":
+		daemonRequest("stop", (*daemon.Client).Stop, "Daemon stopping")
+	default:
+		exitError("Unknown daemon subcommand. Use: polis daemon [start|status|sync|stop]")
+	}
+}
+
+func daemonStatus() {
+	st, err := daemon.NewClient(getDataDir()).Status()
+	running := err == nil
+	if err != nil && !errors.Is(err, daemon.ErrNotRunning) {
+		exitError("Failed to query daemon: %v", err)
+	}
+
+	if jsonOutput {
+		data := map[string]interface{}{"running": running}
+		if running {
+			data["daemon"] = st
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "daemon status",
+			"data":    data,
+		})
+		return
+	}
+
+	if !running {
+		fmt.Println("[i] Daemon is not running")
+		return
+	}
+	fmt.Printf("[✓] Daemon running (pid %d) since %s\n", st.PID, st.StartedAt)
+	if st.LastSync != "" {
+		fmt.Printf("[i] Last sync: %s (%d cycles)\n", st.LastSync, st.SyncCycles)
+	} else {
+		fmt.Println("[i] No sync cycle completed yet")
+	}
+	fmt.Printf("[i] New notifications: %d, new feed items: %d, scheduled posts published: %d\n",
+		st.NewNotifications, st.NewFeedItems, st.ScheduledPublished)
+	if st.LastError != "" {
+		fmt.Printf("[!] Last error: %s\n", st.LastError)
+	}
+}
+
+func daemonRequest(name string, call func(*daemon.Client) error, done string) {
+	if err := call(daemon.NewClient(getDataDir())); err != nil {
+		if errors.Is(err, daemon.ErrNotRunning) {
+			exitError("Daemon is not running (start it with: polis daemon)")
+		}
+		exitError("Failed to send %s to daemon: %v", name, err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "daemon " + name,
+		})
+		return
+	}
+	fmt.Printf("[✓] %s\n", done)
+}
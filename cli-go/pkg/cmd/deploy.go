@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/deploy"
+)
+
+func handleDeploy(args []string) {
+	// Leading positional argument is either a subcommand or a target name
+	targetName := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if args[0] == "targets" {
+			handleDeployTargets()
+			return
+		}
+		targetName = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Show what would be uploaded without transferring")
+	prune := fs.Bool("prune", false, "Delete remote files that no longer exist locally")
+	full := fs.Bool("full", false, "Upload every file, ignoring the last deploy manifest")
+	fs.Parse(args)
+
+	dir := getDataDir()
+
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	cfg, err := deploy.LoadConfig(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+	target, err := cfg.Find(targetName)
+	if err != nil {
+		exitError("%v", err)
+	}
+	transport, err := deploy.NewTransport(target)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := deploy.Options{DryRun: *dryRun, Prune: *prune, Full: *full}
+	if !jsonOutput {
+		fmt.Printf("[i] Deploying to %s (%s)\n", target.Name, target.Type)
+	}
+
+	result, err := deploy.Run(ctx, dir, target, transport, opts, func(p deploy.Progress) {
+		if !jsonOutput {
+			fmt.Printf("  [%d/%d] %s %s\n", p.Done, p.Total, p.Action, p.Path)
+		}
+	})
+	if err != nil {
+		exitError("Deploy failed: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "deploy",
+			"data":    result,
+		})
+		return
+	}
+
+	if result.DryRun {
+		for _, p := range result.Uploaded {
+			fmt.Printf("  upload %s\n", p)
+		}
+		for _, p := range result.Deleted {
+			fmt.Printf("  delete %s\n", p)
+		}
+		fmt.Printf("[i] Dry run: %d to upload, %d to delete, %d unchanged\n",
+			len(result.Uploaded), len(result.Deleted), result.Unchanged)
+		return
+	}
+	fmt.Printf("[✓] Deploy complete: %d uploaded, %d deleted, %d unchanged\n",
+		len(result.Uploaded), len(result.Deleted), result.Unchanged)
+}
+
+func handleDeployTargets() {
+	dir := getDataDir()
+
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	cfg, err := deploy.LoadConfig(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "deploy-targets",
+			"data":    cfg,
+		})
+		return
+	}
+
+	if len(cfg.Targets) == 0 {
+		fmt.Printf("[i] No deploy targets configured. Add one to .polis/%s\n", deploy.ConfigFilename)
+		return
+	}
+	for _, t := range cfg.Targets {
+		marker := " "
+		if t.Name == cfg.Default {
+			marker = "*"
+		}
+		dest := t.Host + ":" + t.Path
+		if t.Type == deploy.TypeS3 {
+			dest = "s3://" + t.Bucket + "/" + strings.Trim(t.Prefix, "/")
+		}
+		fmt.Printf("%s %-12s %-6s %s\n", marker, t.Name, t.Type, dest)
+	}
+}
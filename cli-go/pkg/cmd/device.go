@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleDevice(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis device [init|add|install|list|revoke]")
+	}
+
+	switch args[0] {
+	case "init":
+		deviceInit()
+	case "add":
+		deviceAdd(args[1:])
+	case "install":
+		deviceInstall(args[1:])
+	case "list":
+		deviceList()
+	case "revoke":
+		deviceRevoke(args[1:])
+	default:
+		exitError("Unknown device subcommand. Use: polis device [init|add|install|list|revoke]")
+	}
+}
+
+// deviceInit generates a key on a second machine, to be delegated to by the
+// site key.
+func deviceInit() {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	keysDir := filepath.Join(dir, ".polis", "keys")
+	privPath := filepath.Join(keysDir, "id_ed25519")
+	pubPath := filepath.Join(keysDir, "id_ed25519.pub")
+
+	if existing, err := os.ReadFile(privPath); err == nil {
+		if cert, _ := delegation.FromKey(existing); cert != nil {
+			exitError("This machine is already device %q", cert.Device)
+		}
+		if holdsSiteKey(dir) {
+			exitError("This machine holds the site key; run polis device init on the other machine")
+		}
+		pub, _ := os.ReadFile(pubPath)
+		// An earlier init that hasn't been delegated yet
+		printDeviceKey(strings.TrimSpace(string(pub)), false)
+		return
+	}
+
+	privPEM, pubSSH, err := signing.GenerateKeypair()
+	if err != nil {
+		exitError("Failed to generate device key: %v", err)
+	}
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		exitError("Failed to create keys directory: %v", err)
+	}
+	if err := os.WriteFile(privPath, privPEM, 0600); err != nil {
+		exitError("Failed to write device key: %v", err)
+	}
+	if err := os.WriteFile(pubPath, pubSSH, 0644); err != nil {
+		exitError("Failed to write device public key: %v", err)
+	}
+	printDeviceKey(strings.TrimSpace(string(pubSSH)), true)
+}
+
+func printDeviceKey(pub string, created bool) {
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "device",
+			"data":    map[string]interface{}{"public_key": pub, "created": created},
+		})
+		return
+	}
+	if created {
+		fmt.Println("[✓] Generated a device key")
+	} else {
+		fmt.Println("[i] This machine has a device key waiting for a certificate")
+	}
+	fmt.Println()
+	fmt.Println("[i] On the machine with the site key, run:")
+	fmt.Printf("    polis device add <name> \"%s\"\n", pub)
+	fmt.Println("[i] Then install the certificate it prints here: polis device install <certificate>")
+}
+
+// deviceAdd signs a delegation certificate for a device key and lists the
+// device in .well-known/polis.
+func deviceAdd(args []string) {
+	fs := flag.NewFlagSet("device add", flag.ExitOnError)
+	scopes := fs.String("scopes", strings.Join(delegation.Scopes, ","), "What the device may sign")
+	days := fs.Int("days", 365, "Days until the certificate expires")
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") && len(positional) < 2 {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	fs.Parse(args)
+	if len(positional) < 2 {
+		exitError("Usage: polis device add <name> <public key | key file> [--scopes publish,comment] [--days 365]")
+	}
+	name, deviceKey := positional[0], positional[1]
+	if data, err := os.ReadFile(deviceKey); err == nil {
+		deviceKey = strings.TrimSpace(string(data))
+	}
+
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if !holdsSiteKey(dir) {
+		exitError("%v", delegation.ErrDelegated)
+	}
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	domain := wk.AuthorDomain()
+	if domain == "" {
+		domain = extractDomain(siteBaseURL(dir))
+	}
+	if domain == "" {
+		exitError("No site domain (set base_url)")
+	}
+
+	var scopeList []string
+	for _, s := range strings.Split(*scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopeList = append(scopeList, s)
+		}
+	}
+	expires := time.Now().Add(time.Duration(*days) * 24 * time.Hour)
+	cert, err := delegation.Issue(privKey, domain, name, deviceKey, scopeList, expires)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	// Replace any earlier entry for this device name or key
+	devices := wk.Devices[:0]
+	for _, d := range wk.Devices {
+		if d.Name != cert.Device && d.Key != cert.Key {
+			devices = append(devices, d)
+		}
+	}
+	wk.Devices = append(devices, cert.Record())
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		exitError("Failed to save .well-known/polis: %v", err)
+	}
+
+	token := cert.Encode()
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "device",
+			"data": map[string]interface{}{
+				"device":      cert.Device,
+				"scopes":      cert.Scopes,
+				"expires":     cert.Expires,
+				"certificate": token,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Delegated %s to %s until %s\n", strings.Join(cert.Scopes, " and "), cert.Device, cert.Expires)
+	fmt.Println()
+	fmt.Printf("[i] On %s, run:\n", cert.Device)
+	fmt.Printf("    polis device install %s\n", token)
+	fmt.Println("[i] Deploy your site so readers can see the device list")
+}
+
+// deviceInstall stores a certificate with this machine's device key.
+func deviceInstall(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis device install <certificate>")
+	}
+	cert, err := delegation.Decode(args[0])
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	// The certificate is stored in the key file itself, whichever backend
+	// signs
+	keyPath := filepath.Join(dir, ".polis", "keys", "id_ed25519")
+	privKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		exitError("No device key (run: polis device init)")
+	}
+	pub, err := os.ReadFile(filepath.Join(dir, ".polis", "keys", "id_ed25519.pub"))
+	if err != nil {
+		exitError("Failed to read device public key: %v", err)
+	}
+	if fields := strings.Fields(string(pub)); len(fields) < 2 || fields[0]+" "+fields[1] != cert.Key {
+		exitError("Certificate is for a different key than this device's")
+	}
+	if err := cert.Verify(wk.PublicKey, "", cert.Scopes[0], time.Now()); err != nil {
+		exitError("Certificate rejected: %v", err)
+	}
+
+	if err := os.WriteFile(keyPath, delegation.Attach(privKey, cert), 0600); err != nil {
+		exitError("Failed to save certificate: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "device",
+			"data": map[string]interface{}{
+				"device":  cert.Device,
+				"scopes":  cert.Scopes,
+				"expires": cert.Expires,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] This machine can now sign as %s (%s) until %s\n", cert.Device, strings.Join(cert.Scopes, ", "), cert.Expires)
+}
+
+func deviceList() {
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	var current string
+	if privKey, err := loadPrivateKey(dir); err == nil {
+		if cert, _ := delegation.FromKey(privKey); cert != nil {
+			current = cert.Key
+		}
+	}
+
+	if jsonOutput {
+		devices := wk.Devices
+		if devices == nil {
+			devices = []delegation.Device{}
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "device",
+			"data":    map[string]interface{}{"devices": devices},
+		})
+		return
+	}
+	if len(wk.Devices) == 0 {
+		fmt.Println("[i] No devices (add one with: polis device add <name> <public key>)")
+		return
+	}
+	now := time.Now()
+	for _, d := range wk.Devices {
+		status := "expires " + d.Expires
+		if d.Revoked != "" {
+			status = "revoked " + d.Revoked
+		} else if t, err := delegation.ParseTime(d.Expires); err == nil && now.After(t) {
+			status = "expired " + d.Expires
+		}
+		marker := ""
+		if d.Key == current {
+			marker = "  (this machine)"
+		}
+		fmt.Printf("  %-12s %-16s %s%s\n", d.Name, strings.Join(d.Scopes, ","), status, marker)
+	}
+}
+
+// deviceRevoke marks a device revoked in .well-known/polis. Readers then
+// reject everything it signed.
+func deviceRevoke(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis device revoke <name>")
+	}
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if !holdsSiteKey(dir) {
+		exitError("%v", delegation.ErrDelegated)
+	}
+
+	found := false
+	for i := range wk.Devices {
+		if wk.Devices[i].Name == args[0] {
+			if wk.Devices[i].Revoked == "" {
+				wk.Devices[i].Revoked = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+			}
+			found = true
+		}
+	}
+	if !found {
+		exitError("No device named %q", args[0])
+	}
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		exitError("Failed to save .well-known/polis: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "device",
+			"data":    map[string]interface{}{"revoked": args[0]},
+		})
+		return
+	}
+	fmt.Printf("[✓] Revoked %s in .well-known/polis\n", args[0])
+	fmt.Println("[i] Deploy your site; readers will then reject everything it signed.")
+	fmt.Println("[i] Re-sign posts you want to keep with: polis republish <path>")
+}
+
+// holdsSiteKey reports whether this machine's key is the one in
+// .well-known/polis, rather than a device key.
+func holdsSiteKey(dir string) bool {
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		return false
+	}
+	pub, err := os.ReadFile(filepath.Join(dir, ".polis", "keys", "id_ed25519.pub"))
+	return err == nil && strings.TrimSpace(string(pub)) == strings.TrimSpace(wk.PublicKey)
+}
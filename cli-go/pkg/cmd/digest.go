@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/digest"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+func handleDigest(args []string) {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	since := fs.String("since", digest.DefaultSince, "Period to cover, e.g. 7d, 2w, or 24h")
+	out := fs.String("out", "", "Write the digest to this file instead of printing it")
+	draft := fs.Bool("draft", false, "Save the digest as a post draft")
+	publishPost := fs.Bool("publish", false, "Publish the digest as a post")
+	email := fs.Bool("email", false, "Email the digest with the site's notification email settings")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		exitError("Usage: polis digest [--since 7d] [--out <file>] [--draft | --publish] [--email]")
+	}
+	if *draft && *publishPost {
+		exitError("--draft and --publish can't be combined")
+	}
+
+	period, err := digest.ParseSince(*since)
+	if err != nil {
+		exitError("%v", err)
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+
+	now := time.Now()
+	d, err := digest.Build(dir, digest.Options{
+		Since:           now.Add(-period),
+		Until:           now,
+		DiscoveryDomain: discoveryDomain,
+		BaseURL:         baseURL,
+	})
+	if err != nil {
+		exitError("Failed to build digest: %v", err)
+	}
+	markdown := d.Markdown()
+
+	// Email before publishing, whose output ends the command
+	if *email {
+		cfg, err := mailer.LoadConfig(dir)
+		if err != nil {
+			exitError("Failed to load email settings: %v", err)
+		}
+		if cfg == nil || !cfg.Enabled {
+			exitError("Email notifications are not configured (see Settings in polis serve)")
+		}
+		if err := mailer.SendSMTP(*cfg, "[polis] "+d.Title(), d.Body()); err != nil {
+			exitError("Failed to email digest: %v", err)
+		}
+		if !jsonOutput {
+			fmt.Printf("[✓] Emailed digest to %s\n", cfg.To)
+		}
+	}
+
+	var draftID string
+	if *draft || *publishPost {
+		draftID, err = publish.SaveDraft(dir, "", markdown)
+		if err != nil {
+			exitError("Failed to save draft: %v", err)
+		}
+	}
+	if *publishPost {
+		applyPublishConfig(loadConfig())
+		handlePublishDraft(dir, draftID, "", publish.PublishOptions{}, false)
+		return
+	}
+
+	if *out != "" {
+		if err := fsutil.WriteFile(*out, []byte(markdown), 0644); err != nil {
+			exitError("Failed to write digest: %v", err)
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "digest",
+			"data": map[string]interface{}{
+				"digest":   d,
+				"markdown": markdown,
+				"draft_id": draftID,
+				"emailed":  *email,
+			},
+		})
+		return
+	}
+	switch {
+	case draftID != "":
+		fmt.Printf("[✓] Saved digest as draft: %s\n", draftID)
+		fmt.Printf("Publish with: polis post --draft %s\n", draftID)
+	case *out != "":
+		fmt.Printf("[✓] Wrote digest to %s\n", *out)
+	case !*email:
+		fmt.Print(markdown)
+	}
+}
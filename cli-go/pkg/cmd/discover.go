@@ -6,13 +6,22 @@ import (
 	"strings"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/explore"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
 )
 
 func handleDiscover(args []string) {
+	if len(args) > 0 && (args[0] == "new" || args[0] == "trending") {
+		handleDiscoverNew(args[0], args[1:])
+		return
+	}
+
 	fs := flag.NewFlagSet("discover", flag.ExitOnError)
 	specificAuthor := fs.String("author", "", "Check a specific author")
+	probeMoves := fs.Bool("moves", false, "Check every followed site for a moved statement")
 	fs.Parse(args)
 
 	dir := getDataDir()
@@ -120,6 +129,17 @@ func handleDiscover(args []string) {
 		_ = cm.SetCursor(result.Cursor)
 	}
 
+	// Follow authors who moved to their new address
+	moves, moveErrs := followMoves(dir, discoveryDomain, client, f, domains, *probeMoves)
+
+	// Prefetch unread content so the webapp can show it instantly and offline
+	prefetched := 0
+	if cfg := loadConfig(); cfg.Bool("prefetch") {
+		cache := remote.NewContentCache(remote.ContentCacheDir(dir))
+		cache.MaxBytes = int64(cfg.Int("content_cache_mb")) << 20
+		prefetched = cache.Prefetch(cm.UnreadURLs())
+	}
+
 	if !jsonOutput {
 		fmt.Printf("[i] Checking %d followed author(s)...\n\n", len(domains))
 
@@ -146,6 +166,15 @@ func handleDiscover(args []string) {
 		} else {
 			fmt.Println("[i] No new content from followed authors")
 		}
+		if prefetched > 0 {
+			fmt.Printf("[i] Prefetched %d item(s) for offline reading\n", prefetched)
+		}
+		for _, m := range moves {
+			fmt.Printf("[✓] %s moved to %s; now following the new address\n", m.From, m.To)
+		}
+		for _, err := range moveErrs {
+			fmt.Printf("[!] Ignored a moved statement: %v\n", err)
+		}
 	}
 
 	if jsonOutput {
@@ -166,8 +195,113 @@ func handleDiscover(args []string) {
 			"data": map[string]interface{}{
 				"authors_checked": len(domains),
 				"total_new_items": newCount,
+				"prefetched":      prefetched,
 				"items":           jsonItems,
+				"moves":           moves,
 			},
 		})
 	}
 }
+
+// handleDiscoverNew lists posts in the discovery service's public stream by
+// authors not followed, newest or trending first.
+func handleDiscoverNew(sort string, args []string) {
+	fs := flag.NewFlagSet("discover "+sort, flag.ExitOnError)
+	before := fs.String("before", "", "Continue from a page's next cursor")
+	limit := fs.Int("limit", explore.DefaultLimit, "Posts per page")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	if discoveryURL == "" || discoveryKey == "" {
+		exitError("Discovery service not configured (set DISCOVERY_SERVICE_URL and DISCOVERY_SERVICE_KEY)")
+	}
+
+	f, err := following.Load(following.DefaultPath(dir))
+	if err != nil {
+		exitError("Failed to load following.json: %v", err)
+	}
+	exclude := []string{discovery.ExtractDomainFromURL(baseURL)}
+	for _, entry := range f.All() {
+		exclude = append(exclude, discovery.ExtractDomainFromURL(entry.URL))
+	}
+
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+	filters, _ := feed.NewCacheManager(dir, discoveryDomain).LoadFilters()
+
+	page, err := explore.Query(discovery.NewClient(discoveryURL, discoveryKey), explore.Options{
+		Sort:    sort,
+		Before:  *before,
+		Limit:   *limit,
+		Exclude: exclude,
+		Filters: filters,
+	})
+	if err != nil {
+		exitError("Failed to query discovery stream: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "discover",
+			"data":    page,
+		})
+		return
+	}
+
+	if len(page.Posts) == 0 {
+		fmt.Println("[i] No posts from authors you don't follow")
+		return
+	}
+	for _, p := range page.Posts {
+		dateStr := p.Published
+		if len(dateStr) > 10 {
+			dateStr = dateStr[:10]
+		}
+		title := p.Title
+		if title == "" {
+			title = p.URL
+		}
+		fmt.Printf("  %s  %s", dateStr, title)
+		if sort == explore.SortTrending && p.Comments > 0 {
+			fmt.Printf("  (%d commenting)", p.Comments)
+		}
+		fmt.Printf("\n      %s\n", p.URL)
+		fmt.Printf("      by %s  (polis follow %s)\n", p.AuthorDomain, p.AuthorURL)
+	}
+	if page.Next != "" {
+		fmt.Printf("\n[i] More: polis discover %s --before %s\n", sort, page.Next)
+	}
+}
+
+// followMoves checks followed authors for moves announced to the discovery
+// service (with probe, every followed site), points following.json at the
+// new addresses of those that verify, and raises a notification for each.
+func followMoves(dir, discoveryDomain string, client *discovery.Client, f *following.FollowingFile, domains []string, probe bool) ([]following.Move, []error) {
+	var opts following.MoveOptions
+	if resp, err := client.QueryMigrations(domains); err == nil {
+		opts.Announced = resp.Migrations
+	}
+	opts.Probe = probe
+	if len(opts.Announced) == 0 && !probe {
+		return nil, nil
+	}
+
+	moves, errs := f.FindMoves(remote.NewClient(), opts)
+	applied, err := following.ApplyMoves(following.DefaultPath(dir), moves)
+	if err != nil {
+		return nil, append(errs, err)
+	}
+	if len(applied) > 0 {
+		mgr := notification.NewManager(dir, discoveryDomain)
+		if _, err := mgr.Append(following.MoveNotifications(applied)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return applied, errs
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/book"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+)
+
+// nonSlug matches runs of characters left out of default book filenames.
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func handleExportBook(args []string) {
+	fs := flag.NewFlagSet("export-book", flag.ExitOnError)
+	format := fs.String("format", book.FormatEPUB, "Output format: epub or pdf (print-ready HTML)")
+	tag := fs.String("tag", "", "Only posts with this tag")
+	series := fs.String("series", "", "Only posts in this series")
+	year := fs.String("year", "", "Only posts published this year")
+	title := fs.String("title", "", "Book title")
+	output := fs.String("output", "", "Output file")
+	fs.Parse(args)
+
+	if *format != book.FormatEPUB && *format != book.FormatPDF {
+		exitError("Unknown format: %s (expected epub or pdf)", *format)
+	}
+	if *tag != "" && *series != "" {
+		exitError("Use --tag or --series, not both")
+	}
+	if *year != "" && !regexp.MustCompile(`^\d{4}$`).MatchString(*year) {
+		exitError("Invalid year: %s", *year)
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	b, err := book.Load(dir, book.Options{
+		Tag:              *tag,
+		Series:           *series,
+		Year:             *year,
+		Title:            *title,
+		MarkdownRenderer: render.MarkdownToHTML,
+	})
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	path := *output
+	if path == "" {
+		ext := ".epub"
+		if *format == book.FormatPDF {
+			ext = ".html"
+		}
+		name := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(b.Meta.Title), "-"), "-")
+		if name == "" {
+			name = "book"
+		}
+		path = name + ext
+	}
+
+	var buf bytes.Buffer
+	if *format == book.FormatEPUB {
+		err = b.WriteEPUB(&buf)
+	} else {
+		err = b.WriteHTML(&buf)
+	}
+	if err != nil {
+		exitError("Failed to build book: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		exitError("Failed to write %s: %v", path, err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "export-book",
+			"data": map[string]interface{}{
+				"path":     path,
+				"format":   *format,
+				"meta":     b.Meta,
+				"chapters": b.Chapters,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Wrote %d posts to %s\n", len(b.Chapters), path)
+	if *format == book.FormatPDF {
+		fmt.Println("[i] Open it in a browser and print to PDF")
+	}
+}
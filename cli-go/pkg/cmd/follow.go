@@ -107,6 +107,9 @@ func handleFollow(args []string) {
 	}
 
 	f.Add(authorURL)
+	if entry := f.Get(authorURL); entry != nil && entry.PublicKey == "" {
+		entry.PublicKey = remoteWK.PublicKey
+	}
 
 	if err := following.Save(followingPath, f); err != nil {
 		exitError("Failed to save following.json: %v", err)
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/followlist"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+func handleFollowList(args []string) {
+	usage := "Usage: polis follow-list [list|create|include|exclude|delete|add|remove|sync]"
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	fs := flag.NewFlagSet("follow-list "+args[0], flag.ExitOnError)
+	title := fs.String("title", "", "List title (create)")
+	description := fs.String("description", "", "List description (create)")
+	fromFollowing := fs.Bool("from-following", false, "Start the list with everyone you follow (create)")
+	note := fs.String("note", "", "Why the site is on the list (include)")
+
+	sub := args[0]
+	rest := args[1:]
+	var positional []string
+	for len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		positional = append(positional, rest[0])
+		rest = rest[1:]
+	}
+	fs.Parse(rest)
+	positional = append(positional, fs.Args()...)
+
+	switch sub {
+	case "list":
+		followListShow(dir)
+	case "create":
+		if len(positional) < 1 {
+			exitError("Usage: polis follow-list create <name> [url...] [--title T] [--description D] [--from-following]")
+		}
+		slug := positional[0]
+		if !followlist.ValidSlug(slug) {
+			slug = publish.Slugify(slug)
+			if *title == "" {
+				*title = positional[0]
+			}
+		}
+		if _, err := followlist.Load(dir, slug); err == nil {
+			exitError("List %s already exists; use 'polis follow-list include %s <url>'", slug, slug)
+		}
+		l, err := followlist.New(slug, *title, baseURL)
+		if err != nil {
+			exitError("%v", err)
+		}
+		l.Description = *description
+		if *fromFollowing {
+			f, err := following.Load(following.DefaultPath(dir))
+			if err != nil {
+				exitError("Failed to load following.json: %v", err)
+			}
+			for _, entry := range f.All() {
+				l.Add(entry.URL, "")
+			}
+		}
+		for _, u := range positional[1:] {
+			l.Add(followListMemberURL(u), "")
+		}
+		followListSave(dir, l, "create")
+	case "include", "exclude":
+		if len(positional) < 2 {
+			exitError("Usage: polis follow-list %s <name> <url>...", sub)
+		}
+		l, err := followlist.Load(dir, positional[0])
+		if err != nil {
+			exitError("Failed to load list %s: %v", positional[0], err)
+		}
+		changed := false
+		for _, u := range positional[1:] {
+			if sub == "include" {
+				changed = l.Add(followListMemberURL(u), *note) || changed
+			} else {
+				changed = l.Remove(followListMemberURL(u)) || changed
+			}
+		}
+		if !changed {
+			exitError("List %s is unchanged", l.Slug)
+		}
+		followListSave(dir, l, sub)
+	case "delete":
+		if len(positional) != 1 {
+			exitError("Usage: polis follow-list delete <name>")
+		}
+		if err := followlist.Delete(dir, positional[0]); err != nil {
+			exitError("Failed to delete list: %v", err)
+		}
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"status":  "success",
+				"command": "follow-list",
+				"data":    map[string]interface{}{"deleted": positional[0]},
+			})
+			return
+		}
+		fmt.Printf("[✓] Deleted list %s\n", positional[0])
+	case "add":
+		if len(positional) != 1 {
+			exitError("Usage: polis follow-list add <list-url>")
+		}
+		if !strings.HasPrefix(positional[0], "https://") {
+			exitError("List URL must use HTTPS")
+		}
+		follow := followListFollower(dir)
+		result, err := followlist.Subscribe(dir, positional[0], remote.NewClient(), follow, baseURL)
+		if err != nil {
+			exitError("Failed to subscribe: %v", err)
+		}
+		followListReport("add", []*followlist.SyncResult{result})
+	case "remove":
+		if len(positional) != 1 {
+			exitError("Usage: polis follow-list remove <list-url>")
+		}
+		subs, err := followlist.LoadSubscriptions(dir)
+		if err != nil {
+			exitError("%v", err)
+		}
+		if !subs.Remove(positional[0]) {
+			exitError("Not subscribed to %s", positional[0])
+		}
+		if err := subs.Save(dir); err != nil {
+			exitError("Failed to save subscriptions: %v", err)
+		}
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"status":  "success",
+				"command": "follow-list",
+				"data":    map[string]interface{}{"unsubscribed": positional[0]},
+			})
+			return
+		}
+		fmt.Printf("[✓] Unsubscribed from %s (its members stay followed)\n", positional[0])
+	case "sync":
+		results, err := followlist.Sync(dir, remote.NewClient(), followListFollower(dir), baseURL)
+		if err != nil {
+			exitError("Failed to sync lists: %v", err)
+		}
+		followListReport("sync", results)
+	default:
+		exitError(usage)
+	}
+}
+
+// followListMemberURL accepts a member as a URL or a bare domain.
+func followListMemberURL(u string) string {
+	if !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	return strings.TrimRight(u, "/")
+}
+
+// followListSave signs and saves one of the site's lists.
+func followListSave(dir string, l *followlist.List, action string) {
+	if baseURL == "" {
+		exitError("POLIS_BASE_URL not configured")
+	}
+	l.Author = strings.TrimSuffix(baseURL, "/")
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	if err := l.Sign(privKey); err != nil {
+		exitError("%v", err)
+	}
+	if err := l.Save(dir); err != nil {
+		exitError("Failed to save list: %v", err)
+	}
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "follow-list",
+			"data": map[string]interface{}{
+				"action": action,
+				"list":   l,
+				"url":    followlist.URL(baseURL, l.Slug),
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Saved list %s (%d members)\n", l.Slug, len(l.Members))
+	fmt.Printf("[i] Run 'polis render' and deploy to publish %s\n", followlist.PagePath(l.Slug))
+	fmt.Printf("[i] Others subscribe with: polis follow-list add %s\n", followlist.URL(baseURL, l.Slug))
+}
+
+// followListFollower follows list members the way 'polis follow' does,
+// blessing their pending comments.
+func followListFollower(dir string) followlist.FollowFunc {
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	client := discovery.NewAuthenticatedClient(discoveryURL, discoveryKey, discovery.ExtractDomainFromURL(baseURL), privKey)
+	path := following.DefaultPath(dir)
+	return func(url string) (bool, error) {
+		if f, err := following.Load(path); err == nil && f.IsFollowing(url) {
+			return true, nil
+		}
+		result, err := following.FollowWithBlessing(path, url, client, remote.NewClient(), privKey)
+		if err != nil {
+			return false, err
+		}
+		return result.AlreadyFollowed, nil
+	}
+}
+
+func followListReport(command string, results []*followlist.SyncResult) {
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "follow-list",
+			"data":    map[string]interface{}{"action": command, "lists": results},
+		})
+		return
+	}
+	if len(results) == 0 {
+		fmt.Println("[i] Not subscribed to any lists. Use 'polis follow-list add <list-url>'.")
+		return
+	}
+	failed := false
+	for _, r := range results {
+		name := r.Title
+		if name == "" {
+			name = r.URL
+		}
+		if r.Error != "" {
+			fmt.Fprintf(os.Stderr, "[!] %s: %s\n", name, r.Error)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s\n", name)
+		for _, u := range r.Followed {
+			fmt.Printf("  [✓] Following %s\n", u)
+		}
+		for u, msg := range r.Failed {
+			fmt.Printf("  [!] Failed to follow %s: %s\n", u, msg)
+		}
+		switch {
+		case len(r.Followed) == 0 && len(r.Failed) == 0:
+			fmt.Println("  [i] No new members")
+		case len(r.Already) > 0:
+			fmt.Printf("  [i] Already following %d more\n", len(r.Already))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// followListShow prints the site's lists and its subscriptions.
+func followListShow(dir string) {
+	lists, err := followlist.LoadAll(dir)
+	if err != nil {
+		exitError("Failed to load lists: %v", err)
+	}
+	subs, err := followlist.LoadSubscriptions(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+	if jsonOutput {
+		if lists == nil {
+			lists = []*followlist.List{}
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "follow-list",
+			"data":    map[string]interface{}{"lists": lists, "subscriptions": subs.Lists},
+		})
+		return
+	}
+
+	fmt.Println("Your lists:")
+	if len(lists) == 0 {
+		fmt.Println("  (none; create one with 'polis follow-list create <name>')")
+	}
+	for _, l := range lists {
+		fmt.Printf("  %-20s %-30s %d members\n", l.Slug, l.Title, len(l.Members))
+	}
+	fmt.Println("\nSubscribed:")
+	if len(subs.Lists) == 0 {
+		fmt.Println("  (none; subscribe with 'polis follow-list add <list-url>')")
+	}
+	for _, s := range subs.Lists {
+		checked := s.CheckedAt
+		if len(checked) > 10 {
+			checked = checked[:10]
+		}
+		fmt.Printf("  %s  (%s, checked %s)\n", s.URL, s.Title, checked)
+	}
+}
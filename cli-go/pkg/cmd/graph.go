@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/graph"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleGraph(args []string) {
+	if len(args) < 1 {
+		printGraphUsage()
+		exit(1)
+	}
+
+	switch args[0] {
+	case "export":
+		handleGraphExport(args[1:])
+	case "help", "--help", "-h":
+		printGraphUsage()
+	default:
+		printGraphUsage()
+		exit(1)
+	}
+}
+
+func printGraphUsage() {
+	fmt.Print(`Usage: polis graph export [--format json|dot|graphml] [--output <file>]
+
+Subcommands:
+  export                Write the local social graph (follows, comments, blessings)
+
+Examples:
+  polis graph export --format graphml --output network.graphml
+  polis graph export --format dot | dot -Tsvg > network.svg
+`)
+}
+
+func handleGraphExport(args []string) {
+	fs := flag.NewFlagSet("graph export", flag.ExitOnError)
+	format := fs.String("format", graph.FormatJSON, "Output format: "+strings.Join(graph.Formats, ", "))
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+	self := site.GetAuthorDomain(dir)
+	if self == "" {
+		exitError("No domain in .well-known/polis")
+	}
+
+	g := graph.Build(dir, discoveryDomain, self)
+
+	if jsonOutput && *output == "" {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "graph export",
+			"data":    g,
+		})
+		return
+	}
+
+	w := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			exitError("Failed to create %s: %v", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := graph.Write(w, g, *format); err != nil {
+		exitError("%v", err)
+	}
+	if *output != "" {
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"status":  "success",
+				"command": "graph export",
+				"data":    map[string]interface{}{"path": *output, "format": *format, "nodes": len(g.Nodes), "edges": len(g.Edges)},
+			})
+			return
+		}
+		fmt.Fprintf(os.Stderr, "[✓] Wrote %d nodes and %d edges to %s\n", len(g.Nodes), len(g.Edges), *output)
+	}
+}
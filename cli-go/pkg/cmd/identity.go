@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/identity"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleIdentity(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis identity [prove|list|remove|verify]")
+	}
+
+	switch args[0] {
+	case "prove":
+		identityProve(args[1:])
+	case "list":
+		identityList()
+	case "remove":
+		identityRemove(args[1:])
+	case "verify":
+		identityVerify(args[1:])
+	default:
+		exitError("Unknown identity subcommand. Use: polis identity [prove|list|remove|verify]")
+	}
+}
+
+func identityProve(args []string) {
+	fs := flag.NewFlagSet("identity prove", flag.ExitOnError)
+	postURL := fs.String("url", "", "Gist the GitHub proof is posted in")
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") && len(positional) < 2 {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	fs.Parse(args)
+	if len(positional) == 0 || (positional[0] != identity.ServiceDNS && len(positional) < 2) {
+		exitError("Usage: polis identity prove github <user> [--url <gist>]\n       polis identity prove dns [domain]\n       polis identity prove url <https://profile>")
+	}
+	service, account := positional[0], ""
+	if len(positional) > 1 {
+		account = positional[1]
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	p, err := identity.Prove(dir, privKey, extractDomain(siteBaseURL(dir)), service, account, *postURL)
+	if err != nil {
+		exitError("%v", err)
+	}
+	token := identity.Token(*p)
+	var postedErr error
+	if identity.Location(*p) != "" {
+		postedErr = identity.CheckPosted(*p)
+	}
+
+	if jsonOutput {
+		data := map[string]interface{}{
+			"service":  p.Service,
+			"account":  p.Account,
+			"token":    token,
+			"proof":    identity.Text(*p),
+			"location": identity.Location(*p),
+			"posted":   identity.Location(*p) != "" && postedErr == nil,
+		}
+		if p.URL != "" {
+			data["url"] = p.URL
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "identity",
+			"data":    data,
+		})
+		return
+	}
+
+	fmt.Printf("[✓] Signed a proof for %s on %s and added it to .well-known/polis\n", p.Account, p.Service)
+	fmt.Println()
+	switch {
+	case p.Service == identity.ServiceGitHub && p.URL == "":
+		fmt.Printf("[i] Create a public gist as %s at https://gist.github.com/ with this content:\n\n", p.Account)
+		fmt.Println(identity.Text(*p))
+		fmt.Printf("[i] Then record it: polis identity prove github %s --url <gist URL>\n", p.Account)
+	case p.Service == identity.ServiceGitHub:
+		reportPosted(postedErr, "the gist")
+	case p.Service == identity.ServiceDNS:
+		fmt.Println("[i] Add this TXT record to your DNS:")
+		fmt.Printf("    %s  TXT  \"%s\"\n\n", identity.Location(*p), token)
+		reportPosted(postedErr, "DNS")
+	default:
+		fmt.Printf("[i] Add this line to %s (or paste the full proof):\n", p.Account)
+		fmt.Printf("    %s\n\n", token)
+		reportPosted(postedErr, "the page")
+	}
+	fmt.Println("[i] Deploy your site so others can verify it: polis identity verify <your domain>")
+}
+
+func reportPosted(err error, where string) {
+	if err == nil {
+		fmt.Printf("[✓] Found the proof in %s\n", where)
+		return
+	}
+	fmt.Printf("[!] Not found in %s yet: %v\n", where, err)
+}
+
+func identityList() {
+	dir := getDataDir()
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	if jsonOutput {
+		proofs := wk.Proofs
+		if proofs == nil {
+			proofs = []site.IdentityProof{}
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "identity",
+			"data":    map[string]interface{}{"proofs": proofs},
+		})
+		return
+	}
+	if len(wk.Proofs) == 0 {
+		fmt.Println("[i] No identity proofs (add one with: polis identity prove <service> <account>)")
+		return
+	}
+	for _, p := range wk.Proofs {
+		fmt.Printf("  %-7s %s\n", p.Service, p.Account)
+		if loc := identity.Location(p); loc != "" {
+			fmt.Printf("          %s\n", loc)
+		}
+	}
+}
+
+func identityRemove(args []string) {
+	if len(args) < 1 || (args[0] != identity.ServiceDNS && len(args) < 2) {
+		exitError("Usage: polis identity remove <service> <account>")
+	}
+	account := ""
+	if len(args) > 1 {
+		account = args[1]
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if err := identity.Remove(dir, extractDomain(siteBaseURL(dir)), args[0], account); err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "identity",
+			"data":    map[string]interface{}{"removed": args[0]},
+		})
+		return
+	}
+	fmt.Println("[✓] Removed the proof from .well-known/polis")
+	fmt.Println("[i] Deploy your site, and delete the posted proof if you like")
+}
+
+func identityVerify(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis identity verify <domain>")
+	}
+	domain := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(args[0], "https://"), "http://"), "/")
+
+	results, err := identity.Verify(args[0])
+	// Record the check so the feed's badges reflect it
+	if dir := getDataDir(); isPolisSite(dir) {
+		if c, cerr := identity.LoadCache(dir); cerr == nil {
+			c.Record(domain, results, err)
+			identity.SaveCache(dir, c)
+		}
+	}
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "identity",
+			"data":    map[string]interface{}{"domain": domain, "proofs": results},
+		})
+		return
+	}
+	if len(results) == 0 {
+		fmt.Printf("[i] %s lists no identity proofs\n", domain)
+		return
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Verified {
+			fmt.Printf("[✓] %s on %s\n", r.Account, r.Service)
+		} else {
+			fmt.Printf("[✗] %s on %s: %s\n", r.Account, r.Service, r.Error)
+			failed++
+		}
+	}
+	if failed > 0 {
+		exit(1)
+	}
+}
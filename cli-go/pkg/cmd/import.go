@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/importer"
+)
+
+func handleImport(args []string) {
+	usage := "Usage: polis import tweets <archive.zip> [--announce] [--dry-run]\n       polis import mastodon <archive> [--announce] [--dry-run]"
+	if len(args) < 1 {
+		exitError(usage)
+	}
+	var source string
+	switch args[0] {
+	case "tweets":
+		source = importer.SourceTwitter
+	case "mastodon":
+		source = importer.SourceMastodon
+	default:
+		exitError("Unknown import source. Use: polis import [tweets|mastodon]")
+	}
+
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	announce := fs.Bool("announce", false, "Register imported posts with the discovery service")
+	dryRun := fs.Bool("dry-run", false, "List what would be imported without publishing")
+
+	args = args[1:]
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	fs.Parse(args)
+	positional = append(positional, fs.Args()...)
+	if len(positional) != 1 {
+		exitError(usage)
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	archive, err := importer.Open(positional[0])
+	if err != nil {
+		exitError("Failed to open archive: %v", err)
+	}
+	defer archive.Close()
+	posts, err := importer.Read(archive, source)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	var privKey []byte
+	if !*dryRun {
+		applyPublishConfig(loadConfig())
+		privKey, err = loadPrivateKey(dir)
+		if err != nil {
+			exitError("Failed to load private key: %v", err)
+		}
+	}
+
+	result, err := importer.Import(dir, posts, importer.Options{Announce: *announce, DryRun: *dryRun}, privKey)
+	if err != nil && (result == nil || len(result.Imported) == 0) {
+		exitError("Import failed: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "import",
+			"data": map[string]interface{}{
+				"source":   source,
+				"dry_run":  *dryRun,
+				"imported": result.Imported,
+				"skipped":  result.Skipped,
+			},
+		})
+	} else {
+		for _, p := range result.Imported {
+			if *dryRun {
+				fmt.Printf("  %s  %s  (%s)\n", p.Published[:10], p.Title, p.From)
+			} else {
+				fmt.Printf("  %s  %s\n", p.Path, p.Title)
+			}
+		}
+		verb := "Imported"
+		if *dryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("[✓] %s %d posts", verb, len(result.Imported))
+		if result.Skipped > 0 {
+			fmt.Printf(" (%d imported before)", result.Skipped)
+		}
+		fmt.Println()
+		if !*dryRun && !*announce && len(result.Imported) > 0 {
+			fmt.Println("[i] Not registered with the discovery service (use --announce to register them)")
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Import stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
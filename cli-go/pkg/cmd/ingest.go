@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/ingest"
+)
+
+func handleIngest(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis ingest mail [file.eml]\n       polis ingest imap [--once] [--interval <duration>]")
+	}
+
+	switch args[0] {
+	case "mail":
+		ingestMail(args[1:])
+	case "imap":
+		ingestIMAP(args[1:])
+	default:
+		exitError("Unknown ingest subcommand. Use: polis ingest [mail|imap]")
+	}
+}
+
+// ingestSetup checks the site and loads what publishing by email needs.
+func ingestSetup() (dir string, cfg *config.Config, allow []string, privKey []byte) {
+	dir = getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	cfg = loadConfig()
+	allow = ingest.ParseAllow(cfg.Get("ingest.allow"))
+	if len(allow) == 0 {
+		exitError("No senders allowed to publish by email (set one with: polis config set ingest.allow you@example.com)")
+	}
+	applyPublishConfig(cfg)
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	return dir, cfg, allow, privKey
+}
+
+// ingestMail publishes one RFC 822 message read from a file or stdin.
+func ingestMail(args []string) {
+	dir, _, allow, privKey := ingestSetup()
+
+	var in io.Reader = os.Stdin
+	if len(args) > 0 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			exitError("Failed to read message: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	msg, err := ingest.Parse(in)
+	if err != nil {
+		exitError("%v", err)
+	}
+	result, err := ingest.Publish(dir, msg, allow, privKey)
+	if err != nil {
+		if result == nil {
+			exitError("Not published: %v", err)
+		}
+		if !jsonOutput {
+			fmt.Fprintf(os.Stderr, "[!] %v\n", err)
+		}
+	}
+	printPublishResult(result)
+	timestampPublished(dir, result)
+	crosspostPublished(dir, result)
+}
+
+// ingestIMAP polls a mailbox and publishes unseen messages from allowed
+// senders, marking them seen. Other messages are left unread.
+func ingestIMAP(args []string) {
+	fs := flag.NewFlagSet("ingest imap", flag.ExitOnError)
+	once := fs.Bool("once", false, "Check the mailbox once and exit")
+	interval := fs.Duration("interval", 5*time.Minute, "Time between checks")
+	fs.Parse(args)
+	if *interval < 30*time.Second {
+		exitError("--interval must be at least 30s")
+	}
+
+	dir, cfg, allow, privKey := ingestSetup()
+	imapCfg := ingest.IMAPConfig{
+		Host:     cfg.Get("ingest.imap_host"),
+		Username: cfg.Get("ingest.imap_user"),
+		Password: cfg.Get("imap_password"),
+		Mailbox:  cfg.Get("ingest.imap_mailbox"),
+	}
+	if err := imapCfg.Validate(); err != nil {
+		exitError("%v", err)
+	}
+
+	// Report each refused message once, not on every check
+	reported := make(map[string]bool)
+	var published []map[string]interface{}
+	check := func() error {
+		return ingest.FetchUnseen(imapCfg, func(raw []byte) bool {
+			msg, err := ingest.Parse(bytes.NewReader(raw))
+			if err != nil {
+				logIngest(reported, string(raw[:min(len(raw), 64)]), "[!] Skipped unreadable message: %v", err)
+				return false
+			}
+			result, err := ingest.Publish(dir, msg, allow, privKey)
+			switch {
+			case result != nil:
+				if err != nil && !jsonOutput {
+					fmt.Fprintf(os.Stderr, "[!] %v\n", err)
+				}
+				if jsonOutput {
+					published = append(published, map[string]interface{}{"from": msg.From, "subject": msg.Subject, "path": result.Path, "version": result.Version})
+				} else {
+					fmt.Printf("[✓] Published %q from %s: %s\n", result.Title, msg.From, result.Path)
+				}
+				timestampPublished(dir, result)
+				crosspostPublished(dir, result)
+				return true
+			case errors.Is(err, ingest.ErrAlreadyPublished):
+				return true
+			default:
+				logIngest(reported, msg.MessageID+msg.From+msg.Subject, "[!] Skipped %q from %s: %v", msg.Subject, msg.From, err)
+				return false
+			}
+		})
+	}
+
+	if *once {
+		if err := check(); err != nil {
+			exitError("IMAP check failed: %v", err)
+		}
+		if jsonOutput {
+			if published == nil {
+				published = []map[string]interface{}{}
+			}
+			outputJSON(map[string]interface{}{
+				"status":  "success",
+				"command": "ingest imap",
+				"data":    map[string]interface{}{"published": published},
+			})
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("[i] Checking %s every %s (Ctrl-C to stop)\n", imapCfg.Host, *interval)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := check(); err != nil {
+			fmt.Fprintf(os.Stderr, "[!] IMAP check failed: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func logIngest(reported map[string]bool, key, format string, args ...interface{}) {
+	if reported[key] || jsonOutput {
+		return
+	}
+	reported[key] = true
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+	"github.com/vdibart/polis-cli/cli-go/pkg/starter"
 )
 
 func handleInit(args []string) {
@@ -19,10 +20,21 @@ func handleInit(args []string) {
 	publicIndex := fs.String("public-index", "", "Custom public index path (default: metadata/public.jsonl)")
 	blessedComments := fs.String("blessed-comments", "", "Custom blessed comments path (default: metadata/blessed-comments.json)")
 	followingIndex := fs.String("following-index", "", "Custom following index path (default: metadata/following.json)")
+	starterSrc := fs.String("starter", "", "Starter package to apply (URL or zip path)")
 	fs.Parse(args)
 
 	dir := getDataDir()
 
+	// Fetch before creating anything so a bad URL leaves no half-made site
+	var starterArchive []byte
+	if *starterSrc != "" {
+		var err error
+		starterArchive, err = starter.Fetch(*starterSrc)
+		if err != nil {
+			exitError("Failed to load starter: %v", err)
+		}
+	}
+
 	opts := site.InitOptions{
 		SiteTitle:       *siteTitle,
 		Version:         Version,
@@ -42,22 +54,43 @@ func handleInit(args []string) {
 		exitError("Failed to initialize site: %v", err)
 	}
 
+	var applied *starter.ApplyResult
+	if starterArchive != nil {
+		applied, err = starter.Apply(dir, starterArchive)
+		if err != nil {
+			exitError("Site initialized, but the starter could not be applied: %v", err)
+		}
+	}
+
 	if jsonOutput {
+		data := map[string]interface{}{
+			"directories_created": result.DirsCreated,
+			"files_created":       result.FilesCreated,
+			"key_paths": map[string]interface{}{
+				"private": result.KeyPaths.Private,
+				"public":  result.KeyPaths.Public,
+			},
+		}
+		if applied != nil {
+			data["starter"] = applied
+		}
 		outputJSON(map[string]interface{}{
 			"status":  "success",
 			"command": "init",
-			"data": map[string]interface{}{
-				"directories_created": result.DirsCreated,
-				"files_created":       result.FilesCreated,
-				"key_paths": map[string]interface{}{
-					"private": result.KeyPaths.Private,
-					"public":  result.KeyPaths.Public,
-				},
-			},
+			"data":    data,
 		})
 	} else {
 		fmt.Printf("[✓] Initialized polis site at: %s\n", result.SiteDir)
 		fmt.Printf("[i] Public key: %s\n", result.PublicKey[:50]+"...")
+		if applied != nil {
+			fmt.Printf("[✓] Applied starter %q (%d files)\n", applied.Manifest.Name, len(applied.FilesCreated))
+			if applied.Manifest.ActiveTheme != "" {
+				fmt.Printf("[i] Theme: %s\n", applied.Manifest.ActiveTheme)
+			}
+			if len(applied.Drafts) > 0 {
+				fmt.Printf("[i] %d sample pages saved as drafts in .polis/posts/drafts\n", len(applied.Drafts))
+			}
+		}
 		fmt.Println("\nNext steps:")
 		fmt.Println("  1. Set POLIS_BASE_URL in .env file")
 		fmt.Println("  2. Create your first post: polis post my-post.md")
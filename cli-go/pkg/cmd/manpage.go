@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// handleGenMan writes troff man pages for polis and every visible command.
+// Hidden from the overview; used by packaging scripts.
+func handleGenMan(args []string) {
+	fs := flag.NewFlagSet("gen-man", flag.ExitOnError)
+	outDir := fs.String("out", "man", "Output directory")
+	fs.Parse(args)
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		exitError("Failed to create output directory: %v", err)
+	}
+
+	pages := map[string]string{"polis.1": renderIndexManPage()}
+	for _, c := range visibleCommands() {
+		pages["polis-"+c.Name+".1"] = renderManPage(c)
+	}
+
+	var written []string
+	for name, page := range pages {
+		path := filepath.Join(*outDir, name)
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			exitError("Failed to write %s: %v", path, err)
+		}
+		written = append(written, path)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "gen-man",
+			"data": map[string]interface{}{
+				"dir":   *outDir,
+				"pages": len(written),
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Wrote %d man pages to %s\n", len(written), *outDir)
+}
+
+// renderIndexManPage renders polis(1): global flags and the command list.
+func renderIndexManPage() string {
+	var b strings.Builder
+	writeManHeader(&b, "POLIS")
+	b.WriteString(".SH NAME\npolis \\- decentralized social network CLI\n")
+	b.WriteString(".SH SYNOPSIS\n.B polis\n[\\fB\\-\\-json\\fR] [\\fB\\-\\-data\\-dir\\fR \\fIpath\\fR] \\fIcommand\\fR [\\fIoptions\\fR]\n")
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString(manEscape("Polis publishes signed markdown posts and comments from a local site directory and exchanges them with other authors through a discovery service.") + "\n")
+	b.WriteString(".SH OPTIONS\n")
+	writeManFlag(&b, Flag{"--json", "", "Output results in JSON format"})
+	writeManFlag(&b, Flag{"--data-dir", "<path>", "Site data directory (default: current directory)"})
+	writeManFlag(&b, Flag{"--set", "<key=value>", "Override a config setting for this run"})
+	writeManFlag(&b, Flag{"--log-level", "<level>", "Console log level: debug, info, warn, error"})
+	b.WriteString(".SH COMMANDS\n")
+	for _, c := range visibleCommands() {
+		b.WriteString(".TP\n.BR polis\\-" + manEscape(c.Name) + " (1)\n" + manEscape(c.Summary()) + "\n")
+	}
+	return b.String()
+}
+
+// renderManPage renders polis-<cmd>(1).
+func renderManPage(c *Command) string {
+	var b strings.Builder
+	writeManHeader(&b, "POLIS-"+strings.ToUpper(c.Name))
+	b.WriteString(".SH NAME\npolis\\-" + manEscape(c.Name) + " \\- " + manEscape(c.Summary()) + "\n")
+
+	b.WriteString(".SH SYNOPSIS\n")
+	for i, u := range c.Usages {
+		if i > 0 {
+			b.WriteString(".br\n")
+		}
+		b.WriteString(".B polis " + manEscape(c.Name) + "\n")
+		if u.Args != "" {
+			b.WriteString(manEscape(u.Args) + "\n")
+		}
+	}
+
+	b.WriteString(".SH DESCRIPTION\n")
+	desc := strings.TrimSpace(c.Description)
+	if desc == "" {
+		desc = c.Summary()
+	}
+	for i, para := range strings.Split(desc, "\n\n") {
+		if i > 0 {
+			b.WriteString(".PP\n")
+		}
+		b.WriteString(manEscape(strings.Join(strings.Fields(para), " ")) + "\n")
+	}
+
+	if len(c.Usages) > 1 {
+		b.WriteString(".SH SUBCOMMANDS\n")
+		for _, u := range c.Usages {
+			b.WriteString(".TP\n.B " + manEscape(strings.TrimSpace(c.Name+" "+u.Args)) + "\n" + manEscape(u.Summary) + "\n")
+		}
+	}
+
+	if len(c.Flags) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, f := range c.Flags {
+			writeManFlag(&b, f)
+		}
+	}
+
+	if len(c.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n.nf\n")
+		for _, e := range c.Examples {
+			b.WriteString(manEscape(e) + "\n")
+		}
+		b.WriteString(".fi\n")
+	}
+
+	b.WriteString(".SH SEE ALSO\n.BR polis (1)\n")
+	return b.String()
+}
+
+func writeManHeader(b *strings.Builder, title string) {
+	fmt.Fprintf(b, ".TH %s 1 \"\" \"polis %s\" \"Polis Manual\"\n", manEscape(title), manEscape(Version))
+}
+
+func writeManFlag(b *strings.Builder, f Flag) {
+	b.WriteString(".TP\n.B " + manEscape(f.Name))
+	if f.Arg != "" {
+		b.WriteString(" \\fI" + manEscape(strings.Trim(f.Arg, "<>")) + "\\fR")
+	}
+	b.WriteString("\n" + manEscape(f.Usage) + "\n")
+}
+
+// manEscape escapes text for troff: backslashes, hyphens, and lines that
+// would otherwise start with a control character.
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/migrate"
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
 )
 
@@ -208,3 +214,145 @@ func collectRelevantDomains(dir string) ([]string, error) {
 	}
 	return domains, nil
 }
+
+// handleMigrateDomain moves the site to a new base URL: it rewrites the
+// site's own records, publishes a signed moved statement, tells the
+// discovery service, and optionally writes redirect stubs for the old host.
+func handleMigrateDomain(args []string) {
+	fs := flag.NewFlagSet("migrate-domain", flag.ExitOnError)
+	stubsDir := fs.String("stubs", "", "Write redirect stubs for the old host to this directory")
+	noDiscovery := fs.Bool("no-discovery", false, "Don't register the move with the discovery service")
+
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	fs.Parse(args)
+	positional = append(positional, fs.Args()...)
+	if len(positional) != 1 {
+		exitError("Usage: polis migrate-domain <new-base-url> [--stubs <dir>] [--no-discovery]")
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	newBaseURL := strings.TrimSuffix(strings.TrimSpace(positional[0]), "/")
+	u, err := url.Parse(newBaseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		exitError("Invalid base URL: %s (expected e.g. https://newsite.example.com)", positional[0])
+	}
+	oldBaseURL := strings.TrimSuffix(siteBaseURL(dir), "/")
+	if oldBaseURL == "" {
+		exitError("The site has no base URL to move from (set base_url first)")
+	}
+	if oldBaseURL == newBaseURL {
+		exitError("The site is already at %s", newBaseURL)
+	}
+
+	privKey, err := loadPrivateKey(dir)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+	if cert, _ := delegation.FromKey(privKey); cert != nil {
+		exitError("This is device %q; move the site on the machine that holds the site key", cert.Device)
+	}
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		exitError("Failed to load .well-known/polis: %v", err)
+	}
+
+	moved, err := migrate.NewMoved(oldBaseURL, newBaseURL, wk.PublicKey, privKey, time.Now())
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	wk.BaseURL = newBaseURL
+	if wk.Domain != "" {
+		wk.Domain = extractDomain(newBaseURL)
+	}
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		exitError("Failed to update .well-known/polis: %v", err)
+	}
+	if err := moved.Write(dir); err != nil {
+		exitError("Failed to write moved statement: %v", err)
+	}
+	rebased, err := migrate.Rebase(dir, oldBaseURL, newBaseURL)
+	if err != nil {
+		exitError("Failed to update metadata: %v", err)
+	}
+	if _, err := config.Set(dir, "base_url", newBaseURL); err != nil {
+		exitError("Failed to set base_url: %v", err)
+	}
+
+	var stubs *migrate.StubsResult
+	if *stubsDir != "" {
+		if stubs, err = migrate.WriteStubs(dir, *stubsDir, moved); err != nil {
+			exitError("Failed to write redirect stubs: %v", err)
+		}
+	}
+
+	// The move stands without the discovery service; followers' clients
+	// also find the moved statement on the old host
+	var discoveryErr error
+	if !*noDiscovery {
+		cfg := loadConfig()
+		client := discovery.NewClient(cfg.Get("discovery_url"), cfg.Get("discovery_key"))
+		discoveryErr = client.RegisterMigration(extractDomain(oldBaseURL), extractDomain(newBaseURL), privKey)
+	}
+
+	if jsonOutput {
+		data := map[string]interface{}{
+			"old_base_url":              oldBaseURL,
+			"new_base_url":              newBaseURL,
+			"files_updated":             rebased,
+			"discovery_registered":      !*noDiscovery && discoveryErr == nil,
+			"moved_statement":           migrate.MovedPath,
+			"devices_need_redelegation": len(wk.Devices) > 0,
+		}
+		if discoveryErr != nil {
+			data["discovery_error"] = discoveryErr.Error()
+		}
+		if stubs != nil {
+			data["stubs"] = stubs
+		}
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "migrate-domain",
+			"data":    data,
+		})
+		return
+	}
+
+	fmt.Printf("[✓] Moved %s -> %s\n", oldBaseURL, newBaseURL)
+	fmt.Printf("[✓] Signed moved statement written to %s\n", migrate.MovedPath)
+	if len(rebased) > 0 {
+		fmt.Printf("[✓] Updated %d metadata file(s)\n", len(rebased))
+	}
+	switch {
+	case *noDiscovery:
+	case discoveryErr != nil:
+		fmt.Printf("[!] Failed to register the move with the discovery service: %v\n", discoveryErr)
+		fmt.Println("    Retry later with: polis migrate " + extractDomain(newBaseURL))
+	default:
+		fmt.Println("[✓] Registered the move with the discovery service")
+	}
+	if stubs != nil {
+		fmt.Printf("[✓] Wrote %d redirect page(s) and redirect rules to %s\n", stubs.Pages, stubs.Dir)
+	}
+	if len(wk.Devices) > 0 {
+		fmt.Println("[!] Device certificates name the old domain. Re-delegate each device")
+		fmt.Println("    with: polis device add <name> <device key>")
+	}
+	fmt.Println()
+	fmt.Println("[i] Next steps:")
+	fmt.Println("  1. polis render --force")
+	fmt.Println("  2. Deploy the site to " + newBaseURL)
+	if stubs != nil {
+		fmt.Println("  3. Deploy " + stubs.Dir + " to " + oldBaseURL)
+	} else {
+		fmt.Println("  3. Keep " + migrate.MovedPath + " published at " + oldBaseURL)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/posttemplate"
+)
+
+// varFlags collects repeated --var key=value flags.
+type varFlags map[string]string
+
+func (v varFlags) String() string { return "" }
+
+func (v varFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	v[key] = value
+	return nil
+}
+
+func handleNew(args []string) {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	name := fs.String("template", "", "Post template to start from")
+	title := fs.String("title", "", "Title of the post")
+	list := fs.Bool("list", false, "List the site's post templates")
+	vars := varFlags{}
+	fs.Var(vars, "var", "Value for a template placeholder (key=value)")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		exitError("Usage: polis new [--template <name>] [--title <title>] [--var key=value]...")
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	if *list {
+		newListTemplates(dir)
+		return
+	}
+
+	content := posttemplate.Blank
+	if *name != "" {
+		t, err := posttemplate.Get(dir, *name)
+		if errors.Is(err, posttemplate.ErrNotFound) {
+			exitError("No post template named %s (templates live in %s/)", *name, posttemplate.Dir)
+		}
+		if err != nil {
+			exitError("%v", err)
+		}
+		content = t.Content
+	}
+	if *title != "" {
+		vars["title"] = *title
+	}
+
+	// Ask for the placeholders not given on the command line, when there is
+	// someone to ask
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 && !jsonOutput {
+		in := bufio.NewReader(os.Stdin)
+		for _, v := range posttemplate.Variables(content) {
+			if _, ok := vars[v]; ok {
+				continue
+			}
+			fmt.Printf("%s: ", v)
+			line, err := in.ReadString('\n')
+			if line = strings.TrimSpace(line); line != "" {
+				vars[v] = line
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	id, err := posttemplate.NewDraft(dir, *name, vars, time.Now())
+	if err != nil {
+		exitError("Failed to create draft: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "new",
+			"data": map[string]interface{}{
+				"id":       id,
+				"template": *name,
+				"path":     ".polis/posts/drafts/" + id + ".md",
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Created draft: %s\n", id)
+	fmt.Printf("Edit at: .polis/posts/drafts/%s.md\n", id)
+	fmt.Printf("Publish with: polis post --draft %s\n", id)
+}
+
+func newListTemplates(dir string) {
+	templates, err := posttemplate.List(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "new",
+			"data":    templates,
+		})
+		return
+	}
+	if len(templates) == 0 {
+		fmt.Printf("[i] No post templates; add markdown files to %s/\n", posttemplate.Dir)
+		return
+	}
+	for _, t := range templates {
+		vars := ""
+		if len(t.Variables) > 0 {
+			vars = " {{" + strings.Join(t.Variables, "}} {{") + "}}"
+		}
+		fmt.Printf("%-20s%s\n", t.Name, vars)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+	"github.com/vdibart/polis-cli/cli-go/pkg/starter"
+)
+
+func handlePack(args []string) {
+	fs := flag.NewFlagSet("pack", flag.ExitOnError)
+	out := fs.String("out", "polis-starter.zip", "Output archive path")
+	name := fs.String("name", "", "Starter name (default: site directory name)")
+	description := fs.String("description", "", "One-line starter description")
+	fs.Parse(args)
+
+	result, err := starter.Pack(getDataDir(), *out, starter.PackOptions{
+		Name:        *name,
+		Description: *description,
+		Generator:   site.GetGenerator(),
+		Pages:       fs.Args(),
+	})
+	if err != nil {
+		exitError("Failed to pack starter: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "pack",
+			"data":    result,
+		})
+		return
+	}
+
+	fmt.Printf("[✓] Packed starter %q: %s\n", result.Manifest.Name, result.Path)
+	if result.Manifest.ActiveTheme != "" {
+		fmt.Printf("[i] Theme: %s\n", result.Manifest.ActiveTheme)
+	}
+	fmt.Printf("[i] %d files (keys, .env, posts, and metadata are never included)\n", len(result.Files))
+	fmt.Printf("\nStart a new site from it with: polis init --starter %s\n", result.Path)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/ipfs"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// ipfsConfig returns the IPFS node from the ipfs.* settings.
+func ipfsConfig(cfg *config.Config) ipfs.Config {
+	return ipfs.Config{
+		API:   cfg.Get("ipfs.api"),
+		Token: cfg.Get("ipfs_api_token"),
+	}
+}
+
+// pinPublished pins a published or republished post to IPFS when a node is
+// configured. Failures are reported but don't fail the publish.
+func pinPublished(dir string, result *publish.PublishResult) {
+	cfg := ipfsConfig(loadConfig())
+	if !cfg.Enabled() || !result.Crosspostable() {
+		return
+	}
+	link, err := ipfs.Pin(dir, cfg, result.Path)
+	if jsonOutput {
+		return
+	}
+	if link != nil {
+		fmt.Printf("[✓] Pinned to IPFS: %s\n", link.URL)
+	}
+	if err != nil && !errors.Is(err, ipfs.ErrNotPublic) {
+		fmt.Fprintf(os.Stderr, "[!] IPFS pin failed: %v (retry with: polis pin %s)\n", err, result.Path)
+	}
+}
+
+// handlePin pins a published post, or with --all every public post whose
+// current version isn't pinned, to IPFS.
+func handlePin(args []string) {
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	all := fs.Bool("all", false, "Pin every public post not pinned at its current version")
+	var postArg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		postArg, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if (postArg == "") == !*all {
+		exitError("Usage: polis pin <posts/YYYYMMDD/post.md>\n       polis pin --all")
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	cfg := ipfsConfig(loadConfig())
+	if !cfg.Enabled() {
+		exitError("No IPFS node configured (set one with: polis config set ipfs.api http://127.0.0.1:5001)")
+	}
+
+	var paths []string
+	if *all {
+		entries, err := metadata.GetPostEntries(dir)
+		if err != nil {
+			exitError("Failed to read public.jsonl: %v", err)
+		}
+		for _, e := range entries {
+			if e.IPFS == "" {
+				paths = append(paths, e.Path)
+			}
+		}
+	} else {
+		postPath := fsutil.SiteRelPath(dir, postArg)
+		paths = []string{postPath}
+	}
+
+	var pinned []map[string]string
+	failed := make(map[string]string)
+	for _, path := range paths {
+		link, err := ipfs.Pin(dir, cfg, path)
+		if link != nil {
+			pinned = append(pinned, map[string]string{"path": path, "cid": link.CID, "url": link.URL})
+			if !jsonOutput {
+				fmt.Printf("[✓] Pinned %s: %s\n", path, link.URL)
+			}
+		}
+		if err != nil {
+			if !*all {
+				if errors.Is(err, os.ErrNotExist) {
+					exitError("No published post at %s", path)
+				}
+				if link == nil {
+					exitError("%v", err)
+				}
+			}
+			failed[path] = err.Error()
+			if !jsonOutput {
+				fmt.Fprintf(os.Stderr, "[!] %s: %v\n", path, err)
+			}
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "pin",
+			"data": map[string]interface{}{
+				"pinned": pinned,
+				"failed": failed,
+			},
+		})
+		return
+	}
+	if *all && len(paths) == 0 {
+		fmt.Println("[i] Every public post is pinned")
+	}
+	if len(pinned) > 0 {
+		fmt.Println("[i] Run polis render to add the ipfs:// link to the pages")
+	}
+}
@@ -3,7 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/dnskey"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 	"github.com/vdibart/polis-cli/cli-go/pkg/verify"
 )
 
@@ -19,7 +23,23 @@ func handlePreview(args []string) {
 		exitError("URL must use HTTPS (e.g., https://example.com/posts/hello.md)")
 	}
 
-	result, err := verify.VerifyContent(contentURL)
+	var result *verify.VerificationResult
+	var err error
+	if strings.Contains(contentURL, "/"+publish.ProtectedDir+"/") {
+		// Followers-only post: sign the fetch as this site
+		dir := getDataDir()
+		privKey, keyErr := loadPrivateKey(dir)
+		if keyErr != nil {
+			exitError("Followers-only posts need your site key to fetch: %v", keyErr)
+		}
+		actor := polisurl.ExtractDomain(os.Getenv("POLIS_BASE_URL"))
+		if actor == "" {
+			exitError("POLIS_BASE_URL not set (needed to sign the fetch)")
+		}
+		result, err = verify.VerifyProtectedContent(contentURL, actor, privKey)
+	} else {
+		result, err = verify.VerifyContent(contentURL)
+	}
 	if err != nil {
 		exitError("Failed to preview: %v", err)
 	}
@@ -39,6 +59,7 @@ func handlePreview(args []string) {
 				"author":            result.Author,
 				"signature":         result.Signature,
 				"hash":              result.Hash,
+				"dns_key":           result.DNSKey,
 				"validation_issues": result.ValidationIssues,
 				"body":              result.Body,
 			},
@@ -79,6 +100,14 @@ func handlePreview(args []string) {
 			fmt.Fprintf(os.Stderr, "[!] Could not verify signature\n")
 		}
 
+		// Key cross-check against DNS
+		switch result.DNSKey.Status {
+		case dnskey.StatusMatch:
+			fmt.Println("[✓] Public key matches DNS")
+		case dnskey.StatusMismatch:
+			fmt.Fprintf(os.Stderr, "[x] Public key does NOT match the key in DNS - the author's site may be compromised\n")
+		}
+
 		// Hash status
 		switch result.Hash.Status {
 		case "valid":
@@ -1,26 +1,49 @@
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
 	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/vault"
 )
 
 func handlePublish(args []string) {
 	fs := flag.NewFlagSet("publish", flag.ExitOnError)
 	filename := fs.String("filename", "", "Custom filename for the post (without .md)")
+	draftID := fs.String("draft", "", "Publish the post draft with this ID")
+	keep := fs.Bool("keep", false, "Keep the source file or draft after publishing")
+	unlisted := fs.Bool("unlisted", false, "Keep the post off index pages, feeds, and discovery")
+	followers := fs.Bool("followers", false, "Publish under protected/, readable only by followers through polis serve")
+	noCrosspost := fs.Bool("no-crosspost", false, "Don't cross-post the post to Bluesky")
+	fromVault := fs.String("from-vault", "", "Publish new and changed notes from a markdown vault folder")
+	dryRun := fs.Bool("dry-run", false, "With --from-vault, show what would be published")
+	as := fs.String("as", "", "Sign as this co-author")
+	var remaining []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		remaining, args = append(remaining, args[0]), args[1:]
+	}
 	fs.Parse(args)
+	remaining = append(remaining, fs.Args()...)
 
-	remaining := fs.Args()
-	if len(remaining) < 1 {
-		exitError("Usage: polis post <file.md> [--filename <name>]")
+	if *fromVault != "" {
+		if len(remaining) > 0 || *draftID != "" || *filename != "" || *keep {
+			exitError("--from-vault can't be combined with a file, --draft, --filename, or --keep")
+		}
+		handlePublishVault(*fromVault, *as, vault.Options{DryRun: *dryRun, Unlisted: *unlisted, FollowersOnly: *followers})
+		return
+	}
+
+	if len(remaining) < 1 && *draftID == "" {
+		exitError("Usage: polis post <file.md> [--filename <name>] [--unlisted | --followers] [--keep] [--no-crosspost]\n       polis post --draft <id> [--filename <name>] [--unlisted | --followers] [--keep] [--no-crosspost]\n       polis post --from-vault <dir> [--dry-run] [--unlisted | --followers]")
 	}
 
-	inputFile := remaining[0]
 	dir := getDataDir()
 
 	// Verify it's a polis site
@@ -28,6 +51,15 @@ func handlePublish(args []string) {
 		exitError("Not a polis site directory (no .well-known/polis found)")
 	}
 
+	// Directory layout for new posts
+	applyPublishConfig(loadConfig())
+
+	if *draftID != "" {
+		handlePublishDraft(dir, *draftID, *as, publish.PublishOptions{Slug: *filename, Unlisted: *unlisted, FollowersOnly: *followers, NoCrosspost: *noCrosspost}, *keep)
+		return
+	}
+	inputFile := remaining[0]
+
 	// Read the input file
 	content, err := os.ReadFile(inputFile)
 	if err != nil {
@@ -35,19 +67,27 @@ func handlePublish(args []string) {
 	}
 
 	// Load private key
-	privKey, err := loadPrivateKey(dir)
+	privKey, err := loadSigningKey(dir, *as)
 	if err != nil {
 		exitError("Failed to load private key: %v", err)
 	}
 
-	// Strip frontmatter if present
+	// Strip frontmatter if present (slug, summary, lang, translation_of,
+	// unlisted, and visibility: followers carry over)
 	markdown := string(content)
+	opts := publish.SourceOptions(markdown)
+	if *filename != "" {
+		opts.Slug = *filename
+	}
+	opts.Unlisted = opts.Unlisted || *unlisted
+	opts.FollowersOnly = opts.FollowersOnly || *followers
+	opts.NoCrosspost = opts.NoCrosspost || *noCrosspost
 	if publish.HasFrontmatter(markdown) {
 		markdown = publish.StripFrontmatter(markdown)
 	}
 
 	// Publish the post
-	result, err := publish.PublishPost(dir, markdown, *filename, privKey)
+	result, err := publish.PublishPostWithOptions(dir, markdown, opts, privKey)
 	if err != nil {
 		exitError("Failed to publish: %v", err)
 	}
@@ -55,7 +95,7 @@ func handlePublish(args []string) {
 	// Remove original file if not already in posts/ (matches bash CLI behavior)
 	inputAbs, err1 := filepath.Abs(inputFile)
 	postAbs, err2 := filepath.Abs(filepath.Join(dir, result.Path))
-	if err1 == nil && err2 == nil && inputAbs != postAbs {
+	if err1 == nil && err2 == nil && inputAbs != postAbs && !*keep {
 		if err := os.Remove(inputAbs); err != nil {
 			if !jsonOutput {
 				fmt.Fprintf(os.Stderr, "[!] Could not remove original file: %v\n", err)
@@ -65,45 +105,192 @@ func handlePublish(args []string) {
 		}
 	}
 
+	printPublishResult(result)
+	timestampPublished(dir, result)
+	crosspostPublished(dir, result)
+}
+
+// handlePublishDraft publishes a post draft, signed as co-author as (or the
+// default signer when empty), removing it afterwards unless keep is set.
+// override replaces options set in the draft's frontmatter.
+func handlePublishDraft(dir, draftID, as string, override publish.PublishOptions, keep bool) {
+	privKey, err := loadSigningKey(dir, as)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+
+	result, err := publish.PublishDraftWithOptions(dir, draftID, "", keep, override, privKey)
+	if err != nil {
+		if result == nil {
+			if errors.Is(err, publish.ErrDraftNotFound) {
+				exitError("No draft with ID %s", draftID)
+			}
+			exitError("Failed to publish: %v", err)
+		}
+		if !jsonOutput {
+			fmt.Fprintf(os.Stderr, "[!] %v\n", err)
+		}
+	} else if !keep && !jsonOutput {
+		fmt.Printf("[✓] Removed draft %s\n", draftID)
+	}
+
+	printPublishResult(result)
+	timestampPublished(dir, result)
+	crosspostPublished(dir, result)
+}
+
+// handlePublishVault publishes new and changed notes from a vault folder.
+func handlePublishVault(vaultDir, as string, opts vault.Options) {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	applyPublishConfig(loadConfig())
+
+	privKey, err := loadSigningKey(dir, as)
+	if err != nil {
+		exitError("Failed to load private key: %v", err)
+	}
+
+	result, err := vault.Sync(dir, vaultDir, opts, privKey)
+	if err != nil {
+		exitError("Failed to publish from vault: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "post",
+			"data":    result,
+		})
+		return
+	}
+
+	verb, reverb := "Published", "Republished"
+	if result.DryRun {
+		verb, reverb = "Would publish", "Would republish"
+	}
+	for _, c := range result.Published {
+		fmt.Printf("[✓] %s %s", verb, c.Source)
+		if c.Post != "" {
+			fmt.Printf(" -> %s", c.Post)
+		}
+		fmt.Println()
+	}
+	for _, c := range result.Republished {
+		fmt.Printf("[✓] %s %s -> %s\n", reverb, c.Source, c.Post)
+	}
+	for _, c := range result.Failed {
+		fmt.Fprintf(os.Stderr, "[x] %s: %s\n", c.Source, c.Error)
+	}
+	for _, c := range result.Missing {
+		fmt.Printf("[!] %s is no longer in the vault; %s was left published\n", c.Source, c.Post)
+	}
+	fmt.Printf("%d published, %d republished, %d unchanged, %d skipped, %d attachments copied\n",
+		len(result.Published), len(result.Republished), result.Unchanged, len(result.Skipped), len(result.Attachments))
+	if len(result.Failed) > 0 {
+		exit(1)
+	}
+}
+
+func printPublishResult(result *publish.PublishResult) {
 	if jsonOutput {
 		outputJSON(map[string]interface{}{
-			"success":   result.Success,
-			"path":      result.Path,
-			"title":     result.Title,
-			"version":   result.Version,
-			"signature": result.Signature,
+			"success":          result.Success,
+			"path":             result.Path,
+			"title":            result.Title,
+			"version":          result.Version,
+			"signature":        result.Signature,
+			"unlisted":         result.Unlisted,
+			"followers_only":   result.FollowersOnly,
+			"unresolved_links": result.UnresolvedLinks,
 		})
 	} else {
 		fmt.Printf("Published: %s\n", result.Path)
 		fmt.Printf("Title: %s\n", result.Title)
 		fmt.Printf("Version: %s\n", result.Version)
+		if result.FollowersOnly {
+			fmt.Println("[i] Followers-only: not rendered or deployed; polis serve hands it to followers who sign the request")
+		} else if result.Unlisted {
+			fmt.Println("[i] Unlisted: left out of public.jsonl, index pages, feeds, and discovery")
+		}
+		printUnresolvedLinks(result.UnresolvedLinks)
+	}
+}
+
+// printUnresolvedLinks warns about [[wiki links]] that name no published post.
+func printUnresolvedLinks(targets []string) {
+	for _, t := range targets {
+		fmt.Printf("[!] No published post matches [[%s]]; it renders as plain text until one does\n", t)
+	}
+}
+
+// handleUnpublish turns a published post back into a draft.
+func handleUnpublish(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis unpublish <posts/YYYYMMDD/post.md>")
+	}
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	postPath := fsutil.SiteRelPath(dir, args[0])
+	if !strings.HasPrefix(postPath, "posts/") {
+		exitError("Post path must be under posts/ directory")
+	}
+
+	result, err := publish.UnpublishToDraft(dir, postPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			exitError("Post not found: %s", postPath)
+		}
+		exitError("Failed to unpublish: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "unpublish",
+			"data":    result,
+		})
+		return
 	}
+	fmt.Printf("[✓] Unpublished %s\n", result.Path)
+	fmt.Printf("[✓] Draft saved as .polis/posts/drafts/%s.md\n", result.DraftID)
+	fmt.Printf("[i] The signed post is in the trash (ID %s)\n", result.TrashID)
+	fmt.Println("[i] Run 'polis render' to update the index pages and feeds")
 }
 
 func handleRepublish(args []string) {
 	fs := flag.NewFlagSet("republish", flag.ExitOnError)
+	as := fs.String("as", "", "Sign as this co-author")
+	var remaining []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		remaining, args = append(remaining, args[0]), args[1:]
+	}
 	fs.Parse(args)
-
-	remaining := fs.Args()
+	remaining = append(remaining, fs.Args()...)
 	if len(remaining) < 1 {
-		exitError("Usage: polis republish <posts/YYYYMMDD/post.md> [new-content.md]")
+		exitError("Usage: polis republish <posts/YYYYMMDD/post.md> [new-content.md] [--as <author>]")
 	}
 
-	postPath := remaining[0]
 	dir := getDataDir()
 
 	// Verify it's a polis site
 	if !isPolisSite(dir) {
 		exitError("Not a polis site directory (no .well-known/polis found)")
 	}
+	postPath := fsutil.SiteRelPath(dir, remaining[0])
 
 	// Validate the post path
-	if !strings.HasPrefix(postPath, "posts/") {
-		exitError("Post path must be under posts/ directory")
+	if !strings.HasPrefix(postPath, "posts/") && !publish.IsProtectedPath(postPath) {
+		exitError("Post path must be under posts/ or protected/posts/ directory")
 	}
+	applyPublishConfig(loadConfig())
 
 	// Load private key
-	privKey, err := loadPrivateKey(dir)
+	privKey, err := loadSigningKey(dir, *as)
 	if err != nil {
 		exitError("Failed to load private key: %v", err)
 	}
@@ -140,20 +327,26 @@ func handleRepublish(args []string) {
 
 	if jsonOutput {
 		outputJSON(map[string]interface{}{
-			"success":   result.Success,
-			"path":      result.Path,
-			"title":     result.Title,
-			"version":   result.Version,
-			"signature": result.Signature,
+			"success":          result.Success,
+			"path":             result.Path,
+			"title":            result.Title,
+			"version":          result.Version,
+			"signature":        result.Signature,
+			"unresolved_links": result.UnresolvedLinks,
 		})
 	} else {
 		fmt.Printf("Republished: %s\n", result.Path)
 		fmt.Printf("Title: %s\n", result.Title)
 		fmt.Printf("Version: %s\n", result.Version)
+		printUnresolvedLinks(result.UnresolvedLinks)
 	}
+	timestampPublished(dir, result)
+	mirrorPublished(dir, result, true)
+	pinPublished(dir, result)
 }
 
+// loadPrivateKey returns the site's signing key: the key file, or with
+// signing_backend=agent a reference to the key in ssh-agent.
 func loadPrivateKey(dir string) ([]byte, error) {
-	privKeyPath := filepath.Join(dir, ".polis", "keys", "id_ed25519")
-	return os.ReadFile(privKeyPath)
+	return signing.LoadKey(filepath.Join(dir, ".polis", "keys"), loadConfig().Get("signing_backend"))
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/reconcile"
+)
+
+func handleReconcile(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "Fix repairable divergence")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+
+	opts := reconcile.Options{
+		DataDir:      dir,
+		BaseURL:      baseURL,
+		DiscoveryURL: discoveryURL,
+		DiscoveryKey: discoveryKey,
+		Repair:       *repair,
+	}
+	// The key also authenticates discovery queries, so load it when present
+	privKey, err := loadPrivateKey(dir)
+	if err == nil {
+		opts.PrivateKey = privKey
+	} else if *repair {
+		exitError("Failed to load private key: %v", err)
+	}
+
+	report, err := reconcile.Run(opts)
+	if err != nil {
+		exitError("Reconcile failed: %v", err)
+	}
+	if err := reconcile.SaveReport(dir, report); err != nil && !jsonOutput {
+		fmt.Printf("[!] Failed to save report: %v\n", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "reconcile",
+			"data":    report,
+		})
+		return
+	}
+
+	fmt.Printf("[i] Checked %d blessings, %d comments, %d follows\n",
+		report.Checked.Blessings, report.Checked.Comments, report.Checked.Follows)
+	if len(report.Divergences) == 0 {
+		fmt.Println("[✓] Local state matches the discovery service")
+	}
+	for _, d := range report.Divergences {
+		mark := "[!]"
+		note := ""
+		switch {
+		case d.Repaired:
+			mark = "[✓]"
+			note = " (repaired)"
+		case d.Error != "":
+			note = " (repair failed: " + d.Error + ")"
+		case !d.Repairable:
+			note = " (manual review)"
+		}
+		fmt.Printf("%s %s: %s local=%s remote=%s%s\n", mark, d.Kind, d.URL, d.Local, d.Remote, note)
+	}
+	for _, e := range report.Errors {
+		fmt.Printf("[!] %s\n", e)
+	}
+	if !*repair && len(report.Divergences) > 0 {
+		fmt.Println("[i] Run 'polis reconcile --repair' to fix repairable divergence")
+	}
+}
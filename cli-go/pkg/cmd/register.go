@@ -75,7 +75,7 @@ func handleSiteRegister(client *discovery.Client, dir, domain string, privKey []
 			}
 			fmt.Fprintf(os.Stderr, "[x] Failed to register: could not reach .well-known/polis on %s\n", domain)
 			fmt.Fprintf(os.Stderr, "[i] Is your site deployed? Registration requires your site to be publicly accessible.\n")
-			os.Exit(1)
+			exit(1)
 		}
 		exitError("Failed to register site: %v", err)
 	}
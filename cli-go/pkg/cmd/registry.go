@@ -0,0 +1,1506 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Flag documents one command-line option.
+type Flag struct {
+	Name  string // Including dashes, e.g. "--dry-run" or "-d, --data-dir"
+	Arg   string // Placeholder for the value, e.g. "<path>"; empty for booleans
+	Usage string
+}
+
+// Usage is one line of a command's synopsis in the overview.
+type Usage struct {
+	Args    string // Arguments after the command name, e.g. "<file>" or "grant <hash>"
+	Summary string
+}
+
+// Command is a declarative description of a CLI command. The overview,
+// `polis help <cmd>`, and generated man pages are all built from it.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Group       string
+	Usages      []Usage // Overview lines; the first is the primary synopsis
+	Description string  // Long-form help, plain text paragraphs
+	Flags       []Flag
+	ShowFlags   bool // List flags under the command in the overview
+	Examples    []string
+	Hidden      bool // Omitted from the overview and man page index
+	Run         func(args []string)
+}
+
+// Summary returns the command's one-line description.
+func (c *Command) Summary() string {
+	if len(c.Usages) == 0 {
+		return ""
+	}
+	return c.Usages[0].Summary
+}
+
+// Command groups, in overview order.
+const (
+	groupContent   = "Commands related to creating or viewing content:"
+	groupBlessing  = "Commands related to requesting, reviewing, or granting blessings:"
+	groupFollowing = "Commands related to following or unfollowing an author:"
+	groupDiscovery = "Commands related to content discovery:"
+	groupNotify    = "Commands related to notifications:"
+	groupAdmin     = "Commands related to site administration:"
+	groupClone     = "Commands related to cloning remote polis sites:"
+	groupLocal     = "Commands related to local configuration:"
+)
+
+var groupOrder = []string{
+	groupContent, groupBlessing, groupFollowing, groupDiscovery,
+	groupNotify, groupAdmin, groupClone, groupLocal,
+}
+
+// commands is the command registry, populated in init to avoid an
+// initialization cycle (help and gen-man read the registry).
+var commands []*Command
+
+// findCommand returns the command registered under name or one of its aliases.
+func findCommand(name string) *Command {
+	for _, c := range commands {
+		if c.Name == name {
+			return c
+		}
+		for _, a := range c.Aliases {
+			if a == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// visibleCommands returns non-hidden commands sorted by name.
+func visibleCommands() []*Command {
+	var out []*Command
+	for _, c := range commands {
+		if !c.Hidden {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// isHelpArg reports whether arg requests help.
+func isHelpArg(arg string) bool {
+	return arg == "--help" || arg == "-h"
+}
+
+func printUsage() {
+	var b strings.Builder
+	b.WriteString(`Polis - Decentralized Social Network CLI
+
+Usage:
+  polis [--json] <command> [options]
+
+Global Flags:
+  --json                          Output results in JSON format
+  --data-dir <path|sftp://...>    Site data directory (default: $POLIS_DATA_DIR, or
+                                  the current directory)
+  --set <key=value>               Override a config setting for this run
+  --log-level <level>             Console log level: debug, info, warn, error
+`)
+
+	for _, group := range groupOrder {
+		b.WriteString("\n" + group + "\n")
+		for _, c := range commands {
+			if c.Group != group || c.Hidden {
+				continue
+			}
+			for _, u := range c.Usages {
+				writeUsageLine(&b, "  ", strings.TrimSpace("polis "+c.Name+" "+u.Args), u.Summary)
+			}
+			if c.ShowFlags {
+				for _, f := range c.Flags {
+					writeUsageLine(&b, "    ", strings.TrimSpace(f.Name+" "+f.Arg), f.Usage)
+				}
+			}
+		}
+	}
+
+	b.WriteString(`
+Run 'polis help <command>' for details on a command.
+
+Examples:
+  polis init
+  polis post my-post.md
+  polis comment draft https://example.com/posts/hello.md
+  polis preview https://example.com/posts/hello.md
+  polis blessing requests
+  polis discover
+`)
+	fmt.Print(b.String())
+}
+
+// writeUsageLine writes "indent text  summary", aligning summaries at
+// column 34 and wrapping to the next line when text is too long.
+func writeUsageLine(b *strings.Builder, indent, text, summary string) {
+	const column = 34
+	line := indent + text
+	if len(line) >= column-1 {
+		b.WriteString(line + "\n" + strings.Repeat(" ", column) + summary + "\n")
+		return
+	}
+	b.WriteString(line + strings.Repeat(" ", column-len(line)) + summary + "\n")
+}
+
+// printCommandHelp prints the long-form help for a command.
+func printCommandHelp(c *Command) {
+	var b strings.Builder
+	b.WriteString("Usage:\n")
+	for _, u := range c.Usages {
+		b.WriteString("  " + strings.TrimSpace("polis "+c.Name+" "+u.Args) + "\n")
+	}
+	if c.Description != "" {
+		b.WriteString("\n" + strings.TrimSpace(c.Description) + "\n")
+	} else if s := c.Summary(); s != "" {
+		b.WriteString("\n" + s + "\n")
+	}
+	if len(c.Usages) > 1 {
+		b.WriteString("\nSubcommands:\n")
+		for _, u := range c.Usages {
+			writeUsageLine(&b, "  ", strings.TrimSpace(c.Name+" "+u.Args), u.Summary)
+		}
+	}
+	if len(c.Flags) > 0 {
+		b.WriteString("\nOptions:\n")
+		for _, f := range c.Flags {
+			writeUsageLine(&b, "  ", strings.TrimSpace(f.Name+" "+f.Arg), f.Usage)
+		}
+	}
+	if len(c.Aliases) > 0 {
+		b.WriteString("\nAliases: " + strings.Join(c.Aliases, ", ") + "\n")
+	}
+	if len(c.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, e := range c.Examples {
+			b.WriteString("  " + e + "\n")
+		}
+	}
+	fmt.Print(b.String())
+}
+
+func handleHelp(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		return
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, "Run 'polis help' for a list of commands.")
+		exit(1)
+	}
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "help",
+			"data":    commandJSON(c),
+		})
+		return
+	}
+	printCommandHelp(c)
+}
+
+// commandJSON is the machine-readable form of a command's help.
+func commandJSON(c *Command) map[string]interface{} {
+	usages := make([]map[string]string, len(c.Usages))
+	for i, u := range c.Usages {
+		usages[i] = map[string]string{
+			"synopsis": strings.TrimSpace("polis " + c.Name + " " + u.Args),
+			"summary":  u.Summary,
+		}
+	}
+	flags := make([]map[string]string, len(c.Flags))
+	for i, f := range c.Flags {
+		flags[i] = map[string]string{"name": f.Name, "arg": f.Arg, "usage": f.Usage}
+	}
+	return map[string]interface{}{
+		"name":        c.Name,
+		"aliases":     c.Aliases,
+		"usages":      usages,
+		"description": strings.TrimSpace(c.Description),
+		"flags":       flags,
+		"examples":    c.Examples,
+	}
+}
+
+func handleVersionCommand(args []string) {
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "version",
+			"data": map[string]interface{}{
+				"version": Version,
+			},
+		})
+	} else {
+		fmt.Printf("polis %s\n", Version)
+	}
+}
+
+func handleMigrations(args []string) {
+	if len(args) > 0 && args[0] == "apply" {
+		handleMigrationsApply(args[1:])
+	} else {
+		exitError("Unknown migrations subcommand. Use: polis migrations apply")
+	}
+}
+
+func init() {
+	commands = []*Command{
+		// Content
+		{
+			Name:    "post",
+			Aliases: []string{"publish"},
+			Group:   groupContent,
+			Usages: []Usage{
+				{"<file>", "Create a new post"},
+				{"--from-vault <dir> [--dry-run]", "Publish new and changed notes from a markdown vault"},
+			},
+			Description: `Sign and publish a markdown file as a new post. The post is written to
+posts/YYYYMMDD/<slug>.md with signed frontmatter, added to
+metadata/public.jsonl, and registered with the discovery service when one
+is configured. The filename comes from --filename, a slug: frontmatter field,
+or the title; a taken name gets -2, -3, ... appended. The post_path setting
+picks the directory layout. The source file, or the draft given with --draft,
+is removed once the post is written unless --keep is passed. An unlisted post
+(--unlisted or unlisted: true in frontmatter) is signed and rendered but kept
+out of public.jsonl, index pages, feeds, and discovery. A followers-only post
+(--followers or visibility: followers) is written to protected/posts/ instead,
+is never rendered or deployed, and is served by polis serve only to followers
+who sign the request. When a Bluesky account is configured (bluesky.handle),
+a new public post is also cross-posted there unless --no-crosspost is passed
+or its frontmatter has crosspost: false; see polis crosspost.
+
+With --from-vault, a folder of notes kept outside the site (such as an
+Obsidian vault) is the source of truth. Each note gets a polis_id field in
+its frontmatter on first publish; later runs republish only the notes that
+changed, even if they were renamed or moved. Obsidian [[Note]] links become
+wiki links to the note's post, embedded and linked attachments are copied
+into attachments/, and notes with publish: false are skipped. State is kept
+in .polis/vault.json.`,
+			Flags: []Flag{
+				{"--filename", "<name>", "Custom filename for the post (without .md)"},
+				{"--draft", "<id>", "Publish a draft from .polis/posts/drafts"},
+				{"--unlisted", "", "Keep the post off index pages, feeds, and discovery"},
+				{"--followers", "", "Publish under protected/, readable only by followers"},
+				{"--keep", "", "Keep the source file or draft after publishing"},
+				{"--no-crosspost", "", "Don't cross-post the post to Bluesky"},
+				{"--from-vault", "<dir>", "Sync notes from a markdown vault folder"},
+				{"--dry-run", "", "With --from-vault, show what would be published"},
+				{"--as", "<author>", "Sign as a co-author of the site (default: sign_as, else the site key)"},
+			},
+			Examples: []string{
+				"polis post my-post.md",
+				"polis post draft.md --filename hello-world",
+				"polis post --draft idea",
+				"polis post notes.md --unlisted",
+				"polis post circle.md --followers",
+				"polis publish --from-vault ~/Obsidian/Blog --dry-run",
+			},
+			Run: handlePublish,
+		},
+		{
+			Name:  "new",
+			Group: groupContent,
+			Usages: []Usage{
+				{"[--template <name>] [--title <title>] [--var key=value]...", "Create a post draft, from a template if given"},
+				{"--list", "List the site's post templates"},
+			},
+			Description: `Start a post draft in .polis/posts/drafts. Post templates are markdown
+skeletons (frontmatter and a body scaffold) kept in .polis/templates/posts/
+as <name>.md. Their placeholders are filled in when the draft is made:
+{{date}} and {{datetime}} with the current time, {{title}} from --title, and
+any other {{name}} from --var name=value. Placeholders without a value are
+asked for when run in a terminal, and otherwise left in the draft. Without
+--template the draft has just a title. Publish it with polis post --draft.`,
+			Flags: []Flag{
+				{"--template", "<name>", "Post template to start from"},
+				{"--title", "<title>", "Title of the post ({{title}})"},
+				{"--var", "<key=value>", "Value for a template placeholder (repeatable)"},
+				{"--list", "", "List the site's post templates and their placeholders"},
+			},
+			Examples: []string{
+				"polis new --template review --title \"Dune\"",
+				"polis new --template weekly --var week=12",
+				"polis new --list",
+			},
+			Run: handleNew,
+		},
+		{
+			Name:  "ingest",
+			Group: groupContent,
+			Usages: []Usage{
+				{"mail [file.eml]", "Publish an emailed post read from a file or stdin"},
+				{"imap [--once] [--interval DURATION]", "Poll a mailbox and publish new emailed posts"},
+			},
+			Description: `Blog by email. Messages from the addresses in the ingest.allow setting
+are published as posts: the subject becomes the title, HTML mail is
+converted to markdown, and images are stored in attachments/. A plain-text
+message may start with frontmatter (slug, unlisted, ...). Anything after a
+"-- " signature line is dropped. Other senders, and messages the receiving
+server marked as failing DMARC or SPF, are refused; a message is never
+published twice.
+
+ingest mail reads one RFC 822 message, for use in a mail server delivery
+rule, procmail, or .forward pipe. ingest imap polls the ingest.imap_mailbox
+of ingest.imap_user on ingest.imap_host over TLS (password: IMAP_PASSWORD
+in .env), marks published messages as read, and leaves others unread.`,
+			Flags: []Flag{
+				{"--once", "", "With imap, check the mailbox once and exit"},
+				{"--interval", "<duration>", "With imap, time between checks (default: 5m)"},
+			},
+			Examples: []string{
+				"polis config set ingest.allow me@example.com",
+				"polis ingest mail < message.eml",
+				"polis ingest imap --interval 10m",
+			},
+			Run: handleIngest,
+		},
+		{
+			Name:  "import",
+			Group: groupContent,
+			Usages: []Usage{
+				{"tweets <archive.zip> [--announce] [--dry-run]", "Import the tweets in a Twitter/X archive"},
+				{"mastodon <archive> [--announce] [--dry-run]", "Import the posts in a Mastodon archive"},
+			},
+			Description: `Convert the posts in a Twitter/X or Mastodon account export into polis
+posts, dated when they were first posted. A thread of replies to yourself
+becomes one post; replies to others, reposts, and posts that weren't
+public are left out. Photos and other media in the archive are stored in
+attachments/. A Mastodon archive may be the .tar.gz, a .zip, or the
+extracted directory.
+
+Each imported post names its original in the imported_from frontmatter
+field. Imported posts are not registered with the discovery service unless
+--announce is given, so years of old posts aren't announced as new, and
+they are never cross-posted. Originals are recorded in .polis/import.json:
+importing the same archive again only adds posts not imported before.`,
+			Flags: []Flag{
+				{"--announce", "", "Register imported posts with the discovery service"},
+				{"--dry-run", "", "List what would be imported without publishing"},
+			},
+			Examples: []string{
+				"polis import tweets twitter-2023-01-01.zip --dry-run",
+				"polis import mastodon archive-20240101.tar.gz",
+			},
+			Run: handleImport,
+		},
+		{
+			Name:  "crosspost",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<post>", "Cross-post a published post to Bluesky"},
+			},
+			Description: `Post a link to a published post on Bluesky: the title, the summary, and
+the post's URL, with a link card. polis post, polis ingest, and the webapp
+do this automatically for new public posts once bluesky.handle and
+BLUESKY_APP_PASSWORD (an app password from Bluesky's settings, kept in .env)
+are set; use this command for posts published before that, or to retry a
+failed cross-post. Unlisted and followers-only posts are never cross-posted.
+The link is recorded in metadata/syndication.json, and a post is cross-posted
+only once. bluesky.pds points at a self-hosted AT Protocol server.`,
+			Examples: []string{
+				"polis config set bluesky.handle alice.bsky.social",
+				"polis config set bluesky_app_password xxxx-xxxx-xxxx-xxxx",
+				"polis crosspost posts/20260102/hello.md",
+			},
+			Run: handleCrosspost,
+		},
+		{
+			Name:  "bridge",
+			Group: groupContent,
+			Usages: []Usage{
+				{"nostr enable [--relays <urls>]", "Mirror new public posts to nostr relays"},
+				{"nostr disable", "Stop mirroring (events already sent stay on the relays)"},
+				{"nostr status", "Show the nostr identity, relays, and mirrored posts"},
+				{"nostr sync [--all]", "Update mirrors of republished posts (--all: mirror every public post)"},
+				{"nostr publish <post>", "Mirror one published post"},
+			},
+			Description: `Experimental: mirror published posts to nostr as long-form articles
+(NIP-23, kind 30023), so nostr readers can follow the site. Events are signed
+with a nostr key derived from the site's key, so there is no new key to keep;
+'polis bridge nostr status' shows its npub. Once enabled, polis post and the
+webapp mirror each new public post, and republishing a mirrored post replaces
+its article on the relays. Unlisted and followers-only posts, and posts
+published with --no-crosspost, are never mirrored. Mirrors are recorded in
+metadata/syndication.json. Relays come from nostr.relays.`,
+			Flags: []Flag{
+				{"--relays", "<urls>", "Comma-separated relay URLs (enable)"},
+				{"--all", "", "Also mirror public posts never mirrored (sync)"},
+			},
+			Examples: []string{
+				"polis bridge nostr enable",
+				"polis bridge nostr enable --relays wss://relay.damus.io,wss://nos.lol",
+				"polis bridge nostr sync --all",
+			},
+			Run: handleBridge,
+		},
+		{
+			Name:  "pin",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<post>", "Pin a published post and its page to IPFS"},
+				{"--all", "Pin every public post not pinned at its current version"},
+			},
+			Description: `Add a published post and its rendered HTML page to an IPFS node, giving
+the post an immutable copy. Once ipfs.api is set (a local Kubo node at
+http://127.0.0.1:5001, or a remote node or pinning service with
+IPFS_API_TOKEN in .env), polis post, polis republish, and the webapp pin each
+public post automatically. The CID is recorded in metadata/syndication.json
+and in the post's public.jsonl entry, and polis render adds an ipfs:// link
+to the page. Republishing pins the new version under a new CID. Unlisted and
+followers-only posts, and posts published with --no-crosspost, are never
+pinned.`,
+			Flags: []Flag{
+				{"--all", "", "Pin every public post not pinned at its current version"},
+			},
+			Examples: []string{
+				"polis config set ipfs.api http://127.0.0.1:5001",
+				"polis render && polis pin posts/20260102/hello.md",
+				"polis pin --all",
+			},
+			Run: handlePin,
+		},
+		{
+			Name:  "timestamp",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<post>", "Timestamp a post's current version now"},
+				{"verify <post> [--ca-file <pem>]", "Check every stored proof of a post"},
+			},
+			Description: `Anchor a post's version hash with an RFC 3161 time-stamping authority, so
+anyone can check when that version existed without trusting your clock.
+Once timestamp.tsa is set (e.g. https://freetsa.org/tsr), polis post,
+polis republish, and the webapp timestamp each new version automatically.
+Only the hash is sent, so posts of every visibility are timestamped. Proofs
+are kept as standard .tsr files under .polis/proofs/, one per version, and
+can also be checked with openssl ts -verify. verify recomputes the current
+version's hash from the post, checks that older proofs belong to versions in
+its history, and checks each TSA signature; --ca-file supplies the TSA's root
+certificate when it isn't in the system store.`,
+			Flags: []Flag{
+				{"--ca-file", "<pem>", "Root certificate of the TSA (verify)"},
+			},
+			Examples: []string{
+				"polis config set timestamp.tsa https://freetsa.org/tsr",
+				"polis timestamp posts/20260102/hello.md",
+				"polis timestamp verify posts/20260102/hello.md --ca-file cacert.pem",
+			},
+			Run: handleTimestamp,
+		},
+		{
+			Name:  "comment",
+			Group: groupContent,
+			Usages: []Usage{
+				{"draft <url>", "Create a comment draft replying to <url>"},
+				{"sign <id> [--as <author>]", "Sign a draft comment (moves to pending)"},
+				{"list [status]", "List comments (drafts, pending, blessed, denied)"},
+				{"sync", "Sync pending comments with discovery service"},
+				{"retract <id>", "Withdraw a signed comment and ask the post's author to drop it"},
+			},
+			Description: `Write comments on other authors' posts. A signed comment stays pending
+until the post's author blesses it; 'polis comment sync' picks up blessing
+decisions from the discovery service. 'polis comment retract' removes a
+comment from your site and publishes a signed retraction, which the post's
+author honors automatically.`,
+			Examples: []string{
+				"polis comment draft https://alice.polis.pub/posts/20260201/hello.md",
+				"polis comment sign abc123",
+				"polis comment list drafts",
+				"polis comment sync",
+				"polis comment retract abc123",
+			},
+			Run: handleComment,
+		},
+		{
+			Name:  "republish",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<file> [new-content.md]", "Update an already-published file"},
+			},
+			Description: `Re-sign an existing post after editing it. The previous version is kept in
+the post's version history so it can be reconstructed with 'polis extract'.`,
+			Flags: []Flag{
+				{"--as", "<author>", "Sign as a co-author of the site (default: sign_as, else the site key)"},
+			},
+			Examples: []string{"polis republish posts/20260125/hello.md"},
+			Run:      handleRepublish,
+		},
+		{
+			Name:  "unpublish",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<file>", "Turn a published post back into a draft"},
+			},
+			Description: `Take a post down and reopen it for editing. The body is saved as a draft in
+.polis/posts/drafts, and the signed post, its HTML, and its version history
+move to the trash (see 'polis trash'). Run 'polis render' afterwards to update
+index pages and feeds; publish the draft again with 'polis post --draft'.`,
+			Examples: []string{"polis unpublish posts/20260125/hello.md"},
+			Run:      handleUnpublish,
+		},
+		{
+			Name:  "preview",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<url>", "Preview a post or comment with signature verification"},
+			},
+			Description: `Fetch a remote post or comment, verify its signature against the author's
+public key, and print it. URLs under /protected/ (followers-only posts) are
+fetched with a request signed by your site key, as POLIS_BASE_URL's domain.`,
+			Examples: []string{
+				"polis preview https://example.com/posts/hello.md",
+				"polis preview https://example.com/protected/posts/20260301/circle.md",
+			},
+			Run: handlePreview,
+		},
+		{
+			Name:  "extract",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<file> <hash>", "Reconstruct a specific version of a file"},
+			},
+			Description: `Rebuild an earlier version of a post or comment from its version history.`,
+			Examples:    []string{"polis extract posts/20260125/hello.md sha256:abc123"},
+			Run:         handleExtract,
+		},
+		{
+			Name:  "trash",
+			Group: groupContent,
+			Usages: []Usage{
+				{"<file>", "Move a draft to the trash, or unpublish a post into it"},
+				{"list", "List trashed items"},
+				{"restore <id>", "Put a trashed item back"},
+				{"purge <id>|--all", "Permanently delete trashed items"},
+			},
+			Description: `Deleted drafts and unpublished posts are kept in .polis/trash for
+trash_retention_days (default 30) and can be restored until then. Unpublishing
+a post also moves its rendered HTML and version history and removes it from
+metadata/public.jsonl; run 'polis render' afterwards to update index pages.`,
+			Flags: []Flag{
+				{"--all", "", "With purge: empty the trash"},
+			},
+			Examples: []string{
+				"polis trash .polis/posts/drafts/idea.md",
+				"polis trash posts/20260125/hello.md",
+				"polis trash list",
+				"polis trash restore 20260301-120000-1a2b3c4d",
+			},
+			Run: handleTrash,
+		},
+
+		// Blessings
+		{
+			Name:    "blessing",
+			Aliases: []string{"bless"},
+			Group:   groupBlessing,
+			Usages: []Usage{
+				{"requests", "List pending blessing requests"},
+				{"grant <hash>", "Grant a blessing request by content hash"},
+				{"deny <hash>", "Deny a blessing request by content hash"},
+				{"grant|deny --all-from <domain>", "Grant or deny every pending request from one author"},
+				{"beseech <hash>", "Re-request blessing by content hash"},
+				{"sync", "Sync auto-blessed comments from discovery service"},
+				{"feature|unfeature <url>", "Mark or unmark a blessed comment as featured"},
+				{"sort <post> <order>", "Set a post's comment order: chronological, newest-first, featured-first"},
+			},
+			Description: `Review comments other authors have written on your posts. Blessed comments
+are shown alongside the post; denied comments are not. 'polis bless
+--all-from <domain>' grants every pending request from one author.`,
+			Examples: []string{
+				"polis blessing requests",
+				"polis blessing grant sha256:abc123...",
+				"polis blessing deny sha256:abc123...",
+				"polis bless --all-from bob.polis.pub",
+				"polis blessing sort posts/20260106/hello.md featured-first",
+				"polis blessing sync",
+			},
+			Run: handleBlessing,
+		},
+
+		// Following
+		{
+			Name:  "follow",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"<author-url>", "Follow an author (auto-bless their comments)"},
+			},
+			Description: `Add an author to metadata/following.json. Their posts appear in your feed
+and their comments on your posts are blessed automatically.`,
+			Examples: []string{"polis follow https://alice.polis.pub"},
+			Run:      handleFollow,
+		},
+		{
+			Name:  "unfollow",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"<author-url>", "Unfollow an author"},
+			},
+			Examples: []string{"polis unfollow https://alice.polis.pub"},
+			Run:      handleUnfollow,
+		},
+		{
+			Name:  "follow-list",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"", "Show your lists and the lists you subscribe to"},
+				{"create <name> [url...]", "Publish a signed list of sites to follow"},
+				{"include|exclude <name> <url>...", "Add or remove members of one of your lists"},
+				{"delete <name>", "Delete one of your lists"},
+				{"add <list-url>", "Subscribe to a list: follow its members, and later additions"},
+				{"remove <list-url>", "Unsubscribe from a list (members stay followed)"},
+				{"sync", "Follow members added to subscribed lists since the last check"},
+			},
+			Description: `Follow lists ("starter packs") are signed lists of sites, kept in
+metadata/lists/<name>.json and rendered as lists/<name>.html by polis render.
+Subscribing checks the list's signature against the key its site publishes,
+then follows every member. sync follows members added since; members you
+unfollowed are never followed again. The webapp syncs subscribed lists every
+six hours.`,
+			Flags: []Flag{
+				{"--title", "<title>", "List title (create)"},
+				{"--description", "<text>", "List description (create)"},
+				{"--from-following", "", "Start the list with everyone you follow (create)"},
+				{"--note", "<text>", "Why a site is on the list (include)"},
+			},
+			Examples: []string{
+				`polis follow-list create gardening --title "Gardening blogs" https://alice.polis.pub`,
+				`polis follow-list include gardening bob.polis.pub --note "Roses"`,
+				"polis follow-list add https://alice.polis.pub/metadata/lists/writers.json",
+				"polis follow-list sync",
+			},
+			Run: handleFollowList,
+		},
+		{
+			Name:  "author",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"<domain>", "Show an author's profile, recent items, and your history with them"},
+			},
+			Description: `Show what this site knows about an author: site title, name, and public
+key from their .well-known/polis, whether you follow each other, comments
+and blessings exchanged, and their most recent items in the feed cache
+(* marks unread). Refresh the cache first with "polis discover".`,
+			Flags: []Flag{
+				{"--offline", "", "Skip fetching .well-known/polis; use the following entry"},
+				{"--limit", "<n>", "Number of recent items to show (default: 10)"},
+			},
+			Examples: []string{"polis author alice.polis.pub", "polis author alice.polis.pub --json"},
+			Run:      handleAuthor,
+		},
+		{
+			Name:  "bookmark",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"<url>", "Save a remote post to read later, with a snapshot of its content"},
+				{"list", "List bookmarks"},
+				{"show <id|url>", "Print a bookmark's saved snapshot"},
+				{"remove <id|url>", "Remove a bookmark"},
+			},
+			Description: `Keep remote posts beyond feed cache retention. The list lives in
+metadata/bookmarks.json; the fetched content is saved privately under
+.polis/bookmarks/ so it stays readable if the post changes or disappears.
+Bookmarking a URL again refreshes its snapshot. A feed item ID or URL picks
+up the title and author from the feed cache.`,
+			Flags: []Flag{
+				{"--note", "<text>", "Note to keep with the bookmark"},
+				{"--no-snapshot", "", "Save the bookmark without fetching its content"},
+			},
+			Examples: []string{
+				"polis bookmark https://alice.polis.pub/posts/20260101/hello.md",
+				"polis bookmark list",
+				"polis bookmark show 1a2b3c4d5e6f7a8b",
+				"polis bookmark remove 1a2b3c4d5e6f7a8b",
+			},
+			Run: handleBookmark,
+		},
+		{
+			Name:  "graph",
+			Group: groupFollowing,
+			Usages: []Usage{
+				{"export", "Write the local social graph for visualization tools"},
+			},
+			Description: `Export this site's view of the social graph: who you follow, who follows
+you, and who you have exchanged comments and blessings with. Nodes are
+domains; edges are "follows", "commented", and "blessed", pointing from the
+actor and weighted by count. Everything comes from local state; run
+"polis discover" first for fresh followers and blessings.`,
+			Flags: []Flag{
+				{"--format", "<fmt>", "json (default), dot (Graphviz), or graphml (Gephi, yEd, Cytoscape)"},
+				{"--output", "<file>", "Write to a file instead of stdout"},
+			},
+			Examples: []string{
+				"polis graph export --format graphml --output network.graphml",
+				"polis graph export --format dot | dot -Tsvg > network.svg",
+			},
+			Run: handleGraph,
+		},
+
+		// Discovery
+		{
+			Name:  "discover",
+			Group: groupDiscovery,
+			Usages: []Usage{
+				{"", "Check followed authors for new content"},
+				{"--author <url>", "Check a specific author"},
+				{"new", "List recent posts by authors you don't follow"},
+				{"trending", "List the posts others comment on most"},
+			},
+			Description: `Fetch new posts and comments from followed authors into the feed cache.
+Authors who moved their site with polis migrate-domain are followed at their
+new address once their signed moved statement verifies, with a notification.
+Moves announced to the discovery service are checked on every run; --moves
+checks every followed site.
+
+new and trending read the discovery service's public stream for posts by
+authors you don't follow, newest first or most commented on first. Each page
+ends with the cursor to pass to --before for the next one.`,
+			Flags: []Flag{
+				{"--author", "<url>", "Check a specific author"},
+				{"--moves", "", "Check every followed site for a moved statement"},
+				{"--before", "<cursor>", "With new or trending, continue from a page's next cursor"},
+				{"--limit", "<n>", "With new or trending, posts per page (default 20)"},
+			},
+			Examples: []string{"polis discover", "polis discover --author https://alice.polis.pub", "polis discover --moves", "polis discover new", "polis discover trending --limit 10"},
+			Run:      handleDiscover,
+		},
+
+		// Notifications
+		{
+			Name:  "notifications",
+			Group: groupNotify,
+			Usages: []Usage{
+				{"", "List unread notifications"},
+				{"list", "List notifications (--all to include read)"},
+			},
+			Flags: []Flag{
+				{"-a, --all", "", "Show all notifications, including read ones"},
+			},
+			Examples: []string{"polis notifications", "polis notifications list --all"},
+			Run:      handleNotifications,
+		},
+		{
+			Name:  "digest",
+			Group: groupNotify,
+			Usages: []Usage{
+				{"[--since 7d] [--out <file>]", "Print a markdown digest of recent network activity"},
+				{"[--since 7d] --draft | --publish", "Save the digest as a post draft, or publish it"},
+				{"[--since 7d] --email", "Email the digest"},
+			},
+			Description: `Compile a markdown digest of the period: posts from followed authors in
+the feed (unread first, up to 20), comments, replies, and mentions received,
+new followers, and your own posts. It is built from the local feed cache and
+notification state, so it is as fresh as the last sync by polis serve or
+polis daemon. --email sends it with the notification email settings from
+polis serve (SMTP_PASSWORD in .env). For a recurring digest, add a scheduled
+job with the digest action.`,
+			Flags: []Flag{
+				{"--since", "<period>", "Period to cover: minutes, hours, days, or weeks, e.g. 24h, 7d, 2w (default: 7d)"},
+				{"--out", "<file>", "Write the digest to a file instead of printing it"},
+				{"--draft", "", "Save the digest as a post draft"},
+				{"--publish", "", "Publish the digest as a post"},
+				{"--email", "", "Email the digest"},
+			},
+			Examples: []string{
+				"polis digest",
+				"polis digest --since 30d --out digest.md",
+				"polis digest --publish --email",
+			},
+			Run: handleDigest,
+		},
+
+		// Administration
+		{
+			Name:  "register",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"", "Register site with discovery service"},
+			},
+			Description: `Register this site's domain and public key with the discovery service so
+other authors can find your posts and comments.`,
+			Run: handleRegister,
+		},
+		{
+			Name:  "unregister",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"", "Unregister site"},
+			},
+			Run: handleUnregister,
+		},
+		{
+			Name:  "render",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[--force] [--audit]", "Render markdown to HTML"},
+			},
+			Flags: []Flag{
+				{"--force", "", "Force re-render all files"},
+				{"--audit", "", "Report images without alt text, skipped heading levels, and low-contrast theme colors"},
+				{"--base-url", "<url>", "Site base URL"},
+				{"--cli-themes-dir", "<path>", "CLI themes directory"},
+			},
+			Examples: []string{"polis render", "polis render --force", "polis render --audit"},
+			Run:      handleRender,
+		},
+		{
+			Name:  "validate",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"", "Check that the site directory is a valid polis site"},
+			},
+			Run: handleValidate,
+		},
+		{
+			Name:  "migrate",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"<new-domain>", "Migrate content to a new domain"},
+			},
+			Description: `Rewrite URLs in local content from the current POLIS_BASE_URL domain to
+<new-domain>, re-sign the affected files, and record the migration with the
+discovery service so followers can update their references.`,
+			Examples: []string{"polis migrate newsite.example.com"},
+			Run:      handleMigrate,
+		},
+		{
+			Name:  "migrate-domain",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"<new-base-url>", "Move the site to a new address"},
+			},
+			Description: `Move the site to <new-base-url>: update base_url, .well-known/polis, and the
+URLs in metadata/, write a moved statement signed with the site key to
+.well-known/polis-moved, and register the move with the discovery service so
+followers' clients can follow it. With --stubs, also write a directory to
+deploy on the old host: a redirect page for each rendered page, redirect
+rules, and the moved statement.`,
+			Flags: []Flag{
+				{"--stubs", "<dir>", "Write redirect stubs for the old host to this directory"},
+				{"--no-discovery", "", "Don't register the move with the discovery service"},
+			},
+			Examples: []string{
+				"polis migrate-domain https://newsite.example.com",
+				"polis migrate-domain https://newsite.example.com --stubs ../old-host",
+			},
+			Run: handleMigrateDomain,
+		},
+		{
+			Name:  "migrations",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"apply", "Apply domain migrations to local files"},
+			},
+			Run: handleMigrations,
+		},
+		{
+			Name:  "deploy",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[target]", "Upload changed files to a deploy target"},
+				{"targets", "List configured deploy targets"},
+			},
+			Description: `Upload the rendered site to a target configured in .polis/deploy.json
+(rsync, sftp, or s3). Only files changed since the last deploy to the same
+target are transferred.`,
+			Flags: []Flag{
+				{"--dry-run", "", "Show what would change without uploading"},
+				{"--prune", "", "Delete remote files removed locally"},
+				{"--full", "", "Re-upload everything"},
+			},
+			ShowFlags: true,
+			Examples:  []string{"polis deploy --dry-run", "polis deploy production --prune"},
+			Run:       handleDeploy,
+		},
+		{
+			Name:  "reconcile",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[--repair]", "Compare local blessings, comments, and follows with discovery"},
+			},
+			Description: `Compare blessed-comments.json, your pending and denied comments, and
+following.json with what the discovery service has recorded, and report any
+divergence (for example a blessing that was lost to a failed network call).
+
+With --repair, lost blessing grants and follow announcements are re-sent,
+blessings missing locally are added to blessed-comments.json, and comments are
+moved to the status the target site recorded. Conflicts that need a decision,
+such as a blessing you granted that the service shows as denied, are only
+reported. The last report is saved to .polis/reconcile.json.`,
+			Flags: []Flag{
+				{"--repair", "", "Fix repairable divergence"},
+			},
+			Examples: []string{"polis reconcile", "polis reconcile --repair --json"},
+			Run:      handleReconcile,
+		},
+		{
+			Name:  "resolve",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"<path> pull|keep|merge-frontmatter", "Settle a post changed on the live site from another copy"},
+			},
+			Description: `'polis status' compares the live public.jsonl with your local posts and
+reports posts that were deployed from another copy of the site, such as a
+second machine: remote_newer when the live post builds on your version,
+diverged when both copies have edits the other lacks, and remote_only for
+posts you don't have. The last report is saved to .polis/conflicts.json.
+
+  pull               Replace the local post with the live one. Your copy
+                     goes to the trash.
+  keep               Keep your copy; the next deploy replaces the live one.
+  merge-frontmatter  Keep your body and take the live title, tags,
+                     description, summary, and language.`,
+			Examples: []string{"polis resolve posts/20260301/hello.md pull", "polis resolve posts/20260301/hello.md merge-frontmatter"},
+			Run:      handleResolve,
+		},
+		{
+			Name:  "status",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[--target <name>]", "Show site health, counts, discovery, and deploy drift"},
+			},
+			Flags: []Flag{
+				{"--target", "<name>", "Deploy target to compare against"},
+				{"--offline", "", "Skip checking the live site and discovery service"},
+			},
+			Examples: []string{"polis status", "polis status --offline --json"},
+			Run:      handleStatus,
+		},
+		{
+			Name:  "stats",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[--months <n>]", "Show posting cadence, comment activity, and time to blessing"},
+			},
+			Description: `Compute site statistics from local state: posts per month, comments
+received and given per month, the sites you exchange the most comments
+with, and the average time between a blessing request and its grant. The
+result is cached in metadata/stats.json; a theme with a stats.html
+template renders it as stats/index.html.`,
+			Flags: []Flag{
+				{"--months", "<n>", "Number of recent months to list (default 12, 0 for all)"},
+			},
+			Examples: []string{"polis stats", "polis stats --months 0 --json"},
+			Run:      handleStats,
+		},
+		{
+			Name:  "analytics",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[--days <n>] [--top <n>]", "Show post views counted by polis serve"},
+			},
+			Description: `Show how often each post was read, from the daily counts polis serve keeps
+in .polis/analytics.json. With analytics.enabled on and analytics.beacon_url
+set to the public address of polis serve's /beacon endpoint, rendered posts
+report each view there. polis daemon has no web server of its own; set
+analytics.listen to give it an address to accept views on. Only the post and the day are recorded; readers
+whose browser sends Do Not Track or Global Privacy Control are not counted.`,
+			Flags: []Flag{
+				{"--days", "<n>", "Number of recent days to count (default 30, 0 for all time)"},
+				{"--top", "<n>", "Number of posts to list (default 10, 0 for all)"},
+			},
+			Examples: []string{"polis analytics", "polis analytics --days 0 --top 0 --json"},
+			Run:      handleAnalytics,
+		},
+		{
+			Name:  "export-book",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"--format epub|pdf [--tag <tag> | --series <name>] [--year <yyyy>]", "Compile posts into an EPUB or a printable book"},
+			},
+			Description: `Compile published posts, oldest first, into one book: an EPUB, or with
+--format pdf a self-contained HTML file laid out for print, to save as PDF
+from a browser. Select posts by tag, by the series: frontmatter field, or
+by year; without a selection every public post is included. The title,
+author, and language on the cover come from .well-known/polis. Images
+stored on the site are embedded.`,
+			Flags: []Flag{
+				{"--format", "<fmt>", "epub (default) or pdf (print-ready HTML)"},
+				{"--tag", "<tag>", "Only posts with this tag"},
+				{"--series", "<name>", "Only posts whose series: field is this"},
+				{"--year", "<yyyy>", "Only posts published this year"},
+				{"--title", "<title>", "Book title (default: the series, else the site title)"},
+				{"--output", "<file>", "Output file (default: the title, as <title>.epub or .html)"},
+			},
+			Examples: []string{
+				"polis export-book --year 2025",
+				"polis export-book --format pdf --series \"Letters from Lisbon\"",
+			},
+			Run: handleExportBook,
+		},
+
+		// Cloning
+		{
+			Name:  "clone",
+			Group: groupClone,
+			Usages: []Usage{
+				{"<url> [dir]", "Clone a public polis site"},
+				{"<url> --full", "Re-download all content"},
+				{"<url> --diff", "Only download new/changed content"},
+			},
+			Flags: []Flag{
+				{"--full", "", "Re-download all content (ignore cached state)"},
+				{"--diff", "", "Only download new/changed content (default if previously cloned)"},
+			},
+			Examples: []string{"polis clone https://alice.polis.pub"},
+			Run:      handleClone,
+		},
+
+		// Local configuration
+		{
+			Name:  "init",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[options]", "Initialize Polis directory structure"},
+			},
+			Description: `Create the directory structure, signing keys, and .well-known/polis for a
+new site in the data directory.`,
+			Flags: []Flag{
+				{"--site-title", "<title>", "Site display name"},
+				{"--keys-dir", "<path>", "Custom keys directory (default: .polis/keys)"},
+				{"--posts-dir", "<path>", "Custom posts directory (default: posts)"},
+				{"--comments-dir", "<path>", "Custom comments directory (default: comments)"},
+				{"--snippets-dir", "<path>", "Custom snippets directory (default: snippets)"},
+				{"--themes-dir", "<path>", "Custom themes directory (default: .polis/themes)"},
+				{"--versions-dir", "<path>", "Custom versions directory (default: .versions)"},
+				{"--public-index", "<path>", "Custom public index path (default: metadata/public.jsonl)"},
+				{"--blessed-comments", "<path>", "Custom blessed comments path (default: metadata/blessed-comments.json)"},
+				{"--following-index", "<path>", "Custom following index path (default: metadata/following.json)"},
+				{"--starter", "<url|zip>", "Apply a starter package (theme, snippets, sample pages, settings)"},
+			},
+			ShowFlags: true,
+			Examples: []string{
+				"polis init",
+				`polis init --site-title "My Site"`,
+				"polis init --starter https://example.com/starters/ember.zip",
+			},
+			Run: handleInit,
+		},
+		{
+			Name:  "pack",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[options] [page.md...]", "Package this site's theme and snippets as a starter"},
+			},
+			Description: `Write a starter package that "polis init --starter" can apply to a new site:
+the active local theme, global snippets (navigation, footer, ...), the given
+markdown pages as sample drafts, and presentation settings. Keys, .env,
+.well-known, published posts, comments, metadata, and the about snippet are
+never included; page frontmatter (signatures, hashes) is stripped.`,
+			Flags: []Flag{
+				{"--out", "<file>", "Output archive (default: polis-starter.zip)"},
+				{"--name", "<name>", "Starter name (default: site directory name)"},
+				{"--description", "<text>", "One-line starter description"},
+			},
+			Examples: []string{
+				"polis pack",
+				"polis pack --name ember --out ember.zip posts/20260101/welcome.md",
+			},
+			Run: handlePack,
+		},
+		{
+			Name:  "config",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"list [--show-secrets]", "Show every setting and where it came from"},
+				{"get <key>", "Print one setting"},
+				{"set <key> [value]", "Write a setting (omit value to unset)"},
+				{"secure", "Move secrets from .env to the OS keychain"},
+			},
+			Description: `Read and write site settings without hand-editing files. Values are
+layered, lowest first: defaults, site .env, the OS keychain (for secrets),
+.polis/webapp-config.json (and the manifest for the theme), environment
+variables, then --set flags. Secrets are written to the keychain where there
+is one, unless secret_store is env.
+
+Settings: base_url, discovery_url, discovery_key, listen, admin_token,
+smtp_password, imap_password, bluesky_app_password, ipfs_api_token,
+signing_backend, sign_as, secret_store, theme, post_path,
+audit_block_publish,
+pages.archive, pages.not_found, pages.about, analytics.enabled,
+analytics.beacon_url, analytics.listen, view_mode, show_frontmatter, hide_read, prefetch,
+content_cache_mb, rate_limit, rate_limit_burst, extension_origins,
+trash_retention_days, http_cache_mb, allow_local_fetch, locale,
+translations_dir, log_level, ingest.allow, ingest.imap_host,
+ingest.imap_user, ingest.imap_mailbox, bluesky.handle, bluesky.pds,
+nostr.enabled, nostr.relays, ipfs.api, timestamp.tsa, hooks.post-publish,
+hooks.post-republish, hooks.post-comment.`,
+			Flags: []Flag{
+				{"--show-secrets", "", "Print secret values unmasked"},
+			},
+			Examples: []string{
+				"polis config list",
+				"polis config set view_mode browser",
+				"polis config set hooks.post-publish ./scripts/deploy.sh",
+				"polis --set discovery_url=http://localhost:54321 config get discovery_url",
+				"polis config secure",
+			},
+			Run: handleConfig,
+		},
+		{
+			Name:  "rebuild",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"--posts|--comments|--notifications|--all", "Rebuild indexes and reset state"},
+			},
+			Flags: []Flag{
+				{"--posts", "", "Rebuild posts index"},
+				{"--comments", "", "Rebuild comments index"},
+				{"--notifications", "", "Clear notifications"},
+				{"--all", "", "Rebuild everything"},
+			},
+			Examples: []string{"polis rebuild --all"},
+			Run:      handleRebuild,
+		},
+		{
+			Name:  "index",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"", "View index"},
+			},
+			Run: handleIndex,
+		},
+		{
+			Name:  "self-update",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[--check] [--channel stable|beta]", "Install the latest polis release"},
+			},
+			Description: `Check the release list for a newer version on the configured channel
+(update_channel: stable or beta), download the archive for this binary and
+platform, verify its checksum against the signed checksums.txt, and replace
+the running executable atomically. The new binary then migrates the site's
+data if needed.`,
+			Flags: []Flag{
+				{"--check", "", "Only report whether an update is available"},
+				{"--channel", "<name>", "Release channel for this run (default: update_channel setting)"},
+				{"--migrate", "", "Run this version's data migrations and exit"},
+			},
+			Examples: []string{
+				"polis self-update --check",
+				"polis self-update",
+				"polis config set update_channel beta",
+			},
+			Run: handleSelfUpdate,
+		},
+		{
+			Name:    "version",
+			Aliases: []string{"--version", "-v"},
+			Group:   groupLocal,
+			Usages: []Usage{
+				{"", "Print CLI version"},
+			},
+			Run: handleVersionCommand,
+		},
+		{
+			Name:  "about",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"", "Show site, versions, config info"},
+			},
+			Run: handleAbout,
+		},
+		{
+			Name:  "identity",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"prove github <user> [--url <gist>]", "Prove you own a GitHub account"},
+				{"prove dns [domain]", "Prove you control a DNS name"},
+				{"prove url <https://profile>", "Prove you control a profile page"},
+				{"list", "List your identity proofs"},
+				{"remove <service> <account>", "Remove a proof from .well-known/polis"},
+				{"verify <domain>", "Check another site's identity proofs"},
+			},
+			Description: `Link your site to accounts elsewhere with signed proofs, in the style of
+Keybase. prove signs a statement naming your domain, your public key, and
+the account, adds it to .well-known/polis, and prints a token to post where
+only the account holder can: a public GitHub gist, a TXT record at
+_polis.<domain>, or any https page such as a fediverse profile. verify
+fetches a site's .well-known/polis and checks each proof's signature and its
+posted token. The webapp checks the authors in your feed once a day and
+shows their verified accounts as badges. Rotating your key invalidates your
+proofs; run prove again and update the posted tokens.`,
+			Flags: []Flag{
+				{"--url", "<gist>", "Gist the GitHub proof is posted in (prove github)"},
+			},
+			Examples: []string{
+				"polis identity prove github alice",
+				"polis identity prove github alice --url https://gist.github.com/alice/1a2b3c",
+				"polis identity prove dns",
+				"polis identity verify bob.polis.pub",
+			},
+			Run: handleIdentity,
+		},
+		{
+			Name:  "device",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"init", "Generate a key on a second machine"},
+				{"add <name> <public key>", "Delegate to a device key (on the primary machine)"},
+				{"install <certificate>", "Install a device's certificate (on the device)"},
+				{"list", "List delegated devices"},
+				{"revoke <name>", "Revoke a device key"},
+			},
+			Description: `Use polis on more than one machine without copying the site key. On the
+new machine, init generates a device key. On the machine with the site key,
+add signs a certificate delegating publish and/or comment rights to that key
+until it expires, and lists the device in .well-known/polis; install stores
+the certificate with the device key. Posts and comments signed on the device
+embed the certificate, and verifiers check it against the site key before
+checking the content against the device key. revoke marks a device revoked;
+once deployed, readers reject everything it signed. Discovery service
+registrations and follow events are still verified against the site key, so
+send those from the primary machine.`,
+			Flags: []Flag{
+				{"--scopes", "<list>", "What the device may sign: publish, comment (default both)"},
+				{"--days", "<n>", "Days until the certificate expires (default 365)"},
+			},
+			Examples: []string{
+				"polis device init",
+				"polis device add laptop \"ssh-ed25519 AAAA...\" --scopes publish --days 90",
+				"polis device install eyJkb21haW4iOi...",
+				"polis device revoke laptop",
+			},
+			Run: handleDevice,
+		},
+		{
+			Name:  "coauthor",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"", "List the site's co-authors"},
+				{"add <name> [public key]", "Add an author (on the machine with the site key)"},
+				{"init <name>", "Generate an author key on the author's own machine"},
+				{"remove <name>", "Stop accepting what an author signs from now on"},
+			},
+			Description: `Run a shared site where several people publish under their own keys.
+Co-authors are listed by name with their public keys in .well-known/polis;
+their private keys live in .polis/keys/authors/<name>. add without a key
+generates one here, for sites whose authors share a machine; an author on
+their own machine runs init and sends the printed key to whoever holds the
+site key. Sign posts and comments as an author with --as <name>, or set
+sign_as. Signed content records the author in a signed-by field, and
+verifiers check it against that author's listed key. remove keeps the
+listing with the time of removal: what the author signed before stays
+valid. As with devices, discovery service registrations are verified
+against the site key, so register and follow from the site key's machine.`,
+			Examples: []string{
+				"polis coauthor add alice",
+				"polis coauthor init bob",
+				"polis coauthor add bob \"ssh-ed25519 AAAA...\"",
+				"polis post draft.md --as alice",
+				"polis coauthor remove bob",
+			},
+			Run: handleCoauthor,
+		},
+		{
+			Name:  "access",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"", "List the server API's users and their roles"},
+				{"add <name>", "Add a user and print their token"},
+				{"role <name> <role>", "Change a user's role"},
+				{"token <name>", "Replace a user's token"},
+				{"remove <name>", "Remove a user"},
+			},
+			Flags: []Flag{
+				{"--role", "<role>", "reader, moderator, editor, or admin (default: reader)"},
+			},
+			Description: `Share a polis serve instance without sharing everything. Once a user is
+added, every request to the server's API needs a user's token (the web UI
+asks for it), and the user's role limits what they may do: a reader reads,
+a moderator also decides on blessing requests, an editor also writes,
+publishes, comments, and follows, and an admin also manages settings,
+keys, automations, and the site's registration. The first user must be an
+admin, and one must remain while there are users. Tokens are shown once;
+only their hashes are kept, in .polis/api-users.json. Removing the last
+user opens the API again.`,
+			Examples: []string{
+				"polis access add alex --role admin",
+				"polis access add sam --role editor",
+				"polis access role sam moderator",
+				"polis access token sam",
+			},
+			Run: handleAccess,
+		},
+		{
+			Name:  "remote-admin",
+			Group: groupAdmin,
+			Usages: []Usage{
+				{"[status]", "Show the connected server's site and what is waiting"},
+				{"connect <url> --token <token>", "Connect to a polis serve instance on another machine"},
+				{"disconnect", "Forget the connection"},
+				{"drafts", "List the server's drafts"},
+				{"publish <file.md> | --draft <id>", "Publish on the server"},
+				{"requests", "List blessing requests waiting on the server"},
+				{"call <METHOD> <path> [json body]", "Call any API endpoint on the server"},
+			},
+			Flags: []Flag{
+				{"--token", "<token>", "API user token from polis access add on the server (- reads stdin; default: $POLIS_REMOTE_TOKEN)"},
+				{"--draft", "<id>", "publish: a draft saved on the server"},
+				{"--as", "<author>", "publish: sign as a co-author of the site"},
+				{"--unlisted", "", "publish: keep the post off index pages, feeds, and discovery"},
+			},
+			Description: `Run a site served from another machine, such as a VPS, without SSH. On
+the server, add an API user (polis access add) and start polis serve
+--listen on an address you can reach, ideally behind HTTPS. Then connect
+from your laptop; the connection is saved in ~/.polis/remote-admin.json.
+polis serve --remote opens the web UI for it, passing its API calls to the
+server with your token. Everything is signed on the server with its keys,
+and your role there decides what you may do.`,
+			Examples: []string{
+				"polis remote-admin connect https://polis.example.com --token <token>",
+				"polis remote-admin status",
+				"polis remote-admin publish post.md",
+				"polis remote-admin call POST /blessing/grant '{\"comment_url\": \"...\"}'",
+				"polis serve --remote",
+				"POLIS_DATA_DIR=/srv/site POLIS_LISTEN=0.0.0.0:8080 polis serve --print-config",
+			},
+			Run: handleRemoteAdmin,
+		},
+		{
+			Name:  "rotate-key",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"", "Generate new keypair and re-sign content"},
+			},
+			Flags: []Flag{
+				{"--delete-old-key", "", "Delete the old key after rotation"},
+			},
+			Run: handleRotateKey,
+		},
+		{
+			Name:  "workspace",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[list]", "List registered sites; * marks the active one"},
+				{"add <name> [path]", "Register a site directory (default: the current site)"},
+				{"use <name>", "Make a site the active one"},
+				{"remove <name>", "Forget a site (its directory is untouched)"},
+			},
+			Description: `Keep a named list of your sites in ~/.polis/workspaces.json. polis serve
+works on the active site when it isn't given one (--data-dir,
+POLIS_DATA_DIR, or running it inside a site), and the web UI switches
+between registered sites without restarting. The first site added becomes
+the active one.`,
+			Examples: []string{
+				"polis workspace add blog ~/sites/blog",
+				"polis workspace use blog",
+				"polis workspace",
+			},
+			Run: handleWorkspace,
+		},
+		{
+			Name:  "serve",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[-d|--data-dir PATH] [--log-level LEVEL] [--listen ADDR] [--multi-site]", "Start local web server (bundled binary only)"},
+				{"--remote", "Serve the web UI for the server connected with remote-admin"},
+				{"--print-config", "Show the configuration serve would start with"},
+			},
+			Description: `Every option can also come from the environment, for containers and
+systemd units: POLIS_DATA_DIR for the site directory, and each setting's
+variable (polis config list) for the rest, e.g. POLIS_LISTEN,
+POLIS_ADMIN_TOKEN, POLIS_BASE_URL, DISCOVERY_SERVICE_URL,
+DISCOVERY_SERVICE_KEY, and POLIS_LOG_LEVEL. Flags win over the environment,
+which wins over the site's .env and .polis/webapp-config.json.
+--print-config shows each value and where it came from, secrets masked.
+
+--multi-site serves every registered workspace (polis workspace) from the
+one process, each with its own keys, settings, and API users. API requests
+name a site with the X-Polis-Site header or an /api/sites/<name>/ prefix;
+the rest go to the site serve was started on.`,
+			Flags: []Flag{
+				{"-d, --data-dir", "<path>", "Polis site directory (default: $POLIS_DATA_DIR, the current directory if it is a site, or the active workspace)"},
+				{"--log-level", "<level>", "Write logs/polis.log at this level (debug, info, warn, error)"},
+				{"--listen", "<addr>", "Listen on this address (default: a free port on localhost); other than localhost, needs API users (polis access) or admin_token"},
+				{"--remote", "", "Proxy the web UI's API calls to the server connected with polis remote-admin"},
+				{"--print-config", "", "Print the effective configuration and each value's source, then exit"},
+				{"--multi-site", "", "Also serve every registered workspace; API requests pick one by name"},
+			},
+			Examples: []string{
+				"polis serve",
+				"polis serve --listen 0.0.0.0:8080",
+				"polis serve --remote",
+				"polis serve --multi-site --listen 0.0.0.0:8080",
+			},
+			Run: handleServe,
+		},
+		{
+			Name:  "daemon",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[start]", "Run background sync without the web UI (bundled binary only)"},
+				{"status", "Show whether the daemon is running and its last sync"},
+				{"sync", "Ask a running daemon to sync now"},
+				{"stop", "Stop a running daemon"},
+			},
+			Description: `Run the serve process's background work headless: feed refresh, comment
+status sync, blessing-request polling, notification emails, and scheduled
+publishing, every 30 seconds. The daemon runs in the foreground (use systemd,
+launchd, or nohup to background it) and is controlled through a local socket
+at .polis/daemon.sock.
+
+Post drafts in .polis/posts/drafts with a publish_at frontmatter field
+(RFC 3339 or YYYY-MM-DD HH:MM) are published once that time has passed.`,
+			Examples: []string{
+				"polis daemon --data-dir ~/my-site",
+				"polis daemon status",
+				"polis daemon stop",
+			},
+			Run: handleDaemon,
+		},
+		{
+			Name:  "rpc",
+			Group: groupLocal,
+			Usages: []Usage{
+				{"[--socket PATH]", "Serve a JSON-RPC socket for editor integrations"},
+			},
+			Flags: []Flag{
+				{"--socket", "<path>", "Socket path (default: .polis/rpc.sock)"},
+			},
+			Description: `Listen on a local Unix socket for JSON-RPC 2.0 requests, one per line, so
+editor plugins can save drafts, publish, and preview without polis serve or
+starting a process per call. Methods: ping, draft.save, post.publish, and
+render.preview. The socket is readable only by your user and runs in the
+foreground until interrupted.`,
+			Examples: []string{
+				"polis rpc --data-dir ~/my-site",
+				`echo '{"jsonrpc":"2.0","id":1,"method":"render.preview","params":{"markdown":"# Hi"}}' | nc -U .polis/rpc.sock`,
+			},
+			Run: handleRPC,
+		},
+		{
+			Name:    "help",
+			Aliases: []string{"--help", "-h"},
+			Group:   groupLocal,
+			Usages: []Usage{
+				{"[command]", "Show help for a command"},
+			},
+			Examples: []string{"polis help deploy"},
+			Run:      handleHelp,
+		},
+		{
+			Name:   "gen-man",
+			Hidden: true,
+			Usages: []Usage{
+				{"[--out <dir>]", "Generate troff man pages"},
+			},
+			Flags: []Flag{
+				{"--out", "<dir>", "Output directory (default: man)"},
+			},
+			Run: handleGenMan,
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	return buf.String()
+}
+
+func TestRegistry_CommandsAreComplete(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range commands {
+		if c.Name == "" || c.Run == nil || len(c.Usages) == 0 {
+			t.Errorf("command %q is missing a name, handler, or usage", c.Name)
+		}
+		if !c.Hidden && c.Group == "" {
+			t.Errorf("visible command %q has no group", c.Name)
+		}
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			if seen[name] {
+				t.Errorf("duplicate command name or alias %q", name)
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	if c := findCommand("-v"); c == nil || c.Name != "version" {
+		t.Errorf("expected -v to resolve to version, got %v", c)
+	}
+	if c := findCommand("deploy"); c == nil || c.Name != "deploy" {
+		t.Error("expected deploy to be registered")
+	}
+	if c := findCommand("nope"); c != nil {
+		t.Errorf("expected nil for unknown command, got %q", c.Name)
+	}
+}
+
+func TestPrintUsage_OmitsHiddenCommands(t *testing.T) {
+	output := captureStdout(t, printUsage)
+	if strings.Contains(output, "gen-man") {
+		t.Error("hidden gen-man command should not appear in usage")
+	}
+	if !strings.Contains(output, "polis help <command>") {
+		t.Error("usage should point to per-command help")
+	}
+}
+
+func TestPrintCommandHelp(t *testing.T) {
+	output := captureStdout(t, func() { printCommandHelp(findCommand("deploy")) })
+	for _, want := range []string{"polis deploy [target]", "Options:", "--dry-run", "Examples:", ".polis/deploy.json"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected deploy help to contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestHandleGenMan(t *testing.T) {
+	dir := t.TempDir()
+	captureStdout(t, func() { handleGenMan([]string{"--out", dir}) })
+
+	index, err := os.ReadFile(filepath.Join(dir, "polis.1"))
+	if err != nil {
+		t.Fatalf("polis.1 not written: %v", err)
+	}
+	if !strings.HasPrefix(string(index), ".TH POLIS 1") || !strings.Contains(string(index), `polis\-deploy`) {
+		t.Errorf("unexpected polis.1:\n%s", index)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "polis-init.1"))
+	if err != nil {
+		t.Fatalf("polis-init.1 not written: %v", err)
+	}
+	if !strings.Contains(string(page), `\-\-site\-title`) || !strings.Contains(string(page), ".SH OPTIONS") {
+		t.Errorf("unexpected polis-init.1:\n%s", page)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "polis-gen-man.1")); err == nil {
+		t.Error("hidden commands should not get man pages")
+	}
+}
+
+func TestManEscape(t *testing.T) {
+	if got := manEscape(`.hidden -x \n`); got != `\&.hidden \-x \en` {
+		t.Errorf("unexpected escape: %q", got)
+	}
+}
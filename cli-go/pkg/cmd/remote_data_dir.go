@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/remotedir"
+)
+
+// remoteMount is the working copy of an sftp:// data directory, if any.
+var remoteMount *remotedir.Mount
+
+// localOnlyCommands keep running until stopped, so they can't work on a
+// copy that is only written back when the command ends.
+var localOnlyCommands = map[string]bool{"serve": true, "daemon": true, "rpc": true}
+
+// mountRemoteDataDir checks out the remote data directory into a local
+// working copy and points dataDir at it.
+func mountRemoteDataDir(command string) {
+	if localOnlyCommands[command] {
+		exitError("polis %s needs a local data directory; run it on the server instead", command)
+	}
+	cacheDir, err := remotedir.DefaultCacheDir()
+	if err != nil {
+		exitError("Failed to find a cache directory for the working copy: %v", err)
+	}
+	m, err := remotedir.Open(dataDir, cacheDir, nil)
+	if err != nil {
+		exitError("%v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := m.Pull(ctx); err != nil {
+		m.Unlock(ctx)
+		exitError("Failed to fetch %s: %v", m, err)
+	}
+	remoteMount = m
+	dataDir = m.Dir
+}
+
+// unmountRemoteDataDir writes the working copy's changes back and releases
+// the server lock. Messages go to stderr so JSON output stays parseable.
+// Returns false if the changes couldn't be written back.
+func unmountRemoteDataDir() bool {
+	m := remoteMount
+	if m == nil {
+		return true
+	}
+	remoteMount = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ok := true
+	result, err := m.Push(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (the changes are still in %s)\n", err, m.Dir)
+		ok = false
+	} else if n := len(result.Written) + len(result.Removed); n > 0 && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "[i] Wrote %d changes back to %s\n", n, m)
+	}
+	if err := m.Unlock(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		ok = false
+	}
+	return ok
+}
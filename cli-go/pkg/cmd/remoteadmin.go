@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/client"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remoteadmin"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleRemoteAdmin(args []string) {
+	if len(args) < 1 {
+		remoteAdminStatus()
+		return
+	}
+
+	switch args[0] {
+	case "connect":
+		remoteAdminConnect(args[1:])
+	case "status":
+		remoteAdminStatus()
+	case "disconnect":
+		remoteAdminDisconnect()
+	case "drafts":
+		remoteAdminDrafts()
+	case "publish":
+		remoteAdminPublish(args[1:])
+	case "requests":
+		remoteAdminRequests()
+	case "call":
+		remoteAdminCall(args[1:])
+	default:
+		exitError("Unknown remote-admin subcommand. Use: polis remote-admin [connect|status|disconnect|drafts|publish|requests|call]")
+	}
+}
+
+func remoteAdminConnect(args []string) {
+	fs := flag.NewFlagSet("remote-admin connect", flag.ExitOnError)
+	token := fs.String("token", os.Getenv("POLIS_REMOTE_TOKEN"), "API user token (- reads it from stdin)")
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") && len(positional) < 1 {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	fs.Parse(args)
+	if len(positional) < 1 {
+		exitError("Usage: polis remote-admin connect <url> --token <token>")
+	}
+	if *token == "-" {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		*token = strings.TrimSpace(line)
+	}
+
+	conn, err := remoteadmin.Connect(context.Background(), positional[0], *token)
+	if err != nil {
+		exitError("Failed to connect: %v", err)
+	}
+	if err := remoteadmin.Save(conn); err != nil {
+		exitError("Failed to save connection: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data":    map[string]interface{}{"url": conn.URL, "user": conn.User, "role": conn.Role},
+		})
+		return
+	}
+	fmt.Printf("[✓] Connected to %s as %s (%s)\n", conn.URL, conn.User, conn.Role)
+	if conn.Insecure() {
+		fmt.Println("[!] The token travels unencrypted over http://; put the server behind HTTPS.")
+	}
+	fmt.Println("[i] Open the web UI for it with: polis serve --remote")
+}
+
+// remoteConnection returns the saved connection, or exits.
+func remoteConnection() *remoteadmin.Connection {
+	conn, err := remoteadmin.Load()
+	if err != nil {
+		exitError("%v", err)
+	}
+	return conn
+}
+
+func remoteAdminStatus() {
+	conn := remoteConnection()
+	ctx := context.Background()
+	c := conn.Client()
+	st, err := c.Status(ctx)
+	if err != nil {
+		exitError("Failed to reach %s: %v", conn.URL, err)
+	}
+	var counts site.Counts
+	if err := c.Do(ctx, http.MethodGet, "/counts", nil, &counts); err != nil {
+		exitError("Failed to read counts: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data": map[string]interface{}{
+				"url":        conn.URL,
+				"user":       conn.User,
+				"role":       conn.Role,
+				"site_title": st.SiteTitle,
+				"base_url":   st.BaseURL,
+				"counts":     counts,
+			},
+		})
+		return
+	}
+	fmt.Printf("Server:  %s (as %s, %s)\n", conn.URL, conn.User, conn.Role)
+	fmt.Printf("Site:    %s %s\n", st.SiteTitle, st.BaseURL)
+	fmt.Printf("Posts:   %d published, %d drafts\n", counts.Posts, counts.Drafts)
+	fmt.Printf("Waiting: %d blessing requests, %d unread notifications\n", counts.BlessingRequests, counts.NotificationsUnread)
+}
+
+func remoteAdminDisconnect() {
+	if err := remoteadmin.Disconnect(); err != nil {
+		exitError("Failed to disconnect: %v", err)
+	}
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data":    map[string]interface{}{"connected": false},
+		})
+		return
+	}
+	fmt.Println("[✓] Disconnected")
+}
+
+func remoteAdminDrafts() {
+	conn := remoteConnection()
+	drafts, err := conn.Client().Drafts(context.Background())
+	if err != nil {
+		exitError("Failed to list drafts: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data":    map[string]interface{}{"drafts": drafts},
+		})
+		return
+	}
+	if len(drafts) == 0 {
+		fmt.Println("[i] No drafts")
+		return
+	}
+	for _, d := range drafts {
+		fmt.Printf("  %-32s %s\n", d.ID, d.Name)
+	}
+}
+
+// remoteAdminPublish publishes a local markdown file, or a draft saved on
+// the server, on the remote site. The server signs it with its keys.
+func remoteAdminPublish(args []string) {
+	fs := flag.NewFlagSet("remote-admin publish", flag.ExitOnError)
+	draftID := fs.String("draft", "", "Publish a draft saved on the server")
+	as := fs.String("as", "", "Sign as a co-author of the site")
+	unlisted := fs.Bool("unlisted", false, "Keep the post off index pages, feeds, and discovery")
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") && len(positional) < 1 {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	fs.Parse(args)
+
+	conn := remoteConnection()
+	ctx := context.Background()
+	c := conn.Client()
+	req := client.PublishRequest{DraftID: *draftID, As: *as, Unlisted: *unlisted}
+	switch {
+	case len(positional) == 1:
+		data, err := os.ReadFile(positional[0])
+		if err != nil {
+			exitError("Failed to read %s: %v", positional[0], err)
+		}
+		req.Markdown = string(data)
+		req.Filename = strings.TrimSuffix(filepath.Base(positional[0]), ".md")
+	case *draftID != "":
+		d, err := c.Draft(ctx, *draftID)
+		if err != nil {
+			exitError("Failed to read draft: %v", err)
+		}
+		req.Markdown = d.Markdown
+	default:
+		exitError("Usage: polis remote-admin publish <file.md> | --draft <id> [--as <author>] [--unlisted]")
+	}
+
+	result, err := c.Publish(ctx, req)
+	if err != nil {
+		exitError("Failed to publish: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data":    result,
+		})
+		return
+	}
+	fmt.Printf("[✓] Published %s: %s\n", result.Title, result.Path)
+	if result.URL != "" {
+		fmt.Printf("[i] %s\n", result.URL)
+	}
+}
+
+func remoteAdminRequests() {
+	conn := remoteConnection()
+	requests, err := conn.Client().BlessingRequests(context.Background())
+	if err != nil {
+		exitError("Failed to list blessing requests: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "remote-admin",
+			"data":    map[string]interface{}{"requests": requests},
+		})
+		return
+	}
+	if len(requests) == 0 {
+		fmt.Println("[i] No pending blessing requests")
+		return
+	}
+	for _, r := range requests {
+		fmt.Printf("  %s\n    on %s (by %s)\n", r.CommentURL, r.InReplyTo, r.Author)
+	}
+}
+
+// remoteAdminCall calls any API endpoint and prints the response, for what
+// has no subcommand of its own.
+func remoteAdminCall(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		exitError("Usage: polis remote-admin call <METHOD> <path> [json body]")
+	}
+	method, path := strings.ToUpper(args[0]), args[1]
+	path = "/" + strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(path, "/"), "api/v1/"), "api/")
+	var body interface{}
+	if len(args) == 3 {
+		if err := json.Unmarshal([]byte(args[2]), &body); err != nil {
+			exitError("Body is not valid JSON: %v", err)
+		}
+	}
+
+	conn := remoteConnection()
+	var out interface{}
+	if err := conn.Client().Do(context.Background(), method, path, body, &out); err != nil {
+		exitError("%v", err)
+	}
+	data, _ := json.MarshalIndent(out, "", "  ")
+	fmt.Println(string(data))
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/render"
 )
 
@@ -15,6 +16,7 @@ func handleRender(args []string) {
 	force := fs.Bool("force", false, "Force re-render all files")
 	cliThemesDir := fs.String("cli-themes-dir", "", "CLI themes directory")
 	baseURL := fs.String("base-url", "", "Site base URL")
+	audit := fs.Bool("audit", false, "Also report accessibility issues")
 	fs.Parse(args)
 
 	dir := getDataDir()
@@ -36,12 +38,20 @@ func handleRender(args []string) {
 		url = getBaseURLFromSite(dir)
 	}
 
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+
+	applyRenderConfig(loadConfig())
+
 	// Create renderer
 	renderer, err := render.NewPageRenderer(render.PageConfig{
-		DataDir:       dir,
-		CLIThemesDir:  themesDir,
-		BaseURL:       url,
-		RenderMarkers: false, // CLI rendering doesn't need edit markers
+		DataDir:         dir,
+		CLIThemesDir:    themesDir,
+		BaseURL:         url,
+		RenderMarkers:   false, // CLI rendering doesn't need edit markers
+		DiscoveryDomain: discoveryDomain,
 	})
 	if err != nil {
 		exitError("Failed to create renderer: %v", err)
@@ -53,15 +63,31 @@ func handleRender(args []string) {
 		exitError("Render failed: %v", err)
 	}
 
+	var report *render.AuditReport
+	if *audit {
+		report, err = renderer.Audit()
+		if err != nil {
+			exitError("Audit failed: %v", err)
+		}
+	}
+
 	if jsonOutput {
-		outputJSON(map[string]interface{}{
-			"success":           true,
-			"posts_rendered":    stats.PostsRendered,
-			"posts_skipped":     stats.PostsSkipped,
-			"comments_rendered": stats.CommentsRendered,
-			"comments_skipped":  stats.CommentsSkipped,
-			"index_generated":   stats.IndexGenerated,
-		})
+		result := map[string]interface{}{
+			"success":             true,
+			"posts_rendered":      stats.PostsRendered,
+			"posts_skipped":       stats.PostsSkipped,
+			"comments_rendered":   stats.CommentsRendered,
+			"comments_skipped":    stats.CommentsSkipped,
+			"index_generated":     stats.IndexGenerated,
+			"archive_generated":   stats.ArchiveGenerated,
+			"about_generated":     stats.AboutGenerated,
+			"not_found_generated": stats.NotFoundGenerated,
+			"lists_generated":     stats.ListsGenerated,
+		}
+		if report != nil {
+			result["audit"] = report
+		}
+		outputJSON(result)
 	} else {
 		fmt.Printf("Rendered %d posts, %d comments\n", stats.PostsRendered, stats.CommentsRendered)
 		if stats.PostsSkipped > 0 || stats.CommentsSkipped > 0 {
@@ -70,6 +96,36 @@ func handleRender(args []string) {
 		if stats.IndexGenerated {
 			fmt.Println("Generated index.html")
 		}
+		if stats.ArchiveGenerated {
+			fmt.Println("Generated posts/index.html")
+		}
+		if stats.StatsGenerated {
+			fmt.Println("Generated stats/index.html")
+		}
+		if stats.AboutGenerated {
+			fmt.Println("Generated about/index.html")
+		}
+		if stats.NotFoundGenerated {
+			fmt.Println("Generated 404.html")
+		}
+		if stats.ListsGenerated > 0 {
+			fmt.Printf("Generated %d follow list pages in lists/\n", stats.ListsGenerated)
+		}
+		if report != nil {
+			printAuditReport(report)
+		}
+	}
+}
+
+func printAuditReport(report *render.AuditReport) {
+	fmt.Println()
+	for _, issue := range report.Issues {
+		fmt.Printf("%s: [%s] %s\n", issue.Path, issue.Rule, issue.Message)
+	}
+	if len(report.Issues) == 0 {
+		fmt.Printf("Audit: no accessibility issues in %d files or theme %s\n", report.Checked, report.Theme)
+	} else {
+		fmt.Printf("Audit: %d issues (%d files and theme %s checked)\n", len(report.Issues), report.Checked, report.Theme)
 	}
 }
 
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+func handleResolve(args []string) {
+	if len(args) < 2 {
+		exitError("Usage: polis resolve <path> <pull|keep|merge-frontmatter>")
+	}
+	path, how := args[0], args[1]
+	fs := flag.NewFlagSet("resolve", flag.ExitOnError)
+	fs.Parse(args[2:])
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	siteURL := baseURL
+	if siteURL == "" {
+		siteURL = getBaseURLFromSite(dir)
+	}
+
+	opts := conflict.ResolveOptions{DataDir: dir, BaseURL: siteURL, Path: path, How: how}
+	if how == conflict.ResolveMergeFrontmatter {
+		privKey, err := loadPrivateKey(dir)
+		if err != nil {
+			exitError("Failed to load private key: %v", err)
+		}
+		opts.PrivateKey = privKey
+	}
+	if err := conflict.Resolve(opts, remote.NewClient()); err != nil {
+		exitError("Failed to resolve %s: %v", path, err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "resolve",
+			"data": map[string]interface{}{
+				"path":       path,
+				"resolution": how,
+			},
+		})
+		return
+	}
+
+	switch how {
+	case conflict.ResolvePull:
+		fmt.Printf("[✓] Pulled the live copy of %s\n", path)
+		fmt.Println("[i] The local copy, if any, is in the trash ('polis trash list')")
+	case conflict.ResolveKeep:
+		fmt.Printf("[✓] Keeping the local copy of %s\n", path)
+		fmt.Println("[i] The next deploy replaces the live copy")
+	case conflict.ResolveMergeFrontmatter:
+		fmt.Printf("[✓] Merged the live frontmatter into %s\n", path)
+	}
+	if how != conflict.ResolveKeep {
+		fmt.Println("[i] Run 'polis render' and deploy to publish the change")
+	}
+}
@@ -2,23 +2,32 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/bookmark"
 	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/i18n"
 	"github.com/vdibart/polis-cli/cli-go/pkg/index"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
 	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remotedir"
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
 	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+	"github.com/vdibart/polis-cli/cli-go/pkg/thread"
 )
 
 // Version is set at build time with -ldflags
@@ -26,15 +35,22 @@ var Version = "dev"
 
 // Global flags and config
 var (
-	dataDir      string
-	jsonOutput   bool
-	discoveryURL string
-	discoveryKey string
-	baseURL      string
+	dataDir       string
+	dataDirFlag   bool // dataDir came from --data-dir or -d, not POLIS_DATA_DIR
+	jsonOutput    bool
+	discoveryURL  string
+	discoveryKey  string
+	baseURL       string
+	logLevel      string                // --log-level, passed on to the daemon
+	flagOverrides = map[string]string{} // --set key=value
+	locale        = i18n.Default        // language of human-readable messages
 )
 
+// DataDirEnv names the site directory when --data-dir isn't given.
+const DataDirEnv = "POLIS_DATA_DIR"
+
 // DefaultDiscoveryServiceURL is the default discovery service URL.
-const DefaultDiscoveryServiceURL = "https://ltfpezriiaqvjupxbttw.supabase.co/functions/v1"
+const DefaultDiscoveryServiceURL = config.DefaultDiscoveryURL
 
 // Execute is the main entry point for the CLI.
 func Execute(args []string) {
@@ -43,15 +59,69 @@ func Execute(args []string) {
 	comment.Version = Version
 	metadata.Version = Version
 	following.Version = Version
+	thread.Version = Version
 	index.Version = Version
 	notification.Version = Version
 	theme.Version = Version
 	feed.Version = Version
 	site.Version = Version
+	bookmark.Version = Version
+
+	if len(args) < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Parse global flags
+	var filteredArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--data-dir" && i+1 < len(args):
+			dataDir = args[i+1]
+			dataDirFlag = true
+			i++ // Skip the next arg (the value)
+		case len(arg) > 11 && arg[:11] == "--data-dir=":
+			dataDir = arg[11:]
+			dataDirFlag = true
+		case arg == "--set" && i+1 < len(args):
+			parseSetFlag(args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--set="):
+			parseSetFlag(strings.TrimPrefix(arg, "--set="))
+		case arg == "--log-level" && i+1 < len(args):
+			setLogLevel(args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--log-level="):
+			setLogLevel(strings.TrimPrefix(arg, "--log-level="))
+		default:
+			filteredArgs = append(filteredArgs, arg)
+		}
+	}
+
+	// Containers and services name the site in the environment instead
+	if dataDir == "" {
+		dataDir = os.Getenv(DataDirEnv)
+	}
+
+	// A site on another machine is worked on through a local copy
+	if remotedir.IsRemote(dataDir) && len(filteredArgs) > 0 {
+		mountRemoteDataDir(filteredArgs[0])
+	}
 
 	// Load .env file (does not override existing env vars, matches bash CLI)
 	loadEnv()
 
+	// --set overrides win over everything; export them so commands that
+	// read the environment directly see them too
+	for key, value := range flagOverrides {
+		if s, ok := config.Lookup(key); ok {
+			os.Setenv(s.Env, value)
+		}
+	}
+
 	// Propagate discovery config to packages that register with discovery
 	discoveryURL = os.Getenv("DISCOVERY_SERVICE_URL")
 	if discoveryURL == "" {
@@ -72,25 +142,42 @@ func Execute(args []string) {
 	stream.DiscoveryKey = discoveryKey
 	stream.BaseURL = baseURL
 
-	if len(args) < 1 {
-		printUsage()
-		os.Exit(1)
+	// Messages in the configured language, with translations from
+	// ~/.polis/translations, the site's, and translations_dir
+	if err := i18n.LoadDirs(i18n.Dirs(getDataDir(), os.Getenv("POLIS_TRANSLATIONS_DIR"))); err != nil {
+		logging.Warn("ignoring translations", "error", err)
 	}
+	locale = i18n.FromEnv(os.Getenv("POLIS_LOCALE"))
 
-	// Parse global flags
-	var filteredArgs []string
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-		switch {
-		case arg == "--json":
-			jsonOutput = true
-		case arg == "--data-dir" && i+1 < len(args):
-			dataDir = args[i+1]
-			i++ // Skip the next arg (the value)
-		case len(arg) > 11 && arg[:11] == "--data-dir=":
-			dataDir = arg[11:]
-		default:
-			filteredArgs = append(filteredArgs, arg)
+	// Temp location and fsync policy for durable writes (network mounts)
+	fsutil.TempDir = fsutil.ResolveTempDir(getDataDir(), os.Getenv("POLIS_TEMP_DIR"))
+	if policy, err := fsutil.ParseSyncPolicy(os.Getenv("POLIS_FSYNC")); err == nil {
+		fsutil.Sync = policy
+	} else {
+		logging.Warn("ignoring invalid POLIS_FSYNC", "error", err)
+	}
+
+	// Outbound requests go to public HTTPS addresses only, unless developing locally
+	if v := os.Getenv("POLIS_ALLOW_LOCAL_FETCH"); v != "" {
+		if allow, err := strconv.ParseBool(v); err == nil {
+			safehttp.AllowLocal = allow
+		} else {
+			logging.Warn("ignoring invalid POLIS_ALLOW_LOCAL_FETCH", "error", err)
+		}
+	}
+
+	// Conditional requests for remote fetches, cached under .polis/cache/http
+	if dir := getDataDir(); isPolisSite(dir) {
+		mb := remote.DefaultHTTPCacheBytes >> 20
+		if v := os.Getenv("POLIS_HTTP_CACHE_MB"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				mb = n
+			} else {
+				logging.Warn("ignoring invalid POLIS_HTTP_CACHE_MB", "error", err)
+			}
+		}
+		if mb > 0 {
+			remote.DefaultHTTPCache = remote.NewHTTPCache(remote.HTTPCacheDir(dir), int64(mb)<<20)
 		}
 	}
 
@@ -102,151 +189,22 @@ func Execute(args []string) {
 	command := filteredArgs[0]
 	cmdArgs := filteredArgs[1:]
 
-	switch command {
-	case "init":
-		handleInit(cmdArgs)
-	case "validate":
-		handleValidate(cmdArgs)
-	case "render":
-		handleRender(cmdArgs)
-	case "post":
-		handlePublish(cmdArgs)
-	case "republish":
-		handleRepublish(cmdArgs)
-	case "comment":
-		handleComment(cmdArgs)
-	case "preview":
-		handlePreview(cmdArgs)
-	case "extract":
-		handleExtract(cmdArgs)
-	case "index":
-		handleIndex(cmdArgs)
-	case "about":
-		handleAbout(cmdArgs)
-	case "follow":
-		handleFollow(cmdArgs)
-	case "unfollow":
-		handleUnfollow(cmdArgs)
-	case "discover":
-		handleDiscover(cmdArgs)
-	case "blessing":
-		handleBlessing(cmdArgs)
-	case "rebuild":
-		handleRebuild(cmdArgs)
-	case "migrate":
-		handleMigrate(cmdArgs)
-	case "migrations":
-		if len(cmdArgs) > 0 && cmdArgs[0] == "apply" {
-			handleMigrationsApply(cmdArgs[1:])
-		} else {
-			exitError("Unknown migrations subcommand. Use: polis migrations apply")
-		}
-	case "rotate-key":
-		handleRotateKey(cmdArgs)
-	case "notifications":
-		handleNotifications(cmdArgs)
-	case "clone":
-		handleClone(cmdArgs)
-	case "register":
-		handleRegister(cmdArgs)
-	case "unregister":
-		handleUnregister(cmdArgs)
-	case "serve":
-		handleServe(cmdArgs)
-	case "version", "--version", "-v":
-		if jsonOutput {
-			outputJSON(map[string]interface{}{
-				"status":  "success",
-				"command": "version",
-				"data": map[string]interface{}{
-					"version": Version,
-				},
-			})
-		} else {
-			fmt.Printf("polis %s\n", Version)
-		}
-	case "help", "--help", "-h":
-		printUsage()
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+	c := findCommand(command)
+	if c == nil {
+		unmountRemoteDataDir()
+		fmt.Fprintln(os.Stderr, i18n.Sprintf(locale, "Unknown command: %s", command))
 		printUsage()
 		os.Exit(1)
 	}
-}
-
-func printUsage() {
-	fmt.Print(`Polis - Decentralized Social Network CLI
-
-Usage:
-  polis [--json] <command> [options]
-
-Global Flags:
-  --json                          Output results in JSON format
-  --data-dir <path>               Site data directory (default: current directory)
-
-Commands related to creating or viewing content:
-  polis post <file>               Create a new post
-  polis comment <file> [url]      Create a comment on a post
-  polis republish <file>          Update an already-published file
-  polis preview <url>             Preview a post or comment with signature verification
-  polis extract <file> <hash>     Reconstruct a specific version of a file
-
-Commands related to requesting, reviewing, or granting blessings:
-  polis blessing requests         List pending blessing requests
-  polis blessing grant <hash>     Grant a blessing request by content hash
-  polis blessing deny <hash>      Deny a blessing request by content hash
-  polis blessing beseech <hash>   Re-request blessing by content hash
-  polis blessing sync             Sync auto-blessed comments from discovery service
-
-Commands related to following or unfollowing an author:
-  polis follow <author-url>       Follow an author (auto-bless their comments)
-  polis unfollow <author-url>     Unfollow an author
-
-Commands related to content discovery:
-  polis discover                  Check followed authors for new content
-  polis discover --author <url>   Check a specific author
-  polis discover --since <date>   Show items since date
-
-Commands related to notifications:
-  polis notifications             List unread notifications
-  polis notifications list        List notifications (--type <types>)
-
-Commands related to site administration:
-  polis register                  Register site with discovery service
-  polis unregister [--force]      Unregister site
-  polis render [--force]          Render markdown to HTML
-  polis migrate <new-domain>      Migrate content to a new domain
-  polis migrations apply          Apply domain migrations to local files
-
-Commands related to cloning remote polis sites:
-  polis clone <url> [dir]         Clone a public polis site
-  polis clone <url> --full        Re-download all content
-  polis clone <url> --diff        Only download new/changed content
-
-Commands related to local configuration:
-  polis init [options]            Initialize Polis directory structure
-    --site-title <title>          Site display name
-    --keys-dir <path>             Custom keys directory (default: .polis/keys)
-    --posts-dir <path>            Custom posts directory (default: posts)
-    --comments-dir <path>         Custom comments directory (default: comments)
-    --snippets-dir <path>         Custom snippets directory (default: snippets)
-    --versions-dir <path>         Custom versions directory (default: .versions)
-  polis rebuild --posts|--comments|--notifications|--all
-                                  Rebuild indexes and reset state
-  polis index                     View index
-  polis version                   Print CLI version
-  polis about                     Show site, versions, config info
-  polis rotate-key                Generate new keypair and re-sign content
-  polis serve [-d|--data-dir PATH] Start local web server (bundled binary only)
-
-Examples:
-  polis init
-  polis post my-post.md
-  polis comment my-comment.md https://example.com/posts/hello.md
-  polis preview https://example.com/posts/hello.md
-  polis blessing requests
-  polis discover
-`)
+	if len(cmdArgs) > 0 && isHelpArg(cmdArgs[0]) && c.Name != "help" {
+		unmountRemoteDataDir()
+		printCommandHelp(c)
+		return
+	}
+	c.Run(cmdArgs)
+	if !unmountRemoteDataDir() {
+		os.Exit(1)
+	}
 }
 
 // getDataDir returns the data directory, defaulting to current working directory
@@ -261,73 +219,72 @@ func getDataDir() string {
 	return cwd
 }
 
-// exitError prints an error message and exits
+// exitError prints an error message and exits. The message is shown in the
+// configured locale; JSON output stays in English for scripts.
 func exitError(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
 	if jsonOutput {
 		output := map[string]interface{}{
 			"success": false,
-			"error":   msg,
+			"error":   fmt.Sprintf(format, args...),
 		}
 		json.NewEncoder(os.Stdout).Encode(output)
 	} else {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
+		fmt.Fprintf(os.Stderr, "%s %s\n", i18n.T(locale, "Error:"), i18n.Sprintf(locale, format, args...))
 	}
+	// Write back what a failed command changed, as it would stay in a local directory
+	unmountRemoteDataDir()
 	os.Exit(1)
 }
 
+// exit writes back a remote data directory's changes and exits.
+func exit(code int) {
+	unmountRemoteDataDir()
+	os.Exit(code)
+}
+
 // outputJSON outputs a JSON response
 func outputJSON(data interface{}) {
 	json.NewEncoder(os.Stdout).Encode(data)
 }
 
+// parseSetFlag records a --set key=value override.
+func parseSetFlag(kv string) {
+	key, value, ok := strings.Cut(kv, "=")
+	if !ok {
+		exitError("--set expects key=value, got %q", kv)
+	}
+	setting, found := config.Lookup(key)
+	if !found {
+		exitError("Unknown setting: %s (known: %s)", key, strings.Join(config.Keys(), ", "))
+	}
+	if err := setting.Validate(value); err != nil {
+		exitError("%v", err)
+	}
+	flagOverrides[key] = value
+}
+
+// setLogLevel sets the console level for warnings logged by pkg/*.
+func setLogLevel(name string) {
+	level, err := logging.ParseLevel(name)
+	if err != nil {
+		exitError("%v", err)
+	}
+	logLevel = name
+	logging.SetDefault(slog.New(logging.NewConsoleHandler(os.Stderr, level, false)))
+}
+
 // loadEnv loads a .env file into the process environment.
-// Search order: cwd/.env → ~/.polis/.env (matches bash CLI).
+// Search order: <data-dir>/.env → cwd/.env → ~/.polis/.env.
 // Does NOT override existing environment variables.
 func loadEnv() {
-	// Try current working directory first
-	cwd, err := os.Getwd()
-	if err == nil {
-		if loadEnvFile(filepath.Join(cwd, ".env")) {
-			return
-		}
-	}
-	// Fallback: ~/.polis/.env
-	if home, err := os.UserHomeDir(); err == nil {
-		loadEnvFile(filepath.Join(home, ".polis", ".env"))
+	if path := config.FindEnvFile(dataDir); path != "" {
+		loadEnvFile(path)
 	}
+	config.ApplySecrets(getDataDir())
 }
 
 // loadEnvFile reads a KEY=VALUE file and sets env vars that aren't already set.
 // Returns true if the file was found and loaded.
 func loadEnvFile(path string) bool {
-	f, err := os.Open(path)
-	if err != nil {
-		return false
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		// Remove surrounding quotes
-		if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
-			(value[0] == '\'' && value[len(value)-1] == '\'')) {
-			value = value[1 : len(value)-1]
-		}
-		// Don't override existing env vars
-		if os.Getenv(key) == "" {
-			os.Setenv(key, value)
-		}
-	}
-	return true
+	return config.ApplyEnvFile(path)
 }
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestPrintUsage(t *testing.T) {
@@ -137,6 +139,27 @@ func TestGetDataDirOverride(t *testing.T) {
 	}
 }
 
+func TestHandleServe(t *testing.T) {
+	oldDataDir, oldWebFS, oldHandler := dataDir, WebFS, ServeHandler
+	defer func() { dataDir, WebFS, ServeHandler = oldDataDir, oldWebFS, oldHandler }()
+
+	var got ServeOptions
+	WebFS = fstest.MapFS{"index.html": {Data: []byte("ok")}}
+	ServeHandler = func(opts ServeOptions) { got = opts }
+
+	handleServe([]string{"-d", "/custom/site"})
+
+	if got.DataDir != "/custom/site" {
+		t.Errorf("DataDir = %q, want /custom/site", got.DataDir)
+	}
+	if got.WebFS == nil {
+		t.Fatal("WebFS was not passed to the serve handler")
+	}
+	if data, err := fs.ReadFile(got.WebFS, "index.html"); err != nil || string(data) != "ok" {
+		t.Errorf("index.html = %q, %v", data, err)
+	}
+}
+
 func TestLoadEnvFile_Basic(t *testing.T) {
 	dir := t.TempDir()
 	envFile := filepath.Join(dir, ".env")
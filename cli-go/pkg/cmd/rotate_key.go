@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/dnskey"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
 
@@ -24,6 +26,11 @@ func handleRotateKey(args []string) {
 
 	keysDir := filepath.Join(dir, ".polis", "keys")
 	privateKeyPath := filepath.Join(keysDir, "id_ed25519")
+	if privKey, err := os.ReadFile(privateKeyPath); err == nil {
+		if cert, _ := delegation.FromKey(privKey); cert != nil {
+			exitError("This is device %q; rotate the site key on the machine that holds it", cert.Device)
+		}
+	}
 	publicKeyPath := filepath.Join(keysDir, "id_ed25519.pub")
 	oldPrivateKeyPath := filepath.Join(keysDir, "id_ed25519.old")
 	oldPublicKeyPath := filepath.Join(keysDir, "id_ed25519.pub.old")
@@ -99,6 +106,24 @@ func handleRotateKey(args []string) {
 		fmt.Println("  1. Re-sign all posts with: polis republish <path>")
 		fmt.Println("  2. Re-sign all comments with: polis republish <path>")
 		fmt.Println("  3. Deploy the updated files")
+		if _, ok := wkJSON["proofs"]; ok {
+			fmt.Println("  4. Re-sign identity proofs with: polis identity prove <service> <account>")
+		}
+		if devices, ok := wkJSON["devices"].([]interface{}); ok && len(devices) > 0 {
+			fmt.Println("  [!] Device certificates were signed by the old key. Re-delegate each")
+			fmt.Println("      device with: polis device add <name> <device key>")
+		}
+		domain, _ := wkJSON["domain"].(string)
+		if domain == "" {
+			domain = extractDomain(siteBaseURL(dir))
+		}
+		if domain != "" {
+			if keys, _ := dnskey.Lookup(domain); len(keys) > 0 {
+				fmt.Printf("  [!] Replace the DNS record at %s with:\n", dnskey.Name(domain))
+				fmt.Printf("      %s\n", dnskey.Record(string(pubSSH)))
+				fmt.Println("      Until then, other sites will reject your new key")
+			}
+		}
 	}
 
 	if jsonOutput {
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/daemon"
+	"github.com/vdibart/polis-cli/cli-go/pkg/rpc"
+)
+
+// handleRPC serves the JSON-RPC socket for editor integrations in the
+// foreground until interrupted.
+func handleRPC(args []string) {
+	fs := flag.NewFlagSet("rpc", flag.ExitOnError)
+	socket := fs.String("socket", "", "Socket path (default: .polis/rpc.sock)")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	applyPublishConfig(loadConfig())
+
+	path := *socket
+	if path == "" {
+		path = daemon.SiteSocketPath(dir, rpc.SocketFilename)
+	}
+	ln, err := daemon.ListenSocket(path)
+	if err != nil {
+		exitError("Failed to open RPC socket: %v", err)
+	}
+	defer os.Remove(path)
+
+	srv := rpc.NewSiteServer(rpc.SiteOptions{DataDir: dir, Version: Version})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "rpc",
+			"data":    map[string]interface{}{"socket": path, "methods": srv.Methods()},
+		})
+	} else {
+		fmt.Printf("[i] JSON-RPC listening on %s (Ctrl-C to stop)\n", path)
+	}
+	if err := srv.Serve(ln); err != nil {
+		exitError("RPC socket failed: %v", err)
+	}
+}
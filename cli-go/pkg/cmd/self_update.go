@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/selfupdate"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func handleSelfUpdate(args []string) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	check := fs.Bool("check", false, "Only check for a newer release")
+	channel := fs.String("channel", "", "Release channel: stable or beta (default: update_channel setting)")
+	migrate := fs.Bool("migrate", false, "Run data migrations for this version and exit")
+	fs.Parse(args)
+
+	if *migrate {
+		migrateSiteData()
+		return
+	}
+
+	cfg := loadConfig()
+	if *channel == "" {
+		*channel = cfg.Get("update_channel")
+	}
+	rel, err := selfupdate.Latest(cfg.Get("update_url"), *channel)
+	if err != nil {
+		exitError("%v", err)
+	}
+	available := selfupdate.CompareVersions(rel.Version(), Version) > 0
+
+	if *check || !available {
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"status":  "success",
+				"command": "self-update",
+				"data": map[string]interface{}{
+					"current":          Version,
+					"latest":           rel.Version(),
+					"channel":          *channel,
+					"update_available": available,
+					"updated":          false,
+				},
+			})
+		} else if available {
+			fmt.Printf("[i] polis %s is available on the %s channel (you have %s)\n", rel.Version(), *channel, Version)
+			fmt.Println("[i] Run: polis self-update")
+		} else {
+			fmt.Printf("[✓] polis %s is up to date (%s channel)\n", Version, *channel)
+		}
+		return
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		exitError("Failed to locate the polis executable: %v", err)
+	}
+	binary := selfupdate.BinaryName(exePath)
+	if !jsonOutput {
+		fmt.Printf("[i] Downloading %s %s...\n", binary, rel.Version())
+	}
+	data, err := selfupdate.Download(rel, binary)
+	if err != nil {
+		if errors.Is(err, selfupdate.ErrNoReleaseKey) {
+			exitError("Cannot self-update: %v", err)
+		}
+		exitError("Update failed: %v", err)
+	}
+	if err := selfupdate.Install(exePath, data); err != nil {
+		exitError("Update failed: %v", err)
+	}
+
+	// The new binary knows its own data migrations, so let it run them
+	migrated := false
+	if _, err := os.Stat(filepath.Join(getDataDir(), ".well-known", "polis")); err == nil {
+		cmd := exec.Command(exePath, "--data-dir", getDataDir(), "self-update", "--migrate")
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			exitError("Updated to %s, but data migration failed: %v (re-run: polis self-update --migrate)", rel.Version(), err)
+		}
+		migrated = true
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "self-update",
+			"data": map[string]interface{}{
+				"previous": Version,
+				"current":  rel.Version(),
+				"channel":  *channel,
+				"path":     exePath,
+				"updated":  true,
+				"migrated": migrated,
+			},
+		})
+		return
+	}
+	fmt.Printf("[✓] Updated polis %s → %s (%s)\n", Version, rel.Version(), exePath)
+	if migrated {
+		fmt.Println("[✓] Site data migrated")
+	}
+}
+
+// migrateSiteData brings the site's on-disk data up to this version. It runs
+// in the newly installed binary, so new releases add their migrations here.
+func migrateSiteData() {
+	dir := getDataDir()
+	if _, err := os.Stat(filepath.Join(dir, ".well-known", "polis")); err != nil {
+		exitError("No polis site found in %s", dir)
+	}
+	if _, err := site.UpgradeWellKnown(dir); err != nil {
+		exitError("Failed to upgrade .well-known/polis: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "self-update",
+			"data":    map[string]interface{}{"migrated": true, "version": Version},
+		})
+	}
+}
@@ -2,33 +2,163 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/access"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remoteadmin"
 )
 
-// ServeHandler is the function that handles the serve command.
+// ServeOptions are passed to ServeHandler when the web server is started.
+type ServeOptions struct {
+	DataDir  string
+	LogLevel string // --log-level (empty = use the log_level setting)
+	WebFS    fs.FS  // Web UI assets
+
+	// Listen is the address to listen on (--listen); empty picks a free
+	// port on localhost.
+	Listen string
+
+	// Remote, set by --remote, serves the web UI for the server connected
+	// with polis remote-admin instead of a local site.
+	Remote *remoteadmin.Connection
+
+	// MultiSite, set by --multi-site, also serves every registered
+	// workspace; API requests pick one by name.
+	MultiSite bool
+}
+
+// WebFS holds the web UI assets served by polis serve. The bundled binary
+// sets it to its embedded files; it is nil in the CLI-only binary.
+var WebFS fs.FS
+
+// ServeHandler runs the web server in the foreground until it is stopped.
 // In the CLI-only binary, this prints a message directing users to the bundled binary.
 // In the bundled binary, this is overridden by the webapp's serve implementation.
-var ServeHandler func(args []string) = defaultServeHandler
+var ServeHandler func(opts ServeOptions) = defaultServeHandler
 
-func defaultServeHandler(args []string) {
+func defaultServeHandler(opts ServeOptions) {
 	fmt.Fprintln(os.Stderr, "The serve command requires the bundled binary (polis-full).")
 	fmt.Fprintln(os.Stderr, "Download from: https://github.com/vdibart/polis-cli/releases")
 	os.Exit(1)
 }
 
 func handleServe(args []string) {
-	for _, arg := range args {
-		if arg == "--help" || arg == "-h" {
-			fmt.Print(`Usage: polis serve [options]
+	// --data-dir and --log-level are global flags; -d is serve's short form
+	opts := ServeOptions{LogLevel: logLevel, WebFS: WebFS}
+	remote, printConfig := false, false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-d" && i+1 < len(args):
+			dataDir, dataDirFlag = args[i+1], true
+			i++
+		case strings.HasPrefix(arg, "-d="):
+			dataDir, dataDirFlag = strings.TrimPrefix(arg, "-d="), true
+		case arg == "--listen" && i+1 < len(args):
+			opts.Listen = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--listen="):
+			opts.Listen = strings.TrimPrefix(arg, "--listen=")
+		case arg == "--remote":
+			remote = true
+		case arg == "--multi-site":
+			opts.MultiSite = true
+		case arg == "--print-config":
+			printConfig = true
+		default:
+			exitError("Unknown serve option: %s (see: polis serve --help)", arg)
+		}
+	}
 
-Start the local web server (bundled binary only).
+	if printConfig {
+		printServeConfig(opts)
+		return
+	}
+	if WebFS == nil {
+		defaultServeHandler(ServeOptions{})
+	}
+	if remote && opts.MultiSite {
+		exitError("--multi-site serves local sites; it can't be combined with --remote")
+	}
+	if remote {
+		conn, err := remoteadmin.Load()
+		if err != nil {
+			exitError("%v", err)
+		}
+		opts.Remote = conn
+	} else {
+		opts.DataDir = serveDataDir()
+	}
+	ServeHandler(opts)
+}
 
-Options:
-  -d, --data-dir PATH    Polis site directory (default: current directory)
-  -h, --help             Show this help message
-`)
-			return
+// serveSettings are the settings polis serve reads at startup, in the order
+// --print-config shows them.
+var serveSettings = []string{"listen", "base_url", "discovery_url", "discovery_key", "admin_token", "log_level"}
+
+// printServeConfig prints the configuration polis serve would start with
+// and where each value comes from, so a container's environment can be
+// checked without starting the server. Secrets are masked.
+func printServeConfig(opts ServeOptions) {
+	dir := serveDataDir()
+	dirSource := config.LayerDefault
+	switch {
+	case dataDirFlag:
+		dirSource = config.LayerFlag
+	case os.Getenv(DataDirEnv) != "":
+		dirSource = config.LayerEnvironment
+	}
+
+	flags := map[string]string{"listen": opts.Listen}
+	for key, value := range flagOverrides {
+		flags[key] = value
+	}
+	cfg, err := config.Load(config.Options{DataDir: dir, Flags: flags})
+	if err != nil {
+		exitError("Failed to load config: %v", err)
+	}
+
+	values := []config.Value{{Key: "data_dir", Value: dir, Source: dirSource}}
+	for _, key := range serveSettings {
+		v, _ := cfg.Value(key)
+		v.Value = v.Masked()
+		if key == "log_level" && opts.LogLevel != "" {
+			v.Value, v.Source = opts.LogLevel, config.LayerFlag // --log-level
+		}
+		values = append(values, v)
+	}
+	users, err := access.Load(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "serve",
+			"data": map[string]interface{}{
+				"env_file":  cfg.EnvFile,
+				"settings":  values,
+				"api_users": len(users),
+			},
+		})
+		return
+	}
+
+	if cfg.EnvFile != "" {
+		fmt.Printf("[i] Loaded .env from %s\n", cfg.EnvFile)
+	}
+	for _, v := range values {
+		value := v.Value
+		if value == "" {
+			value = "(unset)"
 		}
+		fmt.Printf("  %-22s %-40s [%s]\n", v.Key, value, v.Source)
+	}
+	fmt.Printf("  %-22s %d\n", "api_users", len(users))
+	if cfg.Get("admin_token") == "" && len(users) == 0 {
+		fmt.Println("[i] No admin_token or API users: serve only listens on localhost")
 	}
-	ServeHandler(args)
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stats"
+)
+
+func handleStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	months := fs.Int("months", 12, "Number of recent months to list")
+	fs.Parse(args)
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	discoveryDomain := discovery.ExtractDomainFromURL(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+
+	s := stats.Compute(dir, discoveryDomain, site.GetAuthorDomain(dir))
+	if err := stats.Save(dir, s); err != nil {
+		exitError("Failed to save stats: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "stats",
+			"data":    s,
+		})
+		return
+	}
+
+	fmt.Printf("Posts:             %d", s.Posts)
+	if s.AvgDaysBetweenPosts > 0 {
+		fmt.Printf(" (every %.1f days on average)", s.AvgDaysBetweenPosts)
+	}
+	fmt.Println()
+	fmt.Printf("Comments received: %d\n", s.CommentsReceived)
+	fmt.Printf("Comments given:    %d\n", s.CommentsGiven)
+	if s.BlessingsTimed > 0 {
+		fmt.Printf("Time to blessing:  %.1f hours on average (%d blessings)\n", s.AvgTimeToBlessingHours, s.BlessingsTimed)
+	}
+
+	if rows := s.Months(); len(rows) > 0 {
+		if *months > 0 && len(rows) > *months {
+			rows = rows[:*months]
+		}
+		fmt.Printf("\n%-8s  %5s  %8s  %5s\n", "Month", "Posts", "Received", "Given")
+		for _, m := range rows {
+			fmt.Printf("%-8s  %5d  %8d  %5d\n", m.Month, m.Posts, m.Received, m.Given)
+		}
+	}
+
+	if len(s.TopDomains) > 0 {
+		fmt.Println("\nMost active with:")
+		for _, d := range s.TopDomains {
+			fmt.Printf("  %-32s %d received, %d given\n", d.Domain, d.Received, d.Given)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
+	"github.com/vdibart/polis-cli/cli-go/pkg/deploy"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// discoveryStatus reports whether the discovery service is reachable.
+type discoveryStatus struct {
+	URL       string `json:"url"`
+	Checked   bool   `json:"checked"`
+	Reachable bool   `json:"reachable"`
+	Stream    string `json:"stream_status,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func handleStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	targetName := fs.String("target", "", "Deploy target to compare against")
+	offline := fs.Bool("offline", false, "Skip checking the live site and discovery service")
+	fs.Parse(args)
+
+	dir := getDataDir()
+
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+
+	validation := site.Validate(dir)
+
+	discoveryURL := os.Getenv("DISCOVERY_SERVICE_URL")
+	if discoveryURL == "" {
+		discoveryURL = "https://ltfpezriiaqvjupxbttw.supabase.co/functions/v1"
+	}
+	discoveryDomain := extractDomain(discoveryURL)
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+	counts := site.CountAll(dir, discoveryDomain)
+
+	ds := discoveryStatus{URL: discoveryURL}
+	if !*offline {
+		ds.Checked = true
+		client := discovery.NewClient(discoveryURL, os.Getenv("DISCOVERY_SERVICE_KEY"))
+		if health, err := client.StreamHealth(); err != nil {
+			ds.Error = err.Error()
+		} else {
+			ds.Reachable = true
+			ds.Stream = health.Status
+		}
+	}
+
+	cfg, err := deploy.LoadConfig(dir)
+	if err != nil {
+		exitError("%v", err)
+	}
+	var target *deploy.Target
+	if len(cfg.Targets) > 0 || *targetName != "" {
+		target, err = cfg.Find(*targetName)
+		if err != nil {
+			exitError("%v", err)
+		}
+	}
+
+	siteURL := ""
+	if !*offline {
+		siteURL = baseURL
+		if siteURL == "" {
+			siteURL = getBaseURLFromSite(dir)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	st, err := deploy.CheckStatus(ctx, dir, target, siteURL, nil)
+	if err != nil {
+		exitError("Failed to check deploy status: %v", err)
+	}
+
+	// Posts deployed from another copy of the site
+	var conflicts *conflict.Report
+	conflictErr := ""
+	if siteURL != "" {
+		conflicts, err = conflict.Check(dir, siteURL, remote.NewClient())
+		if err != nil {
+			conflictErr = err.Error()
+		} else {
+			conflict.SaveReport(dir, conflicts)
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "status",
+			"data": map[string]interface{}{
+				"validation": validation,
+				"counts":     counts,
+				"discovery":  ds,
+				"deploy":     st,
+				"conflicts":  conflicts,
+			},
+		})
+		return
+	}
+
+	fmt.Println("=== Site ===")
+	if validation.Status == site.StatusValid {
+		fmt.Println("[✓] Configuration valid")
+	} else {
+		fmt.Printf("[!] Configuration %s\n", validation.Status)
+		for _, e := range validation.Errors {
+			fmt.Printf("[!]   %s\n", e.Message)
+			if e.Suggestion != "" {
+				fmt.Printf("[i]     %s\n", e.Suggestion)
+			}
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("=== Content ===")
+	fmt.Printf("[i] Posts: %d (%d drafts)\n", counts.Posts, counts.Drafts)
+	fmt.Printf("[i] My comments: %d blessed, %d pending, %d denied, %d drafts\n",
+		counts.MyBlessed, counts.MyPending, counts.MyDenied, counts.MyCommentDrafts)
+	fmt.Printf("[i] Comments on my posts: %d\n", counts.IncomingBlessed)
+	fmt.Println()
+
+	fmt.Println("=== Social ===")
+	fmt.Printf("[i] Feed: %d unread of %d\n", counts.FeedUnread, counts.Feed)
+	if counts.BlessingRequests > 0 {
+		fmt.Printf("[!] Pending blessing requests: %d\n", counts.BlessingRequests)
+	} else {
+		fmt.Println("[i] Pending blessing requests: 0")
+	}
+	fmt.Printf("[i] Following: %d, followers: %d\n", counts.Following, counts.Followers)
+	fmt.Printf("[i] Unread notifications: %d\n", counts.NotificationsUnread)
+	fmt.Println()
+
+	fmt.Println("=== Discovery ===")
+	fmt.Printf("[i] Service: %s\n", ds.URL)
+	switch {
+	case !ds.Checked:
+		fmt.Println("[i] Connectivity not checked (--offline)")
+	case ds.Reachable:
+		fmt.Printf("[✓] Reachable (stream %s)\n", ds.Stream)
+	default:
+		fmt.Printf("[!] Unreachable: %s\n", ds.Error)
+	}
+	fmt.Println()
+
+	fmt.Println("=== Deploy Status ===")
+	if st.Target == "" {
+		fmt.Println("[i] No deploy targets configured")
+	} else {
+		fmt.Printf("[i] Target: %s\n", st.Target)
+		if st.DeployedAt == "" {
+			fmt.Println("[i] Last deploy: never")
+		} else {
+			fmt.Printf("[i] Last deploy: %s\n", st.DeployedAt)
+		}
+		if st.UpToDate {
+			fmt.Println("[✓] No local changes since last deploy")
+		} else {
+			fmt.Printf("[!] Local changes not yet deployed: %d changed, %d removed\n", st.PendingCount, st.DeleteCount)
+		}
+	}
+
+	if st.Live {
+		for _, s := range st.Sentinels {
+			detail := s.Status
+			if s.Error != "" {
+				detail += " (" + s.Error + ")"
+			}
+			fmt.Printf("[i]   %-24s %s\n", s.Path, detail)
+		}
+		if st.Drift {
+			fmt.Println("[!] Live site differs from the last deploy")
+		} else {
+			fmt.Println("[✓] Live site matches")
+		}
+	}
+
+	fmt.Println()
+
+	fmt.Println("=== Sync ===")
+	switch {
+	case siteURL == "":
+		fmt.Println("[i] Live site not checked")
+	case conflictErr != "":
+		fmt.Printf("[!] Could not check the live site: %s\n", conflictErr)
+	case len(conflicts.Conflicts) == 0:
+		fmt.Printf("[✓] No posts changed from elsewhere (%d checked)\n", conflicts.Checked)
+	default:
+		fmt.Printf("[!] %d posts changed on the live site from another copy:\n", len(conflicts.Conflicts))
+		for _, c := range conflicts.Conflicts {
+			fmt.Printf("[!]   %s (%s)\n", c.Path, strings.ReplaceAll(c.Kind, "_", " "))
+		}
+		fmt.Println("[i] Resolve with 'polis resolve <path> pull|keep|merge-frontmatter'")
+	}
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/timestamp"
+)
+
+// timestampPublished anchors a published or republished post's version
+// when a time-stamping authority is configured. Failures are reported but
+// don't fail the publish.
+func timestampPublished(dir string, result *publish.PublishResult) {
+	tsa := loadConfig().Get("timestamp.tsa")
+	if tsa == "" {
+		return
+	}
+	proof, err := timestamp.Anchor(dir, tsa, result.Path)
+	if jsonOutput {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Timestamping failed: %v (retry with: polis timestamp %s)\n", err, result.Path)
+		return
+	}
+	fmt.Printf("[✓] Timestamped %s at %s\n", shortHash(proof.Version), proof.Time.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+func handleTimestamp(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		exitError("Usage: polis timestamp <posts/YYYYMMDD/post.md>\n       polis timestamp verify <posts/YYYYMMDD/post.md> [--ca-file <pem>]")
+	}
+	if args[0] == "verify" {
+		timestampVerify(args[1:])
+		return
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	postPath := fsutil.SiteRelPath(dir, args[0])
+	proof, err := timestamp.Anchor(dir, loadConfig().Get("timestamp.tsa"), postPath)
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "timestamp",
+			"data":    proof,
+		})
+		return
+	}
+	fmt.Printf("[✓] Timestamped %s at %s\n", shortHash(proof.Version), proof.Time.UTC().Format("2006-01-02T15:04:05Z"))
+	fmt.Printf("    Proof: %s\n", proof.File)
+}
+
+func timestampVerify(args []string) {
+	fs := flag.NewFlagSet("timestamp verify", flag.ExitOnError)
+	caFile := fs.String("ca-file", "", "PEM root certificate of the TSA, if not a system root")
+	var postArg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		postArg, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if postArg == "" {
+		exitError("Usage: polis timestamp verify <posts/YYYYMMDD/post.md> [--ca-file <pem>]")
+	}
+
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found)")
+	}
+	var roots *x509.CertPool
+	if *caFile != "" {
+		pem, err := os.ReadFile(*caFile)
+		if err != nil {
+			exitError("Failed to read CA file: %v", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			exitError("No certificates in %s", *caFile)
+		}
+	}
+
+	postPath := fsutil.SiteRelPath(dir, postArg)
+	proofs, err := timestamp.Verify(dir, postPath)
+	if err != nil {
+		exitError("%v", err)
+	}
+	failed := 0
+	for i := range proofs {
+		if roots != nil && proofs[i].Error == "" {
+			proofs[i].Trusted = timestamp.VerifyChain(dir, &proofs[i], roots) == nil
+		}
+		if proofs[i].Error != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		status := "success"
+		if failed > 0 || len(proofs) == 0 {
+			status = "error"
+		}
+		outputJSON(map[string]interface{}{
+			"status":  status,
+			"command": "timestamp",
+			"data": map[string]interface{}{
+				"path":   postPath,
+				"proofs": proofs,
+			},
+		})
+		if status == "error" {
+			exit(1)
+		}
+		return
+	}
+
+	if len(proofs) == 0 {
+		exitError("No timestamp proofs for %s", postPath)
+	}
+	for _, p := range proofs {
+		label := shortHash(p.Version)
+		if p.Current {
+			label += " (current)"
+		}
+		if p.Error != "" {
+			fmt.Printf("[✗] %s: %s\n", label, p.Error)
+			continue
+		}
+		fmt.Printf("[✓] %s existed by %s\n", label, p.Time.UTC().Format("2006-01-02T15:04:05Z"))
+		if p.Trusted {
+			fmt.Printf("    Signed by %s\n", p.TSA)
+		} else {
+			fmt.Printf("    Signed by %s (not a trusted root; pass its CA with --ca-file)\n", p.TSA)
+		}
+	}
+	if failed > 0 {
+		exit(1)
+	}
+}
+
+// shortHash shortens a sha256:... version hash for display.
+func shortHash(version string) string {
+	h := strings.TrimPrefix(version, "sha256:")
+	if len(h) > 12 {
+		h = h[:12]
+	}
+	return h
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/trash"
+)
+
+func handleTrash(args []string) {
+	if len(args) < 1 {
+		printTrashUsage()
+		exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		handleTrashList(args[1:])
+	case "restore":
+		handleTrashRestore(args[1:])
+	case "purge":
+		handleTrashPurge(args[1:])
+	case "help", "--help", "-h":
+		printTrashUsage()
+	default:
+		if strings.HasPrefix(args[0], "-") {
+			printTrashUsage()
+			exit(1)
+		}
+		handleTrashMove(args)
+	}
+}
+
+func printTrashUsage() {
+	fmt.Print(`Usage: polis trash <file>
+       polis trash <subcommand> [args]
+
+Moves a draft to the trash, or unpublishes a post into it.
+
+Subcommands:
+  list                  List trashed items and when they expire
+  restore <id>          Put an item back where it was
+  purge <id>            Permanently delete an item
+  purge --all           Empty the trash
+
+Examples:
+  polis trash .polis/posts/drafts/idea.md
+  polis trash posts/20260125/hello.md
+  polis trash list
+  polis trash restore 20260301-120000-1a2b3c4d
+`)
+}
+
+// trashDir returns the site directory after purging expired items.
+func trashDir() string {
+	dir := getDataDir()
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory")
+	}
+	if n, err := trash.PurgeExpired(dir, loadConfig().Int("trash_retention_days")); err == nil && n > 0 && !jsonOutput {
+		fmt.Printf("[i] Purged %d expired item(s) from trash\n", n)
+	}
+	return dir
+}
+
+func handleTrashMove(args []string) {
+	dir := trashDir()
+
+	relPath := args[0]
+	if filepath.IsAbs(relPath) {
+		rel, err := filepath.Rel(dir, relPath)
+		if err != nil {
+			exitError("File is not in the site directory: %s", args[0])
+		}
+		relPath = rel
+	}
+
+	item, err := trash.Move(dir, relPath)
+	if err != nil {
+		if errors.Is(err, trash.ErrUnsupported) {
+			exitError("%v: %s", err, args[0])
+		}
+		exitError("Failed to move to trash: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "trash",
+			"data":    item,
+		})
+		return
+	}
+	if item.Kind == trash.KindPost {
+		fmt.Printf("[✓] Unpublished %s (trash ID %s)\n", item.Path, item.ID)
+		fmt.Println("[i] Run 'polis render' to update the index pages and feeds")
+	} else {
+		fmt.Printf("[✓] Moved %s to trash (ID %s)\n", item.Path, item.ID)
+	}
+	fmt.Printf("[i] Restore with: polis trash restore %s\n", item.ID)
+}
+
+func handleTrashList(args []string) {
+	dir := trashDir()
+	retention := loadConfig().Int("trash_retention_days")
+
+	items, err := trash.List(dir)
+	if err != nil {
+		exitError("Failed to list trash: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "trash list",
+			"data": map[string]interface{}{
+				"items":          items,
+				"retention_days": retention,
+			},
+		})
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Trash is empty.")
+		return
+	}
+	for _, item := range items {
+		title := item.Title
+		if title == "" {
+			title = item.Path
+		}
+		fmt.Printf("%s  %-13s  %s\n", item.ID, item.Kind, title)
+		fmt.Printf("    %s  (expires %s)\n", item.Path, item.ExpiresAt(retention).Format("2006-01-02"))
+	}
+}
+
+func handleTrashRestore(args []string) {
+	if len(args) < 1 {
+		exitError("Usage: polis trash restore <id>")
+	}
+	dir := trashDir()
+
+	item, err := trash.Restore(dir, args[0])
+	if err != nil {
+		switch {
+		case errors.Is(err, trash.ErrNotFound):
+			exitError("No trash item with ID %s", args[0])
+		case errors.Is(err, trash.ErrExists):
+			exitError("Cannot restore: %v", err)
+		}
+		exitError("Failed to restore: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "trash restore",
+			"data":    item,
+		})
+		return
+	}
+	fmt.Printf("[✓] Restored %s\n", item.Path)
+	if item.Kind == trash.KindPost {
+		fmt.Println("[i] Run 'polis render' to republish it on the index pages and feeds")
+	}
+}
+
+func handleTrashPurge(args []string) {
+	fs := flag.NewFlagSet("trash purge", flag.ExitOnError)
+	all := fs.Bool("all", false, "Permanently delete everything in the trash")
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if id == "" && !*all {
+		exitError("Usage: polis trash purge <id> | --all")
+	}
+	dir := trashDir()
+
+	var ids []string
+	if *all {
+		items, err := trash.List(dir)
+		if err != nil {
+			exitError("Failed to list trash: %v", err)
+		}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+	} else {
+		ids = []string{id}
+	}
+	for _, id := range ids {
+		if err := trash.Purge(dir, id); err != nil {
+			if errors.Is(err, trash.ErrNotFound) {
+				exitError("No trash item with ID %s", id)
+			}
+			exitError("Failed to purge %s: %v", id, err)
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "trash purge",
+			"data":    map[string]interface{}{"purged": len(ids)},
+		})
+		return
+	}
+	fmt.Printf("[✓] Permanently deleted %d item(s)\n", len(ids))
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
+)
+
+func handleWorkspace(args []string) {
+	if len(args) < 1 {
+		workspaceList()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		workspaceList()
+	case "add":
+		workspaceAdd(args[1:])
+	case "use":
+		workspaceUse(args[1:])
+	case "remove":
+		workspaceRemove(args[1:])
+	default:
+		exitError("Unknown workspace subcommand. Use: polis workspace [list|add|use|remove]")
+	}
+}
+
+// serveDataDir returns the site polis serve works on: the one given by
+// --data-dir or POLIS_DATA_DIR, the current directory if it is a site, or
+// else the active workspace.
+func serveDataDir() string {
+	dir := getDataDir()
+	if dataDir != "" || isPolisSite(dir) {
+		return dir
+	}
+	if active := workspace.ActiveDir(); active != "" {
+		return active
+	}
+	return dir
+}
+
+func workspaceAdd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		exitError("Usage: polis workspace add <name> [path]")
+	}
+	dir := getDataDir()
+	if len(args) == 2 {
+		dir = args[1]
+	}
+	if !isPolisSite(dir) {
+		exitError("Not a polis site directory (no .well-known/polis found): %s", dir)
+	}
+	ws, err := workspace.Add(args[0], dir)
+	if errors.Is(err, workspace.ErrExists) {
+		exitError("A workspace named %s already exists", args[0])
+	}
+	if err != nil {
+		exitError("Failed to add workspace: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "workspace",
+			"data":    ws,
+		})
+		return
+	}
+	fmt.Printf("[✓] Added %s: %s\n", ws.Name, ws.Path)
+	if workspace.ActiveDir() == ws.Path {
+		fmt.Println("[i] It is the active workspace: polis serve uses it outside a site directory")
+	}
+}
+
+func workspaceUse(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis workspace use <name>")
+	}
+	ws, err := workspace.Use(args[0])
+	if errors.Is(err, workspace.ErrNotFound) {
+		exitError("No workspace named %s (see: polis workspace list)", args[0])
+	}
+	if err != nil {
+		exitError("Failed to switch workspace: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "workspace",
+			"data":    ws,
+		})
+		return
+	}
+	fmt.Printf("[✓] Active workspace: %s (%s)\n", ws.Name, ws.Path)
+	if !isPolisSite(ws.Path) {
+		fmt.Println("[!] That directory no longer holds a polis site")
+	}
+}
+
+func workspaceRemove(args []string) {
+	if len(args) != 1 {
+		exitError("Usage: polis workspace remove <name>")
+	}
+	if err := workspace.Remove(args[0]); err != nil {
+		exitError("Failed to remove workspace: %v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "workspace",
+			"data":    map[string]interface{}{"removed": args[0]},
+		})
+		return
+	}
+	fmt.Printf("[✓] Removed %s (the site directory is untouched)\n", args[0])
+}
+
+func workspaceList() {
+	reg, err := workspace.Load()
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		outputJSON(map[string]interface{}{
+			"status":  "success",
+			"command": "workspace",
+			"data":    reg,
+		})
+		return
+	}
+	if len(reg.Workspaces) == 0 {
+		fmt.Println("[i] No workspaces; add a site with: polis workspace add <name> [path]")
+		return
+	}
+	for _, ws := range reg.Workspaces {
+		marker := " "
+		if ws.Name == reg.Active {
+			marker = "*"
+		}
+		status := ""
+		if _, err := os.Stat(ws.Path); err != nil {
+			status = " (missing)"
+		}
+		fmt.Printf("%s %-20s %s%s\n", marker, ws.Name, ws.Path, status)
+	}
+}
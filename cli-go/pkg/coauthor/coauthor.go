@@ -0,0 +1,204 @@
+// Package coauthor lets several people publish on one site, each with their
+// own key.
+//
+// .well-known/polis lists the site's co-authors by name with their public
+// keys. A co-author's private key is kept in .polis/keys/authors/<name>, with
+// a block naming the author after the key, and everything it signs carries a
+// signed-by field with that name in the signed frontmatter. Readers check
+// the content against the named author's listed key. Removing an author
+// keeps them listed with the time of removal, so what they signed before
+// still verifies.
+package coauthor
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// Dir holds co-authors' private keys, relative to the site directory.
+const Dir = ".polis/keys/authors"
+
+// PEMType is the block naming the author in a co-author's private key file.
+const PEMType = "POLIS AUTHOR"
+
+// Field is the frontmatter field naming the co-author who signed content.
+const Field = "signed-by"
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// ErrRemoved is returned for content signed after its author was removed.
+var ErrRemoved = errors.New("author was removed from the site")
+
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+
+// Author is a co-author listed in .well-known/polis.
+type Author struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"` // Public key, OpenSSH format
+	Added   string `json:"added"`
+	Removed string `json:"removed,omitempty"`
+}
+
+// ValidName reports whether name can name a co-author: lowercase letters,
+// digits, dots, dashes, and underscores.
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// New returns the listing for a co-author's public key.
+func New(name, publicKey string) (*Author, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid author name %q (use lowercase letters, digits, '.', '-', '_')", name)
+	}
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 || fields[0] != "ssh-ed25519" {
+		return nil, errors.New("author key must be an ssh-ed25519 public key")
+	}
+	return &Author{
+		Name:  name,
+		Key:   fields[0] + " " + fields[1],
+		Added: time.Now().UTC().Format(timeFormat),
+	}, nil
+}
+
+// Active reports whether the author could sign at time at: listed before
+// then, and not yet removed.
+func (a *Author) Active(at time.Time) error {
+	if added, err := time.Parse(timeFormat, a.Added); err == nil && at.Before(added) {
+		return fmt.Errorf("signed before %s was added to the site", a.Name)
+	}
+	if a.Removed == "" {
+		return nil
+	}
+	removed, err := time.Parse(timeFormat, a.Removed)
+	if err != nil || !at.Before(removed) {
+		return fmt.Errorf("%w (%s, %s)", ErrRemoved, a.Name, a.Removed)
+	}
+	return nil
+}
+
+// Find returns the listed author named name, or nil.
+func Find(authors []Author, name string) *Author {
+	for i := range authors {
+		if authors[i].Name == name {
+			return &authors[i]
+		}
+	}
+	return nil
+}
+
+// KeyPath returns the file a co-author's private key is kept in.
+func KeyPath(dataDir, name string) string {
+	return filepath.Join(dataDir, filepath.FromSlash(Dir), name)
+}
+
+// Generate creates a key for a co-author in the site's key directory and
+// returns its public key. An existing key is kept.
+func Generate(dataDir, name string) (string, error) {
+	if !ValidName(name) {
+		return "", fmt.Errorf("invalid author name %q", name)
+	}
+	path := KeyPath(dataDir, name)
+	if pub, err := os.ReadFile(path + ".pub"); err == nil {
+		return strings.TrimSpace(string(pub)), nil
+	}
+	privPEM, pubSSH, err := signing.GenerateKeypair()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, Attach(privPEM, name), 0600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path+".pub", pubSSH, 0644); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(pubSSH)), nil
+}
+
+// LoadKey reads a co-author's private key.
+func LoadKey(dataDir, name string) ([]byte, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid author name %q", name)
+	}
+	data, err := os.ReadFile(KeyPath(dataDir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no key for author %q on this machine (run: polis coauthor init %s)", name, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if FromKey(data) != name {
+		return nil, fmt.Errorf("key file for %q names a different author", name)
+	}
+	return data, nil
+}
+
+// Local lists the co-authors whose keys are on this machine.
+func Local(dataDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(dataDir, filepath.FromSlash(Dir)))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && ValidName(e.Name()) && !strings.HasSuffix(e.Name(), ".pub") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// Attach returns keyPEM with a block naming the author after the key,
+// replacing any already there.
+func Attach(keyPEM []byte, name string) []byte {
+	var out []byte
+	rest := keyPEM
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != PEMType {
+			out = append(out, pem.EncodeToMemory(block)...)
+		}
+		rest = r
+	}
+	return append(out, pem.EncodeToMemory(&pem.Block{Type: PEMType, Bytes: []byte(name)})...)
+}
+
+// FromKey returns the author named in a private key file, or "" for the
+// site's own key.
+func FromKey(keyPEM []byte) string {
+	rest := keyPEM
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return ""
+		}
+		if block.Type == PEMType {
+			return string(block.Bytes)
+		}
+		rest = r
+	}
+}
+
+// FrontmatterLine returns the frontmatter line, with its leading newline,
+// naming the co-author whose key signs. It is empty for the site's own key.
+func FrontmatterLine(keyPEM []byte) string {
+	name := FromKey(keyPEM)
+	if name == "" {
+		return ""
+	}
+	return "\n" + Field + ": " + name
+}
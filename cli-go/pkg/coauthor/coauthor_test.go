@@ -0,0 +1,111 @@
+package coauthor
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestGenerateAndLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	pub, err := Generate(dir, "alice")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if again, _ := Generate(dir, "alice"); again != pub {
+		t.Error("Generate replaced an existing key")
+	}
+
+	key, err := LoadKey(dir, "alice")
+	if err != nil {
+		t.Fatalf("LoadKey: %v", err)
+	}
+	if FromKey(key) != "alice" {
+		t.Errorf("FromKey = %q, want alice", FromKey(key))
+	}
+	if line := FrontmatterLine(key); line != "\nsigned-by: alice" {
+		t.Errorf("FrontmatterLine = %q", line)
+	}
+
+	// The key file still signs, and verifies against the listed key
+	sig, err := signing.SignContent([]byte("hello"), key)
+	if err != nil {
+		t.Fatalf("signing with an author key file: %v", err)
+	}
+	author, err := New("alice", pub)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if ok, _ := signing.VerifySignature([]byte("hello"), []byte(author.Key), sig); !ok {
+		t.Error("signature doesn't verify against the listed key")
+	}
+
+	if got := Local(dir); len(got) != 1 || got[0] != "alice" {
+		t.Errorf("Local = %v", got)
+	}
+	if _, err := LoadKey(dir, "bob"); err == nil {
+		t.Error("LoadKey found a key that doesn't exist")
+	}
+
+	// A key file renamed to another author is refused
+	os.WriteFile(KeyPath(dir, "bob"), key, 0600)
+	if _, err := LoadKey(dir, "bob"); err == nil {
+		t.Error("LoadKey accepted a key naming another author")
+	}
+}
+
+func TestSiteKey(t *testing.T) {
+	priv, _, _ := signing.GenerateKeypair()
+	if FromKey(priv) != "" || FrontmatterLine(priv) != "" {
+		t.Error("the site key named an author")
+	}
+	// Attaching again replaces the name
+	named := Attach(Attach(priv, "alice"), "bob")
+	if n := strings.Count(string(named), "BEGIN "+PEMType); n != 1 || FromKey(named) != "bob" {
+		t.Errorf("re-attached file has %d names, FromKey = %q", n, FromKey(named))
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, pub, _ := signing.GenerateKeypair()
+	if _, err := New("Alice Smith", string(pub)); err == nil {
+		t.Error("New accepted an invalid name")
+	}
+	if _, err := New("alice", "ssh-rsa AAAA"); err == nil {
+		t.Error("New accepted a key that isn't ed25519")
+	}
+}
+
+func TestActive(t *testing.T) {
+	a := Author{Name: "alice", Added: "2026-01-01T00:00:00Z"}
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(timeFormat, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if err := a.Active(at("2026-02-01T00:00:00Z")); err != nil {
+		t.Errorf("Active: %v", err)
+	}
+	if err := a.Active(at("2025-12-31T00:00:00Z")); err == nil {
+		t.Error("Active accepted content signed before the author was added")
+	}
+
+	a.Removed = "2026-03-01T00:00:00Z"
+	if err := a.Active(at("2026-02-01T00:00:00Z")); err != nil {
+		t.Errorf("content signed before removal: %v", err)
+	}
+	if err := a.Active(at("2026-03-02T00:00:00Z")); !errors.Is(err, ErrRemoved) {
+		t.Errorf("content signed after removal = %v, want ErrRemoved", err)
+	}
+
+	if Find([]Author{a}, "alice") == nil || Find([]Author{a}, "bob") != nil {
+		t.Error("Find")
+	}
+}
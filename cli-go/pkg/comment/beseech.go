@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
 
@@ -45,8 +46,18 @@ type BeseechResult struct {
 // If dsCfg is non-nil, it overrides package-level discovery globals for
 // multi-tenant safety. Pass nil to use globals (single-tenant / CLI mode).
 //
+// The outcome is recorded in the comment outbox (see OutboxFile); failed
+// requests are retried by RetryDeliveries. An explicit beseech restarts the
+// retry count.
+//
 // Returns an error if discovery is not configured or the request fails.
 func BeseechComment(dataDir, commentID string, privateKey []byte, dsCfg ...*DiscoveryConfig) (*BeseechResult, error) {
+	return beseech(dataDir, commentID, privateKey, false, dsCfg...)
+}
+
+// beseech sends the blessing request. retry is set for RetryDeliveries,
+// which counts toward MaxDeliveryAttempts rather than starting over.
+func beseech(dataDir, commentID string, privateKey []byte, retry bool, dsCfg ...*DiscoveryConfig) (result *BeseechResult, err error) {
 	var dsURL, dsKey, baseURL string
 	if len(dsCfg) > 0 && dsCfg[0] != nil {
 		dsURL = dsCfg[0].DiscoveryURL
@@ -70,6 +81,11 @@ func BeseechComment(dataDir, commentID string, privateKey []byte, dsCfg ...*Disc
 	if err != nil {
 		return nil, fmt.Errorf("comment not found in pending: %w", err)
 	}
+	defer func() {
+		if rerr := recordAttempt(dataDir, commentID, signed.Meta.InReplyTo, !retry, result != nil && result.AutoBlessed, err, time.Now()); rerr != nil {
+			logging.Warn("Failed to update comment outbox", "comment", commentID, "err", rerr)
+		}
+	}()
 
 	// Publish the comment to the public comments/ directory before DS registration.
 	// This makes the comment accessible via HTTPS so the post owner can fetch it
@@ -126,7 +142,7 @@ func BeseechComment(dataDir, commentID string, privateKey []byte, dsCfg ...*Disc
 		return nil, fmt.Errorf("register: %w", err)
 	}
 
-	result := &BeseechResult{
+	result = &BeseechResult{
 		Success: resp.Success,
 		Status:  resp.Status,
 		Message: resp.Message,
@@ -12,9 +12,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 )
 
@@ -98,7 +102,9 @@ type SignedComment struct {
 	Signature string       `json:"signature"`
 }
 
-// GenerateCommentID generates a unique comment ID from the target post and timestamp.
+// GenerateCommentID generates a unique comment ID from the target post and
+// timestamp: "<subdomain>-<post-slug>-<ulid>". The lowercase ULID suffix keeps
+// IDs distinct when several comments on one post are created together.
 func GenerateCommentID(inReplyTo string, timestamp time.Time) string {
 	// Extract domain and slug from in_reply_to URL
 	// e.g., https://alice.polis.site/posts/20260127/hello-world.md
@@ -122,8 +128,13 @@ func GenerateCommentID(inReplyTo string, timestamp time.Time) string {
 		slug = "post"
 	}
 
-	dateStr := timestamp.Format("20060102")
-	return fmt.Sprintf("%s-%s-%s", domain, slug, dateStr)
+	return fmt.Sprintf("%s-%s-%s", domain, slug, strings.ToLower(idgen.NewAt(timestamp)))
+}
+
+// statusName returns the storage name of a comment in a private status
+// directory, .polis/comments/<status>/<id>.md.
+func statusName(status, id string) string {
+	return ".polis/comments/" + status + "/" + id + ".md"
 }
 
 // SaveDraft saves a comment draft to the private drafts directory.
@@ -132,11 +143,6 @@ func SaveDraft(dataDir string, draft *CommentDraft) error {
 	draft.InReplyTo = polisurl.NormalizeToMD(draft.InReplyTo)
 	draft.RootPost = polisurl.NormalizeToMD(draft.RootPost)
 
-	draftsDir := filepath.Join(dataDir, ".polis", "comments", StatusDrafts)
-	if err := os.MkdirAll(draftsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create drafts directory: %w", err)
-	}
-
 	if draft.ID == "" {
 		draft.ID = GenerateCommentID(draft.InReplyTo, time.Now().UTC())
 	}
@@ -162,8 +168,7 @@ updated_at: %s
 
 %s`, draft.InReplyTo, draft.RootPost, draft.CreatedAt, draft.UpdatedAt, draft.Content)
 
-	draftPath := filepath.Join(draftsDir, draft.ID+".md")
-	if err := os.WriteFile(draftPath, []byte(content), 0644); err != nil {
+	if err := storage.For(dataDir).WriteFile(statusName(StatusDrafts, draft.ID), []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to save draft: %w", err)
 	}
 
@@ -172,8 +177,7 @@ updated_at: %s
 
 // LoadDraft loads a comment draft by ID.
 func LoadDraft(dataDir, id string) (*CommentDraft, error) {
-	draftPath := filepath.Join(dataDir, ".polis", "comments", StatusDrafts, id+".md")
-	data, err := os.ReadFile(draftPath)
+	data, err := storage.For(dataDir).ReadFile(statusName(StatusDrafts, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read draft: %w", err)
 	}
@@ -195,8 +199,7 @@ func LoadDraft(dataDir, id string) (*CommentDraft, error) {
 
 // ListDrafts returns all comment drafts.
 func ListDrafts(dataDir string) ([]*CommentDraft, error) {
-	draftsDir := filepath.Join(dataDir, ".polis", "comments", StatusDrafts)
-	entries, err := os.ReadDir(draftsDir)
+	entries, err := storage.For(dataDir).ReadDir(".polis/comments/" + StatusDrafts)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []*CommentDraft{}, nil
@@ -222,8 +225,7 @@ func ListDrafts(dataDir string) ([]*CommentDraft, error) {
 
 // DeleteDraft removes a comment draft.
 func DeleteDraft(dataDir, id string) error {
-	draftPath := filepath.Join(dataDir, ".polis", "comments", StatusDrafts, id+".md")
-	if err := os.Remove(draftPath); err != nil {
+	if err := storage.For(dataDir).Remove(statusName(StatusDrafts, id)); err != nil {
 		return fmt.Errorf("failed to delete draft: %w", err)
 	}
 	return nil
@@ -276,6 +278,14 @@ func SignComment(dataDir string, draft *CommentDraft, authorIdentity, siteURL st
 		rootPost = draft.InReplyTo
 	}
 
+	// A device key embeds its delegation certificate in the signed fields,
+	// and a co-author's key the author's name
+	delegationLine, err := delegation.FrontmatterLine(privateKey, delegation.ScopeComment)
+	if err != nil {
+		return nil, err
+	}
+	delegationLine += coauthor.FrontmatterLine(privateKey)
+
 	// Build CLI-compatible frontmatter (without signature first)
 	// CLI format uses nested in-reply-to with url and root-post
 	unsignedFrontmatter := fmt.Sprintf(`---
@@ -288,7 +298,7 @@ in-reply-to:
   root-post: %s
 current-version: sha256:%s
 version-history:
-  - sha256:%s (%s)
+  - sha256:%s (%s)%s
 ---`,
 		escapeYAMLTitle(title),
 		timestampStr,
@@ -298,6 +308,7 @@ version-history:
 		hash,
 		hash,
 		timestampStr,
+		delegationLine,
 	)
 
 	// Canonicalize full content for signing (matches CLI behavior)
@@ -325,7 +336,7 @@ in-reply-to:
   root-post: %s
 current-version: sha256:%s
 version-history:
-  - sha256:%s (%s)
+  - sha256:%s (%s)%s
 signature: %s
 ---`,
 		escapeYAMLTitle(title),
@@ -337,20 +348,14 @@ signature: %s
 		hash,
 		hash,
 		timestampStr,
+		delegationLine,
 		sigBase64,
 	)
 
 	finalContent := finalFrontmatter + "\n\n" + content
 
-	// Create pending directory (private)
-	pendingDir := filepath.Join(dataDir, ".polis", "comments", StatusPending)
-	if err := os.MkdirAll(pendingDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create pending directory: %w", err)
-	}
-
-	// Write to pending
-	pendingPath := filepath.Join(pendingDir, commentID+".md")
-	if err := os.WriteFile(pendingPath, []byte(finalContent), 0644); err != nil {
+	// Write to pending (private)
+	if err := storage.For(dataDir).WriteFile(statusName(StatusPending, commentID), []byte(finalContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write pending comment: %w", err)
 	}
 
@@ -359,6 +364,10 @@ signature: %s
 		_ = DeleteDraft(dataDir, draft.ID)
 	}
 
+	// Track the blessing request, so it is sent even if the caller's
+	// beseech doesn't happen or fails
+	_ = queueDelivery(dataDir, commentID, draft.InReplyTo, time.Now())
+
 	meta := &CommentMeta{
 		ID:             commentID,
 		Title:          title,
@@ -384,6 +393,8 @@ signature: %s
 // Other statuses use .polis/comments/<status>/.
 // When moving to blessed, also adds the comment to public.jsonl for CLI compatibility.
 func MoveComment(dataDir, commentID, fromStatus, toStatus string) error {
+	fsys := storage.For(dataDir)
+
 	// Determine source path
 	var fromPath string
 	if fromStatus == StatusBlessed {
@@ -394,11 +405,11 @@ func MoveComment(dataDir, commentID, fromStatus, toStatus string) error {
 		}
 		fromPath = foundPath
 	} else {
-		fromPath = filepath.Join(dataDir, ".polis", "comments", fromStatus, commentID+".md")
+		fromPath = statusName(fromStatus, commentID)
 	}
 
 	// Read source file
-	data, err := os.ReadFile(fromPath)
+	data, err := fsys.ReadFile(fromPath)
 	if err != nil {
 		return fmt.Errorf("failed to read comment: %w", err)
 	}
@@ -423,27 +434,19 @@ func MoveComment(dataDir, commentID, fromStatus, toStatus string) error {
 			}
 		}
 		dateDir := timestamp.Format("20060102")
-		toDir := filepath.Join(dataDir, "comments", dateDir)
-		if err := os.MkdirAll(toDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
-		toPath = filepath.Join(toDir, commentID+".md")
-		relativePath = filepath.Join("comments", dateDir, commentID+".md")
+		relativePath = "comments/" + dateDir + "/" + commentID + ".md"
+		toPath = relativePath
 	} else {
-		toDir := filepath.Join(dataDir, ".polis", "comments", toStatus)
-		if err := os.MkdirAll(toDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
-		toPath = filepath.Join(toDir, commentID+".md")
+		toPath = statusName(toStatus, commentID)
 	}
 
 	// Write to destination
-	if err := os.WriteFile(toPath, data, 0644); err != nil {
+	if err := fsys.WriteFile(toPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write comment: %w", err)
 	}
 
 	// Remove source
-	if err := os.Remove(fromPath); err != nil {
+	if err := fsys.Remove(fromPath); err != nil {
 		return fmt.Errorf("failed to remove source comment: %w", err)
 	}
 
@@ -505,6 +508,11 @@ func MoveComment(dataDir, commentID, fromStatus, toStatus string) error {
 		}
 	}
 
+	// The post's author answered the blessing request
+	if toStatus == StatusBlessed || toStatus == StatusDenied {
+		_ = setDeliveryStatus(dataDir, commentID, toStatus, time.Now())
+	}
+
 	return nil
 }
 
@@ -514,8 +522,8 @@ func MoveComment(dataDir, commentID, fromStatus, toStatus string) error {
 // of blessing status (matching bash CLI behavior).
 func PublishComment(dataDir, commentID string) error {
 	// Read the pending comment
-	pendingPath := filepath.Join(dataDir, ".polis", "comments", StatusPending, commentID+".md")
-	data, err := os.ReadFile(pendingPath)
+	fsys := storage.For(dataDir)
+	data, err := fsys.ReadFile(statusName(StatusPending, commentID))
 	if err != nil {
 		return fmt.Errorf("failed to read pending comment: %w", err)
 	}
@@ -532,15 +540,9 @@ func PublishComment(dataDir, commentID string) error {
 	}
 	dateDir := timestamp.Format("20060102")
 
-	// Create target directory: comments/YYYYMMDD/
-	targetDir := filepath.Join(dataDir, "comments", dateDir)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create comments directory: %w", err)
-	}
-
-	// Copy markdown file to public location
-	mdPath := filepath.Join(targetDir, commentID+".md")
-	if err := os.WriteFile(mdPath, data, 0644); err != nil {
+	// Copy markdown file to public location: comments/YYYYMMDD/
+	relativePath := "comments/" + dateDir + "/" + commentID + ".md"
+	if err := fsys.WriteFile(relativePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write public comment: %w", err)
 	}
 
@@ -549,7 +551,6 @@ func PublishComment(dataDir, commentID string) error {
 	// that gets overwritten anyway.
 
 	// Append to public.jsonl
-	relativePath := filepath.Join("comments", dateDir, commentID+".md")
 
 	// Parse nested in-reply-to for the index entry
 	inReplyToURL, _ := ParseNestedInReplyTo(content)
@@ -583,12 +584,13 @@ func PublishComment(dataDir, commentID string) error {
 }
 
 // findBlessedComment searches for a blessed comment in the date-based directory structure.
-// Structure: comments/YYYYMMDD/comment-id.md
+// Structure: comments/YYYYMMDD/comment-id.md. Returns the comment's storage
+// name, which is also its path relative to the site.
 func findBlessedComment(dataDir, commentID string) (bool, string) {
-	commentsDir := filepath.Join(dataDir, "comments")
+	fsys := storage.For(dataDir)
 
 	// Walk through date directories (YYYYMMDD format)
-	dateDirs, err := os.ReadDir(commentsDir)
+	dateDirs, err := fsys.ReadDir("comments")
 	if err != nil {
 		return false, ""
 	}
@@ -597,8 +599,8 @@ func findBlessedComment(dataDir, commentID string) (bool, string) {
 		if !dateDir.IsDir() {
 			continue
 		}
-		commentPath := filepath.Join(commentsDir, dateDir.Name(), commentID+".md")
-		if _, err := os.Stat(commentPath); err == nil {
+		commentPath := "comments/" + dateDir.Name() + "/" + commentID + ".md"
+		if storage.Exists(fsys, commentPath) {
 			return true, commentPath
 		}
 	}
@@ -615,8 +617,9 @@ func ListComments(dataDir, status string) ([]*CommentMeta, error) {
 		return listBlessedComments(dataDir)
 	}
 
-	commentsDir := filepath.Join(dataDir, ".polis", "comments", status)
-	entries, err := os.ReadDir(commentsDir)
+	fsys := storage.For(dataDir)
+	commentsDir := ".polis/comments/" + status
+	entries, err := fsys.ReadDir(commentsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []*CommentMeta{}, nil
@@ -630,8 +633,7 @@ func ListComments(dataDir, status string) ([]*CommentMeta, error) {
 			continue
 		}
 
-		commentPath := filepath.Join(commentsDir, entry.Name())
-		data, err := os.ReadFile(commentPath)
+		data, err := fsys.ReadFile(commentsDir + "/" + entry.Name())
 		if err != nil {
 			continue
 		}
@@ -683,10 +685,10 @@ func ListComments(dataDir, status string) ([]*CommentMeta, error) {
 // Parses CLI-compatible frontmatter format.
 func listBlessedComments(dataDir string) ([]*CommentMeta, error) {
 	var comments []*CommentMeta
-	commentsDir := filepath.Join(dataDir, "comments")
+	fsys := storage.For(dataDir)
 
 	// Walk through date directories (YYYYMMDD format)
-	dateDirs, err := os.ReadDir(commentsDir)
+	dateDirs, err := fsys.ReadDir("comments")
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []*CommentMeta{}, nil
@@ -698,10 +700,10 @@ func listBlessedComments(dataDir string) ([]*CommentMeta, error) {
 		if !dateDir.IsDir() {
 			continue
 		}
-		datePath := filepath.Join(commentsDir, dateDir.Name())
+		datePath := "comments/" + dateDir.Name()
 
 		// List comment files in this date directory
-		files, err := os.ReadDir(datePath)
+		files, err := fsys.ReadDir(datePath)
 		if err != nil {
 			continue
 		}
@@ -712,13 +714,11 @@ func listBlessedComments(dataDir string) ([]*CommentMeta, error) {
 			}
 
 			// Skip comments that still exist in pending (published-but-pending, not yet blessed)
-			pendingPath := filepath.Join(dataDir, ".polis", "comments", StatusPending, file.Name())
-			if _, err := os.Stat(pendingPath); err == nil {
+			if storage.Exists(fsys, ".polis/comments/"+StatusPending+"/"+file.Name()) {
 				continue
 			}
 
-			commentPath := filepath.Join(datePath, file.Name())
-			data, err := os.ReadFile(commentPath)
+			data, err := fsys.ReadFile(datePath + "/" + file.Name())
 			if err != nil {
 				continue
 			}
@@ -780,10 +780,10 @@ func GetComment(dataDir, commentID, status string) (*SignedComment, error) {
 		}
 		commentPath = foundPath
 	} else {
-		commentPath = filepath.Join(dataDir, ".polis", "comments", status, commentID+".md")
+		commentPath = statusName(status, commentID)
 	}
 
-	data, err := os.ReadFile(commentPath)
+	data, err := storage.For(dataDir).ReadFile(commentPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read comment: %w", err)
 	}
@@ -995,26 +995,24 @@ func escapeYAMLTitle(s string) string {
 // ensureUniqueCommentID checks for comment ID collisions across all status directories.
 // Appends -2, -3, etc. if a collision is found.
 func ensureUniqueCommentID(dataDir, commentID string) string {
+	fsys := storage.For(dataDir)
 	candidate := commentID
 	suffix := 2
 	for {
 		collision := false
 		// Check all private status dirs
 		for _, status := range []string{StatusDrafts, StatusPending, StatusDenied} {
-			path := filepath.Join(dataDir, ".polis", "comments", status, candidate+".md")
-			if _, err := os.Stat(path); err == nil {
+			if storage.Exists(fsys, statusName(status, candidate)) {
 				collision = true
 				break
 			}
 		}
 		// Check blessed (public) comments date dirs
 		if !collision {
-			commentsDir := filepath.Join(dataDir, "comments")
-			if dateDirs, err := os.ReadDir(commentsDir); err == nil {
+			if dateDirs, err := fsys.ReadDir("comments"); err == nil {
 				for _, dd := range dateDirs {
 					if dd.IsDir() {
-						path := filepath.Join(commentsDir, dd.Name(), candidate+".md")
-						if _, err := os.Stat(path); err == nil {
+						if storage.Exists(fsys, "comments/"+dd.Name()+"/"+candidate+".md") {
 							collision = true
 							break
 						}
@@ -1078,3 +1076,51 @@ func ParseNestedInReplyTo(content string) (url, rootPost string) {
 
 	return url, rootPost
 }
+
+// legacyDraftID matches comment draft IDs from before ULIDs:
+// "<subdomain>-<slug>-YYYYMMDD" with an optional "-N" collision suffix.
+var legacyDraftID = regexp.MustCompile(`^(.+)-(\d{8})(?:-\d+)?$`)
+
+// MigrateDraftIDs renames comment drafts that still use date-based IDs to
+// ULID-suffixed IDs, keeping each draft's created_at as the ULID timestamp.
+// Only drafts are migrated; pending and blessed comments already have public
+// URLs. Returns the number of drafts renamed.
+func MigrateDraftIDs(dataDir string) (int, error) {
+	fsys := storage.For(dataDir)
+	entries, err := fsys.ReadDir(".polis/comments/" + StatusDrafts)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read drafts directory: %w", err)
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		oldID := strings.TrimSuffix(entry.Name(), ".md")
+		m := legacyDraftID.FindStringSubmatch(oldID)
+		if m == nil {
+			continue
+		}
+
+		created, err := time.Parse("20060102", m[2])
+		if draft, loadErr := LoadDraft(dataDir, oldID); loadErr == nil {
+			if t, parseErr := time.Parse(time.RFC3339, draft.CreatedAt); parseErr == nil {
+				created, err = t, nil
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		newID := m[1] + "-" + strings.ToLower(idgen.NewAt(created))
+		if err := fsys.Rename(statusName(StatusDrafts, oldID), statusName(StatusDrafts, newID)); err != nil {
+			return migrated, fmt.Errorf("failed to rename draft %s: %w", oldID, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
 
@@ -350,3 +353,55 @@ Test comment ` + id
 		t.Errorf("after second blessing: comment_count = %v, want 2", m2["comment_count"])
 	}
 }
+
+func TestGenerateCommentID_UniqueWithinSecond(t *testing.T) {
+	ts := time.Date(2026, 1, 27, 12, 0, 0, 0, time.UTC)
+	a := GenerateCommentID("https://alice.polis.site/posts/20260127/hello-world.md", ts)
+	b := GenerateCommentID("https://alice.polis.site/posts/20260127/hello-world.md", ts)
+	if a == b {
+		t.Fatalf("expected distinct IDs, got %s twice", a)
+	}
+	if !strings.HasPrefix(a, "alice-hello-world-") || !idgen.IsULID(strings.TrimPrefix(a, "alice-hello-world-")) {
+		t.Errorf("unexpected ID format: %s", a)
+	}
+}
+
+func TestMigrateDraftIDs(t *testing.T) {
+	dataDir := t.TempDir()
+	draftsDir := filepath.Join(dataDir, ".polis", "comments", "drafts")
+	os.MkdirAll(draftsDir, 0755)
+	os.WriteFile(filepath.Join(draftsDir, "alice-hello-20260101.md"), []byte("---\nin_reply_to: https://alice.polis.site/posts/20260101/hello.md\ncreated_at: 2026-01-01T10:00:00Z\n---\n\nHi"), 0644)
+	os.WriteFile(filepath.Join(draftsDir, "alice-hello-20260101-2.md"), []byte("---\ncreated_at: 2026-01-01T10:00:00Z\n---\n\nAgain"), 0644)
+	current := "alice-hello-" + strings.ToLower(idgen.New())
+	os.WriteFile(filepath.Join(draftsDir, current+".md"), []byte("---\n---\n"), 0644)
+
+	n, err := MigrateDraftIDs(dataDir)
+	if err != nil {
+		t.Fatalf("MigrateDraftIDs: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("migrated %d drafts, want 2", n)
+	}
+
+	drafts, _ := ListDrafts(dataDir)
+	if len(drafts) != 3 {
+		t.Fatalf("expected 3 drafts after migration, got %d", len(drafts))
+	}
+	for _, d := range drafts {
+		suffix := strings.TrimPrefix(d.ID, "alice-hello-")
+		if !idgen.IsULID(suffix) {
+			t.Errorf("draft %s was not migrated", d.ID)
+			continue
+		}
+		if d.ID == current {
+			continue
+		}
+		if ts, _ := idgen.Time(suffix); !ts.Equal(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("draft %s timestamp = %v, want created_at", d.ID, ts)
+		}
+	}
+
+	if n, _ := MigrateDraftIDs(dataDir); n != 0 {
+		t.Errorf("second run migrated %d drafts, want 0", n)
+	}
+}
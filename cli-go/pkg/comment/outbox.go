@@ -0,0 +1,235 @@
+package comment
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// OutboxFile tracks the delivery of every signed comment's blessing request
+// (beseech), relative to the site root.
+const OutboxFile = ".polis/comments/outbox.json"
+
+// Delivery statuses.
+const (
+	// DeliveryPending is a signed comment whose blessing request hasn't been
+	// sent yet.
+	DeliveryPending = "pending"
+	// DeliveryDelivered is a request the discovery service accepted; the
+	// post's author hasn't answered it yet.
+	DeliveryDelivered = "delivered"
+	// DeliveryBlessed and DeliveryDenied are the author's answer.
+	DeliveryBlessed = "blessed"
+	DeliveryDenied  = "denied"
+	// DeliveryFailed is a request that couldn't be sent. It is retried at
+	// NextAttempt, until MaxDeliveryAttempts.
+	DeliveryFailed = "failed"
+)
+
+// MaxDeliveryAttempts is how many times a blessing request is sent before
+// giving up. Beseeching the comment again starts over.
+const MaxDeliveryAttempts = 10
+
+// Backoff between delivery attempts: doubling from the first delay, up to
+// the maximum. A newly signed comment waits the first delay too, so the
+// request sent right after signing isn't raced by a retry.
+const (
+	firstRetryDelay = time.Minute
+	maxRetryDelay   = 6 * time.Hour
+)
+
+// Delivery is the state of one comment's blessing request.
+type Delivery struct {
+	CommentID   string `json:"comment_id"`
+	InReplyTo   string `json:"in_reply_to,omitempty"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	LastAttempt string `json:"last_attempt,omitempty"`
+	NextAttempt string `json:"next_attempt,omitempty"` // Empty once delivered, or after the last attempt
+	LastError   string `json:"last_error,omitempty"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// outbox is the on-disk format of OutboxFile.
+type outbox struct {
+	Deliveries map[string]*Delivery `json:"deliveries"`
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	d := firstRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
+
+func loadOutbox(fsys storage.FS) (*outbox, error) {
+	ob := &outbox{Deliveries: map[string]*Delivery{}}
+	data, err := fsys.ReadFile(OutboxFile)
+	if os.IsNotExist(err) {
+		return ob, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ob); err != nil {
+		return nil, err
+	}
+	if ob.Deliveries == nil {
+		ob.Deliveries = map[string]*Delivery{}
+	}
+	return ob, nil
+}
+
+// updateOutbox runs fn on the outbox under its lock and saves the result.
+func updateOutbox(dataDir string, fn func(ob *outbox)) error {
+	fsys := storage.For(dataDir)
+	return storage.With(fsys, OutboxFile, func() error {
+		ob, err := loadOutbox(fsys)
+		if err != nil {
+			return err
+		}
+		fn(ob)
+		data, err := json.MarshalIndent(ob, "", "  ")
+		if err != nil {
+			return err
+		}
+		return fsys.WriteFile(OutboxFile, append(data, '\n'), 0644)
+	})
+}
+
+// ListDeliveries returns every tracked blessing request, most recently
+// updated first.
+func ListDeliveries(dataDir string) ([]Delivery, error) {
+	ob, err := loadOutbox(storage.For(dataDir))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Delivery, 0, len(ob.Deliveries))
+	for _, d := range ob.Deliveries {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UpdatedAt > list[j].UpdatedAt })
+	return list, nil
+}
+
+// GetDelivery returns the comment's blessing request, or nil if it isn't
+// tracked.
+func GetDelivery(dataDir, commentID string) *Delivery {
+	ob, err := loadOutbox(storage.For(dataDir))
+	if err != nil {
+		return nil
+	}
+	return ob.Deliveries[commentID]
+}
+
+// queueDelivery starts tracking a newly signed comment's blessing request.
+func queueDelivery(dataDir, commentID, inReplyTo string, now time.Time) error {
+	return updateOutbox(dataDir, func(ob *outbox) {
+		ob.Deliveries[commentID] = &Delivery{
+			CommentID:   commentID,
+			InReplyTo:   inReplyTo,
+			Status:      DeliveryPending,
+			NextAttempt: now.Add(firstRetryDelay).UTC().Format(time.RFC3339),
+			UpdatedAt:   now.UTC().Format(time.RFC3339),
+		}
+	})
+}
+
+// recordAttempt notes the outcome of sending a blessing request: failures
+// are scheduled for a retry with backoff. restart counts the attempt as the
+// first, for a request the user sent again by hand.
+func recordAttempt(dataDir, commentID, inReplyTo string, restart, autoBlessed bool, sendErr error, now time.Time) error {
+	return updateOutbox(dataDir, func(ob *outbox) {
+		d := ob.Deliveries[commentID]
+		if d == nil {
+			d = &Delivery{CommentID: commentID, InReplyTo: inReplyTo}
+			ob.Deliveries[commentID] = d
+		}
+		if restart {
+			d.Attempts = 0
+		}
+		d.Attempts++
+		d.LastAttempt = now.UTC().Format(time.RFC3339)
+		d.UpdatedAt = d.LastAttempt
+		d.NextAttempt = ""
+		d.LastError = ""
+		switch {
+		case sendErr != nil:
+			d.Status = DeliveryFailed
+			d.LastError = sendErr.Error()
+			if d.Attempts < MaxDeliveryAttempts {
+				d.NextAttempt = now.Add(retryDelay(d.Attempts)).UTC().Format(time.RFC3339)
+			}
+		case autoBlessed:
+			d.Status = DeliveryBlessed
+		default:
+			d.Status = DeliveryDelivered
+		}
+	})
+}
+
+// setDeliveryStatus records the post author's answer for a tracked request.
+func setDeliveryStatus(dataDir, commentID, status string, now time.Time) error {
+	return updateOutbox(dataDir, func(ob *outbox) {
+		if d := ob.Deliveries[commentID]; d != nil {
+			d.Status = status
+			d.NextAttempt = ""
+			d.LastError = ""
+			d.UpdatedAt = now.UTC().Format(time.RFC3339)
+		}
+	})
+}
+
+// removeDelivery stops tracking a comment's blessing request.
+func removeDelivery(dataDir, commentID string) error {
+	return updateOutbox(dataDir, func(ob *outbox) {
+		delete(ob.Deliveries, commentID)
+	})
+}
+
+// RetryResult lists the blessing requests sent by RetryDeliveries.
+type RetryResult struct {
+	Delivered []string `json:"delivered"`
+	Blessed   []string `json:"blessed"` // Auto-blessed on delivery
+	Failed    []string `json:"failed"`
+}
+
+// RetryDeliveries sends the blessing requests that are due: comments signed
+// but never sent, and failed requests whose backoff has passed. Requests
+// for comments no longer pending (retracted, or answered by other means)
+// are dropped. dsCfg is passed to BeseechComment.
+func RetryDeliveries(dataDir string, privateKey []byte, now time.Time, dsCfg ...*DiscoveryConfig) (*RetryResult, error) {
+	result := &RetryResult{Delivered: []string{}, Blessed: []string{}, Failed: []string{}}
+	deliveries, err := ListDeliveries(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		if d.Status != DeliveryPending && d.Status != DeliveryFailed {
+			continue
+		}
+		next, err := time.Parse(time.RFC3339, d.NextAttempt)
+		if err != nil || next.After(now) {
+			continue
+		}
+		if _, err := GetComment(dataDir, d.CommentID, StatusPending); err != nil {
+			removeDelivery(dataDir, d.CommentID)
+			continue
+		}
+		res, err := beseech(dataDir, d.CommentID, privateKey, true, dsCfg...)
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, d.CommentID)
+		case res.AutoBlessed:
+			result.Blessed = append(result.Blessed, d.CommentID)
+		default:
+			result.Delivered = append(result.Delivered, d.CommentID)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package comment
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		5:  16 * time.Minute,
+		10: 6 * time.Hour,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestRetryDeliveries(t *testing.T) {
+	online := false
+	ds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success": true, "status": "created"}`))
+	}))
+	defer ds.Close()
+	cfg := &DiscoveryConfig{DiscoveryURL: ds.URL, DiscoveryKey: "key", BaseURL: "https://bob.polis.pub"}
+
+	dataDir := t.TempDir()
+	privKey := generateTestKey(t)
+	signed, err := SignComment(dataDir, &CommentDraft{
+		InReplyTo: "https://alice.polis.pub/posts/20260101/hello.md",
+		Content:   "Great post!",
+	}, "bob.polis.pub", "https://bob.polis.pub", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := signed.Meta.ID
+	if d := GetDelivery(dataDir, id); d == nil || d.Status != DeliveryPending || d.Attempts != 0 {
+		t.Fatalf("delivery after signing = %+v", d)
+	}
+
+	// Not due right after signing
+	now := time.Now()
+	if res, _ := RetryDeliveries(dataDir, privKey, now, cfg); len(res.Failed)+len(res.Delivered) != 0 {
+		t.Errorf("retried a just-signed comment: %+v", res)
+	}
+
+	// Discovery offline: the attempt fails and is scheduled again
+	now = now.Add(2 * time.Minute)
+	if res, _ := RetryDeliveries(dataDir, privKey, now, cfg); len(res.Failed) != 1 {
+		t.Fatalf("offline retry = %+v", res)
+	}
+	d := GetDelivery(dataDir, id)
+	if d.Status != DeliveryFailed || d.Attempts != 1 || d.LastError == "" || d.NextAttempt == "" {
+		t.Fatalf("delivery after a failure = %+v", d)
+	}
+
+	online = true
+	next, _ := time.Parse(time.RFC3339, d.NextAttempt)
+	if res, _ := RetryDeliveries(dataDir, privKey, next.Add(time.Second), cfg); len(res.Delivered) != 1 {
+		t.Fatalf("online retry = %+v", res)
+	}
+	if d := GetDelivery(dataDir, id); d.Status != DeliveryDelivered || d.Attempts != 2 || d.NextAttempt != "" || d.LastError != "" {
+		t.Errorf("delivery after success = %+v", d)
+	}
+
+	if err := MoveComment(dataDir, id, StatusPending, StatusDenied); err != nil {
+		t.Fatal(err)
+	}
+	if d := GetDelivery(dataDir, id); d.Status != DeliveryDenied {
+		t.Errorf("delivery after denial = %+v", d)
+	}
+
+	if _, err := RetractComment(dataDir, id, privKey, &DiscoveryConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if d := GetDelivery(dataDir, id); d != nil {
+		t.Errorf("delivery after retracting = %+v", d)
+	}
+}
+
+func TestBeseechComment_RestartsRetries(t *testing.T) {
+	ds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ds.Close()
+	cfg := &DiscoveryConfig{DiscoveryURL: ds.URL, DiscoveryKey: "key", BaseURL: "https://bob.polis.pub"}
+
+	dataDir := t.TempDir()
+	privKey := generateTestKey(t)
+	signed, err := SignComment(dataDir, &CommentDraft{
+		InReplyTo: "https://alice.polis.pub/posts/20260101/hello.md",
+		Content:   "Great post!",
+	}, "bob.polis.pub", "https://bob.polis.pub", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := signed.Meta.ID
+
+	// Retries give up after the last attempt
+	now := time.Now()
+	for i := 0; i < MaxDeliveryAttempts; i++ {
+		now = now.Add(maxRetryDelay + time.Minute)
+		RetryDeliveries(dataDir, privKey, now, cfg)
+	}
+	if d := GetDelivery(dataDir, id); d.Attempts != MaxDeliveryAttempts || d.NextAttempt != "" {
+		t.Fatalf("delivery after the last retry = %+v", d)
+	}
+
+	// Beseeching again by hand schedules retries anew
+	if _, err := BeseechComment(dataDir, id, privKey, cfg); err == nil {
+		t.Fatal("expected the beseech to fail")
+	}
+	if d := GetDelivery(dataDir, id); d.Attempts != 1 || d.NextAttempt == "" {
+		t.Errorf("delivery after beseeching again = %+v", d)
+	}
+}
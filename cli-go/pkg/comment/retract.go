@@ -0,0 +1,123 @@
+package comment
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// EventRetracted is the stream event a commenter publishes when withdrawing
+// a comment. The payload names the comment (source_url) and the post it
+// replied to (target_url, target_domain), so the post's author picks it up
+// with the rest of the events targeting their domain.
+const EventRetracted = "polis.comment.retracted"
+
+// RetractResult contains the result of retracting a comment.
+type RetractResult struct {
+	ID             string `json:"id"`
+	CommentURL     string `json:"comment_url,omitempty"`
+	InReplyTo      string `json:"in_reply_to"`
+	PreviousStatus string `json:"previous_status"`
+	EventPublished bool   `json:"event_published"`
+	EventError     string `json:"event_error,omitempty"`
+}
+
+// RetractComment withdraws a signed comment: it removes the comment from
+// this site (private copy, public copy, and public.jsonl entry) and
+// publishes a signed polis.comment.retracted event so the post's author
+// drops the blessing. Drafts can't be retracted; delete them instead.
+//
+// The local removal is not undone if publishing the event fails; the error
+// is reported in EventError. If dsCfg is non-nil, it overrides the
+// package-level discovery globals.
+func RetractComment(dataDir, commentID string, privateKey []byte, dsCfg ...*DiscoveryConfig) (*RetractResult, error) {
+	var signed *SignedComment
+	status := ""
+	for _, st := range []string{StatusPending, StatusDenied, StatusBlessed} {
+		if c, err := GetComment(dataDir, commentID, st); err == nil {
+			signed, status = c, st
+			break
+		}
+	}
+	if signed == nil {
+		return nil, fmt.Errorf("comment not found: %s", commentID)
+	}
+
+	cfg := DiscoveryConfig{DiscoveryURL: DiscoveryURL, DiscoveryKey: DiscoveryKey, BaseURL: BaseURL}
+	if len(dsCfg) > 0 && dsCfg[0] != nil {
+		cfg = *dsCfg[0]
+	}
+	baseURL := cfg.BaseURL
+
+	result := &RetractResult{
+		ID:             commentID,
+		CommentURL:     signed.Meta.CommentURL,
+		InReplyTo:      signed.Meta.InReplyTo,
+		PreviousStatus: status,
+	}
+
+	// Pending comments are published to comments/ when beseeched, so the
+	// public copy can exist whatever the status
+	fsys := storage.For(dataDir)
+	if found, relPath := findBlessedComment(dataDir, commentID); found {
+		if result.CommentURL == "" && baseURL != "" {
+			result.CommentURL = strings.TrimSuffix(baseURL, "/") + "/" + relPath
+		}
+		for _, p := range []string{relPath, strings.TrimSuffix(relPath, ".md") + ".html"} {
+			if err := fsys.Remove(p); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", p, err)
+			}
+		}
+		if err := metadata.RemoveIndexEntry(dataDir, relPath); err != nil {
+			return nil, fmt.Errorf("failed to update public.jsonl: %w", err)
+		}
+		if err := publish.UpdateManifest(dataDir); err != nil {
+			return nil, fmt.Errorf("failed to update manifest: %w", err)
+		}
+	}
+	if status != StatusBlessed {
+		if err := fsys.Remove(statusName(status, commentID)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove comment: %w", err)
+		}
+	}
+	_ = removeDelivery(dataDir, commentID)
+
+	// Same URL BeseechComment registered with discovery
+	if result.CommentURL == "" && baseURL != "" {
+		if ts, err := time.Parse("2006-01-02T15:04:05Z", signed.Meta.Timestamp); err == nil {
+			result.CommentURL = fmt.Sprintf("%s/comments/%s/%s.md", strings.TrimSuffix(baseURL, "/"), ts.Format("20060102"), commentID)
+		}
+	}
+	if result.CommentURL == "" {
+		result.EventError = "POLIS_BASE_URL not configured"
+		return result, nil
+	}
+	if cfg.DiscoveryURL == "" || cfg.DiscoveryKey == "" {
+		result.EventError = "discovery service not configured"
+		return result, nil
+	}
+
+	payload := map[string]interface{}{
+		"source_url":    result.CommentURL,
+		"source_domain": discovery.ExtractDomainFromURL(result.CommentURL),
+		"target_url":    signed.Meta.InReplyTo,
+		"target_domain": discovery.ExtractDomainFromURL(signed.Meta.InReplyTo),
+	}
+	if signed.Meta.CommentVersion != "" {
+		payload["comment_version"] = signed.Meta.CommentVersion
+	}
+	streamCfg := &stream.DiscoveryConfig{DiscoveryURL: cfg.DiscoveryURL, DiscoveryKey: cfg.DiscoveryKey, BaseURL: baseURL}
+	if err := stream.PublishEvent(EventRetracted, payload, privateKey, streamCfg); err != nil {
+		result.EventError = err.Error()
+		return result, nil
+	}
+	result.EventPublished = true
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRetractComment_RemovesPublishedPendingComment(t *testing.T) {
+	dataDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, ".polis", "comments", "pending"), 0755)
+	os.MkdirAll(filepath.Join(dataDir, "metadata"), 0755)
+
+	commentID := "bob-hello-world-20260215"
+	pendingPath := filepath.Join(dataDir, ".polis", "comments", "pending", commentID+".md")
+	os.WriteFile(pendingPath, []byte(`---
+title: Re: hello-world
+type: comment
+published: 2026-02-15T10:00:00Z
+in-reply-to:
+  url: https://alice.polis.pub/posts/20260215/hello-world.md
+  root-post: https://alice.polis.pub/posts/20260215/hello-world.md
+current-version: sha256:abc123
+author: bob.polis.pub
+signature: fakesig
+---
+
+Never mind.`), 0644)
+	if err := PublishComment(dataDir, commentID); err != nil {
+		t.Fatal(err)
+	}
+
+	// No discovery service: the comment is still removed locally
+	result, err := RetractComment(dataDir, commentID, nil, &DiscoveryConfig{BaseURL: "https://bob.polis.pub"})
+	if err != nil {
+		t.Fatalf("RetractComment failed: %v", err)
+	}
+	if result.PreviousStatus != StatusPending || result.EventPublished || result.EventError == "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.CommentURL != "https://bob.polis.pub/comments/20260215/"+commentID+".md" {
+		t.Errorf("CommentURL = %q", result.CommentURL)
+	}
+	for _, p := range []string{pendingPath, filepath.Join(dataDir, "comments", "20260215", commentID+".md")} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed", p)
+		}
+	}
+	index, _ := os.ReadFile(filepath.Join(dataDir, "metadata", "public.jsonl"))
+	if strings.Contains(string(index), commentID) {
+		t.Errorf("public.jsonl still lists the comment: %s", index)
+	}
+
+	if _, err := RetractComment(dataDir, commentID, nil); err == nil {
+		t.Error("expected error retracting a comment that no longer exists")
+	}
+}
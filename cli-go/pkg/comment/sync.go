@@ -8,6 +8,7 @@ import (
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 // SyncResult contains the results of syncing pending comments.
@@ -32,8 +33,8 @@ func SyncPendingComments(dataDir, baseURL string, discoveryClient *discovery.Cli
 		Errors:       []string{},
 	}
 
-	pendingDir := filepath.Join(dataDir, ".polis", "comments", StatusPending)
-	entries, err := os.ReadDir(pendingDir)
+	fsys := storage.For(dataDir)
+	entries, err := fsys.ReadDir(".polis/comments/" + StatusPending)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return result, nil // No pending directory, nothing to sync
@@ -49,8 +50,7 @@ func SyncPendingComments(dataDir, baseURL string, discoveryClient *discovery.Cli
 		commentID := strings.TrimSuffix(entry.Name(), ".md")
 
 		// Read comment to get version and URL
-		commentPath := filepath.Join(pendingDir, entry.Name())
-		data, err := os.ReadFile(commentPath)
+		data, err := fsys.ReadFile(statusName(StatusPending, commentID))
 		if err != nil {
 			result.Errors = append(result.Errors, "failed to read "+commentID+": "+err.Error())
 			continue
@@ -118,7 +118,7 @@ func SyncPendingComments(dataDir, baseURL string, discoveryClient *discovery.Cli
 					}
 				}
 				dateDir := timestamp.Format("20060102")
-				blessedPath := filepath.Join("comments", dateDir, commentID+".md")
+				blessedPath := filepath.ToSlash(filepath.Join("comments", dateDir, commentID+".md"))
 
 				payload := &hooks.HookPayload{
 					Event:         hooks.EventPostComment,
@@ -193,8 +193,8 @@ func SyncFromEvents(dataDir, baseURL string, events []discovery.StreamEvent, hoo
 	}
 
 	// Scan pending comments and match against events
-	pendingDir := filepath.Join(dataDir, ".polis", "comments", StatusPending)
-	entries, err := os.ReadDir(pendingDir)
+	fsys := storage.For(dataDir)
+	entries, err := fsys.ReadDir(".polis/comments/" + StatusPending)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return result, nil
@@ -208,8 +208,7 @@ func SyncFromEvents(dataDir, baseURL string, events []discovery.StreamEvent, hoo
 		}
 
 		commentID := strings.TrimSuffix(entry.Name(), ".md")
-		commentPath := filepath.Join(pendingDir, entry.Name())
-		data, err := os.ReadFile(commentPath)
+		data, err := fsys.ReadFile(statusName(StatusPending, commentID))
 		if err != nil {
 			result.Errors = append(result.Errors, "failed to read "+commentID+": "+err.Error())
 			continue
@@ -257,7 +256,7 @@ func SyncFromEvents(dataDir, baseURL string, events []discovery.StreamEvent, hoo
 					}
 				}
 				dateDir := timestamp.Format("20060102")
-				blessedPath := filepath.Join("comments", dateDir, commentID+".md")
+				blessedPath := filepath.ToSlash(filepath.Join("comments", dateDir, commentID+".md"))
 
 				payload := &hooks.HookPayload{
 					Event:         hooks.EventPostComment,
@@ -288,8 +287,7 @@ func SyncFromEvents(dataDir, baseURL string, events []discovery.StreamEvent, hoo
 // baseURL is this site's base URL, used to reconstruct comment URLs when missing.
 func SyncSingleComment(dataDir, baseURL, commentID string, discoveryClient *discovery.Client, hookConfig *hooks.HookConfig) (string, error) {
 	// Read comment to get URL (from .polis/comments/pending/)
-	commentPath := filepath.Join(dataDir, ".polis", "comments", StatusPending, commentID+".md")
-	data, err := os.ReadFile(commentPath)
+	data, err := storage.For(dataDir).ReadFile(statusName(StatusPending, commentID))
 	if err != nil {
 		return "", err
 	}
@@ -350,7 +348,7 @@ func SyncSingleComment(dataDir, baseURL, commentID string, discoveryClient *disc
 				}
 			}
 			dateDir := timestamp.Format("20060102")
-			blessedPath := filepath.Join("comments", dateDir, commentID+".md")
+			blessedPath := filepath.ToSlash(filepath.Join("comments", dateDir, commentID+".md"))
 
 			payload := &hooks.HookPayload{
 				Event:         hooks.EventPostComment,
@@ -0,0 +1,741 @@
+// Package config loads polis settings from layered sources.
+//
+// Precedence, lowest first:
+//
+//	defaults < site .env < .polis/webapp-config.json (and metadata/manifest.json)
+//	         < process environment < command-line flags
+//
+// Every setting has an environment variable, so any of them can be set in
+// .env or the environment. `polis config set` writes each setting back to
+// the file it belongs in: connection settings and secrets go to .env,
+// webapp preferences to webapp-config.json, and the theme to the manifest.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/secrets"
+	"github.com/vdibart/polis-cli/cli-go/pkg/selfupdate"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+)
+
+// DefaultDiscoveryURL is the public discovery service used when none is configured.
+const DefaultDiscoveryURL = "https://ltfpezriiaqvjupxbttw.supabase.co/functions/v1"
+
+// Layer identifies where a setting's value came from.
+type Layer int
+
+const (
+	LayerDefault Layer = iota
+	LayerEnvFile
+	LayerKeychain
+	LayerSite
+	LayerEnvironment
+	LayerFlag
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerEnvFile:
+		return "env-file"
+	case LayerKeychain:
+		return "keychain"
+	case LayerSite:
+		return "site"
+	case LayerEnvironment:
+		return "environment"
+	case LayerFlag:
+		return "flag"
+	default:
+		return "default"
+	}
+}
+
+// MarshalText renders a layer by name in JSON output.
+func (l Layer) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// Store is the file a setting is written to by Set.
+type Store int
+
+const (
+	StoreEnvFile  Store = iota // <site>/.env
+	StoreWebapp                // .polis/webapp-config.json
+	StoreManifest              // metadata/manifest.json
+)
+
+// Kind is the value type of a setting.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindInt
+)
+
+// Setting describes one configurable value.
+type Setting struct {
+	Key         string
+	Env         string
+	Default     string
+	Kind        Kind
+	Allowed     []string
+	Secret      bool
+	Store       Store
+	Description string
+}
+
+// Settings lists every known setting in display order.
+var Settings = []Setting{
+	{Key: "base_url", Env: "POLIS_BASE_URL", Store: StoreEnvFile,
+		Description: "Public URL of this site"},
+	{Key: "discovery_url", Env: "DISCOVERY_SERVICE_URL", Default: DefaultDiscoveryURL, Store: StoreEnvFile,
+		Description: "Discovery service endpoint"},
+	{Key: "discovery_key", Env: "DISCOVERY_SERVICE_KEY", Secret: true, Store: StoreEnvFile,
+		Description: "Discovery service API key"},
+	{Key: "listen", Env: "POLIS_LISTEN", Store: StoreEnvFile,
+		Description: "Address polis serve listens on, e.g. 0.0.0.0:8080 (empty: a free port on localhost)"},
+	{Key: "admin_token", Env: "POLIS_ADMIN_TOKEN", Secret: true, Store: StoreEnvFile,
+		Description: "Token that signs in to polis serve's API as an admin, besides polis access users"},
+	{Key: "smtp_password", Env: "SMTP_PASSWORD", Secret: true, Store: StoreEnvFile,
+		Description: "SMTP password for owner notification emails"},
+	{Key: "imap_password", Env: "IMAP_PASSWORD", Secret: true, Store: StoreEnvFile,
+		Description: "IMAP password for polis ingest imap"},
+	{Key: "bluesky_app_password", Env: "BLUESKY_APP_PASSWORD", Secret: true, Store: StoreEnvFile,
+		Description: "Bluesky app password for cross-posting"},
+	{Key: "ipfs_api_token", Env: "IPFS_API_TOKEN", Secret: true, Store: StoreEnvFile,
+		Description: "Bearer token for a remote IPFS node or pinning service"},
+	{Key: "secret_store", Env: "POLIS_SECRET_STORE", Default: SecretStoreAuto, Allowed: []string{SecretStoreAuto, SecretStoreKeychain, SecretStoreEnv}, Store: StoreEnvFile,
+		Description: "Where config set keeps secrets: auto (the OS keychain when there is one), keychain, or env (.env)"},
+	{Key: "temp_dir", Env: "POLIS_TEMP_DIR", Store: StoreEnvFile,
+		Description: "Temp location for atomic writes (relative to the site; default: next to each file)"},
+	{Key: "fsync", Env: "POLIS_FSYNC", Default: "auto", Allowed: []string{"auto", "always", "never"}, Store: StoreEnvFile,
+		Description: "Flush writes to disk: auto (network mounts only), always, never"},
+	{Key: "signing_backend", Env: "POLIS_SIGNING_BACKEND", Default: signing.BackendFile, Allowed: []string{signing.BackendFile, signing.BackendAgent}, Store: StoreEnvFile,
+		Description: "Where the site key is kept: file (.polis/keys/id_ed25519) or agent (ssh-agent at SSH_AUTH_SOCK)"},
+	{Key: "sign_as", Env: "POLIS_SIGN_AS", Store: StoreEnvFile,
+		Description: "Co-author whose key signs posts and comments on this machine (empty: the site key)"},
+	{Key: "update_channel", Env: "POLIS_UPDATE_CHANNEL", Default: selfupdate.ChannelStable, Allowed: []string{selfupdate.ChannelStable, selfupdate.ChannelBeta}, Store: StoreEnvFile,
+		Description: "Release channel for polis self-update"},
+	{Key: "update_url", Env: "POLIS_UPDATE_URL", Default: selfupdate.DefaultReleasesURL, Store: StoreEnvFile,
+		Description: "Release list polis self-update checks"},
+	{Key: "allow_local_fetch", Env: "POLIS_ALLOW_LOCAL_FETCH", Default: "false", Kind: KindBool, Store: StoreEnvFile,
+		Description: "Allow remote and discovery requests to localhost, private addresses, and plain HTTP (development only)"},
+	{Key: "trash_retention_days", Env: "POLIS_TRASH_RETENTION_DAYS", Default: "30", Kind: KindInt, Store: StoreEnvFile,
+		Description: "Days deleted drafts and unpublished posts stay in .polis/trash"},
+	{Key: "http_cache_mb", Env: "POLIS_HTTP_CACHE_MB", Default: "100", Kind: KindInt, Store: StoreEnvFile,
+		Description: "Size limit of the on-disk HTTP cache in MB (0 disables conditional requests)"},
+	{Key: "locale", Env: "POLIS_LOCALE", Store: StoreEnvFile,
+		Description: "Language for CLI messages and webapp API errors, e.g. fr (empty: LANG for the CLI, the browser's Accept-Language for the webapp)"},
+	{Key: "translations_dir", Env: "POLIS_TRANSLATIONS_DIR", Store: StoreEnvFile,
+		Description: "Extra directory of <lang>.json message translations, after ~/.polis/translations and .polis/translations"},
+	{Key: "theme", Env: "POLIS_THEME", Store: StoreManifest,
+		Description: "Active theme (re-render after changing)"},
+	{Key: "post_path", Env: "POLIS_POST_PATH", Default: "YYYYMMDD", Allowed: []string{"YYYYMMDD", "YYYY/MM", "flat"}, Store: StoreWebapp,
+		Description: "Directory layout for new posts: posts/YYYYMMDD/, posts/YYYY/MM/, or flat posts/"},
+	{Key: "audit_block_publish", Env: "POLIS_AUDIT_BLOCK_PUBLISH", Default: "false", Kind: KindBool, Store: StoreWebapp,
+		Description: "Refuse to publish posts with accessibility issues (images without alt text, skipped heading levels)"},
+	{Key: "pages.archive", Env: "POLIS_PAGES_ARCHIVE", Default: "true", Kind: KindBool, Store: StoreWebapp,
+		Description: "Render posts/index.html, listing every post (themes with a posts.html template)"},
+	{Key: "pages.not_found", Env: "POLIS_PAGES_NOT_FOUND", Default: "true", Kind: KindBool, Store: StoreWebapp,
+		Description: "Render a 404.html page for hosts to show at missing addresses"},
+	{Key: "pages.about", Env: "POLIS_PAGES_ABOUT", Default: "true", Kind: KindBool, Store: StoreWebapp,
+		Description: "Render about/index.html from snippets/about.md"},
+	{Key: "analytics.enabled", Env: "POLIS_ANALYTICS_ENABLED", Default: "false", Kind: KindBool, Store: StoreWebapp,
+		Description: "Count post views: add a view beacon to rendered posts and accept views in polis serve"},
+	{Key: "analytics.beacon_url", Env: "POLIS_ANALYTICS_BEACON_URL", Store: StoreWebapp,
+		Description: "Public URL of polis serve's /beacon endpoint, which rendered posts report views to"},
+	{Key: "analytics.listen", Env: "POLIS_ANALYTICS_LISTEN", Store: StoreWebapp,
+		Description: "Address polis daemon accepts /beacon view reports on, e.g. 127.0.0.1:8090"},
+	{Key: "view_mode", Env: "POLIS_VIEW_MODE", Default: "list", Allowed: []string{"list", "browser"}, Store: StoreWebapp,
+		Description: "Webapp dashboard layout"},
+	{Key: "show_frontmatter", Env: "POLIS_SHOW_FRONTMATTER", Default: "true", Kind: KindBool, Store: StoreWebapp,
+		Description: "Show frontmatter in the webapp markdown pane"},
+	{Key: "hide_read", Env: "POLIS_HIDE_READ", Default: "false", Kind: KindBool, Store: StoreWebapp,
+		Description: "Hide read items in feed and activity views"},
+	{Key: "prefetch", Env: "POLIS_PREFETCH", Default: "false", Kind: KindBool, Store: StoreWebapp,
+		Description: "Fetch new feed items' content on refresh for instant and offline reading"},
+	{Key: "content_cache_mb", Env: "POLIS_CONTENT_CACHE_MB", Default: "50", Kind: KindInt, Store: StoreWebapp,
+		Description: "Size limit of the remote content cache in MB (least recently read evicted first)"},
+	{Key: "rate_limit", Env: "POLIS_RATE_LIMIT", Default: "30", Kind: KindInt, Store: StoreWebapp,
+		Description: "Webapp publish, comment, blessing, and init requests allowed per minute per group (0 disables)"},
+	{Key: "rate_limit_burst", Env: "POLIS_RATE_LIMIT_BURST", Default: "10", Kind: KindInt, Store: StoreWebapp,
+		Description: "Requests allowed back to back before rate_limit applies"},
+	{Key: "extension_origins", Env: "POLIS_EXTENSION_ORIGINS", Store: StoreWebapp,
+		Description: "Browser extension origins allowed to call the webapp's widget APIs, comma separated"},
+	{Key: "log_level", Env: "POLIS_LOG_LEVEL", Default: "0", Kind: KindInt, Allowed: []string{"0", "1", "2"}, Store: StoreWebapp,
+		Description: "Webapp log level (0=off, 1=basic, 2=verbose)"},
+	{Key: "ingest.allow", Env: "POLIS_INGEST_ALLOW", Store: StoreWebapp,
+		Description: "Sender addresses allowed to publish by email, comma separated"},
+	{Key: "ingest.imap_host", Env: "POLIS_INGEST_IMAP_HOST", Store: StoreWebapp,
+		Description: "IMAP server polled by polis ingest imap (host or host:port, TLS)"},
+	{Key: "ingest.imap_user", Env: "POLIS_INGEST_IMAP_USER", Store: StoreWebapp,
+		Description: "IMAP account polled by polis ingest imap"},
+	{Key: "ingest.imap_mailbox", Env: "POLIS_INGEST_IMAP_MAILBOX", Default: "INBOX", Store: StoreWebapp,
+		Description: "Mailbox polled by polis ingest imap"},
+	{Key: "bluesky.handle", Env: "POLIS_BLUESKY_HANDLE", Store: StoreWebapp,
+		Description: "Bluesky account new posts are cross-posted to (empty disables)"},
+	{Key: "bluesky.pds", Env: "POLIS_BLUESKY_PDS", Default: "https://bsky.social", Store: StoreWebapp,
+		Description: "AT Protocol server of the Bluesky account"},
+	{Key: "nostr.enabled", Env: "POLIS_NOSTR_ENABLED", Default: "false", Kind: KindBool, Store: StoreWebapp,
+		Description: "Mirror public posts to nostr relays as long-form events (experimental)"},
+	{Key: "nostr.relays", Env: "POLIS_NOSTR_RELAYS", Default: "wss://relay.damus.io,wss://nos.lol", Store: StoreWebapp,
+		Description: "Comma-separated nostr relays posts are mirrored to"},
+	{Key: "ipfs.api", Env: "POLIS_IPFS_API", Store: StoreWebapp,
+		Description: "RPC address of the IPFS node posts are pinned to, e.g. http://127.0.0.1:5001 (empty disables)"},
+	{Key: "timestamp.tsa", Env: "POLIS_TIMESTAMP_TSA", Store: StoreWebapp,
+		Description: "RFC 3161 time-stamping authority post versions are anchored with, e.g. https://freetsa.org/tsr (empty disables)"},
+	{Key: "hooks.post-publish", Env: "POLIS_HOOK_POST_PUBLISH", Store: StoreWebapp,
+		Description: "Script run after publishing a post"},
+	{Key: "hooks.post-republish", Env: "POLIS_HOOK_POST_REPUBLISH", Store: StoreWebapp,
+		Description: "Script run after republishing a post"},
+	{Key: "hooks.post-comment", Env: "POLIS_HOOK_POST_COMMENT", Store: StoreWebapp,
+		Description: "Script run after signing a comment"},
+}
+
+// Lookup returns the setting with the given key.
+func Lookup(key string) (*Setting, bool) {
+	for i := range Settings {
+		if Settings[i].Key == key {
+			return &Settings[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks a raw value against the setting's kind and allowed values.
+// An empty value is always valid and means "unset".
+func (s *Setting) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+	switch s.Kind {
+	case KindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false", s.Key)
+		}
+	case KindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be an integer", s.Key)
+		}
+	}
+	if len(s.Allowed) > 0 {
+		for _, a := range s.Allowed {
+			if a == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid %s %q (expected one of: %s)", s.Key, value, strings.Join(s.Allowed, ", "))
+	}
+	return nil
+}
+
+// Value is a resolved setting.
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source Layer  `json:"source"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// Masked returns the value with secrets hidden.
+func (v Value) Masked() string {
+	if !v.Secret || v.Value == "" {
+		return v.Value
+	}
+	if len(v.Value) <= 8 {
+		return "********"
+	}
+	return "********" + v.Value[len(v.Value)-4:]
+}
+
+// Options controls Load.
+type Options struct {
+	// DataDir is the site directory. Its .env and .polis/webapp-config.json
+	// are read; empty means the current directory.
+	DataDir string
+
+	// Flags holds command-line overrides keyed by setting key.
+	Flags map[string]string
+}
+
+// Config is the resolved view of all settings.
+type Config struct {
+	// EnvFile is the .env file that was read, or empty if none was found.
+	EnvFile string
+
+	values  map[string]Value
+	envVars map[string]string
+}
+
+// Load resolves every setting through all layers.
+func Load(opts Options) (*Config, error) {
+	c := &Config{values: make(map[string]Value)}
+
+	c.EnvFile = FindEnvFile(opts.DataDir)
+	if c.EnvFile != "" {
+		vars, err := ReadEnvFile(c.EnvFile)
+		if err != nil {
+			return nil, err
+		}
+		c.envVars = vars
+	}
+
+	webapp, err := readJSONMap(WebappConfigPath(opts.DataDir))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range Settings {
+		v := Value{Key: s.Key, Value: s.Default, Source: LayerDefault, Secret: s.Secret}
+		set := func(value string, layer Layer) {
+			if value != "" {
+				v.Value = value
+				v.Source = layer
+			}
+		}
+
+		set(c.envVars[s.Env], LayerEnvFile)
+		if s.Secret {
+			// A keychain that can't be read (locked, or gone) leaves the
+			// .env value, if any
+			if value, ok, err := secrets.Get(opts.DataDir, s.Key); ok && err == nil {
+				set(value, LayerKeychain)
+			}
+		}
+		switch s.Store {
+		case StoreWebapp:
+			set(jsonPathString(webapp, strings.Split(s.Key, ".")), LayerSite)
+		case StoreManifest:
+			if name, err := theme.GetActiveTheme(opts.DataDir); err == nil {
+				set(name, LayerSite)
+			}
+		}
+		if env, ok := os.LookupEnv(s.Env); ok && !loadedFromEnvFile(s.Env, env) {
+			set(env, LayerEnvironment)
+		}
+		set(opts.Flags[s.Key], LayerFlag)
+
+		c.values[s.Key] = v
+	}
+
+	return c, nil
+}
+
+// Get returns a setting's resolved value, or "" for unknown keys.
+func (c *Config) Get(key string) string {
+	return c.values[key].Value
+}
+
+// Bool returns a boolean setting (false if unset or invalid).
+func (c *Config) Bool(key string) bool {
+	b, _ := strconv.ParseBool(c.values[key].Value)
+	return b
+}
+
+// Int returns an integer setting (0 if unset or invalid).
+func (c *Config) Int(key string) int {
+	n, _ := strconv.Atoi(c.values[key].Value)
+	return n
+}
+
+// Value returns a resolved setting with its source.
+func (c *Config) Value(key string) (Value, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// All returns every resolved setting in display order.
+func (c *Config) All() []Value {
+	all := make([]Value, 0, len(Settings))
+	for _, s := range Settings {
+		all = append(all, c.values[s.Key])
+	}
+	return all
+}
+
+// EnvFileValue returns a raw variable from the .env file that was read,
+// including variables that are not polis settings (e.g. AWS credentials).
+func (c *Config) EnvFileValue(name string) string {
+	return c.envVars[name]
+}
+
+// Set validates a value and writes it to the setting's store. An empty
+// value removes the setting so it falls back to lower layers.
+func Set(dataDir, key, value string) (*Setting, error) {
+	s, ok := Lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting: %s", key)
+	}
+	if err := s.Validate(value); err != nil {
+		return nil, err
+	}
+
+	switch s.Store {
+	case StoreEnvFile:
+		if s.Secret {
+			return s, setSecret(dataDir, s, value)
+		}
+		err := WriteEnvFileValue(filepath.Join(dataDir, ".env"), s.Env, value)
+		return s, err
+	case StoreManifest:
+		return s, theme.SetActiveTheme(dataDir, value)
+	default:
+		path := WebappConfigPath(dataDir)
+		m, err := readJSONMap(path)
+		if err != nil {
+			return nil, err
+		}
+		var typed interface{} = value
+		switch s.Kind {
+		case KindBool:
+			typed, _ = strconv.ParseBool(value)
+		case KindInt:
+			typed, _ = strconv.Atoi(value)
+		}
+		setJSONPath(m, strings.Split(s.Key, "."), typed, value == "")
+		return s, writeJSONMap(path, m)
+	}
+}
+
+// Secret stores, for the secret_store setting.
+const (
+	SecretStoreAuto     = "auto"
+	SecretStoreKeychain = "keychain"
+	SecretStoreEnv      = "env"
+)
+
+// setSecret writes a secret setting to the keychain or .env, per
+// secret_store, and removes it from the other so it isn't shadowed.
+func setSecret(dataDir string, s *Setting, value string) error {
+	envPath := filepath.Join(dataDir, ".env")
+	if value == "" {
+		if err := secrets.Remove(dataDir, s.Key); err != nil {
+			return err
+		}
+		return WriteEnvFileValue(envPath, s.Env, "")
+	}
+	if !useKeychain(dataDir) {
+		if err := secrets.Remove(dataDir, s.Key); err != nil {
+			return err
+		}
+		return WriteEnvFileValue(envPath, s.Env, value)
+	}
+	if err := secrets.Put(dataDir, s.Key, value); err != nil {
+		return err
+	}
+	return WriteEnvFileValue(envPath, s.Env, "")
+}
+
+// useKeychain reports whether secrets are written to the keychain. It is an
+// error to require the keychain where there is none; that surfaces from
+// secrets.Put.
+func useKeychain(dataDir string) bool {
+	store := SecretStoreAuto
+	if c, err := Load(Options{DataDir: dataDir}); err == nil {
+		store = c.Get("secret_store")
+	}
+	switch store {
+	case SecretStoreEnv:
+		return false
+	case SecretStoreKeychain:
+		return true
+	}
+	_, ok := secrets.Available()
+	return ok
+}
+
+// MoveSecrets moves the secrets in a site's .env into the keychain and
+// returns the keys moved.
+func MoveSecrets(dataDir string) ([]string, error) {
+	if _, ok := secrets.Available(); !ok {
+		return nil, secrets.ErrUnavailable
+	}
+	envPath := filepath.Join(dataDir, ".env")
+	vars, err := ReadEnvFile(envPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var moved []string
+	for _, s := range Settings {
+		if !s.Secret || vars[s.Env] == "" {
+			continue
+		}
+		if err := secrets.Put(dataDir, s.Key, vars[s.Env]); err != nil {
+			return moved, err
+		}
+		if err := WriteEnvFileValue(envPath, s.Env, ""); err != nil {
+			return moved, err
+		}
+		moved = append(moved, s.Key)
+	}
+	return moved, nil
+}
+
+// WebappConfigPath returns the path to .polis/webapp-config.json.
+func WebappConfigPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "webapp-config.json")
+}
+
+// Keys returns all setting keys in sorted order (for completion and errors).
+func Keys() []string {
+	keys := make([]string, len(Settings))
+	for i, s := range Settings {
+		keys[i] = s.Key
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// --- .env handling ---
+
+// envFileVars records variables ApplyEnvFile and ApplySecrets copied into
+// the process environment, so Load can still attribute them to their layer.
+var (
+	envFileMu   sync.Mutex
+	envFileVars = map[string]string{}
+)
+
+func loadedFromEnvFile(name, value string) bool {
+	envFileMu.Lock()
+	defer envFileMu.Unlock()
+	v, ok := envFileVars[name]
+	return ok && v == value
+}
+
+// FindEnvFile returns the .env file for a site.
+// Search order: <dataDir>/.env → ./.env → ~/.polis/.env. Returns "" if none exists.
+func FindEnvFile(dataDir string) string {
+	var candidates []string
+	if dataDir != "" {
+		candidates = append(candidates, filepath.Join(dataDir, ".env"))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, filepath.Join(cwd, ".env"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".polis", ".env"))
+	}
+	for _, p := range candidates {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// ReadEnvFile parses a KEY=VALUE file. Blank lines, comments, and lines
+// without a key are skipped; surrounding quotes are removed from values.
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := parseEnvLine(line)
+		if ok {
+			vars[key] = value
+		}
+	}
+	return vars, nil
+}
+
+func parseEnvLine(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(parts[0])
+	value = strings.TrimSpace(parts[1])
+	if key == "" {
+		return "", "", false
+	}
+	if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
+		(value[0] == '\'' && value[len(value)-1] == '\'')) {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, true
+}
+
+// ApplyEnvFile copies variables from a .env file into the process
+// environment without overriding variables that are already set.
+// Returns false if the file could not be read.
+func ApplyEnvFile(path string) bool {
+	vars, err := ReadEnvFile(path)
+	if err != nil {
+		return false
+	}
+	envFileMu.Lock()
+	defer envFileMu.Unlock()
+	for key, value := range vars {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, value)
+			envFileVars[key] = value
+		}
+	}
+	return true
+}
+
+// ApplySecrets copies the secrets a site keeps in the keychain into the
+// process environment, for code that reads them from there. They replace
+// values from the .env file but not variables set in the environment.
+func ApplySecrets(dataDir string) {
+	envFileMu.Lock()
+	defer envFileMu.Unlock()
+	for _, s := range Settings {
+		if !s.Secret {
+			continue
+		}
+		value, ok, err := secrets.Get(dataDir, s.Key)
+		if !ok || err != nil {
+			continue
+		}
+		if cur := os.Getenv(s.Env); cur == "" || envFileVars[s.Env] == cur {
+			os.Setenv(s.Env, value)
+			envFileVars[s.Env] = value
+		}
+	}
+}
+
+// WriteEnvFileValue sets (or, with an empty value, removes) one variable
+// in a .env file, preserving comments and the order of other lines.
+func WriteEnvFileValue(path, name, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	out := make([]string, 0, len(lines)+1)
+	replaced := false
+	for _, line := range lines {
+		if key, _, ok := parseEnvLine(line); ok && key == name {
+			if !replaced && value != "" {
+				out = append(out, name+"="+quoteEnvValue(value))
+			}
+			replaced = true
+			continue
+		}
+		out = append(out, line)
+	}
+	if !replaced && value != "" {
+		out = append(out, name+"="+quoteEnvValue(value))
+	}
+
+	content := strings.Join(out, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := fsutil.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write .env: %w", err)
+	}
+	return nil
+}
+
+// quoteEnvValue quotes values that would not survive parseEnvLine bare.
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " #\"'") {
+		return value
+	}
+	if strings.Contains(value, `"`) {
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// --- webapp-config.json handling ---
+
+func readJSONMap(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return m, nil
+}
+
+func writeJSONMap(path string, m map[string]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// jsonPathString returns the value at a nested path as a string.
+func jsonPathString(m map[string]interface{}, path []string) string {
+	var cur interface{} = m
+	for _, p := range path {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = obj[p]
+	}
+	switch v := cur.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// setJSONPath sets (or deletes) the value at a nested path, creating
+// intermediate objects and pruning ones left empty by a delete.
+func setJSONPath(m map[string]interface{}, path []string, value interface{}, remove bool) {
+	if len(path) == 1 {
+		if remove {
+			delete(m, path[0])
+		} else {
+			m[path[0]] = value
+		}
+		return
+	}
+	child, ok := m[path[0]].(map[string]interface{})
+	if !ok {
+		if remove {
+			return
+		}
+		child = map[string]interface{}{}
+		m[path[0]] = child
+	}
+	setJSONPath(child, path[1:], value, remove)
+	if remove && len(child) == 0 {
+		delete(m, path[0])
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/secrets"
+)
+
+func newSiteDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".polis"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	dir := newSiteDir(t)
+
+	cfg, err := Load(Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	v, _ := cfg.Value("discovery_url")
+	if v.Value != DefaultDiscoveryURL || v.Source != LayerDefault {
+		t.Errorf("discovery_url = %q from %s, want default", v.Value, v.Source)
+	}
+	if cfg.Get("view_mode") != "list" {
+		t.Errorf("view_mode = %q, want list", cfg.Get("view_mode"))
+	}
+	if !cfg.Bool("show_frontmatter") {
+		t.Error("show_frontmatter should default to true")
+	}
+}
+
+func TestLoad_Layering(t *testing.T) {
+	dir := newSiteDir(t)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("POLIS_VIEW_MODE=browser\nPOLIS_LOG_LEVEL=1\nPOLIS_BASE_URL=https://a.example\n"), 0600)
+	os.WriteFile(WebappConfigPath(dir), []byte(`{"log_level": 2, "hooks": {"post-publish": "./deploy.sh"}}`), 0644)
+	t.Setenv("POLIS_BASE_URL", "https://b.example")
+
+	cfg, err := Load(Options{DataDir: dir, Flags: map[string]string{"view_mode": "list"}})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source Layer
+	}{
+		{"view_mode", "list", LayerFlag},
+		{"log_level", "2", LayerSite},
+		{"base_url", "https://b.example", LayerEnvironment},
+		{"hooks.post-publish", "./deploy.sh", LayerSite},
+		{"hide_read", "false", LayerDefault},
+	}
+	for _, tt := range tests {
+		v, _ := cfg.Value(tt.key)
+		if v.Value != tt.value || v.Source != tt.source {
+			t.Errorf("%s = %q from %s, want %q from %s", tt.key, v.Value, v.Source, tt.value, tt.source)
+		}
+	}
+	if cfg.Int("log_level") != 2 {
+		t.Errorf("Int(log_level) = %d, want 2", cfg.Int("log_level"))
+	}
+}
+
+func TestLoad_AppliedEnvFileIsNotEnvironment(t *testing.T) {
+	dir := newSiteDir(t)
+	envPath := filepath.Join(dir, ".env")
+	os.WriteFile(envPath, []byte("POLIS_HOOK_POST_COMMENT=./notify.sh\n"), 0600)
+	t.Setenv("POLIS_HOOK_POST_COMMENT", "")
+	os.Unsetenv("POLIS_HOOK_POST_COMMENT")
+
+	if !ApplyEnvFile(envPath) {
+		t.Fatal("ApplyEnvFile returned false")
+	}
+	cfg, err := Load(Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	v, _ := cfg.Value("hooks.post-comment")
+	if v.Source != LayerEnvFile {
+		t.Errorf("source = %s, want env-file", v.Source)
+	}
+}
+
+func TestSet_WritesToStore(t *testing.T) {
+	old := secrets.SetStore(nil)
+	t.Cleanup(func() { secrets.SetStore(old) })
+	dir := newSiteDir(t)
+	os.WriteFile(WebappConfigPath(dir), []byte(`{"setup_wizard_dismissed": true}`), 0644)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("# keep me\nAWS_ACCESS_KEY_ID=abc\n"), 0600)
+
+	if _, err := Set(dir, "view_mode", "browser"); err != nil {
+		t.Fatalf("Set view_mode: %v", err)
+	}
+	if _, err := Set(dir, "hide_read", "true"); err != nil {
+		t.Fatalf("Set hide_read: %v", err)
+	}
+	if _, err := Set(dir, "hooks.post-publish", "./deploy.sh"); err != nil {
+		t.Fatalf("Set hook: %v", err)
+	}
+	if _, err := Set(dir, "discovery_key", "secret value"); err != nil {
+		t.Fatalf("Set discovery_key: %v", err)
+	}
+
+	m, err := readJSONMap(WebappConfigPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["view_mode"] != "browser" || m["hide_read"] != true || m["setup_wizard_dismissed"] != true {
+		t.Errorf("unexpected webapp config: %v", m)
+	}
+
+	env, _ := os.ReadFile(filepath.Join(dir, ".env"))
+	if !strings.Contains(string(env), "# keep me") || !strings.Contains(string(env), "AWS_ACCESS_KEY_ID=abc") {
+		t.Errorf("existing .env lines lost:\n%s", env)
+	}
+	vars, _ := ReadEnvFile(filepath.Join(dir, ".env"))
+	if vars["DISCOVERY_SERVICE_KEY"] != "secret value" {
+		t.Errorf("DISCOVERY_SERVICE_KEY = %q", vars["DISCOVERY_SERVICE_KEY"])
+	}
+
+	// Empty value unsets and prunes empty parents
+	if _, err := Set(dir, "hooks.post-publish", ""); err != nil {
+		t.Fatalf("unset hook: %v", err)
+	}
+	m, _ = readJSONMap(WebappConfigPath(dir))
+	if _, ok := m["hooks"]; ok {
+		t.Errorf("expected empty hooks object to be removed, got %v", m["hooks"])
+	}
+}
+
+func TestSet_Validation(t *testing.T) {
+	dir := newSiteDir(t)
+
+	if _, err := Set(dir, "nope", "x"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+	if _, err := Set(dir, "view_mode", "grid"); err == nil {
+		t.Error("expected error for disallowed view_mode")
+	}
+	if _, err := Set(dir, "show_frontmatter", "maybe"); err == nil {
+		t.Error("expected error for non-bool")
+	}
+	if _, err := Set(dir, "log_level", "5"); err == nil {
+		t.Error("expected error for out-of-range log level")
+	}
+}
+
+func TestValue_Masked(t *testing.T) {
+	v := Value{Value: "abcdefghijkl", Secret: true}
+	if got := v.Masked(); got != "********ijkl" {
+		t.Errorf("Masked() = %q", got)
+	}
+	v = Value{Value: "short", Secret: true}
+	if got := v.Masked(); got != "********" {
+		t.Errorf("Masked() = %q", got)
+	}
+	v = Value{Value: "plain"}
+	if got := v.Masked(); got != "plain" {
+		t.Errorf("Masked() = %q", got)
+	}
+}
+
+func TestParseEnvLine(t *testing.T) {
+	tests := []struct {
+		line, key, value string
+		ok               bool
+	}{
+		{`A=1`, "A", "1", true},
+		{`B="two words"`, "B", "two words", true},
+		{`C='x'`, "C", "x", true},
+		{`# comment`, "", "", false},
+		{`no-equals`, "", "", false},
+		{`=novalue`, "", "", false},
+	}
+	for _, tt := range tests {
+		k, v, ok := parseEnvLine(tt.line)
+		if k != tt.key || v != tt.value || ok != tt.ok {
+			t.Errorf("parseEnvLine(%q) = %q, %q, %v", tt.line, k, v, ok)
+		}
+	}
+}
+
+// memKeychain is an in-memory keychain.
+type memKeychain map[string]string
+
+func (memKeychain) Name() string { return "test keychain" }
+
+func (m memKeychain) Get(account string) (string, error) {
+	v, ok := m[account]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return v, nil
+}
+
+func (m memKeychain) Set(account, value string) error {
+	m[account] = value
+	return nil
+}
+
+func (m memKeychain) Delete(account string) error {
+	delete(m, account)
+	return nil
+}
+
+func TestSet_SecretInKeychain(t *testing.T) {
+	old := secrets.SetStore(memKeychain{})
+	t.Cleanup(func() { secrets.SetStore(old) })
+	dir := newSiteDir(t)
+	envPath := filepath.Join(dir, ".env")
+	os.WriteFile(envPath, []byte("DISCOVERY_SERVICE_KEY=from-env\nSMTP_PASSWORD=hunter2\n"), 0600)
+	t.Setenv("DISCOVERY_SERVICE_KEY", "")
+	os.Unsetenv("DISCOVERY_SERVICE_KEY")
+
+	if _, err := Set(dir, "discovery_key", "from-keychain"); err != nil {
+		t.Fatalf("Set discovery_key: %v", err)
+	}
+	vars, _ := ReadEnvFile(envPath)
+	if _, ok := vars["DISCOVERY_SERVICE_KEY"]; ok {
+		t.Error("DISCOVERY_SERVICE_KEY left in .env")
+	}
+	cfg, _ := Load(Options{DataDir: dir})
+	v, _ := cfg.Value("discovery_key")
+	if v.Value != "from-keychain" || v.Source != LayerKeychain {
+		t.Errorf("discovery_key = %q from %s, want from-keychain from keychain", v.Value, v.Source)
+	}
+
+	ApplySecrets(dir)
+	if got := os.Getenv("DISCOVERY_SERVICE_KEY"); got != "from-keychain" {
+		t.Errorf("ApplySecrets: DISCOVERY_SERVICE_KEY = %q", got)
+	}
+	cfg, _ = Load(Options{DataDir: dir})
+	if v, _ := cfg.Value("discovery_key"); v.Source != LayerKeychain {
+		t.Errorf("applied secret attributed to %s, want keychain", v.Source)
+	}
+
+	moved, err := MoveSecrets(dir)
+	if err != nil || len(moved) != 1 || moved[0] != "smtp_password" {
+		t.Fatalf("MoveSecrets = %v, %v", moved, err)
+	}
+	vars, _ = ReadEnvFile(envPath)
+	if _, ok := vars["SMTP_PASSWORD"]; ok {
+		t.Error("SMTP_PASSWORD left in .env")
+	}
+
+	// secret_store=env puts the secret back in .env
+	if _, err := Set(dir, "secret_store", SecretStoreEnv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Set(dir, "discovery_key", "plain"); err != nil {
+		t.Fatal(err)
+	}
+	vars, _ = ReadEnvFile(envPath)
+	if vars["DISCOVERY_SERVICE_KEY"] != "plain" {
+		t.Errorf(".env DISCOVERY_SERVICE_KEY = %q, want plain", vars["DISCOVERY_SERVICE_KEY"])
+	}
+	if _, ok, _ := secrets.Get(dir, "discovery_key"); ok {
+		t.Error("discovery_key still in the keychain")
+	}
+}
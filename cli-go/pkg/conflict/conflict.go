@@ -0,0 +1,365 @@
+// Package conflict detects posts that were published to the live site from
+// another copy of the site (a second machine, say) and resolves them.
+//
+// The live public.jsonl records the version of each post that was last
+// deployed. A post is in sync when that version is the local current-version
+// or an earlier entry in the local version-history (the local copy is just
+// ahead of the last deploy). Otherwise the live copy was changed elsewhere:
+// if its version-history contains the local version it is simply newer, and
+// if not, the two copies have diverged.
+package conflict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/trash"
+)
+
+// Conflict kinds.
+const (
+	// The live post has versions made elsewhere on top of the local one.
+	KindRemoteNewer = "remote_newer"
+	// The live and local posts each have versions the other lacks.
+	KindDiverged = "diverged"
+	// The live site has a post that this copy has never had.
+	KindRemoteOnly = "remote_only"
+)
+
+// Resolutions.
+const (
+	// ResolvePull replaces the local post with the live one.
+	ResolvePull = "pull"
+	// ResolveKeep keeps the local post; the next deploy overwrites the live one.
+	ResolveKeep = "keep"
+	// ResolveMergeFrontmatter keeps the local body and takes the live post's
+	// title, tags, description, summary, and language.
+	ResolveMergeFrontmatter = "merge-frontmatter"
+)
+
+// ReportFile is where the last report is stored, relative to the site directory.
+const ReportFile = ".polis/conflicts.json"
+
+// keptFile records live versions the author chose to keep local copies over,
+// so they aren't reported again before the next deploy.
+const keptFile = ".polis/conflicts-kept.json"
+
+// Fetcher reads files from the live site. *remote.Client implements it.
+type Fetcher interface {
+	FetchContent(url string) (string, error)
+}
+
+// Conflict is a post whose live copy was changed from somewhere else.
+type Conflict struct {
+	Kind          string   `json:"kind"`
+	Path          string   `json:"path"`
+	Title         string   `json:"title"`
+	LocalVersion  string   `json:"local_version,omitempty"`
+	RemoteVersion string   `json:"remote_version"`
+	Resolutions   []string `json:"resolutions"`
+}
+
+// Report is the result of a check.
+type Report struct {
+	CheckedAt string     `json:"checked_at"`
+	BaseURL   string     `json:"base_url"`
+	Checked   int        `json:"checked"`
+	Conflicts []Conflict `json:"conflicts"`
+	Errors    []string   `json:"errors"`
+}
+
+// Find returns the conflict for path, or nil.
+func (r *Report) Find(path string) *Conflict {
+	for i := range r.Conflicts {
+		if r.Conflicts[i].Path == path {
+			return &r.Conflicts[i]
+		}
+	}
+	return nil
+}
+
+// Check compares the posts in the live site's public.jsonl with the local
+// copies. Posts that can't be compared are listed in Report.Errors; an error
+// is only returned when the live index can't be read.
+func Check(dataDir, baseURL string, fetcher Fetcher) (*Report, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if baseURL == "" {
+		return nil, errors.New("POLIS_BASE_URL not configured")
+	}
+	content, err := fetcher.FetchContent(baseURL + "/metadata/public.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the live public.jsonl: %w", err)
+	}
+	kept, err := loadKept(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	trashed := make(map[string]bool)
+	if items, err := trash.List(dataDir); err == nil {
+		for _, item := range items {
+			trashed[item.Path] = true
+		}
+	}
+
+	report := &Report{
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		BaseURL:   baseURL,
+		Conflicts: []Conflict{},
+		Errors:    []string{},
+	}
+	for _, line := range strings.Split(content, "\n") {
+		var entry metadata.IndexEntry
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &entry); err != nil {
+			continue
+		}
+		if entry.Type != "post" || entry.CurrentVersion == "" || !publishedPath(entry.Path) {
+			continue
+		}
+		report.Checked++
+		if kept[entry.Path] == entry.CurrentVersion {
+			continue
+		}
+
+		local, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			// A post unpublished here is just waiting for a deploy to remove it
+			if os.IsNotExist(err) && !trashed[entry.Path] {
+				report.Conflicts = append(report.Conflicts, Conflict{
+					Kind:          KindRemoteOnly,
+					Path:          entry.Path,
+					Title:         entry.Title,
+					RemoteVersion: entry.CurrentVersion,
+					Resolutions:   []string{ResolvePull, ResolveKeep},
+				})
+			} else if !os.IsNotExist(err) {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", entry.Path, err))
+			}
+			continue
+		}
+		fm := publish.ParseFrontmatterFields(string(local))
+		if fm.CurrentVersion == entry.CurrentVersion || inHistory(fm.VersionHistory, entry.CurrentVersion) {
+			continue
+		}
+
+		live, err := fetcher.FetchContent(baseURL + "/" + entry.Path)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to fetch the live copy: %v", entry.Path, err))
+			continue
+		}
+		kind := KindDiverged
+		if inHistory(publish.ExtractVersionHistory(live), fm.CurrentVersion) {
+			kind = KindRemoteNewer
+		}
+		report.Conflicts = append(report.Conflicts, Conflict{
+			Kind:          kind,
+			Path:          entry.Path,
+			Title:         entry.Title,
+			LocalVersion:  fm.CurrentVersion,
+			RemoteVersion: entry.CurrentVersion,
+			Resolutions:   []string{ResolvePull, ResolveKeep, ResolveMergeFrontmatter},
+		})
+	}
+	return report, nil
+}
+
+// publishedPath reports whether path names a post file the site serves.
+func publishedPath(path string) bool {
+	return strings.HasPrefix(path, "posts/") && strings.HasSuffix(path, ".md") &&
+		!strings.Contains(path, "..") && !strings.Contains(path, "/.versions/")
+}
+
+// inHistory reports whether version appears in a version-history list,
+// whose entries read "sha256:... (timestamp)".
+func inHistory(history []string, version string) bool {
+	if version == "" {
+		return false
+	}
+	for _, h := range history {
+		if hash, _, _ := strings.Cut(strings.TrimSpace(h), " "); hash == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveOptions says how to resolve a conflict.
+type ResolveOptions struct {
+	DataDir string
+	BaseURL string
+	Path    string
+	How     string // ResolvePull, ResolveKeep, or ResolveMergeFrontmatter
+	// PrivateKey re-signs the post after a frontmatter merge.
+	PrivateKey []byte
+}
+
+// Resolve settles the conflict on a post and drops it from the saved report.
+// Pulling moves the local copy, if any, to the trash first, so it can still
+// be recovered. Local changes made by pull and merge-frontmatter need a
+// render and deploy like any other edit.
+func Resolve(opts ResolveOptions, fetcher Fetcher) error {
+	path := filepath.ToSlash(opts.Path)
+	if !publishedPath(path) {
+		return fmt.Errorf("not a post path: %s", opts.Path)
+	}
+	baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+	if baseURL == "" {
+		return errors.New("POLIS_BASE_URL not configured")
+	}
+	live, err := fetcher.FetchContent(baseURL + "/" + path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the live copy: %w", err)
+	}
+	liveFM := publish.ParseFrontmatterFields(live)
+	if liveFM.CurrentVersion == "" {
+		return errors.New("the live copy has no current-version")
+	}
+
+	switch opts.How {
+	case ResolvePull:
+		err = pull(opts.DataDir, path, live, liveFM)
+	case ResolveKeep:
+		err = keep(opts.DataDir, path, liveFM.CurrentVersion)
+	case ResolveMergeFrontmatter:
+		err = mergeFrontmatter(opts, path, liveFM)
+	default:
+		return fmt.Errorf("unknown resolution %q (expected %s, %s, or %s)", opts.How, ResolvePull, ResolveKeep, ResolveMergeFrontmatter)
+	}
+	if err != nil {
+		return err
+	}
+	return dropFromReport(opts.DataDir, path)
+}
+
+func pull(dataDir, path, live string, fm *publish.PostFrontmatter) error {
+	dest := filepath.Join(dataDir, filepath.FromSlash(path))
+	if _, err := os.Stat(dest); err == nil {
+		if _, err := trash.Move(dataDir, path); err != nil {
+			return fmt.Errorf("failed to move the local copy to the trash: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(dest, []byte(live), 0644); err != nil {
+		return err
+	}
+	summary := fm.Summary
+	if summary == "" {
+		summary = publish.Summarize(publish.StripFrontmatter(live))
+	}
+	return metadata.AppendToPublicIndex(dataDir, &metadata.IndexEntry{
+		Type:           "post",
+		Path:           path,
+		Title:          fm.Title,
+		Published:      fm.Published,
+		CurrentVersion: fm.CurrentVersion,
+		Summary:        summary,
+		Lang:           fm.Lang,
+		TranslationOf:  fm.TranslationOf,
+	})
+}
+
+func keep(dataDir, path, liveVersion string) error {
+	kept, err := loadKept(dataDir)
+	if err != nil {
+		return err
+	}
+	kept[path] = liveVersion
+	return saveJSON(filepath.Join(dataDir, keptFile), kept)
+}
+
+func mergeFrontmatter(opts ResolveOptions, path string, fm *publish.PostFrontmatter) error {
+	if opts.PrivateKey == nil {
+		return errors.New("private key required to re-sign the post")
+	}
+	if _, err := os.Stat(filepath.Join(opts.DataDir, filepath.FromSlash(path))); err != nil {
+		return fmt.Errorf("no local copy to merge into: %w", err)
+	}
+	tags := fm.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	patch := publish.FrontmatterPatch{
+		Title:         &fm.Title,
+		Tags:          &tags,
+		Description:   &fm.Description,
+		Summary:       &fm.Summary,
+		Lang:          &fm.Lang,
+		TranslationOf: &fm.TranslationOf,
+	}
+	if _, err := publish.UpdateFrontmatter(opts.DataDir, path, patch, opts.PrivateKey); err != nil {
+		return err
+	}
+	// The body stays local, so the live version is superseded like a keep
+	return keep(opts.DataDir, path, fm.CurrentVersion)
+}
+
+func loadKept(dataDir string) (map[string]string, error) {
+	kept := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(dataDir, keptFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return kept, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &kept); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", keptFile, err)
+	}
+	return kept, nil
+}
+
+func dropFromReport(dataDir, path string) error {
+	report, err := LoadReport(dataDir)
+	if err != nil || report == nil || report.Find(path) == nil {
+		return err
+	}
+	conflicts := report.Conflicts[:0]
+	for _, c := range report.Conflicts {
+		if c.Path != path {
+			conflicts = append(conflicts, c)
+		}
+	}
+	report.Conflicts = conflicts
+	return SaveReport(dataDir, report)
+}
+
+func saveJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// SaveReport writes a report to .polis/conflicts.json.
+func SaveReport(dataDir string, report *Report) error {
+	return saveJSON(filepath.Join(dataDir, ReportFile), report)
+}
+
+// LoadReport reads the last saved report. Returns nil, nil if none exists.
+func LoadReport(dataDir string) (*Report, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, ReportFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ReportFile, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,170 @@
+package conflict
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+const testBaseURL = "https://me.example"
+
+// liveSite serves a directory as the live site.
+type liveSite string
+
+func (s liveSite) FetchContent(url string) (string, error) {
+	rel := strings.TrimPrefix(url, testBaseURL+"/")
+	data, err := os.ReadFile(filepath.Join(string(s), filepath.FromSlash(rel)))
+	if err != nil {
+		return "", errors.New("404")
+	}
+	return string(data), nil
+}
+
+// deployTo copies a post and the index to the live site directory.
+func deployTo(t *testing.T, from, to, postPath string) {
+	t.Helper()
+	for _, rel := range []string{postPath, "metadata/public.jsonl"} {
+		data, err := os.ReadFile(filepath.Join(from, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.MkdirAll(filepath.Dir(filepath.Join(to, rel)), 0755)
+		if err := os.WriteFile(filepath.Join(to, rel), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// twoMachines publishes a post locally, deploys it, and returns the local
+// directory, a second machine's copy, the live site, and the post path.
+func twoMachines(t *testing.T) (local, other string, live liveSite, postPath string, privKey []byte) {
+	t.Helper()
+	privKey, _, _ = signing.GenerateKeypair()
+	local, other = t.TempDir(), t.TempDir()
+	live = liveSite(t.TempDir())
+	result, err := publish.PublishPost(local, "# Hello\n\nFirst draft.\n", "hello", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployTo(t, local, string(live), result.Path)
+	deployTo(t, local, other, result.Path)
+	return local, other, live, result.Path, privKey
+}
+
+func checkOne(t *testing.T, dataDir string, live liveSite) *Conflict {
+	t.Helper()
+	report, err := Check(dataDir, testBaseURL, live)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("errors: %v", report.Errors)
+	}
+	if len(report.Conflicts) > 1 {
+		t.Fatalf("conflicts = %+v", report.Conflicts)
+	}
+	if len(report.Conflicts) == 0 {
+		return nil
+	}
+	return &report.Conflicts[0]
+}
+
+func TestCheck(t *testing.T) {
+	local, other, live, postPath, privKey := twoMachines(t)
+	if c := checkOne(t, local, live); c != nil {
+		t.Fatalf("conflict right after deploy: %+v", c)
+	}
+
+	// Local edits that haven't been deployed aren't a conflict
+	if _, err := publish.RepublishPost(local, postPath, "# Hello\n\nSecond draft.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkOne(t, local, live); c != nil {
+		t.Fatalf("conflict for an undeployed local edit: %+v", c)
+	}
+
+	// The other machine deploys an edit of the original
+	if _, err := publish.RepublishPost(other, postPath, "# Hello\n\nEdited elsewhere.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	deployTo(t, other, string(live), postPath)
+	c := checkOne(t, local, live)
+	if c == nil || c.Kind != KindDiverged {
+		t.Fatalf("conflict = %+v, want diverged", c)
+	}
+
+	// A copy that never moved past the original is just behind
+	behind := t.TempDir()
+	deployTo(t, string(live), behind, postPath)
+	os.WriteFile(filepath.Join(behind, postPath), mustPublishOriginal(t, privKey), 0644)
+	if c := checkOne(t, behind, live); c == nil || c.Kind != KindRemoteNewer {
+		t.Fatalf("conflict = %+v, want remote_newer", c)
+	}
+
+	// A post only the other machine has
+	if c := checkOne(t, t.TempDir(), live); c == nil || c.Kind != KindRemoteOnly {
+		t.Fatalf("conflict = %+v, want remote_only", c)
+	}
+}
+
+// mustPublishOriginal returns the content of the original post as the
+// first machine published it.
+func mustPublishOriginal(t *testing.T, privKey []byte) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	result, err := publish.PublishPost(dir, "# Hello\n\nFirst draft.\n", "hello", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, result.Path))
+	return data
+}
+
+func TestResolve(t *testing.T) {
+	local, other, live, postPath, privKey := twoMachines(t)
+	publish.RepublishPost(local, postPath, "# Hello\n\nLocal edit.\n", privKey)
+	publish.RepublishPost(other, postPath, "# Hello\n\nRemote edit.\n", privKey)
+	title := "Renamed elsewhere"
+	if _, err := publish.UpdateFrontmatter(other, postPath, publish.FrontmatterPatch{Title: &title}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	deployTo(t, other, string(live), postPath)
+
+	report, _ := Check(local, testBaseURL, live)
+	SaveReport(local, report)
+
+	if err := Resolve(ResolveOptions{DataDir: local, BaseURL: testBaseURL, Path: postPath, How: ResolveMergeFrontmatter}, live); err == nil {
+		t.Error("merge-frontmatter without a key succeeded")
+	}
+	err := Resolve(ResolveOptions{DataDir: local, BaseURL: testBaseURL, Path: postPath, How: ResolveMergeFrontmatter, PrivateKey: privKey}, live)
+	if err != nil {
+		t.Fatalf("merge-frontmatter: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(local, postPath))
+	if fm := publish.ParseFrontmatterFields(string(content)); fm.Title != title || !strings.Contains(string(content), "Local edit.") {
+		t.Errorf("unexpected merge result:\n%s", content)
+	}
+	if c := checkOne(t, local, live); c != nil {
+		t.Errorf("conflict after merge: %+v", c)
+	}
+	if saved, _ := LoadReport(local); saved == nil || len(saved.Conflicts) != 0 {
+		t.Errorf("saved report still lists the conflict: %+v", saved)
+	}
+
+	// Pull replaces the local copy and keeps the old one in the trash
+	if err := Resolve(ResolveOptions{DataDir: local, BaseURL: testBaseURL, Path: postPath, How: ResolvePull}, live); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(local, postPath))
+	if !strings.Contains(string(content), "Remote edit.") {
+		t.Errorf("unexpected pulled content:\n%s", content)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(local, ".polis", "trash")); len(entries) != 1 {
+		t.Errorf("trash has %d items, want 1", len(entries))
+	}
+}
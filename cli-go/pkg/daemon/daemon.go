@@ -0,0 +1,222 @@
+// Package daemon implements the control socket for `polis daemon`.
+//
+// The daemon (bundled binary only) runs the same background sync as
+// `polis serve` without the web UI. It listens on a Unix socket, normally
+// .polis/daemon.sock inside the site directory, and `polis daemon status`,
+// `polis daemon sync`, and `polis daemon stop` talk to it with small HTTP
+// requests over that socket.
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketFilename is the control socket name, relative to .polis/.
+const SocketFilename = "daemon.sock"
+
+// maxSocketPath is the longest socket path that fits in sockaddr_un on all
+// supported platforms (104 bytes on macOS, including the terminator).
+const maxSocketPath = 100
+
+// SocketPath returns the control socket path for a site.
+func SocketPath(dataDir string) string {
+	return SiteSocketPath(dataDir, SocketFilename)
+}
+
+// SiteSocketPath returns the path of the named Unix socket in a site's
+// .polis directory. Sites whose path is too long for a Unix socket use a
+// hashed name in the system temp dir.
+func SiteSocketPath(dataDir, name string) string {
+	if abs, err := filepath.Abs(dataDir); err == nil {
+		dataDir = abs
+	}
+	path := filepath.Join(dataDir, ".polis", name)
+	if len(path) <= maxSocketPath {
+		return path
+	}
+	sum := sha256.Sum256([]byte(dataDir))
+	return filepath.Join(os.TempDir(), "polis-"+hex.EncodeToString(sum[:6])+"-"+name)
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID                int    `json:"pid"`
+	DataDir            string `json:"data_dir"`
+	Version            string `json:"version,omitempty"`
+	StartedAt          string `json:"started_at"`
+	LastSync           string `json:"last_sync,omitempty"`
+	SyncCycles         int    `json:"sync_cycles"`
+	NewNotifications   int    `json:"new_notifications"`
+	NewFeedItems       int    `json:"new_feed_items"`
+	ScheduledPublished int    `json:"scheduled_published"`
+	LastError          string `json:"last_error,omitempty"`
+}
+
+// Controller is implemented by the daemon process.
+type Controller interface {
+	Status() Status
+	TriggerSync()
+	Stop()
+}
+
+// ErrNotRunning is returned by Client methods when no daemon is listening.
+var ErrNotRunning = errors.New("daemon is not running")
+
+// ErrSocketInUse is returned by ListenSocket when another process is
+// listening on the socket.
+var ErrSocketInUse = errors.New("socket is in use")
+
+// Listen opens the control socket for dataDir. A socket file left behind by
+// a daemon that crashed is removed; a live daemon makes Listen fail.
+func Listen(dataDir string) (net.Listener, error) {
+	path := SocketPath(dataDir)
+	ln, err := ListenSocket(path)
+	if errors.Is(err, ErrSocketInUse) {
+		return nil, fmt.Errorf("a daemon is already running for this site (%s)", path)
+	}
+	return ln, err
+}
+
+// ListenSocket listens on the Unix socket at path, readable only by the
+// owner. A stale socket file is removed; a live listener makes it fail with
+// ErrSocketInUse.
+func ListenSocket(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", path, ErrSocketInUse)
+		}
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	os.Chmod(path, 0600)
+	return ln, nil
+}
+
+// Handler returns the control API served on the socket:
+//
+//	GET  /status  daemon status as JSON
+//	POST /sync    run a sync cycle now
+//	POST /stop    shut the daemon down
+func Handler(c Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Status())
+	})
+	mux.HandleFunc("/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		c.TriggerSync()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		// Respond before stopping so the client sees the acknowledgement
+		go c.Stop()
+	})
+	return mux
+}
+
+// Client talks to a running daemon over its control socket.
+type Client struct {
+	SocketPath string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the daemon serving dataDir.
+func NewClient(dataDir string) *Client {
+	path := SocketPath(dataDir)
+	return &Client{
+		SocketPath: path,
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the daemon's status.
+func (c *Client) Status() (*Status, error) {
+	resp, err := c.do(http.MethodGet, "/status")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var st Status
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("invalid status response: %w", err)
+	}
+	return &st, nil
+}
+
+// Sync asks the daemon to run a sync cycle now.
+func (c *Client) Sync() error {
+	resp, err := c.do(http.MethodPost, "/sync")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Stop asks the daemon to shut down.
+func (c *Client) Stop() error {
+	resp, err := c.do(http.MethodPost, "/stop")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) do(method, path string) (*http.Response, error) {
+	req, err := http.NewRequest(method, "http://daemon"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil, ErrNotRunning
+		}
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("daemon returned %s", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeController struct {
+	mu      sync.Mutex
+	syncs   int
+	stopped chan struct{}
+}
+
+func (f *fakeController) Status() Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Status{PID: 42, SyncCycles: f.syncs}
+}
+
+func (f *fakeController) TriggerSync() {
+	f.mu.Lock()
+	f.syncs++
+	f.mu.Unlock()
+}
+
+func (f *fakeController) Stop() { close(f.stopped) }
+
+func TestClient_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ln, err := Listen(dir)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctrl := &fakeController{stopped: make(chan struct{})}
+	srv := &http.Server{Handler: Handler(ctrl)}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	c := NewClient(dir)
+	if err := c.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	st, err := c.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st.PID != 42 || st.SyncCycles != 1 {
+		t.Errorf("status = %+v", st)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case <-ctrl.stopped:
+	case <-time.After(time.Second):
+		t.Error("Stop was not delivered to the controller")
+	}
+}
+
+func TestListen_RejectsSecondDaemon(t *testing.T) {
+	dir := t.TempDir()
+	ln, err := Listen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if _, err := Listen(dir); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("second Listen err = %v", err)
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := SocketPath(dir)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := Listen(dir)
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	ln.Close()
+}
+
+func TestClient_NotRunning(t *testing.T) {
+	if _, err := NewClient(t.TempDir()).Status(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("err = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestSocketPath_LongDataDir(t *testing.T) {
+	long := "/" + strings.Repeat("a", 120)
+	if got := SocketPath(long); len(got) > maxSocketPath || !strings.HasSuffix(got, ".sock") {
+		t.Errorf("SocketPath = %q", got)
+	}
+}
@@ -0,0 +1,275 @@
+// Package delegation lets a site's primary key authorize device keys.
+//
+// A delegation certificate names a device key, what it may sign, and until
+// when, and is signed with the site's primary key. A device keeps its
+// certificate in its private key file, after the key, and embeds it in every
+// post and comment it signs. Readers check the chain: the certificate against
+// the site key in .well-known/polis, then the content against the device key.
+// The primary key never has to be copied to a second machine.
+package delegation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// Scopes a device can be granted.
+const (
+	ScopePublish = "publish" // Posts, including republishing
+	ScopeComment = "comment" // Comments on other sites
+)
+
+// Scopes lists the supported scopes.
+var Scopes = []string{ScopePublish, ScopeComment}
+
+// PEMType is the block a certificate is stored under in a device's private
+// key file.
+const PEMType = "POLIS DELEGATION"
+
+// Field is the frontmatter field that carries a certificate.
+const Field = "delegation"
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+var (
+	// ErrDelegated is returned when a device key tries to delegate.
+	ErrDelegated = errors.New("this is a device key; only the site's primary key can delegate")
+	// ErrRevoked is returned for a device key the site has revoked.
+	ErrRevoked = errors.New("device key has been revoked")
+)
+
+// Cert is a delegation certificate.
+type Cert struct {
+	Domain    string   `json:"domain"`
+	Device    string   `json:"device"`
+	Key       string   `json:"key"` // Device public key, OpenSSH format
+	Scopes    []string `json:"scopes"`
+	Issued    string   `json:"issued"`
+	Expires   string   `json:"expires"`
+	Signature string   `json:"signature"` // By the site's primary key, over Statement
+}
+
+// Device records a delegated key in .well-known/polis, so the site's devices
+// can be listed and revoked.
+type Device struct {
+	Name    string   `json:"name"`
+	Key     string   `json:"key"`
+	Scopes  []string `json:"scopes"`
+	Issued  string   `json:"issued"`
+	Expires string   `json:"expires"`
+	Revoked string   `json:"revoked,omitempty"`
+}
+
+// Statement returns the text the primary key signs.
+func (c *Cert) Statement() string {
+	return fmt.Sprintf("I authorize the device key below to sign for the polis site %s.\n\n"+
+		"domain: %s\ndevice: %s\nkey: %s\nscopes: %s\nissued: %s\nexpires: %s\n",
+		c.Domain, c.Domain, c.Device, c.Key, strings.Join(c.Scopes, ","), c.Issued, c.Expires)
+}
+
+// Issue signs a certificate delegating scopes to deviceKey until expires.
+func Issue(primaryKey []byte, domain, device, deviceKey string, scopes []string, expires time.Time) (*Cert, error) {
+	if cert, _ := FromKey(primaryKey); cert != nil {
+		return nil, ErrDelegated
+	}
+	device = strings.TrimSpace(device)
+	if device == "" || strings.ContainsAny(device, "\n,") {
+		return nil, fmt.Errorf("invalid device name %q", device)
+	}
+	key, err := normalizeKey(deviceKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("no scopes given")
+	}
+	for _, s := range scopes {
+		if !validScope(s) {
+			return nil, fmt.Errorf("unknown scope %q (use %s)", s, strings.Join(Scopes, ", "))
+		}
+	}
+	now := time.Now().UTC()
+	if !expires.After(now) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	c := &Cert{
+		Domain:  domain,
+		Device:  device,
+		Key:     key,
+		Scopes:  scopes,
+		Issued:  now.Format(timeFormat),
+		Expires: expires.UTC().Format(timeFormat),
+	}
+	sig, err := signing.SignContent([]byte(c.Statement()), primaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	c.Signature = sig
+	return c, nil
+}
+
+// Record returns the .well-known/polis entry for the certificate.
+func (c *Cert) Record() Device {
+	return Device{Name: c.Device, Key: c.Key, Scopes: c.Scopes, Issued: c.Issued, Expires: c.Expires}
+}
+
+// Allows reports whether the certificate grants scope.
+func (c *Cert) Allows(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks that the certificate was signed by siteKey for domain, and
+// that it granted scope at the time content was signed. domain may be empty
+// when the site's domain isn't known.
+func (c *Cert) Verify(siteKey, domain, scope string, at time.Time) error {
+	ok, err := signing.VerifySignature([]byte(c.Statement()), []byte(strings.TrimSpace(siteKey)), c.Signature)
+	if err != nil || !ok {
+		return errors.New("certificate is not signed by the site's key")
+	}
+	if domain != "" && !strings.EqualFold(c.Domain, domain) {
+		return fmt.Errorf("certificate is for %s, not %s", c.Domain, domain)
+	}
+	if !c.Allows(scope) {
+		return fmt.Errorf("device %q may not %s", c.Device, scope)
+	}
+	issued, err := time.Parse(timeFormat, c.Issued)
+	if err != nil {
+		return fmt.Errorf("invalid issue time %q", c.Issued)
+	}
+	expires, err := time.Parse(timeFormat, c.Expires)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q", c.Expires)
+	}
+	if at.Before(issued) {
+		return fmt.Errorf("signed before device %q was authorized", c.Device)
+	}
+	if at.After(expires) {
+		return fmt.Errorf("signed after device %q expired (%s)", c.Device, c.Expires)
+	}
+	return nil
+}
+
+// CheckRevoked returns ErrRevoked when devices lists key as revoked.
+func CheckRevoked(devices []Device, key string) error {
+	key, _ = normalizeKey(key)
+	for _, d := range devices {
+		if d.Revoked != "" && d.Key == key {
+			return fmt.Errorf("%w (%s, %s)", ErrRevoked, d.Name, d.Revoked)
+		}
+	}
+	return nil
+}
+
+// Encode returns the certificate as a single line, for frontmatter.
+func (c *Cert) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses an encoded certificate.
+func Decode(s string) (*Cert, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate encoding: %w", err)
+	}
+	var c Cert
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	if c.Key == "" || c.Signature == "" || len(c.Scopes) == 0 {
+		return nil, errors.New("invalid certificate: missing key, scopes, or signature")
+	}
+	return &c, nil
+}
+
+// Attach returns keyPEM with c stored after the key, replacing any
+// certificate already there.
+func Attach(keyPEM []byte, c *Cert) []byte {
+	var out []byte
+	rest := keyPEM
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != PEMType {
+			out = append(out, pem.EncodeToMemory(block)...)
+		}
+		rest = r
+	}
+	data, _ := json.Marshal(c)
+	return append(out, pem.EncodeToMemory(&pem.Block{Type: PEMType, Bytes: data})...)
+}
+
+// FromKey returns the certificate stored in a private key file, or nil for
+// a primary key.
+func FromKey(keyPEM []byte) (*Cert, error) {
+	rest := keyPEM
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return nil, nil
+		}
+		if block.Type == PEMType {
+			var c Cert
+			if err := json.Unmarshal(block.Bytes, &c); err != nil {
+				return nil, fmt.Errorf("invalid certificate in key file: %w", err)
+			}
+			return &c, nil
+		}
+		rest = r
+	}
+}
+
+// FrontmatterLine returns the frontmatter line, with its leading newline,
+// that embeds the certificate of a device key, after checking the device may
+// sign for scope. It is empty for a primary key.
+func FrontmatterLine(keyPEM []byte, scope string) (string, error) {
+	c, err := FromKey(keyPEM)
+	if err != nil || c == nil {
+		return "", err
+	}
+	if !c.Allows(scope) {
+		return "", fmt.Errorf("device %q may not %s (scopes: %s)", c.Device, scope, strings.Join(c.Scopes, ", "))
+	}
+	if expires, err := time.Parse(timeFormat, c.Expires); err == nil && time.Now().After(expires) {
+		return "", fmt.Errorf("device %q expired on %s; ask for a new certificate", c.Device, c.Expires)
+	}
+	return "\n" + Field + ": " + c.Encode(), nil
+}
+
+// ParseTime parses a frontmatter timestamp.
+func ParseTime(s string) (time.Time, error) {
+	return time.Parse(timeFormat, s)
+}
+
+func validScope(s string) bool {
+	for _, v := range Scopes {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey reduces an OpenSSH public key to its type and key data.
+func normalizeKey(key string) (string, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 || fields[0] != "ssh-ed25519" {
+		return "", errors.New("device key must be an ssh-ed25519 public key")
+	}
+	return fields[0] + " " + fields[1], nil
+}
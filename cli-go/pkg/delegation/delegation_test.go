@@ -0,0 +1,121 @@
+package delegation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func keypair(t *testing.T) ([]byte, string) {
+	t.Helper()
+	priv, pub, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatalf("GenerateKeypair: %v", err)
+	}
+	return priv, strings.TrimSpace(string(pub))
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	sitePriv, sitePub := keypair(t)
+	devPriv, devPub := keypair(t)
+	expires := time.Now().Add(24 * time.Hour)
+
+	c, err := Issue(sitePriv, "alice.example.com", "laptop", devPub, []string{ScopePublish}, expires)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	decoded, err := Decode(c.Encode())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	now := time.Now()
+	if err := decoded.Verify(sitePub, "alice.example.com", ScopePublish, now); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := decoded.Verify(sitePub, "alice.example.com", ScopeComment, now); err == nil {
+		t.Error("Verify allowed a scope that wasn't granted")
+	}
+	if err := decoded.Verify(sitePub, "mallory.example.com", ScopePublish, now); err == nil {
+		t.Error("Verify accepted a certificate for another domain")
+	}
+	if err := decoded.Verify(devPub, "alice.example.com", ScopePublish, now); err == nil {
+		t.Error("Verify accepted a certificate against the wrong key")
+	}
+	if err := decoded.Verify(sitePub, "alice.example.com", ScopePublish, expires.Add(time.Hour)); err == nil {
+		t.Error("Verify accepted content signed after expiry")
+	}
+
+	decoded.Scopes = append(decoded.Scopes, ScopeComment)
+	if err := decoded.Verify(sitePub, "alice.example.com", ScopeComment, now); err == nil {
+		t.Error("Verify accepted a certificate with widened scopes")
+	}
+
+	// A device key carries its certificate and can't delegate further
+	devFile := Attach(devPriv, c)
+	got, err := FromKey(devFile)
+	if err != nil || got == nil || got.Key != c.Key {
+		t.Fatalf("FromKey = %+v, %v", got, err)
+	}
+	if _, err := signing.SignContent([]byte("hello"), devFile); err != nil {
+		t.Errorf("signing with a device key file: %v", err)
+	}
+	if _, err := Issue(devFile, "alice.example.com", "phone", devPub, []string{ScopePublish}, expires); !errors.Is(err, ErrDelegated) {
+		t.Errorf("Issue with a device key = %v, want ErrDelegated", err)
+	}
+	if line, err := FrontmatterLine(devFile, ScopePublish); err != nil || !strings.HasPrefix(line, "\ndelegation: ") {
+		t.Errorf("FrontmatterLine(device) = %q, %v", line, err)
+	}
+	if _, err := FrontmatterLine(devFile, ScopeComment); err == nil {
+		t.Error("FrontmatterLine allowed a scope that wasn't granted")
+	}
+	if line, err := FrontmatterLine(sitePriv, ScopeComment); line != "" || err != nil {
+		t.Errorf("FrontmatterLine(primary) = %q, %v", line, err)
+	}
+
+	// Attaching again replaces the certificate
+	if n := strings.Count(string(Attach(devFile, c)), "BEGIN "+PEMType); n != 1 {
+		t.Errorf("re-attached file has %d certificates", n)
+	}
+}
+
+func TestIssue_Invalid(t *testing.T) {
+	sitePriv, _ := keypair(t)
+	_, devPub := keypair(t)
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		device  string
+		key     string
+		scopes  []string
+		expires time.Time
+	}{
+		{"no name", "", devPub, []string{ScopePublish}, later},
+		{"bad key", "laptop", "not a key", []string{ScopePublish}, later},
+		{"no scopes", "laptop", devPub, nil, later},
+		{"unknown scope", "laptop", devPub, []string{"admin"}, later},
+		{"expired", "laptop", devPub, []string{ScopePublish}, time.Now().Add(-time.Hour)},
+	}
+	for _, tt := range tests {
+		if _, err := Issue(sitePriv, "alice.example.com", tt.device, tt.key, tt.scopes, tt.expires); err == nil {
+			t.Errorf("%s: Issue succeeded", tt.name)
+		}
+	}
+}
+
+func TestCheckRevoked(t *testing.T) {
+	_, devPub := keypair(t)
+	key, _ := normalizeKey(devPub)
+	devices := []Device{{Name: "laptop", Key: key}}
+	if err := CheckRevoked(devices, devPub); err != nil {
+		t.Errorf("CheckRevoked on an active device = %v", err)
+	}
+	devices[0].Revoked = "2026-10-01T00:00:00Z"
+	if err := CheckRevoked(devices, devPub); !errors.Is(err, ErrRevoked) {
+		t.Errorf("CheckRevoked = %v, want ErrRevoked", err)
+	}
+}
@@ -0,0 +1,409 @@
+// Package deploy uploads a rendered polis site to a remote host.
+//
+// Targets are configured in .polis/deploy.json. Each deploy hashes the public
+// site files and compares them to the manifest recorded by the previous deploy
+// to the same target (.polis/deploy/<target>.json), so only changed files are
+// transferred. Supported transports: rsync over ssh, SFTP, and S3-compatible
+// object storage.
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// Target types.
+const (
+	TypeRsync = "rsync"
+	TypeSFTP  = "sftp"
+	TypeS3    = "s3"
+)
+
+// ConfigFilename is the deploy target config, relative to .polis/.
+const ConfigFilename = "deploy.json"
+
+// Target describes one deploy destination.
+type Target struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // rsync, sftp, or s3
+
+	// rsync / sftp
+	Host    string `json:"host,omitempty"`
+	User    string `json:"user,omitempty"`
+	Port    int    `json:"port,omitempty"`
+	Path    string `json:"path,omitempty"`     // Remote directory
+	KeyFile string `json:"key_file,omitempty"` // Optional ssh identity file
+
+	// s3
+	Bucket   string `json:"bucket,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"` // e.g. https://s3.us-east-1.amazonaws.com or an R2/MinIO URL
+	Prefix   string `json:"prefix,omitempty"`   // Key prefix inside the bucket
+}
+
+// Config is the on-disk deploy configuration.
+type Config struct {
+	Default string   `json:"default,omitempty"`
+	Targets []Target `json:"targets"`
+}
+
+// Validate checks that a target has the fields its transport needs.
+func (t *Target) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("target name is required")
+	}
+	switch t.Type {
+	case TypeRsync, TypeSFTP:
+		if t.Host == "" {
+			return fmt.Errorf("target %q: host is required", t.Name)
+		}
+		if t.Path == "" {
+			return fmt.Errorf("target %q: path is required", t.Name)
+		}
+	case TypeS3:
+		if t.Bucket == "" {
+			return fmt.Errorf("target %q: bucket is required", t.Name)
+		}
+	default:
+		return fmt.Errorf("target %q: unknown type %q (expected rsync, sftp, or s3)", t.Name, t.Type)
+	}
+	return nil
+}
+
+// ConfigPath returns the path to .polis/deploy.json.
+func ConfigPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", ConfigFilename)
+}
+
+// LoadConfig reads the deploy config. Returns an empty config if none exists.
+func LoadConfig(dataDir string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read deploy config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse deploy config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// SaveConfig writes the deploy config.
+func SaveConfig(dataDir string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deploy config: %w", err)
+	}
+	path := ConfigPath(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .polis directory: %w", err)
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// Find returns the named target, or the default target if name is empty.
+// With a single configured target, it is used when no default is set.
+func (c *Config) Find(name string) (*Target, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		if len(c.Targets) == 1 {
+			return &c.Targets[0], nil
+		}
+		if len(c.Targets) == 0 {
+			return nil, fmt.Errorf("no deploy targets configured (add one to .polis/%s)", ConfigFilename)
+		}
+		return nil, fmt.Errorf("multiple deploy targets configured; specify one by name")
+	}
+	for i := range c.Targets {
+		if c.Targets[i].Name == name {
+			return &c.Targets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deploy target not found: %s", name)
+}
+
+// Transport moves files to a remote destination. Paths are slash-separated
+// and relative to the site root; progress is called once per completed file.
+type Transport interface {
+	Upload(ctx context.Context, root string, files []string, progress func(path string)) error
+	Delete(ctx context.Context, files []string, progress func(path string)) error
+}
+
+// NewTransport returns the transport for a target.
+func NewTransport(t *Target) (Transport, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	switch t.Type {
+	case TypeRsync:
+		return &RsyncTransport{Target: *t}, nil
+	case TypeSFTP:
+		return &SFTPTransport{Target: *t}, nil
+	default:
+		return NewS3Transport(t)
+	}
+}
+
+// Manifest records the hash of every file at the time of the last deploy.
+type Manifest struct {
+	Target     string            `json:"target"`
+	DeployedAt string            `json:"deployed_at,omitempty"`
+	Hash       string            `json:"hash,omitempty"` // ManifestHash of Files at DeployedAt
+	Files      map[string]string `json:"files"`          // relative path -> sha256:<hex>
+}
+
+// ManifestPath returns the path to .polis/deploy/<target>.json.
+func ManifestPath(dataDir, target string) string {
+	return filepath.Join(dataDir, ".polis", "deploy", target+".json")
+}
+
+// LoadManifest reads the last deploy manifest for a target.
+// Returns an empty manifest if the target has never been deployed.
+func LoadManifest(dataDir, target string) (*Manifest, error) {
+	m := &Manifest{Target: target, Files: map[string]string{}}
+	data, err := os.ReadFile(ManifestPath(dataDir, target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read deploy manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse deploy manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+	return m, nil
+}
+
+// SaveManifest writes the deploy manifest for a target.
+func SaveManifest(dataDir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deploy manifest: %w", err)
+	}
+	path := ManifestPath(dataDir, m.Target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create deploy state directory: %w", err)
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// excludedTopLevel lists site-root entries that are never deployed.
+// Matches the .gitignore written by polis init, plus private runtime state.
+var excludedTopLevel = map[string]bool{
+	".polis":     true,
+	".git":       true,
+	".gitignore": true,
+	"themes":     true,
+	"polis":      true,
+	"polis-tui":  true,
+	"logs":       true,
+	// Followers-only posts, served only by polis serve
+	publish.ProtectedDir: true,
+}
+
+// isExcluded reports whether a relative path should not be deployed.
+func isExcluded(rel string) bool {
+	top := strings.SplitN(rel, "/", 2)[0]
+	if excludedTopLevel[top] {
+		return true
+	}
+	return strings.HasPrefix(top, ".env")
+}
+
+// Scan hashes every deployable file under dataDir.
+func Scan(dataDir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if isExcluded(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() && strings.HasSuffix(info.Name(), lockfile.Suffix) {
+			// An in-progress metadata update (see pkg/lockfile).
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan site: %w", err)
+	}
+	return files, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Plan is the set of changes needed to bring a target up to date.
+type Plan struct {
+	Upload    []string `json:"upload"`
+	Delete    []string `json:"delete"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Diff compares current file hashes with the last deployed manifest.
+func Diff(current, previous map[string]string) *Plan {
+	plan := &Plan{}
+	for path, hash := range current {
+		if previous[path] == hash {
+			plan.Unchanged++
+			continue
+		}
+		plan.Upload = append(plan.Upload, path)
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			plan.Delete = append(plan.Delete, path)
+		}
+	}
+	sort.Strings(plan.Upload)
+	sort.Strings(plan.Delete)
+	return plan
+}
+
+// Options control a deploy run.
+type Options struct {
+	DryRun bool // Compute the plan without transferring anything
+	Full   bool // Ignore the previous manifest and upload everything
+	Prune  bool // Delete remote files that no longer exist locally
+}
+
+// Progress is reported for each file transferred or deleted.
+type Progress struct {
+	Target string `json:"target"`
+	Action string `json:"action"` // "upload" or "delete"
+	Path   string `json:"path"`
+	Done   int    `json:"done"`
+	Total  int    `json:"total"`
+}
+
+// Result summarizes a deploy run.
+type Result struct {
+	Target    string   `json:"target"`
+	DryRun    bool     `json:"dry_run"`
+	Uploaded  []string `json:"uploaded"`
+	Deleted   []string `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Run deploys dataDir to a target. The manifest is updated with every file
+// that was transferred, even if the run fails part-way, so a retry resumes
+// where it left off.
+func Run(ctx context.Context, dataDir string, target *Target, transport Transport, opts Options, progress func(Progress)) (*Result, error) {
+	current, err := Scan(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := LoadManifest(dataDir, target.Name)
+	if err != nil {
+		return nil, err
+	}
+	previous := manifest.Files
+	if opts.Full {
+		previous = map[string]string{}
+	}
+
+	plan := Diff(current, previous)
+	if !opts.Prune {
+		plan.Delete = nil
+	}
+
+	result := &Result{
+		Target:    target.Name,
+		DryRun:    opts.DryRun,
+		Uploaded:  []string{},
+		Deleted:   []string{},
+		Unchanged: plan.Unchanged,
+	}
+	if opts.DryRun {
+		result.Uploaded = append(result.Uploaded, plan.Upload...)
+		result.Deleted = append(result.Deleted, plan.Delete...)
+		return result, nil
+	}
+
+	total := len(plan.Upload) + len(plan.Delete)
+	done := 0
+	report := func(action, path string) {
+		done++
+		if progress != nil {
+			progress(Progress{Target: target.Name, Action: action, Path: path, Done: done, Total: total})
+		}
+	}
+
+	var runErr error
+	if len(plan.Upload) > 0 {
+		runErr = transport.Upload(ctx, dataDir, plan.Upload, func(path string) {
+			manifest.Files[path] = current[path]
+			result.Uploaded = append(result.Uploaded, path)
+			report("upload", path)
+		})
+	}
+	if runErr == nil && len(plan.Delete) > 0 {
+		runErr = transport.Delete(ctx, plan.Delete, func(path string) {
+			delete(manifest.Files, path)
+			result.Deleted = append(result.Deleted, path)
+			report("delete", path)
+		})
+	}
+
+	if len(result.Uploaded) > 0 || len(result.Deleted) > 0 {
+		manifest.DeployedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	manifest.Hash = ManifestHash(manifest.Files)
+	if err := SaveManifest(dataDir, manifest); err != nil && runErr == nil {
+		runErr = err
+	}
+	if runErr != nil {
+		return result, runErr
+	}
+	return result, nil
+}
@@ -0,0 +1,320 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func writeSiteFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	full := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeTransport records transfers and optionally fails after N uploads.
+type fakeTransport struct {
+	uploaded []string
+	deleted  []string
+	failAt   int
+}
+
+func (f *fakeTransport) Upload(ctx context.Context, root string, files []string, progress func(string)) error {
+	for i, file := range files {
+		if f.failAt > 0 && i == f.failAt {
+			return errors.New("connection lost")
+		}
+		f.uploaded = append(f.uploaded, file)
+		progress(file)
+	}
+	return nil
+}
+
+func (f *fakeTransport) Delete(ctx context.Context, files []string, progress func(string)) error {
+	for _, file := range files {
+		f.deleted = append(f.deleted, file)
+		progress(file)
+	}
+	return nil
+}
+
+func TestScan_ExcludesPrivateFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "index.html", "<html>")
+	writeSiteFile(t, dir, ".well-known/polis", "{}")
+	writeSiteFile(t, dir, "posts/20260101/hello.html", "hi")
+	writeSiteFile(t, dir, ".polis/keys/id_ed25519", "secret")
+	writeSiteFile(t, dir, ".env", "KEY=1")
+	writeSiteFile(t, dir, ".env.local", "KEY=2")
+	writeSiteFile(t, dir, "themes/turbo/post.html", "theme")
+	writeSiteFile(t, dir, "metadata/public.jsonl.lock/owner.json", "{}")
+
+	files, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	for _, want := range []string{"index.html", ".well-known/polis", "posts/20260101/hello.html"} {
+		if _, ok := files[want]; !ok {
+			t.Errorf("expected %s in scan", want)
+		}
+	}
+	for _, unwanted := range []string{".polis/keys/id_ed25519", ".env", ".env.local", "themes/turbo/post.html", "metadata/public.jsonl.lock/owner.json"} {
+		if _, ok := files[unwanted]; ok {
+			t.Errorf("%s must not be deployed", unwanted)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	plan := Diff(
+		map[string]string{"a": "1", "b": "2", "c": "3"},
+		map[string]string{"a": "1", "b": "old", "d": "4"},
+	)
+	if !reflect.DeepEqual(plan.Upload, []string{"b", "c"}) {
+		t.Errorf("unexpected upload list: %v", plan.Upload)
+	}
+	if !reflect.DeepEqual(plan.Delete, []string{"d"}) {
+		t.Errorf("unexpected delete list: %v", plan.Delete)
+	}
+	if plan.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged, got %d", plan.Unchanged)
+	}
+}
+
+func TestRun_UploadsOnlyChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "index.html", "v1")
+	writeSiteFile(t, dir, "styles.css", "body{}")
+	target := &Target{Name: "prod", Type: TypeRsync, Host: "h", Path: "/srv"}
+
+	tr := &fakeTransport{}
+	var events []Progress
+	res, err := Run(context.Background(), dir, target, tr, Options{}, func(p Progress) { events = append(events, p) })
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(res.Uploaded) != 2 || len(events) != 2 {
+		t.Fatalf("expected 2 uploads and 2 progress events, got %v / %d", res.Uploaded, len(events))
+	}
+	if events[1].Done != 2 || events[1].Total != 2 {
+		t.Errorf("unexpected progress: %+v", events[1])
+	}
+
+	// Second run with one changed file
+	writeSiteFile(t, dir, "index.html", "v2")
+	tr = &fakeTransport{}
+	res, err = Run(context.Background(), dir, target, tr, Options{}, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !reflect.DeepEqual(tr.uploaded, []string{"index.html"}) {
+		t.Errorf("expected only index.html re-uploaded, got %v", tr.uploaded)
+	}
+	if res.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged, got %d", res.Unchanged)
+	}
+}
+
+func TestRun_SkipsFollowersOnlyPosts(t *testing.T) {
+	dir := t.TempDir()
+	privKey, _, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := publish.PublishPost(dir, "# Hello\n\nFor everyone.\n", "hello.md", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := publish.PublishPostWithOptions(dir, "# Members\n\nFor followers.\n", publish.SourceOptions("---\nvisibility: followers\n---\n"), privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(private.Path, publish.ProtectedDir+"/") {
+		t.Fatalf("followers-only post published at %s", private.Path)
+	}
+
+	tr := &fakeTransport{}
+	target := &Target{Name: "prod", Type: TypeRsync, Host: "h", Path: "/srv"}
+	if _, err := Run(context.Background(), dir, target, tr, Options{}, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	uploaded := strings.Join(tr.uploaded, "\n")
+	if !strings.Contains(uploaded, public.Path) {
+		t.Errorf("public post %s not uploaded: %v", public.Path, tr.uploaded)
+	}
+	for _, f := range tr.uploaded {
+		if strings.HasPrefix(f, publish.ProtectedDir+"/") {
+			t.Errorf("followers-only file %s was uploaded", f)
+		}
+	}
+}
+
+func TestRun_PruneAndDryRun(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "a.html", "a")
+	writeSiteFile(t, dir, "b.html", "b")
+	target := &Target{Name: "prod", Type: TypeS3, Bucket: "site"}
+
+	if _, err := Run(context.Background(), dir, target, &fakeTransport{}, Options{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "b.html"))
+
+	// Without --prune nothing is deleted
+	tr := &fakeTransport{}
+	Run(context.Background(), dir, target, tr, Options{}, nil)
+	if len(tr.deleted) != 0 {
+		t.Errorf("expected no deletes without prune, got %v", tr.deleted)
+	}
+
+	// Dry run reports without transferring
+	tr = &fakeTransport{}
+	res, _ := Run(context.Background(), dir, target, tr, Options{Prune: true, DryRun: true}, nil)
+	if len(tr.deleted) != 0 || !reflect.DeepEqual(res.Deleted, []string{"b.html"}) {
+		t.Errorf("unexpected dry run: transport=%v result=%v", tr.deleted, res.Deleted)
+	}
+
+	tr = &fakeTransport{}
+	Run(context.Background(), dir, target, tr, Options{Prune: true}, nil)
+	if !reflect.DeepEqual(tr.deleted, []string{"b.html"}) {
+		t.Errorf("expected b.html deleted, got %v", tr.deleted)
+	}
+	m, _ := LoadManifest(dir, "prod")
+	if _, ok := m.Files["b.html"]; ok {
+		t.Error("deleted file should be removed from manifest")
+	}
+}
+
+func TestRun_PartialFailureRecordsProgress(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "a.html", "a")
+	writeSiteFile(t, dir, "b.html", "b")
+	writeSiteFile(t, dir, "c.html", "c")
+	target := &Target{Name: "prod", Type: TypeSFTP, Host: "h", Path: "/srv"}
+
+	_, err := Run(context.Background(), dir, target, &fakeTransport{failAt: 1}, Options{}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	tr := &fakeTransport{}
+	if _, err := Run(context.Background(), dir, target, tr, Options{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tr.uploaded, []string{"b.html", "c.html"}) {
+		t.Errorf("expected retry to resume after a.html, got %v", tr.uploaded)
+	}
+}
+
+func TestConfig_Find(t *testing.T) {
+	cfg := &Config{Targets: []Target{{Name: "only", Type: TypeRsync}}}
+	if tgt, err := cfg.Find(""); err != nil || tgt.Name != "only" {
+		t.Errorf("expected single target as default, got %v %v", tgt, err)
+	}
+
+	cfg.Targets = append(cfg.Targets, Target{Name: "other", Type: TypeS3})
+	if _, err := cfg.Find(""); err == nil {
+		t.Error("expected error with multiple targets and no default")
+	}
+	cfg.Default = "other"
+	if tgt, _ := cfg.Find(""); tgt.Name != "other" {
+		t.Errorf("expected default target, got %s", tgt.Name)
+	}
+	if _, err := cfg.Find("missing"); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestTarget_Validate(t *testing.T) {
+	bad := []Target{
+		{Name: "a", Type: TypeRsync, Path: "/srv"},
+		{Name: "b", Type: TypeS3},
+		{Name: "c", Type: "ftp"},
+	}
+	for _, tgt := range bad {
+		if err := tgt.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", tgt)
+		}
+	}
+}
+
+func TestParentDirs(t *testing.T) {
+	got := parentDirs([]string{"posts/2026/a.html", "posts/2026/b.html", "index.html", ".well-known/polis"})
+	want := []string{".well-known", "posts", "posts/2026"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parentDirs = %v, want %v", got, want)
+	}
+}
+
+func TestS3Transport_UploadAndDelete(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			http.Error(w, "unsigned", http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got[r.Method+" "+r.URL.Path] = string(body) + "|" + r.Header.Get("Content-Type")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "posts/hello.html", "<p>hi</p>")
+
+	tr := &S3Transport{
+		Target:    Target{Name: "s3", Type: TypeS3, Bucket: "site", Endpoint: srv.URL, Prefix: "/blog/"},
+		AccessKey: "AKID",
+		SecretKey: "secret",
+	}
+	var done []string
+	if err := tr.Upload(context.Background(), dir, []string{"posts/hello.html"}, func(p string) { done = append(done, p) }); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if err := tr.Delete(context.Background(), []string{"old.html"}, func(p string) { done = append(done, p) }); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if v := got["PUT /site/blog/posts/hello.html"]; !strings.HasPrefix(v, "<p>hi</p>|text/html") {
+		t.Errorf("unexpected PUT: %q (all: %v)", v, got)
+	}
+	if _, ok := got["DELETE /site/blog/old.html"]; !ok {
+		t.Errorf("expected DELETE request, got %v", got)
+	}
+	if len(done) != 2 {
+		t.Errorf("expected 2 progress callbacks, got %v", done)
+	}
+}
+
+func TestS3Transport_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "index.html", "x")
+	tr := &S3Transport{Target: Target{Bucket: "site", Endpoint: srv.URL}, AccessKey: "a", SecretKey: "b"}
+	err := tr.Upload(context.Background(), dir, []string{"index.html"}, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected 403 error, got %v", err)
+	}
+}
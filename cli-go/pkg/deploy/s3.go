@@ -0,0 +1,202 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// S3Transport uploads to S3-compatible object storage (AWS S3, Cloudflare R2,
+// MinIO, etc.) using path-style requests signed with AWS Signature V4.
+//
+// Credentials come from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY so they
+// never land in .polis/deploy.json.
+type S3Transport struct {
+	Target     Target
+	AccessKey  string
+	SecretKey  string
+	HTTPClient *http.Client
+}
+
+// NewS3Transport creates an S3 transport using credentials from the environment.
+func NewS3Transport(t *Target) (*S3Transport, error) {
+	access := os.Getenv("AWS_ACCESS_KEY_ID")
+	secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if access == "" || secret == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for s3 targets")
+	}
+	return &S3Transport{
+		Target:     *t,
+		AccessKey:  access,
+		SecretKey:  secret,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Upload PUTs each file as an object.
+func (s *S3Transport) Upload(ctx context.Context, root string, files []string, progress func(string)) error {
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		contentType := mime.TypeByExtension(path.Ext(f))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		if err := s.do(ctx, http.MethodPut, f, data, contentType); err != nil {
+			return err
+		}
+		progress(f)
+	}
+	return nil
+}
+
+// Delete removes each object.
+func (s *S3Transport) Delete(ctx context.Context, files []string, progress func(string)) error {
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.do(ctx, http.MethodDelete, f, nil, ""); err != nil {
+			return err
+		}
+		progress(f)
+	}
+	return nil
+}
+
+func (s *S3Transport) region() string {
+	if s.Target.Region != "" {
+		return s.Target.Region
+	}
+	return "us-east-1"
+}
+
+func (s *S3Transport) endpoint() string {
+	if s.Target.Endpoint != "" {
+		return strings.TrimSuffix(s.Target.Endpoint, "/")
+	}
+	return "https://s3." + s.region() + ".amazonaws.com"
+}
+
+// objectURL returns the path-style URL for a site-relative file.
+func (s *S3Transport) objectURL(rel string) (*url.URL, error) {
+	key := rel
+	if prefix := strings.Trim(s.Target.Prefix, "/"); prefix != "" {
+		key = prefix + "/" + rel
+	}
+	u, err := url.Parse(s.endpoint())
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	u.Path = "/" + s.Target.Bucket + "/" + key
+	return u, nil
+}
+
+func (s *S3Transport) do(ctx context.Context, method, rel string, body []byte, contentType string) error {
+	u, err := s.objectURL(rel)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 %s %s: %w", method, rel, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 %s %s: HTTP %d: %s", method, rel, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign adds AWS Signature V4 headers to req.
+func (s *S3Transport) sign(req *http.Request, body []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = append([]string{"content-type"}, signedHeaders...)
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region() + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
@@ -0,0 +1,193 @@
+package deploy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RsyncTransport uploads with rsync over ssh. Requires rsync and ssh on PATH.
+type RsyncTransport struct {
+	Target Target
+}
+
+// Upload sends files in a single rsync invocation, reporting each file as
+// rsync prints it.
+func (r *RsyncTransport) Upload(ctx context.Context, root string, files []string, progress func(string)) error {
+	want := make(map[string]bool, len(files))
+	for _, f := range files {
+		want[f] = true
+	}
+
+	args := []string{
+		"-rlt", "--compress", "--files-from=-", "--out-format=%n",
+		"--rsync-path=" + "mkdir -p " + shellQuote(r.Target.Path) + " && rsync",
+		"-e", strings.Join(append([]string{"ssh"}, sshArgs(&r.Target, "-p")...), " "),
+		strings.TrimSuffix(root, "/") + "/",
+		remoteSpec(&r.Target) + ":" + strings.TrimSuffix(r.Target.Path, "/") + "/",
+	}
+	cmd := exec.CommandContext(ctx, "rsync", args...)
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\n") + "\n")
+	return runStreaming(cmd, "rsync", func(line string) {
+		if want[line] {
+			progress(line)
+		}
+	})
+}
+
+// Delete removes remote files with a single ssh rm command.
+func (r *RsyncTransport) Delete(ctx context.Context, files []string, progress func(string)) error {
+	base := strings.TrimSuffix(r.Target.Path, "/")
+	quoted := make([]string, len(files))
+	for i, f := range files {
+		quoted[i] = shellQuote(base + "/" + f)
+	}
+	args := append(sshArgs(&r.Target, "-p"), remoteSpec(&r.Target), "rm -f -- "+strings.Join(quoted, " "))
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ssh rm failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	for _, f := range files {
+		progress(f)
+	}
+	return nil
+}
+
+// SFTPTransport uploads with the OpenSSH sftp client in batch mode.
+type SFTPTransport struct {
+	Target Target
+}
+
+// Upload creates any missing directories and puts each file. sftp echoes
+// each batch command as it runs, which drives per-file progress.
+func (s *SFTPTransport) Upload(ctx context.Context, root string, files []string, progress func(string)) error {
+	base := strings.TrimSuffix(s.Target.Path, "/")
+	var batch bytes.Buffer
+	for _, dir := range parentDirs(files) {
+		fmt.Fprintf(&batch, "-mkdir %s\n", sftpQuote(base+"/"+dir))
+	}
+	remoteToRel := make(map[string]string, len(files))
+	for _, f := range files {
+		remote := base + "/" + f
+		remoteToRel[remote] = f
+		fmt.Fprintf(&batch, "put %s %s\n", sftpQuote(filepath.Join(root, filepath.FromSlash(f))), sftpQuote(remote))
+	}
+	return s.runBatch(ctx, &batch, "put ", remoteToRel, progress)
+}
+
+// Delete removes each remote file.
+func (s *SFTPTransport) Delete(ctx context.Context, files []string, progress func(string)) error {
+	base := strings.TrimSuffix(s.Target.Path, "/")
+	var batch bytes.Buffer
+	remoteToRel := make(map[string]string, len(files))
+	for _, f := range files {
+		remote := base + "/" + f
+		remoteToRel[remote] = f
+		fmt.Fprintf(&batch, "-rm %s\n", sftpQuote(remote))
+	}
+	return s.runBatch(ctx, &batch, "-rm ", remoteToRel, progress)
+}
+
+func (s *SFTPTransport) runBatch(ctx context.Context, batch io.Reader, prefix string, remoteToRel map[string]string, progress func(string)) error {
+	args := append([]string{"-b", "-"}, sshArgs(&s.Target, "-P")...)
+	args = append(args, remoteSpec(&s.Target))
+	cmd := exec.CommandContext(ctx, "sftp", args...)
+	cmd.Stdin = batch
+
+	// Echoed lines look like: sftp> put "local" "remote"
+	return runStreaming(cmd, "sftp", func(line string) {
+		line = strings.TrimPrefix(line, "sftp> ")
+		if !strings.HasPrefix(line, prefix) {
+			return
+		}
+		fields := strings.Split(line, `"`)
+		if len(fields) < 2 {
+			return
+		}
+		remote := fields[len(fields)-2]
+		if rel, ok := remoteToRel[remote]; ok {
+			progress(rel)
+		}
+	})
+}
+
+// sshArgs returns ssh options for a target. portFlag is -p for ssh and -P for sftp.
+func sshArgs(t *Target, portFlag string) []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if t.Port > 0 {
+		args = append(args, portFlag, strconv.Itoa(t.Port))
+	}
+	if t.KeyFile != "" {
+		args = append(args, "-i", t.KeyFile)
+	}
+	return args
+}
+
+// remoteSpec returns user@host (or host if no user is configured).
+func remoteSpec(t *Target) string {
+	if t.User != "" {
+		return t.User + "@" + t.Host
+	}
+	return t.Host
+}
+
+// parentDirs returns every directory that must exist for files, parents first.
+func parentDirs(files []string) []string {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for dir := path.Dir(f); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			seen[dir] = true
+		}
+	}
+	dirs := make([]string, 0, len(seen))
+	for d := range seen {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := strings.Count(dirs[i], "/"), strings.Count(dirs[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs
+}
+
+// runStreaming runs cmd, passing each stdout line to onLine.
+func runStreaming(cmd *exec.Cmd, name string, onLine func(string)) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", name, err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		onLine(strings.TrimSpace(scanner.Text()))
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sftpQuote double-quotes s for an sftp batch file.
+func sftpQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,171 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SentinelFiles are fetched from the live site to detect drift. They are
+// small, always present, and change whenever content is published.
+var SentinelFiles = []string{
+	".well-known/polis",
+	"metadata/public.jsonl",
+	"index.html",
+}
+
+// Sentinel statuses.
+const (
+	SentinelOK      = "ok"      // Live matches what was last deployed (or local, if never deployed)
+	SentinelDrift   = "drift"   // Live differs from what we deployed
+	SentinelMissing = "missing" // Live returned 404
+	SentinelError   = "error"   // Live could not be fetched
+)
+
+// SentinelCheck compares one sentinel file across local, deployed, and live.
+type SentinelCheck struct {
+	Path     string `json:"path"`
+	Local    string `json:"local,omitempty"`
+	Deployed string `json:"deployed,omitempty"`
+	Live     string `json:"live,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Status reports how far the local site has moved since the last deploy and
+// whether the live site still matches it.
+type Status struct {
+	Target       string          `json:"target,omitempty"`
+	DeployedAt   string          `json:"deployed_at,omitempty"`
+	DeployedHash string          `json:"deployed_hash,omitempty"`
+	LocalHash    string          `json:"local_hash"`
+	PendingCount int             `json:"pending_count"` // Files changed or added locally since last deploy
+	DeleteCount  int             `json:"delete_count"`  // Files removed locally since last deploy
+	UpToDate     bool            `json:"up_to_date"`
+	Live         bool            `json:"live_checked"`
+	Drift        bool            `json:"drift"`
+	Sentinels    []SentinelCheck `json:"sentinels,omitempty"`
+}
+
+// ManifestHash returns a single hash over a file->hash map, so two
+// manifests can be compared without diffing every entry.
+func ManifestHash(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s %s\n", files[p], p)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// CheckStatus compares the local site to the last deploy of target and, if
+// baseURL is set, fetches sentinel files from the live site. target may be nil
+// when no deploy targets are configured; sentinels are then compared to local.
+func CheckStatus(ctx context.Context, dataDir string, target *Target, baseURL string, client *http.Client) (*Status, error) {
+	current, err := Scan(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{LocalHash: ManifestHash(current)}
+
+	deployed := map[string]string{}
+	if target != nil {
+		m, err := LoadManifest(dataDir, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		status.Target = target.Name
+		status.DeployedAt = m.DeployedAt
+		if m.DeployedAt != "" {
+			deployed = m.Files
+			status.DeployedHash = m.Hash
+			if status.DeployedHash == "" {
+				status.DeployedHash = ManifestHash(m.Files)
+			}
+		}
+		plan := Diff(current, deployed)
+		status.PendingCount = len(plan.Upload)
+		status.DeleteCount = len(plan.Delete)
+		status.UpToDate = status.DeployedHash == status.LocalHash
+	}
+
+	if baseURL == "" {
+		return status, nil
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	status.Live = true
+	base := strings.TrimSuffix(baseURL, "/")
+	for _, path := range SentinelFiles {
+		check := SentinelCheck{
+			Path:     path,
+			Local:    current[path],
+			Deployed: deployed[path],
+		}
+		expected := check.Deployed
+		if expected == "" {
+			expected = check.Local
+		}
+
+		live, code, err := fetchHash(ctx, client, base+"/"+path)
+		switch {
+		case err != nil:
+			check.Status = SentinelError
+			check.Error = err.Error()
+		case code == http.StatusNotFound:
+			check.Status = SentinelMissing
+			if expected != "" {
+				status.Drift = true
+			}
+		case code != http.StatusOK:
+			check.Status = SentinelError
+			check.Error = fmt.Sprintf("HTTP %d", code)
+		default:
+			check.Live = live
+			if expected != "" && live != expected {
+				check.Status = SentinelDrift
+				status.Drift = true
+			} else {
+				check.Status = SentinelOK
+			}
+		}
+		status.Sentinels = append(status.Sentinels, check)
+	}
+
+	return status, nil
+}
+
+// fetchHash GETs url and returns the sha256 of the body.
+func fetchHash(ctx context.Context, client *http.Client, url string) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", resp.StatusCode, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(resp.Body, 10<<20)); err != nil {
+		return "", resp.StatusCode, err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), resp.StatusCode, nil
+}
@@ -0,0 +1,110 @@
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestHash_OrderIndependent(t *testing.T) {
+	a := ManifestHash(map[string]string{"a": "1", "b": "2"})
+	b := ManifestHash(map[string]string{"b": "2", "a": "1"})
+	if a != b {
+		t.Errorf("expected equal hashes, got %s vs %s", a, b)
+	}
+	if a == ManifestHash(map[string]string{"a": "1", "b": "3"}) {
+		t.Error("expected hash to change with content")
+	}
+}
+
+func TestCheckStatus_PendingChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "index.html", "v1")
+	writeSiteFile(t, dir, "old.html", "gone soon")
+	target := &Target{Name: "prod", Type: TypeRsync, Host: "h", Path: "/srv"}
+
+	st, err := CheckStatus(context.Background(), dir, target, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.UpToDate || st.PendingCount != 2 {
+		t.Errorf("expected 2 pending before first deploy, got %+v", st)
+	}
+
+	if _, err := Run(context.Background(), dir, target, &fakeTransport{}, Options{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	st, _ = CheckStatus(context.Background(), dir, target, "", nil)
+	if !st.UpToDate || st.PendingCount != 0 || st.DeployedAt == "" {
+		t.Errorf("expected up to date after deploy, got %+v", st)
+	}
+
+	writeSiteFile(t, dir, "index.html", "v2")
+	os.Remove(filepath.Join(dir, "old.html"))
+	st, _ = CheckStatus(context.Background(), dir, target, "", nil)
+	if st.UpToDate || st.PendingCount != 1 || st.DeleteCount != 1 {
+		t.Errorf("expected 1 pending + 1 delete, got %+v", st)
+	}
+}
+
+func TestCheckStatus_DetectsLiveDrift(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, ".well-known/polis", `{"v":1}`)
+	writeSiteFile(t, dir, "metadata/public.jsonl", "{}\n")
+	writeSiteFile(t, dir, "index.html", "home")
+	target := &Target{Name: "prod", Type: TypeRsync, Host: "h", Path: "/srv"}
+	Run(context.Background(), dir, target, &fakeTransport{}, Options{}, nil)
+
+	live := map[string]string{
+		"/.well-known/polis":     `{"v":1}`,
+		"/metadata/public.jsonl": "{}\n",
+		"/index.html":            "edited on the server",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := live[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	st, err := CheckStatus(context.Background(), dir, target, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Drift {
+		t.Fatal("expected drift to be detected")
+	}
+	byPath := map[string]string{}
+	for _, s := range st.Sentinels {
+		byPath[s.Path] = s.Status
+	}
+	if byPath[".well-known/polis"] != SentinelOK || byPath["index.html"] != SentinelDrift {
+		t.Errorf("unexpected sentinel statuses: %v", byPath)
+	}
+
+	delete(live, "/metadata/public.jsonl")
+	live["/index.html"] = "home"
+	st, _ = CheckStatus(context.Background(), dir, target, srv.URL, nil)
+	if !st.Drift {
+		t.Error("expected missing sentinel to count as drift")
+	}
+}
+
+func TestCheckStatus_NoTarget(t *testing.T) {
+	dir := t.TempDir()
+	writeSiteFile(t, dir, "index.html", "home")
+
+	st, err := CheckStatus(context.Background(), dir, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Target != "" || st.PendingCount != 0 || st.LocalHash == "" {
+		t.Errorf("unexpected status without target: %+v", st)
+	}
+}
@@ -0,0 +1,281 @@
+// Package digest compiles a summary of a site's network activity over a
+// period: highlights from the feed, comments received, new followers, and
+// the site's own posts.
+//
+// A digest is built from what is already on disk (the feed cache, the
+// notification state, the follower state, and metadata/public.jsonl), so
+// it needs no network access; run a sync first for an up-to-date one.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// DefaultSince is the period a digest covers when none is given.
+const DefaultSince = "7d"
+
+// MaxHighlights caps the feed posts listed in a digest.
+const MaxHighlights = 20
+
+// commentRules are the notification rules that report comments on, replies
+// to, or mentions of the site.
+var commentRules = map[string]bool{
+	"new-comment":        true,
+	"blessing-requested": true,
+	"mention":            true,
+	"thread-reply":       true,
+}
+
+// Options selects what a digest covers.
+type Options struct {
+	Since           time.Time
+	Until           time.Time
+	DiscoveryDomain string // Where the feed cache and stream state live
+	BaseURL         string // Used to link the site's own posts; relative links when empty
+}
+
+// Activity is one comment, reply, or mention received.
+type Activity struct {
+	Actor   string `json:"actor"`
+	Message string `json:"message"`
+	URL     string `json:"url,omitempty"`
+	At      string `json:"at"`
+}
+
+// Post is one of the site's own posts.
+type Post struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Published string `json:"published"`
+}
+
+// Digest is the site's activity between Since and Until.
+type Digest struct {
+	Since      time.Time             `json:"since"`
+	Until      time.Time             `json:"until"`
+	Highlights []feed.CachedFeedItem `json:"highlights"`
+	FeedTotal  int                   `json:"feed_total"` // Feed items in the period, including the ones not listed
+	Comments   []Activity            `json:"comments"`
+	Followers  []author.Follower     `json:"followers"`
+	Posts      []Post                `json:"posts"`
+}
+
+// ParseSince parses a digest period such as 7d, 2w, 36h, or 90m.
+func ParseSince(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 7d, 2w, or 24h)", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 7d, 2w, or 24h)", s)
+	}
+	unit := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}[s[len(s)-1]]
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 7d, 2w, or 24h)", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Build compiles the digest for dataDir. Sources that don't exist yet (no
+// feed cache, no followers) contribute nothing.
+func Build(dataDir string, opts Options) (*Digest, error) {
+	d := &Digest{
+		Since:      opts.Since,
+		Until:      opts.Until,
+		Highlights: []feed.CachedFeedItem{},
+		Comments:   []Activity{},
+		Followers:  []author.Follower{},
+		Posts:      []Post{},
+	}
+	domain := opts.DiscoveryDomain
+	if domain == "" {
+		domain = "default"
+	}
+
+	items, err := feed.NewCacheManager(dataDir, domain).ListByType("post")
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if d.within(item.Published) {
+			d.FeedTotal++
+			d.Highlights = append(d.Highlights, item)
+		}
+	}
+	// Unread posts first, then newest
+	sort.SliceStable(d.Highlights, func(i, j int) bool {
+		a, b := d.Highlights[i], d.Highlights[j]
+		if (a.ReadAt == "") != (b.ReadAt == "") {
+			return a.ReadAt == ""
+		}
+		return a.Published > b.Published
+	})
+	if len(d.Highlights) > MaxHighlights {
+		d.Highlights = d.Highlights[:MaxHighlights]
+	}
+
+	entries, err := notification.NewManager(dataDir, domain).List()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !commentRules[e.RuleID] || !d.within(e.CreatedAt) {
+			continue
+		}
+		d.Comments = append(d.Comments, Activity{
+			Actor:   e.Actor,
+			Message: e.Message,
+			URL:     activityURL(e),
+			At:      e.CreatedAt,
+		})
+	}
+	sort.SliceStable(d.Comments, func(i, j int) bool { return d.Comments[i].At > d.Comments[j].At })
+
+	for _, f := range author.ListFollowers(dataDir, domain) {
+		if d.within(f.FollowedAt) {
+			d.Followers = append(d.Followers, f)
+		}
+	}
+
+	posts, err := metadata.GetPostEntries(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range posts {
+		if !d.within(p.Published) {
+			continue
+		}
+		url := "/" + strings.TrimSuffix(p.Path, ".md") + ".html"
+		if opts.BaseURL != "" {
+			url = publish.PageURL(opts.BaseURL, p.Path)
+		}
+		d.Posts = append(d.Posts, Post{Title: p.Title, URL: url, Published: p.Published})
+	}
+	sort.SliceStable(d.Posts, func(i, j int) bool { return d.Posts[i].Published > d.Posts[j].Published })
+
+	return d, nil
+}
+
+// within reports whether the timestamp falls in the digest's period.
+func (d *Digest) within(ts string) bool {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return false
+	}
+	return !t.Before(d.Since) && !t.After(d.Until)
+}
+
+// activityURL picks the comment's own URL from a notification, falling back
+// to its link when that points off the site's admin UI.
+func activityURL(e notification.StateEntry) string {
+	for _, key := range []string{"source_url", "comment_url"} {
+		if u, _ := e.Payload[key].(string); strings.HasPrefix(u, "http") {
+			return u
+		}
+	}
+	if strings.HasPrefix(e.Link, "http") {
+		return e.Link
+	}
+	return ""
+}
+
+// Empty reports whether nothing happened in the period.
+func (d *Digest) Empty() bool {
+	return d.FeedTotal == 0 && len(d.Comments) == 0 && len(d.Followers) == 0 && len(d.Posts) == 0
+}
+
+// Title names the digest by its period, e.g. "Digest: Oct 11 – Oct 18, 2026".
+func (d *Digest) Title() string {
+	since, until := d.Since.Local(), d.Until.Local()
+	if since.Year() != until.Year() {
+		return "Digest: " + since.Format("Jan 2, 2006") + " – " + until.Format("Jan 2, 2006")
+	}
+	return "Digest: " + since.Format("Jan 2") + " – " + until.Format("Jan 2, 2006")
+}
+
+// Markdown renders the digest as a post: frontmatter with the title, then a
+// section for each kind of activity.
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: %q\n---\n\n", d.Title())
+	b.WriteString(d.Body())
+	return b.String()
+}
+
+// Body renders the digest without frontmatter, for email.
+func (d *Digest) Body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title())
+	if d.Empty() {
+		b.WriteString("A quiet period: no new posts, comments, or followers.\n")
+		return b.String()
+	}
+
+	if len(d.Highlights) > 0 {
+		b.WriteString("## From my network\n\n")
+		for _, item := range d.Highlights {
+			fmt.Fprintf(&b, "- [%s](%s) by %s\n", linkText(item.Title, item.URL), item.URL, item.AuthorDomain)
+		}
+		if more := d.FeedTotal - len(d.Highlights); more > 0 {
+			fmt.Fprintf(&b, "\nAnd %d more in the feed.\n", more)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(d.Comments) > 0 {
+		b.WriteString("## Comments received\n\n")
+		for _, c := range d.Comments {
+			if c.URL != "" {
+				fmt.Fprintf(&b, "- [%s](%s)\n", c.Message, c.URL)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", c.Message)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(d.Followers) > 0 {
+		b.WriteString("## New followers\n\n")
+		for _, f := range d.Followers {
+			name := f.Domain
+			if f.SiteTitle != "" {
+				name = f.SiteTitle + " (" + f.Domain + ")"
+			}
+			fmt.Fprintf(&b, "- [%s](%s)\n", name, f.URL)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(d.Posts) > 0 {
+		b.WriteString("## My posts\n\n")
+		for _, p := range d.Posts {
+			fmt.Fprintf(&b, "- [%s](%s)\n", linkText(p.Title, p.URL), p.URL)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// linkText is a title for a link, or the URL when the title is empty.
+func linkText(title, url string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	return url
+}
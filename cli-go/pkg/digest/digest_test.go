@@ -0,0 +1,100 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+func TestParseSince(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"90m": 90 * time.Minute,
+	} {
+		if got, err := ParseSince(in); err != nil || got != want {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "7", "0d", "-1d", "7y"} {
+		if _, err := ParseSince(in); err == nil {
+			t.Errorf("ParseSince(%q) succeeded", in)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+	recent := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	old := now.Add(-30 * 24 * time.Hour).Format(time.RFC3339)
+
+	if _, err := feed.NewCacheManager(dir, "ds.test").MergeItems([]feed.FeedItem{
+		{Type: "post", Title: "Fresh", URL: "https://alice.test/posts/fresh.html", Published: recent, AuthorURL: "https://alice.test", AuthorDomain: "alice.test"},
+		{Type: "post", Title: "Stale", URL: "https://alice.test/posts/stale.html", Published: old, AuthorURL: "https://alice.test", AuthorDomain: "alice.test"},
+		{Type: "comment", Title: "Re", URL: "https://bob.test/comments/re.html", Published: recent, AuthorURL: "https://bob.test", AuthorDomain: "bob.test"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notification.NewManager(dir, "ds.test").Append([]notification.StateEntry{
+		{ID: "c1", RuleID: "new-comment", Actor: "bob.test", Message: "bob.test commented on hello", Link: "/_/#blessings",
+			Payload: map[string]interface{}{"source_url": "https://bob.test/comments/re.html"}, EventIDs: []int{1}, CreatedAt: recent},
+		{ID: "p1", RuleID: "new-post", Actor: "alice.test", Message: "alice.test published a new post", EventIDs: []int{2}, CreatedAt: recent},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.NewStore(dir, "ds.test").SaveState("polis.follow", stream.FollowerState{
+		Followers: []string{"carol.test", "dave.test"},
+		Count:     2,
+		Since:     map[string]string{"carol.test": recent, "dave.test": old},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := metadata.AppendPostToIndex(dir, "posts/20260101/hello.md", "Hello", recent, "sha256:x"); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Build(dir, Options{Since: now.Add(-7 * 24 * time.Hour), Until: now, DiscoveryDomain: "ds.test", BaseURL: "https://me.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Highlights) != 1 || d.Highlights[0].Title != "Fresh" {
+		t.Errorf("Highlights = %+v", d.Highlights)
+	}
+	if len(d.Comments) != 1 || d.Comments[0].URL != "https://bob.test/comments/re.html" {
+		t.Errorf("Comments = %+v", d.Comments)
+	}
+	if len(d.Followers) != 1 || d.Followers[0].Domain != "carol.test" {
+		t.Errorf("Followers = %+v", d.Followers)
+	}
+	if len(d.Posts) != 1 || d.Posts[0].URL != "https://me.test/posts/20260101/hello.html" {
+		t.Errorf("Posts = %+v", d.Posts)
+	}
+
+	md := d.Markdown()
+	for _, want := range []string{
+		"title: \"Digest: ",
+		"## From my network\n\n- [Fresh](https://alice.test/posts/fresh.html) by alice.test\n",
+		"## Comments received\n\n- [bob.test commented on hello](https://bob.test/comments/re.html)\n",
+		"## New followers\n\n- [carol.test](https://carol.test)\n",
+		"## My posts\n\n- [Hello](https://me.test/posts/20260101/hello.html)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() is missing %q:\n%s", want, md)
+		}
+	}
+
+	empty, err := Build(t.TempDir(), Options{Since: now.Add(-time.Hour), Until: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !empty.Empty() || !strings.Contains(empty.Body(), "A quiet period") {
+		t.Errorf("digest of a new site =\n%s", empty.Body())
+	}
+}
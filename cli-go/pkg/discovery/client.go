@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
 
@@ -27,11 +28,9 @@ type Client struct {
 // NewClient creates a new discovery service client (unauthenticated GET requests).
 func NewClient(baseURL, apiKey string) *Client {
 	return &Client{
-		BaseURL: baseURL,
-		APIKey:  apiKey,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		HTTPClient: safehttp.NewClient(safehttp.Options{Timeout: 30 * time.Second}),
 	}
 }
 
@@ -43,9 +42,7 @@ func NewAuthenticatedClient(baseURL, apiKey, domain string, privateKeyPEM []byte
 		APIKey:        apiKey,
 		Domain:        domain,
 		PrivateKeyPEM: privateKeyPEM,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		HTTPClient:    safehttp.NewClient(safehttp.Options{Timeout: 30 * time.Second}),
 	}
 }
 
@@ -0,0 +1,165 @@
+// Package dnskey cross-checks a site's public key against DNS.
+//
+// A site may publish its key as a TXT record at _polis.<domain>, in the
+// form "polis-key=ssh-ed25519 AAAA...". DNS is usually controlled
+// separately from the web host, so when the record exists, a key served in
+// .well-known/polis that doesn't match it means the host (or the path to
+// it) has been tampered with. Sites without the record are unaffected.
+package dnskey
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix starts the TXT record that carries a key.
+const Prefix = "polis-key="
+
+// Label is prepended to the site's domain to form the record name. It is
+// shared with identity proofs, which use a different prefix.
+const Label = "_polis."
+
+// Key check outcomes.
+const (
+	StatusMatch    = "match"    // DNS publishes this key
+	StatusMismatch = "mismatch" // DNS publishes keys, none of them this one
+	StatusAbsent   = "absent"   // No key in DNS; nothing to check against
+	StatusError    = "error"    // The lookup failed
+)
+
+// ErrMismatch is returned when a site's key doesn't match the key its
+// domain publishes in DNS.
+var ErrMismatch = errors.New("public key does not match the key published in DNS")
+
+// CacheTTL is how long a lookup is reused within a process.
+const CacheTTL = 10 * time.Minute
+
+// Result is the outcome of checking a key against DNS.
+type Result struct {
+	Status  string   `json:"status"`
+	Keys    []string `json:"keys,omitempty"` // Keys found in DNS
+	Message string   `json:"message,omitempty"`
+}
+
+// Mismatch reports whether DNS contradicts the key.
+func (r Result) Mismatch() bool {
+	return r.Status == StatusMismatch
+}
+
+// lookupTXT resolves TXT records; replaced in tests.
+var lookupTXT = net.LookupTXT
+
+type cached struct {
+	keys    []string
+	err     error
+	expires time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]cached)
+)
+
+// Name returns the DNS name a domain's key is published at.
+func Name(domain string) string {
+	return Label + domain
+}
+
+// Record returns the TXT record value that publishes publicKey.
+func Record(publicKey string) string {
+	return Prefix + normalize(publicKey)
+}
+
+// Check compares publicKey with the keys domain publishes in DNS. Domains
+// that are IP addresses or localhost aren't checked.
+func Check(domain, publicKey string) Result {
+	domain = hostOnly(domain)
+	if domain == "" || domain == "localhost" || net.ParseIP(domain) != nil {
+		return Result{Status: StatusAbsent}
+	}
+	keys, err := Lookup(domain)
+	if err != nil {
+		return Result{Status: StatusError, Message: err.Error()}
+	}
+	if len(keys) == 0 {
+		return Result{Status: StatusAbsent}
+	}
+	want := normalize(publicKey)
+	for _, k := range keys {
+		if k == want {
+			return Result{Status: StatusMatch, Keys: keys}
+		}
+	}
+	return Result{
+		Status:  StatusMismatch,
+		Keys:    keys,
+		Message: fmt.Sprintf("%s publishes a different key in DNS (%s)", domain, Name(domain)),
+	}
+}
+
+// Verify returns ErrMismatch when DNS contradicts publicKey. Lookup
+// failures are not errors, so sites stay reachable when DNS is flaky.
+func Verify(domain, publicKey string) error {
+	if r := Check(domain, publicKey); r.Mismatch() {
+		return fmt.Errorf("%w (%s)", ErrMismatch, Name(hostOnly(domain)))
+	}
+	return nil
+}
+
+// Lookup returns the keys a domain publishes in DNS. A missing record is
+// not an error.
+func Lookup(domain string) ([]string, error) {
+	domain = hostOnly(domain)
+	cacheMu.Lock()
+	c, ok := cache[domain]
+	cacheMu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.keys, c.err
+	}
+
+	records, err := lookupTXT(Name(domain))
+	var keys []string
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("DNS lookup of %s failed: %w", Name(domain), err)
+	}
+	for _, r := range records {
+		if strings.HasPrefix(r, Prefix) {
+			keys = append(keys, normalize(strings.TrimPrefix(r, Prefix)))
+		}
+	}
+
+	cacheMu.Lock()
+	cache[domain] = cached{keys: keys, err: err, expires: time.Now().Add(CacheTTL)}
+	cacheMu.Unlock()
+	return keys, err
+}
+
+// normalize reduces an OpenSSH public key to its type and key data,
+// dropping the comment.
+func normalize(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) >= 2 {
+		return fields[0] + " " + fields[1]
+	}
+	return strings.TrimSpace(key)
+}
+
+// hostOnly strips a scheme, path, and port from domain.
+func hostOnly(domain string) string {
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	if i := strings.Index(domain, "/"); i >= 0 {
+		domain = domain[:i]
+	}
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
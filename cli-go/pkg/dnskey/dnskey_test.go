@@ -0,0 +1,84 @@
+package dnskey
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+const (
+	key      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIL4+6dVdmC5X/L3gJXLGpV/9lRfhlYfUdttv8R2BiEt3 polis-local"
+	otherKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICkNWqLwj5QWyKffetUkBULEC+Syvoc0LW0ZSHhj2fSS"
+)
+
+// stub answers TXT lookups from records for the duration of a test.
+func stub(t *testing.T, records map[string][]string) {
+	t.Helper()
+	old := lookupTXT
+	lookupTXT = func(name string) ([]string, error) {
+		r, ok := records[name]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return r, nil
+	}
+	cache = make(map[string]cached)
+	t.Cleanup(func() {
+		lookupTXT = old
+		cache = make(map[string]cached)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	stub(t, map[string][]string{
+		"_polis.alice.example.com": {"polis-proof=abc", Record(key)},
+		"_polis.bob.example.com":   {Record(otherKey)},
+		"_polis.carol.example.com": {"polis-proof=abc"},
+	})
+
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{"alice.example.com", StatusMatch},
+		{"https://alice.example.com/posts/hello.md", StatusMatch},
+		{"bob.example.com", StatusMismatch},
+		{"carol.example.com", StatusAbsent},
+		{"dave.example.com", StatusAbsent},
+		{"127.0.0.1:8080", StatusAbsent},
+	}
+	for _, tt := range tests {
+		if got := Check(tt.domain, key); got.Status != tt.want {
+			t.Errorf("Check(%q) = %s (%s), want %s", tt.domain, got.Status, got.Message, tt.want)
+		}
+	}
+
+	if err := Verify("bob.example.com", key); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify with a swapped key = %v, want ErrMismatch", err)
+	}
+	if err := Verify("dave.example.com", key); err != nil {
+		t.Errorf("Verify without a record = %v, want nil", err)
+	}
+}
+
+func TestCheck_LookupError(t *testing.T) {
+	stub(t, nil)
+	lookupTXT = func(name string) ([]string, error) {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+	}
+	r := Check("alice.example.com", key)
+	if r.Status != StatusError {
+		t.Errorf("status = %s, want %s", r.Status, StatusError)
+	}
+	// A failed lookup doesn't block the key
+	if err := Verify("alice.example.com", key); err != nil {
+		t.Errorf("Verify = %v, want nil", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	want := "polis-key=ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIL4+6dVdmC5X/L3gJXLGpV/9lRfhlYfUdttv8R2BiEt3"
+	if got := Record(key); got != want {
+		t.Errorf("Record = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+// Package explore finds posts by authors one doesn't follow, in the
+// discovery service's public stream. Posts are listed newest first, or
+// trending: most commented on by others first.
+//
+// Pages step back through the stream. A page's Next cursor is passed as
+// Before to get the page after it; newest pages continue where the last
+// one stopped, and trending pages each rank the posts of an older stretch
+// of the stream.
+package explore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+)
+
+// Sort orders.
+const (
+	SortNewest   = "newest"
+	SortTrending = "trending"
+)
+
+// DefaultLimit is the number of posts in a page.
+const DefaultLimit = 20
+
+const (
+	// window is the number of stream events read per query.
+	window = 1000
+
+	// maxWindows caps the queries made for one page, so a stream that is
+	// mostly followed authors doesn't take the whole history to fill a page.
+	maxWindows = 5
+
+	eventTypes = "polis.post.published,polis.comment.published"
+)
+
+// ErrInvalidOption is returned for an unknown sort or a malformed cursor.
+var ErrInvalidOption = errors.New("invalid option")
+
+// Options selects a page of posts.
+type Options struct {
+	Sort    string   // SortNewest (default) or SortTrending
+	Before  string   // A previous page's Next; empty for the latest posts
+	Limit   int      // Posts per page; DefaultLimit if 0
+	Exclude []string // Domains left out: one's own and those followed
+
+	// Filters drops posts with muted keywords in their titles.
+	Filters feed.Filters
+}
+
+// Post is a post found in the stream.
+type Post struct {
+	URL          string `json:"url"`
+	Title        string `json:"title"`
+	Published    string `json:"published"`
+	AuthorDomain string `json:"author_domain"`
+	AuthorURL    string `json:"author_url"`
+	Comments     int    `json:"comments"` // Comments by others in the stretch read
+
+	cursor int64
+}
+
+// Page is one page of posts.
+type Page struct {
+	Sort  string `json:"sort"`
+	Posts []Post `json:"posts"`
+	Next  string `json:"next,omitempty"` // Empty on the last page
+}
+
+// Query returns a page of recent public posts by authors not excluded.
+func Query(client *discovery.Client, opts Options) (*Page, error) {
+	switch opts.Sort {
+	case "":
+		opts.Sort = SortNewest
+	case SortNewest, SortTrending:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q (expected %s or %s)", ErrInvalidOption, opts.Sort, SortNewest, SortTrending)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+
+	health, err := client.StreamHealth()
+	if err != nil {
+		return nil, err
+	}
+	oldest, _ := strconv.ParseInt(health.OldestCursor, 10, 64)
+	end, err := strconv.ParseInt(health.LatestCursor, 10, 64)
+	if err != nil {
+		return &Page{Sort: opts.Sort, Posts: []Post{}}, nil // An empty stream
+	}
+	end++ // Events before end are read
+	if opts.Before != "" {
+		before, err := strconv.ParseInt(opts.Before, 10, 64)
+		if err != nil || before < 0 {
+			return nil, fmt.Errorf("%w: bad cursor %q", ErrInvalidOption, opts.Before)
+		}
+		end = min(end, before)
+	}
+
+	excluded := make(map[string]bool, len(opts.Exclude))
+	for _, d := range opts.Exclude {
+		excluded[strings.ToLower(d)] = true
+	}
+
+	// Each query reads the events after since, stepping back a window at
+	// a time until the page is full or the stream's start is reached
+	floor := max(oldest-1, 0)
+	posts := map[string]*Post{}
+	comments := map[string]map[string]bool{} // Post URL -> commenting domains
+	since := end - 1
+	for i := 0; i < maxWindows && since > floor && len(posts) <= opts.Limit; i++ {
+		since = max(since-window, floor)
+		resp, err := client.StreamQuery(strconv.FormatInt(since, 10), window, eventTypes, "", "")
+		if err != nil {
+			return nil, err
+		}
+		for _, evt := range resp.Events {
+			id, err := evt.ID.Int64()
+			if err != nil || id >= end {
+				continue
+			}
+			items := (&feed.FeedHandler{}).Process([]discovery.StreamEvent{evt})
+			if len(items) == 0 {
+				continue
+			}
+			item := items[0]
+			switch item.Type {
+			case "comment":
+				if item.TargetURL == "" {
+					continue
+				}
+				if comments[item.TargetURL] == nil {
+					comments[item.TargetURL] = map[string]bool{}
+				}
+				comments[item.TargetURL][item.AuthorDomain] = true
+			case "post":
+				if item.URL == "" || excluded[strings.ToLower(item.AuthorDomain)] || opts.Filters.MutesTitle(item.Title) {
+					continue
+				}
+				if p, ok := posts[item.URL]; ok && p.cursor > id {
+					continue
+				}
+				posts[item.URL] = &Post{
+					URL:          item.URL,
+					Title:        item.Title,
+					Published:    item.Published,
+					AuthorDomain: item.AuthorDomain,
+					AuthorURL:    item.AuthorURL,
+					cursor:       id,
+				}
+			}
+		}
+	}
+
+	page := &Page{Sort: opts.Sort, Posts: []Post{}}
+	for url, p := range posts {
+		for domain := range comments[url] {
+			if domain != p.AuthorDomain {
+				p.Comments++
+			}
+		}
+		page.Posts = append(page.Posts, *p)
+	}
+	sort.Slice(page.Posts, func(i, j int) bool {
+		a, b := page.Posts[i], page.Posts[j]
+		if opts.Sort == SortTrending && a.Comments != b.Comments {
+			return a.Comments > b.Comments
+		}
+		return a.cursor > b.cursor
+	})
+
+	if len(page.Posts) > opts.Limit {
+		page.Posts = page.Posts[:opts.Limit]
+		if opts.Sort == SortNewest {
+			// The next page starts after the last post shown
+			page.Next = strconv.FormatInt(page.Posts[opts.Limit-1].cursor, 10)
+			return page, nil
+		}
+	}
+	if since > floor {
+		page.Next = strconv.FormatInt(since+1, 10)
+	}
+	return page, nil
+}
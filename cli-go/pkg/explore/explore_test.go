@@ -0,0 +1,143 @@
+package explore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+)
+
+// fakeStream serves events with IDs 1..len(events) the way the discovery
+// service does: those after since, oldest first, up to limit.
+func fakeStream(t *testing.T, events []discovery.StreamEvent) *discovery.Client {
+	t.Helper()
+	for i := range events {
+		events[i].ID = json.Number(strconv.Itoa(i + 1))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ds-stream-health", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery.StreamHealthResponse{
+			Status:       "ok",
+			LatestCursor: strconv.Itoa(len(events)),
+			OldestCursor: "1",
+			EventCount:   len(events),
+		})
+	})
+	mux.HandleFunc("/ds-stream", func(w http.ResponseWriter, r *http.Request) {
+		since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		resp := discovery.StreamQueryResponse{Events: []discovery.StreamEvent{}}
+		for _, e := range events[min(since, len(events)):] {
+			if len(resp.Events) == limit {
+				resp.HasMore = true
+				break
+			}
+			resp.Events = append(resp.Events, e)
+			resp.Cursor = string(e.ID)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return discovery.NewClient(srv.URL, "key")
+}
+
+func post(actor, slug, title string) discovery.StreamEvent {
+	return discovery.StreamEvent{
+		Type:    "polis.post.published",
+		Actor:   actor,
+		Payload: map[string]interface{}{"url": "https://" + actor + "/posts/" + slug + ".md", "title": title},
+	}
+}
+
+func comment(actor, target string) discovery.StreamEvent {
+	return discovery.StreamEvent{
+		Type:    "polis.comment.published",
+		Actor:   actor,
+		Payload: map[string]interface{}{"url": "https://" + actor + "/comments/c.md", "in_reply_to": target},
+	}
+}
+
+func titles(p *Page) []string {
+	var out []string
+	for _, post := range p.Posts {
+		out = append(out, post.Title)
+	}
+	return out
+}
+
+func TestQuery_Newest(t *testing.T) {
+	client := fakeStream(t, []discovery.StreamEvent{
+		post("a.com", "1", "First"),
+		post("me.com", "2", "Mine"),
+		post("b.com", "3", "Second"),
+		post("friend.com", "4", "Followed"),
+		post("c.com", "5", "Spoilers ahead"),
+		post("c.com", "6", "Third"),
+	})
+	opts := Options{
+		Limit:   2,
+		Exclude: []string{"me.com", "Friend.com"},
+		Filters: feed.Filters{MutedKeywords: []string{"spoilers"}},
+	}
+
+	page, err := Query(client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(titles(page)); got != "[Third Second]" || page.Next != "3" {
+		t.Fatalf("first page = %s, next %q", got, page.Next)
+	}
+	if page.Posts[0].AuthorURL != "https://c.com" {
+		t.Errorf("author URL = %q", page.Posts[0].AuthorURL)
+	}
+
+	opts.Before = page.Next
+	page, err = Query(client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(titles(page)); got != "[First]" || page.Next != "" {
+		t.Errorf("second page = %s, next %q", got, page.Next)
+	}
+}
+
+func TestQuery_Trending(t *testing.T) {
+	client := fakeStream(t, []discovery.StreamEvent{
+		post("a.com", "1", "Quiet"),
+		post("b.com", "2", "Busy"),
+		comment("x.com", "https://b.com/posts/2.md"),
+		comment("y.com", "https://b.com/posts/2.md"),
+		comment("y.com", "https://b.com/posts/2.md"),
+		comment("b.com", "https://b.com/posts/2.md"),
+		post("c.com", "3", "Newer"),
+		comment("x.com", "https://c.com/posts/3.md"),
+	})
+
+	page, err := Query(client, Options{Sort: SortTrending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(titles(page)); got != "[Busy Newer Quiet]" {
+		t.Fatalf("trending = %s", got)
+	}
+	if page.Posts[0].Comments != 2 {
+		t.Errorf("comments = %d, want 2 (one per other domain)", page.Posts[0].Comments)
+	}
+}
+
+func TestQuery_BadOptions(t *testing.T) {
+	client := fakeStream(t, nil)
+	if _, err := Query(client, Options{Sort: "oldest"}); !errors.Is(err, ErrInvalidOption) {
+		t.Error("expected an error for an unknown sort")
+	}
+	if _, err := Query(client, Options{Before: "abc"}); !errors.Is(err, ErrInvalidOption) {
+		t.Error("expected an error for a bad cursor")
+	}
+}
@@ -0,0 +1,9 @@
+package explore
+
+import (
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp/safehttptest"
+)
+
+func TestMain(m *testing.M) { safehttptest.Main(m) }
@@ -1,18 +1,23 @@
 package feed
 
 import (
-	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
 )
 
+// ErrItemNotFound is returned when a feed item ID isn't in the cache.
+var ErrItemNotFound = errors.New("item not found")
+
 // Version is set at init time by cmd package.
 var Version = "dev"
 
@@ -42,6 +47,7 @@ type FeedConfig struct {
 	StalenessMinutes int `json:"staleness_minutes"`
 	MaxItems         int `json:"max_items"`
 	MaxAgeDays       int `json:"max_age_days"`
+	Filters
 }
 
 // DefaultFeedConfig returns the default feed configuration.
@@ -55,8 +61,9 @@ func DefaultFeedConfig() FeedConfig {
 
 // CacheManager handles feed cache operations.
 type CacheManager struct {
-	cacheFile  string        // state/polis.feed.jsonl
-	configFile string        // config/feed.json
+	fsys       storage.FS    // the site's storage
+	cacheFile  string        // state/polis.feed.jsonl, as a storage name
+	configFile string        // config/feed.json, as a storage name
 	store      *stream.Store // for cursor operations
 }
 
@@ -72,9 +79,11 @@ func ConfigFile(dataDir, discoveryDomain string) string {
 
 // NewCacheManager creates a new feed cache manager scoped to a discovery service domain.
 func NewCacheManager(dataDir, discoveryDomain string) *CacheManager {
+	dsDir := storage.Join(".polis/ds", discoveryDomain)
 	return &CacheManager{
-		cacheFile:  CacheFile(dataDir, discoveryDomain),
-		configFile: ConfigFile(dataDir, discoveryDomain),
+		fsys:       storage.For(dataDir),
+		cacheFile:  dsDir + "/state/polis.feed.jsonl",
+		configFile: dsDir + "/config/feed.json",
 		store:      stream.NewStore(dataDir, discoveryDomain),
 	}
 }
@@ -88,22 +97,16 @@ func ComputeItemID(authorURL, path string) string {
 
 // List returns all cached feed items, sorted by published descending.
 func (cm *CacheManager) List() ([]CachedFeedItem, error) {
-	file, err := os.Open(cm.cacheFile)
+	lines, err := storage.ReadJSONLines(cm.fsys, cm.cacheFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []CachedFeedItem{}, nil
 		}
-		return nil, fmt.Errorf("failed to open cache file: %w", err)
+		return nil, fmt.Errorf("failed to read cache: %w", err)
 	}
-	defer file.Close()
 
 	var items []CachedFeedItem
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
+	for _, line := range lines {
 		var item CachedFeedItem
 		if err := json.Unmarshal(line, &item); err != nil {
 			continue // Skip malformed lines
@@ -111,10 +114,6 @@ func (cm *CacheManager) List() ([]CachedFeedItem, error) {
 		items = append(items, item)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read cache: %w", err)
-	}
-
 	return items, nil
 }
 
@@ -199,7 +198,7 @@ func (cm *CacheManager) SetCursor(cursor string) error {
 
 // LoadConfig loads the feed configuration, returning defaults if not found.
 func (cm *CacheManager) LoadConfig() (*FeedConfig, error) {
-	data, err := os.ReadFile(cm.configFile)
+	data, err := cm.fsys.ReadFile(cm.configFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			cfg := DefaultFeedConfig()
@@ -218,10 +217,6 @@ func (cm *CacheManager) LoadConfig() (*FeedConfig, error) {
 
 // SaveConfig writes the feed configuration to disk.
 func (cm *CacheManager) SaveConfig(cfg *FeedConfig) error {
-	if err := os.MkdirAll(filepath.Dir(cm.configFile), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
 	// Ensure defaults
 	if cfg.StalenessMinutes <= 0 {
 		cfg.StalenessMinutes = 15
@@ -238,7 +233,7 @@ func (cm *CacheManager) SaveConfig(cfg *FeedConfig) error {
 		return fmt.Errorf("failed to marshal feed config: %w", err)
 	}
 
-	return os.WriteFile(cm.configFile, append(data, '\n'), 0644)
+	return cm.fsys.WriteFile(cm.configFile, append(data, '\n'), 0644)
 }
 
 // IsStale returns true if the cache needs refreshing based on staleness_minutes.
@@ -279,13 +274,22 @@ func (cm *CacheManager) LastUpdated() string {
 	return entry.LastUpdated
 }
 
-// MergeItems integrates new FeedItems into the cache. Returns the number of new items added.
+// MergeItems integrates new FeedItems into the cache, dropping items muted
+// by the feed filters. Returns the number of new items added.
 func (cm *CacheManager) MergeItems(items []FeedItem) (int, error) {
+	unlock, err := cm.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
 	existing, err := cm.List()
 	if err != nil {
 		return 0, err
 	}
 
+	cfg, _ := cm.LoadConfig()
+
 	// Build ID map of existing items
 	idMap := make(map[string]struct{}, len(existing))
 	for _, item := range existing {
@@ -300,6 +304,9 @@ func (cm *CacheManager) MergeItems(items []FeedItem) (int, error) {
 		if _, exists := idMap[id]; exists {
 			continue
 		}
+		if cfg != nil && cfg.Filters.Mutes(item) {
+			continue
+		}
 		existing = append(existing, CachedFeedItem{
 			ID:           id,
 			Type:         item.Type,
@@ -327,13 +334,117 @@ func (cm *CacheManager) MergeItems(items []FeedItem) (int, error) {
 	}
 
 	// Prune after merge
-	cm.Prune()
+	cm.prune()
 
 	return newCount, nil
 }
 
+// UnreadURLs returns the URLs of unread items, newest first. These are the
+// items worth prefetching.
+func (cm *CacheManager) UnreadURLs() []string {
+	items, err := cm.List()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, item := range items {
+		if item.ReadAt == "" && item.URL != "" {
+			urls = append(urls, item.URL)
+		}
+	}
+	return urls
+}
+
+// NextOptions selects the next unread item for keyboard triage.
+type NextOptions struct {
+	Type  string // "post", "comment", or "" (all)
+	After string // Item ID to continue after (in feed order); "" starts at the top
+}
+
+// NextUnread returns the first unread item after opts.After in feed order,
+// wrapping to the top if nothing unread follows it. Returns nil when the feed
+// has no unread items. remaining counts unread items matching opts.Type,
+// including the returned one.
+func (cm *CacheManager) NextUnread(opts NextOptions) (item *CachedFeedItem, remaining int, err error) {
+	items, err := cm.List()
+	if err != nil {
+		return nil, 0, err
+	}
+	item, remaining = nextUnread(items, opts)
+	return item, remaining, nil
+}
+
+// MarkReadAndNext marks id as read and returns the next unread item after it,
+// as a single atomic step.
+func (cm *CacheManager) MarkReadAndNext(id string, opts NextOptions) (*CachedFeedItem, int, error) {
+	unlock, err := cm.lock()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer unlock()
+
+	items, err := cm.List()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	found := false
+	for i := range items {
+		if items[i].ID == id {
+			if items[i].ReadAt == "" {
+				items[i].ReadAt = time.Now().UTC().Format(time.RFC3339)
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, 0, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	if err := cm.writeAll(items); err != nil {
+		return nil, 0, err
+	}
+
+	opts.After = id
+	next, remaining := nextUnread(items, opts)
+	return next, remaining, nil
+}
+
+func nextUnread(items []CachedFeedItem, opts NextOptions) (*CachedFeedItem, int) {
+	start := 0
+	if opts.After != "" {
+		for i := range items {
+			if items[i].ID == opts.After {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var next *CachedFeedItem
+	remaining := 0
+	for n := 0; n < len(items); n++ {
+		i := (start + n) % len(items)
+		if items[i].ReadAt != "" || (opts.Type != "" && items[i].Type != opts.Type) {
+			continue
+		}
+		remaining++
+		if next == nil {
+			found := items[i]
+			next = &found
+		}
+	}
+	return next, remaining
+}
+
 // MarkRead marks a single item as read.
 func (cm *CacheManager) MarkRead(id string) error {
+	unlock, err := cm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	items, err := cm.List()
 	if err != nil {
 		return err
@@ -350,7 +461,7 @@ func (cm *CacheManager) MarkRead(id string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("item not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrItemNotFound, id)
 	}
 
 	return cm.writeAll(items)
@@ -358,6 +469,12 @@ func (cm *CacheManager) MarkRead(id string) error {
 
 // MarkUnread marks a single item as unread.
 func (cm *CacheManager) MarkUnread(id string) error {
+	unlock, err := cm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	items, err := cm.List()
 	if err != nil {
 		return err
@@ -373,7 +490,7 @@ func (cm *CacheManager) MarkUnread(id string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("item not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrItemNotFound, id)
 	}
 
 	return cm.writeAll(items)
@@ -381,6 +498,12 @@ func (cm *CacheManager) MarkUnread(id string) error {
 
 // MarkAllRead marks all items as read.
 func (cm *CacheManager) MarkAllRead() error {
+	unlock, err := cm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	items, err := cm.List()
 	if err != nil {
 		return err
@@ -398,6 +521,12 @@ func (cm *CacheManager) MarkAllRead() error {
 
 // MarkUnreadFrom marks the item with the given ID and all more recent items (by published date) as unread.
 func (cm *CacheManager) MarkUnreadFrom(id string) error {
+	unlock, err := cm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	items, err := cm.List()
 	if err != nil {
 		return err
@@ -413,7 +542,7 @@ func (cm *CacheManager) MarkUnreadFrom(id string) error {
 	}
 
 	if targetPublished == "" {
-		return fmt.Errorf("item not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrItemNotFound, id)
 	}
 
 	// Mark the target and all items with same or newer published date as unread
@@ -428,6 +557,16 @@ func (cm *CacheManager) MarkUnreadFrom(id string) error {
 
 // Prune enforces MaxItems and MaxAgeDays limits. Returns the number of items removed.
 func (cm *CacheManager) Prune() (int, error) {
+	unlock, err := cm.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	return cm.prune()
+}
+
+func (cm *CacheManager) prune() (int, error) {
 	items, err := cm.List()
 	if err != nil {
 		return 0, err
@@ -485,25 +624,27 @@ func (cm *CacheManager) SetStalenessMinutes(minutes int) error {
 	return cm.SaveConfig(cfg)
 }
 
+// lock takes the cache file's lock. Every read-modify-write of the cache
+// holds it, so the CLI and a running serve process can't lose each other's
+// updates.
+func (cm *CacheManager) lock() (unlock func(), err error) {
+	return cm.fsys.Lock(cm.cacheFile)
+}
+
 // writeAll rewrites all items to the cache file.
 func (cm *CacheManager) writeAll(items []CachedFeedItem) error {
-	if err := os.MkdirAll(filepath.Dir(cm.cacheFile), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	file, err := os.Create(cm.cacheFile)
-	if err != nil {
-		return fmt.Errorf("failed to create cache file: %w", err)
-	}
-	defer file.Close()
-
+	var buf bytes.Buffer
 	for _, item := range items {
 		data, err := json.Marshal(item)
 		if err != nil {
 			continue
 		}
-		file.WriteString(string(data) + "\n")
+		buf.Write(data)
+		buf.WriteByte('\n')
 	}
 
+	if err := cm.fsys.WriteFile(cm.cacheFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
 	return nil
 }
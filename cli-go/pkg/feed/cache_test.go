@@ -2,6 +2,7 @@ package feed
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -532,17 +533,17 @@ func TestCacheManager_MarkReadNotFound(t *testing.T) {
 	cm := NewCacheManager(t.TempDir(), testDiscoveryDomain)
 
 	err := cm.MarkRead("nonexistent")
-	if err == nil {
+	if !errors.Is(err, ErrItemNotFound) {
 		t.Error("expected error for nonexistent ID")
 	}
 
 	err = cm.MarkUnread("nonexistent")
-	if err == nil {
+	if !errors.Is(err, ErrItemNotFound) {
 		t.Error("expected error for nonexistent ID")
 	}
 
 	err = cm.MarkUnreadFrom("nonexistent")
-	if err == nil {
+	if !errors.Is(err, ErrItemNotFound) {
 		t.Error("expected error for nonexistent ID")
 	}
 }
@@ -596,3 +597,113 @@ func TestCacheManager_ConfigRoundTrip(t *testing.T) {
 		t.Error("config file should exist at config/feed.json")
 	}
 }
+
+func TestCacheManager_NextUnread(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), testDiscoveryDomain)
+	now := time.Now().UTC()
+	at := func(h int) string { return now.Add(-time.Duration(h) * time.Hour).Format(time.RFC3339) }
+
+	cm.MergeItems([]FeedItem{
+		{Type: "post", Title: "Post A", URL: "posts/a.md", Published: at(3), AuthorURL: "https://alice.polis.pub", AuthorDomain: "alice.polis.pub"},
+		{Type: "comment", Title: "Comment B", URL: "comments/b.md", Published: at(2), AuthorURL: "https://bob.polis.pub", AuthorDomain: "bob.polis.pub"},
+		{Type: "post", Title: "Post C", URL: "posts/c.md", Published: at(1), AuthorURL: "https://alice.polis.pub", AuthorDomain: "alice.polis.pub"},
+	})
+
+	// Feed order is newest first: C, B, A
+	next, remaining, err := cm.NextUnread(NextOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.Title != "Post C" || remaining != 3 {
+		t.Fatalf("expected Post C with 3 remaining, got %+v (%d)", next, remaining)
+	}
+
+	next, remaining, _ = cm.NextUnread(NextOptions{Type: "post", After: next.ID})
+	if next == nil || next.Title != "Post A" || remaining != 2 {
+		t.Errorf("expected Post A with 2 remaining posts, got %+v (%d)", next, remaining)
+	}
+}
+
+func TestCacheManager_MarkReadAndNext(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), testDiscoveryDomain)
+	now := time.Now().UTC()
+	at := func(h int) string { return now.Add(-time.Duration(h) * time.Hour).Format(time.RFC3339) }
+
+	cm.MergeItems([]FeedItem{
+		{Type: "post", Title: "Post A", URL: "posts/a.md", Published: at(2), AuthorURL: "https://alice.polis.pub", AuthorDomain: "alice.polis.pub"},
+		{Type: "post", Title: "Post B", URL: "posts/b.md", Published: at(1), AuthorURL: "https://alice.polis.pub", AuthorDomain: "alice.polis.pub"},
+	})
+	items, _ := cm.List()
+
+	next, remaining, err := cm.MarkReadAndNext(items[0].ID, NextOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.ID != items[1].ID || remaining != 1 {
+		t.Errorf("expected second item with 1 remaining, got %+v (%d)", next, remaining)
+	}
+
+	next, remaining, _ = cm.MarkReadAndNext(items[1].ID, NextOptions{})
+	if next != nil || remaining != 0 {
+		t.Errorf("expected feed exhausted, got %+v (%d)", next, remaining)
+	}
+	if unread, _ := cm.UnreadCount(); unread != 0 {
+		t.Errorf("expected 0 unread, got %d", unread)
+	}
+
+	if _, _, err := cm.MarkReadAndNext("missing", NextOptions{}); !errors.Is(err, ErrItemNotFound) {
+		t.Error("expected error for unknown item")
+	}
+}
+
+func TestCacheManager_MergeItemsMuted(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), testDiscoveryDomain)
+	now := time.Now().UTC()
+	post := "https://alice.polis.pub/posts/noisy.md"
+
+	if _, err := cm.SaveFilters(Filters{MutedThreads: []string{post}, MutedKeywords: []string{" Crypto ", "crypto"}}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := cm.LoadFilters()
+	if len(f.MutedKeywords) != 1 || f.MutedKeywords[0] != "crypto" {
+		t.Errorf("keywords = %v, want normalized [crypto]", f.MutedKeywords)
+	}
+
+	added, err := cm.MergeItems([]FeedItem{
+		{Type: "post", Title: "Noisy", URL: post, Published: now.Format(time.RFC3339), AuthorURL: "https://alice.polis.pub"},
+		{Type: "comment", Title: "Re: Noisy", URL: "https://bob.polis.pub/comments/1.md", TargetURL: post, Published: now.Format(time.RFC3339), AuthorURL: "https://bob.polis.pub"},
+		{Type: "post", Title: "My CRYPTO picks", URL: "https://bob.polis.pub/posts/picks.md", Published: now.Format(time.RFC3339), AuthorURL: "https://bob.polis.pub"},
+		{Type: "post", Title: "Gardening", URL: "https://bob.polis.pub/posts/garden.md", Published: now.Format(time.RFC3339), AuthorURL: "https://bob.polis.pub"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Errorf("added = %d, want 2 (thread comment and keyword post muted)", added)
+	}
+}
+
+func TestCacheManager_MuteThreadMarksRead(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), testDiscoveryDomain)
+	now := time.Now().UTC().Format(time.RFC3339)
+	post := "https://alice.polis.pub/posts/busy.md"
+	cm.MergeItems([]FeedItem{
+		{Type: "comment", Title: "Re", URL: "https://bob.polis.pub/comments/1.md", TargetURL: post, Published: now, AuthorURL: "https://bob.polis.pub"},
+		{Type: "comment", Title: "Re", URL: "https://bob.polis.pub/comments/2.md", TargetURL: "https://alice.polis.pub/posts/other.md", Published: now, AuthorURL: "https://bob.polis.pub"},
+	})
+
+	marked, err := cm.MuteThread(post)
+	if err != nil || marked != 1 {
+		t.Fatalf("MuteThread = %d, %v; want 1 item marked read", marked, err)
+	}
+	if n, _ := cm.UnreadCount(); n != 1 {
+		t.Errorf("unread = %d, want 1", n)
+	}
+
+	if err := cm.UnmuteThread(post); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := cm.LoadFilters(); len(f.MutedThreads) != 0 {
+		t.Errorf("threads = %v, want none after unmute", f.MutedThreads)
+	}
+}
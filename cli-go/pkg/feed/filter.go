@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"strings"
+	"time"
+)
+
+// Filters are the feed's mute lists, stored alongside the rest of the feed
+// configuration in config/feed.json. Muted items are dropped when new
+// items are merged into the cache, so they never count as unread.
+type Filters struct {
+	// MutedThreads are post URLs whose comments are suppressed. A comment
+	// is muted when it replies to a muted URL, so muting a comment's URL
+	// also mutes replies to it.
+	MutedThreads []string `json:"muted_threads,omitempty"`
+	// MutedKeywords suppress posts and comments whose title contains any
+	// of them, case-insensitively. Stored lowercase.
+	MutedKeywords []string `json:"muted_keywords,omitempty"`
+}
+
+// Mutes reports whether item is suppressed by the filters.
+func (f Filters) Mutes(item FeedItem) bool {
+	return f.mutes(item.Type, item.Title, item.TargetURL)
+}
+
+// MutesCached reports whether a cached item is suppressed by the filters.
+func (f Filters) MutesCached(item CachedFeedItem) bool {
+	return f.mutes(item.Type, item.Title, item.TargetURL)
+}
+
+// MutesThread reports whether comments replying to targetURL are muted.
+func (f Filters) MutesThread(targetURL string) bool {
+	if targetURL == "" {
+		return false
+	}
+	for _, u := range f.MutedThreads {
+		if u == targetURL {
+			return true
+		}
+	}
+	return false
+}
+
+// MutesTitle reports whether title contains a muted keyword.
+func (f Filters) MutesTitle(title string) bool {
+	if title == "" {
+		return false
+	}
+	lower := strings.ToLower(title)
+	for _, kw := range f.MutedKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filters) mutes(itemType, title, targetURL string) bool {
+	if itemType == "comment" && f.MutesThread(targetURL) {
+		return true
+	}
+	return f.MutesTitle(title)
+}
+
+// Normalize trims and de-duplicates both lists, drops empty entries, and
+// lowercases keywords.
+func (f *Filters) Normalize() {
+	f.MutedThreads = dedupe(f.MutedThreads, false)
+	f.MutedKeywords = dedupe(f.MutedKeywords, true)
+}
+
+func dedupe(values []string, lower bool) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if lower {
+			v = strings.ToLower(v)
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// LoadFilters returns the feed's mute lists.
+func (cm *CacheManager) LoadFilters() (Filters, error) {
+	cfg, err := cm.LoadConfig()
+	if err != nil {
+		return Filters{}, err
+	}
+	return cfg.Filters, nil
+}
+
+// SaveFilters replaces the feed's mute lists and marks cached unread items
+// they now match as read. Returns the number of items marked read.
+func (cm *CacheManager) SaveFilters(f Filters) (int, error) {
+	cfg, err := cm.LoadConfig()
+	if err != nil {
+		return 0, err
+	}
+	f.Normalize()
+	cfg.Filters = f
+	if err := cm.SaveConfig(cfg); err != nil {
+		return 0, err
+	}
+
+	unlock, err := cm.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	items, err := cm.List()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	marked := 0
+	for i := range items {
+		if items[i].ReadAt == "" && f.MutesCached(items[i]) {
+			items[i].ReadAt = now
+			marked++
+		}
+	}
+	if marked == 0 {
+		return 0, nil
+	}
+	return marked, cm.writeAll(items)
+}
+
+// MuteThread adds url to the muted threads. Returns the number of cached
+// items marked read.
+func (cm *CacheManager) MuteThread(url string) (int, error) {
+	f, err := cm.LoadFilters()
+	if err != nil {
+		return 0, err
+	}
+	f.MutedThreads = append(f.MutedThreads, url)
+	return cm.SaveFilters(f)
+}
+
+// UnmuteThread removes url from the muted threads. Comments dropped while
+// it was muted are not restored.
+func (cm *CacheManager) UnmuteThread(url string) error {
+	f, err := cm.LoadFilters()
+	if err != nil {
+		return err
+	}
+	kept := f.MutedThreads[:0]
+	for _, u := range f.MutedThreads {
+		if u != url {
+			kept = append(kept, u)
+		}
+	}
+	f.MutedThreads = kept
+	_, err = cm.SaveFilters(f)
+	return err
+}
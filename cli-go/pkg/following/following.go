@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
 )
 
 // normalizeFollowURL ensures consistent URL comparison by trimming trailing slashes.
@@ -35,6 +37,9 @@ type FollowingEntry struct {
 	AddedAt    string `json:"added_at"`
 	SiteTitle  string `json:"site_title,omitempty"`
 	AuthorName string `json:"author_name,omitempty"`
+	// PublicKey is the site key seen when the author was followed, against
+	// which moved statements are checked
+	PublicKey string `json:"public_key,omitempty"`
 }
 
 // DefaultPath returns the default path to following.json.
@@ -75,7 +80,7 @@ func Save(path string, f *FollowingFile) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+	if err := fsutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
 		return fmt.Errorf("failed to write following.json: %w", err)
 	}
 
@@ -0,0 +1,199 @@
+package following
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/migrate"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// MoveFetcher reads what move detection needs from followed sites.
+// *remote.Client implements it.
+type MoveFetcher interface {
+	FetchContent(url string) (string, error)
+	FetchWellKnown(baseURL string) (*remote.WellKnown, error)
+}
+
+// Move is a verified move of a followed site to a new base URL.
+type Move struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Moved     string `json:"moved"`
+	PublicKey string `json:"public_key"`
+}
+
+// MoveOptions says where to look for moves.
+type MoveOptions struct {
+	// Announced are moves registered with the discovery service; the
+	// followed sites they name are checked.
+	Announced []discovery.MigrationRecord
+	// Probe checks every followed site for a moved statement, announced
+	// or not. It fetches one file per site.
+	Probe bool
+}
+
+// FindMoves looks for signed moved statements from followed sites and
+// returns the moves that verify. A move verifies when its statement is
+// signed with the key trusted for the old site (the key recorded when it
+// was followed, else the old site's or the discovery service's current
+// key), and the site at the new address publishes the same key. Statements
+// that fail to verify are returned as errors and otherwise ignored.
+func (f *FollowingFile) FindMoves(fetcher MoveFetcher, opts MoveOptions) ([]Move, []error) {
+	announced := make(map[string]discovery.MigrationRecord, len(opts.Announced))
+	for _, rec := range opts.Announced {
+		announced[strings.ToLower(rec.OldDomain)] = rec
+	}
+
+	var moves []Move
+	var errs []error
+	for _, entry := range f.Following {
+		domain := strings.ToLower(discovery.ExtractDomainFromURL(entry.URL))
+		rec, isAnnounced := announced[domain]
+		if !isAnnounced && !opts.Probe {
+			continue
+		}
+
+		// The old host is the better witness; the new one keeps a copy for
+		// when the old host is gone
+		sources := []string{normalizeFollowURL(entry.URL) + "/" + migrate.MovedPath}
+		if isAnnounced && rec.NewDomain != "" {
+			sources = append(sources, "https://"+rec.NewDomain+"/"+migrate.MovedPath)
+		}
+
+		var lastErr error
+		for _, src := range sources {
+			content, err := fetcher.FetchContent(src)
+			if err != nil {
+				continue // No statement here
+			}
+			move, err := verifyMove(entry, content, rec.PublicKey, fetcher)
+			if err != nil {
+				lastErr = fmt.Errorf("%s: %w", entry.URL, err)
+				continue
+			}
+			moves = append(moves, *move)
+			lastErr = nil
+			break
+		}
+		if lastErr != nil {
+			errs = append(errs, lastErr)
+		}
+	}
+	return moves, errs
+}
+
+// verifyMove checks a moved statement for a followed entry.
+// announcedKey is the key the discovery service holds for the old domain.
+func verifyMove(entry FollowingEntry, content, announcedKey string, fetcher MoveFetcher) (*Move, error) {
+	m, err := migrate.ParseMoved([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(normalizeFollowURL(m.OldBaseURL), normalizeFollowURL(entry.URL)) {
+		return nil, fmt.Errorf("moved statement is for %s", m.OldBaseURL)
+	}
+	if strings.EqualFold(normalizeFollowURL(m.NewBaseURL), normalizeFollowURL(entry.URL)) {
+		return nil, errors.New("moved statement names the same address")
+	}
+
+	trusted := entry.PublicKey
+	if trusted == "" {
+		// A redirecting old host yields the new site's key, which the check
+		// below compares anyway; the redirect is the old host's word
+		if wk, err := fetcher.FetchWellKnown(entry.URL); err == nil {
+			trusted = wk.PublicKey
+		}
+	}
+	if trusted == "" {
+		trusted = announcedKey
+	}
+	if trusted == "" {
+		return nil, errors.New("no key known for the old site to check the moved statement against")
+	}
+	if err := m.Verify(trusted); err != nil {
+		return nil, err
+	}
+
+	wk, err := fetcher.FetchWellKnown(m.NewBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the new site's .well-known/polis: %w", err)
+	}
+	if strings.TrimSpace(wk.PublicKey) != m.PublicKey {
+		return nil, fmt.Errorf("%s publishes a different key than the moved statement", m.NewBaseURL)
+	}
+	return &Move{
+		From:      normalizeFollowURL(entry.URL),
+		To:        m.NewBaseURL,
+		Moved:     m.Moved,
+		PublicKey: m.PublicKey,
+	}, nil
+}
+
+// ApplyMove points the entry for a moved site at its new address, keeping
+// when it was followed. If the new address is already followed, the old
+// entry is dropped. Returns false if the old address isn't followed.
+func (f *FollowingFile) ApplyMove(move Move) bool {
+	entry := f.Get(move.From)
+	if entry == nil {
+		return false
+	}
+	if existing := f.Get(move.To); existing != nil {
+		if existing.PublicKey == "" {
+			existing.PublicKey = move.PublicKey
+		}
+		f.Remove(move.From)
+		return true
+	}
+	entry.URL = move.To
+	entry.PublicKey = move.PublicKey
+	return true
+}
+
+// ApplyMoves applies moves to the following file at path and returns those
+// that changed it.
+func ApplyMoves(path string, moves []Move) ([]Move, error) {
+	if len(moves) == 0 {
+		return nil, nil
+	}
+	f, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Move
+	for _, m := range moves {
+		if f.ApplyMove(m) {
+			applied = append(applied, m)
+		}
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	return applied, Save(path, f)
+}
+
+// MoveNotifications returns a notification for each applied move, so the
+// move isn't silent.
+func MoveNotifications(moves []Move) []notification.StateEntry {
+	entries := make([]notification.StateEntry, 0, len(moves))
+	for _, m := range moves {
+		from := discovery.ExtractDomainFromURL(m.From)
+		to := discovery.ExtractDomainFromURL(m.To)
+		entries = append(entries, notification.StateEntry{
+			ID:        "author-moved:" + m.From,
+			RuleID:    "author-moved",
+			Actor:     to,
+			Icon:      "\u27A1",
+			Message:   fmt.Sprintf("%s moved to %s; you now follow the new address", from, to),
+			Link:      m.To,
+			Payload:   map[string]interface{}{"from": m.From, "to": m.To, "moved": m.Moved},
+			EventIDs:  []int{},
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	return entries
+}
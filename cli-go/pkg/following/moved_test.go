@@ -0,0 +1,113 @@
+package following
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/migrate"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// fakeSites serves files and .well-known/polis keys by URL.
+type fakeSites struct {
+	files map[string]string
+	keys  map[string]string
+}
+
+func (s fakeSites) FetchContent(url string) (string, error) {
+	if c, ok := s.files[url]; ok {
+		return c, nil
+	}
+	return "", errors.New("404")
+}
+
+func (s fakeSites) FetchWellKnown(baseURL string) (*remote.WellKnown, error) {
+	if k, ok := s.keys[baseURL]; ok {
+		return &remote.WellKnown{PublicKey: k}, nil
+	}
+	return nil, errors.New("404")
+}
+
+func movedStatement(t *testing.T, oldURL, newURL string, priv, pub []byte) string {
+	t.Helper()
+	m, err := migrate.NewMoved(oldURL, newURL, string(pub), priv, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := m.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".well-known", "polis-moved"))
+	return string(data)
+}
+
+func TestFindMoves(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	otherPriv, otherPub, _ := signing.GenerateKeypair()
+
+	sites := fakeSites{
+		files: map[string]string{
+			"https://old.example/.well-known/polis-moved":    movedStatement(t, "https://old.example", "https://new.example", priv, pub),
+			"https://hijack.example/.well-known/polis-moved": movedStatement(t, "https://hijack.example", "https://evil.example", otherPriv, otherPub),
+		},
+		keys: map[string]string{
+			"https://new.example":  string(pub),
+			"https://evil.example": string(otherPub),
+		},
+	}
+	f := &FollowingFile{Following: []FollowingEntry{
+		{URL: "https://old.example", AddedAt: "2026-01-01T00:00:00Z", PublicKey: string(pub)},
+		{URL: "https://hijack.example", PublicKey: string(pub)}, // Moved by someone else's key
+		{URL: "https://quiet.example"},
+	}}
+
+	// Without probing, only announced moves are checked
+	if moves, _ := f.FindMoves(sites, MoveOptions{}); len(moves) != 0 {
+		t.Fatalf("unannounced moves found without probing: %v", moves)
+	}
+	moves, errs := f.FindMoves(sites, MoveOptions{Announced: []discovery.MigrationRecord{{OldDomain: "old.example", NewDomain: "new.example"}}})
+	if len(moves) != 1 || moves[0].To != "https://new.example" || len(errs) != 0 {
+		t.Fatalf("announced moves = %v, errors %v", moves, errs)
+	}
+
+	moves, errs = f.FindMoves(sites, MoveOptions{Probe: true})
+	if len(moves) != 1 || moves[0].From != "https://old.example" {
+		t.Errorf("probed moves = %v", moves)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected the hijacked statement to be rejected, got errors %v", errs)
+	}
+
+	if !f.ApplyMove(moves[0]) {
+		t.Fatal("ApplyMove returned false")
+	}
+	entry := f.Get("https://new.example")
+	if entry == nil || entry.AddedAt != "2026-01-01T00:00:00Z" || f.IsFollowing("https://old.example") {
+		t.Errorf("unexpected entries after move: %+v", f.Following)
+	}
+}
+
+func TestFindMoves_NewSiteKeyMismatch(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	_, otherPub, _ := signing.GenerateKeypair()
+	sites := fakeSites{
+		files: map[string]string{
+			"https://old.example/.well-known/polis-moved": movedStatement(t, "https://old.example", "https://new.example", priv, pub),
+		},
+		keys: map[string]string{
+			"https://old.example": string(pub),
+			"https://new.example": string(otherPub),
+		},
+	}
+	f := &FollowingFile{Following: []FollowingEntry{{URL: "https://old.example"}}}
+	moves, errs := f.FindMoves(sites, MoveOptions{Probe: true})
+	if len(moves) != 0 || len(errs) != 1 {
+		t.Errorf("moves = %v, errors = %v; want the move rejected", moves, errs)
+	}
+}
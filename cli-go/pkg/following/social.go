@@ -27,8 +27,9 @@ type UnfollowResult struct {
 
 // FollowWithBlessing adds an author to the following list and blesses any
 // pending or denied comments from that author. This matches the CLI behavior
-// where following someone auto-blesses their comments.
-func FollowWithBlessing(followingPath string, authorURL string, discoveryClient *discovery.Client, remoteClient *remote.Client, privKey []byte) (*FollowResult, error) {
+// where following someone auto-blesses their comments. dsCfg, if given,
+// is used for the follow event instead of the stream package's globals.
+func FollowWithBlessing(followingPath string, authorURL string, discoveryClient *discovery.Client, remoteClient *remote.Client, privKey []byte, dsCfg ...*stream.DiscoveryConfig) (*FollowResult, error) {
 	result := &FollowResult{
 		AuthorURL: authorURL,
 	}
@@ -92,6 +93,9 @@ func FollowWithBlessing(followingPath string, authorURL string, discoveryClient
 	if entry := f.Get(authorURL); entry != nil {
 		entry.SiteTitle = remoteWK.SiteTitle
 		entry.AuthorName = remoteWK.Author
+		if entry.PublicKey == "" {
+			entry.PublicKey = remoteWK.PublicKey
+		}
 	}
 
 	if err := Save(followingPath, f); err != nil {
@@ -101,14 +105,15 @@ func FollowWithBlessing(followingPath string, authorURL string, discoveryClient
 	// Emit follow event to discovery stream (non-fatal)
 	stream.PublishEvent("polis.follow.announced", map[string]interface{}{
 		"target_domain": discovery.ExtractDomainFromURL(authorURL),
-	}, privKey)
+	}, privKey, dsCfg...)
 
 	return result, nil
 }
 
 // UnfollowWithDenial removes an author from the following list and denies
 // any blessed comments from that author. This matches the CLI behavior.
-func UnfollowWithDenial(followingPath string, authorURL string, discoveryClient *discovery.Client, remoteClient *remote.Client, privKey []byte) (*UnfollowResult, error) {
+// dsCfg is used for the unfollow event as in FollowWithBlessing.
+func UnfollowWithDenial(followingPath string, authorURL string, discoveryClient *discovery.Client, remoteClient *remote.Client, privKey []byte, dsCfg ...*stream.DiscoveryConfig) (*UnfollowResult, error) {
 	result := &UnfollowResult{
 		AuthorURL: authorURL,
 	}
@@ -152,7 +157,7 @@ func UnfollowWithDenial(followingPath string, authorURL string, discoveryClient
 	// Emit unfollow event to discovery stream (non-fatal)
 	stream.PublishEvent("polis.follow.removed", map[string]interface{}{
 		"target_domain": discovery.ExtractDomainFromURL(authorURL),
-	}, privKey)
+	}, privKey, dsCfg...)
 
 	return result, nil
 }
@@ -0,0 +1,224 @@
+// Package followlist publishes signed lists of sites to follow ("starter
+// packs"), and subscribes to other sites' lists.
+//
+// A list lives at metadata/lists/<slug>.json and is rendered as
+// lists/<slug>.html. It is signed with the site key, so a subscriber can
+// check that it comes from the site it is hosted on. Subscribing follows
+// every member; later syncs follow the members added since, and never
+// re-follow one that was unfollowed.
+package followlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// Dir holds a site's lists, relative to the site root.
+const Dir = "metadata/lists"
+
+// slugPattern is a list's name in its file names and URLs.
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// ValidSlug reports whether slug can name a list.
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
+
+// Member is a site on a list.
+type Member struct {
+	URL  string `json:"url"`
+	Note string `json:"note,omitempty"`
+}
+
+// List is a signed list of sites.
+type List struct {
+	Version     int      `json:"version"`
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author"` // The publishing site's base URL
+	Updated     string   `json:"updated"`
+	Members     []Member `json:"members"`
+	Signature   string   `json:"signature"`
+}
+
+// New returns an empty, unsigned list.
+func New(slug, title, author string) (*List, error) {
+	if !ValidSlug(slug) {
+		return nil, fmt.Errorf("invalid list name %q (use lowercase letters, digits, and dashes)", slug)
+	}
+	if title == "" {
+		title = slug
+	}
+	return &List{Version: 1, Slug: slug, Title: title, Author: strings.TrimSuffix(author, "/"), Members: []Member{}}, nil
+}
+
+// Add adds a member, or updates its note if note isn't empty. It reports
+// whether the list changed.
+func (l *List) Add(url, note string) bool {
+	url = strings.TrimRight(url, "/")
+	for i, m := range l.Members {
+		if strings.EqualFold(m.URL, url) {
+			if note == "" || m.Note == note {
+				return false
+			}
+			l.Members[i].Note = note
+			return true
+		}
+	}
+	l.Members = append(l.Members, Member{URL: url, Note: note})
+	return true
+}
+
+// Remove removes a member and reports whether it was on the list.
+func (l *List) Remove(url string) bool {
+	url = strings.TrimRight(url, "/")
+	for i, m := range l.Members {
+		if strings.EqualFold(m.URL, url) {
+			l.Members = append(l.Members[:i], l.Members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// statement is the signed form: every field but the signature, in a fixed
+// order.
+func (l *List) statement() []byte {
+	members := l.Members
+	if members == nil {
+		members = []Member{}
+	}
+	data, _ := json.Marshal(struct {
+		Version     int      `json:"version"`
+		Slug        string   `json:"slug"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Author      string   `json:"author"`
+		Updated     string   `json:"updated"`
+		Members     []Member `json:"members"`
+	}{l.Version, l.Slug, l.Title, l.Description, l.Author, l.Updated, members})
+	return data
+}
+
+// Sign stamps the list with the current time and signs it.
+func (l *List) Sign(privateKey []byte) error {
+	l.Updated = time.Now().UTC().Format(time.RFC3339)
+	sig, err := signing.SignContent(l.statement(), privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign list: %w", err)
+	}
+	l.Signature = sig
+	return nil
+}
+
+// Verify checks the list's signature against publicKey, the key its
+// author's site publishes.
+func (l *List) Verify(publicKey string) error {
+	ok, err := signing.VerifySignature(l.statement(), []byte(strings.TrimSpace(publicKey)), l.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid list signature: %w", err)
+	}
+	if !ok {
+		return errors.New("list signature does not verify")
+	}
+	return nil
+}
+
+// Parse decodes a list. It does not verify it.
+func Parse(data []byte) (*List, error) {
+	var l List
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("invalid follow list: %w", err)
+	}
+	if l.Author == "" || l.Signature == "" || !ValidSlug(l.Slug) {
+		return nil, errors.New("incomplete follow list")
+	}
+	return &l, nil
+}
+
+// Path returns the file a list is stored in.
+func Path(dataDir, slug string) string {
+	return filepath.Join(dataDir, filepath.FromSlash(Dir), slug+".json")
+}
+
+// Load reads one of the site's lists.
+func Load(dataDir, slug string) (*List, error) {
+	if !ValidSlug(slug) {
+		return nil, fmt.Errorf("invalid list name %q", slug)
+	}
+	data, err := os.ReadFile(Path(dataDir, slug))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// LoadAll reads the site's lists, ordered by slug. Files that don't parse
+// are skipped.
+func LoadAll(dataDir string) ([]*List, error) {
+	entries, err := os.ReadDir(filepath.Join(dataDir, filepath.FromSlash(Dir)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lists []*List
+	for _, e := range entries {
+		slug, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if l, err := Load(dataDir, slug); err == nil {
+			lists = append(lists, l)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Slug < lists[j].Slug })
+	return lists, nil
+}
+
+// Save writes the list to its file. Sign it first.
+func (l *List) Save(dataDir string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := Path(dataDir, l.Slug)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Delete removes a list and its rendered page.
+func Delete(dataDir, slug string) error {
+	if !ValidSlug(slug) {
+		return fmt.Errorf("invalid list name %q", slug)
+	}
+	if err := os.Remove(Path(dataDir, slug)); err != nil {
+		return err
+	}
+	os.Remove(filepath.Join(dataDir, filepath.FromSlash(PagePath(slug))))
+	return nil
+}
+
+// PagePath is where a list's page is rendered, relative to the site root.
+func PagePath(slug string) string {
+	return "lists/" + slug + ".html"
+}
+
+// URL returns where a site at baseURL publishes the list, for subscribers.
+func URL(baseURL, slug string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + Dir + "/" + slug + ".json"
+}
@@ -0,0 +1,172 @@
+package followlist
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// fakeFetcher serves lists by URL and keys by base URL.
+type fakeFetcher struct {
+	content map[string]string
+	keys    map[string]string
+}
+
+func (f *fakeFetcher) FetchContent(url string) (string, error) {
+	if c, ok := f.content[url]; ok {
+		return c, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (f *fakeFetcher) FetchPublicKey(baseURL string) (string, error) {
+	if k, ok := f.keys[baseURL]; ok {
+		return k, nil
+	}
+	return "", errors.New("not found")
+}
+
+// signedList saves a signed list by alice.example.com in dir and loads it
+// back.
+func signedList(t *testing.T, dir string, priv []byte, members ...string) *List {
+	t.Helper()
+	l, err := New("writers", "Writers", "https://alice.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		l.Add(m, "")
+	}
+	if err := l.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir, "writers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	_, otherPub, _ := signing.GenerateKeypair()
+	l := signedList(t, t.TempDir(), priv, "https://bob.example.com/", "https://carol.example.com")
+
+	if l.Author != "https://alice.example.com" || len(l.Members) != 2 || l.Members[0].URL != "https://bob.example.com" {
+		t.Fatalf("list = %+v", l)
+	}
+	if err := l.Verify(string(pub)); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := l.Verify(string(otherPub)); err == nil {
+		t.Error("expected a different key to fail")
+	}
+	l.Members = append(l.Members, Member{URL: "https://mallory.example.com"})
+	if err := l.Verify(string(pub)); err == nil {
+		t.Error("expected a tampered list to fail")
+	}
+	if _, err := New("Not A Slug", "", "https://alice.example.com"); err == nil {
+		t.Error("expected an invalid slug to fail")
+	}
+}
+
+func TestSubscribeAndSync(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	listURL := URL("https://alice.example.com", "writers")
+	publish := func(l *List) string {
+		data, _ := json.Marshal(l)
+		return string(data)
+	}
+	l := signedList(t, t.TempDir(), priv, "https://bob.example.com", "https://carol.example.com", "https://me.example.com")
+	fetcher := &fakeFetcher{
+		content: map[string]string{listURL: publish(l)},
+		keys:    map[string]string{"https://alice.example.com": string(pub)},
+	}
+
+	var followed []string
+	follow := func(url string) (bool, error) {
+		if url == "https://carol.example.com" {
+			return true, nil
+		}
+		followed = append(followed, url)
+		return false, nil
+	}
+
+	dir := t.TempDir()
+	result, err := Subscribe(dir, listURL, fetcher, follow, "https://me.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Followed) != 1 || result.Followed[0] != "https://bob.example.com" || len(result.Already) != 1 || result.Title != "Writers" {
+		t.Errorf("subscribe = %+v", result)
+	}
+
+	// A member added later is followed on the next sync; those seen
+	// before are not followed again
+	l.Add("https://dave.example.com", "")
+	l.Sign(priv)
+	fetcher.content[listURL] = publish(l)
+	followed = nil
+	results, err := Sync(dir, fetcher, follow, "https://me.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || len(followed) != 1 || followed[0] != "https://dave.example.com" {
+		t.Errorf("sync followed %v, results %+v", followed, results)
+	}
+
+	subs, _ := LoadSubscriptions(dir)
+	if len(subs.Lists) != 1 || len(subs.Lists[0].Seen) != 3 || subs.Lists[0].Author != "https://alice.example.com" {
+		t.Errorf("subscriptions = %+v", subs.Lists)
+	}
+}
+
+func TestFetch_Rejects(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	_, otherPub, _ := signing.GenerateKeypair()
+	data, _ := json.Marshal(signedList(t, t.TempDir(), priv, "https://bob.example.com"))
+
+	// Hosted on a site other than its author's
+	elsewhere := "https://mallory.example.com/metadata/lists/writers.json"
+	fetcher := &fakeFetcher{
+		content: map[string]string{elsewhere: string(data)},
+		keys:    map[string]string{"https://alice.example.com": string(pub)},
+	}
+	if _, err := Fetch(fetcher, elsewhere); err == nil {
+		t.Error("expected a list hosted elsewhere to fail")
+	}
+
+	// Signed with a key the author's site doesn't publish
+	listURL := URL("https://alice.example.com", "writers")
+	fetcher.content[listURL] = string(data)
+	fetcher.keys["https://alice.example.com"] = string(otherPub)
+	if _, err := Fetch(fetcher, listURL); err == nil {
+		t.Error("expected a list signed with another key to fail")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	priv, _, _ := signing.GenerateKeypair()
+	dir := t.TempDir()
+	signedList(t, dir, priv)
+	page := filepath.Join(dir, filepath.FromSlash(PagePath("writers")))
+	os.MkdirAll(filepath.Dir(page), 0755)
+	os.WriteFile(page, []byte("<html>"), 0644)
+
+	if err := Delete(dir, "writers"); err != nil {
+		t.Fatal(err)
+	}
+	if lists, _ := LoadAll(dir); len(lists) != 0 {
+		t.Errorf("lists after delete = %d", len(lists))
+	}
+	if _, err := os.Stat(page); !os.IsNotExist(err) {
+		t.Errorf("page still exists: %v", err)
+	}
+}
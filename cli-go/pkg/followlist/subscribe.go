@@ -0,0 +1,206 @@
+package followlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// SubscriptionsFilename records subscribed lists, relative to .polis/.
+const SubscriptionsFilename = "follow-lists.json"
+
+// subscriptionsName is the subscriptions file's name in site storage.
+const subscriptionsName = ".polis/" + SubscriptionsFilename
+
+// Fetcher reads lists and the keys that sign them. *remote.Client
+// implements it.
+type Fetcher interface {
+	FetchContent(url string) (string, error)
+	FetchPublicKey(baseURL string) (string, error)
+}
+
+// Fetch reads a list and verifies it. The list must be hosted on its
+// author's site and signed with the key that site publishes.
+func Fetch(fetcher Fetcher, listURL string) (*List, error) {
+	content, err := fetcher.FetchContent(listURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch list: %w", err)
+	}
+	l, err := Parse([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(listURL); err != nil || !strings.EqualFold(u.Hostname(), discovery.ExtractDomainFromURL(l.Author)) {
+		return nil, fmt.Errorf("list names %s as its author but is hosted elsewhere", l.Author)
+	}
+	key, err := fetcher.FetchPublicKey(l.Author)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the author's key: %w", err)
+	}
+	if err := l.Verify(key); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Subscription is a list followed for updates.
+type Subscription struct {
+	URL          string   `json:"url"`
+	Title        string   `json:"title"`
+	Author       string   `json:"author"`
+	SubscribedAt string   `json:"subscribed_at"`
+	CheckedAt    string   `json:"checked_at,omitempty"`
+	Updated      string   `json:"updated,omitempty"` // The list's, when last checked
+	Seen         []string `json:"seen"`              // Members followed or skipped so far
+}
+
+// Subscriptions is the site's subscribed lists.
+type Subscriptions struct {
+	Lists []Subscription `json:"lists"`
+}
+
+// LoadSubscriptions reads the subscriptions; a missing file is none.
+func LoadSubscriptions(dataDir string) (*Subscriptions, error) {
+	subs := &Subscriptions{}
+	data, err := storage.For(dataDir).ReadFile(subscriptionsName)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", SubscriptionsFilename, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, subs); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", SubscriptionsFilename, err)
+		}
+	}
+	if subs.Lists == nil {
+		subs.Lists = []Subscription{}
+	}
+	return subs, nil
+}
+
+// Save writes the subscriptions.
+func (s *Subscriptions) Save(dataDir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.For(dataDir).WriteFile(subscriptionsName, append(data, '\n'), 0644)
+}
+
+// Get returns the subscription to listURL, or nil.
+func (s *Subscriptions) Get(listURL string) *Subscription {
+	for i := range s.Lists {
+		if s.Lists[i].URL == listURL {
+			return &s.Lists[i]
+		}
+	}
+	return nil
+}
+
+// Remove drops the subscription to listURL and reports whether there was
+// one. Members already followed stay followed.
+func (s *Subscriptions) Remove(listURL string) bool {
+	for i := range s.Lists {
+		if s.Lists[i].URL == listURL {
+			s.Lists = append(s.Lists[:i], s.Lists[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FollowFunc follows a site, or reports that it is already followed.
+type FollowFunc func(url string) (already bool, err error)
+
+// SyncResult reports the members followed from one list.
+type SyncResult struct {
+	URL      string            `json:"url"`
+	Title    string            `json:"title"`
+	Followed []string          `json:"followed"`
+	Already  []string          `json:"already_following"`
+	Failed   map[string]string `json:"failed,omitempty"` // Member -> error; retried next sync
+	Error    string            `json:"error,omitempty"`  // The list couldn't be read
+}
+
+// Subscribe fetches a list, follows its members, and records the
+// subscription. Subscribing to a list again syncs it.
+func Subscribe(dataDir, listURL string, fetcher Fetcher, follow FollowFunc, self string) (*SyncResult, error) {
+	subs, err := LoadSubscriptions(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	sub := subs.Get(listURL)
+	if sub == nil {
+		subs.Lists = append(subs.Lists, Subscription{URL: listURL, SubscribedAt: time.Now().UTC().Format(time.RFC3339), Seen: []string{}})
+		sub = &subs.Lists[len(subs.Lists)-1]
+	}
+	result := syncList(sub, fetcher, follow, self)
+	if result.Error != "" {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	return result, subs.Save(dataDir)
+}
+
+// Sync checks every subscribed list and follows the members added since
+// the last check.
+func Sync(dataDir string, fetcher Fetcher, follow FollowFunc, self string) ([]*SyncResult, error) {
+	subs, err := LoadSubscriptions(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SyncResult, 0, len(subs.Lists))
+	for i := range subs.Lists {
+		results = append(results, syncList(&subs.Lists[i], fetcher, follow, self))
+	}
+	return results, subs.Save(dataDir)
+}
+
+// syncList follows a list's unseen members. self, this site's base URL, is
+// never followed.
+func syncList(sub *Subscription, fetcher Fetcher, follow FollowFunc, self string) *SyncResult {
+	result := &SyncResult{URL: sub.URL, Title: sub.Title, Followed: []string{}, Already: []string{}}
+	l, err := Fetch(fetcher, sub.URL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	sub.Title, sub.Author, sub.Updated = l.Title, l.Author, l.Updated
+	sub.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+	result.Title = l.Title
+
+	seen := make(map[string]bool, len(sub.Seen))
+	for _, u := range sub.Seen {
+		seen[strings.ToLower(u)] = true
+	}
+	selfDomain := discovery.ExtractDomainFromURL(self)
+	for _, m := range l.Members {
+		key := strings.ToLower(m.URL)
+		if seen[key] || !strings.HasPrefix(key, "https://") {
+			continue
+		}
+		if d := discovery.ExtractDomainFromURL(m.URL); d == "" || strings.EqualFold(d, selfDomain) {
+			continue
+		}
+		already, err := follow(m.URL)
+		if err != nil {
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[m.URL] = err.Error()
+			continue
+		}
+		if already {
+			result.Already = append(result.Already, m.URL)
+		} else {
+			result.Followed = append(result.Followed, m.URL)
+		}
+		seen[key] = true
+		sub.Seen = append(sub.Seen, m.URL)
+	}
+	return result
+}
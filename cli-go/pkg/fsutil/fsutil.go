@@ -0,0 +1,188 @@
+// Package fsutil provides durable file writes for site data.
+//
+// Writes go to a temp file that is renamed over the target. By default the
+// temp file sits next to the target, so the rename never crosses a
+// filesystem boundary even when the data directory is on NFS or SMB. The
+// temp location and the fsync policy are configurable (temp_dir and fsync
+// settings) and are applied at startup by the CLI and the webapp.
+//
+// JSON-lines files that are appended to (public.jsonl, notification state)
+// use AppendJSONLines and ReadJSONLines, which tolerate a partial last line
+// left by a crash.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// SyncPolicy controls when writes are flushed to stable storage.
+type SyncPolicy string
+
+const (
+	// SyncAuto fsyncs only when the target is on a network filesystem.
+	SyncAuto SyncPolicy = "auto"
+	// SyncAlways fsyncs the file and its directory on every write.
+	SyncAlways SyncPolicy = "always"
+	// SyncNever never fsyncs (fastest; relies on the OS to flush).
+	SyncNever SyncPolicy = "never"
+)
+
+// ParseSyncPolicy converts a setting value to a SyncPolicy.
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch p := SyncPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case SyncAuto, SyncAlways, SyncNever:
+		return p, nil
+	case "":
+		return SyncAuto, nil
+	}
+	return SyncAuto, fmt.Errorf("unknown fsync policy %q (expected auto, always, or never)", s)
+}
+
+// Package configuration, set from the temp_dir and fsync settings.
+var (
+	// TempDir is where temp files are created before being renamed into
+	// place. Empty means "next to the target file".
+	TempDir string
+
+	// Sync is the fsync policy for WriteFile.
+	Sync = SyncAuto
+)
+
+// shouldSync reports whether writes into dir should be fsynced.
+func shouldSync(dir string) bool {
+	switch Sync {
+	case SyncAlways:
+		return true
+	case SyncNever:
+		return false
+	}
+	return IsNetworkFS(dir)
+}
+
+// WriteFile writes data to path atomically: readers see either the old
+// content or the new content, never a partial file. If the configured
+// TempDir is on a different filesystem than path, it falls back to a temp
+// file next to path.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	doSync := shouldSync(dir)
+
+	if TempDir != "" {
+		err := writeVia(TempDir, path, data, perm, doSync)
+		if err == nil || !isCrossDevice(err) {
+			return err
+		}
+	}
+	return writeVia(dir, path, data, perm, doSync)
+}
+
+func writeVia(tmpDir, path string, data []byte, perm os.FileMode, doSync bool) error {
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	f, err := os.CreateTemp(tmpDir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := f.Name()
+	cleanup := func() {
+		f.Close()
+		os.Remove(tmpPath)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if doSync {
+		if err := f.Sync(); err != nil {
+			cleanup()
+			return fmt.Errorf("failed to sync temp file: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	if doSync {
+		if err := SyncDir(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to sync directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// SyncDir fsyncs a directory so a rename into it survives a crash.
+// Filesystems that do not support syncing directories are ignored.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return err
+	}
+	return nil
+}
+
+// MkdirTemp creates a private scratch directory under TempDir (or the
+// system temp dir when TempDir is unset).
+func MkdirTemp(pattern string) (string, error) {
+	if TempDir != "" {
+		if err := os.MkdirAll(TempDir, 0755); err != nil {
+			return "", err
+		}
+	}
+	dir, err := os.MkdirTemp(TempDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	os.Chmod(dir, 0700)
+	return dir, nil
+}
+
+// CreateTemp creates a scratch file under TempDir (or the system temp dir
+// when TempDir is unset).
+func CreateTemp(pattern string) (*os.File, error) {
+	if TempDir != "" {
+		if err := os.MkdirAll(TempDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return os.CreateTemp(TempDir, pattern)
+}
+
+// isCrossDevice reports whether err came from renaming across filesystems.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// ResolveTempDir turns a temp_dir setting into an absolute path. Relative
+// values are taken relative to the data directory.
+func ResolveTempDir(dataDir, setting string) string {
+	if setting == "" {
+		return ""
+	}
+	if filepath.IsAbs(setting) {
+		return setting
+	}
+	return filepath.Join(dataDir, setting)
+}
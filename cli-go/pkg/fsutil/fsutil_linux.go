@@ -0,0 +1,24 @@
+package fsutil
+
+import "syscall"
+
+// Filesystem magic numbers from statfs(2) for network and FUSE mounts.
+var networkFSTypes = map[int64]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x65735546: true, // FUSE (sshfs, rclone, ...)
+	0x564C:     true, // NCP
+	0x73757245: true, // Coda
+	0x61636673: true, // ACFS
+}
+
+// IsNetworkFS reports whether path is on a network filesystem.
+func IsNetworkFS(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return networkFSTypes[int64(st.Type)]
+}
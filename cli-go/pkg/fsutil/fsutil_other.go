@@ -0,0 +1,10 @@
+//go:build !linux
+
+package fsutil
+
+// IsNetworkFS reports whether path is on a network filesystem. Detection is
+// only implemented on Linux; elsewhere set fsync to "always" for network
+// mounts.
+func IsNetworkFS(path string) bool {
+	return false
+}
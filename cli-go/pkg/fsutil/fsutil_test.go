@@ -0,0 +1,83 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withConfig(t *testing.T, tempDir string, sync SyncPolicy) {
+	t.Helper()
+	oldDir, oldSync := TempDir, Sync
+	TempDir, Sync = tempDir, sync
+	t.Cleanup(func() { TempDir, Sync = oldDir, oldSync })
+}
+
+func TestWriteFile_ReplacesContent(t *testing.T) {
+	withConfig(t, "", SyncAlways)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metadata", "public.jsonl")
+
+	if err := WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := WriteFile(path, []byte("two\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "two\n" {
+		t.Errorf("content = %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the target file, found %d entries", len(entries))
+	}
+}
+
+func TestWriteFile_UsesConfiguredTempDir(t *testing.T) {
+	dataDir := t.TempDir()
+	tmp := filepath.Join(dataDir, ".polis", "tmp")
+	withConfig(t, tmp, SyncNever)
+
+	path := filepath.Join(dataDir, "posts", "20260101", "hello.md")
+	if err := WriteFile(path, []byte("# Hello"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("perm = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("temp dir should be empty after rename, has %d entries", len(entries))
+	}
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	for in, want := range map[string]SyncPolicy{"": SyncAuto, "Always": SyncAlways, "never": SyncNever, "auto": SyncAuto} {
+		got, err := ParseSyncPolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSyncPolicy(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseSyncPolicy("sometimes"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestResolveTempDir(t *testing.T) {
+	if got := ResolveTempDir("/site", ""); got != "" {
+		t.Errorf("empty setting = %q", got)
+	}
+	if got := ResolveTempDir("/site", ".polis/tmp"); got != filepath.Join("/site", ".polis", "tmp") {
+		t.Errorf("relative setting = %q", got)
+	}
+	abs := filepath.Join(t.TempDir(), "scratch")
+	if got := ResolveTempDir("/site", abs); got != abs {
+		t.Errorf("absolute setting = %q", got)
+	}
+}
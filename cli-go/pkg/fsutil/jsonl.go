@@ -0,0 +1,101 @@
+package fsutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+)
+
+// ReadJSONLines reads a JSON-lines file and returns its non-empty lines
+// (without line endings). A final line that has no trailing newline and is
+// not valid JSON is what a crash mid-append leaves behind; it is dropped
+// with a warning, and the next rewrite of the file removes it. Other
+// malformed lines are returned for the caller to skip.
+func ReadJSONLines(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return SplitJSONLines(data, path), nil
+}
+
+// SplitJSONLines splits the contents of a JSON-lines file as ReadJSONLines
+// does. name identifies the file in the warning for a dropped partial line.
+func SplitJSONLines(data []byte, name string) [][]byte {
+	var lines [][]byte
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			if line := bytes.TrimSpace(data); len(line) > 0 {
+				if json.Valid(line) {
+					lines = append(lines, line)
+				} else {
+					logging.Warn("dropped partial trailing line", "file", name, "bytes", len(data))
+				}
+			}
+			break
+		}
+		if line := bytes.TrimRight(data[:i], "\r"); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		data = data[i+1:]
+	}
+	return lines
+}
+
+// AppendJSONLines appends one record per line to path, creating it if
+// needed. If the file ends in a partial line, a newline is written first so
+// the new records stay readable. Writes are fsynced per the Sync policy.
+func AppendJSONLines(path string, lines [][]byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if needsNewline(path) {
+		buf.WriteByte('\n')
+	}
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append to %s: %w", filepath.Base(path), err)
+	}
+	if shouldSync(dir) {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to sync %s: %w", filepath.Base(path), err)
+		}
+	}
+	return f.Close()
+}
+
+// needsNewline reports whether path is non-empty and does not end in '\n'.
+func needsNewline(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false
+	}
+	return last[0] != '\n'
+}
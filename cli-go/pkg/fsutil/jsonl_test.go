@@ -0,0 +1,51 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadJSONLines_DropsPartialTrailingLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "public.jsonl")
+	os.WriteFile(path, []byte("{\"a\":1}\r\n\n{\"b\":2}\n{\"c\":"), 0644)
+
+	lines, err := ReadJSONLines(path)
+	if err != nil {
+		t.Fatalf("ReadJSONLines: %v", err)
+	}
+	if len(lines) != 2 || string(lines[0]) != `{"a":1}` || string(lines[1]) != `{"b":2}` {
+		t.Errorf("lines = %q", lines)
+	}
+}
+
+func TestReadJSONLines_KeepsCompleteUnterminatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "public.jsonl")
+	os.WriteFile(path, []byte("{\"a\":1}\n{\"b\":2}"), 0644)
+
+	lines, _ := ReadJSONLines(path)
+	if len(lines) != 2 {
+		t.Errorf("lines = %q", lines)
+	}
+}
+
+func TestAppendJSONLines_TerminatesPartialLine(t *testing.T) {
+	withConfig(t, "", SyncAlways)
+	path := filepath.Join(t.TempDir(), "state", "polis.notification.jsonl")
+
+	if err := AppendJSONLines(path, [][]byte{[]byte(`{"a":1}`)}, 0644); err != nil {
+		t.Fatalf("AppendJSONLines: %v", err)
+	}
+	// Simulate a crash mid-append.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"b":`)
+	f.Close()
+
+	if err := AppendJSONLines(path, [][]byte{[]byte(`{"c":3}`)}, 0644); err != nil {
+		t.Fatalf("AppendJSONLines: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"a\":1}\n{\"b\":\n{\"c\":3}\n" {
+		t.Errorf("content = %q", data)
+	}
+}
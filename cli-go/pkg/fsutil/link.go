@@ -0,0 +1,70 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LinkFileSuffix names the fallback link file written next to a directory
+// link when the platform can create neither a symlink nor a junction. The
+// file holds the absolute target path.
+const LinkFileSuffix = ".link"
+
+// LinkDir makes link point at the directory target. It uses a symlink where
+// possible; on Windows it falls back to a directory junction, then to a
+// link file (see LinkFileSuffix). link must not exist.
+func LinkDir(target, link string) error {
+	os.Remove(link + LinkFileSuffix)
+	return linkDir(target, link)
+}
+
+// IsLink reports whether path is a symlink, a Windows junction, or has a
+// link file beside it.
+func IsLink(path string) bool {
+	if info, err := os.Lstat(path); err == nil && isLinkMode(info.Mode()) {
+		return true
+	}
+	_, ok := readLinkFile(path)
+	return ok
+}
+
+// RemoveLink removes a link created by LinkDir without touching its target.
+func RemoveLink(link string) error {
+	os.Remove(link + LinkFileSuffix)
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ResolveLink follows symlinks, junctions, and link files. Paths that do
+// not exist are returned unchanged.
+func ResolveLink(path string) string {
+	if target, ok := readLinkFile(path); ok {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = target
+		}
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+func readLinkFile(path string) (string, bool) {
+	data, err := os.ReadFile(path + LinkFileSuffix)
+	if err != nil {
+		return "", false
+	}
+	target := strings.TrimSpace(string(data))
+	if target == "" || !filepath.IsAbs(target) {
+		return "", false
+	}
+	return target, true
+}
+
+// writeLinkFile records target in link's link file.
+func writeLinkFile(target, link string) error {
+	return WriteFile(link+LinkFileSuffix, []byte(target+"\n"), 0644)
+}
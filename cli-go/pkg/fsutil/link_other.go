@@ -0,0 +1,13 @@
+//go:build !windows
+
+package fsutil
+
+import "os"
+
+func linkDir(target, link string) error {
+	return os.Symlink(target, link)
+}
+
+func isLinkMode(mode os.FileMode) bool {
+	return mode&os.ModeSymlink != 0
+}
@@ -0,0 +1,53 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkDir(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "site")
+	os.MkdirAll(filepath.Join(target, ".polis"), 0755)
+	link := filepath.Join(dir, "data")
+
+	if err := LinkDir(target, link); err != nil {
+		t.Fatalf("LinkDir: %v", err)
+	}
+	if !IsLink(link) {
+		t.Error("IsLink should report the new link")
+	}
+	want, _ := filepath.EvalSymlinks(target)
+	if got := ResolveLink(link); got != want {
+		t.Errorf("ResolveLink = %q, want %q", got, want)
+	}
+
+	if err := RemoveLink(link); err != nil {
+		t.Fatalf("RemoveLink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, ".polis")); err != nil {
+		t.Error("RemoveLink must not touch the target")
+	}
+}
+
+func TestResolveLink_LinkFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "site")
+	os.MkdirAll(target, 0755)
+	link := filepath.Join(dir, "data")
+	if err := writeLinkFile(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsLink(link) {
+		t.Error("IsLink should report a link file")
+	}
+	want, _ := filepath.EvalSymlinks(target)
+	if got := ResolveLink(link); got != want {
+		t.Errorf("ResolveLink = %q, want %q", got, want)
+	}
+	if got := ResolveLink(filepath.Join(dir, "missing")); got != filepath.Join(dir, "missing") {
+		t.Errorf("missing path should be returned unchanged, got %q", got)
+	}
+}
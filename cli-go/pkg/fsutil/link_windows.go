@@ -0,0 +1,29 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// linkDir tries, in order: a symlink (needs Developer Mode or admin rights),
+// a directory junction (any user, local volumes only), and a link file.
+func linkDir(target, link string) error {
+	if err := os.Symlink(target, link); err == nil {
+		return nil
+	}
+	if out, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput(); err == nil {
+		return nil
+	} else if _, statErr := os.Lstat(link); statErr == nil {
+		return fmt.Errorf("mklink /J failed: %v: %s", err, out)
+	}
+	return writeLinkFile(target, link)
+}
+
+// isLinkMode reports symlinks and junctions. Newer Go releases report
+// junctions as irregular files rather than symlinks.
+func isLinkMode(mode os.FileMode) bool {
+	return mode&(os.ModeSymlink|os.ModeIrregular) != 0
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLink_Junction(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "site")
+	os.MkdirAll(target, 0755)
+	link := filepath.Join(dir, "data")
+
+	if out, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput(); err != nil {
+		t.Skipf("mklink /J unavailable: %v: %s", err, out)
+	}
+	if !IsLink(link) {
+		t.Error("IsLink should report a junction")
+	}
+	if err := RemoveLink(link); err != nil {
+		t.Fatalf("RemoveLink: %v", err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Error("removing a junction must not remove its target")
+	}
+}
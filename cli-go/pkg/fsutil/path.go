@@ -0,0 +1,46 @@
+package fsutil
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Site paths (posts/20260125/hello.md, comments/..., .polis/posts/drafts/...)
+// are slash-separated on every platform: they are what index files, URLs,
+// and the webapp API carry. SlashPath and SiteRelPath turn paths typed on
+// Windows, or produced by the path/filepath package there, into that form.
+
+// SlashPath returns p as a clean, slash-separated site path. Backslashes
+// count as separators on every platform, so a path like posts\..\..\x is
+// checked the same way wherever it arrives. A leading "./" is dropped.
+func SlashPath(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// IsAbsPath reports whether p is absolute on any platform: a leading slash
+// or backslash, or a Windows volume name (C:, \\server\share).
+func IsAbsPath(p string) bool {
+	if strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) || filepath.IsAbs(p) {
+		return true
+	}
+	// A drive letter, with or without a separator after it (C:posts)
+	return len(p) >= 2 && p[1] == ':' && unicode.IsLetter(rune(p[0]))
+}
+
+// SiteRelPath returns the site path of arg, a path given on the command
+// line: relative to dir if arg (absolute, or relative to the working
+// directory) is inside it, otherwise arg itself as a slash path.
+func SiteRelPath(dir, arg string) string {
+	if abs, err := filepath.Abs(arg); err == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return SlashPath(rel)
+		}
+	}
+	return SlashPath(arg)
+}
@@ -0,0 +1,61 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlashPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"posts/20260125/hello.md":    "posts/20260125/hello.md",
+		`posts\20260125\hello.md`:    "posts/20260125/hello.md",
+		"./posts//20260125/hello.md": "posts/20260125/hello.md",
+		`posts\..\..\.env`:           "../.env",
+		".":                          "",
+		"":                           "",
+	} {
+		if got := SlashPath(in); got != want {
+			t.Errorf("SlashPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIsAbsPath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"posts/hello.md":          false,
+		`posts\hello.md`:          false,
+		"/etc/passwd":             true,
+		`\Windows\win.ini`:        true,
+		`C:\site\posts\hello.md`:  true,
+		"c:posts/hello.md":        true,
+		`\\server\share\hello.md`: true,
+		"posts/20260125:hello.md": false,
+	} {
+		if got := IsAbsPath(p); got != want {
+			t.Errorf("IsAbsPath(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestSiteRelPath(t *testing.T) {
+	dir := t.TempDir()
+	post := filepath.Join(dir, "posts", "20260125", "hello.md")
+	if got := SiteRelPath(dir, post); got != "posts/20260125/hello.md" {
+		t.Errorf("SiteRelPath(absolute) = %q", got)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+	if got := SiteRelPath(dir, filepath.Join("posts", "20260125", "hello.md")); got != "posts/20260125/hello.md" {
+		t.Errorf("SiteRelPath(relative) = %q", got)
+	}
+	if got := SiteRelPath(dir, `posts\20260125\hello.md`); got != "posts/20260125/hello.md" {
+		t.Errorf("SiteRelPath(backslashes) = %q", got)
+	}
+	// Outside the site the argument is kept, as a slash path
+	if got := SiteRelPath(filepath.Join(dir, "posts"), `..\other\hello.md`); got != "../other/hello.md" {
+		t.Errorf("SiteRelPath(outside) = %q", got)
+	}
+}
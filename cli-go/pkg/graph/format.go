@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats.
+const (
+	FormatJSON    = "json"
+	FormatDOT     = "dot"
+	FormatGraphML = "graphml"
+)
+
+// Formats lists the export formats.
+var Formats = []string{FormatJSON, FormatDOT, FormatGraphML}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatDOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	case FormatGraphML:
+		return "application/graphml+xml; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Write writes g to w in format.
+func Write(w io.Writer, g *Graph, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatGraphML:
+		return writeGraphML(w, g)
+	default:
+		return fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// writeDOT writes a Graphviz digraph. Follows are solid, comments dashed,
+// and blessings bold; the pen width grows with the weight.
+func writeDOT(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph polis {\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Label != "" {
+			label = n.Label + "\n" + n.ID
+		}
+		attrs := "label=" + strconv.Quote(label)
+		if n.Self {
+			attrs += ", style=\"rounded,filled\", fillcolor=lightblue"
+		}
+		fmt.Fprintf(&sb, "  %s [%s];\n", strconv.Quote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		style := "solid"
+		switch e.Kind {
+		case EdgeCommented:
+			style = "dashed"
+		case EdgeBlessed:
+			style = "bold"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [label=%s, style=%s, penwidth=%d];\n",
+			strconv.Quote(e.Source), strconv.Quote(e.Target), strconv.Quote(fmt.Sprintf("%s (%d)", e.Kind, e.Weight)), style, min(e.Weight, 5))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// GraphML document structure.
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// writeGraphML writes a GraphML document (readable by Gephi, yEd, Cytoscape,
+// and networkx).
+func writeGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "self", For: "node", AttrName: "self", AttrType: "boolean"},
+			{ID: "following", For: "node", AttrName: "following", AttrType: "boolean"},
+			{ID: "follower", For: "node", AttrName: "follower", AttrType: "boolean"},
+			{ID: "kind", For: "edge", AttrName: "kind", AttrType: "string"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+		},
+	}
+	doc.Graph.ID = "polis"
+	doc.Graph.EdgeDefault = "directed"
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "label", Value: n.Label},
+			{Key: "self", Value: strconv.FormatBool(n.Self)},
+			{Key: "following", Value: strconv.FormatBool(n.Following)},
+			{Key: "follower", Value: strconv.FormatBool(n.Follower)},
+		}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: []graphMLData{
+			{Key: "kind", Value: e.Kind},
+			{Key: "weight", Value: strconv.Itoa(e.Weight)},
+		}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,193 @@
+// Package graph builds this site's local view of the social graph — who we
+// follow, who follows us, and who we have exchanged comments and blessings
+// with — and writes it in formats that graph tools read. Suggest ranks
+// sites to follow by the comments of the authors we follow.
+//
+// Everything comes from local state (the following list, stream state, and
+// comment files); nothing is fetched.
+package graph
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// Edge kinds. Edges point from the actor: "a follows b", "a commented on
+// b's posts", "a blessed b's comments".
+const (
+	EdgeFollows   = "follows"
+	EdgeCommented = "commented"
+	EdgeBlessed   = "blessed"
+)
+
+// Node is a site in the graph, identified by domain.
+type Node struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	Self  bool   `json:"self,omitempty"`
+	// Following and Follower describe the node's relationship to us.
+	Following bool `json:"following,omitempty"`
+	Follower  bool `json:"follower,omitempty"`
+}
+
+// Edge is a directed relationship between two nodes. Weight counts the
+// interactions (1 for follows).
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+	Weight int    `json:"weight"`
+}
+
+// Graph is a snapshot of the local social graph.
+type Graph struct {
+	Self        string `json:"self"`
+	GeneratedAt string `json:"generated_at"`
+	Nodes       []Node `json:"nodes"`
+	Edges       []Edge `json:"edges"`
+}
+
+// builder accumulates nodes and weighted edges.
+type builder struct {
+	nodes map[string]*Node
+	edges map[Edge]int // keyed with Weight 0
+}
+
+func (b *builder) node(domain string) *Node {
+	domain = strings.ToLower(domain)
+	n, ok := b.nodes[domain]
+	if !ok {
+		n = &Node{ID: domain}
+		b.nodes[domain] = n
+	}
+	return n
+}
+
+func (b *builder) edge(source, target, kind string) {
+	source, target = strings.ToLower(source), strings.ToLower(target)
+	if source == "" || target == "" || source == target {
+		return
+	}
+	b.node(source)
+	b.node(target)
+	b.edges[Edge{Source: source, Target: target, Kind: kind}]++
+}
+
+// Build assembles the graph around self (this site's domain).
+func Build(dataDir, discoveryDomain, self string) *Graph {
+	self = strings.ToLower(self)
+	b := &builder{nodes: make(map[string]*Node), edges: make(map[Edge]int)}
+	b.node(self).Self = true
+
+	// Follows in both directions
+	if f, err := following.Load(following.DefaultPath(dataDir)); err == nil {
+		for _, entry := range f.All() {
+			domain := discovery.ExtractDomainFromURL(entry.URL)
+			if domain == "" {
+				continue
+			}
+			n := b.node(domain)
+			n.Following = true
+			if n.Label == "" {
+				n.Label = entry.SiteTitle
+			}
+			b.edge(self, domain, EdgeFollows)
+		}
+	}
+	store := stream.NewStore(dataDir, discoveryDomain)
+	var followers stream.FollowerState
+	_ = store.LoadState("polis.follow", &followers)
+	for _, domain := range followers.Followers {
+		b.node(domain).Follower = true
+		b.edge(domain, self, EdgeFollows)
+	}
+	for domain, title := range author.LoadFollowerProfiles(dataDir, discoveryDomain).SiteTitles() {
+		if n, ok := b.nodes[domain]; ok && n.Label == "" {
+			n.Label = title
+		}
+	}
+
+	// Their comments on our posts, and our blessings of them: stream
+	// blessing state, plus the blessed index for grants that predate it
+	seen := make(map[string]bool)
+	var blessings stream.BlessingState
+	_ = store.LoadState("polis.blessing", &blessings)
+	for _, entry := range blessings.Blessings {
+		domain := discovery.ExtractDomainFromURL(entry.SourceURL)
+		if domain == "" || seen[entry.SourceURL] {
+			continue
+		}
+		seen[entry.SourceURL] = true
+		b.edge(domain, self, EdgeCommented)
+		if entry.Status == "granted" {
+			b.edge(self, domain, EdgeBlessed)
+		}
+	}
+	if bc, err := metadata.LoadBlessedComments(dataDir); err == nil {
+		for _, pc := range bc.Comments {
+			for _, c := range pc.Blessed {
+				domain := discovery.ExtractDomainFromURL(c.URL)
+				if domain == "" || seen[c.URL] {
+					continue
+				}
+				seen[c.URL] = true
+				b.edge(domain, self, EdgeCommented)
+				b.edge(self, domain, EdgeBlessed)
+			}
+		}
+	}
+
+	// Our comments on their posts, and their blessings of ours
+	for _, status := range []string{comment.StatusPending, comment.StatusBlessed, comment.StatusDenied} {
+		metas, err := comment.ListComments(dataDir, status)
+		if err != nil {
+			continue
+		}
+		for _, m := range metas {
+			domain := discovery.ExtractDomainFromURL(m.InReplyTo)
+			b.edge(self, domain, EdgeCommented)
+			if status == comment.StatusBlessed {
+				b.edge(domain, self, EdgeBlessed)
+			}
+		}
+	}
+
+	g := &Graph{
+		Self:        self,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Nodes:       make([]Node, 0, len(b.nodes)),
+		Edges:       make([]Edge, 0, len(b.edges)),
+	}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	for e, weight := range b.edges {
+		e.Weight = weight
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Self != g.Nodes[j].Self {
+			return g.Nodes[i].Self
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Kind < b.Kind
+	})
+	return g
+}
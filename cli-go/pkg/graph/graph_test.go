@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	following.Save(following.DefaultPath(dir), &following.FollowingFile{Following: []following.FollowingEntry{
+		{URL: "https://alice.example.com", SiteTitle: "Alice Writes"},
+	}})
+	store := stream.NewStore(dir, "ds.example.com")
+	store.SaveState("polis.follow", stream.FollowerState{Followers: []string{"alice.example.com", "bob.example.com"}})
+	store.SaveState("polis.blessing", stream.BlessingState{Blessings: []stream.BlessingEntry{
+		{SourceURL: "https://bob.example.com/comments/20260301/a.md", Status: "granted"},
+		{SourceURL: "https://bob.example.com/comments/20260302/b.md", Status: "granted"},
+		{SourceURL: "https://carol.example.com/comments/20260302/c.md", Status: "denied"},
+	}})
+
+	g := Build(dir, "ds.example.com", "me.example.com")
+
+	if len(g.Nodes) != 4 || !g.Nodes[0].Self || g.Nodes[0].ID != "me.example.com" {
+		t.Fatalf("unexpected nodes: %+v", g.Nodes)
+	}
+	alice := g.Nodes[1]
+	if alice.Label != "Alice Writes" || !alice.Following || !alice.Follower {
+		t.Errorf("alice = %+v, want mutual follow with site title", alice)
+	}
+
+	edges := make(map[string]int)
+	for _, e := range g.Edges {
+		edges[e.Source+" "+e.Kind+" "+e.Target] = e.Weight
+	}
+	want := map[string]int{
+		"me.example.com follows alice.example.com":   1,
+		"alice.example.com follows me.example.com":   1,
+		"bob.example.com follows me.example.com":     1,
+		"bob.example.com commented me.example.com":   2,
+		"me.example.com blessed bob.example.com":     2,
+		"carol.example.com commented me.example.com": 1,
+	}
+	if len(edges) != len(want) {
+		t.Errorf("edges = %v, want %v", edges, want)
+	}
+	for k, w := range want {
+		if edges[k] != w {
+			t.Errorf("edge %q weight = %d, want %d", k, edges[k], w)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := &Graph{
+		Self:  "me.example.com",
+		Nodes: []Node{{ID: "me.example.com", Self: true}, {ID: "alice.example.com", Label: `Alice "A"`}},
+		Edges: []Edge{{Source: "me.example.com", Target: "alice.example.com", Kind: EdgeFollows, Weight: 1}},
+	}
+
+	var dot bytes.Buffer
+	if err := Write(&dot, g, FormatDOT); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"me.example.com" -> "alice.example.com"`) || !strings.Contains(dot.String(), `Alice \"A\"`) {
+		t.Errorf("unexpected DOT:\n%s", dot.String())
+	}
+
+	var gml bytes.Buffer
+	if err := Write(&gml, g, FormatGraphML); err != nil {
+		t.Fatal(err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal(gml.Bytes(), &doc); err != nil {
+		t.Fatalf("GraphML doesn't parse: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 || doc.Graph.EdgeDefault != "directed" {
+		t.Errorf("unexpected GraphML: %+v", doc.Graph)
+	}
+
+	if err := Write(&bytes.Buffer{}, g, "csv"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	dir := t.TempDir()
+	following.Save(following.DefaultPath(dir), &following.FollowingFile{Following: []following.FollowingEntry{
+		{URL: "https://alice.example.com"},
+		{URL: "https://bob.example.com"},
+	}})
+	now := time.Now().UTC().Format(time.RFC3339)
+	comment := func(author, n, target string) feed.FeedItem {
+		return feed.FeedItem{
+			Type:         "comment",
+			URL:          "https://" + author + "/comments/" + n + ".md",
+			Published:    now,
+			AuthorURL:    "https://" + author,
+			AuthorDomain: author,
+			TargetURL:    "https://" + target + "/posts/p.md",
+		}
+	}
+	cm := feed.NewCacheManager(dir, "ds.example.com")
+	if _, err := cm.MergeItems([]feed.FeedItem{
+		comment("alice.example.com", "1", "carol.example.com"),
+		comment("alice.example.com", "2", "dave.example.com"),
+		comment("alice.example.com", "3", "dave.example.com"),
+		comment("bob.example.com", "4", "carol.example.com"),
+		comment("bob.example.com", "5", "alice.example.com"), // Already followed
+		comment("bob.example.com", "6", "me.example.com"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Suggest(dir, "ds.example.com", "me.example.com", 0)
+	if len(got) != 2 {
+		t.Fatalf("suggestions = %+v, want carol and dave", got)
+	}
+	if got[0].Domain != "carol.example.com" || got[0].Reason != "alice.example.com and bob.example.com commented on their posts" {
+		t.Errorf("first = %+v, want carol, commented on by both", got[0])
+	}
+	if got[1].Domain != "dave.example.com" || got[1].URL != "https://dave.example.com" || got[1].Reason != "alice.example.com commented on their posts 2 times" {
+		t.Errorf("second = %+v", got[1])
+	}
+	if got := Suggest(dir, "ds.example.com", "me.example.com", 1); len(got) != 1 {
+		t.Errorf("limit 1 returned %d suggestions", len(got))
+	}
+}
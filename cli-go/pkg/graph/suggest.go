@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+)
+
+// Suggestion is a site to follow: one whose posts the authors we follow
+// comment on.
+type Suggestion struct {
+	Domain   string   `json:"domain"`
+	URL      string   `json:"url"`
+	Via      []string `json:"via"`      // Followed domains that commented, most comments first
+	Comments int      `json:"comments"` // Their comments on the site's posts
+	Reason   string   `json:"reason"`
+}
+
+// Suggest ranks the sites that the authors we follow comment on, from the
+// comments in the feed cache. Sites commented on by more of them come
+// first, then those with more comments. Sites already followed and self
+// are left out. A limit of 0 returns every suggestion.
+func Suggest(dataDir, discoveryDomain, self string, limit int) []Suggestion {
+	followed := map[string]bool{strings.ToLower(self): true}
+	if f, err := following.Load(following.DefaultPath(dataDir)); err == nil {
+		for _, entry := range f.All() {
+			if d := discovery.ExtractDomainFromURL(entry.URL); d != "" {
+				followed[strings.ToLower(d)] = true
+			}
+		}
+	}
+
+	items, err := feed.NewCacheManager(dataDir, discoveryDomain).ListByType("comment")
+	if err != nil {
+		return []Suggestion{}
+	}
+	counts := make(map[string]map[string]int) // Target -> commenting followed domain -> comments
+	for _, item := range items {
+		author := strings.ToLower(item.AuthorDomain)
+		target := strings.ToLower(item.TargetDomain)
+		if target == "" {
+			target = strings.ToLower(discovery.ExtractDomainFromURL(item.TargetURL))
+		}
+		if target == "" || !followed[author] || followed[target] {
+			continue
+		}
+		if counts[target] == nil {
+			counts[target] = make(map[string]int)
+		}
+		counts[target][author]++
+	}
+
+	suggestions := make([]Suggestion, 0, len(counts))
+	for target, by := range counts {
+		s := Suggestion{Domain: target, URL: "https://" + target}
+		for domain, n := range by {
+			s.Via = append(s.Via, domain)
+			s.Comments += n
+		}
+		sort.Slice(s.Via, func(i, j int) bool {
+			if by[s.Via[i]] != by[s.Via[j]] {
+				return by[s.Via[i]] > by[s.Via[j]]
+			}
+			return s.Via[i] < s.Via[j]
+		})
+		s.Reason = reason(s.Via, s.Comments)
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if len(a.Via) != len(b.Via) {
+			return len(a.Via) > len(b.Via)
+		}
+		if a.Comments != b.Comments {
+			return a.Comments > b.Comments
+		}
+		return a.Domain < b.Domain
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// reason explains a suggestion, naming up to two of the followed domains.
+func reason(via []string, comments int) string {
+	switch {
+	case len(via) == 1 && comments == 1:
+		return fmt.Sprintf("%s commented on one of their posts", via[0])
+	case len(via) == 1:
+		return fmt.Sprintf("%s commented on their posts %d times", via[0], comments)
+	case len(via) == 2:
+		return fmt.Sprintf("%s and %s commented on their posts", via[0], via[1])
+	case len(via) == 3:
+		return fmt.Sprintf("%s, %s, and 1 other you follow commented on their posts", via[0], via[1])
+	default:
+		return fmt.Sprintf("%s, %s, and %d others you follow commented on their posts", via[0], via[1], len(via)-2)
+	}
+}
@@ -22,6 +22,9 @@ const (
 	// This includes explicit grant, blessing sync, and auto-bless on beseech.
 	// The hook typically re-renders the post page to include the new comment.
 	EventPostComment HookEvent = "post-comment"
+	// EventScheduled is passed to scripts run by a scheduled automation
+	// (see pkg/schedule). Path holds the job ID.
+	EventScheduled HookEvent = "scheduled"
 )
 
 // HookConfig contains paths to hook scripts.
@@ -78,6 +81,14 @@ func RunHook(siteDir string, config *HookConfig, payload *HookPayload) (*HookRes
 		return &HookResult{Executed: false}, nil
 	}
 
+	return RunScript(siteDir, hookPath, payload)
+}
+
+// RunScript executes the script at scriptPath (relative to siteDir unless
+// absolute) with the hook environment and payload.
+func RunScript(siteDir, scriptPath string, payload *HookPayload) (*HookResult, error) {
+	hookPath := scriptPath
+
 	// Resolve relative paths from site root
 	if !filepath.IsAbs(hookPath) {
 		hookPath = filepath.Join(siteDir, hookPath)
@@ -0,0 +1,252 @@
+// Package i18n translates the messages polis shows people: CLI errors and
+// the webapp's API error responses.
+//
+// Messages are written in English in the source, and the English text is
+// the lookup key, gettext style. A translation is a JSON file named for its
+// language tag (fr.json, pt-BR.json) mapping English messages to
+// translations:
+//
+//	{"Post not found": "Billet introuvable", "Unknown command: %s": "Commande inconnue : %s"}
+//
+// A translated format string must keep the original's verbs in order.
+// Files are loaded from translation directories (the site's
+// .polis/translations, ~/.polis/translations, or the translations_dir
+// setting); English needs none.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the source language, used when nothing better matches.
+const Default = "en"
+
+// DirName is the translations directory under .polis, in a site and in the
+// user's home directory.
+const DirName = "translations"
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]map[string]string{} // By lowercased language tag
+)
+
+// LoadDir loads every <lang>.json in dir. A missing directory is not an
+// error. Files loaded later add to, and override, earlier ones for the
+// same language.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(dir, name), err)
+		}
+		Add(strings.TrimSuffix(name, ".json"), messages)
+	}
+	return nil
+}
+
+// Dirs returns the translation directories for a site, lowest precedence
+// first: ~/.polis/translations, <site>/.polis/translations, then extra if
+// set (the translations_dir setting).
+func Dirs(dataDir, extra string) []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".polis", DirName))
+	}
+	if dataDir != "" {
+		dirs = append(dirs, filepath.Join(dataDir, ".polis", DirName))
+	}
+	if extra != "" {
+		dirs = append(dirs, extra)
+	}
+	return dirs
+}
+
+// LoadDirs loads each directory in turn, stopping at the first error.
+func LoadDirs(dirs []string) error {
+	for _, dir := range dirs {
+		if err := LoadDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add registers translations for a language, replacing any already
+// registered for the same messages.
+func Add(lang string, messages map[string]string) {
+	key := strings.ToLower(Canonical(lang))
+	mu.Lock()
+	defer mu.Unlock()
+	c := catalogs[key]
+	if c == nil {
+		c = make(map[string]string, len(messages))
+		catalogs[key] = c
+	}
+	for k, v := range messages {
+		c[k] = v
+	}
+}
+
+// Reset drops every loaded translation.
+func Reset() {
+	mu.Lock()
+	catalogs = map[string]map[string]string{}
+	mu.Unlock()
+}
+
+// Languages returns the languages with translations loaded, plus Default,
+// sorted.
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	langs := []string{Default}
+	for lang := range catalogs {
+		if lang != Default {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// T returns msg in lang: the exact translation if there is one, else the
+// base language's (fr for fr-CA), else msg unchanged.
+func T(lang, msg string) string {
+	if lang == "" || msg == "" {
+		return msg
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for tag := strings.ToLower(Canonical(lang)); tag != ""; tag = parent(tag) {
+		if tr, ok := catalogs[tag][msg]; ok && tr != "" {
+			return tr
+		}
+	}
+	return msg
+}
+
+// Sprintf formats args with the translation of format.
+func Sprintf(lang, format string, args ...interface{}) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Message translates a message that may carry detail after its first
+// ": " (as in "Failed to save post: permission denied"): the whole message
+// if it has a translation, else just the part before the colon.
+func Message(lang, msg string) string {
+	if tr := T(lang, msg); tr != msg {
+		return tr
+	}
+	if head, detail, ok := strings.Cut(msg, ": "); ok {
+		if tr := T(lang, head); tr != head {
+			return tr + ": " + detail
+		}
+	}
+	return msg
+}
+
+// parent returns the tag with its last subtag removed ("" for a bare
+// language).
+func parent(tag string) string {
+	if i := strings.LastIndexByte(tag, '-'); i > 0 {
+		return tag[:i]
+	}
+	return ""
+}
+
+// Canonical normalizes a language tag or POSIX locale name: "fr_CA.UTF-8"
+// becomes "fr-CA". "C" and "POSIX" mean Default.
+func Canonical(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag == "C" || tag == "POSIX" {
+		return Default
+	}
+	parts := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// FromEnv returns the language the environment asks for: setting (the
+// locale setting) if non-empty, else LC_ALL, LC_MESSAGES, or LANG.
+func FromEnv(setting string) string {
+	if setting != "" {
+		return Canonical(setting)
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return Canonical(v)
+		}
+	}
+	return Default
+}
+
+// Negotiate picks the best loaded language for an Accept-Language header,
+// falling back to Default. A request for fr-CA is served fr if that is all
+// there is.
+func Negotiate(acceptLanguage string) string {
+	type choice struct {
+		tag string
+		q   float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{Canonical(tag), q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, c := range choices {
+		for tag := strings.ToLower(c.tag); tag != ""; tag = parent(tag) {
+			if tag == Default {
+				return Default
+			}
+			if _, ok := catalogs[tag]; ok {
+				return c.tag
+			}
+		}
+	}
+	return Default
+}
@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestT_FallsBackThroughBaseLanguage(t *testing.T) {
+	Reset()
+	defer Reset()
+	Add("fr", map[string]string{"Post not found": "Billet introuvable"})
+	Add("fr-CA", map[string]string{"Draft not found": "Brouillon introuvable (CA)"})
+
+	tests := []struct {
+		lang, msg, want string
+	}{
+		{"fr", "Post not found", "Billet introuvable"},
+		{"fr-CA", "Post not found", "Billet introuvable"},
+		{"fr_CA.UTF-8", "Draft not found", "Brouillon introuvable (CA)"},
+		{"fr", "Draft not found", "Draft not found"},
+		{"de", "Post not found", "Post not found"},
+		{"", "Post not found", "Post not found"},
+	}
+	for _, tt := range tests {
+		if got := T(tt.lang, tt.msg); got != tt.want {
+			t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestMessage_TranslatesPrefixBeforeDetail(t *testing.T) {
+	Reset()
+	defer Reset()
+	Add("fr", map[string]string{"Failed to save post": "Échec de l'enregistrement"})
+
+	got := Message("fr", "Failed to save post: permission denied")
+	if want := "Échec de l'enregistrement: permission denied"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := Sprintf("fr", "%s", "x"); got != "x" {
+		t.Errorf("Sprintf with an untranslated format = %q", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	Reset()
+	defer Reset()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "pt-BR.json"), []byte(`{"Unknown command: %s": "Comando desconhecido: %s"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	if err := LoadDirs([]string{filepath.Join(dir, "missing"), dir}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Sprintf("pt-br", "Unknown command: %s", "foo"); got != "Comando desconhecido: foo" {
+		t.Errorf("got %q", got)
+	}
+	if langs := Languages(); len(langs) != 2 || langs[0] != "en" || langs[1] != "pt-br" {
+		t.Errorf("Languages() = %v", langs)
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{`), 0644)
+	if err := LoadDir(dir); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	Reset()
+	defer Reset()
+	Add("fr", map[string]string{"x": "y"})
+	Add("de", map[string]string{"x": "z"})
+
+	tests := []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr-CA"},
+		{"es, de;q=0.5", "de"},
+		{"en-US,en;q=0.9,fr;q=0.8", "en"},
+		{"de;q=0.2, fr;q=0.7", "fr"},
+		{"fr;q=0, es", "en"},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	if got := FromEnv("fr"); got != "fr" {
+		t.Errorf("setting should win, got %q", got)
+	}
+	if got := FromEnv(""); got != "de-DE" {
+		t.Errorf("expected LANG, got %q", got)
+	}
+	t.Setenv("LANG", "C")
+	if got := FromEnv(""); got != "en" {
+		t.Errorf("expected en for the C locale, got %q", got)
+	}
+}
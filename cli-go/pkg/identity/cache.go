@@ -0,0 +1,118 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// CacheTTL is how long a site's checked proofs are trusted before they are
+// checked again.
+const CacheTTL = 24 * time.Hour
+
+// Cache holds the last check of other sites' proofs, keyed by domain. It
+// backs the verified badges shown in the feed.
+type Cache struct {
+	Sites map[string]CacheEntry `json:"sites"`
+}
+
+// CacheEntry is the last check of one site.
+type CacheEntry struct {
+	Checked string   `json:"checked"`
+	Results []Result `json:"results,omitempty"`
+	Error   string   `json:"error,omitempty"` // The site's .well-known/polis couldn't be read
+}
+
+// CachePath returns the path of the identity cache.
+func CachePath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "identities.json")
+}
+
+// LoadCache reads the identity cache, returning an empty cache if it does
+// not exist.
+func LoadCache(dataDir string) (*Cache, error) {
+	c := &Cache{Sites: make(map[string]CacheEntry)}
+	data, err := os.ReadFile(CachePath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to read identities.json: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse identities.json: %w", err)
+	}
+	if c.Sites == nil {
+		c.Sites = make(map[string]CacheEntry)
+	}
+	return c, nil
+}
+
+// SaveCache writes the identity cache.
+func SaveCache(dataDir string, c *Cache) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal identities.json: %w", err)
+	}
+	path := CachePath(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return fsutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Record stores the outcome of checking a site.
+func (c *Cache) Record(domain string, results []Result, err error) {
+	e := CacheEntry{Checked: time.Now().UTC().Format(time.RFC3339), Results: results}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	c.Sites[domain] = e
+}
+
+// Stale reports whether a site hasn't been checked within CacheTTL.
+func (c *Cache) Stale(domain string) bool {
+	e, ok := c.Sites[domain]
+	if !ok {
+		return true
+	}
+	checked, err := time.Parse(time.RFC3339, e.Checked)
+	return err != nil || time.Since(checked) > CacheTTL
+}
+
+// Verified returns a site's verified proofs from the last check.
+func (c *Cache) Verified(domain string) []Result {
+	var verified []Result
+	for _, r := range c.Sites[domain].Results {
+		if r.Verified {
+			verified = append(verified, r)
+		}
+	}
+	return verified
+}
+
+// Refresh checks the proofs of each domain not checked within CacheTTL and
+// saves the cache. It returns how many sites were checked.
+func Refresh(dataDir string, domains []string) (int, error) {
+	c, err := LoadCache(dataDir)
+	if err != nil {
+		return 0, err
+	}
+	checked := 0
+	for _, d := range domains {
+		if !c.Stale(d) {
+			continue
+		}
+		results, err := Verify(d)
+		c.Record(d, results, err)
+		checked++
+	}
+	if checked == 0 {
+		return 0, nil
+	}
+	return checked, SaveCache(dataDir, c)
+}
@@ -0,0 +1,330 @@
+// Package identity links a polis site to accounts elsewhere with signed
+// proofs, in the style of Keybase.
+//
+// A proof is a short statement naming the site's domain, its public key, and
+// an account on another service, signed with the site key and listed in
+// .well-known/polis. The author then posts the proof's token where only the
+// account holder could: a public GitHub gist, a DNS TXT record, or a profile
+// page. Anyone can verify the link in both directions: the signature shows
+// the site's author made the claim, and the token shows the account holder
+// agrees.
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// Services proofs can be made for.
+const (
+	ServiceGitHub = "github" // A public gist owned by the account
+	ServiceDNS    = "dns"    // A TXT record at _polis.<name>
+	ServiceURL    = "url"    // Any https page, such as a fediverse profile
+)
+
+// Services lists the supported services.
+var Services = []string{ServiceGitHub, ServiceDNS, ServiceURL}
+
+// TokenPrefix starts every proof token.
+const TokenPrefix = "polis-proof="
+
+// DNSPrefix is the label under which DNS proofs are published.
+const DNSPrefix = "_polis."
+
+// Result is the outcome of checking one proof.
+type Result struct {
+	Service  string `json:"service"`
+	Account  string `json:"account"`
+	URL      string `json:"url,omitempty"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Lookups used to check where proofs are posted; replaced in tests.
+var (
+	lookupTXT = net.LookupTXT
+	fetch     = func(u string) (string, error) { return remote.NewClient().FetchContent(u) }
+)
+
+var (
+	githubUser = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+	gistURL    = regexp.MustCompile(`^https://gist\.github\.com/([A-Za-z0-9-]+)/([0-9a-f]+)/?$`)
+)
+
+// NormalizeAccount validates an account name for a service and returns its
+// canonical form. For dns an empty account means the site's own domain.
+func NormalizeAccount(service, account, domain string) (string, error) {
+	switch service {
+	case ServiceGitHub:
+		account = strings.TrimPrefix(strings.TrimSpace(account), "@")
+		if !githubUser.MatchString(account) {
+			return "", fmt.Errorf("invalid GitHub username %q", account)
+		}
+		return strings.ToLower(account), nil
+	case ServiceDNS:
+		if account == "" {
+			account = domain
+		}
+		account = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(account), "."))
+		if account == "" || strings.ContainsAny(account, "/: ") {
+			return "", fmt.Errorf("invalid domain name %q", account)
+		}
+		return account, nil
+	case ServiceURL:
+		u, err := url.Parse(strings.TrimSpace(account))
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("profile must be an https URL, got %q", account)
+		}
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("unknown service %q (use %s)", service, strings.Join(Services, ", "))
+}
+
+// Statement returns the text signed for a proof.
+func Statement(domain, publicKey, service, account, created string) string {
+	return fmt.Sprintf("I am %s on %s, and I publish the polis site %s.\n\n"+
+		"domain: %s\nservice: %s\naccount: %s\npublic_key: %s\ncreated: %s\n",
+		account, service, domain, domain, service, account, publicKey, created)
+}
+
+// Token returns the string posted on the other service to accept a proof.
+// It commits to the signed statement, so it can't be reused for another
+// site or key.
+func Token(p site.IdentityProof) string {
+	sum := sha256.Sum256([]byte(p.Statement + p.Signature))
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Text returns the full proof, suitable for a gist or a profile page.
+func Text(p site.IdentityProof) string {
+	return p.Statement + "\n" + p.Signature + "\n" + Token(p) + "\n"
+}
+
+// Location returns where the token of a proof is looked for: a URL, or a
+// DNS name for dns proofs. It is empty for a GitHub proof whose gist isn't
+// known yet.
+func Location(p site.IdentityProof) string {
+	switch p.Service {
+	case ServiceGitHub:
+		m := gistURL.FindStringSubmatch(p.URL)
+		if m == nil {
+			return ""
+		}
+		return "https://gist.githubusercontent.com/" + m[1] + "/" + m[2] + "/raw"
+	case ServiceDNS:
+		return DNSPrefix + p.Account
+	}
+	return p.Account
+}
+
+// Prove signs a proof linking the site at domain to an account and records
+// it in .well-known/polis, replacing any earlier proof for the same account.
+// domain defaults to the one in .well-known/polis. A proof signed with the
+// current key is kept, so its token stays the same; postURL, when set,
+// records where a GitHub proof was posted.
+func Prove(dataDir string, privateKey []byte, domain, service, account, postURL string) (*site.IdentityProof, error) {
+	wk, err := site.LoadWellKnown(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .well-known/polis: %w", err)
+	}
+	if domain == "" {
+		domain = wk.AuthorDomain()
+	}
+	if domain == "" {
+		return nil, errors.New("no site domain (set base_url)")
+	}
+	account, err = NormalizeAccount(service, account, domain)
+	if err != nil {
+		return nil, err
+	}
+	if postURL != "" {
+		if service != ServiceGitHub {
+			return nil, fmt.Errorf("--url only applies to github proofs")
+		}
+		m := gistURL.FindStringSubmatch(postURL)
+		if m == nil || !strings.EqualFold(m[1], account) {
+			return nil, fmt.Errorf("%s is not a gist owned by %s", postURL, account)
+		}
+	}
+
+	idx := find(wk.Proofs, service, account)
+	var p site.IdentityProof
+	if idx >= 0 && checkSigned(wk, wk.Proofs[idx], domain) == nil {
+		p = wk.Proofs[idx]
+	} else {
+		created := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+		statement := Statement(domain, strings.TrimSpace(wk.PublicKey), service, account, created)
+		sig, err := signing.SignContent([]byte(statement), privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign proof: %w", err)
+		}
+		p = site.IdentityProof{
+			Service:   service,
+			Account:   account,
+			Statement: statement,
+			Signature: sig,
+			Created:   created,
+		}
+	}
+	if postURL != "" {
+		p.URL = postURL
+	}
+
+	if idx >= 0 {
+		wk.Proofs[idx] = p
+	} else {
+		wk.Proofs = append(wk.Proofs, p)
+	}
+	if err := site.SaveWellKnown(dataDir, wk); err != nil {
+		return nil, fmt.Errorf("failed to save .well-known/polis: %w", err)
+	}
+	return &p, nil
+}
+
+// Remove deletes a proof from .well-known/polis. domain is the site's
+// domain, the default account of a dns proof.
+func Remove(dataDir, domain, service, account string) error {
+	wk, err := site.LoadWellKnown(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to read .well-known/polis: %w", err)
+	}
+	if domain == "" {
+		domain = wk.AuthorDomain()
+	}
+	account, err = NormalizeAccount(service, account, domain)
+	if err != nil {
+		return err
+	}
+	idx := find(wk.Proofs, service, account)
+	if idx < 0 {
+		return fmt.Errorf("no %s proof for %s", service, account)
+	}
+	wk.Proofs = append(wk.Proofs[:idx], wk.Proofs[idx+1:]...)
+	return site.SaveWellKnown(dataDir, wk)
+}
+
+// Verify fetches a site's .well-known/polis and checks each of its proofs.
+// domain may be a bare domain or a site URL.
+func Verify(domain string) ([]Result, error) {
+	baseURL := strings.TrimSuffix(domain, "/")
+	if !strings.HasPrefix(baseURL, "https://") && !strings.HasPrefix(baseURL, "http://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+	content, err := fetch(baseURL + "/.well-known/polis")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch .well-known/polis: %w", err)
+	}
+	var wk site.WellKnown
+	if err := json.Unmarshal([]byte(content), &wk); err != nil {
+		return nil, fmt.Errorf("failed to parse .well-known/polis: %w", err)
+	}
+	return CheckAll(&wk, u.Host), nil
+}
+
+// CheckAll checks every proof in wk, for a site served at domain.
+func CheckAll(wk *site.WellKnown, domain string) []Result {
+	results := make([]Result, 0, len(wk.Proofs))
+	for _, p := range wk.Proofs {
+		r := Result{Service: p.Service, Account: p.Account, URL: p.URL}
+		if err := Check(wk, p, domain); err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Verified = true
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Check verifies one proof: its signature and statement against the site,
+// then its token where it is posted.
+func Check(wk *site.WellKnown, p site.IdentityProof, domain string) error {
+	if err := checkSigned(wk, p, domain); err != nil {
+		return err
+	}
+	return CheckPosted(p)
+}
+
+// CheckPosted looks for a proof's token where it is posted.
+func CheckPosted(p site.IdentityProof) error {
+	loc := Location(p)
+	if loc == "" {
+		return errors.New("no gist recorded (run polis identity prove github <user> --url <gist>)")
+	}
+	token := Token(p)
+	if p.Service == ServiceDNS {
+		records, err := lookupTXT(loc)
+		if err != nil {
+			return fmt.Errorf("DNS lookup of %s failed: %w", loc, err)
+		}
+		for _, r := range records {
+			if strings.TrimSpace(r) == token {
+				return nil
+			}
+		}
+		return fmt.Errorf("no matching TXT record at %s", loc)
+	}
+	body, err := fetch(loc)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(body, token) {
+		return fmt.Errorf("proof token not found at %s", loc)
+	}
+	return nil
+}
+
+// checkSigned verifies a proof's signature with the site key and that its
+// statement names this site, this key, and this account.
+func checkSigned(wk *site.WellKnown, p site.IdentityProof, domain string) error {
+	key := strings.TrimSpace(wk.PublicKey)
+	ok, err := signing.VerifySignature([]byte(p.Statement), []byte(key), p.Signature)
+	if err != nil || !ok {
+		return errors.New("signature does not match the site's key")
+	}
+	fields := statementFields(p.Statement)
+	switch {
+	case !strings.EqualFold(fields["domain"], domain):
+		return fmt.Errorf("proof is for %s, not %s", fields["domain"], domain)
+	case fields["public_key"] != key:
+		return errors.New("proof was signed for a different key")
+	case fields["service"] != p.Service || fields["account"] != p.Account:
+		return errors.New("statement does not name this account")
+	}
+	return nil
+}
+
+func statementFields(statement string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(statement, "\n") {
+		if k, v, ok := strings.Cut(line, ": "); ok && !strings.Contains(k, " ") {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+func find(proofs []site.IdentityProof, service, account string) int {
+	for i, p := range proofs {
+		if p.Service == service && p.Account == account {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,194 @@
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func newSite(t *testing.T) (string, []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	priv, pub, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk := &site.WellKnown{Domain: "alice.example.com", PublicKey: strings.TrimSpace(string(pub))}
+	if err := site.SaveWellKnown(dir, wk); err != nil {
+		t.Fatal(err)
+	}
+	return dir, priv
+}
+
+// stub serves pages and TXT records from maps for the duration of a test.
+func stub(t *testing.T, pages map[string]string, txt map[string][]string) {
+	t.Helper()
+	oldFetch, oldLookup := fetch, lookupTXT
+	fetch = func(u string) (string, error) {
+		body, ok := pages[u]
+		if !ok {
+			return "", errors.New("fetch failed with status 404 for " + u)
+		}
+		return body, nil
+	}
+	lookupTXT = func(name string) ([]string, error) {
+		return txt[name], nil
+	}
+	t.Cleanup(func() { fetch, lookupTXT = oldFetch, oldLookup })
+}
+
+func TestProveAndVerify(t *testing.T) {
+	dir, priv := newSite(t)
+
+	gh, err := Prove(dir, priv, "", ServiceGitHub, "@Alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gh.Account != "alice" || !strings.Contains(gh.Statement, "domain: alice.example.com") {
+		t.Fatalf("unexpected proof: %+v", gh)
+	}
+	// Proving again with the gist keeps the signed statement, so the
+	// token already posted stays valid
+	gh2, err := Prove(dir, priv, "", ServiceGitHub, "alice", "https://gist.github.com/alice/0123abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Token(*gh2) != Token(*gh) {
+		t.Error("re-proving changed the token")
+	}
+	if _, err := Prove(dir, priv, "", ServiceGitHub, "alice", "https://gist.github.com/mallory/0123abcd"); err == nil {
+		t.Error("accepted a gist owned by another account")
+	}
+	dns, err := Prove(dir, priv, "", ServiceDNS, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dns.Account != "alice.example.com" {
+		t.Errorf("dns account = %q, want the site's domain", dns.Account)
+	}
+	profile, err := Prove(dir, priv, "", ServiceURL, "https://social.example/@alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wk, err := site.LoadWellKnown(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wk.Proofs) != 3 {
+		t.Fatalf("got %d proofs, want 3", len(wk.Proofs))
+	}
+	data, _ := json.Marshal(wk)
+	stub(t, map[string]string{
+		"https://alice.example.com/.well-known/polis":           string(data),
+		"https://gist.githubusercontent.com/alice/0123abcd/raw": Text(*gh2),
+		"https://social.example/@alice":                         "<p>" + Token(*profile) + "</p>",
+	}, map[string][]string{
+		"_polis.alice.example.com": {"v=spf1 -all", Token(*dns)},
+	})
+
+	results, err := Verify("alice.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !r.Verified {
+			t.Errorf("%s %s not verified: %s", r.Service, r.Account, r.Error)
+		}
+	}
+
+	// Served from another domain, the proofs don't hold
+	stub(t, map[string]string{"https://mallory.example.com/.well-known/polis": string(data)}, nil)
+	results, err = Verify("https://mallory.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Verified || !strings.Contains(r.Error, "not mallory.example.com") {
+			t.Errorf("%s %s: verified=%v error=%q", r.Service, r.Account, r.Verified, r.Error)
+		}
+	}
+}
+
+func TestCheck_Tampered(t *testing.T) {
+	dir, priv := newSite(t)
+	p, err := Prove(dir, priv, "", ServiceDNS, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk, _ := site.LoadWellKnown(dir)
+	stub(t, nil, map[string][]string{"_polis.alice.example.com": {Token(*p)}})
+
+	if err := Check(wk, *p, "alice.example.com"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+
+	forged := *p
+	forged.Account = "bob.example.com"
+	if err := Check(wk, forged, "alice.example.com"); err == nil {
+		t.Error("accepted a proof whose statement names another account")
+	}
+
+	// A proof signed before a key rotation no longer holds
+	_, pub, _ := signing.GenerateKeypair()
+	rotated := *wk
+	rotated.PublicKey = strings.TrimSpace(string(pub))
+	if err := Check(&rotated, *p, "alice.example.com"); err == nil {
+		t.Error("accepted a proof signed with an old key")
+	}
+
+	stub(t, nil, nil)
+	if err := Check(wk, *p, "alice.example.com"); err == nil {
+		t.Error("accepted a proof whose TXT record is missing")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir, priv := newSite(t)
+	if _, err := Prove(dir, priv, "", ServiceGitHub, "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(dir, "", ServiceGitHub, "Alice"); err != nil {
+		t.Fatal(err)
+	}
+	wk, _ := site.LoadWellKnown(dir)
+	if len(wk.Proofs) != 0 {
+		t.Errorf("proof not removed: %+v", wk.Proofs)
+	}
+	if err := Remove(dir, "", ServiceGitHub, "alice"); err == nil {
+		t.Error("removing a missing proof succeeded")
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := LoadCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Stale("bob.example.com") {
+		t.Error("unchecked site is not stale")
+	}
+	c.Record("bob.example.com", []Result{
+		{Service: ServiceGitHub, Account: "bob", Verified: true},
+		{Service: ServiceDNS, Account: "bob.example.com", Error: "no matching TXT record"},
+	}, nil)
+	if err := SaveCache(dir, c); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = LoadCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Stale("bob.example.com") {
+		t.Error("just-checked site is stale")
+	}
+	if v := c.Verified("bob.example.com"); len(v) != 1 || v[0].Account != "bob" {
+		t.Errorf("Verified = %+v", v)
+	}
+}
@@ -0,0 +1,167 @@
+// Package idgen generates identifiers for drafts and comments.
+//
+// IDs are ULIDs (https://github.com/ulid/spec): 26 characters of Crockford
+// base32 encoding a 48-bit millisecond timestamp followed by 80 random bits.
+// They sort by creation time and, unlike the unix-second IDs used before,
+// do not collide when two items are created in the same second.
+package idgen
+
+import (
+	"crypto/rand"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generator produces new IDs. Tests can swap the package default with
+// SetDefault to get predictable values.
+type Generator interface {
+	NewID(t time.Time) string
+}
+
+// Length is the length of an encoded ULID.
+const Length = 26
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator produces monotonic ULIDs: IDs created within the same
+// millisecond increment the random part instead of drawing a new one, so
+// they still sort in creation order.
+type ULIDGenerator struct {
+	entropy io.Reader
+
+	mu     sync.Mutex
+	used   bool
+	lastMs uint64
+	last   [10]byte
+}
+
+// NewULIDGenerator returns a generator drawing randomness from entropy
+// (crypto/rand when nil).
+func NewULIDGenerator(entropy io.Reader) *ULIDGenerator {
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &ULIDGenerator{entropy: entropy}
+}
+
+// NewID returns a ULID for time t.
+func (g *ULIDGenerator) NewID(t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fresh := !g.used || ms != g.lastMs
+	if !fresh && !increment(&g.last) {
+		// Random part overflowed within one millisecond; borrow the next one
+		ms++
+		fresh = true
+	}
+	if fresh {
+		if _, err := io.ReadFull(g.entropy, g.last[:]); err != nil {
+			// Fall back to the clock so IDs stay unique within this process
+			n := uint64(time.Now().UnixNano())
+			for i := range g.last {
+				g.last[i] = byte(n >> (8 * (i % 8)))
+			}
+		}
+		g.lastMs = ms
+		g.used = true
+	}
+	return encode(ms, g.last)
+}
+
+// increment adds one to the big-endian value in b, reporting false on overflow.
+func increment(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(ms uint64, r [10]byte) string {
+	var out [Length]byte
+	// 48-bit timestamp -> 10 characters
+	for i := 9; i >= 0; i-- {
+		out[i] = crockford[ms&0x1f]
+		ms >>= 5
+	}
+	// 80 bits of randomness -> 16 characters, 5 bits at a time
+	var acc uint64
+	bits := 0
+	pos := 10
+	for _, b := range r {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockford[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(out[:])
+}
+
+var (
+	defaultMu  sync.RWMutex
+	defaultGen Generator = NewULIDGenerator(nil)
+)
+
+// SetDefault replaces the generator used by New and NewAt and returns the
+// previous one.
+func SetDefault(g Generator) Generator {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	prev := defaultGen
+	defaultGen = g
+	return prev
+}
+
+// New returns a new ID for the current time.
+func New() string {
+	return NewAt(time.Now())
+}
+
+// NewAt returns a new ID whose timestamp part is t. Migrations use this to
+// keep the original creation time of items they re-key.
+func NewAt(t time.Time) string {
+	defaultMu.RLock()
+	g := defaultGen
+	defaultMu.RUnlock()
+	return g.NewID(t)
+}
+
+// IsULID reports whether s is a well-formed ULID (case-insensitive).
+func IsULID(s string) bool {
+	_, ok := Time(s)
+	return ok
+}
+
+// Time decodes the timestamp of a ULID.
+func Time(s string) (time.Time, bool) {
+	if len(s) != Length {
+		return time.Time{}, false
+	}
+	s = strings.ToUpper(s)
+	// The first character carries only 3 bits of a 48-bit timestamp
+	if s[0] > '7' {
+		return time.Time{}, false
+	}
+	var ms uint64
+	for i := 0; i < Length; i++ {
+		v := strings.IndexByte(crockford, s[i])
+		if v < 0 {
+			return time.Time{}, false
+		}
+		if i < 10 {
+			ms = ms<<5 | uint64(v)
+		}
+	}
+	return time.UnixMilli(int64(ms)).UTC(), true
+}
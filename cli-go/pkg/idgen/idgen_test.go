@@ -0,0 +1,79 @@
+package idgen
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewID_Format(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	id := NewULIDGenerator(nil).NewID(ts)
+
+	if len(id) != Length {
+		t.Fatalf("len(%q) = %d, want %d", id, len(id), Length)
+	}
+	got, ok := Time(id)
+	if !ok || !got.Equal(ts.Truncate(time.Millisecond)) {
+		t.Errorf("Time(%q) = %v, %v; want %v", id, got, ok, ts)
+	}
+}
+
+func TestNewID_KnownEncoding(t *testing.T) {
+	// Zero entropy makes the output fully determined by the timestamp
+	g := NewULIDGenerator(bytes.NewReader(make([]byte, 10)))
+	if id := g.NewID(time.UnixMilli(0)); id != "00000000000000000000000000" {
+		t.Errorf("NewID(0) = %q", id)
+	}
+	g = NewULIDGenerator(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	if id := g.NewID(time.UnixMilli(1<<48 - 1)); id != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("NewID(max) = %q", id)
+	}
+}
+
+func TestNewID_MonotonicWithinMillisecond(t *testing.T) {
+	g := NewULIDGenerator(nil)
+	ts := time.Now()
+
+	ids := make([]string, 1000)
+	seen := make(map[string]bool)
+	for i := range ids {
+		ids[i] = g.NewID(ts)
+		if seen[ids[i]] {
+			t.Fatalf("duplicate ID %s", ids[i])
+		}
+		seen[ids[i]] = true
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("IDs created in the same millisecond should sort in creation order")
+	}
+}
+
+type fixedGen string
+
+func (f fixedGen) NewID(time.Time) string { return string(f) }
+
+func TestSetDefault(t *testing.T) {
+	prev := SetDefault(fixedGen("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	defer SetDefault(prev)
+
+	if id := New(); id != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Errorf("New() = %q", id)
+	}
+}
+
+func TestIsULID(t *testing.T) {
+	tests := map[string]bool{
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV": true,
+		"01arz3ndektsv4rrffq69g5fav": true,
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV": false, // timestamp overflow
+		"01ARZ3NDEKTSV4RRFFQ69G5FAI": false, // I is not in the alphabet
+		"draft-1735689600":           false,
+	}
+	for in, want := range tests {
+		if got := IsULID(in); got != want {
+			t.Errorf("IsULID(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+// Package importer converts the posts in a Twitter/X or Mastodon archive
+// into polis posts, dated when they were first posted. A thread of replies
+// to oneself becomes a single post. Replies to others, reposts, and
+// non-public posts are left out.
+//
+// Imported posts name their original in the imported_from frontmatter
+// field. They are not registered with the discovery service unless asked,
+// so an import doesn't announce years of old posts as new. The originals
+// imported so far are recorded in .polis/import.json, so importing the same
+// archive again only adds what is new.
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// Archive sources.
+const (
+	SourceTwitter  = "twitter"
+	SourceMastodon = "mastodon"
+)
+
+// StateFilename records imported originals, relative to .polis/.
+const StateFilename = "import.json"
+
+// stateName is the state file's name in site storage.
+const stateName = ".polis/" + StateFilename
+
+// Post is one post to import: a status, or a thread of them.
+type Post struct {
+	URL       string    // The original, written to imported_from
+	Published time.Time // When the first status was posted
+	Title     string    // A content warning, used as the title; usually empty
+	Parts     []Part    // The statuses, oldest first
+}
+
+// Part is one status in a post.
+type Part struct {
+	Text  string // Markdown
+	Media []Media
+}
+
+// Media is a file attached to a status.
+type Media struct {
+	Name  string // Filename, which keeps its extension in attachments/
+	Alt   string
+	Image bool // Shown inline; other media are linked
+	Data  []byte
+}
+
+// Archive is an export opened for reading: a .zip, a .tar.gz, or an
+// extracted directory. Files are named relative to the archive root, which
+// may be a single top-level directory inside the file.
+type Archive struct {
+	fsys   fs.FS
+	root   string
+	closer io.Closer
+}
+
+// Open opens an archive file or directory.
+func Open(name string) (*Archive, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &Archive{fsys: os.DirFS(name)}, nil
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		zr, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		return &Archive{fsys: zr, root: commonRoot(names), closer: zr}, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		files, err := readTarGz(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		names := make([]string, 0, len(files))
+		for n := range files {
+			names = append(names, n)
+		}
+		return &Archive{fsys: files, root: commonRoot(names)}, nil
+	}
+	return nil, fmt.Errorf("unsupported archive %s (expected a .zip, a .tar.gz, or a directory)", name)
+}
+
+// ReadFile reads the named file in the archive.
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	if a.root != "" {
+		// The one top-level directory may be part of the archive's layout
+		// (data/ in a Twitter archive holding nothing else)
+		if data, err := fs.ReadFile(a.fsys, path.Join(a.root, name)); err == nil {
+			return data, nil
+		}
+	}
+	return fs.ReadFile(a.fsys, name)
+}
+
+// Close releases the archive.
+func (a *Archive) Close() error {
+	if a.closer != nil {
+		return a.closer.Close()
+	}
+	return nil
+}
+
+// commonRoot returns the one top-level directory all names are in, or "".
+func commonRoot(names []string) string {
+	root := ""
+	for _, n := range names {
+		dir, _, ok := strings.Cut(strings.TrimPrefix(n, "./"), "/")
+		if !ok || (root != "" && dir != root) {
+			return ""
+		}
+		root = dir
+	}
+	return root
+}
+
+// tarFiles is an in-memory fs.FS for a .tar.gz, which can't be read at
+// random.
+type tarFiles map[string][]byte
+
+func (t tarFiles) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+}
+
+func (t tarFiles) ReadFile(name string) ([]byte, error) {
+	data, ok := t[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return data, nil
+}
+
+func readTarGz(r io.Reader) (tarFiles, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	files := tarFiles{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(h.Name, "./")] = data
+	}
+}
+
+// Read reads the posts in an archive of the given source, oldest first.
+func Read(a *Archive, source string) ([]Post, error) {
+	var posts []Post
+	var err error
+	switch source {
+	case SourceTwitter:
+		posts, err = readTwitter(a)
+	case SourceMastodon:
+		posts, err = readMastodon(a)
+	default:
+		return nil, fmt.Errorf("unknown archive source %q (expected %s or %s)", source, SourceTwitter, SourceMastodon)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].Published.Before(posts[j].Published) })
+	return posts, nil
+}
+
+// status is one post in an archive, before threads are merged.
+type status struct {
+	id        string
+	parent    string // The status this replies to
+	reply     bool   // A reply to someone else, kept to drop one's replies to it
+	url       string
+	published time.Time
+	title     string
+	part      Part
+}
+
+// threads merges replies to oneself into the post they reply to, and
+// drops conversations with others. Replies whose parent isn't in the
+// archive stand alone.
+func threads(statuses []status) []Post {
+	byID := make(map[string]*status, len(statuses))
+	for i := range statuses {
+		byID[statuses[i].id] = &statuses[i]
+	}
+	rootOf := func(s *status) *status {
+		for seen := 0; s.parent != "" && seen < len(statuses); seen++ {
+			p, ok := byID[s.parent]
+			if !ok {
+				break
+			}
+			s = p
+		}
+		return s
+	}
+
+	members := make(map[string][]*status)
+	var roots []*status
+	for i := range statuses {
+		s := &statuses[i]
+		r := rootOf(s)
+		if r == s {
+			roots = append(roots, s)
+		}
+		members[r.id] = append(members[r.id], s)
+	}
+
+	posts := make([]Post, 0, len(roots))
+	for _, r := range roots {
+		if r.reply {
+			continue
+		}
+		m := members[r.id]
+		sort.SliceStable(m, func(i, j int) bool { return m[i].published.Before(m[j].published) })
+		p := Post{URL: r.url, Published: r.published, Title: r.title}
+		for _, s := range m {
+			p.Parts = append(p.Parts, s.part)
+		}
+		posts = append(posts, p)
+	}
+	return posts
+}
+
+// Markdown converts a post to markdown, storing its media in the site's
+// attachments/ directory. With dataDir empty, nothing is stored and media
+// are left out.
+func Markdown(dataDir string, p Post) (string, error) {
+	var b strings.Builder
+	if p.Title != "" {
+		b.WriteString("# " + p.Title + "\n\n")
+	}
+	for i, part := range p.Parts {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.TrimSpace(part.Text))
+		if dataDir == "" {
+			continue
+		}
+		for _, m := range part.Media {
+			rel, _, err := publish.SaveAttachment(dataDir, m.Name, m.Data)
+			if err != nil {
+				return "", fmt.Errorf("failed to save %s: %w", m.Name, err)
+			}
+			alt := strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(m.Alt)
+			if m.Image {
+				b.WriteString("\n\n![" + alt + "](/" + rel + ")")
+			} else {
+				if alt == "" {
+					alt = m.Name
+				}
+				b.WriteString("\n\n[" + alt + "](/" + rel + ")")
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// State lists the originals imported so far.
+type State struct {
+	Imported map[string]string `json:"imported"` // Original URL -> post path
+}
+
+// LoadState reads the import state; a missing file is an empty state.
+func LoadState(dataDir string) (*State, error) {
+	st := &State{}
+	data, err := storage.For(dataDir).ReadFile(stateName)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", StateFilename, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", StateFilename, err)
+		}
+	}
+	if st.Imported == nil {
+		st.Imported = make(map[string]string)
+	}
+	return st, nil
+}
+
+// Save writes the import state.
+func (st *State) Save(dataDir string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.For(dataDir).WriteFile(stateName, append(data, '\n'), 0644)
+}
+
+// Options controls an import.
+type Options struct {
+	// Announce registers imported posts with the discovery service, as
+	// publishing a new post does.
+	Announce bool
+
+	// DryRun reports what would be imported without publishing anything.
+	DryRun bool
+}
+
+// Imported is one post published by an import.
+type Imported struct {
+	From      string `json:"from"`
+	Path      string `json:"path,omitempty"` // Empty for a dry run
+	Title     string `json:"title"`
+	Published string `json:"published"`
+}
+
+// Result reports an import.
+type Result struct {
+	Imported []Imported `json:"imported"`
+	Skipped  int        `json:"skipped"` // Imported before
+}
+
+// Import publishes the posts not imported before. Posts imported before
+// an error are kept and recorded, and reported in the result.
+func Import(dataDir string, posts []Post, opts Options, privateKey []byte) (*Result, error) {
+	st, err := LoadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Imported: []Imported{}}
+	for _, p := range posts {
+		if _, ok := st.Imported[p.URL]; ok {
+			result.Skipped++
+			continue
+		}
+		published := p.Published.UTC().Format("2006-01-02T15:04:05Z")
+		if opts.DryRun {
+			markdown, err := Markdown("", p)
+			if err != nil {
+				return result, err
+			}
+			result.Imported = append(result.Imported, Imported{From: p.URL, Title: publish.ExtractTitle(markdown), Published: published})
+			continue
+		}
+
+		markdown, err := Markdown(dataDir, p)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", p.URL, err)
+		}
+		pub, err := publish.PublishPostWithOptions(dataDir, markdown, publish.PublishOptions{
+			Published:    p.Published,
+			ImportedFrom: p.URL,
+			NoAnnounce:   !opts.Announce,
+			NoCrosspost:  true,
+		}, privateKey)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", p.URL, err)
+		}
+		result.Imported = append(result.Imported, Imported{From: p.URL, Path: pub.Path, Title: pub.Title, Published: published})
+
+		st.Imported[p.URL] = pub.Path
+		if err := st.Save(dataDir); err != nil {
+			return result, fmt.Errorf("imported %s, but failed to save %s: %w", pub.Path, StateFilename, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+const testAccount = `window.YTD.account.part0 = [ { "account" : { "accountId" : "42", "username" : "alice" } } ]`
+
+const testTweets = `window.YTD.tweets.part0 = [
+  { "tweet" : { "id_str" : "100", "created_at" : "Sun Mar 09 17:04:05 +0000 2014",
+      "full_text" : "Thread about gardens &amp; such 1/ https://t.co/img",
+      "entities" : { "urls" : [ ], "media" : [ { "url" : "https://t.co/img", "media_url_https" : "https://pbs.twimg.com/media/Abc.jpg", "type" : "photo" } ] },
+      "extended_entities" : { "media" : [ { "url" : "https://t.co/img", "media_url_https" : "https://pbs.twimg.com/media/Abc.jpg", "type" : "photo" } ] } } },
+  { "tweet" : { "id_str" : "101", "created_at" : "Sun Mar 09 17:06:00 +0000 2014",
+      "in_reply_to_status_id_str" : "100", "in_reply_to_user_id_str" : "42",
+      "full_text" : "@alice 2/ Read https://t.co/x.",
+      "entities" : { "urls" : [ { "url" : "https://t.co/x", "expanded_url" : "https://example.com/roses" } ] } } },
+  { "tweet" : { "id_str" : "200", "created_at" : "Mon Mar 10 09:00:00 +0000 2014",
+      "in_reply_to_status_id_str" : "7", "in_reply_to_user_id_str" : "9",
+      "full_text" : "@bob agreed", "entities" : { "urls" : [ ] } } },
+  { "tweet" : { "id_str" : "201", "created_at" : "Mon Mar 10 09:01:00 +0000 2014",
+      "in_reply_to_status_id_str" : "200", "in_reply_to_user_id_str" : "42",
+      "full_text" : "@alice and another thing", "entities" : { "urls" : [ ] } } },
+  { "tweet" : { "id_str" : "300", "created_at" : "Tue Mar 11 12:00:00 +0000 2014",
+      "full_text" : "RT @bob: something", "entities" : { "urls" : [ ] } } },
+  { "tweet" : { "id_str" : "400", "created_at" : "Wed Mar 12 12:00:00 +0000 2014",
+      "full_text" : "#gardening\n- not a list\n\n2. nor this\n# nor this", "entities" : { "urls" : [ ] } } }
+]`
+
+// writeZip writes files into a zip archive under a top-level directory, as
+// Twitter's exports are when re-zipped.
+func writeZip(t *testing.T, name, root string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for n, content := range files {
+		w, err := zw.Create(root + "/" + n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readArchive(t *testing.T, name, source string) []Post {
+	t.Helper()
+	a, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	posts, err := Read(a, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return posts
+}
+
+func TestReadTwitter(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "twitter.zip")
+	writeZip(t, name, "twitter-2023-01-01", map[string]string{
+		"data/account.js":               testAccount,
+		"data/tweets.js":                testTweets,
+		"data/tweets_media/100-Abc.jpg": "jpeg",
+	})
+
+	posts := readArchive(t, name, SourceTwitter)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want the thread and the hashtag tweet: %+v", len(posts), posts)
+	}
+
+	thread := posts[0]
+	if thread.URL != "https://twitter.com/alice/status/100" {
+		t.Errorf("URL = %q", thread.URL)
+	}
+	if len(thread.Parts) != 2 {
+		t.Fatalf("thread has %d parts, want 2", len(thread.Parts))
+	}
+	if got := thread.Parts[0].Text; got != "Thread about gardens & such 1/" {
+		t.Errorf("first part = %q", got)
+	}
+	if got := thread.Parts[1].Text; got != "2/ Read <https://example.com/roses>." {
+		t.Errorf("second part = %q", got)
+	}
+	if m := thread.Parts[0].Media; len(m) != 1 || m[0].Name != "Abc.jpg" || string(m[0].Data) != "jpeg" {
+		t.Errorf("media = %+v", m)
+	}
+
+	if got, want := posts[1].Parts[0].Text, "#gardening\\\n\\- not a list\n\n2\\. nor this\\\n\\# nor this"; got != want {
+		t.Errorf("escaped text = %q, want %q", got, want)
+	}
+}
+
+func TestReadMastodon(t *testing.T) {
+	dir := t.TempDir()
+	outbox := `{"orderedItems": [
+	  {"type": "Create", "object": {"id": "https://m.example/users/alice/statuses/1", "url": "https://m.example/@alice/1",
+	    "published": "2022-11-05T10:00:00Z", "to": ["https://www.w3.org/ns/activitystreams#Public"],
+	    "content": "<p>Hello <strong>fediverse</strong></p>",
+	    "attachment": [{"mediaType": "image/png", "url": "/media_attachments/files/1/original/cat.png", "name": "A cat"}]}},
+	  {"type": "Create", "object": {"id": "https://m.example/users/alice/statuses/2", "url": "https://m.example/@alice/2",
+	    "published": "2022-11-05T10:05:00Z", "inReplyTo": "https://m.example/users/alice/statuses/1",
+	    "cc": ["https://www.w3.org/ns/activitystreams#Public"], "content": "<p>More</p>"}},
+	  {"type": "Create", "object": {"id": "https://m.example/users/alice/statuses/3",
+	    "published": "2022-11-06T10:00:00Z", "to": ["https://m.example/users/alice/followers"], "content": "<p>Private</p>"}},
+	  {"type": "Create", "object": {"id": "https://m.example/users/alice/statuses/4",
+	    "published": "2022-11-07T10:00:00Z", "inReplyTo": "https://other.example/statuses/9",
+	    "to": ["https://www.w3.org/ns/activitystreams#Public"], "content": "<p>Reply</p>"}},
+	  {"type": "Announce", "object": "https://other.example/statuses/10"},
+	  {"type": "Create", "object": {"id": "https://m.example/users/alice/statuses/5", "url": "https://m.example/@alice/5",
+	    "published": "2022-11-08T10:00:00Z", "to": ["https://www.w3.org/ns/activitystreams#Public"],
+	    "summary": "Spoilers", "content": "<p>The butler</p>"}}
+	]}`
+
+	// Mastodon exports a .tar.gz
+	name := filepath.Join(dir, "archive.tar.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for n, content := range map[string]string{"outbox.json": outbox, "media_attachments/files/1/original/cat.png": "png"} {
+		tw.WriteHeader(&tar.Header{Name: n, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	posts := readArchive(t, name, SourceMastodon)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want the thread and the CW post: %+v", len(posts), posts)
+	}
+	if len(posts[0].Parts) != 2 || posts[0].Parts[0].Text != "Hello **fediverse**" || posts[0].Parts[1].Text != "More" {
+		t.Errorf("thread = %+v", posts[0].Parts)
+	}
+	if m := posts[0].Parts[0].Media; len(m) != 1 || m[0].Alt != "A cat" || !m[0].Image {
+		t.Errorf("media = %+v", m)
+	}
+	if posts[1].Title != "Spoilers" || posts[1].URL != "https://m.example/@alice/5" {
+		t.Errorf("CW post = %+v", posts[1])
+	}
+}
+
+func TestImport(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	archive := filepath.Join(t.TempDir(), "twitter.zip")
+	writeZip(t, archive, "twitter-2023-01-01", map[string]string{
+		"data/account.js":               testAccount,
+		"data/tweets.js":                testTweets,
+		"data/tweets_media/100-Abc.jpg": "jpeg",
+	})
+	posts := readArchive(t, archive, SourceTwitter)
+
+	dry, err := Import(dataDir, posts, Options{DryRun: true}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Imported) != 2 || dry.Imported[0].Path != "" {
+		t.Errorf("dry run = %+v", dry.Imported)
+	}
+	if entries, _ := metadata.GetPostEntries(dataDir); len(entries) != 0 {
+		t.Fatalf("dry run published %d posts", len(entries))
+	}
+
+	result, err := Import(dataDir, posts, Options{}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Imported) != 2 {
+		t.Fatalf("imported %d posts, want 2", len(result.Imported))
+	}
+	first := result.Imported[0]
+	if !strings.HasPrefix(first.Path, "posts/20140309/") {
+		t.Errorf("path = %q, want the date of the tweet", first.Path)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dataDir, first.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := publish.ParseFrontmatterFields(string(content))
+	if fm.ImportedFrom != "https://twitter.com/alice/status/100" || fm.Published != "2014-03-09T17:04:05Z" {
+		t.Errorf("frontmatter = %+v", fm)
+	}
+	body := publish.StripFrontmatter(string(content))
+	if !strings.Contains(body, "![](/attachments/abc-") || !strings.Contains(body, "2/ Read") {
+		t.Errorf("body = %q", body)
+	}
+
+	// Importing again adds nothing
+	again, err := Import(dataDir, posts, Options{}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Imported) != 0 || again.Skipped != 2 {
+		t.Errorf("second import = %+v", again)
+	}
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/sanitize"
+)
+
+// publicAddress is the ActivityStreams audience of public and unlisted
+// statuses.
+const publicAddress = "https://www.w3.org/ns/activitystreams#Public"
+
+// note is the part of a status in outbox.json used here.
+type note struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	Published  string   `json:"published"`
+	InReplyTo  string   `json:"inReplyTo"`
+	To         []string `json:"to"`
+	CC         []string `json:"cc"`
+	Summary    string   `json:"summary"` // Content warning
+	Content    string   `json:"content"` // HTML
+	Attachment []struct {
+		MediaType string `json:"mediaType"`
+		URL       string `json:"url"`
+		Name      string `json:"name"` // Alt text
+	} `json:"attachment"`
+}
+
+// readMastodon reads outbox.json. Boosts and statuses only for followers
+// or mentioned accounts are left out.
+func readMastodon(a *Archive) ([]Post, error) {
+	data, err := a.ReadFile("outbox.json")
+	if err != nil {
+		return nil, fmt.Errorf("not a Mastodon archive: %w", err)
+	}
+	var outbox struct {
+		OrderedItems []struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		} `json:"orderedItems"`
+	}
+	if err := json.Unmarshal(data, &outbox); err != nil {
+		return nil, fmt.Errorf("failed to parse outbox.json: %w", err)
+	}
+
+	var notes []note
+	own := make(map[string]bool)
+	for _, item := range outbox.OrderedItems {
+		if item.Type != "Create" {
+			continue // Announce is a boost, with the status's URI as the object
+		}
+		var n note
+		if err := json.Unmarshal(item.Object, &n); err != nil {
+			return nil, fmt.Errorf("failed to parse outbox.json: %w", err)
+		}
+		if !isPublic(n) {
+			continue
+		}
+		notes = append(notes, n)
+		own[n.ID] = true
+	}
+
+	var statuses []status
+	for _, n := range notes {
+		if n.InReplyTo != "" && !own[n.InReplyTo] {
+			statuses = append(statuses, status{id: n.ID, parent: n.InReplyTo, reply: true})
+			continue
+		}
+		published, err := time.Parse(time.RFC3339, n.Published)
+		if err != nil {
+			return nil, fmt.Errorf("status %s: bad published %q", n.ID, n.Published)
+		}
+		link := n.URL
+		if link == "" {
+			link = n.ID
+		}
+		statuses = append(statuses, status{
+			id:        n.ID,
+			parent:    n.InReplyTo,
+			url:       link,
+			published: published,
+			title:     strings.Join(strings.Fields(n.Summary), " "),
+			part:      Part{Text: strings.TrimSpace(sanitize.Markdown(n.Content)), Media: noteMedia(a, n)},
+		})
+	}
+	return threads(statuses), nil
+}
+
+// isPublic reports whether a status is addressed to the public (public or
+// unlisted on Mastodon).
+func isPublic(n note) bool {
+	for _, addr := range append(n.To, n.CC...) {
+		if addr == publicAddress || addr == "as:Public" || addr == "Public" {
+			return true
+		}
+	}
+	return false
+}
+
+// noteMedia reads a status's attachments from media_attachments/ in the
+// archive. Attachment URLs are paths in the archive, or in older archives
+// URLs on the instance with the same path.
+func noteMedia(a *Archive, n note) []Media {
+	var media []Media
+	for _, att := range n.Attachment {
+		name := att.URL
+		if u, err := url.Parse(att.URL); err == nil && u.Host != "" {
+			name = u.Path
+		}
+		name = strings.TrimPrefix(name, "/")
+		if i := strings.Index(name, "media_attachments/"); i > 0 {
+			name = name[i:]
+		}
+		data, err := a.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		media = append(media, Media{
+			Name:  path.Base(name),
+			Alt:   strings.Join(strings.Fields(att.Name), " "),
+			Image: strings.HasPrefix(att.MediaType, "image/"),
+			Data:  data,
+		})
+	}
+	return media
+}
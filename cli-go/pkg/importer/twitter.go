@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// twitterTime is the created_at format in a Twitter archive.
+const twitterTime = "Mon Jan 02 15:04:05 -0700 2006"
+
+// tweet is the part of a tweet in data/tweets.js used here.
+type tweet struct {
+	ID            string `json:"id_str"`
+	FullText      string `json:"full_text"`
+	CreatedAt     string `json:"created_at"`
+	ReplyToStatus string `json:"in_reply_to_status_id_str"`
+	ReplyToUser   string `json:"in_reply_to_user_id_str"`
+	Retweeted     bool   `json:"retweeted"`
+	Entities      struct {
+		URLs []struct {
+			URL         string `json:"url"`
+			ExpandedURL string `json:"expanded_url"`
+		} `json:"urls"`
+		Media []tweetMedia `json:"media"`
+	} `json:"entities"`
+	ExtendedEntities struct {
+		Media []tweetMedia `json:"media"`
+	} `json:"extended_entities"`
+}
+
+type tweetMedia struct {
+	URL      string `json:"url"` // The t.co link in the text
+	MediaURL string `json:"media_url_https"`
+	Type     string `json:"type"` // photo, video, or animated_gif
+}
+
+// readTwitter reads data/tweets.js (data/tweet.js in older archives, with
+// large archives split into data/tweets-part1.js and so on).
+func readTwitter(a *Archive) ([]Post, error) {
+	var account []struct {
+		Account struct {
+			ID       string `json:"accountId"`
+			Username string `json:"username"`
+		} `json:"account"`
+	}
+	if err := readYTD(a, "data/account.js", &account); err != nil {
+		return nil, fmt.Errorf("not a Twitter archive: %w", err)
+	}
+	if len(account) == 0 {
+		return nil, fmt.Errorf("not a Twitter archive: no account in data/account.js")
+	}
+	self := account[0].Account
+
+	var tweets []struct {
+		Tweet tweet `json:"tweet"`
+	}
+	for _, name := range []string{"data/tweets.js", "data/tweet.js"} {
+		err := readYTD(a, name, &tweets)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	for i := 1; ; i++ {
+		var part []struct {
+			Tweet tweet `json:"tweet"`
+		}
+		if err := readYTD(a, fmt.Sprintf("data/tweets-part%d.js", i), &part); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		tweets = append(tweets, part...)
+	}
+
+	var statuses []status
+	for _, t := range tweets {
+		tw := t.Tweet
+		if tw.Retweeted || strings.HasPrefix(tw.FullText, "RT @") {
+			continue
+		}
+		if tw.ReplyToStatus != "" && tw.ReplyToUser != self.ID {
+			statuses = append(statuses, status{id: tw.ID, parent: tw.ReplyToStatus, reply: true})
+			continue
+		}
+		published, err := time.Parse(twitterTime, tw.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("tweet %s: bad created_at %q", tw.ID, tw.CreatedAt)
+		}
+		statuses = append(statuses, status{
+			id:        tw.ID,
+			parent:    tw.ReplyToStatus,
+			url:       "https://twitter.com/" + self.Username + "/status/" + tw.ID,
+			published: published,
+			part:      Part{Text: tweetMarkdown(tw, self.Username), Media: tweetPhotos(a, tw)},
+		})
+	}
+	return threads(statuses), nil
+}
+
+// readYTD reads an archive data file, a JSON array assigned to a variable:
+// window.YTD.tweets.part0 = [...].
+func readYTD(a *Archive, name string, v interface{}) error {
+	data, err := a.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if i := bytes.IndexByte(data, '='); i >= 0 && bytes.HasPrefix(data, []byte("window.")) {
+		data = data[i+1:]
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+var (
+	// urlPattern matches a link in tweet text.
+	urlPattern = regexp.MustCompile(`https?://\S+`)
+
+	// listMarker matches text at the start of a line that markdown would
+	// read as a list item, block quote, or heading.
+	listMarker = regexp.MustCompile(`^([-+>]|\d+[.)]|#+)(\s|$)`)
+
+	// paraBreak separates paragraphs.
+	paraBreak = regexp.MustCompile(`\n\s*\n`)
+
+	textEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`)
+)
+
+// tweetMarkdown converts a tweet's text to markdown: t.co links are
+// expanded, links to attached media and the leading mention of oneself in
+// a thread are dropped, and line breaks are kept.
+func tweetMarkdown(tw tweet, self string) string {
+	text := tw.FullText
+	for _, u := range tw.Entities.URLs {
+		if u.URL != "" && u.ExpandedURL != "" {
+			text = strings.ReplaceAll(text, u.URL, u.ExpandedURL)
+		}
+	}
+	for _, m := range append(tw.Entities.Media, tw.ExtendedEntities.Media...) {
+		if m.URL != "" {
+			text = strings.ReplaceAll(text, m.URL, "")
+		}
+	}
+	text = html.UnescapeString(text)
+	if tw.ReplyToStatus != "" {
+		text = strings.TrimPrefix(text, "@"+self+" ")
+	}
+	return plainMarkdown(text)
+}
+
+// plainMarkdown escapes plain text for markdown, keeping links, paragraphs,
+// and line breaks.
+func plainMarkdown(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var paras []string
+	for _, p := range paraBreak.Split(strings.TrimSpace(text), -1) {
+		lines := strings.Split(p, "\n")
+		for i, line := range lines {
+			lines[i] = markdownLine(strings.TrimSpace(line))
+		}
+		paras = append(paras, strings.Join(lines, "\\\n"))
+	}
+	return strings.Join(paras, "\n\n")
+}
+
+// markdownLine escapes one line of plain text. Links become autolinks,
+// without the punctuation that ends a sentence.
+func markdownLine(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(line, -1) {
+		end := loc[1]
+		for end > loc[0] && strings.ContainsRune(".,;:!?)\"'", rune(line[end-1])) {
+			end--
+		}
+		b.WriteString(textEscaper.Replace(line[last:loc[0]]))
+		b.WriteString("<" + line[loc[0]:end] + ">")
+		last = end
+	}
+	b.WriteString(textEscaper.Replace(line[last:]))
+	line = b.String()
+
+	if m := listMarker.FindString(line); m != "" {
+		if i := strings.IndexAny(m, ".)"); i > 0 {
+			return line[:i] + `\` + line[i:]
+		}
+		return `\` + line
+	}
+	return line
+}
+
+// tweetPhotos reads the photos attached to a tweet from
+// data/tweets_media/<id>-<name> (data/tweet_media in older archives).
+// Videos and GIFs are left out.
+func tweetPhotos(a *Archive, tw tweet) []Media {
+	media := tw.ExtendedEntities.Media
+	if len(media) == 0 {
+		media = tw.Entities.Media
+	}
+	var photos []Media
+	for _, m := range media {
+		if m.Type != "photo" || m.MediaURL == "" {
+			continue
+		}
+		name := tw.ID + "-" + path.Base(m.MediaURL)
+		for _, dir := range []string{"data/tweets_media/", "data/tweet_media/"} {
+			if data, err := a.ReadFile(dir + name); err == nil {
+				photos = append(photos, Media{Name: path.Base(m.MediaURL), Image: true, Data: data})
+				break
+			}
+		}
+	}
+	return photos
+}
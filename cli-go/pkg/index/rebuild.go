@@ -2,6 +2,7 @@
 package index
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -12,7 +13,10 @@ import (
 	"time"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/ipfs"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
 )
 
 // Version is set at init time by cmd package.
@@ -30,6 +34,13 @@ type PostEntry struct {
 	URL       string `json:"url"`
 	Published string `json:"published"`
 	Hash      string `json:"hash"`
+	Summary   string `json:"summary,omitempty"`
+
+	Lang          string `json:"lang,omitempty"`
+	TranslationOf string `json:"translation_of,omitempty"`
+	IPFS          string `json:"ipfs,omitempty"`
+
+	unlisted bool // Unlisted posts are left out of public.jsonl
 }
 
 // RebuildOptions configures what to rebuild.
@@ -97,9 +108,16 @@ func rebuildPostsIndex(dataDir, baseURL string) (int, error) {
 		return 0, err
 	}
 
+	// IPFS copies are recorded in syndication.json; carry over the CIDs of
+	// copies of the current versions
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return 0, err
+	}
+
 	// Find all markdown files
 	var entries []PostEntry
-	err := filepath.Walk(postsDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(postsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
 		}
@@ -118,6 +136,13 @@ func rebuildPostsIndex(dataDir, baseURL string) (int, error) {
 		if err != nil {
 			return nil // Skip files that can't be parsed
 		}
+		if entry.unlisted {
+			return nil // Indexed in .polis/unlisted.jsonl, not public.jsonl
+		}
+		relPath, _ := filepath.Rel(dataDir, path)
+		if link, ok := synd.Link(filepath.ToSlash(relPath), ipfs.Service); ok && link.Version == entry.Hash {
+			entry.IPFS = link.CID
+		}
 		entries = append(entries, entry)
 		return nil
 	})
@@ -132,18 +157,17 @@ func rebuildPostsIndex(dataDir, baseURL string) (int, error) {
 	})
 
 	// Write index file
-	file, err := os.Create(indexPath)
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
+	var buf bytes.Buffer
 	for _, entry := range entries {
 		data, err := json.Marshal(entry)
 		if err != nil {
 			continue
 		}
-		file.WriteString(string(data) + "\n")
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if err := fsutil.WriteFile(indexPath, buf.Bytes(), 0644); err != nil {
+		return 0, err
 	}
 
 	return len(entries), nil
@@ -160,17 +184,28 @@ func buildPostEntry(path, dataDir, baseURL string) (PostEntry, error) {
 
 	// Calculate relative path for URL
 	relPath, _ := filepath.Rel(dataDir, path)
-	url := baseURL + "/" + relPath
+	url := baseURL + "/" + filepath.ToSlash(relPath)
 
 	// Calculate hash
 	hash := sha256.Sum256([]byte(canonicalizeContent(body)))
 
+	summary := publish.FrontmatterSummary(string(content))
+	if summary == "" {
+		summary = publish.Summarize(body)
+	}
+
 	return PostEntry{
 		Type:      "post",
 		Title:     fm["title"],
 		URL:       url,
 		Published: fm["published"],
 		Hash:      fmt.Sprintf("sha256:%x", hash),
+		Summary:   summary,
+
+		Lang:          publish.FrontmatterLang(string(content)),
+		TranslationOf: publish.FrontmatterTranslationOf(string(content)),
+
+		unlisted: publish.FrontmatterUnlisted(string(content)),
 	}, nil
 }
 
@@ -347,7 +382,7 @@ func regenerateManifest(dataDir string) error {
 		return err
 	}
 
-	return os.WriteFile(manifestPath, append(data, '\n'), 0644)
+	return fsutil.WriteFile(manifestPath, append(data, '\n'), 0644)
 }
 
 // parseFrontmatter extracts frontmatter fields from content.
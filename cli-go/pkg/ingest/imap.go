@@ -0,0 +1,234 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIMAPPort is the IMAP-over-TLS port. Plain-text IMAP is not
+// supported.
+const DefaultIMAPPort = "993"
+
+// imapTimeout bounds each network read and write.
+const imapTimeout = time.Minute
+
+// IMAPConfig is a mailbox to poll.
+type IMAPConfig struct {
+	Host     string // host or host:port
+	Username string
+	Password string
+	Mailbox  string // INBOX if empty
+}
+
+// Validate checks that the config names a server and account.
+func (c IMAPConfig) Validate() error {
+	if c.Host == "" {
+		return errors.New("ingest.imap_host is not set")
+	}
+	if c.Username == "" {
+		return errors.New("ingest.imap_user is not set")
+	}
+	if c.Password == "" {
+		return errors.New("IMAP_PASSWORD is not set")
+	}
+	return nil
+}
+
+func (c IMAPConfig) addr() string {
+	if _, _, err := net.SplitHostPort(c.Host); err == nil {
+		return c.Host
+	}
+	return net.JoinHostPort(c.Host, DefaultIMAPPort)
+}
+
+// dialIMAP opens the connection; tests replace it.
+var dialIMAP = func(addr string) (net.Conn, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	return tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, &tls.Config{ServerName: host})
+}
+
+// Handler is called with each unseen message. Returning true marks the
+// message as seen so it isn't fetched again.
+type Handler func(raw []byte) (seen bool)
+
+// FetchUnseen logs in, passes every unseen message in the mailbox to
+// handle, and logs out. Messages are fetched without setting \Seen; only
+// those handle accepts are marked.
+func FetchUnseen(cfg IMAPConfig, handle Handler) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	conn, err := dialIMAP(cfg.addr())
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", cfg.addr(), err)
+	}
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn)}
+	defer conn.Close()
+
+	if err := c.greeting(); err != nil {
+		return err
+	}
+	if _, err := c.command("LOGIN " + quote(cfg.Username) + " " + quote(cfg.Password)); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	mailbox := cfg.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	if _, err := c.command("SELECT " + quote(mailbox)); err != nil {
+		return fmt.Errorf("failed to open %s: %w", mailbox, err)
+	}
+
+	resp, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	var uids []string
+	for _, line := range resp {
+		if rest, ok := strings.CutPrefix(line.text, "* SEARCH"); ok {
+			uids = append(uids, strings.Fields(rest)...)
+		}
+	}
+
+	for _, uid := range uids {
+		if _, err := strconv.ParseUint(uid, 10, 32); err != nil {
+			continue
+		}
+		resp, err := c.command("UID FETCH " + uid + " BODY.PEEK[]")
+		if err != nil {
+			return fmt.Errorf("fetch of message %s failed: %w", uid, err)
+		}
+		var raw []byte
+		for _, line := range resp {
+			if len(line.literals) > 0 {
+				raw = line.literals[0]
+				break
+			}
+		}
+		if raw == nil || !handle(raw) {
+			continue
+		}
+		if _, err := c.command("UID STORE " + uid + ` +FLAGS.SILENT (\Seen)`); err != nil {
+			return fmt.Errorf("failed to mark message %s seen: %w", uid, err)
+		}
+	}
+
+	c.command("LOGOUT")
+	return nil
+}
+
+// imapLine is one untagged response line, with any literals it carried.
+type imapLine struct {
+	text     string
+	literals [][]byte
+}
+
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+func (c *imapClient) greeting() error {
+	c.conn.SetDeadline(time.Now().Add(imapTimeout))
+	line, err := c.readLine()
+	if err != nil {
+		return fmt.Errorf("no greeting from server: %w", err)
+	}
+	if !strings.HasPrefix(line.text, "* OK") && !strings.HasPrefix(line.text, "* PREAUTH") {
+		return fmt.Errorf("server refused connection: %s", line.text)
+	}
+	return nil
+}
+
+// command sends a tagged command and returns the untagged responses, or an
+// error if the server didn't answer OK.
+func (c *imapClient) command(cmd string) ([]imapLine, error) {
+	c.tag++
+	tag := "p" + strconv.Itoa(c.tag)
+	c.conn.SetDeadline(time.Now().Add(imapTimeout))
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, err
+	}
+	var lines []imapLine
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(line.text, tag+" "); ok {
+			if !strings.HasPrefix(rest, "OK") {
+				return lines, errors.New(rest)
+			}
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// readLine reads one response line. A line ending in {n} is followed by
+// an n-byte literal and then the rest of the line.
+func (c *imapClient) readLine() (imapLine, error) {
+	var line imapLine
+	var text strings.Builder
+	for {
+		s, err := c.r.ReadString('\n')
+		if err != nil {
+			return line, err
+		}
+		s = strings.TrimRight(s, "\r\n")
+		n, ok := literalSize(s)
+		if !ok {
+			text.WriteString(s)
+			line.text = text.String()
+			return line, nil
+		}
+		if n > MaxMessageSize {
+			return line, fmt.Errorf("message larger than %d bytes", MaxMessageSize)
+		}
+		text.WriteString(s[:strings.LastIndexByte(s, '{')])
+		lit := make([]byte, n)
+		if _, err := io.ReadFull(c.r, lit); err != nil {
+			return line, err
+		}
+		line.literals = append(line.literals, lit)
+	}
+}
+
+// literalSize parses a trailing {n} literal marker.
+func literalSize(s string) (int, bool) {
+	if !strings.HasSuffix(s, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(s, '{')
+	if open < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[open+1 : len(s)-1])
+	return n, err == nil && n >= 0
+}
+
+// quote returns s as an IMAP quoted string.
+func quote(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		if r == '\r' || r == '\n' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,225 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/sanitize"
+)
+
+// StateFilename records published Message-IDs, relative to .polis/.
+const StateFilename = "ingest.json"
+
+var (
+	// ErrNotAllowed is returned for messages from senders not on the
+	// allowlist, or that the receiving server marked as forged.
+	ErrNotAllowed = errors.New("sender not allowed")
+
+	// ErrAlreadyPublished is returned for a Message-ID published before.
+	ErrAlreadyPublished = errors.New("message already published")
+
+	// ErrEmpty is returned for a message with no subject or body.
+	ErrEmpty = errors.New("message is empty")
+)
+
+// ParseAllow splits the ingest.allow setting into lowercased addresses.
+func ParseAllow(setting string) []string {
+	var allow []string
+	for _, a := range strings.FieldsFunc(setting, func(r rune) bool { return r == ',' || r == ' ' }) {
+		allow = append(allow, strings.ToLower(a))
+	}
+	return allow
+}
+
+// dmarcFail matches a failed DMARC or SPF check in Authentication-Results.
+var dmarcFail = regexp.MustCompile(`(?i)\b(dmarc|spf)=(fail|softfail)\b`)
+
+// CheckSender returns ErrNotAllowed unless the message's From address is on
+// the allowlist. The From header is easy to forge, so a message the
+// receiving server marked as failing DMARC or SPF is refused too.
+func CheckSender(msg *Message, allow []string) error {
+	allowed := false
+	for _, a := range allow {
+		if a == msg.From {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrNotAllowed, msg.From)
+	}
+	if dmarcFail.MatchString(msg.AuthResults) {
+		return fmt.Errorf("%w: %s failed sender authentication", ErrNotAllowed, msg.From)
+	}
+	return nil
+}
+
+// Markdown converts a message to post markdown. Images are stored in the
+// site's attachments/ directory: those referenced from the HTML body
+// (cid: URLs) stay in place, and the rest are appended after the text.
+// The subject becomes the title unless the body starts with a heading.
+func Markdown(dataDir string, msg *Message) (string, error) {
+	paths := make(map[int]string)
+	for i, p := range msg.Parts {
+		if !p.IsImage() || len(p.Data) == 0 {
+			continue
+		}
+		name := p.Filename
+		if name == "" {
+			name = "image" + imageExt(p.ContentType)
+		}
+		rel, _, err := publish.SaveAttachment(dataDir, name, p.Data)
+		if err != nil {
+			return "", fmt.Errorf("failed to save %s: %w", name, err)
+		}
+		paths[i] = "/" + rel
+	}
+
+	var body, fm string
+	referenced := make(map[int]bool)
+	if strings.TrimSpace(msg.HTML) != "" {
+		html := msg.HTML
+		for i, p := range msg.Parts {
+			if path, ok := paths[i]; ok && p.ContentID != "" && strings.Contains(html, "cid:"+p.ContentID) {
+				html = strings.ReplaceAll(html, "cid:"+p.ContentID, path)
+				referenced[i] = true
+			}
+		}
+		body = sanitize.Markdown(html)
+	} else {
+		// A plain-text message may start with frontmatter (slug, unlisted, ...)
+		body = strings.TrimLeft(stripSignature(msg.Text), "\n")
+		fm = frontmatterPattern.FindString(body)
+		body = body[len(fm):]
+	}
+	body = strings.TrimSpace(body)
+
+	var b strings.Builder
+	b.WriteString(fm)
+	if msg.Subject != "" && !strings.HasPrefix(body, "# ") {
+		b.WriteString("# " + msg.Subject + "\n\n")
+	}
+	b.WriteString(body)
+	for i, p := range msg.Parts {
+		if path, ok := paths[i]; ok && !referenced[i] {
+			alt := strings.TrimSuffix(p.Filename, filepath.Ext(p.Filename))
+			b.WriteString("\n\n![" + alt + "](" + path + ")")
+		}
+	}
+	out := strings.TrimSpace(b.String())
+	if strings.TrimSpace(publish.StripFrontmatter(out)) == "" {
+		return "", ErrEmpty
+	}
+	return out + "\n", nil
+}
+
+var (
+	// sigDelimiter starts a conventional email signature.
+	sigDelimiter = regexp.MustCompile(`(?m)^-- ?$`)
+
+	frontmatterPattern = regexp.MustCompile(`^---\n(?s:.*?)\n---\n`)
+)
+
+// stripSignature drops a signature block and normalizes line endings.
+func stripSignature(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if loc := sigDelimiter.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	return text
+}
+
+func imageExt(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
+
+// State lists the Message-IDs published so far.
+type State struct {
+	Published map[string]string `json:"published"` // Message-ID -> post path
+}
+
+// StatePath returns the path to .polis/ingest.json.
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", StateFilename)
+}
+
+// LoadState reads the ingest state; a missing file is an empty state.
+func LoadState(dataDir string) (*State, error) {
+	st := &State{Published: make(map[string]string)}
+	data, err := os.ReadFile(StatePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StateFilename, err)
+	}
+	if st.Published == nil {
+		st.Published = make(map[string]string)
+	}
+	return st, nil
+}
+
+// Save writes the ingest state.
+func (st *State) Save(dataDir string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(StatePath(dataDir), append(data, '\n'), 0644)
+}
+
+// Publish checks a message against the allowlist and publishes it as a
+// post.
+func Publish(dataDir string, msg *Message, allow []string, privateKey []byte) (*publish.PublishResult, error) {
+	if err := CheckSender(msg, allow); err != nil {
+		return nil, err
+	}
+	st, err := LoadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if post, ok := st.Published[msg.MessageID]; ok && msg.MessageID != "" {
+		return nil, fmt.Errorf("%w as %s", ErrAlreadyPublished, post)
+	}
+
+	markdown, err := Markdown(dataDir, msg)
+	if err != nil {
+		return nil, err
+	}
+	opts := publish.SourceOptions(markdown)
+	if publish.HasFrontmatter(markdown) {
+		markdown = publish.StripFrontmatter(markdown)
+	}
+	result, err := publish.PublishPostWithOptions(dataDir, markdown, opts, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.MessageID != "" {
+		st.Published[msg.MessageID] = result.Path
+		if err := st.Save(dataDir); err != nil {
+			return result, fmt.Errorf("published, but failed to save %s: %w", StateFilename, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package ingest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+const htmlMessage = "From: Alice <Alice@Example.com>\r\n" +
+	"To: blog@example.com\r\n" +
+	"Subject: =?UTF-8?Q?Caf=C3=A9_notes?=\r\n" +
+	"Message-ID: <abc123@example.com>\r\n" +
+	"Date: Mon, 02 Feb 2026 10:00:00 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/related; boundary=REL\r\n" +
+	"\r\n" +
+	"--REL\r\n" +
+	"Content-Type: multipart/alternative; boundary=ALT\r\n" +
+	"\r\n" +
+	"--ALT\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Plain version\r\n" +
+	"--ALT\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p>Hello <b>world</b>.</p><p><img src=3D\"cid:pic1\" alt=3D\"cup\"></p>\r\n" +
+	"--ALT--\r\n" +
+	"--REL\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"Content-ID: <pic1>\r\n" +
+	"Content-Disposition: inline; filename=\"cup.png\"\r\n" +
+	"\r\n" +
+	"UE5HREFUQQ==\r\n" +
+	"--REL\r\n" +
+	"Content-Type: image/jpeg; name=\"extra.jpg\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"Content-Disposition: attachment; filename=\"extra.jpg\"\r\n" +
+	"\r\n" +
+	"SlBFRw==\r\n" +
+	"--REL--\r\n"
+
+func TestParse_MultipartHTML(t *testing.T) {
+	msg, err := Parse(strings.NewReader(htmlMessage))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if msg.From != "alice@example.com" || msg.Subject != "Café notes" || msg.MessageID != "abc123@example.com" {
+		t.Errorf("headers = %q %q %q", msg.From, msg.Subject, msg.MessageID)
+	}
+	if !strings.HasPrefix(msg.Text, "Plain version") || !strings.Contains(msg.HTML, `src="cid:pic1"`) {
+		t.Errorf("bodies = %q / %q", msg.Text, msg.HTML)
+	}
+	if len(msg.Parts) != 2 || string(msg.Parts[0].Data) != "PNGDATA" || msg.Parts[0].ContentID != "pic1" {
+		t.Fatalf("parts = %+v", msg.Parts)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	msg, err := Parse(strings.NewReader(htmlMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Publish(dataDir, msg, ParseAllow("bob@example.com"), privKey); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("unlisted sender: err = %v", err)
+	}
+
+	allow := ParseAllow("bob@example.com, alice@example.com")
+	result, err := Publish(dataDir, msg, allow, privKey)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Title != "Café notes" {
+		t.Errorf("title = %q", result.Title)
+	}
+	post, _ := os.ReadFile(filepath.Join(dataDir, result.Path))
+	for _, want := range []string{"Hello **world**.", "![cup](/attachments/cup-", "![extra](/attachments/extra-"} {
+		if !strings.Contains(string(post), want) {
+			t.Errorf("post missing %q:\n%s", want, post)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dataDir, "attachments")); len(entries) != 2 {
+		t.Errorf("attachments = %d, want 2", len(entries))
+	}
+
+	if _, err := Publish(dataDir, msg, allow, privKey); !errors.Is(err, ErrAlreadyPublished) {
+		t.Errorf("second publish: err = %v", err)
+	}
+
+	forged := *msg
+	forged.MessageID = "other@example.com"
+	forged.AuthResults = "mx.example.net; dmarc=fail header.from=example.com"
+	if _, err := Publish(dataDir, &forged, allow, privKey); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("forged sender: err = %v", err)
+	}
+}
+
+func TestMarkdown_PlainTextWithFrontmatterAndSignature(t *testing.T) {
+	msg := &Message{
+		Subject: "Quick note",
+		Text:    "---\r\nslug: quick\r\n---\r\nJust a line.\r\n\r\n-- \r\nAlice\r\n",
+	}
+	got, err := Markdown(t.TempDir(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\nslug: quick\n---\n# Quick note\n\nJust a line.\n"
+	if got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+	if _, err := Markdown(t.TempDir(), &Message{Text: "-- \nsig only"}); !errors.Is(err, ErrEmpty) {
+		t.Errorf("empty message: err = %v", err)
+	}
+}
+
+// fakeIMAP answers one IMAP session over a pipe, serving messages by UID
+// and recording the UIDs marked seen.
+func fakeIMAP(t *testing.T, messages map[string]string, seen *[]string) {
+	t.Helper()
+	server, client := net.Pipe()
+	orig := dialIMAP
+	dialIMAP = func(string) (net.Conn, error) { return client, nil }
+	t.Cleanup(func() { dialIMAP = orig; server.Close() })
+
+	go func() {
+		r := bufio.NewReader(server)
+		fmt.Fprint(server, "* OK ready\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			tag, cmd, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+			switch {
+			case strings.HasPrefix(cmd, "LOGIN"):
+				if cmd != `LOGIN "me" "p\"w"` {
+					fmt.Fprintf(server, "%s NO bad credentials\r\n", tag)
+					continue
+				}
+			case cmd == "UID SEARCH UNSEEN":
+				fmt.Fprint(server, "* SEARCH 7 9\r\n")
+			case strings.HasPrefix(cmd, "UID FETCH "):
+				uid := strings.Fields(cmd)[2]
+				raw := messages[uid]
+				fmt.Fprintf(server, "* 1 FETCH (UID %s BODY[] {%d}\r\n%s)\r\n", uid, len(raw), raw)
+			case strings.HasPrefix(cmd, "UID STORE "):
+				*seen = append(*seen, strings.Fields(cmd)[2])
+			case cmd == "LOGOUT":
+				fmt.Fprintf(server, "* BYE\r\n%s OK bye\r\n", tag)
+				return
+			}
+			fmt.Fprintf(server, "%s OK done\r\n", tag)
+		}
+	}()
+}
+
+func TestFetchUnseen(t *testing.T) {
+	messages := map[string]string{
+		"7": "From: a@example.com\r\nSubject: one\r\n\r\nfirst\r\n",
+		"9": "From: b@example.com\r\nSubject: two\r\n\r\nsecond\r\n",
+	}
+	var seen []string
+	fakeIMAP(t, messages, &seen)
+
+	var subjects []string
+	cfg := IMAPConfig{Host: "imap.example.com", Username: "me", Password: `p"w`}
+	err := FetchUnseen(cfg, func(raw []byte) bool {
+		msg, err := Parse(strings.NewReader(string(raw)))
+		if err != nil {
+			t.Errorf("Parse: %v", err)
+			return false
+		}
+		subjects = append(subjects, msg.Subject)
+		return msg.From == "a@example.com"
+	})
+	if err != nil {
+		t.Fatalf("FetchUnseen failed: %v", err)
+	}
+	if strings.Join(subjects, ",") != "one,two" {
+		t.Errorf("subjects = %v", subjects)
+	}
+	if strings.Join(seen, ",") != "7" {
+		t.Errorf("marked seen = %v, want only 7", seen)
+	}
+}
+
+func TestFetchUnseen_LoginFailure(t *testing.T) {
+	var seen []string
+	fakeIMAP(t, nil, &seen)
+	cfg := IMAPConfig{Host: "imap.example.com", Username: "me", Password: "wrong"}
+	if err := FetchUnseen(cfg, func([]byte) bool { return true }); err == nil || !strings.Contains(err.Error(), "login failed") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package ingest turns email into posts: blogging by email.
+//
+// A message arrives either piped to `polis ingest mail` (from a mail
+// server's delivery rule, procmail, or a .forward file) or fetched by
+// `polis ingest imap` from a mailbox. Messages from senders on the
+// ingest.allow list are converted to markdown, with HTML mail reduced to
+// markdown and images stored in the site's attachments/ directory, and
+// published as posts. The subject becomes the title. Message-IDs already
+// published are recorded in .polis/ingest.json so a message is never
+// posted twice.
+package ingest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxMessageSize bounds a message read from stdin or IMAP.
+const MaxMessageSize = 25 << 20
+
+// maxDepth bounds multipart nesting.
+const maxDepth = 8
+
+// Message is a parsed email.
+type Message struct {
+	MessageID string
+	From      string // Sender address, lowercased
+	Subject   string
+	Date      time.Time
+	Text      string // First text/plain body
+	HTML      string // First text/html body
+	Parts     []Part // Attachments and inline images
+
+	// AuthResults is the Authentication-Results header added by the
+	// receiving server, if any.
+	AuthResults string
+}
+
+// Part is an attachment or inline image.
+type Part struct {
+	Filename    string
+	ContentType string
+	ContentID   string // Without angle brackets
+	Data        []byte
+}
+
+// IsImage reports whether the part is an image.
+func (p Part) IsImage() bool {
+	return strings.HasPrefix(p.ContentType, "image/")
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// Parse reads an RFC 5322 message.
+func Parse(r io.Reader) (*Message, error) {
+	msg, err := mail.ReadMessage(io.LimitReader(r, MaxMessageSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	parser := mail.AddressParser{WordDecoder: headerDecoder}
+	from, err := parser.Parse(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %w", err)
+	}
+	m := &Message{
+		MessageID:   strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		From:        strings.ToLower(from.Address),
+		AuthResults: msg.Header.Get("Authentication-Results"),
+	}
+	m.Subject = msg.Header.Get("Subject")
+	if dec, err := headerDecoder.DecodeHeader(m.Subject); err == nil {
+		m.Subject = dec
+	}
+	m.Subject = strings.Join(strings.Fields(m.Subject), " ")
+	if d, err := msg.Header.Date(); err == nil {
+		m.Date = d
+	}
+	if err := m.walk(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// walk collects the bodies and attachments of one MIME entity.
+func (m *Message) walk(h textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	body = decodeTransfer(h.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxDepth {
+			return errors.New("invalid message: multipart nesting too deep")
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid message: %w", err)
+			}
+			if err := m.walk(p.Header, p, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if dec, err := headerDecoder.DecodeHeader(filename); err == nil {
+		filename = dec
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	if disposition != "attachment" && filename == "" {
+		switch mediaType {
+		case "text/plain":
+			if m.Text == "" {
+				m.Text = decodeCharset(data, params["charset"])
+			}
+			return nil
+		case "text/html":
+			if m.HTML == "" {
+				m.HTML = decodeCharset(data, params["charset"])
+			}
+			return nil
+		}
+	}
+	m.Parts = append(m.Parts, Part{
+		Filename:    filename,
+		ContentType: mediaType,
+		ContentID:   strings.Trim(strings.TrimSpace(h.Get("Content-Id")), "<>"),
+		Data:        data,
+	})
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// decodeCharset converts a text body to UTF-8. Latin-1 and Windows-1252
+// are converted; other charsets are assumed to be UTF-8-compatible.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		if !utf8.Valid(data) {
+			return latin1(data)
+		}
+	}
+	return strings.ToValidUTF8(string(data), "�")
+}
+
+func latin1(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader([]byte(decodeCharset(data, charset))), nil
+}
@@ -0,0 +1,109 @@
+package ipfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the RPC address of a local IPFS (Kubo) node.
+const DefaultAPI = "http://127.0.0.1:5001"
+
+// Client talks to the RPC API of an IPFS node: a local Kubo daemon, or a
+// remote node or pinning service that speaks the same /api/v0 calls.
+type Client struct {
+	API        string
+	Token      string // Sent as a bearer token when set
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the node at api (DefaultAPI if empty).
+// The node is configured by the site owner and is usually on localhost, so
+// the client isn't restricted to public addresses.
+func NewClient(api, token string) *Client {
+	if api == "" {
+		api = DefaultAPI
+	}
+	return &Client{
+		API:        strings.TrimSuffix(api, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// File is a file to add.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// addEntry is one line of the add call's newline-delimited JSON response.
+type addEntry struct {
+	Name string `json:"Name"`
+	Hash string `json:"Hash"`
+}
+
+// apiError is the error body the RPC API returns.
+type apiError struct {
+	Message string `json:"Message"`
+}
+
+// AddDir adds files, wrapped in a directory, and pins them. It returns the
+// directory's CID (CIDv1), under which each file is reachable by name.
+func (c *Client) AddDir(files []File) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, f := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, f.Name))
+		h.Set("Content-Type", "application/octet-stream")
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return "", err
+		}
+		part.Write(f.Data)
+	}
+	mw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, c.API+"/api/v0/add?cid-version=1&wrap-with-directory=true&pin=true", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("IPFS node unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return "", fmt.Errorf("IPFS add failed: %s", apiErr.Message)
+		}
+		return "", fmt.Errorf("IPFS add failed: HTTP %d", resp.StatusCode)
+	}
+
+	// One entry per file, then the wrapping directory, which has no name
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var e addEntry
+		if err := dec.Decode(&e); err != nil {
+			return "", fmt.Errorf("failed to parse IPFS response: %w", err)
+		}
+		if e.Name == "" && e.Hash != "" {
+			return e.Hash, nil
+		}
+	}
+	return "", fmt.Errorf("IPFS response has no directory CID")
+}
@@ -0,0 +1,104 @@
+// Package ipfs pins published posts to an IPFS node.
+//
+// When an IPFS node is configured, each published public post is added to
+// it together with its rendered HTML page, wrapped in a directory so both
+// keep their file names. The directory's CID is recorded in
+// metadata/syndication.json and in the post's public.jsonl entry, and
+// rendered pages link to the copy with an ipfs:// alternate link. A CID
+// names one version of a post; republishing pins the new version under a
+// new CID and leaves the old one pinned.
+package ipfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// Service is the service name recorded in syndication.json.
+const Service = "ipfs"
+
+// ErrNotPublic is returned for unlisted and followers-only posts.
+var ErrNotPublic = errors.New("only public posts are pinned to IPFS")
+
+// Config is the IPFS node to pin to.
+type Config struct {
+	API   string // RPC address, e.g. http://127.0.0.1:5001; empty disables pinning
+	Token string // Bearer token for a remote node or pinning service
+}
+
+// Enabled reports whether pinning is configured.
+func (c Config) Enabled() bool {
+	return c.API != ""
+}
+
+// Pin adds a published post and its rendered page to the IPFS node and
+// records the CID. A post already pinned at its current version is only
+// pinned again if its page has been rendered since.
+func Pin(dataDir string, cfg Config, postPath string) (*metadata.SyndicationLink, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("no IPFS node configured (set ipfs.api)")
+	}
+	postPath = filepath.ToSlash(postPath)
+	if publish.IsProtectedPath(postPath) {
+		return nil, ErrNotPublic
+	}
+	mdPath := filepath.Join(dataDir, filepath.FromSlash(postPath))
+	content, err := os.ReadFile(mdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post: %w", err)
+	}
+	fm := publish.ParseFrontmatterFields(string(content))
+	if fm.Visibility != publish.VisibilityPublic {
+		return nil, ErrNotPublic
+	}
+
+	name := path.Base(postPath)
+	files := []File{{Name: name, Data: content}}
+	page := strings.TrimSuffix(name, ".md") + ".html"
+	if html, err := os.ReadFile(strings.TrimSuffix(mdPath, ".md") + ".html"); err == nil {
+		files = append(files, File{Name: page, Data: html})
+	}
+
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if link, ok := synd.Link(postPath, Service); ok && link.Version == fm.CurrentVersion &&
+		strings.HasSuffix(link.URL, ".html") == (len(files) > 1) {
+		return &link, nil
+	}
+
+	cid, err := NewClient(cfg.API, cfg.Token).AddDir(files)
+	if err != nil {
+		return nil, err
+	}
+	target := name
+	if len(files) > 1 {
+		target = page
+	}
+	link := metadata.SyndicationLink{
+		Service: Service,
+		URL:     "ipfs://" + cid + "/" + target,
+		CID:     cid,
+		Version: fm.CurrentVersion,
+		Posted:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	synd.SetLink(postPath, link)
+	if err := metadata.SaveSyndication(dataDir, synd); err != nil {
+		return &link, fmt.Errorf("pinned, but failed to save %s: %w", metadata.SyndicationFilename, err)
+	}
+	if err := metadata.ModifyIndexEntry(dataDir, postPath, func(e *metadata.IndexEntry) {
+		e.IPFS = cid
+	}); err != nil {
+		return &link, fmt.Errorf("pinned, but failed to update public.jsonl: %w", err)
+	}
+	return &link, nil
+}
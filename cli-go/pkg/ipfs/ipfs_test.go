@@ -0,0 +1,103 @@
+package ipfs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// fakeNode serves /api/v0/add, recording the file names of each call and
+// answering with a directory CID numbered by call.
+func fakeNode(t *testing.T, calls *[][]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/add" || r.URL.Query().Get("wrap-with-directory") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"Message":"invalid token","Code":0,"Type":"error"}`))
+			return
+		}
+		mr, err := r.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			names = append(names, part.FileName())
+			fmt.Fprintf(w, `{"Name":%q,"Hash":"bafyfile%d"}`+"\n", part.FileName(), len(names))
+		}
+		*calls = append(*calls, names)
+		fmt.Fprintf(w, `{"Name":"","Hash":"bafydir%d"}`+"\n", len(*calls))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPin(t *testing.T) {
+	var calls [][]string
+	node := fakeNode(t, &calls)
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	result, err := publish.PublishPostWithOptions(dataDir, "# Hello\n\nBody.\n", publish.PublishOptions{Slug: "hello"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Pin(dataDir, Config{API: node.URL, Token: "bad"}, result.Path); err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Fatalf("bad token: err = %v", err)
+	}
+
+	cfg := Config{API: node.URL, Token: "tok"}
+	link, err := Pin(dataDir, cfg, result.Path)
+	if err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if link.CID != "bafydir1" || link.URL != "ipfs://bafydir1/hello.md" || link.Version != result.Version {
+		t.Errorf("link = %+v", link)
+	}
+	entries, _ := metadata.LoadPublicIndex(dataDir)
+	if len(entries) != 1 || entries[0].IPFS != "bafydir1" {
+		t.Errorf("public.jsonl = %+v", entries)
+	}
+
+	// Pinned at this version already: no new add
+	if _, err := Pin(dataDir, cfg, result.Path); err != nil || len(calls) != 1 {
+		t.Errorf("repin: err = %v, %d calls", err, len(calls))
+	}
+
+	// Once the page is rendered it is pinned alongside the markdown
+	os.WriteFile(filepath.Join(dataDir, strings.TrimSuffix(result.Path, ".md")+".html"), []byte("<p>Body.</p>"), 0644)
+	link, err = Pin(dataDir, cfg, result.Path)
+	if err != nil || link.URL != "ipfs://bafydir2/hello.html" {
+		t.Fatalf("pin with page: link = %+v, err = %v", link, err)
+	}
+	if got := strings.Join(calls[1], ","); got != "hello.md,hello.html" {
+		t.Errorf("added %s", got)
+	}
+	synd, _ := metadata.LoadSyndication(dataDir)
+	if len(synd.Posts[result.Path]) != 1 {
+		t.Errorf("syndication.json = %+v", synd.Posts)
+	}
+
+	unlisted, _ := publish.PublishPostWithOptions(dataDir, "# Quiet\n\nShh.\n", publish.PublishOptions{Unlisted: true}, privKey)
+	if _, err := Pin(dataDir, cfg, unlisted.Path); !errors.Is(err, ErrNotPublic) {
+		t.Errorf("unlisted post: err = %v", err)
+	}
+}
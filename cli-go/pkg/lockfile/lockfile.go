@@ -0,0 +1,165 @@
+// Package lockfile provides advisory locks that serialize read-modify-write
+// updates to shared data files (public.jsonl, manifest.json, the feed cache,
+// stream state) between the CLI and a running serve or daemon process.
+//
+// A lock is a directory created next to the protected file (path + ".lock").
+// os.Mkdir is atomic on every platform and network filesystem Polis
+// supports, so whichever process creates the directory holds the lock. The
+// directory contains an owner file recording the holder's pid and host;
+// locks whose holder has exited, or that are older than StaleAfter, are
+// broken automatically.
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+)
+
+// Suffix is appended to the protected file's path to name its lock directory.
+const Suffix = ".lock"
+
+const ownerFile = "owner.json"
+
+// Package configuration.
+var (
+	// StaleAfter is how old a lock must be before it is broken regardless of
+	// whether its holder still appears to be alive. Every locked section in
+	// Polis is a single small file rewrite, so this is generous.
+	StaleAfter = 2 * time.Minute
+
+	// Timeout bounds how long Acquire waits for a held lock.
+	Timeout = 10 * time.Second
+
+	// pollInterval is how often Acquire retries a held lock.
+	pollInterval = 25 * time.Millisecond
+)
+
+// ErrTimeout is returned when a lock cannot be acquired within Timeout.
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// Owner identifies the process holding a lock.
+type Owner struct {
+	PID     int    `json:"pid"`
+	Host    string `json:"host"`
+	Created string `json:"created"`
+}
+
+// Lock is a held lock. Call Release when the update is finished.
+type Lock struct {
+	dir string
+}
+
+// Acquire locks path, waiting up to Timeout for another holder to release
+// it. path is the file being protected; it need not exist.
+func Acquire(path string) (*Lock, error) {
+	dir := path + Suffix
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			writeOwner(dir)
+			return &Lock{dir: dir}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock %s: %w", dir, err)
+		}
+		if breakStale(dir) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrTimeout, dir)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release removes the lock. Releasing a lock twice is a no-op.
+func (l *Lock) Release() error {
+	if l == nil || l.dir == "" {
+		return nil
+	}
+	dir := l.dir
+	l.dir = ""
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", dir, err)
+	}
+	return nil
+}
+
+// With runs fn while holding the lock for path.
+func With(path string, fn func() error) error {
+	l, err := Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return fn()
+}
+
+// ReadOwner returns the recorded holder of the lock for path, if any.
+func ReadOwner(path string) (*Owner, error) {
+	data, err := os.ReadFile(filepath.Join(path+Suffix, ownerFile))
+	if err != nil {
+		return nil, err
+	}
+	var o Owner
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func writeOwner(dir string) {
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(Owner{
+		PID:     os.Getpid(),
+		Host:    host,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	})
+	os.WriteFile(filepath.Join(dir, ownerFile), data, 0644)
+}
+
+// breakStale removes dir if its holder is gone or it has outlived
+// StaleAfter. It reports whether the caller should retry immediately.
+func breakStale(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		// Released between our Mkdir and Stat.
+		return os.IsNotExist(err)
+	}
+
+	reason := ""
+	if time.Since(info.ModTime()) > StaleAfter {
+		reason = "older than " + StaleAfter.String()
+	} else if data, err := os.ReadFile(filepath.Join(dir, ownerFile)); err == nil {
+		var o Owner
+		host, _ := os.Hostname()
+		if json.Unmarshal(data, &o) == nil && o.PID > 0 && o.Host == host && !processAlive(o.PID) {
+			reason = "holder pid " + strconv.Itoa(o.PID) + " has exited"
+		}
+	}
+	if reason == "" {
+		return false
+	}
+
+	// Rename first: only one of several waiters breaking the same stale
+	// lock wins the rename, and the directory disappears in one step.
+	tomb := fmt.Sprintf("%s.stale-%d-%d", dir, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(dir, tomb); err != nil {
+		return false
+	}
+	os.RemoveAll(tomb)
+	logging.Warn("broke stale lock", "lock", dir, "reason", reason)
+	return true
+}
@@ -0,0 +1,119 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func withTimeouts(t *testing.T, timeout, stale time.Duration) {
+	t.Helper()
+	oldTimeout, oldStale := Timeout, StaleAfter
+	Timeout, StaleAfter = timeout, stale
+	t.Cleanup(func() { Timeout, StaleAfter = oldTimeout, oldStale })
+}
+
+func TestWith_SerializesUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata", "counter")
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := With(path, func() error {
+				n := counter
+				time.Sleep(time.Millisecond)
+				counter = n + 1
+				return nil
+			})
+			if err != nil {
+				t.Errorf("With: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter != 20 {
+		t.Errorf("counter = %d, want 20", counter)
+	}
+	if _, err := os.Stat(path + Suffix); !os.IsNotExist(err) {
+		t.Errorf("lock directory left behind: %v", err)
+	}
+}
+
+func TestAcquire_TimesOutWhileHeld(t *testing.T) {
+	withTimeouts(t, 100*time.Millisecond, time.Hour)
+	path := filepath.Join(t.TempDir(), "public.jsonl")
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer l.Release()
+
+	if _, err := Acquire(path); !errors.Is(err, ErrTimeout) {
+		t.Errorf("second Acquire error = %v, want ErrTimeout", err)
+	}
+	owner, err := ReadOwner(path)
+	if err != nil || owner.PID != os.Getpid() {
+		t.Errorf("ReadOwner = %+v, %v", owner, err)
+	}
+}
+
+func TestAcquire_BreaksLockOlderThanStaleAfter(t *testing.T) {
+	withTimeouts(t, time.Second, time.Minute)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	if err := os.Mkdir(path+Suffix, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path+Suffix, old, old)
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	l.Release()
+}
+
+func TestAcquire_BreaksLockOfExitedProcess(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Skip("holder liveness is not checked on this platform")
+	}
+	withTimeouts(t, time.Second, time.Hour)
+	path := filepath.Join(t.TempDir(), "feed-cache.jsonl")
+
+	if err := os.Mkdir(path+Suffix, 0755); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	// PIDs this large are never allocated.
+	data, _ := json.Marshal(Owner{PID: 1 << 30, Host: host})
+	os.WriteFile(filepath.Join(path+Suffix, ownerFile), data, 0644)
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	l.Release()
+}
+
+func TestRelease_Idempotent(t *testing.T) {
+	l, err := Acquire(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(); err != nil {
+		t.Errorf("second Release: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package lockfile
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether pid is a running process. EPERM means the
+// process exists but belongs to another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,474 @@
+// Package logging provides the structured logger shared by the CLI and webapp.
+//
+// Log records are written as JSON lines to logs/polis.log under the site
+// directory, rotated by size, and mirrored to the console in the familiar
+// "[warning] message" format. Packages under pkg/ log through the package
+// default (see SetDefault) so the webapp can route their output to its file.
+package logging
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// FileName is the active log file inside the logs directory.
+	FileName = "polis.log"
+
+	// DefaultMaxSize is the size at which the active log file is rotated.
+	DefaultMaxSize = 5 << 20
+
+	// DefaultMaxFiles is the number of rotated files kept (polis.log.1 .. N).
+	DefaultMaxFiles = 5
+)
+
+// ParseLevel converts a level name to an slog level. It accepts debug, info,
+// warn (or warning) and error, case-insensitively.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q (expected debug, info, warn, or error)", s)
+}
+
+// LevelFromVerbosity maps the webapp's numeric log_level setting (0 = off,
+// 1 = basic, 2 = verbose) to an slog level. The bool is false when logging
+// to file is off.
+func LevelFromVerbosity(n int) (slog.Level, bool) {
+	switch {
+	case n <= 0:
+		return slog.LevelInfo, false
+	case n == 1:
+		return slog.LevelInfo, true
+	default:
+		return slog.LevelDebug, true
+	}
+}
+
+// Options configures New.
+type Options struct {
+	Dir          string     // logs directory; empty disables the file output
+	Level        slog.Level // minimum level written to the file
+	MaxSize      int64      // rotate after this many bytes (default DefaultMaxSize)
+	MaxFiles     int        // rotated files to keep (default DefaultMaxFiles)
+	Console      io.Writer  // console output; nil disables it
+	ConsoleLevel slog.Level // minimum level written to the console
+	ConsoleTime  bool       // prefix console lines with a timestamp
+}
+
+// Logger is an slog.Logger that owns its log file.
+type Logger struct {
+	*slog.Logger
+	file *RotatingWriter
+}
+
+// New creates a logger writing JSON lines to Dir and text to Console.
+func New(opts Options) (*Logger, error) {
+	var handlers []slog.Handler
+	l := &Logger{}
+	if opts.Dir != "" {
+		w, err := NewRotatingWriter(filepath.Join(opts.Dir, FileName), opts.MaxSize, opts.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		l.file = w
+		handlers = append(handlers, slog.NewJSONHandler(w, &slog.HandlerOptions{Level: opts.Level}))
+	}
+	if opts.Console != nil {
+		handlers = append(handlers, NewConsoleHandler(opts.Console, opts.ConsoleLevel, opts.ConsoleTime))
+	}
+	l.Logger = slog.New(fanout(handlers))
+	return l, nil
+}
+
+// FileEnabled reports whether the logger writes to a log file.
+func (l *Logger) FileEnabled() bool {
+	return l != nil && l.file != nil
+}
+
+// Close closes the log file, if any.
+func (l *Logger) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Package default, used by pkg/* for warnings that have no caller to return to.
+var std atomic.Pointer[slog.Logger]
+
+func init() {
+	std.Store(slog.New(NewConsoleHandler(os.Stderr, slog.LevelInfo, false)))
+}
+
+// Default returns the package default logger.
+func Default() *slog.Logger {
+	return std.Load()
+}
+
+// SetDefault replaces the package default logger.
+func SetDefault(l *slog.Logger) {
+	if l != nil {
+		std.Store(l)
+	}
+}
+
+// Debug logs at debug level on the package default logger.
+func Debug(msg string, args ...any) { Default().Debug(msg, args...) }
+
+// Info logs at info level on the package default logger.
+func Info(msg string, args ...any) { Default().Info(msg, args...) }
+
+// Warn logs at warn level on the package default logger.
+func Warn(msg string, args ...any) { Default().Warn(msg, args...) }
+
+// Error logs at error level on the package default logger.
+func Error(msg string, args ...any) { Default().Error(msg, args...) }
+
+// ============================================================================
+// Rotating file writer
+// ============================================================================
+
+// RotatingWriter appends to a file and rotates it once it reaches maxSize,
+// shifting older files to path.1, path.2, ... up to maxFiles.
+type RotatingWriter struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingWriter opens (or creates) path for appending.
+func NewRotatingWriter(path string, maxSize int64, maxFiles int) (*RotatingWriter, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotatingWriter) rotate() error {
+	w.file.Close()
+	w.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxFiles))
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the underlying file.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+// consoleHandler writes "[warning] msg key=value" lines, optionally prefixed
+// with a "2006/01/02 15:04:05" timestamp like the standard log package.
+type consoleHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Level
+	time  bool
+	attrs []slog.Attr
+	group string
+}
+
+// NewConsoleHandler returns a handler that writes human-readable lines to w.
+func NewConsoleHandler(w io.Writer, level slog.Level, timestamps bool) slog.Handler {
+	return &consoleHandler{w: w, mu: &sync.Mutex{}, level: level, time: timestamps}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	if h.time {
+		b.WriteString(r.Time.Format("2006/01/02 15:04:05"))
+		b.WriteString(" ")
+	}
+	b.WriteString(levelTag(r.Level))
+	b.WriteString(" ")
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, h.group, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	if c.group != "" {
+		name = c.group + "." + name
+	}
+	c.group = name
+	return &c
+}
+
+func levelTag(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "[error]"
+	case l >= slog.LevelWarn:
+		return "[warning]"
+	case l >= slog.LevelInfo:
+		return "[i]"
+	default:
+		return "[debug]"
+	}
+}
+
+func writeAttr(b *strings.Builder, group string, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := a.Key
+	if group != "" {
+		key = group + "." + key
+	}
+	v := a.Value.Resolve().String()
+	if strings.ContainsAny(v, " \t\"=") {
+		v = strconv.Quote(v)
+	}
+	b.WriteString(" ")
+	b.WriteString(key)
+	b.WriteString("=")
+	b.WriteString(v)
+}
+
+// multiHandler sends each record to every handler that accepts its level.
+type multiHandler []slog.Handler
+
+func fanout(handlers []slog.Handler) slog.Handler {
+	if len(handlers) == 1 {
+		return handlers[0]
+	}
+	return multiHandler(handlers)
+}
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// ============================================================================
+// Reading logs back
+// ============================================================================
+
+// Entry is one parsed log record.
+type Entry struct {
+	Time  time.Time              `json:"time"`
+	Level string                 `json:"level"`
+	Msg   string                 `json:"msg"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// Query filters Read results.
+type Query struct {
+	Level slog.Level // minimum level
+	Since time.Time  // only entries at or after this time (zero = all)
+	Limit int        // most recent N entries (0 = no limit)
+}
+
+// Read returns entries from the active and rotated log files in dir, oldest
+// first, filtered by q. Lines that are not JSON records are skipped.
+func Read(dir string, q Query) ([]Entry, error) {
+	active := filepath.Join(dir, FileName)
+	matches, _ := filepath.Glob(active + ".*")
+	var rotated []int
+	for _, m := range matches {
+		if n, err := strconv.Atoi(strings.TrimPrefix(m, active+".")); err == nil && n > 0 {
+			rotated = append(rotated, n)
+		}
+	}
+	// Rotated files hold older records; read highest suffix first
+	sort.Sort(sort.Reverse(sort.IntSlice(rotated)))
+	paths := make([]string, 0, len(rotated)+1)
+	for _, n := range rotated {
+		paths = append(paths, fmt.Sprintf("%s.%d", active, n))
+	}
+	paths = append(paths, active)
+
+	var entries []Entry
+	for _, path := range paths {
+		got, err := readFile(path, q)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, got...)
+	}
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[len(entries)-q.Limit:]
+	}
+	return entries, nil
+}
+
+func readFile(path string, q Query) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			continue
+		}
+		e := Entry{}
+		if s, ok := raw[slog.TimeKey].(string); ok {
+			e.Time, _ = time.Parse(time.RFC3339Nano, s)
+		}
+		e.Level, _ = raw[slog.LevelKey].(string)
+		e.Msg, _ = raw[slog.MessageKey].(string)
+		delete(raw, slog.TimeKey)
+		delete(raw, slog.LevelKey)
+		delete(raw, slog.MessageKey)
+		if len(raw) > 0 {
+			e.Attrs = raw
+		}
+
+		if lvl, err := ParseLevel(e.Level); err == nil && lvl < q.Level {
+			continue
+		}
+		if !q.Since.IsZero() && e.Time.Before(q.Since) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
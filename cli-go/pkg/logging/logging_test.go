@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+		ok   bool
+	}{
+		{"debug", slog.LevelDebug, true},
+		{"INFO", slog.LevelInfo, true},
+		{"warning", slog.LevelWarn, true},
+		{"warn", slog.LevelWarn, true},
+		{"error", slog.LevelError, true},
+		{"", slog.LevelInfo, true},
+		{"loud", slog.LevelInfo, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestNew_FileAndConsole(t *testing.T) {
+	dir := t.TempDir()
+	var console bytes.Buffer
+	l, err := New(Options{Dir: dir, Level: slog.LevelDebug, Console: &console, ConsoleLevel: slog.LevelWarn})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close()
+
+	l.Debug("checking", "domain", "alice.polis.pub")
+	l.Warn("render failed", "err", "boom")
+
+	out := console.String()
+	if strings.Contains(out, "checking") {
+		t.Errorf("debug record reached console: %q", out)
+	}
+	if !strings.Contains(out, "[warning] render failed err=boom") {
+		t.Errorf("console = %q", out)
+	}
+
+	entries, err := Read(dir, Query{Level: slog.LevelDebug})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Msg != "checking" || entries[0].Attrs["domain"] != "alice.polis.pub" {
+		t.Errorf("entry[0] = %+v", entries[0])
+	}
+
+	warn, _ := Read(dir, Query{Level: slog.LevelWarn})
+	if len(warn) != 1 || warn[0].Level != "WARN" {
+		t.Errorf("warn filter = %+v", warn)
+	}
+	future, _ := Read(dir, Query{Since: time.Now().Add(time.Hour)})
+	if len(future) != 0 {
+		t.Errorf("since filter returned %d entries", len(future))
+	}
+}
+
+func TestRotatingWriter_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	w, err := NewRotatingWriter(path, 64, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	line := []byte(`{"time":"2026-01-01T00:00:00Z","level":"INFO","msg":"0123456789"}` + "\n")
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{FileName, FileName + ".1", FileName + ".2"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName+".3")); !os.IsNotExist(err) {
+		t.Error("expected only maxFiles rotated files to be kept")
+	}
+
+	entries, err := Read(dir, Query{Limit: 2})
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Read with limit = %d entries, %v", len(entries), err)
+	}
+}
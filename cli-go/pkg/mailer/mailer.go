@@ -0,0 +1,351 @@
+// Package mailer sends owner notification emails over SMTP.
+//
+// Items (new blessing requests, pending comments) are queued on disk in
+// .polis/email-queue.json and flushed as a single digest email at most once
+// per batch window, so a burst of activity produces one message instead of many.
+package mailer
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// DefaultBatchMinutes is the minimum interval between notification emails.
+const DefaultBatchMinutes = 15
+
+// QueueFilename is the on-disk queue, relative to .polis/.
+const QueueFilename = "email-queue.json"
+
+// Config holds SMTP settings. The password is never persisted in JSON; it is
+// supplied at runtime (SMTP_PASSWORD in .env or the environment).
+type Config struct {
+	Enabled      bool   `json:"enabled"`
+	Host         string `json:"host"`
+	Port         int    `json:"port,omitempty"` // default 587 (STARTTLS); 465 uses implicit TLS
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"-"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	BatchMinutes int    `json:"batch_minutes,omitempty"` // default DefaultBatchMinutes
+}
+
+// Validate checks that the config has enough information to send mail.
+func (c *Config) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("smtp host is required")
+	}
+	if c.From == "" {
+		return fmt.Errorf("from address is required")
+	}
+	if c.To == "" {
+		return fmt.Errorf("to address is required")
+	}
+	return nil
+}
+
+// LoadConfig reads the email settings polis serve keeps under "email" in
+// .polis/webapp-config.json, with the password from SMTP_PASSWORD. It
+// returns nil when the site has no email settings.
+func LoadConfig(dataDir string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, ".polis", "webapp-config.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var wc struct {
+		Email *Config `json:"email"`
+	}
+	if err := json.Unmarshal(data, &wc); err != nil {
+		return nil, fmt.Errorf("failed to parse webapp-config.json: %w", err)
+	}
+	if wc.Email != nil {
+		wc.Email.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	return wc.Email, nil
+}
+
+// port returns the configured port or the STARTTLS default.
+func (c *Config) port() int {
+	if c.Port > 0 {
+		return c.Port
+	}
+	return 587
+}
+
+// batchWindow returns the configured batch interval.
+func (c *Config) batchWindow() time.Duration {
+	if c.BatchMinutes > 0 {
+		return time.Duration(c.BatchMinutes) * time.Minute
+	}
+	return DefaultBatchMinutes * time.Minute
+}
+
+// Item is a single event worth emailing about.
+type Item struct {
+	Kind      string `json:"kind"`                 // "blessing_request" or "comment"
+	Actor     string `json:"actor"`                // Domain that caused the event
+	URL       string `json:"url"`                  // Comment URL
+	TargetURL string `json:"target_url,omitempty"` // Post the comment replies to
+	At        string `json:"at"`                   // Event timestamp
+}
+
+// queueFile is the on-disk format for the email queue.
+type queueFile struct {
+	Items    []Item `json:"items"`
+	LastSent string `json:"last_sent,omitempty"`
+}
+
+// SendFunc delivers a composed message. Replaceable for tests.
+type SendFunc func(cfg Config, subject, body string) error
+
+// Notifier queues items and flushes them as batched emails.
+type Notifier struct {
+	cfg       Config
+	queuePath string
+	Send      SendFunc
+}
+
+// NewNotifier creates a notifier whose queue lives under dataDir/.polis/.
+func NewNotifier(dataDir string, cfg Config) *Notifier {
+	return &Notifier{
+		cfg:       cfg,
+		queuePath: filepath.Join(dataDir, ".polis", QueueFilename),
+		Send:      SendSMTP,
+	}
+}
+
+// Enqueue adds items to the queue, skipping URLs already queued.
+func (n *Notifier) Enqueue(items []Item) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	q, err := n.load()
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool, len(q.Items))
+	for _, it := range q.Items {
+		seen[it.Kind+"|"+it.URL] = true
+	}
+	added := 0
+	for _, it := range items {
+		key := it.Kind + "|" + it.URL
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		q.Items = append(q.Items, it)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, n.save(q)
+}
+
+// Pending returns the number of queued items.
+func (n *Notifier) Pending() int {
+	q, err := n.load()
+	if err != nil {
+		return 0
+	}
+	return len(q.Items)
+}
+
+// Flush sends one digest email if items are queued and the batch window has
+// elapsed since the last send. Returns the number of items delivered.
+func (n *Notifier) Flush(now time.Time) (int, error) {
+	if !n.cfg.Enabled {
+		return 0, nil
+	}
+	q, err := n.load()
+	if err != nil {
+		return 0, err
+	}
+	if len(q.Items) == 0 {
+		return 0, nil
+	}
+	if q.LastSent != "" {
+		if last, err := time.Parse(time.RFC3339, q.LastSent); err == nil && now.Sub(last) < n.cfg.batchWindow() {
+			return 0, nil
+		}
+	}
+	if err := n.cfg.Validate(); err != nil {
+		return 0, err
+	}
+
+	subject, body := Compose(q.Items)
+	if err := n.Send(n.cfg, subject, body); err != nil {
+		return 0, fmt.Errorf("send failed: %w", err)
+	}
+
+	sent := len(q.Items)
+	q.Items = nil
+	q.LastSent = now.UTC().Format(time.RFC3339)
+	return sent, n.save(q)
+}
+
+// Compose builds the subject and plain-text body for a batch of items.
+func Compose(items []Item) (string, string) {
+	requests := 0
+	for _, it := range items {
+		if it.Kind == "blessing_request" {
+			requests++
+		}
+	}
+
+	var subject string
+	if len(items) == 1 {
+		subject = fmt.Sprintf("[polis] New comment from %s", items[0].Actor)
+	} else {
+		subject = fmt.Sprintf("[polis] %d new comments awaiting review", len(items))
+	}
+
+	var b strings.Builder
+	if requests > 0 {
+		fmt.Fprintf(&b, "You have %d new blessing request(s) on your posts.\n\n", requests)
+	} else {
+		fmt.Fprintf(&b, "You have %d new comment(s) on your posts.\n\n", len(items))
+	}
+	for _, it := range items {
+		fmt.Fprintf(&b, "- %s commented", it.Actor)
+		if it.TargetURL != "" {
+			fmt.Fprintf(&b, " on %s", it.TargetURL)
+		}
+		b.WriteString("\n")
+		if it.URL != "" {
+			fmt.Fprintf(&b, "  %s\n", it.URL)
+		}
+	}
+	b.WriteString("\nReview them in the polis web UI or with `polis blessing requests`.\n")
+	return subject, b.String()
+}
+
+// SendSMTP delivers a plain-text message using the config's SMTP server.
+// Port 465 uses implicit TLS; other ports use STARTTLS when offered.
+func SendSMTP(cfg Config, subject, body string) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.port()))
+	msg := buildMessage(cfg, subject, body)
+	recipients := splitAddresses(cfg.To)
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	if cfg.port() != 465 {
+		return smtp.SendMail(addr, auth, cfg.From, recipients, msg)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: cfg.Host})
+	if err != nil {
+		return fmt.Errorf("tls dial: %w", err)
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp client: %w", err)
+	}
+	defer c.Close()
+
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(msg); err != nil {
+		wc.Close()
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMessage formats RFC 5322 headers and body. Header values have line
+// breaks removed, since the subject carries a remote actor's name, and the
+// subject is MIME-encoded when it isn't plain ASCII.
+func buildMessage(cfg Config, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(cfg.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(strings.Join(splitAddresses(cfg.To), ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue drops CR and LF so a value can't end its header line.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// splitAddresses splits a comma-separated recipient list.
+func splitAddresses(s string) []string {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (n *Notifier) load() (*queueFile, error) {
+	data, err := os.ReadFile(n.queuePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &queueFile{}, nil
+		}
+		return nil, fmt.Errorf("read email queue: %w", err)
+	}
+	var q queueFile
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("parse email queue: %w", err)
+	}
+	return &q, nil
+}
+
+func (n *Notifier) save(q *queueFile) error {
+	if err := os.MkdirAll(filepath.Dir(n.queuePath), 0755); err != nil {
+		return fmt.Errorf("create .polis dir: %w", err)
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal email queue: %w", err)
+	}
+	return fsutil.WriteFile(n.queuePath, data, 0644)
+}
@@ -0,0 +1,154 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		Enabled:      true,
+		Host:         "smtp.example.com",
+		From:         "polis@example.com",
+		To:           "me@example.com",
+		BatchMinutes: 10,
+	}
+}
+
+func TestEnqueue_Dedup(t *testing.T) {
+	n := NewNotifier(t.TempDir(), testConfig())
+
+	items := []Item{
+		{Kind: "blessing_request", Actor: "bob.com", URL: "https://bob.com/comments/1.md"},
+		{Kind: "blessing_request", Actor: "bob.com", URL: "https://bob.com/comments/1.md"},
+	}
+	added, err := n.Enqueue(items)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("expected 1 added, got %d", added)
+	}
+	added, _ = n.Enqueue(items[:1])
+	if added != 0 {
+		t.Errorf("expected re-enqueue to be a no-op, got %d", added)
+	}
+	if n.Pending() != 1 {
+		t.Errorf("expected 1 pending, got %d", n.Pending())
+	}
+}
+
+func TestFlush_BatchesWithinWindow(t *testing.T) {
+	n := NewNotifier(t.TempDir(), testConfig())
+	var sent []string
+	n.Send = func(cfg Config, subject, body string) error {
+		sent = append(sent, subject)
+		return nil
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	n.Enqueue([]Item{{Kind: "blessing_request", Actor: "a.com", URL: "https://a.com/c/1.md"}})
+	count, err := n.Flush(now)
+	if err != nil || count != 1 {
+		t.Fatalf("expected first flush to send 1 item, got %d (%v)", count, err)
+	}
+
+	// Two more arrive within the batch window: held back
+	n.Enqueue([]Item{
+		{Kind: "blessing_request", Actor: "b.com", URL: "https://b.com/c/2.md"},
+		{Kind: "blessing_request", Actor: "c.com", URL: "https://c.com/c/3.md"},
+	})
+	count, _ = n.Flush(now.Add(5 * time.Minute))
+	if count != 0 {
+		t.Errorf("expected flush within window to hold items, sent %d", count)
+	}
+
+	// After the window, both go out in one email
+	count, _ = n.Flush(now.Add(11 * time.Minute))
+	if count != 2 {
+		t.Errorf("expected 2 items in batched email, got %d", count)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 emails total, got %d", len(sent))
+	}
+	if !strings.Contains(sent[1], "2 new comments") {
+		t.Errorf("unexpected batch subject: %s", sent[1])
+	}
+	if n.Pending() != 0 {
+		t.Errorf("expected queue to be empty, got %d", n.Pending())
+	}
+}
+
+func TestFlush_KeepsQueueOnSendError(t *testing.T) {
+	n := NewNotifier(t.TempDir(), testConfig())
+	n.Send = func(cfg Config, subject, body string) error {
+		return errors.New("connection refused")
+	}
+	n.Enqueue([]Item{{Kind: "blessing_request", Actor: "a.com", URL: "https://a.com/c/1.md"}})
+
+	if _, err := n.Flush(time.Now()); err == nil {
+		t.Fatal("expected error")
+	}
+	if n.Pending() != 1 {
+		t.Errorf("expected item to stay queued after failure, got %d", n.Pending())
+	}
+}
+
+func TestFlush_DisabledIsNoop(t *testing.T) {
+	cfg := testConfig()
+	cfg.Enabled = false
+	n := NewNotifier(t.TempDir(), cfg)
+	n.Send = func(cfg Config, subject, body string) error {
+		t.Fatal("send should not be called when disabled")
+		return nil
+	}
+	n.Enqueue([]Item{{Kind: "blessing_request", Actor: "a.com", URL: "https://a.com/c/1.md"}})
+	if count, err := n.Flush(time.Now()); err != nil || count != 0 {
+		t.Errorf("expected no-op, got %d (%v)", count, err)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	subject, body := Compose([]Item{{
+		Kind:      "blessing_request",
+		Actor:     "bob.com",
+		URL:       "https://bob.com/comments/1.md",
+		TargetURL: "https://me.com/posts/hello.md",
+	}})
+	if subject != "[polis] New comment from bob.com" {
+		t.Errorf("unexpected subject: %s", subject)
+	}
+	if !strings.Contains(body, "bob.com commented on https://me.com/posts/hello.md") {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
+
+func TestBuildMessage_Headers(t *testing.T) {
+	cfg := testConfig()
+	cfg.To = "a@example.com, b@example.com"
+	msg := string(buildMessage(cfg, "Hello", "line1\nline2"))
+	if !strings.Contains(msg, "To: a@example.com, b@example.com\r\n") {
+		t.Errorf("missing To header: %q", msg)
+	}
+	if !strings.HasSuffix(msg, "line1\r\nline2") {
+		t.Errorf("body should use CRLF: %q", msg)
+	}
+}
+
+func TestBuildMessage_HeaderInjection(t *testing.T) {
+	subject, _ := Compose([]Item{{Kind: "blessing_request", Actor: "bob.com\r\nBcc: eve@example.com"}})
+	msg := string(buildMessage(testConfig(), subject, "body"))
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("actor injected a header: %q", msg)
+	}
+	if !strings.Contains(msg, "Subject: [polis] New comment from bob.comBcc: eve@example.com\r\n") {
+		t.Errorf("unexpected subject: %q", msg)
+	}
+
+	msg = string(buildMessage(testConfig(), "[polis] New comment from café.example", "body"))
+	if !strings.Contains(msg, "Subject: =?utf-8?q?") {
+		t.Errorf("non-ASCII subject should be encoded: %q", msg)
+	}
+}
@@ -0,0 +1,270 @@
+// Package mention detects posts and comments by authors we don't follow
+// that link to this site, in the spirit of Webmention.
+//
+// The discovery stream carries no link data, so mentions are found by
+// scanning content: each post or comment event from an author we don't
+// follow is fetched once and searched for links to our domain. Comments
+// replying to our own posts are skipped; they already arrive as blessing
+// requests.
+package mention
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// StateName is the stream state file holding detected mentions
+// (state/polis.mention.json).
+const StateName = "polis.mention"
+
+// EventType is the type of the local events built from mentions for the
+// notification rules. They are never published to the stream.
+const EventType = "polis.mention.detected"
+
+// EventTypes are the stream event types scanned for mentions.
+var EventTypes = []string{
+	"polis.post.published",
+	"polis.post.republished",
+	"polis.comment.published",
+	"polis.comment.republished",
+}
+
+// Limits on the state kept.
+const (
+	maxMentions = 500
+	maxScanned  = 5000
+)
+
+// Mention is a post or comment that links to our site.
+type Mention struct {
+	URL   string `json:"url"`
+	Type  string `json:"type"` // "post" or "comment"
+	Actor string `json:"actor"`
+	Title string `json:"title,omitempty"`
+	// Links are the URLs on our site that it links to.
+	Links      []string `json:"links"`
+	Published  string   `json:"published,omitempty"`
+	DetectedAt string   `json:"detected_at"`
+}
+
+// State is the materialized mention state.
+type State struct {
+	Mentions []Mention `json:"mentions"` // Newest first
+	// Scanned maps each fetched content URL to the event timestamp it was
+	// scanned for, so content is fetched once per version.
+	Scanned map[string]string `json:"scanned"`
+}
+
+// Load reads the mention state.
+func Load(dataDir, discoveryDomain string) *State {
+	state := &State{}
+	_ = stream.NewStore(dataDir, discoveryDomain).LoadState(StateName, state)
+	if state.Scanned == nil {
+		state.Scanned = make(map[string]string)
+	}
+	return state
+}
+
+// Save writes the mention state, dropping the oldest entries past the
+// limits.
+func Save(dataDir, discoveryDomain string, state *State) error {
+	if len(state.Mentions) > maxMentions {
+		state.Mentions = state.Mentions[:maxMentions]
+	}
+	if len(state.Scanned) > maxScanned {
+		urls := make([]string, 0, len(state.Scanned))
+		for u := range state.Scanned {
+			urls = append(urls, u)
+		}
+		sort.Slice(urls, func(i, j int) bool { return state.Scanned[urls[i]] < state.Scanned[urls[j]] })
+		for _, u := range urls[:len(urls)-maxScanned] {
+			delete(state.Scanned, u)
+		}
+	}
+	return stream.NewStore(dataDir, discoveryDomain).SaveState(StateName, state)
+}
+
+// Detector finds mentions of MyDomain in stream events.
+type Detector struct {
+	MyDomain        string
+	FollowedDomains map[string]bool
+	// Fetch returns the content at a URL.
+	Fetch func(url string) (string, error)
+	// MaxScans caps the fetches per Scan call; 0 means no limit. Events
+	// past the cap are not scanned.
+	MaxScans int
+}
+
+// Scan fetches the content of each candidate event, records it in
+// state.Scanned, and adds any mentions found to the front of
+// state.Mentions. Returns the new mentions.
+func (d *Detector) Scan(events []discovery.StreamEvent, state *State) []Mention {
+	known := make(map[string]bool, len(state.Mentions))
+	for _, m := range state.Mentions {
+		known[m.URL] = true
+	}
+
+	var found []Mention
+	scans := 0
+	for _, evt := range events {
+		url, kind, ok := d.candidate(evt)
+		if !ok || known[url] {
+			continue
+		}
+		if last, scanned := state.Scanned[url]; scanned && last >= evt.Timestamp {
+			continue
+		}
+		if d.MaxScans > 0 && scans >= d.MaxScans {
+			break
+		}
+		scans++
+
+		content, err := d.Fetch(url)
+		if err != nil {
+			continue
+		}
+		state.Scanned[url] = evt.Timestamp
+		links := FindLinks(content, d.MyDomain)
+		if len(links) == 0 {
+			continue
+		}
+		known[url] = true
+		title, published := eventMeta(evt)
+		found = append(found, Mention{
+			URL:        url,
+			Type:       kind,
+			Actor:      evt.Actor,
+			Title:      title,
+			Links:      links,
+			Published:  published,
+			DetectedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	if len(found) > 0 {
+		newest := make([]Mention, len(found))
+		for i, m := range found {
+			newest[len(found)-1-i] = m
+		}
+		state.Mentions = append(newest, state.Mentions...)
+	}
+	return found
+}
+
+// candidate returns the content URL and kind of an event worth scanning:
+// a post or comment by someone other than us and the authors we follow,
+// that isn't a reply to one of our posts.
+func (d *Detector) candidate(evt discovery.StreamEvent) (string, string, bool) {
+	var kind string
+	switch evt.Type {
+	case "polis.post.published", "polis.post.republished":
+		kind = "post"
+	case "polis.comment.published", "polis.comment.republished":
+		kind = "comment"
+	default:
+		return "", "", false
+	}
+	if evt.Actor == "" || evt.Actor == d.MyDomain || d.FollowedDomains[evt.Actor] {
+		return "", "", false
+	}
+	if kind == "comment" {
+		if target, _ := evt.Payload["target_domain"].(string); target == d.MyDomain {
+			return "", "", false
+		}
+		for _, key := range []string{"in_reply_to", "root_post"} {
+			if u, _ := evt.Payload[key].(string); u != "" && discovery.ExtractDomainFromURL(u) == d.MyDomain {
+				return "", "", false
+			}
+		}
+	}
+	url, _ := evt.Payload["url"].(string)
+	if url == "" {
+		url, _ = evt.Payload["comment_url"].(string)
+	}
+	if url == "" || discovery.ExtractDomainFromURL(url) != evt.Actor {
+		return "", "", false
+	}
+	return url, kind, true
+}
+
+// eventMeta returns an event's title and publication time, which may be
+// top-level or nested under metadata.
+func eventMeta(evt discovery.StreamEvent) (string, string) {
+	title, _ := evt.Payload["title"].(string)
+	published, _ := evt.Payload["published_at"].(string)
+	if md, ok := evt.Payload["metadata"].(map[string]interface{}); ok {
+		if title == "" {
+			title, _ = md["title"].(string)
+		}
+		if published == "" {
+			published, _ = md["published_at"].(string)
+		}
+	}
+	if published == "" {
+		published = evt.Timestamp
+	}
+	return title, published
+}
+
+// Event returns the local notification event for m.
+func (m Mention) Event(myDomain string) discovery.StreamEvent {
+	return discovery.StreamEvent{
+		Type:  EventType,
+		Actor: m.Actor,
+		Payload: map[string]interface{}{
+			"source_url":       m.URL,
+			"url":              m.URL,
+			"title":            m.Title,
+			"mentioned_domain": myDomain,
+			"links":            m.Links,
+		},
+		Timestamp: m.DetectedAt,
+	}
+}
+
+// linkTrailer is punctuation that ends a sentence rather than a URL.
+const linkTrailer = ".,;:!?"
+
+// FindLinks returns the distinct links to domain (or www.domain) in
+// content, in order of appearance.
+func FindLinks(content, domain string) []string {
+	if domain == "" {
+		return nil
+	}
+	re := regexp.MustCompile(`(?i)https?://(?:www\.)?` + regexp.QuoteMeta(domain) + `(?:[/?#][^\s<>"'()\[\]]*)?`)
+	var links []string
+	seen := make(map[string]bool)
+	for _, loc := range re.FindAllStringIndex(content, -1) {
+		if continuesHost(content, loc[1]) {
+			continue
+		}
+		link := strings.TrimRight(content[loc[0]:loc[1]], linkTrailer)
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// continuesHost reports whether the host of a link ending at content[i]
+// goes on (example.com.evil.org, example.community), making it a
+// different site. A trailing full stop doesn't count.
+func continuesHost(content string, i int) bool {
+	if i >= len(content) {
+		return false
+	}
+	if content[i] == '.' {
+		return i+1 < len(content) && isAlnum(content[i+1])
+	}
+	return isAlnum(content[i]) || content[i] == '-' || content[i] == '_'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
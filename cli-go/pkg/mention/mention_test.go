@@ -0,0 +1,76 @@
+package mention
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+func TestFindLinks(t *testing.T) {
+	content := `See https://me.example.com/posts/20260301/hello.md. Also [home](https://WWW.me.example.com)
+and https://me.example.com/posts/20260301/hello.md again, but not https://me.example.com.evil.org/x,
+https://me.example.community, or http://notme.example.com/.`
+
+	got := FindLinks(content, "me.example.com")
+	want := []string{"https://me.example.com/posts/20260301/hello.md", "https://WWW.me.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindLinks = %q, want %q", got, want)
+	}
+}
+
+func TestDetector_Scan(t *testing.T) {
+	pages := map[string]string{
+		"https://stranger.example.com/posts/a.md": "I liked https://me.example.com/posts/x.md",
+		"https://stranger.example.com/posts/b.md": "Nothing to see",
+		"https://friend.example.com/posts/c.md":   "Thanks https://me.example.com",
+	}
+	var fetched []string
+	d := &Detector{
+		MyDomain:        "me.example.com",
+		FollowedDomains: map[string]bool{"friend.example.com": true},
+		Fetch: func(url string) (string, error) {
+			fetched = append(fetched, url)
+			if content, ok := pages[url]; ok {
+				return content, nil
+			}
+			return "", errors.New("not found")
+		},
+	}
+	post := func(actor, url, ts string) discovery.StreamEvent {
+		return discovery.StreamEvent{Type: "polis.post.published", Actor: actor, Timestamp: ts,
+			Payload: map[string]interface{}{"url": url, "title": "T"}}
+	}
+	events := []discovery.StreamEvent{
+		post("stranger.example.com", "https://stranger.example.com/posts/a.md", "2026-03-01T10:00:00Z"),
+		post("stranger.example.com", "https://stranger.example.com/posts/b.md", "2026-03-01T11:00:00Z"),
+		post("friend.example.com", "https://friend.example.com/posts/c.md", "2026-03-01T12:00:00Z"),
+		{Type: "polis.comment.published", Actor: "stranger.example.com", Timestamp: "2026-03-01T13:00:00Z",
+			Payload: map[string]interface{}{"url": "https://stranger.example.com/comments/d.md", "in_reply_to": "https://me.example.com/posts/x.md"}},
+	}
+
+	state := &State{Scanned: map[string]string{}}
+	found := d.Scan(events, state)
+	if len(found) != 1 || found[0].URL != "https://stranger.example.com/posts/a.md" || found[0].Links[0] != "https://me.example.com/posts/x.md" {
+		t.Fatalf("found = %+v", found)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("fetched %v, want only the stranger's posts", fetched)
+	}
+
+	// A second pass fetches nothing new
+	fetched = nil
+	if found := d.Scan(events, state); len(found) != 0 || len(fetched) != 0 || len(state.Mentions) != 1 {
+		t.Errorf("rescan found %v, fetched %v", found, fetched)
+	}
+
+	// Mentions notify through the default rules
+	h := &stream.NotificationHandler{MyDomain: "me.example.com", Rules: notification.DefaultRules()}
+	entries := h.Process([]discovery.StreamEvent{state.Mentions[0].Event("me.example.com")})
+	if len(entries) != 1 || entries[0].RuleID != "mention" || entries[0].Message != "stranger.example.com mentioned you in a" {
+		t.Errorf("notification entries = %+v", entries)
+	}
+}
@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+const (
+	// BacklinksFilename is the name of the backlinks index file.
+	BacklinksFilename = "backlinks.json"
+)
+
+// Backlinks represents the backlinks.json file structure. It maps each post
+// to the posts on the same site that link to it with [[wiki links]], and is
+// rebuilt whenever the site is rendered.
+type Backlinks struct {
+	Version string              `json:"version"`
+	Posts   map[string][]string `json:"posts"` // target post path -> linking post paths, newest first
+}
+
+// LoadBacklinks reads backlinks.json from the metadata directory. A missing
+// file yields an empty index.
+func LoadBacklinks(siteDir string) (*Backlinks, error) {
+	data, err := storage.For(siteDir).ReadFile("metadata/" + BacklinksFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Backlinks{Version: GetGenerator(), Posts: map[string][]string{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read backlinks.json: %w", err)
+	}
+
+	var bl Backlinks
+	if err := json.Unmarshal(data, &bl); err != nil {
+		return nil, fmt.Errorf("failed to parse backlinks.json: %w", err)
+	}
+	if bl.Posts == nil {
+		bl.Posts = map[string][]string{}
+	}
+	return &bl, nil
+}
+
+// SaveBacklinks writes backlinks.json atomically.
+func SaveBacklinks(siteDir string, bl *Backlinks) error {
+	data, err := json.MarshalIndent(bl, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backlinks: %w", err)
+	}
+	if err := storage.For(siteDir).WriteFile("metadata/"+BacklinksFilename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write backlinks: %w", err)
+	}
+	return nil
+}
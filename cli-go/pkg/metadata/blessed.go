@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 // Version is set at startup by the cmd package.
@@ -24,6 +26,19 @@ const (
 	BlessedCommentsFilename = "blessed-comments.json"
 )
 
+// Comment sort orders for a post's blessed comments.
+const (
+	SortChronological = "chronological" // Oldest blessing first (default)
+	SortNewestFirst   = "newest-first"
+	SortFeaturedFirst = "featured-first" // Featured comments first, then chronological
+)
+
+// SortOrders lists the valid comment sort orders.
+var SortOrders = []string{SortChronological, SortNewestFirst, SortFeaturedFirst}
+
+// ErrCommentNotBlessed is returned when a comment URL isn't in the blessed index.
+var ErrCommentNotBlessed = errors.New("comment is not blessed")
+
 // BlessedComments represents the blessed-comments.json file structure.
 // This file is the public index of comments that the site owner has blessed,
 // grouped by the post they're replying to.
@@ -33,8 +48,10 @@ type BlessedComments struct {
 }
 
 // PostComments groups blessed comments for a single post.
+// Sort is the post's comment order; empty means SortChronological.
 type PostComments struct {
 	Post    string           `json:"post"`
+	Sort    string           `json:"sort,omitempty"`
 	Blessed []BlessedComment `json:"blessed"`
 }
 
@@ -43,13 +60,13 @@ type BlessedComment struct {
 	URL       string `json:"url"`
 	Version   string `json:"version"`
 	BlessedAt string `json:"blessed_at"`
+	Featured  bool   `json:"featured,omitempty"`
 }
 
 // LoadBlessedComments reads the blessed-comments.json file from the metadata directory.
 // Returns an error if the file doesn't exist.
 func LoadBlessedComments(siteDir string) (*BlessedComments, error) {
-	filePath := filepath.Join(siteDir, "metadata", BlessedCommentsFilename)
-	data, err := os.ReadFile(filePath)
+	data, err := storage.For(siteDir).ReadFile(blessedName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read blessed-comments.json: %w", err)
 	}
@@ -65,27 +82,14 @@ func LoadBlessedComments(siteDir string) (*BlessedComments, error) {
 // SaveBlessedComments writes the blessed-comments.json file atomically.
 // It writes to a temporary file first, then renames to ensure atomic update.
 func SaveBlessedComments(siteDir string, bc *BlessedComments) error {
-	metadataDir := filepath.Join(siteDir, "metadata")
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
-
 	data, err := json.MarshalIndent(bc, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal blessed comments: %w", err)
 	}
 
 	// Write atomically via temp file
-	filePath := filepath.Join(metadataDir, BlessedCommentsFilename)
-	tmpPath := filePath + ".tmp"
-
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, filePath); err != nil {
-		os.Remove(tmpPath) // Clean up temp file on failure
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	if err := storage.For(siteDir).WriteFile(blessedName, data, 0644); err != nil {
+		return fmt.Errorf("failed to write blessed comments: %w", err)
 	}
 
 	return nil
@@ -94,19 +98,11 @@ func SaveBlessedComments(siteDir string, bc *BlessedComments) error {
 // InitBlessedComments creates an empty blessed-comments.json if it doesn't exist.
 // Returns nil if the file already exists (does not overwrite).
 func InitBlessedComments(siteDir string, version string) error {
-	filePath := filepath.Join(siteDir, "metadata", BlessedCommentsFilename)
-
 	// Check if file already exists
-	if _, err := os.Stat(filePath); err == nil {
+	if storage.Exists(storage.For(siteDir), blessedName) {
 		return nil // Already exists, don't overwrite
 	}
 
-	// Create metadata directory if needed
-	metadataDir := filepath.Join(siteDir, "metadata")
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
-
 	// Create empty structure
 	bc := &BlessedComments{
 		Version:  version,
@@ -120,6 +116,12 @@ func InitBlessedComments(siteDir string, version string) error {
 // Creates the post entry if it doesn't exist.
 // This is an atomic read-modify-write operation.
 func AddBlessedComment(siteDir string, postPath string, comment BlessedComment) error {
+	return withBlessedLock(siteDir, func() error {
+		return addBlessedComment(siteDir, postPath, comment)
+	})
+}
+
+func addBlessedComment(siteDir string, postPath string, comment BlessedComment) error {
 	// Load current state
 	bc, err := LoadBlessedComments(siteDir)
 	if err != nil {
@@ -171,6 +173,12 @@ func AddBlessedComment(siteDir string, postPath string, comment BlessedComment)
 // RemoveBlessedComment removes a comment from the blessed comments index.
 // Matches by URL.
 func RemoveBlessedComment(siteDir string, commentURL string) error {
+	return withBlessedLock(siteDir, func() error {
+		return removeBlessedComment(siteDir, commentURL)
+	})
+}
+
+func removeBlessedComment(siteDir string, commentURL string) error {
 	bc, err := LoadBlessedComments(siteDir)
 	if err != nil {
 		return err
@@ -183,8 +191,8 @@ func RemoveBlessedComment(siteDir string, commentURL string) error {
 				// Remove this comment
 				bc.Comments[i].Blessed = append(pc.Blessed[:j], pc.Blessed[j+1:]...)
 
-				// If post has no more blessed comments, remove the post entry
-				if len(bc.Comments[i].Blessed) == 0 {
+				// If post has no more blessed comments (and no sort setting), remove the post entry
+				if len(bc.Comments[i].Blessed) == 0 && bc.Comments[i].Sort == "" {
 					bc.Comments = append(bc.Comments[:i], bc.Comments[i+1:]...)
 				}
 
@@ -197,6 +205,14 @@ func RemoveBlessedComment(siteDir string, commentURL string) error {
 	return nil
 }
 
+// withBlessedLock holds the blessed-comments.json lock while fn runs.
+func withBlessedLock(siteDir string, fn func() error) error {
+	return storage.With(storage.For(siteDir), blessedName, fn)
+}
+
+// blessedName is blessed-comments.json's name in site storage.
+const blessedName = "metadata/" + BlessedCommentsFilename
+
 // GetBlessedCommentsForPost returns all blessed comments for a specific post.
 // Uses flexible path matching: tries exact match, .md/.html swap, and URL-to-path extraction.
 func GetBlessedCommentsForPost(siteDir string, postPath string) ([]BlessedComment, error) {
@@ -210,13 +226,102 @@ func GetBlessedCommentsForPost(siteDir string, postPath string) ([]BlessedCommen
 
 	for _, pc := range bc.Comments {
 		if matchesPostPath(pc.Post, postPath) {
-			return pc.Blessed, nil
+			return SortBlessedComments(pc.Blessed, pc.Sort), nil
 		}
 	}
 
 	return []BlessedComment{}, nil
 }
 
+// ValidSortOrder reports whether order is a known comment sort order.
+func ValidSortOrder(order string) bool {
+	for _, o := range SortOrders {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
+
+// SortBlessedComments returns a copy of comments in the given order.
+// Unknown or empty orders are chronological.
+func SortBlessedComments(comments []BlessedComment, order string) []BlessedComment {
+	sorted := make([]BlessedComment, len(comments))
+	copy(sorted, comments)
+	// blessed_at is RFC 3339 UTC, so string order is time order
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch order {
+		case SortNewestFirst:
+			return a.BlessedAt > b.BlessedAt
+		case SortFeaturedFirst:
+			if a.Featured != b.Featured {
+				return a.Featured
+			}
+		}
+		return a.BlessedAt < b.BlessedAt
+	})
+	return sorted
+}
+
+// SetCommentFeatured marks or unmarks a blessed comment as featured.
+// Returns ErrCommentNotBlessed if the comment isn't in the index.
+func SetCommentFeatured(siteDir string, commentURL string, featured bool) error {
+	return withBlessedLock(siteDir, func() error {
+		bc, err := LoadBlessedComments(siteDir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return ErrCommentNotBlessed
+			}
+			return err
+		}
+		for i, pc := range bc.Comments {
+			for j, c := range pc.Blessed {
+				if c.URL == commentURL {
+					bc.Comments[i].Blessed[j].Featured = featured
+					return SaveBlessedComments(siteDir, bc)
+				}
+			}
+		}
+		return ErrCommentNotBlessed
+	})
+}
+
+// SetPostCommentSort sets the comment order of a post (relative path or
+// URL). An empty order resets it to the default (chronological).
+func SetPostCommentSort(siteDir string, postPath string, order string) error {
+	if order != "" && !ValidSortOrder(order) {
+		return fmt.Errorf("unknown sort order %q (expected %s)", order, strings.Join(SortOrders, ", "))
+	}
+	return withBlessedLock(siteDir, func() error {
+		bc, err := LoadBlessedComments(siteDir)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			bc = &BlessedComments{Version: GetGenerator(), Comments: []PostComments{}}
+		}
+		for i, pc := range bc.Comments {
+			if matchesPostPath(pc.Post, postPath) {
+				bc.Comments[i].Sort = order
+				if order == "" && len(pc.Blessed) == 0 {
+					bc.Comments = append(bc.Comments[:i], bc.Comments[i+1:]...)
+				}
+				return SaveBlessedComments(siteDir, bc)
+			}
+		}
+		if order == "" {
+			return nil
+		}
+		// Store relative paths, like the entries blessing creates
+		if idx := strings.Index(postPath, "/posts/"); idx >= 0 {
+			postPath = postPath[idx+1:]
+		}
+		bc.Comments = append(bc.Comments, PostComments{Post: postPath, Sort: order, Blessed: []BlessedComment{}})
+		return SaveBlessedComments(siteDir, bc)
+	})
+}
+
 // matchesPostPath checks if two post paths refer to the same post.
 // Handles exact match, .md/.html extension swaps, and full URL vs relative path.
 func matchesPostPath(stored, query string) bool {
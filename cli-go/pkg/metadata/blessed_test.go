@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -83,3 +84,70 @@ func TestMatchesPostPath(t *testing.T) {
 		}
 	}
 }
+
+func TestBlessedCommentOrdering(t *testing.T) {
+	siteDir := t.TempDir()
+	post := "posts/20260101/hello.md"
+	for _, c := range []BlessedComment{
+		{URL: "https://a.example.com/comments/20260102/a.md", Version: "sha256:a", BlessedAt: "2026-01-02T00:00:00Z"},
+		{URL: "https://b.example.com/comments/20260103/b.md", Version: "sha256:b", BlessedAt: "2026-01-03T00:00:00Z"},
+		{URL: "https://c.example.com/comments/20260101/c.md", Version: "sha256:c", BlessedAt: "2026-01-01T00:00:00Z"},
+	} {
+		if err := AddBlessedComment(siteDir, post, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order := func() string {
+		comments, err := GetBlessedCommentsForPost(siteDir, post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s string
+		for _, c := range comments {
+			s += c.Version[len(c.Version)-1:]
+		}
+		return s
+	}
+
+	if got := order(); got != "cab" {
+		t.Errorf("chronological: got %s, want cab", got)
+	}
+
+	if err := SetCommentFeatured(siteDir, "https://b.example.com/comments/20260103/b.md", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPostCommentSort(siteDir, post, SortFeaturedFirst); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "bca" {
+		t.Errorf("featured-first: got %s, want bca", got)
+	}
+
+	if err := SetPostCommentSort(siteDir, post, SortNewestFirst); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "bac" {
+		t.Errorf("newest-first: got %s, want bac", got)
+	}
+
+	if err := SetPostCommentSort(siteDir, post, "random"); err == nil {
+		t.Error("expected error for unknown sort order")
+	}
+	if err := SetCommentFeatured(siteDir, "https://x.example.com/comments/20260101/x.md", true); !errors.Is(err, ErrCommentNotBlessed) {
+		t.Errorf("expected ErrCommentNotBlessed, got %v", err)
+	}
+
+	// The sort setting outlives the post's last comment
+	for _, u := range []string{
+		"https://a.example.com/comments/20260102/a.md",
+		"https://b.example.com/comments/20260103/b.md",
+		"https://c.example.com/comments/20260101/c.md",
+	} {
+		RemoveBlessedComment(siteDir, u)
+	}
+	bc, _ := LoadBlessedComments(siteDir)
+	if len(bc.Comments) != 1 || bc.Comments[0].Sort != SortNewestFirst {
+		t.Errorf("expected sort setting to remain: %+v", bc.Comments)
+	}
+}
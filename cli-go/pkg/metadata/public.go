@@ -2,10 +2,14 @@
 package metadata
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 const (
@@ -16,12 +20,16 @@ const (
 // IndexEntry represents a single entry in public.jsonl.
 // Can be either a post or a comment.
 type IndexEntry struct {
-	Type           string          `json:"type"`                  // "post" or "comment"
-	Path           string          `json:"path"`                  // Relative file path
-	Title          string          `json:"title"`                 // Entry title
-	Published      string          `json:"published"`             // ISO timestamp
-	CurrentVersion string          `json:"current_version"`       // sha256:... hash
-	InReplyTo      *InReplyToEntry `json:"in_reply_to,omitempty"` // Only for comments
+	Type           string          `json:"type"`                     // "post" or "comment"
+	Path           string          `json:"path"`                     // Relative file path
+	Title          string          `json:"title"`                    // Entry title
+	Published      string          `json:"published"`                // ISO timestamp
+	CurrentVersion string          `json:"current_version"`          // sha256:... hash
+	Summary        string          `json:"summary,omitempty"`        // Short excerpt (posts only)
+	Lang           string          `json:"lang,omitempty"`           // Language tag from frontmatter (posts only)
+	TranslationOf  string          `json:"translation_of,omitempty"` // Path of the original post (translations only)
+	IPFS           string          `json:"ipfs,omitempty"`           // CID of the current version's IPFS copy (posts only)
+	InReplyTo      *InReplyToEntry `json:"in_reply_to,omitempty"`    // Only for comments
 }
 
 // InReplyToEntry represents the in-reply-to reference in a comment index entry.
@@ -34,8 +42,18 @@ type InReplyToEntry struct {
 // If an entry with the same Path already exists, it is updated in place.
 // Otherwise the entry is appended. Creates the metadata directory and file if they don't exist.
 func AppendToPublicIndex(siteDir string, entry *IndexEntry) error {
+	entry.Path = filepath.ToSlash(entry.Path)
+	return withPublicIndexLock(siteDir, func() error {
+		return appendToIndex(storage.For(siteDir), publicIndexName, entry)
+	})
+}
+
+// appendToIndex adds entry to the index file name, replacing an entry with
+// the same path.
+func appendToIndex(fsys storage.FS, name string, entry *IndexEntry) error {
+
 	// Load existing entries to check for duplicates
-	existing, err := LoadPublicIndex(siteDir)
+	existing, err := loadIndex(fsys, name)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
@@ -51,29 +69,16 @@ func AppendToPublicIndex(siteDir string, entry *IndexEntry) error {
 	}
 
 	if found {
-		return writePublicIndex(siteDir, existing)
+		return writeIndex(fsys, name, existing)
 	}
 
 	// No duplicate - append
-	metadataDir := filepath.Join(siteDir, "metadata")
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
-
-	indexPath := filepath.Join(metadataDir, PublicIndexFilename)
-
 	jsonLine, err := json.Marshal(entry)
 	if err != nil {
 		return fmt.Errorf("failed to marshal entry: %w", err)
 	}
 
-	f, err := os.OpenFile(indexPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open index file: %w", err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(append(jsonLine, '\n')); err != nil {
+	if err := storage.AppendJSONLines(fsys, name, [][]byte{jsonLine}, 0644); err != nil {
 		return fmt.Errorf("failed to write to index: %w", err)
 	}
 
@@ -110,71 +115,70 @@ func AppendPostToIndex(siteDir string, path, title, published, currentVersion st
 
 // LoadPublicIndex reads all entries from public.jsonl.
 func LoadPublicIndex(siteDir string) ([]IndexEntry, error) {
-	indexPath := filepath.Join(siteDir, "metadata", PublicIndexFilename)
-	data, err := os.ReadFile(indexPath)
+	return loadIndex(storage.For(siteDir), publicIndexName)
+}
+
+// loadIndex reads all entries from the index file name. A missing file has
+// no entries.
+func loadIndex(fsys storage.FS, name string) ([]IndexEntry, error) {
+	lines, err := storage.ReadJSONLines(fsys, name)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []IndexEntry{}, nil
 		}
-		return nil, fmt.Errorf("failed to read public.jsonl: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", path.Base(name), err)
 	}
 
 	var entries []IndexEntry
-	// Split by newlines and parse each line
-	lines := splitLines(data)
 	for _, line := range lines {
-		if len(line) == 0 {
-			continue
-		}
 		var entry IndexEntry
 		if err := json.Unmarshal(line, &entry); err != nil {
 			// Skip malformed lines
 			continue
 		}
+		// Entries written on Windows before paths were normalized
+		entry.Path = filepath.ToSlash(entry.Path)
 		entries = append(entries, entry)
 	}
 
 	return entries, nil
 }
 
-// splitLines splits byte slice by newlines, handling both \n and \r\n.
-func splitLines(data []byte) [][]byte {
-	var lines [][]byte
-	start := 0
-	for i := 0; i < len(data); i++ {
-		if data[i] == '\n' {
-			end := i
-			if end > start && data[end-1] == '\r' {
-				end--
-			}
-			lines = append(lines, data[start:end])
-			start = i + 1
-		}
-	}
-	if start < len(data) {
-		lines = append(lines, data[start:])
-	}
-	return lines
-}
-
 // UpdateIndexEntry updates an existing entry in public.jsonl by path.
 // Rewrites the entire file with the updated entry.
 func UpdateIndexEntry(siteDir, path, newTitle, newVersion string) error {
-	entries, err := LoadPublicIndex(siteDir)
+	return ModifyIndexEntry(siteDir, path, func(entry *IndexEntry) {
+		if newTitle != "" {
+			entry.Title = newTitle
+		}
+		if newVersion != "" {
+			entry.CurrentVersion = newVersion
+		}
+	})
+}
+
+// ModifyIndexEntry applies update to the entry at path in public.jsonl and
+// rewrites the file. Returns an error if there is no such entry.
+func ModifyIndexEntry(siteDir, path string, update func(entry *IndexEntry)) error {
+	path = filepath.ToSlash(path)
+	return withPublicIndexLock(siteDir, func() error {
+		return modifyIndexEntry(storage.For(siteDir), publicIndexName, path, update)
+	})
+}
+
+// modifyIndexEntry applies update to the entry at path in the index file
+// name.
+func modifyIndexEntry(fsys storage.FS, name, path string, update func(entry *IndexEntry)) error {
+	entries, err := loadIndex(fsys, name)
 	if err != nil {
 		return err
 	}
 
 	// Find and update the entry
 	found := false
-	for i, entry := range entries {
-		if entry.Path == path {
-			if newTitle != "" {
-				entries[i].Title = newTitle
-			}
-			if newVersion != "" {
-				entries[i].CurrentVersion = newVersion
-			}
+	for i := range entries {
+		if entries[i].Path == path {
+			update(&entries[i])
 			found = true
 			break
 		}
@@ -185,12 +189,20 @@ func UpdateIndexEntry(siteDir, path, newTitle, newVersion string) error {
 	}
 
 	// Rewrite the file
-	return writePublicIndex(siteDir, entries)
+	return writeIndex(fsys, name, entries)
 }
 
 // RemoveIndexEntry removes an entry from public.jsonl by path.
 func RemoveIndexEntry(siteDir, path string) error {
-	entries, err := LoadPublicIndex(siteDir)
+	path = filepath.ToSlash(path)
+	return withPublicIndexLock(siteDir, func() error {
+		return removeIndexEntry(storage.For(siteDir), publicIndexName, path)
+	})
+}
+
+// removeIndexEntry removes the entry at path from the index file name.
+func removeIndexEntry(fsys storage.FS, name, path string) error {
+	entries, err := loadIndex(fsys, name)
 	if err != nil {
 		return err
 	}
@@ -202,47 +214,37 @@ func RemoveIndexEntry(siteDir, path string) error {
 			filtered = append(filtered, entry)
 		}
 	}
+	if len(filtered) == len(entries) {
+		return nil // Not in this index
+	}
 
-	return writePublicIndex(siteDir, filtered)
+	return writeIndex(fsys, name, filtered)
 }
 
-// writePublicIndex writes all entries to public.jsonl.
-func writePublicIndex(siteDir string, entries []IndexEntry) error {
-	metadataDir := filepath.Join(siteDir, "metadata")
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
+// withPublicIndexLock holds the public.jsonl lock while fn runs, so a
+// concurrent CLI command and serve process can't lose each other's updates.
+func withPublicIndexLock(siteDir string, fn func() error) error {
+	return storage.With(storage.For(siteDir), publicIndexName, fn)
+}
 
-	indexPath := filepath.Join(metadataDir, PublicIndexFilename)
-	tmpPath := indexPath + ".tmp"
-
-	f, err := os.Create(tmpPath)
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
+// publicIndexName is public.jsonl's name in site storage.
+const publicIndexName = "metadata/" + PublicIndexFilename
 
+// writeIndex writes all entries to the index file name.
+func writeIndex(fsys storage.FS, name string, entries []IndexEntry) error {
+	var buf bytes.Buffer
 	for _, entry := range entries {
 		jsonLine, err := json.Marshal(entry)
 		if err != nil {
-			f.Close()
-			os.Remove(tmpPath)
 			return fmt.Errorf("failed to marshal entry: %w", err)
 		}
-		if _, err := f.Write(append(jsonLine, '\n')); err != nil {
-			f.Close()
-			os.Remove(tmpPath)
-			return fmt.Errorf("failed to write entry: %w", err)
-		}
-	}
-
-	if err := f.Close(); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("failed to close temp file: %w", err)
+		buf.Write(jsonLine)
+		buf.WriteByte('\n')
 	}
 
-	if err := os.Rename(tmpPath, indexPath); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	// Write atomically via temp file
+	if err := fsys.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path.Base(name), err)
 	}
 
 	return nil
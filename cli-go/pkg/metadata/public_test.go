@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 func TestAppendToPublicIndex_NoDuplicates(t *testing.T) {
@@ -101,3 +103,53 @@ func TestAppendCommentToIndex_Dedup(t *testing.T) {
 		t.Errorf("expected updated title, got %s", entries[0].Title)
 	}
 }
+
+func TestPublicIndex_RecoversFromPartialAppend(t *testing.T) {
+	siteDir := t.TempDir()
+	AppendPostToIndex(siteDir, "posts/20260101/one.md", "One", "2026-01-01T00:00:00Z", "sha256:1")
+
+	// A crash mid-append leaves half a line behind.
+	indexPath := filepath.Join(siteDir, "metadata", PublicIndexFilename)
+	f, _ := os.OpenFile(indexPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"post","path":"posts/20260102/tw`)
+	f.Close()
+
+	entries, err := LoadPublicIndex(siteDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("LoadPublicIndex = %d entries, %v", len(entries), err)
+	}
+
+	if err := AppendPostToIndex(siteDir, "posts/20260103/three.md", "Three", "2026-01-03T00:00:00Z", "sha256:3"); err != nil {
+		t.Fatalf("append after partial line: %v", err)
+	}
+	entries, _ = LoadPublicIndex(siteDir)
+	if len(entries) != 2 || entries[1].Title != "Three" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestPublicIndex_InMemoryStorage(t *testing.T) {
+	siteDir := filepath.Join(t.TempDir(), "site")
+	mem := storage.NewMem()
+	defer storage.Mount(siteDir, mem)()
+
+	AppendPostToIndex(siteDir, "posts/20260101/one.md", "One", "2026-01-01T00:00:00Z", "sha256:1")
+	AppendPostToIndex(siteDir, "posts/20260102/two.md", "Two", "2026-01-02T00:00:00Z", "sha256:2")
+	if err := UpdateIndexEntry(siteDir, "posts/20260101/one.md", "One v2", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveIndexEntry(siteDir, "posts/20260102/two.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadPublicIndex(siteDir)
+	if err != nil || len(entries) != 1 || entries[0].Title != "One v2" {
+		t.Fatalf("LoadPublicIndex = %+v, %v", entries, err)
+	}
+	if _, err := os.Stat(siteDir); !os.IsNotExist(err) {
+		t.Errorf("site directory was created on disk: %v", err)
+	}
+	if !storage.Exists(mem, "metadata/"+PublicIndexFilename) {
+		t.Error("public.jsonl not written to the mounted storage")
+	}
+}
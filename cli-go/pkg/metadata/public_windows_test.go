@@ -0,0 +1,28 @@
+//go:build windows
+
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendToPublicIndex_NormalizesBackslashes(t *testing.T) {
+	siteDir := t.TempDir()
+	os.MkdirAll(filepath.Join(siteDir, "metadata"), 0755)
+
+	entry := &IndexEntry{Type: "post", Path: `posts\20260101\hello.md`, Title: "Hello"}
+	if err := AppendToPublicIndex(siteDir, entry); err != nil {
+		t.Fatal(err)
+	}
+	// Updating by the slash form must find the same entry
+	if err := UpdateIndexEntry(siteDir, "posts/20260101/hello.md", "Hello again", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := LoadPublicIndex(siteDir)
+	if len(entries) != 1 || entries[0].Path != "posts/20260101/hello.md" || entries[0].Title != "Hello again" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+const (
+	// SyndicationFilename is the name of the cross-post index file.
+	SyndicationFilename = "syndication.json"
+)
+
+// Syndication represents the syndication.json file structure. It records
+// where each post has been cross-posted to other networks.
+type Syndication struct {
+	Version string                       `json:"version"`
+	Posts   map[string][]SyndicationLink `json:"posts"` // post path -> copies, oldest first
+}
+
+// SyndicationLink is one copy of a post on another network.
+type SyndicationLink struct {
+	Service string `json:"service"`           // e.g. "bluesky"
+	URL     string `json:"url"`               // Web URL of the copy
+	ID      string `json:"id,omitempty"`      // Service-specific ID (an at:// URI for Bluesky)
+	CID     string `json:"cid,omitempty"`     // Content hash, where the service has one
+	Version string `json:"version,omitempty"` // Post version copied, for copies that are kept up to date
+	Posted  string `json:"posted"`
+}
+
+// LoadSyndication reads syndication.json from the metadata directory. A
+// missing file yields an empty index.
+func LoadSyndication(siteDir string) (*Syndication, error) {
+	data, err := storage.For(siteDir).ReadFile("metadata/" + SyndicationFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Syndication{Version: GetGenerator(), Posts: map[string][]SyndicationLink{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read syndication.json: %w", err)
+	}
+
+	var s Syndication
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse syndication.json: %w", err)
+	}
+	if s.Posts == nil {
+		s.Posts = map[string][]SyndicationLink{}
+	}
+	return &s, nil
+}
+
+// Link returns the post's copy on service, if there is one.
+func (s *Syndication) Link(postPath, service string) (SyndicationLink, bool) {
+	for _, l := range s.Posts[postPath] {
+		if l.Service == service {
+			return l, true
+		}
+	}
+	return SyndicationLink{}, false
+}
+
+// SetLink records the post's copy on link.Service, replacing an earlier one.
+func (s *Syndication) SetLink(postPath string, link SyndicationLink) {
+	links := s.Posts[postPath]
+	for i, l := range links {
+		if l.Service == link.Service {
+			links[i] = link
+			return
+		}
+	}
+	s.Posts[postPath] = append(links, link)
+}
+
+// SaveSyndication writes syndication.json atomically.
+func SaveSyndication(siteDir string, s *Syndication) error {
+	s.Version = GetGenerator()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal syndication: %w", err)
+	}
+	if err := storage.For(siteDir).WriteFile("metadata/"+SyndicationFilename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write syndication: %w", err)
+	}
+	return nil
+}
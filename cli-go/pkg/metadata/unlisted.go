@@ -0,0 +1,56 @@
+package metadata
+
+import (
+	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+const (
+	// UnlistedIndexFilename is the name of the private index of unlisted
+	// posts, kept in .polis/.
+	UnlistedIndexFilename = "unlisted.jsonl"
+)
+
+// Unlisted posts are signed and rendered like any other, but are left out
+// of public.jsonl so index pages, the public feed, and discovery never see
+// them. Their entries live in .polis/unlisted.jsonl instead, in the same
+// format, so the author's tools can still list them.
+
+// LoadUnlistedIndex reads all entries from .polis/unlisted.jsonl.
+func LoadUnlistedIndex(siteDir string) ([]IndexEntry, error) {
+	return loadIndex(storage.For(siteDir), unlistedIndexName)
+}
+
+// AppendToUnlistedIndex adds an entry to .polis/unlisted.jsonl, replacing
+// an entry with the same path.
+func AppendToUnlistedIndex(siteDir string, entry *IndexEntry) error {
+	entry.Path = filepath.ToSlash(entry.Path)
+	return withUnlistedIndexLock(siteDir, func() error {
+		return appendToIndex(storage.For(siteDir), unlistedIndexName, entry)
+	})
+}
+
+// ModifyUnlistedEntry applies update to the entry at path in
+// .polis/unlisted.jsonl. Returns an error if there is no such entry.
+func ModifyUnlistedEntry(siteDir, path string, update func(entry *IndexEntry)) error {
+	path = filepath.ToSlash(path)
+	return withUnlistedIndexLock(siteDir, func() error {
+		return modifyIndexEntry(storage.For(siteDir), unlistedIndexName, path, update)
+	})
+}
+
+// RemoveUnlistedEntry removes an entry from .polis/unlisted.jsonl by path.
+func RemoveUnlistedEntry(siteDir, path string) error {
+	path = filepath.ToSlash(path)
+	return withUnlistedIndexLock(siteDir, func() error {
+		return removeIndexEntry(storage.For(siteDir), unlistedIndexName, path)
+	})
+}
+
+func withUnlistedIndexLock(siteDir string, fn func() error) error {
+	return storage.With(storage.For(siteDir), unlistedIndexName, fn)
+}
+
+// unlistedIndexName is unlisted.jsonl's name in site storage.
+const unlistedIndexName = ".polis/" + UnlistedIndexFilename
@@ -0,0 +1,238 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// MovedPath is where a site publishes its moved statement, on both the old
+// host and the new one.
+const MovedPath = ".well-known/polis-moved"
+
+// Moved is a signed statement that a site has moved to a new base URL. It is
+// signed with the site key, which stays the same across the move, so a
+// reader who knew the site at its old address can check that the new one
+// belongs to the same author.
+type Moved struct {
+	Version    int    `json:"version"`
+	OldBaseURL string `json:"old_base_url"`
+	NewBaseURL string `json:"new_base_url"`
+	PublicKey  string `json:"public_key"`
+	Moved      string `json:"moved"`
+	Signature  string `json:"signature"`
+}
+
+// NewMoved creates and signs a moved statement.
+func NewMoved(oldBaseURL, newBaseURL, publicKey string, privateKey []byte, at time.Time) (*Moved, error) {
+	m := &Moved{
+		Version:    1,
+		OldBaseURL: strings.TrimSuffix(oldBaseURL, "/"),
+		NewBaseURL: strings.TrimSuffix(newBaseURL, "/"),
+		PublicKey:  strings.TrimSpace(publicKey),
+		Moved:      at.UTC().Format(time.RFC3339),
+	}
+	sig, err := signing.SignContent(m.statement(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign moved statement: %w", err)
+	}
+	m.Signature = sig
+	return m, nil
+}
+
+// statement is the signed form: every field but the signature, in a fixed
+// order.
+func (m *Moved) statement() []byte {
+	data, _ := json.Marshal(struct {
+		Version    int    `json:"version"`
+		OldBaseURL string `json:"old_base_url"`
+		NewBaseURL string `json:"new_base_url"`
+		PublicKey  string `json:"public_key"`
+		Moved      string `json:"moved"`
+	}{m.Version, m.OldBaseURL, m.NewBaseURL, m.PublicKey, m.Moved})
+	return data
+}
+
+// Verify checks the statement's signature against publicKey, the key the
+// reader already trusts for the old site. An empty publicKey checks against
+// the key the statement names, which only shows it is self-consistent.
+func (m *Moved) Verify(publicKey string) error {
+	if publicKey == "" {
+		publicKey = m.PublicKey
+	}
+	if strings.TrimSpace(publicKey) != m.PublicKey {
+		return errors.New("moved statement is signed by a different key")
+	}
+	ok, err := signing.VerifySignature(m.statement(), []byte(m.PublicKey), m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid moved statement signature: %w", err)
+	}
+	if !ok {
+		return errors.New("moved statement signature does not verify")
+	}
+	return nil
+}
+
+// ParseMoved decodes a moved statement. It does not verify it.
+func ParseMoved(data []byte) (*Moved, error) {
+	var m Moved
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid moved statement: %w", err)
+	}
+	if m.OldBaseURL == "" || m.NewBaseURL == "" || m.PublicKey == "" || m.Signature == "" {
+		return nil, errors.New("incomplete moved statement")
+	}
+	return &m, nil
+}
+
+// Write saves the statement at MovedPath under dir.
+func (m *Moved) Write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, filepath.FromSlash(MovedPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Rebase rewrites URLs under oldBaseURL to newBaseURL in the site's metadata
+// (the public index, blessed comments, and the like) and returns the files
+// changed, relative to dataDir. following.json is left alone: it lists other sites.
+func Rebase(dataDir, oldBaseURL, newBaseURL string) ([]string, error) {
+	oldBaseURL = strings.TrimSuffix(oldBaseURL, "/")
+	newBaseURL = strings.TrimSuffix(newBaseURL, "/")
+	var changed []string
+	metaDir := filepath.Join(dataDir, "metadata")
+	err := filepath.WalkDir(metaDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == metaDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != ".json" && ext != ".jsonl") || d.Name() == "following.json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Match the base URL only where a path or the end of the string
+		// follows, so https://a.example doesn't rewrite https://a.example.org
+		updated := string(data)
+		for _, end := range []string{"/", `"`} {
+			updated = strings.ReplaceAll(updated, oldBaseURL+end, newBaseURL+end)
+		}
+		if updated == string(data) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := fsutil.WriteFile(path, []byte(updated), info.Mode().Perm()); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dataDir, path)
+		changed = append(changed, filepath.ToSlash(rel))
+		return nil
+	})
+	return changed, err
+}
+
+// StubsResult describes the redirect stubs written for the old host.
+type StubsResult struct {
+	Dir   string `json:"dir"`
+	Pages int    `json:"pages"`
+}
+
+// WriteStubs writes a site for the old host into outDir: a page redirecting
+// to the new address for every rendered page in dataDir, redirect rules for
+// hosts that read _redirects (Netlify, Cloudflare Pages) or .htaccess
+// (Apache), and the moved statement. Hosts with redirect rules also send
+// the markdown files on, which pages can't.
+func WriteStubs(dataDir, outDir string, m *Moved) (*StubsResult, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	result := &StubsResult{Dir: outDir}
+	outAbs, _ := filepath.Abs(outDir)
+	err := filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dataDir, path)
+		if d.IsDir() {
+			if abs, _ := filepath.Abs(path); abs == outAbs || (rel != "." && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".html" {
+			return nil
+		}
+		target := m.NewBaseURL + "/" + filepath.ToSlash(rel)
+		if d.Name() == "index.html" {
+			target = strings.TrimSuffix(target, "index.html")
+		}
+		dest := filepath.Join(outDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, []byte(redirectPage(target)), 0644); err != nil {
+			return err
+		}
+		result.Pages++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := "/* " + m.NewBaseURL + "/:splat 301\n"
+	if err := os.WriteFile(filepath.Join(outDir, "_redirects"), []byte(rules), 0644); err != nil {
+		return nil, err
+	}
+	htaccess := "RewriteEngine On\nRewriteCond %{REQUEST_URI} !^/\\.well-known/polis-moved$\nRewriteRule ^(.*)$ " + m.NewBaseURL + "/$1 [R=301,L]\n"
+	if u, err := url.Parse(m.OldBaseURL); err == nil && strings.Trim(u.Path, "/") != "" {
+		htaccess = "# Place this file in " + u.Path + " on the old host\n" + htaccess
+	}
+	if err := os.WriteFile(filepath.Join(outDir, ".htaccess"), []byte(htaccess), 0644); err != nil {
+		return nil, err
+	}
+	if err := m.Write(outDir); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func redirectPage(target string) string {
+	t := html.EscapeString(target)
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Moved</title>
+<link rel="canonical" href="` + t + `">
+<meta http-equiv="refresh" content="0; url=` + t + `">
+</head>
+<body>
+<p>This page has moved to <a href="` + t + `">` + t + `</a>.</p>
+</body>
+</html>
+`
+}
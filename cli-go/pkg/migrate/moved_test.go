@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestMoved_SignVerify(t *testing.T) {
+	priv, pub, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMoved("https://old.example/", "https://new.example", string(pub), priv, time.Now())
+	if err != nil {
+		t.Fatalf("NewMoved: %v", err)
+	}
+	dir := t.TempDir()
+	if err := m.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".well-known", "polis-moved"))
+	parsed, err := ParseMoved(data)
+	if err != nil {
+		t.Fatalf("ParseMoved: %v", err)
+	}
+	if parsed.OldBaseURL != "https://old.example" {
+		t.Errorf("old_base_url = %q", parsed.OldBaseURL)
+	}
+	if err := parsed.Verify(string(pub)); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	_, otherPub, _ := signing.GenerateKeypair()
+	if err := parsed.Verify(string(otherPub)); err == nil {
+		t.Error("Verify succeeded against another key")
+	}
+	parsed.NewBaseURL = "https://evil.example"
+	if err := parsed.Verify(string(pub)); err == nil {
+		t.Error("Verify succeeded for a tampered statement")
+	}
+}
+
+func TestRebase(t *testing.T) {
+	dir := t.TempDir()
+	meta := filepath.Join(dir, "metadata")
+	os.MkdirAll(meta, 0755)
+	os.WriteFile(filepath.Join(meta, "public.jsonl"), []byte(`{"url":"https://old.example/posts/a.md"}`+"\n"+`{"url":"https://old.example.org/x.md"}`+"\n"), 0644)
+	os.WriteFile(filepath.Join(meta, "following.json"), []byte(`{"following":[{"url":"https://old.example"}]}`), 0644)
+
+	changed, err := Rebase(dir, "https://old.example", "https://new.example")
+	if err != nil {
+		t.Fatalf("Rebase: %v", err)
+	}
+	if len(changed) != 1 {
+		t.Errorf("changed = %v, want only public.jsonl", changed)
+	}
+	index, _ := os.ReadFile(filepath.Join(meta, "public.jsonl"))
+	if !strings.Contains(string(index), "https://new.example/posts/a.md") || !strings.Contains(string(index), "https://old.example.org/x.md") {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+}
+
+func TestWriteStubs(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	m, _ := NewMoved("https://old.example", "https://new.example", string(pub), priv, time.Now())
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "posts", "2026"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".polis"), 0755)
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("home"), 0644)
+	os.WriteFile(filepath.Join(dir, "posts", "2026", "hello.html"), []byte("post"), 0644)
+	os.WriteFile(filepath.Join(dir, ".polis", "draft.html"), []byte("private"), 0644)
+
+	out := filepath.Join(dir, "stubs")
+	result, err := WriteStubs(dir, out, m)
+	if err != nil {
+		t.Fatalf("WriteStubs: %v", err)
+	}
+	if result.Pages != 2 {
+		t.Errorf("pages = %d, want 2", result.Pages)
+	}
+	page, _ := os.ReadFile(filepath.Join(out, "posts", "2026", "hello.html"))
+	if !strings.Contains(string(page), `url=https://new.example/posts/2026/hello.html`) {
+		t.Errorf("unexpected stub:\n%s", page)
+	}
+	home, _ := os.ReadFile(filepath.Join(out, "index.html"))
+	if !strings.Contains(string(home), `url=https://new.example/"`) {
+		t.Errorf("unexpected home stub:\n%s", home)
+	}
+	for _, name := range []string{"_redirects", ".htaccess", ".well-known/polis-moved"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, ".polis")); !os.IsNotExist(err) {
+		t.Error("stubs include .polis")
+	}
+}
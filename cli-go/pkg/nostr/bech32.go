@@ -0,0 +1,87 @@
+package nostr
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// NIP-19 bech32 encodings: npub public keys and naddr article addresses.
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32Encode encodes data under a human-readable prefix. NIP-19 has no
+// length limit, unlike BIP-173.
+func bech32Encode(hrp string, data []byte) string {
+	// Regroup 8-bit bytes into 5-bit values
+	var values []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+
+	expanded := make([]byte, 0, len(hrp)*2+1+len(values)+6)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	expanded = append(expanded, values...)
+	mod := bech32Polymod(append(expanded, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, v := range values {
+		b.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[(mod>>(5*(5-i)))&31])
+	}
+	return b.String()
+}
+
+// Npub returns the npub form of a hex public key.
+func Npub(pubkeyHex string) string {
+	return bech32Encode("npub", mustHex(pubkeyHex))
+}
+
+// Naddr returns the naddr address of a parameterized replaceable event,
+// with an optional relay hint.
+func Naddr(kind int, pubkeyHex, identifier, relay string) string {
+	var tlv []byte
+	tlv = append(tlv, 0, byte(len(identifier)))
+	tlv = append(tlv, identifier...)
+	if relay != "" && len(relay) < 256 {
+		tlv = append(tlv, 1, byte(len(relay)))
+		tlv = append(tlv, relay...)
+	}
+	tlv = append(tlv, 2, 32)
+	tlv = append(tlv, mustHex(pubkeyHex)...)
+	tlv = append(tlv, 3, 4)
+	tlv = binary.BigEndian.AppendUint32(tlv, uint32(kind))
+	return bech32Encode("naddr", tlv)
+}
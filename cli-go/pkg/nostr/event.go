@@ -0,0 +1,153 @@
+package nostr
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// KindLongForm is the NIP-23 long-form content event kind. It is
+// parameterized replaceable: a later event with the same "d" tag replaces
+// the earlier one, so republishing a post updates its mirror.
+const KindLongForm = 30023
+
+// Event is a NIP-01 event.
+type Event struct {
+	ID        string     `json:"id"`
+	PubKey    string     `json:"pubkey"`
+	CreatedAt int64      `json:"created_at"`
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig"`
+}
+
+// Key is a nostr key pair.
+type Key struct {
+	secret []byte
+	PubKey string // Hex x-only public key
+}
+
+// NewKey returns the key for a 32-byte secret.
+func NewKey(secret []byte) (*Key, error) {
+	pub, err := publicKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{secret: secret, PubKey: hex.EncodeToString(pub)}, nil
+}
+
+// Npub returns the key's public key in npub form.
+func (k *Key) Npub() string {
+	return Npub(k.PubKey)
+}
+
+// serialize returns the NIP-01 serialization the event ID is hashed from.
+// NIP-01 fixes the string escaping, which differs from encoding/json's
+// (U+2028 and U+2029 stay raw), so it is written by hand.
+func (e *Event) serialize() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[0,%s,%d,%d,[", quoteString(e.PubKey), e.CreatedAt, e.Kind)
+	for i, tag := range e.Tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('[')
+		for j, v := range tag {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(quoteString(v))
+		}
+		b.WriteByte(']')
+	}
+	b.WriteString("]," + quoteString(e.Content) + "]")
+	return b.Bytes()
+}
+
+// quoteString quotes s as NIP-01 requires.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Sign sets the event's pubkey, ID, and signature.
+func (e *Event) Sign(k *Key) error {
+	e.PubKey = k.PubKey
+	if e.Tags == nil {
+		e.Tags = [][]string{}
+	}
+	id := sha256.Sum256(e.serialize())
+	aux := make([]byte, 32)
+	if _, err := rand.Read(aux); err != nil {
+		return err
+	}
+	sig, err := signSchnorr(k.secret, id[:], aux)
+	if err != nil {
+		return err
+	}
+	e.ID = hex.EncodeToString(id[:])
+	e.Sig = hex.EncodeToString(sig)
+	return nil
+}
+
+// Verify checks the event's ID and signature.
+func (e *Event) Verify() error {
+	id := sha256.Sum256(e.serialize())
+	if hex.EncodeToString(id[:]) != e.ID {
+		return fmt.Errorf("event ID does not match its content")
+	}
+	pub, err1 := hex.DecodeString(e.PubKey)
+	sig, err2 := hex.DecodeString(e.Sig)
+	if err1 != nil || err2 != nil || !verifySchnorr(pub, id[:], sig) {
+		return fmt.Errorf("invalid event signature")
+	}
+	return nil
+}
+
+// Tag returns the first value of the named tag, or "".
+func (e *Event) Tag(name string) string {
+	for _, t := range e.Tags {
+		if len(t) > 1 && t[0] == name {
+			return t[1]
+		}
+	}
+	return ""
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,212 @@
+// Package nostr mirrors published posts to nostr relays (experimental).
+//
+// Each public post becomes a NIP-23 long-form event (kind 30023) whose "d"
+// tag is the post's path, so mirroring a republished post replaces the
+// earlier event instead of adding another. Events are signed with a
+// secp256k1 key derived from the site's Ed25519 key, so the nostr identity
+// needs no key file of its own and is the same wherever the site key is.
+// The mirrored event and post version are recorded in
+// metadata/syndication.json.
+package nostr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// Service is the service name recorded in syndication.json.
+const Service = "nostr"
+
+// KeyLabel is the label the nostr key is derived from the site key under.
+const KeyLabel = "nostr"
+
+// DefaultRelays are used when nostr.relays is unset.
+const DefaultRelays = "wss://relay.damus.io,wss://nos.lol"
+
+// ErrNotPublic is returned for unlisted and followers-only posts.
+var ErrNotPublic = errors.New("only public posts are mirrored")
+
+// DeriveKey returns the site's nostr key, derived from its private key.
+func DeriveKey(privateKeyPEM []byte) (*Key, error) {
+	secret, err := signing.DeriveSecret(privateKeyPEM, KeyLabel)
+	if err != nil {
+		return nil, err
+	}
+	// A secret outside the curve order is astronomically unlikely; hash
+	// again rather than fail
+	for {
+		if _, err := secretScalar(secret); err == nil {
+			return NewKey(secret)
+		}
+		h := sha256.Sum256(secret)
+		secret = h[:]
+	}
+}
+
+// ParseRelays splits the nostr.relays setting.
+func ParseRelays(setting string) []string {
+	return strings.FieldsFunc(setting, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// rootLink matches a markdown link or image target on the site's root.
+var rootLink = regexp.MustCompile(`(\]\()/`)
+
+// Article builds the unsigned long-form event for a published post. Links
+// to site-relative paths are made absolute, since nostr clients show the
+// content away from the site.
+func Article(dataDir, baseURL, postPath string, now time.Time) (*Event, error) {
+	postPath = filepath.ToSlash(postPath)
+	if publish.IsProtectedPath(postPath) {
+		return nil, ErrNotPublic
+	}
+	content, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(postPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post: %w", err)
+	}
+	fm := publish.ParseFrontmatterFields(string(content))
+	if fm.Visibility != publish.VisibilityPublic {
+		return nil, ErrNotPublic
+	}
+
+	body := strings.TrimSpace(publish.StripFrontmatter(string(content)))
+	title := fm.Title
+	if title == "" {
+		title = publish.ExtractTitle(body)
+	}
+	// The title is a tag of its own; clients show it above the content
+	if first, rest, _ := strings.Cut(body, "\n"); strings.TrimSpace(strings.TrimPrefix(first, "# ")) == title {
+		body = strings.TrimSpace(rest)
+	}
+	root := strings.TrimSuffix(baseURL, "/")
+	body = rootLink.ReplaceAllString(body, "${1}"+root+"/")
+
+	summary := fm.Summary
+	if summary == "" {
+		summary = publish.Summarize(string(content))
+	}
+	tags := [][]string{
+		{"d", postPath},
+		{"title", title},
+	}
+	if summary != "" {
+		tags = append(tags, []string{"summary", summary})
+	}
+	if t, err := time.Parse(time.RFC3339, fm.Published); err == nil {
+		tags = append(tags, []string{"published_at", strconv.FormatInt(t.Unix(), 10)})
+	}
+	for _, tag := range fm.Tags {
+		tags = append(tags, []string{"t", strings.ToLower(tag)})
+	}
+	if baseURL != "" {
+		tags = append(tags, []string{"r", publish.PageURL(baseURL, postPath)})
+	}
+	tags = append(tags, []string{"alt", "Long-form article: " + title})
+
+	return &Event{
+		CreatedAt: now.Unix(),
+		Kind:      KindLongForm,
+		Tags:      tags,
+		Content:   body,
+	}, nil
+}
+
+// Result reports a mirror to each relay.
+type Result struct {
+	Link     *metadata.SyndicationLink
+	Accepted []string          // Relays that took the event
+	Failed   map[string]string // Relay -> error
+}
+
+// Mirror signs the post as a long-form event and sends it to each relay,
+// recording it in syndication.json if any relay accepted it.
+func Mirror(dataDir, baseURL string, relays []string, key *Key, postPath string) (*Result, error) {
+	if len(relays) == 0 {
+		return nil, errors.New("no nostr relays configured (set nostr.relays)")
+	}
+	postPath = filepath.ToSlash(postPath)
+	ev, err := Article(dataDir, baseURL, postPath, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ev.Sign(key); err != nil {
+		return nil, fmt.Errorf("failed to sign event: %w", err)
+	}
+
+	res := &Result{Failed: make(map[string]string)}
+	for _, relay := range relays {
+		if err := Publish(relay, ev); err != nil {
+			res.Failed[relay] = err.Error()
+			continue
+		}
+		res.Accepted = append(res.Accepted, relay)
+	}
+	if len(res.Accepted) == 0 {
+		return res, fmt.Errorf("no relay accepted the event")
+	}
+
+	fm, err := publish.ReadFrontmatter(dataDir, postPath)
+	if err != nil {
+		return res, err
+	}
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return res, err
+	}
+	res.Link = &metadata.SyndicationLink{
+		Service: Service,
+		URL:     "nostr:" + Naddr(KindLongForm, key.PubKey, postPath, res.Accepted[0]),
+		ID:      ev.ID,
+		Version: fm.CurrentVersion,
+		Posted:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	synd.SetLink(postPath, *res.Link)
+	if err := metadata.SaveSyndication(dataDir, synd); err != nil {
+		return res, fmt.Errorf("mirrored, but failed to save %s: %w", metadata.SyndicationFilename, err)
+	}
+	return res, nil
+}
+
+// Mirrored reports whether a post has been mirrored before.
+func Mirrored(dataDir, postPath string) bool {
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return false
+	}
+	_, ok := synd.Link(filepath.ToSlash(postPath), Service)
+	return ok
+}
+
+// Stale returns the mirrored posts whose current version differs from the
+// version mirrored, and, with all set, the public posts never mirrored.
+func Stale(dataDir string, all bool) ([]string, error) {
+	synd, err := metadata.LoadSyndication(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := metadata.LoadPublicIndex(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, e := range entries {
+		if e.Type != "" && e.Type != "post" {
+			continue
+		}
+		link, ok := synd.Link(e.Path, Service)
+		if (ok && link.Version != e.CurrentVersion) || (!ok && all) {
+			stale = append(stale, e.Path)
+		}
+	}
+	return stale, nil
+}
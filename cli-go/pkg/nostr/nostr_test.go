@@ -0,0 +1,142 @@
+package nostr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/websocket"
+)
+
+// NIP-19 example.
+func TestNpub(t *testing.T) {
+	got := Npub("3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d")
+	if got != "npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6" {
+		t.Errorf("Npub = %s", got)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	privKey, _, _ := signing.GenerateKeypair()
+	k1, err := DeriveKey(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, _ := DeriveKey(privKey)
+	if k1.PubKey != k2.PubKey || len(k1.PubKey) != 64 {
+		t.Errorf("derived keys differ: %s %s", k1.PubKey, k2.PubKey)
+	}
+	if !strings.HasPrefix(k1.Npub(), "npub1") {
+		t.Errorf("Npub = %s", k1.Npub())
+	}
+}
+
+func TestEventSignVerify(t *testing.T) {
+	privKey, _, _ := signing.GenerateKeypair()
+	key, _ := DeriveKey(privKey)
+	ev := &Event{CreatedAt: 1700000000, Kind: 1, Content: "line sep \"quoted\" <b>\n", Tags: [][]string{{"t", "x"}}}
+	if err := ev.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := ev.Verify(); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if want := `[0,"` + key.PubKey + `",1700000000,1,[["t","x"]],"line` + " " + `sep \"quoted\" <b>\n"]`; string(ev.serialize()) != want {
+		t.Errorf("serialize = %s", ev.serialize())
+	}
+	ev.Content = "changed"
+	if ev.Verify() == nil {
+		t.Error("changed event verifies")
+	}
+}
+
+// fakeRelay accepts WebSocket connections and answers each EVENT with OK,
+// rejecting events whose content contains "spam".
+func fakeRelay(t *testing.T, received *[]Event) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Upgrade(w, r, maxRelayMessage)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			var m []json.RawMessage
+			json.Unmarshal(msg, &m)
+			var ev Event
+			json.Unmarshal(m[1], &ev)
+			*received = append(*received, ev)
+			ok := ev.Verify() == nil && !strings.Contains(ev.Content, "spam")
+			reply, _ := json.Marshal([]interface{}{"OK", ev.ID, ok, "blocked: no"})
+			ws.WriteText(reply)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMirror(t *testing.T) {
+	var received []Event
+	relay := "ws" + strings.TrimPrefix(fakeRelay(t, &received).URL, "http")
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	key, _ := DeriveKey(privKey)
+
+	result, err := publish.PublishPostWithOptions(dataDir, "# Hello\n\nSee ![cup](/attachments/cup.png).\n", publish.PublishOptions{Slug: "hello"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Mirror(dataDir, "https://example.com", []string{relay, "wss://127.0.0.1:1"}, key, result.Path)
+	if err != nil {
+		t.Fatalf("Mirror failed: %v", err)
+	}
+	if len(res.Accepted) != 1 || len(res.Failed) != 1 {
+		t.Errorf("accepted %v, failed %v", res.Accepted, res.Failed)
+	}
+	if len(received) != 1 {
+		t.Fatalf("relay received %d events", len(received))
+	}
+	ev := received[0]
+	if ev.Kind != KindLongForm || ev.Tag("d") != result.Path || ev.Tag("title") != "Hello" || ev.PubKey != key.PubKey {
+		t.Errorf("event = %+v", ev)
+	}
+	if ev.Content != "See ![cup](https://example.com/attachments/cup.png)." {
+		t.Errorf("content = %q", ev.Content)
+	}
+	if !strings.HasPrefix(res.Link.URL, "nostr:naddr1") || res.Link.Version != result.Version {
+		t.Errorf("link = %+v", res.Link)
+	}
+	if !Mirrored(dataDir, result.Path) {
+		t.Error("post not recorded as mirrored")
+	}
+
+	if stale, _ := Stale(dataDir, false); len(stale) != 0 {
+		t.Errorf("stale after mirror = %v", stale)
+	}
+	time.Sleep(1100 * time.Millisecond) // republish needs a new timestamp
+	if _, err := publish.RepublishPost(dataDir, result.Path, "# Hello\n\nEdited.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	if stale, _ := Stale(dataDir, false); len(stale) != 1 || stale[0] != result.Path {
+		t.Errorf("stale after republish = %v", stale)
+	}
+
+	spam, _ := publish.PublishPostWithOptions(dataDir, "# Buy\n\nspam spam\n", publish.PublishOptions{}, privKey)
+	if _, err := Mirror(dataDir, "https://example.com", []string{relay}, key, spam.Path); err == nil {
+		t.Error("rejected event: want error")
+	}
+	synd, _ := metadata.LoadSyndication(dataDir)
+	if _, ok := synd.Link(spam.Path, Service); ok {
+		t.Error("rejected event recorded")
+	}
+}
@@ -0,0 +1,106 @@
+package nostr
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
+	"github.com/vdibart/polis-cli/cli-go/pkg/websocket"
+)
+
+// relayTimeout bounds connecting to a relay and waiting for its answer.
+const relayTimeout = 15 * time.Second
+
+// maxRelayMessage bounds a message read from a relay.
+const maxRelayMessage = 1 << 20
+
+// ErrRejected is returned when a relay refuses an event.
+var ErrRejected = errors.New("relay rejected the event")
+
+// Publish sends a signed event to a relay and waits for its OK.
+func Publish(relayURL string, ev *Event) error {
+	ws, err := dialRelay(relayURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ws.WriteClose(websocket.CloseNormal, "")
+		ws.Close()
+	}()
+
+	msg, err := json.Marshal([]interface{}{"EVENT", ev})
+	if err != nil {
+		return err
+	}
+	if err := ws.WriteText(msg); err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("no answer from relay: %w", err)
+		}
+		var reply []json.RawMessage
+		if json.Unmarshal(data, &reply) != nil || len(reply) < 3 {
+			continue
+		}
+		var typ, id string
+		json.Unmarshal(reply[0], &typ)
+		json.Unmarshal(reply[1], &id)
+		if typ != "OK" || id != ev.ID {
+			continue // NOTICE, AUTH, ...
+		}
+		var accepted bool
+		var reason string
+		json.Unmarshal(reply[2], &accepted)
+		if len(reply) > 3 {
+			json.Unmarshal(reply[3], &reason)
+		}
+		if !accepted {
+			return fmt.Errorf("%w: %s", ErrRejected, reason)
+		}
+		return nil
+	}
+}
+
+// dialRelay opens a WebSocket to a wss:// relay (ws:// only with
+// allow_local_fetch), subject to the same address checks as HTTP requests.
+func dialRelay(rawURL string) (*websocket.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relay URL %q: %w", rawURL, err)
+	}
+	port := "443"
+	switch {
+	case u.Scheme == "wss":
+	case u.Scheme == "ws" && safehttp.AllowLocal:
+		port = "80"
+	default:
+		return nil, fmt.Errorf("%w: %s", safehttp.ErrInsecureScheme, rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err := safehttp.NewDialer(relayTimeout).Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Host, err)
+	}
+	conn.SetDeadline(time.Now().Add(relayTimeout))
+	if u.Scheme == "wss" {
+		tc := tls.Client(conn, &tls.Config{ServerName: u.Hostname(), NextProtos: []string{"http/1.1"}})
+		if err := tc.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", u.Host, err)
+		}
+		conn = tc
+	}
+
+	return websocket.Handshake(conn, u, maxRelayMessage)
+}
@@ -0,0 +1,177 @@
+package nostr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// BIP-340 Schnorr signatures over secp256k1, the signature scheme nostr
+// uses. The standard library has no secp256k1, and the few signatures a
+// bridge run makes don't need a constant-time or fast implementation, so
+// this is plain affine arithmetic on math/big.
+
+var (
+	curveP  = hexInt("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+	curveN  = hexInt("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141")
+	curveG  = point{hexInt("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"), hexInt("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8")}
+	curveB  = big.NewInt(7)
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(curveP, big.NewInt(1)), 2) // (p+1)/4
+)
+
+var errInvalidKey = errors.New("invalid secp256k1 key")
+
+func hexInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 16)
+	return n
+}
+
+// point is an affine curve point; a nil x is the point at infinity.
+type point struct{ x, y *big.Int }
+
+func (p point) infinity() bool { return p.x == nil }
+
+func mod(n *big.Int) *big.Int { return n.Mod(n, curveP) }
+
+func add(a, b point) point {
+	if a.infinity() {
+		return b
+	}
+	if b.infinity() {
+		return a
+	}
+	var lambda *big.Int
+	if a.x.Cmp(b.x) == 0 {
+		if a.y.Cmp(b.y) != 0 || a.y.Sign() == 0 {
+			return point{}
+		}
+		// Tangent: 3x² / 2y
+		num := new(big.Int).Mul(a.x, a.x)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(a.y, 1)
+		lambda = mod(num.Mul(num, den.ModInverse(den, curveP)))
+	} else {
+		num := new(big.Int).Sub(b.y, a.y)
+		den := mod(new(big.Int).Sub(b.x, a.x))
+		lambda = mod(num.Mul(num, den.ModInverse(den, curveP)))
+	}
+	x := new(big.Int).Mul(lambda, lambda)
+	x = mod(x.Sub(x, a.x).Sub(x, b.x))
+	y := new(big.Int).Sub(a.x, x)
+	y = mod(y.Mul(y, lambda).Sub(y, a.y))
+	return point{x, y}
+}
+
+func mul(k *big.Int, p point) point {
+	var r point
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = add(r, r)
+		if k.Bit(i) == 1 {
+			r = add(r, p)
+		}
+	}
+	return r
+}
+
+// liftX returns the point with the given x and an even y.
+func liftX(x *big.Int) (point, bool) {
+	if x.Cmp(curveP) >= 0 {
+		return point{}, false
+	}
+	c := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	c = mod(c.Add(c, curveB))
+	y := new(big.Int).Exp(c, sqrtExp, curveP)
+	if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(c) != 0 {
+		return point{}, false
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(curveP, y)
+	}
+	return point{new(big.Int).Set(x), y}, true
+}
+
+func bytes32(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+func taggedHash(tag string, parts ...[]byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// secretScalar checks a 32-byte secret key.
+func secretScalar(secret []byte) (*big.Int, error) {
+	d := new(big.Int).SetBytes(secret)
+	if len(secret) != 32 || d.Sign() == 0 || d.Cmp(curveN) >= 0 {
+		return nil, errInvalidKey
+	}
+	return d, nil
+}
+
+// publicKey returns the x-only public key of a secret key.
+func publicKey(secret []byte) ([]byte, error) {
+	d, err := secretScalar(secret)
+	if err != nil {
+		return nil, err
+	}
+	return bytes32(mul(d, curveG).x), nil
+}
+
+// signSchnorr signs a 32-byte message. aux is 32 bytes of fresh randomness.
+func signSchnorr(secret, msg, aux []byte) ([]byte, error) {
+	d, err := secretScalar(secret)
+	if err != nil {
+		return nil, err
+	}
+	P := mul(d, curveG)
+	if P.y.Bit(0) == 1 {
+		d.Sub(curveN, d)
+	}
+	t := bytes32(d)
+	for i, b := range taggedHash("BIP0340/aux", aux) {
+		t[i] ^= b
+	}
+	px := bytes32(P.x)
+	k := new(big.Int).SetBytes(taggedHash("BIP0340/nonce", t, px, msg))
+	k.Mod(k, curveN)
+	if k.Sign() == 0 {
+		return nil, errors.New("signing failed: zero nonce")
+	}
+	R := mul(k, curveG)
+	if R.y.Bit(0) == 1 {
+		k.Sub(curveN, k)
+	}
+	rx := bytes32(R.x)
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", rx, px, msg))
+	e.Mod(e, curveN)
+	s := e.Mul(e, d).Add(e, k)
+	s.Mod(s, curveN)
+	return append(rx, bytes32(s)...), nil
+}
+
+// verifySchnorr checks a signature against an x-only public key.
+func verifySchnorr(pub, msg, sig []byte) bool {
+	if len(pub) != 32 || len(sig) != 64 {
+		return false
+	}
+	P, ok := liftX(new(big.Int).SetBytes(pub))
+	if !ok {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(curveP) >= 0 || s.Cmp(curveN) >= 0 {
+		return false
+	}
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], pub, msg))
+	e.Mod(e, curveN)
+	e.Sub(curveN, e) // -e
+	R := add(mul(s, curveG), mul(e, P))
+	return !R.infinity() && R.y.Bit(0) == 0 && R.x.Cmp(r) == 0
+}
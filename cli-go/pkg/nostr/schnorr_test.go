@@ -0,0 +1,45 @@
+package nostr
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// BIP-340 test vector 0.
+func TestSignSchnorr_BIP340Vector(t *testing.T) {
+	secret := unhex(t, "0000000000000000000000000000000000000000000000000000000000000003")
+	zero := make([]byte, 32)
+
+	pub, err := publicKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ToUpper(hex.EncodeToString(pub)); got != "F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9" {
+		t.Errorf("public key = %s", got)
+	}
+	sig, err := signSchnorr(secret, zero, zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0"
+	if got := strings.ToUpper(hex.EncodeToString(sig)); got != want {
+		t.Errorf("signature = %s", got)
+	}
+	if !verifySchnorr(pub, zero, sig) {
+		t.Error("signature does not verify")
+	}
+	sig[5] ^= 1
+	if verifySchnorr(pub, zero, sig) {
+		t.Error("tampered signature verifies")
+	}
+}
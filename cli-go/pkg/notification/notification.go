@@ -6,12 +6,14 @@
 package notification
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
 )
 
 // Version is set at init time by cmd package.
@@ -60,22 +62,16 @@ func NewManager(dataDir, discoveryDomain string) *Manager {
 
 // List returns all notification entries from state.jsonl.
 func (m *Manager) List() ([]StateEntry, error) {
-	file, err := os.Open(m.stateFile)
+	lines, err := fsutil.ReadJSONLines(m.stateFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []StateEntry{}, nil
 		}
-		return nil, fmt.Errorf("failed to open state file: %w", err)
+		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
-	defer file.Close()
 
 	var entries []StateEntry
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
+	for _, line := range lines {
 		var e StateEntry
 		if err := json.Unmarshal(line, &e); err != nil {
 			continue // Skip malformed lines
@@ -83,10 +79,6 @@ func (m *Manager) List() ([]StateEntry, error) {
 		entries = append(entries, e)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
-	}
-
 	return entries, nil
 }
 
@@ -120,25 +112,16 @@ func (m *Manager) Append(entries []StateEntry) (int, error) {
 		return 0, nil
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(m.stateFile), 0755); err != nil {
-		return 0, fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	file, err := os.OpenFile(m.stateFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return 0, fmt.Errorf("failed to open state file: %w", err)
-	}
-	defer file.Close()
-
+	var lines [][]byte
 	for _, e := range toWrite {
 		data, err := json.Marshal(e)
 		if err != nil {
 			continue
 		}
-		if _, err := file.WriteString(string(data) + "\n"); err != nil {
-			return 0, fmt.Errorf("failed to write entry: %w", err)
-		}
+		lines = append(lines, data)
+	}
+	if err := fsutil.AppendJSONLines(m.stateFile, lines, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write entry: %w", err)
 	}
 
 	return len(toWrite), nil
@@ -291,23 +274,18 @@ func (m *Manager) Prune(cfg PruneConfig) (int, error) {
 
 // writeAll rewrites the entire state file.
 func (m *Manager) writeAll(entries []StateEntry) error {
-	if err := os.MkdirAll(filepath.Dir(m.stateFile), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	file, err := os.Create(m.stateFile)
-	if err != nil {
-		return fmt.Errorf("failed to create state file: %w", err)
-	}
-	defer file.Close()
-
+	var buf bytes.Buffer
 	for _, e := range entries {
 		data, err := json.Marshal(e)
 		if err != nil {
 			continue
 		}
-		file.WriteString(string(data) + "\n")
+		buf.Write(data)
+		buf.WriteByte('\n')
 	}
 
+	if err := fsutil.WriteFile(m.stateFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
 	return nil
 }
@@ -21,7 +21,8 @@ type Rule struct {
 
 // RuleFilter specifies how to determine relevance of an event.
 type RuleFilter struct {
-	// Relevance is one of: "target_domain", "source_domain", "followed_author"
+	// Relevance is one of: "target_domain", "source_domain", "followed_author",
+	// or "mentioned" (local mention events naming our domain)
 	Relevance string `json:"relevance"`
 }
 
@@ -103,6 +104,13 @@ func DefaultRules() []Rule {
 			Filter:    RuleFilter{Relevance: "followed_author"},
 			Template:  RuleTemplate{Icon: "\U0001F4DD", Message: "{{actor}} updated a post", Link: "/_/#feed"},
 		},
+		{
+			ID:        "mention",
+			EventType: "polis.mention.detected",
+			Enabled:   true,
+			Filter:    RuleFilter{Relevance: "mentioned"},
+			Template:  RuleTemplate{Icon: "\U0001F517", Message: "{{actor}} mentioned you in {{post_name}}", Link: "{{source_url}}"},
+		},
 	}
 }
 
@@ -6,8 +6,8 @@ import (
 
 func TestDefaultRules(t *testing.T) {
 	rules := DefaultRules()
-	if len(rules) != 9 {
-		t.Errorf("DefaultRules() returned %d rules, want 9", len(rules))
+	if len(rules) != 10 {
+		t.Errorf("DefaultRules() returned %d rules, want 10", len(rules))
 	}
 
 	// Check all event types are covered
@@ -21,6 +21,7 @@ func TestDefaultRules(t *testing.T) {
 		"polis.comment.republished": false,
 		"polis.post.published":      false,
 		"polis.post.republished":    false,
+		"polis.mention.detected":    false,
 	}
 	for _, r := range rules {
 		if _, ok := eventTypes[r.EventType]; !ok {
@@ -0,0 +1,184 @@
+// Package posttemplate keeps a site's post templates: named markdown
+// skeletons (frontmatter and a body scaffold) that new drafts start from.
+//
+// A template lives at .polis/templates/posts/<name>.md. Placeholders such
+// as {{title}} are filled in when a draft is made from it: {{date}} and
+// {{datetime}} with the current time, the rest from the values given.
+// Placeholders without a value are left in the draft to fill in by hand.
+package posttemplate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// Dir holds a site's post templates, relative to the site root.
+const Dir = ".polis/templates/posts"
+
+// Blank is what a draft made without a template starts from.
+const Blank = "---\ntitle: {{title}}\n---\n\n"
+
+var (
+	// ErrNotFound is returned for a template that doesn't exist.
+	ErrNotFound = errors.New("template not found")
+
+	// ErrExists is returned when creating a template whose name is taken.
+	ErrExists = errors.New("template already exists")
+)
+
+var (
+	namePattern        = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+)
+
+// builtins are the placeholders filled in from the time a draft is made.
+var builtins = map[string]func(time.Time) string{
+	"date":     func(t time.Time) string { return t.Format("2006-01-02") },
+	"datetime": func(t time.Time) string { return t.Format(time.RFC3339) },
+}
+
+// Template is a named post skeleton.
+type Template struct {
+	Name      string   `json:"name"`
+	Content   string   `json:"content"`
+	Variables []string `json:"variables"` // Placeholders to ask for, besides the built-in ones
+	Updated   string   `json:"updated"`
+}
+
+// ValidName reports whether name can name a template.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+func fileName(name string) string {
+	return Dir + "/" + name + ".md"
+}
+
+// Variables returns the placeholders in content that aren't filled in
+// automatically, in order of first use.
+func Variables(content string) []string {
+	vars := []string{}
+	seen := map[string]bool{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(content, -1) {
+		name := m[1]
+		if _, ok := builtins[name]; ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		vars = append(vars, name)
+	}
+	return vars
+}
+
+// Expand fills in content's placeholders: the built-in ones from now (in
+// local time), the rest from vars. Placeholders without a value are kept.
+func Expand(content string, vars map[string]string, now time.Time) string {
+	now = now.Local()
+	return placeholderPattern.ReplaceAllStringFunc(content, func(m string) string {
+		name := placeholderPattern.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if fn, ok := builtins[name]; ok {
+			return fn(now)
+		}
+		return m
+	})
+}
+
+// Get reads the named template.
+func Get(dataDir, name string) (*Template, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	fsys := storage.For(dataDir)
+	data, err := fsys.ReadFile(fileName(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	t := &Template{Name: name, Content: string(data), Variables: Variables(string(data))}
+	if info, err := fsys.Stat(fileName(name)); err == nil {
+		t.Updated = info.ModTime().UTC().Format(time.RFC3339)
+	}
+	return t, nil
+}
+
+// List returns the site's templates, ordered by name.
+func List(dataDir string) ([]*Template, error) {
+	entries, err := storage.For(dataDir).ReadDir(Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Template{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	templates := []*Template{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".md")
+		if e.IsDir() || !ok || !ValidName(name) {
+			continue
+		}
+		if t, err := Get(dataDir, name); err == nil {
+			templates = append(templates, t)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Save writes the named template, replacing it if it exists.
+func Save(dataDir, name, content string) (*Template, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid template name %q (use lowercase letters, digits, '-', '_')", name)
+	}
+	if err := storage.For(dataDir).WriteFile(fileName(name), []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+	return Get(dataDir, name)
+}
+
+// Create writes a new template; ErrExists if the name is taken.
+func Create(dataDir, name, content string) (*Template, error) {
+	if _, err := Get(dataDir, name); err == nil {
+		return nil, ErrExists
+	}
+	return Save(dataDir, name, content)
+}
+
+// Delete removes the named template.
+func Delete(dataDir, name string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("invalid template name %q", name)
+	}
+	err := storage.For(dataDir).Remove(fileName(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// NewDraft saves a post draft made from the named template (Blank if name
+// is empty), with its placeholders filled in from vars and now, and returns
+// the draft's ID.
+func NewDraft(dataDir, name string, vars map[string]string, now time.Time) (string, error) {
+	content := Blank
+	if name != "" {
+		t, err := Get(dataDir, name)
+		if err != nil {
+			return "", err
+		}
+		content = t.Content
+	}
+	return publish.SaveDraft(dataDir, "", Expand(content, vars, now))
+}
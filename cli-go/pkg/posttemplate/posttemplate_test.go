@@ -0,0 +1,93 @@
+package posttemplate
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+const review = `---
+title: "Review: {{title}}"
+rating: {{rating}}
+---
+
+# {{ title }}
+
+Read on {{date}}.
+`
+
+func TestExpand(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.Local)
+	got := Expand(review, map[string]string{"title": "Dune"}, now)
+	want := `---
+title: "Review: Dune"
+rating: {{rating}}
+---
+
+# Dune
+
+Read on 2026-03-14.
+`
+	if got != want {
+		t.Errorf("Expand() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Variables(review); !reflect.DeepEqual(got, []string{"title", "rating"}) {
+		t.Errorf("Variables() = %v", got)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+
+	if list, err := List(dir); err != nil || len(list) != 0 {
+		t.Fatalf("List() on a new site = %v, %v", list, err)
+	}
+	if _, err := Create(dir, "review", review); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(dir, "review", "again"); !errors.Is(err, ErrExists) {
+		t.Errorf("Create() with a taken name: %v, want ErrExists", err)
+	}
+	if _, err := Save(dir, "Bad Name", "x"); err == nil {
+		t.Error("Save() accepted an invalid name")
+	}
+	if _, err := Save(dir, "note", "{{date}}"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := List(dir)
+	if err != nil || len(list) != 2 || list[0].Name != "note" || list[1].Name != "review" {
+		t.Fatalf("List() = %v, %v", list, err)
+	}
+	if len(list[0].Variables) != 0 || list[0].Updated == "" {
+		t.Errorf("note = %+v", list[0])
+	}
+
+	now := time.Now()
+	id, err := NewDraft(dir, "review", map[string]string{"title": "Dune", "rating": "5"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(publish.DraftPath(dir, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Expand(review, map[string]string{"title": "Dune", "rating": "5"}, now); string(data) != want {
+		t.Errorf("draft =\n%s", data)
+	}
+	if _, err := NewDraft(dir, "missing", nil, time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("NewDraft() from a missing template: %v, want ErrNotFound", err)
+	}
+
+	if err := Delete(dir, "note"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(dir, "note"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() twice: %v, want ErrNotFound", err)
+	}
+}
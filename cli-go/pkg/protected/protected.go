@@ -0,0 +1,212 @@
+// Package protected implements the signed-request handshake for
+// followers-only content.
+//
+// Followers-only posts are published under protected/ (see
+// publish.ProtectedDir) and served only by polis serve. A reader fetches
+// one in two round trips:
+//
+//  1. An unsigned GET is answered with 401 and
+//     "WWW-Authenticate: Polis-Signature challenge=<nonce>".
+//  2. The reader signs Payload(challenge, host, path) with their site key
+//     and repeats the GET with
+//     "Authorization: Polis-Signature actor=<domain>, challenge=<nonce>, signature=<base64>".
+//
+// The server checks that it issued the challenge (each is good for one
+// request, for ChallengeTTL), verifies the signature against the key
+// published in the actor's .well-known/polis, and then decides whether the
+// actor may read the file (polis serve requires a follower). The host in the
+// signed payload is the server's own, from its configuration; the request's
+// Host header is chosen by the client and can't be trusted for this.
+package protected
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// Scheme is the HTTP authentication scheme name.
+const Scheme = "Polis-Signature"
+
+// ChallengeTTL is how long an issued challenge may be answered.
+const ChallengeTTL = 2 * time.Minute
+
+// maxChallenges bounds the number of outstanding challenges a store holds.
+// Past it, the oldest are dropped, so a flood of unsigned requests can only
+// shorten how long a challenge stays good, not stop new ones being issued.
+const maxChallenges = 10000
+
+// Errors returned by Verify.
+var (
+	ErrNoCredentials    = errors.New("request is not signed")
+	ErrUnknownChallenge = errors.New("challenge is unknown, used, or expired")
+	ErrBadSignature     = errors.New("signature does not match the actor's key")
+)
+
+// Payload returns the bytes a reader signs to answer challenge for the
+// file at path on host. Binding the host stops a site from relaying a
+// reader's signature to another site.
+func Payload(challenge, host, path string) []byte {
+	return []byte("polis-protected-fetch\n" + challenge + "\n" + strings.ToLower(host) + "\n" + path)
+}
+
+// Credentials are the parameters of a signed request.
+type Credentials struct {
+	Actor     string
+	Challenge string
+	Signature string // Armored SSH signature (base64-encoded on the wire)
+}
+
+// Header formats credentials as an Authorization header value.
+func (c Credentials) Header() string {
+	return fmt.Sprintf(`%s actor="%s", challenge="%s", signature="%s"`,
+		Scheme, c.Actor, c.Challenge, base64.StdEncoding.EncodeToString([]byte(c.Signature)))
+}
+
+// Sign answers challenge for the file at host and path as actor.
+func Sign(challenge, host, path, actor string, privateKey []byte) (Credentials, error) {
+	sig, err := signing.SignContent(Payload(challenge, host, path), privateKey)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to sign challenge: %w", err)
+	}
+	return Credentials{Actor: actor, Challenge: challenge, Signature: sig}, nil
+}
+
+// ChallengeHeader formats a WWW-Authenticate header value for challenge.
+func ChallengeHeader(challenge string) string {
+	return fmt.Sprintf(`%s challenge="%s"`, Scheme, challenge)
+}
+
+// ParseChallenge extracts the challenge from a WWW-Authenticate header value.
+func ParseChallenge(header string) (string, bool) {
+	params, ok := parseParams(header)
+	if !ok || params["challenge"] == "" {
+		return "", false
+	}
+	return params["challenge"], true
+}
+
+// ParseCredentials extracts credentials from an Authorization header value.
+func ParseCredentials(header string) (Credentials, bool) {
+	params, ok := parseParams(header)
+	if !ok || params["actor"] == "" || params["challenge"] == "" || params["signature"] == "" {
+		return Credentials{}, false
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return Credentials{}, false
+	}
+	return Credentials{Actor: params["actor"], Challenge: params["challenge"], Signature: string(sig)}, true
+}
+
+// parseParams parses `Polis-Signature k="v", k2="v2"`.
+func parseParams(header string) (map[string]string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(header), Scheme+" ")
+	if !ok {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return params, true
+}
+
+// ChallengeStore issues single-use challenges. The zero value is not
+// usable; call NewChallengeStore.
+type ChallengeStore struct {
+	mu     sync.Mutex
+	issued map[string]*list.Element // challenge -> element in order
+	order  *list.List               // issuedChallenge values, oldest first
+	now    func() time.Time
+}
+
+type issuedChallenge struct {
+	challenge string
+	expires   time.Time
+}
+
+// NewChallengeStore returns an empty store.
+func NewChallengeStore() *ChallengeStore {
+	return &ChallengeStore{issued: make(map[string]*list.Element), order: list.New(), now: time.Now}
+}
+
+// Issue returns a new challenge.
+func (s *ChallengeStore) Issue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	// Challenges expire in the order they were issued
+	for e := s.order.Front(); e != nil; e = s.order.Front() {
+		ic := e.Value.(issuedChallenge)
+		if len(s.issued) < maxChallenges && !now.After(ic.expires) {
+			break
+		}
+		s.remove(e)
+	}
+	s.issued[challenge] = s.order.PushBack(issuedChallenge{challenge, now.Add(ChallengeTTL)})
+	return challenge, nil
+}
+
+func (s *ChallengeStore) remove(e *list.Element) {
+	s.order.Remove(e)
+	delete(s.issued, e.Value.(issuedChallenge).challenge)
+}
+
+// Consume reports whether challenge was issued and hasn't expired, and
+// invalidates it.
+func (s *ChallengeStore) Consume(challenge string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.issued[challenge]
+	if !ok {
+		return false
+	}
+	s.remove(e)
+	return !s.now().After(e.Value.(issuedChallenge).expires)
+}
+
+// Verify checks the credentials of a signed request for a file on host, the
+// server's own host: the challenge must be one the store issued, and the
+// signature must verify against the key publicKey returns for the actor.
+// Returns the verified actor domain.
+func Verify(r *http.Request, host string, challenges *ChallengeStore, publicKey func(actor string) (string, error)) (string, error) {
+	creds, ok := ParseCredentials(r.Header.Get("Authorization"))
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	if host == "" {
+		return "", errors.New("server host is not configured")
+	}
+	if !challenges.Consume(creds.Challenge) {
+		return "", ErrUnknownChallenge
+	}
+	key, err := publicKey(creds.Actor)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch public key for %s: %w", creds.Actor, err)
+	}
+	valid, err := signing.VerifySignature(Payload(creds.Challenge, host, r.URL.EscapedPath()), []byte(key), creds.Signature)
+	if err != nil || !valid {
+		return "", ErrBadSignature
+	}
+	return creds.Actor, nil
+}
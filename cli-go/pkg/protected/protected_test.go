@@ -0,0 +1,119 @@
+package protected
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestVerify(t *testing.T) {
+	privKey, pubKey, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(actor string) (string, error) {
+		if actor != "bob.example.com" {
+			return "", errors.New("no such site")
+		}
+		return string(pubKey), nil
+	}
+	store := NewChallengeStore()
+
+	challenge, err := store.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := Sign(challenge, "alice.example.com", "/protected/posts/20260101/p.md", "bob.example.com", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, ok := ParseCredentials(creds.Header())
+	if !ok || parsed != creds {
+		t.Fatalf("credentials don't round-trip: %+v", parsed)
+	}
+
+	req := httptest.NewRequest("GET", "https://alice.example.com/protected/posts/20260101/p.md", nil)
+	req.Header.Set("Authorization", creds.Header())
+	if actor, err := Verify(req, "alice.example.com", store, keys); err != nil || actor != "bob.example.com" {
+		t.Fatalf("Verify = %q, %v", actor, err)
+	}
+	// Challenges are single-use
+	if _, err := Verify(req, "alice.example.com", store, keys); !errors.Is(err, ErrUnknownChallenge) {
+		t.Errorf("replay: expected ErrUnknownChallenge, got %v", err)
+	}
+
+	// A signature for another path, or for another host, doesn't verify.
+	// The relayed case claims the other host in the request, as a site
+	// passing a reader's answer on would: only the server's own host counts.
+	for _, tc := range []struct{ name, signedHost, target string }{
+		{"other path", "alice.example.com", "https://alice.example.com/protected/posts/20260101/other.md"},
+		{"relayed", "evil.example.com", "https://evil.example.com/protected/posts/20260101/p.md"},
+	} {
+		challenge, _ := store.Issue()
+		creds, _ := Sign(challenge, tc.signedHost, "/protected/posts/20260101/p.md", "bob.example.com", privKey)
+		req := httptest.NewRequest("GET", tc.target, nil)
+		req.Header.Set("Authorization", creds.Header())
+		if _, err := Verify(req, "alice.example.com", store, keys); !errors.Is(err, ErrBadSignature) {
+			t.Errorf("%s: expected ErrBadSignature, got %v", tc.name, err)
+		}
+	}
+
+	if _, err := Verify(httptest.NewRequest("GET", "/protected/x.md", nil), "alice.example.com", store, keys); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("unsigned: expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestChallengeStore_Expiry(t *testing.T) {
+	store := NewChallengeStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	challenge, _ := store.Issue()
+	now = now.Add(ChallengeTTL + time.Second)
+	if store.Consume(challenge) {
+		t.Error("expired challenge was accepted")
+	}
+	if store.Consume("never-issued") {
+		t.Error("unknown challenge was accepted")
+	}
+}
+
+func TestChallengeStore_Full(t *testing.T) {
+	privKey, pubKey, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(string) (string, error) { return string(pubKey), nil }
+	store := NewChallengeStore()
+
+	// A flood of unsigned requests fills the store
+	oldest, _ := store.Issue()
+	for i := 1; i < maxChallenges; i++ {
+		if _, err := store.Issue(); err != nil {
+			t.Fatalf("challenge %d: %v", i, err)
+		}
+	}
+
+	// A reader still gets a challenge, and their signed fetch succeeds
+	challenge, err := store.Issue()
+	if err != nil {
+		t.Fatalf("Issue on a full store: %v", err)
+	}
+	creds, _ := Sign(challenge, "alice.example.com", "/protected/posts/20260101/p.md", "bob.example.com", privKey)
+	req := httptest.NewRequest("GET", "https://alice.example.com/protected/posts/20260101/p.md", nil)
+	req.Header.Set("Authorization", creds.Header())
+	if actor, err := Verify(req, "alice.example.com", store, keys); err != nil || actor != "bob.example.com" {
+		t.Errorf("signed fetch on a full store: %q, %v", actor, err)
+	}
+
+	// The oldest challenge made room
+	if store.Consume(oldest) {
+		t.Error("oldest challenge survived a full store")
+	}
+	if n := len(store.issued); n > maxChallenges {
+		t.Errorf("store holds %d challenges, want at most %d", n, maxChallenges)
+	}
+}
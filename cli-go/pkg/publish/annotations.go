@@ -0,0 +1,269 @@
+package publish
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// ErrAnnotationNotFound is returned when a draft has no annotation with the
+// given ID.
+var ErrAnnotationNotFound = errors.New("annotation not found")
+
+// Annotation is a review note on a range of lines in a draft, for editors
+// and co-authors working on it before it is published. Annotations are
+// never published.
+type Annotation struct {
+	ID        string `json:"id"`
+	StartLine int    `json:"start_line"` // 1-based, inclusive
+	EndLine   int    `json:"end_line"`   // 1-based, inclusive
+	Author    string `json:"author"`     // Free-form label, e.g. "editor"
+	Note      string `json:"note"`
+	Resolved  bool   `json:"resolved,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AnnotationUpdate holds the fields to change with UpdateAnnotation; nil
+// fields are left as they are.
+type AnnotationUpdate struct {
+	StartLine *int    `json:"start_line"`
+	EndLine   *int    `json:"end_line"`
+	Author    *string `json:"author"`
+	Note      *string `json:"note"`
+	Resolved  *bool   `json:"resolved"`
+}
+
+// annotationsName is the storage name of a draft's annotations.
+func annotationsName(draftID string) string {
+	return ".polis/posts/annotations/" + draftID + ".json"
+}
+
+// Annotations returns a draft's annotations, ordered by start line.
+func Annotations(dataDir, draftID string) ([]Annotation, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return nil, fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	return loadAnnotations(storage.For(dataDir), draftID)
+}
+
+// AddAnnotation adds a note on lines a.StartLine to a.EndLine of a draft
+// (EndLine 0 means the start line only) and returns it with its ID set.
+func AddAnnotation(dataDir, draftID string, a Annotation) (*Annotation, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return nil, fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	fsys := storage.For(dataDir)
+	content, err := fsys.ReadFile(draftName(draftID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	if a.EndLine == 0 {
+		a.EndLine = a.StartLine
+	}
+	if err := validateAnnotation(a, string(content), true); err != nil {
+		return nil, err
+	}
+	a.ID = idgen.New()
+	a.Author = strings.TrimSpace(a.Author)
+	a.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	a.UpdatedAt = ""
+
+	name := annotationsName(draftID)
+	err = storage.With(fsys, name, func() error {
+		list, err := loadAnnotations(fsys, draftID)
+		if err != nil {
+			return err
+		}
+		return saveAnnotations(fsys, draftID, append(list, a))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpdateAnnotation changes an annotation and returns it.
+func UpdateAnnotation(dataDir, draftID, id string, u AnnotationUpdate) (*Annotation, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return nil, fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	fsys := storage.For(dataDir)
+	content, err := fsys.ReadFile(draftName(draftID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+
+	var updated *Annotation
+	err = storage.With(fsys, annotationsName(draftID), func() error {
+		list, err := loadAnnotations(fsys, draftID)
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			if list[i].ID != id {
+				continue
+			}
+			a := list[i]
+			if u.StartLine != nil {
+				a.StartLine = *u.StartLine
+			}
+			if u.EndLine != nil {
+				a.EndLine = *u.EndLine
+			}
+			if u.Author != nil {
+				a.Author = strings.TrimSpace(*u.Author)
+			}
+			if u.Note != nil {
+				a.Note = *u.Note
+			}
+			if u.Resolved != nil {
+				a.Resolved = *u.Resolved
+			}
+			// Lines are only checked when they change: the draft may have
+			// shrunk since, and the note should stay editable
+			if err := validateAnnotation(a, string(content), u.StartLine != nil || u.EndLine != nil); err != nil {
+				return err
+			}
+			a.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+			list[i] = a
+			updated = &a
+			return saveAnnotations(fsys, draftID, list)
+		}
+		return ErrAnnotationNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteAnnotation removes an annotation from a draft.
+func DeleteAnnotation(dataDir, draftID, id string) error {
+	if !draftIDPattern.MatchString(draftID) {
+		return fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	fsys := storage.For(dataDir)
+	return storage.With(fsys, annotationsName(draftID), func() error {
+		list, err := loadAnnotations(fsys, draftID)
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			if list[i].ID == id {
+				return saveAnnotations(fsys, draftID, append(list[:i], list[i+1:]...))
+			}
+		}
+		return ErrAnnotationNotFound
+	})
+}
+
+// validateAnnotation checks a note and, if checkLines is set, its lines
+// against the draft.
+func validateAnnotation(a Annotation, draft string, checkLines bool) error {
+	if strings.TrimSpace(a.Note) == "" {
+		return fmt.Errorf("note is required")
+	}
+	if !checkLines {
+		return nil
+	}
+	lines := strings.Count(strings.TrimSuffix(draft, "\n"), "\n") + 1
+	if a.StartLine < 1 || a.EndLine < a.StartLine || a.EndLine > lines {
+		return fmt.Errorf("lines %d-%d are outside the draft (1-%d)", a.StartLine, a.EndLine, lines)
+	}
+	return nil
+}
+
+func loadAnnotations(fsys storage.FS, draftID string) ([]Annotation, error) {
+	data, err := fsys.ReadFile(annotationsName(draftID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Annotation{}, nil
+		}
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+	var list []Annotation
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations: %w", err)
+	}
+	return list, nil
+}
+
+func saveAnnotations(fsys storage.FS, draftID string, list []Annotation) error {
+	// By start line, then creation
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].StartLine != list[j].StartLine {
+			return list[i].StartLine < list[j].StartLine
+		}
+		return list[i].CreatedAt < list[j].CreatedAt
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+	if err := fsys.WriteFile(annotationsName(draftID), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+	return nil
+}
+
+// ExportDraft returns a draft's markdown with its unresolved annotations
+// appended as an HTML comment, so they travel with the file to an editor
+// but never show up if the file is published as it is.
+func ExportDraft(dataDir, draftID string) (string, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return "", fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	fsys := storage.For(dataDir)
+	content, err := fsys.ReadFile(draftName(draftID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrDraftNotFound
+		}
+		return "", err
+	}
+	list, err := loadAnnotations(fsys, draftID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, a := range list {
+		if a.Resolved {
+			continue
+		}
+		lines := fmt.Sprintf("line %d", a.StartLine)
+		if a.EndLine > a.StartLine {
+			lines = fmt.Sprintf("lines %d-%d", a.StartLine, a.EndLine)
+		}
+		author := a.Author
+		if author == "" {
+			author = "reviewer"
+		}
+		note := strings.ReplaceAll(strings.TrimSpace(a.Note), "-->", "-- >")
+		note = strings.ReplaceAll(note, "\n", "\n  ")
+		fmt.Fprintf(&b, "- %s (%s): %s\n", lines, author, note)
+	}
+
+	out := string(content)
+	if b.Len() == 0 {
+		return out, nil
+	}
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out + "\n<!-- Review notes\n" + b.String() + "-->\n", nil
+}
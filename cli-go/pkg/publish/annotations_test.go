@@ -0,0 +1,67 @@
+package publish
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	dataDir := t.TempDir()
+	if _, err := SaveDraft(dataDir, "essay", "# Essay\n\nFirst paragraph.\n\nSecond paragraph.\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := AddAnnotation(dataDir, "missing", Annotation{StartLine: 1, Note: "x"}); !errors.Is(err, ErrDraftNotFound) {
+		t.Errorf("expected ErrDraftNotFound, got %v", err)
+	}
+	if _, err := AddAnnotation(dataDir, "essay", Annotation{StartLine: 4, EndLine: 9, Note: "x"}); err == nil {
+		t.Error("expected an error for lines past the end of the draft")
+	}
+	if _, err := AddAnnotation(dataDir, "essay", Annotation{StartLine: 1}); err == nil {
+		t.Error("expected an error for an empty note")
+	}
+
+	later, err := AddAnnotation(dataDir, "essay", Annotation{StartLine: 5, Author: "editor", Note: "Cut this?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later.EndLine != 5 {
+		t.Errorf("EndLine defaults to StartLine, got %d", later.EndLine)
+	}
+	first, err := AddAnnotation(dataDir, "essay", Annotation{StartLine: 1, EndLine: 3, Note: "Stronger opening"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := Annotations(dataDir, "essay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != first.ID || list[1].ID != later.ID {
+		t.Fatalf("expected annotations ordered by line, got %+v", list)
+	}
+
+	resolved := true
+	if _, err := UpdateAnnotation(dataDir, "essay", later.ID, AnnotationUpdate{Resolved: &resolved}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpdateAnnotation(dataDir, "essay", "nope", AnnotationUpdate{Resolved: &resolved}); !errors.Is(err, ErrAnnotationNotFound) {
+		t.Errorf("expected ErrAnnotationNotFound, got %v", err)
+	}
+
+	export, err := ExportDraft(dataDir, "essay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(export, "\n<!-- Review notes\n- lines 1-3 (reviewer): Stronger opening\n-->\n") {
+		t.Errorf("export should list only the open note:\n%s", export)
+	}
+
+	if err := DeleteAnnotation(dataDir, "essay", first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := Annotations(dataDir, "essay"); len(list) != 1 {
+		t.Errorf("expected 1 annotation after delete, got %d", len(list))
+	}
+}
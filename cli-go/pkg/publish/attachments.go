@@ -0,0 +1,39 @@
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// AttachmentsDir is the site directory images and other files referenced
+// by posts are stored in.
+const AttachmentsDir = "attachments"
+
+// AttachmentName returns the file name an attachment is stored under: the
+// slugified name and the start of a hash of its content, so a changed file
+// gets a new URL and identical files are stored once.
+func AttachmentName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := strings.ToLower(path.Ext(name))
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	return Slugify(base) + "-" + hex.EncodeToString(sum[:4]) + ext
+}
+
+// SaveAttachment stores data in the site's attachments/ directory and
+// returns its site-relative path (attachments/<name>) and whether the file
+// was written; an attachment already stored is left as it is.
+func SaveAttachment(dataDir, name string, data []byte) (string, bool, error) {
+	rel := AttachmentsDir + "/" + AttachmentName(name, data)
+	fsys := storage.For(dataDir)
+	if storage.Exists(fsys, rel) {
+		return rel, false, nil
+	}
+	if err := fsys.WriteFile(rel, data, 0644); err != nil {
+		return "", false, err
+	}
+	return rel, true, nil
+}
@@ -0,0 +1,155 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+	"github.com/vdibart/polis-cli/cli-go/pkg/trash"
+)
+
+// ErrDraftNotFound is returned when a post draft doesn't exist.
+var ErrDraftNotFound = errors.New("draft not found")
+
+var (
+	draftIDPattern   = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	draftIDSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+)
+
+// DraftPath returns the path of the post draft with the given ID.
+func DraftPath(dataDir, id string) string {
+	return filepath.Join(dataDir, filepath.FromSlash(draftName(id)))
+}
+
+// draftName is the storage name of the post draft with the given ID.
+func draftName(id string) string {
+	return ".polis/posts/drafts/" + id + ".md"
+}
+
+// SaveDraft writes a post draft and returns its ID. An empty id saves a new
+// draft under a generated ID; other IDs are sanitized to letters, digits,
+// dashes, and underscores.
+func SaveDraft(dataDir, id, markdown string) (string, error) {
+	if id == "" {
+		id = "draft-" + idgen.New()
+	}
+	id = draftIDSanitizer.ReplaceAllString(id, "-")
+	if err := storage.For(dataDir).WriteFile(draftName(id), []byte(markdown), 0644); err != nil {
+		return "", fmt.Errorf("failed to save draft: %w", err)
+	}
+	return id, nil
+}
+
+// PublishDraft publishes a post draft and then deletes it, unless keepDraft
+// is set. markdown overrides the saved draft content (e.g. unsaved edits
+// from the editor); pass "" to publish the draft as saved. The draft is
+// only removed once the post has been written, so a failed publish never
+// loses it.
+func PublishDraft(dataDir, draftID, markdown, filename string, keepDraft bool, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
+	return PublishDraftWithOptions(dataDir, draftID, markdown, keepDraft, PublishOptions{Slug: filename}, privateKey, dsCfg...)
+}
+
+// PublishDraftWithOptions is PublishDraft with overrides for the options
+// set in the draft's frontmatter: a non-empty override.Slug replaces the
+// slug, override.Unlisted publishes the post unlisted, and
+// override.FollowersOnly publishes it followers-only.
+func PublishDraftWithOptions(dataDir, draftID, markdown string, keepDraft bool, override PublishOptions, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return nil, fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	fsys := storage.For(dataDir)
+	if markdown == "" {
+		content, err := fsys.ReadFile(draftName(draftID))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrDraftNotFound
+			}
+			return nil, err
+		}
+		markdown = string(content)
+	}
+	opts := SourceOptions(markdown)
+	if override.Slug != "" {
+		opts.Slug = override.Slug
+	}
+	opts.Unlisted = opts.Unlisted || override.Unlisted
+	opts.FollowersOnly = opts.FollowersOnly || override.FollowersOnly
+	opts.NoCrosspost = opts.NoCrosspost || override.NoCrosspost
+	opts.DraftID = draftID
+	if HasFrontmatter(markdown) {
+		markdown = StripFrontmatter(markdown)
+	}
+	if strings.TrimSpace(markdown) == "" {
+		return nil, fmt.Errorf("draft %s is empty", draftID)
+	}
+
+	result, err := PublishPostWithOptions(dataDir, markdown, opts, privateKey, dsCfg...)
+	if err != nil {
+		return nil, err
+	}
+	if !keepDraft {
+		if err := fsys.Remove(draftName(draftID)); err != nil && !os.IsNotExist(err) {
+			return result, fmt.Errorf("published, but failed to remove draft: %w", err)
+		}
+		fsys.Remove(annotationsName(draftID)) // Review notes go with the draft
+	}
+	return result, nil
+}
+
+// UnpublishResult describes a post turned back into a draft.
+type UnpublishResult struct {
+	Path    string `json:"path"`     // Unpublished post path
+	DraftID string `json:"draft_id"` // New draft ID
+	TrashID string `json:"trash_id"` // Trash item holding the signed post
+}
+
+// UnpublishToDraft turns a published post back into a draft. The body
+// (without the signed frontmatter) becomes .polis/posts/drafts/<name>.md,
+// and the post itself is moved to the trash, which removes its HTML,
+// version history, and public.jsonl entry. The site still has to be
+// re-rendered for index pages and feeds to drop it.
+func UnpublishToDraft(dataDir, postPath string) (*UnpublishResult, error) {
+	postPath = path.Clean(filepath.ToSlash(postPath))
+	if trash.KindOf(postPath) != trash.KindPost {
+		return nil, fmt.Errorf("not a post: %s", postPath)
+	}
+	fsys := storage.For(dataDir)
+	content, err := fsys.ReadFile(postPath)
+	if err != nil {
+		return nil, err
+	}
+
+	draftID := uniqueDraftID(dataDir, strings.TrimSuffix(path.Base(postPath), ".md"))
+	if err := fsys.WriteFile(draftName(draftID), []byte(StripFrontmatter(string(content))), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write draft: %w", err)
+	}
+
+	item, err := trash.Move(dataDir, postPath)
+	if err != nil {
+		if item == nil {
+			fsys.Remove(draftName(draftID))
+		}
+		return nil, err
+	}
+	return &UnpublishResult{Path: postPath, DraftID: draftID, TrashID: item.ID}, nil
+}
+
+// uniqueDraftID returns base (sanitized), or base-2, base-3, ... if a draft
+// with that ID already exists.
+func uniqueDraftID(dataDir, base string) string {
+	base = draftIDSanitizer.ReplaceAllString(base, "-")
+	fsys := storage.For(dataDir)
+	id := base
+	for i := 2; ; i++ {
+		if _, err := fsys.Stat(draftName(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
@@ -0,0 +1,123 @@
+package publish
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func writeDraft(t *testing.T, dataDir, id, content string) {
+	t.Helper()
+	path := DraftPath(dataDir, id)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishDraft_RemovesDraft(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeDraft(t, dataDir, "idea", "---\npublish_at: 2026-01-01\n---\n# My Idea\n\nBody.\n")
+
+	result, err := PublishDraft(dataDir, "idea", "", "", false, privKey)
+	if err != nil {
+		t.Fatalf("PublishDraft failed: %v", err)
+	}
+	if result.Title != "My Idea" {
+		t.Errorf("title = %q", result.Title)
+	}
+	if _, err := os.Stat(DraftPath(dataDir, "idea")); !os.IsNotExist(err) {
+		t.Error("draft should be removed after publishing")
+	}
+}
+
+func TestPublishDraft_KeepDraftAndOverride(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeDraft(t, dataDir, "idea", "# Saved\n")
+
+	result, err := PublishDraft(dataDir, "idea", "# Edited\n", "", true, privKey)
+	if err != nil {
+		t.Fatalf("PublishDraft failed: %v", err)
+	}
+	if result.Title != "Edited" {
+		t.Errorf("expected the markdown override to be published, got title %q", result.Title)
+	}
+	if _, err := os.Stat(DraftPath(dataDir, "idea")); err != nil {
+		t.Error("draft should be kept")
+	}
+}
+
+func TestPublishDraft_Errors(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	if _, err := PublishDraft(dataDir, "missing", "", "", false, privKey); !errors.Is(err, ErrDraftNotFound) {
+		t.Errorf("err = %v, want ErrDraftNotFound", err)
+	}
+	if _, err := PublishDraft(dataDir, "../keys/id_ed25519", "", "", false, privKey); err == nil {
+		t.Error("expected an error for an invalid draft ID")
+	}
+}
+
+func TestUnpublishToDraft(t *testing.T) {
+	dataDir, postPath, _ := publishTestPost(t)
+	name := strings.TrimSuffix(filepath.Base(postPath), ".md")
+	writeDraft(t, dataDir, name, "existing draft")
+
+	result, err := UnpublishToDraft(dataDir, postPath)
+	if err != nil {
+		t.Fatalf("UnpublishToDraft failed: %v", err)
+	}
+	if result.DraftID != name+"-2" || result.TrashID == "" {
+		t.Errorf("result = %+v", result)
+	}
+
+	draft, err := os.ReadFile(DraftPath(dataDir, result.DraftID))
+	if err != nil {
+		t.Fatalf("draft not written: %v", err)
+	}
+	if HasFrontmatter(string(draft)) || !strings.Contains(string(draft), "Hello world.") {
+		t.Errorf("draft should hold the body without frontmatter, got %q", draft)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, postPath)); !os.IsNotExist(err) {
+		t.Error("post should be removed")
+	}
+	if entries, _ := metadata.LoadPublicIndex(dataDir); len(entries) != 0 {
+		t.Errorf("post should be removed from public index, got %v", entries)
+	}
+
+	if _, err := UnpublishToDraft(dataDir, ".polis/keys/id_ed25519"); err == nil {
+		t.Error("expected an error for a non-post path")
+	}
+}
+
+func TestSaveDraft(t *testing.T) {
+	dataDir := t.TempDir()
+
+	id, err := SaveDraft(dataDir, "", "# New\n")
+	if err != nil {
+		t.Fatalf("SaveDraft failed: %v", err)
+	}
+	if !strings.HasPrefix(id, "draft-") {
+		t.Errorf("generated id = %q", id)
+	}
+
+	id, err = SaveDraft(dataDir, "my idea/../x", "# Idea\n")
+	if err != nil {
+		t.Fatalf("SaveDraft failed: %v", err)
+	}
+	if id != "my-idea----x" {
+		t.Errorf("sanitized id = %q", id)
+	}
+	got, err := os.ReadFile(DraftPath(dataDir, id))
+	if err != nil || string(got) != "# Idea\n" {
+		t.Errorf("draft content = %q, %v", got, err)
+	}
+}
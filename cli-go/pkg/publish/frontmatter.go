@@ -0,0 +1,332 @@
+package publish
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// Post visibility values. Public is the default and is not written to frontmatter.
+const (
+	VisibilityPublic    = "public"
+	VisibilityUnlisted  = "unlisted"
+	VisibilityFollowers = "followers" // Published under protected/; see ProtectedDir
+)
+
+// PostFrontmatter is the structured view of a published post's frontmatter.
+type PostFrontmatter struct {
+	Path           string   `json:"path"`
+	Title          string   `json:"title"`
+	Published      string   `json:"published"`
+	Updated        string   `json:"updated,omitempty"`
+	Description    string   `json:"description"`
+	Summary        string   `json:"summary"`
+	Lang           string   `json:"lang"`
+	TranslationOf  string   `json:"translation_of"`
+	Tags           []string `json:"tags"`
+	Visibility     string   `json:"visibility"`
+	ImportedFrom   string   `json:"imported_from,omitempty"`
+	Generator      string   `json:"generator,omitempty"`
+	CurrentVersion string   `json:"current_version"`
+	VersionHistory []string `json:"version_history"`
+	Signed         bool     `json:"signed"`
+}
+
+// FrontmatterPatch is a field-level update. Nil fields are left unchanged.
+type FrontmatterPatch struct {
+	Title         *string   `json:"title,omitempty"`
+	Tags          *[]string `json:"tags,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	Summary       *string   `json:"summary,omitempty"`
+	Lang          *string   `json:"lang,omitempty"`
+	TranslationOf *string   `json:"translation_of,omitempty"`
+	Visibility    *string   `json:"visibility,omitempty"`
+}
+
+// Validate checks patch values before anything is written.
+func (p *FrontmatterPatch) Validate() error {
+	if p.Title != nil && strings.TrimSpace(*p.Title) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	if p.Title != nil && strings.ContainsAny(*p.Title, "\r\n") {
+		return fmt.Errorf("title must be a single line")
+	}
+	if p.Description != nil && strings.ContainsAny(*p.Description, "\r\n") {
+		return fmt.Errorf("description must be a single line")
+	}
+	if p.Summary != nil && strings.ContainsAny(*p.Summary, "\r\n") {
+		return fmt.Errorf("summary must be a single line")
+	}
+	if p.Lang != nil && strings.TrimSpace(*p.Lang) != "" {
+		if _, err := NormalizeLang(*p.Lang); err != nil {
+			return err
+		}
+	}
+	if p.TranslationOf != nil {
+		if _, err := cleanTranslationOf(*p.TranslationOf); err != nil {
+			return err
+		}
+	}
+	if p.Tags != nil {
+		for _, tag := range *p.Tags {
+			if strings.ContainsAny(tag, ",[]\"\r\n") {
+				return fmt.Errorf("invalid tag %q: tags cannot contain commas, brackets, quotes, or newlines", tag)
+			}
+		}
+	}
+	if p.Visibility != nil {
+		switch *p.Visibility {
+		case VisibilityPublic, VisibilityUnlisted, VisibilityFollowers:
+		default:
+			return fmt.Errorf("invalid visibility %q (expected public, unlisted, or followers)", *p.Visibility)
+		}
+	}
+	return nil
+}
+
+// ParseFrontmatterFields returns the structured frontmatter of a post's content.
+func ParseFrontmatterFields(content string) *PostFrontmatter {
+	fm := ParseFrontmatter(content)
+	visibility := fm["visibility"]
+	if isFollowersOnly(fm) {
+		visibility = VisibilityFollowers
+	} else if isUnlisted(fm) {
+		visibility = VisibilityUnlisted
+	} else if visibility == "" {
+		visibility = VisibilityPublic
+	}
+	return &PostFrontmatter{
+		Title:          unquoteYAMLString(fm["title"]),
+		Published:      fm["published"],
+		Updated:        fm["updated"],
+		Description:    unquoteYAMLString(fm["description"]),
+		Summary:        unquoteYAMLString(fm["summary"]),
+		Lang:           unquoteYAMLString(fm["lang"]),
+		TranslationOf:  unquoteYAMLString(fm["translation_of"]),
+		Tags:           ParseTags(fm["tags"]),
+		Visibility:     visibility,
+		ImportedFrom:   unquoteYAMLString(fm["imported_from"]),
+		Generator:      fm["generator"],
+		CurrentVersion: fm["current-version"],
+		VersionHistory: ExtractVersionHistory(content),
+		Signed:         fm["signature"] != "",
+	}
+}
+
+// ReadFrontmatter reads and parses the frontmatter of a published post.
+func ReadFrontmatter(dataDir, postPath string) (*PostFrontmatter, error) {
+	content, err := storage.For(dataDir).ReadFile(storage.Name(postPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post: %w", err)
+	}
+	fm := ParseFrontmatterFields(string(content))
+	fm.Path = postPath
+	return fm, nil
+}
+
+// ParseTags parses a YAML flow sequence ("[a, b]") or comma-separated list.
+func ParseTags(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	tags := []string{}
+	for _, t := range strings.Split(value, ",") {
+		t = unquoteYAMLString(strings.TrimSpace(t))
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// unquoteYAMLString reverses escapeYAMLString.
+func unquoteYAMLString(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, "\"") && strings.HasSuffix(s, "\"") {
+		return strings.ReplaceAll(s[1:len(s)-1], "\\\"", "\"")
+	}
+	return s
+}
+
+// optionalFrontmatterLines renders the optional fields of fm, in order,
+// each prefixed with a newline. Empty and default values are omitted.
+func optionalFrontmatterLines(fm *PostFrontmatter) string {
+	var b strings.Builder
+	if fm.Description != "" {
+		b.WriteString("\ndescription: " + escapeYAMLString(fm.Description))
+	}
+	if fm.Summary != "" {
+		b.WriteString("\nsummary: " + escapeYAMLString(fm.Summary))
+	}
+	if fm.Lang != "" {
+		b.WriteString("\nlang: " + fm.Lang)
+	}
+	if fm.TranslationOf != "" {
+		b.WriteString("\ntranslation_of: " + escapeYAMLString(fm.TranslationOf))
+	}
+	if len(fm.Tags) > 0 {
+		quoted := make([]string, len(fm.Tags))
+		for i, t := range fm.Tags {
+			quoted[i] = escapeYAMLString(t)
+		}
+		b.WriteString("\ntags: [" + strings.Join(quoted, ", ") + "]")
+	}
+	if fm.Visibility != "" && fm.Visibility != VisibilityPublic {
+		b.WriteString("\nvisibility: " + fm.Visibility)
+	}
+	if fm.ImportedFrom != "" {
+		b.WriteString("\nimported_from: " + escapeYAMLString(fm.ImportedFrom))
+	}
+	return b.String()
+}
+
+// UpdateFrontmatter applies a field-level patch to a published post's
+// frontmatter, re-signs it, and updates the index. The body is untouched, so
+// current-version and version-history are preserved.
+func UpdateFrontmatter(dataDir, postPath string, patch FrontmatterPatch, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
+	postPath = filepath.ToSlash(postPath)
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	fsys := storage.For(dataDir)
+	existingContent, err := fsys.ReadFile(storage.Name(postPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing post: %w", err)
+	}
+	if !HasFrontmatter(string(existingContent)) {
+		return nil, fmt.Errorf("post has no frontmatter: %s", postPath)
+	}
+
+	fm := ParseFrontmatterFields(string(existingContent))
+	titleChanged := false
+	if patch.Title != nil {
+		title := strings.TrimSpace(*patch.Title)
+		titleChanged = title != fm.Title
+		fm.Title = title
+	}
+	if patch.Description != nil {
+		fm.Description = strings.TrimSpace(*patch.Description)
+	}
+	if patch.Summary != nil {
+		fm.Summary = strings.TrimSpace(*patch.Summary)
+	}
+	if patch.Lang != nil {
+		fm.Lang = ""
+		if strings.TrimSpace(*patch.Lang) != "" {
+			fm.Lang, _ = NormalizeLang(*patch.Lang)
+		}
+	}
+	if patch.TranslationOf != nil {
+		root, err := translationRoot(dataDir, postPath, *patch.TranslationOf)
+		if err != nil {
+			return nil, err
+		}
+		fm.TranslationOf = root
+	}
+	if patch.Tags != nil {
+		tags := []string{}
+		seen := make(map[string]bool)
+		for _, t := range *patch.Tags {
+			t = strings.TrimSpace(t)
+			if t != "" && !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+		fm.Tags = tags
+	}
+	if patch.Visibility != nil {
+		// Followers-only posts live under protected/, so the visibility
+		// can't be switched in place
+		if (fm.Visibility == VisibilityFollowers) != (*patch.Visibility == VisibilityFollowers) {
+			return nil, fmt.Errorf("visibility can't be changed to or from %q; publish a new post instead", VisibilityFollowers)
+		}
+		fm.Visibility = *patch.Visibility
+	}
+
+	body := StripFrontmatter(string(existingContent))
+	published := fm.Published
+	if published == "" {
+		published = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	}
+	updated := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	var versionHistoryYAML string
+	for _, v := range fm.VersionHistory {
+		versionHistoryYAML += fmt.Sprintf("\n  - %s", v)
+	}
+
+	delegationLine, err := delegation.FrontmatterLine(privateKey, delegation.ScopePublish)
+	if err != nil {
+		return nil, err
+	}
+	delegationLine += coauthor.FrontmatterLine(privateKey)
+
+	unsignedFrontmatter := fmt.Sprintf(`---
+title: %s
+published: %s
+updated: %s%s
+generator: %s
+current-version: %s
+version-history:%s%s
+---`,
+		escapeYAMLString(fm.Title),
+		published,
+		updated,
+		optionalFrontmatterLines(fm),
+		GetGenerator(),
+		fm.CurrentVersion,
+		versionHistoryYAML,
+		delegationLine,
+	)
+
+	canonicalizedForSigning := CanonicalizeContent(unsignedFrontmatter + "\n\n" + body)
+	signature, err := signing.SignContent([]byte(canonicalizedForSigning), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign content: %w", err)
+	}
+
+	signedFrontmatter := strings.TrimSuffix(unsignedFrontmatter, "\n---") +
+		"\nsignature: " + extractSignatureBase64(signature) + "\n---"
+	if err := fsys.WriteFile(storage.Name(postPath), []byte(signedFrontmatter+"\n\n"+body), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write post file: %w", err)
+	}
+
+	fm.Published = published
+	unlisted := keptOffIndex(fm.Visibility)
+	if err := updateIndex(dataDir, indexMeta(postPath, fm, fm.CurrentVersion, body), unlisted); err != nil {
+		logging.Warn("Failed to update index", "path", postPath, "err", err)
+	}
+	if err := UpdateManifest(dataDir); err != nil {
+		logging.Warn("Failed to update manifest", "err", err)
+	}
+
+	result := &PublishResult{
+		Success:       true,
+		Path:          postPath,
+		Title:         fm.Title,
+		Version:       fm.CurrentVersion,
+		Signature:     signature,
+		Unlisted:      unlisted,
+		FollowersOnly: fm.Visibility == VisibilityFollowers,
+	}
+
+	// Discovery only records the title, so re-register only when it changed
+	if titleChanged && !unlisted {
+		var cfg *DiscoveryConfig
+		if len(dsCfg) > 0 {
+			cfg = dsCfg[0]
+		}
+		if err := RegisterPost(dataDir, result, privateKey, cfg); err != nil {
+			logging.Warn("Discovery registration skipped", "path", result.Path, "err", err)
+		}
+	}
+
+	return result, nil
+}
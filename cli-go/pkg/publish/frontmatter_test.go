@@ -0,0 +1,121 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func publishTestPost(t *testing.T) (dataDir, postPath string, privKey []byte) {
+	t.Helper()
+	dataDir = t.TempDir()
+	privKey, _, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatalf("failed to generate keypair: %v", err)
+	}
+	result, err := PublishPost(dataDir, "# Original Title\n\nHello world.\n", "", privKey)
+	if err != nil {
+		t.Fatalf("PublishPost failed: %v", err)
+	}
+	return dataDir, result.Path, privKey
+}
+
+func TestUpdateFrontmatter_AppliesPatchAndPreservesBody(t *testing.T) {
+	dataDir, postPath, privKey := publishTestPost(t)
+	before, _ := ReadFrontmatter(dataDir, postPath)
+
+	title := "New: Title"
+	desc := "A short description"
+	tags := []string{"go", " polis ", "go"}
+	vis := VisibilityUnlisted
+	result, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{
+		Title: &title, Description: &desc, Tags: &tags, Visibility: &vis,
+	}, privKey)
+	if err != nil {
+		t.Fatalf("UpdateFrontmatter failed: %v", err)
+	}
+	if result.Version != before.CurrentVersion {
+		t.Errorf("version changed: %s -> %s", before.CurrentVersion, result.Version)
+	}
+
+	fm, err := ReadFrontmatter(dataDir, postPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != title || fm.Description != desc || fm.Visibility != VisibilityUnlisted {
+		t.Errorf("unexpected frontmatter: %+v", fm)
+	}
+	if strings.Join(fm.Tags, ",") != "go,polis" {
+		t.Errorf("expected deduped, trimmed tags, got %v", fm.Tags)
+	}
+	if fm.Updated == "" || !fm.Signed || len(fm.VersionHistory) != len(before.VersionHistory) {
+		t.Errorf("expected updated timestamp, signature, and unchanged history: %+v", fm)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dataDir, postPath))
+	if !strings.Contains(string(content), "Hello world.") {
+		t.Error("body was lost")
+	}
+	// An unlisted post's entry moves to .polis/unlisted.jsonl
+	index, _ := os.ReadFile(filepath.Join(dataDir, ".polis", "unlisted.jsonl"))
+	if !strings.Contains(string(index), `"title":"New: Title"`) {
+		t.Errorf("index not updated: %s", index)
+	}
+}
+
+func TestUpdateFrontmatter_ClearsFieldsAndRejectsInvalid(t *testing.T) {
+	dataDir, postPath, privKey := publishTestPost(t)
+
+	tags := []string{"a"}
+	if _, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{Tags: &tags}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	empty := []string{}
+	public := VisibilityPublic
+	if _, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{Tags: &empty, Visibility: &public}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dataDir, postPath))
+	if strings.Contains(string(content), "tags:") || strings.Contains(string(content), "visibility:") {
+		t.Errorf("expected cleared fields to be omitted:\n%s", content)
+	}
+
+	bad := "secret"
+	if _, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{Visibility: &bad}, privKey); err == nil {
+		t.Error("expected error for invalid visibility")
+	}
+	blank := "  "
+	if _, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{Title: &blank}, privKey); err == nil {
+		t.Error("expected error for empty title")
+	}
+}
+
+func TestRepublishPost_PreservesEditedFrontmatter(t *testing.T) {
+	dataDir, postPath, privKey := publishTestPost(t)
+
+	tags := []string{"kept"}
+	desc := "still here"
+	if _, err := UpdateFrontmatter(dataDir, postPath, FrontmatterPatch{Tags: &tags, Description: &desc}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RepublishPost(dataDir, postPath, "# Original Title\n\nEdited body.\n", privKey); err != nil {
+		t.Fatalf("RepublishPost failed: %v", err)
+	}
+
+	fm, _ := ReadFrontmatter(dataDir, postPath)
+	if fm.Description != desc || len(fm.Tags) != 1 || fm.Tags[0] != "kept" {
+		t.Errorf("republish dropped edited fields: %+v", fm)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	if got := ParseTags(`[go, "a: b", web]`); strings.Join(got, "|") != "go|a: b|web" {
+		t.Errorf("unexpected tags: %v", got)
+	}
+	if got := ParseTags(""); len(got) != 0 {
+		t.Errorf("expected no tags, got %v", got)
+	}
+}
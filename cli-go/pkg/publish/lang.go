@@ -0,0 +1,113 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// DefaultLang is the language of posts without a lang field on a site that
+// doesn't set one in .well-known/polis.
+const DefaultLang = "en"
+
+// ErrInvalidTranslation is returned when translation_of doesn't name
+// another published post.
+var ErrInvalidTranslation = errors.New("translation_of must name another published post")
+
+var langPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// NormalizeLang checks that tag is a well-formed language tag (en, pt-BR,
+// zh-Hant-TW) and returns it in the conventional case: language lowercase,
+// script title case, region uppercase.
+func NormalizeLang(tag string) (string, error) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if !langPattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid language tag %q (expected e.g. en, fr, pt-BR)", tag)
+	}
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// MatchLang reports whether tag falls under the language range filter:
+// "pt" matches pt and pt-BR, "pt-BR" only pt-BR. Case is ignored.
+func MatchLang(tag, filter string) bool {
+	tag, filter = strings.ToLower(tag), strings.ToLower(filter)
+	return tag == filter || strings.HasPrefix(tag, filter+"-")
+}
+
+// FrontmatterLang returns the lang field of a source file's frontmatter,
+// or "".
+func FrontmatterLang(content string) string {
+	if !HasFrontmatter(content) {
+		return ""
+	}
+	return unquoteYAMLString(ParseFrontmatter(content)["lang"])
+}
+
+// FrontmatterTranslationOf returns the translation_of field of a source
+// file's frontmatter, or "".
+func FrontmatterTranslationOf(content string) string {
+	if !HasFrontmatter(content) {
+		return ""
+	}
+	return unquoteYAMLString(ParseFrontmatter(content)["translation_of"])
+}
+
+// cleanTranslationOf normalizes a translation_of value to a post path
+// (posts/.../slug.md). The .html page path is accepted too.
+func cleanTranslationOf(target string) (string, error) {
+	p := strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(target)), "/")
+	if p == "" {
+		return "", nil
+	}
+	p = path.Clean(p)
+	if strings.HasSuffix(p, ".html") {
+		p = strings.TrimSuffix(p, ".html") + ".md"
+	}
+	if !strings.HasPrefix(p, "posts/") || !strings.HasSuffix(p, ".md") || strings.Contains(p, "..") {
+		return "", fmt.Errorf("invalid translation_of %q (expected a post path like posts/20260125/hello.md)", target)
+	}
+	return p, nil
+}
+
+// translationRoot resolves the translation_of value of the post at
+// postPath ("" for a post not yet written) to the original it translates.
+// A translation of a translation points at the same original, so each
+// group of translations has a single root.
+func translationRoot(dataDir, postPath, target string) (string, error) {
+	root, err := cleanTranslationOf(target)
+	if err != nil || root == "" {
+		return root, err
+	}
+	content, err := storage.For(dataDir).ReadFile(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s not found", ErrInvalidTranslation, root)
+		}
+		return "", err
+	}
+	if parent, err := cleanTranslationOf(FrontmatterTranslationOf(string(content))); err == nil && parent != "" {
+		root = parent
+	}
+	if root == postPath {
+		return "", fmt.Errorf("%w: %s is this post", ErrInvalidTranslation, root)
+	}
+	return root, nil
+}
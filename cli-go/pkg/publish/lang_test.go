@@ -0,0 +1,95 @@
+package publish
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestNormalizeLang(t *testing.T) {
+	tests := map[string]string{
+		"en":         "en",
+		"FR":         "fr",
+		"pt-br":      "pt-BR",
+		"pt_BR":      "pt-BR",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+	}
+	for in, want := range tests {
+		got, err := NormalizeLang(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeLang(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "e", "english1", "en--US", "en/US"} {
+		if _, err := NormalizeLang(bad); err == nil {
+			t.Errorf("NormalizeLang(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMatchLang(t *testing.T) {
+	if !MatchLang("pt-BR", "pt") || !MatchLang("pt-BR", "PT-br") || !MatchLang("fr", "fr") {
+		t.Error("expected matches")
+	}
+	if MatchLang("pt", "pt-BR") || MatchLang("ptx", "pt") {
+		t.Error("unexpected match")
+	}
+}
+
+func TestPublishPost_Translation(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	original, err := PublishPost(dataDir, "# Hello\n\nHi.\n", "hello", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr, err := PublishPostWithOptions(dataDir, "# Bonjour\n\nSalut.\n", PublishOptions{Slug: "bonjour", Lang: "FR", TranslationOf: "/" + strings.TrimSuffix(original.Path, ".md") + ".html"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, _ := ReadFrontmatter(dataDir, fr.Path)
+	if fm.Lang != "fr" || fm.TranslationOf != original.Path {
+		t.Errorf("frontmatter lang=%q translation_of=%q", fm.Lang, fm.TranslationOf)
+	}
+
+	// A translation of a translation points at the original
+	de, err := PublishPostWithOptions(dataDir, "# Hallo\n", PublishOptions{Slug: "hallo", Lang: "de", TranslationOf: fr.Path}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := metadata.LoadPublicIndex(dataDir)
+	for _, e := range entries {
+		if e.Path == de.Path && (e.Lang != "de" || e.TranslationOf != original.Path) {
+			t.Errorf("index entry lang=%q translation_of=%q", e.Lang, e.TranslationOf)
+		}
+	}
+
+	// lang and translation_of survive a republish
+	if _, err := RepublishPost(dataDir, fr.Path, "# Bonjour\n\nSalut encore.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dataDir, fr.Path))
+	if !strings.Contains(string(content), "\nlang: fr\ntranslation_of: "+original.Path+"\n") {
+		t.Errorf("expected lang and translation_of after republish, got:\n%s", content)
+	}
+
+	if _, err := PublishPostWithOptions(dataDir, "# Hola\n", PublishOptions{Lang: "es", TranslationOf: "posts/20200101/missing.md"}, privKey); !errors.Is(err, ErrInvalidTranslation) {
+		t.Errorf("expected ErrInvalidTranslation for a missing original, got %v", err)
+	}
+	if _, err := PublishPostWithOptions(dataDir, "# Hola\n", PublishOptions{Lang: "spanish!"}, privKey); err == nil {
+		t.Error("expected an error for an invalid lang")
+	}
+
+	// The original can't be made a translation of its own translation
+	patch := FrontmatterPatch{TranslationOf: &fr.Path}
+	if _, err := UpdateFrontmatter(dataDir, original.Path, patch, privKey); !errors.Is(err, ErrInvalidTranslation) {
+		t.Errorf("expected ErrInvalidTranslation for a cycle, got %v", err)
+	}
+}
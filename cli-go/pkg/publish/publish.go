@@ -9,19 +9,31 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+	"github.com/vdibart/polis-cli/cli-go/pkg/wikilink"
 )
 
 // Version is set at startup by the cmd package.
 var Version = "dev"
 
+// ContentCheck, if set, vets a post's markdown before it is published or
+// republished; an error stops the publish. The cmd package and the webapp
+// set it from the audit_block_publish setting.
+var ContentCheck func(markdown string) error
+
 // GetGenerator returns the generator identifier for frontmatter.
 func GetGenerator() string {
 	return "polis-cli-go/" + Version
@@ -35,6 +47,34 @@ type PublishResult struct {
 	Version   string `json:"version"`
 	Signature string `json:"signature"`
 	URL       string `json:"url,omitempty"`
+
+	// Unlisted is set when the post is kept out of public.jsonl, index
+	// pages, feeds, and discovery.
+	Unlisted bool `json:"unlisted,omitempty"`
+
+	// FollowersOnly is set when the post is published under protected/.
+	FollowersOnly bool `json:"followers_only,omitempty"`
+
+	// NoCrosspost is set when the source frontmatter opted the post out
+	// of cross-posting to other networks ("crosspost: false").
+	NoCrosspost bool `json:"no_crosspost,omitempty"`
+
+	// UnresolvedLinks lists [[wiki link]] targets that name no published
+	// post. The post is published anyway; the links render as plain text
+	// until a matching post exists.
+	UnresolvedLinks []string `json:"unresolved_links,omitempty"`
+}
+
+// Crosspostable reports whether the post may be copied to other networks:
+// a public post not opted out in its frontmatter.
+func (r *PublishResult) Crosspostable() bool {
+	return !r.Unlisted && !r.FollowersOnly && !r.NoCrosspost
+}
+
+// PageURL returns the public URL of the page rendered from a post or
+// comment.
+func PageURL(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimSuffix(filepath.ToSlash(path), ".md") + ".html"
 }
 
 // PostMeta contains metadata for a published post (for index)
@@ -44,6 +84,9 @@ type PostMeta struct {
 	Title          string `json:"title"`
 	Published      string `json:"published"`
 	CurrentVersion string `json:"current_version"`
+	Summary        string `json:"summary,omitempty"`
+	Lang           string `json:"lang,omitempty"`
+	TranslationOf  string `json:"translation_of,omitempty"`
 }
 
 // ManifestData contains the manifest.json structure
@@ -79,10 +122,11 @@ func ExtractTitle(markdown string) string {
 	return "Untitled"
 }
 
-// Slugify converts a title to a URL-safe filename.
+// Slugify converts a title to a URL-safe filename. Accented Latin, Greek,
+// and Cyrillic letters are transliterated to ASCII.
 func Slugify(title string) string {
 	// Convert to lowercase
-	slug := strings.ToLower(title)
+	slug := transliterate(strings.ToLower(title))
 
 	// Replace spaces and special chars with hyphens
 	var result []rune
@@ -102,9 +146,9 @@ func Slugify(title string) string {
 	// Trim leading/trailing hyphens
 	slug = strings.Trim(slug, "-")
 
-	// Limit length
-	if len(slug) > 50 {
-		slug = slug[:50]
+	// Limit length (in runes, so a multi-byte letter is never split)
+	if r := []rune(slug); len(r) > 50 {
+		slug = string(r[:50])
 		// Don't end with a hyphen
 		slug = strings.TrimRight(slug, "-")
 	}
@@ -224,31 +268,131 @@ func escapeYAMLString(s string) string {
 	return s
 }
 
+// PublishOptions controls where PublishPostWithOptions writes a post.
+type PublishOptions struct {
+	Slug       string // Filename without .md; derived from the title if empty
+	PathFormat string // Directory layout under posts/; PostPathFormat if empty
+	DraftID    string // Draft being published, which doesn't count as a collision
+	Summary    string // Written to frontmatter; generated from the body for the index if empty
+
+	Lang          string // Language tag written to frontmatter; the site's language if empty
+	TranslationOf string // Path of the post this one translates
+
+	Unlisted bool // Keep the post out of public.jsonl, index pages, feeds, and discovery
+
+	// FollowersOnly publishes the post under protected/ (never rendered or
+	// deployed), readable through polis serve with a follower's signed request.
+	// Implies Unlisted.
+	FollowersOnly bool
+
+	// NoCrosspost keeps the post off other networks (see package bluesky).
+	// It is reported in the result but not written to frontmatter.
+	NoCrosspost bool
+
+	// Published backdates the post: it sets the published timestamp and
+	// the date directory. The current time if zero.
+	Published time.Time
+
+	// ImportedFrom is written to frontmatter as imported_from, naming the
+	// post an imported one was converted from (see package importer).
+	ImportedFrom string
+
+	// NoAnnounce keeps a public post from being registered with the
+	// discovery service.
+	NoAnnounce bool
+}
+
+// SourceOptions returns the options set in a source file's frontmatter:
+// slug, summary, lang, translation_of, unlisted, followers-only, and the
+// cross-posting opt-out.
+func SourceOptions(content string) PublishOptions {
+	return PublishOptions{
+		Slug:          FrontmatterSlug(content),
+		Summary:       FrontmatterSummary(content),
+		Lang:          FrontmatterLang(content),
+		TranslationOf: FrontmatterTranslationOf(content),
+		Unlisted:      FrontmatterUnlisted(content),
+		FollowersOnly: FrontmatterFollowersOnly(content),
+		NoCrosspost:   FrontmatterNoCrosspost(content),
+	}
+}
+
+// FrontmatterNoCrosspost reports whether a source file's frontmatter opts
+// the post out of cross-posting with "crosspost: false".
+func FrontmatterNoCrosspost(content string) bool {
+	if !HasFrontmatter(content) {
+		return false
+	}
+	return strings.EqualFold(unquoteYAMLString(ParseFrontmatter(content)["crosspost"]), "false")
+}
+
+// Validate checks option values that don't depend on the site.
+func (o *PublishOptions) Validate() error {
+	if strings.TrimSpace(o.Lang) != "" {
+		if _, err := NormalizeLang(o.Lang); err != nil {
+			return err
+		}
+	}
+	_, err := cleanTranslationOf(o.TranslationOf)
+	return err
+}
+
 // PublishPost publishes a markdown post and returns the result.
 // If dsCfg is non-nil, it overrides package-level discovery globals for
 // multi-tenant safety. Pass nil to use globals (single-tenant / CLI mode).
 func PublishPost(dataDir, markdown, filename string, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
+	return PublishPostWithOptions(dataDir, markdown, PublishOptions{Slug: filename}, privateKey, dsCfg...)
+}
+
+// PublishPostWithOptions is PublishPost with control over the post's path.
+// A slug that is already taken in the target directory (or by another
+// draft) gets the first free numeric suffix: hello, hello-2, hello-3, ...
+func PublishPostWithOptions(dataDir, markdown string, opts PublishOptions, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
 	// Extract title
 	title := ExtractTitle(markdown)
-
-	// Generate filename if not provided
-	if filename == "" {
-		filename = Slugify(title)
-		// If the slug is generic (no meaningful title), add a random suffix
-		if filename == "untitled" {
-			filename = "untitled-" + randomSuffix(8)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if ContentCheck != nil {
+		if err := ContentCheck(markdown); err != nil {
+			return nil, err
 		}
-	} else {
-		// Sanitize provided filename
-		filename = Slugify(filename)
+	}
+	opts.Summary = strings.Join(strings.Fields(opts.Summary), " ")
+	if opts.Lang != "" {
+		opts.Lang, _ = NormalizeLang(opts.Lang)
+	}
+	translationOf, err := translationRoot(dataDir, "", opts.TranslationOf)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FollowersOnly {
+		opts.Unlisted = true
+	}
+
+	format := opts.PathFormat
+	if format == "" {
+		format = PostPathFormat
+	}
+	published := time.Now()
+	if !opts.Published.IsZero() {
+		published = opts.Published
+	}
+	dateDir, err := PostDir(format, published)
+	if err != nil {
+		return nil, err
 	}
 
-	// Ensure .md extension is not duplicated
-	filename = strings.TrimSuffix(filename, ".md")
+	// Generate filename if not provided, otherwise sanitize the provided one
+	// (without a .md extension, which would otherwise become "-md")
+	filename := Slugify(title)
+	if opts.Slug != "" {
+		filename = Slugify(strings.TrimSuffix(opts.Slug, ".md"))
+	}
 
-	// Ensure unique filename (prevent collisions)
-	dateDir := time.Now().UTC().Format("20060102")
-	filename = ensureUniqueFilename(dataDir, dateDir, filename)
+	// Ensure unique filename (prevent collisions). An untitled post is
+	// "untitled", then "untitled-2", and so on.
+	filename = uniqueFilename(dataDir, dateDir, filename, opts.DraftID)
 
 	// Canonicalize the raw markdown for consistent hashing
 	canonicalBody := CanonicalizeContent(markdown)
@@ -257,23 +401,45 @@ func PublishPost(dataDir, markdown, filename string, privateKey []byte, dsCfg ..
 	hash := HashContent([]byte(canonicalBody))
 
 	// Get timestamp
-	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	timestamp := published.UTC().Format("2006-01-02T15:04:05Z")
 
 	// Build content to sign (frontmatter without signature + content)
+	visibility := VisibilityPublic
+	if opts.FollowersOnly {
+		visibility = VisibilityFollowers
+	} else if opts.Unlisted {
+		visibility = VisibilityUnlisted
+	}
+	optionalFields := optionalFrontmatterLines(&PostFrontmatter{
+		Summary:       opts.Summary,
+		Lang:          opts.Lang,
+		TranslationOf: translationOf,
+		Visibility:    visibility,
+		ImportedFrom:  opts.ImportedFrom,
+	})
+	// A device key embeds its delegation certificate in the signed fields,
+	// and a co-author's key the author's name
+	delegationLine, err := delegation.FrontmatterLine(privateKey, delegation.ScopePublish)
+	if err != nil {
+		return nil, err
+	}
+	delegationLine += coauthor.FrontmatterLine(privateKey)
 	unsignedFrontmatter := fmt.Sprintf(`---
 title: %s
-published: %s
+published: %s%s
 generator: %s
 current-version: sha256:%s
 version-history:
-  - sha256:%s (%s)
+  - sha256:%s (%s)%s
 ---`,
 		escapeYAMLString(title),
 		timestamp,
+		optionalFields,
 		GetGenerator(),
 		hash,
 		hash,
 		timestamp,
+		delegationLine,
 	)
 
 	// Build full unsigned content, then canonicalize the whole thing for signing
@@ -290,32 +456,29 @@ version-history:
 	// Extract just the base64 part of the signature for frontmatter
 	sigBase64 := extractSignatureBase64(signature)
 
-	// Build final frontmatter with signature
-	finalFrontmatter := BuildFrontmatter(title, hash, timestamp, sigBase64)
+	// Build final frontmatter: the signed fields plus the signature
+	finalFrontmatter := strings.TrimSuffix(unsignedFrontmatter, "\n---") + "\nsignature: " + sigBase64 + "\n---"
 
 	// Build final content
 	finalContent := finalFrontmatter + "\n\n" + canonicalBody
 
-	// Create directory structure: posts/YYYYMMDD/
-	postsDir := filepath.Join(dataDir, "posts", dateDir)
-	if err := os.MkdirAll(postsDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create posts directory: %w", err)
+	// Write post file to posts/<dateDir>/ (protected/posts/<dateDir>/ for
+	// followers-only posts), creating the directories
+	relativePath := PostPath(dateDir, filename)
+	if opts.FollowersOnly {
+		relativePath = ProtectedDir + "/" + relativePath
 	}
-
-	// Write post file
-	postPath := filepath.Join(postsDir, filename+".md")
-	if err := os.WriteFile(postPath, []byte(finalContent), 0644); err != nil {
+	if err := storage.For(dataDir).WriteFile(relativePath, []byte(finalContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write post file: %w", err)
 	}
 
 	// Update index
-	relativePath := filepath.Join("posts", dateDir, filename+".md")
 
 	// Initialize version history with CLI-compatible format
 	// Pass content WITHOUT frontmatter (canonicalBody)
 	if err := initializeVersionHistory(dataDir, dateDir, filename, relativePath, canonicalBody, hash, timestamp); err != nil {
 		// Log but don't fail - version history is nice to have
-		fmt.Printf("[warning] Failed to initialize version history: %v\n", err)
+		logging.Warn("Failed to initialize version history", "path", relativePath, "err", err)
 	}
 	meta := &PostMeta{
 		Type:           "post",
@@ -323,14 +486,17 @@ version-history:
 		Title:          title,
 		Published:      timestamp,
 		CurrentVersion: "sha256:" + hash,
+		Summary:        summaryOf(opts.Summary, markdown),
+		Lang:           opts.Lang,
+		TranslationOf:  translationOf,
 	}
-	if err := AppendToIndex(dataDir, meta); err != nil {
-		fmt.Printf("[warning] Failed to update index: %v\n", err)
+	if err := updateIndex(dataDir, meta, opts.Unlisted); err != nil {
+		logging.Warn("Failed to update index", "path", relativePath, "err", err)
 	}
 
 	// Update manifest
 	if err := UpdateManifest(dataDir); err != nil {
-		fmt.Printf("[warning] Failed to update manifest: %v\n", err)
+		logging.Warn("Failed to update manifest", "err", err)
 	}
 
 	result := &PublishResult{
@@ -340,6 +506,13 @@ version-history:
 		Version:   "sha256:" + hash,
 		Signature: signature,
 	}
+	result.UnresolvedLinks = unresolvedLinks(dataDir, markdown)
+	result.Unlisted = opts.Unlisted
+	result.FollowersOnly = opts.FollowersOnly
+	result.NoCrosspost = opts.NoCrosspost
+	if opts.Unlisted || opts.NoAnnounce {
+		return result, nil
+	}
 
 	// Register with discovery service (non-fatal)
 	var cfg *DiscoveryConfig
@@ -347,8 +520,8 @@ version-history:
 		cfg = dsCfg[0]
 	}
 	if err := RegisterPost(dataDir, result, privateKey, cfg); err != nil {
-		fmt.Printf("[!] Discovery registration skipped: %v\n", err)
-		fmt.Println("[i] If your site is newly deployed, run: polis register")
+		logging.Warn("Discovery registration skipped", "path", result.Path, "err", err)
+		logging.Info("If your site is newly deployed, run: polis register")
 	}
 
 	return result, nil
@@ -356,26 +529,32 @@ version-history:
 
 // ensureUniqueFilename checks for filename collisions and appends -2, -3, etc. if needed.
 func ensureUniqueFilename(dataDir, dateDir, filename string) string {
+	return uniqueFilename(dataDir, dateDir, filename, "")
+}
+
+// uniqueFilename is ensureUniqueFilename, except that the draft ownDraft
+// (the one being published) is not a collision.
+func uniqueFilename(dataDir, dateDir, filename, ownDraft string) string {
+	fsys := storage.For(dataDir)
 	candidate := filename
 	suffix := 2
 	for {
-		// Check posts directory
-		postPath := filepath.Join(dataDir, "posts", dateDir, candidate+".md")
-		if _, err := os.Stat(postPath); err == nil {
+		// Check posts directories (public and followers-only share slugs,
+		// and version history)
+		postPath := PostPath(dateDir, candidate)
+		if storage.Exists(fsys, postPath) || storage.Exists(fsys, ProtectedDir+"/"+postPath) {
 			candidate = fmt.Sprintf("%s-%d", filename, suffix)
 			suffix++
 			continue
 		}
 
 		// Check drafts directories (both old and new paths)
-		draftPath1 := filepath.Join(dataDir, ".polis", "posts", "drafts", candidate+".md")
-		draftPath2 := filepath.Join(dataDir, ".polis", "drafts", candidate+".md")
-		if _, err := os.Stat(draftPath1); err == nil {
+		if storage.Exists(fsys, draftName(candidate)) && candidate != ownDraft {
 			candidate = fmt.Sprintf("%s-%d", filename, suffix)
 			suffix++
 			continue
 		}
-		if _, err := os.Stat(draftPath2); err == nil {
+		if storage.Exists(fsys, ".polis/drafts/"+candidate+".md") {
 			candidate = fmt.Sprintf("%s-%d", filename, suffix)
 			suffix++
 			continue
@@ -405,7 +584,7 @@ func extractSignatureBase64(sig string) string {
 // Returns the diff output, or empty string if contents are identical.
 func computeUnifiedDiff(oldContent, newContent string) (string, error) {
 	// Create a private temp directory (0700) so files aren't world-readable
-	tmpDir, err := os.MkdirTemp("", "polis-diff-*")
+	tmpDir, err := fsutil.MkdirTemp("polis-diff-*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp dir: %w", err)
 	}
@@ -455,22 +634,23 @@ func computeUnifiedDiff(oldContent, newContent string) (string, error) {
 	return string(output), nil
 }
 
-// updateCurrentHashInVersionsFile updates the CURRENT_HASH header in a versions file.
-func updateCurrentHashInVersionsFile(versionsPath, newHash string) error {
-	content, err := os.ReadFile(versionsPath)
-	if err != nil {
-		return fmt.Errorf("failed to read versions file: %w", err)
-	}
-
-	lines := strings.Split(string(content), "\n")
+// updateCurrentHash returns a versions file with its CURRENT_HASH header
+// set to newHash.
+func updateCurrentHash(content, newHash string) string {
+	lines := strings.Split(content, "\n")
 	for i, line := range lines {
 		if strings.HasPrefix(line, "# CURRENT_HASH=") {
 			lines[i] = "# CURRENT_HASH=sha256:" + newHash
 			break
 		}
 	}
+	return strings.Join(lines, "\n")
+}
 
-	return os.WriteFile(versionsPath, []byte(strings.Join(lines, "\n")), 0644)
+// versionsName returns the storage name of a post's version history file.
+// dateDir is the post's directory under posts/, possibly empty.
+func versionsName(dateDir, filename string) string {
+	return storage.Join("posts", dateDir, ".versions", filename+".md")
 }
 
 // initializeVersionHistory creates the initial version history file.
@@ -478,11 +658,6 @@ func updateCurrentHashInVersionsFile(versionsPath, newHash string) error {
 // canonicalPath is the relative path like "posts/20260128/filename.md"
 // contentWithoutFrontmatter is the body content without YAML frontmatter
 func initializeVersionHistory(dataDir, dateDir, filename, canonicalPath, contentWithoutFrontmatter, hash, timestamp string) error {
-	versionsDir := filepath.Join(dataDir, "posts", dateDir, ".versions")
-	if err := os.MkdirAll(versionsDir, 0755); err != nil {
-		return err
-	}
-
 	// CLI-compatible format with header and VERSION block
 	versionContent := fmt.Sprintf(`# VERSION_FILE_FORMAT=1.0
 # CANONICAL_FILE=%s
@@ -496,14 +671,27 @@ FULL_CONTENT_START
 
 `, canonicalPath, hash, hash, timestamp, contentWithoutFrontmatter)
 
-	versionPath := filepath.Join(versionsDir, filename+".md")
-	return os.WriteFile(versionPath, []byte(versionContent), 0644)
+	return storage.For(dataDir).WriteFile(versionsName(dateDir, filename), []byte(versionContent), 0644)
 }
 
 // AppendToIndex appends a post entry to public.jsonl.
-// Delegates to metadata.AppendPostToIndex for deduplication support.
+// Delegates to metadata.AppendToPublicIndex for deduplication support.
 func AppendToIndex(dataDir string, meta *PostMeta) error {
-	return metadata.AppendPostToIndex(dataDir, meta.Path, meta.Title, meta.Published, meta.CurrentVersion)
+	return metadata.AppendToPublicIndex(dataDir, meta.indexEntry())
+}
+
+// indexEntry returns meta as a post index entry.
+func (meta *PostMeta) indexEntry() *metadata.IndexEntry {
+	return &metadata.IndexEntry{
+		Type:           "post",
+		Path:           meta.Path,
+		Title:          meta.Title,
+		Published:      meta.Published,
+		CurrentVersion: meta.CurrentVersion,
+		Summary:        meta.Summary,
+		Lang:           meta.Lang,
+		TranslationOf:  meta.TranslationOf,
+	}
 }
 
 // DefaultVersion returns the generator identifier for new manifests.
@@ -514,12 +702,20 @@ func DefaultVersion() string {
 // UpdateManifest updates the manifest.json file.
 // Matches the bash CLI's manifest structure exactly.
 func UpdateManifest(dataDir string) error {
-	metadataDir := filepath.Join(dataDir, "metadata")
-	manifestPath := filepath.Join(metadataDir, "manifest.json")
+	fsys := storage.For(dataDir)
+	return storage.With(fsys, manifestName, func() error {
+		return updateManifest(fsys)
+	})
+}
+
+// manifestName is manifest.json's name in site storage.
+const manifestName = "metadata/manifest.json"
+
+func updateManifest(fsys storage.FS) error {
 
 	// Load existing manifest if present (preserves active_theme, version)
 	var manifest ManifestData
-	if data, err := os.ReadFile(manifestPath); err == nil {
+	if data, err := fsys.ReadFile(manifestName); err == nil {
 		json.Unmarshal(data, &manifest)
 	}
 
@@ -531,19 +727,16 @@ func UpdateManifest(dataDir string) error {
 	// Count posts and find last_published timestamp
 	postCount := 0
 	var lastPublished string
-	postsDir := filepath.Join(dataDir, "posts")
-	if entries, err := os.ReadDir(postsDir); err == nil {
+	if entries, err := fsys.ReadDir("posts"); err == nil {
 		for _, entry := range entries {
 			if entry.IsDir() {
 				// Count .md files in date directory
-				dateDirPath := filepath.Join(postsDir, entry.Name())
-				if files, err := os.ReadDir(dateDirPath); err == nil {
+				if files, err := fsys.ReadDir("posts/" + entry.Name()); err == nil {
 					for _, file := range files {
 						if !file.IsDir() && strings.HasSuffix(file.Name(), ".md") {
 							postCount++
 							// Check file modification time for last_published
-							filePath := filepath.Join(dateDirPath, file.Name())
-							if info, err := os.Stat(filePath); err == nil {
+							if info, err := file.Info(); err == nil {
 								modTime := info.ModTime().UTC().Format("2006-01-02T15:04:05Z")
 								if modTime > lastPublished {
 									lastPublished = modTime
@@ -558,13 +751,11 @@ func UpdateManifest(dataDir string) error {
 
 	// Count comments
 	commentCount := 0
-	commentsDir := filepath.Join(dataDir, "comments")
-	if entries, err := os.ReadDir(commentsDir); err == nil {
+	if entries, err := fsys.ReadDir("comments"); err == nil {
 		for _, entry := range entries {
 			if entry.IsDir() {
 				// Count .md files in date directory
-				dateDirPath := filepath.Join(commentsDir, entry.Name())
-				if files, err := os.ReadDir(dateDirPath); err == nil {
+				if files, err := fsys.ReadDir("comments/" + entry.Name()); err == nil {
 					for _, file := range files {
 						if !file.IsDir() && strings.HasSuffix(file.Name(), ".md") {
 							commentCount++
@@ -590,7 +781,7 @@ func UpdateManifest(dataDir string) error {
 		return err
 	}
 
-	return os.WriteFile(manifestPath, data, 0644)
+	return fsys.WriteFile(manifestName, data, 0644)
 }
 
 // HasFrontmatter checks if content already has YAML frontmatter.
@@ -682,11 +873,29 @@ func ExtractVersionHistory(content string) []string {
 	return history
 }
 
+// unresolvedLinks returns the wiki link targets in markdown that don't name
+// a published post. Called after the index is updated, so a post can link
+// to itself.
+func unresolvedLinks(dataDir, markdown string) []string {
+	ix, err := wikilink.LoadIndex(dataDir)
+	if err != nil {
+		return nil
+	}
+	return ix.Unresolved(markdown)
+}
+
 // RepublishPost updates an existing published post.
 func RepublishPost(dataDir, postPath, markdown string, privateKey []byte, dsCfg ...*DiscoveryConfig) (*PublishResult, error) {
+	// Index entries and URLs use forward slashes on every platform
+	postPath = filepath.ToSlash(postPath)
+	if ContentCheck != nil {
+		if err := ContentCheck(markdown); err != nil {
+			return nil, err
+		}
+	}
 	// Read existing post to get original metadata
-	fullPath := filepath.Join(dataDir, postPath)
-	existingContent, err := os.ReadFile(fullPath)
+	fsys := storage.For(dataDir)
+	existingContent, err := fsys.ReadFile(storage.Name(postPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read existing post: %w", err)
 	}
@@ -708,6 +917,10 @@ func RepublishPost(dataDir, postPath, markdown string, privateKey []byte, dsCfg
 	// Get existing version history
 	versionHistory := ExtractVersionHistory(string(existingContent))
 
+	// Carry over fields edited via UpdateFrontmatter
+	existing := ParseFrontmatterFields(string(existingContent))
+	optionalFields := optionalFrontmatterLines(existing)
+
 	// Extract title from new content
 	title := ExtractTitle(markdown)
 
@@ -727,21 +940,29 @@ func RepublishPost(dataDir, postPath, markdown string, privateKey []byte, dsCfg
 		versionHistoryYAML += fmt.Sprintf("\n  - %s", v)
 	}
 
+	delegationLine, err := delegation.FrontmatterLine(privateKey, delegation.ScopePublish)
+	if err != nil {
+		return nil, err
+	}
+	delegationLine += coauthor.FrontmatterLine(privateKey)
+
 	// Build content to sign (frontmatter without signature + content)
 	unsignedFrontmatter := fmt.Sprintf(`---
 title: %s
 published: %s
-updated: %s
+updated: %s%s
 generator: %s
 current-version: sha256:%s
-version-history:%s
+version-history:%s%s
 ---`,
 		escapeYAMLString(title),
 		originalPublished,
 		updateTimestamp,
+		optionalFields,
 		GetGenerator(),
 		hash,
 		versionHistoryYAML,
+		delegationLine,
 	)
 
 	// Build full unsigned content, then canonicalize the whole thing for signing
@@ -762,18 +983,20 @@ version-history:%s
 	finalFrontmatter := fmt.Sprintf(`---
 title: %s
 published: %s
-updated: %s
+updated: %s%s
 generator: %s
 current-version: sha256:%s
-version-history:%s
+version-history:%s%s
 signature: %s
 ---`,
 		escapeYAMLString(title),
 		originalPublished,
 		updateTimestamp,
+		optionalFields,
 		GetGenerator(),
 		hash,
 		versionHistoryYAML,
+		delegationLine,
 		sigBase64,
 	)
 
@@ -781,30 +1004,33 @@ signature: %s
 	finalContent := finalFrontmatter + "\n\n" + canonicalBody
 
 	// Write updated post file
-	if err := os.WriteFile(fullPath, []byte(finalContent), 0644); err != nil {
+	if err := fsys.WriteFile(storage.Name(postPath), []byte(finalContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write post file: %w", err)
 	}
 
 	// Update version history file with CLI-compatible format
-	// Path format: posts/YYYYMMDD/filename.md
-	pathParts := strings.Split(postPath, string(filepath.Separator))
-	if len(pathParts) >= 3 {
-		dateDir := pathParts[1]
-		filename := strings.TrimSuffix(pathParts[2], ".md")
+	// Path format: posts/<dir>/filename.md, where dir is YYYYMMDD, YYYY/MM,
+	// or empty for flat posts
+	if slashPath := strings.TrimPrefix(filepath.ToSlash(postPath), ProtectedDir+"/"); strings.HasPrefix(slashPath, "posts/") {
+		dateDir := strings.TrimPrefix(strings.TrimPrefix(path.Dir(slashPath), "posts"), "/")
+		filename := strings.TrimSuffix(path.Base(slashPath), ".md")
 		// Pass content WITHOUT frontmatter for diff computation
 		if err := appendVersionHistory(dataDir, dateDir, filename, postPath, oldHash, hash, updateTimestamp, oldContentWithoutFrontmatter, canonicalBody); err != nil {
-			fmt.Printf("[warning] Failed to update version history: %v\n", err)
+			logging.Warn("Failed to update version history", "path", postPath, "err", err)
 		}
 	}
 
 	// Update index entry
-	if err := UpdateIndexEntry(dataDir, postPath, title, "sha256:"+hash); err != nil {
-		fmt.Printf("[warning] Failed to update index: %v\n", err)
+	existing.Title = title
+	existing.Published = originalPublished
+	unlisted := keptOffIndex(existing.Visibility)
+	if err := updateIndex(dataDir, indexMeta(postPath, existing, "sha256:"+hash, markdown), unlisted); err != nil {
+		logging.Warn("Failed to update index", "path", postPath, "err", err)
 	}
 
 	// Update manifest
 	if err := UpdateManifest(dataDir); err != nil {
-		fmt.Printf("[warning] Failed to update manifest: %v\n", err)
+		logging.Warn("Failed to update manifest", "err", err)
 	}
 
 	result := &PublishResult{
@@ -814,6 +1040,12 @@ signature: %s
 		Version:   "sha256:" + hash,
 		Signature: signature,
 	}
+	result.UnresolvedLinks = unresolvedLinks(dataDir, markdown)
+	result.Unlisted = unlisted
+	result.FollowersOnly = existing.Visibility == VisibilityFollowers
+	if unlisted {
+		return result, nil
+	}
 
 	// Register with discovery service (non-fatal)
 	var cfg *DiscoveryConfig
@@ -821,8 +1053,8 @@ signature: %s
 		cfg = dsCfg[0]
 	}
 	if err := RegisterPost(dataDir, result, privateKey, cfg); err != nil {
-		fmt.Printf("[!] Discovery registration skipped: %v\n", err)
-		fmt.Println("[i] If your site is newly deployed, run: polis register")
+		logging.Warn("Discovery registration skipped", "path", result.Path, "err", err)
+		logging.Info("If your site is newly deployed, run: polis register")
 	}
 
 	return result, nil
@@ -835,28 +1067,22 @@ signature: %s
 // oldContentWithoutFrontmatter is the previous version's content for diff computation
 // newContentWithoutFrontmatter is the new content for diff computation
 func appendVersionHistory(dataDir, dateDir, filename, canonicalPath, previousHash, newHash, timestamp, oldContentWithoutFrontmatter, newContentWithoutFrontmatter string) error {
-	versionsDir := filepath.Join(dataDir, "posts", dateDir, ".versions")
-	if err := os.MkdirAll(versionsDir, 0755); err != nil {
-		return err
-	}
-
-	versionPath := filepath.Join(versionsDir, filename+".md")
+	fsys := storage.For(dataDir)
+	versionPath := versionsName(dateDir, filename)
 
 	// Update CURRENT_HASH in the header
-	if err := updateCurrentHashInVersionsFile(versionPath, newHash); err != nil {
+	var content string
+	if data, err := fsys.ReadFile(versionPath); err == nil {
+		content = updateCurrentHash(string(data), newHash)
+	} else if os.IsNotExist(err) {
 		// If file doesn't exist, create it with header
-		if os.IsNotExist(err) {
-			header := fmt.Sprintf(`# VERSION_FILE_FORMAT=1.0
+		content = fmt.Sprintf(`# VERSION_FILE_FORMAT=1.0
 # CANONICAL_FILE=%s
 # CURRENT_HASH=sha256:%s
 
 `, canonicalPath, newHash)
-			if err := os.WriteFile(versionPath, []byte(header), 0644); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
+	} else {
+		return fmt.Errorf("failed to read versions file: %w", err)
 	}
 
 	// Compute unified diff
@@ -874,56 +1100,50 @@ DIFF_START
 
 `, newHash, timestamp, previousHash, diffContent)
 
-	f, err := os.OpenFile(versionPath, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	_, err = f.WriteString(versionEntry)
-	return err
+	return fsys.WriteFile(versionPath, []byte(content+versionEntry), 0644)
 }
 
-// UpdateIndexEntry updates an existing entry in public.jsonl.
-func UpdateIndexEntry(dataDir, postPath, newTitle, newVersion string) error {
-	indexPath := filepath.Join(dataDir, "metadata", "public.jsonl")
-
-	data, err := os.ReadFile(indexPath)
-	if err != nil {
-		return err
-	}
-
-	var newLines []string
-	lines := strings.Split(string(data), "\n")
-	found := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// UpdateIndexEntry updates an existing entry in public.jsonl from meta. An
+// empty title or version leaves the entry's value unchanged; the other
+// fields are always replaced.
+// Delegates to metadata.ModifyIndexEntry, which locks and rewrites the index atomically.
+func UpdateIndexEntry(dataDir, postPath string, meta *PostMeta) error {
+	return metadata.ModifyIndexEntry(dataDir, postPath, func(entry *metadata.IndexEntry) {
+		if meta.Title != "" {
+			entry.Title = meta.Title
 		}
-
-		var entry PostMeta
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			newLines = append(newLines, line)
-			continue
+		if meta.CurrentVersion != "" {
+			if meta.CurrentVersion != entry.CurrentVersion {
+				entry.IPFS = "" // The IPFS copy is of the old version
+			}
+			entry.CurrentVersion = meta.CurrentVersion
 		}
+		entry.Summary = meta.Summary
+		entry.Lang = meta.Lang
+		entry.TranslationOf = meta.TranslationOf
+	})
+}
 
-		if entry.Path == postPath {
-			// Update this entry
-			entry.Title = newTitle
-			entry.CurrentVersion = newVersion
-			updated, _ := json.Marshal(entry)
-			newLines = append(newLines, string(updated))
-			found = true
-		} else {
-			newLines = append(newLines, line)
-		}
+// indexMeta returns the index entry of the post at postPath with
+// frontmatter fm, at version, with the given body.
+func indexMeta(postPath string, fm *PostFrontmatter, version, body string) *PostMeta {
+	return &PostMeta{
+		Type:           "post",
+		Path:           postPath,
+		Title:          fm.Title,
+		Published:      fm.Published,
+		CurrentVersion: version,
+		Summary:        summaryOf(fm.Summary, body),
+		Lang:           fm.Lang,
+		TranslationOf:  fm.TranslationOf,
 	}
+}
 
-	if !found {
-		return fmt.Errorf("post not found in index: %s", postPath)
+// summaryOf returns the post's summary: the frontmatter summary if it has
+// one, otherwise one generated from the body.
+func summaryOf(frontmatterSummary, body string) string {
+	if frontmatterSummary != "" {
+		return frontmatterSummary
 	}
-
-	return os.WriteFile(indexPath, []byte(strings.Join(newLines, "\n")+"\n"), 0644)
+	return Summarize(body)
 }
@@ -1,10 +1,18 @@
 package publish
 
 import (
+	"errors"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 func TestGetGenerator_UsesVersion(t *testing.T) {
@@ -97,7 +105,7 @@ func TestSlugify_NormalTitleNoSuffix(t *testing.T) {
 
 func TestSlugify_UntitledTitleInPublishPost(t *testing.T) {
 	// When ExtractTitle returns "Untitled", Slugify produces "untitled"
-	// PublishPost should detect this and add a random suffix
+	// PublishPost then relies on collision suffixes (untitled-2, ...)
 	title := ExtractTitle("")
 	if title != "Untitled" {
 		t.Errorf("expected 'Untitled' for empty content, got %s", title)
@@ -106,8 +114,8 @@ func TestSlugify_UntitledTitleInPublishPost(t *testing.T) {
 	if slug != "untitled" {
 		t.Errorf("expected 'untitled', got %s", slug)
 	}
-	// The random suffix is added in PublishPost, not Slugify,
-	// so Slugify("Untitled") correctly returns "untitled"
+	// Slugify("Untitled") correctly returns "untitled"; only titles with
+	// no letters or digits at all get a random suffix
 }
 
 func TestEnsureUniqueFilename_DraftCollision(t *testing.T) {
@@ -125,3 +133,178 @@ func TestEnsureUniqueFilename_DraftCollision(t *testing.T) {
 		t.Errorf("expected 'hello-world-2', got %s", result)
 	}
 }
+
+func TestPublishPost_ReportsUnresolvedWikiLinks(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	if _, err := PublishPost(dataDir, "# First\n\nHello.\n", "", privKey); err != nil {
+		t.Fatalf("PublishPost failed: %v", err)
+	}
+	result, err := PublishPost(dataDir, "# Second\n\nSee [[first]], [[second]], and [[nowhere]].\n", "", privKey)
+	if err != nil {
+		t.Fatalf("PublishPost failed: %v", err)
+	}
+	if len(result.UnresolvedLinks) != 1 || result.UnresolvedLinks[0] != "nowhere" {
+		t.Errorf("UnresolvedLinks = %v, want [nowhere]", result.UnresolvedLinks)
+	}
+}
+
+func TestPublishPost_ContentCheckBlocks(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	ContentCheck = func(markdown string) error {
+		if strings.Contains(markdown, "<img") {
+			return errors.New("image without alt text")
+		}
+		return nil
+	}
+	defer func() { ContentCheck = nil }()
+
+	if _, err := PublishPost(dataDir, "# Pic\n\n<img src=\"a.png\">\n", "", privKey); err == nil {
+		t.Fatal("expected ContentCheck to block the post")
+	}
+	if entries, _ := metadata.LoadPublicIndex(dataDir); len(entries) != 0 {
+		t.Errorf("blocked post was indexed: %+v", entries)
+	}
+	if _, err := PublishPost(dataDir, "# Fine\n\nText.\n", "", privKey); err != nil {
+		t.Fatalf("PublishPost failed: %v", err)
+	}
+}
+
+func TestFrontmatterNoCrosspost(t *testing.T) {
+	for content, want := range map[string]bool{
+		"---\ncrosspost: false\n---\n# Hi\n": true,
+		"---\ncrosspost: true\n---\n# Hi\n":  false,
+		"---\nslug: hi\n---\n# Hi\n":         false,
+		"# Hi\n\ncrosspost: false\n":         false,
+	} {
+		if got := FrontmatterNoCrosspost(content); got != want {
+			t.Errorf("FrontmatterNoCrosspost(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestPublishResult_Crosspostable(t *testing.T) {
+	cases := []struct {
+		result PublishResult
+		want   bool
+	}{
+		{PublishResult{}, true},
+		{PublishResult{Unlisted: true}, false},
+		{PublishResult{FollowersOnly: true, Unlisted: true}, false},
+		{PublishResult{NoCrosspost: true}, false},
+	}
+	for _, c := range cases {
+		if got := c.result.Crosspostable(); got != c.want {
+			t.Errorf("Crosspostable(%+v) = %v", c.result, got)
+		}
+	}
+}
+
+func TestPublishPost_InMemoryStorage(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "site")
+	mem := storage.NewMem()
+	defer storage.Mount(dataDir, mem)()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	result, err := PublishPost(dataDir, "# Hello\n\nFirst.\n", "hello", privKey)
+	if err != nil {
+		t.Fatalf("PublishPost: %v", err)
+	}
+	if _, err := RepublishPost(dataDir, result.Path, "# Hello\n\nSecond.\n", privKey); err != nil {
+		t.Fatalf("RepublishPost: %v", err)
+	}
+
+	content, err := mem.ReadFile(result.Path)
+	if err != nil || !strings.Contains(string(content), "Second.") {
+		t.Fatalf("post = %q, %v", content, err)
+	}
+	versions, err := mem.ReadFile(versionsName(strings.TrimPrefix(path.Dir(result.Path), "posts/"), "hello"))
+	if err != nil || strings.Count(string(versions), "[VERSION ") != 2 {
+		t.Errorf("version history = %q, %v", versions, err)
+	}
+	if entries, _ := metadata.LoadPublicIndex(dataDir); len(entries) != 1 {
+		t.Errorf("public.jsonl has %d entries, want 1", len(entries))
+	}
+	if !storage.Exists(mem, "metadata/manifest.json") {
+		t.Error("manifest.json not written")
+	}
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Errorf("site directory was created on disk: %v", err)
+	}
+}
+
+func TestPublishPostWithOptions_Backdated(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	at := time.Date(2014, 3, 9, 17, 4, 5, 0, time.UTC)
+	result, err := PublishPostWithOptions(dataDir, "Just setting up my site\n", PublishOptions{
+		Published:    at,
+		ImportedFrom: "https://twitter.com/alice/status/1",
+		NoAnnounce:   true,
+	}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(result.Path, "posts/20140309/") {
+		t.Errorf("path = %q, want the 2014-03-09 directory", result.Path)
+	}
+
+	fm, err := ReadFrontmatter(dataDir, result.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Published != "2014-03-09T17:04:05Z" {
+		t.Errorf("published = %q", fm.Published)
+	}
+	if fm.ImportedFrom != "https://twitter.com/alice/status/1" {
+		t.Errorf("imported_from = %q", fm.ImportedFrom)
+	}
+
+	// Editing the post keeps where it came from
+	if _, err := RepublishPost(dataDir, result.Path, "Just setting up my site.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	if fm, _ := ReadFrontmatter(dataDir, result.Path); fm.ImportedFrom != "https://twitter.com/alice/status/1" {
+		t.Errorf("imported_from after republish = %q", fm.ImportedFrom)
+	}
+}
+
+func TestPublishPost_Coauthor(t *testing.T) {
+	dataDir := t.TempDir()
+	sitePriv, _, _ := signing.GenerateKeypair()
+	pub, err := coauthor.Generate(dataDir, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorKey, err := coauthor.LoadKey(dataDir, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := PublishPost(dataDir, "# Minutes\n\nWe met.\n", "", authorKey)
+	if err != nil {
+		t.Fatalf("PublishPost failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dataDir, result.Path))
+	before, sig, ok := strings.Cut(string(content), "\nsignature: ")
+	if !ok || !strings.Contains(before, "\nsigned-by: alice") {
+		t.Fatalf("expected a signed-by line before the signature:\n%s", content)
+	}
+	sig, after, _ := strings.Cut(sig, "\n")
+	signed := CanonicalizeContent(before + "\n" + after)
+	armored := "-----BEGIN SSH SIGNATURE-----\n" + sig + "\n-----END SSH SIGNATURE-----\n"
+	if ok, _ := signing.VerifySignature([]byte(signed), []byte(pub), armored); !ok {
+		t.Error("signature doesn't verify against the author's key")
+	}
+
+	// Republished with the site key, the post no longer names her
+	if _, err := RepublishPost(dataDir, result.Path, "# Minutes\n\nWe met twice.\n", sitePriv); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dataDir, result.Path)); strings.Contains(string(content), "signed-by:") {
+		t.Errorf("republished post still names an author:\n%s", content)
+	}
+}
@@ -0,0 +1,80 @@
+package publish
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// ScheduledDraft is a post draft whose frontmatter has a publish_at time.
+type ScheduledDraft struct {
+	ID        string    // Draft filename without .md
+	Path      string    // Absolute path to the draft file
+	PublishAt time.Time // When the draft should be published
+}
+
+// ParsePublishAt parses a publish_at value: RFC 3339, or a bare date
+// (YYYY-MM-DD) or date and time (YYYY-MM-DD HH:MM) in local time.
+func ParsePublishAt(value string) (time.Time, error) {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid publish_at %q (expected RFC 3339 or YYYY-MM-DD [HH:MM])", value)
+}
+
+// DueDrafts returns post drafts in .polis/posts/drafts whose publish_at is
+// at or before now, oldest first. Drafts with an unparseable publish_at are
+// skipped with an error describing the first one.
+func DueDrafts(dataDir string, now time.Time) ([]ScheduledDraft, error) {
+	fsys := storage.For(dataDir)
+	entries, err := fsys.ReadDir(".polis/posts/drafts")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+
+	var due []ScheduledDraft
+	var firstErr error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".md")
+		content, err := fsys.ReadFile(draftName(id))
+		if err != nil {
+			continue
+		}
+		value := ParseFrontmatter(string(content))["publish_at"]
+		if value == "" {
+			continue
+		}
+		at, err := ParsePublishAt(value)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+			continue
+		}
+		if !at.After(now) {
+			due = append(due, ScheduledDraft{
+				ID:        id,
+				Path:      DraftPath(dataDir, id),
+				PublishAt: at,
+			})
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].PublishAt.Before(due[j].PublishAt) })
+	return due, firstErr
+}
@@ -0,0 +1,46 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDueDrafts(t *testing.T) {
+	dataDir := t.TempDir()
+	draftsDir := filepath.Join(dataDir, ".polis", "posts", "drafts")
+	os.MkdirAll(draftsDir, 0755)
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(draftsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("later.md", "---\npublish_at: 2026-03-01T09:00:00Z\n---\n# Later\n")
+	write("second.md", "---\npublish_at: 2026-02-01T10:00:00Z\n---\n# Second\n")
+	write("first.md", "---\npublish_at: \"2026-01-15T08:00:00Z\"\n---\n# First\n")
+	write("unscheduled.md", "# No frontmatter\n")
+	write("bad.md", "---\npublish_at: next tuesday\n---\n# Bad\n")
+
+	now := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	due, err := DueDrafts(dataDir, now)
+	if err == nil {
+		t.Error("expected an error for the unparseable publish_at")
+	}
+	if len(due) != 2 || due[0].ID != "first" || due[1].ID != "second" {
+		t.Fatalf("due = %+v", due)
+	}
+}
+
+func TestParsePublishAt(t *testing.T) {
+	for _, in := range []string{"2026-02-01T10:00:00Z", "2026-02-01 10:00", "2026-02-01"} {
+		if _, err := ParsePublishAt(in); err != nil {
+			t.Errorf("ParsePublishAt(%q): %v", in, err)
+		}
+	}
+	if _, err := ParsePublishAt("soon"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
@@ -0,0 +1,223 @@
+package publish
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// DraftSharesFilename is the draft preview link file, relative to .polis/.
+const DraftSharesFilename = "draft-shares.json"
+
+// Draft share lifetimes.
+const (
+	DefaultShareTTL = 72 * time.Hour
+	MaxShareTTL     = 30 * 24 * time.Hour
+)
+
+// DraftShare is a preview link to a draft: whoever has the token can read
+// the draft, rendered, until it expires or is revoked. Only the token's
+// SHA-256 hash is stored; the token itself is returned once, by ShareDraft.
+type DraftShare struct {
+	ID        string `json:"id"` // Names the share for revoking; derived from Hash
+	Hash      string `json:"hash"`
+	Token     string `json:"token,omitempty"` // Set only on a newly issued share
+	Draft     string `json:"draft"`           // Draft ID
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// Expired reports whether the share has expired at now.
+func (sh DraftShare) Expired(now time.Time) bool {
+	exp, err := time.Parse(time.RFC3339, sh.ExpiresAt)
+	return err != nil || !now.Before(exp)
+}
+
+// hashShareToken returns the hex SHA-256 of a preview token.
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// DraftShareID returns the ID of the share issued with token.
+func DraftShareID(token string) string {
+	return hashShareToken(token)[:12]
+}
+
+// sharesMu serializes read-modify-write cycles of the shares file.
+var sharesMu sync.Mutex
+
+// DraftSharesPath returns the path to .polis/draft-shares.json.
+func DraftSharesPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", DraftSharesFilename)
+}
+
+// ShareDraft issues a preview token for a draft, valid for ttl (0 uses
+// DefaultShareTTL; longer than MaxShareTTL is capped). Expired shares are
+// dropped from the file while it is rewritten.
+func ShareDraft(dataDir, draftID string, ttl time.Duration) (*DraftShare, error) {
+	if !draftIDPattern.MatchString(draftID) {
+		return nil, fmt.Errorf("invalid draft ID: %q", draftID)
+	}
+	if _, err := os.Stat(DraftPath(dataDir, draftID)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultShareTTL
+	}
+	if ttl > MaxShareTTL {
+		ttl = MaxShareTTL
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	now := time.Now().UTC()
+	share := DraftShare{
+		ID:        DraftShareID(token),
+		Hash:      hashShareToken(token),
+		Draft:     draftID,
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(ttl).Format(time.RFC3339),
+	}
+
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	shares, err := loadDraftShares(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveDraftShares(dataDir, append(shares, share)); err != nil {
+		return nil, err
+	}
+	share.Token = token
+	return &share, nil
+}
+
+// DraftShares returns the unexpired shares of a draft, or of all drafts if
+// draftID is empty, oldest first.
+func DraftShares(dataDir, draftID string) ([]DraftShare, error) {
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	shares, err := loadDraftShares(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	out := []DraftShare{}
+	for _, sh := range shares {
+		if draftID == "" || sh.Draft == draftID {
+			out = append(out, sh)
+		}
+	}
+	return out, nil
+}
+
+// FindDraftShare returns the unexpired share with the given token, or nil.
+func FindDraftShare(dataDir, token string) (*DraftShare, error) {
+	if token == "" {
+		return nil, nil
+	}
+	shares, err := DraftShares(dataDir, "")
+	if err != nil {
+		return nil, err
+	}
+	hash := hashShareToken(token)
+	for _, sh := range shares {
+		if subtle.ConstantTimeCompare([]byte(sh.Hash), []byte(hash)) == 1 {
+			return &sh, nil
+		}
+	}
+	return nil, nil
+}
+
+// RevokeDraftShares removes the share with the given ID from a draft, or
+// all of the draft's shares if shareID is empty. It returns how many were
+// removed.
+func RevokeDraftShares(dataDir, draftID, shareID string) (int, error) {
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	shares, err := loadDraftShares(dataDir)
+	if err != nil {
+		return 0, err
+	}
+	kept := shares[:0]
+	for _, sh := range shares {
+		if sh.Draft == draftID && (shareID == "" || sh.ID == shareID) {
+			continue
+		}
+		kept = append(kept, sh)
+	}
+	removed := len(shares) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, saveDraftShares(dataDir, kept)
+}
+
+// loadDraftShares reads the unexpired shares. Shares saved before tokens
+// were hashed are rewritten hashed. Callers hold sharesMu.
+func loadDraftShares(dataDir string) ([]DraftShare, error) {
+	data, err := os.ReadFile(DraftSharesPath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read draft shares: %w", err)
+	}
+	var shares []DraftShare
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return nil, fmt.Errorf("failed to parse draft shares: %w", err)
+	}
+	now := time.Now()
+	live := shares[:0]
+	migrated := false
+	for _, sh := range shares {
+		if sh.Expired(now) {
+			continue
+		}
+		if sh.Hash == "" && sh.Token != "" {
+			sh.ID, sh.Hash, sh.Token = DraftShareID(sh.Token), hashShareToken(sh.Token), ""
+			migrated = true
+		}
+		live = append(live, sh)
+	}
+	if migrated {
+		if err := saveDraftShares(dataDir, live); err != nil {
+			return nil, err
+		}
+	}
+	return live, nil
+}
+
+// saveDraftShares writes the shares file. Callers hold sharesMu.
+func saveDraftShares(dataDir string, shares []DraftShare) error {
+	if shares == nil {
+		shares = []DraftShare{}
+	}
+	data, err := json.MarshalIndent(shares, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal draft shares: %w", err)
+	}
+	path := DraftSharesPath(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .polis directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write draft shares: %w", err)
+	}
+	return nil
+}
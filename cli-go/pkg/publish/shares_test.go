@@ -0,0 +1,83 @@
+package publish
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareDraft(t *testing.T) {
+	dataDir := t.TempDir()
+	if _, err := SaveDraft(dataDir, "essay", "# Essay\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ShareDraft(dataDir, "missing", 0); !errors.Is(err, ErrDraftNotFound) {
+		t.Errorf("expected ErrDraftNotFound, got %v", err)
+	}
+
+	a, err := ShareDraft(dataDir, "essay", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, _ := time.Parse(time.RFC3339, a.ExpiresAt)
+	if d := time.Until(exp); d < DefaultShareTTL-time.Minute || d > DefaultShareTTL {
+		t.Errorf("default lifetime = %v", d)
+	}
+	b, err := ShareDraft(dataDir, "essay", 365*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, _ = time.Parse(time.RFC3339, b.ExpiresAt)
+	if time.Until(exp) > MaxShareTTL {
+		t.Errorf("lifetime not capped: expires %s", b.ExpiresAt)
+	}
+
+	if sh, _ := FindDraftShare(dataDir, a.Token); sh == nil || sh.Draft != "essay" {
+		t.Errorf("FindDraftShare = %+v", sh)
+	}
+	if n, err := RevokeDraftShares(dataDir, "essay", a.ID); err != nil || n != 1 {
+		t.Fatalf("revoke one: n=%d err=%v", n, err)
+	}
+	if sh, _ := FindDraftShare(dataDir, a.Token); sh != nil {
+		t.Error("revoked token still found")
+	}
+	if shares, _ := DraftShares(dataDir, "essay"); len(shares) != 1 || shares[0].ID != b.ID || shares[0].Token != "" {
+		t.Errorf("DraftShares = %+v", shares)
+	}
+	if data, _ := os.ReadFile(DraftSharesPath(dataDir)); strings.Contains(string(data), b.Token) {
+		t.Errorf("token stored in plaintext:\n%s", data)
+	}
+	if n, _ := RevokeDraftShares(dataDir, "essay", ""); n != 1 {
+		t.Errorf("revoke all removed %d", n)
+	}
+}
+
+func TestDraftShares_HashesPlaintextTokens(t *testing.T) {
+	dataDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, ".polis"), 0755)
+	os.WriteFile(DraftSharesPath(dataDir), []byte(`[{"token": "abc123", "draft": "essay", "created_at": "2026-01-01T00:00:00Z", "expires_at": "2999-01-01T00:00:00Z"}]`), 0600)
+
+	sh, err := FindDraftShare(dataDir, "abc123")
+	if err != nil || sh == nil || sh.Draft != "essay" || sh.Token != "" {
+		t.Fatalf("FindDraftShare = %+v, %v", sh, err)
+	}
+	if data, _ := os.ReadFile(DraftSharesPath(dataDir)); strings.Contains(string(data), "abc123") {
+		t.Errorf("plaintext token kept:\n%s", data)
+	}
+	if n, _ := RevokeDraftShares(dataDir, "essay", DraftShareID("abc123")); n != 1 {
+		t.Errorf("revoke by ID removed %d", n)
+	}
+}
+
+func TestDraftShare_Expired(t *testing.T) {
+	sh := DraftShare{ExpiresAt: "2026-03-01T00:00:00Z"}
+	if sh.Expired(time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expired before its time")
+	}
+	if !sh.Expired(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("not expired at its time")
+	}
+}
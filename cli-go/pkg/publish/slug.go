@@ -0,0 +1,99 @@
+package publish
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Post path formats for the post_path setting. They decide the directory
+// under posts/ a new post is written to; existing posts keep their paths,
+// so republishing and the index are unaffected by a change.
+const (
+	PathDate      = "YYYYMMDD" // posts/20260125/hello.md (default)
+	PathYearMonth = "YYYY/MM"  // posts/2026/01/hello.md
+	PathFlat      = "flat"     // posts/hello.md
+)
+
+// PathFormats lists the valid post_path values.
+var PathFormats = []string{PathDate, PathYearMonth, PathFlat}
+
+// PostPathFormat is the format new posts are published under. It is set
+// from the post_path setting.
+var PostPathFormat = PathDate
+
+// PostDir returns the directory under posts/ (slash-separated, "" for flat)
+// that a post published at t goes in.
+func PostDir(format string, t time.Time) (string, error) {
+	t = t.UTC()
+	switch format {
+	case "", PathDate:
+		return t.Format("20060102"), nil
+	case PathYearMonth:
+		return t.Format("2006/01"), nil
+	case PathFlat:
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown post path format %q (expected %s)", format, strings.Join(PathFormats, ", "))
+}
+
+// PostPath returns the path of a post relative to the site root.
+func PostPath(dir, slug string) string {
+	return path.Join("posts", dir, slug+".md")
+}
+
+// FrontmatterSlug returns the slug field of a source file's frontmatter,
+// which sets the published filename, or "".
+func FrontmatterSlug(content string) string {
+	if !HasFrontmatter(content) {
+		return ""
+	}
+	return unquoteYAMLString(ParseFrontmatter(content)["slug"])
+}
+
+// transliterations spell common non-ASCII letters in ASCII, so titles in
+// Latin-script languages, Greek, and Cyrillic get readable slugs. Letters
+// from other scripts are kept as they are.
+var transliterations = map[rune]string{
+	// Latin-1 Supplement and Latin Extended-A
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "ae", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g", 'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĳ': "ij", 'ĵ': "j", 'ķ': "k", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n", 'ŉ': "n", 'ŋ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "oe", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'œ': "oe", 'ŕ': "r", 'ŗ': "r", 'ř': "r", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ß': "ss",
+	'ţ': "t", 'ť': "t", 'ŧ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "ue", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w", 'ý': "y", 'ÿ': "y", 'ŷ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+
+	// Greek
+	'α': "a", 'ά': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'έ': "e", 'ζ': "z", 'η': "i",
+	'ή': "i", 'θ': "th", 'ι': "i", 'ί': "i", 'ϊ': "i", 'ΐ': "i", 'κ': "k", 'λ': "l", 'μ': "m",
+	'ν': "n", 'ξ': "x", 'ο': "o", 'ό': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'ύ': "y", 'ϋ': "y", 'ΰ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o", 'ώ': "o",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "yo", 'є': "ye",
+	'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh",
+	'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// transliterate replaces letters with an ASCII spelling where one is known.
+// s must already be lowercase.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
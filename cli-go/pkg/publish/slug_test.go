@@ -0,0 +1,134 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestSlugify_Transliterates(t *testing.T) {
+	tests := map[string]string{
+		"Café au lait":          "cafe-au-lait",
+		"Über Straße":           "ueber-strasse",
+		"Łódź in spring":        "lodz-in-spring",
+		"Привет, мир":           "privet-mir",
+		"Καλημέρα":              "kalimera",
+		"日本語のタイトル":              "日本語のタイトル",
+		strings.Repeat("é", 60): strings.Repeat("e", 50),
+	}
+	for title, want := range tests {
+		if got := Slugify(title); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestSlugify_TruncatesByRune(t *testing.T) {
+	slug := Slugify(strings.Repeat("日", 60))
+	if got := len([]rune(slug)); got != 50 {
+		t.Errorf("expected 50 runes, got %d", got)
+	}
+	if !strings.HasPrefix(strings.Repeat("日", 60), slug) {
+		t.Errorf("slug was split mid-rune: %q", slug)
+	}
+}
+
+func TestPostDir(t *testing.T) {
+	ts := time.Date(2026, 3, 7, 23, 0, 0, 0, time.UTC)
+	tests := map[string]string{"": "20260307", PathDate: "20260307", PathYearMonth: "2026/03", PathFlat: ""}
+	for format, want := range tests {
+		got, err := PostDir(format, ts)
+		if err != nil || got != want {
+			t.Errorf("PostDir(%q) = %q, %v; want %q", format, got, err, want)
+		}
+	}
+	if _, err := PostDir("YYYY", ts); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if got := PostPath("", "hello"); got != "posts/hello.md" {
+		t.Errorf("PostPath flat = %q", got)
+	}
+}
+
+func TestFrontmatterSlug(t *testing.T) {
+	if got := FrontmatterSlug("---\nslug: \"my-slug\"\n---\n# Title\n"); got != "my-slug" {
+		t.Errorf("got %q", got)
+	}
+	if got := FrontmatterSlug("# slug: nope\n"); got != "" {
+		t.Errorf("got %q for content without frontmatter", got)
+	}
+}
+
+func TestPublishPostWithOptions_PathFormats(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	flat, err := PublishPostWithOptions(dataDir, "# Hello\n", PublishOptions{PathFormat: PathFlat}, privKey)
+	if err != nil {
+		t.Fatalf("publish flat: %v", err)
+	}
+	if flat.Path != "posts/hello.md" {
+		t.Errorf("flat path = %q", flat.Path)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "posts", ".versions", "hello.md")); err != nil {
+		t.Errorf("flat version history missing: %v", err)
+	}
+
+	// Same title again: deterministic suffix
+	again, err := PublishPostWithOptions(dataDir, "# Hello\n", PublishOptions{PathFormat: PathFlat}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Path != "posts/hello-2.md" {
+		t.Errorf("collision path = %q, want posts/hello-2.md", again.Path)
+	}
+
+	ym, err := PublishPostWithOptions(dataDir, "# Hello\n", PublishOptions{PathFormat: PathYearMonth, Slug: "greeting.md"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "posts/" + time.Now().UTC().Format("2006/01") + "/greeting.md"
+	if ym.Path != want {
+		t.Errorf("YYYY/MM path = %q, want %q", ym.Path, want)
+	}
+
+	// Republishing appends to the version history next to the post
+	if _, err := RepublishPost(dataDir, ym.Path, "# Hello\n\nEdited.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := os.ReadFile(filepath.Join(dataDir, "posts", time.Now().UTC().Format("2006"), time.Now().UTC().Format("01"), ".versions", "greeting.md"))
+	if err != nil {
+		t.Fatalf("YYYY/MM version history missing: %v", err)
+	}
+	if n := strings.Count(string(versions), "[VERSION "); n != 2 {
+		t.Errorf("version history has %d versions, want 2", n)
+	}
+}
+
+func TestPublishDraft_OwnDraftIsNotACollision(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeDraft(t, dataDir, "hello", "# Hello\n")
+	writeDraft(t, dataDir, "other", "---\nslug: hello\n---\n# Something Else\n")
+
+	result, err := PublishDraft(dataDir, "hello", "", "hello", true, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(result.Path, "/hello.md") {
+		t.Errorf("path = %q, want .../hello.md", result.Path)
+	}
+
+	// Another draft asking for the same slug gets the next suffix
+	result, err = PublishDraft(dataDir, "other", "", "", false, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(result.Path, "/hello-2.md") {
+		t.Errorf("path = %q, want .../hello-2.md", result.Path)
+	}
+}
@@ -0,0 +1,116 @@
+package publish
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SummaryMaxLength is the longest generated summary, in characters.
+const SummaryMaxLength = 200
+
+var (
+	summaryImagePattern    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	summaryLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	summaryWikiLabel       = regexp.MustCompile(`\[\[[^\]|]+\|([^\]]+)\]\]`)
+	summaryWikiLink        = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+	summaryHTMLTag         = regexp.MustCompile(`<[^>]+>`)
+	summaryEmphasis        = regexp.MustCompile("\\*+|~~|`+")
+	summaryUnderscore      = regexp.MustCompile(`(^|[\s(])_+|_+([\s).,;:!?]|$)`)
+	summaryListMarker      = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+	summaryHorizontalRule  = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	summaryWhitespaceRuns  = regexp.MustCompile(`\s+`)
+	summaryReferenceMarker = regexp.MustCompile(`^\s*\[[^\]]+\]:\s`)
+)
+
+// FrontmatterSummary returns the summary field of a source file's
+// frontmatter, or "".
+func FrontmatterSummary(content string) string {
+	if !HasFrontmatter(content) {
+		return ""
+	}
+	return strings.TrimSpace(unquoteYAMLString(ParseFrontmatter(content)["summary"]))
+}
+
+// Summarize returns a plain-text summary of a post body: its first
+// paragraph of prose with markdown formatting removed, cut at a word
+// boundary to at most SummaryMaxLength characters. Headings, code blocks,
+// images, tables, and raw HTML blocks are skipped.
+func Summarize(markdown string) string {
+	if HasFrontmatter(markdown) {
+		markdown = StripFrontmatter(markdown)
+	}
+
+	var para []string
+	fence := ""
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if trimmed == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if len(para) == 0 && skipForSummary(trimmed) {
+			continue
+		}
+		para = append(para, trimmed)
+	}
+
+	text := cleanSummaryText(para)
+	if len([]rune(text)) <= SummaryMaxLength {
+		return text
+	}
+	cut := string([]rune(text)[:SummaryMaxLength])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// skipForSummary reports whether a line starting a block is something other
+// than prose.
+func skipForSummary(line string) bool {
+	switch {
+	case strings.HasPrefix(line, "#"),
+		strings.HasPrefix(line, "<"),
+		strings.HasPrefix(line, "|"),
+		summaryHorizontalRule.MatchString(line),
+		summaryReferenceMarker.MatchString(line):
+		return true
+	}
+	// A line that is only images has no text to summarize
+	return strings.TrimSpace(summaryImagePattern.ReplaceAllString(line, "")) == ""
+}
+
+// cleanSummaryText joins paragraph lines and strips markdown formatting.
+func cleanSummaryText(lines []string) string {
+	for i, line := range lines {
+		line = strings.TrimLeft(line, "> ")
+		lines[i] = summaryListMarker.ReplaceAllString(line, "")
+	}
+	text := strings.Join(lines, " ")
+	text = summaryImagePattern.ReplaceAllString(text, "")
+	text = summaryLinkPattern.ReplaceAllString(text, "$1")
+	text = summaryWikiLabel.ReplaceAllString(text, "$1")
+	text = summaryWikiLink.ReplaceAllString(text, "$1")
+	text = summaryHTMLTag.ReplaceAllString(text, "")
+	text = summaryEmphasis.ReplaceAllString(text, "")
+	text = summaryUnderscore.ReplaceAllString(text, "$1$2")
+	return strings.TrimSpace(summaryWhitespaceRuns.ReplaceAllString(text, " "))
+}
@@ -0,0 +1,105 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "first paragraph after heading",
+			markdown: "# Title\n\nThe **first** paragraph,\nacross two lines.\n\nThe second.\n",
+			want:     "The first paragraph, across two lines.",
+		},
+		{
+			name:     "links and code stripped",
+			markdown: "# T\n\n![cover](cover.png)\n\nSee [the docs](https://example.com), [[other-post|my note]], and `code`.\n",
+			want:     "See the docs, my note, and code.",
+		},
+		{
+			name:     "skips code blocks and html",
+			markdown: "```go\nfmt.Println(\"x\")\n```\n\n<div>raw</div>\n\n_Emphasis_ and snake_case stay readable.\n",
+			want:     "Emphasis and snake_case stay readable.",
+		},
+		{
+			name:     "frontmatter ignored",
+			markdown: "---\ntitle: T\n---\nBody text.\n",
+			want:     "Body text.",
+		},
+		{
+			name:     "headings only",
+			markdown: "# Just a title\n",
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.markdown); got != tt.want {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize_Truncates(t *testing.T) {
+	got := Summarize(strings.Repeat("word ", 100))
+	if !strings.HasSuffix(got, "word…") {
+		t.Errorf("expected a word-boundary cut with an ellipsis, got %q", got)
+	}
+	if n := len([]rune(got)); n > SummaryMaxLength+1 {
+		t.Errorf("summary is %d characters, want at most %d", n, SummaryMaxLength+1)
+	}
+}
+
+func TestPublishPost_Summary(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	generated, err := PublishPost(dataDir, "# Auto\n\nThe opening paragraph.\n\nMore.\n", "", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explicit, err := PublishPostWithOptions(dataDir, "# Explicit\n\nBody.\n", PublishOptions{Summary: "Hand-written summary"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := metadata.LoadPublicIndex(dataDir)
+	summaries := map[string]string{}
+	for _, e := range entries {
+		summaries[e.Path] = e.Summary
+	}
+	if summaries[generated.Path] != "The opening paragraph." {
+		t.Errorf("generated summary = %q", summaries[generated.Path])
+	}
+	if summaries[explicit.Path] != "Hand-written summary" {
+		t.Errorf("explicit summary = %q", summaries[explicit.Path])
+	}
+
+	// Only an explicit summary is written to frontmatter, and it survives a republish
+	content, _ := os.ReadFile(filepath.Join(dataDir, explicit.Path))
+	if !strings.Contains(string(content), "\nsummary: Hand-written summary\n") {
+		t.Errorf("expected summary in frontmatter, got:\n%s", content)
+	}
+	content, _ = os.ReadFile(filepath.Join(dataDir, generated.Path))
+	if strings.Contains(string(content), "summary:") {
+		t.Errorf("generated summary should not be written to frontmatter:\n%s", content)
+	}
+	if _, err := RepublishPost(dataDir, explicit.Path, "# Explicit\n\nNew body.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	fm, _ := ReadFrontmatter(dataDir, explicit.Path)
+	if fm.Summary != "Hand-written summary" {
+		t.Errorf("summary after republish = %q", fm.Summary)
+	}
+}
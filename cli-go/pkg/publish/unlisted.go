@@ -0,0 +1,93 @@
+package publish
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+// Unlisted posts are signed and rendered like any other post, but their
+// index entry lives in .polis/unlisted.jsonl instead of public.jsonl, so
+// they stay off index pages, the public feed, and the discovery service.
+// They are reachable only by direct URL.
+
+// FrontmatterUnlisted reports whether a source file's frontmatter marks the
+// post unlisted, with either "unlisted: true" or "visibility: unlisted".
+func FrontmatterUnlisted(content string) bool {
+	if !HasFrontmatter(content) {
+		return false
+	}
+	return isUnlisted(ParseFrontmatter(content))
+}
+
+// isUnlisted reports whether parsed frontmatter fields mark a post unlisted.
+func isUnlisted(fm map[string]string) bool {
+	if strings.EqualFold(unquoteYAMLString(fm["visibility"]), VisibilityUnlisted) {
+		return true
+	}
+	return strings.EqualFold(unquoteYAMLString(fm["unlisted"]), "true")
+}
+
+// ProtectedDir is the site-root directory followers-only posts are
+// published to. It is never rendered or deployed; polis serve hands its
+// files only to followers who sign the request (see package protected).
+const ProtectedDir = "protected"
+
+// FrontmatterFollowersOnly reports whether a source file's frontmatter marks
+// the post followers-only ("visibility: followers").
+func FrontmatterFollowersOnly(content string) bool {
+	if !HasFrontmatter(content) {
+		return false
+	}
+	return isFollowersOnly(ParseFrontmatter(content))
+}
+
+// isFollowersOnly reports whether parsed frontmatter fields mark a post followers-only.
+func isFollowersOnly(fm map[string]string) bool {
+	return strings.EqualFold(unquoteYAMLString(fm["visibility"]), VisibilityFollowers)
+}
+
+// FrontmatterVisibility returns the visibility a source file's frontmatter
+// gives the post: VisibilityFollowers, VisibilityUnlisted, or
+// VisibilityPublic, classified as publishing indexes it.
+func FrontmatterVisibility(content string) string {
+	if !HasFrontmatter(content) {
+		return VisibilityPublic
+	}
+	fm := ParseFrontmatter(content)
+	switch {
+	case isFollowersOnly(fm):
+		return VisibilityFollowers
+	case isUnlisted(fm):
+		return VisibilityUnlisted
+	}
+	return VisibilityPublic
+}
+
+// IsProtectedPath reports whether a site-relative path is under ProtectedDir.
+func IsProtectedPath(relPath string) bool {
+	return strings.HasPrefix(filepath.ToSlash(relPath), ProtectedDir+"/")
+}
+
+// keptOffIndex reports whether a post with the given visibility is indexed
+// in .polis/unlisted.jsonl rather than public.jsonl.
+func keptOffIndex(visibility string) bool {
+	return visibility == VisibilityUnlisted || visibility == VisibilityFollowers
+}
+
+// updateIndex writes meta to the index matching the post's visibility and
+// removes it from the other, so toggling visibility moves the entry. A
+// public entry keeps its position in public.jsonl.
+func updateIndex(dataDir string, meta *PostMeta, unlisted bool) error {
+	if unlisted {
+		if err := metadata.RemoveIndexEntry(dataDir, meta.Path); err != nil {
+			return err
+		}
+		return metadata.AppendToUnlistedIndex(dataDir, meta.indexEntry())
+	}
+	if err := metadata.RemoveUnlistedEntry(dataDir, meta.Path); err != nil {
+		return err
+	}
+	return AppendToIndex(dataDir, meta)
+}
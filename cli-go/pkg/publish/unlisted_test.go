@@ -0,0 +1,112 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestFrontmatterUnlisted(t *testing.T) {
+	for content, want := range map[string]bool{
+		"---\nunlisted: true\n---\n# Hi\n":           true,
+		"---\nvisibility: unlisted\n---\n# Hi\n":     true,
+		"---\nunlisted: false\n---\n# Hi\n":          false,
+		"---\nvisibility: followers\n---\n# Hi\n":    false,
+		"# Hi\n\nunlisted: true\n":                   false,
+		"---\nvisibility: \"Unlisted\"\n---\n# Hi\n": true,
+	} {
+		if got := FrontmatterUnlisted(content); got != want {
+			t.Errorf("FrontmatterUnlisted(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestFrontmatterVisibility(t *testing.T) {
+	for content, want := range map[string]string{
+		"# Hi\n":                                     VisibilityPublic,
+		"---\ntitle: Hi\n---\n# Hi\n":                VisibilityPublic,
+		"---\nunlisted: true\n---\n# Hi\n":           VisibilityUnlisted,
+		"---\nvisibility: \"Unlisted\"\n---\n# Hi\n": VisibilityUnlisted,
+		"---\nvisibility: followers\n---\n# Hi\n":    VisibilityFollowers,
+		"---\nvisibility: bogus\n---\n# Hi\n":        VisibilityPublic,
+	} {
+		if got := FrontmatterVisibility(content); got != want {
+			t.Errorf("FrontmatterVisibility(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+// indexPaths returns the paths in the index loaded by load.
+func indexPaths(t *testing.T, load func(string) ([]metadata.IndexEntry, error), dataDir string) []string {
+	t.Helper()
+	entries, err := load(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestPublishPost_Unlisted(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	result, err := PublishPostWithOptions(dataDir, "# Secret\n\nFor friends.\n", SourceOptions("---\nunlisted: true\n---\n"), privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Unlisted {
+		t.Error("expected result.Unlisted")
+	}
+	content, _ := os.ReadFile(filepath.Join(dataDir, result.Path))
+	if !strings.Contains(string(content), "\nvisibility: unlisted\n") {
+		t.Errorf("expected visibility: unlisted in signed frontmatter, got:\n%s", content)
+	}
+	if paths := indexPaths(t, metadata.LoadPublicIndex, dataDir); len(paths) != 0 {
+		t.Errorf("unlisted post should not be in public.jsonl, got %v", paths)
+	}
+	if paths := indexPaths(t, metadata.LoadUnlistedIndex, dataDir); len(paths) != 1 || paths[0] != result.Path {
+		t.Errorf("unlisted.jsonl = %v, want [%s]", paths, result.Path)
+	}
+
+	// Republishing keeps it unlisted
+	if _, err := RepublishPost(dataDir, result.Path, "# Secret\n\nStill for friends.\n", privKey); err != nil {
+		t.Fatal(err)
+	}
+	if paths := indexPaths(t, metadata.LoadPublicIndex, dataDir); len(paths) != 0 {
+		t.Errorf("republished unlisted post should not be in public.jsonl, got %v", paths)
+	}
+
+	// Making it public moves the entry to public.jsonl, and back again
+	public := VisibilityPublic
+	if _, err := UpdateFrontmatter(dataDir, result.Path, FrontmatterPatch{Visibility: &public}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := metadata.LoadPublicIndex(dataDir)
+	if len(entries) != 1 || entries[0].Path != result.Path {
+		t.Errorf("public.jsonl = %+v, want one entry for %s", entries, result.Path)
+	} else if entries[0].Title != "Secret" || entries[0].Published == "" || entries[0].Type != "post" {
+		t.Errorf("incomplete public entry: %+v", entries[0])
+	}
+	if paths := indexPaths(t, metadata.LoadUnlistedIndex, dataDir); len(paths) != 0 {
+		t.Errorf("unlisted.jsonl should be empty, got %v", paths)
+	}
+
+	unlisted := VisibilityUnlisted
+	if _, err := UpdateFrontmatter(dataDir, result.Path, FrontmatterPatch{Visibility: &unlisted}, privKey); err != nil {
+		t.Fatal(err)
+	}
+	if paths := indexPaths(t, metadata.LoadPublicIndex, dataDir); len(paths) != 0 {
+		t.Errorf("public.jsonl should be empty, got %v", paths)
+	}
+	if paths := indexPaths(t, metadata.LoadUnlistedIndex, dataDir); len(paths) != 1 {
+		t.Errorf("unlisted.jsonl = %v, want one entry", paths)
+	}
+}
@@ -0,0 +1,466 @@
+// Package reconcile compares local blessing, comment, and follow state with
+// the discovery service's view and optionally repairs divergence.
+//
+// Local files are authoritative for decisions this site made (blessings it
+// granted, authors it follows); the discovery service is authoritative for
+// decisions other sites made (whether our comments were blessed). Anything
+// that cannot be repaired safely in one direction is reported only.
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// Divergence kinds.
+const (
+	// A blessing granted on the discovery service is missing from blessed-comments.json.
+	KindBlessingMissingLocally = "blessing_missing_locally"
+	// A comment in blessed-comments.json is not granted on the discovery service.
+	KindBlessingMissingRemotely = "blessing_missing_remotely"
+	// One of our comments is filed under a different status than the discovery service reports.
+	KindCommentStatus = "comment_status"
+	// One of our pending comments has no blessing request on the discovery service.
+	KindCommentNotRegistered = "comment_not_registered"
+	// An author in following.json has no follow announcement on the discovery stream.
+	KindFollowNotAnnounced = "follow_not_announced"
+	// The discovery stream still shows a follow for an author we no longer follow.
+	KindUnfollowNotAnnounced = "unfollow_not_announced"
+)
+
+// ReportFile is where the last report is stored, relative to the site directory.
+const ReportFile = ".polis/reconcile.json"
+
+// Divergence is a single mismatch between local and discovery state.
+type Divergence struct {
+	Kind       string `json:"kind"`
+	URL        string `json:"url"`
+	Target     string `json:"target,omitempty"`
+	Local      string `json:"local"`
+	Remote     string `json:"remote"`
+	Repairable bool   `json:"repairable"`
+	Repaired   bool   `json:"repaired,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the result of a reconciliation run.
+type Report struct {
+	CheckedAt   string       `json:"checked_at"`
+	Repair      bool         `json:"repair"`
+	Checked     Counts       `json:"checked"`
+	Divergences []Divergence `json:"divergences"`
+	Repaired    int          `json:"repaired"`
+	Errors      []string     `json:"errors"`
+}
+
+// Counts records how many local items of each kind were compared.
+type Counts struct {
+	Blessings int `json:"blessings"`
+	Comments  int `json:"comments"`
+	Follows   int `json:"follows"`
+}
+
+// Options configures a reconciliation run.
+type Options struct {
+	DataDir      string
+	BaseURL      string
+	DiscoveryURL string
+	DiscoveryKey string
+	PrivateKey   []byte
+	HookConfig   *hooks.HookConfig
+
+	// Repair applies fixes for repairable divergences.
+	Repair bool
+}
+
+// Run compares local state with the discovery service. Individual check
+// failures are collected in Report.Errors; an error is only returned when
+// the run cannot start at all.
+func Run(opts Options) (*Report, error) {
+	if opts.DiscoveryURL == "" || opts.DiscoveryKey == "" {
+		return nil, fmt.Errorf("discovery service not configured")
+	}
+	domain := discovery.ExtractDomainFromURL(opts.BaseURL)
+	if domain == "" {
+		return nil, fmt.Errorf("POLIS_BASE_URL not configured")
+	}
+	if opts.Repair && opts.PrivateKey == nil {
+		return nil, fmt.Errorf("private key required to repair")
+	}
+
+	r := &reconciler{
+		opts:   opts,
+		domain: domain,
+		client: discovery.NewAuthenticatedClient(opts.DiscoveryURL, opts.DiscoveryKey, domain, opts.PrivateKey),
+		report: &Report{
+			CheckedAt:   time.Now().UTC().Format(time.RFC3339),
+			Repair:      opts.Repair,
+			Divergences: []Divergence{},
+			Errors:      []string{},
+		},
+	}
+
+	r.checkBlessings()
+	r.checkComments()
+	r.checkFollows()
+
+	for _, d := range r.report.Divergences {
+		if d.Repaired {
+			r.report.Repaired++
+		}
+	}
+	return r.report, nil
+}
+
+type reconciler struct {
+	opts   Options
+	domain string
+	client *discovery.Client
+	report *Report
+}
+
+func (r *reconciler) errorf(format string, args ...interface{}) {
+	r.report.Errors = append(r.report.Errors, fmt.Sprintf(format, args...))
+}
+
+// add records a divergence, running fix first when repairing.
+func (r *reconciler) add(d Divergence, fix func() error) {
+	d.Repairable = fix != nil
+	if r.opts.Repair && fix != nil {
+		if err := fix(); err != nil {
+			d.Error = err.Error()
+		} else {
+			d.Repaired = true
+		}
+	}
+	r.report.Divergences = append(r.report.Divergences, d)
+}
+
+// blessingStatus returns the discovery status of a single blessing, or ""
+// if the discovery service has no record of it.
+func (r *reconciler) blessingStatus(commentURL, targetURL string) (string, error) {
+	resp, err := r.client.QueryRelationships("polis.blessing", map[string]string{
+		"source_url": commentURL,
+		"target_url": targetURL,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Records) == 0 {
+		return "", nil
+	}
+	return resp.Records[0].Status, nil
+}
+
+// checkBlessings compares blessed-comments.json (comments we blessed on our
+// posts) with the blessings the discovery service has recorded as granted.
+func (r *reconciler) checkBlessings() {
+	local, err := metadata.LoadBlessedComments(r.opts.DataDir)
+	if err != nil {
+		r.errorf("blessings: %v", err)
+		return
+	}
+	granted, err := r.client.QueryRelationships("polis.blessing", map[string]string{
+		"actor":  r.domain,
+		"status": "granted",
+	})
+	if err != nil {
+		r.errorf("blessings: %v", err)
+		return
+	}
+
+	// Only blessings on our own posts; the actor filter can also match
+	// records where we are the commenter.
+	var mine []discovery.RelationshipRecord
+	remote := make(map[string]bool)
+	for _, rel := range granted.Records {
+		if discovery.ExtractDomainFromURL(rel.TargetURL) != r.domain {
+			continue
+		}
+		mine = append(mine, rel)
+		remote[rel.SourceURL] = true
+	}
+
+	localURLs := make(map[string]bool)
+	baseURL := strings.TrimSuffix(r.opts.BaseURL, "/")
+	for _, post := range local.Comments {
+		postURL := post.Post
+		if !strings.HasPrefix(postURL, "http") {
+			postURL = baseURL + "/" + strings.TrimPrefix(postURL, "/")
+		}
+		for _, bc := range post.Blessed {
+			localURLs[bc.URL] = true
+			r.report.Checked.Blessings++
+			if remote[bc.URL] {
+				continue
+			}
+
+			// Not in the granted list; look it up directly before reporting
+			status, err := r.blessingStatus(bc.URL, postURL)
+			if err != nil {
+				r.errorf("blessing %s: %v", bc.URL, err)
+				continue
+			}
+			if status == "granted" {
+				continue
+			}
+			d := Divergence{
+				Kind:   KindBlessingMissingRemotely,
+				URL:    bc.URL,
+				Target: postURL,
+				Local:  "granted",
+				Remote: statusOrMissing(status),
+			}
+			// A denial on the service may be a later decision made elsewhere,
+			// so only re-send grants that were lost (no record or still pending).
+			var fix func() error
+			if status == "" || status == "pending" {
+				commentURL, target := bc.URL, postURL
+				fix = func() error {
+					return r.client.UpdateRelationship("polis.blessing", commentURL, target, "grant", r.opts.PrivateKey)
+				}
+			}
+			r.add(d, fix)
+		}
+	}
+
+	for _, rel := range mine {
+		if localURLs[rel.SourceURL] {
+			continue
+		}
+		rel := rel
+		r.add(Divergence{
+			Kind:   KindBlessingMissingLocally,
+			URL:    rel.SourceURL,
+			Target: rel.TargetURL,
+			Local:  "missing",
+			Remote: "granted",
+		}, func() error {
+			version, _ := rel.Metadata["comment_version"].(string)
+			return metadata.AddBlessedComment(r.opts.DataDir, postPath(rel.TargetURL), metadata.BlessedComment{
+				URL:       rel.SourceURL,
+				Version:   version,
+				BlessedAt: rel.UpdatedAt,
+			})
+		})
+	}
+}
+
+// checkComments compares our own pending and denied comments with the
+// blessing status the target site recorded on the discovery service.
+func (r *reconciler) checkComments() {
+	for _, status := range []string{comment.StatusPending, comment.StatusDenied} {
+		comments, err := comment.ListComments(r.opts.DataDir, status)
+		if err != nil {
+			r.errorf("comments: %v", err)
+			continue
+		}
+		for _, c := range comments {
+			commentURL := r.commentURL(c)
+			if commentURL == "" || c.InReplyTo == "" {
+				continue
+			}
+			r.report.Checked.Comments++
+
+			remote, err := r.blessingStatus(commentURL, c.InReplyTo)
+			if err != nil {
+				r.errorf("comment %s: %v", c.ID, err)
+				continue
+			}
+			if remote == status {
+				continue
+			}
+
+			d := Divergence{
+				Kind:   KindCommentStatus,
+				URL:    commentURL,
+				Target: c.InReplyTo,
+				Local:  status,
+				Remote: statusOrMissing(remote),
+			}
+			id := c.ID
+			var fix func() error
+			switch {
+			case status == comment.StatusPending && remote == "":
+				d.Kind = KindCommentNotRegistered
+				fix = func() error {
+					_, err := comment.BeseechComment(r.opts.DataDir, id, r.opts.PrivateKey, &comment.DiscoveryConfig{
+						DiscoveryURL: r.opts.DiscoveryURL,
+						DiscoveryKey: r.opts.DiscoveryKey,
+						BaseURL:      r.opts.BaseURL,
+					})
+					return err
+				}
+			case status == comment.StatusPending:
+				fix = func() error {
+					_, err := comment.SyncSingleComment(r.opts.DataDir, r.opts.BaseURL, id, r.client, r.opts.HookConfig)
+					return err
+				}
+			case status == comment.StatusDenied && remote == "granted":
+				fix = func() error {
+					return comment.MoveComment(r.opts.DataDir, id, comment.StatusDenied, comment.StatusBlessed)
+				}
+			}
+			r.add(d, fix)
+		}
+	}
+}
+
+// commentURL returns a comment's public URL, reconstructing it from the
+// base URL and publish date when the frontmatter has no comment_url.
+func (r *reconciler) commentURL(c *comment.CommentMeta) string {
+	if c.CommentURL != "" {
+		return c.CommentURL
+	}
+	if r.opts.BaseURL == "" {
+		return ""
+	}
+	ts, err := time.Parse("2006-01-02T15:04:05Z", c.Timestamp)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(r.opts.BaseURL, "/") + "/comments/" + ts.Format("20060102") + "/" + c.ID + ".md"
+}
+
+// checkFollows compares following.json with the latest follow/unfollow
+// announcement we published for each author.
+func (r *reconciler) checkFollows() {
+	f, err := following.Load(following.DefaultPath(r.opts.DataDir))
+	if err != nil {
+		r.errorf("follows: %v", err)
+		return
+	}
+
+	announced, err := r.announcedFollows()
+	if err != nil {
+		r.errorf("follows: %v", err)
+		return
+	}
+
+	dsCfg := &stream.DiscoveryConfig{
+		DiscoveryURL: r.opts.DiscoveryURL,
+		DiscoveryKey: r.opts.DiscoveryKey,
+		BaseURL:      r.opts.BaseURL,
+	}
+	localDomains := make(map[string]bool)
+	for _, entry := range f.All() {
+		target := discovery.ExtractDomainFromURL(entry.URL)
+		if target == "" {
+			continue
+		}
+		localDomains[target] = true
+		r.report.Checked.Follows++
+		if announced[target] {
+			continue
+		}
+		r.add(Divergence{
+			Kind:   KindFollowNotAnnounced,
+			URL:    entry.URL,
+			Local:  "following",
+			Remote: "missing",
+		}, func() error {
+			return stream.PublishEvent("polis.follow.announced", map[string]interface{}{
+				"target_domain": target,
+			}, r.opts.PrivateKey, dsCfg)
+		})
+	}
+
+	for target, following := range announced {
+		if !following || localDomains[target] {
+			continue
+		}
+		target := target
+		r.add(Divergence{
+			Kind:   KindUnfollowNotAnnounced,
+			URL:    "https://" + target,
+			Local:  "not following",
+			Remote: "following",
+		}, func() error {
+			return stream.PublishEvent("polis.follow.removed", map[string]interface{}{
+				"target_domain": target,
+			}, r.opts.PrivateKey, dsCfg)
+		})
+	}
+}
+
+// announcedFollows replays our own follow events and returns the latest
+// state per target domain (true = followed).
+func (r *reconciler) announcedFollows() (map[string]bool, error) {
+	state := make(map[string]bool)
+	types := discovery.JoinDomains([]string{"polis.follow.announced", "polis.follow.removed"})
+	cursor := ""
+	for {
+		resp, err := r.client.StreamQuery(cursor, 1000, types, r.domain, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, evt := range resp.Events {
+			target, _ := evt.Payload["target_domain"].(string)
+			if target == "" {
+				continue
+			}
+			state[target] = evt.Type == "polis.follow.announced"
+		}
+		if !resp.HasMore || resp.Cursor == "" || resp.Cursor == cursor {
+			return state, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func statusOrMissing(status string) string {
+	if status == "" {
+		return "missing"
+	}
+	return status
+}
+
+// postPath extracts the relative post path from a full URL.
+// e.g., https://alice.polis.site/posts/20260127/hello.md -> posts/20260127/hello.md
+func postPath(url string) string {
+	if idx := strings.Index(url, "/posts/"); idx >= 0 {
+		return url[idx+1:]
+	}
+	return url
+}
+
+// SaveReport writes a report to .polis/reconcile.json.
+func SaveReport(dataDir string, report *Report) error {
+	path := filepath.Join(dataDir, ReportFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// LoadReport reads the last saved report. Returns nil, nil if none exists.
+func LoadReport(dataDir string) (*Report, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, ReportFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ReportFile, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,199 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+const testBaseURL = "https://me.polis.pub"
+
+// fakeDS serves the discovery endpoints reconcile uses and records writes.
+type fakeDS struct {
+	mu        sync.Mutex
+	granted   []discovery.RelationshipRecord
+	events    []discovery.StreamEvent
+	updates   []map[string]interface{}
+	published []map[string]interface{}
+}
+
+func (f *fakeDS) handler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.URL.Path {
+	case "/ds-relationship-query":
+		q := r.URL.Query()
+		var records []discovery.RelationshipRecord
+		for _, rec := range f.granted {
+			if src := q.Get("source_url"); src != "" && rec.SourceURL != src {
+				continue
+			}
+			records = append(records, rec)
+		}
+		json.NewEncoder(w).Encode(discovery.RelationshipQueryResponse{Count: len(records), Records: records})
+	case "/ds-stream":
+		json.NewEncoder(w).Encode(discovery.StreamQueryResponse{Events: f.events})
+	case "/ds-relationship-update", "/ds-stream-publish":
+		body, _ := io.ReadAll(r.Body)
+		var m map[string]interface{}
+		json.Unmarshal(body, &m)
+		if r.URL.Path == "/ds-relationship-update" {
+			f.updates = append(f.updates, m)
+		} else {
+			f.published = append(f.published, m)
+		}
+		w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func setupSite(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "metadata"), 0755)
+	if err := metadata.InitBlessedComments(dir, "test"); err != nil {
+		t.Fatal(err)
+	}
+	// Blessed locally and on the service
+	metadata.AddBlessedComment(dir, "posts/20260101/hello.md", metadata.BlessedComment{URL: "https://alice.polis.pub/comments/20260102/a.md", Version: "sha256:aaa"})
+	// Blessed locally, grant never reached the service
+	metadata.AddBlessedComment(dir, "posts/20260101/hello.md", metadata.BlessedComment{URL: "https://bob.polis.pub/comments/20260103/b.md", Version: "sha256:bbb"})
+
+	f, _ := following.Load(following.DefaultPath(dir))
+	f.Add("https://alice.polis.pub")
+	if err := following.Save(following.DefaultPath(dir), f); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func newFakeDS(t *testing.T) (*fakeDS, *httptest.Server) {
+	ds := &fakeDS{
+		granted: []discovery.RelationshipRecord{
+			{Type: "polis.blessing", SourceURL: "https://alice.polis.pub/comments/20260102/a.md", TargetURL: testBaseURL + "/posts/20260101/hello.md", Status: "granted"},
+			// Granted on the service, missing from blessed-comments.json
+			{Type: "polis.blessing", SourceURL: "https://carol.polis.pub/comments/20260104/c.md", TargetURL: testBaseURL + "/posts/20260101/hello.md", Status: "granted", UpdatedAt: "2026-01-04T00:00:00Z",
+				Metadata: map[string]interface{}{"comment_version": "sha256:ccc"}},
+			// Our own comment on someone else's post; not ours to reconcile here
+			{Type: "polis.blessing", SourceURL: testBaseURL + "/comments/20260105/mine.md", TargetURL: "https://dave.polis.pub/posts/x.md", Status: "granted"},
+		},
+		events: []discovery.StreamEvent{
+			{Type: "polis.follow.announced", Actor: "me.polis.pub", Payload: map[string]interface{}{"target_domain": "erin.polis.pub"}},
+			{Type: "polis.follow.announced", Actor: "me.polis.pub", Payload: map[string]interface{}{"target_domain": "frank.polis.pub"}},
+			{Type: "polis.follow.removed", Actor: "me.polis.pub", Payload: map[string]interface{}{"target_domain": "frank.polis.pub"}},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(ds.handler))
+	t.Cleanup(srv.Close)
+	return ds, srv
+}
+
+func kinds(report *Report) map[string][]Divergence {
+	m := make(map[string][]Divergence)
+	for _, d := range report.Divergences {
+		m[d.Kind] = append(m[d.Kind], d)
+	}
+	return m
+}
+
+func TestRun_ReportOnly(t *testing.T) {
+	dir := setupSite(t)
+	ds, srv := newFakeDS(t)
+
+	report, err := Run(Options{DataDir: dir, BaseURL: testBaseURL, DiscoveryURL: srv.URL, DiscoveryKey: "k"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+
+	got := kinds(report)
+	if d := got[KindBlessingMissingRemotely]; len(d) != 1 || d[0].URL != "https://bob.polis.pub/comments/20260103/b.md" || d[0].Remote != "missing" {
+		t.Errorf("blessing_missing_remotely = %+v", d)
+	}
+	if d := got[KindBlessingMissingLocally]; len(d) != 1 || d[0].URL != "https://carol.polis.pub/comments/20260104/c.md" {
+		t.Errorf("blessing_missing_locally = %+v", d)
+	}
+	if d := got[KindFollowNotAnnounced]; len(d) != 1 || d[0].URL != "https://alice.polis.pub" {
+		t.Errorf("follow_not_announced = %+v", d)
+	}
+	if d := got[KindUnfollowNotAnnounced]; len(d) != 1 || d[0].URL != "https://erin.polis.pub" {
+		t.Errorf("unfollow_not_announced = %+v", d)
+	}
+	if report.Repaired != 0 || len(ds.updates) != 0 || len(ds.published) != 0 {
+		t.Errorf("report-only run should not write: repaired=%d updates=%d published=%d", report.Repaired, len(ds.updates), len(ds.published))
+	}
+	if report.Checked.Blessings != 2 || report.Checked.Follows != 1 {
+		t.Errorf("checked = %+v", report.Checked)
+	}
+}
+
+func TestRun_Repair(t *testing.T) {
+	dir := setupSite(t)
+	ds, srv := newFakeDS(t)
+	priv, _, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{DataDir: dir, BaseURL: testBaseURL, DiscoveryURL: srv.URL, DiscoveryKey: "k", PrivateKey: priv, Repair: true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Repaired != 4 {
+		t.Errorf("repaired = %d, want 4 (%+v)", report.Repaired, report.Divergences)
+	}
+
+	if len(ds.updates) != 1 || ds.updates[0]["source_url"] != "https://bob.polis.pub/comments/20260103/b.md" || ds.updates[0]["action"] != "grant" {
+		t.Errorf("updates = %v", ds.updates)
+	}
+	if len(ds.published) != 2 {
+		t.Errorf("published = %v", ds.published)
+	}
+
+	blessed, err := metadata.IsBlessedComment(dir, "https://carol.polis.pub/comments/20260104/c.md")
+	if err != nil || !blessed {
+		t.Errorf("expected carol's comment to be added locally (err=%v)", err)
+	}
+}
+
+func TestRun_RequiresConfig(t *testing.T) {
+	if _, err := Run(Options{DataDir: t.TempDir(), BaseURL: testBaseURL}); err == nil {
+		t.Error("expected error without discovery config")
+	}
+	if _, err := Run(Options{DataDir: t.TempDir(), DiscoveryURL: "http://x", DiscoveryKey: "k"}); err == nil {
+		t.Error("expected error without base URL")
+	}
+	if _, err := Run(Options{DataDir: t.TempDir(), BaseURL: testBaseURL, DiscoveryURL: "http://x", DiscoveryKey: "k", Repair: true}); err == nil {
+		t.Error("expected error repairing without a key")
+	}
+}
+
+func TestSaveLoadReport(t *testing.T) {
+	dir := t.TempDir()
+
+	if r, err := LoadReport(dir); err != nil || r != nil {
+		t.Fatalf("LoadReport on empty dir = %v, %v", r, err)
+	}
+
+	in := &Report{CheckedAt: "2026-01-01T00:00:00Z", Divergences: []Divergence{{Kind: KindFollowNotAnnounced, URL: "https://a.pub"}}, Errors: []string{}}
+	if err := SaveReport(dir, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := LoadReport(dir)
+	if err != nil || out == nil || len(out.Divergences) != 1 || out.Divergences[0].URL != "https://a.pub" {
+		t.Errorf("LoadReport = %+v, %v", out, err)
+	}
+}
@@ -0,0 +1,176 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Content cache defaults.
+const (
+	DefaultContentCacheBytes = 50 << 20 // 50MB
+	// ContentCacheTTL is how long a cached post is served without
+	// refetching. Older entries are still served when the author's site
+	// can't be reached.
+	ContentCacheTTL = 24 * time.Hour
+	// MaxPrefetchItems caps how many items one Prefetch call fetches.
+	MaxPrefetchItems = 50
+)
+
+// ContentCache keeps the markdown source of remote posts and comments on
+// disk so they can be read instantly and offline. Entries are evicted least
+// recently used first once the cache exceeds MaxBytes.
+type ContentCache struct {
+	Dir      string // Cache directory (e.g. .polis/cache/content)
+	MaxBytes int64  // Total cache size before eviction (0 = DefaultContentCacheBytes)
+	Client   *Client
+
+	mu sync.Mutex
+}
+
+// CachedContent is a cached remote document.
+type CachedContent struct {
+	URL        string `json:"url"`         // URL that was requested
+	FetchedURL string `json:"fetched_url"` // URL the content came from (may differ in extension)
+	FetchedAt  string `json:"fetched_at"`
+	Content    string `json:"-"`
+}
+
+// Fresh reports whether the entry is younger than ContentCacheTTL.
+func (cc *CachedContent) Fresh() bool {
+	fetched, err := time.Parse(time.RFC3339, cc.FetchedAt)
+	return err == nil && time.Since(fetched) < ContentCacheTTL
+}
+
+// ContentCacheDir returns the content cache directory for a site (.polis/cache/content).
+func ContentCacheDir(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "cache", "content")
+}
+
+// NewContentCache creates a content cache rooted at dir.
+func NewContentCache(dir string) *ContentCache {
+	return &ContentCache{Dir: dir, Client: NewClient()}
+}
+
+// Get returns the cached entry for url, fresh or not, or nil.
+func (c *ContentCache) Get(url string) *CachedContent {
+	key := contentCacheKey(url)
+	metaPath := filepath.Join(c.Dir, key+".json")
+	metaData, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var cc CachedContent
+	if err := json.Unmarshal(metaData, &cc); err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.Dir, key+".md"))
+	if err != nil {
+		return nil
+	}
+	cc.Content = string(data)
+	// Touch so eviction treats this entry as recently used
+	now := time.Now()
+	os.Chtimes(metaPath, now, now)
+	return &cc
+}
+
+// Fetch fetches url's source (see Client.FetchSource) and caches it.
+func (c *ContentCache) Fetch(url string) (*CachedContent, error) {
+	content, fetchedURL, err := c.Client.FetchSource(url)
+	if err != nil {
+		return nil, err
+	}
+	return c.Put(url, fetchedURL, content), nil
+}
+
+// Put stores content fetched for url.
+func (c *ContentCache) Put(url, fetchedURL, content string) *CachedContent {
+	cc := &CachedContent{
+		URL:        url,
+		FetchedURL: fetchedURL,
+		FetchedAt:  time.Now().UTC().Format(time.RFC3339),
+		Content:    content,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return cc
+	}
+	key := contentCacheKey(url)
+	meta, _ := json.Marshal(cc)
+	body := filepath.Join(c.Dir, key+".md")
+	if err := fsutil.WriteFile(body, []byte(content), 0644); err != nil {
+		return cc
+	}
+	// A body without its metadata is never served but counts toward MaxBytes
+	if err := fsutil.WriteFile(filepath.Join(c.Dir, key+".json"), meta, 0644); err != nil {
+		os.Remove(body)
+		return cc
+	}
+	c.evict()
+	return cc
+}
+
+// Prefetch fetches each HTTPS URL that has no fresh cache entry, up to
+// MaxPrefetchItems. Failures are skipped. Returns the number fetched.
+func (c *ContentCache) Prefetch(urls []string) int {
+	fetched := 0
+	for _, url := range urls {
+		if fetched >= MaxPrefetchItems {
+			break
+		}
+		if !strings.HasPrefix(url, "https://") {
+			continue
+		}
+		if cc := c.Get(url); cc != nil && cc.Fresh() {
+			continue
+		}
+		if _, err := c.Fetch(url); err == nil {
+			fetched++
+		}
+	}
+	return fetched
+}
+
+// Size returns the number of entries and their total size in bytes.
+func (c *ContentCache) Size() (int, int64) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return 0, 0
+	}
+	count, total := 0, int64(0)
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			count++
+			total += info.Size()
+		}
+	}
+	return count, total
+}
+
+func contentCacheKey(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:])
+}
+
+// evict removes least recently used entries until the cache fits MaxBytes.
+func (c *ContentCache) evict() {
+	limit := c.MaxBytes
+	if limit <= 0 {
+		limit = DefaultContentCacheBytes
+	}
+	evictLRU(c.Dir, ".md", limit)
+}
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentCache_PrefetchPrefersSourceAndSkipsFresh(t *testing.T) {
+	hits := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/posts/a.html":
+			w.Write([]byte("<!DOCTYPE html><html><body>page</body></html>"))
+		case "/posts/a.md":
+			w.Write([]byte("# Source"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &ContentCache{Dir: t.TempDir(), Client: &Client{HTTPClient: srv.Client()}}
+	url := srv.URL + "/posts/a.html"
+
+	if n := c.Prefetch([]string{url, srv.URL + "/posts/missing.md", "http://insecure.example/p.md"}); n != 1 {
+		t.Fatalf("Prefetch fetched %d, want 1", n)
+	}
+	cc := c.Get(url)
+	if cc == nil || cc.Content != "# Source" || cc.FetchedURL != srv.URL+"/posts/a.md" || !cc.Fresh() {
+		t.Fatalf("cached = %+v, want fresh markdown source", cc)
+	}
+
+	before := hits
+	if n := c.Prefetch([]string{url}); n != 0 || hits != before {
+		t.Errorf("second Prefetch fetched %d (%d requests), want fresh entry skipped", n, hits-before)
+	}
+	if count, size := c.Size(); count != 1 || size != int64(len("# Source")) {
+		t.Errorf("Size = %d, %d", count, size)
+	}
+}
+
+func TestContentCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := &ContentCache{Dir: t.TempDir(), MaxBytes: 25}
+	c.Put("https://a.example/old.md", "https://a.example/old.md", strings.Repeat("o", 10))
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(c.Dir, contentCacheKey("https://a.example/old.md")+".json"), old, old)
+	c.Put("https://a.example/mid.md", "https://a.example/mid.md", strings.Repeat("m", 10))
+	c.Put("https://a.example/new.md", "https://a.example/new.md", strings.Repeat("n", 10))
+
+	if c.Get("https://a.example/old.md") != nil {
+		t.Error("expected least recently used entry evicted")
+	}
+	if c.Get("https://a.example/mid.md") == nil || c.Get("https://a.example/new.md") == nil {
+		t.Error("expected recent entries kept")
+	}
+}
@@ -8,6 +8,12 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/dnskey"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
 )
 
 // Client is an HTTP client for fetching remote content.
@@ -15,12 +21,20 @@ type Client struct {
 	HTTPClient *http.Client
 }
 
-// NewClient creates a new remote content client.
+// NewClient creates a new remote content client. Remote URLs are
+// user-supplied, so it uses a safehttp client (public HTTPS addresses only).
+// Requests go through DefaultHTTPCache when one is configured.
 func NewClient() *Client {
+	c := newUncachedClient()
+	if DefaultHTTPCache != nil {
+		c.HTTPClient.Transport = DefaultHTTPCache.Wrap(c.HTTPClient.Transport)
+	}
+	return c
+}
+
+func newUncachedClient() *Client {
 	return &Client{
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		HTTPClient: safehttp.NewClient(safehttp.Options{Timeout: 30 * time.Second}),
 	}
 }
 
@@ -35,6 +49,8 @@ type WellKnown struct {
 	SiteTitle  string `json:"site_title,omitempty"`
 	BaseURL    string `json:"base_url,omitempty"`
 	Config     Config `json:"config,omitempty"`
+	Devices    []delegation.Device `json:"devices,omitempty"`
+	Authors    []coauthor.Author `json:"authors,omitempty"`
 }
 
 // AuthorDomain returns the domain identity for this site.
@@ -144,11 +160,16 @@ func (c *Client) FetchWellKnown(baseURL string) (*WellKnown, error) {
 }
 
 // FetchPublicKey fetches the public key from a site's .well-known/polis file.
+// When the site's domain publishes a key in DNS, the two must match; a
+// mismatch returns an error wrapping dnskey.ErrMismatch.
 func (c *Client) FetchPublicKey(baseURL string) (string, error) {
 	wk, err := c.FetchWellKnown(baseURL)
 	if err != nil {
 		return "", err
 	}
+	if err := dnskey.Verify(baseURL, wk.PublicKey); err != nil {
+		return "", err
+	}
 	return wk.PublicKey, nil
 }
 
@@ -206,6 +227,24 @@ func (c *Client) FetchPublicIndex(baseURL string) ([]PublicIndexEntry, error) {
 	return entries, nil
 }
 
+// FetchBlessedComments fetches and parses the blessed-comments.json index from a site.
+func (c *Client) FetchBlessedComments(baseURL string) (*metadata.BlessedComments, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	url := baseURL + "/metadata/" + metadata.BlessedCommentsFilename
+
+	content, err := c.FetchContent(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blessed-comments.json: %w", err)
+	}
+
+	var bc metadata.BlessedComments
+	if err := json.Unmarshal([]byte(content), &bc); err != nil {
+		return nil, fmt.Errorf("failed to parse blessed-comments.json: %w", err)
+	}
+
+	return &bc, nil
+}
+
 // ExtractBaseURL extracts the base URL (scheme + host) from a full URL.
 func ExtractBaseURL(fullURL string) string {
 	// Find the third slash (after scheme://)
@@ -242,3 +281,27 @@ func (c *Client) TryAlternateExtension(url string) (string, string, error) {
 
 	return content, altURL, nil
 }
+
+// FetchSource fetches a post or comment, preferring its markdown source.
+// If the host serves a rendered HTML page, the alternate extension is tried;
+// when both are HTML the original page is returned. Returns the content and
+// the URL it came from.
+func (c *Client) FetchSource(url string) (string, string, error) {
+	content, err := c.FetchContent(url)
+	if err != nil {
+		return "", "", err
+	}
+	if LooksLikeHTML(content) {
+		if alt, altURL, err := c.TryAlternateExtension(url); err == nil && !LooksLikeHTML(alt) {
+			return alt, altURL, nil
+		}
+	}
+	return content, url, nil
+}
+
+// LooksLikeHTML reports whether content is a full HTML page rather than
+// markdown.
+func LooksLikeHTML(content string) bool {
+	trimmed := strings.ToLower(strings.TrimSpace(content))
+	return strings.HasPrefix(trimmed, "<!doctype") || strings.HasPrefix(trimmed, "<html")
+}
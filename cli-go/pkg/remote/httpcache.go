@@ -0,0 +1,243 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// HTTP cache defaults.
+const (
+	DefaultHTTPCacheBytes = 100 << 20 // 100MB
+	maxHTTPCacheEntry     = 5 << 20   // larger responses are never cached
+
+	// HTTPCacheHeader is set on responses served from the cache after the
+	// server answered 304 Not Modified.
+	HTTPCacheHeader = "X-Polis-Cache"
+)
+
+// DefaultHTTPCache is used by NewClient when set. It is configured from the
+// http_cache_mb setting; nil disables conditional requests.
+var DefaultHTTPCache *HTTPCache
+
+// HTTPCache is an http.RoundTripper that remembers the ETag and
+// Last-Modified of GET responses and revalidates with If-None-Match and
+// If-Modified-Since on the next request. A 304 Not Modified is answered
+// from disk, so unchanged .well-known files, indexes, and posts cost a
+// round trip but no body. Responses without validators are not cached.
+type HTTPCache struct {
+	Dir       string            // Cache directory (e.g. .polis/cache/http)
+	MaxBytes  int64             // Total cache size before eviction (0 = DefaultHTTPCacheBytes)
+	Transport http.RoundTripper // Underlying transport (nil = http.DefaultTransport)
+
+	mu sync.Mutex
+}
+
+type httpCacheMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	StoredAt     string `json:"stored_at"`
+}
+
+// HTTPCacheDir returns the HTTP cache directory for a site (.polis/cache/http).
+func HTTPCacheDir(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "cache", "http")
+}
+
+// NewHTTPCache creates an HTTP cache rooted at dir.
+func NewHTTPCache(dir string, maxBytes int64) *HTTPCache {
+	return &HTTPCache{Dir: dir, MaxBytes: maxBytes}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *HTTPCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.roundTrip(req, c.Transport)
+}
+
+// Wrap returns a RoundTripper that caches through c but sends requests
+// with next instead of c.Transport.
+func (c *HTTPCache) Wrap(next http.RoundTripper) http.RoundTripper {
+	return cachingTransport{cache: c, next: next}
+}
+
+type cachingTransport struct {
+	cache *HTTPCache
+	next  http.RoundTripper
+}
+
+func (t cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.cache.roundTrip(req, t.next)
+}
+
+func (c *HTTPCache) roundTrip(req *http.Request, transport http.RoundTripper) (*http.Response, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" || req.Header.Get("Range") != "" ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	key := httpCacheKey(req.URL.String())
+	meta := c.loadMeta(key)
+	if meta != nil {
+		req = req.Clone(req.Context())
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && meta != nil {
+		if cached := c.cachedResponse(key, meta, req); cached != nil {
+			resp.Body.Close()
+			return cached, nil
+		}
+		return resp, nil
+	}
+
+	if resp.StatusCode == http.StatusOK && cacheable(resp) {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPCacheEntry+1))
+		rest := resp.Body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), rest), rest}
+		if err == nil && len(body) <= maxHTTPCacheEntry {
+			c.store(key, req.URL.String(), resp, body)
+		}
+	}
+	return resp, nil
+}
+
+// Size returns the number of cached responses and their total size in bytes.
+func (c *HTTPCache) Size() (int, int64) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return 0, 0
+	}
+	count, total := 0, int64(0)
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".body") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			count++
+			total += info.Size()
+		}
+	}
+	return count, total
+}
+
+// cacheable reports whether a 200 response has validators and may be stored.
+func cacheable(resp *http.Response) bool {
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	return !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store")
+}
+
+func httpCacheKey(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:])
+}
+
+func (c *HTTPCache) loadMeta(key string) *httpCacheMeta {
+	data, err := os.ReadFile(filepath.Join(c.Dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	var meta httpCacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil
+	}
+	return &meta
+}
+
+// cachedResponse builds a 200 response from a stored body.
+func (c *HTTPCache) cachedResponse(key string, meta *httpCacheMeta, req *http.Request) *http.Response {
+	body, err := os.ReadFile(filepath.Join(c.Dir, key+".body"))
+	if err != nil {
+		return nil
+	}
+	// Touch so eviction treats this entry as recently used
+	now := time.Now()
+	os.Chtimes(filepath.Join(c.Dir, key+".json"), now, now)
+
+	header := make(http.Header)
+	if meta.ContentType != "" {
+		header.Set("Content-Type", meta.ContentType)
+	}
+	if meta.ETag != "" {
+		header.Set("ETag", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		header.Set("Last-Modified", meta.LastModified)
+	}
+	header.Set(HTTPCacheHeader, "revalidated")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func (c *HTTPCache) store(key, url string, resp *http.Response, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return
+	}
+	meta, _ := json.Marshal(httpCacheMeta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		StoredAt:     time.Now().UTC().Format(time.RFC3339),
+	})
+	path := filepath.Join(c.Dir, key+".body")
+	if err := fsutil.WriteFile(path, body, 0644); err != nil {
+		return
+	}
+	// A body without its metadata is never served but counts toward MaxBytes
+	if err := fsutil.WriteFile(filepath.Join(c.Dir, key+".json"), meta, 0644); err != nil {
+		os.Remove(path)
+		return
+	}
+	c.evict()
+}
+
+// evict removes least recently used entries until the cache fits MaxBytes.
+func (c *HTTPCache) evict() {
+	limit := c.MaxBytes
+	if limit <= 0 {
+		limit = DefaultHTTPCacheBytes
+	}
+	evictLRU(c.Dir, ".body", limit)
+}
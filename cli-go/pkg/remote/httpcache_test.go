@@ -0,0 +1,95 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPCache_RevalidatesWithETag(t *testing.T) {
+	requests, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/markdown")
+		w.Write([]byte("# Hello"))
+	}))
+	defer srv.Close()
+
+	cache := NewHTTPCache(t.TempDir(), 0)
+	client := &Client{HTTPClient: &http.Client{Transport: cache}}
+
+	for i := 0; i < 2; i++ {
+		body, err := client.FetchContent(srv.URL + "/posts/hello.md")
+		if err != nil || body != "# Hello" {
+			t.Fatalf("fetch %d = %q, %v", i, body, err)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("requests = %d, 304s = %d; want the second fetch revalidated", requests, notModified)
+	}
+	if n, size := cache.Size(); n != 1 || size != int64(len("# Hello")) {
+		t.Errorf("Size = %d, %d", n, size)
+	}
+}
+
+func TestHTTPCache_LastModifiedAndUncacheable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dated":
+			if r.Header.Get("If-Modified-Since") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			w.Write([]byte("dated"))
+		case "/nostore":
+			w.Header().Set("ETag", `"x"`)
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte("secret"))
+		default:
+			w.Write([]byte("plain"))
+		}
+	}))
+	defer srv.Close()
+
+	cache := NewHTTPCache(t.TempDir(), 0)
+	httpClient := &http.Client{Transport: cache}
+
+	httpClient.Get(srv.URL + "/dated")
+	resp, err := httpClient.Get(srv.URL + "/dated")
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header.Get(HTTPCacheHeader) != "revalidated" {
+		t.Fatalf("revalidated response = %+v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	httpClient.Get(srv.URL + "/nostore")
+	httpClient.Get(srv.URL + "/plain")
+	if n, _ := cache.Size(); n != 1 {
+		t.Errorf("cached %d responses, want only the one with validators", n)
+	}
+}
+
+func TestHTTPCache_EvictsToMaxBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		w.Write([]byte(strings.Repeat("x", 10)))
+	}))
+	defer srv.Close()
+
+	cache := NewHTTPCache(t.TempDir(), 25)
+	httpClient := &http.Client{Transport: cache}
+	for _, p := range []string{"/a", "/b", "/c"} {
+		resp, _ := httpClient.Get(srv.URL + p)
+		resp.Body.Close()
+	}
+	if _, size := cache.Size(); size > 25 {
+		t.Errorf("cache size %d exceeds MaxBytes", size)
+	}
+}
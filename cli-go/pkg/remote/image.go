@@ -0,0 +1,291 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+
+	_ "image/gif" // register GIF decoder for DecodeConfig
+)
+
+// Image proxy defaults.
+const (
+	DefaultImageMaxWidth   = 1600
+	MaxImageWidth          = 4096
+	MinImageWidth          = 16
+	DefaultImageCacheBytes = 200 << 20 // 200MB
+	maxImageFetchBytes     = 10 << 20
+	maxImagePixels         = 40_000_000 // Decoded size, whatever the file size: a small PNG can be huge
+	imageCacheTTL          = 7 * 24 * time.Hour
+)
+
+// ImageCache fetches remote images, downsizes them, and caches the result on
+// disk so repeated views never touch the remote host.
+type ImageCache struct {
+	Dir      string // Cache directory (e.g. .polis/cache/images)
+	MaxBytes int64  // Total cache size before eviction (0 = DefaultImageCacheBytes)
+	Client   *Client
+
+	mu sync.Mutex
+}
+
+// CachedImage is an image ready to serve.
+type CachedImage struct {
+	Data        []byte
+	ContentType string
+	FromCache   bool
+}
+
+type imageMeta struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	FetchedAt   string `json:"fetched_at"`
+}
+
+// ImageCacheDir returns the image cache directory for a site (.polis/cache/images).
+func ImageCacheDir(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "cache", "images")
+}
+
+// NewImageCache creates an image cache rooted at dir. Its fetches bypass
+// DefaultHTTPCache, since the downsized image is cached here already.
+func NewImageCache(dir string) *ImageCache {
+	return &ImageCache{Dir: dir, Client: newUncachedClient()}
+}
+
+// Get returns the image at imageURL, scaled down to at most maxWidth pixels
+// wide. maxWidth <= 0 uses DefaultImageMaxWidth.
+func (c *ImageCache) Get(imageURL string, maxWidth int) (*CachedImage, error) {
+	maxWidth = ClampImageWidth(maxWidth)
+	key := imageCacheKey(imageURL, maxWidth)
+
+	if img := c.load(key); img != nil {
+		return img, nil
+	}
+
+	data, contentType, err := c.fetch(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	if tooManyPixels(data) {
+		return nil, ErrImageTooLarge
+	}
+	data, contentType = ResizeImage(data, contentType, maxWidth)
+
+	img := &CachedImage{Data: data, ContentType: contentType}
+	c.store(key, imageURL, maxWidth, img)
+	return img, nil
+}
+
+// ClampImageWidth bounds a requested width to the supported range.
+func ClampImageWidth(w int) int {
+	if w <= 0 {
+		return DefaultImageMaxWidth
+	}
+	if w < MinImageWidth {
+		return MinImageWidth
+	}
+	if w > MaxImageWidth {
+		return MaxImageWidth
+	}
+	return w
+}
+
+func (c *ImageCache) fetch(imageURL string) ([]byte, string, error) {
+	client := c.Client
+	if client == nil {
+		client = NewClient()
+	}
+	resp, err := client.HTTPClient.Get(imageURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("remote returned HTTP %d", resp.StatusCode)
+	}
+	contentType := strings.TrimSpace(strings.SplitN(resp.Header.Get("Content-Type"), ";", 2)[0])
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", ErrNotImage
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageFetchBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageFetchBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxImageFetchBytes)
+	}
+	return data, contentType, nil
+}
+
+// ErrNotImage is returned when the remote resource is not an image.
+var ErrNotImage = fmt.Errorf("remote resource is not an image")
+
+// ErrImageTooLarge is returned when the remote image has more pixels than
+// the proxy will decode.
+var ErrImageTooLarge = fmt.Errorf("remote image has more than %d pixels", maxImagePixels)
+
+// tooManyPixels reports whether data is an image whose header declares more
+// than maxImagePixels. Only the header is read.
+func tooManyPixels(data []byte) bool {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return err == nil && int64(cfg.Width)*int64(cfg.Height) > maxImagePixels
+}
+
+// ResizeImage scales PNG and JPEG images wider than maxWidth down to maxWidth,
+// preserving aspect ratio. Other formats (GIF, WebP, SVG), images that fail
+// to decode, and images over maxImagePixels are returned unchanged.
+func ResizeImage(data []byte, contentType string, maxWidth int) ([]byte, string) {
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return data, contentType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= maxWidth || int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return data, contentType
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, contentType
+	}
+	dst := scaleImage(src, maxWidth)
+
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		err = png.Encode(&buf, dst)
+	default:
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		contentType = "image/jpeg"
+	}
+	if err != nil {
+		return data, contentType
+	}
+	return buf.Bytes(), contentType
+}
+
+// scaleImage downsizes src to width using a box filter (averages every
+// source pixel that falls within each destination pixel).
+func scaleImage(src image.Image, width int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		sy0 := b.Min.Y + y*sh/height
+		sy1 := b.Min.Y + (y+1)*sh/height
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < width; x++ {
+			sx0 := b.Min.X + x*sw/width
+			sx1 := b.Min.X + (x+1)*sw/width
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r / n) >> 8),
+				G: uint8((g / n) >> 8),
+				B: uint8((bl / n) >> 8),
+				A: uint8((a / n) >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+func imageCacheKey(imageURL string, width int) string {
+	h := sha256.Sum256([]byte(imageURL + "|" + strconv.Itoa(width)))
+	return hex.EncodeToString(h[:])
+}
+
+func (c *ImageCache) load(key string) *CachedImage {
+	metaPath := filepath.Join(c.Dir, key+".json")
+	metaData, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var meta imageMeta
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil
+	}
+	if fetched, err := time.Parse(time.RFC3339, meta.FetchedAt); err != nil || time.Since(fetched) > imageCacheTTL {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.Dir, key+".bin"))
+	if err != nil {
+		return nil
+	}
+	// Touch so eviction treats this entry as recently used
+	now := time.Now()
+	os.Chtimes(metaPath, now, now)
+	return &CachedImage{Data: data, ContentType: meta.ContentType, FromCache: true}
+}
+
+func (c *ImageCache) store(key, imageURL string, width int, img *CachedImage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return
+	}
+	meta, _ := json.Marshal(imageMeta{
+		URL:         imageURL,
+		ContentType: img.ContentType,
+		Width:       width,
+		FetchedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+	path := filepath.Join(c.Dir, key+".bin")
+	if err := fsutil.WriteFile(path, img.Data, 0644); err != nil {
+		return
+	}
+	// A body without its metadata is never served but counts toward MaxBytes
+	if err := fsutil.WriteFile(filepath.Join(c.Dir, key+".json"), meta, 0644); err != nil {
+		os.Remove(path)
+		return
+	}
+	c.evict()
+}
+
+// evict removes least recently used entries until the cache fits MaxBytes.
+func (c *ImageCache) evict() {
+	limit := c.MaxBytes
+	if limit <= 0 {
+		limit = DefaultImageCacheBytes
+	}
+	evictLRU(c.Dir, ".bin", limit)
+}
@@ -0,0 +1,165 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestResizeImage_DownscalesWideImages(t *testing.T) {
+	data, ct := ResizeImage(testPNG(t, 400, 200), "image/png", 100)
+	if ct != "image/png" {
+		t.Errorf("expected image/png, got %s", ct)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("resized image does not decode: %v", err)
+	}
+	if cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("expected 100x50, got %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestResizeImage_LeavesSmallAndUnsupportedImages(t *testing.T) {
+	small := testPNG(t, 50, 50)
+	if data, _ := ResizeImage(small, "image/png", 100); !bytes.Equal(data, small) {
+		t.Error("expected small image to be returned unchanged")
+	}
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	if data, ct := ResizeImage(svg, "image/svg+xml", 100); !bytes.Equal(data, svg) || ct != "image/svg+xml" {
+		t.Error("expected SVG to pass through")
+	}
+}
+
+// hugePNG returns a small PNG whose header declares w x h pixels.
+func hugePNG(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	data := testPNG(t, 1, 1)
+	// IHDR: 8-byte signature, length, type, then width, height, ..., CRC
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:], w)
+	binary.BigEndian.PutUint32(ihdr[8:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+func TestImageCache_RejectsTooManyPixels(t *testing.T) {
+	huge := hugePNG(t, 10000, 100000)
+	if data, _ := ResizeImage(huge, "image/png", 100); !bytes.Equal(data, huge) {
+		t.Error("ResizeImage decoded an image over the pixel limit")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(huge)
+	}))
+	defer srv.Close()
+	cache := NewImageCache(filepath.Join(t.TempDir(), "images"))
+	if _, err := cache.Get(srv.URL+"/huge.png", 100); err != ErrImageTooLarge {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+}
+
+func TestClampImageWidth(t *testing.T) {
+	cases := map[int]int{0: DefaultImageMaxWidth, -5: DefaultImageMaxWidth, 1: MinImageWidth, 800: 800, 100000: MaxImageWidth}
+	for in, want := range cases {
+		if got := ClampImageWidth(in); got != want {
+			t.Errorf("ClampImageWidth(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestImageCache_FetchesOnceThenServesFromDisk(t *testing.T) {
+	hits := 0
+	pngData := testPNG(t, 300, 300)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if strings.HasSuffix(r.URL.Path, ".html") {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<p>hi</p>"))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngData)
+	}))
+	defer srv.Close()
+
+	cache := NewImageCache(filepath.Join(t.TempDir(), "images"))
+
+	img, err := cache.Get(srv.URL+"/a.png", 150)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if img.FromCache {
+		t.Error("first Get should not be a cache hit")
+	}
+	cfg, _, _ := image.DecodeConfig(bytes.NewReader(img.Data))
+	if cfg.Width != 150 {
+		t.Errorf("expected cached image resized to 150, got %d", cfg.Width)
+	}
+
+	img, err = cache.Get(srv.URL+"/a.png", 150)
+	if err != nil {
+		t.Fatalf("second Get failed: %v", err)
+	}
+	if !img.FromCache || hits != 1 {
+		t.Errorf("expected cache hit without refetch (hits=%d, fromCache=%v)", hits, img.FromCache)
+	}
+
+	if _, err := cache.Get(srv.URL+"/page.html", 0); err != ErrNotImage {
+		t.Errorf("expected ErrNotImage, got %v", err)
+	}
+}
+
+func TestImageCache_EvictsOldestEntries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write(bytes.Repeat([]byte("x"), 1000))
+	}))
+	defer srv.Close()
+
+	dir := filepath.Join(t.TempDir(), "images")
+	cache := NewImageCache(dir)
+	cache.MaxBytes = 2500
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if _, err := cache.Get(srv.URL+"/"+name+".gif", 0); err != nil {
+			t.Fatalf("Get %s failed: %v", name, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	bins := 0
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".bin") {
+			bins++
+		}
+	}
+	if bins != 2 {
+		t.Errorf("expected 2 cached images after eviction, got %d", bins)
+	}
+}
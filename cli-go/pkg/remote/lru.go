@@ -0,0 +1,56 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// evictLRU removes the least recently used entries of a cache directory
+// until their data fits limit. An entry is a <key><dataExt> file with a
+// <key>.json sidecar, which the cache touches on every hit; its
+// modification time marks the entry's last use.
+func evictLRU(dir, dataExt string, limit int64) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type cacheEntry struct {
+		key     string
+		size    int64
+		touched time.Time
+	}
+	var all []cacheEntry
+	var total int64
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), dataExt) {
+			continue
+		}
+		key := strings.TrimSuffix(e.Name(), dataExt)
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		touched := info.ModTime()
+		if mi, err := os.Stat(filepath.Join(dir, key+".json")); err == nil {
+			touched = mi.ModTime()
+		}
+		all = append(all, cacheEntry{key: key, size: info.Size(), touched: touched})
+		total += info.Size()
+	}
+	if total <= limit {
+		return
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].touched.Before(all[j].touched) })
+	for _, e := range all {
+		if total <= limit {
+			break
+		}
+		os.Remove(filepath.Join(dir, e.key+dataExt))
+		os.Remove(filepath.Join(dir, e.key+".json"))
+		total -= e.size
+	}
+}
@@ -0,0 +1,182 @@
+package remote
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Media modes control how images and embeds in remote content are handled.
+const (
+	MediaLoad  = "load"  // Leave media as-is (browser contacts the remote host)
+	MediaProxy = "proxy" // Rewrite image URLs through the local image proxy
+	MediaClick = "click" // Replace with a placeholder the reader can click to load
+	MediaBlock = "block" // Remove media entirely
+)
+
+// ImageProxyPath is the local endpoint images are rewritten to in proxy mode.
+const ImageProxyPath = "/api/remote/image"
+
+// MediaPolicy configures how media in remote content is embedded.
+type MediaPolicy struct {
+	Images string `json:"images"` // <img>, <picture>/<source>, <video poster>
+	Embeds string `json:"embeds"` // <iframe>, <video>, <audio>, <embed>, <object>
+}
+
+// DefaultMediaPolicy loads images and embeds directly (previous behavior).
+func DefaultMediaPolicy() MediaPolicy {
+	return MediaPolicy{Images: MediaLoad, Embeds: MediaLoad}
+}
+
+// NormalizeMediaMode returns a known mode, defaulting to MediaLoad.
+// Embeds can't be proxied; proxy mode for embeds falls back to click-to-load.
+func NormalizeMediaMode(mode string, embed bool) string {
+	switch mode {
+	case MediaProxy:
+		if embed {
+			return MediaClick
+		}
+		return MediaProxy
+	case MediaClick, MediaBlock:
+		return mode
+	default:
+		return MediaLoad
+	}
+}
+
+var (
+	mediaTagPattern  = regexp.MustCompile(`(?is)<(img|source|iframe|video|audio|embed|object)\b[^>]*>`)
+	mediaCloseTag    = regexp.MustCompile(`(?is)</(iframe|video|audio|object)\s*>`)
+	attrPattern      = regexp.MustCompile(`(?is)\s([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+	embedTags        = map[string]bool{"iframe": true, "video": true, "audio": true, "embed": true, "object": true}
+	urlAttrsByTag    = map[string][]string{"img": {"src", "srcset"}, "source": {"src", "srcset"}, "video": {"poster"}}
+	placeholderLabel = map[string]string{"img": "image", "source": "image", "iframe": "embedded content", "video": "video", "audio": "audio", "embed": "embedded content", "object": "embedded content"}
+)
+
+// ApplyMediaPolicy rewrites media elements in remote HTML according to policy.
+// baseURL is the page the content was fetched from, used to resolve relative URLs.
+func ApplyMediaPolicy(content, baseURL string, policy MediaPolicy) string {
+	imageMode := NormalizeMediaMode(policy.Images, false)
+	embedMode := NormalizeMediaMode(policy.Embeds, true)
+	if imageMode == MediaLoad && embedMode == MediaLoad {
+		return content
+	}
+
+	base, _ := url.Parse(baseURL)
+
+	// Remove closing tags for embeds we replace, so no orphan </iframe> remains
+	if embedMode != MediaLoad {
+		content = mediaCloseTag.ReplaceAllString(content, "")
+	}
+
+	return mediaTagPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		name := strings.ToLower(mediaTagPattern.FindStringSubmatch(tag)[1])
+		mode := imageMode
+		if embedTags[name] {
+			mode = embedMode
+		}
+
+		switch mode {
+		case MediaBlock:
+			return `<span class="remote-media-blocked">[` + placeholderLabel[name] + ` blocked]</span>`
+		case MediaClick:
+			src := resolveURL(base, attrValue(tag, "src"))
+			if src == "" && name == "video" {
+				src = resolveURL(base, attrValue(tag, "poster"))
+			}
+			if src == "" {
+				return ""
+			}
+			host := ""
+			if u, err := url.Parse(src); err == nil {
+				host = u.Host
+			}
+			return `<button type="button" class="remote-media-placeholder" data-tag="` + name +
+				`" data-src="` + html.EscapeString(src) + `">Load ` + placeholderLabel[name] +
+				` from ` + html.EscapeString(host) + `</button>`
+		case MediaProxy:
+			return rewriteURLAttrs(tag, name, base)
+		default:
+			return tag
+		}
+	})
+}
+
+// rewriteURLAttrs points src/srcset/poster attributes at the local image proxy.
+func rewriteURLAttrs(tag, name string, base *url.URL) string {
+	attrs := urlAttrsByTag[name]
+	if len(attrs) == 0 {
+		return tag
+	}
+	return attrPattern.ReplaceAllStringFunc(tag, func(attr string) string {
+		m := attrPattern.FindStringSubmatch(attr)
+		key := strings.ToLower(m[1])
+		if !containsString(attrs, key) {
+			return attr
+		}
+		raw := html.UnescapeString(strings.Trim(m[2], `"'`))
+		var rewritten string
+		if key == "srcset" {
+			rewritten = rewriteSrcset(raw, base)
+		} else {
+			rewritten = ProxyImageURL(resolveURL(base, raw))
+		}
+		return " " + m[1] + `="` + html.EscapeString(rewritten) + `"`
+	})
+}
+
+// rewriteSrcset proxies every candidate URL in a srcset attribute.
+func rewriteSrcset(srcset string, base *url.URL) string {
+	parts := strings.Split(srcset, ",")
+	for i, p := range parts {
+		fields := strings.Fields(strings.TrimSpace(p))
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = ProxyImageURL(resolveURL(base, fields[0]))
+		parts[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ProxyImageURL returns the local proxy URL for a remote image.
+// Non-http(s) URLs (e.g. data:) are returned unchanged.
+func ProxyImageURL(imageURL string) string {
+	if !strings.HasPrefix(imageURL, "https://") && !strings.HasPrefix(imageURL, "http://") {
+		return imageURL
+	}
+	return ImageProxyPath + "?url=" + url.QueryEscape(imageURL)
+}
+
+// resolveURL resolves ref against base. Returns ref unchanged if base is nil.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == nil || base.Host == "" {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// attrValue returns the unescaped value of an attribute in a tag.
+func attrValue(tag, name string) string {
+	for _, m := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		if strings.EqualFold(m[1], name) {
+			return html.UnescapeString(strings.Trim(m[2], `"'`))
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
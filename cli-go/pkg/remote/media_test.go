@@ -0,0 +1,68 @@
+package remote
+
+import (
+	"strings"
+	"testing"
+)
+
+const mediaSample = `<p>Hi</p><img src="/images/cat.png" alt="cat"><iframe src="https://video.example/embed/1"></iframe>`
+
+func TestApplyMediaPolicy_LoadIsNoop(t *testing.T) {
+	if got := ApplyMediaPolicy(mediaSample, "https://alice.example/posts/a.md", DefaultMediaPolicy()); got != mediaSample {
+		t.Errorf("expected unchanged content, got %s", got)
+	}
+}
+
+func TestApplyMediaPolicy_ProxyImages(t *testing.T) {
+	got := ApplyMediaPolicy(mediaSample, "https://alice.example/posts/a.md", MediaPolicy{Images: MediaProxy, Embeds: MediaLoad})
+	want := `src="/api/remote/image?url=https%3A%2F%2Falice.example%2Fimages%2Fcat.png"`
+	if !strings.Contains(got, want) {
+		t.Errorf("expected proxied relative image, got %s", got)
+	}
+	if !strings.Contains(got, `<iframe src="https://video.example/embed/1"></iframe>`) {
+		t.Errorf("iframe should be untouched, got %s", got)
+	}
+}
+
+func TestApplyMediaPolicy_Srcset(t *testing.T) {
+	in := `<img srcset="https://a.example/1.png 1x, https://a.example/2.png 2x" src="data:image/png;base64,AAAA">`
+	got := ApplyMediaPolicy(in, "", MediaPolicy{Images: MediaProxy})
+	if strings.Count(got, ImageProxyPath) != 2 {
+		t.Errorf("expected both srcset candidates proxied, got %s", got)
+	}
+	if !strings.Contains(got, `src="data:image/png;base64,AAAA"`) {
+		t.Errorf("data: URLs should not be proxied, got %s", got)
+	}
+}
+
+func TestApplyMediaPolicy_ClickToLoad(t *testing.T) {
+	got := ApplyMediaPolicy(mediaSample, "https://alice.example/posts/a.md", MediaPolicy{Images: MediaClick, Embeds: MediaProxy})
+	if strings.Contains(got, "<img") || strings.Contains(got, "<iframe") || strings.Contains(got, "</iframe>") {
+		t.Errorf("expected media replaced by placeholders, got %s", got)
+	}
+	if !strings.Contains(got, `data-tag="img" data-src="https://alice.example/images/cat.png">Load image from alice.example`) {
+		t.Errorf("missing image placeholder, got %s", got)
+	}
+	if !strings.Contains(got, `data-tag="iframe"`) {
+		t.Errorf("proxy mode for embeds should fall back to click-to-load, got %s", got)
+	}
+}
+
+func TestApplyMediaPolicy_Block(t *testing.T) {
+	got := ApplyMediaPolicy(mediaSample, "", MediaPolicy{Images: MediaBlock, Embeds: MediaBlock})
+	if strings.Contains(got, "cat.png") || strings.Contains(got, "video.example") {
+		t.Errorf("expected media URLs removed, got %s", got)
+	}
+	if strings.Count(got, "remote-media-blocked") != 2 {
+		t.Errorf("expected 2 blocked markers, got %s", got)
+	}
+}
+
+func TestNormalizeMediaMode(t *testing.T) {
+	if NormalizeMediaMode("bogus", false) != MediaLoad {
+		t.Error("unknown mode should default to load")
+	}
+	if NormalizeMediaMode(MediaProxy, true) != MediaClick {
+		t.Error("embeds cannot be proxied")
+	}
+}
@@ -0,0 +1,64 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/protected"
+)
+
+// FetchProtected fetches a followers-only file, answering the server's
+// challenge with a signature from the reader's site key. actor is the
+// reader's domain, whose .well-known/polis publishes the matching public
+// key. Files that turn out not to be protected are returned as fetched.
+func (c *Client) FetchProtected(rawURL, actor string, privateKey []byte) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+
+	resp, err := c.HTTPClient.Get(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	challenge, challenged := protected.ParseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if resp.StatusCode != http.StatusUnauthorized || !challenged {
+		return readFetched(resp, rawURL)
+	}
+	resp.Body.Close()
+
+	creds, err := protected.Sign(challenge, u.Host, u.EscapedPath(), actor, privateKey)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	// An Authorization header also keeps the response out of the HTTP cache
+	req.Header.Set("Authorization", creds.Header())
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return "", fmt.Errorf("%s refused the signed request for %s (status %d); is %s following them?", u.Host, rawURL, resp.StatusCode, actor)
+	}
+	return readFetched(resp, rawURL)
+}
+
+// readFetched reads and closes a response body, failing on error statuses.
+func readFetched(resp *http.Response, rawURL string) (string, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("fetch failed with status %d for %s", resp.StatusCode, rawURL)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/protected"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestFetchProtected(t *testing.T) {
+	privKey, pubKey, err := signing.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := protected.NewChallengeStore()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			challenge, _ := store.Issue()
+			w.Header().Set("WWW-Authenticate", protected.ChallengeHeader(challenge))
+			http.Error(w, "Signature required", http.StatusUnauthorized)
+			return
+		}
+		actor, err := protected.Verify(r, r.Host, store, func(string) (string, error) { return string(pubKey), nil })
+		if err != nil || actor != "bob.example.com" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("# Members only"))
+	}))
+	defer srv.Close()
+
+	c := &Client{HTTPClient: srv.Client()}
+	body, err := c.FetchProtected(srv.URL+"/protected/posts/20260101/p.md", "bob.example.com", privKey)
+	if err != nil {
+		t.Fatalf("FetchProtected: %v", err)
+	}
+	if body != "# Members only" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	_, err = c.FetchProtected(srv.URL+"/protected/posts/20260101/p.md", "carol.example.com", privKey)
+	if err == nil || !strings.Contains(err.Error(), "refused") {
+		t.Errorf("expected refusal for non-follower, got %v", err)
+	}
+}
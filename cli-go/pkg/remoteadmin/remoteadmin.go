@@ -0,0 +1,153 @@
+// Package remoteadmin keeps the connection to a polis serve instance on
+// another machine, so a site served from a VPS can be run from a laptop
+// through the server's API instead of over SSH.
+//
+// The connection (the server's URL and an API user's token, see package
+// access) is saved per user in ~/.polis/remote-admin.json, not in a site,
+// since the laptop needn't have a copy of the site.
+package remoteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/client"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Filename holds the connection, in the user's ~/.polis directory.
+const Filename = "remote-admin.json"
+
+// ErrNotConnected is returned when there is no saved connection.
+var ErrNotConnected = errors.New("not connected to a remote server (run: polis remote-admin connect <url> --token <token>)")
+
+// Connection is a saved connection to a remote server.
+type Connection struct {
+	URL         string `json:"url"`
+	Token       string `json:"token"`
+	User        string `json:"user"`
+	Role        string `json:"role"`
+	ConnectedAt string `json:"connected_at"`
+}
+
+// Path returns the file the connection is saved in.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".polis", Filename), nil
+}
+
+// Load returns the saved connection.
+func Load() (*Connection, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotConnected
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", Filename, err)
+	}
+	var conn Connection
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+	if conn.URL == "" {
+		return nil, ErrNotConnected
+	}
+	return &conn, nil
+}
+
+// Save writes the connection, readable only by the user since it holds the
+// token.
+func Save(conn *Connection) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(conn, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal connection: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create ~/.polis: %w", err)
+	}
+	return fsutil.WriteFile(path, data, 0600)
+}
+
+// Disconnect forgets the saved connection.
+func Disconnect() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Connect checks that token signs in to the server at rawURL and returns the
+// connection, unsaved.
+func Connect(ctx context.Context, rawURL, token string) (*Connection, error) {
+	u, err := url.Parse(strings.TrimRight(rawURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q (expected http(s)://host[:port])", rawURL)
+	}
+	if token == "" {
+		return nil, errors.New("a token is required (create one on the server with: polis access add <name>)")
+	}
+	c := client.New(u.String())
+	c.Token = token
+	me, err := c.Me(ctx)
+	if errors.Is(err, client.ErrUnauthorized) {
+		return nil, errors.New("the server refused the token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !me.Auth {
+		return nil, errors.New("the server has no API users, so anyone who can reach it can run it; add one there first (polis access add <name> --role admin)")
+	}
+	return &Connection{
+		URL:         u.String(),
+		Token:       token,
+		User:        me.Name,
+		Role:        me.Role,
+		ConnectedAt: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Client returns an API client for the connection.
+func (c *Connection) Client() *client.Client {
+	cl := client.New(c.URL)
+	cl.Token = c.Token
+	return cl
+}
+
+// Insecure reports whether the connection sends its token unencrypted: over
+// plain HTTP to a host other than this machine.
+func (c *Connection) Insecure() bool {
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Scheme == "https" {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || !ip.IsLoopback()
+}
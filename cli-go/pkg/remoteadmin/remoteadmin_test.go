@@ -0,0 +1,81 @@
+package remoteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnect(t *testing.T) {
+	users := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !users {
+			json.NewEncoder(w).Encode(map[string]interface{}{"auth": false, "role": "admin"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Sign in", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"auth": true, "name": "alex", "role": "admin"})
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	conn, err := Connect(ctx, srv.URL+"/", "secret")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if conn.URL != srv.URL || conn.User != "alex" || conn.Role != "admin" {
+		t.Errorf("connection = %+v", conn)
+	}
+	if _, err := Connect(ctx, srv.URL, "wrong"); err == nil {
+		t.Error("Connect accepted a bad token")
+	}
+	if _, err := Connect(ctx, "ftp://example.com", "secret"); err == nil {
+		t.Error("Connect accepted a non-HTTP URL")
+	}
+
+	// A server anyone can run isn't one to connect to
+	users = false
+	if _, err := Connect(ctx, srv.URL, "secret"); err == nil {
+		t.Error("Connect accepted a server without API users")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := Load(); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Load before connecting = %v, want ErrNotConnected", err)
+	}
+	if err := Save(&Connection{URL: "https://vps.example.com", Token: "secret", User: "alex"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	conn, err := Load()
+	if err != nil || conn.URL != "https://vps.example.com" || conn.Token != "secret" {
+		t.Fatalf("Load = %+v, %v", conn, err)
+	}
+	if err := Disconnect(); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+	if _, err := Load(); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Load after disconnecting = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestInsecure(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://vps.example.com":   false,
+		"http://localhost:8080":     false,
+		"http://127.0.0.1:8080":     false,
+		"http://vps.example.com":    true,
+		"http://192.168.1.10:53123": true,
+	} {
+		if got := (&Connection{URL: url}).Insecure(); got != want {
+			t.Errorf("Insecure(%s) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+// Package remotedir lets the CLI manage a site that lives on another
+// machine. A data directory given as sftp://[user@]host[:port]/path is
+// mirrored into a local working copy for the length of a command, and what
+// the command changed is written back when it finishes, so every package
+// keeps working against an ordinary directory.
+//
+// The transport is OpenSSH with tar on both ends, as for deploy targets:
+// one ssh round trip pulls the site and one writes changes back. While a
+// working copy is checked out the site is locked with .polis/remote.lock on
+// the server, so two machines can't overwrite each other's changes.
+package remotedir
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// LockDir is the lock held on the server while a working copy is checked out.
+const LockDir = ".polis/remote.lock"
+
+// ErrLocked is returned by Pull when another working copy holds the lock.
+var ErrLocked = errors.New("site is locked by another session (if none is running, remove " + LockDir + " on the server)")
+
+// excluded reports whether a site-relative path stays on its own side:
+// caches are rebuilt wherever they're needed, and lock directories belong
+// to the process that made them.
+func excluded(rel string) bool {
+	if rel == ".polis/cache" || strings.HasPrefix(rel, ".polis/cache/") {
+		return true
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasSuffix(part, ".lock") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRemote reports whether dir names a remote data directory.
+func IsRemote(dir string) bool {
+	return strings.HasPrefix(dir, "sftp://") || strings.HasPrefix(dir, "ssh://")
+}
+
+// Location is a parsed remote data directory.
+type Location struct {
+	User string
+	Host string
+	Port int
+	Path string // Absolute, or relative to the remote home directory
+}
+
+// Parse parses sftp://[user@]host[:port]/path. A path starting with /~/ is
+// relative to the remote user's home directory.
+func Parse(raw string) (*Location, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote data directory: %w", err)
+	}
+	if u.Scheme != "sftp" && u.Scheme != "ssh" {
+		return nil, fmt.Errorf("unsupported scheme %q (expected sftp://)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("remote data directory has no host: %s", raw)
+	}
+	loc := &Location{Host: u.Hostname(), Path: u.Path}
+	if u.User != nil {
+		loc.User = u.User.Username()
+	}
+	if p := u.Port(); p != "" {
+		if loc.Port, err = strconv.Atoi(p); err != nil {
+			return nil, fmt.Errorf("invalid port %q", p)
+		}
+	}
+	loc.Path = strings.TrimSuffix(loc.Path, "/")
+	if loc.Path == "/~" || strings.HasPrefix(loc.Path, "/~/") {
+		loc.Path = strings.TrimPrefix(strings.TrimPrefix(loc.Path, "/~"), "/")
+	}
+	if loc.Path == "" || loc.Path == "/" {
+		return nil, fmt.Errorf("remote data directory has no path: %s", raw)
+	}
+	return loc, nil
+}
+
+// String returns the location as a URL.
+func (l *Location) String() string {
+	host := l.Host
+	if l.Port > 0 {
+		host += ":" + strconv.Itoa(l.Port)
+	}
+	if l.User != "" {
+		host = l.User + "@" + host
+	}
+	p := l.Path
+	if !strings.HasPrefix(p, "/") {
+		p = "/~/" + p
+	}
+	return "sftp://" + host + p
+}
+
+// shellPath returns the remote path quoted for the remote shell.
+func (l *Location) shellPath() string {
+	if strings.HasPrefix(l.Path, "/") {
+		return shellQuote(l.Path)
+	}
+	return `"$HOME"/` + shellQuote(l.Path)
+}
+
+// Runner runs a shell script on the remote host with stdin and stdout
+// attached. A failing script's *exec.ExitError is wrapped in the error.
+type Runner func(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error
+
+// SSHRunner runs scripts on loc's host with the ssh client on PATH.
+func SSHRunner(loc *Location) Runner {
+	return func(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error {
+		args := []string{"-o", "BatchMode=yes"}
+		if loc.Port > 0 {
+			args = append(args, "-p", strconv.Itoa(loc.Port))
+		}
+		host := loc.Host
+		if loc.User != "" {
+			host = loc.User + "@" + host
+		}
+		args = append(args, host, script)
+		cmd := exec.CommandContext(ctx, "ssh", args...)
+		var stderr bytes.Buffer
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("ssh %s: %w: %s", host, err, msg)
+			}
+			return fmt.Errorf("ssh %s: %w", host, err)
+		}
+		return nil
+	}
+}
+
+// lockedExit is the exit status of the pull script when the lock is held.
+const lockedExit = 75
+
+// Mount is a local working copy of a remote site.
+type Mount struct {
+	Location *Location
+	Dir      string // The working copy; use it as the data directory
+
+	run      Runner
+	stateDir string
+	manifest map[string]string // Path -> hash as pulled
+	locked   bool
+}
+
+// Open prepares a working copy of the remote data directory raw under
+// cacheDir. Nothing is fetched until Pull. run may be nil to use ssh.
+func Open(raw, cacheDir string, run Runner) (*Mount, error) {
+	loc, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		run = SSHRunner(loc)
+	}
+	sum := sha256.Sum256([]byte(loc.String()))
+	stateDir := filepath.Join(cacheDir, loc.Host+"-"+hex.EncodeToString(sum[:])[:12])
+	return &Mount{
+		Location: loc,
+		Dir:      filepath.Join(stateDir, "site"),
+		run:      run,
+		stateDir: stateDir,
+	}, nil
+}
+
+// DefaultCacheDir is where working copies are kept.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "polis", "remote"), nil
+}
+
+// Pull locks the remote site and brings the working copy up to date with it.
+// The remote directory is created if it doesn't exist, so a new site can be
+// initialized remotely.
+func (m *Mount) Pull(ctx context.Context) error {
+	// The private key is pulled too; keep working copies to this user
+	if err := os.MkdirAll(m.Dir, 0700); err != nil {
+		return err
+	}
+	os.Chmod(m.stateDir, 0700)
+
+	p := m.Location.shellPath()
+	script := fmt.Sprintf("mkdir -p %s/.polis && cd %s && { mkdir %s 2>/dev/null || exit %d; } && tar -cf - --exclude=./.polis/cache --exclude=./%s .",
+		p, p, shellQuote(LockDir), lockedExit, LockDir)
+	var archive bytes.Buffer
+	if err := m.run(ctx, script, nil, &archive); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == lockedExit {
+			return ErrLocked
+		}
+		return err
+	}
+	m.locked = true
+
+	pulled, err := extract(&archive, m.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", m.Location, err)
+	}
+	// Files gone from the server go from the working copy too
+	local, err := scan(m.Dir)
+	if err != nil {
+		return err
+	}
+	for rel := range local {
+		if _, ok := pulled[rel]; !ok {
+			os.Remove(filepath.Join(m.Dir, filepath.FromSlash(rel)))
+		}
+	}
+	m.manifest = pulled
+	return nil
+}
+
+// PushResult lists what Push wrote back.
+type PushResult struct {
+	Written []string `json:"written"`
+	Removed []string `json:"removed"`
+}
+
+// Push writes files changed in the working copy since Pull back to the
+// server and removes files deleted from it.
+func (m *Mount) Push(ctx context.Context) (*PushResult, error) {
+	if m.manifest == nil {
+		return nil, errors.New("nothing pulled")
+	}
+	current, err := scan(m.Dir)
+	if err != nil {
+		return nil, err
+	}
+	result := &PushResult{}
+	for rel, hash := range current {
+		if m.manifest[rel] != hash {
+			result.Written = append(result.Written, rel)
+		}
+	}
+	for rel := range m.manifest {
+		if _, ok := current[rel]; !ok {
+			result.Removed = append(result.Removed, rel)
+		}
+	}
+	sort.Strings(result.Written)
+	sort.Strings(result.Removed)
+	if len(result.Written) == 0 && len(result.Removed) == 0 {
+		return result, nil
+	}
+
+	var archive bytes.Buffer
+	if err := pack(&archive, m.Dir, result.Written); err != nil {
+		return nil, err
+	}
+	script := "cd " + m.Location.shellPath() + " && tar -xf -"
+	if len(result.Removed) > 0 {
+		quoted := make([]string, len(result.Removed))
+		for i, rel := range result.Removed {
+			quoted[i] = shellQuote("./" + rel)
+		}
+		script += " && rm -f -- " + strings.Join(quoted, " ")
+	}
+	if err := m.run(ctx, script, &archive, io.Discard); err != nil {
+		return nil, fmt.Errorf("failed to write changes to %s: %w", m.Location, err)
+	}
+	m.manifest = current
+	return result, nil
+}
+
+// Unlock releases the server lock taken by Pull.
+func (m *Mount) Unlock(ctx context.Context) error {
+	if !m.locked {
+		return nil
+	}
+	script := "rmdir " + m.Location.shellPath() + "/" + shellQuote(LockDir)
+	if err := m.run(ctx, script, nil, io.Discard); err != nil {
+		return fmt.Errorf("failed to release %s on %s: %w", LockDir, m.Location, err)
+	}
+	m.locked = false
+	return nil
+}
+
+// extract unpacks a tar stream into dir and returns the hashes of the
+// files it contained.
+func extract(r io.Reader, dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rel := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if rel == "." || excluded(rel) {
+			continue
+		}
+		if !fs.ValidPath(rel) {
+			return nil, fmt.Errorf("unsafe path in archive: %s", hdr.Name)
+		}
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return nil, err
+			}
+			if err := fsutil.WriteFile(dest, data, fs.FileMode(hdr.Mode).Perm()); err != nil {
+				return nil, err
+			}
+			files[rel] = hashOf(data)
+		}
+		// Links and devices have no place in a site and are skipped
+	}
+}
+
+// scan returns the hash of every file in dir, by slash-separated path.
+func scan(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		rel = filepath.ToSlash(rel)
+		if rel != "." && excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = hashOf(data)
+		return nil
+	})
+	return files, err
+}
+
+// pack writes the files, relative to dir, to a tar stream.
+func pack(w io.Writer, dir string, files []string) error {
+	tw := tar.NewWriter(w)
+	for _, rel := range files {
+		full := filepath.Join(dir, filepath.FromSlash(rel))
+		info, err := os.Stat(full)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    "./" + rel,
+			Mode:    int64(info.Mode().Perm()),
+			Size:    int64(len(data)),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// String describes the mount for messages.
+func (m *Mount) String() string {
+	return m.Location.String()
+}
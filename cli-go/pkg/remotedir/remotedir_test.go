@@ -0,0 +1,117 @@
+package remotedir
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// localRunner runs the remote scripts with the local shell, with $HOME
+// pointing at home, so a temp directory stands in for the server.
+func localRunner(home string) Runner {
+	return func(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error {
+		cmd := exec.CommandContext(ctx, "sh", "-c", script)
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		cmd.Stdin, cmd.Stdout = stdin, stdout
+		return cmd.Run()
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Location
+	}{
+		{"sftp://alice@example.com/var/www/site", Location{User: "alice", Host: "example.com", Path: "/var/www/site"}},
+		{"sftp://example.com:2222/~/site/", Location{Host: "example.com", Port: 2222, Path: "site"}},
+		{"ssh://bob@example.com/srv", Location{User: "bob", Host: "example.com", Path: "/srv"}},
+	}
+	for _, tt := range tests {
+		loc, err := Parse(tt.raw)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.raw, err)
+			continue
+		}
+		if *loc != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, *loc, tt.want)
+		}
+	}
+	for _, bad := range []string{"sftp://example.com", "sftp:///srv", "https://example.com/srv"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+	if loc, _ := Parse("sftp://a@h:22/~/site"); loc.String() != "sftp://a@h:22/~/site" {
+		t.Errorf("String() = %q", loc.String())
+	}
+}
+
+func TestMount_PullPush(t *testing.T) {
+	if _, err := exec.LookPath("tar"); err != nil {
+		t.Skip("tar not available")
+	}
+	home := t.TempDir()
+	server := filepath.Join(home, "site")
+	for name, content := range map[string]string{
+		".well-known/polis":      "{}",
+		"posts/2026/hello.md":    "hello",
+		"posts/2026/gone.md":     "gone",
+		".polis/cache/http/x":    "cached",
+		"metadata/public.jsonl":  "{}\n",
+		".polis/keys/id_ed25519": "secret",
+	} {
+		os.MkdirAll(filepath.Dir(filepath.Join(server, name)), 0755)
+		os.WriteFile(filepath.Join(server, name), []byte(content), 0644)
+	}
+
+	ctx := context.Background()
+	m, err := Open("sftp://me@host/~/site", t.TempDir(), localRunner(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Pull(ctx); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(m.Dir, "posts/2026/hello.md")); string(data) != "hello" {
+		t.Errorf("working copy has %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir, ".polis/cache/http/x")); !os.IsNotExist(err) {
+		t.Error("cache was pulled")
+	}
+
+	// A second session can't check the site out while the first holds it
+	other, _ := Open("sftp://me@host/~/site", t.TempDir(), localRunner(home))
+	if err := other.Pull(ctx); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Pull = %v, want ErrLocked", err)
+	}
+
+	os.WriteFile(filepath.Join(m.Dir, "posts/2026/hello.md"), []byte("hello again"), 0644)
+	os.WriteFile(filepath.Join(m.Dir, "posts/2026/new.md"), []byte("new"), 0644)
+	os.Remove(filepath.Join(m.Dir, "posts/2026/gone.md"))
+	result, err := m.Push(ctx)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if strings.Join(result.Written, ",") != "posts/2026/hello.md,posts/2026/new.md" || strings.Join(result.Removed, ",") != "posts/2026/gone.md" {
+		t.Errorf("push result = %+v", result)
+	}
+	if data, _ := os.ReadFile(filepath.Join(server, "posts/2026/hello.md")); string(data) != "hello again" {
+		t.Errorf("server has %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(server, "posts/2026/gone.md")); !os.IsNotExist(err) {
+		t.Error("deleted file still on the server")
+	}
+
+	if err := m.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := other.Pull(ctx); err != nil {
+		t.Errorf("Pull after unlock: %v", err)
+	}
+	other.Unlock(ctx)
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// AnalyticsBeacon is the URL rendered posts report views to, or "" to
+// render them without a view beacon. Callers set it from the
+// analytics.enabled and analytics.beacon_url settings.
+var AnalyticsBeacon string
+
+// analyticsScript returns the view beacon for the post at postPath: it
+// sends the post's path, and nothing else, to AnalyticsBeacon. Readers
+// whose browser asks not to be tracked are not counted.
+func analyticsScript(postPath string) string {
+	url, _ := json.Marshal(AnalyticsBeacon)
+	path, _ := json.Marshal(postPath)
+	return `<script>(function(){var n=navigator;if(n.doNotTrack==="1"||n.globalPrivacyControl||!n.sendBeacon)return;` +
+		`try{n.sendBeacon(` + string(url) + `,` + string(path) + `)}catch(e){}})();</script>`
+}
+
+// injectAnalytics adds the view beacon to a rendered post page, before
+// </body>, if AnalyticsBeacon is set.
+func injectAnalytics(page, postPath string) string {
+	if AnalyticsBeacon == "" {
+		return page
+	}
+	script := analyticsScript(postPath)
+	if i := strings.LastIndex(strings.ToLower(page), "</body>"); i >= 0 {
+		return page[:i] + script + "\n" + page[i:]
+	}
+	return page + script + "\n"
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderFile_AnalyticsBeacon(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	os.MkdirAll(filepath.Join(tempDir, "posts"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "posts", "hello.md"), []byte("---\ntitle: Hello\n---\n# Hello\n"), 0644)
+
+	r, err := NewPageRenderer(PageConfig{DataDir: tempDir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+
+	html, _, _ := r.RenderFile("posts/hello.md", "post", true)
+	if strings.Contains(html, "sendBeacon") {
+		t.Errorf("expected no beacon with analytics off:\n%s", html)
+	}
+
+	defer func(saved string) { AnalyticsBeacon = saved }(AnalyticsBeacon)
+	AnalyticsBeacon = "https://stats.example.com/beacon"
+	html, _, err = r.RenderFile("posts/hello.md", "post", true)
+	if err != nil {
+		t.Fatalf("RenderFile failed: %v", err)
+	}
+	want := `n.sendBeacon("https://stats.example.com/beacon","posts/hello.md")`
+	if !strings.Contains(html, want) || strings.Index(html, want) > strings.Index(html, "</body>") {
+		t.Errorf("expected beacon before </body>:\n%s", html)
+	}
+}
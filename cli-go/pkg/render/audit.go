@@ -0,0 +1,232 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+)
+
+// Accessibility audit rules.
+const (
+	RuleImageAlt     = "img-alt"       // <img> without an alt attribute
+	RuleHeadingOrder = "heading-order" // Heading more than one level below the previous
+	RuleContrast     = "contrast"      // Theme text color below WCAG AA contrast on the background
+)
+
+// MinContrast is the WCAG 2 AA contrast ratio for body text.
+const MinContrast = 4.5
+
+// AuditIssue is one accessibility problem found by an audit.
+type AuditIssue struct {
+	Path    string `json:"path"` // Post or comment source, or the theme CSS file
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// AuditReport is the result of PageRenderer.Audit.
+type AuditReport struct {
+	Theme   string       `json:"theme"`
+	Checked int          `json:"checked"` // Posts and comments audited
+	Issues  []AuditIssue `json:"issues"`
+}
+
+var (
+	imgTag     = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	altAttr    = regexp.MustCompile(`(?i)\salt\s*=`)
+	srcAttr    = regexp.MustCompile(`(?i)\ssrc\s*=\s*"([^"]*)"`)
+	headingTag = regexp.MustCompile(`(?i)<h([1-6])\b[^>]*>(.*?)</h[1-6]>`)
+	htmlTag    = regexp.MustCompile(`<[^>]+>`)
+)
+
+// AuditHTML checks rendered post content for images without alt text and
+// skipped heading levels. An empty alt (alt="") is allowed; it marks an
+// image as decorative.
+func AuditHTML(content string) []AuditIssue {
+	var issues []AuditIssue
+	for _, tag := range imgTag.FindAllString(content, -1) {
+		if altAttr.MatchString(tag) {
+			continue
+		}
+		src := "an image"
+		if m := srcAttr.FindStringSubmatch(tag); m != nil {
+			src = m[1]
+		}
+		issues = append(issues, AuditIssue{
+			Rule:    RuleImageAlt,
+			Message: fmt.Sprintf("%s has no alt text", src),
+		})
+	}
+
+	prev := 0
+	for _, m := range headingTag.FindAllStringSubmatch(content, -1) {
+		level, _ := strconv.Atoi(m[1])
+		if prev > 0 && level > prev+1 {
+			text := strings.TrimSpace(htmlTag.ReplaceAllString(m[2], ""))
+			issues = append(issues, AuditIssue{
+				Rule:    RuleHeadingOrder,
+				Message: fmt.Sprintf("h%d %q follows h%d (skips a level)", level, text, prev),
+			})
+		}
+		prev = level
+	}
+	return issues
+}
+
+// AuditMarkdown renders a post's markdown (frontmatter is ignored) and
+// audits the result with AuditHTML.
+func AuditMarkdown(markdown string) ([]AuditIssue, error) {
+	content, err := MarkdownToHTML(stripFrontmatter(markdown))
+	if err != nil {
+		return nil, err
+	}
+	return AuditHTML(content), nil
+}
+
+// ErrAuditFailed is returned by CheckPublish for a post with issues.
+var ErrAuditFailed = errors.New("accessibility audit failed (audit_block_publish is on)")
+
+// CheckPublish is a publish.ContentCheck that refuses posts with images
+// without alt text or skipped heading levels.
+func CheckPublish(markdown string) error {
+	issues, err := AuditMarkdown(markdown)
+	if err != nil || len(issues) == 0 {
+		return err
+	}
+	msgs := make([]string, len(issues))
+	for i, issue := range issues {
+		msgs[i] = issue.Message
+	}
+	return fmt.Errorf("%w: %s", ErrAuditFailed, strings.Join(msgs, "; "))
+}
+
+// AuditThemeCSS checks the contrast of a theme's text colors (--color-text*,
+// --color-accent*, --color-cyan) against its --color-bg. Variables that
+// aren't plain hex colors are skipped, as are -glow and -dim variants,
+// which themes use for decoration rather than text.
+func AuditThemeCSS(cssPath string) ([]AuditIssue, error) {
+	vars, err := theme.ColorVars(cssPath)
+	if err != nil {
+		return nil, err
+	}
+	bg, ok := parseHexColor(vars["bg"])
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	for name := range vars {
+		if !strings.HasPrefix(name, "text") && !strings.HasPrefix(name, "accent") && name != "cyan" {
+			continue
+		}
+		if strings.HasSuffix(name, "-glow") || strings.HasSuffix(name, "-dim") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []AuditIssue
+	for _, name := range names {
+		fg, ok := parseHexColor(vars[name])
+		if !ok {
+			continue
+		}
+		if ratio := contrastRatio(fg, bg); ratio < MinContrast {
+			issues = append(issues, AuditIssue{
+				Path: cssPath,
+				Rule: RuleContrast,
+				Message: fmt.Sprintf("--color-%s (%s) on --color-bg (%s) has contrast %.2f:1, below %.1f:1",
+					name, vars[name], vars["bg"], ratio, MinContrast),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// Audit checks every published post and comment (public and unlisted) and
+// the active theme's colors. It reads the markdown sources, so it reflects
+// what the next render will produce.
+func (r *PageRenderer) Audit() (*AuditReport, error) {
+	report := &AuditReport{Theme: r.themeName, Issues: []AuditIssue{}}
+
+	entries, err := metadata.LoadPublicIndex(r.config.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	if unlisted, err := metadata.LoadUnlistedIndex(r.config.DataDir); err == nil {
+		entries = append(entries, unlisted...)
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(r.config.DataDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			continue // Listed but missing; validate reports that
+		}
+		issues, err := AuditMarkdown(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		for _, issue := range issues {
+			issue.Path = entry.Path
+			report.Issues = append(report.Issues, issue)
+		}
+		report.Checked++
+	}
+
+	themeDir := theme.GetThemeDir(r.config.DataDir, r.config.CLIThemesDir, r.themeName)
+	if themeDir != "" {
+		cssPath := filepath.Join(themeDir, r.themeName+".css")
+		if issues, err := AuditThemeCSS(cssPath); err == nil {
+			report.Issues = append(report.Issues, issues...)
+		}
+	}
+	return report, nil
+}
+
+// parseHexColor parses #rgb or #rrggbb (alpha forms are skipped: their
+// contrast depends on what's behind them).
+func parseHexColor(s string) ([3]float64, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return [3]float64{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return [3]float64{}, false
+	}
+	return [3]float64{float64(v >> 16 & 0xFF), float64(v >> 8 & 0xFF), float64(v & 0xFF)}, true
+}
+
+// relativeLuminance is the WCAG 2 relative luminance of an sRGB color.
+func relativeLuminance(c [3]float64) float64 {
+	var lin [3]float64
+	for i, v := range c {
+		v /= 255
+		if v <= 0.03928 {
+			lin[i] = v / 12.92
+		} else {
+			lin[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*lin[0] + 0.7152*lin[1] + 0.0722*lin[2]
+}
+
+// contrastRatio is the WCAG 2 contrast ratio of two colors, from 1 to 21.
+func contrastRatio(a, b [3]float64) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
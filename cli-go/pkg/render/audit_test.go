@@ -0,0 +1,74 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditHTML(t *testing.T) {
+	content := `<h1>Title</h1>
+<p><img src="a.png" alt="A chart"><img src="decor.png" alt=""><img src="b.png"></p>
+<h2>Section</h2>
+<h4>Deep <em>dive</em></h4>
+<h2>Next</h2>
+<h3>Fine</h3>`
+
+	issues := AuditHTML(content)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", issues)
+	}
+	if issues[0].Rule != RuleImageAlt || !strings.Contains(issues[0].Message, "b.png") {
+		t.Errorf("expected missing alt on b.png, got %+v", issues[0])
+	}
+	if issues[1].Rule != RuleHeadingOrder || !strings.Contains(issues[1].Message, `h4 "Deep dive" follows h2`) {
+		t.Errorf("expected h2 -> h4 skip, got %+v", issues[1])
+	}
+}
+
+func TestAuditMarkdown_IgnoresFrontmatter(t *testing.T) {
+	issues, err := AuditMarkdown("---\ntitle: x\n---\n# Post\n\n### Skipped\n\n![](pic.png)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Markdown images always get an alt attribute, possibly empty
+	if len(issues) != 1 || issues[0].Rule != RuleHeadingOrder {
+		t.Errorf("expected one heading-order issue, got %+v", issues)
+	}
+}
+
+func TestAuditThemeCSS(t *testing.T) {
+	css := filepath.Join(t.TempDir(), "t.css")
+	os.WriteFile(css, []byte(`:root {
+    --color-bg: #ffffff;
+    --color-text: #222;
+    --color-text-muted: #aaaaaa;
+    --color-accent: #0055cc;
+    --color-accent-dim: #eeeeee;
+    --color-border: rgba(0, 0, 0, 0.2);
+}
+`), 0644)
+
+	issues, err := AuditThemeCSS(css)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "--color-text-muted") {
+		t.Errorf("expected only text-muted to fail, got %+v", issues)
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	black, _ := parseHexColor("#000")
+	white, _ := parseHexColor("#ffffff")
+	if got := contrastRatio(black, white); got < 20.99 || got > 21.01 {
+		t.Errorf("black on white = %.2f, want 21", got)
+	}
+	if got := contrastRatio(white, white); got != 1 {
+		t.Errorf("white on white = %.2f, want 1", got)
+	}
+	if _, ok := parseHexColor("#ffffff80"); ok {
+		t.Error("expected colors with alpha to be skipped")
+	}
+}
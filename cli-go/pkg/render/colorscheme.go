@@ -0,0 +1,22 @@
+package render
+
+import "github.com/vdibart/polis-cli/cli-go/pkg/theme"
+
+// colorSchemeToggleHTML is a button that switches the page between the
+// theme's light and dark color schemes, remembering the choice in
+// localStorage. The script also applies a remembered choice on load, so
+// themes should place the toggle early in <body>.
+const colorSchemeToggleHTML = `<button type="button" class="color-scheme-toggle" aria-label="Switch between light and dark mode" title="Switch between light and dark mode">&#9680;</button>` +
+	`<script>(function(){var d=document.documentElement,a="` + theme.ColorSchemeAttr + `",k="polis-color-scheme",b=document.currentScript.previousElementSibling;` +
+	`try{var s=localStorage.getItem(k);if(s==="light"||s==="dark")d.setAttribute(a,s)}catch(e){}` +
+	`b.addEventListener("click",function(){var c=d.getAttribute(a)||(matchMedia("(prefers-color-scheme: dark)").matches?"dark":"light");` +
+	`c=c==="dark"?"light":"dark";d.setAttribute(a,c);try{localStorage.setItem(k,c)}catch(e){}})})();</script>`
+
+// colorSchemeToggle returns the {{color_scheme_toggle}} markup, or "" when
+// the theme doesn't declare both color schemes.
+func (r *PageRenderer) colorSchemeToggle() string {
+	if !r.templates.Config.HasColorSchemes() {
+		return ""
+	}
+	return colorSchemeToggleHTML
+}
@@ -0,0 +1,136 @@
+/* polis static comment widget | Generated by polis render; edits are overwritten.
+ *
+ * Loads the blessed comments of the current post from the JSON file that
+ * polis render writes next to it, so comments blessed after the page was
+ * rendered still show up on static hosts. Markup:
+ *
+ *   <div class="polis-comments" data-src="hello.comments.json"
+ *        data-post-url="https://example.com/posts/20260101/hello.md"
+ *        data-reply-url="https://example.com/reply?to={url}"></div>
+ *
+ * Comments replace the contents of the page's .comments-list (or the
+ * element named by data-list) if there is one, otherwise they are listed
+ * inside the widget. Elements with class polis-comment-count get the count.
+ */
+(function () {
+    'use strict';
+
+    var STYLE = '.polis-reply{margin-top:1rem}' +
+        '.polis-reply button{font:inherit;color:inherit;background:none;border:1px solid currentColor;border-radius:4px;padding:.3rem .8rem;cursor:pointer;opacity:.85}' +
+        '.polis-reply button:hover{opacity:1}' +
+        '.polis-reply-help{margin-top:.6rem;font-size:.9em}' +
+        '.polis-reply-help code{display:block;margin-top:.3rem;padding:.4rem .6rem;border:1px solid currentColor;border-radius:4px;overflow-x:auto;white-space:nowrap;opacity:.85}';
+
+    function el(tag, className, text) {
+        var node = document.createElement(tag);
+        if (className) node.className = className;
+        if (text) node.textContent = text;
+        return node;
+    }
+
+    // The post's source URL, which is what a polis comment replies to
+    function postURL(widget) {
+        if (widget.dataset.postUrl) return widget.dataset.postUrl;
+        return location.href.split(/[?#]/)[0].replace(/\.html$/, '.md');
+    }
+
+    function authorName(url) {
+        try {
+            return new URL(url, location.href).hostname;
+        } catch (e) {
+            return url;
+        }
+    }
+
+    function renderComment(c) {
+        var item = el('div', c.featured ? 'comment featured' : 'comment');
+        var header = el('div', 'comment-header');
+        var author = el('a', 'comment-author', c.author || authorName(c.url));
+        author.href = c.url;
+        header.appendChild(author);
+        header.appendChild(el('span', 'comment-date', c.published_human || c.published || ''));
+        item.appendChild(header);
+
+        var body = el('div', 'comment-body');
+        if (c.content) {
+            // Sanitized by polis render when the JSON was written
+            body.innerHTML = c.content;
+        } else {
+            var link = el('a', '', 'Read this comment on ' + authorName(c.url));
+            link.href = c.url;
+            body.appendChild(link);
+        }
+        item.appendChild(body);
+        return item;
+    }
+
+    function renderReply(widget) {
+        var target = postURL(widget);
+        var box = el('div', 'polis-reply');
+        var replyURL = widget.dataset.replyUrl;
+        if (replyURL) {
+            var link = el('a', '', 'Reply via polis');
+            link.href = replyURL.split('{url}').join(encodeURIComponent(target));
+            link.rel = 'nofollow';
+            box.appendChild(link);
+            return box;
+        }
+
+        var button = el('button', '', 'Reply via polis');
+        button.type = 'button';
+        var help = el('div', 'polis-reply-help', 'Reply from your own polis site, and your comment appears here once it is blessed:');
+        help.appendChild(el('code', '', 'polis comment draft ' + target));
+        help.hidden = true;
+        button.addEventListener('click', function () {
+            help.hidden = !help.hidden;
+        });
+        box.appendChild(button);
+        box.appendChild(help);
+        return box;
+    }
+
+    function load(widget) {
+        widget.appendChild(renderReply(widget));
+        if (!widget.dataset.src || !window.fetch) return;
+
+        fetch(widget.dataset.src, { cache: 'no-cache' })
+            .then(function (res) {
+                if (!res.ok) throw new Error(res.status);
+                return res.json();
+            })
+            .then(function (data) {
+                var comments = data.comments || [];
+                var list = document.querySelector(widget.dataset.list || '.comments-list');
+                if (!list) {
+                    list = el('div', 'comments-list');
+                    widget.insertBefore(list, widget.firstChild);
+                }
+                list.textContent = '';
+                comments.forEach(function (c) {
+                    list.appendChild(renderComment(c));
+                });
+                var counts = document.querySelectorAll('.polis-comment-count');
+                for (var i = 0; i < counts.length; i++) {
+                    counts[i].textContent = String(comments.length);
+                }
+            })
+            .catch(function () {
+                // Keep whatever the page was rendered with
+            });
+    }
+
+    function init() {
+        var widgets = document.querySelectorAll('.polis-comments');
+        if (!widgets.length) return;
+        var style = el('style');
+        style.textContent = STYLE;
+        document.head.appendChild(style);
+        for (var i = 0; i < widgets.length; i++) load(widgets[i]);
+    }
+
+    if (document.readyState === 'loading') {
+        document.addEventListener('DOMContentLoaded', init);
+    } else {
+        init();
+    }
+})();
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	figureLine = regexp.MustCompile(`^\s*\{\{figure((?:\s+[a-z]+="[^"]*")*)\s*\}\}\s*$`)
+	figureAttr = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+	codeFence  = regexp.MustCompile("^\\s*(```|~~~)")
+	inlinePara = regexp.MustCompile(`(?s)^<p>(.*)</p>\n?$`)
+)
+
+// expandFigures replaces figure shortcodes, each alone on a line:
+//
+//	{{figure src="attachments/chart.png" alt="Sales by quarter" caption="Q3 was *up*."}}
+//
+// with a <figure> holding the image and, if there is a caption, a
+// <figcaption> rendered as inline markdown. Shortcodes in fenced code blocks
+// are left as they are.
+func expandFigures(markdown string) string {
+	if !strings.Contains(markdown, "{{figure") {
+		return markdown
+	}
+	return mapLinesOutsideFences(markdown, func(line string) string {
+		m := figureLine.FindStringSubmatch(line)
+		if m == nil {
+			return line
+		}
+		attrs := make(map[string]string)
+		for _, a := range figureAttr.FindAllStringSubmatch(m[1], -1) {
+			attrs[a[1]] = a[2]
+		}
+		if attrs["src"] == "" {
+			return line
+		}
+		return "\n" + figureHTML(attrs) + "\n"
+	})
+}
+
+// mapLinesOutsideFences replaces each line of markdown outside fenced code
+// blocks with fn(line).
+func mapLinesOutsideFences(markdown string, fn func(line string) string) string {
+	lines := strings.Split(markdown, "\n")
+	var fence string
+	for i, line := range lines {
+		if m := codeFence.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if m[1] == fence {
+				fence = ""
+			}
+			continue
+		}
+		if fence == "" {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// figureHTML renders one figure shortcode, on a single line so markdown
+// treats it as one HTML block. Attribute values are taken as written, so
+// entities like &quot; work for characters the shortcode can't hold.
+func figureHTML(attrs map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`<figure><img src="` + html.EscapeString(html.UnescapeString(attrs["src"])) + `"`)
+	if alt, ok := attrs["alt"]; ok {
+		b.WriteString(` alt="` + html.EscapeString(html.UnescapeString(alt)) + `"`)
+	}
+	b.WriteString(" />")
+	if caption := attrs["caption"]; caption != "" {
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(html.UnescapeString(caption)), &buf); err == nil {
+			inner := buf.String()
+			if m := inlinePara.FindStringSubmatch(inner); m != nil {
+				inner = m[1]
+			}
+			caption = strings.ReplaceAll(strings.TrimSpace(inner), "\n", " ")
+		}
+		b.WriteString("<figcaption>" + caption + "</figcaption>")
+	}
+	b.WriteString("</figure>")
+	return b.String()
+}
@@ -0,0 +1,292 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "image/gif" // register GIF, JPEG, and PNG decoders for DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// ImageVariantWidths are the widths, in pixels, of the size variants
+// generated for each JPEG and PNG in attachments/. Widths at or above the
+// original's are skipped.
+var ImageVariantWidths = []int{480, 960, 1600}
+
+// ImageSizes is the sizes attribute given to images with a srcset: full
+// viewport width on narrow screens, otherwise about a content column.
+var ImageSizes = "(max-width: 800px) 100vw, 800px"
+
+// resizableTypes are the attachment formats variants are generated for.
+var resizableTypes = map[string]string{".jpg": "image/jpeg", ".jpeg": "image/jpeg", ".png": "image/png"}
+
+// variantName matches a size variant: <original stem>-<width>w.<ext>.
+var variantName = regexp.MustCompile(`^(.+)-(\d+)w\.(jpe?g|png|gif|webp|avif)$`)
+
+// imageVariant is one pre-generated size of an attachment.
+type imageVariant struct {
+	Name  string // File name in attachments/
+	Width int
+	Ext   string // Lowercase, without the dot
+}
+
+// imageSet is an attachment and its size variants.
+type imageSet struct {
+	Width    int // Width of the original, 0 if unknown
+	Variants []imageVariant
+}
+
+// GenerateImageVariants writes the missing ImageVariantWidths variants of
+// every JPEG and PNG in the site's attachments/ directory, next to the
+// original as <name>-<width>w.<ext>. It returns the number written. Variants
+// in other formats (a hand-made .webp, say) are never generated, only used.
+func GenerateImageVariants(dataDir string) (int, error) {
+	dir := filepath.Join(dataDir, publish.AttachmentsDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	existing := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		existing[e.Name()] = true
+	}
+
+	written := 0
+	for _, e := range entries {
+		name := e.Name()
+		contentType := resizableTypes[strings.ToLower(path.Ext(name))]
+		if e.IsDir() || contentType == "" || variantName.MatchString(name) {
+			continue
+		}
+
+		stem := strings.TrimSuffix(name, path.Ext(name))
+		var data []byte
+		var width int
+		for _, w := range ImageVariantWidths {
+			variant := fmt.Sprintf("%s-%dw%s", stem, w, path.Ext(name))
+			if existing[variant] {
+				continue
+			}
+			if data == nil {
+				if data, err = os.ReadFile(filepath.Join(dir, name)); err != nil {
+					return written, err
+				}
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+				if err != nil {
+					break // Not an image we can read; leave it as is
+				}
+				width = cfg.Width
+			}
+			if w >= width {
+				continue
+			}
+			resized, _ := remote.ResizeImage(data, contentType, w)
+			if err := os.WriteFile(filepath.Join(dir, variant), resized, 0644); err != nil {
+				return written, err
+			}
+			existing[variant] = true
+			written++
+		}
+	}
+	return written, nil
+}
+
+// loadImageSets indexes the size variants in attachments/ by the file name
+// of their original.
+func loadImageSets(dataDir string) map[string]*imageSet {
+	dir := filepath.Join(dataDir, publish.AttachmentsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	originals := make(map[string]string) // Stem -> original file name
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && !variantName.MatchString(name) {
+			originals[strings.TrimSuffix(name, path.Ext(name))] = name
+		}
+	}
+
+	sets := make(map[string]*imageSet)
+	for _, e := range entries {
+		m := variantName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		original, ok := originals[m[1]]
+		if !ok {
+			continue
+		}
+		set := sets[original]
+		if set == nil {
+			set = &imageSet{}
+			if f, err := os.Open(filepath.Join(dir, original)); err == nil {
+				if cfg, _, err := image.DecodeConfig(f); err == nil {
+					set.Width = cfg.Width
+				}
+				f.Close()
+			}
+			sets[original] = set
+		}
+		w, _ := strconv.Atoi(m[2])
+		set.Variants = append(set.Variants, imageVariant{Name: e.Name(), Width: w, Ext: imageExt(m[3])})
+	}
+	for _, set := range sets {
+		sort.Slice(set.Variants, func(i, j int) bool { return set.Variants[i].Width < set.Variants[j].Width })
+	}
+	return sets
+}
+
+var (
+	imgAttr     = regexp.MustCompile(`(?i)\s([a-z-]+)\s*=\s*"([^"]*)"`)
+	pictureTags = regexp.MustCompile(`(?i)<(/?)picture\b`)
+)
+
+// optimizeImages rewrites the <img> tags of a rendered page: every image
+// gets loading="lazy" and decoding="async" unless it sets them, and an
+// image from attachments/ with size variants gets a srcset of the variants
+// in its own format, wrapped in a <picture> with a <source> per other
+// format (webp, avif) when there are variants in those. pagePath is the
+// page's site-relative path, for resolving relative src URLs.
+func (r *PageRenderer) optimizeImages(content, pagePath string) string {
+	if !strings.Contains(content, "<img") {
+		return content
+	}
+	sets := r.imageSets()
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range imgTag.FindAllStringIndex(content, -1) {
+		b.WriteString(content[last:loc[0]])
+		last = loc[1]
+		tag := content[loc[0]:loc[1]]
+
+		attrs := make(map[string]string)
+		for _, m := range imgAttr.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2]
+		}
+		var extra []string
+		if _, ok := attrs["loading"]; !ok {
+			extra = append(extra, `loading="lazy"`)
+		}
+		if _, ok := attrs["decoding"]; !ok {
+			extra = append(extra, `decoding="async"`)
+		}
+
+		// Images already in a <picture> or with a srcset are left to the author
+		var sources []string
+		if set := sets[r.attachmentName(attrs["src"], pagePath)]; set != nil && attrs["srcset"] == "" && !insidePicture(content[:loc[0]]) {
+			src := html.UnescapeString(attrs["src"])
+			prefix := src[:strings.LastIndex(src, "/")+1]
+			own := imageExt(path.Ext(src))
+			srcsets := make(map[string][]string)
+			var exts []string
+			for _, v := range set.Variants {
+				if _, ok := srcsets[v.Ext]; !ok && v.Ext != own {
+					exts = append(exts, v.Ext)
+				}
+				srcsets[v.Ext] = append(srcsets[v.Ext], fmt.Sprintf("%s%s %dw", prefix, v.Name, v.Width))
+			}
+			if set.Width > 0 && len(srcsets[own]) > 0 {
+				srcsets[own] = append(srcsets[own], fmt.Sprintf("%s %dw", src, set.Width))
+			}
+			if len(srcsets[own]) > 0 {
+				extra = append(extra, `srcset="`+html.EscapeString(strings.Join(srcsets[own], ", "))+`"`, `sizes="`+ImageSizes+`"`)
+			}
+			for _, ext := range exts {
+				sources = append(sources, fmt.Sprintf(`<source type="image/%s" srcset="%s" sizes="%s" />`,
+					strings.Replace(ext, "jpg", "jpeg", 1), html.EscapeString(strings.Join(srcsets[ext], ", ")), ImageSizes))
+			}
+		}
+
+		if len(extra) > 0 {
+			body, end := tag[:len(tag)-1], ">"
+			if strings.HasSuffix(tag, "/>") {
+				body, end = tag[:len(tag)-2], " />"
+			}
+			tag = strings.TrimRight(body, " ") + " " + strings.Join(extra, " ") + end
+		}
+		if len(sources) > 0 {
+			tag = "<picture>" + strings.Join(sources, "") + tag + "</picture>"
+		}
+		b.WriteString(tag)
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// attachmentName returns the attachments/ file name an image src refers to
+// from pagePath, or "" if it's elsewhere.
+func (r *PageRenderer) attachmentName(src, pagePath string) string {
+	src = html.UnescapeString(src)
+	if base := strings.TrimSuffix(r.config.BaseURL, "/"); base != "" && strings.HasPrefix(src, base+"/") {
+		src = strings.TrimPrefix(src, base)
+	}
+	if strings.Contains(src, "://") || strings.HasPrefix(src, "//") || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	if i := strings.IndexAny(src, "?#"); i >= 0 {
+		src = src[:i]
+	}
+	var rel string
+	if strings.HasPrefix(src, "/") {
+		rel = path.Clean(strings.TrimPrefix(src, "/"))
+	} else {
+		rel = path.Join(path.Dir(pagePath), src)
+	}
+	dir, name := path.Split(rel)
+	if dir != publish.AttachmentsDir+"/" {
+		return ""
+	}
+	return name
+}
+
+// imageExt normalizes a file extension for comparing image formats.
+func imageExt(ext string) string {
+	ext = strings.TrimPrefix(strings.ToLower(ext), ".")
+	if ext == "jpeg" {
+		return "jpg"
+	}
+	return ext
+}
+
+// insidePicture reports whether the end of before is inside a <picture>.
+func insidePicture(before string) bool {
+	depth := 0
+	for _, m := range pictureTags.FindAllStringSubmatch(before, -1) {
+		if m[1] == "" {
+			depth++
+		} else if depth > 0 {
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// imageSets returns the site's attachment size variants, loaded on first use.
+func (r *PageRenderer) imageSets() map[string]*imageSet {
+	if r.images == nil {
+		r.images = loadImageSets(r.config.DataDir)
+		if r.images == nil {
+			r.images = map[string]*imageSet{}
+		}
+	}
+	return r.images
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateImageVariants(t *testing.T) {
+	dir := t.TempDir()
+	attachments := filepath.Join(dir, "attachments")
+	writeTestPNG(t, filepath.Join(attachments, "wide-1a2b3c4d.png"), 1000, 10)
+	writeTestPNG(t, filepath.Join(attachments, "small-5e6f7a8b.png"), 300, 10)
+
+	n, err := GenerateImageVariants(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 variants (480 and 960 of wide), got %d", n)
+	}
+	f, err := os.Open(filepath.Join(attachments, "wide-1a2b3c4d-480w.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if cfg, _, err := image.DecodeConfig(f); err != nil || cfg.Width != 480 {
+		t.Errorf("480w variant: width %d, err %v", cfg.Width, err)
+	}
+
+	if n, _ := GenerateImageVariants(dir); n != 0 {
+		t.Errorf("expected no new variants on a second run, got %d", n)
+	}
+}
+
+func TestRenderFile_ResponsiveImages(t *testing.T) {
+	dir := t.TempDir()
+	setupTestSite(t, dir)
+	attachments := filepath.Join(dir, "attachments")
+	writeTestPNG(t, filepath.Join(attachments, "chart-1a2b3c4d.png"), 1000, 10)
+	os.WriteFile(filepath.Join(attachments, "chart-1a2b3c4d-480w.webp"), []byte("webp"), 0644)
+	if _, err := GenerateImageVariants(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "posts", "20260115"), 0755)
+	os.WriteFile(filepath.Join(dir, "posts", "20260115", "pics.md"), []byte(`---
+title: Pics
+---
+![Chart](../../attachments/chart-1a2b3c4d.png)
+
+![Remote](https://other.example/cat.png)
+
+<img src="/attachments/chart-1a2b3c4d.png" alt="Eager" loading="eager">
+`), 0644)
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: dir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, _, err := renderer.RenderFile("posts/20260115/pics.md", "post", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<picture><source type="image/webp" srcset="../../attachments/chart-1a2b3c4d-480w.webp 480w" sizes="` + ImageSizes + `" />` +
+		`<img src="../../attachments/chart-1a2b3c4d.png" alt="Chart" loading="lazy" decoding="async" ` +
+		`srcset="../../attachments/chart-1a2b3c4d-480w.png 480w, ../../attachments/chart-1a2b3c4d-960w.png 960w, ../../attachments/chart-1a2b3c4d.png 1000w" sizes="` + ImageSizes + `" /></picture>`
+	if !strings.Contains(html, want) {
+		t.Errorf("expected responsive markup\n%s\ngot:\n%s", want, html)
+	}
+	if !strings.Contains(html, `<img src="https://other.example/cat.png" alt="Remote" loading="lazy" decoding="async" />`) {
+		t.Errorf("expected remote image lazy-loaded without srcset, got:\n%s", html)
+	}
+	if !strings.Contains(html, `alt="Eager" loading="eager" decoding="async" srcset="/attachments/chart-1a2b3c4d-480w.png 480w`) {
+		t.Errorf("expected root-relative src resolved and loading kept, got:\n%s", html)
+	}
+}
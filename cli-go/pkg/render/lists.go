@@ -0,0 +1,61 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/followlist"
+)
+
+// RenderFollowLists generates lists/<slug>.html for each of the site's
+// follow lists, with the theme's post.html template. It returns the number
+// of pages generated.
+func (r *PageRenderer) RenderFollowLists() (int, error) {
+	lists, err := followlist.LoadAll(r.config.DataDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range lists {
+		ctx := r.specialPageContext("../")
+		ctx.Title = l.Title
+		ctx.Content = followListContent(l, followlist.URL(r.config.BaseURL, l.Slug))
+		ctx.URL = r.buildURL(followlist.PagePath(l.Slug))
+		if err := r.writeSpecialPage(followlist.PagePath(l.Slug), r.templates.Post, ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(lists), nil
+}
+
+// followListContent renders a list's description and members, and how to
+// subscribe to it.
+func followListContent(l *followlist.List, listURL string) string {
+	var b strings.Builder
+	b.WriteString(`<section class="follow-list">`)
+	if l.Description != "" {
+		fmt.Fprintf(&b, `<p>%s</p>`, html.EscapeString(l.Description))
+	}
+	if len(l.Members) == 0 {
+		b.WriteString(`<p>This list is empty.</p>`)
+	} else {
+		b.WriteString(`<ul>`)
+		for _, m := range l.Members {
+			name := discovery.ExtractDomainFromURL(m.URL)
+			if name == "" {
+				name = m.URL
+			}
+			fmt.Fprintf(&b, `<li><a href="%s">%s</a>`, html.EscapeString(m.URL), html.EscapeString(name))
+			if m.Note != "" {
+				fmt.Fprintf(&b, ` — %s`, html.EscapeString(m.Note))
+			}
+			b.WriteString(`</li>`)
+		}
+		b.WriteString(`</ul>`)
+	}
+	u := html.EscapeString(listURL)
+	fmt.Fprintf(&b, `<p class="follow-list-subscribe">Follow everyone on this list with <code>polis follow-list add %s</code> (<a href="%s">signed list</a>).</p>`, u, u)
+	b.WriteString(`</section>`)
+	return b.String()
+}
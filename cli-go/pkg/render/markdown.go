@@ -30,10 +30,11 @@ func init() {
 	)
 }
 
-// MarkdownToHTML converts markdown content to HTML.
+// MarkdownToHTML converts markdown content to HTML. Figure shortcodes (see
+// expandFigures) become <figure> elements.
 func MarkdownToHTML(markdown string) (string, error) {
 	var buf bytes.Buffer
-	if err := md.Convert([]byte(markdown), &buf); err != nil {
+	if err := md.Convert([]byte(expandFigures(markdown)), &buf); err != nil {
 		return "", err
 	}
 	return buf.String(), nil
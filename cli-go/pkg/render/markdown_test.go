@@ -313,3 +313,21 @@ func BenchmarkMarkdownToHTML_Long(b *testing.B) {
 		MarkdownToHTML(input)
 	}
 }
+
+func TestMarkdownToHTML_Figure(t *testing.T) {
+	input := "Intro.\n{{figure src=\"attachments/chart.png\" alt=\"Sales &quot;chart&quot;\" caption=\"Q3 was *up*.\"}}\nAfter.\n\n```\n{{figure src=\"x.png\"}}\n```\n"
+	got, err := MarkdownToHTML(input)
+	if err != nil {
+		t.Fatalf("MarkdownToHTML failed: %v", err)
+	}
+	want := `<figure><img src="attachments/chart.png" alt="Sales &#34;chart&#34;" /><figcaption>Q3 was <em>up</em>.</figcaption></figure>`
+	if !strings.Contains(got, want) {
+		t.Errorf("expected figure markup %s, got:\n%s", want, got)
+	}
+	if !strings.Contains(got, "<p>Intro.</p>") || !strings.Contains(got, "<p>After.</p>") {
+		t.Errorf("expected surrounding paragraphs kept, got:\n%s", got)
+	}
+	if !strings.Contains(got, `<code>{{figure src=&quot;x.png&quot;}}`) {
+		t.Errorf("expected shortcode in a code block left as is, got:\n%s", got)
+	}
+}
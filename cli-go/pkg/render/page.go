@@ -3,22 +3,31 @@ package render
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/ipfs"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/rss"
+	"github.com/vdibart/polis-cli/cli-go/pkg/sanitize"
 	"github.com/vdibart/polis-cli/cli-go/pkg/template"
 	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+	"github.com/vdibart/polis-cli/cli-go/pkg/wikilink"
 )
 
 // PageConfig holds configuration for page rendering.
 type PageConfig struct {
-	DataDir       string // Site data directory
-	CLIThemesDir  string // CLI themes directory (fallback)
-	BaseURL       string // Site base URL
-	RenderMarkers bool   // Add snippet markers for editing
+	DataDir         string // Site data directory
+	CLIThemesDir    string // CLI themes directory (fallback)
+	BaseURL         string // Site base URL
+	RenderMarkers   bool   // Add snippet markers for editing
+	DiscoveryDomain string // Stream state to read for the stats page ("default" if empty)
 }
 
 // PageRenderer renders polis pages using templates.
@@ -27,16 +36,28 @@ type PageRenderer struct {
 	engine    *template.Engine
 	templates *theme.Templates
 	themeName string
+
+	links        *wikilink.Index                  // Loaded on first use
+	backlinks    map[string][]string              // Loaded on first use
+	translations map[string][]metadata.IndexEntry // Post path -> its translation group; loaded on first use
+	syndication  *metadata.Syndication            // Loaded on first use
+	siteLang     string                           // Loaded on first use
+	images       map[string]*imageSet             // Attachment size variants; loaded on first use
 }
 
 // RenderStats holds statistics from a render operation.
 type RenderStats struct {
-	PostsRendered    int
-	PostsSkipped     int
-	CommentsRendered int
-	CommentsSkipped  int
-	IndexGenerated   bool
-	ArchiveGenerated bool
+	PostsRendered     int
+	PostsSkipped      int
+	CommentsRendered  int
+	CommentsSkipped   int
+	IndexGenerated    bool
+	ArchiveGenerated  bool
+	StatsGenerated    bool
+	AboutGenerated    bool
+	NotFoundGenerated bool
+	ListsGenerated    int
+	FeedGenerated     bool
 }
 
 // NewPageRenderer creates a new page renderer.
@@ -77,7 +98,8 @@ func NewPageRenderer(cfg PageConfig) (*PageRenderer, error) {
 // RenderFile renders a single file (post or comment) to HTML.
 // Returns the rendered HTML, whether it was rendered (vs skipped), and any error.
 func (r *PageRenderer) RenderFile(path string, fileType string, force bool) (string, bool, error) {
-	// Build full paths
+	// Paths are slash-separated (they become URLs); Join converts for the OS
+	path = filepath.ToSlash(path)
 	mdPath := filepath.Join(r.config.DataDir, path)
 	htmlPath := strings.TrimSuffix(mdPath, ".md") + ".html"
 
@@ -101,20 +123,67 @@ func (r *PageRenderer) RenderFile(path string, fileType string, force bool) (str
 		return "", false, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	ctx, err := r.pageContext(path, string(content), fileType)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Render template
+	rendered, err := r.engine.Render(r.pageTemplate(fileType), ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to render template: %w", err)
+	}
+	if fileType == "post" {
+		rendered = injectAnalytics(rendered, path)
+	}
+
+	// Write output
+	if err := os.MkdirAll(filepath.Dir(htmlPath), 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(htmlPath, []byte(rendered), 0644); err != nil {
+		return "", false, fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return rendered, true, nil
+}
+
+// pageContext builds the template context for a post or comment page at
+// path from its markdown source (with frontmatter).
+func (r *PageRenderer) pageContext(path, content, fileType string) (*template.RenderContext, error) {
 	// Parse frontmatter
-	fm := parseFrontmatter(string(content))
-	body := stripFrontmatter(string(content))
+	fm := parseFrontmatter(content)
+	body := stripFrontmatter(content)
+
+	// Expand {{snippet:...}} and {{var:...}}; comments are other people's
+	// words and are left as written
+	if fileType == "post" {
+		body = r.ExpandShortcodes(body).Markdown
+	}
+
+	// Resolve [[wiki links]] to relative links to the posts they name
+	body = r.linkIndex().Rewrite(body, func(target string) string {
+		return relativeURL(path, target)
+	})
 
 	// Convert markdown to HTML
 	htmlContent, err := MarkdownToHTML(body)
 	if err != nil {
-		return "", false, fmt.Errorf("failed to render markdown: %w", err)
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
 	}
+	htmlContent = r.optimizeImages(htmlContent, path)
 
 	// Build render context
 	ctx := template.NewRenderContext()
 	ctx.Title = fm["title"]
 	ctx.Content = htmlContent
+	summary := publish.FrontmatterSummary(content)
+	if summary == "" {
+		summary = publish.Summarize(body)
+	}
+	ctx.Summary = html.EscapeString(summary)
+	ctx.Lang = r.langOf(fm["lang"])
 	ctx.Published = fm["published"]
 	ctx.PublishedHuman = template.FormatHumanDate(fm["published"])
 	ctx.URL = r.buildURL(path)
@@ -134,6 +203,7 @@ func (r *PageRenderer) RenderFile(path string, fileType string, force bool) (str
 		ctx.AuthorName = r.getAuthorDomain()
 	}
 	ctx.AuthorURL = r.config.BaseURL
+	ctx.ColorSchemeToggle = r.colorSchemeToggle()
 
 	// Widget variables
 	ctx.AuthorDomain = r.getAuthorDomain()
@@ -144,11 +214,11 @@ func (r *PageRenderer) RenderFile(path string, fileType string, force bool) (str
 		ctx.InReplyToURL = fm["in_reply_to"]
 		if ctx.InReplyToURL == "" {
 			// Try nested format
-			ctx.InReplyToURL = parseNestedField(string(content), "in-reply-to", "url")
+			ctx.InReplyToURL = parseNestedField(content, "in-reply-to", "url")
 		}
 		ctx.RootPostURL = fm["root_post"]
 		if ctx.RootPostURL == "" {
-			ctx.RootPostURL = parseNestedField(string(content), "in-reply-to", "root-post")
+			ctx.RootPostURL = parseNestedField(content, "in-reply-to", "root-post")
 		}
 	}
 
@@ -157,38 +227,57 @@ func (r *PageRenderer) RenderFile(path string, fileType string, force bool) (str
 		blessedComments, _ := r.loadBlessedCommentsForPost(path)
 		ctx.BlessedComments = blessedComments
 		ctx.BlessedCount = len(blessedComments)
-	}
 
-	// Select template
-	var tmpl string
-	switch fileType {
-	case "post":
-		tmpl = r.templates.Post
-	case "comment":
-		tmpl = r.templates.Comment
-	default:
-		tmpl = r.templates.Post
-	}
+		ctx.Backlinks = r.backlinksForPost(path)
+		ctx.BacklinkCount = len(ctx.Backlinks)
+		ctx.BacklinksSection = backlinksSection(ctx.Backlinks)
 
-	// Render template
-	rendered, err := r.engine.Render(tmpl, ctx)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to render template: %w", err)
+		ctx.HreflangLinks = r.hreflangLinks(path)
+		ctx.IPFSLink = r.ipfsLink(path, ctx.Version)
+
+		// Unlisted posts are shared by direct URL; keep them out of search
+		if publish.FrontmatterUnlisted(content) {
+			ctx.RobotsMeta = `<meta name="robots" content="noindex">`
+		}
+
+		ctx.CommentWidget = r.commentWidget(path)
 	}
 
-	// Write output
-	if err := os.MkdirAll(filepath.Dir(htmlPath), 0755); err != nil {
-		return "", false, fmt.Errorf("failed to create output directory: %w", err)
+	return ctx, nil
+}
+
+// pageTemplate returns the theme template for a page type.
+func (r *PageRenderer) pageTemplate(fileType string) string {
+	if fileType == "comment" {
+		return r.templates.Comment
 	}
+	return r.templates.Post
+}
 
-	if err := os.WriteFile(htmlPath, []byte(rendered), 0644); err != nil {
-		return "", false, fmt.Errorf("failed to write output: %w", err)
+// RenderPreview renders a draft as a post page without writing anything,
+// for sharing before it is published. The page loads its stylesheet from
+// cssPath and is marked noindex.
+func (r *PageRenderer) RenderPreview(markdown, cssPath string) (string, error) {
+	ctx, err := r.pageContext("posts/preview.md", markdown, "post")
+	if err != nil {
+		return "", err
 	}
+	ctx.CSSPath = cssPath
+	ctx.HomePath = r.config.BaseURL
+	ctx.URL = ""
+	ctx.RobotsMeta = `<meta name="robots" content="noindex">`
+	ctx.CommentWidget = ""
 
-	return rendered, true, nil
+	rendered, err := r.engine.Render(r.pageTemplate("post"), ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return rendered, nil
 }
 
-// RenderIndex generates the index.html page.
+// RenderIndex generates the index.html page. When the site has posts in
+// more than one language, it also generates index.<lang>.html for each,
+// listing only the posts in that language.
 func (r *PageRenderer) RenderIndex() error {
 	// Load posts and comments from public.jsonl
 	posts, comments, err := r.loadPublicIndex()
@@ -196,6 +285,31 @@ func (r *PageRenderer) RenderIndex() error {
 		return fmt.Errorf("failed to load public index: %w", err)
 	}
 
+	langs := r.postLanguages(posts)
+	if len(langs) < 2 {
+		langs = nil
+	}
+	if err := r.renderIndexPage("", posts, comments, langs); err != nil {
+		return err
+	}
+	for _, lang := range langs {
+		var filtered []template.PostData
+		for _, p := range posts {
+			if p.Lang == lang {
+				filtered = append(filtered, p)
+			}
+		}
+		if err := r.renderIndexPage(lang, filtered, comments, langs); err != nil {
+			return err
+		}
+	}
+	return r.removeStaleLangIndexes(langs)
+}
+
+// renderIndexPage renders the index template to index.html, or to
+// index.<lang>.html when lang is set. langs lists every language with its
+// own index page (nil if the site has only one).
+func (r *PageRenderer) renderIndexPage(lang string, posts []template.PostData, comments []template.CommentData, langs []string) error {
 	// Build render context
 	ctx := template.NewRenderContext()
 	ctx.SiteURL = r.config.BaseURL
@@ -207,12 +321,19 @@ func (r *PageRenderer) RenderIndex() error {
 		ctx.AuthorName = r.getAuthorDomain()
 	}
 	ctx.AuthorURL = r.config.BaseURL
+	ctx.ColorSchemeToggle = r.colorSchemeToggle()
 	ctx.PostCount = len(posts)
 	ctx.CommentCount = len(comments)
 	ctx.Posts = posts
 	ctx.Comments = comments
 	ctx.AuthorDomain = r.getAuthorDomain()
 	ctx.PageType = "index"
+	ctx.Lang = lang
+	if lang == "" {
+		ctx.Lang = r.getSiteLang()
+	}
+	ctx.HreflangLinks = r.indexHreflangLinks(langs)
+	ctx.LanguageLinks = languageLinks(lang, langs)
 
 	// Load following data (non-fatal if missing)
 	followPath := following.DefaultPath(r.config.DataDir)
@@ -253,18 +374,20 @@ func (r *PageRenderer) RenderIndex() error {
 	}
 
 	// Write output
-	indexPath := filepath.Join(r.config.DataDir, "index.html")
+	filename := langIndexFilename(lang)
+	indexPath := filepath.Join(r.config.DataDir, filename)
 	if err := os.WriteFile(indexPath, []byte(rendered), 0644); err != nil {
-		return fmt.Errorf("failed to write index.html: %w", err)
+		return fmt.Errorf("failed to write %s: %w", filename, err)
 	}
 
 	return nil
 }
 
 // RenderArchive generates the posts/index.html archive page.
-// No-ops silently if the theme doesn't have a posts.html template.
+// No-ops silently if the theme doesn't have a posts.html template, or if
+// SpecialPages.Archive is off.
 func (r *PageRenderer) RenderArchive() error {
-	if r.templates.Archive == "" {
+	if r.templates.Archive == "" || !SpecialPages.Archive {
 		return nil
 	}
 
@@ -285,10 +408,13 @@ func (r *PageRenderer) RenderArchive() error {
 		ctx.AuthorName = r.getAuthorDomain()
 	}
 	ctx.AuthorURL = r.config.BaseURL
+	ctx.ColorSchemeToggle = r.colorSchemeToggle()
 	ctx.PostCount = len(posts)
 	ctx.Posts = posts
+	ctx.ArchiveSection = archiveSection(posts, "../")
 	ctx.AuthorDomain = r.getAuthorDomain()
 	ctx.PageType = "index"
+	ctx.Lang = r.getSiteLang()
 
 	// Render template
 	rendered, err := r.engine.Render(r.templates.Archive, ctx)
@@ -319,7 +445,27 @@ func (r *PageRenderer) RenderAll(force bool) (*RenderStats, error) {
 		return nil, fmt.Errorf("failed to copy CSS: %w", err)
 	}
 
+	// Size variants of new images, used by the srcset of images in pages
+	if _, err := GenerateImageVariants(r.config.DataDir); err != nil {
+		return nil, fmt.Errorf("failed to generate image variants: %w", err)
+	}
+
+	// Rebuild backlinks; posts gaining or losing a link are re-rendered
+	dirty, err := r.updateBacklinks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update backlinks: %w", err)
+	}
+	// So are posts with a translation that changed since they were rendered
+	for p := range r.staleTranslations() {
+		dirty[p] = true
+	}
+	// And posts pinned to IPFS since they were rendered
+	for p := range r.staleIPFSLinks() {
+		dirty[p] = true
+	}
+
 	// Find all posts
+	var postPaths []string
 	postsDir := filepath.Join(r.config.DataDir, "posts")
 	if err := filepath.Walk(postsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -336,7 +482,8 @@ func (r *PageRenderer) RenderAll(force bool) (*RenderStats, error) {
 		}
 
 		relPath, _ := filepath.Rel(r.config.DataDir, path)
-		_, rendered, err := r.RenderFile(relPath, "post", force)
+		postPaths = append(postPaths, filepath.ToSlash(relPath))
+		_, rendered, err := r.RenderFile(relPath, "post", force || dirty[filepath.ToSlash(relPath)])
 		if err != nil {
 			return fmt.Errorf("failed to render %s: %w", relPath, err)
 		}
@@ -351,6 +498,12 @@ func (r *PageRenderer) RenderAll(force bool) (*RenderStats, error) {
 		return nil, err
 	}
 
+	// Comment JSON is rewritten on every render, so newly blessed comments
+	// reach the widget without re-rendering the post
+	if err := r.writeCommentWidget(postPaths); err != nil {
+		return nil, fmt.Errorf("failed to write comment widget: %w", err)
+	}
+
 	// Find all comments
 	commentsDir := filepath.Join(r.config.DataDir, "comments")
 	if err := filepath.Walk(commentsDir, func(path string, info os.FileInfo, err error) error {
@@ -393,9 +546,41 @@ func (r *PageRenderer) RenderAll(force bool) (*RenderStats, error) {
 	if err := r.RenderArchive(); err != nil {
 		return nil, fmt.Errorf("failed to render archive: %w", err)
 	}
-	if r.templates.Archive != "" {
-		stats.ArchiveGenerated = true
+	stats.ArchiveGenerated = r.templates.Archive != "" && SpecialPages.Archive
+
+	// Generate about and not found pages
+	if stats.AboutGenerated, err = r.RenderAboutPage(); err != nil {
+		return nil, fmt.Errorf("failed to render about page: %w", err)
+	}
+	if err := r.RenderNotFoundPage(); err != nil {
+		return nil, fmt.Errorf("failed to render 404 page: %w", err)
 	}
+	stats.NotFoundGenerated = SpecialPages.NotFound
+
+	// Generate follow list pages
+	if stats.ListsGenerated, err = r.RenderFollowLists(); err != nil {
+		return nil, fmt.Errorf("failed to render follow lists: %w", err)
+	}
+
+	// Generate stats page
+	if err := r.RenderStatsPage(); err != nil {
+		return nil, fmt.Errorf("failed to render stats page: %w", err)
+	}
+	if r.templates.Stats != "" {
+		stats.StatsGenerated = true
+	}
+
+	// Generate RSS feeds (public + token-protected private feed)
+	if _, err := rss.Render(rss.Config{
+		DataDir:          r.config.DataDir,
+		BaseURL:          r.config.BaseURL,
+		SiteTitle:        r.getSiteTitle(),
+		Language:         r.getSiteLang(),
+		MarkdownRenderer: r.feedMarkdownToHTML,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to render feed: %w", err)
+	}
+	stats.FeedGenerated = true
 
 	return stats, nil
 }
@@ -443,6 +628,8 @@ func (r *PageRenderer) loadPublicIndex() ([]template.PostData, []template.Commen
 				Published:      entry.Published,
 				PublishedHuman: template.FormatHumanDate(entry.Published),
 				CommentCount:   count,
+				Summary:        html.EscapeString(entry.Summary),
+				Lang:           r.langOf(entry.Lang),
 			})
 		} else if strings.HasPrefix(entry.Path, "comments/") || entry.Type == "comment" {
 			htmlPath := strings.TrimSuffix(entry.Path, ".md") + ".html"
@@ -471,7 +658,345 @@ func (r *PageRenderer) loadPublicIndex() ([]template.PostData, []template.Commen
 	return posts, comments, nil
 }
 
-// loadBlessedCommentsForPost loads blessed comments for a specific post.
+// linkIndex returns the index used to resolve wiki links.
+func (r *PageRenderer) linkIndex() *wikilink.Index {
+	if r.links == nil {
+		ix, err := wikilink.LoadIndex(r.config.DataDir)
+		if err != nil {
+			ix = wikilink.NewIndex(nil)
+		}
+		r.links = ix
+	}
+	return r.links
+}
+
+// updateBacklinks rebuilds metadata/backlinks.json from the published posts
+// and returns the posts whose pages are stale because a link to or from
+// them was added or removed since the last render.
+func (r *PageRenderer) updateBacklinks() (map[string]bool, error) {
+	r.links = nil
+	backlinks, err := r.linkIndex().Backlinks(r.config.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	old, err := metadata.LoadBacklinks(r.config.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	r.backlinks = backlinks
+
+	dirty := make(map[string]bool)
+	for _, p := range wikilink.Changed(old.Posts, backlinks) {
+		dirty[p] = true
+	}
+	_, statErr := os.Stat(filepath.Join(r.config.DataDir, "metadata", metadata.BacklinksFilename))
+	if len(dirty) > 0 || os.IsNotExist(statErr) {
+		if err := metadata.SaveBacklinks(r.config.DataDir, &metadata.Backlinks{
+			Version: metadata.GetGenerator(),
+			Posts:   backlinks,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return dirty, nil
+}
+
+// backlinksForPost returns the posts linking to postPath, with URLs
+// relative to it.
+func (r *PageRenderer) backlinksForPost(postPath string) []template.PostData {
+	if r.backlinks == nil {
+		bl, err := metadata.LoadBacklinks(r.config.DataDir)
+		if err != nil {
+			return []template.PostData{}
+		}
+		r.backlinks = bl.Posts
+	}
+	posts := []template.PostData{}
+	for _, src := range r.backlinks[postPath] {
+		e, ok := r.linkIndex().Post(src)
+		if !ok {
+			continue
+		}
+		posts = append(posts, template.PostData{
+			URL:            relativeURL(postPath, e.Path),
+			Title:          e.Title,
+			Published:      e.Published,
+			PublishedHuman: template.FormatHumanDate(e.Published),
+		})
+	}
+	return posts
+}
+
+// feedMarkdownToHTML renders a feed item body, resolving wiki links to
+// absolute URLs since feed readers have no page to resolve relative ones
+// against.
+func (r *PageRenderer) feedMarkdownToHTML(markdown string) (string, error) {
+	markdown = r.ExpandShortcodes(markdown).Markdown
+	return MarkdownToHTML(r.linkIndex().Rewrite(markdown, func(target string) string {
+		return r.buildURL(strings.TrimSuffix(target, ".md") + ".html")
+	}))
+}
+
+// backlinksSection pre-renders the "Posts that link here" list, or "" when
+// nothing links to the post.
+func backlinksSection(posts []template.PostData) string {
+	if len(posts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<aside class="backlinks"><h2>Posts that link here</h2><ul>`)
+	for _, p := range posts {
+		fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, html.EscapeString(p.URL), html.EscapeString(p.Title))
+	}
+	b.WriteString(`</ul></aside>`)
+	return b.String()
+}
+
+// relativeURL returns the link from the page rendered from fromPath to the
+// page rendered from toPath (both .md paths relative to the site root).
+func relativeURL(fromPath, toPath string) string {
+	depth := strings.Count(filepath.ToSlash(fromPath), "/")
+	return strings.Repeat("../", depth) + strings.TrimSuffix(toPath, ".md") + ".html"
+}
+
+// langOf returns a post's language: its lang field, normalized, or the
+// site's language if it has none (or an invalid one).
+func (r *PageRenderer) langOf(lang string) string {
+	if lang, err := publish.NormalizeLang(strings.Trim(lang, `"'`)); err == nil {
+		return lang
+	}
+	return r.getSiteLang()
+}
+
+// postLanguages returns the languages of posts, the site's language first
+// and the rest in alphabetical order.
+func (r *PageRenderer) postLanguages(posts []template.PostData) []string {
+	siteLang := r.getSiteLang()
+	seen := make(map[string]bool)
+	var langs []string
+	for _, p := range posts {
+		if !seen[p.Lang] {
+			seen[p.Lang] = true
+			langs = append(langs, p.Lang)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if (langs[i] == siteLang) != (langs[j] == siteLang) {
+			return langs[i] == siteLang
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// langIndexFilename returns the index page for lang ("" for all posts).
+func langIndexFilename(lang string) string {
+	if lang == "" {
+		return "index.html"
+	}
+	return "index." + lang + ".html"
+}
+
+// removeStaleLangIndexes deletes index.<lang>.html pages for languages the
+// site no longer has posts in.
+func (r *PageRenderer) removeStaleLangIndexes(langs []string) error {
+	current := make(map[string]bool)
+	for _, lang := range langs {
+		current[langIndexFilename(lang)] = true
+	}
+	matches, err := filepath.Glob(filepath.Join(r.config.DataDir, "index.*.html"))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		name := filepath.Base(m)
+		lang := strings.TrimSuffix(strings.TrimPrefix(name, "index."), ".html")
+		if normalized, err := publish.NormalizeLang(lang); err != nil || normalized != lang || current[name] {
+			continue
+		}
+		if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// languageLinks pre-renders the links between the index pages of a site
+// with several languages, or "" for a site with one.
+func languageLinks(current string, langs []string) string {
+	if len(langs) == 0 {
+		return ""
+	}
+	link := func(href, lang, label string, selected bool) string {
+		attrs := ""
+		if lang != "" {
+			attrs = fmt.Sprintf(` hreflang="%s" lang="%s"`, lang, lang)
+		}
+		if selected {
+			attrs += ` aria-current="page"`
+		}
+		return fmt.Sprintf(`<a href="%s"%s>%s</a>`, href, attrs, label)
+	}
+	var b strings.Builder
+	b.WriteString(`<nav class="languages">`)
+	b.WriteString(link("index.html", "", "All", current == ""))
+	for _, lang := range langs {
+		b.WriteString(link(langIndexFilename(lang), lang, strings.ToUpper(lang), current == lang))
+	}
+	b.WriteString(`</nav>`)
+	return b.String()
+}
+
+// indexHreflangLinks pre-renders the alternate links between index pages,
+// with index.html as the x-default.
+func (r *PageRenderer) indexHreflangLinks(langs []string) string {
+	if len(langs) == 0 {
+		return ""
+	}
+	links := []string{hreflangLink("x-default", r.pageURL("index.html", "index.html"))}
+	for _, lang := range langs {
+		links = append(links, hreflangLink(lang, r.pageURL("index.html", langIndexFilename(lang))))
+	}
+	return strings.Join(links, "\n    ")
+}
+
+// hreflangLinks pre-renders the alternate links between a post and its
+// translations, or "" when it has none.
+func (r *PageRenderer) hreflangLinks(postPath string) string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, e := range r.translationGroup(postPath) {
+		lang := r.langOf(e.Lang)
+		if seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		links = append(links, hreflangLink(lang, r.pageURL(postPath, strings.TrimSuffix(e.Path, ".md")+".html")))
+	}
+	return strings.Join(links, "\n    ")
+}
+
+func hreflangLink(lang, url string) string {
+	return fmt.Sprintf(`<link rel="alternate" hreflang="%s" href="%s">`, lang, html.EscapeString(url))
+}
+
+// loadSyndication returns syndication.json, loading it on first use.
+func (r *PageRenderer) loadSyndication() *metadata.Syndication {
+	if r.syndication == nil {
+		synd, err := metadata.LoadSyndication(r.config.DataDir)
+		if err != nil {
+			synd = &metadata.Syndication{}
+		}
+		r.syndication = synd
+	}
+	return r.syndication
+}
+
+// ipfsLink pre-renders the alternate link to the post's IPFS copy, if the
+// current version has one.
+func (r *PageRenderer) ipfsLink(postPath, version string) string {
+	link, ok := r.loadSyndication().Link(postPath, ipfs.Service)
+	if !ok || link.Version != version {
+		return ""
+	}
+	return `<link rel="alternate" href="` + html.EscapeString(link.URL) + `" title="IPFS copy">`
+}
+
+// staleIPFSLinks returns the posts pinned to IPFS after their page was
+// rendered, whose pages lack the ipfs:// link.
+func (r *PageRenderer) staleIPFSLinks() map[string]bool {
+	synd := r.loadSyndication()
+	stale := make(map[string]bool)
+	for path := range synd.Posts {
+		link, ok := synd.Link(path, ipfs.Service)
+		if !ok {
+			continue
+		}
+		pinned, err := time.Parse(time.RFC3339, link.Posted)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(r.config.DataDir, filepath.FromSlash(strings.TrimSuffix(path, ".md")+".html")))
+		// Posted has whole seconds; a pin in the second of the render counts
+		if err == nil && !pinned.Before(info.ModTime().Truncate(time.Second)) {
+			stale[path] = true
+		}
+	}
+	return stale
+}
+
+// pageURL returns the URL of page (relative to the site root) as linked
+// from the page rendered from fromPath: absolute when the site has a base
+// URL, relative otherwise.
+func (r *PageRenderer) pageURL(fromPath, page string) string {
+	if r.config.BaseURL != "" {
+		return r.buildURL(page)
+	}
+	return strings.Repeat("../", strings.Count(filepath.ToSlash(fromPath), "/")) + page
+}
+
+// translationGroup returns the posts that are translations of the same
+// original as postPath, the original first, or nil if it has none.
+func (r *PageRenderer) translationGroup(postPath string) []metadata.IndexEntry {
+	if r.translations == nil {
+		r.loadTranslations()
+	}
+	return r.translations[postPath]
+}
+
+// loadTranslations groups the published posts by the original they
+// translate (from translation_of in public.jsonl).
+func (r *PageRenderer) loadTranslations() {
+	r.translations = make(map[string][]metadata.IndexEntry)
+	entries, err := metadata.GetPostEntries(r.config.DataDir)
+	if err != nil {
+		return
+	}
+	byPath := make(map[string]metadata.IndexEntry)
+	for _, e := range entries {
+		byPath[e.Path] = e
+	}
+	groups := make(map[string][]metadata.IndexEntry)
+	for _, e := range entries {
+		if _, ok := byPath[e.TranslationOf]; ok {
+			groups[e.TranslationOf] = append(groups[e.TranslationOf], e)
+		}
+	}
+	for root, translations := range groups {
+		sort.SliceStable(translations, func(i, j int) bool { return translations[i].Published < translations[j].Published })
+		group := append([]metadata.IndexEntry{byPath[root]}, translations...)
+		for _, e := range group {
+			r.translations[e.Path] = group
+		}
+	}
+}
+
+// staleTranslations returns the posts whose hreflang links may be out of
+// date: those rendered before one of their translations last changed.
+func (r *PageRenderer) staleTranslations() map[string]bool {
+	r.loadTranslations()
+	modTime := func(path string) time.Time {
+		info, err := os.Stat(filepath.Join(r.config.DataDir, filepath.FromSlash(path)))
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+	stale := make(map[string]bool)
+	for postPath, group := range r.translations {
+		rendered := modTime(strings.TrimSuffix(postPath, ".md") + ".html")
+		for _, e := range group {
+			if modTime(e.Path).After(rendered) {
+				stale[postPath] = true
+				break
+			}
+		}
+	}
+	return stale
+}
+
+// loadBlessedCommentsForPost loads blessed comments for a specific post,
+// in the post's comment sort order.
 func (r *PageRenderer) loadBlessedCommentsForPost(postPath string) ([]template.BlessedCommentData, error) {
 	// Load blessed comments for this specific post
 	comments, err := metadata.GetBlessedCommentsForPost(r.config.DataDir, postPath)
@@ -491,6 +1016,7 @@ func (r *PageRenderer) loadBlessedCommentsForPost(postPath string) ([]template.B
 			Published:      comment.BlessedAt,
 			PublishedHuman: template.FormatHumanDate(comment.BlessedAt),
 			Content:        content,
+			Featured:       comment.Featured,
 		})
 	}
 
@@ -524,13 +1050,14 @@ func (r *PageRenderer) loadLocalCommentContent(commentURL string) string {
 		return ""
 	}
 
-	// Strip frontmatter and render markdown
+	// Strip frontmatter and render markdown. Blessed comments are written
+	// by other people, so the HTML is sanitized before it reaches the page.
 	body := stripFrontmatter(string(data))
 	html, err := MarkdownToHTML(body)
 	if err != nil {
-		return body // Return raw text if rendering fails
+		return sanitize.HTML(body) // Return raw text if rendering fails
 	}
-	return html
+	return sanitize.HTML(html)
 }
 
 // getSiteTitle returns the site title from .well-known/polis.
@@ -596,6 +1123,28 @@ func (r *PageRenderer) getAuthorDomain() string {
 	return extractDomain(wk.BaseURL)
 }
 
+// getSiteLang returns the site's default language from .well-known/polis,
+// or publish.DefaultLang.
+func (r *PageRenderer) getSiteLang() string {
+	if r.siteLang != "" {
+		return r.siteLang
+	}
+	r.siteLang = publish.DefaultLang
+	data, err := os.ReadFile(filepath.Join(r.config.DataDir, ".well-known", "polis"))
+	if err != nil {
+		return r.siteLang
+	}
+	var wk struct {
+		Lang string `json:"lang"`
+	}
+	if err := json.Unmarshal(data, &wk); err == nil && wk.Lang != "" {
+		if lang, err := publish.NormalizeLang(wk.Lang); err == nil {
+			r.siteLang = lang
+		}
+	}
+	return r.siteLang
+}
+
 // buildURL builds a full URL for a file path.
 func (r *PageRenderer) buildURL(path string) string {
 	if r.config.BaseURL == "" {
@@ -1,12 +1,15 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 )
 
 func TestNewPageRenderer(t *testing.T) {
@@ -387,6 +390,33 @@ func TestRenderArchive_NoTemplate(t *testing.T) {
 	}
 }
 
+func TestRenderStatsPage(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	os.WriteFile(filepath.Join(tempDir, ".polis", "themes", "turbo", "stats.html"), []byte("<h1>{{title}}</h1>{{stats_section}}"), 0644)
+	os.MkdirAll(filepath.Join(tempDir, "metadata"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "metadata", "public.jsonl"), []byte(`{"path":"posts/a.md","title":"A","published":"2026-01-05T12:00:00Z","type":"post"}`+"\n"), 0644)
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: tempDir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	if err := renderer.RenderStatsPage(); err != nil {
+		t.Fatalf("RenderStatsPage failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "stats", "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read stats/index.html: %v", err)
+	}
+	if !strings.Contains(string(content), "<dt>Posts</dt><dd>1</dd>") || !strings.Contains(string(content), "<td>2026-01</td>") {
+		t.Errorf("stats page missing post counts: %s", content)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "metadata", "stats.json")); err != nil {
+		t.Errorf("stats.json not cached: %v", err)
+	}
+}
+
 func TestRenderFile_AuthorDomainAndPageType(t *testing.T) {
 	tempDir := t.TempDir()
 	setupTestSite(t, tempDir)
@@ -502,6 +532,248 @@ func TestRenderIndex_PageTypeIsIndex(t *testing.T) {
 }
 
 // setupTestSite creates a minimal polis site structure for testing.
+func TestRenderAll_WikiLinksAndBacklinks(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "post.html"), []byte(`<div class="content">{{content}}</div>{{backlinks_section}}<p class="count">{{backlink_count}}</p>`), 0644)
+
+	writePost := func(path, content string) {
+		full := filepath.Join(tempDir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	writePost("posts/20260101/first.md", "---\ntitle: First\n---\n# First\n")
+	writePost("posts/20260102/second.md", "---\ntitle: Second\n---\nSee [[first]] and [[missing]].\n")
+	os.WriteFile(filepath.Join(tempDir, "metadata", "public.jsonl"), []byte(
+		`{"path":"posts/20260101/first.md","title":"First","type":"post","published":"2026-01-01T00:00:00Z"}`+"\n"+
+			`{"path":"posts/20260102/second.md","title":"Second","type":"post","published":"2026-01-02T00:00:00Z"}`+"\n"), 0644)
+
+	renderer, _ := NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	second, _ := os.ReadFile(filepath.Join(tempDir, "posts", "20260102", "second.html"))
+	if !strings.Contains(string(second), `<a href="../../posts/20260101/first.html">First</a>`) {
+		t.Errorf("Expected resolved wiki link in second.html, got: %s", second)
+	}
+	if !strings.Contains(string(second), `<span class="wikilink-missing">missing</span>`) {
+		t.Errorf("Expected unresolved link marked missing, got: %s", second)
+	}
+
+	first, _ := os.ReadFile(filepath.Join(tempDir, "posts", "20260101", "first.html"))
+	if !strings.Contains(string(first), "Posts that link here") || !strings.Contains(string(first), `<a href="../../posts/20260102/second.html">Second</a>`) {
+		t.Errorf("Expected backlink to second in first.html, got: %s", first)
+	}
+	if !strings.Contains(string(first), `<p class="count">1</p>`) {
+		t.Errorf("Expected backlink_count 1, got: %s", first)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "metadata", "backlinks.json"))
+	if err != nil {
+		t.Fatalf("Expected metadata/backlinks.json: %v", err)
+	}
+	if !strings.Contains(string(data), "posts/20260102/second.md") {
+		t.Errorf("Expected backlink in backlinks.json, got: %s", data)
+	}
+
+	// Dropping the link re-renders first.html, even though first.md is unchanged
+	writePost("posts/20260102/second.md", "---\ntitle: Second\n---\nNo links now.\n")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(tempDir, "posts", "20260102", "second.md"), future, future)
+
+	stats, err := renderer.RenderAll(false)
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	if stats.PostsRendered != 2 {
+		t.Errorf("Expected both posts re-rendered, got %d", stats.PostsRendered)
+	}
+	first, _ = os.ReadFile(filepath.Join(tempDir, "posts", "20260101", "first.html"))
+	if strings.Contains(string(first), "Posts that link here") {
+		t.Errorf("Expected backlink removed from first.html, got: %s", first)
+	}
+}
+
+func TestRenderFile_Summary(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "post.html"), []byte(`<meta name="description" content="{{summary}}">`), 0644)
+
+	postsDir := filepath.Join(tempDir, "posts")
+	os.MkdirAll(postsDir, 0755)
+	os.WriteFile(filepath.Join(postsDir, "auto.md"), []byte("---\ntitle: Auto\n---\n# Auto\n\nFish & \"chips\" today.\n"), 0644)
+	os.WriteFile(filepath.Join(postsDir, "set.md"), []byte("---\ntitle: Set\nsummary: \"Chosen: by hand\"\n---\n# Set\n\nBody.\n"), 0644)
+
+	renderer, _ := NewPageRenderer(PageConfig{DataDir: tempDir})
+
+	html, _, err := renderer.RenderFile("posts/auto.md", "post", true)
+	if err != nil {
+		t.Fatalf("RenderFile failed: %v", err)
+	}
+	if html != `<meta name="description" content="Fish &amp; &#34;chips&#34; today.">` {
+		t.Errorf("expected escaped generated summary, got: %s", html)
+	}
+
+	html, _, _ = renderer.RenderFile("posts/set.md", "post", true)
+	if html != `<meta name="description" content="Chosen: by hand">` {
+		t.Errorf("expected frontmatter summary, got: %s", html)
+	}
+}
+
+func TestRenderAll_Translations(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "post.html"), []byte(`<html lang="{{lang}}">{{hreflang_links}}`), 0644)
+	os.WriteFile(filepath.Join(themesDir, "index.html"), []byte(`<html lang="{{lang}}">{{language_links}}{{#recent_posts}}<a href="{{url}}" lang="{{lang}}">{{title}}</a>{{/recent_posts}}`), 0644)
+
+	writePost := func(path, content string) {
+		full := filepath.Join(tempDir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	writePost("posts/20260101/hello.md", "---\ntitle: Hello\n---\n# Hello\n")
+	writePost("posts/20260102/bonjour.md", "---\ntitle: Bonjour\nlang: fr\ntranslation_of: posts/20260101/hello.md\n---\n# Bonjour\n")
+	os.WriteFile(filepath.Join(tempDir, "metadata", "public.jsonl"), []byte(
+		`{"path":"posts/20260101/hello.md","title":"Hello","type":"post","published":"2026-01-01T00:00:00Z"}`+"\n"+
+			`{"path":"posts/20260102/bonjour.md","title":"Bonjour","type":"post","published":"2026-01-02T00:00:00Z","lang":"fr","translation_of":"posts/20260101/hello.md"}`+"\n"), 0644)
+
+	renderer, _ := NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	alternates := `<link rel="alternate" hreflang="en" href="../../posts/20260101/hello.html">` + "\n    " +
+		`<link rel="alternate" hreflang="fr" href="../../posts/20260102/bonjour.html">`
+	hello, _ := os.ReadFile(filepath.Join(tempDir, "posts", "20260101", "hello.html"))
+	if string(hello) != `<html lang="en">`+alternates {
+		t.Errorf("unexpected hello.html: %s", hello)
+	}
+	bonjour, _ := os.ReadFile(filepath.Join(tempDir, "posts", "20260102", "bonjour.html"))
+	if string(bonjour) != `<html lang="fr">`+alternates {
+		t.Errorf("unexpected bonjour.html: %s", bonjour)
+	}
+
+	index, _ := os.ReadFile(filepath.Join(tempDir, "index.html"))
+	for _, want := range []string{`<a href="index.fr.html" hreflang="fr" lang="fr">FR</a>`, `lang="fr">Bonjour</a>`, `lang="en">Hello</a>`} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("expected %q in index.html, got: %s", want, index)
+		}
+	}
+	fr, err := os.ReadFile(filepath.Join(tempDir, "index.fr.html"))
+	if err != nil {
+		t.Fatalf("expected index.fr.html: %v", err)
+	}
+	if !strings.HasPrefix(string(fr), `<html lang="fr">`) || strings.Contains(string(fr), "Hello</a>") || !strings.Contains(string(fr), `aria-current="page">FR`) {
+		t.Errorf("unexpected index.fr.html: %s", fr)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "index.en.html")); err != nil {
+		t.Errorf("expected index.en.html: %v", err)
+	}
+
+	// A site back to one language drops the per-language pages
+	os.WriteFile(filepath.Join(tempDir, "metadata", "public.jsonl"), []byte(
+		`{"path":"posts/20260101/hello.md","title":"Hello","type":"post","published":"2026-01-01T00:00:00Z"}`+"\n"), 0644)
+	if err := renderer.RenderIndex(); err != nil {
+		t.Fatalf("RenderIndex failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "index.fr.html")); !os.IsNotExist(err) {
+		t.Errorf("expected index.fr.html removed, got err=%v", err)
+	}
+}
+
+func TestRenderAll_CommentWidget(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "post.html"), []byte(`<h1>{{title}}</h1>{{comment_widget}}`), 0644)
+	os.WriteFile(filepath.Join(themesDir, "theme.json"), []byte(`{"comment_widget": true}`), 0644)
+
+	postDir := filepath.Join(tempDir, "posts", "20260101")
+	os.MkdirAll(postDir, 0755)
+	os.WriteFile(filepath.Join(postDir, "hello.md"), []byte("---\ntitle: Hello\n---\n# Hello\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "metadata", "blessed-comments.json"), []byte(`{"version":"1","comments":[{"post":"posts/20260101/hello.md","blessed":[{"url":"https://alice.example.com/comments/20260102/reply.md","version":"sha256:abc","blessed_at":"2026-01-02T00:00:00Z"}]}]}`), 0644)
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: tempDir})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	page, _ := os.ReadFile(filepath.Join(postDir, "hello.html"))
+	if !strings.Contains(string(page), `data-src="hello.comments.json"`) || !strings.Contains(string(page), `<script src="../../comments.js" defer></script>`) {
+		t.Errorf("expected widget markup, got: %s", page)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, CommentWidgetScript)); err != nil {
+		t.Errorf("expected %s: %v", CommentWidgetScript, err)
+	}
+	data, err := os.ReadFile(filepath.Join(postDir, "hello.comments.json"))
+	if err != nil {
+		t.Fatalf("expected comment JSON: %v", err)
+	}
+	var comments PostComments
+	if err := json.Unmarshal(data, &comments); err != nil {
+		t.Fatal(err)
+	}
+	if comments.Count != 1 || len(comments.Comments) != 1 || comments.Comments[0].Author != "alice.example.com" {
+		t.Errorf("unexpected comment JSON: %s", data)
+	}
+
+	// Turning the widget off removes the generated files
+	os.WriteFile(filepath.Join(themesDir, "theme.json"), []byte(`{"comment_widget": false}`), 0644)
+	renderer, _ = NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(true); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	for _, p := range []string{filepath.Join(tempDir, CommentWidgetScript), filepath.Join(postDir, "hello.comments.json")} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed, got err=%v", p, err)
+		}
+	}
+}
+
+func TestRenderAll_ColorSchemeToggle(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "index.html"), []byte(`<body>{{color_scheme_toggle}}<h1>{{site_title}}</h1></body>`), 0644)
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: tempDir})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	page, _ := os.ReadFile(filepath.Join(tempDir, "index.html"))
+	if strings.Contains(string(page), "color-scheme-toggle") {
+		t.Errorf("expected no toggle without color schemes, got: %s", page)
+	}
+
+	os.WriteFile(filepath.Join(themesDir, "light.css"), []byte(":root { --color-bg: #fff; }"), 0644)
+	os.WriteFile(filepath.Join(themesDir, "dark.css"), []byte(":root { --color-bg: #000; }"), 0644)
+	os.WriteFile(filepath.Join(themesDir, "theme.json"), []byte(`{"color_schemes": {"light": "light.css", "dark": "dark.css"}}`), 0644)
+	renderer, _ = NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(true); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	page, _ = os.ReadFile(filepath.Join(tempDir, "index.html"))
+	if !strings.Contains(string(page), `<button type="button" class="color-scheme-toggle"`) {
+		t.Errorf("expected toggle, got: %s", page)
+	}
+	css, _ := os.ReadFile(filepath.Join(tempDir, "styles.css"))
+	if !strings.Contains(string(css), "@media (prefers-color-scheme: dark)") {
+		t.Errorf("expected color scheme rules in styles.css, got: %s", css)
+	}
+}
+
 func setupTestSite(t *testing.T, dir string) {
 	t.Helper()
 
@@ -560,3 +832,43 @@ func setupTestSite(t *testing.T, dir string) {
 	os.MkdirAll(metadataDir, 0755)
 	os.WriteFile(filepath.Join(metadataDir, "public.jsonl"), []byte(""), 0644)
 }
+
+func TestRenderAll_IPFSLink(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	themesDir := filepath.Join(tempDir, ".polis", "themes", "turbo")
+	os.WriteFile(filepath.Join(themesDir, "post.html"), []byte(`<head>{{ipfs_link}}</head>`), 0644)
+
+	postsDir := filepath.Join(tempDir, "posts")
+	os.MkdirAll(postsDir, 0755)
+	os.WriteFile(filepath.Join(postsDir, "pinned.md"), []byte("---\ntitle: Pinned\ncurrent-version: sha256:bbb\n---\n# Pinned\n"), 0644)
+	os.WriteFile(filepath.Join(postsDir, "old.md"), []byte("---\ntitle: Old\ncurrent-version: sha256:new\n---\n# Old\n"), 0644)
+
+	renderer, _ := NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(postsDir, "pinned.html")); string(data) != "<head></head>" {
+		t.Fatalf("unpinned page = %s", data)
+	}
+
+	synd := &metadata.Syndication{Posts: map[string][]metadata.SyndicationLink{
+		"posts/pinned.md": {{Service: "ipfs", URL: "ipfs://bafydir/pinned.html", CID: "bafydir", Version: "sha256:bbb", Posted: time.Now().UTC().Format(time.RFC3339)}},
+		"posts/old.md":    {{Service: "ipfs", URL: "ipfs://bafyold/old.html", CID: "bafyold", Version: "sha256:old", Posted: time.Now().UTC().Format(time.RFC3339)}},
+	}}
+	metadata.SaveSyndication(tempDir, synd)
+
+	// Pinned since the last render: re-rendered without --force
+	renderer, _ = NewPageRenderer(PageConfig{DataDir: tempDir})
+	if _, err := renderer.RenderAll(false); err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(postsDir, "pinned.html"))
+	if string(data) != `<head><link rel="alternate" href="ipfs://bafydir/pinned.html" title="IPFS copy"></head>` {
+		t.Errorf("pinned page = %s", data)
+	}
+	// A copy of an older version isn't linked
+	if data, _ := os.ReadFile(filepath.Join(postsDir, "old.html")); string(data) != "<head></head>" {
+		t.Errorf("page with stale pin = %s", data)
+	}
+}
@@ -0,0 +1,178 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/template"
+)
+
+// SpecialPageOptions selects the site-wide pages RenderAll generates
+// besides the index.
+type SpecialPageOptions struct {
+	Archive  bool `json:"archive"`   // posts/index.html, if the theme has posts.html
+	NotFound bool `json:"not_found"` // 404.html
+	About    bool `json:"about"`     // about/index.html, from snippets/about.md
+}
+
+// SpecialPages is the pages RenderAll generates. Callers set it from the
+// pages.archive, pages.not_found, and pages.about settings. Turning a page
+// off stops it being regenerated; the last generated copy stays until it is
+// deleted.
+var SpecialPages = SpecialPageOptions{Archive: true, NotFound: true, About: true}
+
+// AboutSnippet is the global snippet the about page is rendered from.
+const AboutSnippet = "snippets/about.md"
+
+// RenderAboutPage generates about/index.html from snippets/about.md, with
+// the theme's about.html template or, without one, its post.html. The
+// page title is the snippet's first heading. It reports whether a page was
+// generated: not if SpecialPages.About is off or there is no snippet.
+func (r *PageRenderer) RenderAboutPage() (bool, error) {
+	if !SpecialPages.About {
+		return false, nil
+	}
+	source, err := os.ReadFile(filepath.Join(r.config.DataDir, AboutSnippet))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	markdown := r.ExpandShortcodes(string(source)).Markdown
+	content, err := MarkdownToHTML(markdown)
+	if err != nil {
+		return false, fmt.Errorf("failed to render %s: %w", AboutSnippet, err)
+	}
+
+	ctx := r.specialPageContext("../")
+	ctx.Title = "About"
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			ctx.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			break
+		}
+	}
+	ctx.Content = content
+	ctx.URL = r.buildURL("about/index.html")
+
+	tmpl := r.templates.About
+	if tmpl == "" {
+		tmpl = r.templates.Post
+	}
+	return true, r.writeSpecialPage("about/index.html", tmpl, ctx)
+}
+
+// RenderNotFoundPage generates 404.html at the site root, with the theme's
+// 404.html template or, without one, its post.html. Hosts serve it at any
+// missing address, so its links are absolute. No-ops if
+// SpecialPages.NotFound is off.
+func (r *PageRenderer) RenderNotFoundPage() error {
+	if !SpecialPages.NotFound {
+		return nil
+	}
+
+	root := strings.TrimSuffix(r.config.BaseURL, "/") + "/"
+	ctx := r.specialPageContext(root)
+	ctx.Title = "Page not found"
+	ctx.Content = fmt.Sprintf(`<p>There is nothing at this address. It may have moved, or never existed.</p><p><a href="%s">Go to the home page</a></p>`, html.EscapeString(ctx.HomePath))
+
+	tmpl := r.templates.NotFound
+	if tmpl == "" {
+		tmpl = r.templates.Post
+	}
+	return r.writeSpecialPage("404.html", tmpl, ctx)
+}
+
+// specialPageContext returns the context shared by generated pages, with
+// links to the site root prefixed by root.
+func (r *PageRenderer) specialPageContext(root string) *template.RenderContext {
+	ctx := template.NewRenderContext()
+	ctx.SiteURL = r.config.BaseURL
+	ctx.SiteTitle = r.getSiteTitle()
+	ctx.CSSPath = root + "styles.css"
+	ctx.HomePath = root + "index.html"
+	ctx.AuthorName = r.getAuthorName()
+	if ctx.AuthorName == "" {
+		ctx.AuthorName = r.getAuthorDomain()
+	}
+	ctx.AuthorURL = r.config.BaseURL
+	ctx.ColorSchemeToggle = r.colorSchemeToggle()
+	ctx.AuthorDomain = r.getAuthorDomain()
+	ctx.PageType = "page"
+	ctx.Lang = r.getSiteLang()
+	return ctx
+}
+
+// writeSpecialPage renders tmpl to path, relative to the site root.
+func (r *PageRenderer) writeSpecialPage(path, tmpl string, ctx *template.RenderContext) error {
+	rendered, err := r.engine.Render(tmpl, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	full := filepath.Join(r.config.DataDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// archiveSection renders the {{archive_section}} fragment: posts, newest
+// first, under a heading per year and month. Post URLs are prefixed with
+// root, the path from the page to the site root.
+func archiveSection(posts []template.PostData, root string) string {
+	if len(posts) == 0 {
+		return `<section class="archive"><p>No posts yet.</p></section>`
+	}
+	sorted := append([]template.PostData(nil), posts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Published > sorted[j].Published })
+
+	var b strings.Builder
+	b.WriteString(`<section class="archive">`)
+	year, month := "", ""
+	inList := false
+	for _, p := range sorted {
+		y, m := "Undated", ""
+		if t, err := time.Parse(time.RFC3339, p.Published); err == nil {
+			y, m = t.Format("2006"), t.Format("January")
+		}
+		if y != year {
+			if inList {
+				b.WriteString(`</ul>`)
+				inList = false
+			}
+			if year != "" {
+				b.WriteString(`</section>`)
+			}
+			fmt.Fprintf(&b, `<section class="archive-year"><h2>%s</h2>`, y)
+			year, month = y, "-"
+		}
+		if m != month {
+			if inList {
+				b.WriteString(`</ul>`)
+			}
+			if m != "" {
+				fmt.Fprintf(&b, `<h3 class="archive-month">%s %s</h3>`, m, y)
+			}
+			b.WriteString(`<ul>`)
+			inList, month = true, m
+		}
+		url := p.URL
+		if !strings.Contains(url, "://") {
+			url = root + url
+		}
+		fmt.Fprintf(&b, `<li><a href="%s">%s</a> <time datetime="%s">%s</time></li>`,
+			html.EscapeString(url), html.EscapeString(p.Title), html.EscapeString(p.Published), html.EscapeString(p.PublishedHuman))
+	}
+	b.WriteString(`</ul></section></section>`)
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/followlist"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/template"
+)
+
+func TestArchiveSection(t *testing.T) {
+	posts := []template.PostData{
+		{URL: "posts/20260105/b.html", Title: "B & C", Published: "2026-01-05T00:00:00Z"},
+		{URL: "posts/20251201/a.html", Title: "A", Published: "2025-12-01T00:00:00Z"},
+		{URL: "posts/20260220/d.html", Title: "D", Published: "2026-02-20T00:00:00Z"},
+		{URL: "posts/e.html", Title: "E"},
+	}
+	got := archiveSection(posts, "../")
+
+	want := []string{
+		`<section class="archive-year"><h2>2026</h2><h3 class="archive-month">February 2026</h3><ul><li><a href="../posts/20260220/d.html">D</a>`,
+		`</ul><h3 class="archive-month">January 2026</h3><ul><li><a href="../posts/20260105/b.html">B &amp; C</a>`,
+		`</ul></section><section class="archive-year"><h2>2025</h2><h3 class="archive-month">December 2025</h3>`,
+		`<section class="archive-year"><h2>Undated</h2><ul><li><a href="../posts/e.html">E</a>`,
+	}
+	last := 0
+	for _, w := range want {
+		i := strings.Index(got, w)
+		if i < last {
+			t.Fatalf("expected %q after offset %d in:\n%s", w, last, got)
+		}
+		last = i
+	}
+	if strings.Count(got, "<ul>") != strings.Count(got, "</ul>") || strings.Count(got, "<section") != strings.Count(got, "</section>") {
+		t.Errorf("unbalanced tags:\n%s", got)
+	}
+}
+
+func TestRenderAll_SpecialPages(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	os.MkdirAll(filepath.Join(tempDir, "snippets"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "snippets", "about.md"), []byte("# About me\n\nI write about {{var:site_title}}.\n"), 0644)
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: tempDir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	stats, err := renderer.RenderAll(false)
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	if !stats.AboutGenerated || !stats.NotFoundGenerated {
+		t.Errorf("expected about and 404 pages, got %+v", stats)
+	}
+
+	about, _ := os.ReadFile(filepath.Join(tempDir, "about", "index.html"))
+	if !strings.Contains(string(about), "<title>About me - Test Site</title>") || !strings.Contains(string(about), "I write about Test Site.") {
+		t.Errorf("unexpected about page:\n%s", about)
+	}
+	notFound, _ := os.ReadFile(filepath.Join(tempDir, "404.html"))
+	if !strings.Contains(string(notFound), `<a href="https://example.com/index.html">`) {
+		t.Errorf("unexpected 404 page:\n%s", notFound)
+	}
+
+	// Turned off, pages are no longer generated
+	defer func(saved SpecialPageOptions) { SpecialPages = saved }(SpecialPages)
+	SpecialPages = SpecialPageOptions{}
+	os.Remove(filepath.Join(tempDir, "404.html"))
+	stats, err = renderer.RenderAll(true)
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	if stats.AboutGenerated || stats.NotFoundGenerated || stats.ArchiveGenerated {
+		t.Errorf("expected no special pages, got %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "404.html")); !os.IsNotExist(err) {
+		t.Errorf("expected no 404.html, got err=%v", err)
+	}
+}
+
+func TestRenderAll_FollowLists(t *testing.T) {
+	tempDir := t.TempDir()
+	setupTestSite(t, tempDir)
+	l, _ := followlist.New("writers", "Writers", "https://example.com")
+	l.Description = "People who write well"
+	l.Add("https://alice.example.com", "Essays & notes")
+	privKey, _, _ := signing.GenerateKeypair()
+	l.Sign(privKey)
+	if err := l.Save(tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := NewPageRenderer(PageConfig{DataDir: tempDir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	stats, err := renderer.RenderAll(false)
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+	if stats.ListsGenerated != 1 {
+		t.Errorf("ListsGenerated = %d, want 1", stats.ListsGenerated)
+	}
+	page, _ := os.ReadFile(filepath.Join(tempDir, "lists", "writers.html"))
+	for _, want := range []string{
+		"<h1>Writers</h1>",
+		`<a href="https://alice.example.com">alice.example.com</a> — Essays &amp; notes`,
+		"polis follow-list add https://example.com/metadata/lists/writers.json",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("expected %q in list page:\n%s", want, page)
+		}
+	}
+}
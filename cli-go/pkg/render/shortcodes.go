@@ -0,0 +1,129 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/snippet"
+)
+
+// shortcodePattern matches {{snippet:name}} and {{var:name}} in a post.
+var shortcodePattern = regexp.MustCompile(`\{\{(snippet|var):([A-Za-z0-9_./-]+)\}\}`)
+
+// maxShortcodeDepth bounds snippets including snippets.
+const maxShortcodeDepth = 8
+
+// Expansion is the result of expanding a post's shortcodes.
+type Expansion struct {
+	Markdown string   `json:"markdown"`
+	Warnings []string `json:"warnings"` // Unknown names and include cycles, left unexpanded
+}
+
+// ExpandShortcodes replaces {{snippet:name}} with the named snippet (global
+// snippets first, then the theme's, as for {{> name}} in templates) and
+// {{var:name}} with a site setting: site_title, site_url, site_lang,
+// author_name, author_domain, or year. Snippets may use shortcodes
+// themselves; a snippet that includes itself, directly or not, is left
+// unexpanded with a warning. Shortcodes in code are left as they are.
+func (r *PageRenderer) ExpandShortcodes(markdown string) *Expansion {
+	x := &Expansion{Warnings: []string{}}
+	if !strings.Contains(markdown, "{{") {
+		x.Markdown = markdown
+		return x
+	}
+	vars := r.shortcodeVars()
+	x.Markdown = r.expandShortcodes(markdown, vars, nil, x)
+	return x
+}
+
+func (r *PageRenderer) expandShortcodes(markdown string, vars map[string]string, stack []string, x *Expansion) string {
+	return mapLinesOutsideFences(markdown, func(line string) string {
+		if !strings.Contains(line, "{{") {
+			return line
+		}
+		// Odd-numbered pieces are `inline code`, unless the last backtick
+		// is unclosed
+		parts := strings.Split(line, "`")
+		for i := range parts {
+			if i%2 == 1 && i < len(parts)-1 {
+				continue
+			}
+			parts[i] = shortcodePattern.ReplaceAllStringFunc(parts[i], func(match string) string {
+				m := shortcodePattern.FindStringSubmatch(match)
+				kind, name := m[1], m[2]
+				if kind == "var" {
+					if v, ok := vars[name]; ok {
+						return v
+					}
+					x.warn("unknown variable %q", name)
+					return match
+				}
+				return r.expandSnippet(match, name, vars, stack, x)
+			})
+		}
+		return strings.Join(parts, "`")
+	})
+}
+
+// expandSnippet returns the snippet name, expanded, or match if it can't be.
+func (r *PageRenderer) expandSnippet(match, name string, vars map[string]string, stack []string, x *Expansion) string {
+	for _, s := range stack {
+		if s == name {
+			x.warn("snippet %q includes itself (%s)", name, strings.Join(append(stack, name), " -> "))
+			return match
+		}
+	}
+	if len(stack) >= maxShortcodeDepth {
+		x.warn("snippet %q is nested more than %d deep", name, maxShortcodeDepth)
+		return match
+	}
+
+	content, err := snippet.ReadSnippet(r.config.DataDir, r.config.CLIThemesDir, r.themeName, name, "global")
+	if err != nil {
+		content, err = snippet.ReadSnippet(r.config.DataDir, r.config.CLIThemesDir, r.themeName, name, "theme")
+	}
+	if err != nil {
+		x.warn("unknown snippet %q", name)
+		return match
+	}
+	expanded := r.expandShortcodes(strings.TrimSuffix(content.Content, "\n"), vars, append(stack, name), x)
+	if strings.Contains(expanded, "\n") {
+		// A multi-line snippet is a block of its own
+		return "\n\n" + expanded + "\n\n"
+	}
+	return expanded
+}
+
+func (x *Expansion) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, w := range x.Warnings {
+		if w == msg {
+			return
+		}
+	}
+	x.Warnings = append(x.Warnings, msg)
+}
+
+// shortcodeVars returns the values {{var:name}} can insert, HTML-escaped.
+func (r *PageRenderer) shortcodeVars() map[string]string {
+	author := r.getAuthorName()
+	if author == "" {
+		author = r.getAuthorDomain()
+	}
+	vars := map[string]string{
+		"site_title":    r.getSiteTitle(),
+		"site_url":      r.config.BaseURL,
+		"site_lang":     r.getSiteLang(),
+		"author_name":   author,
+		"author_domain": r.getAuthorDomain(),
+		"year":          strconv.Itoa(time.Now().Year()),
+	}
+	for k, v := range vars {
+		vars[k] = html.EscapeString(v)
+	}
+	return vars
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newShortcodeRenderer(t *testing.T, snippets map[string]string) *PageRenderer {
+	t.Helper()
+	dir := t.TempDir()
+	setupTestSite(t, dir)
+	os.MkdirAll(filepath.Join(dir, "snippets"), 0755)
+	for name, content := range snippets {
+		os.WriteFile(filepath.Join(dir, "snippets", name), []byte(content), 0644)
+	}
+	r, err := NewPageRenderer(PageConfig{DataDir: dir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewPageRenderer failed: %v", err)
+	}
+	return r
+}
+
+func TestExpandShortcodes(t *testing.T) {
+	r := newShortcodeRenderer(t, map[string]string{
+		"about.md":  "Written by {{var:author_name}} for {{snippet:site}}.\n",
+		"site.md":   "{{var:site_title}}",
+		"bio.md":    "First paragraph.\n\nSecond paragraph.\n",
+		"loop-a.md": "A then {{snippet:loop-b}}",
+		"loop-b.md": "B then {{snippet:loop-a}}",
+	})
+
+	tests := []struct {
+		name     string
+		in       string
+		want     string
+		warnings int
+	}{
+		{"var", "Welcome to {{var:site_title}}.", "Welcome to Test Site.", 0},
+		{"nested snippet", "{{snippet:about}}", "Written by Test Author for Test Site.", 0},
+		{"multi-line snippet", "Intro {{snippet:bio}}", "Intro \n\nFirst paragraph.\n\nSecond paragraph.\n\n", 0},
+		{"inline code", "Use `{{var:site_title}}` for {{var:site_title}}.", "Use `{{var:site_title}}` for Test Site.", 0},
+		{"fenced code", "```\n{{var:site_title}}\n```", "```\n{{var:site_title}}\n```", 0},
+		{"unknown", "{{var:nope}} {{snippet:nope}}", "{{var:nope}} {{snippet:nope}}", 2},
+		{"cycle", "{{snippet:loop-a}}", "A then B then {{snippet:loop-a}}", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := r.ExpandShortcodes(tt.in)
+			if x.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", x.Markdown, tt.want)
+			}
+			if len(x.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %v, want %d", x.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestRenderFile_Shortcodes(t *testing.T) {
+	r := newShortcodeRenderer(t, map[string]string{"note.md": "A **bold** note."})
+	os.MkdirAll(filepath.Join(r.config.DataDir, "posts"), 0755)
+	os.WriteFile(filepath.Join(r.config.DataDir, "posts", "hello.md"),
+		[]byte("---\ntitle: Hello\n---\n# Hello\n\n{{snippet:note}}\n"), 0644)
+
+	html, _, err := r.RenderFile("posts/hello.md", "post", true)
+	if err != nil {
+		t.Fatalf("RenderFile failed: %v", err)
+	}
+	if !strings.Contains(html, "A <strong>bold</strong> note.") {
+		t.Errorf("snippet not expanded:\n%s", html)
+	}
+}
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/stats"
+	"github.com/vdibart/polis-cli/cli-go/pkg/template"
+)
+
+// RenderStatsPage computes site statistics, caches them in
+// metadata/stats.json, and generates the stats/index.html page.
+// No-ops silently if the theme doesn't have a stats.html template.
+func (r *PageRenderer) RenderStatsPage() error {
+	if r.templates.Stats == "" {
+		return nil
+	}
+
+	discoveryDomain := r.config.DiscoveryDomain
+	if discoveryDomain == "" {
+		discoveryDomain = "default"
+	}
+	s := stats.Compute(r.config.DataDir, discoveryDomain, r.getAuthorDomain())
+	if err := stats.Save(r.config.DataDir, s); err != nil {
+		return err
+	}
+
+	ctx := template.NewRenderContext()
+	ctx.Title = "Stats"
+	ctx.SiteURL = r.config.BaseURL
+	ctx.SiteTitle = r.getSiteTitle()
+	ctx.CSSPath = "../styles.css"
+	ctx.HomePath = "../index.html"
+	ctx.AuthorName = r.getAuthorName()
+	if ctx.AuthorName == "" {
+		ctx.AuthorName = r.getAuthorDomain()
+	}
+	ctx.AuthorURL = r.config.BaseURL
+	ctx.ColorSchemeToggle = r.colorSchemeToggle()
+	ctx.PostCount = s.Posts
+	ctx.CommentCount = s.CommentsReceived
+	ctx.StatsSection = statsSection(s)
+	ctx.AuthorDomain = r.getAuthorDomain()
+	ctx.PageType = "stats"
+	ctx.Lang = r.getSiteLang()
+
+	rendered, err := r.engine.Render(r.templates.Stats, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to render stats template: %w", err)
+	}
+
+	statsDir := filepath.Join(r.config.DataDir, "stats")
+	if err := os.MkdirAll(statsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(statsDir, "index.html"), []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write stats/index.html: %w", err)
+	}
+	return nil
+}
+
+// statsSection renders the {{stats_section}} fragment: a summary list,
+// the monthly activity table, and the most active domains.
+func statsSection(s *stats.Stats) string {
+	var b strings.Builder
+	b.WriteString(`<section class="site-stats"><dl class="stats-summary">`)
+	fmt.Fprintf(&b, `<dt>Posts</dt><dd>%d</dd>`, s.Posts)
+	if s.AvgDaysBetweenPosts > 0 {
+		fmt.Fprintf(&b, `<dt>Days between posts</dt><dd>%.1f</dd>`, s.AvgDaysBetweenPosts)
+	}
+	fmt.Fprintf(&b, `<dt>Comments received</dt><dd>%d</dd>`, s.CommentsReceived)
+	fmt.Fprintf(&b, `<dt>Comments given</dt><dd>%d</dd>`, s.CommentsGiven)
+	if s.BlessingsTimed > 0 {
+		fmt.Fprintf(&b, `<dt>Hours to blessing</dt><dd>%.1f</dd>`, s.AvgTimeToBlessingHours)
+	}
+	b.WriteString(`</dl>`)
+
+	if months := s.Months(); len(months) > 0 {
+		b.WriteString(`<table class="stats-months"><thead><tr><th>Month</th><th>Posts</th><th>Comments received</th><th>Comments given</th></tr></thead><tbody>`)
+		for _, m := range months {
+			fmt.Fprintf(&b, `<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td></tr>`, html.EscapeString(m.Month), m.Posts, m.Received, m.Given)
+		}
+		b.WriteString(`</tbody></table>`)
+	}
+
+	if len(s.TopDomains) > 0 {
+		b.WriteString(`<table class="stats-domains"><thead><tr><th>Site</th><th>Comments received</th><th>Comments given</th></tr></thead><tbody>`)
+		for _, d := range s.TopDomains {
+			domain := html.EscapeString(d.Domain)
+			fmt.Fprintf(&b, `<tr><td><a href="https://%s">%s</a></td><td>%d</td><td>%d</td></tr>`, domain, domain, d.Received, d.Given)
+		}
+		b.WriteString(`</tbody></table>`)
+	}
+	b.WriteString(`</section>`)
+	return b.String()
+}
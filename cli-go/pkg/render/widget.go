@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// CommentWidgetScript is the static comment widget, written to the site
+// root when the theme's theme.json sets "comment_widget": true.
+const CommentWidgetScript = "comments.js"
+
+// CommentsJSONSuffix replaces .md in a post's path to name the JSON file of
+// its blessed comments (posts/20260101/hello.comments.json).
+const CommentsJSONSuffix = ".comments.json"
+
+//go:embed comments.js
+var commentWidgetJS []byte
+
+// PostComments is the per-post comment data loaded by the widget.
+type PostComments struct {
+	Post     string        `json:"post"`
+	Count    int           `json:"count"`
+	Comments []CommentJSON `json:"comments"`
+}
+
+// CommentJSON is one blessed comment in a post's comment JSON.
+type CommentJSON struct {
+	URL            string `json:"url"`
+	Author         string `json:"author"`
+	Published      string `json:"published"`
+	PublishedHuman string `json:"published_human"`
+	Content        string `json:"content,omitempty"` // Sanitized HTML; only for comments stored on this site
+	Featured       bool   `json:"featured,omitempty"`
+}
+
+// commentsJSONPath returns the comment JSON path of the post at postPath.
+func commentsJSONPath(postPath string) string {
+	return strings.TrimSuffix(postPath, ".md") + CommentsJSONSuffix
+}
+
+// commentWidget pre-renders the widget markup for the post at postPath,
+// or returns "" when the theme doesn't enable the widget.
+func (r *PageRenderer) commentWidget(postPath string) string {
+	cfg := r.templates.Config
+	if !cfg.CommentWidget {
+		return ""
+	}
+	attrs := fmt.Sprintf(` data-src="%s"`, html.EscapeString(path.Base(commentsJSONPath(postPath))))
+	if r.config.BaseURL != "" {
+		attrs += fmt.Sprintf(` data-post-url="%s"`, html.EscapeString(r.buildURL(postPath)))
+	}
+	if cfg.ReplyURL != "" {
+		attrs += fmt.Sprintf(` data-reply-url="%s"`, html.EscapeString(cfg.ReplyURL))
+	}
+	script := strings.Repeat("../", strings.Count(postPath, "/")) + CommentWidgetScript
+	return fmt.Sprintf("<div class=\"polis-comments\"%s></div>\n<script src=\"%s\" defer></script>", attrs, script)
+}
+
+// writeCommentWidget writes comments.js and the comment JSON of each post
+// in postPaths. When the theme doesn't enable the widget, files left over
+// from an earlier render are removed instead.
+func (r *PageRenderer) writeCommentWidget(postPaths []string) error {
+	scriptPath := filepath.Join(r.config.DataDir, CommentWidgetScript)
+	if !r.templates.Config.CommentWidget {
+		for _, p := range append([]string{scriptPath}, jsonPaths(r.config.DataDir, postPaths)...) {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := writeIfChanged(scriptPath, commentWidgetJS); err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommentWidgetScript, err)
+	}
+	for _, postPath := range postPaths {
+		blessed, err := r.loadBlessedCommentsForPost(postPath)
+		if err != nil {
+			return err
+		}
+		data := PostComments{Post: r.buildURL(postPath), Count: len(blessed), Comments: []CommentJSON{}}
+		for _, c := range blessed {
+			data.Comments = append(data.Comments, CommentJSON{
+				URL:            c.URL,
+				Author:         c.AuthorName,
+				Published:      c.Published,
+				PublishedHuman: c.PublishedHuman,
+				Content:        c.Content,
+				Featured:       c.Featured,
+			})
+		}
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		jsonPath := filepath.Join(r.config.DataDir, filepath.FromSlash(commentsJSONPath(postPath)))
+		if err := writeIfChanged(jsonPath, append(out, '\n')); err != nil {
+			return fmt.Errorf("failed to write comments for %s: %w", postPath, err)
+		}
+	}
+	return nil
+}
+
+// jsonPaths returns the comment JSON file paths of postPaths.
+func jsonPaths(dataDir string, postPaths []string) []string {
+	paths := make([]string, len(postPaths))
+	for i, p := range postPaths {
+		paths[i] = filepath.Join(dataDir, filepath.FromSlash(commentsJSONPath(p)))
+	}
+	return paths
+}
+
+// writeIfChanged writes data to path unless the file already holds it, so
+// unchanged files keep their modification time for incremental deploys.
+func writeIfChanged(path string, data []byte) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
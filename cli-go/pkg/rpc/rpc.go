@@ -0,0 +1,196 @@
+// Package rpc implements the local JSON-RPC control socket used by editor
+// integrations.
+//
+// `polis rpc` listens on a Unix socket, normally .polis/rpc.sock inside the
+// site directory, and speaks JSON-RPC 2.0 with one message per line. Editor
+// plugins keep a connection open and call methods such as draft.save,
+// post.publish, and render.preview without going through polis serve or
+// starting a CLI process per call.
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"sync"
+)
+
+// SocketFilename is the RPC socket name, relative to .polis/.
+const SocketFilename = "rpc.sock"
+
+// maxMessageSize bounds a single request line. Drafts are sent inline, so
+// this is generous.
+const maxMessageSize = 16 << 20
+
+// Standard JSON-RPC 2.0 error codes, plus CodeFailed for method errors.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeFailed         = -32000
+)
+
+// Request is a JSON-RPC 2.0 request. A request without an ID is a
+// notification and gets no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object. Methods may return one to pick
+// the code; any other error is reported with CodeFailed.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// InvalidParams returns an error reported with CodeInvalidParams.
+func InvalidParams(msg string) error {
+	return &Error{Code: CodeInvalidParams, Message: msg}
+}
+
+// Method handles one RPC method. params is the raw params value, or nil
+// when the request had none.
+type Method func(params json.RawMessage) (interface{}, error)
+
+// Server dispatches JSON-RPC requests to registered methods. Requests on
+// one connection are handled in order.
+type Server struct {
+	mu      sync.RWMutex
+	methods map[string]Method
+}
+
+// NewServer returns a server with no methods.
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Method)}
+}
+
+// Register adds or replaces a method.
+func (s *Server) Register(name string, m Method) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[name] = m
+}
+
+// Methods returns the registered method names, sorted.
+func (s *Server) Methods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Serve accepts connections on ln until it is closed.
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn reads newline-delimited requests from rw and writes one
+// response line per call until rw reaches EOF.
+func (s *Server) ServeConn(rw io.ReadWriter) error {
+	scanner := bufio.NewScanner(rw)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	enc := json.NewEncoder(rw)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.handle(line); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle runs one request line and returns its response, or nil for a
+// notification.
+func (s *Server) handle(line []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "parse error: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+
+	s.mu.RLock()
+	m, ok := s.methods[req.Method]
+	s.mu.RUnlock()
+
+	var resp *Response
+	if !ok {
+		resp = errorResponse(req.ID, CodeMethodNotFound, "method not found: "+req.Method)
+	} else if result, err := call(m, req.Params); err != nil {
+		var rpcErr *Error
+		if errors.As(err, &rpcErr) {
+			resp = errorResponse(req.ID, rpcErr.Code, rpcErr.Message)
+		} else {
+			resp = errorResponse(req.ID, CodeFailed, err.Error())
+		}
+	} else {
+		resp = &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+// call runs a method, turning a panic into an internal error so one bad
+// request doesn't take the socket down.
+func call(m Method, params json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Error{Code: CodeInternalError, Message: "internal error"}
+		}
+	}()
+	result, err = m(params)
+	if err == nil && result == nil {
+		result = struct{}{}
+	}
+	return result, err
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: msg}}
+}
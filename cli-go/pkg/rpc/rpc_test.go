@@ -0,0 +1,129 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// session runs ServeConn on one end of a pipe and returns a function that
+// sends a request line and reads the response.
+func session(t *testing.T, s *Server) func(line string) Response {
+	t.Helper()
+	client, conn := net.Pipe()
+	go s.ServeConn(conn)
+	t.Cleanup(func() { client.Close() })
+	r := bufio.NewReader(client)
+	return func(line string) Response {
+		t.Helper()
+		if _, err := client.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		b, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp Response
+		if err := json.Unmarshal(b, &resp); err != nil {
+			t.Fatalf("bad response %s: %v", b, err)
+		}
+		return resp
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := NewServer()
+	s.Register("fail", func(json.RawMessage) (interface{}, error) { return nil, errors.New("boom") })
+	s.Register("panic", func(json.RawMessage) (interface{}, error) { panic("oops") })
+	call := session(t, s)
+
+	tests := []struct {
+		line string
+		code int
+	}{
+		{`{not json`, CodeParseError},
+		{`{"id":1,"method":"fail"}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"missing"}`, CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":1,"method":"fail"}`, CodeFailed},
+		{`{"jsonrpc":"2.0","id":1,"method":"panic"}`, CodeInternalError},
+	}
+	for _, tt := range tests {
+		resp := call(tt.line)
+		if resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: error = %+v, want code %d", tt.line, resp.Error, tt.code)
+		}
+	}
+}
+
+func TestServer_NotificationGetsNoResponse(t *testing.T) {
+	calls := 0
+	s := NewServer()
+	s.Register("ping", func(json.RawMessage) (interface{}, error) {
+		calls++
+		return "pong", nil
+	})
+	call := session(t, s)
+
+	// The notification is handled silently; the next response is for id 2
+	resp := call(`{"jsonrpc":"2.0","method":"ping"}` + "\n" + `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if string(resp.ID) != "2" || resp.Result != "pong" {
+		t.Errorf("response = %+v", resp)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestSiteServer(t *testing.T) {
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	os.MkdirAll(filepath.Join(dataDir, ".polis", "keys"), 0700)
+	os.WriteFile(filepath.Join(dataDir, ".polis", "keys", "id_ed25519"), privKey, 0600)
+	call := session(t, NewSiteServer(SiteOptions{DataDir: dataDir, Version: "test"}))
+
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"render.preview","params":{"markdown":"---\nslug: x\n---\n# Hello\n\n*hi*"}}`)
+	if resp.Error != nil {
+		t.Fatalf("render.preview: %v", resp.Error)
+	}
+	preview := resp.Result.(map[string]interface{})
+	if preview["title"] != "Hello" || !strings.Contains(preview["html"].(string), "<em>hi</em>") {
+		t.Errorf("preview = %v", preview)
+	}
+
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"draft.save","params":{"id":"idea","markdown":"# Idea\n\nBody.\n"}}`)
+	if resp.Error != nil {
+		t.Fatalf("draft.save: %v", resp.Error)
+	}
+	if _, err := os.Stat(publish.DraftPath(dataDir, "idea")); err != nil {
+		t.Fatalf("draft not saved: %v", err)
+	}
+
+	resp = call(`{"jsonrpc":"2.0","id":3,"method":"post.publish","params":{"draft_id":"idea"}}`)
+	if resp.Error != nil {
+		t.Fatalf("post.publish: %v", resp.Error)
+	}
+	result := resp.Result.(map[string]interface{})
+	if result["title"] != "Idea" || result["path"] == "" {
+		t.Errorf("publish result = %v", result)
+	}
+	if _, err := os.Stat(publish.DraftPath(dataDir, "idea")); !os.IsNotExist(err) {
+		t.Error("draft should be removed after publishing")
+	}
+
+	resp = call(`{"jsonrpc":"2.0","id":4,"method":"post.publish","params":{"draft_id":"idea"}}`)
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("missing draft: error = %+v", resp.Error)
+	}
+	resp = call(`{"jsonrpc":"2.0","id":5,"method":"draft.save","params":{"markdwn":"typo"}}`)
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("unknown field: error = %+v", resp.Error)
+	}
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// SiteOptions configures the methods served for a site.
+type SiteOptions struct {
+	DataDir string
+	Version string // Reported by ping
+}
+
+// DraftSaveParams are the params of draft.save. An empty ID creates a new
+// draft.
+type DraftSaveParams struct {
+	ID       string `json:"id,omitempty"`
+	Markdown string `json:"markdown"`
+}
+
+// PublishParams are the params of post.publish: either Markdown (with
+// optional frontmatter) or DraftID.
+type PublishParams struct {
+	Markdown  string `json:"markdown,omitempty"`
+	DraftID   string `json:"draft_id,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+	Unlisted  bool   `json:"unlisted,omitempty"`
+	Followers bool   `json:"followers,omitempty"`
+	Keep      bool   `json:"keep,omitempty"`
+}
+
+// PreviewParams are the params of render.preview.
+type PreviewParams struct {
+	Markdown string `json:"markdown"`
+}
+
+// NewSiteServer returns a server with the site methods:
+//
+//	ping            {}                                  → {version, data_dir}
+//	draft.save      {id?, markdown}                     → {id, path}
+//	post.publish    {markdown | draft_id, filename?,
+//	                 unlisted?, followers?, keep?}      → publish result
+//	render.preview  {markdown}                          → {title, html}
+func NewSiteServer(opts SiteOptions) *Server {
+	s := NewServer()
+	s.Register("ping", func(json.RawMessage) (interface{}, error) {
+		return map[string]string{"version": opts.Version, "data_dir": opts.DataDir}, nil
+	})
+	s.Register("draft.save", func(raw json.RawMessage) (interface{}, error) {
+		var p DraftSaveParams
+		if err := decodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+		id, err := publish.SaveDraft(opts.DataDir, p.ID, p.Markdown)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(opts.DataDir, publish.DraftPath(opts.DataDir, id))
+		return map[string]string{"id": id, "path": rel}, nil
+	})
+	s.Register("post.publish", func(raw json.RawMessage) (interface{}, error) {
+		var p PublishParams
+		if err := decodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+		return publishPost(opts, p)
+	})
+	s.Register("render.preview", func(raw json.RawMessage) (interface{}, error) {
+		var p PreviewParams
+		if err := decodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+		markdown := p.Markdown
+		if publish.HasFrontmatter(markdown) {
+			markdown = publish.StripFrontmatter(markdown)
+		}
+		html, err := render.MarkdownToHTML(markdown)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"title": publish.ExtractTitle(markdown), "html": html}, nil
+	})
+	return s
+}
+
+// publishPost publishes inline markdown or a draft, the way polis post does.
+// New posts use the directory layout in publish.PostPathFormat.
+func publishPost(opts SiteOptions, p PublishParams) (interface{}, error) {
+	if (p.Markdown == "") == (p.DraftID == "") {
+		return nil, InvalidParams("exactly one of markdown or draft_id is required")
+	}
+	var backend string
+	if cfg, err := config.Load(config.Options{DataDir: opts.DataDir}); err == nil {
+		backend = cfg.Get("signing_backend")
+	}
+	privKey, err := signing.LoadKey(filepath.Join(opts.DataDir, ".polis", "keys"), backend)
+	if err != nil {
+		return nil, err
+	}
+	override := publish.PublishOptions{
+		Slug:          p.Filename,
+		Unlisted:      p.Unlisted,
+		FollowersOnly: p.Followers,
+	}
+
+	var result *publish.PublishResult
+	if p.DraftID != "" {
+		result, err = publish.PublishDraftWithOptions(opts.DataDir, p.DraftID, "", p.Keep, override, privKey)
+		if errors.Is(err, publish.ErrDraftNotFound) {
+			return nil, InvalidParams("no draft with ID " + p.DraftID)
+		}
+		// A result with an error means the post was published but the
+		// draft couldn't be removed
+		if result == nil {
+			return nil, err
+		}
+	} else {
+		markdown := p.Markdown
+		po := publish.SourceOptions(markdown)
+		if p.Filename != "" {
+			po.Slug = p.Filename
+		}
+		po.Unlisted = po.Unlisted || p.Unlisted
+		po.FollowersOnly = po.FollowersOnly || p.Followers
+		if publish.HasFrontmatter(markdown) {
+			markdown = publish.StripFrontmatter(markdown)
+		}
+		if result, err = publish.PublishPostWithOptions(opts.DataDir, markdown, po, privKey); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{
+		"success":          result.Success,
+		"path":             result.Path,
+		"title":            result.Title,
+		"version":          result.Version,
+		"signature":        result.Signature,
+		"unlisted":         result.Unlisted,
+		"followers_only":   result.FollowersOnly,
+		"unresolved_links": result.UnresolvedLinks,
+	}, nil
+}
+
+// decodeParams unmarshals params into v, rejecting unknown fields so typos
+// in editor plugins surface as errors.
+func decodeParams(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return InvalidParams("params are required")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return InvalidParams("invalid params: " + err.Error())
+	}
+	return nil
+}
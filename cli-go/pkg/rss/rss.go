@@ -0,0 +1,283 @@
+// Package rss generates RSS 2.0 feeds for a polis site.
+//
+// Two feeds are produced on every render:
+//
+//	feed.xml              — public posts only
+//	feeds/<token>.xml     — all posts, including unlisted and followers-only
+//
+// The private feed's filename is a secret token stored in .polis/feed-token.json.
+// Anyone holding the URL can subscribe with a normal RSS reader; rotating the
+// token invalidates the old URL.
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+const (
+	// PublicFeedFilename is the public feed location relative to the site root.
+	PublicFeedFilename = "feed.xml"
+
+	// PrivateFeedDir holds token-named private feeds relative to the site root.
+	PrivateFeedDir = "feeds"
+
+	// DefaultMaxItems is the number of posts included in each feed.
+	DefaultMaxItems = 50
+)
+
+// Config holds configuration for feed generation.
+type Config struct {
+	DataDir          string                       // Site data directory
+	BaseURL          string                       // Site base URL (links are relative if empty)
+	SiteTitle        string                       // Channel title
+	Language         string                       // Channel language tag (omitted if empty)
+	MaxItems         int                          // Max items per feed (0 = DefaultMaxItems)
+	MarkdownRenderer func(string) (string, error) // Renders post bodies to HTML (nil = omit body)
+}
+
+// Item is a single post prepared for feed output.
+type Item struct {
+	Title       string
+	Path        string // Relative .md path (e.g. posts/20260101/hello.md)
+	Published   string // RFC3339 timestamp
+	Visibility  string // publish.VisibilityPublic, VisibilityUnlisted, or VisibilityFollowers
+	Summary     string // Plain-text excerpt
+	Description string // HTML body
+}
+
+// Result reports what Render wrote.
+type Result struct {
+	PublicItems  int    `json:"public_items"`
+	PrivateItems int    `json:"private_items"`
+	PrivatePath  string `json:"private_path,omitempty"` // Relative path of the private feed, if a token exists
+}
+
+// rssDoc is the XML document structure for RSS 2.0.
+type rssDoc struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	Generator     string    `xml:"generator,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	Content     string  `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// LoadItems reads post entries from the public index, plus unlisted posts
+// from .polis/unlisted.jsonl, and enriches them with visibility and body
+// from each post's frontmatter. Items are returned newest first.
+func LoadItems(cfg Config) ([]Item, error) {
+	entries, err := metadata.GetPostEntries(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	unlisted, err := metadata.LoadUnlistedIndex(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	isUnlisted := make(map[string]bool, len(unlisted))
+	for _, entry := range unlisted {
+		isUnlisted[entry.Path] = true
+	}
+	entries = append(entries, unlisted...)
+
+	items := make([]Item, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		item := Item{
+			Title:      entry.Title,
+			Path:       entry.Path,
+			Published:  entry.Published,
+			Visibility: publish.VisibilityPublic,
+			Summary:    entry.Summary,
+		}
+
+		content, err := os.ReadFile(filepath.Join(cfg.DataDir, entry.Path))
+		if err == nil {
+			item.Visibility = publish.FrontmatterVisibility(string(content))
+			body := publish.StripFrontmatter(string(content))
+			if item.Summary == "" {
+				item.Summary = publish.Summarize(body)
+			}
+			if cfg.MarkdownRenderer != nil {
+				if html, err := cfg.MarkdownRenderer(body); err == nil {
+					item.Description = html
+				}
+			}
+		}
+
+		if isUnlisted[entry.Path] {
+			item.Visibility = publish.VisibilityUnlisted
+		}
+
+		items = append(items, item)
+	}
+	if len(unlisted) > 0 {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Published > items[j].Published })
+	}
+
+	return items, nil
+}
+
+// Build returns the RSS XML for the given items.
+func Build(cfg Config, items []Item, title string) ([]byte, error) {
+	base := strings.TrimSuffix(cfg.BaseURL, "/")
+	link := base + "/"
+	if base == "" {
+		link = "index.html"
+	}
+
+	doc := rssDoc{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          link,
+			Description:   title,
+			Language:      cfg.Language,
+			Generator:     publish.GetGenerator(),
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, item := range items {
+		url := strings.TrimSuffix(item.Path, ".md") + ".html"
+		if base != "" {
+			url = base + "/" + url
+		}
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        url,
+			GUID:        rssGUID{IsPermaLink: "true", Value: url},
+			Description: item.Summary,
+			Content:     item.Description,
+		}
+		if t, err := time.Parse(time.RFC3339, item.Published); err == nil {
+			ri.PubDate = t.UTC().Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// Render writes feed.xml and, when a token has been issued, the private feed.
+// Stale private feeds (from rotated tokens) are removed.
+func Render(cfg Config) (*Result, error) {
+	items, err := LoadItems(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+
+	maxItems := cfg.MaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	}
+
+	var public []Item
+	for _, item := range items {
+		if item.Visibility == publish.VisibilityPublic {
+			public = append(public, item)
+		}
+	}
+	public = limit(public, maxItems)
+
+	result := &Result{PublicItems: len(public)}
+
+	title := cfg.SiteTitle
+	if title == "" {
+		title = cfg.BaseURL
+	}
+
+	data, err := Build(cfg, public, title)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(cfg.DataDir, PublicFeedFilename), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", PublicFeedFilename, err)
+	}
+
+	tok, err := LoadToken(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	privateName := ""
+	if tok != nil {
+		privateName = tok.Token + ".xml"
+		private := limit(items, maxItems)
+		data, err := Build(cfg, private, title+" (followers)")
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Join(cfg.DataDir, PrivateFeedDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s directory: %w", PrivateFeedDir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, privateName), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write private feed: %w", err)
+		}
+		result.PrivateItems = len(private)
+		result.PrivatePath = PrivateFeedDir + "/" + privateName
+	}
+
+	removeStaleFeeds(cfg.DataDir, privateName)
+
+	return result, nil
+}
+
+// removeStaleFeeds deletes private feeds that don't match the current token.
+func removeStaleFeeds(dataDir, keep string) {
+	dir := filepath.Join(dataDir, PrivateFeedDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".xml") || e.Name() == keep {
+			continue
+		}
+		os.Remove(filepath.Join(dir, e.Name()))
+	}
+}
+
+func limit(items []Item, n int) []Item {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,229 @@
+package rss
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+func writePost(t *testing.T, dataDir, path, title, published, visibility string) {
+	t.Helper()
+	fm := "---\ntitle: " + title + "\npublished: " + published + "\n"
+	if visibility != "" {
+		fm += "visibility: " + visibility + "\n"
+	}
+	fm += "---\n# " + title + "\n\nBody of " + title + ".\n"
+
+	full := filepath.Join(dataDir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(fm), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := metadata.AppendPostToIndex(dataDir, path, title, published, "sha256:abc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Posts are classified the way publishing indexes them.
+func TestLoadItems_Visibility(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/a.md", "Plain", "2026-01-01T10:00:00Z", "")
+	writePost(t, dir, "posts/20260102/b.md", "Quoted", "2026-01-02T10:00:00Z", `"Unlisted"`)
+	writePost(t, dir, "posts/20260103/c.md", "Followers", "2026-01-03T10:00:00Z", "followers")
+	writePost(t, dir, "posts/20260104/d.md", "Bogus", "2026-01-04T10:00:00Z", "bogus")
+	writePost(t, dir, "posts/20260105/e.md", "Flagged", "2026-01-05T10:00:00Z", "")
+	os.WriteFile(filepath.Join(dir, "posts/20260105/e.md"), []byte("---\ntitle: Flagged\nunlisted: true\n---\n# Flagged\n"), 0644)
+
+	items, err := LoadItems(Config{DataDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title+"/"+item.Visibility)
+	}
+	if got := strings.Join(titles, ","); got != "Flagged/unlisted,Bogus/public,Followers/followers,Quoted/unlisted,Plain/public" {
+		t.Errorf("items = %s", got)
+	}
+}
+
+func TestRender_PublicOnlyWithoutToken(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/open.md", "Open", "2026-01-01T10:00:00Z", "")
+	writePost(t, dir, "posts/20260102/secret.md", "Secret", "2026-01-02T10:00:00Z", "followers")
+
+	res, err := Render(Config{DataDir: dir, BaseURL: "https://example.com", SiteTitle: "Example"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if res.PublicItems != 1 {
+		t.Errorf("expected 1 public item, got %d", res.PublicItems)
+	}
+	if res.PrivatePath != "" {
+		t.Errorf("expected no private feed without token, got %s", res.PrivatePath)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, PublicFeedFilename))
+	if err != nil {
+		t.Fatalf("feed.xml not written: %v", err)
+	}
+	feed := string(data)
+	if !strings.Contains(feed, "https://example.com/posts/20260101/open.html") {
+		t.Errorf("public feed missing public post: %s", feed)
+	}
+	if strings.Contains(feed, "Secret") {
+		t.Error("public feed must not include followers-only post")
+	}
+}
+
+func TestRender_PrivateFeedIncludesAllAndRotates(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/open.md", "Open", "2026-01-01T10:00:00Z", "")
+	writePost(t, dir, "posts/20260102/hidden.md", "Hidden", "2026-01-02T10:00:00Z", "unlisted")
+
+	first, err := RotateToken(dir)
+	if err != nil {
+		t.Fatalf("RotateToken failed: %v", err)
+	}
+
+	res, err := Render(Config{DataDir: dir, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if res.PrivateItems != 2 {
+		t.Errorf("expected 2 private items, got %d", res.PrivateItems)
+	}
+
+	firstPath := filepath.Join(dir, PrivateFeedDir, first.Token+".xml")
+	data, err := os.ReadFile(firstPath)
+	if err != nil {
+		t.Fatalf("private feed not written: %v", err)
+	}
+	feed := string(data)
+	if strings.Index(feed, "Hidden") > strings.Index(feed, "Open") {
+		t.Error("expected newest item first in private feed")
+	}
+
+	second, err := RotateToken(dir)
+	if err != nil {
+		t.Fatalf("RotateToken failed: %v", err)
+	}
+	if second.Token == first.Token {
+		t.Fatal("rotation should produce a new token")
+	}
+	if _, err := Render(Config{DataDir: dir}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if _, err := os.Stat(firstPath); !os.IsNotExist(err) {
+		t.Error("old private feed should be removed after rotation")
+	}
+	if _, err := os.Stat(filepath.Join(dir, PrivateFeedDir, second.Token+".xml")); err != nil {
+		t.Errorf("new private feed missing: %v", err)
+	}
+
+	if err := RevokeToken(dir); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if _, err := Render(Config{DataDir: dir}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, PrivateFeedDir))
+	if len(entries) != 0 {
+		t.Errorf("expected no private feeds after revoke, got %d", len(entries))
+	}
+}
+
+func TestRender_UnlistedIndexInPrivateFeedOnly(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/open.md", "Open", "2026-01-01T10:00:00Z", "")
+	writePost(t, dir, "posts/20260103/newer.md", "Newer", "2026-01-03T10:00:00Z", "")
+	writePost(t, dir, "posts/20260102/quiet.md", "Quiet", "2026-01-02T10:00:00Z", "unlisted")
+	// Published unlisted posts are indexed in .polis/unlisted.jsonl only
+	entries, _ := metadata.LoadPublicIndex(dir)
+	if err := metadata.RemoveIndexEntry(dir, "posts/20260102/quiet.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metadata.AppendToUnlistedIndex(dir, &entries[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := LoadItems(Config{DataDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title+"/"+item.Visibility)
+	}
+	if got := strings.Join(titles, ","); got != "Newer/public,Quiet/unlisted,Open/public" {
+		t.Errorf("items = %s", got)
+	}
+
+	res, err := Render(Config{DataDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PublicItems != 2 {
+		t.Errorf("expected 2 public items, got %d", res.PublicItems)
+	}
+}
+
+func TestRender_IncludesBodyWhenRendererSet(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/open.md", "Open", "2026-01-01T10:00:00Z", "")
+
+	_, err := Render(Config{
+		DataDir: dir,
+		MarkdownRenderer: func(md string) (string, error) {
+			return "<p>" + strings.TrimSpace(md) + "</p>", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, PublicFeedFilename))
+	if !strings.Contains(string(data), "<content:encoded>&lt;p&gt;# Open") {
+		t.Errorf("expected escaped HTML body in content:encoded, got: %s", data)
+	}
+}
+
+func TestRender_SummaryInDescription(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "posts/20260101/open.md", "Open", "2026-01-01T10:00:00Z", "")
+	writePost(t, dir, "posts/20260102/noted.md", "Noted", "2026-01-02T10:00:00Z", "")
+	if err := metadata.ModifyIndexEntry(dir, "posts/20260102/noted.md", func(e *metadata.IndexEntry) {
+		e.Summary = "A summary from the index."
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Render(Config{DataDir: dir}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, PublicFeedFilename))
+	// Generated from the first paragraph when the index has no summary
+	if !strings.Contains(string(data), "<description>Body of Open.</description>") {
+		t.Errorf("expected generated summary in description, got: %s", data)
+	}
+	if !strings.Contains(string(data), "<description>A summary from the index.</description>") {
+		t.Errorf("expected index summary in description, got: %s", data)
+	}
+}
+
+func TestPrivateFeedURL(t *testing.T) {
+	tok := &Token{Token: "abc123"}
+	if got := PrivateFeedURL("https://example.com/", tok); got != "https://example.com/feeds/abc123.xml" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+	if got := PrivateFeedURL("", tok); got != "feeds/abc123.xml" {
+		t.Errorf("unexpected relative URL: %s", got)
+	}
+	if got := PrivateFeedURL("https://example.com", nil); got != "" {
+		t.Errorf("expected empty URL for nil token, got %s", got)
+	}
+}
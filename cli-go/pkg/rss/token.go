@@ -0,0 +1,95 @@
+package rss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// TokenFilename is the private feed token file, relative to .polis/.
+const TokenFilename = "feed-token.json"
+
+// Token is the secret embedded in the private feed URL.
+type Token struct {
+	Token     string `json:"token"`
+	CreatedAt string `json:"created_at"`
+}
+
+// TokenPath returns the path to .polis/feed-token.json.
+func TokenPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", TokenFilename)
+}
+
+// LoadToken reads the current private feed token. Returns nil if none has been issued.
+func LoadToken(dataDir string) (*Token, error) {
+	data, err := os.ReadFile(TokenPath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read feed token: %w", err)
+	}
+	var tok Token
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return nil, fmt.Errorf("failed to parse feed token: %w", err)
+	}
+	if tok.Token == "" {
+		return nil, nil
+	}
+	return &tok, nil
+}
+
+// RotateToken issues a new private feed token, replacing any existing one.
+// Callers should re-render so the old feed file is removed.
+func RotateToken(dataDir string) (*Token, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	tok := &Token{
+		Token:     hex.EncodeToString(b),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	data, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed token: %w", err)
+	}
+	path := TokenPath(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create .polis directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write feed token: %w", err)
+	}
+	return tok, nil
+}
+
+// RevokeToken removes the private feed token. Callers should re-render so
+// the private feed file is removed.
+func RevokeToken(dataDir string) error {
+	if err := os.Remove(TokenPath(dataDir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove feed token: %w", err)
+	}
+	return nil
+}
+
+// PrivateFeedURL returns the full private feed URL for a token.
+// Returns a site-relative path if baseURL is empty.
+func PrivateFeedURL(baseURL string, tok *Token) string {
+	if tok == nil {
+		return ""
+	}
+	path := PrivateFeedDir + "/" + tok.Token + ".xml"
+	if baseURL == "" {
+		return path
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + path
+}
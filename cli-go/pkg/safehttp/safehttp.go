@@ -0,0 +1,200 @@
+// Package safehttp builds the HTTP clients polis uses for outbound requests
+// to URLs it does not control: remote polis sites and the discovery service.
+//
+// The serve process fetches user-supplied URLs (followed sites, remote
+// posts, bookmarks), so these clients refuse to connect to loopback,
+// private, link-local, and other non-public addresses, require HTTPS, cap
+// redirect chains, and limit how much of a response body is read. The
+// address check runs on the resolved IP at dial time, so a hostname that
+// resolves (or re-resolves) to an internal address is blocked too.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// Client defaults.
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRedirects = 5
+	DefaultMaxBodyBytes = 10 << 20 // 10MB
+)
+
+// AllowLocal disables the private address and HTTPS checks. It is set from
+// the allow_local_fetch setting, for developing against a discovery service
+// or site running on localhost, and by tests that use httptest servers (see
+// package safehttptest).
+var AllowLocal bool
+
+// Errors returned (wrapped in *url.Error) by clients from NewClient.
+var (
+	ErrBlockedAddress   = errors.New("request to a non-public address blocked")
+	ErrInsecureScheme   = errors.New("request must use HTTPS")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrBodyTooLarge     = errors.New("response body too large")
+)
+
+// Options configures a client. Zero values use the defaults above.
+type Options struct {
+	Timeout      time.Duration
+	MaxRedirects int
+	MaxBodyBytes int64
+}
+
+// NewClient returns an HTTP client that enforces the outbound request policy.
+func NewClient(opts Options) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = DefaultMaxRedirects
+	}
+	maxRedirects := opts.MaxRedirects
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: NewTransport(opts.MaxBodyBytes),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return ErrTooManyRedirects
+			}
+			return checkScheme(req)
+		},
+	}
+}
+
+// Transport is an http.RoundTripper that enforces the outbound request
+// policy. Use NewTransport to create one.
+type Transport struct {
+	base         *http.Transport
+	maxBodyBytes int64
+}
+
+// NewTransport returns a Transport that reads at most maxBodyBytes of each
+// response body (0 = DefaultMaxBodyBytes).
+func NewTransport(maxBodyBytes int64) *Transport {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}
+	return &Transport{
+		// No proxy: the address check must see the real destination.
+		base: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkScheme(req); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ContentLength > t.maxBodyBytes {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w (%d bytes)", ErrBodyTooLarge, resp.ContentLength)
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.maxBodyBytes}
+	return resp, nil
+}
+
+// IsPublicAddr reports whether addr is a globally routable unicast address.
+// Loopback, private (RFC 1918, fc00::/7), link-local, CGNAT (100.64/10),
+// multicast, and unspecified addresses are not.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// blockedPrefixes are non-public ranges the netip predicates don't cover.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewDialer returns a dialer that applies the address check, for protocols
+// dialed directly rather than through an HTTP client (such as WebSocket).
+func NewDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{Timeout: timeout, Control: dialControl}
+}
+
+func checkScheme(req *http.Request) error {
+	if AllowLocal || req.URL.Scheme == "https" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInsecureScheme, req.URL.Redacted())
+}
+
+// dialControl runs after DNS resolution, once per address dialed.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	if AllowLocal {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// limitedBody fails reads past the body limit instead of truncating
+// silently.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Probe for one more byte to distinguish EOF from overflow
+		var one [1]byte
+		if n, _ := b.ReadCloser.Read(one[:]); n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
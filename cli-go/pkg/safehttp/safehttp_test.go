@@ -0,0 +1,89 @@
+package safehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if got := IsPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestClient_BlocksLoopbackAndHTTP(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := NewClient(Options{})
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("GET loopback = %v, want ErrBlockedAddress", err)
+	}
+	if _, err := client.Get("http://example.com/"); !errors.Is(err, ErrInsecureScheme) {
+		t.Errorf("GET http = %v, want ErrInsecureScheme", err)
+	}
+}
+
+func TestClient_RedirectAndBodyLimits(t *testing.T) {
+	AllowLocal = true
+	defer func() { AllowLocal = false }()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/big":
+			w.(http.Flusher).Flush() // chunked, so no Content-Length
+			w.Write([]byte(strings.Repeat("x", 200)))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(Options{MaxRedirects: 3, MaxBodyBytes: 100})
+	if _, err := client.Get(srv.URL + "/loop"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("redirect loop = %v, want ErrTooManyRedirects", err)
+	}
+
+	resp, err := client.Get(srv.URL + "/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("oversized body read = %v, want ErrBodyTooLarge", err)
+	}
+
+	resp, err = client.Get(srv.URL + "/small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("small body = %q, %v", body, err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package safehttptest lets tests reach httptest servers through safehttp
+// clients, which otherwise refuse plain-HTTP loopback addresses.
+package safehttptest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
+)
+
+// Main runs a package's tests with safehttp.AllowLocal set. Use it as the
+// package's TestMain:
+//
+//	func TestMain(m *testing.M) { safehttptest.Main(m) }
+func Main(m *testing.M) {
+	safehttp.AllowLocal = true
+	os.Exit(m.Run())
+}
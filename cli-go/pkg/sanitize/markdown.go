@@ -0,0 +1,224 @@
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockElements start and end a paragraph.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true,
+	"aside": true, "table": true, "tr": true, "figure": true, "figcaption": true,
+	"dl": true, "dt": true, "dd": true, "center": true, "address": true, "main": true,
+}
+
+var (
+	spaceRun    = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+	trailSpaces = regexp.MustCompile(`(?m) +$`)
+	markdownMap = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`)
+)
+
+// Markdown converts untrusted HTML, such as the body of an email, to
+// markdown. Headings, paragraphs, emphasis, links, images, lists, quotes,
+// and code survive; everything else is reduced to its text. Links and
+// images keep only the URLs HTML would.
+func Markdown(s string) string {
+	c := &mdConverter{}
+	c.out = append(c.out, &strings.Builder{})
+
+	walk(s, c.text, func(t tag) {
+		if t.closing {
+			c.end(t.name)
+		} else {
+			c.start(t)
+		}
+	})
+
+	for len(c.out) > 1 {
+		c.end("blockquote")
+	}
+	out := trailSpaces.ReplaceAllStringFunc(c.out[0].String(), func(m string) string {
+		// Keep the two-space hard line break
+		if m == "  " {
+			return m
+		}
+		return ""
+	})
+	out = blankLines.ReplaceAllString(out, "\n\n")
+	return strings.TrimSpace(out) + "\n"
+}
+
+type mdList struct {
+	ordered bool
+	n       int
+}
+
+type mdConverter struct {
+	out   []*strings.Builder // One per open blockquote, innermost last
+	lists []mdList
+	links []string // href of each open <a>, "" when dropped
+	pre   int
+}
+
+func (c *mdConverter) b() *strings.Builder { return c.out[len(c.out)-1] }
+
+// atLineStart reports whether output is at the start of a line.
+func (c *mdConverter) atLineStart() bool {
+	s := c.b().String()
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+func (c *mdConverter) block() {
+	if !c.atLineStart() {
+		c.b().WriteString("\n")
+	}
+	c.b().WriteString("\n")
+}
+
+func (c *mdConverter) text(s string) {
+	s = html.UnescapeString(s)
+	if c.pre > 0 {
+		c.b().WriteString(s)
+		return
+	}
+	s = spaceRun.ReplaceAllString(s, " ")
+	if c.atLineStart() {
+		s = strings.TrimLeft(s, " ")
+	}
+	c.b().WriteString(markdownMap.Replace(s))
+}
+
+func (c *mdConverter) start(t tag) {
+	attr := func(name string) string {
+		for _, a := range t.attrs {
+			if a[0] == name {
+				return a[1]
+			}
+		}
+		return ""
+	}
+	switch t.name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.block()
+		c.b().WriteString(strings.Repeat("#", int(t.name[1]-'0')) + " ")
+	case "br":
+		if c.pre > 0 {
+			c.b().WriteString("\n")
+		} else {
+			c.b().WriteString("  \n")
+		}
+	case "hr":
+		c.block()
+		c.b().WriteString("---\n\n")
+	case "strong", "b":
+		c.b().WriteString("**")
+	case "em", "i":
+		c.b().WriteString("*")
+	case "del", "s", "strike":
+		c.b().WriteString("~~")
+	case "code", "kbd", "samp":
+		if c.pre == 0 {
+			c.b().WriteString("`")
+		}
+	case "pre":
+		c.block()
+		c.b().WriteString("```\n")
+		c.pre++
+	case "a":
+		href := strings.TrimSpace(attr("href"))
+		if href == "" || strings.HasPrefix(href, "#") || !safeURL(href, false) {
+			href = ""
+		} else {
+			c.b().WriteString("[")
+		}
+		c.links = append(c.links, href)
+	case "img":
+		src := strings.TrimSpace(attr("src"))
+		if src != "" && safeURL(src, false) {
+			c.b().WriteString("![" + markdownMap.Replace(attr("alt")) + "](" + src + ")")
+		}
+	case "ul", "ol":
+		if len(c.lists) == 0 {
+			c.block()
+		}
+		c.lists = append(c.lists, mdList{ordered: t.name == "ol"})
+	case "li":
+		if !c.atLineStart() {
+			c.b().WriteString("\n")
+		}
+		depth := len(c.lists)
+		marker := "- "
+		if depth > 0 {
+			l := &c.lists[depth-1]
+			if l.ordered {
+				l.n++
+				marker = strconv.Itoa(l.n) + ". "
+			}
+		} else {
+			depth = 1
+		}
+		c.b().WriteString(strings.Repeat("  ", depth-1) + marker)
+	case "blockquote":
+		c.block()
+		c.out = append(c.out, &strings.Builder{})
+	default:
+		if blockElements[t.name] {
+			c.block()
+		}
+	}
+}
+
+func (c *mdConverter) end(name string) {
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.block()
+	case "strong", "b":
+		c.b().WriteString("**")
+	case "em", "i":
+		c.b().WriteString("*")
+	case "del", "s", "strike":
+		c.b().WriteString("~~")
+	case "code", "kbd", "samp":
+		if c.pre == 0 {
+			c.b().WriteString("`")
+		}
+	case "pre":
+		if c.pre > 0 {
+			c.pre--
+			if !c.atLineStart() {
+				c.b().WriteString("\n")
+			}
+			c.b().WriteString("```\n\n")
+		}
+	case "a":
+		if n := len(c.links); n > 0 {
+			if href := c.links[n-1]; href != "" {
+				c.b().WriteString("](" + href + ")")
+			}
+			c.links = c.links[:n-1]
+		}
+	case "ul", "ol":
+		if n := len(c.lists); n > 0 {
+			c.lists = c.lists[:n-1]
+		}
+		if len(c.lists) == 0 {
+			c.block()
+		}
+	case "blockquote":
+		if len(c.out) > 1 {
+			inner := blankLines.ReplaceAllString(strings.TrimSpace(c.out[len(c.out)-1].String()), "\n\n")
+			c.out = c.out[:len(c.out)-1]
+			for _, line := range strings.Split(inner, "\n") {
+				c.b().WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			c.b().WriteString("\n")
+		}
+	default:
+		if blockElements[name] {
+			c.block()
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Package sanitize cleans untrusted HTML with an allowlist of elements and
+// attributes.
+//
+// Remote posts and comments are written by other people. Their markdown is
+// rendered with raw HTML enabled, and HTML pages are shown as fetched, so
+// without sanitizing a followed site could run script in the webapp or, via
+// a blessed comment, on the published site. HTML keeps ordinary document
+// markup, media (which remote.ApplyMediaPolicy handles afterwards), and
+// safe links, and drops everything else: scripts, styles, event handlers,
+// forms, javascript: and data: URLs, ids, and inline styles.
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// allowedAttrs maps each allowed element to its allowed attributes. Elements
+// not listed are removed but their text content is kept.
+var allowedAttrs = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "bdi": nil, "bdo": {"dir"}, "blockquote": {"cite"}, "br": nil,
+	"caption": nil, "cite": nil, "code": {"class"}, "col": {"span"}, "colgroup": {"span"},
+	"dd": nil, "del": nil, "details": {"open"}, "dfn": nil, "div": nil, "dl": nil, "dt": nil,
+	"em": nil, "figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil,
+	"h5": nil, "h6": nil, "hr": nil, "i": nil, "ins": nil, "kbd": nil, "li": {"value"},
+	"mark": nil, "ol": {"start", "reversed", "type"}, "p": nil, "pre": {"class"}, "q": {"cite"},
+	"rp": nil, "rt": nil, "ruby": nil, "s": nil, "samp": nil, "small": nil, "span": nil,
+	"strong": nil, "sub": nil, "summary": nil, "sup": nil, "table": nil, "tbody": nil,
+	"td": {"colspan", "rowspan", "align"}, "tfoot": nil, "th": {"colspan", "rowspan", "align", "scope"},
+	"thead": nil, "time": {"datetime"}, "tr": nil, "u": nil, "ul": nil, "var": nil, "wbr": nil,
+	"article": nil, "aside": nil, "footer": nil, "header": nil, "section": nil,
+
+	// GFM task list checkboxes
+	"input": {"type", "checked"},
+
+	// Media, left for the remote media policy to load, proxy, or block
+	"img":     {"src", "srcset", "alt", "title", "width", "height", "loading"},
+	"picture": nil,
+	"source":  {"src", "srcset", "type", "media", "sizes"},
+	"video":   {"src", "poster", "controls", "width", "height", "loop", "muted", "playsinline", "preload"},
+	"audio":   {"src", "controls", "loop", "muted", "preload"},
+	"track":   {"src", "kind", "srclang", "label"},
+	"iframe":  {"src", "width", "height", "title", "allowfullscreen"},
+}
+
+// voidElements have no closing tag.
+var voidElements = map[string]bool{
+	"br": true, "col": true, "hr": true, "img": true, "input": true, "source": true, "track": true, "wbr": true,
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "template": true, "textarea": true, "title": true,
+	"noscript": true, "noembed": true, "noframes": true, "xmp": true, "select": true,
+	"object": true, "svg": true, "math": true, "head": true,
+}
+
+// urlAttrs hold URLs and are checked with safeURL.
+var urlAttrs = map[string]bool{"href": true, "src": true, "poster": true, "cite": true}
+
+// HTML returns s with disallowed elements, attributes, and URLs removed.
+// Unbalanced tags are closed, so the result can't break out of the element
+// it is inserted into.
+func HTML(s string) string {
+	var b strings.Builder
+	var open []string
+
+	walk(s, func(text string) {
+		b.WriteString(strings.ReplaceAll(text, "<", "&lt;"))
+	}, func(t tag) {
+		attrs, allowed := allowedAttrs[t.name]
+		if !allowed {
+			return
+		}
+
+		if t.closing {
+			// Close back to the matching open element, if any
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == t.name {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+			return
+		}
+
+		if !writeStartTag(&b, t, attrs) {
+			return
+		}
+		if !voidElements[t.name] {
+			open = append(open, t.name)
+		}
+	})
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// walk tokenizes HTML, calling text with character data (as written, with
+// entities) and onTag with each tag. Comments, doctypes, CDATA, processing
+// instructions, and droppedElements with their content are skipped. A '<'
+// that doesn't start a tag is passed to text.
+func walk(s string, text func(string), onTag func(tag)) {
+	drop := "" // element whose content is being skipped
+	emit := func(t string) {
+		if drop == "" && t != "" {
+			text(t)
+		}
+	}
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			emit(s)
+			return
+		}
+		emit(s[:lt])
+		s = s[lt:]
+
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return
+			}
+			s = s[4+end+3:]
+			continue
+		}
+		if strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?") {
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		t, rest, ok := parseTag(s)
+		if !ok {
+			emit("<")
+			s = s[1:]
+			continue
+		}
+		s = rest
+
+		if drop != "" {
+			if t.closing && t.name == drop {
+				drop = ""
+			}
+			continue
+		}
+		if droppedElements[t.name] {
+			if !t.closing && !t.selfClosing {
+				drop = t.name
+			}
+			continue
+		}
+		onTag(t)
+	}
+}
+
+type tag struct {
+	name        string
+	closing     bool
+	selfClosing bool
+	attrs       [][2]string
+}
+
+// parseTag parses the tag at the start of s ("<name ...>" or "</name>").
+// It returns the remaining input and false if s doesn't start a tag.
+func parseTag(s string) (tag, string, bool) {
+	var t tag
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	if i == start || !isLetter(s[start]) {
+		return t, s, false
+	}
+	t.name = strings.ToLower(s[start:i])
+
+	for i < len(s) {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		switch s[i] {
+		case '>':
+			return t, s[i+1:], true
+		case '/':
+			t.selfClosing = true
+			i++
+			continue
+		}
+
+		nameStart := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(s[nameStart:i])
+		if name == "" {
+			// Stray '=' or similar; skip it
+			i++
+			continue
+		}
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					return t, s, false
+				}
+				value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				valStart := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[valStart:i]
+			}
+		}
+		t.attrs = append(t.attrs, [2]string{name, html.UnescapeString(value)})
+	}
+	// Unterminated tag at end of input
+	return t, s, false
+}
+
+// writeStartTag writes t with only the allowed attributes. It returns false
+// if the element was dropped instead.
+func writeStartTag(b *strings.Builder, t tag, allowed []string) bool {
+	var out strings.Builder
+	out.WriteString("<" + t.name)
+	seen := map[string]bool{}
+	for _, a := range t.attrs {
+		name, value := a[0], a[1]
+		if seen[name] || !contains(allowed, name) {
+			continue
+		}
+		seen[name] = true
+		switch {
+		case urlAttrs[name]:
+			if !safeURL(value, t.name == "iframe") {
+				if name == "src" && (t.name == "iframe" || t.name == "img") {
+					return false
+				}
+				continue
+			}
+		case name == "srcset":
+			if !safeSrcset(value) {
+				continue
+			}
+		case name == "class":
+			// Only syntax-highlighting classes, so remote content can't
+			// borrow the page's own styles and behaviors
+			if !strings.HasPrefix(value, "language-") || strings.ContainsAny(value, " \t\n") {
+				continue
+			}
+		case name == "type" && t.name == "input":
+			if !strings.EqualFold(value, "checkbox") {
+				return false
+			}
+		}
+		out.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+
+	switch t.name {
+	case "a":
+		out.WriteString(` rel="nofollow noopener noreferrer"`)
+	case "input":
+		if !seen["type"] {
+			return false
+		}
+		out.WriteString(` disabled=""`)
+	case "iframe":
+		if !seen["src"] {
+			return false
+		}
+		out.WriteString(` sandbox="allow-scripts allow-same-origin allow-popups" referrerpolicy="no-referrer"`)
+	case "img":
+		if !seen["src"] && !seen["srcset"] {
+			return false
+		}
+	}
+	out.WriteString(">")
+	b.WriteString(out.String())
+	return true
+}
+
+// safeURL reports whether a URL attribute value may be kept: http(s) and
+// mailto links, and relative URLs. httpsOnly restricts absolute URLs to
+// https (and disallows mailto).
+func safeURL(value string, httpsOnly bool) bool {
+	value = strings.TrimSpace(value)
+	// Browsers ignore control characters and whitespace inside the scheme
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		return true
+	case "https":
+		return true
+	case "http":
+		return !httpsOnly
+	case "mailto":
+		return !httpsOnly
+	default:
+		return false
+	}
+}
+
+func safeSrcset(value string) bool {
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !safeURL(fields[0], false) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
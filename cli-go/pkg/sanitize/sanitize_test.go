@@ -0,0 +1,65 @@
+package sanitize
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain markup", `<p>Hello <em>world</em> &amp; <code class="language-go">x</code></p>`,
+			`<p>Hello <em>world</em> &amp; <code class="language-go">x</code></p>`},
+		{"script removed with content", `<p>a</p><script>alert(1)</script><p>b</p>`, `<p>a</p><p>b</p>`},
+		{"style removed", `<style>body{display:none}</style>ok`, `ok`},
+		{"event handlers and ids dropped", `<div id="x" onclick="evil()" style="color:red">t</div>`, `<div>t</div>`},
+		{"javascript href dropped", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"entity-encoded scheme", `<a href="jav&#x09;ascript&colon;alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"safe link kept", `<a href="https://a.com/?x=1&amp;y=2" target="_blank">x</a>`,
+			`<a href="https://a.com/?x=1&amp;y=2" rel="nofollow noopener noreferrer">x</a>`},
+		{"unknown tag unwrapped", `<form action="/x"><button>Go</button></form>`, `Go`},
+		{"img with data src removed", `<img src="data:image/svg+xml,<svg onload=alert(1)>">`, ``},
+		{"img kept", `<img src="/a.png" alt="A" onerror="x()">`, `<img src="/a.png" alt="A">`},
+		{"iframe needs https", `<iframe src="http://a.com"></iframe><iframe src="https://a.com/e"></iframe>`,
+			`<iframe src="https://a.com/e" sandbox="allow-scripts allow-same-origin allow-popups" referrerpolicy="no-referrer"></iframe>`},
+		{"task list checkbox", `<li><input checked="" type="checkbox"> done</li>`,
+			`<li><input checked="" type="checkbox" disabled=""> done</li>`},
+		{"other inputs removed", `<input type="text" value="x">`, ``},
+		{"unbalanced tags closed", `<div><p>open`, `<div><p>open</p></div>`},
+		{"stray close ignored", `</div></main>text`, `text`},
+		{"comments removed", `a<!-- <script>x</script> -->b`, `ab`},
+		{"bare less-than escaped", `1 < 2 <3`, `1 &lt; 2 &lt;3`},
+		{"attribute quotes escaped", `<img src='/a.png' alt='say "hi"'>`, `<img src="/a.png" alt="say &#34;hi&#34;">`},
+		{"unterminated tag", `<p>x</p><img src="a`, `<p>x</p>&lt;img src="a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.in); got != tt.want {
+				t.Errorf("HTML(%q)\n got  %q\n want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	in := `<html><head><style>p{color:red}</style></head><body>
+<h1>Hi &amp; bye</h1>
+<p>Some <b>bold</b>, <em>em</em>, and <a href="https://example.com">a link</a> <a href="javascript:alert(1)">bad</a>.<br>Next line</p>
+<ul><li>one<ul><li>nested</li></ul></li><li>two</li></ul>
+<ol><li>a</li><li>b</li></ol>
+<blockquote><p>quoted</p><p>more</p></blockquote>
+<pre><code>x := 1
+y</code></pre>
+<img src="/attachments/x.png" alt="pic"><img src="data:image/png;base64,AAAA">
+<p>a*b_c</p><script>alert(1)</script>
+</body></html>`
+	want := "# Hi & bye\n\n" +
+		"Some **bold**, *em*, and [a link](https://example.com) bad.  \nNext line\n\n" +
+		"- one\n  - nested\n- two\n\n" +
+		"1. a\n2. b\n\n" +
+		"> quoted\n>\n> more\n\n" +
+		"```\nx := 1\ny\n```\n\n" +
+		"![pic](/attachments/x.png)\n\n" +
+		"a\\*b\\_c\n"
+	if got := Markdown(in); got != want {
+		t.Errorf("Markdown() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+)
+
+// CreateDraft writes a post draft from the job's template into
+// .polis/posts/drafts and returns the draft ID. When j.Publish is set the
+// draft gets a publish_at of now, so the scheduled-publish pass picks it
+// up.
+func CreateDraft(dataDir string, j Job, now time.Time) (string, error) {
+	templatePath := j.Template
+	if !filepath.IsAbs(templatePath) {
+		templatePath = filepath.Join(dataDir, templatePath)
+	}
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+
+	return SaveDraft(dataDir, ExpandTemplate(string(data), now), j.Publish, now)
+}
+
+// SaveDraft writes content as a post draft in .polis/posts/drafts and
+// returns the draft ID. With publish set the draft gets a publish_at of
+// now.
+func SaveDraft(dataDir, content string, publish bool, now time.Time) (string, error) {
+	if publish {
+		content = setPublishAt(content, now)
+	}
+
+	id := "draft-" + idgen.New()
+	path := filepath.Join(dataDir, ".polis", "posts", "drafts", id+".md")
+	if err := fsutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write draft: %w", err)
+	}
+	return id, nil
+}
+
+// ExpandTemplate replaces {{date}} (YYYY-MM-DD) and {{datetime}} (RFC 3339)
+// with now in local time.
+func ExpandTemplate(content string, now time.Time) string {
+	now = now.Local()
+	return strings.NewReplacer(
+		"{{date}}", now.Format("2006-01-02"),
+		"{{datetime}}", now.Format(time.RFC3339),
+	).Replace(content)
+}
+
+// setPublishAt adds a publish_at field to content's frontmatter, creating
+// the frontmatter block if there is none.
+func setPublishAt(content string, t time.Time) string {
+	field := "publish_at: " + t.UTC().Format(time.RFC3339) + "\n"
+	if strings.HasPrefix(content, "---\n") {
+		return "---\n" + field + content[len("---\n"):]
+	}
+	return "---\n" + field + "---\n\n" + content
+}
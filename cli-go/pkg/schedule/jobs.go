@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/digest"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Job actions.
+const (
+	// ActionSync runs a discovery sync (feed, notifications, blessings).
+	ActionSync = "sync"
+	// ActionBackup writes a .tar.gz of the data directory (see pkg/backup).
+	ActionBackup = "backup"
+	// ActionDraft creates a post draft from a markdown template.
+	ActionDraft = "draft"
+	// ActionScript runs a script with the hook environment.
+	ActionScript = "script"
+	// ActionDigest compiles a digest of the site's network activity (see
+	// pkg/digest).
+	ActionDigest = "digest"
+)
+
+// Job is a recurring automation.
+type Job struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Schedule string `json:"schedule"` // cron expression or macro (see ParseCron)
+	Action   string `json:"action"`
+	Disabled bool   `json:"disabled,omitempty"`
+
+	// Template is the draft action's markdown template, relative to the
+	// data directory. {{date}} and {{datetime}} are replaced with the run time.
+	Template string `json:"template,omitempty"`
+	// Publish schedules the created draft for immediate publishing.
+	Publish bool `json:"publish,omitempty"`
+
+	// Since is the period the digest action covers, e.g. 7d (default
+	// digest.DefaultSince).
+	Since string `json:"since,omitempty"`
+	// Email sends the digest with the site's notification email settings.
+	// Without Publish, no draft is saved.
+	Email bool `json:"email,omitempty"`
+
+	// Script is the script action's path, relative to the data directory.
+	Script string `json:"script,omitempty"`
+
+	// Keep is how many archives the backup action retains (default 7).
+	Keep int `json:"keep,omitempty"`
+}
+
+var jobIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// Validate checks the job's ID, schedule, and action-specific fields.
+func (j *Job) Validate() error {
+	if !jobIDPattern.MatchString(j.ID) {
+		return fmt.Errorf("invalid job id %q (lowercase letters, digits, and dashes)", j.ID)
+	}
+	if _, err := ParseCron(j.Schedule); err != nil {
+		return err
+	}
+	switch j.Action {
+	case ActionSync, ActionBackup:
+	case ActionDraft:
+		if j.Template == "" {
+			return fmt.Errorf("draft action requires a template")
+		}
+	case ActionScript:
+		if j.Script == "" {
+			return fmt.Errorf("script action requires a script")
+		}
+	case ActionDigest:
+		if j.Since != "" {
+			if _, err := digest.ParseSince(j.Since); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action %q (expected sync, backup, draft, script, or digest)", j.Action)
+	}
+	return nil
+}
+
+// Run status values.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// RunState is a job's run history, kept in .polis/schedule.json.
+type RunState struct {
+	NextRun    string `json:"next_run,omitempty"`
+	LastRun    string `json:"last_run,omitempty"`
+	LastStatus string `json:"last_status,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	LastResult string `json:"last_result,omitempty"` // e.g. the backup or draft path
+}
+
+// State maps job IDs to their run state.
+type State map[string]RunState
+
+// StatePath returns the path to .polis/schedule.json.
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "schedule.json")
+}
+
+// LoadState reads the run state. A missing file is an empty state.
+func LoadState(dataDir string) (State, error) {
+	data, err := os.ReadFile(StatePath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return State{}, nil
+		}
+		return nil, fmt.Errorf("failed to read schedule state: %w", err)
+	}
+	st := State{}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule state: %w", err)
+	}
+	return st, nil
+}
+
+// SaveState writes the run state.
+func SaveState(dataDir string, st State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(StatePath(dataDir), append(data, '\n'), 0644)
+}
+
+// Due returns the enabled jobs whose next run is at or before now, in ID
+// order. Jobs seen for the first time are given a next run after now
+// rather than running immediately. State entries for jobs that no longer
+// exist are dropped. It reports whether st was changed.
+func Due(jobs []Job, st State, now time.Time) ([]Job, bool) {
+	changed := false
+	known := make(map[string]bool, len(jobs))
+	var due []Job
+	for _, j := range jobs {
+		known[j.ID] = true
+		if j.Disabled {
+			continue
+		}
+		cron, err := ParseCron(j.Schedule)
+		if err != nil {
+			continue
+		}
+		rs := st[j.ID]
+		next, err := time.Parse(time.RFC3339, rs.NextRun)
+		if err != nil {
+			rs.NextRun = formatTime(cron.Next(now))
+			st[j.ID] = rs
+			changed = true
+			continue
+		}
+		if !next.After(now) {
+			due = append(due, j)
+		}
+	}
+	for id := range st {
+		if !known[id] {
+			delete(st, id)
+			changed = true
+		}
+	}
+	sort.Slice(due, func(a, b int) bool { return due[a].ID < due[b].ID })
+	return due, changed
+}
+
+// Record stores the outcome of a run and schedules the next one. Runs that
+// were missed while nothing was running are not replayed.
+func Record(st State, j Job, now time.Time, result string, runErr error) {
+	rs := st[j.ID]
+	rs.LastRun = formatTime(now)
+	rs.LastResult = result
+	rs.LastStatus, rs.LastError = StatusOK, ""
+	if runErr != nil {
+		rs.LastStatus, rs.LastError = StatusError, runErr.Error()
+	}
+	if cron, err := ParseCron(j.Schedule); err == nil {
+		rs.NextRun = formatTime(cron.Next(now))
+	}
+	st[j.ID] = rs
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
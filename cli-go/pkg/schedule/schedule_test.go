@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, spec string) *Cron {
+	t.Helper()
+	c, err := ParseCron(spec)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", spec, err)
+	}
+	return c
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2026, 3, 4, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"@nightly", time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon", time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"30 8 1-7 * 1", time.Date(2026, 3, 5, 8, 30, 0, 0, time.UTC)}, // day-of-month OR weekday
+		{"0 0 1 jan,jul *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)}, // 7 is Sunday
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.spec).Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCronNext_Impossible(t *testing.T) {
+	if got := mustParse(t, "0 0 30 2 *").Next(time.Now()); !got.IsZero() {
+		t.Errorf("Feb 30 = %v, want zero time", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * funday", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestJobValidate(t *testing.T) {
+	ok := Job{ID: "weekly-digest", Schedule: "@weekly", Action: ActionDraft, Template: "templates/digest.md"}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	digest := Job{ID: "digest", Schedule: "@weekly", Action: ActionDigest, Since: "7d", Email: true}
+	if err := digest.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	bad := []Job{
+		{ID: "Bad ID", Schedule: "@daily", Action: ActionSync},
+		{ID: "x", Schedule: "nope", Action: ActionSync},
+		{ID: "x", Schedule: "@daily", Action: "reboot"},
+		{ID: "x", Schedule: "@daily", Action: ActionDraft},
+		{ID: "x", Schedule: "@daily", Action: ActionScript},
+		{ID: "x", Schedule: "@daily", Action: ActionDigest, Since: "a week"},
+	}
+	for _, j := range bad {
+		if err := j.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", j)
+		}
+	}
+}
+
+func TestDueAndRecord(t *testing.T) {
+	jobs := []Job{
+		{ID: "hourly", Schedule: "@hourly", Action: ActionSync},
+		{ID: "off", Schedule: "@hourly", Action: ActionSync, Disabled: true},
+	}
+	st := State{"deleted": {LastRun: "2026-01-01T00:00:00Z"}}
+	now := time.Date(2026, 3, 4, 10, 17, 0, 0, time.UTC)
+
+	// First sight schedules the job instead of running it.
+	due, changed := Due(jobs, st, now)
+	if len(due) != 0 || !changed {
+		t.Fatalf("first Due = %v, changed=%v", due, changed)
+	}
+	if _, ok := st["deleted"]; ok {
+		t.Error("state for removed job was kept")
+	}
+	if st["hourly"].NextRun != "2026-03-04T11:00:00Z" {
+		t.Errorf("next run = %q", st["hourly"].NextRun)
+	}
+
+	// Hours later (e.g. after downtime) the job runs once.
+	later := now.Add(3 * time.Hour)
+	due, _ = Due(jobs, st, later)
+	if len(due) != 1 || due[0].ID != "hourly" {
+		t.Fatalf("due = %v", due)
+	}
+	Record(st, due[0], later, "done", nil)
+	rs := st["hourly"]
+	if rs.LastStatus != StatusOK || rs.LastResult != "done" || rs.NextRun != "2026-03-04T14:00:00Z" {
+		t.Errorf("after Record: %+v", rs)
+	}
+	if due, _ = Due(jobs, st, later); len(due) != 0 {
+		t.Errorf("job due again immediately: %v", due)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	st := State{"a": {LastRun: "2026-03-04T10:00:00Z", LastStatus: StatusError, LastError: "boom"}}
+	if err := SaveState(dir, st); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != st["a"] {
+		t.Errorf("LoadState = %+v", got)
+	}
+}
+
+func TestCreateDraft(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "templates", "digest.md")
+	os.MkdirAll(filepath.Dir(tmpl), 0755)
+	os.WriteFile(tmpl, []byte("# Digest for {{date}}\n"), 0644)
+
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	id, err := CreateDraft(dir, Job{ID: "digest", Template: "templates/digest.md", Publish: true}, now)
+	if err != nil {
+		t.Fatalf("CreateDraft: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".polis", "posts", "drafts", id+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "---\npublish_at: ") {
+		t.Errorf("draft missing publish_at frontmatter:\n%s", content)
+	}
+	if !strings.Contains(content, "# Digest for 2026-03-04") {
+		t.Errorf("template not expanded:\n%s", content)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build darwin
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityTool stores secrets in the macOS login keychain.
+type securityTool struct{}
+
+func systemKeychain() Store {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil
+	}
+	return securityTool{}
+}
+
+func (securityTool) Name() string { return "macOS Keychain" }
+
+func (securityTool) Get(account string) (string, error) {
+	out, err := run("find-generic-password", "-s", Service, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (securityTool) Set(account, value string) error {
+	// security only takes the value as an argument, so it is briefly visible
+	// to other processes of the same user; it is never written to disk
+	_, err := run("add-generic-password", "-U", "-s", Service, "-a", account, "-l", Service+" "+account, "-w", value)
+	return err
+}
+
+func (securityTool) Delete(account string) error {
+	_, err := run("delete-generic-password", "-s", Service, "-a", account)
+	return err
+}
+
+func run(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("security", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		// 44 is errSecItemNotFound
+		if errors.As(err, &exit) && exit.ExitCode() == 44 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("security %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
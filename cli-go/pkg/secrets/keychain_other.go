@@ -0,0 +1,62 @@
+//go:build !darwin && !windows
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretTool stores secrets with a libsecret service (GNOME Keyring,
+// KWallet) through secret-tool.
+type secretTool struct{}
+
+func systemKeychain() Store {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+	return secretTool{}
+}
+
+func (secretTool) Name() string { return "the Secret Service keyring" }
+
+func (secretTool) Get(account string) (string, error) {
+	out, err := run("", "lookup", "service", Service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+func (secretTool) Set(account, value string) error {
+	// The value goes on stdin, so it never shows up in a process listing
+	_, err := run(value, "store", "--label="+Service+" "+account, "service", Service, "account", account)
+	return err
+}
+
+func (secretTool) Delete(account string) error {
+	_, err := run("", "clear", "service", Service, "account", account)
+	return err
+}
+
+func run(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		// lookup exits 1 with no output when nothing matches
+		if errors.As(err, &exit) && args[0] == "lookup" && stderr.Len() == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
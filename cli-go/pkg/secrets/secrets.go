@@ -0,0 +1,206 @@
+// Package secrets keeps API keys and passwords in the OS keychain instead of
+// a site's .env file.
+//
+// The keychain is macOS Keychain (through the security tool), Windows
+// Credential Manager, or a libsecret service such as GNOME Keyring (through
+// secret-tool). Which settings a site keeps there is recorded, without their
+// values, in .polis/secrets.json; everything else stays in .env, which is
+// also the fallback when no keychain is available.
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Service is the keychain service secrets are stored under.
+const Service = "polis"
+
+var (
+	// ErrNotFound is returned for a secret that isn't in the keychain.
+	ErrNotFound = errors.New("secret not found in keychain")
+	// ErrUnavailable is returned when this system has no usable keychain.
+	ErrUnavailable = errors.New("no OS keychain available")
+)
+
+// Store is a keychain.
+type Store interface {
+	// Name describes the keychain for messages.
+	Name() string
+	Get(account string) (string, error)
+	Set(account, value string) error
+	Delete(account string) error
+}
+
+// keychain is the system keychain, nil when there is none; replaced in
+// tests.
+var keychain = systemKeychain()
+
+// Available reports whether a keychain can be used, and names it.
+func Available() (string, bool) {
+	if keychain == nil {
+		return "", false
+	}
+	return keychain.Name(), true
+}
+
+// SetStore replaces the keychain, or with nil disables it, and returns the
+// one it replaced. Tests use it to keep away from the real keychain.
+func SetStore(s Store) Store {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	old := keychain
+	keychain = s
+	cache = make(map[string]string)
+	return old
+}
+
+// Index lists the settings a site keeps in the keychain.
+type Index struct {
+	// ID distinguishes this site's entries from other sites' in the
+	// keychain, and survives moving the site directory.
+	ID   string   `json:"id"`
+	Keys []string `json:"keys"`
+}
+
+// IndexPath returns the path of a site's index.
+func IndexPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "secrets.json")
+}
+
+// LoadIndex reads a site's index. A missing index is empty.
+func LoadIndex(dataDir string) (*Index, error) {
+	data, err := os.ReadFile(IndexPath(dataDir))
+	if os.IsNotExist(err) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", IndexPath(dataDir), err)
+	}
+	return &ix, nil
+}
+
+func saveIndex(dataDir string, ix *Index) error {
+	sort.Strings(ix.Keys)
+	data, err := json.MarshalIndent(ix, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(IndexPath(dataDir)), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFile(IndexPath(dataDir), append(data, '\n'), 0644)
+}
+
+// Has reports whether the site keeps key in the keychain.
+func (ix *Index) Has(key string) bool {
+	for _, k := range ix.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (ix *Index) account(key string) string {
+	return ix.ID + ":" + key
+}
+
+// Values read from the keychain are kept for the life of the process, since
+// each read can start a helper process.
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]string)
+)
+
+// Get returns a setting the site keeps in the keychain. ok is false when
+// the site doesn't keep key there.
+func Get(dataDir, key string) (value string, ok bool, err error) {
+	ix, err := LoadIndex(dataDir)
+	if err != nil || !ix.Has(key) {
+		return "", false, err
+	}
+	if keychain == nil {
+		return "", true, ErrUnavailable
+	}
+	account := ix.account(key)
+	cacheMu.Lock()
+	v, cached := cache[account]
+	cacheMu.Unlock()
+	if cached {
+		return v, true, nil
+	}
+	v, err = keychain.Get(account)
+	if err != nil {
+		return "", true, err
+	}
+	cacheMu.Lock()
+	cache[account] = v
+	cacheMu.Unlock()
+	return v, true, nil
+}
+
+// Put stores a setting in the keychain and records it in the site's index.
+func Put(dataDir, key, value string) error {
+	if keychain == nil {
+		return ErrUnavailable
+	}
+	ix, err := LoadIndex(dataDir)
+	if err != nil {
+		return err
+	}
+	if ix.ID == "" {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return err
+		}
+		ix.ID = hex.EncodeToString(b[:])
+	}
+	if err := keychain.Set(ix.account(key), value); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", keychain.Name(), err)
+	}
+	cacheMu.Lock()
+	cache[ix.account(key)] = value
+	cacheMu.Unlock()
+	if !ix.Has(key) {
+		ix.Keys = append(ix.Keys, key)
+	}
+	return saveIndex(dataDir, ix)
+}
+
+// Remove deletes a setting from the keychain and the site's index.
+func Remove(dataDir, key string) error {
+	ix, err := LoadIndex(dataDir)
+	if err != nil || !ix.Has(key) {
+		return err
+	}
+	if keychain != nil {
+		if err := keychain.Delete(ix.account(key)); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to delete from %s: %w", keychain.Name(), err)
+		}
+	}
+	cacheMu.Lock()
+	delete(cache, ix.account(key))
+	cacheMu.Unlock()
+	keys := ix.Keys[:0]
+	for _, k := range ix.Keys {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	ix.Keys = keys
+	return saveIndex(dataDir, ix)
+}
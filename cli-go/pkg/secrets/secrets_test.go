@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+// memStore is an in-memory keychain.
+type memStore map[string]string
+
+func (memStore) Name() string { return "test keychain" }
+
+func (m memStore) Get(account string) (string, error) {
+	v, ok := m[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (m memStore) Set(account, value string) error {
+	m[account] = value
+	return nil
+}
+
+func (m memStore) Delete(account string) error {
+	if _, ok := m[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m, account)
+	return nil
+}
+
+func useStore(t *testing.T, s Store) {
+	t.Helper()
+	old := SetStore(s)
+	t.Cleanup(func() { SetStore(old) })
+}
+
+func TestPutGetRemove(t *testing.T) {
+	store := memStore{}
+	useStore(t, store)
+	dir := t.TempDir()
+
+	if _, ok, err := Get(dir, "discovery_key"); ok || err != nil {
+		t.Fatalf("Get before Put = ok %v, err %v", ok, err)
+	}
+	if err := Put(dir, "discovery_key", "sk-123"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// A fresh process reads the value back from the keychain
+	cache = make(map[string]string)
+	v, ok, err := Get(dir, "discovery_key")
+	if !ok || err != nil || v != "sk-123" {
+		t.Errorf("Get = %q, %v, %v", v, ok, err)
+	}
+
+	ix, _ := LoadIndex(dir)
+	if ix.ID == "" || !ix.Has("discovery_key") {
+		t.Errorf("index = %+v", ix)
+	}
+	if _, ok := store[ix.ID+":discovery_key"]; !ok {
+		t.Errorf("keychain entries = %v", store)
+	}
+
+	// Another site's entries don't collide
+	other := t.TempDir()
+	Put(other, "discovery_key", "sk-other")
+	if v, _, _ := Get(dir, "discovery_key"); v != "sk-123" {
+		t.Errorf("Get after another site's Put = %q", v)
+	}
+
+	if err := Remove(dir, "discovery_key"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, ok, _ := Get(dir, "discovery_key"); ok {
+		t.Error("Get after Remove still finds the key")
+	}
+	if len(store) != 1 {
+		t.Errorf("keychain entries after Remove = %v", store)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	useStore(t, nil)
+	dir := t.TempDir()
+	if _, ok := Available(); ok {
+		t.Error("Available with no keychain")
+	}
+	if err := Put(dir, "discovery_key", "x"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Put = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,215 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+const (
+	checksumsName = "checksums.txt"
+	signatureName = "checksums.txt.sig"
+	maxDownload   = 256 << 20
+)
+
+// ErrNoReleaseKey is returned when this build has no release key to verify
+// updates with.
+var ErrNoReleaseKey = errors.New("this build has no release signing key; reinstall with scripts/install.sh")
+
+// BinaryName returns the distribution the running executable belongs to:
+// "polis-full" for the bundled binary, otherwise "polis".
+func BinaryName(exePath string) string {
+	if strings.TrimSuffix(filepath.Base(exePath), ".exe") == "polis-full" {
+		return "polis-full"
+	}
+	return "polis"
+}
+
+// ArchiveName returns the release archive for binary on goos/goarch, as
+// named by .goreleaser.yaml (e.g. polis-full-linux-amd64.tar.gz).
+func ArchiveName(binary, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("%s-%s-%s%s", binary, goos, goarch, ext)
+}
+
+// signedChecksums returns what the release signature covers: a line naming
+// the version, then checksums.txt. The version line keeps the checksums of
+// an older release from being offered under a newer tag.
+func signedChecksums(version string, sums []byte) []byte {
+	return append([]byte("polis-release "+version+"\n"), sums...)
+}
+
+// Download fetches binary for the running platform from r, verifies the
+// signed checksums, and returns the extracted executable.
+func Download(r *Release, binary string) ([]byte, error) {
+	if ReleaseKey == "" {
+		return nil, ErrNoReleaseKey
+	}
+	archiveName := ArchiveName(binary, runtime.GOOS, runtime.GOARCH)
+	archiveAsset := r.Asset(archiveName)
+	sumsAsset := r.Asset(checksumsName)
+	sigAsset := r.Asset(signatureName)
+	switch {
+	case archiveAsset == nil:
+		return nil, fmt.Errorf("release %s has no %s", r.Tag, archiveName)
+	case sumsAsset == nil || sigAsset == nil:
+		return nil, fmt.Errorf("release %s is not signed (missing %s or %s)", r.Tag, checksumsName, signatureName)
+	}
+
+	sums, err := fetch(sumsAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := fetch(sigAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := signing.VerifySignature(signedChecksums(r.Version(), sums), []byte(ReleaseKey), string(sig))
+	if err != nil || !ok {
+		return nil, fmt.Errorf("release %s: checksum signature does not verify", r.Tag)
+	}
+	want, err := lookupChecksum(sums, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := fetch(archiveAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	got := sha256.Sum256(archive)
+	if hex.EncodeToString(got[:]) != want {
+		return nil, fmt.Errorf("%s: checksum mismatch", archiveName)
+	}
+	return extractBinary(archive, archiveName, binary)
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := HTTPClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", path.Base(url), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", path.Base(url), resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", path.Base(url), err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("%s is too large", path.Base(url))
+	}
+	return data, nil
+}
+
+// lookupChecksum finds name in a sha256sum-format checksums file.
+func lookupChecksum(sums []byte, name string) (string, error) {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not listed in %s", name, checksumsName)
+}
+
+// extractBinary returns the executable named binary from a release archive.
+func extractBinary(archive []byte, archiveName, binary string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) == binary+".exe" {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(io.LimitReader(rc, maxDownload))
+			}
+		}
+		return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == binary {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+	return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+}
+
+// Install atomically replaces the executable at exePath with binary. The
+// new file is written next to the old one and renamed over it, so a failed
+// update leaves the old executable in place. Windows cannot replace a
+// running executable, so there the old one is first moved to <exe>.old and
+// restored if the rename fails.
+func Install(exePath string, binary []byte) error {
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat executable: %w", err)
+	}
+
+	newPath := exePath + ".new"
+	if err := os.WriteFile(newPath, binary, info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to write new executable (is %s writable?): %w", filepath.Dir(exePath), err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := exePath + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(exePath, oldPath); err != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("failed to move old executable aside: %w", err)
+		}
+		if err := os.Rename(newPath, exePath); err != nil {
+			os.Rename(oldPath, exePath)
+			os.Remove(newPath)
+			return fmt.Errorf("failed to install new executable: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("failed to install new executable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.47.0", "0.47.0", 0},
+		{"v0.47.0", "0.47.0", 0},
+		{"0.48.0", "0.47.9", 1},
+		{"0.10.0", "0.9.0", 1},
+		{"0.48.0-beta.1", "0.48.0", -1},
+		{"0.48.0-beta.2", "0.48.0-beta.10", -1},
+		{"0.48.0-beta.1", "0.47.0", 1},
+		{"dev", "0.1.0", -1},
+		{"0.1.0", "dev", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// releaseServer serves a release list and the assets of a single signed
+// release containing binary.
+type releaseServer struct {
+	*httptest.Server
+	files map[string][]byte
+}
+
+func newReleaseServer(t *testing.T, binary []byte, privKey []byte) *releaseServer {
+	t.Helper()
+	rs := &releaseServer{files: map[string][]byte{}}
+	archiveName := ArchiveName("polis", runtime.GOOS, runtime.GOARCH)
+	archive := buildArchive(t, archiveName, "polis", binary)
+	sum := sha256.Sum256(archive)
+	sums := []byte(fmt.Sprintf("%s  %s\n%s  other.tar.gz\n", hex.EncodeToString(sum[:]), archiveName, hex.EncodeToString(sum[:])))
+	sig, err := signing.SignContent(signedChecksums("0.48.0", sums), privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.files[archiveName] = archive
+	rs.files[checksumsName] = sums
+	rs.files[signatureName] = []byte(sig)
+
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/releases" {
+			asset := func(name string) Asset { return Asset{Name: name, URL: rs.URL + "/dl/" + name} }
+			json.NewEncoder(w).Encode([]Release{
+				{Tag: "v0.48.0", Assets: []Asset{asset(archiveName), asset(checksumsName), asset(signatureName)}},
+				{Tag: "v0.49.0-beta.1", Prerelease: true},
+				{Tag: "v0.50.0", Draft: true},
+				{Tag: "v0.47.0"},
+			})
+			return
+		}
+		data, ok := rs.files[filepath.Base(r.URL.Path)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func buildArchive(t *testing.T, archiveName, binary string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if runtime.GOOS == "windows" {
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create(binary + ".exe")
+		w.Write(content)
+		zw.Close()
+		return buf.Bytes()
+	}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: binary, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func withReleaseKey(t *testing.T, key []byte) {
+	t.Helper()
+	old := ReleaseKey
+	ReleaseKey = string(key)
+	t.Cleanup(func() { ReleaseKey = old })
+}
+
+func TestLatest_RespectsChannel(t *testing.T) {
+	priv, _, _ := signing.GenerateKeypair()
+	rs := newReleaseServer(t, []byte("new"), priv)
+
+	rel, err := Latest(rs.URL+"/releases", ChannelStable)
+	if err != nil {
+		t.Fatalf("Latest stable: %v", err)
+	}
+	if rel.Version() != "0.48.0" {
+		t.Errorf("stable = %s, want 0.48.0", rel.Version())
+	}
+
+	rel, err = Latest(rs.URL+"/releases", ChannelBeta)
+	if err != nil {
+		t.Fatalf("Latest beta: %v", err)
+	}
+	if rel.Version() != "0.49.0-beta.1" {
+		t.Errorf("beta = %s, want 0.49.0-beta.1 (drafts are never offered)", rel.Version())
+	}
+
+	if _, err := Latest(rs.URL+"/releases", "nightly"); err == nil {
+		t.Error("expected error for unknown channel")
+	}
+}
+
+func TestDownload_VerifiesSignatureAndChecksum(t *testing.T) {
+	priv, pub, _ := signing.GenerateKeypair()
+	rs := newReleaseServer(t, []byte("new polis binary"), priv)
+	rel, err := Latest(rs.URL+"/releases", ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withReleaseKey(t, nil)
+	if _, err := Download(rel, "polis"); err != ErrNoReleaseKey {
+		t.Errorf("without a release key: err = %v, want ErrNoReleaseKey", err)
+	}
+
+	withReleaseKey(t, pub)
+	data, err := Download(rel, "polis")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if string(data) != "new polis binary" {
+		t.Errorf("binary = %q", data)
+	}
+
+	// A different key must not verify
+	_, otherPub, _ := signing.GenerateKeypair()
+	withReleaseKey(t, otherPub)
+	if _, err := Download(rel, "polis"); err == nil {
+		t.Error("expected signature failure with the wrong key")
+	}
+
+	// The signature is for v0.48.0, not whatever the release list says
+	relabeled := *rel
+	relabeled.Tag = "v0.51.0"
+	if _, err := Download(&relabeled, "polis"); err == nil {
+		t.Error("expected signature failure for a release under another tag")
+	}
+
+	// A tampered archive must not match the signed checksum
+	withReleaseKey(t, pub)
+	archiveName := ArchiveName("polis", runtime.GOOS, runtime.GOARCH)
+	rs.files[archiveName] = buildArchive(t, archiveName, "polis", []byte("evil"))
+	if _, err := Download(rel, "polis"); err == nil {
+		t.Error("expected checksum mismatch for tampered archive")
+	}
+}
+
+func TestInstall_ReplacesExecutable(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "polis")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(exe, []byte("new")); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	data, _ := os.ReadFile(exe)
+	if string(data) != "new" {
+		t.Errorf("executable = %q, want new", data)
+	}
+	if _, err := os.Stat(exe + ".new"); !os.IsNotExist(err) {
+		t.Error("temporary .new file left behind")
+	}
+	if info, _ := os.Stat(exe); runtime.GOOS != "windows" && info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("mode = %v, want executable", info.Mode())
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	for path, want := range map[string]string{
+		"/usr/local/bin/polis":      "polis",
+		"/opt/polis-full":           "polis-full",
+		"/bin/polis-full.exe":       "polis-full",
+		"/home/alice/bin/polis-dev": "polis",
+	} {
+		if got := BinaryName(filepath.FromSlash(path)); got != want {
+			t.Errorf("BinaryName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// AgentKeyType is the PEM block that stands in for a private key held by
+// ssh-agent. It carries only the public key blob; signing goes to the agent,
+// so the private key is never in this process's memory.
+const AgentKeyType = "POLIS AGENT KEY"
+
+var (
+	// ErrNoAgent is returned when no ssh-agent is reachable.
+	ErrNoAgent = errors.New("ssh-agent not available (SSH_AUTH_SOCK is not set)")
+	// ErrAgentKey is returned for operations that need the private key
+	// itself, which an agent never hands out.
+	ErrAgentKey = errors.New("the site key is held by ssh-agent; this needs the key file")
+)
+
+// ssh-agent protocol messages (draft-miller-ssh-agent).
+const (
+	agentFailure           = 5
+	agentRequestIdentities = 11
+	agentIdentitiesAnswer  = 12
+	agentSignRequest       = 13
+	agentSignResponse      = 14
+)
+
+// agentTimeout bounds a request, leaving time to touch a hardware key.
+const agentTimeout = time.Minute
+
+// maxAgentReply caps the size of a reply read from the agent.
+const maxAgentReply = 256 * 1024
+
+// dialAgent connects to the agent at SSH_AUTH_SOCK; replaced in tests.
+var dialAgent = func() (net.Conn, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, ErrNoAgent
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+	return conn, nil
+}
+
+// AgentKey returns key data referring to the key with the given OpenSSH
+// public key in ssh-agent, after checking the agent holds it.
+func AgentKey(publicKeySSH []byte) ([]byte, error) {
+	pub, err := parsePublicKey(publicKeySSH)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := AgentKeys()
+	if err != nil {
+		return nil, err
+	}
+	want := strings.Fields(string(encodePublicKey(pub)))[1]
+	for _, k := range keys {
+		if strings.Fields(k)[1] == want {
+			return pem.EncodeToMemory(&pem.Block{Type: AgentKeyType, Bytes: encodePublicKeyBlob(pub)}), nil
+		}
+	}
+	return nil, errors.New("ssh-agent doesn't hold the site key (add it with ssh-add)")
+}
+
+// AgentKeys lists the Ed25519 keys in ssh-agent, in OpenSSH format.
+func AgentKeys() ([]string, error) {
+	reply, err := agentRequest([]byte{agentRequestIdentities})
+	if err != nil {
+		return nil, err
+	}
+	if reply[0] != agentIdentitiesAnswer {
+		return nil, fmt.Errorf("unexpected ssh-agent reply %d", reply[0])
+	}
+	n, data, err := readUint32(reply[1:])
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for i := uint32(0); i < n; i++ {
+		var blob []byte
+		if blob, data, err = readBytes(data); err != nil {
+			return nil, err
+		}
+		if _, data, err = readString(data); err != nil { // comment
+			return nil, err
+		}
+		if keyType, _, _ := readString(blob); keyType == "ssh-ed25519" {
+			keys = append(keys, "ssh-ed25519 "+base64.StdEncoding.EncodeToString(blob))
+		}
+	}
+	return keys, nil
+}
+
+type agentSigner struct {
+	pub ed25519.PublicKey
+}
+
+func newAgentSigner(key []byte) (Signer, error) {
+	block, _ := pem.Decode(key)
+	keyType, rest, err := readString(block.Bytes)
+	if err != nil || keyType != "ssh-ed25519" {
+		return nil, errors.New("invalid agent key reference")
+	}
+	pub, _, err := readBytes(rest)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid agent key reference")
+	}
+	return agentSigner{ed25519.PublicKey(pub)}, nil
+}
+
+func (a agentSigner) PublicKey() ed25519.PublicKey {
+	return a.pub
+}
+
+func (a agentSigner) Sign(data []byte) ([]byte, error) {
+	req := []byte{agentSignRequest}
+	req = appendBytes(req, encodePublicKeyBlob(a.pub))
+	req = appendBytes(req, data)
+	req = appendUint32(req, 0)
+	reply, err := agentRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	switch reply[0] {
+	case agentSignResponse:
+	case agentFailure:
+		return nil, errors.New("ssh-agent refused to sign (is the key still loaded? check ssh-add -l)")
+	default:
+		return nil, fmt.Errorf("unexpected ssh-agent reply %d", reply[0])
+	}
+	sigBlob, _, err := readBytes(reply[1:])
+	if err != nil {
+		return nil, err
+	}
+	format, sigBlob, err := readString(sigBlob)
+	if err != nil || format != "ssh-ed25519" {
+		return nil, fmt.Errorf("unexpected ssh-agent signature format %q", format)
+	}
+	sig, _, err := readBytes(sigBlob)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(a.pub, data, sig) {
+		return nil, errors.New("ssh-agent returned an invalid signature")
+	}
+	return sig, nil
+}
+
+// agentRequest sends one message to the agent and returns its reply.
+func agentRequest(msg []byte) ([]byte, error) {
+	conn, err := dialAgent()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentTimeout))
+
+	if _, err := conn.Write(appendBytes(nil, msg)); err != nil {
+		return nil, fmt.Errorf("ssh-agent request failed: %w", err)
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, fmt.Errorf("ssh-agent reply failed: %w", err)
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n == 0 || n > maxAgentReply {
+		return nil, fmt.Errorf("invalid ssh-agent reply length %d", n)
+	}
+	reply := make([]byte, n)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, fmt.Errorf("ssh-agent reply failed: %w", err)
+	}
+	return reply, nil
+}
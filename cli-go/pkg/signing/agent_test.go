@@ -0,0 +1,116 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeAgent serves the ssh-agent protocol for one key, like ssh-agent with
+// a single identity loaded.
+func fakeAgent(t *testing.T, key ed25519.PrivateKey) {
+	t.Helper()
+	old := dialAgent
+	dialAgent = func() (net.Conn, error) {
+		client, server := net.Pipe()
+		go serveAgent(server, key)
+		return client, nil
+	}
+	t.Cleanup(func() { dialAgent = old })
+}
+
+func serveAgent(conn net.Conn, key ed25519.PrivateKey) {
+	defer conn.Close()
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return
+	}
+	blob := encodePublicKeyBlob(key.Public().(ed25519.PublicKey))
+
+	reply := []byte{agentFailure}
+	switch msg[0] {
+	case agentRequestIdentities:
+		reply = appendUint32([]byte{agentIdentitiesAnswer}, 1)
+		reply = appendBytes(reply, blob)
+		reply = appendString(reply, "test key")
+	case agentSignRequest:
+		reqBlob, rest, _ := readBytes(msg[1:])
+		data, _, _ := readBytes(rest)
+		if string(reqBlob) == string(blob) {
+			var sig []byte
+			sig = appendString(sig, "ssh-ed25519")
+			sig = appendBytes(sig, ed25519.Sign(key, data))
+			reply = appendBytes([]byte{agentSignResponse}, sig)
+		}
+	}
+	conn.Write(appendBytes(nil, reply))
+}
+
+func TestAgentSigning(t *testing.T) {
+	privPEM, pubSSH, err := GenerateKeypair()
+	if err != nil {
+		t.Fatalf("GenerateKeypair failed: %v", err)
+	}
+	privKey, _ := parsePrivateKey(privPEM)
+	fakeAgent(t, privKey)
+
+	// Only the public key is on disk
+	keysDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(keysDir, "id_ed25519.pub"), pubSSH, 0644); err != nil {
+		t.Fatal(err)
+	}
+	key, err := LoadKey(keysDir, BackendAgent)
+	if err != nil {
+		t.Fatalf("LoadKey: %v", err)
+	}
+	if !IsAgentKey(key) {
+		t.Fatal("LoadKey with the agent backend didn't return an agent key")
+	}
+
+	content := []byte("Hello from the agent")
+	sig, err := SignContent(content, key)
+	if err != nil {
+		t.Fatalf("SignContent: %v", err)
+	}
+	if ok, err := VerifySignature(content, pubSSH, sig); !ok || err != nil {
+		t.Errorf("agent signature doesn't verify: %v", err)
+	}
+
+	// Same signature as signing with the key file, as Ed25519 is deterministic
+	fileSig, _ := SignContent(content, privPEM)
+	if sig != fileSig {
+		t.Error("agent and file signatures differ")
+	}
+
+	if _, err := DeriveSecret(key, "nostr"); !errors.Is(err, ErrAgentKey) {
+		t.Errorf("DeriveSecret with an agent key = %v, want ErrAgentKey", err)
+	}
+}
+
+func TestAgentKey_NotLoaded(t *testing.T) {
+	_, pubSSH, _ := GenerateKeypair()
+	other, _, _ := GenerateKeypair()
+	otherKey, _ := parsePrivateKey(other)
+	fakeAgent(t, otherKey)
+
+	if _, err := AgentKey(pubSSH); err == nil {
+		t.Error("AgentKey succeeded for a key the agent doesn't hold")
+	}
+}
+
+func TestAgentKey_NoAgent(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	_, pubSSH, _ := GenerateKeypair()
+	if _, err := AgentKey(pubSSH); !errors.Is(err, ErrNoAgent) {
+		t.Errorf("AgentKey without an agent = %v, want ErrNoAgent", err)
+	}
+}
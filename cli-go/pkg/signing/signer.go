@@ -0,0 +1,70 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Signer signs with an Ed25519 key, wherever the key is kept.
+type Signer interface {
+	PublicKey() ed25519.PublicKey
+	// Sign returns a raw Ed25519 signature of data.
+	Sign(data []byte) ([]byte, error)
+}
+
+// Key backends, selected with the signing_backend setting.
+const (
+	BackendFile  = "file"  // .polis/keys/id_ed25519
+	BackendAgent = "agent" // A key loaded in ssh-agent
+)
+
+// NewSigner returns a signer for key data: an OpenSSH private key, or a
+// reference to a key held by ssh-agent.
+func NewSigner(key []byte) (Signer, error) {
+	if IsAgentKey(key) {
+		return newAgentSigner(key)
+	}
+	privKey, err := parsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return fileSigner{privKey}, nil
+}
+
+// LoadKey returns the key data for the keys in keysDir. With the agent
+// backend only the public key is read, and the result refers to the key in
+// ssh-agent; the private key file need not exist.
+func LoadKey(keysDir, backend string) ([]byte, error) {
+	switch backend {
+	case "", BackendFile:
+		return os.ReadFile(filepath.Join(keysDir, "id_ed25519"))
+	case BackendAgent:
+		pub, err := os.ReadFile(filepath.Join(keysDir, "id_ed25519.pub"))
+		if err != nil {
+			return nil, err
+		}
+		return AgentKey(pub)
+	}
+	return nil, fmt.Errorf("unknown signing backend %q", backend)
+}
+
+type fileSigner struct {
+	key ed25519.PrivateKey
+}
+
+func (f fileSigner) PublicKey() ed25519.PublicKey {
+	return f.key.Public().(ed25519.PublicKey)
+}
+
+func (f fileSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(f.key, data), nil
+}
+
+// IsAgentKey reports whether key data refers to a key held by ssh-agent.
+func IsAgentKey(key []byte) bool {
+	block, _ := pem.Decode(key)
+	return block != nil && block.Type == AgentKeyType
+}
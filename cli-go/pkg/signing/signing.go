@@ -3,7 +3,9 @@ package signing
 
 import (
 	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/binary"
@@ -43,29 +45,48 @@ func GenerateKeypair() ([]byte, []byte, error) {
 }
 
 // SignContent signs content with the private key and returns an SSH signature.
-// The privateKey should be in OpenSSH PEM format.
+// The privateKey should be in OpenSSH PEM format, or a reference to a key
+// held by ssh-agent (see AgentKey).
 // This produces signatures compatible with `ssh-keygen -Y sign`.
 func SignContent(content, privateKeyPEM []byte) (string, error) {
-	// Parse private key
-	privKey, err := parsePrivateKey(privateKeyPEM)
+	signer, err := NewSigner(privateKeyPEM)
 	if err != nil {
 		return "", err
 	}
+	return SignWith(signer, content)
+}
 
+// SignWith signs content with signer and returns an SSH signature.
+func SignWith(signer Signer, content []byte) (string, error) {
 	// Build the SSH signing blob (what ssh-keygen -Y sign actually signs)
 	// Format: MAGIC + namespace + reserved + hash_algo + hash(content)
 	signingBlob := buildSigningBlob(content)
 
 	// Sign the blob, not the raw content
-	sig := ed25519.Sign(privKey, signingBlob)
-
-	// Format as SSH signature
-	sshSig, err := formatSSHSignature(privKey.Public().(ed25519.PublicKey), sig)
+	sig, err := signer.Sign(signingBlob)
 	if err != nil {
 		return "", err
 	}
 
-	return sshSig, nil
+	// Format as SSH signature
+	return formatSSHSignature(signer.PublicKey(), sig)
+}
+
+// DeriveSecret returns a 32-byte secret derived from the site's private key
+// and a label naming its use, so other keys (such as a nostr key) can be
+// recreated from the site key instead of being stored. Different labels
+// give unrelated secrets, and the site key can't be recovered from them.
+func DeriveSecret(privateKeyPEM []byte, label string) ([]byte, error) {
+	if IsAgentKey(privateKeyPEM) {
+		return nil, ErrAgentKey
+	}
+	privKey, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, privKey.Seed())
+	mac.Write([]byte("polis derived key: " + label))
+	return mac.Sum(nil), nil
 }
 
 // buildSigningBlob creates the blob that SSH signature verification expects.
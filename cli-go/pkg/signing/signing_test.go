@@ -75,6 +75,25 @@ func TestSignContent_Deterministic(t *testing.T) {
 	}
 }
 
+func TestDeriveSecret(t *testing.T) {
+	privKey, _, _ := GenerateKeypair()
+	a1, err := DeriveSecret(privKey, "a")
+	if err != nil {
+		t.Fatalf("DeriveSecret failed: %v", err)
+	}
+	a2, _ := DeriveSecret(privKey, "a")
+	b, _ := DeriveSecret(privKey, "b")
+	if len(a1) != 32 || !bytes.Equal(a1, a2) {
+		t.Error("DeriveSecret should return the same 32 bytes for the same key and label")
+	}
+	if bytes.Equal(a1, b) {
+		t.Error("DeriveSecret should return different secrets for different labels")
+	}
+	if _, err := DeriveSecret([]byte("not a key"), "a"); err == nil {
+		t.Error("DeriveSecret should fail for an invalid key")
+	}
+}
+
 func TestSignContent_DifferentContent(t *testing.T) {
 	privKey, _, _ := GenerateKeypair()
 
@@ -0,0 +1,189 @@
+package site
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// Counts holds the dashboard badge counts for a site.
+type Counts struct {
+	Posts               int `json:"posts"`
+	Drafts              int `json:"drafts"`
+	MyPending           int `json:"my_pending"`
+	MyBlessed           int `json:"my_blessed"`
+	MyDenied            int `json:"my_denied"`
+	MyCommentDrafts     int `json:"my_comment_drafts"`
+	IncomingPending     int `json:"incoming_pending"`
+	IncomingBlessed     int `json:"incoming_blessed"`
+	Feed                int `json:"feed"`
+	FeedUnread          int `json:"feed_unread"`
+	Following           int `json:"following"`
+	Followers           int `json:"followers"`
+	NotificationsUnread int `json:"notifications_unread"`
+	BlessingRequests    int `json:"blessing_requests"`
+}
+
+// CountSources holds already-loaded copies of files CountAll reads. A nil
+// field is read from disk; the serve process passes its cached copies.
+type CountSources struct {
+	PublicIndex []byte // metadata/public.jsonl
+	Following   *following.FollowingFile
+	FeedItems   []feed.CachedFeedItem // the feed cache for discoveryDomain
+}
+
+// CountAll reads all badge counts from local state and the filesystem.
+// No discovery service queries are made; stream-derived counts (followers,
+// blessing requests) come from the state cached under .polis/ds/<discoveryDomain>.
+func CountAll(dataDir, discoveryDomain string) Counts {
+	return CountAllFrom(dataDir, discoveryDomain, CountSources{})
+}
+
+// CountAllFrom is CountAll using the copies in src where it has them.
+func CountAllFrom(dataDir, discoveryDomain string, src CountSources) Counts {
+	counts := Counts{}
+
+	// Posts — read from public.jsonl index (handles date-based subdirectories)
+	data := src.PublicIndex
+	if data == nil {
+		data, _ = os.ReadFile(filepath.Join(dataDir, "metadata", "public.jsonl"))
+	}
+	if data != nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			// Skip comment entries in public.jsonl
+			if !strings.Contains(line, `"comments/`) {
+				counts.Posts++
+			}
+		}
+	}
+
+	// Drafts
+	draftsDir := filepath.Join(dataDir, ".polis", "posts", "drafts")
+	if entries, err := os.ReadDir(draftsDir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				counts.Drafts++
+			}
+		}
+	}
+
+	// My comments by status (pending/denied are flat in .polis/comments/<status>/)
+	for _, status := range []struct {
+		dir  string
+		dest *int
+	}{
+		{"pending", &counts.MyPending},
+		{"denied", &counts.MyDenied},
+	} {
+		dir := filepath.Join(dataDir, ".polis", "comments", status.dir)
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, e := range entries {
+				if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+					*status.dest++
+				}
+			}
+		}
+	}
+
+	// My blessed comments live in public comments/YYYYMMDD/ (date-based subdirs)
+	blessedDir := filepath.Join(dataDir, "comments")
+	if dateDirs, err := os.ReadDir(blessedDir); err == nil {
+		for _, dd := range dateDirs {
+			if !dd.IsDir() {
+				continue
+			}
+			subDir := filepath.Join(blessedDir, dd.Name())
+			if files, err := os.ReadDir(subDir); err == nil {
+				for _, f := range files {
+					if !f.IsDir() && strings.HasSuffix(f.Name(), ".md") {
+						counts.MyBlessed++
+					}
+				}
+			}
+		}
+	}
+
+	// Comment drafts
+	commentDraftsDir := filepath.Join(dataDir, ".polis", "comments", "drafts")
+	if entries, err := os.ReadDir(commentDraftsDir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				counts.MyCommentDrafts++
+			}
+		}
+	}
+
+	// Incoming blessed comments (on our posts)
+	blessedIndex := filepath.Join(dataDir, "metadata", "blessed-comments.json")
+	if data, err := os.ReadFile(blessedIndex); err == nil {
+		var idx map[string]interface{}
+		if json.Unmarshal(data, &idx) == nil {
+			if posts, ok := idx["posts"].(map[string]interface{}); ok {
+				for _, v := range posts {
+					if comments, ok := v.([]interface{}); ok {
+						counts.IncomingBlessed += len(comments)
+					}
+				}
+			}
+		}
+	}
+
+	// Following
+	f := src.Following
+	if f == nil {
+		f, _ = following.Load(following.DefaultPath(dataDir))
+	}
+	if f != nil {
+		counts.Following = f.Count()
+	}
+
+	// Feed counts
+	items := src.FeedItems
+	if items == nil {
+		items, _ = feed.NewCacheManager(dataDir, discoveryDomain).List()
+	}
+	if items != nil {
+		counts.Feed = len(items)
+		for _, item := range items {
+			if item.ReadAt == "" {
+				counts.FeedUnread++
+			}
+		}
+	}
+
+	// Followers (from cached state)
+	store := stream.NewStore(dataDir, discoveryDomain)
+	var followerState stream.FollowerState
+	if store.LoadState("polis.follow", &followerState) == nil {
+		counts.Followers = followerState.Count
+	}
+
+	// Notification unread count
+	mgr := notification.NewManager(dataDir, discoveryDomain)
+	if unread, err := mgr.CountUnread(); err == nil {
+		counts.NotificationsUnread = unread
+	}
+
+	// Incoming pending blessing requests — read from DS-cached blessing state
+	var blessingState stream.BlessingState
+	if store.LoadState("polis.blessing", &blessingState) == nil {
+		for _, b := range blessingState.Blessings {
+			if b.Status == "pending" {
+				counts.BlessingRequests++
+				counts.IncomingPending++
+			}
+		}
+	}
+
+	return counts
+}
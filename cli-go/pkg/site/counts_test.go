@@ -0,0 +1,42 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountAll(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("metadata/public.jsonl", `{"path":"posts/20260101/a.md"}
+{"path":"posts/20260102/b.md"}
+{"path":"comments/20260103/c.md"}
+`)
+	write(".polis/posts/drafts/d1.json", "{}")
+	write(".polis/comments/pending/p1.md", "")
+	write(".polis/comments/denied/x1.md", "")
+	write(".polis/comments/drafts/cd1.json", "{}")
+	write("comments/20260103/c.md", "")
+
+	counts := CountAll(dir, "example.com")
+	if counts.Posts != 2 {
+		t.Errorf("Posts = %d, want 2", counts.Posts)
+	}
+	if counts.Drafts != 1 || counts.MyPending != 1 || counts.MyDenied != 1 || counts.MyCommentDrafts != 1 || counts.MyBlessed != 1 {
+		t.Errorf("unexpected comment/draft counts: %+v", counts)
+	}
+	if counts.Feed != 0 || counts.BlessingRequests != 0 {
+		t.Errorf("expected empty social counts, got %+v", counts)
+	}
+}
@@ -144,7 +144,7 @@ func Init(siteDir string, opts InitOptions) (*InitResult, error) {
 		// Track relative path for result
 		rel, _ := filepath.Rel(siteDir, dir)
 		if rel != "." {
-			dirsCreated = append(dirsCreated, rel)
+			dirsCreated = append(dirsCreated, filepath.ToSlash(rel))
 		}
 	}
 	result.DirsCreated = dirsCreated
@@ -218,7 +218,7 @@ func Init(siteDir string, opts InitOptions) (*InitResult, error) {
 			return nil, fmt.Errorf("failed to create default about snippet: %w", err)
 		}
 		rel, _ := filepath.Rel(siteDir, aboutPath)
-		filesCreated = append(filesCreated, rel)
+		filesCreated = append(filesCreated, filepath.ToSlash(rel))
 	}
 
 	// Create webapp-config.json with webapp-specific defaults only.
@@ -274,7 +274,7 @@ func initMetadataFiles(siteDir, version string, opts InitOptions, filesCreated *
 			return err
 		}
 		rel, _ := filepath.Rel(siteDir, manifestPath)
-		*filesCreated = append(*filesCreated, rel)
+		*filesCreated = append(*filesCreated, filepath.ToSlash(rel))
 	}
 
 	// Create following index
@@ -2,10 +2,11 @@ package site
 
 import (
 	"encoding/json"
-	"log"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
 )
 
 // KnownFields lists all recognized .well-known/polis field paths.
@@ -17,6 +18,7 @@ var KnownFields = map[string]bool{
 	"email":                         true,
 	"public_key":                    true,
 	"site_title":                    true,
+	"lang":                          true,
 	"domain":                        true,
 	"created":                       true,
 	"config":                        true,
@@ -54,10 +56,10 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 		if wk.CreatedAt != "" {
 			wk.Created = wk.CreatedAt
 			wk.CreatedAt = "" // Clear deprecated field
-			log.Printf("[upgrade] Migrated created_at to created")
+			logging.Info("Migrated created_at to created", "component", "upgrade")
 		} else {
 			wk.Created = time.Now().UTC().Format(time.RFC3339)
-			log.Printf("[upgrade] Added created timestamp: %s", wk.Created)
+			logging.Info("Added created timestamp", "component", "upgrade", "created", wk.Created)
 		}
 		upgraded = true
 	}
@@ -66,7 +68,7 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 	if wk.Author == "" {
 		if author := getGitConfig("user.name"); author != "" {
 			wk.Author = author
-			log.Printf("[upgrade] Added author from git config: %s", author)
+			logging.Info("Added author from git config", "component", "upgrade", "author", author)
 			upgraded = true
 		}
 	}
@@ -75,7 +77,7 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 	if wk.Email == "" {
 		if email := getGitConfig("user.email"); email != "" {
 			wk.Email = email
-			log.Printf("[upgrade] Added email from git config: %s", email)
+			logging.Info("Added email from git config", "component", "upgrade", "email", email)
 			upgraded = true
 		}
 	}
@@ -97,7 +99,7 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 				FollowingIndex:  "metadata/following.json",
 			},
 		}
-		log.Printf("[upgrade] Added config section with default paths")
+		logging.Info("Added config section with default paths", "component", "upgrade")
 		upgraded = true
 	}
 
@@ -113,14 +115,14 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 
 	// Remove base_url (runtime config, should use POLIS_BASE_URL env var instead)
 	if wk.BaseURL != "" {
-		log.Printf("[upgrade] Removing base_url (use POLIS_BASE_URL env var instead)")
+		logging.Info("Removing base_url (use POLIS_BASE_URL env var instead)", "component", "upgrade")
 		wk.BaseURL = ""
 		upgraded = true
 	}
 
 	// Remove subdomain (derived from POLIS_BASE_URL at runtime)
 	if wk.Subdomain != "" {
-		log.Printf("[upgrade] Removing subdomain (derived from POLIS_BASE_URL)")
+		logging.Info("Removing subdomain (derived from POLIS_BASE_URL)", "component", "upgrade")
 		wk.Subdomain = ""
 		upgraded = true
 	}
@@ -129,7 +131,7 @@ func UpgradeWellKnown(siteDir string) (*WellKnown, error) {
 		if err := SaveWellKnown(siteDir, wk); err != nil {
 			return nil, err
 		}
-		log.Printf("[upgrade] Saved upgraded .well-known/polis")
+		logging.Info("Saved upgraded .well-known/polis", "component", "upgrade")
 	}
 
 	return wk, nil
@@ -150,9 +152,9 @@ func CheckUnrecognizedFields(siteDir string) {
 
 	checkFields(raw, "", func(path string) {
 		if known, exists := KnownFields[path]; !exists {
-			log.Printf("[wellknown] Unrecognized field '%s' - may be safe to remove", path)
+			logging.Warn("Unrecognized .well-known/polis field - may be safe to remove", "field", path)
 		} else if !known {
-			log.Printf("[wellknown] Deprecated field '%s' - safe to remove after upgrade", path)
+			logging.Warn("Deprecated .well-known/polis field - safe to remove after upgrade", "field", path)
 		}
 	})
 }
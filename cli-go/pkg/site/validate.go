@@ -7,6 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
 
 // ValidationStatus represents the result of site validation.
@@ -97,12 +100,15 @@ func Validate(siteDir string) *ValidationResult {
 	// Check private key
 	privKeyPath := filepath.Join(siteDir, ".polis", "keys", "id_ed25519")
 	if _, err := os.Stat(privKeyPath); os.IsNotExist(err) {
-		errors = append(errors, ValidationError{
-			Code:       "PRIVATE_KEY_MISSING",
-			Message:    "Private key file not found",
-			Path:       privKeyPath,
-			Suggestion: "Initialize the site to generate keys, or restore your backed-up keys",
-		})
+		// A key moved into ssh-agent leaves only the public key on disk
+		if !agentHoldsKey(siteDir) {
+			errors = append(errors, ValidationError{
+				Code:       "PRIVATE_KEY_MISSING",
+				Message:    "Private key file not found",
+				Path:       privKeyPath,
+				Suggestion: "Initialize the site to generate keys, restore your backed-up keys, or load the key in ssh-agent",
+			})
+		}
 	} else if err != nil {
 		errors = append(errors, ValidationError{
 			Code:    "PRIVATE_KEY_ERROR",
@@ -133,7 +139,7 @@ func Validate(siteDir string) *ValidationResult {
 	if wellKnown != nil && pubKeyErr == nil && len(pubKeyData) > 0 {
 		pubKeyStr := strings.TrimSpace(string(pubKeyData))
 		wellKnownPubKey := strings.TrimSpace(wellKnown.PublicKey)
-		if pubKeyStr != wellKnownPubKey {
+		if pubKeyStr != wellKnownPubKey && !delegatedKey(privKeyPath, pubKeyStr) {
 			errors = append(errors, ValidationError{
 				Code:       "PUBLIC_KEY_MISMATCH",
 				Message:    "Public key in .well-known/polis does not match key file",
@@ -224,3 +230,29 @@ func IsValidPolisSite(siteDir string) bool {
 	result := Validate(siteDir)
 	return result.Status == StatusValid
 }
+
+// delegatedKey reports whether the private key at privKeyPath is a device key
+// whose delegation certificate covers pubKey.
+func delegatedKey(privKeyPath, pubKey string) bool {
+	data, err := os.ReadFile(privKeyPath)
+	if err != nil {
+		return false
+	}
+	cert, err := delegation.FromKey(data)
+	if err != nil || cert == nil {
+		return false
+	}
+	fields := strings.Fields(pubKey)
+	return len(fields) >= 2 && cert.Key == fields[0]+" "+fields[1]
+}
+
+// agentHoldsKey reports whether ssh-agent holds the site's key, for sites
+// whose private key file was moved into the agent.
+func agentHoldsKey(siteDir string) bool {
+	pub, err := os.ReadFile(filepath.Join(siteDir, ".polis", "keys", "id_ed25519.pub"))
+	if err != nil {
+		return false
+	}
+	_, err = signing.AgentKey(pub)
+	return err == nil
+}
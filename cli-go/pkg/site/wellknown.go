@@ -5,6 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
 )
 
 // WellKnownDirectories contains directory path configuration.
@@ -30,18 +34,33 @@ type WellKnownConfig struct {
 	Files       WellKnownFiles       `json:"files,omitempty"`
 }
 
+// IdentityProof is a signed statement that the site's author also controls
+// an account elsewhere (a GitHub account, a DNS name, a profile page).
+type IdentityProof struct {
+	Service   string `json:"service"`       // github, dns, or url
+	Account   string `json:"account"`       // Username, domain name, or profile URL
+	URL       string `json:"url,omitempty"` // Where the proof is posted, if not implied by the account
+	Statement string `json:"statement"`
+	Signature string `json:"signature"`
+	Created   string `json:"created"`
+}
+
 // WellKnown represents the .well-known/polis file structure.
 // This struct supports both canonical fields (bash CLI) and webapp-specific fields.
 type WellKnown struct {
 	// Canonical fields (bash CLI)
-	Version   string           `json:"version,omitempty"`
-	Author    string           `json:"author,omitempty"`
-	Domain    string           `json:"domain,omitempty"`
-	Email     string           `json:"email,omitempty"` // Private by default; only serialized if user opts in
-	PublicKey string           `json:"public_key"`
-	SiteTitle string           `json:"site_title,omitempty"`
-	Created   string           `json:"created,omitempty"`
-	Config    *WellKnownConfig `json:"config,omitempty"`
+	Version   string              `json:"version,omitempty"`
+	Author    string              `json:"author,omitempty"`
+	Domain    string              `json:"domain,omitempty"`
+	Email     string              `json:"email,omitempty"` // Private by default; only serialized if user opts in
+	PublicKey string              `json:"public_key"`
+	SiteTitle string              `json:"site_title,omitempty"`
+	Lang      string              `json:"lang,omitempty"` // Default language of posts (e.g. "en")
+	Created   string              `json:"created,omitempty"`
+	Config    *WellKnownConfig    `json:"config,omitempty"`
+	Proofs    []IdentityProof     `json:"proofs,omitempty"`  // Accounts elsewhere the author has proven
+	Devices   []delegation.Device `json:"devices,omitempty"` // Device keys the site key has delegated to
+	Authors   []coauthor.Author   `json:"authors,omitempty"` // Co-authors who sign with their own keys
 
 	// Webapp-specific fields (kept for compatibility)
 	Subdomain string `json:"subdomain,omitempty"`
@@ -83,7 +102,7 @@ func SaveWellKnown(siteDir string, wk *WellKnown) error {
 	}
 
 	path := filepath.Join(dir, "polis")
-	return os.WriteFile(path, data, 0644)
+	return fsutil.WriteFile(path, data, 0644)
 }
 
 // GetSiteTitle returns the site title from .well-known/polis.
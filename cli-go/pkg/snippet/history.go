@@ -0,0 +1,190 @@
+package snippet
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
+)
+
+// MaxVersions is how many previous versions of each snippet are kept.
+const MaxVersions = 20
+
+// historyDirName is the directory, inside a snippets directory, that holds
+// previous versions: .history/<snippet path>/<version>. ListSnippets skips
+// it like any other dot-file.
+const historyDirName = ".history"
+
+// versionFormat names versions by when they were replaced, so they sort in
+// time order.
+const versionFormat = "20060102T150405.000Z"
+
+// SnippetVersion is a previous version of a snippet, saved when it was
+// overwritten or deleted.
+type SnippetVersion struct {
+	Version string `json:"version"`
+	Path    string `json:"path"` // Snippet path, with extension
+	Source  string `json:"source"`
+	Size    int64  `json:"size"`
+	SavedAt string `json:"saved_at"`
+}
+
+// historyName returns the storage name of the history of the snippet at
+// name in d.
+func (d snippetDir) historyName(name string) string {
+	return storage.Join(d.name, historyDirName, strings.TrimPrefix(name, d.name+"/"))
+}
+
+// saveVersion copies the snippet at name in d to its history, unless it is
+// missing or unchanged from content, and drops versions past MaxVersions.
+func (d snippetDir) saveVersion(name string, content *string) error {
+	data, err := d.fsys.ReadFile(name)
+	if err != nil || (content != nil && string(data) == *content) {
+		return nil
+	}
+	hist := d.historyName(name)
+	now := time.Now().UTC()
+	version := now.Format(versionFormat)
+	for storage.Exists(d.fsys, storage.Join(hist, version)) {
+		now = now.Add(time.Millisecond)
+		version = now.Format(versionFormat)
+	}
+	if err := d.fsys.WriteFile(storage.Join(hist, version), data, 0644); err != nil {
+		return fmt.Errorf("failed to save snippet version: %w", err)
+	}
+
+	versions := d.versionNames(hist)
+	for len(versions) > MaxVersions {
+		d.fsys.Remove(storage.Join(hist, versions[0]))
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// versionNames returns the versions in a history directory, oldest first.
+func (d snippetDir) versionNames(hist string) []string {
+	entries, err := d.fsys.ReadDir(hist)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if _, err := time.Parse(versionFormat, e.Name()); err == nil && !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sourceDirs returns the directories a snippet from source may live in, in
+// lookup order.
+func sourceDirs(dataDir, cliThemesDir, activeTheme, source string) ([]snippetDir, error) {
+	switch source {
+	case "global":
+		return []snippetDir{globalSnippets(dataDir)}, nil
+	case "theme":
+		if activeTheme == "" {
+			var err error
+			activeTheme, err = GetActiveTheme(dataDir)
+			if err != nil {
+				activeTheme = "zane"
+			}
+		}
+		dirs := []snippetDir{localThemeSnippets(dataDir, activeTheme)}
+		if cliThemesDir != "" {
+			dirs = append(dirs, cliThemeSnippets(cliThemesDir, activeTheme))
+		}
+		return dirs, nil
+	default:
+		return nil, fmt.Errorf("invalid source: must be 'global' or 'theme'")
+	}
+}
+
+// findHistory returns the directory and history name of a snippet, which
+// may since have been deleted. Extension fallback is as for ReadSnippet.
+func findHistory(dataDir, cliThemesDir, activeTheme, snippetPath, source string) (snippetDir, string, error) {
+	if err := validatePath(snippetPath); err != nil {
+		return snippetDir{}, "", err
+	}
+	dirs, err := sourceDirs(dataDir, cliThemesDir, activeTheme, source)
+	if err != nil {
+		return snippetDir{}, "", err
+	}
+	candidates := []string{snippetPath}
+	if ext := filepath.Ext(snippetPath); ext != ".html" && ext != ".md" {
+		candidates = []string{snippetPath + ".md", snippetPath + ".html", snippetPath}
+	}
+	for _, dir := range dirs {
+		if name := resolveSnippetFile(dir, snippetPath); name != "" {
+			return dir, dir.historyName(name), nil
+		}
+		for _, c := range candidates {
+			if hist := dir.historyName(dir.join(c)); len(dir.versionNames(hist)) > 0 {
+				return dir, hist, nil
+			}
+		}
+	}
+	return snippetDir{}, "", fmt.Errorf("snippet not found: %s", snippetPath)
+}
+
+// ListVersions returns the saved versions of a snippet, newest first.
+func ListVersions(dataDir, cliThemesDir, activeTheme, snippetPath, source string) ([]SnippetVersion, error) {
+	dir, hist, err := findHistory(dataDir, cliThemesDir, activeTheme, snippetPath, source)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimPrefix(hist, storage.Join(dir.name, historyDirName)+"/")
+	names := dir.versionNames(hist)
+	versions := make([]SnippetVersion, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		v := SnippetVersion{Version: names[i], Path: path, Source: source}
+		if info, err := dir.fsys.Stat(storage.Join(hist, names[i])); err == nil {
+			v.Size = info.Size()
+		}
+		if t, err := time.Parse(versionFormat, names[i]); err == nil {
+			v.SavedAt = t.Format(time.RFC3339)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// ReadVersion returns the content of a saved version of a snippet.
+func ReadVersion(dataDir, cliThemesDir, activeTheme, snippetPath, source, version string) (*SnippetContent, error) {
+	if _, err := time.Parse(versionFormat, version); err != nil {
+		return nil, fmt.Errorf("invalid version: %q", version)
+	}
+	dir, hist, err := findHistory(dataDir, cliThemesDir, activeTheme, snippetPath, source)
+	if err != nil {
+		return nil, err
+	}
+	data, err := dir.fsys.ReadFile(storage.Join(hist, version))
+	if err != nil {
+		return nil, fmt.Errorf("version not found: %s", version)
+	}
+	t, _ := time.Parse(versionFormat, version)
+	return &SnippetContent{
+		Path:    strings.TrimPrefix(hist, storage.Join(dir.name, historyDirName)+"/"),
+		Source:  source,
+		Content: string(data),
+		ModTime: t.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// RestoreVersion makes a saved version the snippet's content again,
+// recreating it if it was deleted. The content it replaces is saved as a
+// version in turn, so a restore can itself be undone.
+func RestoreVersion(dataDir, cliThemesDir, activeTheme, snippetPath, source, version string) (*SnippetContent, error) {
+	old, err := ReadVersion(dataDir, cliThemesDir, activeTheme, snippetPath, source, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteSnippet(dataDir, cliThemesDir, activeTheme, old.Path, old.Content, source); err != nil {
+		return nil, err
+	}
+	return ReadSnippet(dataDir, cliThemesDir, activeTheme, old.Path, source)
+}
@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/storage"
 )
 
 // SnippetInfo represents metadata about a single snippet file or directory.
@@ -42,6 +44,30 @@ type SnippetContent struct {
 	ModTime string `json:"mod_time"`
 }
 
+// snippetDir is a directory of snippets: a directory in the site's storage,
+// or one of the CLI's theme directories on disk.
+type snippetDir struct {
+	fsys storage.FS
+	name string
+}
+
+func globalSnippets(dataDir string) snippetDir {
+	return snippetDir{storage.For(dataDir), "snippets"}
+}
+
+func localThemeSnippets(dataDir, theme string) snippetDir {
+	return snippetDir{storage.For(dataDir), storage.Join(".polis/themes", theme, "snippets")}
+}
+
+func cliThemeSnippets(cliThemesDir, theme string) snippetDir {
+	return snippetDir{storage.Dir(cliThemesDir), storage.Join(theme, "snippets")}
+}
+
+// join returns the storage name of rel inside d.
+func (d snippetDir) join(rel string) string {
+	return storage.Join(d.name, storage.Name(rel))
+}
+
 // validatePath ensures the path is safe and doesn't contain traversal sequences.
 func validatePath(path string) error {
 	// Reject absolute paths
@@ -61,8 +87,7 @@ func validatePath(path string) error {
 
 // GetActiveTheme reads the active_theme from manifest.json.
 func GetActiveTheme(dataDir string) (string, error) {
-	manifestPath := filepath.Join(dataDir, "metadata", "manifest.json")
-	data, err := os.ReadFile(manifestPath)
+	data, err := storage.For(dataDir).ReadFile("metadata/manifest.json")
 	if err != nil {
 		// Default to "zane" if manifest doesn't exist or can't be read
 		return "zane", nil
@@ -105,18 +130,15 @@ func ListSnippets(dataDir, cliThemesDir, activeTheme, relativePath, filter strin
 		}
 	}
 
-	globalBase := filepath.Join(dataDir, "snippets")
+	globalBase := globalSnippets(dataDir)
 
 	// Theme snippets: prefer local .polis/themes/, fall back to CLI themes
-	themeBase := filepath.Join(dataDir, ".polis", "themes", activeTheme, "snippets")
-	if _, err := os.Stat(themeBase); os.IsNotExist(err) && cliThemesDir != "" {
+	themeBase := localThemeSnippets(dataDir, activeTheme)
+	if _, err := themeBase.fsys.Stat(themeBase.name); os.IsNotExist(err) && cliThemesDir != "" {
 		// Fall back to CLI themes directory
-		themeBase = filepath.Join(cliThemesDir, activeTheme, "snippets")
+		themeBase = cliThemeSnippets(cliThemesDir, activeTheme)
 	}
 
-	globalPath := filepath.Join(globalBase, relativePath)
-	themePath := filepath.Join(themeBase, relativePath)
-
 	// Maps to track entries: key is the entry name
 	entries := make(map[string]SnippetInfo)
 	themeEntries := make(map[string]bool) // Track which entries exist in theme
@@ -127,7 +149,7 @@ func ListSnippets(dataDir, cliThemesDir, activeTheme, relativePath, filter strin
 
 	// First, read theme snippets (will be overridden by global if scanning both)
 	if scanTheme {
-		if themeDir, err := os.ReadDir(themePath); err == nil {
+		if themeDir, err := themeBase.fsys.ReadDir(themeBase.join(relativePath)); err == nil {
 			for _, entry := range themeDir {
 				name := entry.Name()
 				// Skip hidden files
@@ -170,7 +192,7 @@ func ListSnippets(dataDir, cliThemesDir, activeTheme, relativePath, filter strin
 
 	// Then, read global snippets (override theme entries if scanning both)
 	if scanGlobal {
-		if globalDir, err := os.ReadDir(globalPath); err == nil {
+		if globalDir, err := globalBase.fsys.ReadDir(globalBase.join(relativePath)); err == nil {
 			for _, entry := range globalDir {
 				name := entry.Name()
 				// Skip hidden files
@@ -243,36 +265,24 @@ func ListSnippets(dataDir, cliThemesDir, activeTheme, relativePath, filter strin
 	}, nil
 }
 
-// resolveSnippetFile tries to find the snippet file, handling extension fallback.
+// resolveSnippetFile tries to find the snippet file in dir, handling
+// extension fallback, and returns its storage name or "".
 // Per TEMPLATING.md, resolution order is: .md -> .html -> exact
-func resolveSnippetFile(baseDir, snippetPath string) string {
+func resolveSnippetFile(dir snippetDir, snippetPath string) string {
 	// If path already has extension, try exact match only
 	ext := filepath.Ext(snippetPath)
 	if ext == ".html" || ext == ".md" {
-		fullPath := filepath.Join(baseDir, snippetPath)
-		if _, err := os.Stat(fullPath); err == nil {
-			return fullPath
+		if name := dir.join(snippetPath); storage.Exists(dir.fsys, name) {
+			return name
 		}
 		return ""
 	}
 
 	// Per TEMPLATING.md: .md -> .html -> exact
-	// Try with .md extension first
-	fullPath := filepath.Join(baseDir, snippetPath+".md")
-	if _, err := os.Stat(fullPath); err == nil {
-		return fullPath
-	}
-
-	// Try with .html extension
-	fullPath = filepath.Join(baseDir, snippetPath+".html")
-	if _, err := os.Stat(fullPath); err == nil {
-		return fullPath
-	}
-
-	// Try exact path
-	fullPath = filepath.Join(baseDir, snippetPath)
-	if _, err := os.Stat(fullPath); err == nil {
-		return fullPath
+	for _, candidate := range []string{snippetPath + ".md", snippetPath + ".html", snippetPath} {
+		if name := dir.join(candidate); storage.Exists(dir.fsys, name) {
+			return name
+		}
 	}
 
 	return ""
@@ -297,31 +307,35 @@ func ReadSnippet(dataDir, cliThemesDir, activeTheme, snippetPath, source string)
 		}
 	}
 
-	var fullPath string
+	var dir snippetDir
+	var name string
 	switch source {
 	case "global":
-		fullPath = resolveSnippetFile(filepath.Join(dataDir, "snippets"), snippetPath)
+		dir = globalSnippets(dataDir)
+		name = resolveSnippetFile(dir, snippetPath)
 	case "theme":
 		// Theme snippets: prefer local .polis/themes/, fall back to CLI themes
-		fullPath = resolveSnippetFile(filepath.Join(dataDir, ".polis", "themes", activeTheme, "snippets"), snippetPath)
-		if fullPath == "" && cliThemesDir != "" {
+		dir = localThemeSnippets(dataDir, activeTheme)
+		name = resolveSnippetFile(dir, snippetPath)
+		if name == "" && cliThemesDir != "" {
 			// Fall back to CLI themes directory
-			fullPath = resolveSnippetFile(filepath.Join(cliThemesDir, activeTheme, "snippets"), snippetPath)
+			dir = cliThemeSnippets(cliThemesDir, activeTheme)
+			name = resolveSnippetFile(dir, snippetPath)
 		}
 	default:
 		return nil, fmt.Errorf("invalid source: must be 'global' or 'theme'")
 	}
 
-	if fullPath == "" {
+	if name == "" {
 		return nil, fmt.Errorf("snippet not found: %s", snippetPath)
 	}
 
-	data, err := os.ReadFile(fullPath)
+	data, err := dir.fsys.ReadFile(name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read snippet: %w", err)
 	}
 
-	info, err := os.Stat(fullPath)
+	info, err := dir.fsys.Stat(name)
 	modTime := ""
 	if err == nil {
 		modTime = info.ModTime().Format("2006-01-02T15:04:05Z")
@@ -341,7 +355,8 @@ func ReadSnippet(dataDir, cliThemesDir, activeTheme, snippetPath, source string)
 //  2. cli/themes/{active_theme}/snippets/ (fallback to CLI themes)
 //
 // When writing, if the snippetPath doesn't have an extension and no existing
-// file is found, defaults to .html extension.
+// file is found, defaults to .html extension. The content being replaced is
+// kept as a version (see ListVersions).
 func WriteSnippet(dataDir, cliThemesDir, activeTheme, snippetPath, content, source string) error {
 	if err := validatePath(snippetPath); err != nil {
 		return err
@@ -356,65 +371,54 @@ func WriteSnippet(dataDir, cliThemesDir, activeTheme, snippetPath, content, sour
 		}
 	}
 
-	var fullPath string
+	var dir snippetDir
+	var name string
 	switch source {
 	case "global":
 		// Try to resolve existing file first
-		baseDir := filepath.Join(dataDir, "snippets")
-		resolved := resolveSnippetFile(baseDir, snippetPath)
-		if resolved != "" {
-			fullPath = resolved
-		} else {
+		dir = globalSnippets(dataDir)
+		name = resolveSnippetFile(dir, snippetPath)
+		if name == "" {
 			// New file: default to .html if no extension
 			ext := filepath.Ext(snippetPath)
 			if ext != ".html" && ext != ".md" {
 				snippetPath = snippetPath + ".html"
 			}
-			fullPath = filepath.Join(baseDir, snippetPath)
+			name = dir.join(snippetPath)
 		}
 	case "theme":
 		// Theme snippets: prefer local .polis/themes/, fall back to CLI themes
-		localThemeBase := filepath.Join(dataDir, ".polis", "themes", activeTheme, "snippets")
-		cliThemeBase := filepath.Join(cliThemesDir, activeTheme, "snippets")
+		localTheme := localThemeSnippets(dataDir, activeTheme)
 
 		// Try to resolve existing file
-		resolved := resolveSnippetFile(localThemeBase, snippetPath)
-		if resolved != "" {
-			fullPath = resolved
-		} else if cliThemesDir != "" {
-			resolved = resolveSnippetFile(cliThemeBase, snippetPath)
-			if resolved != "" {
-				fullPath = resolved
-			}
+		dir = localTheme
+		name = resolveSnippetFile(dir, snippetPath)
+		if name == "" && cliThemesDir != "" {
+			dir = cliThemeSnippets(cliThemesDir, activeTheme)
+			name = resolveSnippetFile(dir, snippetPath)
 		}
 
 		// If no existing file found, default to local theme path with .html
-		if fullPath == "" {
+		if name == "" {
 			ext := filepath.Ext(snippetPath)
 			if ext != ".html" && ext != ".md" {
 				snippetPath = snippetPath + ".html"
 			}
-			fullPath = filepath.Join(localThemeBase, snippetPath)
+			dir = localTheme
+			name = dir.join(snippetPath)
 		}
 	default:
 		return fmt.Errorf("invalid source: must be 'global' or 'theme'")
 	}
 
-	// Ensure parent directory exists
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Write atomically via temp file
-	tmpPath := fullPath + ".tmp"
-	if err := os.WriteFile(tmpPath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
+	// Keep what's being replaced, so a bad save can be rolled back
+	if err := dir.saveVersion(name, &content); err != nil {
+		return err
 	}
 
-	if err := os.Rename(tmpPath, fullPath); err != nil {
-		os.Remove(tmpPath) // Clean up temp file on failure
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	// Write atomically via temp file, creating the parent directory
+	if err := dir.fsys.WriteFile(name, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write snippet: %w", err)
 	}
 
 	return nil
@@ -433,21 +437,16 @@ func CreateSnippet(dataDir, snippetPath, content string) error {
 		return fmt.Errorf("snippet must have .html or .md extension")
 	}
 
-	fullPath := filepath.Join(dataDir, "snippets", snippetPath)
+	dir := globalSnippets(dataDir)
+	name := dir.join(snippetPath)
 
 	// Check if snippet already exists
-	if _, err := os.Stat(fullPath); err == nil {
+	if storage.Exists(dir.fsys, name) {
 		return fmt.Errorf("snippet already exists: %s", snippetPath)
 	}
 
-	// Ensure parent directory exists
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Write the file
-	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+	// Write the file, creating the parent directory
+	if err := dir.fsys.WriteFile(name, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to create snippet: %w", err)
 	}
 
@@ -455,16 +454,18 @@ func CreateSnippet(dataDir, snippetPath, content string) error {
 }
 
 // DeleteSnippet removes a snippet from the global snippets directory only.
-// Theme snippet deletion is not supported.
+// Theme snippet deletion is not supported. The snippet is kept as a version,
+// so RestoreVersion can bring it back.
 func DeleteSnippet(dataDir, snippetPath string) error {
 	if err := validatePath(snippetPath); err != nil {
 		return err
 	}
 
-	fullPath := filepath.Join(dataDir, "snippets", snippetPath)
+	dir := globalSnippets(dataDir)
+	name := dir.join(snippetPath)
 
 	// Check if it exists
-	info, err := os.Stat(fullPath)
+	info, err := dir.fsys.Stat(name)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("snippet not found: %s", snippetPath)
@@ -477,7 +478,10 @@ func DeleteSnippet(dataDir, snippetPath string) error {
 		return fmt.Errorf("cannot delete directory: %s", snippetPath)
 	}
 
-	if err := os.Remove(fullPath); err != nil {
+	if err := dir.saveVersion(name, nil); err != nil {
+		return err
+	}
+	if err := dir.fsys.Remove(name); err != nil {
 		return fmt.Errorf("failed to delete snippet: %w", err)
 	}
 
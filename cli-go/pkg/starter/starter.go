@@ -0,0 +1,368 @@
+// Package starter packs and applies starter kits: zip archives holding a
+// theme, global snippets (including navigation), sample pages, and a few
+// presentation settings, used to bootstrap a new site.
+//
+// A starter never carries keys, .env, .well-known, published content,
+// metadata, or anything else tied to the author who packed it.
+package starter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+)
+
+// ManifestName is the starter manifest at the root of the archive.
+const ManifestName = "polis-starter.json"
+
+// MaxSize caps how much Fetch will download or read.
+const MaxSize = 32 << 20
+
+// settingKeys lists the webapp-config.json settings a starter may carry.
+// Everything else in that file (setup time, hooks, schedules, credentials)
+// belongs to the site owner.
+var settingKeys = []string{"view_mode", "show_frontmatter", "hide_read"}
+
+// personalSnippets are global snippets that describe the author rather than
+// the site design, so Pack leaves them out.
+var personalSnippets = map[string]bool{"about.md": true}
+
+// Manifest describes a starter package.
+type Manifest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Generator   string                 `json:"generator,omitempty"`
+	Created     string                 `json:"created"`
+	ActiveTheme string                 `json:"active_theme,omitempty"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+}
+
+// PackOptions controls Pack.
+type PackOptions struct {
+	Name        string   // Starter name (default: data directory name)
+	Description string   // Optional one-line description
+	Generator   string   // Tool version recorded in the manifest
+	Pages       []string // Markdown files to include as sample pages
+}
+
+// PackResult reports what Pack wrote.
+type PackResult struct {
+	Path     string   `json:"path"`
+	Manifest Manifest `json:"manifest"`
+	Files    []string `json:"files"`
+}
+
+// Pack writes a starter archive for dataDir to out. It includes the active
+// local theme (if any), global snippets other than the author bio, the given pages with their
+// frontmatter removed, and the settings in settingKeys.
+func Pack(dataDir, out string, opts PackOptions) (*PackResult, error) {
+	name := opts.Name
+	if name == "" {
+		abs, _ := filepath.Abs(dataDir)
+		name = filepath.Base(abs)
+	}
+	m := Manifest{
+		Name:        name,
+		Description: opts.Description,
+		Generator:   opts.Generator,
+		Created:     time.Now().UTC().Format(time.RFC3339),
+		ActiveTheme: activeTheme(dataDir),
+		Settings:    packSettings(dataDir),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var files []string
+	add := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	}
+
+	if m.ActiveTheme != "" {
+		themeDir := filepath.Join(dataDir, ".polis", "themes", m.ActiveTheme)
+		if info, err := os.Stat(themeDir); err == nil && info.IsDir() {
+			if err := addTree(themeDir, "themes/"+m.ActiveTheme, add); err != nil {
+				return nil, fmt.Errorf("failed to pack theme: %w", err)
+			}
+		}
+	}
+	addSnippet := func(name string, data []byte) error {
+		if personalSnippets[strings.TrimPrefix(name, "snippets/")] {
+			return nil
+		}
+		return add(name, data)
+	}
+	if err := addTree(filepath.Join(dataDir, "snippets"), "snippets", addSnippet); err != nil {
+		return nil, fmt.Errorf("failed to pack snippets: %w", err)
+	}
+	for _, p := range opts.Pages {
+		if !strings.HasSuffix(p, ".md") {
+			return nil, fmt.Errorf("page %s is not a markdown file", p)
+		}
+		src := p
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(dataDir, src)
+		}
+		content, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page: %w", err)
+		}
+		body := publish.StripFrontmatter(string(content))
+		if err := add("pages/"+filepath.Base(p), []byte(strings.TrimLeft(body, "\n"))); err != nil {
+			return nil, fmt.Errorf("failed to pack page: %w", err)
+		}
+	}
+
+	data, _ := json.MarshalIndent(m, "", "  ")
+	if err := add(ManifestName, append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := fsutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	sort.Strings(files)
+	return &PackResult{Path: out, Manifest: m, Files: files}, nil
+}
+
+// addTree adds every regular file under dir to the archive beneath prefix.
+// A missing dir adds nothing. Dotfiles and lock directories are skipped.
+func addTree(dir, prefix string, add func(string, []byte) error) error {
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") || strings.HasSuffix(info.Name(), lockfile.Suffix) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return add(prefix+"/"+filepath.ToSlash(rel), data)
+	})
+	return err
+}
+
+func activeTheme(dataDir string) string {
+	m, err := theme.LoadManifest(dataDir)
+	if err != nil {
+		return ""
+	}
+	return m.ActiveTheme
+}
+
+func packSettings(dataDir string) map[string]interface{} {
+	data, err := os.ReadFile(filepath.Join(dataDir, ".polis", "webapp-config.json"))
+	if err != nil {
+		return nil
+	}
+	var cfg map[string]interface{}
+	if json.Unmarshal(data, &cfg) != nil {
+		return nil
+	}
+	settings := map[string]interface{}{}
+	for _, k := range settingKeys {
+		if v, ok := cfg[k]; ok {
+			settings[k] = v
+		}
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+	return settings
+}
+
+// Fetch returns the raw starter archive at src, which is either an http(s)
+// URL or a local zip path.
+func Fetch(src string) ([]byte, error) {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		client := &http.Client{Timeout: 60 * time.Second}
+		resp, err := client.Get(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download starter: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download starter: HTTP %d", resp.StatusCode)
+		}
+		return readLimited(resp.Body)
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open starter: %w", err)
+	}
+	defer f.Close()
+	return readLimited(f)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read starter: %w", err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("starter exceeds %d MB", MaxSize>>20)
+	}
+	return data, nil
+}
+
+// ApplyResult reports what Apply installed.
+type ApplyResult struct {
+	Manifest     Manifest `json:"manifest"`
+	FilesCreated []string `json:"files_created"`
+	Drafts       []string `json:"drafts,omitempty"`
+}
+
+// Apply installs a starter archive into an initialized site: themes into
+// .polis/themes, snippets over the defaults, pages as post drafts, then
+// the active theme and settings. Entries outside those directories are
+// ignored, so a hostile archive cannot write keys or escape dataDir.
+func Apply(dataDir string, archive []byte) (*ApplyResult, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid starter archive: %w", err)
+	}
+
+	var m Manifest
+	found := false
+	for _, f := range zr.File {
+		if f.Name == ManifestName {
+			data, err := readEntry(f)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("not a starter archive: missing %s", ManifestName)
+	}
+
+	result := &ApplyResult{Manifest: m}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(f.Name)
+		if strings.HasPrefix(name, "../") || path.IsAbs(name) || strings.Contains(name, "\\") {
+			return nil, fmt.Errorf("invalid path in starter: %s", f.Name)
+		}
+
+		var rel string
+		switch {
+		case strings.HasPrefix(name, "themes/"):
+			rel = ".polis/" + name
+		case strings.HasPrefix(name, "snippets/"):
+			rel = name
+		case strings.HasPrefix(name, "pages/") && strings.HasSuffix(name, ".md") && path.Dir(name) == "pages":
+			rel = ".polis/posts/drafts/draft-" + idgen.New() + ".md"
+			result.Drafts = append(result.Drafts, path.Base(name))
+		default:
+			continue
+		}
+		dest := filepath.Join(dataDir, filepath.FromSlash(rel))
+
+		data, err := readEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", rel, err)
+		}
+		if err := fsutil.WriteFile(dest, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		result.FilesCreated = append(result.FilesCreated, rel)
+	}
+
+	if m.ActiveTheme != "" {
+		if _, err := os.Stat(filepath.Join(dataDir, ".polis", "themes", m.ActiveTheme)); err != nil {
+			return nil, fmt.Errorf("starter sets theme %q but does not include it", m.ActiveTheme)
+		}
+		if err := theme.SetActiveTheme(dataDir, m.ActiveTheme); err != nil {
+			return nil, fmt.Errorf("failed to set theme: %w", err)
+		}
+	}
+	if err := applySettings(dataDir, m.Settings); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("%s exceeds %d MB", f.Name, MaxSize>>20)
+	}
+	return data, nil
+}
+
+func applySettings(dataDir string, settings map[string]interface{}) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	cfgPath := filepath.Join(dataDir, ".polis", "webapp-config.json")
+	cfg := map[string]interface{}{}
+	if data, err := os.ReadFile(cfgPath); err == nil {
+		json.Unmarshal(data, &cfg)
+	}
+	for _, k := range settingKeys {
+		if v, ok := settings[k]; ok {
+			cfg[k] = v
+		}
+	}
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	if err := fsutil.WriteFile(cfgPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write webapp-config.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package starter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newSourceSite(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, dir, "metadata/manifest.json", `{"version":"0.1.0","active_theme":"ember"}`)
+	writeFile(t, dir, ".polis/themes/ember/index.html", "<html>{{content}}</html>")
+	writeFile(t, dir, ".polis/themes/ember/snippets/header.html", "<header/>")
+	writeFile(t, dir, ".polis/webapp-config.json", `{"setup_at":"2026-01-01T00:00:00Z","view_mode":"browser","hooks":{"post-publish":"x.sh"}}`)
+	writeFile(t, dir, ".polis/keys/id_ed25519", "PRIVATE")
+	writeFile(t, dir, ".env", "POLIS_BASE_URL=https://alice.example\n")
+	writeFile(t, dir, ".well-known/polis", "{}")
+	writeFile(t, dir, "snippets/nav.md", "[Home](/)")
+	writeFile(t, dir, "snippets/about.md", "I am Alice.")
+	writeFile(t, dir, "posts/20260101/hello.md", "---\ntitle: Hello\nsignature: abc\n---\n# Hello\n\nWelcome.\n")
+	return dir
+}
+
+func zipNames(t *testing.T, path string) map[string]bool {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	return names
+}
+
+func TestPack_ExcludesPersonalData(t *testing.T) {
+	src := newSourceSite(t)
+	out := filepath.Join(t.TempDir(), "starter.zip")
+
+	result, err := Pack(src, out, PackOptions{Name: "ember-kit", Pages: []string{"posts/20260101/hello.md"}})
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if result.Manifest.ActiveTheme != "ember" {
+		t.Errorf("active theme = %q, want ember", result.Manifest.ActiveTheme)
+	}
+	if result.Manifest.Settings["view_mode"] != "browser" || result.Manifest.Settings["setup_at"] != nil || result.Manifest.Settings["hooks"] != nil {
+		t.Errorf("settings = %v, want only view_mode", result.Manifest.Settings)
+	}
+
+	names := zipNames(t, out)
+	for _, want := range []string{ManifestName, "themes/ember/index.html", "themes/ember/snippets/header.html", "snippets/nav.md", "pages/hello.md"} {
+		if !names[want] {
+			t.Errorf("archive missing %s", want)
+		}
+	}
+	for name := range names {
+		if strings.Contains(name, "keys") || strings.Contains(name, ".env") || strings.Contains(name, ".well-known") || name == "snippets/about.md" {
+			t.Errorf("archive contains personal file %s", name)
+		}
+	}
+}
+
+func TestApply_InstallsStarter(t *testing.T) {
+	src := newSourceSite(t)
+	out := filepath.Join(t.TempDir(), "starter.zip")
+	if _, err := Pack(src, out, PackOptions{Pages: []string{"posts/20260101/hello.md"}}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	archive, err := Fetch(out)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	dst := t.TempDir()
+	writeFile(t, dst, "metadata/manifest.json", `{"version":"0.1.0","active_theme":"turbo"}`)
+	writeFile(t, dst, ".polis/webapp-config.json", `{"setup_at":"2026-05-01T00:00:00Z","view_mode":"list"}`)
+
+	result, err := Apply(dst, archive)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(result.Drafts) != 1 {
+		t.Fatalf("drafts = %v, want 1", result.Drafts)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".polis", "themes", "ember", "index.html")); err != nil {
+		t.Errorf("theme not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "snippets", "nav.md")); err != nil {
+		t.Errorf("snippet not installed: %v", err)
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(dst, "metadata", "manifest.json"))
+	if !strings.Contains(string(manifest), `"active_theme": "ember"`) {
+		t.Errorf("manifest = %s, want active_theme ember", manifest)
+	}
+
+	var cfg map[string]interface{}
+	data, _ := os.ReadFile(filepath.Join(dst, ".polis", "webapp-config.json"))
+	json.Unmarshal(data, &cfg)
+	if cfg["view_mode"] != "browser" || cfg["setup_at"] != "2026-05-01T00:00:00Z" {
+		t.Errorf("webapp config = %v", cfg)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dst, ".polis", "posts", "drafts"))
+	if len(entries) != 1 {
+		t.Fatalf("drafts dir has %d entries, want 1", len(entries))
+	}
+	draft, _ := os.ReadFile(filepath.Join(dst, ".polis", "posts", "drafts", entries[0].Name()))
+	if strings.Contains(string(draft), "signature") || !strings.HasPrefix(string(draft), "# Hello") {
+		t.Errorf("draft = %q, want body without frontmatter", draft)
+	}
+}
+
+func TestApply_RejectsUnsafeArchives(t *testing.T) {
+	build := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, _ := zw.Create(name)
+			w.Write([]byte(content))
+		}
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	dst := t.TempDir()
+	if _, err := Apply(dst, build(map[string]string{"snippets/nav.md": "x"})); err == nil {
+		t.Error("expected error for archive without manifest")
+	}
+	if _, err := Apply(dst, build(map[string]string{ManifestName: `{"name":"x"}`, "../evil.md": "x"})); err == nil {
+		t.Error("expected error for path traversal")
+	}
+
+	result, err := Apply(dst, build(map[string]string{
+		ManifestName:             `{"name":"x"}`,
+		".polis/keys/id_ed25519": "attacker key",
+		".well-known/polis":      "{}",
+		"snippets/footer.md":     "footer",
+	}))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(result.FilesCreated) != 1 || result.FilesCreated[0] != "snippets/footer.md" {
+		t.Errorf("files created = %v, want only snippets/footer.md", result.FilesCreated)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".polis", "keys", "id_ed25519")); !os.IsNotExist(err) {
+		t.Error("starter must not write keys")
+	}
+}
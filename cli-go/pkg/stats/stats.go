@@ -0,0 +1,317 @@
+// Package stats computes site statistics: posting cadence, comments
+// received and given per month, the domains this site interacts with most,
+// and how long comments wait for a blessing.
+//
+// Like the graph package, everything comes from local state (the public
+// index, stream state, and comment files). Results are cached in
+// metadata/stats.json.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// Filename is the stats cache file in the metadata directory.
+const Filename = "stats.json"
+
+// maxTopDomains is the number of domains listed in TopDomains.
+const maxTopDomains = 10
+
+// MonthCount is a count for one calendar month ("2006-01").
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+// DomainCount counts the comments exchanged with one domain: received are
+// their comments on our posts, given are ours on theirs.
+type DomainCount struct {
+	Domain   string `json:"domain"`
+	Received int    `json:"received"`
+	Given    int    `json:"given"`
+	Total    int    `json:"total"`
+}
+
+// Stats is a snapshot of site statistics. Monthly series run from the
+// first month with activity to the last, including empty months.
+type Stats struct {
+	GeneratedAt string `json:"generated_at"`
+
+	Posts               int          `json:"posts"`
+	PostsPerMonth       []MonthCount `json:"posts_per_month"`
+	AvgDaysBetweenPosts float64      `json:"avg_days_between_posts"`
+
+	CommentsReceived         int          `json:"comments_received"`
+	CommentsReceivedPerMonth []MonthCount `json:"comments_received_per_month"`
+	CommentsGiven            int          `json:"comments_given"`
+	CommentsGivenPerMonth    []MonthCount `json:"comments_given_per_month"`
+
+	TopDomains []DomainCount `json:"top_domains"`
+
+	// AvgTimeToBlessingHours averages the time between a blessing request
+	// and its grant, over the BlessingsTimed grants whose request time is
+	// known.
+	AvgTimeToBlessingHours float64 `json:"avg_time_to_blessing_hours"`
+	BlessingsTimed         int     `json:"blessings_timed"`
+}
+
+// MonthRow combines the monthly series for one month.
+type MonthRow struct {
+	Month    string `json:"month"`
+	Posts    int    `json:"posts"`
+	Received int    `json:"received"`
+	Given    int    `json:"given"`
+}
+
+// Months returns one row per month covered by any of the series, newest
+// first.
+func (s *Stats) Months() []MonthRow {
+	rows := make(map[string]*MonthRow)
+	row := func(month string) *MonthRow {
+		r, ok := rows[month]
+		if !ok {
+			r = &MonthRow{Month: month}
+			rows[month] = r
+		}
+		return r
+	}
+	for _, m := range s.PostsPerMonth {
+		row(m.Month).Posts = m.Count
+	}
+	for _, m := range s.CommentsReceivedPerMonth {
+		row(m.Month).Received = m.Count
+	}
+	for _, m := range s.CommentsGivenPerMonth {
+		row(m.Month).Given = m.Count
+	}
+	months := make([]MonthRow, 0, len(rows))
+	for _, r := range rows {
+		months = append(months, *r)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Month > months[j].Month })
+	return months
+}
+
+// Compute gathers statistics for the site in dataDir. self is the site's
+// domain; comments on our own posts don't count towards TopDomains.
+func Compute(dataDir, discoveryDomain, self string) *Stats {
+	self = strings.ToLower(self)
+	s := &Stats{GeneratedAt: time.Now().UTC().Format(time.RFC3339)}
+	domains := make(map[string]*DomainCount)
+	domain := func(d string) *DomainCount {
+		d = strings.ToLower(d)
+		dc, ok := domains[d]
+		if !ok {
+			dc = &DomainCount{Domain: d}
+			domains[d] = dc
+		}
+		return dc
+	}
+
+	// Posting cadence
+	posts, _ := metadata.GetPostEntries(dataDir)
+	var published []time.Time
+	for _, p := range posts {
+		if t, ok := parseTime(p.Published); ok {
+			published = append(published, t)
+		}
+	}
+	s.Posts = len(posts)
+	s.PostsPerMonth = perMonth(published)
+	if len(published) > 1 {
+		sort.Slice(published, func(i, j int) bool { return published[i].Before(published[j]) })
+		span := published[len(published)-1].Sub(published[0])
+		s.AvgDaysBetweenPosts = round1(span.Hours() / 24 / float64(len(published)-1))
+	}
+
+	// Comments received: stream blessing state, plus the blessed index for
+	// grants that predate it
+	var received []time.Time
+	var waits []time.Duration
+	seen := make(map[string]bool)
+	var blessings stream.BlessingState
+	_ = stream.NewStore(dataDir, discoveryDomain).LoadState("polis.blessing", &blessings)
+	for _, entry := range blessings.Blessings {
+		if entry.Status == "retracted" || seen[entry.SourceURL] {
+			continue
+		}
+		seen[entry.SourceURL] = true
+		s.CommentsReceived++
+		if d := discovery.ExtractDomainFromURL(entry.SourceURL); d != "" && d != self {
+			domain(d).Received++
+		}
+		requested, hasRequest := parseTime(entry.RequestedAt)
+		updated, _ := parseTime(entry.UpdatedAt)
+		if hasRequest {
+			received = append(received, requested)
+		} else if !updated.IsZero() {
+			received = append(received, updated)
+		}
+		if entry.Status == "granted" && hasRequest && !updated.Before(requested) {
+			waits = append(waits, updated.Sub(requested))
+		}
+	}
+	if bc, err := metadata.LoadBlessedComments(dataDir); err == nil {
+		for _, pc := range bc.Comments {
+			for _, c := range pc.Blessed {
+				if seen[c.URL] {
+					continue
+				}
+				seen[c.URL] = true
+				s.CommentsReceived++
+				if d := discovery.ExtractDomainFromURL(c.URL); d != "" && d != self {
+					domain(d).Received++
+				}
+				if t, ok := parseTime(c.BlessedAt); ok {
+					received = append(received, t)
+				}
+			}
+		}
+	}
+	s.CommentsReceivedPerMonth = perMonth(received)
+	if len(waits) > 0 {
+		var total time.Duration
+		for _, w := range waits {
+			total += w
+		}
+		s.AvgTimeToBlessingHours = round1(total.Hours() / float64(len(waits)))
+		s.BlessingsTimed = len(waits)
+	}
+
+	// Comments given, in any state
+	var given []time.Time
+	for _, status := range []string{comment.StatusPending, comment.StatusBlessed, comment.StatusDenied} {
+		metas, err := comment.ListComments(dataDir, status)
+		if err != nil {
+			continue
+		}
+		for _, m := range metas {
+			s.CommentsGiven++
+			if d := discovery.ExtractDomainFromURL(m.InReplyTo); d != "" && d != self {
+				domain(d).Given++
+			}
+			if t, ok := parseTime(m.Timestamp); ok {
+				given = append(given, t)
+			}
+		}
+	}
+	s.CommentsGivenPerMonth = perMonth(given)
+
+	s.TopDomains = make([]DomainCount, 0, len(domains))
+	for _, dc := range domains {
+		dc.Total = dc.Received + dc.Given
+		s.TopDomains = append(s.TopDomains, *dc)
+	}
+	sort.Slice(s.TopDomains, func(i, j int) bool {
+		if s.TopDomains[i].Total != s.TopDomains[j].Total {
+			return s.TopDomains[i].Total > s.TopDomains[j].Total
+		}
+		return s.TopDomains[i].Domain < s.TopDomains[j].Domain
+	})
+	if len(s.TopDomains) > maxTopDomains {
+		s.TopDomains = s.TopDomains[:maxTopDomains]
+	}
+	return s
+}
+
+// Path returns the path of the stats cache file.
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, "metadata", Filename)
+}
+
+// Load reads the cached stats.
+func Load(dataDir string) (*Stats, error) {
+	data, err := os.ReadFile(Path(dataDir))
+	if err != nil {
+		return nil, err
+	}
+	var s Stats
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+	return &s, nil
+}
+
+// Save writes s to the stats cache.
+func Save(dataDir string, s *Stats) error {
+	if err := os.MkdirAll(filepath.Dir(Path(dataDir)), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	if err := fsutil.WriteFile(Path(dataDir), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", Filename, err)
+	}
+	return nil
+}
+
+// Cached returns the cached stats if they are younger than maxAge, and
+// otherwise computes and caches fresh ones.
+func Cached(dataDir, discoveryDomain, self string, maxAge time.Duration) (*Stats, error) {
+	if s, err := Load(dataDir); err == nil {
+		if t, ok := parseTime(s.GeneratedAt); ok && time.Since(t) < maxAge {
+			return s, nil
+		}
+	}
+	s := Compute(dataDir, discoveryDomain, self)
+	if err := Save(dataDir, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// perMonth buckets times by month, filling in empty months between the
+// first and the last.
+func perMonth(times []time.Time) []MonthCount {
+	counts := make(map[string]int)
+	var first, last time.Time
+	for _, t := range times {
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		counts[t.Format("2006-01")]++
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	months := []MonthCount{}
+	if first.IsZero() {
+		return months
+	}
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		key := m.Format("2006-01")
+		months = append(months, MonthCount{Month: key, Count: counts[key]})
+	}
+	return months
+}
+
+// parseTime parses an RFC 3339 timestamp or a bare date.
+func parseTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), true
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+func TestCompute(t *testing.T) {
+	dir := t.TempDir()
+	metadata.AppendPostToIndex(dir, "posts/20260105/a.md", "A", "2026-01-05T10:00:00Z", "sha256:a")
+	metadata.AppendPostToIndex(dir, "posts/20260301/b.md", "B", "2026-03-01T10:00:00Z", "sha256:b")
+	metadata.AppendPostToIndex(dir, "posts/20260309/c.md", "C", "2026-03-09T10:00:00Z", "sha256:c")
+
+	stream.NewStore(dir, "ds.example.com").SaveState("polis.blessing", stream.BlessingState{Blessings: []stream.BlessingEntry{
+		{SourceURL: "https://bob.example.com/comments/20260301/a.md", Status: "granted", RequestedAt: "2026-03-01T10:00:00Z", UpdatedAt: "2026-03-01T16:00:00Z"},
+		{SourceURL: "https://bob.example.com/comments/20260302/b.md", Status: "granted", RequestedAt: "2026-03-02T10:00:00Z", UpdatedAt: "2026-03-03T04:00:00Z"},
+		{SourceURL: "https://carol.example.com/comments/20260302/c.md", Status: "denied", UpdatedAt: "2026-01-02T10:00:00Z"},
+		{SourceURL: "https://dave.example.com/comments/20260302/d.md", Status: "retracted", UpdatedAt: "2026-03-02T10:00:00Z"},
+	}})
+
+	pending := filepath.Join(dir, ".polis", "comments", "pending")
+	os.MkdirAll(pending, 0755)
+	os.WriteFile(filepath.Join(pending, "x.md"), []byte("---\npublished: 2026-02-10T10:00:00Z\nin_reply_to: https://carol.example.com/posts/x.md\n---\n\nHi\n"), 0644)
+
+	s := Compute(dir, "ds.example.com", "me.example.com")
+
+	if s.Posts != 3 {
+		t.Errorf("Posts = %d, want 3", s.Posts)
+	}
+	wantPosts := []MonthCount{{"2026-01", 1}, {"2026-02", 0}, {"2026-03", 2}}
+	if len(s.PostsPerMonth) != len(wantPosts) {
+		t.Fatalf("PostsPerMonth = %v, want %v", s.PostsPerMonth, wantPosts)
+	}
+	for i, m := range wantPosts {
+		if s.PostsPerMonth[i] != m {
+			t.Errorf("PostsPerMonth[%d] = %v, want %v", i, s.PostsPerMonth[i], m)
+		}
+	}
+	if s.AvgDaysBetweenPosts != 31.5 {
+		t.Errorf("AvgDaysBetweenPosts = %v, want 31.5", s.AvgDaysBetweenPosts)
+	}
+
+	if s.CommentsReceived != 3 || len(s.CommentsReceivedPerMonth) != 3 {
+		t.Errorf("received = %d over %v, want 3 over Jan-Mar", s.CommentsReceived, s.CommentsReceivedPerMonth)
+	}
+	if s.CommentsGiven != 1 || len(s.CommentsGivenPerMonth) != 1 || s.CommentsGivenPerMonth[0].Month != "2026-02" {
+		t.Errorf("given = %d over %v, want 1 in 2026-02", s.CommentsGiven, s.CommentsGivenPerMonth)
+	}
+
+	if s.BlessingsTimed != 2 || s.AvgTimeToBlessingHours != 12 {
+		t.Errorf("time to blessing = %vh over %d, want 12h over 2", s.AvgTimeToBlessingHours, s.BlessingsTimed)
+	}
+
+	want := []DomainCount{
+		{Domain: "bob.example.com", Received: 2, Total: 2},
+		{Domain: "carol.example.com", Received: 1, Given: 1, Total: 2},
+	}
+	if len(s.TopDomains) != len(want) {
+		t.Fatalf("TopDomains = %v, want %v", s.TopDomains, want)
+	}
+	for i, dc := range want {
+		if s.TopDomains[i] != dc {
+			t.Errorf("TopDomains[%d] = %+v, want %+v", i, s.TopDomains[i], dc)
+		}
+	}
+}
+
+func TestCached(t *testing.T) {
+	dir := t.TempDir()
+	Save(dir, &Stats{GeneratedAt: time.Now().UTC().Format(time.RFC3339), Posts: 7})
+
+	s, err := Cached(dir, "ds.example.com", "me.example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("Cached: %v", err)
+	}
+	if s.Posts != 7 {
+		t.Errorf("fresh cache not used: Posts = %d", s.Posts)
+	}
+
+	s, err = Cached(dir, "ds.example.com", "me.example.com", 0)
+	if err != nil {
+		t.Fatalf("Cached: %v", err)
+	}
+	if s.Posts != 0 {
+		t.Errorf("stale cache used: Posts = %d", s.Posts)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
+)
+
+// dirFS is a site directory on disk.
+type dirFS struct {
+	root string
+}
+
+// Dir returns the storage of the directory root on disk. Writes go through
+// fsutil.WriteFile and locks through lockfile, so they interoperate with
+// other processes working on the same directory.
+func Dir(root string) FS {
+	return dirFS{root: root}
+}
+
+// path returns the OS path of name.
+func (d dirFS) path(op, name string) (string, error) {
+	clean, err := checkName(op, name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d.root, filepath.FromSlash(clean)), nil
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	p, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	p, err := d.path("read", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := d.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(p)
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := d.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(p)
+}
+
+func (d dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(p, data, perm)
+}
+
+func (d dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := d.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, perm)
+}
+
+func (d dirFS) Remove(name string) error {
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+func (d dirFS) Rename(oldname, newname string) error {
+	from, err := d.path("rename", oldname)
+	if err != nil {
+		return err
+	}
+	to, err := d.path("rename", newname)
+	if err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+func (d dirFS) Lock(name string) (func(), error) {
+	p, err := d.path("lock", name)
+	if err != nil {
+		return nil, err
+	}
+	l, err := lockfile.Acquire(p)
+	if err != nil {
+		return nil, err
+	}
+	return func() { l.Release() }, nil
+}
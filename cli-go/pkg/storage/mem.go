@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is storage held in memory, for tests that would otherwise need a
+// temporary directory. The zero value is not usable; call NewMem.
+type MemFS struct {
+	mu      sync.RWMutex
+	entries map[string]*memEntry // By name; "." is the root
+	locks   map[string]*sync.Mutex
+}
+
+type memEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMem returns an empty in-memory file system.
+func NewMem() *MemFS {
+	return &MemFS{
+		entries: map[string]*memEntry{".": {mode: fs.ModeDir | 0755, modTime: time.Now()}},
+		locks:   map[string]*sync.Mutex{},
+	}
+}
+
+func notExist(op, name string) error {
+	return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// lookup returns the entry for a checked name. Callers hold m.mu.
+func (m *MemFS) lookup(op, name string) (string, *memEntry, error) {
+	clean, err := checkName(op, name)
+	if err != nil {
+		return "", nil, err
+	}
+	e, ok := m.entries[clean]
+	if !ok {
+		return clean, nil, notExist(op, name)
+	}
+	return clean, e, nil
+}
+
+// children returns the names directly inside dir, sorted. Callers hold m.mu.
+func (m *MemFS) children(dir string) []string {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	var names []string
+	for name := range m.entries {
+		if name == "." || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if rest := name[len(prefix):]; !strings.Contains(rest, "/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	clean, e, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info := memInfo{name: path.Base(clean), entry: *e}
+	if !e.mode.IsDir() {
+		return &memFile{info: info, Reader: bytes.NewReader(e.data)}, nil
+	}
+	var entries []fs.DirEntry
+	for _, child := range m.children(clean) {
+		entries = append(entries, memInfo{name: path.Base(child), entry: *m.entries[child]})
+	}
+	return &memDir{info: info, entries: entries}, nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, e, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return bytes.Clone(e.data), nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := f.(*memDir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return d.entries, nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	clean, e, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{name: path.Base(clean), entry: *e}, nil
+}
+
+// parentIsDir reports whether name's parent is a directory. Callers hold m.mu.
+func (m *MemFS) parentIsDir(name string) bool {
+	e, ok := m.entries[path.Dir(name)]
+	return ok && e.mode.IsDir()
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clean, err := checkName("write", name)
+	if err != nil {
+		return err
+	}
+	if err := m.mkdirAll(path.Dir(clean), 0755); err != nil {
+		return err
+	}
+	if e, ok := m.entries[clean]; ok && e.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errors.New("is a directory")}
+	}
+	m.entries[clean] = &memEntry{data: bytes.Clone(data), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clean, err := checkName("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return m.mkdirAll(clean, perm)
+}
+
+// mkdirAll creates a checked directory name. Callers hold m.mu.
+func (m *MemFS) mkdirAll(clean string, perm fs.FileMode) error {
+	var missing []string
+	for dir := clean; dir != "."; dir = path.Dir(dir) {
+		if e, ok := m.entries[dir]; ok {
+			if !e.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: errors.New("not a directory")}
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		m.entries[dir] = &memEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clean, e, err := m.lookup("remove", name)
+	if err != nil {
+		return err
+	}
+	if clean == "." || (e.mode.IsDir() && len(m.children(clean)) > 0) {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	delete(m.entries, clean)
+	return nil
+}
+
+func (m *MemFS) Rename(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	from, e, err := m.lookup("rename", oldname)
+	if err != nil {
+		return err
+	}
+	to, err := checkName("rename", newname)
+	if err != nil {
+		return err
+	}
+	if from == "." || !m.parentIsDir(to) || strings.HasPrefix(to, from+"/") {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrInvalid}
+	}
+	if existing, ok := m.entries[to]; ok && existing.mode.IsDir() && to != from {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+	}
+	delete(m.entries, from)
+	m.entries[to] = e
+	if e.mode.IsDir() {
+		for name, child := range m.entries {
+			if strings.HasPrefix(name, from+"/") {
+				delete(m.entries, name)
+				m.entries[to+name[len(from):]] = child
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Lock(name string) (func(), error) {
+	clean, err := checkName("lock", name)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	l, ok := m.locks[clean]
+	if !ok {
+		l = &sync.Mutex{}
+		m.locks[clean] = l
+	}
+	m.mu.Unlock()
+	l.Lock()
+	return l.Unlock, nil
+}
+
+// memInfo describes an entry; it is both its fs.FileInfo and fs.DirEntry.
+type memInfo struct {
+	name  string
+	entry memEntry
+}
+
+func (i memInfo) Name() string               { return i.name }
+func (i memInfo) Size() int64                { return int64(len(i.entry.data)) }
+func (i memInfo) Mode() fs.FileMode          { return i.entry.mode }
+func (i memInfo) ModTime() time.Time         { return i.entry.modTime }
+func (i memInfo) IsDir() bool                { return i.entry.mode.IsDir() }
+func (i memInfo) Sys() any                   { return nil }
+func (i memInfo) Type() fs.FileMode          { return i.entry.mode.Type() }
+func (i memInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+type memFile struct {
+	info memInfo
+	*bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,151 @@
+// Package storage is the file system a site's data lives on. Packages that
+// read and write site files (metadata, publish, comment, feed, snippet) go
+// through For(dataDir) rather than the os package, so a site can be backed
+// by something other than a directory on disk: an in-memory file system in
+// tests today, and remote or object stores later.
+//
+// Names are slash-separated and relative to the site directory, as in
+// io/fs; Name converts an OS-style relative path. Errors for missing files
+// satisfy os.IsNotExist and errors.Is(err, fs.ErrNotExist) on every backend.
+package storage
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// FS is a writable file system rooted at a site directory.
+type FS interface {
+	fs.ReadFileFS
+	fs.ReadDirFS
+	fs.StatFS
+
+	// WriteFile replaces name with data, creating its directory if needed.
+	// On disk the write is atomic.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// MkdirAll creates a directory and any missing parents.
+	MkdirAll(name string, perm fs.FileMode) error
+	// Remove removes a file or an empty directory.
+	Remove(name string) error
+	// Rename moves a file or directory, replacing a file at newname.
+	Rename(oldname, newname string) error
+	// Lock serializes read-modify-write updates to name with other writers
+	// of the same storage, including other processes where the backend
+	// allows. Call the returned function to release it.
+	Lock(name string) (unlock func(), err error)
+}
+
+var (
+	mountsMu sync.RWMutex
+	mounts   = map[string]FS{}
+)
+
+// For returns the storage of the site directory dir: the file system
+// mounted there with Mount, else the directory on disk.
+func For(dir string) FS {
+	key := filepath.Clean(dir)
+	mountsMu.RLock()
+	fsys, ok := mounts[key]
+	mountsMu.RUnlock()
+	if ok {
+		return fsys
+	}
+	return Dir(dir)
+}
+
+// Mount makes For(dir) return fsys until the returned function is called.
+// dir needn't exist on disk.
+func Mount(dir string, fsys FS) (unmount func()) {
+	key := filepath.Clean(dir)
+	mountsMu.Lock()
+	mounts[key] = fsys
+	mountsMu.Unlock()
+	return func() {
+		mountsMu.Lock()
+		delete(mounts, key)
+		mountsMu.Unlock()
+	}
+}
+
+// Name converts a path relative to the site directory, in either separator
+// style, to the slash-separated name storage expects. As with
+// filepath.Join(dir, rel), a leading slash is ignored; ".." elements can't
+// climb above the site directory.
+func Name(rel string) string {
+	name := path.Clean("/" + filepath.ToSlash(rel))
+	if name == "/" {
+		return "."
+	}
+	return name[1:]
+}
+
+// Join joins path elements into a storage name.
+func Join(elem ...string) string {
+	return Name(path.Join(elem...))
+}
+
+// checkName rejects names that aren't valid io/fs names, which includes
+// any that would leave the site directory.
+func checkName(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
+
+// ReadJSONLines reads a JSON-lines file as fsutil.ReadJSONLines does.
+func ReadJSONLines(fsys FS, name string) ([][]byte, error) {
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return fsutil.SplitJSONLines(data, name), nil
+}
+
+// AppendJSONLines appends one record per line to name, creating it and its
+// directory if needed. On disk this is a true append; elsewhere the file is
+// rewritten.
+func AppendJSONLines(fsys FS, name string, lines [][]byte, perm fs.FileMode) error {
+	if d, ok := fsys.(dirFS); ok {
+		full, err := d.path("append", name)
+		if err != nil {
+			return err
+		}
+		return fsutil.AppendJSONLines(full, lines, perm)
+	}
+	data, err := fsys.ReadFile(name)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return fsys.WriteFile(name, buf.Bytes(), perm)
+}
+
+// With runs fn while holding the lock for name.
+func With(fsys FS, name string, fn func() error) error {
+	unlock, err := fsys.Lock(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// Exists reports whether name exists.
+func Exists(fsys FS, name string) bool {
+	_, err := fsys.Stat(name)
+	return err == nil
+}
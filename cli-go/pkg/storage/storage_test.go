@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// backends runs a test against the disk and memory backends.
+func backends(t *testing.T, test func(t *testing.T, fsys FS)) {
+	t.Run("dir", func(t *testing.T) { test(t, Dir(t.TempDir())) })
+	t.Run("mem", func(t *testing.T) { test(t, NewMem()) })
+}
+
+func TestFS(t *testing.T) {
+	backends(t, func(t *testing.T, fsys FS) {
+		if err := fsys.WriteFile("metadata/public.jsonl", []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.MkdirAll("posts/20260101", 0755); err != nil {
+			t.Fatal(err)
+		}
+		fsys.WriteFile("posts/20260101/a.md", []byte("a"), 0644)
+		if err := fstest.TestFS(fsys, "metadata/public.jsonl", "posts/20260101/a.md"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := fsys.Rename("posts/20260101/a.md", "posts/b.md"); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := fsys.ReadFile("posts/b.md"); err != nil || string(data) != "a" {
+			t.Errorf("after rename: %q, %v", data, err)
+		}
+		if err := fsys.Remove("posts"); err == nil {
+			t.Error("removed a non-empty directory")
+		}
+		if err := fsys.Remove("posts/b.md"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fsys.Stat("posts/b.md"); !os.IsNotExist(err) {
+			t.Errorf("stat after remove = %v", err)
+		}
+		if _, err := fsys.ReadFile("../outside"); err == nil {
+			t.Error("read outside the site")
+		}
+	})
+}
+
+func TestAppendJSONLinesAndLock(t *testing.T) {
+	backends(t, func(t *testing.T, fsys FS) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				With(fsys, "state/feed.jsonl", func() error {
+					return AppendJSONLines(fsys, "state/feed.jsonl", [][]byte{[]byte(`{"n":1}`)}, 0644)
+				})
+			}()
+		}
+		wg.Wait()
+		lines, err := ReadJSONLines(fsys, "state/feed.jsonl")
+		if err != nil || len(lines) != 10 {
+			t.Errorf("lines = %d, %v; want 10", len(lines), err)
+		}
+	})
+}
+
+func TestMount(t *testing.T) {
+	mem := NewMem()
+	unmount := Mount("/sites/test", mem)
+	if For("/sites/test/") != FS(mem) {
+		t.Error("For did not return the mounted storage")
+	}
+	unmount()
+	if _, ok := For("/sites/test").(*MemFS); ok {
+		t.Error("storage still mounted after unmount")
+	}
+}
+
+func TestName(t *testing.T) {
+	for rel, want := range map[string]string{
+		"":                         ".",
+		"./metadata/public.jsonl":  "metadata/public.jsonl",
+		"/posts/20260101/a.md":     "posts/20260101/a.md",
+		"posts//20260101/../b.md":  "posts/b.md",
+		"../../etc/passwd":         "etc/passwd",
+		filepath.Join("a", "b.md"): "a/b.md",
+	} {
+		if got := Name(rel); got != want {
+			t.Errorf("Name(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
@@ -18,9 +18,12 @@ type BlessingHandler struct {
 type BlessingEntry struct {
 	SourceURL string `json:"source_url"`
 	TargetURL string `json:"target_url"`
-	Status    string `json:"status"` // "granted" or "denied"
+	Status    string `json:"status"` // "pending", "granted", "denied", or "retracted"
 	Actor     string `json:"actor"`
 	UpdatedAt string `json:"updated_at"`
+	// RequestedAt is when the blessing was requested (unset when the
+	// request predates the stream).
+	RequestedAt string `json:"requested_at,omitempty"`
 }
 
 // BlessingState is the materialized state for the blessing projection.
@@ -33,7 +36,7 @@ type BlessingState struct {
 func (h *BlessingHandler) TypePrefix() string { return "polis.blessing" }
 
 func (h *BlessingHandler) EventTypes() []string {
-	return []string{"polis.blessing.requested", "polis.blessing.granted", "polis.blessing.denied"}
+	return []string{"polis.blessing.requested", "polis.blessing.granted", "polis.blessing.denied", "polis.comment.retracted"}
 }
 
 func (h *BlessingHandler) NewState() interface{} {
@@ -70,15 +73,18 @@ func (h *BlessingHandler) Process(events []discovery.StreamEvent, state interfac
 
 		switch evt.Type {
 		case "polis.blessing.requested":
-			if _, exists := blessingMap[sourceURL]; !exists {
-				entry := &BlessingEntry{
-					SourceURL: sourceURL,
-					TargetURL: targetURL,
-					Status:    "pending",
-					Actor:     evt.Actor,
-					UpdatedAt: evt.Timestamp,
+			entry, exists := blessingMap[sourceURL]
+			if !exists {
+				blessingMap[sourceURL] = &BlessingEntry{
+					SourceURL:   sourceURL,
+					TargetURL:   targetURL,
+					Status:      "pending",
+					Actor:       evt.Actor,
+					UpdatedAt:   evt.Timestamp,
+					RequestedAt: evt.Timestamp,
 				}
-				blessingMap[sourceURL] = entry
+			} else if entry.RequestedAt == "" {
+				entry.RequestedAt = evt.Timestamp
 			}
 
 		case "polis.blessing.granted":
@@ -95,6 +101,16 @@ func (h *BlessingHandler) Process(events []discovery.StreamEvent, state interfac
 				}
 			}
 
+		case "polis.comment.retracted":
+			// Only the comment's author can retract it
+			if discovery.ExtractDomainFromURL(sourceURL) != evt.Actor {
+				continue
+			}
+			if entry, exists := blessingMap[sourceURL]; exists {
+				entry.Status = "retracted"
+				entry.UpdatedAt = evt.Timestamp
+			}
+
 		case "polis.blessing.denied":
 			if entry, exists := blessingMap[sourceURL]; exists {
 				entry.Status = "denied"
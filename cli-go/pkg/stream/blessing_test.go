@@ -11,14 +11,15 @@ func TestBlessingHandler_EventTypes(t *testing.T) {
 	h := &BlessingHandler{MyDomain: "bob.com"}
 	types := h.EventTypes()
 
-	if len(types) != 3 {
-		t.Fatalf("EventTypes() len = %d, want 3", len(types))
+	if len(types) != 4 {
+		t.Fatalf("EventTypes() len = %d, want 4", len(types))
 	}
 
 	expected := map[string]bool{
 		"polis.blessing.requested": true,
 		"polis.blessing.granted":   true,
 		"polis.blessing.denied":    true,
+		"polis.comment.retracted":  true,
 	}
 	for _, typ := range types {
 		if !expected[typ] {
@@ -164,6 +165,40 @@ func TestBlessingHandler_ProcessDenied(t *testing.T) {
 	}
 }
 
+func TestBlessingHandler_ProcessRetracted(t *testing.T) {
+	h := &BlessingHandler{MyDomain: "bob.com"}
+	payload := map[string]interface{}{
+		"source_url":    "https://alice.com/comments/1.md",
+		"target_url":    "https://bob.com/posts/1.md",
+		"target_domain": "bob.com",
+	}
+	events := []discovery.StreamEvent{
+		{ID: json.Number("1"), Type: "polis.blessing.granted", Actor: "bob.com", Payload: payload, Timestamp: "2026-02-10T10:00:00Z"},
+		// Only the comment's author may retract it
+		{ID: json.Number("2"), Type: "polis.comment.retracted", Actor: "mallory.com", Payload: payload, Timestamp: "2026-02-10T11:00:00Z"},
+	}
+
+	result, err := h.Process(events, h.NewState())
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if bs := result.(*BlessingState); bs.Blessings[0].Status != "granted" {
+		t.Fatalf("retraction by another actor applied: %+v", bs.Blessings[0])
+	}
+
+	events = []discovery.StreamEvent{
+		{ID: json.Number("3"), Type: "polis.comment.retracted", Actor: "alice.com", Payload: payload, Timestamp: "2026-02-10T12:00:00Z"},
+	}
+	result, err = h.Process(events, result)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	bs := result.(*BlessingState)
+	if bs.Blessings[0].Status != "retracted" || bs.Granted != 0 {
+		t.Errorf("expected retracted with no grants, got %+v", bs)
+	}
+}
+
 func TestBlessingHandler_IgnoresOtherDomains(t *testing.T) {
 	h := &BlessingHandler{MyDomain: "bob.com"}
 	state := h.NewState()
@@ -253,4 +288,7 @@ func TestBlessingHandler_FullLifecycle(t *testing.T) {
 	if bs2.Granted != 1 {
 		t.Errorf("after grant: granted = %d, want 1", bs2.Granted)
 	}
+	if bs2.Blessings[0].RequestedAt != "2026-02-10T10:00:00Z" {
+		t.Errorf("after grant: requested_at = %q, want the request time", bs2.Blessings[0].RequestedAt)
+	}
 }
@@ -18,6 +18,9 @@ type FollowHandler struct {
 type FollowerState struct {
 	Followers []string `json:"followers"`
 	Count     int      `json:"count"`
+	// Since maps each follower to the timestamp of their follow event.
+	// Followers recorded before it existed have no entry.
+	Since map[string]string `json:"since,omitempty"`
 }
 
 func (h *FollowHandler) TypePrefix() string { return "polis.follow" }
@@ -41,6 +44,10 @@ func (h *FollowHandler) Process(events []discovery.StreamEvent, state interface{
 	for _, f := range fs.Followers {
 		followerSet[f] = true
 	}
+	since := make(map[string]string, len(fs.Since))
+	for f, ts := range fs.Since {
+		since[f] = ts
+	}
 
 	for _, evt := range events {
 		// Only process events targeted at our domain
@@ -51,9 +58,13 @@ func (h *FollowHandler) Process(events []discovery.StreamEvent, state interface{
 
 		switch evt.Type {
 		case "polis.follow.announced":
+			if !followerSet[evt.Actor] && evt.Timestamp != "" {
+				since[evt.Actor] = evt.Timestamp
+			}
 			followerSet[evt.Actor] = true
 		case "polis.follow.removed":
 			delete(followerSet, evt.Actor)
+			delete(since, evt.Actor)
 		}
 	}
 
@@ -63,8 +74,12 @@ func (h *FollowHandler) Process(events []discovery.StreamEvent, state interface{
 		followers = append(followers, f)
 	}
 
+	if len(since) == 0 {
+		since = nil
+	}
 	return &FollowerState{
 		Followers: followers,
 		Count:     len(followers),
+		Since:     since,
 	}, nil
 }
@@ -263,3 +263,25 @@ func TestFollowHandler_FullCycle(t *testing.T) {
 		t.Errorf("cursor = %q, want %q", cursor, "1")
 	}
 }
+
+func TestFollowHandler_Since(t *testing.T) {
+	h := &FollowHandler{MyDomain: "bob.com"}
+	follow := func(id, typ, ts string) discovery.StreamEvent {
+		return discovery.StreamEvent{ID: json.Number(id), Type: typ, Actor: "alice.com", Timestamp: ts,
+			Payload: map[string]interface{}{"target_domain": "bob.com"}}
+	}
+
+	result, _ := h.Process([]discovery.StreamEvent{
+		follow("1", "polis.follow.announced", "2026-03-01T00:00:00Z"),
+		follow("2", "polis.follow.announced", "2026-03-02T00:00:00Z"), // repeat keeps the first time
+	}, h.NewState())
+	fs := result.(*FollowerState)
+	if fs.Since["alice.com"] != "2026-03-01T00:00:00Z" {
+		t.Errorf("Since = %v, want first follow time", fs.Since)
+	}
+
+	result, _ = h.Process([]discovery.StreamEvent{follow("3", "polis.follow.removed", "2026-03-03T00:00:00Z")}, fs)
+	if fs := result.(*FollowerState); fs.Since != nil {
+		t.Errorf("Since = %v, want cleared on unfollow", fs.Since)
+	}
+}
@@ -3,6 +3,7 @@ package stream
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
@@ -18,11 +19,22 @@ type NotificationHandler struct {
 	Rules []notification.Rule
 	// MutedDomains is a set of domains to suppress notifications from.
 	MutedDomains map[string]bool
+	// MutedThreads is a set of post URLs whose comments don't notify (the
+	// feed's muted threads).
+	MutedThreads map[string]bool
+	// MutedKeywords are lowercase keywords; comments and posts whose title
+	// contains one don't notify.
+	MutedKeywords []string
 	// FollowedDomains is a set of domains we follow. Used by the unified sync
 	// loop for client-side "followed_author" filtering when events aren't
 	// pre-filtered by actor in the DS query. If nil, falls back to the legacy
 	// behavior of accepting any non-self actor.
 	FollowedDomains map[string]bool
+	// SiteTitles maps domains to known site titles. Follow notifications
+	// carry the follower's domain and, when known, site title in their
+	// payload ("domain", "site_title"); unknown titles are filled in
+	// lazily when notifications are listed.
+	SiteTitles map[string]string
 }
 
 // NotificationConfig is the user configuration stored in config/notifications.json.
@@ -100,8 +112,8 @@ func (h *NotificationHandler) Process(events []discovery.StreamEvent) []notifica
 			continue
 		}
 
-		// Skip muted domains
-		if h.MutedDomains[evt.Actor] {
+		// Skip muted domains, threads, and keywords
+		if h.MutedDomains[evt.Actor] || h.isMuted(evt) {
 			continue
 		}
 
@@ -131,6 +143,11 @@ func (h *NotificationHandler) Process(events []discovery.StreamEvent) []notifica
 			// Parse event ID
 			eventID, _ := strconv.Atoi(fmt.Sprintf("%v", evt.ID))
 
+			payload := evt.Payload
+			if strings.HasPrefix(evt.Type, "polis.follow.") {
+				payload = h.followerPayload(evt)
+			}
+
 			entries = append(entries, notification.StateEntry{
 				ID:        dedupeKey,
 				RuleID:    rule.ID,
@@ -138,7 +155,7 @@ func (h *NotificationHandler) Process(events []discovery.StreamEvent) []notifica
 				Icon:      rule.Template.Icon,
 				Message:   message,
 				Link:      link,
-				Payload:   evt.Payload,
+				Payload:   payload,
 				EventIDs:  []int{eventID},
 				CreatedAt: evt.Timestamp,
 			})
@@ -148,6 +165,51 @@ func (h *NotificationHandler) Process(events []discovery.StreamEvent) []notifica
 	return entries
 }
 
+// followerPayload copies a follow event's payload, adding the follower's
+// domain and known site title.
+func (h *NotificationHandler) followerPayload(evt discovery.StreamEvent) map[string]interface{} {
+	payload := make(map[string]interface{}, len(evt.Payload)+2)
+	for k, v := range evt.Payload {
+		payload[k] = v
+	}
+	payload["domain"] = evt.Actor
+	if title := h.SiteTitles[evt.Actor]; title != "" {
+		payload["site_title"] = title
+	}
+	return payload
+}
+
+// isMuted reports whether a post or comment event is suppressed by the muted
+// threads or keywords.
+func (h *NotificationHandler) isMuted(evt discovery.StreamEvent) bool {
+	if !strings.HasPrefix(evt.Type, "polis.comment.") && !strings.HasPrefix(evt.Type, "polis.post.") {
+		return false
+	}
+	if strings.HasPrefix(evt.Type, "polis.comment.") {
+		for _, key := range []string{"in_reply_to", "root_post", "target_url"} {
+			if u, _ := evt.Payload[key].(string); u != "" && h.MutedThreads[u] {
+				return true
+			}
+		}
+	}
+	if len(h.MutedKeywords) == 0 {
+		return false
+	}
+	title, _ := evt.Payload["title"].(string)
+	if title == "" {
+		if md, ok := evt.Payload["metadata"].(map[string]interface{}); ok {
+			title, _ = md["title"].(string)
+		}
+	}
+	title = strings.ToLower(title)
+	for _, kw := range h.MutedKeywords {
+		if title != "" && strings.Contains(title, kw) {
+			return true
+		}
+	}
+	return false
+}
+
 // matchesFilter checks if an event matches a rule's filter.
 func (h *NotificationHandler) matchesFilter(rule notification.Rule, evt discovery.StreamEvent) bool {
 	switch rule.Filter.Relevance {
@@ -159,6 +221,10 @@ func (h *NotificationHandler) matchesFilter(rule notification.Rule, evt discover
 		sourceDomain, _ := evt.Payload["source_domain"].(string)
 		return sourceDomain == h.MyDomain
 
+	case "mentioned":
+		mentioned, _ := evt.Payload["mentioned_domain"].(string)
+		return mentioned == h.MyDomain
+
 	case "followed_author":
 		// In unified sync mode, FollowedDomains is populated for client-side
 		// filtering. In legacy mode (nil), the caller pre-filtered via the
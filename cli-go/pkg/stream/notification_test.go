@@ -216,6 +216,38 @@ func TestNotificationHandler_SkipMutedDomains(t *testing.T) {
 	}
 }
 
+func TestNotificationHandler_SkipMutedThreadsAndKeywords(t *testing.T) {
+	h := &NotificationHandler{
+		MyDomain:      "bob.com",
+		Rules:         notification.DefaultRules(),
+		MutedThreads:  map[string]bool{"https://bob.com/posts/busy.md": true},
+		MutedKeywords: []string{"giveaway"},
+	}
+
+	comment := func(id, inReplyTo, title string) discovery.StreamEvent {
+		return discovery.StreamEvent{
+			ID:    json.Number(id),
+			Type:  "polis.comment.published",
+			Actor: "alice.com",
+			Payload: map[string]interface{}{
+				"source_url":    "https://alice.com/comments/" + id + ".md",
+				"in_reply_to":   inReplyTo,
+				"target_domain": "bob.com",
+				"title":         title,
+			},
+			Timestamp: "2026-02-10T10:00:00Z",
+		}
+	}
+	entries := h.Process([]discovery.StreamEvent{
+		comment("1", "https://bob.com/posts/busy.md", "Re: busy"),
+		comment("2", "https://bob.com/posts/quiet.md", "Big GIVEAWAY"),
+		comment("3", "https://bob.com/posts/quiet.md", "Re: quiet"),
+	})
+	if len(entries) != 1 || entries[0].EventIDs[0] != 3 {
+		t.Errorf("expected only the unmuted comment to notify, got %+v", entries)
+	}
+}
+
 func TestNotificationHandler_IgnoresOtherDomains(t *testing.T) {
 	h := &NotificationHandler{
 		MyDomain: "bob.com",
@@ -304,10 +336,10 @@ func TestNotificationHandler_EnabledEventTypes(t *testing.T) {
 	}
 
 	types := h.EnabledEventTypes()
-	// Default: 7 enabled rules covering 7 unique event types
+	// Default: 8 enabled rules covering 8 unique event types
 	// (updated-comment and updated-post are disabled)
-	if len(types) != 7 {
-		t.Errorf("EnabledEventTypes() len = %d, want 7", len(types))
+	if len(types) != 8 {
+		t.Errorf("EnabledEventTypes() len = %d, want 8", len(types))
 	}
 }
 
@@ -328,6 +360,9 @@ func TestNotificationHandler_RulesByRelevance(t *testing.T) {
 	if len(groups["followed_author"]) != 1 {
 		t.Errorf("followed_author rules = %d, want 1", len(groups["followed_author"]))
 	}
+	if len(groups["mentioned"]) != 1 {
+		t.Errorf("mentioned rules = %d, want 1", len(groups["mentioned"]))
+	}
 }
 
 func TestNotificationHandler_FollowedDomains_FiltersUnfollowed(t *testing.T) {
@@ -510,3 +545,28 @@ func TestNotificationHandler_AllDefaultRulesHaveLinks(t *testing.T) {
 		}
 	}
 }
+
+func TestNotificationHandler_NewFollowerPayload(t *testing.T) {
+	h := &NotificationHandler{
+		MyDomain:   "bob.com",
+		Rules:      notification.DefaultRules(),
+		SiteTitles: map[string]string{"alice.com": "Alice Writes"},
+	}
+	payload := map[string]interface{}{"target_domain": "bob.com"}
+	entries := h.Process([]discovery.StreamEvent{
+		{ID: json.Number("1"), Type: "polis.follow.announced", Actor: "alice.com", Payload: payload},
+		{ID: json.Number("2"), Type: "polis.follow.announced", Actor: "carol.com", Payload: payload},
+	})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(entries))
+	}
+	if entries[0].Payload["domain"] != "alice.com" || entries[0].Payload["site_title"] != "Alice Writes" {
+		t.Errorf("alice payload = %v", entries[0].Payload)
+	}
+	if _, ok := entries[1].Payload["site_title"]; ok || entries[1].Payload["domain"] != "carol.com" {
+		t.Errorf("carol payload = %v, want domain without a title", entries[1].Payload)
+	}
+	if len(payload) != 1 {
+		t.Errorf("event payload was modified: %v", payload)
+	}
+}
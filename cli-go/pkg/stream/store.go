@@ -13,6 +13,9 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
 )
 
 // Store manages per-projection cursors, state, and config on disk.
@@ -73,17 +76,20 @@ func (s *Store) GetCursor(projection string) (string, error) {
 	return entry.Position, nil
 }
 
-// SetCursor stores the cursor position for a projection.
+// SetCursor stores the cursor position for a projection. cursors.json is
+// shared by every projection, so the update holds its lock.
 func (s *Store) SetCursor(projection string, cursor string) error {
-	cf, _ := s.loadCursors()
-	if cf == nil {
-		cf = &CursorsFile{Cursors: make(map[string]CursorEntry)}
-	}
-	cf.Cursors[projection] = CursorEntry{
-		Position:    cursor,
-		LastUpdated: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
-	}
-	return s.saveCursors(cf)
+	return lockfile.With(filepath.Join(s.stateDir, "cursors.json"), func() error {
+		cf, _ := s.loadCursors()
+		if cf == nil {
+			cf = &CursorsFile{Cursors: make(map[string]CursorEntry)}
+		}
+		cf.Cursors[projection] = CursorEntry{
+			Position:    cursor,
+			LastUpdated: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		return s.saveCursors(cf)
+	})
 }
 
 // GetCursorEntry returns the full cursor entry for a projection, or a zero entry if not set.
@@ -125,7 +131,7 @@ func (s *Store) saveCursors(cf *CursorsFile) error {
 	if err != nil {
 		return fmt.Errorf("marshal cursors: %w", err)
 	}
-	return os.WriteFile(filepath.Join(s.stateDir, "cursors.json"), data, 0644)
+	return fsutil.WriteFile(filepath.Join(s.stateDir, "cursors.json"), data, 0644)
 }
 
 // --- State operations (state/<name>.json) ---
@@ -154,7 +160,7 @@ func (s *Store) SaveState(name string, state interface{}) error {
 	if err != nil {
 		return fmt.Errorf("marshal state %s: %w", name, err)
 	}
-	return os.WriteFile(filepath.Join(s.stateDir, name+".json"), data, 0644)
+	return fsutil.WriteFile(filepath.Join(s.stateDir, name+".json"), data, 0644)
 }
 
 // --- Config operations (config/<name>.json) ---
@@ -183,5 +189,5 @@ func (s *Store) SaveConfig(name string, config interface{}) error {
 	if err != nil {
 		return fmt.Errorf("marshal config %s: %w", name, err)
 	}
-	return os.WriteFile(filepath.Join(s.configDir, name+".json"), data, 0644)
+	return fsutil.WriteFile(filepath.Join(s.configDir, name+".json"), data, 0644)
 }
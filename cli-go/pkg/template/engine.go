@@ -34,6 +34,8 @@ type RenderContext struct {
 	// Page variables
 	Title          string
 	Content        string
+	Summary        string // HTML-escaped plain text excerpt
+	Lang           string // Language tag of the page (post lang, or the site's)
 	Published      string // ISO 8601 format
 	PublishedHuman string // Human-readable format
 	URL            string
@@ -50,12 +52,22 @@ type RenderContext struct {
 	Year       string
 
 	// Counts
-	BlessedCount int
-	CommentCount int
-	PostCount    int
+	BlessedCount  int
+	BacklinkCount int
+	CommentCount  int
+	PostCount     int
 
 	// Conditional HTML fragments
-	ViewAllPostsLink string // Pre-rendered "View all N posts" link (empty if ≤10)
+	ViewAllPostsLink  string // Pre-rendered "View all N posts" link (empty if ≤10)
+	BacklinksSection  string // Pre-rendered "Posts that link here" list (empty if none)
+	HreflangLinks     string // Pre-rendered <link rel="alternate" hreflang> tags (empty if no translations)
+	LanguageLinks     string // Pre-rendered links to the per-language index pages (empty if one language)
+	RobotsMeta        string // Pre-rendered <meta name="robots" content="noindex"> (unlisted posts only)
+	IPFSLink          string // Pre-rendered <link rel="alternate"> to the post's IPFS copy (empty if not pinned)
+	CommentWidget     string // Pre-rendered static comment widget (empty unless the theme enables it)
+	ColorSchemeToggle string // Pre-rendered light/dark mode button (empty unless the theme declares both schemes)
+	StatsSection      string // Pre-rendered site statistics tables (stats page only)
+	ArchiveSection    string // Pre-rendered posts grouped by year and month (archive page only)
 
 	// Widget variables
 	AuthorDomain string // Site domain (e.g. "alice.polis.pub")
@@ -74,6 +86,7 @@ type RenderContext struct {
 	RecentPosts     []PostData
 	RecentComments  []CommentData
 	Following       []FollowingData
+	Backlinks       []PostData // Posts on this site that link to this post
 }
 
 // FollowingData represents a followed author in a loop.
@@ -91,6 +104,8 @@ type PostData struct {
 	Published      string
 	PublishedHuman string
 	CommentCount   int
+	Summary        string // HTML-escaped plain text
+	Lang           string // Language tag
 }
 
 // CommentData represents a comment in a loop.
@@ -109,6 +124,7 @@ type BlessedCommentData struct {
 	Published      string
 	PublishedHuman string
 	Content        string
+	Featured       bool
 }
 
 // New creates a new template engine with the given configuration.
@@ -170,6 +186,8 @@ func (e *Engine) substituteVariables(template string, ctx *RenderContext) string
 		// Page variables
 		"title":           ctx.Title,
 		"content":         ctx.Content,
+		"summary":         ctx.Summary,
+		"lang":            ctx.Lang,
 		"published":       ctx.Published,
 		"published_human": ctx.PublishedHuman,
 		"url":             ctx.URL,
@@ -186,12 +204,22 @@ func (e *Engine) substituteVariables(template string, ctx *RenderContext) string
 		"year":        ctx.Year,
 
 		// Counts
-		"blessed_count": fmt.Sprintf("%d", ctx.BlessedCount),
-		"comment_count": fmt.Sprintf("%d", ctx.CommentCount),
-		"post_count":    fmt.Sprintf("%d", ctx.PostCount),
+		"blessed_count":  fmt.Sprintf("%d", ctx.BlessedCount),
+		"backlink_count": fmt.Sprintf("%d", ctx.BacklinkCount),
+		"comment_count":  fmt.Sprintf("%d", ctx.CommentCount),
+		"post_count":     fmt.Sprintf("%d", ctx.PostCount),
 
 		// Conditional fragments
-		"view_all_posts": ctx.ViewAllPostsLink,
+		"view_all_posts":      ctx.ViewAllPostsLink,
+		"backlinks_section":   ctx.BacklinksSection,
+		"hreflang_links":      ctx.HreflangLinks,
+		"language_links":      ctx.LanguageLinks,
+		"robots_meta":         ctx.RobotsMeta,
+		"ipfs_link":           ctx.IPFSLink,
+		"comment_widget":      ctx.CommentWidget,
+		"color_scheme_toggle": ctx.ColorSchemeToggle,
+		"stats_section":       ctx.StatsSection,
+		"archive_section":     ctx.ArchiveSection,
 
 		// Widget variables
 		"author_domain": ctx.AuthorDomain,
@@ -273,5 +301,6 @@ func NewRenderContext() *RenderContext {
 		BlessedComments: []BlessedCommentData{},
 		RecentPosts:     []PostData{},
 		RecentComments:  []CommentData{},
+		Backlinks:       []PostData{},
 	}
 }
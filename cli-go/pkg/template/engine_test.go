@@ -96,6 +96,26 @@ func TestBlessedCommentsSection(t *testing.T) {
 	}
 }
 
+func TestBlessedCommentsSection_Featured(t *testing.T) {
+	engine := New(Config{})
+	ctx := NewRenderContext()
+	ctx.BlessedComments = []BlessedCommentData{
+		{URL: "/comment1", AuthorName: "Alice", Content: "<p>Great post!</p>"},
+		{URL: "/comment2", AuthorName: "Bob", Content: "<p>Thanks for sharing</p>", Featured: true},
+	}
+
+	template := `{{#blessed_comments}}<div class="comment {{featured}}">{{author_name}}</div>{{/blessed_comments}}`
+
+	result, err := engine.Render(template, ctx)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if !strings.Contains(result, `<div class="comment featured">Bob`) || strings.Contains(result, `featured">Alice`) {
+		t.Errorf("Expected only Bob's comment to be featured, got: %s", result)
+	}
+}
+
 func TestCommentsSection(t *testing.T) {
 	engine := New(Config{})
 	ctx := NewRenderContext()
@@ -634,3 +654,25 @@ func TestFollowingSectionEmpty(t *testing.T) {
 		t.Errorf("Expected empty following section, got: %s", result)
 	}
 }
+
+func TestBacklinksSection(t *testing.T) {
+	engine := New(Config{})
+	ctx := NewRenderContext()
+	ctx.Backlinks = []PostData{
+		{URL: "../20260102/second.html", Title: "Second"},
+		{URL: "../20260103/third.html", Title: "Third"},
+	}
+	ctx.BacklinkCount = len(ctx.Backlinks)
+
+	tmpl := `<p>{{backlink_count}}</p>{{#backlinks}}<a href="{{url}}">{{title}}</a>{{/backlinks}}`
+
+	result, err := engine.Render(tmpl, ctx)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<p>2</p><a href="../20260102/second.html">Second</a><a href="../20260103/third.html">Third</a>`
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -17,6 +17,7 @@ var sectionOpenPattern = regexp.MustCompile(`\{\{#(\w+)\}\}`)
 // - {{#recent_posts}}...{{/recent_posts}} - Loop over 10 most recent posts
 // - {{#recent_comments}}...{{/recent_comments}} - Loop over 10 most recent comments
 // - {{#following}}...{{/following}} - Loop over followed authors
+// - {{#backlinks}}...{{/backlinks}} - Loop over posts that link to this post
 func (e *Engine) processSections(template string, ctx *RenderContext, depth int) (string, error) {
 	// Process sections iteratively since Go regex doesn't support backreferences
 	result := template
@@ -62,6 +63,8 @@ func (e *Engine) processSections(template string, ctx *RenderContext, depth int)
 			output, err = e.renderRecentCommentsSection(sectionContent, ctx, depth)
 		case "following":
 			output, err = e.renderFollowingSection(sectionContent, ctx, depth)
+		case "backlinks":
+			output, err = e.renderBacklinksSection(sectionContent, ctx, depth)
 		default:
 			// Unknown section - leave as-is and continue
 			break
@@ -76,7 +79,7 @@ func (e *Engine) processSections(template string, ctx *RenderContext, depth int)
 		result = result[:match[0]] + output + result[closeTagStart+len(closeTag):]
 
 		// Avoid checking unsupported section names again
-		if sectionName != "posts" && sectionName != "comments" && sectionName != "blessed_comments" && sectionName != "recent_posts" && sectionName != "recent_comments" && sectionName != "following" {
+		if sectionName != "posts" && sectionName != "comments" && sectionName != "blessed_comments" && sectionName != "recent_posts" && sectionName != "recent_comments" && sectionName != "following" && sectionName != "backlinks" {
 			// Skip to after this section to avoid infinite loop on unknown sections
 			result = result[:match[0]] + openTag + sectionContent + closeTag + result[match[0]:]
 			break
@@ -98,6 +101,8 @@ func (e *Engine) renderPostsSection(content string, ctx *RenderContext, depth in
 			Published:      post.Published,
 			PublishedHuman: post.PublishedHuman,
 			CommentCount:   post.CommentCount,
+			Summary:        post.Summary,
+			Lang:           post.Lang,
 
 			// Copy site-level variables
 			SiteURL:   ctx.SiteURL,
@@ -119,6 +124,8 @@ func (e *Engine) renderPostsSection(content string, ctx *RenderContext, depth in
 			"published":       post.Published,
 			"published_human": post.PublishedHuman,
 			"comment_count":   fmt.Sprintf("%d", post.CommentCount),
+			"summary":         post.Summary,
+			"lang":            post.Lang,
 		})
 
 		builder.WriteString(rendered)
@@ -199,6 +206,7 @@ func (e *Engine) renderBlessedCommentsSection(content string, ctx *RenderContext
 			"published":       bc.Published,
 			"published_human": bc.PublishedHuman,
 			"content":         bc.Content,
+			"featured":        featuredClass(bc.Featured),
 		})
 
 		builder.WriteString(rendered)
@@ -207,6 +215,15 @@ func (e *Engine) renderBlessedCommentsSection(content string, ctx *RenderContext
 	return builder.String(), nil
 }
 
+// featuredClass returns the {{featured}} loop value: "featured" for a
+// featured comment, so themes can write class="comment {{featured}}".
+func featuredClass(featured bool) string {
+	if featured {
+		return "featured"
+	}
+	return ""
+}
+
 // renderRecentPostsSection renders the {{#recent_posts}} section.
 // This shows the 10 most recent posts.
 func (e *Engine) renderRecentPostsSection(content string, ctx *RenderContext, depth int) (string, error) {
@@ -230,6 +247,8 @@ func (e *Engine) renderRecentPostsSection(content string, ctx *RenderContext, de
 			Published:      post.Published,
 			PublishedHuman: post.PublishedHuman,
 			CommentCount:   post.CommentCount,
+			Summary:        post.Summary,
+			Lang:           post.Lang,
 
 			// Copy site-level variables
 			SiteURL:   ctx.SiteURL,
@@ -250,6 +269,8 @@ func (e *Engine) renderRecentPostsSection(content string, ctx *RenderContext, de
 			"published":       post.Published,
 			"published_human": post.PublishedHuman,
 			"comment_count":   fmt.Sprintf("%d", post.CommentCount),
+			"summary":         post.Summary,
+			"lang":            post.Lang,
 		})
 
 		builder.WriteString(rendered)
@@ -365,3 +386,42 @@ func (e *Engine) substituteLoopVariables(template string, vars map[string]string
 		return match
 	})
 }
+
+// renderBacklinksSection renders the {{#backlinks}} section for each post
+// that links to the current one.
+func (e *Engine) renderBacklinksSection(content string, ctx *RenderContext, depth int) (string, error) {
+	var builder strings.Builder
+
+	for _, post := range ctx.Backlinks {
+		iterCtx := &RenderContext{
+			URL:            post.URL,
+			Title:          post.Title,
+			Published:      post.Published,
+			PublishedHuman: post.PublishedHuman,
+			Summary:        post.Summary,
+			Lang:           post.Lang,
+
+			SiteURL:   ctx.SiteURL,
+			SiteTitle: ctx.SiteTitle,
+			Year:      ctx.Year,
+		}
+
+		processed, err := e.processPartials(content, iterCtx, depth+1)
+		if err != nil {
+			return "", err
+		}
+
+		rendered := e.substituteLoopVariables(processed, map[string]string{
+			"url":             post.URL,
+			"title":           post.Title,
+			"published":       post.Published,
+			"published_human": post.PublishedHuman,
+			"summary":         post.Summary,
+			"lang":            post.Lang,
+		})
+
+		builder.WriteString(rendered)
+	}
+
+	return builder.String(), nil
+}
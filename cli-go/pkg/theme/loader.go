@@ -11,12 +11,19 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/lockfile"
 )
 
 // Version is set at init time by cmd package.
 var Version = "dev"
 
+// ConfigFilename is the optional settings file in a theme directory.
+const ConfigFilename = "theme.json"
+
 // Templates holds the loaded theme templates.
 type Templates struct {
 	Post          string // post.html - required
@@ -24,6 +31,41 @@ type Templates struct {
 	CommentInline string // comment-inline.html - required
 	Index         string // index.html - required
 	Archive       string // posts.html - optional (archive page)
+	Stats         string // stats.html - optional (site statistics page)
+	About         string // about.html - optional (about page)
+	NotFound      string // 404.html - optional (not found page)
+	Config        Config // theme.json - optional
+}
+
+// Config holds a theme's optional settings from theme.json.
+type Config struct {
+	// CommentWidget makes render write comments.js and a JSON file of
+	// blessed comments next to each post, so static hosts can show
+	// comments without a server.
+	CommentWidget bool `json:"comment_widget"`
+
+	// ReplyURL is where the comment widget's reply button links, with
+	// {url} replaced by the post URL. Without one, the button shows the
+	// command for replying from another polis site.
+	ReplyURL string `json:"reply_url,omitempty"`
+
+	// ColorSchemes pairs light and dark sets of the CSS variables the
+	// theme's stylesheet uses. With both set, styles.css follows the
+	// reader's prefers-color-scheme, and {{color_scheme_toggle}} renders a
+	// button to override it.
+	ColorSchemes *ColorSchemes `json:"color_schemes,omitempty"`
+}
+
+// ColorSchemes names CSS files, relative to the theme directory, whose
+// :root blocks hold the variables for each color scheme.
+type ColorSchemes struct {
+	Light string `json:"light"`
+	Dark  string `json:"dark"`
+}
+
+// HasColorSchemes reports whether the theme declares both color schemes.
+func (c Config) HasColorSchemes() bool {
+	return c.ColorSchemes != nil && c.ColorSchemes.Light != "" && c.ColorSchemes.Dark != ""
 }
 
 // Manifest represents the site manifest (metadata/manifest.json).
@@ -88,10 +130,39 @@ func loadFromDir(themeDir string) (*Templates, error) {
 	if content, err := os.ReadFile(filepath.Join(themeDir, "posts.html")); err == nil {
 		templates.Archive = string(content)
 	}
+	if content, err := os.ReadFile(filepath.Join(themeDir, "stats.html")); err == nil {
+		templates.Stats = string(content)
+	}
+	if content, err := os.ReadFile(filepath.Join(themeDir, "about.html")); err == nil {
+		templates.About = string(content)
+	}
+	if content, err := os.ReadFile(filepath.Join(themeDir, "404.html")); err == nil {
+		templates.NotFound = string(content)
+	}
+
+	// Load optional settings
+	cfg, err := loadConfig(themeDir)
+	if err != nil {
+		return nil, err
+	}
+	templates.Config = cfg
 
 	return templates, nil
 }
 
+// loadConfig reads a theme directory's theme.json, if it has one.
+func loadConfig(themeDir string) (Config, error) {
+	var cfg Config
+	content, err := os.ReadFile(filepath.Join(themeDir, ConfigFilename))
+	if err != nil {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", ConfigFilename, err)
+	}
+	return cfg, nil
+}
+
 // GetActiveTheme returns the active theme name from the manifest.
 // Returns empty string if no theme is set.
 func GetActiveTheme(dataDir string) (string, error) {
@@ -147,7 +218,7 @@ func SaveManifest(dataDir string, manifest *Manifest) error {
 		return fmt.Errorf("failed to marshal manifest: %w", err)
 	}
 
-	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+	if err := fsutil.WriteFile(manifestPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
@@ -156,41 +227,123 @@ func SaveManifest(dataDir string, manifest *Manifest) error {
 
 // SetActiveTheme updates the active theme in the manifest.
 func SetActiveTheme(dataDir, themeName string) error {
-	manifest, err := LoadManifest(dataDir)
-	if err != nil {
-		// Create new manifest if it doesn't exist
-		manifest = &Manifest{
-			Version: Version,
+	manifestPath := filepath.Join(dataDir, "metadata", "manifest.json")
+	return lockfile.With(manifestPath, func() error {
+		manifest, err := LoadManifest(dataDir)
+		if err != nil {
+			// Create new manifest if it doesn't exist
+			manifest = &Manifest{
+				Version: Version,
+			}
 		}
-	}
 
-	manifest.ActiveTheme = themeName
+		manifest.ActiveTheme = themeName
 
-	return SaveManifest(dataDir, manifest)
+		return SaveManifest(dataDir, manifest)
+	})
 }
 
 // CopyCSS copies the theme's CSS file to styles.css at the site root.
-// The CSS filename should match the theme name ({themename}.css).
+// The CSS filename should match the theme name ({themename}.css). A theme
+// that declares color schemes gets their rules appended (see SchemeCSS).
 func CopyCSS(dataDir, cliThemesDir, themeName string) error {
 	cssFilename := themeName + ".css"
 
 	// Try local theme first
-	localCSSPath := filepath.Join(dataDir, ".polis", "themes", themeName, cssFilename)
+	themeDir := filepath.Join(dataDir, ".polis", "themes", themeName)
 	destPath := filepath.Join(dataDir, "styles.css")
 
-	if _, err := os.Stat(localCSSPath); err == nil {
-		return copyFile(localCSSPath, destPath)
+	if _, err := os.Stat(filepath.Join(themeDir, cssFilename)); err != nil {
+		// Fall back to CLI themes
+		themeDir = ""
+		if cliThemesDir != "" {
+			if _, err := os.Stat(filepath.Join(cliThemesDir, themeName, cssFilename)); err == nil {
+				themeDir = filepath.Join(cliThemesDir, themeName)
+			}
+		}
+	}
+	if themeDir == "" {
+		return fmt.Errorf("CSS file not found: %s", cssFilename)
 	}
 
-	// Fall back to CLI themes
-	if cliThemesDir != "" {
-		cliCSSPath := filepath.Join(cliThemesDir, themeName, cssFilename)
-		if _, err := os.Stat(cliCSSPath); err == nil {
-			return copyFile(cliCSSPath, destPath)
+	if err := copyFile(filepath.Join(themeDir, cssFilename), destPath); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(themeDir)
+	if err != nil || !cfg.HasColorSchemes() {
+		return err
+	}
+	schemes, err := SchemeCSS(themeDir, *cfg.ColorSchemes)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(destPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(schemes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ColorSchemeAttr is the attribute on <html> that overrides the reader's
+// preferred color scheme ("light" or "dark").
+const ColorSchemeAttr = "data-color-scheme"
+
+// SchemeCSS returns the rules that apply a theme's light and dark variable
+// sets: by prefers-color-scheme, unless <html> has a ColorSchemeAttr, in
+// which case that scheme wins.
+func SchemeCSS(themeDir string, schemes ColorSchemes) (string, error) {
+	light, err := rootDeclarations(filepath.Join(themeDir, schemes.Light))
+	if err != nil {
+		return "", fmt.Errorf("light color scheme: %w", err)
+	}
+	dark, err := rootDeclarations(filepath.Join(themeDir, schemes.Dark))
+	if err != nil {
+		return "", fmt.Errorf("dark color scheme: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("\n/* Color schemes (generated from theme.json) */\n")
+	b.WriteString(":root { color-scheme: light dark; }\n")
+	for _, s := range []struct{ name, other, vars string }{{"light", "dark", light}, {"dark", "light", dark}} {
+		fmt.Fprintf(&b, "@media (prefers-color-scheme: %s) {\n  :root:not([%s=\"%s\"]) {\n%s  }\n}\n", s.name, ColorSchemeAttr, s.other, indent(s.vars, "    "))
+		fmt.Fprintf(&b, ":root[%s=\"%s\"] {\n  color-scheme: %s;\n%s}\n", ColorSchemeAttr, s.name, s.name, indent(s.vars, "  "))
+	}
+	return b.String(), nil
+}
+
+// rootDeclarations returns the declarations in a CSS file's :root block,
+// one per line.
+func rootDeclarations(cssPath string) (string, error) {
+	data, err := os.ReadFile(cssPath)
+	if err != nil {
+		return "", err
+	}
+	css := string(data)
+	i := strings.Index(css, ":root")
+	if i < 0 {
+		return "", fmt.Errorf("no :root block in %s", filepath.Base(cssPath))
+	}
+	open := strings.Index(css[i:], "{")
+	end := strings.Index(css[i:], "}")
+	if open < 0 || end < open {
+		return "", fmt.Errorf("unterminated :root block in %s", filepath.Base(cssPath))
+	}
+	var lines []string
+	for _, line := range strings.Split(css[i+open+1:i+end], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
 		}
 	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
 
-	return fmt.Errorf("CSS file not found: %s", cssFilename)
+// indent prefixes each line of s with prefix.
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n"+prefix) + "\n"
 }
 
 // copyFile copies a file from src to dst.
@@ -330,24 +483,24 @@ type ThemePalette struct {
 	Name   string   `json:"name"`
 	Colors []string `json:"colors"` // 5 hex colors: bg, text, accent1, accent2, cyan
 	Active bool     `json:"active"`
+
+	// ColorSchemes is ["light", "dark"] for themes that declare both, and
+	// otherwise the one their background suggests.
+	ColorSchemes []string `json:"color_schemes"`
 }
 
 // cssColorVar matches CSS custom property declarations like --color-bg: #1a1525;
 var cssColorVar = regexp.MustCompile(`^\s*--color-([a-z0-9-]+)\s*:\s*(#[0-9a-fA-F]{3,8})\s*;`)
 
-// ExtractPalette reads a theme's CSS file and extracts 5 representative colors.
-// Returns bg, text, two accent colors, and cyan.
-func ExtractPalette(themeDir, themeName string) ThemePalette {
-	palette := ThemePalette{Name: themeName}
-
-	cssPath := filepath.Join(themeDir, themeName+".css")
+// ColorVars returns the hex --color-* variables declared in a theme CSS
+// file's :root block, keyed by name without the prefix ("bg", "text-muted").
+func ColorVars(cssPath string) (map[string]string, error) {
 	f, err := os.Open(cssPath)
 	if err != nil {
-		return palette
+		return nil, err
 	}
 	defer f.Close()
 
-	// Parse all --color-* variables from the :root block
 	vars := make(map[string]string)
 	inRoot := false
 	scanner := bufio.NewScanner(f)
@@ -368,6 +521,19 @@ func ExtractPalette(themeDir, themeName string) ThemePalette {
 			vars[m[1]] = m[2]
 		}
 	}
+	return vars, scanner.Err()
+}
+
+// ExtractPalette reads a theme's CSS file and extracts 5 representative colors.
+// Returns bg, text, two accent colors, and cyan.
+func ExtractPalette(themeDir, themeName string) ThemePalette {
+	palette := ThemePalette{Name: themeName}
+
+	// Parse all --color-* variables from the :root block
+	vars, err := ColorVars(filepath.Join(themeDir, themeName+".css"))
+	if err != nil {
+		return palette
+	}
 
 	// Pick 5 representative colors: bg, text, accent1, accent2, cyan
 	bg := vars["bg"]
@@ -402,9 +568,34 @@ func ExtractPalette(themeDir, themeName string) ThemePalette {
 	}
 
 	palette.Colors = []string{bg, text, accent1, accent2, cyan}
+
+	if cfg, err := loadConfig(themeDir); err == nil && cfg.HasColorSchemes() {
+		palette.ColorSchemes = []string{"light", "dark"}
+	} else if isLightColor(bg) {
+		palette.ColorSchemes = []string{"light"}
+	} else {
+		palette.ColorSchemes = []string{"dark"}
+	}
 	return palette
 }
 
+// isLightColor reports whether a hex color is closer to white than black.
+func isLightColor(hex string) bool {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) < 6 {
+		return false
+	}
+	rgb, err := strconv.ParseUint(hex[:6], 16, 32)
+	if err != nil {
+		return false
+	}
+	r, g, b := float64(rgb>>16), float64(rgb>>8&0xff), float64(rgb&0xff)
+	return 0.299*r+0.587*g+0.114*b > 128
+}
+
 // ListThemesWithPalettes returns all available themes with their color palettes.
 // The active theme is marked with Active=true.
 func ListThemesWithPalettes(dataDir, cliThemesDir string) ([]ThemePalette, error) {
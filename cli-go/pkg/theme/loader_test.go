@@ -3,6 +3,7 @@ package theme
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -144,6 +145,49 @@ func TestCopyCSS(t *testing.T) {
 	}
 }
 
+func TestCopyCSS_ColorSchemes(t *testing.T) {
+	tempDir := t.TempDir()
+	themesDir := filepath.Join(tempDir, ".polis", "themes")
+	createTestTheme(t, themesDir, "duo")
+	dir := filepath.Join(themesDir, "duo")
+	os.WriteFile(filepath.Join(dir, "light.css"), []byte(":root {\n  --color-bg: #ffffff;\n  --color-text: #111111;\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "dark.css"), []byte(":root {\n  --color-bg: #111111;\n  --color-text: #eeeeee;\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ConfigFilename), []byte(`{"color_schemes": {"light": "light.css", "dark": "dark.css"}}`), 0644)
+
+	if err := CopyCSS(tempDir, "", "duo"); err != nil {
+		t.Fatalf("CopyCSS failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(tempDir, "styles.css"))
+	css := string(data)
+	for _, want := range []string{
+		"body {}",
+		"@media (prefers-color-scheme: dark) {\n  :root:not([data-color-scheme=\"light\"]) {\n    --color-bg: #111111;",
+		":root[data-color-scheme=\"light\"] {\n  color-scheme: light;\n  --color-bg: #ffffff;",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("styles.css missing %q:\n%s", want, css)
+		}
+	}
+
+	palette := ExtractPalette(dir, "duo")
+	if len(palette.ColorSchemes) != 2 {
+		t.Errorf("ColorSchemes = %v, want light and dark", palette.ColorSchemes)
+	}
+}
+
+func TestExtractPalette_ColorScheme(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "paper.css"), []byte(":root {\n  --color-bg: #f4f0e8;\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "night.css"), []byte(":root {\n  --color-bg: #1a1525;\n}\n"), 0644)
+
+	if got := ExtractPalette(dir, "paper").ColorSchemes; len(got) != 1 || got[0] != "light" {
+		t.Errorf("paper: ColorSchemes = %v, want [light]", got)
+	}
+	if got := ExtractPalette(dir, "night").ColorSchemes; len(got) != 1 || got[0] != "dark" {
+		t.Errorf("night: ColorSchemes = %v, want [dark]", got)
+	}
+}
+
 func TestListThemes(t *testing.T) {
 	dataDir := t.TempDir()
 	cliThemesDir := t.TempDir()
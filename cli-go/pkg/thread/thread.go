@@ -0,0 +1,295 @@
+// Package thread tracks remote comment threads the user has joined and
+// detects newly blessed replies in them.
+//
+// Subscriptions live in .polis/threads.json. A thread is the root post of a
+// comment the user wrote; replies are found by fetching the post owner's
+// metadata/blessed-comments.json and diffing it against the comment URLs
+// already seen.
+package thread
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+// Version is set at init time by cmd package.
+var Version = "dev"
+
+// GetGenerator returns the generator identifier for metadata files.
+func GetGenerator() string {
+	return "polis-cli-go/" + Version
+}
+
+// MaxReplies caps the number of recent replies kept in threads.json.
+const MaxReplies = 100
+
+// File represents the threads.json structure.
+type File struct {
+	Version string         `json:"version"`
+	Threads []Subscription `json:"threads"`
+	Replies []Reply        `json:"replies"`
+}
+
+// Subscription is a remote post whose comment thread is being watched.
+type Subscription struct {
+	PostURL      string   `json:"post_url"`
+	Domain       string   `json:"domain"`
+	SubscribedAt string   `json:"subscribed_at"`
+	LastChecked  string   `json:"last_checked,omitempty"`
+	Muted        bool     `json:"muted,omitempty"`
+	Seen         []string `json:"seen,omitempty"` // blessed comment URLs already reported
+}
+
+// Reply is a newly blessed comment found in a watched thread.
+type Reply struct {
+	PostURL    string `json:"post_url"`
+	CommentURL string `json:"comment_url"`
+	Author     string `json:"author"`
+	BlessedAt  string `json:"blessed_at,omitempty"`
+	FoundAt    string `json:"found_at"`
+}
+
+// Fetcher fetches a site's blessed comments index. *remote.Client implements it.
+type Fetcher interface {
+	FetchBlessedComments(baseURL string) (*metadata.BlessedComments, error)
+}
+
+// DefaultPath returns the default path to threads.json.
+func DefaultPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "threads.json")
+}
+
+// Load loads threads.json, returning an empty file if it does not exist.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{
+				Version: GetGenerator(),
+				Threads: []Subscription{},
+				Replies: []Reply{},
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read threads.json: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse threads.json: %w", err)
+	}
+	if f.Threads == nil {
+		f.Threads = []Subscription{}
+	}
+	if f.Replies == nil {
+		f.Replies = []Reply{}
+	}
+	return &f, nil
+}
+
+// Save writes threads.json to the given path.
+func Save(path string, f *File) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal threads.json: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := fsutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write threads.json: %w", err)
+	}
+	return nil
+}
+
+// normalizePostURL strips fragments and trailing slashes so the same post
+// is not subscribed twice.
+func normalizePostURL(u string) string {
+	if idx := strings.Index(u, "#"); idx >= 0 {
+		u = u[:idx]
+	}
+	return strings.TrimRight(u, "/")
+}
+
+// Get returns the subscription for a post, or nil.
+func (f *File) Get(postURL string) *Subscription {
+	norm := normalizePostURL(postURL)
+	for i := range f.Threads {
+		if f.Threads[i].PostURL == norm {
+			return &f.Threads[i]
+		}
+	}
+	return nil
+}
+
+// Subscribe starts watching a post's thread. An existing muted subscription
+// is unmuted. Returns true if anything changed.
+func (f *File) Subscribe(postURL string) bool {
+	norm := normalizePostURL(postURL)
+	if existing := f.Get(norm); existing != nil {
+		if existing.Muted {
+			existing.Muted = false
+			return true
+		}
+		return false
+	}
+	domain := discovery.ExtractDomainFromURL(norm)
+	if domain == "" {
+		return false
+	}
+	f.Threads = append(f.Threads, Subscription{
+		PostURL:      norm,
+		Domain:       domain,
+		SubscribedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	return true
+}
+
+// Unsubscribe mutes a thread. The subscription is kept so that
+// SubscribeFromComments does not re-add it. Returns true if it was active.
+func (f *File) Unsubscribe(postURL string) bool {
+	existing := f.Get(postURL)
+	if existing == nil || existing.Muted {
+		return false
+	}
+	existing.Muted = true
+	return true
+}
+
+// Active returns the subscriptions that are not muted.
+func (f *File) Active() []Subscription {
+	var active []Subscription
+	for _, t := range f.Threads {
+		if !t.Muted {
+			active = append(active, t)
+		}
+	}
+	return active
+}
+
+// SubscribeFromComments subscribes to the root post of every comment the
+// user has written (pending, blessed, or denied). Returns the number of new
+// subscriptions.
+func (f *File) SubscribeFromComments(dataDir string) int {
+	added := 0
+	for _, status := range []string{comment.StatusPending, comment.StatusBlessed, comment.StatusDenied} {
+		metas, err := comment.ListComments(dataDir, status)
+		if err != nil {
+			continue
+		}
+		for _, m := range metas {
+			postURL := m.RootPost
+			if postURL == "" {
+				postURL = m.InReplyTo
+			}
+			if postURL != "" && f.Get(postURL) == nil && f.Subscribe(postURL) {
+				added++
+			}
+		}
+	}
+	return added
+}
+
+// Check fetches the blessed comments index for every active thread and
+// returns replies not seen before. Comments from myDomain are never reported.
+// The first check of a thread only records what is already there, so
+// joining an old thread does not flood the user with its history.
+// A fetch failure for one site does not stop the others; the last error is
+// returned alongside whatever replies were found.
+func (f *File) Check(fetcher Fetcher, myDomain string) ([]Reply, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	// Fetch each site once, even when several of its posts are watched
+	indexes := make(map[string]*metadata.BlessedComments)
+	var lastErr error
+	var replies []Reply
+
+	for i := range f.Threads {
+		t := &f.Threads[i]
+		if t.Muted {
+			continue
+		}
+
+		baseURL := siteBaseURL(t.PostURL)
+		bc, fetched := indexes[baseURL]
+		if !fetched {
+			var err error
+			bc, err = fetcher.FetchBlessedComments(baseURL)
+			if err != nil {
+				lastErr = err
+			}
+			indexes[baseURL] = bc
+		}
+		if bc == nil {
+			continue
+		}
+
+		seen := make(map[string]bool, len(t.Seen))
+		for _, u := range t.Seen {
+			seen[u] = true
+		}
+		firstCheck := t.LastChecked == ""
+		postPath := postPathFromURL(t.PostURL)
+
+		for _, pc := range bc.Comments {
+			if strings.TrimPrefix(pc.Post, "/") != postPath {
+				continue
+			}
+			for _, c := range pc.Blessed {
+				if seen[c.URL] {
+					continue
+				}
+				seen[c.URL] = true
+				t.Seen = append(t.Seen, c.URL)
+
+				author := discovery.ExtractDomainFromURL(c.URL)
+				if firstCheck || author == "" || author == myDomain {
+					continue
+				}
+				replies = append(replies, Reply{
+					PostURL:    t.PostURL,
+					CommentURL: c.URL,
+					Author:     author,
+					BlessedAt:  c.BlessedAt,
+					FoundAt:    now,
+				})
+			}
+		}
+		t.LastChecked = now
+	}
+
+	f.Replies = append(f.Replies, replies...)
+	if len(f.Replies) > MaxReplies {
+		f.Replies = f.Replies[len(f.Replies)-MaxReplies:]
+	}
+
+	return replies, lastErr
+}
+
+// siteBaseURL returns the scheme and host of a post URL.
+func siteBaseURL(postURL string) string {
+	if idx := strings.Index(postURL, "://"); idx >= 0 {
+		rest := postURL[idx+3:]
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			return postURL[:idx+3+slash]
+		}
+	}
+	return postURL
+}
+
+// postPathFromURL returns the site-relative path of a post URL
+// ("posts/..."), matching the keys of blessed-comments.json.
+func postPathFromURL(postURL string) string {
+	base := siteBaseURL(postURL)
+	return strings.TrimPrefix(strings.TrimPrefix(postURL, base), "/")
+}
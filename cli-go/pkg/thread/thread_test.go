@@ -0,0 +1,169 @@
+package thread
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+type fakeFetcher struct {
+	sites map[string]*metadata.BlessedComments
+	calls int
+}
+
+func (f *fakeFetcher) FetchBlessedComments(baseURL string) (*metadata.BlessedComments, error) {
+	f.calls++
+	if bc, ok := f.sites[baseURL]; ok {
+		return bc, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestLoadSave_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".polis", "threads.json")
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load missing file: %v", err)
+	}
+	if len(f.Threads) != 0 || f.Replies == nil {
+		t.Fatalf("expected empty file, got %+v", f)
+	}
+
+	f.Subscribe("https://alice.example.com/posts/20260101/hello.md")
+	if err := Save(path, f); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Threads) != 1 || loaded.Threads[0].Domain != "alice.example.com" {
+		t.Errorf("unexpected threads: %+v", loaded.Threads)
+	}
+}
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	f := &File{}
+	post := "https://alice.example.com/posts/hello.md"
+
+	if !f.Subscribe(post) {
+		t.Fatal("expected first subscribe to change state")
+	}
+	if f.Subscribe(post + "/") {
+		t.Error("expected duplicate subscribe (trailing slash) to be a no-op")
+	}
+	if !f.Unsubscribe(post) {
+		t.Error("expected unsubscribe to mute")
+	}
+	if len(f.Active()) != 0 {
+		t.Error("muted thread should not be active")
+	}
+	if !f.Subscribe(post) {
+		t.Error("expected subscribe to unmute")
+	}
+	if f.Subscribe("not a url") {
+		t.Error("expected subscribe without a domain to fail")
+	}
+}
+
+func TestSubscribeFromComments_RespectsMuted(t *testing.T) {
+	dataDir := t.TempDir()
+	pendingDir := filepath.Join(dataDir, ".polis", "comments", "pending")
+	os.MkdirAll(pendingDir, 0755)
+
+	write := func(name, root string) {
+		content := "---\ntitle: Re\npublished: 2026-01-01T00:00:00Z\nin-reply-to:\n  url: " + root +
+			"\n  root-post: " + root + "\n---\n\nHi\n"
+		os.WriteFile(filepath.Join(pendingDir, name), []byte(content), 0644)
+	}
+	write("a.md", "https://alice.example.com/posts/one.md")
+	write("b.md", "https://bob.example.com/posts/two.md")
+
+	f := &File{}
+	f.Subscribe("https://bob.example.com/posts/two.md")
+	f.Unsubscribe("https://bob.example.com/posts/two.md")
+
+	if added := f.SubscribeFromComments(dataDir); added != 1 {
+		t.Errorf("expected 1 new subscription, got %d", added)
+	}
+	if active := f.Active(); len(active) != 1 || active[0].Domain != "alice.example.com" {
+		t.Errorf("expected only alice active, got %+v", active)
+	}
+}
+
+func TestCheck_ReportsOnlyNewReplies(t *testing.T) {
+	post := "https://alice.example.com/posts/20260101/hello.md"
+	index := &metadata.BlessedComments{Comments: []metadata.PostComments{
+		{Post: "posts/20260101/hello.md", Blessed: []metadata.BlessedComment{
+			{URL: "https://me.example.com/comments/mine.md"},
+			{URL: "https://carol.example.com/comments/old.md"},
+		}},
+		{Post: "posts/20260101/other.md", Blessed: []metadata.BlessedComment{
+			{URL: "https://dave.example.com/comments/elsewhere.md"},
+		}},
+	}}
+	fetcher := &fakeFetcher{sites: map[string]*metadata.BlessedComments{"https://alice.example.com": index}}
+
+	f := &File{}
+	f.Subscribe(post)
+
+	// First check seeds the thread without reporting existing comments
+	replies, err := f.Check(fetcher, "me.example.com")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(replies) != 0 {
+		t.Fatalf("expected no replies on first check, got %+v", replies)
+	}
+
+	index.Comments[0].Blessed = append(index.Comments[0].Blessed,
+		metadata.BlessedComment{URL: "https://bob.example.com/comments/reply.md", BlessedAt: "2026-01-02T00:00:00Z"},
+		metadata.BlessedComment{URL: "https://me.example.com/comments/second.md"},
+	)
+
+	replies, err = f.Check(fetcher, "me.example.com")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(replies) != 1 || replies[0].Author != "bob.example.com" || replies[0].PostURL != post {
+		t.Fatalf("expected one reply from bob, got %+v", replies)
+	}
+	if len(f.Replies) != 1 {
+		t.Errorf("expected reply recorded, got %d", len(f.Replies))
+	}
+
+	// Nothing new on a third check
+	replies, _ = f.Check(fetcher, "me.example.com")
+	if len(replies) != 0 {
+		t.Errorf("expected no repeat replies, got %+v", replies)
+	}
+}
+
+func TestCheck_FetchesEachSiteOnce(t *testing.T) {
+	fetcher := &fakeFetcher{sites: map[string]*metadata.BlessedComments{
+		"https://alice.example.com": {},
+	}}
+	f := &File{}
+	f.Subscribe("https://alice.example.com/posts/one.md")
+	f.Subscribe("https://alice.example.com/posts/two.md")
+	f.Subscribe("https://gone.example.com/posts/three.md")
+
+	_, err := f.Check(fetcher, "me.example.com")
+	if err == nil {
+		t.Error("expected the failed site's error to be returned")
+	}
+	if fetcher.calls != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetcher.calls)
+	}
+	if f.Get("https://alice.example.com/posts/one.md").LastChecked == "" {
+		t.Error("expected reachable thread to be marked checked")
+	}
+	if f.Get("https://gone.example.com/posts/three.md").LastChecked != "" {
+		t.Error("unreachable thread should stay unchecked")
+	}
+}
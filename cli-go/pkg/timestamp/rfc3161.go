@@ -0,0 +1,367 @@
+package timestamp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
+)
+
+var (
+	oidSHA1        = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidRSAPSS      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+	oidSignedData  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMsgDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// ASN.1 structures from RFC 3161 and RFC 5652 (CMS), reduced to the fields
+// polis reads. Trailing fields it doesn't need are ignored when parsing.
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+// Token is a verified time-stamp token: the TSA's signed statement that
+// Digest existed at GenTime.
+type Token struct {
+	Digest       []byte // SHA-256 digest that was time-stamped
+	GenTime      time.Time
+	SerialNumber *big.Int
+	Nonce        *big.Int
+	Signer       *x509.Certificate   // The TSA certificate that signed the token
+	Certificates []*x509.Certificate // Certificates included in the token
+}
+
+// Request asks the time-stamping authority at tsaURL to time-stamp a
+// SHA-256 digest, checks the reply, and returns it in DER (the contents of
+// a .tsr file) with the parsed token.
+func Request(tsaURL string, digest []byte) ([]byte, *Token, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := safehttp.NewClient(safehttp.Options{Timeout: 30 * time.Second})
+	resp, err := client.Post(tsaURL, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, nil, fmt.Errorf("time-stamping authority unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("time-stamping authority returned HTTP %d", resp.StatusCode)
+	}
+
+	tok, err := ParseResponse(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(tok.Digest, digest) {
+		return nil, nil, errors.New("time-stamp token is for a different digest")
+	}
+	if tok.Nonce == nil || tok.Nonce.Cmp(nonce) != 0 {
+		return nil, nil, errors.New("time-stamp token does not echo the request nonce")
+	}
+	return body, tok, nil
+}
+
+// ParseResponse parses a DER TimeStampResp and verifies the token's
+// signature. It does not check who issued the signing certificate; see
+// Token.VerifyChain.
+func ParseResponse(der []byte) (*Token, error) {
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(der, &resp); err != nil {
+		return nil, fmt.Errorf("malformed time-stamp response: %w", err)
+	}
+	// 0 is granted, 1 granted with modifications
+	if resp.Status.Status > 1 {
+		var text []string
+		for _, s := range resp.Status.StatusString {
+			text = append(text, string(s.Bytes))
+		}
+		if len(text) == 0 {
+			return nil, fmt.Errorf("time-stamp request rejected (status %d)", resp.Status.Status)
+		}
+		return nil, fmt.Errorf("time-stamp request rejected: %s", strings.Join(text, "; "))
+	}
+	if len(resp.Token.FullBytes) == 0 {
+		return nil, errors.New("time-stamp response has no token")
+	}
+	return parseToken(resp.Token.FullBytes)
+}
+
+// parseToken parses and verifies a TimeStampToken (a CMS SignedData).
+func parseToken(der []byte) (*Token, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("malformed time-stamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("time-stamp token is not signed data")
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("malformed time-stamp token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("time-stamp token holds no TSTInfo")
+	}
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &content); err != nil {
+		return nil, fmt.Errorf("malformed TSTInfo: %w", err)
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(content, &info); err != nil {
+		return nil, fmt.Errorf("malformed TSTInfo: %w", err)
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		return nil, errors.New("time-stamp token is not for a SHA-256 digest")
+	}
+
+	var certs []*x509.Certificate
+	if len(sd.Certificates.Bytes) > 0 {
+		var err error
+		if certs, err = x509.ParseCertificates(sd.Certificates.Bytes); err != nil {
+			return nil, fmt.Errorf("malformed certificate in time-stamp token: %w", err)
+		}
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("time-stamp token has %d signers, want 1", len(sd.SignerInfos))
+	}
+	signer, err := verifySigner(&sd.SignerInfos[0], content, certs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		Digest:       info.MessageImprint.HashedMessage,
+		GenTime:      info.GenTime,
+		SerialNumber: info.SerialNumber,
+		Nonce:        info.Nonce,
+		Signer:       signer,
+		Certificates: certs,
+	}, nil
+}
+
+// verifySigner checks the signed attributes against content and their
+// signature against the certificates, returning the signing certificate.
+func verifySigner(si *signerInfo, content []byte, certs []*x509.Certificate) (*x509.Certificate, error) {
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return nil, errors.New("time-stamp token has no signed attributes")
+	}
+	hash, ok := hashFor(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %v", si.DigestAlgorithm.Algorithm)
+	}
+
+	// The signature covers the attributes encoded as a SET, not with the
+	// [0] tag they carry in the SignerInfo
+	signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+		return nil, fmt.Errorf("malformed signed attributes: %w", err)
+	}
+	var digest []byte
+	var contentType asn1.ObjectIdentifier
+	for _, a := range attrs {
+		switch {
+		case a.Type.Equal(oidMsgDigest):
+			asn1.Unmarshal(a.Values.Bytes, &digest)
+		case a.Type.Equal(oidContentType):
+			asn1.Unmarshal(a.Values.Bytes, &contentType)
+		}
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		return nil, errors.New("signed content type is not TSTInfo")
+	}
+	h := hash.New()
+	h.Write(content)
+	if !bytes.Equal(digest, h.Sum(nil)) {
+		return nil, errors.New("time-stamp token content does not match its signed digest")
+	}
+
+	for _, cert := range certs {
+		alg := signatureAlgorithm(cert.PublicKeyAlgorithm, hash, si.SignatureAlgorithm.Algorithm.Equal(oidRSAPSS))
+		if alg == x509.UnknownSignatureAlgorithm {
+			continue
+		}
+		if cert.CheckSignature(alg, signed, si.Signature) != nil {
+			continue
+		}
+		for _, usage := range cert.ExtKeyUsage {
+			if usage == x509.ExtKeyUsageTimeStamping {
+				return cert, nil
+			}
+		}
+		return nil, errors.New("time-stamp token signed by a certificate not issued for time-stamping")
+	}
+	return nil, errors.New("time-stamp token signature does not verify (the TSA certificate must be included)")
+}
+
+func hashFor(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, true
+	}
+	return 0, false
+}
+
+func signatureAlgorithm(key x509.PublicKeyAlgorithm, hash crypto.Hash, pss bool) x509.SignatureAlgorithm {
+	switch key {
+	case x509.RSA:
+		switch {
+		case pss && hash == crypto.SHA256:
+			return x509.SHA256WithRSAPSS
+		case pss && hash == crypto.SHA384:
+			return x509.SHA384WithRSAPSS
+		case pss && hash == crypto.SHA512:
+			return x509.SHA512WithRSAPSS
+		case hash == crypto.SHA256:
+			return x509.SHA256WithRSA
+		case hash == crypto.SHA384:
+			return x509.SHA384WithRSA
+		case hash == crypto.SHA512:
+			return x509.SHA512WithRSA
+		case hash == crypto.SHA1:
+			return x509.SHA1WithRSA
+		}
+	case x509.ECDSA:
+		switch hash {
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512
+		case crypto.SHA1:
+			return x509.ECDSAWithSHA1
+		}
+	case x509.Ed25519:
+		return x509.PureEd25519
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// VerifyChain checks that the signing certificate chains to roots (the
+// system roots if nil) and was valid when the token was made.
+func (t *Token) VerifyChain(roots *x509.CertPool) error {
+	intermediates := x509.NewCertPool()
+	for _, c := range t.Certificates {
+		if c != t.Signer {
+			intermediates.AddCert(c)
+		}
+	}
+	_, err := t.Signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	return err
+}
@@ -0,0 +1,188 @@
+// Package timestamp anchors post versions with RFC 3161 time-stamp tokens.
+//
+// When a time-stamping authority (TSA) is configured, publishing a post or
+// a new version of it sends the version hash — never the content — to the
+// TSA, which returns a signed statement that the hash existed at that
+// moment. The reply is kept as a standard .tsr file under .polis/proofs/,
+// one per version, so anyone holding the post can check when that version
+// existed without trusting the author's clock, with polis or with
+// "openssl ts -verify".
+package timestamp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// ProofsDir is where proofs are stored, relative to the site directory.
+const ProofsDir = ".polis/proofs"
+
+// ProofExt is the extension of a stored TSA reply.
+const ProofExt = ".tsr"
+
+// Proof describes a stored time-stamp proof of one post version.
+type Proof struct {
+	Path    string    `json:"path"`    // Post path
+	Version string    `json:"version"` // sha256:... version hash
+	File    string    `json:"file"`    // Proof file, relative to the site directory
+	Time    time.Time `json:"time"`    // When the TSA saw the hash
+	TSA     string    `json:"tsa"`     // Subject of the TSA certificate
+	Trusted bool      `json:"trusted"` // TSA certificate chains to a system root
+	Current bool      `json:"current"` // Version is the post's current version
+	Error   string    `json:"error,omitempty"`
+}
+
+// ProofFile returns the proof file of a post version, relative to the site
+// directory.
+func ProofFile(postPath, version string) string {
+	dir := strings.TrimSuffix(filepath.ToSlash(postPath), ".md")
+	return ProofsDir + "/" + dir + "/" + strings.TrimPrefix(version, "sha256:") + ProofExt
+}
+
+// Anchor time-stamps the post's current version with the TSA at tsaURL and
+// stores the proof. A version already anchored is not sent again.
+func Anchor(dataDir, tsaURL, postPath string) (*Proof, error) {
+	if tsaURL == "" {
+		return nil, errors.New("no time-stamping authority configured (set timestamp.tsa)")
+	}
+	postPath = filepath.ToSlash(postPath)
+	fm, err := publish.ReadFrontmatter(dataDir, postPath)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := versionDigest(fm.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	file := ProofFile(postPath, fm.CurrentVersion)
+	if _, err := os.Stat(filepath.Join(dataDir, filepath.FromSlash(file))); err == nil {
+		return check(dataDir, postPath, fm.CurrentVersion, fm.CurrentVersion), nil
+	}
+
+	der, tok, err := Request(tsaURL, digest)
+	if err != nil {
+		return nil, err
+	}
+	full := filepath.Join(dataDir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create proofs directory: %w", err)
+	}
+	if err := fsutil.WriteFile(full, der, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save proof: %w", err)
+	}
+	return &Proof{
+		Path:    postPath,
+		Version: fm.CurrentVersion,
+		File:    file,
+		Time:    tok.GenTime,
+		TSA:     tok.Signer.Subject.String(),
+		Trusted: tok.VerifyChain(nil) == nil,
+		Current: true,
+	}, nil
+}
+
+// Verify checks every stored proof of a post: that it is a validly signed
+// token for its version's hash, and that the version is one the post has
+// had. The current version's hash is recomputed from the post's content.
+// Proofs are returned oldest first; a proof that fails has Error set.
+func Verify(dataDir, postPath string) ([]Proof, error) {
+	postPath = filepath.ToSlash(postPath)
+	content, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(postPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post: %w", err)
+	}
+	fm := publish.ParseFrontmatterFields(string(content))
+	body := publish.StripFrontmatter(string(content))
+	if "sha256:"+publish.HashContent([]byte(publish.CanonicalizeContent(body))) != fm.CurrentVersion {
+		return nil, fmt.Errorf("post content does not match its current version %s", fm.CurrentVersion)
+	}
+
+	dir := filepath.Join(dataDir, filepath.FromSlash(ProofsDir), filepath.FromSlash(strings.TrimSuffix(postPath, ".md")))
+	files, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var proofs []Proof
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ProofExt) {
+			continue
+		}
+		version := "sha256:" + strings.TrimSuffix(f.Name(), ProofExt)
+		p := check(dataDir, postPath, version, fm.CurrentVersion)
+		if p.Error == "" && !p.Current && !inHistory(fm.VersionHistory, version) {
+			p.Error = "not a version of this post"
+		}
+		proofs = append(proofs, *p)
+	}
+	sort.Slice(proofs, func(i, j int) bool { return proofs[i].Time.Before(proofs[j].Time) })
+	return proofs, nil
+}
+
+// check verifies the stored proof of one version.
+func check(dataDir, postPath, version, current string) *Proof {
+	p := &Proof{
+		Path:    postPath,
+		Version: version,
+		File:    ProofFile(postPath, version),
+		Current: version == current,
+	}
+	der, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(p.File)))
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	tok, err := ParseResponse(der)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	p.Time = tok.GenTime
+	p.TSA = tok.Signer.Subject.String()
+	p.Trusted = tok.VerifyChain(nil) == nil
+	if digest, err := versionDigest(version); err != nil || !bytes.Equal(digest, tok.Digest) {
+		p.Error = "proof is for a different hash"
+	}
+	return p
+}
+
+// VerifyChain reports whether the proof's TSA certificate chains to roots,
+// for TSAs whose root isn't in the system store.
+func VerifyChain(dataDir string, p *Proof, roots *x509.CertPool) error {
+	der, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(p.File)))
+	if err != nil {
+		return err
+	}
+	tok, err := ParseResponse(der)
+	if err != nil {
+		return err
+	}
+	return tok.VerifyChain(roots)
+}
+
+func versionDigest(version string) ([]byte, error) {
+	digest, err := hex.DecodeString(strings.TrimPrefix(version, "sha256:"))
+	if err != nil || len(digest) != 32 || !strings.HasPrefix(version, "sha256:") {
+		return nil, fmt.Errorf("post has no valid version hash (%q)", version)
+	}
+	return digest, nil
+}
+
+func inHistory(history []string, version string) bool {
+	for _, h := range history {
+		if strings.HasPrefix(h, version) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package timestamp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+// testTSA is a time-stamping authority with a self-signed certificate.
+type testTSA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	now  time.Time
+}
+
+func newTestTSA(t *testing.T) *testTSA {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test TSA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testTSA{key: key, cert: cert, now: time.Now().UTC().Truncate(time.Second)}
+}
+
+// respond builds a granted TimeStampResp for req.
+func (tsa *testTSA) respond(t *testing.T, req timeStampReq) []byte {
+	t.Helper()
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(42),
+		GenTime:        tsa.now,
+		Nonce:          req.Nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(info)
+	attr := func(oid asn1.ObjectIdentifier, v interface{}) attribute {
+		b, _ := asn1.Marshal(v)
+		return attribute{Type: oid, Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: b}}
+	}
+	attrs, _ := asn1.MarshalWithParams([]attribute{
+		attr(oidContentType, oidTSTInfo),
+		attr(oidMsgDigest, digest[:]),
+	}, "set")
+	h := sha256.Sum256(attrs)
+	sig, _ := tsa.key.Sign(rand.Reader, h[:], crypto.SHA256)
+
+	eContent, _ := asn1.Marshal(info)
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+	sd, err := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(sha256Alg)},
+		EncapContentInfo: encapsulatedContentInfo{
+			EContentType: oidTSTInfo,
+			EContent:     asn1.RawValue{FullBytes: append(explicit0(len(eContent)), eContent...)},
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: tsa.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: mustMarshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true})},
+			DigestAlgorithm:    sha256Alg,
+			SignedAttrs:        asn1.RawValue{FullBytes: append([]byte{0xa0}, attrs[1:]...)},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          sig,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := asn1.Marshal(contentInfo{ContentType: oidSignedData, Content: asn1.RawValue{FullBytes: append(explicit0(len(sd)), sd...)}})
+	resp, _ := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, Token: asn1.RawValue{FullBytes: token}})
+	return resp
+}
+
+// explicit0 returns the header of an explicit [0] tag around n bytes.
+func explicit0(n int) []byte {
+	switch {
+	case n < 0x80:
+		return []byte{0xa0, byte(n)}
+	case n < 0x100:
+		return []byte{0xa0, 0x81, byte(n)}
+	}
+	return []byte{0xa0, 0x82, byte(n >> 8), byte(n)}
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (tsa *testTSA) server(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/timestamp-query" {
+			http.Error(w, "bad content type", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests++
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(tsa.respond(t, req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAnchorAndVerify(t *testing.T) {
+	tsa := newTestTSA(t)
+	var requests int
+	srv := tsa.server(t, &requests)
+	dataDir := t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+
+	result, err := publish.PublishPostWithOptions(dataDir, "# Hello\n\nFirst version.\n", publish.PublishOptions{Slug: "hello"}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := Anchor(dataDir, srv.URL, result.Path)
+	if err != nil {
+		t.Fatalf("Anchor failed: %v", err)
+	}
+	if !proof.Time.Equal(tsa.now) || proof.TSA != "CN=Test TSA" || proof.Trusted {
+		t.Errorf("proof = %+v", proof)
+	}
+	if want := ".polis/proofs/" + strings.TrimSuffix(result.Path, ".md") + "/" + strings.TrimPrefix(result.Version, "sha256:") + ".tsr"; proof.File != want {
+		t.Errorf("proof file = %s, want %s", proof.File, want)
+	}
+	if _, err := Anchor(dataDir, srv.URL, result.Path); err != nil || requests != 1 {
+		t.Errorf("re-anchor: err = %v, %d requests", err, requests)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tsa.cert)
+	if err := VerifyChain(dataDir, proof, roots); err != nil {
+		t.Errorf("VerifyChain with the TSA root: %v", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	second, err := publish.RepublishPost(dataDir, result.Path, "# Hello\n\nSecond version.\n", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsa.now = tsa.now.Add(time.Minute)
+	if _, err := Anchor(dataDir, srv.URL, result.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	proofs, err := Verify(dataDir, result.Path)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(proofs) != 2 || proofs[0].Version != result.Version || proofs[1].Version != second.Version {
+		t.Fatalf("proofs = %+v", proofs)
+	}
+	for _, p := range proofs {
+		if p.Error != "" {
+			t.Errorf("%s: %s", p.Version, p.Error)
+		}
+	}
+	if proofs[0].Current || !proofs[1].Current {
+		t.Errorf("current flags = %v, %v", proofs[0].Current, proofs[1].Current)
+	}
+
+	// A proof copied under another version's name is caught
+	data, _ := os.ReadFile(filepath.Join(dataDir, proofs[0].File))
+	os.WriteFile(filepath.Join(dataDir, ProofFile(result.Path, "sha256:"+strings.Repeat("ab", 32))), data, 0644)
+	proofs, _ = Verify(dataDir, result.Path)
+	bad := 0
+	for _, p := range proofs {
+		if p.Error != "" {
+			bad++
+		}
+	}
+	if bad != 1 {
+		t.Errorf("%d proofs failed, want 1: %+v", bad, proofs)
+	}
+
+	// Edited content no longer matches its version
+	path := filepath.Join(dataDir, result.Path)
+	content, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(content), "Second", "Forged", 1)), 0644)
+	if _, err := Verify(dataDir, result.Path); err == nil {
+		t.Error("edited post verifies")
+	}
+}
+
+func TestParseResponse_Rejected(t *testing.T) {
+	resp, _ := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{
+		Status:       2,
+		StatusString: []asn1.RawValue{{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte("bad digest")}},
+	}})
+	if _, err := ParseResponse(resp); err == nil || !strings.Contains(err.Error(), "bad digest") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+// Package trash keeps deleted drafts and unpublished posts in .polis/trash
+// so they can be restored until the retention period runs out.
+//
+// Each trashed item is a directory .polis/trash/<id>/ holding item.json and
+// the item's files under files/, at the same relative paths they had in the
+// site. An unpublished post takes its rendered HTML and version history
+// with it, and its public.jsonl (or, for an unlisted post, unlisted.jsonl)
+// entry is kept in item.json so Restore can put the post back exactly as it
+// was.
+package trash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+// DefaultRetentionDays is how long trashed items are kept when the
+// trash_retention_days setting is not set.
+const DefaultRetentionDays = 30
+
+// Item kinds.
+const (
+	KindDraft        = "draft"         // .polis/posts/drafts/*.md
+	KindCommentDraft = "comment-draft" // .polis/comments/drafts/*.md
+	KindPost         = "post"          // posts/**/*.md (unpublished)
+)
+
+var (
+	// ErrNotFound is returned for an unknown trash ID.
+	ErrNotFound = errors.New("not in trash")
+	// ErrExists is returned by Restore when something already occupies
+	// the item's original path.
+	ErrExists = errors.New("original path already exists")
+	// ErrUnsupported is returned for paths that can't be trashed.
+	ErrUnsupported = errors.New("only drafts, comment drafts, and posts can be moved to trash")
+)
+
+// Item describes a trashed draft or post.
+type Item struct {
+	ID        string               `json:"id"`
+	Kind      string               `json:"kind"`
+	Path      string               `json:"path"` // Original path relative to the site, slash-separated
+	Title     string               `json:"title,omitempty"`
+	DeletedAt string               `json:"deleted_at"`
+	Files     []string             `json:"files"`              // All moved files (Path first)
+	Index     *metadata.IndexEntry `json:"index,omitempty"`    // public.jsonl entry of an unpublished post
+	Unlisted  bool                 `json:"unlisted,omitempty"` // Index came from .polis/unlisted.jsonl
+}
+
+// Dir returns the trash directory (.polis/trash).
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", "trash")
+}
+
+// KindOf returns the kind of item at relPath, or "" if it can't be trashed.
+func KindOf(relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	if !strings.HasSuffix(relPath, ".md") || strings.Contains(relPath, "..") {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(relPath, ".polis/posts/drafts/"):
+		return KindDraft
+	case strings.HasPrefix(relPath, ".polis/comments/drafts/"):
+		return KindCommentDraft
+	case strings.HasPrefix(relPath, "posts/") && !strings.Contains(relPath, "/.versions/"):
+		return KindPost
+	}
+	return ""
+}
+
+// Move moves the draft or post at relPath into the trash. A post is
+// unpublished: its rendered .html and version history move with it and its
+// entry is removed from public.jsonl. The site still has to be re-rendered
+// for index pages and feeds to drop it.
+func Move(dataDir, relPath string) (*Item, error) {
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	kind := KindOf(relPath)
+	if kind == "" {
+		return nil, ErrUnsupported
+	}
+	src := filepath.Join(dataDir, filepath.FromSlash(relPath))
+	content, err := os.ReadFile(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", relPath, os.ErrNotExist)
+		}
+		return nil, err
+	}
+
+	item := &Item{
+		ID:        newID(),
+		Kind:      kind,
+		Path:      relPath,
+		Title:     titleOf(string(content)),
+		DeletedAt: time.Now().UTC().Format(time.RFC3339),
+		Files:     []string{relPath},
+	}
+	if kind == KindPost {
+		dir, name := path.Split(relPath)
+		for _, extra := range []string{
+			strings.TrimSuffix(relPath, ".md") + ".html",
+			dir + ".versions/" + name,
+		} {
+			if _, err := os.Stat(filepath.Join(dataDir, filepath.FromSlash(extra))); err == nil {
+				item.Files = append(item.Files, extra)
+			}
+		}
+		entries, err := metadata.LoadPublicIndex(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		item.Index = findEntry(entries, relPath)
+		if item.Index == nil {
+			unlisted, err := metadata.LoadUnlistedIndex(dataDir)
+			if err != nil {
+				return nil, err
+			}
+			item.Index = findEntry(unlisted, relPath)
+			item.Unlisted = item.Index != nil
+		}
+	}
+
+	itemDir := filepath.Join(Dir(dataDir), item.ID)
+	for _, f := range item.Files {
+		dst := filepath.Join(itemDir, "files", filepath.FromSlash(f))
+		if err := moveFile(filepath.Join(dataDir, filepath.FromSlash(f)), dst); err != nil {
+			return nil, fmt.Errorf("failed to move %s to trash: %w", f, err)
+		}
+	}
+	if err := writeItem(itemDir, item); err != nil {
+		return nil, err
+	}
+	if item.Unlisted {
+		if err := metadata.RemoveUnlistedEntry(dataDir, relPath); err != nil {
+			return item, fmt.Errorf("moved to trash but failed to update unlisted.jsonl: %w", err)
+		}
+	} else if item.Index != nil {
+		if err := metadata.RemoveIndexEntry(dataDir, relPath); err != nil {
+			return item, fmt.Errorf("moved to trash but failed to update public.jsonl: %w", err)
+		}
+	}
+	return item, nil
+}
+
+// findEntry returns the entry at relPath, or nil.
+func findEntry(entries []metadata.IndexEntry, relPath string) *metadata.IndexEntry {
+	for i := range entries {
+		if entries[i].Path == relPath {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// List returns trashed items, most recently deleted first.
+func List(dataDir string) ([]Item, error) {
+	entries, err := os.ReadDir(Dir(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Item{}, nil
+		}
+		return nil, err
+	}
+	items := []Item{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		item, err := readItem(filepath.Join(Dir(dataDir), e.Name()))
+		if err != nil {
+			continue
+		}
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt > items[j].DeletedAt })
+	return items, nil
+}
+
+// Get returns the trashed item with the given ID.
+func Get(dataDir, id string) (*Item, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	item, err := readItem(filepath.Join(Dir(dataDir), id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Restore moves a trashed item back to its original path. A restored post
+// gets its public.jsonl entry back; the site still has to be re-rendered.
+func Restore(dataDir, id string) (*Item, error) {
+	item, err := Get(dataDir, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range item.Files {
+		if _, err := os.Stat(filepath.Join(dataDir, filepath.FromSlash(f))); err == nil {
+			return nil, fmt.Errorf("%s: %w", f, ErrExists)
+		}
+	}
+
+	itemDir := filepath.Join(Dir(dataDir), item.ID)
+	for _, f := range item.Files {
+		src := filepath.Join(itemDir, "files", filepath.FromSlash(f))
+		if err := moveFile(src, filepath.Join(dataDir, filepath.FromSlash(f))); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", f, err)
+		}
+	}
+	if item.Unlisted {
+		if err := metadata.AppendToUnlistedIndex(dataDir, item.Index); err != nil {
+			return item, fmt.Errorf("restored but failed to update unlisted.jsonl: %w", err)
+		}
+	} else if item.Index != nil {
+		if err := metadata.AppendToPublicIndex(dataDir, item.Index); err != nil {
+			return item, fmt.Errorf("restored but failed to update public.jsonl: %w", err)
+		}
+	}
+	return item, os.RemoveAll(itemDir)
+}
+
+// Purge permanently deletes a trashed item.
+func Purge(dataDir, id string) error {
+	if _, err := Get(dataDir, id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(Dir(dataDir), id))
+}
+
+// PurgeExpired permanently deletes items trashed more than retentionDays
+// ago (retentionDays <= 0 uses DefaultRetentionDays). Returns the number
+// purged.
+func PurgeExpired(dataDir string, retentionDays int) (int, error) {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+	items, err := List(dataDir)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-time.Duration(retentionDays) * 24 * time.Hour)
+	purged := 0
+	for _, item := range items {
+		deleted, err := time.Parse(time.RFC3339, item.DeletedAt)
+		if err != nil || deleted.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(Dir(dataDir), item.ID)); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// ExpiresAt returns when item will be purged under retentionDays.
+func (item *Item) ExpiresAt(retentionDays int) time.Time {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+	deleted, _ := time.Parse(time.RFC3339, item.DeletedAt)
+	return deleted.Add(time.Duration(retentionDays) * 24 * time.Hour)
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && id != "." && id != ".."
+}
+
+func readItem(itemDir string) (*Item, error) {
+	data, err := os.ReadFile(filepath.Join(itemDir, "item.json"))
+	if err != nil {
+		return nil, err
+	}
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse trash item: %w", err)
+	}
+	return &item, nil
+}
+
+func writeItem(itemDir string, item *Item) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(filepath.Join(itemDir, "item.json"), append(data, '\n'), 0644)
+}
+
+// moveFile renames src to dst, copying across filesystems if needed.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// titleOf returns the frontmatter title, or the first heading.
+func titleOf(content string) string {
+	body := content
+	if strings.HasPrefix(content, "---\n") {
+		if end := strings.Index(content[4:], "\n---"); end >= 0 {
+			for _, line := range strings.Split(content[4:4+end], "\n") {
+				if v, ok := strings.CutPrefix(line, "title:"); ok {
+					return strings.Trim(strings.TrimSpace(v), `"'`)
+				}
+			}
+			body = content[4+end+4:]
+		}
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if v, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+func writeFile(t *testing.T, dataDir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dataDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(dataDir, rel string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, filepath.FromSlash(rel)))
+	return err == nil
+}
+
+func TestMoveAndRestoreDraft(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, ".polis/posts/drafts/idea.md", "# My Idea\n\nBody\n")
+
+	item, err := Move(dataDir, ".polis/posts/drafts/idea.md")
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if item.Kind != KindDraft || item.Title != "My Idea" {
+		t.Errorf("item = %+v", item)
+	}
+	if exists(dataDir, ".polis/posts/drafts/idea.md") {
+		t.Error("draft still at original path")
+	}
+
+	items, err := List(dataDir)
+	if err != nil || len(items) != 1 || items[0].ID != item.ID {
+		t.Fatalf("List = %v, %v", items, err)
+	}
+
+	if _, err := Restore(dataDir, item.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !exists(dataDir, ".polis/posts/drafts/idea.md") {
+		t.Error("draft not restored")
+	}
+	if items, _ := List(dataDir); len(items) != 0 {
+		t.Errorf("trash not empty after restore: %v", items)
+	}
+}
+
+func TestMoveAndRestorePost(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, "posts/20260101/hello.md", "---\ntitle: Hello\n---\n# Hello\n")
+	writeFile(t, dataDir, "posts/20260101/hello.html", "<h1>Hello</h1>")
+	writeFile(t, dataDir, "posts/20260101/.versions/hello.md", "history")
+	if err := metadata.AppendToPublicIndex(dataDir, &metadata.IndexEntry{
+		Type: "post", Path: "posts/20260101/hello.md", Title: "Hello", Published: "2026-01-01T00:00:00Z",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := Move(dataDir, "posts/20260101/hello.md")
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if item.Kind != KindPost || len(item.Files) != 3 || item.Index == nil {
+		t.Fatalf("item = %+v", item)
+	}
+	for _, f := range item.Files {
+		if exists(dataDir, f) {
+			t.Errorf("%s still in site", f)
+		}
+	}
+	if entries, _ := metadata.LoadPublicIndex(dataDir); len(entries) != 0 {
+		t.Errorf("public index still has %v", entries)
+	}
+
+	if _, err := Restore(dataDir, item.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for _, f := range item.Files {
+		if !exists(dataDir, f) {
+			t.Errorf("%s not restored", f)
+		}
+	}
+	entries, _ := metadata.LoadPublicIndex(dataDir)
+	if len(entries) != 1 || entries[0].Path != "posts/20260101/hello.md" {
+		t.Errorf("public index = %v", entries)
+	}
+}
+
+func TestRestoreExisting(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, ".polis/posts/drafts/idea.md", "old")
+	item, err := Move(dataDir, ".polis/posts/drafts/idea.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dataDir, ".polis/posts/drafts/idea.md", "new")
+
+	if _, err := Restore(dataDir, item.ID); !errors.Is(err, ErrExists) {
+		t.Fatalf("Restore err = %v, want ErrExists", err)
+	}
+	if _, err := Get(dataDir, item.ID); err != nil {
+		t.Errorf("item should still be in trash: %v", err)
+	}
+}
+
+func TestMoveUnsupported(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, p := range []string{"about.md", ".polis/keys/id_ed25519", "posts/../about.md", "posts/x/.versions/a.md"} {
+		if _, err := Move(dataDir, p); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Move(%q) err = %v, want ErrUnsupported", p, err)
+		}
+	}
+	if _, err := Move(dataDir, ".polis/posts/drafts/missing.md"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Move(missing) err = %v, want ErrNotExist", err)
+	}
+}
+
+func TestPurgeAndPurgeExpired(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, ".polis/posts/drafts/a.md", "a")
+	writeFile(t, dataDir, ".polis/comments/drafts/b.md", "b")
+	a, err := Move(dataDir, ".polis/posts/drafts/a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Move(dataDir, ".polis/comments/drafts/b.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Backdate a past the retention period
+	a.DeletedAt = time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	if err := writeItem(filepath.Join(Dir(dataDir), a.ID), a); err != nil {
+		t.Fatal(err)
+	}
+	n, err := PurgeExpired(dataDir, 7)
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %d, %v; want 1", n, err)
+	}
+	if _, err := Get(dataDir, a.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired item still present: %v", err)
+	}
+
+	if err := Purge(dataDir, b.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if err := Purge(dataDir, b.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Purge err = %v, want ErrNotFound", err)
+	}
+	if err := Purge(dataDir, "../keys"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Purge(../keys) err = %v, want ErrNotFound", err)
+	}
+}
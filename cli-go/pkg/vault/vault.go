@@ -0,0 +1,528 @@
+// Package vault publishes a folder of markdown notes, such as an Obsidian
+// vault, kept outside the site.
+//
+// The folder stays the source of truth. Each note is tied to its post by a
+// polis_id field written into the note's frontmatter on first publish, so
+// renaming or moving a note inside the vault doesn't create a second post.
+// .polis/vault.json records, per ID, the post and a hash of what was last
+// published; a sync publishes new notes, republishes changed ones, and
+// leaves the rest alone.
+//
+// Notes are translated on the way in: Obsidian [[Note Name]] links become
+// [[slug]] wiki links to the note's post, and embedded or linked
+// attachments (![[image.png]], ![alt](image.png)) are copied into the
+// site's attachments/ directory.
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/wikilink"
+)
+
+// IDField is the frontmatter field holding a note's stable ID.
+const IDField = "polis_id"
+
+// StateFilename is the sync state file, relative to .polis/.
+const StateFilename = "vault.json"
+
+// Note is the recorded state of a published note.
+type Note struct {
+	Source string `json:"source"` // Path inside the vault, slash-separated
+	Post   string `json:"post"`   // Post path, e.g. posts/20260125/hello.md
+	Hash   string `json:"hash"`   // SHA-256 of the markdown last published
+}
+
+// State maps note IDs to their posts.
+type State struct {
+	Notes map[string]Note `json:"notes"`
+}
+
+// StatePath returns the path to .polis/vault.json.
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", StateFilename)
+}
+
+// LoadState reads the sync state; a missing file is an empty state.
+func LoadState(dataDir string) (*State, error) {
+	st := &State{Notes: make(map[string]Note)}
+	data, err := os.ReadFile(StatePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StateFilename, err)
+	}
+	if st.Notes == nil {
+		st.Notes = make(map[string]Note)
+	}
+	return st, nil
+}
+
+// Save writes the sync state.
+func (st *State) Save(dataDir string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(StatePath(dataDir), append(data, '\n'), 0644)
+}
+
+// Options configures a sync.
+type Options struct {
+	DryRun bool // Report what would change without writing anything
+
+	// Unlisted and FollowersOnly apply to notes published for the first
+	// time, on top of their frontmatter.
+	Unlisted      bool
+	FollowersOnly bool
+}
+
+// Change is one note acted on (or that would be, in a dry run).
+type Change struct {
+	Source string `json:"source"`
+	Post   string `json:"post,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result summarizes a sync.
+type Result struct {
+	Published   []Change `json:"published"`   // New notes
+	Republished []Change `json:"republished"` // Changed notes
+	Unchanged   int      `json:"unchanged"`
+	Skipped     []string `json:"skipped"`     // Notes with publish: false
+	Missing     []Change `json:"missing"`     // Recorded notes no longer in the vault; their posts are kept
+	Attachments []string `json:"attachments"` // Site paths copied this run
+	Failed      []Change `json:"failed"`
+	DryRun      bool     `json:"dry_run"`
+}
+
+// note is a markdown file found in the vault.
+type note struct {
+	rel     string // Slash-separated path inside the vault
+	name    string // Filename without .md
+	content string
+	id      string
+}
+
+// Sync publishes new and changed notes from vaultDir to the site.
+func Sync(dataDir, vaultDir string, opts Options, privateKey []byte) (*Result, error) {
+	info, err := os.Stat(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", vaultDir)
+	}
+	st, err := LoadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	notes, files, err := scan(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{DryRun: opts.DryRun}
+
+	// Give notes without an ID one now, so links to them can be resolved
+	// on the first run
+	for _, n := range notes {
+		if n.id != "" {
+			continue
+		}
+		n.id = idgen.New()
+		if opts.DryRun {
+			continue
+		}
+		n.content = setID(n.content, n.id)
+		if err := fsutil.WriteFile(filepath.Join(vaultDir, filepath.FromSlash(n.rel)), []byte(n.content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s to %s: %w", IDField, n.rel, err)
+		}
+	}
+
+	tr := &translator{
+		dataDir: dataDir,
+		vault:   vaultDir,
+		files:   files,
+		targets: make(map[string]string),
+		dryRun:  opts.DryRun,
+	}
+	seen := make(map[string]bool)
+	for _, n := range notes {
+		if seen[n.id] {
+			result.Failed = append(result.Failed, Change{Source: n.rel, Error: "duplicate " + IDField + " " + n.id})
+			continue
+		}
+		seen[n.id] = true
+		tr.targets[strings.ToLower(n.name)] = linkTarget(st.Notes[n.id], n)
+		tr.targets[strings.ToLower(strings.TrimSuffix(n.rel, ".md"))] = tr.targets[strings.ToLower(n.name)]
+	}
+
+	seen = make(map[string]bool)
+	for _, n := range notes {
+		if seen[n.id] {
+			continue
+		}
+		seen[n.id] = true
+		if strings.EqualFold(publish.ParseFrontmatter(n.content)["publish"], "false") {
+			result.Skipped = append(result.Skipped, n.rel)
+			continue
+		}
+
+		markdown, err := tr.translate(n)
+		if err != nil {
+			result.Failed = append(result.Failed, Change{Source: n.rel, Error: err.Error()})
+			continue
+		}
+		hash := hashString(markdown)
+		rec, known := st.Notes[n.id]
+		if known && rec.Hash == hash {
+			if rec.Source != n.rel && !opts.DryRun {
+				rec.Source = n.rel
+				st.Notes[n.id] = rec
+			}
+			result.Unchanged++
+			continue
+		}
+
+		change := Change{Source: n.rel, Post: rec.Post, Title: publish.ExtractTitle(publish.StripFrontmatter(markdown))}
+		if opts.DryRun {
+			if known {
+				result.Republished = append(result.Republished, change)
+			} else {
+				result.Published = append(result.Published, change)
+			}
+			continue
+		}
+
+		var res *publish.PublishResult
+		if known {
+			res, err = publish.RepublishPost(dataDir, rec.Post, publish.StripFrontmatter(markdown), privateKey)
+		} else {
+			po := publish.SourceOptions(markdown)
+			if po.Slug == "" {
+				po.Slug = publish.Slugify(n.name)
+			}
+			po.Unlisted = po.Unlisted || opts.Unlisted
+			po.FollowersOnly = po.FollowersOnly || opts.FollowersOnly
+			res, err = publish.PublishPostWithOptions(dataDir, publish.StripFrontmatter(markdown), po, privateKey)
+		}
+		if err != nil {
+			change.Error = err.Error()
+			result.Failed = append(result.Failed, change)
+			continue
+		}
+		change.Post = res.Path
+		change.Title = res.Title
+		st.Notes[n.id] = Note{Source: n.rel, Post: res.Path, Hash: hash}
+		if known {
+			result.Republished = append(result.Republished, change)
+		} else {
+			result.Published = append(result.Published, change)
+		}
+	}
+
+	for id, rec := range st.Notes {
+		if !seen[id] {
+			result.Missing = append(result.Missing, Change{Source: rec.Source, Post: rec.Post})
+		}
+	}
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i].Source < result.Missing[j].Source })
+	result.Attachments = tr.copied
+
+	if !opts.DryRun {
+		if err := st.Save(dataDir); err != nil {
+			return result, fmt.Errorf("failed to save %s: %w", StateFilename, err)
+		}
+	}
+	return result, nil
+}
+
+// scan lists the notes in a vault, sorted by path, and indexes the other
+// files by lowercase name for attachment lookup. Hidden directories such
+// as .obsidian and .trash are skipped.
+func scan(vaultDir string) ([]*note, map[string][]string, error) {
+	var notes []*note
+	files := make(map[string][]string)
+	err := filepath.WalkDir(vaultDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != vaultDir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(vaultDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.EqualFold(path.Ext(rel), ".md") {
+			key := strings.ToLower(d.Name())
+			files[key] = append(files[key], rel)
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		content := strings.ReplaceAll(string(data), "\r\n", "\n")
+		notes = append(notes, &note{
+			rel:     rel,
+			name:    strings.TrimSuffix(d.Name(), path.Ext(d.Name())),
+			content: content,
+			id:      publish.ParseFrontmatter(content)[IDField],
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].rel < notes[j].rel })
+	return notes, files, nil
+}
+
+// setID adds the polis_id field to a note's frontmatter, creating the
+// frontmatter if the note has none.
+func setID(content, id string) string {
+	line := IDField + ": " + id + "\n"
+	if fm, body := splitFrontmatter(content); fm != "" {
+		return "---\n" + line + strings.TrimPrefix(strings.TrimLeft(fm, " \t\n"), "---\n") + body
+	}
+	return "---\n" + line + "---\n" + content
+}
+
+// splitFrontmatter splits a note into its frontmatter block (with the
+// closing ---) and body. fm is empty when the note has none.
+func splitFrontmatter(content string) (fm, body string) {
+	loc := frontmatterPattern.FindStringIndex(content)
+	if loc == nil {
+		return "", content
+	}
+	return content[:loc[1]], content[loc[1]:]
+}
+
+// linkTarget is the wiki link target for a note: its post's slug once
+// published, otherwise the slug it will be published under. Both are the
+// same unless the slug was taken, so publishing a note doesn't change the
+// notes that link to it.
+func linkTarget(rec Note, n *note) string {
+	if rec.Post != "" {
+		return strings.TrimSuffix(path.Base(rec.Post), ".md")
+	}
+	if slug := publish.FrontmatterSlug(n.content); slug != "" {
+		return slug
+	}
+	return publish.Slugify(n.name)
+}
+
+// translator rewrites Obsidian syntax into polis markdown.
+type translator struct {
+	dataDir string
+	vault   string
+	files   map[string][]string // Lowercase filename -> vault paths
+	targets map[string]string   // Lowercase note name or path -> link target
+	dryRun  bool
+	copied  []string
+}
+
+var (
+	embedPattern = regexp.MustCompile(`!\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]*))?\]\]`)
+	imagePattern = regexp.MustCompile(`!\[([^\]\n]*)\]\(([^)\s]+)(\s+"[^"\n]*")?\)`)
+
+	frontmatterPattern = regexp.MustCompile(`^\s*---\n(?s:.*?)\n---\n`)
+	idLinePattern      = regexp.MustCompile(`(?m)^` + IDField + `:.*\n`)
+)
+
+// translate returns the note's markdown as it will be published. The
+// polis_id field is dropped; other frontmatter is kept for the publish
+// options it carries.
+func (tr *translator) translate(n *note) (string, error) {
+	fm, body := splitFrontmatter(n.content)
+	fm = idLinePattern.ReplaceAllString(fm, "")
+	if strings.TrimSpace(strings.Trim(strings.TrimSpace(fm), "-")) == "" {
+		fm = ""
+	}
+
+	var firstErr error
+	body = replaceOutsideCode(body, embedPattern, func(m []string) string {
+		target := strings.TrimSpace(m[1])
+		if strings.EqualFold(path.Ext(target), ".md") || path.Ext(target) == "" {
+			// Transcluded note: link to it instead
+			return "[[" + tr.noteTarget(target) + "]]"
+		}
+		site, err := tr.attachment(target, "")
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return m[0]
+		}
+		alt := strings.TrimSuffix(path.Base(target), path.Ext(target))
+		return "![" + alt + "](" + site + ")"
+	})
+	body = replaceOutsideCode(body, imagePattern, func(m []string) string {
+		ref := m[2]
+		if strings.Contains(ref, "://") || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "data:") {
+			return m[0]
+		}
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		site, err := tr.attachment(ref, path.Dir(n.rel))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return m[0]
+		}
+		return "![" + m[1] + "](" + site + m[3] + ")"
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	// [[Note Name#Heading|label]] -> [[slug|label]]; polis links have no
+	// heading anchors
+	var b strings.Builder
+	last := 0
+	for _, l := range wikilink.Find(body) {
+		b.WriteString(body[last:l.Start])
+		label := l.Label
+		target := l.Target
+		if name, heading, ok := strings.Cut(target, "#"); ok {
+			target = strings.TrimSpace(name)
+			if label == "" && target != "" {
+				label = l.Target
+			}
+			if target == "" {
+				// Link to a heading in the same note
+				b.WriteString(heading)
+				last = l.End
+				continue
+			}
+		}
+		resolved := tr.noteTarget(target)
+		if label == "" && resolved != target {
+			label = target
+		}
+		b.WriteString("[[" + resolved)
+		if label != "" {
+			b.WriteString("|" + label)
+		}
+		b.WriteString("]]")
+		last = l.End
+	}
+	b.WriteString(body[last:])
+	body = b.String()
+
+	// Obsidian titles are filenames; give the post a heading when the note
+	// has none
+	if !strings.HasPrefix(strings.TrimSpace(body), "# ") {
+		body = "# " + n.name + "\n\n" + strings.TrimLeft(body, "\n")
+	}
+	return fm + body, nil
+}
+
+// noteTarget resolves a note name or vault path to its link target. Names
+// that match no note are returned unchanged.
+func (tr *translator) noteTarget(name string) string {
+	key := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), ".md"))
+	if t, ok := tr.targets[key]; ok {
+		return t
+	}
+	return name
+}
+
+// attachment copies a vault file into the site and returns its site path.
+// ref is looked up relative to dir, then the vault root, then by filename
+// anywhere in the vault, as Obsidian does.
+func (tr *translator) attachment(ref, dir string) (string, error) {
+	var src string
+	for _, cand := range []string{path.Join(dir, ref), path.Clean(ref)} {
+		if strings.HasPrefix(cand, "../") || cand == ".." {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(tr.vault, filepath.FromSlash(cand))); err == nil && info.Mode().IsRegular() {
+			src = cand
+			break
+		}
+	}
+	if src == "" {
+		if matches := tr.files[strings.ToLower(path.Base(ref))]; len(matches) > 0 {
+			src = matches[0]
+		}
+	}
+	if src == "" {
+		return "", fmt.Errorf("attachment not found: %s", ref)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tr.vault, filepath.FromSlash(src)))
+	if err != nil {
+		return "", err
+	}
+	if tr.dryRun {
+		rel := publish.AttachmentsDir + "/" + publish.AttachmentName(src, data)
+		if _, err := os.Stat(filepath.Join(tr.dataDir, filepath.FromSlash(rel))); err != nil {
+			tr.copied = append(tr.copied, rel)
+		}
+		return "/" + rel, nil
+	}
+	rel, written, err := publish.SaveAttachment(tr.dataDir, src, data)
+	if err != nil {
+		return "", err
+	}
+	if written {
+		tr.copied = append(tr.copied, rel)
+	}
+	return "/" + rel, nil
+}
+
+// replaceOutsideCode replaces matches of re that aren't inside code.
+func replaceOutsideCode(s string, re *regexp.Regexp, fn func(m []string) string) string {
+	masked := wikilink.MaskCode(s)
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(masked, -1) {
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = s[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(fn(m))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
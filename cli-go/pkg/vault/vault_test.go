@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+)
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSync_PublishesThenRepublishesChangedNotes(t *testing.T) {
+	dataDir, vaultDir := t.TempDir(), t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeFile(t, vaultDir, "Garden/First Note.md", "Links to [[Second Note]] and [[Second Note#Part|part two]].\n\n![[diagram.png]]\n")
+	writeFile(t, vaultDir, "Second Note.md", "---\nslug: second\n---\n# Second\n\n`[[not a link]]`\n")
+	writeFile(t, vaultDir, "Private.md", "---\npublish: false\n---\nSecret\n")
+	writeFile(t, vaultDir, "files/diagram.png", "PNG")
+	writeFile(t, vaultDir, ".obsidian/app.md", "ignored")
+
+	result, err := Sync(dataDir, vaultDir, Options{}, privKey)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Published) != 2 || len(result.Failed) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "Private.md" {
+		t.Errorf("skipped = %v", result.Skipped)
+	}
+	if len(result.Attachments) != 1 || !strings.HasPrefix(result.Attachments[0], "attachments/diagram-") {
+		t.Errorf("attachments = %v", result.Attachments)
+	}
+
+	// The note keeps its ID in frontmatter
+	first := readFile(t, filepath.Join(vaultDir, "Garden", "First Note.md"))
+	if !strings.HasPrefix(first, "---\n"+IDField+": ") {
+		t.Errorf("note frontmatter = %q", first)
+	}
+
+	var firstPost string
+	for _, c := range result.Published {
+		if c.Source == "Garden/First Note.md" {
+			firstPost = c.Post
+		}
+	}
+	post := readFile(t, filepath.Join(dataDir, firstPost))
+	for _, want := range []string{"# First Note", "[[second|Second Note]]", "[[second|part two]]", "](/attachments/diagram-"} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post missing %q:\n%s", want, post)
+		}
+	}
+	if strings.Contains(post, IDField) {
+		t.Errorf("post should not carry %s:\n%s", IDField, post)
+	}
+
+	// Nothing changed: nothing to do
+	result, err = Sync(dataDir, vaultDir, Options{}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Unchanged != 2 || len(result.Published)+len(result.Republished) != 0 {
+		t.Errorf("second sync = %+v", result)
+	}
+
+	// A renamed, edited note republishes the same post
+	os.Remove(filepath.Join(vaultDir, "Garden", "First Note.md"))
+	writeFile(t, vaultDir, "Renamed.md", strings.Replace(first, "Links to", "Now links to", 1))
+	result, err = Sync(dataDir, vaultDir, Options{}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Republished) != 1 || result.Republished[0].Post != firstPost || len(result.Missing) != 0 {
+		t.Fatalf("third sync = %+v", result)
+	}
+	if !strings.Contains(readFile(t, filepath.Join(dataDir, firstPost)), "Now links to") {
+		t.Error("post not republished")
+	}
+}
+
+func TestSync_DryRunWritesNothing(t *testing.T) {
+	dataDir, vaultDir := t.TempDir(), t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeFile(t, vaultDir, "Note.md", "Hello ![](img/a.png)\n")
+	writeFile(t, vaultDir, "img/a.png", "PNG")
+
+	result, err := Sync(dataDir, vaultDir, Options{DryRun: true}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Published) != 1 || len(result.Attachments) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if readFile(t, filepath.Join(vaultDir, "Note.md")) != "Hello ![](img/a.png)\n" {
+		t.Error("dry run modified the note")
+	}
+	if _, err := os.Stat(StatePath(dataDir)); !os.IsNotExist(err) {
+		t.Error("dry run wrote state")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, publish.AttachmentsDir)); !os.IsNotExist(err) {
+		t.Error("dry run copied attachments")
+	}
+}
+
+func TestSync_MissingAttachmentFailsNote(t *testing.T) {
+	dataDir, vaultDir := t.TempDir(), t.TempDir()
+	privKey, _, _ := signing.GenerateKeypair()
+	writeFile(t, vaultDir, "Note.md", "![[gone.png]]\n")
+
+	result, err := Sync(dataDir, vaultDir, Options{}, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Failed) != 1 || !strings.Contains(result.Failed[0].Error, "gone.png") {
+		t.Errorf("failed = %+v", result.Failed)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "posts")); !os.IsNotExist(err) {
+		t.Error("note with a missing attachment should not be published")
+	}
+}
+
+func TestSetID(t *testing.T) {
+	if got := setID("# Hi\n", "X"); got != "---\npolis_id: X\n---\n# Hi\n" {
+		t.Errorf("no frontmatter: %q", got)
+	}
+	got := setID("---\ntags: a\n---\n# Hi\n", "X")
+	if got != "---\npolis_id: X\ntags: a\n---\n# Hi\n" {
+		t.Errorf("with frontmatter: %q", got)
+	}
+	if publish.ParseFrontmatter(got)[IDField] != "X" {
+		t.Error("ID not readable")
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/delegation"
+	"github.com/vdibart/polis-cli/cli-go/pkg/dnskey"
 	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 )
@@ -28,8 +32,11 @@ type VerificationResult struct {
 	Generator        string      `json:"generator,omitempty"`
 	InReplyTo        string      `json:"in_reply_to,omitempty"`
 	Author           string      `json:"author,omitempty"`
+	Device           string      `json:"device,omitempty"` // Delegated device key that signed the content
+	SignedBy         string      `json:"signed_by,omitempty"` // Co-author whose key signed the content
 	Signature        SignatureResult `json:"signature"`
 	Hash             HashResult      `json:"hash"`
+	DNSKey           dnskey.Result   `json:"dns_key"`
 	ValidationIssues []string        `json:"validation_issues,omitempty"`
 	Body             string          `json:"body"`
 }
@@ -50,7 +57,10 @@ type Frontmatter struct {
 	Title          string
 	Type           string
 	Published      string
+	Updated        string
 	CurrentVersion string
+	Delegation     string
+	SignedBy       string
 	Signature      string
 	Generator      string
 	InReplyTo      string
@@ -66,7 +76,22 @@ func VerifyContent(contentURL string) (*VerificationResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch content: %w", err)
 	}
+	return verifyFetched(client, contentURL, content)
+}
+
+// VerifyProtectedContent is VerifyContent for a followers-only post: the
+// fetch is signed as actor (the reader's domain) with privateKey.
+func VerifyProtectedContent(contentURL, actor string, privateKey []byte) (*VerificationResult, error) {
+	client := remote.NewClient()
+	content, err := client.FetchProtected(contentURL, actor, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch content: %w", err)
+	}
+	return verifyFetched(client, contentURL, content)
+}
 
+// verifyFetched verifies content fetched from contentURL.
+func verifyFetched(client *remote.Client, contentURL, content string) (*VerificationResult, error) {
 	actualURL := contentURL
 
 	// Check for frontmatter - if not found, try alternate extension
@@ -109,8 +134,74 @@ func VerifyContent(contentURL string) (*VerificationResult, error) {
 		}
 	}
 
+	// Content signed by a device key carries the site key's delegation;
+	// check the chain, then the content against the device key
+	signingKey := publicKey
+	var device string
+	var delegationErr error
+	if fm.Delegation != "" && publicKey != "" {
+		var cert *delegation.Cert
+		cert, delegationErr = checkDelegation(fm, contentType, publicKey, wk.AuthorDomain(), wk.Devices)
+		if delegationErr == nil {
+			signingKey, device = cert.Key, cert.Device
+		}
+	}
+
+	// Content signed by a co-author names them; check it against the key
+	// the site lists for them
+	var signedBy string
+	var authorErr error
+	if fm.SignedBy != "" && fm.Delegation == "" && publicKey != "" {
+		var author *coauthor.Author
+		author, authorErr = checkCoauthor(fm, wk.Authors)
+		if authorErr == nil {
+			signingKey, signedBy = author.Key, author.Name
+		}
+	}
+
 	// Verify signature
-	sigResult := verifySignature(content, publicKey, fm.Signature, authorIdentity)
+	sigResult := verifySignature(content, signingKey, fm.Signature, authorIdentity)
+	if sigResult.Status == "invalid" && fm.SignedBy == "" && fm.Delegation == "" && publicKey != "" {
+		// Content that doesn't name its author may still be signed by any
+		// author the site lists
+		for _, a := range wk.Authors {
+			if checkSigningTime(fm, a.Active) != nil {
+				continue
+			}
+			if r := verifySignature(content, a.Key, fm.Signature, authorIdentity); r.Status == "valid" {
+				sigResult, signedBy = r, a.Name
+				break
+			}
+		}
+	}
+	if delegationErr != nil {
+		sigResult = SignatureResult{
+			Status:  "invalid",
+			Message: "DEVICE KEY NOT AUTHORIZED - " + delegationErr.Error(),
+		}
+	} else if authorErr != nil {
+		sigResult = SignatureResult{
+			Status:  "invalid",
+			Message: "AUTHOR KEY NOT AUTHORIZED - " + authorErr.Error(),
+		}
+	} else if device != "" && sigResult.Status == "valid" {
+		sigResult.Message = fmt.Sprintf("Signature verified against device key %q, delegated by the author's key", device)
+	} else if signedBy != "" && sigResult.Status == "valid" {
+		sigResult.Message = fmt.Sprintf("Signature verified against the key of %q, a listed author of the site", signedBy)
+	}
+
+	// Cross-check the key against DNS; a key swapped on the web host can't
+	// vouch for content
+	var keyResult dnskey.Result
+	if publicKey != "" {
+		keyResult = dnskey.Check(baseURL, publicKey)
+		if keyResult.Mismatch() {
+			sigResult = SignatureResult{
+				Status:  "invalid",
+				Message: "PUBLIC KEY DOES NOT MATCH DNS - the author's site may be compromised",
+			}
+		}
+	}
 
 	// Verify hash
 	hashResult := verifyHash(body, fm.CurrentVersion)
@@ -132,6 +223,15 @@ func VerifyContent(contentURL string) (*VerificationResult, error) {
 	if contentType == TypeComment && fm.InReplyTo == "" {
 		issues = append(issues, "missing_in_reply_to")
 	}
+	if keyResult.Mismatch() {
+		issues = append(issues, "dns_key_mismatch")
+	}
+	if delegationErr != nil {
+		issues = append(issues, "invalid_delegation")
+	}
+	if authorErr != nil {
+		issues = append(issues, "invalid_author")
+	}
 
 	return &VerificationResult{
 		URL:              actualURL,
@@ -142,8 +242,11 @@ func VerifyContent(contentURL string) (*VerificationResult, error) {
 		Generator:        fm.Generator,
 		InReplyTo:        fm.InReplyTo,
 		Author:           authorIdentity,
+		Device:           device,
+		SignedBy:         signedBy,
 		Signature:        sigResult,
 		Hash:             hashResult,
+		DNSKey:           keyResult,
 		ValidationIssues: issues,
 		Body:             body,
 	}, nil
@@ -181,8 +284,14 @@ func parseFrontmatter(content string) (*Frontmatter, string, error) {
 					fm.Type = value
 				case "published":
 					fm.Published = value
+				case "updated":
+					fm.Updated = value
 				case "current-version":
 					fm.CurrentVersion = value
+				case delegation.Field:
+					fm.Delegation = value
+				case coauthor.Field:
+					fm.SignedBy = value
 				case "signature":
 					fm.Signature = value
 				case "generator":
@@ -218,6 +327,53 @@ func parseFrontmatter(content string) (*Frontmatter, string, error) {
 	return &fm, body, nil
 }
 
+// checkDelegation checks the delegation certificate embedded in content:
+// signed by the site key, not revoked, and granting the content's scope when
+// it was signed.
+func checkDelegation(fm *Frontmatter, contentType ContentType, siteKey, domain string, devices []delegation.Device) (*delegation.Cert, error) {
+	cert, err := delegation.Decode(fm.Delegation)
+	if err != nil {
+		return nil, err
+	}
+	scope := delegation.ScopePublish
+	if contentType == TypeComment {
+		scope = delegation.ScopeComment
+	}
+	if err := checkSigningTime(fm, func(at time.Time) error { return cert.Verify(siteKey, domain, scope, at) }); err != nil {
+		return nil, err
+	}
+	if err := delegation.CheckRevoked(devices, cert.Key); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// checkCoauthor finds the co-author content names in the site's list, and
+// checks they were an author when it was signed.
+func checkCoauthor(fm *Frontmatter, authors []coauthor.Author) (*coauthor.Author, error) {
+	author := coauthor.Find(authors, fm.SignedBy)
+	if author == nil {
+		return nil, fmt.Errorf("%q is not listed as an author of the site", fm.SignedBy)
+	}
+	if err := checkSigningTime(fm, author.Active); err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// checkSigningTime calls check with the time content was last signed.
+func checkSigningTime(fm *Frontmatter, check func(at time.Time) error) error {
+	signed := fm.Updated
+	if signed == "" {
+		signed = fm.Published
+	}
+	at, err := delegation.ParseTime(signed)
+	if err != nil {
+		return fmt.Errorf("no valid signing time (%q)", signed)
+	}
+	return check(at)
+}
+
 // verifySignature verifies the content signature against the public key.
 func verifySignature(content, publicKey, signature, authorIdentity string) SignatureResult {
 	if publicKey == "" {
@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
 )
 
 // VersionEntry represents a version in the history file.
@@ -300,7 +302,7 @@ func applyReversePatch(content, diff string) (string, error) {
 	}
 
 	// Write content to temp file
-	tempContent, err := os.CreateTemp("", "polis-content-*")
+	tempContent, err := fsutil.CreateTemp("polis-content-*")
 	if err != nil {
 		return "", err
 	}
@@ -313,7 +315,7 @@ func applyReversePatch(content, diff string) (string, error) {
 	tempContent.Close()
 
 	// Write diff to temp file
-	tempDiff, err := os.CreateTemp("", "polis-diff-*")
+	tempDiff, err := fsutil.CreateTemp("polis-diff-*")
 	if err != nil {
 		return "", err
 	}
@@ -326,7 +328,7 @@ func applyReversePatch(content, diff string) (string, error) {
 	tempDiff.Close()
 
 	// Apply reverse patch
-	tempOutput, err := os.CreateTemp("", "polis-output-*")
+	tempOutput, err := fsutil.CreateTemp("polis-output-*")
 	if err != nil {
 		return "", err
 	}
@@ -353,7 +355,7 @@ func applyForwardPatch(content, diff string) (string, error) {
 	}
 
 	// Write content to temp file
-	tempContent, err := os.CreateTemp("", "polis-content-*")
+	tempContent, err := fsutil.CreateTemp("polis-content-*")
 	if err != nil {
 		return "", err
 	}
@@ -366,7 +368,7 @@ func applyForwardPatch(content, diff string) (string, error) {
 	tempContent.Close()
 
 	// Write diff to temp file
-	tempDiff, err := os.CreateTemp("", "polis-diff-*")
+	tempDiff, err := fsutil.CreateTemp("polis-diff-*")
 	if err != nil {
 		return "", err
 	}
@@ -379,7 +381,7 @@ func applyForwardPatch(content, diff string) (string, error) {
 	tempDiff.Close()
 
 	// Apply forward patch
-	tempOutput, err := os.CreateTemp("", "polis-output-*")
+	tempOutput, err := fsutil.CreateTemp("polis-output-*")
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,306 @@
+// Package websocket is a minimal RFC 6455 implementation, used by the local
+// server's /api/ws endpoint and by the nostr relay client. It exchanges text
+// messages in both directions, answers pings, and reassembles fragments. No
+// extensions or subprotocols.
+//
+// Upgrade takes over an HTTP request on the server side; Handshake opens the
+// client side over a connection the caller has dialed (so the caller picks
+// the dialer, with its address checks, and TLS).
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opcodes.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close status codes.
+const (
+	CloseNormal      = 1000
+	CloseGoingAway   = 1001
+	CloseProtocol    = 1002
+	CloseUnsupported = 1003
+	CloseTooBig      = 1009
+)
+
+// writeTimeout bounds sending one frame.
+const writeTimeout = 10 * time.Second
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned by ReadMessage once the peer has closed.
+var ErrClosed = errors.New("websocket closed")
+
+// Conn is a WebSocket connection. Writes may come from any goroutine; reads
+// from one.
+type Conn struct {
+	conn       net.Conn
+	br         *bufio.Reader
+	client     bool // Client frames are masked, server frames never
+	maxMessage int
+	writeMu    sync.Mutex
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a Sec-WebSocket-Key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade completes the server side of the opening handshake and takes over
+// the connection. Messages longer than maxMessage are refused. On failure it
+// has already written an HTTP error.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessage int) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!headerHasToken(r.Header, "Connection", "upgrade") ||
+		!headerHasToken(r.Header, "Upgrade", "websocket") ||
+		key == "" {
+		http.Error(w, "Expected a WebSocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("not a websocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, err
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", AcceptKey(key))
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, br: brw.Reader, maxMessage: maxMessage}, nil
+}
+
+// Handshake performs the client side of the opening handshake for u over
+// conn, which the caller has dialed (and wrapped in TLS for wss://).
+// Messages longer than maxMessage are refused. conn is closed on failure.
+func Handshake(conn net.Conn, u *url.URL, maxMessage int) (*Conn, error) {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req, _ := http.NewRequest(http.MethodGet, u.String(), nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake with %s failed: %w", u.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != AcceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake with %s failed: %s", u.Host, resp.Status)
+	}
+	return &Conn{conn: conn, br: br, client: true, maxMessage: maxMessage}, nil
+}
+
+// headerHasToken reports whether a comma-separated header lists token,
+// ignoring case.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage returns the next text message, answering pings and
+// reassembling fragments on the way. It returns ErrClosed once the peer
+// closes, after replying with a close frame. Protocol errors are answered
+// with a close frame too.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	fragmented := false
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return nil, ErrClosed
+		case opBinary:
+			c.WriteClose(CloseUnsupported, "text messages only")
+			return nil, errors.New("websocket: binary message")
+		case opText:
+			if fragmented {
+				c.WriteClose(CloseProtocol, "expected continuation")
+				return nil, errors.New("websocket: interleaved message")
+			}
+		case opContinuation:
+			if !fragmented {
+				c.WriteClose(CloseProtocol, "unexpected continuation")
+				return nil, errors.New("websocket: stray continuation")
+			}
+		default:
+			c.WriteClose(CloseProtocol, "unknown opcode")
+			return nil, fmt.Errorf("websocket: opcode %#x", op)
+		}
+
+		if len(msg)+len(payload) > c.maxMessage {
+			c.WriteClose(CloseTooBig, "message too big")
+			return nil, errors.New("websocket: message too big")
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+		fragmented = true
+	}
+}
+
+// readFrame reads one frame and unmasks its payload. Frames from a client
+// must be masked, frames from a server must not be.
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	op = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	if head[0]&0x70 != 0 || masked == c.client {
+		c.WriteClose(CloseProtocol, "reserved bits set or wrong masking")
+		return false, 0, nil, errors.New("websocket: bad frame header")
+	}
+
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > uint64(c.maxMessage) || (op >= opClose && n > 125) {
+		c.WriteClose(CloseTooBig, "frame too big")
+		return false, 0, nil, errors.New("websocket: frame too big")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame sends one unfragmented frame, masked when c is a client.
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	header := []byte{0x80 | op, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if c.client {
+		header[1] |= 0x80
+		var mask [4]byte
+		rand.Read(mask[:])
+		header = append(header, mask[:]...)
+		masked := make([]byte, len(payload))
+		for i, b := range payload {
+			masked[i] = b ^ mask[i%4]
+		}
+		payload = masked
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+// WriteText sends a text message.
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Ping sends a ping; the peer's pong is consumed by ReadMessage.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// WriteClose sends a close frame with a status code and reason. The caller
+// still closes the connection.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.writeFrame(opClose, append(payload, reason...))
+}
+
+// Close closes the underlying connection without a close frame.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The example key and accept value from RFC 6455.
+func TestAcceptKey(t *testing.T) {
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("AcceptKey = %q", got)
+	}
+}
+
+// echoServer echoes each text message back, and reports the error that
+// ended the connection.
+func echoServer(t *testing.T, maxMessage int) (*httptest.Server, <-chan error) {
+	t.Helper()
+	ended := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := Upgrade(w, r, maxMessage)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			msg, err := ws.ReadMessage()
+			if err != nil {
+				ended <- err
+				return
+			}
+			ws.WriteText(msg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ended
+}
+
+func dial(t *testing.T, srv *httptest.Server) *Conn {
+	t.Helper()
+	u, _ := url.Parse(strings.Replace(srv.URL, "http", "ws", 1))
+	conn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	ws, err := Handshake(conn, u, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func TestRoundTrip(t *testing.T) {
+	srv, ended := echoServer(t, 1<<10)
+	ws := dial(t, srv)
+
+	// Pings are answered without surfacing as messages
+	if err := ws.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"hello", strings.Repeat("x", 300)} {
+		if err := ws.WriteText([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ws.ReadMessage()
+		if err != nil || string(got) != msg {
+			t.Fatalf("echo of %d bytes: got %d bytes, %v", len(msg), len(got), err)
+		}
+	}
+
+	// The server refuses a message over its limit and closes
+	ws.WriteText([]byte(strings.Repeat("x", 2<<10)))
+	if _, err := ws.ReadMessage(); !errors.Is(err, ErrClosed) {
+		t.Errorf("after an oversized message: got %v, want ErrClosed", err)
+	}
+	if err := <-ended; err == nil || !strings.Contains(err.Error(), "too big") {
+		t.Errorf("server ended with %v", err)
+	}
+}
+
+func TestUpgrade_RejectsPlainGet(t *testing.T) {
+	w := httptest.NewRecorder()
+	if _, err := Upgrade(w, httptest.NewRequest(http.MethodGet, "/ws", nil), 1<<10); err == nil || w.Code != http.StatusBadRequest {
+		t.Errorf("plain GET: got %d, %v", w.Code, err)
+	}
+}
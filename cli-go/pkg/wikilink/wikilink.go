@@ -0,0 +1,273 @@
+// Package wikilink handles [[post-slug]] links between posts on the same
+// site.
+//
+// A wiki link names a post by its slug (the filename without .md), or by
+// its directory and slug when the slug alone is ambiguous:
+//
+//	[[hello-world]]
+//	[[20260125/hello-world]]
+//	[[hello-world|the first post]]
+//
+// Links are resolved against public.jsonl when a post is rendered, so a
+// link to a post published later starts working on the next render. The
+// reverse direction is kept in metadata/backlinks.json.
+package wikilink
+
+import (
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+// Link is a [[target]] or [[target|label]] occurrence in markdown.
+type Link struct {
+	Target string // As written, trimmed
+	Label  string // Text after |, or ""
+	Start  int    // Byte offset of the opening [[
+	End    int    // Byte offset after the closing ]]
+}
+
+var (
+	linkPattern       = regexp.MustCompile(`\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]+))?\]\]`)
+	inlineCodePattern = regexp.MustCompile("``[^\n]*?``|`[^`\n]*`")
+)
+
+// Find returns the wiki links in markdown, skipping fenced code blocks and
+// inline code.
+func Find(markdown string) []Link {
+	masked := MaskCode(markdown)
+	var links []Link
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(masked, -1) {
+		target := strings.TrimSpace(markdown[m[2]:m[3]])
+		if target == "" {
+			continue
+		}
+		link := Link{Target: target, Start: m[0], End: m[1]}
+		if m[4] >= 0 {
+			link.Label = strings.TrimSpace(markdown[m[4]:m[5]])
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// MaskCode blanks out fenced and inline code so links inside it aren't
+// matched. The result has the same length as markdown, so offsets carry
+// over.
+func MaskCode(markdown string) string {
+	lines := strings.SplitAfter(markdown, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			lines[i] = blank(line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			lines[i] = blank(line)
+			continue
+		}
+		lines[i] = inlineCodePattern.ReplaceAllStringFunc(line, blank)
+	}
+	return strings.Join(lines, "")
+}
+
+// blank replaces every byte but newlines with a space.
+func blank(s string) string {
+	b := []byte(s)
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// Index resolves wiki link targets to published posts.
+type Index struct {
+	bySlug map[string]metadata.IndexEntry // slug -> newest post with it
+	byPath map[string]metadata.IndexEntry // dir/slug (without posts/) -> post
+	posts  []metadata.IndexEntry          // In public.jsonl order
+}
+
+// NewIndex builds an Index from public.jsonl entries. When several posts
+// share a slug, a bare [[slug]] resolves to the most recently published.
+func NewIndex(entries []metadata.IndexEntry) *Index {
+	ix := &Index{
+		bySlug: make(map[string]metadata.IndexEntry),
+		byPath: make(map[string]metadata.IndexEntry),
+	}
+	for _, e := range entries {
+		if e.Type != "post" && !strings.HasPrefix(e.Path, "posts/") {
+			continue
+		}
+		ix.posts = append(ix.posts, e)
+		key := strings.TrimSuffix(strings.TrimPrefix(e.Path, "posts/"), ".md")
+		ix.byPath[key] = e
+		slug := path.Base(key)
+		if prev, ok := ix.bySlug[slug]; !ok || e.Published >= prev.Published {
+			ix.bySlug[slug] = e
+		}
+	}
+	return ix
+}
+
+// LoadIndex builds an Index from the site's public.jsonl.
+func LoadIndex(dataDir string) (*Index, error) {
+	entries, err := metadata.LoadPublicIndex(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndex(entries), nil
+}
+
+// Post returns the published post at postPath (posts/.../slug.md).
+func (ix *Index) Post(postPath string) (metadata.IndexEntry, bool) {
+	e, ok := ix.byPath[strings.TrimSuffix(strings.TrimPrefix(postPath, "posts/"), ".md")]
+	return e, ok && e.Path == postPath
+}
+
+// Resolve returns the post a link target names.
+func (ix *Index) Resolve(target string) (metadata.IndexEntry, bool) {
+	key := strings.Trim(strings.TrimSpace(target), "/")
+	key = strings.TrimPrefix(key, "posts/")
+	key = strings.TrimSuffix(strings.TrimSuffix(key, ".md"), ".html")
+	for _, k := range []string{key, strings.ToLower(strings.ReplaceAll(key, " ", "-"))} {
+		if strings.Contains(k, "/") {
+			if e, ok := ix.byPath[k]; ok {
+				return e, true
+			}
+		} else if e, ok := ix.bySlug[k]; ok {
+			return e, true
+		}
+	}
+	return metadata.IndexEntry{}, false
+}
+
+// Rewrite replaces each wiki link in markdown with a markdown link to the
+// post it names; url turns the post's path (posts/.../slug.md) into the
+// link destination. A link without a label shows the post's title. Links
+// that don't resolve become <span class="wikilink-missing"> text.
+func (ix *Index) Rewrite(markdown string, url func(postPath string) string) string {
+	links := Find(markdown)
+	if len(links) == 0 {
+		return markdown
+	}
+	var b strings.Builder
+	last := 0
+	for _, l := range links {
+		b.WriteString(markdown[last:l.Start])
+		last = l.End
+		label := l.Label
+		e, ok := ix.Resolve(l.Target)
+		if !ok {
+			if label == "" {
+				label = l.Target
+			}
+			b.WriteString(`<span class="wikilink-missing">` + html.EscapeString(label) + `</span>`)
+			continue
+		}
+		if label == "" {
+			label = e.Title
+		}
+		if label == "" {
+			label = l.Target
+		}
+		b.WriteString("[" + escapeLabel(label) + "](" + url(e.Path) + ")")
+	}
+	b.WriteString(markdown[last:])
+	return b.String()
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// Unresolved returns the targets of links in markdown that don't name a
+// published post, in order and without duplicates.
+func (ix *Index) Unresolved(markdown string) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, l := range Find(markdown) {
+		if seen[l.Target] {
+			continue
+		}
+		seen[l.Target] = true
+		if _, ok := ix.Resolve(l.Target); !ok {
+			missing = append(missing, l.Target)
+		}
+	}
+	return missing
+}
+
+// Backlinks reads every published post and returns, for each post that is
+// linked to, the paths of the posts linking to it (newest first). Links
+// from a post to itself are ignored.
+func (ix *Index) Backlinks(dataDir string) (map[string][]string, error) {
+	posts := append([]metadata.IndexEntry(nil), ix.posts...)
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].Published > posts[j].Published })
+
+	backlinks := make(map[string][]string)
+	for _, post := range posts {
+		src := post.Path
+		content, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(src)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		linked := make(map[string]bool)
+		for _, l := range Find(string(content)) {
+			e, ok := ix.Resolve(l.Target)
+			if !ok || e.Path == src || linked[e.Path] {
+				continue
+			}
+			linked[e.Path] = true
+			backlinks[e.Path] = append(backlinks[e.Path], src)
+		}
+	}
+	return backlinks, nil
+}
+
+// Changed returns the posts whose rendered page depends on the difference
+// between two backlink maps: both ends of every link added or removed.
+func Changed(old, new map[string][]string) []string {
+	edges := func(m map[string][]string) map[[2]string]bool {
+		set := make(map[[2]string]bool)
+		for target, sources := range m {
+			for _, src := range sources {
+				set[[2]string{target, src}] = true
+			}
+		}
+		return set
+	}
+	before, after := edges(old), edges(new)
+	dirty := make(map[string]bool)
+	for e := range before {
+		if !after[e] {
+			dirty[e[0]], dirty[e[1]] = true, true
+		}
+	}
+	for e := range after {
+		if !before[e] {
+			dirty[e[0]], dirty[e[1]] = true, true
+		}
+	}
+	paths := make([]string, 0, len(dirty))
+	for p := range dirty {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
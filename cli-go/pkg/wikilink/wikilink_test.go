@@ -0,0 +1,130 @@
+package wikilink
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+func testIndex() *Index {
+	return NewIndex([]metadata.IndexEntry{
+		{Type: "post", Path: "posts/20260101/hello.md", Title: "Hello", Published: "2026-01-01T00:00:00Z"},
+		{Type: "post", Path: "posts/20260201/hello.md", Title: "Hello Again", Published: "2026-02-01T00:00:00Z"},
+		{Type: "post", Path: "posts/20260115/second-post.md", Title: "Second Post", Published: "2026-01-15T00:00:00Z"},
+		{Type: "comment", Path: "comments/20260116/reply.md", Title: "Reply", Published: "2026-01-16T00:00:00Z"},
+	})
+}
+
+func TestFind(t *testing.T) {
+	md := "See [[hello]] and [[ second-post | the second ]].\n" +
+		"Not `[[in-code]]` here.\n" +
+		"```\n[[in-fence]]\n```\n" +
+		"Nor [[]] or [single]."
+
+	links := Find(md)
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d: %+v", len(links), links)
+	}
+	if links[0].Target != "hello" || links[0].Label != "" {
+		t.Errorf("Unexpected first link: %+v", links[0])
+	}
+	if links[1].Target != "second-post" || links[1].Label != "the second" {
+		t.Errorf("Unexpected second link: %+v", links[1])
+	}
+	if md[links[0].Start:links[0].End] != "[[hello]]" {
+		t.Errorf("Offsets don't cover the link: %q", md[links[0].Start:links[0].End])
+	}
+}
+
+func TestResolve(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"hello", "posts/20260201/hello.md"}, // newest of two
+		{"20260101/hello", "posts/20260101/hello.md"},
+		{"posts/20260101/hello.md", "posts/20260101/hello.md"},
+		{"Second Post", "posts/20260115/second-post.md"},
+		{"reply", ""},   // comments aren't link targets
+		{"missing", ""}, // not published
+	}
+	for _, tt := range tests {
+		e, ok := ix.Resolve(tt.target)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("Resolve(%q) = %s, want no match", tt.target, e.Path)
+			}
+			continue
+		}
+		if !ok || e.Path != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", tt.target, e.Path, ok, tt.want)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	ix := testIndex()
+	url := func(p string) string { return "/" + strings.TrimSuffix(p, ".md") + ".html" }
+
+	got := ix.Rewrite("Read [[second-post]], [[hello|this]] and [[nope]].", url)
+	want := `Read [Second Post](/posts/20260115/second-post.html), [this](/posts/20260201/hello.html) and <span class="wikilink-missing">nope</span>.`
+	if got != want {
+		t.Errorf("Rewrite:\n got %q\nwant %q", got, want)
+	}
+
+	if got := ix.Rewrite("No links here.", url); got != "No links here." {
+		t.Errorf("Expected markdown without links unchanged, got %q", got)
+	}
+}
+
+func TestUnresolved(t *testing.T) {
+	ix := testIndex()
+	got := ix.Unresolved("[[hello]] [[nope]] [[other]] [[nope]]")
+	if !reflect.DeepEqual(got, []string{"nope", "other"}) {
+		t.Errorf("Unresolved = %v", got)
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(p, content string) {
+		full := filepath.Join(dir, filepath.FromSlash(p))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	write("posts/20260101/hello.md", "# Hello\n\nLinks to itself: [[20260101/hello]]")
+	write("posts/20260115/second-post.md", "# Second\n\n[[20260101/hello]] and again [[20260101/hello|hi]]")
+	write("posts/20260201/hello.md", "# Hello Again\n\n[[second-post]] and [[20260101/hello]]")
+
+	backlinks, err := testIndex().Backlinks(dir)
+	if err != nil {
+		t.Fatalf("Backlinks failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"posts/20260101/hello.md":       {"posts/20260201/hello.md", "posts/20260115/second-post.md"},
+		"posts/20260115/second-post.md": {"posts/20260201/hello.md"},
+	}
+	if !reflect.DeepEqual(backlinks, want) {
+		t.Errorf("Backlinks:\n got %v\nwant %v", backlinks, want)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	old := map[string][]string{"a.md": {"b.md"}, "c.md": {"d.md"}}
+	new := map[string][]string{"a.md": {"b.md"}, "e.md": {"d.md"}}
+
+	got := Changed(old, new)
+	if !reflect.DeepEqual(got, []string{"c.md", "d.md", "e.md"}) {
+		t.Errorf("Changed = %v", got)
+	}
+	if got := Changed(old, old); len(got) != 0 {
+		t.Errorf("Expected no changes, got %v", got)
+	}
+}
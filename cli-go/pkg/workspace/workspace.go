@@ -0,0 +1,243 @@
+// Package workspace keeps the user's registry of polis sites: a name for
+// each site directory, and which one is active.
+//
+// polis serve works on the active site when it isn't given one (by
+// --data-dir, POLIS_DATA_DIR, or running it inside a site), and the webapp
+// switches between registered sites without restarting. The registry is
+// saved per user in ~/.polis/workspaces.json, so it doesn't depend on where
+// the binary is installed or on filesystem links.
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Filename holds the registry, in the user's ~/.polis directory.
+const Filename = "workspaces.json"
+
+var (
+	// ErrNotFound is returned for a workspace that isn't registered.
+	ErrNotFound = errors.New("no such workspace")
+
+	// ErrExists is returned when adding a workspace whose name is taken.
+	ErrExists = errors.New("workspace already exists")
+)
+
+var (
+	nameRe     = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+	nameCharRe = regexp.MustCompile(`[^a-z0-9._-]+`)
+)
+
+// mu serializes read-modify-write of the registry.
+var mu sync.Mutex
+
+// Workspace is a registered site directory.
+type Workspace struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	AddedAt string `json:"added_at"`
+}
+
+// Registry is the saved list of workspaces.
+type Registry struct {
+	Active     string      `json:"active,omitempty"`
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Path returns the file the registry is saved in.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".polis", Filename), nil
+}
+
+// Load returns the registry; an empty one if none has been saved.
+func Load() (*Registry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Registry{Workspaces: []Workspace{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", Filename, err)
+	}
+	var reg Registry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+	if reg.Workspaces == nil {
+		reg.Workspaces = []Workspace{}
+	}
+	return &reg, nil
+}
+
+func save(reg *Registry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspaces: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create ~/.polis: %w", err)
+	}
+	return fsutil.WriteFile(path, data, 0644)
+}
+
+// update loads the registry, applies fn, and saves it if fn succeeds.
+func update(fn func(reg *Registry) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+	reg, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(reg); err != nil {
+		return err
+	}
+	return save(reg)
+}
+
+// Get returns the named workspace.
+func (reg *Registry) Get(name string) (*Workspace, bool) {
+	for i := range reg.Workspaces {
+		if reg.Workspaces[i].Name == name {
+			return &reg.Workspaces[i], true
+		}
+	}
+	return nil, false
+}
+
+// Find returns the workspace for the site directory dir.
+func (reg *Registry) Find(dir string) (*Workspace, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false
+	}
+	for i := range reg.Workspaces {
+		if reg.Workspaces[i].Path == abs {
+			return &reg.Workspaces[i], true
+		}
+	}
+	return nil, false
+}
+
+// ActiveWorkspace returns the active workspace, if there is one.
+func (reg *Registry) ActiveWorkspace() (*Workspace, bool) {
+	if reg.Active == "" {
+		return nil, false
+	}
+	return reg.Get(reg.Active)
+}
+
+// NameFor suggests a workspace name for the site directory dir: its base
+// name, lowercased, with anything not allowed in a name replaced.
+func NameFor(dir string) string {
+	base := strings.ToLower(filepath.Base(filepath.Clean(dir)))
+	name := strings.Trim(nameCharRe.ReplaceAllString(base, "-"), "-._")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	if name == "" {
+		name = "site"
+	}
+	return name
+}
+
+// Add registers the site directory dir under name. The first workspace
+// added becomes the active one.
+func Add(name, dir string) (*Workspace, error) {
+	if !nameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid workspace name %q (use lowercase letters, digits, '.', '-', '_')", name)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", dir, err)
+	}
+	ws := Workspace{Name: name, Path: abs, AddedAt: time.Now().UTC().Format(time.RFC3339)}
+	err = update(func(reg *Registry) error {
+		if _, ok := reg.Get(name); ok {
+			return ErrExists
+		}
+		reg.Workspaces = append(reg.Workspaces, ws)
+		if reg.Active == "" {
+			reg.Active = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// Use makes the named workspace the active one.
+func Use(name string) (*Workspace, error) {
+	var ws Workspace
+	err := update(func(reg *Registry) error {
+		w, ok := reg.Get(name)
+		if !ok {
+			return ErrNotFound
+		}
+		ws = *w
+		reg.Active = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// Remove forgets the named workspace. The site directory is untouched.
+func Remove(name string) error {
+	return update(func(reg *Registry) error {
+		kept := reg.Workspaces[:0]
+		found := false
+		for _, w := range reg.Workspaces {
+			if w.Name == name {
+				found = true
+				continue
+			}
+			kept = append(kept, w)
+		}
+		if !found {
+			return ErrNotFound
+		}
+		reg.Workspaces = kept
+		if reg.Active == name {
+			reg.Active = ""
+		}
+		return nil
+	})
+}
+
+// ActiveDir returns the active workspace's directory, or "" if there is
+// none or the registry can't be read.
+func ActiveDir() string {
+	reg, err := Load()
+	if err != nil {
+		return ""
+	}
+	if ws, ok := reg.ActiveWorkspace(); ok {
+		return ws.Path
+	}
+	return ""
+}
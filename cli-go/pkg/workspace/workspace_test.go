@@ -0,0 +1,69 @@
+package workspace
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddUseRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if ActiveDir() != "" {
+		t.Fatal("an empty registry has an active workspace")
+	}
+
+	blog := filepath.Join(t.TempDir(), "blog")
+	notes := filepath.Join(t.TempDir(), "notes")
+	if _, err := Add("blog", blog); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := Add("notes", notes); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := Add("blog", notes); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate Add = %v, want ErrExists", err)
+	}
+	if _, err := Add("My Blog", blog); err == nil {
+		t.Error("Add accepted an invalid name")
+	}
+	if got := ActiveDir(); got != blog {
+		t.Errorf("ActiveDir = %q, want the first workspace %q", got, blog)
+	}
+
+	if _, err := Use("notes"); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	if got := ActiveDir(); got != notes {
+		t.Errorf("ActiveDir after Use = %q, want %q", got, notes)
+	}
+	if _, err := Use("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Use(missing) = %v, want ErrNotFound", err)
+	}
+
+	reg, _ := Load()
+	if ws, ok := reg.Find(blog); !ok || ws.Name != "blog" {
+		t.Errorf("Find(blog) = %+v, %v", ws, ok)
+	}
+
+	if err := Remove("notes"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if ActiveDir() != "" {
+		t.Error("removing the active workspace left it active")
+	}
+	if err := Remove("notes"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Remove = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNameFor(t *testing.T) {
+	for dir, want := range map[string]string{
+		"/home/alex/My Blog":   "my-blog",
+		"/srv/polis/site.com/": "site.com",
+		"/":                    "site",
+	} {
+		if got := NameFor(dir); got != want {
+			t.Errorf("NameFor(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
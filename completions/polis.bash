@@ -11,31 +11,67 @@ _polis_completion() {
     prev="${COMP_WORDS[COMP_CWORD-1]}"
 
     # All top-level commands
-    local commands="about blessing clone comment discover extract follow
-        index init migrate migrations notifications post preview
-        rebuild register render republish rotate-key serve unfollow
-        unregister validate version"
+    local commands="about access analytics author blessing bookmark bridge clone coauthor comment config crosspost daemon deploy device digest discover export-book extract follow follow-list
+        graph help identity import index ingest init migrate migrate-domain migrations new notifications pack pin post preview
+        publish rebuild reconcile register remote-admin render republish resolve rotate-key rpc self-update serve stats status timestamp trash unfollow unpublish
+        unregister validate version workspace"
 
     # Subcommands for specific commands
-    local blessing_subcommands="beseech deny grant requests sync"
+    local blessing_subcommands="beseech deny feature grant requests sort sync unfeature"
+    local bookmark_subcommands="list remove show"
+    local bridge_nostr_subcommands="disable enable publish status sync"
+    local identity_subcommands="list prove remove verify"
+    local device_subcommands="add init install list revoke"
+    local coauthor_subcommands="add init list remove"
+    local access_subcommands="add list remove role token"
+    local access_roles="admin editor moderator reader"
+    local remote_admin_subcommands="call connect disconnect drafts publish requests status"
+    local workspace_subcommands="add list remove use"
+    local identity_services="dns github url"
+    local graph_subcommands="export"
+    local trash_subcommands="list purge restore"
+    local daemon_subcommands="start status stop sync"
+    local ingest_subcommands="imap mail"
+    local import_subcommands="mastodon tweets"
+    local discover_subcommands="new trending"
+    local follow_list_subcommands="add create delete exclude include list remove sync"
     local migrations_subcommands="apply"
     local notifications_subcommands="list"
+    local config_subcommands="get list secure set"
+    local config_keys="base_url discovery_url discovery_key listen admin_token smtp_password imap_password bluesky_app_password ipfs_api_token signing_backend sign_as secret_store theme post_path
+        audit_block_publish view_mode show_frontmatter hide_read prefetch content_cache_mb rate_limit rate_limit_burst extension_origins trash_retention_days http_cache_mb allow_local_fetch locale translations_dir log_level ingest.allow ingest.imap_host ingest.imap_user ingest.imap_mailbox bluesky.handle bluesky.pds nostr.enabled nostr.relays ipfs.api timestamp.tsa hooks.post-publish
+        hooks.post-republish hooks.post-comment pages.archive pages.not_found pages.about analytics.enabled analytics.beacon_url analytics.listen update_channel update_url"
 
     # Options for specific commands
     local notifications_list_opts="--type --json"
     local follow_opts="--announce --json"
     local unfollow_opts="--announce --json"
-    local render_opts="--force --init-templates --json"
+    local follow_list_opts="--title --description --from-following --note --json"
+    local render_opts="--force --init-templates --audit --json"
     local rebuild_opts="--posts --comments --notifications --all --json"
-    local init_opts="--site-title --register --posts-dir --comments-dir --keys-dir --snippets-dir --versions-dir --themes-dir --json"
+    local init_opts="--site-title --register --posts-dir --comments-dir --keys-dir --snippets-dir --versions-dir --themes-dir --starter --json"
+    local pack_opts="--out --name --description --json"
+    local author_opts="--offline --limit --json"
+    local bookmark_opts="--note --no-snapshot --json"
+    local graph_export_opts="--format --output --json"
+    local self_update_opts="--check --channel --migrate --json"
     local unregister_opts="--force --json"
     local clone_opts="--full --diff --json"
-    local discover_opts="--author --since --json"
+    local discover_opts="--author --moves --since --before --limit --json"
     local rotate_key_opts="--delete-old-key --json"
-    local post_opts="--filename --title --json"
-    local comment_opts="--filename --title --json"
-    local serve_opts="--data-dir -d"
+    local deploy_opts="--dry-run --prune --full --json"
+    local status_opts="--target --offline --json"
+    local reconcile_opts="--repair --json"
+    local new_opts="--template --title --var --list --json"
+    local digest_opts="--since --out --draft --publish --email --json"
+    local post_opts="--filename --title --as --draft --unlisted --followers --keep --no-crosspost --from-vault --dry-run --json"
+    local comment_opts="--filename --title --as --json"
+    local serve_opts="--data-dir -d --log-level --listen --remote --print-config --multi-site"
     local validate_opts="--json"
+    local stats_opts="--months --json"
+    local analytics_opts="--days --top --json"
+    local export_book_opts="--format --tag --series --year --title --output --json"
+    local pin_opts="--all --json"
 
     # Global options
     local global_opts="--json --help"
@@ -67,9 +103,126 @@ _polis_completion() {
 
             # Handle command-specific completions
             case $actual_cmd in
-                blessing)
+                help)
                     if [[ $effective_pos -eq 1 ]]; then
-                        COMPREPLY=($(compgen -W "$blessing_subcommands --json" -- "$cur"))
+                        COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+                    fi
+                    ;;
+                blessing|bless)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$blessing_subcommands --all-from --json" -- "$cur"))
+                    elif [[ $effective_pos -eq 2 ]]; then
+                        COMPREPLY=($(compgen -W "--all-from" -- "$cur"))
+                    fi
+                    ;;
+                bridge)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "nostr" -- "$cur"))
+                    elif [[ $effective_pos -eq 2 ]]; then
+                        COMPREPLY=($(compgen -W "$bridge_nostr_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        case "${COMP_WORDS[cmd_pos+2]}" in
+                            enable) COMPREPLY=($(compgen -W "--relays --json" -- "$cur")) ;;
+                            sync) COMPREPLY=($(compgen -W "--all --json" -- "$cur")) ;;
+                            *) COMPREPLY=($(compgen -W "--json" -- "$cur")) ;;
+                        esac
+                    elif [[ "${COMP_WORDS[cmd_pos+2]}" == "publish" ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.md' -- "$cur"))
+                    fi
+                    ;;
+                identity)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$identity_subcommands" -- "$cur"))
+                    elif [[ $effective_pos -eq 2 && "${COMP_WORDS[cmd_pos+1]}" =~ ^(prove|remove)$ ]]; then
+                        COMPREPLY=($(compgen -W "$identity_services" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        if [[ "${COMP_WORDS[cmd_pos+1]}" == "prove" ]]; then
+                            COMPREPLY=($(compgen -W "--url --json" -- "$cur"))
+                        else
+                            COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                        fi
+                    fi
+                    ;;
+                remote-admin)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$remote_admin_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        case "${COMP_WORDS[cmd_pos+1]}" in
+                            connect) COMPREPLY=($(compgen -W "--token --json" -- "$cur")) ;;
+                            publish) COMPREPLY=($(compgen -W "--draft --as --unlisted --json" -- "$cur")) ;;
+                            *) COMPREPLY=($(compgen -W "--json" -- "$cur")) ;;
+                        esac
+                    elif [[ $effective_pos -eq 2 && "${COMP_WORDS[cmd_pos+1]}" == "publish" ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.md' -- "$cur"))
+                    elif [[ $effective_pos -eq 2 && "${COMP_WORDS[cmd_pos+1]}" == "call" ]]; then
+                        COMPREPLY=($(compgen -W "GET POST PUT PATCH DELETE" -- "$cur"))
+                    fi
+                    ;;
+                access)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$access_subcommands" -- "$cur"))
+                    elif [[ "$prev" == "--role" ]]; then
+                        COMPREPLY=($(compgen -W "$access_roles" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        if [[ "${COMP_WORDS[cmd_pos+1]}" == "add" ]]; then
+                            COMPREPLY=($(compgen -W "--role --json" -- "$cur"))
+                        else
+                            COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                        fi
+                    elif [[ $effective_pos -eq 3 && "${COMP_WORDS[cmd_pos+1]}" == "role" ]]; then
+                        COMPREPLY=($(compgen -W "$access_roles" -- "$cur"))
+                    fi
+                    ;;
+                workspace)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$workspace_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                    elif [[ $effective_pos -eq 3 && "${COMP_WORDS[cmd_pos+1]}" == "add" ]]; then
+                        COMPREPLY=($(compgen -d -- "$cur"))
+                    fi
+                    ;;
+                coauthor)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$coauthor_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                    elif [[ $effective_pos -eq 3 && "${COMP_WORDS[cmd_pos+1]}" == "add" ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.pub' -- "$cur"))
+                    fi
+                    ;;
+                device)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$device_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        if [[ "${COMP_WORDS[cmd_pos+1]}" == "add" ]]; then
+                            COMPREPLY=($(compgen -W "--scopes --days --json" -- "$cur"))
+                        else
+                            COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                        fi
+                    elif [[ $effective_pos -eq 3 && "${COMP_WORDS[cmd_pos+1]}" == "add" ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.pub' -- "$cur"))
+                    fi
+                    ;;
+                daemon)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$daemon_subcommands --json" -- "$cur"))
+                    fi
+                    ;;
+                import)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$import_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--announce --dry-run --json" -- "$cur"))
+                    else
+                        COMPREPLY=($(compgen -f -- "$cur"))
+                    fi
+                    ;;
+                ingest)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$ingest_subcommands" -- "$cur"))
+                    elif [[ "${COMP_WORDS[cmd_pos+1]}" == "imap" && "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--once --interval --json" -- "$cur"))
                     fi
                     ;;
                 migrations)
@@ -89,6 +242,21 @@ _polis_completion() {
                         esac
                     fi
                     ;;
+                config)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$config_subcommands --json" -- "$cur"))
+                    elif [[ $effective_pos -eq 2 ]]; then
+                        local subcmd="${COMP_WORDS[$((cmd_pos + 1))]}"
+                        case $subcmd in
+                            get|set)
+                                COMPREPLY=($(compgen -W "$config_keys" -- "$cur"))
+                                ;;
+                            list)
+                                COMPREPLY=($(compgen -W "--show-secrets" -- "$cur"))
+                                ;;
+                        esac
+                    fi
+                    ;;
                 follow|unfollow)
                     # Only complete flags if typing a flag, otherwise allow default (URL input)
                     if [[ "$cur" == -* ]]; then
@@ -103,7 +271,46 @@ _polis_completion() {
                     [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$rebuild_opts" -- "$cur"))
                     ;;
                 init)
-                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$init_opts" -- "$cur"))
+                    if [[ "$prev" == "--starter" ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.zip' -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$init_opts" -- "$cur"))
+                    fi
+                    ;;
+                author)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$author_opts" -- "$cur"))
+                    ;;
+                bookmark)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$bookmark_subcommands --json" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$bookmark_opts" -- "$cur"))
+                    fi
+                    ;;
+                graph)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$graph_subcommands" -- "$cur"))
+                    elif [[ "$prev" == "--format" ]]; then
+                        COMPREPLY=($(compgen -W "json dot graphml" -- "$cur"))
+                    elif [[ "$prev" == "--output" ]]; then
+                        COMPREPLY=($(compgen -f -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$graph_export_opts" -- "$cur"))
+                    fi
+                    ;;
+                self-update)
+                    if [[ "$prev" == "--channel" ]]; then
+                        COMPREPLY=($(compgen -W "stable beta" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$self_update_opts" -- "$cur"))
+                    fi
+                    ;;
+                pack)
+                    if [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$pack_opts" -- "$cur"))
+                    else
+                        COMPREPLY=($(compgen -f -X '!*.md' -- "$cur"))
+                    fi
                     ;;
                 unregister)
                     [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$unregister_opts" -- "$cur"))
@@ -112,12 +319,92 @@ _polis_completion() {
                     [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$clone_opts" -- "$cur"))
                     ;;
                 discover)
-                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$discover_opts" -- "$cur"))
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$discover_subcommands $discover_opts" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$discover_opts" -- "$cur"))
+                    fi
+                    ;;
+                follow-list)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "$follow_list_subcommands" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$follow_list_opts" -- "$cur"))
+                    fi
                     ;;
                 rotate-key)
                     [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$rotate_key_opts" -- "$cur"))
                     ;;
-                post|republish)
+                rpc)
+                    if [[ "$prev" == "--socket" ]]; then
+                        COMPREPLY=($(compgen -f -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--socket --json" -- "$cur"))
+                    fi
+                    ;;
+                deploy)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$deploy_opts" -- "$cur"))
+                    ;;
+                status)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$status_opts" -- "$cur"))
+                    ;;
+                stats)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$stats_opts" -- "$cur"))
+                    ;;
+                analytics)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$analytics_opts" -- "$cur"))
+                    ;;
+                export-book)
+                    if [[ "$prev" == "--format" ]]; then
+                        COMPREPLY=($(compgen -W "epub pdf" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$export_book_opts" -- "$cur"))
+                    fi
+                    ;;
+                pin)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$pin_opts" -- "$cur"))
+                    ;;
+                timestamp)
+                    if [[ "$cur" == -* ]]; then
+                        if [[ "${COMP_WORDS[cmd_pos+1]}" == "verify" ]]; then
+                            COMPREPLY=($(compgen -W "--ca-file --json" -- "$cur"))
+                        else
+                            COMPREPLY=($(compgen -W "--json" -- "$cur"))
+                        fi
+                    elif [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -W "verify" -- "$cur") $(compgen -f -X '!*.md' -- "$cur"))
+                    elif [[ "$prev" == "--ca-file" ]]; then
+                        COMPREPLY=($(compgen -f -- "$cur"))
+                    else
+                        COMPREPLY=($(compgen -f -X '!*.md' -- "$cur"))
+                    fi
+                    ;;
+                reconcile)
+                    [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$reconcile_opts" -- "$cur"))
+                    ;;
+                resolve)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        COMPREPLY=($(compgen -f -X '!*.md' -- "$cur"))
+                    elif [[ $effective_pos -eq 2 ]]; then
+                        COMPREPLY=($(compgen -W "pull keep merge-frontmatter" -- "$cur"))
+                    fi
+                    ;;
+                digest)
+                    if [[ "$prev" == "--since" ]]; then
+                        COMPREPLY=($(compgen -W "24h 7d 2w 30d" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$digest_opts" -- "$cur"))
+                    fi
+                    ;;
+                new)
+                    if [[ "$prev" == "--template" ]]; then
+                        local templates=$(ls .polis/templates/posts 2>/dev/null | sed -n 's/\.md$//p')
+                        COMPREPLY=($(compgen -W "$templates" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "$new_opts" -- "$cur"))
+                    fi
+                    ;;
+                post|publish|republish)
                     # Complete flags if typing flag, otherwise fall back to file completion
                     if [[ "$cur" == -* ]]; then
                         COMPREPLY=($(compgen -W "$post_opts" -- "$cur"))
@@ -130,6 +417,14 @@ _polis_completion() {
                         COMPREPLY=($(compgen -W "$comment_opts" -- "$cur"))
                     fi
                     ;;
+                trash)
+                    if [[ $effective_pos -eq 1 ]]; then
+                        # Subcommand, or a draft/post file to move to trash
+                        COMPREPLY=($(compgen -W "$trash_subcommands --json" -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--all --json" -- "$cur"))
+                    fi
+                    ;;
                 extract)
                     # First arg is file, second is hash - only complete flags
                     if [[ "$cur" == -* ]]; then
@@ -142,6 +437,13 @@ _polis_completion() {
                         COMPREPLY=($(compgen -W "--json" -- "$cur"))
                     fi
                     ;;
+                migrate-domain)
+                    if [[ "$prev" == "--stubs" ]]; then
+                        COMPREPLY=($(compgen -d -- "$cur"))
+                    elif [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--stubs --no-discovery --json" -- "$cur"))
+                    fi
+                    ;;
                 serve)
                     [[ "$cur" == -* ]] && COMPREPLY=($(compgen -W "$serve_opts" -- "$cur"))
                     ;;
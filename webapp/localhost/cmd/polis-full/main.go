@@ -1,4 +1,4 @@
-// polis-full is the bundled Polis binary with both CLI commands and the serve command.
+// polis-full is the bundled Polis binary with both CLI commands and the serve and daemon commands.
 package main
 
 import (
@@ -15,44 +15,27 @@ import (
 var Version = "dev"
 
 func main() {
-	// Set version for CLI commands
-	cmd.Version = Version
-
-	// Check if first argument is "serve" to start the server
-	if len(os.Args) > 1 && os.Args[1] == "serve" {
-		runServer(os.Args[2:], Version)
-	} else {
-		// Dispatch to CLI command handler
-		cmd.Execute(os.Args[1:])
-	}
-}
-
-func runServer(args []string, cliVersion string) {
-	// Parse serve-specific flags
-	dataDir := "."
-
-	// Simple flag parsing for serve command
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--data-dir":
-			if i+1 < len(args) {
-				dataDir = args[i+1]
-				i++
-			}
-		case "-d":
-			if i+1 < len(args) {
-				dataDir = args[i+1]
-				i++
-			}
-		}
-	}
-
 	// Get the embedded web UI filesystem
 	webFS, err := fs.Sub(webui.Assets, "www")
 	if err != nil {
 		log.Fatal("Failed to create sub filesystem:", err)
 	}
 
-	// Run the server with CLI version for metadata
-	server.Run(webFS, dataDir, server.RunOptions{CLIVersion: cliVersion})
+	// serve and daemon are registered commands like any other; the bundled
+	// binary supplies their implementations
+	cmd.Version = Version
+	cmd.WebFS = webFS
+	cmd.ServeHandler = func(opts cmd.ServeOptions) {
+		runOpts := server.RunOptions{CLIVersion: Version, LogLevel: opts.LogLevel, Listen: opts.Listen, MultiSite: opts.MultiSite}
+		if opts.Remote != nil {
+			server.RunRemote(opts.WebFS, opts.Remote, runOpts)
+			return
+		}
+		server.Run(opts.WebFS, opts.DataDir, runOpts)
+	}
+	cmd.DaemonHandler = func(opts cmd.DaemonOptions) {
+		server.RunDaemon(opts.DataDir, server.RunOptions{CLIVersion: Version, LogLevel: opts.LogLevel})
+	}
+
+	cmd.Execute(os.Args[1:])
 }
@@ -4,7 +4,9 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
 	"github.com/vdibart/polis-cli/webapp/localhost/internal/server"
 	"github.com/vdibart/polis-cli/webapp/localhost/internal/webui"
 )
@@ -13,18 +15,48 @@ import (
 var Version = "dev"
 
 func main() {
-	// Default to current working directory (matches bundled binary behavior)
+	// Default to POLIS_DATA_DIR, then the current working directory, then
+	// the active workspace (matches bundled binary behavior)
 	dataDir := "."
+	dataDirGiven := false
+	if dir := os.Getenv("POLIS_DATA_DIR"); dir != "" {
+		dataDir, dataDirGiven = dir, true
+	}
+	logLevel := ""
+	listen := ""
+	multiSite := false
 
-	// Simple flag parsing for --data-dir / -d
+	// Simple flag parsing for --data-dir / -d, --log-level, --listen, and
+	// --multi-site
 	args := os.Args[1:]
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--data-dir", "-d":
 			if i+1 < len(args) {
-				dataDir = args[i+1]
+				dataDir, dataDirGiven = args[i+1], true
+				i++
+			}
+		case "--log-level":
+			if i+1 < len(args) {
+				logLevel = args[i+1]
 				i++
 			}
+		case "--listen":
+			if i+1 < len(args) {
+				listen = args[i+1]
+				i++
+			}
+		case "--multi-site":
+			multiSite = true
+		}
+	}
+
+	// Outside a site, work on the active workspace (polis workspace use)
+	if !dataDirGiven {
+		if _, err := os.Stat(filepath.Join(dataDir, ".well-known", "polis")); err != nil {
+			if active := workspace.ActiveDir(); active != "" {
+				dataDir = active
+			}
 		}
 	}
 
@@ -35,5 +67,5 @@ func main() {
 	}
 
 	// Run the server
-	server.Run(webFS, dataDir, server.RunOptions{CLIVersion: Version})
+	server.Run(webFS, dataDir, server.RunOptions{CLIVersion: Version, LogLevel: logLevel, Listen: listen, MultiSite: multiSite})
 }
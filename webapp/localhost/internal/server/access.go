@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/access"
+)
+
+// Once the site has API users (polis access add), every API request needs a
+// user's token, as a bearer token or the polis_token cookie the dashboard
+// sets when signing in, and the user's role must allow the route. Reads need
+// a reader, except adminReads; changes need the role in writeRoles, or admin.
+// That includes cross-origin requests: an Origin header is set by the client,
+// so it can't exempt a request. The one exception is an extension calling the
+// widget APIs with a token an API user approved in the handshake (which needs
+// an admin), or revoking its own token.
+
+// AccessCookie holds the token of the user signed in to the dashboard.
+const AccessCookie = "polis_token"
+
+// writeRoles maps route patterns to the least role that may change things
+// through them.
+var writeRoles = map[string]access.Role{
+	// Reading: the reader's own view of the dashboard and feed
+	"/api/settings/view-mode":        access.Reader,
+	"/api/settings/show-frontmatter": access.Reader,
+	"/api/settings/hide-read":        access.Reader,
+	"/api/feed/refresh":              access.Reader,
+	"/api/feed/read":                 access.Reader,
+	"/api/feed/next-unread":          access.Reader,
+	"/api/feed/filters":              access.Reader,
+	"/api/feed/bookmark":             access.Reader,
+	"/api/notifications/read":        access.Reader,
+
+	// Moderating comments on the site's posts
+	"/api/blessing/grant":   access.Moderator,
+	"/api/blessing/deny":    access.Moderator,
+	"/api/blessing/bulk":    access.Moderator,
+	"/api/blessing/revoke":  access.Moderator,
+	"/api/blessing/feature": access.Moderator,
+	"/api/blessing/sort":    access.Moderator,
+
+	// Writing, publishing, commenting, and following
+	"/api/render":            access.Editor,
+	"/api/publish":           access.Editor,
+	"/api/republish":         access.Editor,
+	"/api/drafts":            access.Editor,
+	"/api/drafts/":           access.Editor,
+	"/api/post-templates":    access.Editor,
+	"/api/post-templates/":   access.Editor,
+	"/api/posts/":            access.Editor,
+	"/api/trash/restore":     access.Editor,
+	"/api/trash/purge":       access.Editor,
+	"/api/conflicts":         access.Editor,
+	"/api/conflicts/resolve": access.Editor,
+	"/api/deploy":            access.Editor,
+	"/api/about":             access.Editor,
+	"/api/snippets":          access.Editor,
+	"/api/snippets/":         access.Editor,
+	"/api/render-page":       access.Editor,
+	"/api/render-shortcodes": access.Editor,
+	"/api/comments/drafts":   access.Editor,
+	"/api/comments/drafts/":  access.Editor,
+	"/api/comments/sign":     access.Editor,
+	"/api/comments/beseech":  access.Editor,
+	"/api/comments/sync":     access.Editor,
+	"/api/comments/":         access.Editor,
+	"/api/following":         access.Editor,
+	"/api/followers/":        access.Editor,
+	"/api/threads":           access.Editor,
+	"/api/reconcile":         access.Editor,
+}
+
+// adminReads lists route patterns only admins may read: secrets, logs, the
+// whole site with its keys, and the machine's other sites.
+var adminReads = map[string]bool{
+	"/api/settings/email":        true,
+	"/api/settings/private-feed": true,
+	"/api/logs":                  true,
+	"/api/metrics":               true,
+	"/api/debug/stats":           true,
+	"/api/download-site":         true,
+	"/api/automations":           true,
+	"/api/automations/":          true,
+	"/api/templates":             true,
+	"/api/workspaces":            true,
+	"/api/sites":                 true,
+}
+
+type accessUserKey struct{}
+
+// accessUser returns the user a request was authenticated as, or nil when
+// the site has no API users.
+func accessUser(r *http.Request) *access.User {
+	u, _ := r.Context().Value(accessUserKey{}).(*access.User)
+	return u
+}
+
+// neededRole returns the least role that may make a method request to path.
+func (s *Server) neededRole(method, path string) access.Role {
+	pattern := ""
+	for _, rt := range s.apiRoutes() {
+		p := rt.pattern()
+		if (p == path || strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) && len(p) > len(pattern) {
+			pattern = p
+		}
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if adminReads[pattern] {
+			return access.Admin
+		}
+		return access.Reader
+	}
+	if role, ok := writeRoles[pattern]; ok {
+		return role
+	}
+	return access.Admin
+}
+
+// adminToken returns the admin_token setting, which signs in as an admin.
+func (s *Server) adminToken() string {
+	if s.Settings == nil {
+		return ""
+	}
+	return s.Settings.Get("admin_token")
+}
+
+// accessEnabled reports whether API requests need a token.
+func (s *Server) accessEnabled() bool {
+	return s.adminToken() != "" || access.Enabled(s.DataDir)
+}
+
+// WithAccess requires API requests to carry the token of a user whose role
+// allows them, once the site has API users. On a network address a site
+// without API users refuses every API request, since anyone could make it.
+func (s *Server) WithAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		users, err := access.Load(s.DataDir)
+		if err != nil {
+			s.LogError("Failed to load API users: %v", err)
+			http.Error(w, "Failed to load API users", http.StatusInternalServerError)
+			return
+		}
+		if u := access.TokenUser(s.adminToken()); u != nil {
+			users = append(users, *u)
+		}
+		if len(users) == 0 {
+			if s.networked.Load() {
+				http.Error(w, "No API users; add one with polis access add <name> --role admin", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		path := unversionedPath(r.URL.Path)
+		if s.extensionAuthorized(r, path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := bearerToken(r)
+		if token == "" {
+			if c, err := r.Cookie(AccessCookie); err == nil {
+				token = c.Value
+			}
+		}
+		user := access.Authenticate(users, token)
+		if user == nil {
+			http.Error(w, "Sign in with an API token (polis access add)", http.StatusUnauthorized)
+			return
+		}
+		if need := s.neededRole(r.Method, path); !user.Role.Allows(need) {
+			s.LogWarn("Denied %s %s to %s (%s)", r.Method, path, user.Name, user.Role)
+			http.Error(w, fmt.Sprintf("Your role (%s) can't do this; it needs %s", user.Role, need), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accessUserKey{}, user)))
+	})
+}
+
+// extensionAuthorized reports whether a cross-origin request is authorized by
+// its extension token alone: a widget call within the scopes of a token an
+// API user approved, or the revocation of the token the request carries.
+func (s *Server) extensionAuthorized(r *http.Request, path string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || sameOrigin(r, origin) {
+		return false
+	}
+	t := s.findExtensionToken(bearerToken(r), origin)
+	if t == nil {
+		return false
+	}
+	if path == "/api/ext/handshake" {
+		return r.Method == http.MethodDelete
+	}
+	scope, ok := extensionRoutes[path]
+	return ok && t.IssuedBy != "" && t.allows(scope)
+}
+
+// GET /api/me — The user the request is signed in as, and their role.
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resp := map[string]interface{}{"auth": false, "role": access.Admin}
+	if u := accessUser(r); u != nil {
+		resp = map[string]interface{}{"auth": true, "name": u.Name, "role": u.Role}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
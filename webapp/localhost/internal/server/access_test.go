@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/access"
+)
+
+func TestWithAccess_Roles(t *testing.T) {
+	s := newConfiguredServer(t)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+	h := s.WithAccess(mux)
+
+	do := func(method, target, token, cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: AccessCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without users, the API is open as before
+	if w := do(http.MethodGet, "/api/me", "", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"auth":false`) {
+		t.Fatalf("open /api/me: %d %s", w.Code, w.Body.String())
+	}
+
+	admin, err := access.Add(s.DataDir, "alex", access.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, _ := access.Add(s.DataDir, "sam", access.Editor)
+	moderator, _ := access.Add(s.DataDir, "mo", access.Moderator)
+	reader, _ := access.Add(s.DataDir, "rae", access.Reader)
+
+	if w := do(http.MethodGet, "/api/status", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: got %d, want 401", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/status", "wrong", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: got %d, want 401", w.Code)
+	}
+
+	w := do(http.MethodGet, "/api/me", "", editor)
+	var me struct {
+		Auth bool   `json:"auth"`
+		Name string `json:"name"`
+		Role string `json:"role"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &me)
+	if !me.Auth || me.Name != "sam" || me.Role != "editor" {
+		t.Errorf("/api/me with the cookie = %s", w.Body.String())
+	}
+
+	forbidden := func(method, target, token string) bool {
+		return do(method, target, token, "").Code == http.StatusForbidden
+	}
+	cases := []struct {
+		name, method, target, token string
+		denied                      bool
+	}{
+		{"reader reads posts", http.MethodGet, "/api/posts", reader, false},
+		{"reader marks read", http.MethodPost, "/api/feed/read", reader, false},
+		{"reader publishes", http.MethodPost, "/api/publish", reader, true},
+		{"reader reads logs", http.MethodGet, "/api/logs", reader, true},
+		{"moderator blesses", http.MethodPost, "/api/blessing/grant", moderator, false},
+		{"moderator saves a draft", http.MethodPost, "/api/drafts", moderator, true},
+		{"editor publishes", http.MethodPost, "/api/publish", editor, false},
+		{"editor unpublishes", http.MethodDelete, "/api/posts/posts/x.md", editor, false},
+		{"editor blesses", http.MethodPost, "/api/blessing/deny", editor, false},
+		{"editor via v1", http.MethodPost, "/api/v1/republish", editor, false},
+		{"editor changes the theme", http.MethodPost, "/api/settings/theme", editor, true},
+		{"editor unregisters", http.MethodPost, "/api/site/unregister", editor, true},
+		{"editor adds automations", http.MethodPost, "/api/automations", editor, true},
+		{"editor reads the email settings", http.MethodGet, "/api/settings/email", editor, true},
+		{"editor downloads the site", http.MethodGet, "/api/download-site", editor, true},
+		{"admin unregisters", http.MethodPost, "/api/site/unregister", admin, false},
+		{"admin reads logs", http.MethodGet, "/api/logs", admin, false},
+	}
+	for _, c := range cases {
+		if got := forbidden(c.method, c.target, c.token); got != c.denied {
+			t.Errorf("%s: forbidden = %v, want %v", c.name, got, c.denied)
+		}
+	}
+
+	// Pages outside the API are unaffected
+	if w := do(http.MethodGet, "/healthz", "", ""); w.Code == http.StatusUnauthorized {
+		t.Error("/healthz required a token")
+	}
+}
+
+func TestWithAccess_NetworkedWithoutUsers(t *testing.T) {
+	s := newConfiguredServer(t)
+	s.networked.Store(true)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+	h := s.WithAccess(mux)
+
+	do := func(target, token string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// On a network address, a site without API users refuses the API
+	if code := do("/api/me", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("no users: got %d, want 503", code)
+	}
+	if code := do("/healthz", ""); code != http.StatusOK {
+		t.Errorf("/healthz: got %d, want 200", code)
+	}
+
+	token, err := access.Add(s.DataDir, "alex", access.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := do("/api/me", token); code != http.StatusOK {
+		t.Errorf("with a user: got %d, want 200", code)
+	}
+
+	// Removing the last user while serving closes the API again
+	if err := access.Remove(s.DataDir, "alex"); err != nil {
+		t.Fatal(err)
+	}
+	if code := do("/api/me", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("last user removed: got %d, want 503", code)
+	}
+}
+
+func TestWithAccess_AdminToken(t *testing.T) {
+	t.Setenv("POLIS_ADMIN_TOKEN", "from-the-environment")
+	s := newConfiguredServer(t)
+	s.LoadEnv()
+	if !s.accessEnabled() {
+		t.Fatal("admin_token didn't turn on access control")
+	}
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+	h := s.WithAccess(mux)
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	if w := do(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: got %d, want 401", w.Code)
+	}
+	w := do("from-the-environment")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"`+access.TokenUserName+`"`) || !strings.Contains(w.Body.String(), `"role":"admin"`) {
+		t.Errorf("admin token: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestWithAccess_CrossOrigin(t *testing.T) {
+	s, _ := newExtensionServer(t)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+	h := s.WithCORS(s.WithAccess(mux))
+
+	handshake := func(token string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, extensionRequest(http.MethodPost, "/api/ext/handshake", testExtensionOrigin, token, `{"scopes":["state"]}`))
+		var resp struct {
+			Token string `json:"token"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Token
+	}
+	state := func(token string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, extensionRequest(http.MethodGet, "/api/widget/state?author=alice.example.com", testExtensionOrigin, token, ""))
+		return w.Code
+	}
+
+	// A token issued while the site had no users isn't honored once it has
+	code, before := handshake("")
+	if code != http.StatusOK {
+		t.Fatalf("open handshake: got %d", code)
+	}
+	admin, _ := access.Add(s.DataDir, "alex", access.Admin)
+	editor, _ := access.Add(s.DataDir, "sam", access.Editor)
+	if got := state(before); got != http.StatusUnauthorized {
+		t.Errorf("token from before access control: got %d, want 401", got)
+	}
+
+	// The Origin header alone no longer gets a token
+	if code, _ := handshake(""); code != http.StatusUnauthorized {
+		t.Errorf("handshake without a token: got %d, want 401", code)
+	}
+	if code, _ := handshake(editor); code != http.StatusForbidden {
+		t.Errorf("handshake as an editor: got %d, want 403", code)
+	}
+	code, approved := handshake(admin)
+	if code != http.StatusOK || approved == "" {
+		t.Fatalf("handshake as an admin: got %d", code)
+	}
+	if got := state(approved); got != http.StatusOK {
+		t.Errorf("approved token: got %d, want 200", got)
+	}
+
+	// The extension can still revoke its own token
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodDelete, "/api/ext/handshake", testExtensionOrigin, approved, ""))
+	if w.Code != http.StatusOK {
+		t.Errorf("revoke: got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/analytics"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+)
+
+// Post view counting: with analytics.enabled on, render adds a beacon to
+// each post that reports views to analytics.beacon_url, which should be the
+// public address of this server's /beacon endpoint (or, for polis daemon,
+// of the analytics.listen address). Views are counted per post per day in
+// .polis/analytics.json; nothing about the reader is kept.
+
+// maxBeaconBody caps the size of a /beacon request, which carries only a
+// post path.
+const maxBeaconBody = 1024
+
+// applyAnalyticsSettings records the analytics.* settings for /beacon and
+// sets the beacon render adds to posts.
+func (s *Server) applyAnalyticsSettings(cfg *config.Config) {
+	a := &AnalyticsConfig{
+		Enabled:   cfg.Bool("analytics.enabled"),
+		BeaconURL: cfg.Get("analytics.beacon_url"),
+		Listen:    cfg.Get("analytics.listen"),
+	}
+	s.analytics.Store(a)
+	render.AnalyticsBeacon = ""
+	if a.Enabled {
+		render.AnalyticsBeacon = a.BeaconURL
+	}
+}
+
+// AnalyticsSettings returns the resolved analytics.* settings.
+func (s *Server) AnalyticsSettings() AnalyticsConfig {
+	if a := s.analytics.Load(); a != nil {
+		return *a
+	}
+	return AnalyticsConfig{}
+}
+
+// analyticsListenAddr returns the address polis daemon serves /beacon on,
+// or "" if analytics is off or analytics.listen is unset.
+func (s *Server) analyticsListenAddr() string {
+	if a := s.AnalyticsSettings(); a.Enabled {
+		return a.Listen
+	}
+	return ""
+}
+
+// handleBeacon counts a view of the post whose path is the request body.
+// It is called cross-origin by the beacon in rendered posts, so it lives
+// outside /api/ and answers any origin.
+// POST /beacon
+func (s *Server) handleBeacon(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.AnalyticsSettings().Enabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBeaconBody))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := analytics.Record(s.DataDir, strings.TrimSpace(string(body)), time.Now()); err != nil {
+		if errors.Is(err, analytics.ErrUnknownPost) {
+			http.Error(w, "Unknown post", http.StatusBadRequest)
+			return
+		}
+		s.LogError("failed to record view: %v", err)
+		http.Error(w, "Failed to record view", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAnalytics returns the view counts of the last ?days= days (default
+// 30, 0 for all time), with the analytics settings.
+// GET /api/analytics
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "days must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	report, err := analytics.Summarize(s.DataDir, days, time.Now())
+	if err != nil {
+		s.LogError("failed to read analytics: %v", err)
+		http.Error(w, "Failed to read analytics", http.StatusInternalServerError)
+		return
+	}
+	settings := s.AnalyticsSettings()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":    settings.Enabled,
+		"beacon_url": settings.BeaconURL,
+		"report":     report,
+	})
+}
+
+// handleAnalyticsSettings reads or changes the analytics settings:
+// {"enabled": bool, "beacon_url": string}. Fields left out of a POST are
+// unchanged. The beacon is added to or removed from posts as they are next
+// rendered.
+func (s *Server) handleAnalyticsSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.AnalyticsSettings())
+
+	case http.MethodPost:
+		var req struct {
+			Enabled   *bool   `json:"enabled"`
+			BeaconURL *string `json:"beacon_url"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.BeaconURL != nil {
+			*req.BeaconURL = strings.TrimSpace(*req.BeaconURL)
+			if *req.BeaconURL != "" && !strings.HasPrefix(*req.BeaconURL, "https://") && !strings.HasPrefix(*req.BeaconURL, "http://") {
+				http.Error(w, "beacon_url must be an http(s) URL", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if s.Config == nil {
+			s.Config = &Config{}
+		}
+		if s.Config.Analytics == nil {
+			s.Config.Analytics = &AnalyticsConfig{}
+		}
+		if req.Enabled != nil {
+			s.Config.Analytics.Enabled = *req.Enabled
+		}
+		if req.BeaconURL != nil {
+			s.Config.Analytics.BeaconURL = *req.BeaconURL
+		}
+		if err := s.SaveConfig(); err != nil {
+			s.LogError("failed to save config: %v", err)
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+
+		// Re-resolve, since the environment can override the saved values
+		cfg, err := config.Load(config.Options{DataDir: s.DataDir})
+		if err != nil {
+			s.LogError("failed to load settings: %v", err)
+			http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+			return
+		}
+		s.applyAnalyticsSettings(cfg)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.AnalyticsSettings())
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
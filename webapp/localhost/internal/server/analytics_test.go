@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+)
+
+func TestAnalyticsBeacon(t *testing.T) {
+	s := newTestServer(t)
+	defer func(saved string) { render.AnalyticsBeacon = saved }(render.AnalyticsBeacon)
+	os.WriteFile(filepath.Join(s.DataDir, "metadata", "public.jsonl"),
+		[]byte(`{"type":"post","path":"posts/20260101/hello.md","title":"Hello"}`+"\n"), 0644)
+	h := s.Handler()
+
+	beacon := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/beacon", strings.NewReader(body))
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Off by default
+	if code := beacon("posts/20260101/hello.md"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 with analytics off, got %d", code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/analytics",
+		jsonBody(t, map[string]interface{}{"enabled": true, "beacon_url": "https://stats.example.com/beacon"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("settings: %d %s", w.Code, w.Body.String())
+	}
+	if render.AnalyticsBeacon != "https://stats.example.com/beacon" {
+		t.Errorf("AnalyticsBeacon = %q", render.AnalyticsBeacon)
+	}
+
+	if code := beacon("posts/20260101/hello.md"); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if code := beacon("posts/20260101/hello.html"); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if code := beacon("posts/elsewhere.md"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown post, got %d", code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/analytics?days=0", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var resp struct {
+		Enabled bool `json:"enabled"`
+		Report  struct {
+			Views int `json:"views"`
+			Posts []struct {
+				Title string `json:"title"`
+				Views int    `json:"views"`
+			} `json:"posts"`
+		} `json:"report"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %s", w.Body.String())
+	}
+	if !resp.Enabled || resp.Report.Views != 2 || len(resp.Report.Posts) != 1 || resp.Report.Posts[0].Title != "Hello" {
+		t.Errorf("unexpected analytics: %s", w.Body.String())
+	}
+}
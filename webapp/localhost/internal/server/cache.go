@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// fileCache holds parsed copies of the site files the server reads on
+// nearly every request (public.jsonl, .well-known/polis, following.json,
+// and the feed cache), so dashboard polling doesn't re-read and re-parse
+// them on large sites.
+//
+// An entry is reused while its file's size and modification time are
+// unchanged, so writes from the CLI or another process are picked up on
+// the next request without any hooks. Values are shared between requests:
+// callers must not modify them.
+type fileCache struct {
+	mu      sync.Mutex
+	entries map[string]fileCacheEntry // By file path
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type fileCacheEntry struct {
+	size    int64
+	modTime time.Time
+	value   any
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{entries: make(map[string]fileCacheEntry)}
+}
+
+// load returns the value read from path, calling read when there's no
+// entry or the file has changed since. Errors and missing files aren't
+// cached; read decides what they mean.
+func (c *fileCache) load(path string, read func() (any, error)) (any, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.entries, path)
+		c.mu.Unlock()
+		c.misses.Add(1)
+		return read()
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+		c.hits.Add(1)
+		return e.value, nil
+	}
+
+	// A write between the Stat and read leaves an entry stamped with the
+	// older time, which the next load replaces.
+	c.misses.Add(1)
+	value, err := read()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[path] = fileCacheEntry{size: info.Size(), modTime: info.ModTime(), value: value}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// files returns the server's file cache, creating it on first use.
+func (s *Server) files() *fileCache {
+	s.fileCacheOnce.Do(func() {
+		s.fileCache = newFileCache()
+	})
+	return s.fileCache
+}
+
+// cachedWellKnown is site.LoadWellKnown through the file cache.
+func (s *Server) cachedWellKnown() (*site.WellKnown, error) {
+	v, err := s.files().load(filepath.Join(s.DataDir, ".well-known", "polis"), func() (any, error) {
+		return site.LoadWellKnown(s.DataDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*site.WellKnown), nil
+}
+
+// cachedFollowing is following.Load through the file cache. Handlers that
+// change the list must load their own copy.
+func (s *Server) cachedFollowing() (*following.FollowingFile, error) {
+	path := following.DefaultPath(s.DataDir)
+	v, err := s.files().load(path, func() (any, error) {
+		return following.Load(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*following.FollowingFile), nil
+}
+
+// cachedIndexFile returns the raw contents of public.jsonl (unlisted false)
+// or .polis/unlisted.jsonl (unlisted true) through the file cache.
+func (s *Server) cachedIndexFile(unlisted bool) ([]byte, error) {
+	path := filepath.Join(s.DataDir, "metadata", metadata.PublicIndexFilename)
+	if unlisted {
+		path = filepath.Join(s.DataDir, ".polis", metadata.UnlistedIndexFilename)
+	}
+	v, err := s.files().load(path, func() (any, error) {
+		return os.ReadFile(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// cachedFeedItems is CacheManager.List for the current discovery service
+// through the file cache. The slice is a copy, so callers may reorder it.
+func (s *Server) cachedFeedItems() ([]feed.CachedFeedItem, error) {
+	discoveryDomain := s.GetDiscoveryDomain()
+	v, err := s.files().load(feed.CacheFile(s.DataDir, discoveryDomain), func() (any, error) {
+		return feed.NewCacheManager(s.DataDir, discoveryDomain).List()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]feed.CachedFeedItem{}, v.([]feed.CachedFeedItem)...), nil
+}
+
+// writePrometheus writes the cache's hit and miss counts in the Prometheus
+// text format, after the request metrics.
+func (c *fileCache) writePrometheus(w io.Writer) {
+	fmt.Fprintln(w, "# HELP polis_file_cache_lookups_total Site file cache lookups by result.")
+	fmt.Fprintln(w, "# TYPE polis_file_cache_lookups_total counter")
+	fmt.Fprintf(w, "polis_file_cache_lookups_total{result=\"hit\"} %d\n", c.hits.Load())
+	fmt.Fprintf(w, "polis_file_cache_lookups_total{result=\"miss\"} %d\n", c.misses.Load())
+}
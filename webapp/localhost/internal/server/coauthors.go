@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// handleCoauthors lists the site's co-authors, marking those whose keys are
+// on this machine; publish, republish, and comment signing take one of
+// those names as "as".
+// GET /api/coauthors
+func (s *Server) handleCoauthors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	type entry struct {
+		coauthor.Author
+		KeyHere bool `json:"key_here"`
+	}
+	authors := []entry{}
+	if wk, err := site.LoadWellKnown(s.DataDir); err == nil {
+		local := make(map[string]bool)
+		for _, name := range coauthor.Local(s.DataDir) {
+			local[name] = true
+		}
+		for _, a := range wk.Authors {
+			authors = append(authors, entry{a, local[a.Name]})
+		}
+	}
+	signAs := ""
+	if s.Settings != nil {
+		signAs = s.Settings.Get("sign_as")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"authors": authors,
+		"sign_as": signAs,
+	})
+}
+
+// signingKey returns the key posts and comments are signed with: the key of
+// co-author as, or of sign_as when as is empty, or else the site key.
+func (s *Server) signingKey(as string) ([]byte, error) {
+	if as == "" && s.Settings != nil {
+		as = s.Settings.Get("sign_as")
+	}
+	if as == "" {
+		return s.PrivateKey, nil
+	}
+	wk, err := site.LoadWellKnown(s.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	author := coauthor.Find(wk.Authors, as)
+	if author == nil {
+		return nil, fmt.Errorf("%q is not an author of this site", as)
+	}
+	if author.Removed != "" {
+		return nil, fmt.Errorf("%q was removed from the site on %s", as, author.Removed)
+	}
+	return coauthor.LoadKey(s.DataDir, as)
+}
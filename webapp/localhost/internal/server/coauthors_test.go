@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/coauthor"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+func TestHandlePublish_AsCoauthor(t *testing.T) {
+	s := newConfiguredServer(t)
+	s.LoadEnv()
+
+	pub, err := coauthor.Generate(s.DataDir, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	author, err := coauthor.New("alice", pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk, err := site.LoadWellKnown(s.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk.Authors = append(wk.Authors, *author)
+	if err := site.SaveWellKnown(s.DataDir, wk); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleCoauthors(rr, httptest.NewRequest(http.MethodGet, "/api/coauthors", nil))
+	var list struct {
+		Authors []struct {
+			Name    string `json:"name"`
+			KeyHere bool   `json:"key_here"`
+		} `json:"authors"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Authors) != 1 || list.Authors[0].Name != "alice" || !list.Authors[0].KeyHere {
+		t.Fatalf("GET /api/coauthors = %s", rr.Body.String())
+	}
+
+	publish := func(as string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/publish", jsonBody(t, map[string]string{
+			"markdown": "# By " + as + "\n\nHello.",
+			"as":       as,
+		}))
+		rr := httptest.NewRecorder()
+		s.handlePublish(rr, req)
+		return rr
+	}
+
+	rr = publish("alice")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("publish as alice: status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Path string `json:"path"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	data, err := os.ReadFile(filepath.Join(s.DataDir, resp.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\nsigned-by: alice\n") {
+		t.Errorf("post doesn't name its author:\n%s", data)
+	}
+
+	if rr = publish("bob"); rr.Code != http.StatusBadRequest {
+		t.Errorf("publish as an unlisted author: status %d, want 400", rr.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/bluesky"
+	"github.com/vdibart/polis-cli/cli-go/pkg/ipfs"
+	"github.com/vdibart/polis-cli/cli-go/pkg/nostr"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/timestamp"
+)
+
+// BlueskyConfig returns the Bluesky account new posts are cross-posted to,
+// from the bluesky.* settings. It is disabled until a handle and app
+// password are set.
+func (s *Server) BlueskyConfig() bluesky.Config {
+	if s.Settings == nil {
+		return bluesky.Config{}
+	}
+	return bluesky.Config{
+		Handle:      s.Settings.Get("bluesky.handle"),
+		AppPassword: s.Settings.Get("bluesky_app_password"),
+		PDS:         s.Settings.Get("bluesky.pds"),
+	}
+}
+
+// crosspostInBackground runs crosspost without holding up the request or
+// sync cycle that published the post: each step waits on a third-party
+// service, sometimes for minutes. Shutdown waits for it.
+func (s *Server) crosspostInBackground(result *publish.PublishResult, republished bool) {
+	s.crossposts.Add(1)
+	go func() {
+		defer s.crossposts.Done()
+		s.crosspost(result, republished)
+	}()
+}
+
+// crosspost timestamps a published or republished post when a TSA is
+// configured, mirrors it when the nostr bridge is enabled, pins it when an
+// IPFS node is configured, and cross-posts a new post to Bluesky when
+// configured. Failures are logged but don't fail the publish; polis
+// crosspost retries.
+func (s *Server) crosspost(result *publish.PublishResult, republished bool) {
+	s.timestamp(result)
+	s.mirror(result, republished)
+	s.pin(result)
+	if republished {
+		return
+	}
+	cfg := s.BlueskyConfig()
+	if !cfg.Enabled() || !result.Crosspostable() {
+		return
+	}
+	link, err := bluesky.Crosspost(s.DataDir, s.GetBaseURL(), cfg, result.Path)
+	if link != nil {
+		s.LogInfo("Cross-posted %s to Bluesky: %s", result.Path, link.URL)
+	}
+	if err != nil {
+		s.LogWarn("Bluesky cross-post of %s failed: %v", result.Path, err)
+	}
+}
+
+// mirror mirrors a post to nostr when the bridge is enabled: new public
+// posts, and republished posts mirrored before. Failures are logged; polis
+// bridge nostr sync retries.
+func (s *Server) mirror(result *publish.PublishResult, republished bool) {
+	if s.Settings == nil || !s.Settings.Bool("nostr.enabled") || !result.Crosspostable() || s.PrivateKey == nil {
+		return
+	}
+	if republished && !nostr.Mirrored(s.DataDir, result.Path) {
+		return
+	}
+	key, err := nostr.DeriveKey(s.PrivateKey)
+	if err != nil {
+		s.LogWarn("nostr key: %v", err)
+		return
+	}
+	res, err := nostr.Mirror(s.DataDir, s.GetBaseURL(), nostr.ParseRelays(s.Settings.Get("nostr.relays")), key, result.Path)
+	if res != nil {
+		for relay, msg := range res.Failed {
+			s.LogWarn("nostr relay %s refused %s: %s", relay, result.Path, msg)
+		}
+	}
+	if err != nil {
+		s.LogWarn("nostr mirror of %s failed: %v", result.Path, err)
+		return
+	}
+	s.LogInfo("Mirrored %s to nostr (%d relay(s))", result.Path, len(res.Accepted))
+}
+
+// IPFSConfig returns the IPFS node posts are pinned to, from the ipfs.*
+// settings. It is disabled until ipfs.api is set.
+func (s *Server) IPFSConfig() ipfs.Config {
+	if s.Settings == nil {
+		return ipfs.Config{}
+	}
+	return ipfs.Config{
+		API:   s.Settings.Get("ipfs.api"),
+		Token: s.Settings.Get("ipfs_api_token"),
+	}
+}
+
+// pin pins a published or republished post and its page to IPFS when a
+// node is configured, then renders the page again so it links to the copy.
+// Failures are logged; polis pin retries.
+func (s *Server) pin(result *publish.PublishResult) {
+	cfg := s.IPFSConfig()
+	if !cfg.Enabled() || !result.Crosspostable() {
+		return
+	}
+	// Pin the current page; scheduled publishes haven't been rendered yet
+	s.renderPost(result.Path, false)
+	link, err := ipfs.Pin(s.DataDir, cfg, result.Path)
+	if err != nil {
+		if !errors.Is(err, ipfs.ErrNotPublic) {
+			s.LogWarn("IPFS pin of %s failed: %v", result.Path, err)
+		}
+		return
+	}
+	s.LogInfo("Pinned %s to IPFS: %s", result.Path, link.URL)
+	s.renderPost(result.Path, true)
+}
+
+// timestamp anchors a published or republished post's version with the
+// time-stamping authority in timestamp.tsa. Failures are logged; polis
+// timestamp retries.
+func (s *Server) timestamp(result *publish.PublishResult) {
+	if s.Settings == nil || s.Settings.Get("timestamp.tsa") == "" {
+		return
+	}
+	proof, err := timestamp.Anchor(s.DataDir, s.Settings.Get("timestamp.tsa"), result.Path)
+	if err != nil {
+		s.LogWarn("Timestamping %s failed: %v", result.Path, err)
+		return
+	}
+	s.LogInfo("Timestamped %s at %s", result.Path, proof.Time.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+// renderPost renders one post's page, unless it is up to date and force is
+// not set.
+func (s *Server) renderPost(path string, force bool) {
+	renderer, err := render.NewPageRenderer(render.PageConfig{
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         s.GetBaseURL(),
+		DiscoveryDomain: s.GetDiscoveryDomain(),
+	})
+	if err == nil {
+		_, _, err = renderer.RenderFile(path, "post", force)
+	}
+	if err != nil {
+		s.LogWarn("render of %s failed: %v", path, err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/bluesky"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+)
+
+func TestHandlePublish_CrosspostsToBluesky(t *testing.T) {
+	var mu sync.Mutex
+	var created []string
+	pds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xrpc/com.atproto.server.createSession":
+			json.NewEncoder(w).Encode(map[string]string{"accessJwt": "jwt", "handle": "me.bsky.social", "did": "did:plc:me"})
+		case "/xrpc/com.atproto.repo.createRecord":
+			var req struct {
+				Record bluesky.Post `json:"record"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			created = append(created, req.Record.Text)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]string{"uri": "at://did:plc:me/app.bsky.feed.post/abc", "cid": "bafy"})
+		}
+	}))
+	defer pds.Close()
+
+	t.Setenv("POLIS_BLUESKY_HANDLE", "me.bsky.social")
+	t.Setenv("POLIS_BLUESKY_PDS", pds.URL)
+	t.Setenv("BLUESKY_APP_PASSWORD", "app-pass")
+	s := newConfiguredServer(t)
+	s.LoadEnv()
+
+	publish := func(markdown string) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/publish", jsonBody(t, map[string]string{"markdown": markdown}))
+		rr := httptest.NewRecorder()
+		s.handlePublish(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("publish: status %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Path string `json:"path"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Path
+	}
+
+	path := publish("# Shared\n\nHello Bluesky.")
+	publish("---\ncrosspost: false\n---\n# Kept here\n\nNot shared.")
+	publish("---\nunlisted: true\n---\n# Quiet\n\nNot shared either.")
+	// Cross-posting runs after the response
+	s.crossposts.Wait()
+
+	if len(created) != 1 {
+		t.Fatalf("created %d Bluesky posts, want 1: %q", len(created), created)
+	}
+	synd, _ := metadata.LoadSyndication(s.DataDir)
+	if link, ok := synd.Link(path, bluesky.Service); !ok || link.URL != "https://bsky.app/profile/me.bsky.social/post/abc" {
+		t.Errorf("syndication.json = %+v", synd.Posts)
+	}
+}
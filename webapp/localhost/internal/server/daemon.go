@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/daemon"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+// syncStats are the background sync counters reported by `polis daemon status`.
+type syncStats struct {
+	Started            time.Time
+	LastSync           time.Time
+	Cycles             int
+	NewNotifications   int
+	NewFeedItems       int
+	ScheduledPublished int
+	LastError          string
+}
+
+// recordSync adds one completed sync cycle to the server's counters.
+func (s *Server) recordSync(r SyncResult) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats.LastSync = time.Now()
+	s.stats.Cycles++
+	s.stats.NewNotifications += r.NewNotifications
+	s.stats.NewFeedItems += r.NewFeedItems
+}
+
+// recordSyncError remembers the most recent background failure.
+func (s *Server) recordSyncError(err error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats.LastError = err.Error()
+}
+
+// publishScheduledDrafts publishes post drafts whose publish_at time has
+// passed, then deletes the drafts. Called after every sync cycle.
+func (s *Server) publishScheduledDrafts() {
+	if s.PrivateKey == nil {
+		return
+	}
+	due, err := publish.DueDrafts(s.DataDir, time.Now())
+	if err != nil {
+		s.LogWarn("scheduled publish: %v", err)
+	}
+
+	published := 0
+	for _, d := range due {
+		result, err := publish.PublishDraft(s.DataDir, d.ID, "", "", false, s.PrivateKey, s.DiscoveryConfig())
+		if err != nil && result == nil {
+			s.LogError("scheduled publish of %s failed: %v", d.ID, err)
+			s.recordSyncError(fmt.Errorf("scheduled publish of %s: %w", d.ID, err))
+			continue
+		}
+		if err != nil {
+			s.LogWarn("scheduled publish of %s: %v", d.ID, err)
+		}
+		s.LogInfo("Published scheduled post: %s (title: %s)", result.Path, result.Title)
+		s.runPostPublishHook(result)
+		s.crosspostInBackground(result, false)
+		published++
+	}
+	if published == 0 {
+		return
+	}
+
+	s.statsMu.Lock()
+	s.stats.ScheduledPublished += published
+	s.statsMu.Unlock()
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-publish render failed: %v", err)
+	}
+	s.broadcastCounts(SyncResult{FilesChanged: true})
+}
+
+// daemonController exposes a Server to the daemon control socket.
+type daemonController struct {
+	server   *Server
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (c *daemonController) Status() daemon.Status {
+	s := c.server
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	st := daemon.Status{
+		PID:                os.Getpid(),
+		DataDir:            s.DataDir,
+		Version:            s.CLIVersion,
+		StartedAt:          s.stats.Started.UTC().Format(time.RFC3339),
+		SyncCycles:         s.stats.Cycles,
+		NewNotifications:   s.stats.NewNotifications,
+		NewFeedItems:       s.stats.NewFeedItems,
+		ScheduledPublished: s.stats.ScheduledPublished,
+		LastError:          s.stats.LastError,
+	}
+	if !s.stats.LastSync.IsZero() {
+		st.LastSync = s.stats.LastSync.UTC().Format(time.RFC3339)
+	}
+	return st
+}
+
+func (c *daemonController) TriggerSync() { c.server.TriggerSync() }
+
+func (c *daemonController) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// RunDaemon runs the background sync loop without the web UI, controlled
+// through the daemon socket (see pkg/daemon). It returns after SIGINT,
+// SIGTERM, or `polis daemon stop`.
+func RunDaemon(dataDir string, opts ...RunOptions) {
+	dataDir = ResolveSymlink(dataDir)
+
+	server := NewServer(dataDir, "")
+	if len(opts) > 0 && opts[0].CLIVersion != "" {
+		server.CLIVersion = opts[0].CLIVersion
+	}
+	if len(opts) > 0 {
+		server.LogLevel = opts[0].LogLevel
+	}
+	server.Initialize()
+	defer server.Close()
+
+	if server.PrivateKey == nil {
+		server.LogWarn("Site is not initialized in %s; the daemon will idle until it is", dataDir)
+	}
+
+	ln, err := daemon.Listen(dataDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server.statsMu.Lock()
+	server.stats.Started = time.Now()
+	server.statsMu.Unlock()
+	server.StartBackgroundSync()
+
+	ctrl := &daemonController{server: server, stop: make(chan struct{})}
+	ctrlServer := &http.Server{Handler: daemon.Handler(ctrl)}
+	go func() {
+		if err := ctrlServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			server.LogError("control socket: %v", err)
+		}
+	}()
+
+	// Without the web UI there is no /beacon unless analytics.listen
+	// gives the daemon an address to accept view reports on
+	var beaconServer *http.Server
+	if addr := server.analyticsListenAddr(); addr != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/beacon", server.handleBeacon)
+		beaconServer = &http.Server{Addr: addr, Handler: mux}
+		go func() {
+			if err := beaconServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				server.LogError("view beacon: %v", err)
+			}
+		}()
+	}
+
+	fmt.Printf("[i] Polis daemon running (pid %d)\n", os.Getpid())
+	fmt.Printf("[i] Data directory: %s\n", dataDir)
+	fmt.Printf("[i] Control socket: %s\n", daemon.SocketPath(dataDir))
+	if beaconServer != nil {
+		fmt.Printf("[i] View beacon: http://%s/beacon\n", beaconServer.Addr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+	case <-ctrl.stop:
+	}
+	stop()
+
+	fmt.Printf("[i] Shutting down...\n")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	ctrlServer.Shutdown(shutdownCtx)
+	if beaconServer != nil {
+		beaconServer.Shutdown(shutdownCtx)
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.LogWarn("Shutdown: %v", err)
+	}
+	fmt.Printf("[✓] Daemon stopped\n")
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/explore"
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/graph"
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+)
+
+// handleDiscover returns a page of recent public posts by authors we don't
+// follow, from the discovery service's stream. Following one is a POST to
+// /api/following.
+// GET /api/discover?sort=newest|trending&before=<cursor>&limit=20
+func (s *Server) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.DiscoveryURL == "" {
+		http.Error(w, "Discovery service not configured", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit > 100 {
+		limit = 100
+	}
+
+	exclude := []string{discovery.ExtractDomainFromURL(s.GetBaseURL())}
+	if f, err := s.cachedFollowing(); err == nil {
+		for _, entry := range f.All() {
+			exclude = append(exclude, discovery.ExtractDomainFromURL(entry.URL))
+		}
+	}
+	filters, _ := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain()).LoadFilters()
+
+	page, err := explore.Query(discovery.NewClient(s.DiscoveryURL, s.DiscoveryKey), explore.Options{
+		Sort:    q.Get("sort"),
+		Before:  q.Get("before"),
+		Limit:   limit,
+		Exclude: exclude,
+		Filters: filters,
+	})
+	if errors.Is(err, explore.ErrInvalidOption) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.LogWarn("discover query failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// handleDiscoverSuggestions suggests sites to follow: those whose posts the
+// authors we follow comment on, each with the reason it is suggested.
+// GET /api/discover/suggestions?limit=10
+func (s *Server) handleDiscoverSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	self := site.GetAuthorDomain(s.DataDir)
+	if self == "" {
+		http.Error(w, "Not configured - please complete setup first", http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 10
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"suggestions": graph.Suggest(s.DataDir, s.GetDiscoveryDomain(), self, limit),
+	})
+}
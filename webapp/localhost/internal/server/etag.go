@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Conditional GETs for the endpoints the web UI polls. Each response
+// carries an ETag; a request whose If-None-Match matches it gets an empty
+// 304 instead of the payload.
+//
+// Endpoints whose payload comes from a known set of files (posts, feed)
+// derive the tag from those files' sizes and modification times, so a 304
+// costs a few stats. The rest (counts, settings) draw on too many sources
+// to list and tag a hash of the encoded payload, which still saves the
+// transfer and the client's re-render.
+
+// fileETag returns a weak ETag that changes whenever the size or
+// modification time of any of paths does, or any of params. A missing
+// file counts as a distinct state.
+func fileETag(paths []string, params ...string) string {
+	h := sha256.New()
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", p, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(h, "%s\x00-\n", p)
+		}
+	}
+	for _, p := range params {
+		fmt.Fprintf(h, "%s\n", p)
+	}
+	return formatETag(h)
+}
+
+func formatETag(h hash.Hash) string {
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// notModified sets the ETag header and reports whether the request already
+// has that version, in which case it writes the 304 and the caller is done.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	// Let the browser keep the body, but revalidate before each use
+	w.Header().Set("Cache-Control", "no-cache")
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using
+// the weak comparison RFC 9110 specifies for it.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONWithETag encodes v as the JSON response, tagged with a hash of
+// the encoding, or answers 304 if the client already has it.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h := sha256.New()
+	h.Write(buf.Bytes())
+	if notModified(w, r, formatETag(h)) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
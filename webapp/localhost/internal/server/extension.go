@@ -0,0 +1,329 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+)
+
+// Browser extensions call the local server cross-origin. Only origins in the
+// extension_origins setting get CORS headers, and they may only reach the
+// widget APIs, with a token from /api/ext/handshake that is limited to the
+// scopes it was issued for. Cross-origin requests from any other origin are
+// rejected; same-origin requests and requests without an Origin header (the
+// dashboard, curl, the CLI) are unaffected.
+
+// ExtensionTokensFilename holds issued extension tokens, relative to .polis/.
+const ExtensionTokensFilename = "extension-tokens.json"
+
+// Extension token scopes.
+const (
+	ScopeState   = "state"   // GET /api/widget/state
+	ScopeComment = "comment" // /api/widget/comment and /api/widget/publish
+	ScopeFollow  = "follow"  // /api/widget/follow
+)
+
+// ExtensionScopes lists every scope in the order they are reported.
+var ExtensionScopes = []string{ScopeState, ScopeComment, ScopeFollow}
+
+// extensionRoutes maps the API paths extensions may call to the scope they require.
+var extensionRoutes = map[string]string{
+	"/api/widget/state":   ScopeState,
+	"/api/widget/comment": ScopeComment,
+	"/api/widget/publish": ScopeComment,
+	"/api/widget/follow":  ScopeFollow,
+}
+
+func validExtensionScope(scope string) bool {
+	for _, sc := range ExtensionScopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtensionToken is an issued token. Only the SHA-256 of the token is stored.
+type ExtensionToken struct {
+	Hash      string   `json:"hash"`
+	Origin    string   `json:"origin"`
+	Name      string   `json:"name,omitempty"`
+	Scopes    []string `json:"scopes"`
+	IssuedBy  string   `json:"issued_by,omitempty"` // API user who approved the handshake; empty when the site had none
+	CreatedAt string   `json:"created_at"`
+}
+
+// allows reports whether the token was issued with scope.
+func (t *ExtensionToken) allows(scope string) bool {
+	for _, sc := range t.Scopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// extensionTokensMu serializes read-modify-write of the tokens file.
+var extensionTokensMu sync.Mutex
+
+func extensionTokensPath(dataDir string) string {
+	return filepath.Join(dataDir, ".polis", ExtensionTokensFilename)
+}
+
+func hashExtensionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadExtensionTokens(dataDir string) ([]ExtensionToken, error) {
+	data, err := os.ReadFile(extensionTokensPath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read extension tokens: %w", err)
+	}
+	var tokens []ExtensionToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse extension tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func saveExtensionTokens(dataDir string, tokens []ExtensionToken) error {
+	if tokens == nil {
+		tokens = []ExtensionToken{}
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal extension tokens: %w", err)
+	}
+	path := extensionTokensPath(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .polis directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write extension tokens: %w", err)
+	}
+	return nil
+}
+
+// ExtensionOrigins returns the origins allowed by the extension_origins
+// setting (comma or whitespace separated).
+func (s *Server) ExtensionOrigins() []string {
+	if s.Settings == nil {
+		return nil
+	}
+	return strings.FieldsFunc(s.Settings.Get("extension_origins"), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// extensionOriginAllowed reports whether origin is in the allowlist.
+func (s *Server) extensionOriginAllowed(origin string) bool {
+	for _, o := range s.ExtensionOrigins() {
+		if strings.TrimRight(o, "/") == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin reports whether the Origin header names the host the request was sent to.
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// findExtensionToken returns the token issued to origin, or nil.
+func (s *Server) findExtensionToken(token, origin string) *ExtensionToken {
+	if token == "" {
+		return nil
+	}
+	tokens, err := loadExtensionTokens(s.DataDir)
+	if err != nil {
+		s.LogError("Failed to load extension tokens: %v", err)
+		return nil
+	}
+	hash := hashExtensionToken(token)
+	for i, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 && t.Origin == origin {
+			return &tokens[i]
+		}
+	}
+	return nil
+}
+
+// extensionTokenAllows reports whether token was issued to origin with scope.
+func (s *Server) extensionTokenAllows(token, origin, scope string) bool {
+	t := s.findExtensionToken(token, origin)
+	return t != nil && t.allows(scope)
+}
+
+// WithCORS applies the extension origin policy to API requests.
+func (s *Server) WithCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !strings.HasPrefix(r.URL.Path, "/api/") || sameOrigin(r, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.extensionOriginAllowed(origin) {
+			s.LogWarn("Blocked cross-origin %s %s from %s", r.Method, r.URL.Path, origin)
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+		if r.Method == http.MethodOptions {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+SiteHeader)
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		path := unversionedPath(r.URL.Path)
+		if path == "/api/ext/handshake" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		scope, ok := extensionRoutes[path]
+		if !ok {
+			http.Error(w, "Not available to extensions", http.StatusForbidden)
+			return
+		}
+		if !s.extensionTokenAllows(bearerToken(r), origin, scope) {
+			http.Error(w, "Extension token missing or lacks the "+scope+" scope", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// POST /api/ext/handshake — Issue a scoped token to an allowlisted extension origin.
+// Body: {"name": "...", "scopes": ["state", "comment", "follow"]} (scopes default to all).
+// Once the site has API users, the request must carry an admin's token.
+// DELETE /api/ext/handshake — Revoke the bearer token the request carries.
+func (s *Server) handleExtHandshake(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || !s.extensionOriginAllowed(origin) {
+		http.Error(w, "Origin not in extension_origins", http.StatusForbidden)
+		return
+	}
+
+	extensionTokensMu.Lock()
+	defer extensionTokensMu.Unlock()
+
+	tokens, err := loadExtensionTokens(s.DataDir)
+	if err != nil {
+		s.LogError("Failed to load extension tokens: %v", err)
+		http.Error(w, "Failed to load extension tokens", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		hash := hashExtensionToken(bearerToken(r))
+		kept := tokens[:0]
+		revoked := false
+		for _, t := range tokens {
+			if t.Hash == hash && t.Origin == origin {
+				revoked = true
+				continue
+			}
+			kept = append(kept, t)
+		}
+		if !revoked {
+			http.Error(w, "Token not found", http.StatusNotFound)
+			return
+		}
+		if err := saveExtensionTokens(s.DataDir, kept); err != nil {
+			s.LogError("Failed to save extension tokens: %v", err)
+			http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+			return
+		}
+		s.LogInfo("Revoked extension token for %s", origin)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		return
+	}
+
+	var req struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = ExtensionScopes
+	}
+	for _, sc := range scopes {
+		if !validExtensionScope(sc) {
+			http.Error(w, fmt.Sprintf("Unknown scope %q (expected %s)", sc, strings.Join(ExtensionScopes, ", ")), http.StatusBadRequest)
+			return
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+	token := hex.EncodeToString(b)
+	issued := ExtensionToken{
+		Hash:      hashExtensionToken(token),
+		Origin:    origin,
+		Name:      req.Name,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if u := accessUser(r); u != nil {
+		issued.IssuedBy = u.Name
+	}
+	tokens = append(tokens, issued)
+	if err := saveExtensionTokens(s.DataDir, tokens); err != nil {
+		s.LogError("Failed to save extension tokens: %v", err)
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	s.LogInfo("Issued extension token for %s (scopes: %s)", origin, strings.Join(scopes, ", "))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":    token,
+		"scopes":   scopes,
+		"site_url": s.GetBaseURL(),
+		"author":   s.GetAuthorDomain(),
+	})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+)
+
+const testExtensionOrigin = "chrome-extension://abcdefghijklmnop"
+
+// newExtensionServer returns a server that allows testExtensionOrigin, and
+// its routes wrapped in the CORS policy.
+func newExtensionServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	s := newConfiguredServer(t)
+	settings, err := config.Load(config.Options{DataDir: s.DataDir, Flags: map[string]string{
+		"extension_origins": "https://other.example.com, " + testExtensionOrigin,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Settings = settings
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+	return s, s.WithCORS(mux)
+}
+
+func extensionRequest(method, target, origin, token, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestWithCORS_BlocksOtherOrigins(t *testing.T) {
+	_, h := newExtensionServer(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodGet, "/api/status", "https://evil.example.com", "", ""))
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("foreign origin: got %d, ACAO %q", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	// Same-origin and Origin-less requests are untouched
+	req := extensionRequest(http.MethodGet, "/api/status", "http://localhost:8080", "", "")
+	req.Host = "localhost:8080"
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("same origin: got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodGet, "/api/status", "", "", ""))
+	if w.Code != http.StatusOK {
+		t.Errorf("no origin: got %d", w.Code)
+	}
+}
+
+func TestWithCORS_Preflight(t *testing.T) {
+	_, h := newExtensionServer(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodOptions, "/api/widget/state", testExtensionOrigin, "", ""))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != testExtensionOrigin ||
+		!strings.Contains(w.Header().Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Errorf("unexpected CORS headers: %v", w.Header())
+	}
+}
+
+func TestExtHandshake_ScopedToken(t *testing.T) {
+	s, h := newExtensionServer(t)
+
+	// Only allowlisted origins get a token
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodPost, "/api/ext/handshake", "", "", `{}`))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("handshake without origin: got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodPost, "/api/ext/handshake", testExtensionOrigin, "", `{"scopes":["admin"]}`))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown scope: got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodPost, "/api/ext/handshake", testExtensionOrigin, "", `{"name":"Polis for Chrome","scopes":["state"]}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("handshake: got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token  string   `json:"token"`
+		Scopes []string `json:"scopes"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Token == "" || len(resp.Scopes) != 1 {
+		t.Fatalf("unexpected handshake response: %+v", resp)
+	}
+	if data, _ := os.ReadFile(extensionTokensPath(s.DataDir)); strings.Contains(string(data), resp.Token) {
+		t.Error("token should be stored hashed")
+	}
+
+	for _, tc := range []struct {
+		name, method, path, origin, token string
+		want                              int
+	}{
+		{"no token", http.MethodGet, "/api/widget/state?author=alice.example.com", testExtensionOrigin, "", http.StatusUnauthorized},
+		{"in scope", http.MethodGet, "/api/widget/state?author=alice.example.com", testExtensionOrigin, resp.Token, http.StatusOK},
+		{"in scope, versioned", http.MethodGet, "/api/v1/widget/state?author=alice.example.com", testExtensionOrigin, resp.Token, http.StatusOK},
+		{"out of scope", http.MethodPost, "/api/widget/follow", testExtensionOrigin, resp.Token, http.StatusUnauthorized},
+		{"other origin", http.MethodGet, "/api/widget/state?author=alice.example.com", "https://other.example.com", resp.Token, http.StatusUnauthorized},
+		{"non-widget API", http.MethodGet, "/api/posts", testExtensionOrigin, resp.Token, http.StatusForbidden},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, extensionRequest(tc.method, tc.path, tc.origin, tc.token, `{}`))
+		if w.Code != tc.want {
+			t.Errorf("%s: got %d, want %d: %s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+
+	// Revoking the token locks the extension out again
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodDelete, "/api/ext/handshake", testExtensionOrigin, resp.Token, ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke: got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, extensionRequest(http.MethodGet, "/api/widget/state?author=alice.example.com", testExtensionOrigin, resp.Token, ""))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: got %d", w.Code)
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// maxProfileFetches caps the follower .well-known/polis fetches made while
+// answering one request; the rest are fetched on later requests.
+const maxProfileFetches = 3
+
+// fetchFollowerProfiles fills in site titles for followers that have no
+// cached profile yet.
+func (s *Server) fetchFollowerProfiles(domains []string) author.FollowerProfiles {
+	return author.FetchFollowerProfiles(s.DataDir, s.GetDiscoveryDomain(), domains, remote.NewClient(), maxProfileFetches)
+}
+
+// handleFollowers returns a page of followers, newest first.
+// GET /api/followers?offset=0&limit=50
+func (s *Server) handleFollowers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	all := author.ListFollowers(s.DataDir, s.GetDiscoveryDomain())
+	page := []author.Follower{}
+	if offset < len(all) {
+		page = all[offset:min(offset+limit, len(all))]
+	}
+
+	// Site titles are fetched lazily, a few per request
+	var missing []string
+	for _, f := range page {
+		if f.SiteTitle == "" {
+			missing = append(missing, f.Domain)
+		}
+	}
+	if len(missing) > 0 {
+		author.ApplyProfiles(page, s.fetchFollowerProfiles(missing))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"followers": page,
+		"total":     len(all),
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// handleFollower returns one follower with everything known locally about
+// them, or follows them back.
+// GET  /api/followers/{domain}
+// POST /api/followers/{domain}/follow-back
+func (s *Server) handleFollower(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/followers/")
+	domain, action, _ := strings.Cut(rest, "/")
+	domain = strings.ToLower(domain)
+	if !author.ValidDomain(domain) {
+		http.Error(w, "Invalid domain", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "follow-back" && r.Method == http.MethodPost:
+	case action == "" || action == "follow-back":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var follower *author.Follower
+	for _, f := range author.ListFollowers(s.DataDir, s.GetDiscoveryDomain()) {
+		if f.Domain == domain {
+			follower = &f
+			break
+		}
+	}
+	if follower == nil {
+		http.Error(w, "Not a follower", http.StatusNotFound)
+		return
+	}
+
+	if action == "follow-back" {
+		s.handleFollowBack(w, follower)
+		return
+	}
+
+	if follower.SiteTitle == "" {
+		followers := []author.Follower{*follower}
+		author.ApplyProfiles(followers, s.fetchFollowerProfiles([]string{domain}))
+		follower = &followers[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"follower": follower,
+		"summary":  author.Summarize(s.DataDir, s.GetDiscoveryDomain(), domain),
+	})
+}
+
+// handleFollowBack follows a follower's site (blessing their comments, as
+// POST /api/following does).
+func (s *Server) handleFollowBack(w http.ResponseWriter, follower *author.Follower) {
+	if s.PrivateKey == nil {
+		http.Error(w, "Not configured: no private key", http.StatusBadRequest)
+		return
+	}
+	if follower.FollowingBack {
+		http.Error(w, "Already following "+follower.Domain, http.StatusConflict)
+		return
+	}
+
+	ownDomain := discovery.ExtractDomainFromURL(s.GetBaseURL())
+	discoveryClient := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, ownDomain, s.PrivateKey)
+	result, err := following.FollowWithBlessing(following.DefaultPath(s.DataDir), follower.URL, discoveryClient, remote.NewClient(), s.PrivateKey, s.streamConfig())
+	if err != nil {
+		s.LogError("follow back failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.LogInfo("Followed back %s (blessed %d comments)", follower.URL, result.CommentsBlessed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    result,
+	})
+
+	go s.syncFeed()
+}
+
+// addFollowerTitles fills in the site_title of follow notifications whose
+// follower's title wasn't known when the notification was created.
+func (s *Server) addFollowerTitles(items []notification.StateEntry) {
+	var missing []string
+	for _, item := range items {
+		if _, ok := item.Payload["domain"]; ok && item.Payload["site_title"] == nil {
+			missing = append(missing, item.Actor)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	titles := s.fetchFollowerProfiles(missing).SiteTitles()
+	for _, item := range items {
+		if title := titles[item.Actor]; title != "" && item.Payload["domain"] != nil && item.Payload["site_title"] == nil {
+			item.Payload["site_title"] = title
+		}
+	}
+}
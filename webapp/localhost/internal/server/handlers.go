@@ -2,36 +2,58 @@ package server
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
 	"github.com/vdibart/polis-cli/cli-go/pkg/blessing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/bookmark"
 	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
+	"github.com/vdibart/polis-cli/cli-go/pkg/deploy"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/graph"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/identity"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
 	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/reconcile"
 	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
 	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/rss"
+	"github.com/vdibart/polis-cli/cli-go/pkg/sanitize"
+	"github.com/vdibart/polis-cli/cli-go/pkg/schedule"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stats"
 	"github.com/vdibart/polis-cli/cli-go/pkg/snippet"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
 	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+	"github.com/vdibart/polis-cli/cli-go/pkg/thread"
+	"github.com/vdibart/polis-cli/cli-go/pkg/trash"
 	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 )
 
@@ -41,10 +63,15 @@ var draftIDSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
 // validatePostPath ensures the path is safe and within the posts directory.
 // This prevents path traversal attacks that could read/write arbitrary files.
 func validatePostPath(path string) error {
-	// Canonicalize first to normalize encoded traversals (e.g., ./, //)
-	path = filepath.Clean(path)
-	// Must start with "posts/"
-	if !strings.HasPrefix(path, "posts/") {
+	// No absolute paths or drive letters (C:\, /etc)
+	if fsutil.IsAbsPath(path) {
+		return fmt.Errorf("invalid path: must be relative")
+	}
+	// Canonicalize first to normalize encoded traversals (e.g., ./, //) and
+	// Windows separators
+	path = fsutil.SlashPath(path)
+	// Must start with "posts/" (or "protected/posts/" for followers-only posts)
+	if !strings.HasPrefix(path, "posts/") && !strings.HasPrefix(path, publish.ProtectedDir+"/posts/") {
 		return fmt.Errorf("invalid path: must be under posts/")
 	}
 	// No path traversal sequences
@@ -61,8 +88,13 @@ func validatePostPath(path string) error {
 // validateContentPath ensures the path is safe and within allowed directories.
 // This prevents path traversal attacks.
 func validateContentPath(path string) error {
-	// Canonicalize first to normalize encoded traversals (e.g., ./, //)
-	path = filepath.Clean(path)
+	// No absolute paths or drive letters (C:\, /etc)
+	if fsutil.IsAbsPath(path) {
+		return fmt.Errorf("invalid path: must be relative")
+	}
+	// Canonicalize first to normalize encoded traversals (e.g., ./, //) and
+	// Windows separators
+	path = fsutil.SlashPath(path)
 	// No path traversal sequences
 	if strings.Contains(path, "..") {
 		return fmt.Errorf("invalid path: traversal not allowed")
@@ -130,8 +162,17 @@ func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
 
 	validation := site.Validate(s.DataDir)
 
+	// Posts deployed from another copy of the site, from the last check
+	conflicts, err := conflict.LoadReport(s.DataDir)
+	if err != nil {
+		s.LogWarn("conflict report load failed: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(validation)
+	json.NewEncoder(w).Encode(struct {
+		*site.ValidationResult
+		Conflicts *conflict.Report `json:"conflicts"`
+	}{validation, conflicts})
 }
 
 // handleInit initializes a new polis site in the data directory.
@@ -210,7 +251,7 @@ func (s *Server) handleInit(w http.ResponseWriter, r *http.Request) {
 	}
 	s.ApplyDiscoveryDefaults()
 	if err := s.SaveConfig(); err != nil {
-		log.Printf("[warning] Failed to save config: %v", err)
+		s.LogWarn("Failed to save config: %v", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -249,7 +290,7 @@ func (s *Server) writeEnvFile(envPath string, vars map[string]string) {
 		for key, val := range remaining {
 			lines = append(lines, key+"="+val)
 		}
-		if err := os.WriteFile(envPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		if err := fsutil.WriteFile(envPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
 			s.LogWarn("Failed to update .env: %v", err)
 		}
 	} else {
@@ -262,21 +303,27 @@ func (s *Server) writeEnvFile(envPath string, vars map[string]string) {
 				b.WriteString(key + "=" + val + "\n")
 			}
 		}
-		if err := os.WriteFile(envPath, []byte(b.String()), 0644); err != nil {
+		if err := fsutil.WriteFile(envPath, []byte(b.String()), 0644); err != nil {
 			s.LogWarn("Failed to create .env: %v", err)
 		}
 	}
 }
 
-// handleLink creates a symlink from data/ to an existing polis site.
+// handleLink switches to an existing polis site and registers it as the
+// active workspace, so later runs of polis serve start on it too.
 func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if s.host != nil {
+		http.Error(w, errMultiSite, http.StatusConflict)
+		return
+	}
 
 	var req struct {
 		Path string `json:"path"`
+		Name string `json:"name"` // Workspace name (default: from the directory)
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
@@ -288,17 +335,7 @@ func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Expand ~ to home directory
-	targetPath := req.Path
-	if strings.HasPrefix(targetPath, "~/") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			targetPath = filepath.Join(home, targetPath[2:])
-		}
-	}
-
-	// Convert to absolute path
-	targetPath, err := filepath.Abs(targetPath)
+	targetPath, err := expandHome(req.Path)
 	if err != nil {
 		http.Error(w, "Invalid path", http.StatusBadRequest)
 		return
@@ -315,58 +352,20 @@ func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the current data directory path (before it's a symlink)
-	execPath, err := os.Executable()
+	ws, err := registerSite(req.Name, targetPath)
 	if err != nil {
-		s.LogError("failed to get executable path: %v", err)
-		http.Error(w, "Failed to get executable path", http.StatusInternalServerError)
-		return
-	}
-	linkPath := filepath.Join(filepath.Dir(execPath), "data")
-
-	// Safety check: refuse if data/ already has content
-	entries, err := os.ReadDir(linkPath)
-	if err == nil && len(entries) > 0 {
-		// Check if it's already a symlink pointing somewhere
-		info, err := os.Lstat(linkPath)
-		if err == nil && info.Mode()&os.ModeSymlink != 0 {
-			// It's already a symlink - we can replace it
-		} else {
-			// It's a real directory with files
-			http.Error(w, "Data directory already contains files. Remove them first or use init instead.", http.StatusConflict)
-			return
-		}
-	}
-
-	// Remove existing data directory/symlink
-	if err := os.RemoveAll(linkPath); err != nil {
-		s.LogError("failed to remove existing data directory: %v", err)
-		http.Error(w, "Failed to remove existing data directory", http.StatusInternalServerError)
-		return
-	}
-
-	// Create symlink
-	s.LogDebug("Linking to existing site: %s", targetPath)
-	if err := os.Symlink(targetPath, linkPath); err != nil {
-		s.LogError("Failed to create symlink: %v", err)
-		http.Error(w, "Failed to create symlink", http.StatusInternalServerError)
+		s.LogError("Failed to register workspace: %v", err)
+		http.Error(w, "Failed to register workspace: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	s.LogInfo("Linked to existing site: %s", targetPath)
-
-	// Update server's data directory to the resolved path
-	s.DataDir = targetPath
-
-	// Reload keys and config from the linked site
-	s.LoadKeys()
-	s.LoadConfig()
-	s.LoadEnv()
-	s.ApplyDiscoveryDefaults()
+	s.LogInfo("Linked to existing site: %s (workspace %s)", targetPath, ws.Name)
+	s.useSite(targetPath)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":    true,
 		"linked_to":  targetPath,
+		"workspace":  ws.Name,
 		"site_title": s.GetSiteTitle(),
 		"site_info":  validation.SiteInfo,
 	})
@@ -461,19 +460,14 @@ func (s *Server) handleDrafts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if req.ID == "" {
-			req.ID = fmt.Sprintf("draft-%d", time.Now().Unix())
-		}
-
-		// Sanitize ID - whitelist only safe characters
-		req.ID = draftIDSanitizer.ReplaceAllString(req.ID, "-")
-
-		draftPath := filepath.Join(draftsDir, req.ID+".md")
-		if err := os.WriteFile(draftPath, []byte(req.Markdown), 0644); err != nil {
+		// Generates an ID when empty and sanitizes the rest
+		id, err := publish.SaveDraft(s.DataDir, req.ID, req.Markdown)
+		if err != nil {
 			s.LogError("failed to save draft: %v", err)
 			http.Error(w, "Failed to save draft", http.StatusInternalServerError)
 			return
 		}
+		req.ID = id
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -487,6 +481,19 @@ func (s *Server) handleDrafts(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleDraft(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/share") {
+		s.handleDraftShare(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/export") {
+		s.handleDraftExport(w, r)
+		return
+	}
+	if strings.Contains(r.URL.Path, "/annotations") {
+		s.handleDraftAnnotations(w, r)
+		return
+	}
+
 	// Extract ID from path: /api/drafts/{id}
 	id := strings.TrimPrefix(r.URL.Path, "/api/drafts/")
 	if id == "" {
@@ -514,7 +521,12 @@ func (s *Server) handleDraft(w http.ResponseWriter, r *http.Request) {
 		})
 
 	case http.MethodDelete:
-		if err := os.Remove(draftPath); err != nil {
+		item, err := trash.Move(s.DataDir, ".polis/posts/drafts/"+id+".md")
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				http.Error(w, "Draft not found", http.StatusNotFound)
+				return
+			}
 			s.LogError("failed to delete draft: %v", err)
 			http.Error(w, "Failed to delete draft", http.StatusInternalServerError)
 			return
@@ -522,7 +534,8 @@ func (s *Server) handleDraft(w http.ResponseWriter, r *http.Request) {
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
+			"success":  true,
+			"trash_id": item.ID,
 		})
 
 	default:
@@ -530,6 +543,108 @@ func (s *Server) handleDraft(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleDraftAnnotations serves review notes on a draft:
+//
+//	GET    /api/drafts/{id}/annotations        list them
+//	POST   /api/drafts/{id}/annotations        add one {start_line, end_line, author, note}
+//	PATCH  /api/drafts/{id}/annotations/{aid}  change any of those fields, or resolved
+//	DELETE /api/drafts/{id}/annotations/{aid}  delete one
+func (s *Server) handleDraftAnnotations(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/drafts/")
+	id, annotationID, _ := strings.Cut(rest, "/annotations")
+	id = draftIDSanitizer.ReplaceAllString(id, "-")
+	annotationID = strings.TrimPrefix(annotationID, "/")
+
+	writeAnnotationError := func(err error) {
+		switch {
+		case errors.Is(err, publish.ErrDraftNotFound):
+			http.Error(w, "Draft not found", http.StatusNotFound)
+		case errors.Is(err, publish.ErrAnnotationNotFound):
+			http.Error(w, "Annotation not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && annotationID == "":
+		list, err := publish.Annotations(s.DataDir, id)
+		if err != nil {
+			writeAnnotationError(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"annotations": list})
+
+	case r.Method == http.MethodPost && annotationID == "":
+		var a publish.Annotation
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		created, err := publish.AddAnnotation(s.DataDir, id, a)
+		if err != nil {
+			writeAnnotationError(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	case r.Method == http.MethodPatch && annotationID != "":
+		var u publish.AnnotationUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		updated, err := publish.UpdateAnnotation(s.DataDir, id, annotationID, u)
+		if err != nil {
+			writeAnnotationError(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+
+	case r.Method == http.MethodDelete && annotationID != "":
+		if err := publish.DeleteAnnotation(s.DataDir, id, annotationID); err != nil {
+			writeAnnotationError(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleDraftExport serves GET /api/drafts/{id}/export: the draft's
+// markdown with its open review notes in a trailing HTML comment, as a
+// download.
+func (s *Server) handleDraftExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/drafts/"), "/export")
+	id = draftIDSanitizer.ReplaceAllString(id, "-")
+
+	content, err := publish.ExportDraft(s.DataDir, id)
+	if err != nil {
+		if errors.Is(err, publish.ErrDraftNotFound) {
+			http.Error(w, "Draft not found", http.StatusNotFound)
+			return
+		}
+		s.LogError("failed to export draft: %v", err)
+		http.Error(w, "Failed to export draft", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+id+`.md"`)
+	w.Write([]byte(content))
+}
+
 func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -542,27 +657,68 @@ func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Markdown string `json:"markdown"`
-		Filename string `json:"filename"`
+		Markdown  string `json:"markdown"`
+		Filename  string `json:"filename"`
+		DraftID   string `json:"draft_id"`       // Source draft, deleted after publishing
+		KeepDraft bool   `json:"keep_draft"`     // Keep the source draft instead
+		Unlisted  bool   `json:"unlisted"`       // Keep the post off index pages, feeds, and discovery
+		Followers bool   `json:"followers_only"` // Publish under protected/ for followers only
+		NoCross   bool   `json:"no_crosspost"`   // Don't cross-post to Bluesky
+		As        string `json:"as"`             // Co-author to sign as
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	if strings.TrimSpace(req.Markdown) == "" {
+	if strings.TrimSpace(req.Markdown) == "" && req.DraftID == "" {
 		http.Error(w, "Markdown content required", http.StatusBadRequest)
 		return
 	}
-
-	// Strip existing frontmatter if present
-	markdown := req.Markdown
-	if publish.HasFrontmatter(markdown) {
-		markdown = publish.StripFrontmatter(markdown)
+	privKey, err := s.signingKey(req.As)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	var result *publish.PublishResult
 	s.LogDebug("Publishing post with filename: %s", req.Filename)
-	result, err := publish.PublishPost(s.DataDir, markdown, req.Filename, s.PrivateKey, s.DiscoveryConfig())
+	if req.DraftID != "" {
+		override := publish.PublishOptions{Slug: req.Filename, Unlisted: req.Unlisted, FollowersOnly: req.Followers, NoCrosspost: req.NoCross}
+		result, err = publish.PublishDraftWithOptions(s.DataDir, req.DraftID, req.Markdown, req.KeepDraft, override, privKey, s.DiscoveryConfig())
+		if errors.Is(err, publish.ErrDraftNotFound) {
+			http.Error(w, "Draft not found", http.StatusNotFound)
+			return
+		}
+		if err != nil && result != nil {
+			// Published, but the draft couldn't be removed
+			s.LogWarn("%v", err)
+			err = nil
+		}
+	} else {
+		// Strip existing frontmatter if present (slug, summary, lang,
+		// translation_of, unlisted, and visibility: followers carry over)
+		markdown := req.Markdown
+		opts := publish.SourceOptions(markdown)
+		if req.Filename != "" {
+			opts.Slug = req.Filename
+		}
+		opts.Unlisted = opts.Unlisted || req.Unlisted
+		opts.FollowersOnly = opts.FollowersOnly || req.Followers
+		opts.NoCrosspost = opts.NoCrosspost || req.NoCross
+		if err := opts.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if publish.HasFrontmatter(markdown) {
+			markdown = publish.StripFrontmatter(markdown)
+		}
+		result, err = publish.PublishPostWithOptions(s.DataDir, markdown, opts, privKey, s.DiscoveryConfig())
+	}
+	if errors.Is(err, publish.ErrInvalidTranslation) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		s.LogError("Failed to publish: %v", err)
 		http.Error(w, "Failed to publish", http.StatusInternalServerError)
@@ -573,81 +729,115 @@ func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
 	// Render site to generate HTML files
 	if err := s.RenderSite(); err != nil {
 		// Log but don't fail - the post was published successfully
-		log.Printf("[warning] post-publish render failed: %v", err)
+		s.LogWarn("post-publish render failed: %v", err)
 	}
 
-	// Run post-publish hook (checks explicit config, then auto-discovers .polis/hooks/)
-	{
-		var hc *hooks.HookConfig
-		if s.Config != nil {
-			hc = s.Config.Hooks
-		}
-		payload := &hooks.HookPayload{
-			Event:         hooks.EventPostPublish,
-			Path:          result.Path,
-			Title:         result.Title,
-			Version:       result.Version,
-			Timestamp:     time.Now().UTC().Format("2006-01-02T15:04:05Z"),
-			CommitMessage: hooks.GenerateCommitMessage(hooks.EventPostPublish, result.Title),
-		}
-		hookResult, err := hooks.RunHook(s.DataDir, hc, payload)
-		if err != nil {
-			// Log hook error but don't fail the publish
-			log.Printf("[warning] post-publish hook failed: %v", err)
-			s.LogWarn("Post-publish hook failed: %v", err)
-		}
-		if hookResult != nil && hookResult.Executed {
-			log.Printf("[info] post-publish hook executed: %s", hookResult.Output)
-			s.LogInfo("Post-publish hook executed: %s", hookResult.Output)
-		}
-	}
+	s.runPostPublishHook(result)
+	s.crosspostInBackground(result, false)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
+// runPostPublishHook runs the post-publish hook (checks explicit config, then
+// auto-discovers .polis/hooks/). Hook failures are logged, not returned.
+func (s *Server) runPostPublishHook(result *publish.PublishResult) {
+	var hc *hooks.HookConfig
+	if s.Config != nil {
+		hc = s.Config.Hooks
+	}
+	payload := &hooks.HookPayload{
+		Event:         hooks.EventPostPublish,
+		Path:          result.Path,
+		Title:         result.Title,
+		Version:       result.Version,
+		Timestamp:     time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		CommitMessage: hooks.GenerateCommitMessage(hooks.EventPostPublish, result.Title),
+	}
+	hookResult, err := hooks.RunHook(s.DataDir, hc, payload)
+	if err != nil {
+		// Log hook error but don't fail the publish
+		s.LogWarn("Post-publish hook failed: %v", err)
+	}
+	if hookResult != nil && hookResult.Executed {
+		s.LogInfo("Post-publish hook executed: %s", hookResult.Output)
+	}
+}
+
 func (s *Server) handlePosts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Read posts from public.jsonl
-	indexPath := filepath.Join(s.DataDir, "metadata", "public.jsonl")
-	data, err := os.ReadFile(indexPath)
-	if err != nil {
-		// No posts yet
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"posts": []interface{}{},
-		})
+	// ?lang=fr lists only posts in that language (fr, fr-CA, ...); posts
+	// without a lang field are in the site's language
+	langFilter := strings.TrimSpace(r.URL.Query().Get("lang"))
+	if langFilter != "" {
+		if _, err := publish.NormalizeLang(langFilter); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if notModified(w, r, fileETag([]string{
+		filepath.Join(s.DataDir, "metadata", metadata.PublicIndexFilename),
+		filepath.Join(s.DataDir, ".polis", metadata.UnlistedIndexFilename),
+		filepath.Join(s.DataDir, ".well-known", "polis"), // Site language
+	}, langFilter)) {
 		return
 	}
+	siteLang := s.GetSiteLang()
 
-	var posts []map[string]interface{}
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		var entry map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			continue
+	// Read posts from public.jsonl, then unlisted posts from
+	// .polis/unlisted.jsonl
+	posts := []map[string]interface{}{}
+	for _, unlisted := range []bool{false, true} {
+		data, err := s.cachedIndexFile(unlisted)
+		if err != nil {
+			continue // No posts yet
 		}
-		// Filter out comments - only include posts
-		if path, ok := entry["path"].(string); ok {
+		lines := strings.Split(string(data), "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			line := strings.TrimSpace(lines[i])
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				continue
+			}
+			// Filter out comments - only include posts
+			path, _ := entry["path"].(string)
 			if strings.HasPrefix(path, "comments/") {
 				continue
 			}
+			if lang, _ := entry["lang"].(string); lang == "" {
+				entry["lang"] = siteLang
+			}
+			if langFilter != "" && !publish.MatchLang(entry["lang"].(string), langFilter) {
+				continue
+			}
+			// Entries written before summaries existed get one from the post
+			if summary, _ := entry["summary"].(string); summary == "" && path != "" {
+				if content, err := os.ReadFile(filepath.Join(s.DataDir, filepath.FromSlash(path))); err == nil {
+					entry["summary"] = publish.Summarize(string(content))
+				}
+			}
+			if publish.IsProtectedPath(path) {
+				entry["visibility"] = publish.VisibilityFollowers
+			} else if unlisted {
+				entry["visibility"] = publish.VisibilityUnlisted
+			}
+			posts = append(posts, entry)
 		}
-		posts = append(posts, entry)
 	}
 
-	// Reverse order (newest first)
-	for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
-		posts[i], posts[j] = posts[j], posts[i]
-	}
+	// Newest first (each index is read backwards, in publication order)
+	sort.SliceStable(posts, func(i, j int) bool {
+		pi, _ := posts[i]["published"].(string)
+		pj, _ := posts[j]["published"].(string)
+		return pi > pj
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -656,6 +846,19 @@ func (s *Server) handlePosts(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/frontmatter") {
+		s.handlePostFrontmatter(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/unpublish-to-draft") {
+		s.handleUnpublishToDraft(w, r)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		s.handleUnpublish(w, r)
+		return
+	}
+
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -701,6 +904,102 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handlePostFrontmatter exposes a post's frontmatter as JSON and applies
+// field-level updates without touching the body. Updated posts are re-signed,
+// re-indexed, and re-rendered.
+// GET   /api/posts/posts/20260125/my-post.md/frontmatter
+// PATCH /api/posts/posts/20260125/my-post.md/frontmatter
+// Body: {"title":"...","tags":["a","b"],"description":"...","lang":"fr","translation_of":"posts/...md","visibility":"public|unlisted"}
+func (s *Server) handlePostFrontmatter(w http.ResponseWriter, r *http.Request) {
+	postPath := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/posts/"), "/frontmatter")
+	if postPath == "" {
+		http.Error(w, "Post path required", http.StatusBadRequest)
+		return
+	}
+	if err := validatePostPath(postPath); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(s.DataDir, postPath)); err != nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		fm, err := publish.ReadFrontmatter(s.DataDir, postPath)
+		if err != nil {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fm)
+
+	case http.MethodPatch:
+		if s.PrivateKey == nil {
+			http.Error(w, "Not configured - please complete setup first", http.StatusBadRequest)
+			return
+		}
+
+		var patch publish.FrontmatterPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := patch.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.LogDebug("Updating frontmatter: %s", postPath)
+		result, err := publish.UpdateFrontmatter(s.DataDir, postPath, patch, s.PrivateKey, s.DiscoveryConfig())
+		if errors.Is(err, publish.ErrInvalidTranslation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			s.LogError("Failed to update frontmatter for %s: %v", postPath, err)
+			http.Error(w, "Failed to update frontmatter", http.StatusInternalServerError)
+			return
+		}
+		s.LogInfo("Updated frontmatter: %s (title: %s)", result.Path, result.Title)
+
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("post-frontmatter render failed: %v", err)
+		}
+
+		// Metadata edits are a republish as far as hooks are concerned
+		{
+			var hc *hooks.HookConfig
+			if s.Config != nil {
+				hc = s.Config.Hooks
+			}
+			payload := &hooks.HookPayload{
+				Event:         hooks.EventPostRepublish,
+				Path:          result.Path,
+				Title:         result.Title,
+				Version:       result.Version,
+				Timestamp:     time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+				CommitMessage: hooks.GenerateCommitMessage(hooks.EventPostRepublish, result.Title),
+			}
+			hookResult, err := hooks.RunHook(s.DataDir, hc, payload)
+			if err != nil {
+				s.LogWarn("post-republish hook failed: %v", err)
+			}
+			if hookResult != nil && hookResult.Executed {
+				s.LogInfo("post-republish hook executed: %s", hookResult.Output)
+			}
+		}
+
+		fm, _ := publish.ReadFrontmatter(s.DataDir, postPath)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fm)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func (s *Server) handleRepublish(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -715,6 +1014,7 @@ func (s *Server) handleRepublish(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Path     string `json:"path"`
 		Markdown string `json:"markdown"`
+		As       string `json:"as"` // Co-author to sign as
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
@@ -743,8 +1043,14 @@ func (s *Server) handleRepublish(w http.ResponseWriter, r *http.Request) {
 		markdown = publish.StripFrontmatter(markdown)
 	}
 
+	privKey, err := s.signingKey(req.As)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	s.LogDebug("Republishing post: %s", req.Path)
-	result, err := publish.RepublishPost(s.DataDir, req.Path, markdown, s.PrivateKey, s.DiscoveryConfig())
+	result, err := publish.RepublishPost(s.DataDir, req.Path, markdown, privKey, s.DiscoveryConfig())
 	if err != nil {
 		s.LogError("Failed to republish %s: %v", req.Path, err)
 		http.Error(w, "Failed to republish", http.StatusInternalServerError)
@@ -755,7 +1061,7 @@ func (s *Server) handleRepublish(w http.ResponseWriter, r *http.Request) {
 	// Render site to generate HTML files
 	if err := s.RenderSite(); err != nil {
 		// Log but don't fail - the post was republished successfully
-		log.Printf("[warning] post-republish render failed: %v", err)
+		s.LogWarn("post-republish render failed: %v", err)
 	}
 
 	// Run post-republish hook (checks explicit config, then auto-discovers .polis/hooks/)
@@ -775,12 +1081,13 @@ func (s *Server) handleRepublish(w http.ResponseWriter, r *http.Request) {
 		hookResult, err := hooks.RunHook(s.DataDir, hc, payload)
 		if err != nil {
 			// Log hook error but don't fail the republish
-			log.Printf("[warning] post-republish hook failed: %v", err)
+			s.LogWarn("post-republish hook failed: %v", err)
 		}
 		if hookResult != nil && hookResult.Executed {
-			log.Printf("[info] post-republish hook executed: %s", hookResult.Output)
+			s.LogInfo("post-republish hook executed: %s", hookResult.Output)
 		}
 	}
+	s.crosspostInBackground(result, true)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -799,9 +1106,17 @@ func (s *Server) handleCommentDrafts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Delivery status of the blessing requests for signed comments
+		deliveries, err := comment.ListDeliveries(s.DataDir)
+		if err != nil {
+			s.LogWarn("failed to read comment outbox: %v", err)
+			deliveries = []comment.Delivery{}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"drafts": drafts,
+			"drafts":     drafts,
+			"deliveries": deliveries,
 		})
 
 	case http.MethodPost:
@@ -866,7 +1181,12 @@ func (s *Server) handleCommentDraft(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(draft)
 
 	case http.MethodDelete:
-		if err := comment.DeleteDraft(s.DataDir, id); err != nil {
+		item, err := trash.Move(s.DataDir, ".polis/comments/drafts/"+id+".md")
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				http.Error(w, "Draft not found", http.StatusNotFound)
+				return
+			}
 			s.LogError("failed to delete draft: %v", err)
 			http.Error(w, "Failed to delete draft", http.StatusInternalServerError)
 			return
@@ -874,7 +1194,8 @@ func (s *Server) handleCommentDraft(w http.ResponseWriter, r *http.Request) {
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
+			"success":  true,
+			"trash_id": item.ID,
 		})
 
 	default:
@@ -898,11 +1219,17 @@ func (s *Server) handleCommentSign(w http.ResponseWriter, r *http.Request) {
 		InReplyTo string `json:"in_reply_to"`
 		RootPost  string `json:"root_post"`
 		Content   string `json:"content"`
+		As        string `json:"as"` // Co-author to sign as
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
+	privKey, err := s.signingKey(req.As)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Load draft if ID provided, otherwise use inline content
 	var draft *comment.CommentDraft
@@ -939,18 +1266,26 @@ func (s *Server) handleCommentSign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	signed, err := comment.SignComment(s.DataDir, draft, authorDomain, siteURL, s.PrivateKey)
+	signed, err := comment.SignComment(s.DataDir, draft, authorDomain, siteURL, privKey)
 	if err != nil {
 		s.LogError("failed to sign comment: %v", err)
 		http.Error(w, "Failed to sign comment", http.StatusInternalServerError)
 		return
 	}
 
+	// Watch the thread for replies from other commenters
+	threadPost := signed.Meta.RootPost
+	if threadPost == "" {
+		threadPost = signed.Meta.InReplyTo
+	}
+	s.subscribeThread(threadPost)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":   true,
 		"comment":   signed.Meta,
 		"signature": signed.Signature,
+		"delivery":  comment.GetDelivery(s.DataDir, signed.Meta.ID),
 	})
 }
 
@@ -984,7 +1319,7 @@ func (s *Server) handleCommentBeseech(w http.ResponseWriter, r *http.Request) {
 	// BeseechComment, so the .md may already be on disk even if DS registration
 	// fails afterward. Without this, the comment HTML and index.html are never generated.
 	if renderErr := s.RenderSite(); renderErr != nil {
-		log.Printf("[warning] post-beseech render failed: %v", renderErr)
+		s.LogWarn("post-beseech render failed: %v", renderErr)
 	}
 
 	if err != nil {
@@ -994,6 +1329,8 @@ func (s *Server) handleCommentBeseech(w http.ResponseWriter, r *http.Request) {
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "not configured") || strings.Contains(errMsg, "not found in pending") {
 			status = http.StatusBadRequest
+		} else if d := comment.GetDelivery(s.DataDir, req.CommentID); d != nil && d.NextAttempt != "" {
+			errMsg += " (queued; will retry automatically)"
 		}
 		http.Error(w, errMsg, status)
 		return
@@ -1018,9 +1355,10 @@ func (s *Server) handleCommentBeseech(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": result.Success,
-		"status":  result.Status,
-		"message": result.Message,
+		"success":  result.Success,
+		"status":   result.Status,
+		"message":  result.Message,
+		"delivery": comment.GetDelivery(s.DataDir, req.CommentID),
 	})
 }
 
@@ -1149,49 +1487,93 @@ func (s *Server) handleCommentsSync(w http.ResponseWriter, r *http.Request) {
 	// Sync pending comments
 	result, err := comment.SyncPendingComments(s.DataDir, s.GetBaseURL(), client, s.Config.Hooks)
 	if err != nil {
-		log.Printf("handleCommentsSync: failed for %s: %v", myDomain, err)
-		s.LogError("failed to sync comments: %v", err)
+		s.LogError("failed to sync comments for %s: %v", myDomain, err)
 		http.Error(w, fmt.Sprintf("Failed to sync comments: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Re-render site so HTML reflects updated comment statuses (blessed/denied)
 	if err := s.RenderSite(); err != nil {
-		log.Printf("[warning] post-comment-sync render failed: %v", err)
+		s.LogWarn("post-comment-sync render failed: %v", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
-// Blessing API handlers (ON MY POSTS - incoming blessing requests)
-
-func (s *Server) handleBlessingRequests(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// handleCommentRetract handles DELETE /api/comments/{id}: removes a signed
+// comment from this site and publishes a signed retraction so the post's
+// author drops it.
+func (s *Server) handleCommentRetract(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if s.DiscoveryURL == "" {
-		http.Error(w, "Discovery service not configured", http.StatusBadRequest)
+	commentID := strings.TrimPrefix(r.URL.Path, "/api/comments/")
+	if commentID == "" || strings.Contains(commentID, "/") {
+		http.Error(w, "Comment ID required", http.StatusBadRequest)
 		return
 	}
 
 	if s.PrivateKey == nil {
-		log.Printf("handleBlessingRequests: private key not configured for %s", s.GetBaseURL())
 		http.Error(w, "Private key not configured", http.StatusBadRequest)
 		return
 	}
 
-	// Create authenticated discovery client (needed for status=pending queries)
-	myDomain := discovery.ExtractDomainFromURL(s.GetBaseURL())
+	result, err := comment.RetractComment(s.DataDir, commentID, s.PrivateKey, &comment.DiscoveryConfig{
+		DiscoveryURL: s.DiscoveryURL,
+		DiscoveryKey: s.DiscoveryKey,
+		BaseURL:      s.GetBaseURL(),
+	})
+	if err != nil {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if result.EventPublished {
+		s.LogInfo("Retracted comment %s", commentID)
+	} else {
+		s.LogWarn("Retracted comment %s locally, retraction not announced: %s", commentID, result.EventError)
+	}
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-retract render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"comment": result,
+	})
+}
+
+// Blessing API handlers (ON MY POSTS - incoming blessing requests)
+
+func (s *Server) handleBlessingRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.DiscoveryURL == "" {
+		http.Error(w, "Discovery service not configured", http.StatusBadRequest)
+		return
+	}
+
+	if s.PrivateKey == nil {
+		s.LogWarn("blessing requests: private key not configured for %s", s.GetBaseURL())
+		http.Error(w, "Private key not configured", http.StatusBadRequest)
+		return
+	}
+
+	// Create authenticated discovery client (needed for status=pending queries)
+	myDomain := discovery.ExtractDomainFromURL(s.GetBaseURL())
 	client := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, myDomain, s.PrivateKey)
 
 	// Fetch pending blessing requests (actor must be full domain, not subdomain)
 	requests, err := blessing.FetchPendingRequests(client, myDomain)
 	if err != nil {
-		log.Printf("handleBlessingRequests: failed to fetch for %s: %v", myDomain, err)
-		s.LogError("failed to fetch requests: %v", err)
+		s.LogError("failed to fetch blessing requests for %s: %v", myDomain, err)
 		http.Error(w, fmt.Sprintf("Failed to fetch requests: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -1262,27 +1644,99 @@ func (s *Server) handleBlessingGrant(w http.ResponseWriter, r *http.Request) {
 	}
 	s.LogInfo("Granted blessing for comment: %s", req.CommentURL)
 
-	// Fetch the remote comment markdown and save it locally so the renderer
-	// can display the comment body on the post page. The comment .md file
-	// lives on the commenter's site, not ours.
-	if commentRelPath := extractCommentRelPath(req.CommentURL); commentRelPath != "" {
-		localPath := filepath.Join(s.DataDir, commentRelPath)
-		if _, err := os.Stat(localPath); os.IsNotExist(err) {
-			rc := remote.NewClient()
-			if content, err := rc.FetchContent(polisurl.NormalizeToMD(req.CommentURL)); err == nil {
-				if err := os.MkdirAll(filepath.Dir(localPath), 0755); err == nil {
-					os.WriteFile(localPath, []byte(content), 0644)
-				}
-			} else {
-				log.Printf("[warning] could not fetch remote comment %s: %v", req.CommentURL, err)
-			}
-		}
-	}
+	s.storeBlessedComment(req.CommentURL)
 
 	// Render site to include the newly blessed comment
 	if err := s.RenderSite(); err != nil {
 		// Log but don't fail - the blessing was granted successfully
-		log.Printf("[warning] post-blessing render failed: %v", err)
+		s.LogWarn("post-blessing render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// storeBlessedComment fetches the remote comment markdown and saves it
+// locally so the renderer can display the comment body on the post page.
+// The comment .md file lives on the commenter's site, not ours.
+func (s *Server) storeBlessedComment(commentURL string) {
+	commentRelPath := extractCommentRelPath(commentURL)
+	if commentRelPath == "" {
+		return
+	}
+	localPath := filepath.Join(s.DataDir, commentRelPath)
+	if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+		return
+	}
+	rc := remote.NewClient()
+	if content, err := rc.FetchContent(polisurl.NormalizeToMD(commentURL)); err == nil {
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err == nil {
+			os.WriteFile(localPath, []byte(content), 0644)
+		}
+	} else {
+		s.LogWarn("could not fetch remote comment %s: %v", commentURL, err)
+	}
+}
+
+// handleBlessingBulk handles POST /api/blessing/bulk.
+// Body: {"actions": [{"comment_version": "...", "action": "grant"|"deny"}, ...]}
+// Pending requests are fetched once and the site is re-rendered once.
+func (s *Server) handleBlessingBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.DiscoveryURL == "" {
+		http.Error(w, "Discovery service not configured", http.StatusBadRequest)
+		return
+	}
+
+	if s.PrivateKey == nil {
+		http.Error(w, "Private key not configured", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Actions []blessing.BulkAction `json:"actions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Actions) == 0 {
+		http.Error(w, "actions is required", http.StatusBadRequest)
+		return
+	}
+	for _, a := range req.Actions {
+		if a.Action != blessing.ActionGrant && a.Action != blessing.ActionDeny {
+			http.Error(w, fmt.Sprintf("Unknown action %q (expected grant or deny)", a.Action), http.StatusBadRequest)
+			return
+		}
+	}
+
+	myDomain := discovery.ExtractDomainFromURL(s.GetBaseURL())
+	client := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, myDomain, s.PrivateKey)
+
+	pending, err := blessing.FetchPendingRequests(client, myDomain)
+	if err != nil {
+		s.LogError("failed to fetch blessing requests for %s: %v", myDomain, err)
+		http.Error(w, fmt.Sprintf("Failed to fetch blessing requests: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := blessing.Bulk(s.DataDir, pending, req.Actions, client, s.Config.Hooks, s.PrivateKey)
+	for _, item := range result.Results {
+		if item.Success && item.Action == blessing.ActionGrant {
+			s.storeBlessedComment(item.CommentURL)
+		}
+	}
+	s.LogInfo("Bulk moderation: granted %d, denied %d, failed %d", result.Granted, result.Denied, result.Failed)
+
+	if result.Granted > 0 {
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("post-blessing render failed: %v", err)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1354,10 +1808,108 @@ func (s *Server) handleBlessedComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Report each post's comments in its configured order
+	for i, pc := range bc.Comments {
+		bc.Comments[i].Blessed = metadata.SortBlessedComments(pc.Blessed, pc.Sort)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(bc)
 }
 
+// handleBlessingFeature handles POST /api/blessing/feature.
+// Body: {"comment_url": "...", "featured": true}
+func (s *Server) handleBlessingFeature(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		CommentURL string `json:"comment_url"`
+		Featured   bool   `json:"featured"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.CommentURL == "" {
+		http.Error(w, "comment_url is required", http.StatusBadRequest)
+		return
+	}
+
+	normalizedURL := polisurl.NormalizeToMD(req.CommentURL)
+	if err := metadata.SetCommentFeatured(s.DataDir, normalizedURL, req.Featured); err != nil {
+		if errors.Is(err, metadata.ErrCommentNotBlessed) {
+			http.Error(w, "Comment is not blessed", http.StatusNotFound)
+			return
+		}
+		s.LogError("failed to update featured comment: %v", err)
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+	s.LogInfo("Set featured=%t for comment: %s", req.Featured, normalizedURL)
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-feature render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"comment_url": normalizedURL,
+		"featured":    req.Featured,
+	})
+}
+
+// handleBlessingSort handles POST /api/blessing/sort.
+// Body: {"post": "posts/20260101/hello.md", "sort": "chronological"|"newest-first"|"featured-first"}
+func (s *Server) handleBlessingSort(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Post string `json:"post"`
+		Sort string `json:"sort"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Post == "" {
+		http.Error(w, "post is required", http.StatusBadRequest)
+		return
+	}
+	if req.Sort != "" && !metadata.ValidSortOrder(req.Sort) {
+		http.Error(w, fmt.Sprintf("Unknown sort %q (expected %s)", req.Sort, strings.Join(metadata.SortOrders, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	order := req.Sort
+	if order == metadata.SortChronological {
+		order = "" // The default; don't store it
+	}
+	if err := metadata.SetPostCommentSort(s.DataDir, req.Post, order); err != nil {
+		s.LogError("failed to set comment order: %v", err)
+		http.Error(w, "Failed to set comment order", http.StatusInternalServerError)
+		return
+	}
+	s.LogInfo("Set comment order for %s: %s", req.Post, req.Sort)
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-sort render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"post":    req.Post,
+		"sort":    req.Sort,
+	})
+}
+
 func (s *Server) handleBlessingRevoke(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1391,7 +1943,7 @@ func (s *Server) handleBlessingRevoke(w http.ResponseWriter, r *http.Request) {
 	// Render site to remove the comment from pages
 	if err := s.RenderSite(); err != nil {
 		// Log but don't fail - the revoke was successful
-		log.Printf("[warning] post-revoke render failed: %v", err)
+		s.LogWarn("post-revoke render failed: %v", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1403,7 +1955,8 @@ func (s *Server) handleBlessingRevoke(w http.ResponseWriter, r *http.Request) {
 
 // Settings and Automation API handlers
 
-// Automation represents a configured automation (hook)
+// Automation represents a configured automation: an event hook, or a
+// scheduled job (Schedule set) with its run status.
 type Automation struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -1411,6 +1964,15 @@ type Automation struct {
 	Event       string `json:"event"`
 	ScriptPath  string `json:"script_path"`
 	Enabled     bool   `json:"enabled"`
+
+	Schedule   string `json:"schedule,omitempty"`
+	Action     string `json:"action,omitempty"`
+	Template   string `json:"template,omitempty"`
+	NextRun    string `json:"next_run,omitempty"`
+	LastRun    string `json:"last_run,omitempty"`
+	LastStatus string `json:"last_status,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	LastResult string `json:"last_result,omitempty"`
 }
 
 func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
@@ -1460,8 +2022,7 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	themes, _ := theme.ListThemesWithPalettes(s.DataDir, s.CLIThemesDir)
 	activeTheme, _ := theme.GetActiveTheme(s.DataDir)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeJSONWithETag(w, r, map[string]interface{}{
 		"site": map[string]interface{}{
 			"subdomain":            subdomain,
 			"site_title":           siteTitle,
@@ -1477,8 +2038,12 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 		"existing_hooks":          existingHooks,
 		"setup_wizard_dismissed":  setupWizardDismissed,
 		"hide_read":               s.Config != nil && s.Config.HideRead,
+		"remote_media":            s.RemoteMediaPolicy(),
+		"prefetch":                s.PrefetchEnabled(),
 		"active_theme":            activeTheme,
 		"themes":                  themes,
+		"pages":                   render.SpecialPages,
+		"analytics":               s.AnalyticsSettings(),
 	})
 }
 
@@ -1515,6 +2080,39 @@ func (s *Server) getAutomations() []Automation {
 		}
 	}
 
+	jobs := s.scheduledJobs()
+	if len(jobs) == 0 {
+		return automations
+	}
+	st, err := schedule.LoadState(s.DataDir)
+	if err != nil {
+		s.LogWarn("scheduler: %v", err)
+		st = schedule.State{}
+	}
+	for _, j := range jobs {
+		rs := st[j.ID]
+		name := j.Name
+		if name == "" {
+			name = j.ID
+		}
+		automations = append(automations, Automation{
+			ID:          j.ID,
+			Name:        name,
+			Description: "Runs " + j.Action + " on schedule " + j.Schedule,
+			Event:       string(hooks.EventScheduled),
+			ScriptPath:  j.Script,
+			Enabled:     !j.Disabled,
+			Schedule:    j.Schedule,
+			Action:      j.Action,
+			Template:    j.Template,
+			NextRun:     rs.NextRun,
+			LastRun:     rs.LastRun,
+			LastStatus:  rs.LastStatus,
+			LastError:   rs.LastError,
+			LastResult:  rs.LastResult,
+		})
+	}
+
 	return automations
 }
 
@@ -1529,16 +2127,17 @@ func (s *Server) handleAutomations(w http.ResponseWriter, r *http.Request) {
 
 	case http.MethodPost:
 		// Create a new automation
-		var req struct {
-			TemplateID string `json:"template_id"`
-			HookType   string `json:"hook_type"`
-			Script     string `json:"script"`
-		}
+		var req scheduledAutomationRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "Invalid request", http.StatusBadRequest)
 			return
 		}
 
+		if req.Schedule != "" {
+			s.saveScheduledAutomation(w, req)
+			return
+		}
+
 		// Default to post-publish if not specified
 		hookType := req.HookType
 		if hookType == "" {
@@ -1591,6 +2190,121 @@ func (s *Server) handleAutomations(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// scheduledAutomationRequest is the POST /api/automations body. Requests
+// with a schedule create or replace a scheduled job; the rest create an
+// event hook.
+type scheduledAutomationRequest struct {
+	TemplateID string `json:"template_id"`
+	HookType   string `json:"hook_type"`
+	Script     string `json:"script"` // script body (hooks and the script action)
+
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Action   string `json:"action"`
+	Template string `json:"template"`
+	Publish  bool   `json:"publish"`
+	Since    string `json:"since"`
+	Email    bool   `json:"email"`
+	Keep     int    `json:"keep"`
+	Disabled bool   `json:"disabled"`
+}
+
+// hookAutomationIDs are reserved for event hooks.
+var hookAutomationIDs = map[string]bool{
+	"post-publish":   true,
+	"post-republish": true,
+	"post-comment":   true,
+}
+
+func (s *Server) saveScheduledAutomation(w http.ResponseWriter, req scheduledAutomationRequest) {
+	job := schedule.Job{
+		ID:       req.ID,
+		Name:     req.Name,
+		Schedule: req.Schedule,
+		Action:   req.Action,
+		Template: req.Template,
+		Publish:  req.Publish,
+		Since:    req.Since,
+		Email:    req.Email,
+		Keep:     req.Keep,
+		Disabled: req.Disabled,
+	}
+	if job.ID == "" {
+		job.ID = job.Action + "-" + strings.ToLower(idgen.New())
+	}
+	if hookAutomationIDs[job.ID] {
+		http.Error(w, "Automation ID is reserved for event hooks", http.StatusBadRequest)
+		return
+	}
+
+	// The script action stores its body like a hook script.
+	if job.Action == schedule.ActionScript {
+		script := req.Script
+		if req.TemplateID != "" {
+			template, ok := hooks.GetTemplate(req.TemplateID)
+			if !ok {
+				http.Error(w, "Unknown template ID", http.StatusBadRequest)
+				return
+			}
+			script = template.Script
+		}
+		if script == "" {
+			http.Error(w, "Script is required", http.StatusBadRequest)
+			return
+		}
+		job.Script = ".polis/hooks/" + job.ID + ".sh"
+		if err := job.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := fsutil.WriteFile(filepath.Join(s.DataDir, job.Script), []byte(script), 0755); err != nil {
+			s.LogError("failed to write automation script: %v", err)
+			http.Error(w, "Failed to create automation", http.StatusInternalServerError)
+			return
+		}
+	} else if err := job.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if s.Config == nil {
+		s.Config = &Config{}
+	}
+	replaced := false
+	for i := range s.Config.Schedules {
+		if s.Config.Schedules[i].ID == job.ID {
+			s.Config.Schedules[i] = job
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.Config.Schedules = append(s.Config.Schedules, job)
+	}
+	if err := s.SaveConfig(); err != nil {
+		s.LogError("failed to save config: %v", err)
+		http.Error(w, "Failed to save config", http.StatusInternalServerError)
+		return
+	}
+
+	// A changed schedule takes effect from now rather than the old next run.
+	if st, err := schedule.LoadState(s.DataDir); err == nil {
+		if _, ok := st[job.ID]; ok {
+			rs := st[job.ID]
+			rs.NextRun = ""
+			st[job.ID] = rs
+			schedule.SaveState(s.DataDir, st)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      job.ID,
+	})
+}
+
 func (s *Server) handleAutomationsQuick(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1664,6 +2378,25 @@ func (s *Server) handleAutomation(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodDelete:
+		if !hookAutomationIDs[id] {
+			// Remove a scheduled automation
+			found, err := s.removeScheduledJob(id)
+			if err != nil {
+				s.LogError("failed to save config: %v", err)
+				http.Error(w, "Failed to save config", http.StatusInternalServerError)
+				return
+			}
+			if !found {
+				http.Error(w, "Unknown automation ID", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+			})
+			return
+		}
+
 		// Remove the automation
 		if s.Config == nil || s.Config.Hooks == nil {
 			http.Error(w, "No automations configured", http.StatusNotFound)
@@ -2043,18 +2776,483 @@ func (s *Server) handleUpdateSiteTitle(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleContent handles GET /api/content/{path} for browser mode navigation
-func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+// handlePrivateFeed manages the token-protected followers feed.
+// GET: returns the current private feed URL (if a token has been issued).
+// POST: issues a new token (rotating any existing one) and re-renders feeds.
+// DELETE: revokes the token and removes the private feed.
+func (s *Server) handlePrivateFeed(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tok, err := rss.LoadToken(s.DataDir)
+		if err != nil {
+			s.LogError("failed to load feed token: %v", err)
+			http.Error(w, "Failed to load feed token", http.StatusInternalServerError)
+			return
+		}
 
-	// Extract path from URL: /api/content/{path}
-	contentPath := strings.TrimPrefix(r.URL.Path, "/api/content/")
-	if contentPath == "" {
-		http.Error(w, "Path required", http.StatusBadRequest)
-		return
+		response := map[string]interface{}{
+			"enabled":    tok != nil,
+			"public_url": s.publicFeedURL(),
+		}
+		if tok != nil {
+			response["url"] = rss.PrivateFeedURL(s.GetBaseURL(), tok)
+			response["created_at"] = tok.CreatedAt
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+
+	case http.MethodPost:
+		tok, err := rss.RotateToken(s.DataDir)
+		if err != nil {
+			s.LogError("failed to rotate feed token: %v", err)
+			http.Error(w, "Failed to rotate feed token", http.StatusInternalServerError)
+			return
+		}
+
+		// Re-render so the new feed exists and the old one is removed
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("private feed render failed: %v", err)
+		}
+
+		s.LogInfo("Private feed token rotated")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"url":        rss.PrivateFeedURL(s.GetBaseURL(), tok),
+			"created_at": tok.CreatedAt,
+		})
+
+	case http.MethodDelete:
+		if err := rss.RevokeToken(s.DataDir); err != nil {
+			s.LogError("failed to revoke feed token: %v", err)
+			http.Error(w, "Failed to revoke feed token", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("private feed render failed: %v", err)
+		}
+
+		s.LogInfo("Private feed token revoked")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// publicFeedURL returns the URL of the public feed.xml.
+func (s *Server) publicFeedURL() string {
+	if baseURL := s.GetBaseURL(); baseURL != "" {
+		return baseURL + "/" + rss.PublicFeedFilename
+	}
+	return rss.PublicFeedFilename
+}
+
+// handleEmailSettings manages SMTP settings for owner notification emails.
+// GET: returns the current settings (the password is never returned).
+// POST: saves settings. The SMTP password is read from SMTP_PASSWORD in .env.
+func (s *Server) handleEmailSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg := mailer.Config{}
+		if s.Config != nil && s.Config.Email != nil {
+			cfg = *s.Config.Email
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"email":        cfg,
+			"password_set": s.SMTPPassword != "",
+			"pending":      mailer.NewNotifier(s.DataDir, cfg).Pending(),
+		})
+
+	case http.MethodPost:
+		var req mailer.Config
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.Enabled {
+			if err := req.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if s.Config == nil {
+			s.Config = &Config{}
+		}
+		s.Config.Email = &req
+		if err := s.SaveConfig(); err != nil {
+			s.LogError("failed to save config: %v", err)
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+
+		s.LogInfo("Email notification settings updated (enabled=%v)", req.Enabled)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"email":   req,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleEmailTest handles POST /api/settings/email/test to send a test message
+// using the saved SMTP settings.
+func (s *Server) handleEmailTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Config == nil || s.Config.Email == nil {
+		http.Error(w, "Email notifications not configured", http.StatusBadRequest)
+		return
+	}
+
+	cfg := *s.Config.Email
+	cfg.Password = s.SMTPPassword
+	body := "This is a test message from your polis site.\n\nComment notifications will be delivered to this address.\n"
+	if err := mailer.SendSMTP(cfg, "[polis] Test notification", body); err != nil {
+		s.LogError("test email failed: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to send test email: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// handleRemoteMediaSettings reads or updates how media in remote posts is loaded.
+// GET: returns {images, embeds}. POST: {images, embeds} with each one of
+// "load", "proxy", "click", or "block" (embeds cannot be proxied).
+func (s *Server) handleRemoteMediaSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.RemoteMediaPolicy())
+
+	case http.MethodPost:
+		var req remote.MediaPolicy
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		policy := remote.MediaPolicy{
+			Images: remote.NormalizeMediaMode(req.Images, false),
+			Embeds: remote.NormalizeMediaMode(req.Embeds, true),
+		}
+
+		if s.Config == nil {
+			s.Config = &Config{}
+		}
+		s.Config.RemoteMedia = &policy
+		if err := s.SaveConfig(); err != nil {
+			s.LogError("failed to save config: %v", err)
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"remote_media": policy,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePrefetchSettings reads or updates feed content prefetching.
+// GET: returns {prefetch, content_cache_mb, cached_items, cached_bytes}.
+// POST: {prefetch, content_cache_mb} (content_cache_mb optional; 0 = default).
+func (s *Server) handlePrefetchSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writePrefetchSettings(w)
+
+	case http.MethodPost:
+		var req struct {
+			Prefetch       bool `json:"prefetch"`
+			ContentCacheMB *int `json:"content_cache_mb"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.ContentCacheMB != nil && *req.ContentCacheMB < 0 {
+			http.Error(w, "content_cache_mb must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		if s.Config == nil {
+			s.Config = &Config{}
+		}
+		s.Config.Prefetch = req.Prefetch
+		if req.ContentCacheMB != nil {
+			s.Config.ContentCacheMB = *req.ContentCacheMB
+			s.ContentCache().MaxBytes = int64(*req.ContentCacheMB) << 20
+		}
+		if err := s.SaveConfig(); err != nil {
+			s.LogError("failed to save config: %v", err)
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+		if req.Prefetch {
+			go s.prefetchFeed(s.GetDiscoveryDomain())
+		}
+		s.writePrefetchSettings(w)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePageSettings reads or changes which special pages render
+// generates: {"archive": bool, "not_found": bool, "about": bool}. Fields
+// left out of a POST are unchanged. Takes effect on the next render.
+func (s *Server) handlePageSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(render.SpecialPages)
+
+	case http.MethodPost:
+		// Special pages are process-wide, so only the primary site sets them
+		if s.host != nil && s.host.primary != s {
+			http.Error(w, "Special pages are set by the primary site", http.StatusConflict)
+			return
+		}
+		var req PagesConfig
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if s.Config == nil {
+			s.Config = &Config{}
+		}
+		if s.Config.Pages == nil {
+			s.Config.Pages = &PagesConfig{}
+		}
+		if req.Archive != nil {
+			s.Config.Pages.Archive = req.Archive
+		}
+		if req.NotFound != nil {
+			s.Config.Pages.NotFound = req.NotFound
+		}
+		if req.About != nil {
+			s.Config.Pages.About = req.About
+		}
+		if err := s.SaveConfig(); err != nil {
+			s.LogError("failed to save config: %v", err)
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+
+		// Re-resolve, since the environment can override the saved values
+		cfg, err := config.Load(config.Options{DataDir: s.DataDir})
+		if err != nil {
+			s.LogError("failed to load settings: %v", err)
+			http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+			return
+		}
+		applyPageSettings(cfg)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(render.SpecialPages)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) writePrefetchSettings(w http.ResponseWriter) {
+	limitMB := remote.DefaultContentCacheBytes >> 20
+	if s.Config != nil && s.Config.ContentCacheMB > 0 {
+		limitMB = s.Config.ContentCacheMB
+	}
+	items, size := s.ContentCache().Size()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"prefetch":         s.PrefetchEnabled(),
+		"content_cache_mb": limitMB,
+		"cached_items":     items,
+		"cached_bytes":     size,
+	})
+}
+
+// handleLogs returns recent structured log entries for the debug panel.
+// Query params: level (debug|info|warn|error), since (RFC 3339 timestamp or
+// duration like "15m"), limit (default 500).
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := logging.Query{Limit: 500}
+	params := r.URL.Query()
+	if v := params.Get("level"); v != "" {
+		level, err := logging.ParseLevel(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q.Level = level
+	} else {
+		q.Level = slog.LevelDebug
+	}
+	if v := params.Get("since"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			q.Since = t
+		} else if d, err := time.ParseDuration(v); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else {
+			http.Error(w, "since must be an RFC 3339 timestamp or a duration", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+
+	entries := []logging.Entry{}
+	enabled := s.Logger.FileEnabled()
+	if enabled {
+		got, err := logging.Read(filepath.Join(s.DataDir, "logs"), q)
+		if err != nil {
+			s.LogError("failed to read logs: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if got != nil {
+			entries = got
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": enabled,
+		"entries": entries,
+	})
+}
+
+// handleMetrics exposes per-route request counts and latencies in the
+// Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics().WritePrometheus(w)
+	s.files().writePrometheus(w)
+}
+
+// handleDebugStats returns a JSON summary of request metrics for the web UI.
+func (s *Server) handleDebugStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m := s.metrics()
+	routes := m.Summary()
+	var total, errors uint64
+	for _, rs := range routes {
+		total += rs.Count
+		errors += rs.Errors
+	}
+	errorRate := 0.0
+	if total > 0 {
+		errorRate = float64(errors) / float64(total)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"uptime_seconds": int64(m.Uptime().Seconds()),
+		"requests":       total,
+		"errors":         errors,
+		"error_rate":     errorRate,
+		"routes":         routes,
+		"file_cache": map[string]uint64{
+			"hits":   s.files().hits.Load(),
+			"misses": s.files().misses.Load(),
+		},
+	})
+}
+
+// handleHealthz is the liveness probe: 200 whenever the process can serve
+// HTTP, including while it is shutting down.
+// GET /healthz
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleReadyz is the readiness probe: 200 once Initialize has completed and
+// the data directory is reachable, 503 while starting or shutting down.
+// GET /readyz
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := "ready"
+	switch {
+	case s.shuttingDown.Load():
+		status = "shutting_down"
+	case !s.ready.Load():
+		status = "starting"
+	default:
+		if _, err := os.Stat(s.DataDir); err != nil {
+			status = "data_dir_unavailable"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != "ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// handleContent handles GET /api/content/{path} for browser mode navigation
+func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract path from URL: /api/content/{path}
+	contentPath := strings.TrimPrefix(r.URL.Path, "/api/content/")
+	if contentPath == "" {
+		http.Error(w, "Path required", http.StatusBadRequest)
+		return
 	}
 
 	// Validate path to prevent directory traversal
@@ -2286,7 +3484,7 @@ func (s *Server) handleSiteRegister(w http.ResponseWriter, r *http.Request) {
 
 	// Get author_name from .well-known/polis (email is private, not sent to DS)
 	var authorName string
-	if wk, err := site.LoadWellKnown(s.DataDir); err == nil {
+	if wk, err := s.cachedWellKnown(); err == nil {
 		authorName = wk.Author
 	}
 
@@ -2402,20 +3600,176 @@ func (s *Server) handleDeployCheck(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"deployed": false,
-			"domain":   domain,
+			"deployed": false,
+			"domain":   domain,
+		})
+		return
+	}
+	defer resp.Body.Close()
+
+	deployed := resp.StatusCode == http.StatusOK
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deployed": deployed,
+		"domain":   domain,
+	})
+}
+
+// handleDeployStatus reports local changes not yet deployed and whether the
+// live site still matches the last deploy (via sentinel files).
+// Query params: target (optional deploy target), live=0 to skip the live check.
+func (s *Server) handleDeployStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, err := deploy.LoadConfig(s.DataDir)
+	if err != nil {
+		s.LogError("failed to load deploy config: %v", err)
+		http.Error(w, "Failed to load deploy config", http.StatusInternalServerError)
+		return
+	}
+
+	targetName := r.URL.Query().Get("target")
+	var target *deploy.Target
+	if len(cfg.Targets) > 0 || targetName != "" {
+		target, err = cfg.Find(targetName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	siteURL := ""
+	if r.URL.Query().Get("live") != "0" {
+		siteURL = s.GetBaseURL()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	status, err := deploy.CheckStatus(ctx, s.DataDir, target, siteURL, &http.Client{Timeout: 5 * time.Second})
+	if err != nil {
+		s.LogError("deploy status check failed: %v", err)
+		http.Error(w, "Failed to check deploy status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleDeploy manages deploys to configured targets (.polis/deploy.json).
+// GET: lists targets with the time of their last deploy.
+// POST: uploads changed files to a target. Per-file progress is pushed to SSE
+// clients as "deploy-progress" events, followed by a "deploy-complete" event.
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg, err := deploy.LoadConfig(s.DataDir)
+		if err != nil {
+			s.LogError("failed to load deploy config: %v", err)
+			http.Error(w, "Failed to load deploy config", http.StatusInternalServerError)
+			return
+		}
+
+		targets := make([]map[string]interface{}, 0, len(cfg.Targets))
+		for _, t := range cfg.Targets {
+			entry := map[string]interface{}{
+				"name":    t.Name,
+				"type":    t.Type,
+				"default": t.Name == cfg.Default,
+			}
+			if m, err := deploy.LoadManifest(s.DataDir, t.Name); err == nil && m.DeployedAt != "" {
+				entry["deployed_at"] = m.DeployedAt
+			}
+			targets = append(targets, entry)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"targets": targets,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Target string `json:"target"`
+			DryRun bool   `json:"dry_run"`
+			Prune  bool   `json:"prune"`
+			Full   bool   `json:"full"`
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		cfg, err := deploy.LoadConfig(s.DataDir)
+		if err != nil {
+			s.LogError("failed to load deploy config: %v", err)
+			http.Error(w, "Failed to load deploy config", http.StatusInternalServerError)
+			return
+		}
+		target, err := cfg.Find(req.Target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		transport, err := s.newDeployTransport(target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !s.deployMu.TryLock() {
+			http.Error(w, "A deploy is already in progress", http.StatusConflict)
+			return
+		}
+		defer s.deployMu.Unlock()
+
+		opts := deploy.Options{DryRun: req.DryRun, Prune: req.Prune, Full: req.Full}
+		result, err := deploy.Run(r.Context(), s.DataDir, target, transport, opts, func(p deploy.Progress) {
+			if data, err := json.Marshal(p); err == nil {
+				s.broadcastSSE(SSEEvent{Event: "deploy-progress", Data: string(data)})
+			}
+		})
+
+		complete := map[string]interface{}{"target": target.Name, "uploaded": []string{}, "deleted": []string{}}
+		if result != nil {
+			complete["uploaded"] = result.Uploaded
+			complete["deleted"] = result.Deleted
+		}
+		if err != nil {
+			complete["error"] = err.Error()
+		}
+		if !req.DryRun {
+			if data, jerr := json.Marshal(complete); jerr == nil {
+				s.broadcastSSE(SSEEvent{Event: "deploy-complete", Data: string(data)})
+			}
+		}
+
+		if err != nil {
+			s.LogError("deploy to %s failed: %v", target.Name, err)
+			http.Error(w, fmt.Sprintf("Deploy failed: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		if !req.DryRun {
+			s.LogInfo("Deployed to %s: %d uploaded, %d deleted", target.Name, len(result.Uploaded), len(result.Deleted))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"result":  result,
 		})
-		return
-	}
-	defer resp.Body.Close()
-
-	deployed := resp.StatusCode == http.StatusOK
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"deployed": deployed,
-		"domain":   domain,
-	})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
 }
 
 // handleSetupWizardDismiss marks the setup wizard as dismissed in config.
@@ -2568,6 +3922,10 @@ func (s *Server) handleSnippet(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Snippet path required", http.StatusBadRequest)
 		return
 	}
+	if m := snippetVersionsPath.FindStringSubmatch(snippetPath); m != nil {
+		s.handleSnippetVersions(w, r, m[1], m[2], m[3] != "")
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -2641,6 +3999,61 @@ func (s *Server) handleSnippet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// snippetVersionsPath matches {name}/versions, {name}/versions/{version},
+// and {name}/versions/{version}/restore under /api/snippets/.
+var snippetVersionsPath = regexp.MustCompile(`^(.+)/versions(?:/([0-9T.Z]+)(/restore)?)?$`)
+
+// handleSnippetVersions serves a snippet's saved versions.
+//
+//	GET  /api/snippets/{name}/versions                    lists them, newest first
+//	GET  /api/snippets/{name}/versions/{version}          returns one's content
+//	POST /api/snippets/{name}/versions/{version}/restore  makes it current again
+//
+// All take ?source=global (default) or ?source=theme.
+func (s *Server) handleSnippetVersions(w http.ResponseWriter, r *http.Request, snippetPath, version string, restore bool) {
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "global"
+	}
+
+	switch {
+	case r.Method == http.MethodGet && version == "":
+		versions, err := snippet.ListVersions(s.DataDir, s.CLIThemesDir, "", snippetPath, source)
+		if err != nil {
+			http.Error(w, "Snippet not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"path":     snippetPath,
+			"versions": versions,
+		})
+
+	case r.Method == http.MethodGet && !restore:
+		content, err := snippet.ReadVersion(s.DataDir, s.CLIThemesDir, "", snippetPath, source, version)
+		if err != nil {
+			http.Error(w, "Version not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(content)
+
+	case r.Method == http.MethodPost && restore:
+		content, err := snippet.RestoreVersion(s.DataDir, s.CLIThemesDir, "", snippetPath, source, version)
+		if err != nil {
+			s.LogError("failed to restore snippet: %v", err)
+			http.Error(w, "Failed to restore snippet", http.StatusNotFound)
+			return
+		}
+		s.LogInfo("Restored snippet %s to version %s", content.Path, version)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(content)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // handleRenderPage handles POST /api/render-page to re-render pages using Go packages.
 // This is used for snippet editing workflow - after saving a snippet, re-render
 // the current page to see the changes.
@@ -2663,13 +4076,14 @@ func (s *Server) handleRenderPage(w http.ResponseWriter, r *http.Request) {
 
 	// Create page renderer using Go packages
 	renderer, err := render.NewPageRenderer(render.PageConfig{
-		DataDir:       s.DataDir,
-		CLIThemesDir:  s.CLIThemesDir,
-		BaseURL:       baseURL,
-		RenderMarkers: true, // Enable snippet markers for editing
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         baseURL,
+		RenderMarkers:   true, // Enable snippet markers for editing
+		DiscoveryDomain: s.GetDiscoveryDomain(),
 	})
 	if err != nil {
-		log.Printf("[render-page] Failed to create renderer: %v", err)
+		s.LogError("render-page: failed to create renderer: %v", err)
 		http.Error(w, "Failed to create renderer", http.StatusInternalServerError)
 		return
 	}
@@ -2677,12 +4091,12 @@ func (s *Server) handleRenderPage(w http.ResponseWriter, r *http.Request) {
 	// Render all pages with force=true to ensure snippets are updated
 	stats, err := renderer.RenderAll(true)
 	if err != nil {
-		log.Printf("[render-page] Render failed: %v", err)
+		s.LogError("render-page: render failed: %v", err)
 		http.Error(w, "Render failed", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("[render-page] Rendered %d posts, %d comments, requested path: %s",
+	s.LogInfo("render-page: rendered %d posts, %d comments, requested path: %s",
 		stats.PostsRendered, stats.CommentsRendered, req.Path)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -2694,6 +4108,82 @@ func (s *Server) handleRenderPage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleRenderAudit reports accessibility issues in published posts and
+// comments and the active theme (see render.PageRenderer.Audit).
+func (s *Server) handleRenderAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	renderer, err := render.NewPageRenderer(render.PageConfig{
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         s.GetBaseURL(),
+		DiscoveryDomain: s.GetDiscoveryDomain(),
+	})
+	if err != nil {
+		s.LogError("render-audit: failed to create renderer: %v", err)
+		http.Error(w, "Failed to create renderer", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := renderer.Audit()
+	if err != nil {
+		s.LogError("render-audit: %v", err)
+		http.Error(w, "Audit failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleRenderShortcodes expands the {{snippet:...}} and {{var:...}}
+// shortcodes in posted markdown without publishing or writing anything, and
+// returns the expanded markdown, its HTML, and any shortcodes left unexpanded.
+func (s *Server) handleRenderShortcodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Markdown string `json:"markdown"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	renderer, err := render.NewPageRenderer(render.PageConfig{
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         s.GetBaseURL(),
+		DiscoveryDomain: s.GetDiscoveryDomain(),
+	})
+	if err != nil {
+		s.LogError("render-shortcodes: failed to create renderer: %v", err)
+		http.Error(w, "Failed to create renderer", http.StatusInternalServerError)
+		return
+	}
+
+	expansion := renderer.ExpandShortcodes(req.Markdown)
+	html, err := render.MarkdownToHTML(expansion.Markdown)
+	if err != nil {
+		s.LogError("render markdown: %v", err)
+		http.Error(w, "Failed to render markdown", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"markdown": expansion.Markdown,
+		"html":     html,
+		"warnings": expansion.Warnings,
+	})
+}
+
 // ============================================================================
 // Social handlers (following, feed, remote post)
 // ============================================================================
@@ -2776,7 +4266,7 @@ func (s *Server) handleFollowing(w http.ResponseWriter, r *http.Request) {
 		discoveryClient := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, followDomain, s.PrivateKey)
 		remoteClient := remote.NewClient()
 
-		result, err := following.FollowWithBlessing(followingPath, req.URL, discoveryClient, remoteClient, s.PrivateKey)
+		result, err := following.FollowWithBlessing(followingPath, req.URL, discoveryClient, remoteClient, s.PrivateKey, s.streamConfig())
 		if err != nil {
 			s.LogError("follow failed: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2818,7 +4308,7 @@ func (s *Server) handleFollowing(w http.ResponseWriter, r *http.Request) {
 		discoveryClient := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, unfollowDomain, s.PrivateKey)
 		remoteClient := remote.NewClient()
 
-		result, err := following.UnfollowWithDenial(followingPath, req.URL, discoveryClient, remoteClient, s.PrivateKey)
+		result, err := following.UnfollowWithDenial(followingPath, req.URL, discoveryClient, remoteClient, s.PrivateKey, s.streamConfig())
 		if err != nil {
 			s.LogError("unfollow failed: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2851,6 +4341,17 @@ func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
 	typeFilter := r.URL.Query().Get("type")
 	statusFilter := r.URL.Query().Get("status")
 
+	// The feed cache plus cursors.json (last refresh); staleness changes
+	// with the clock alone
+	stale, _ := cm.IsStale()
+	etag := fileETag([]string{
+		feed.CacheFile(s.DataDir, discoveryDomain),
+		filepath.Join(stream.NewStore(s.DataDir, discoveryDomain).StateDir(), "cursors.json"),
+	}, discoveryDomain, typeFilter, statusFilter, strconv.FormatBool(stale))
+	if notModified(w, r, etag) {
+		return
+	}
+
 	items, err := cm.ListFiltered(feed.FilterOptions{
 		Type:   typeFilter,
 		Status: statusFilter,
@@ -2868,8 +4369,6 @@ func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stale, _ := cm.IsStale()
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"items":        items,
@@ -2888,6 +4387,13 @@ func (s *Server) handleFeedRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.refreshFeed())
+}
+
+// refreshFeed runs a feed sync and returns the updated cache in the shape
+// of the /api/feed response, plus how many items the sync added.
+func (s *Server) refreshFeed() map[string]interface{} {
 	// Count items before sync to determine how many are actually new
 	discoveryDomain := s.GetDiscoveryDomain()
 	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
@@ -2911,66 +4417,410 @@ func (s *Server) handleFeedRefresh(w http.ResponseWriter, r *http.Request) {
 		newItems = 0
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	return map[string]interface{}{
 		"items":        items,
 		"total":        len(items),
 		"unread":       unread,
 		"new_items":    newItems,
 		"stale":        false,
 		"last_refresh": cm.LastUpdated(),
+	}
+}
+
+// handleFeedRead marks feed items as read/unread.
+// POST /api/feed/read
+// Body: {"id":"x"} | {"id":"x","unread":true} | {"all":true} | {"from_id":"x"}
+func (s *Server) handleFeedRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req feedReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.markFeedRead(req); err != nil {
+		if errors.Is(err, errFeedReadTarget) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.LogError("feed read failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// feedReadRequest says which feed items to mark, for POST /api/feed/read
+// and the mark-read WebSocket command.
+type feedReadRequest struct {
+	ID     string `json:"id"`
+	Unread bool   `json:"unread"`
+	All    bool   `json:"all"`
+	FromID string `json:"from_id"`
+}
+
+var errFeedReadTarget = errors.New("Missing id, all, or from_id")
+
+// markFeedRead applies a feedReadRequest to the feed cache.
+func (s *Server) markFeedRead(req feedReadRequest) error {
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+	switch {
+	case req.All:
+		return cm.MarkAllRead()
+	case req.FromID != "":
+		return cm.MarkUnreadFrom(req.FromID)
+	case req.ID != "" && req.Unread:
+		return cm.MarkUnread(req.ID)
+	case req.ID != "":
+		return cm.MarkRead(req.ID)
+	}
+	return errFeedReadTarget
+}
+
+// handleFeedFilters manages the feed's mute lists. Muted threads drop
+// comments replying to a post URL; muted keywords drop items whose title
+// contains them. Both also silence notifications. Saving marks matching
+// cached items read.
+// GET  /api/feed/filters
+// POST /api/feed/filters
+// Body: {"muted_threads":[...],"muted_keywords":[...]} (replaces the given lists) |
+// {"mute_thread":"<post url or feed item id>"} | {"unmute_thread":"<post url>"}
+func (s *Server) handleFeedFilters(w http.ResponseWriter, r *http.Request) {
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+
+	switch r.Method {
+	case http.MethodGet:
+		f, err := cm.LoadFilters()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(filtersResponse(f, 0))
+
+	case http.MethodPost:
+		var req struct {
+			MutedThreads  *[]string `json:"muted_threads"`
+			MutedKeywords *[]string `json:"muted_keywords"`
+			MuteThread    string    `json:"mute_thread"`
+			UnmuteThread  string    `json:"unmute_thread"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		var marked int
+		var err error
+		switch {
+		case req.MuteThread != "":
+			thread := req.MuteThread
+			// A feed item ID mutes the thread the item belongs to
+			if items, listErr := cm.List(); listErr == nil {
+				for _, item := range items {
+					if item.ID != thread {
+						continue
+					}
+					thread = item.URL
+					if item.Type == "comment" && item.TargetURL != "" {
+						thread = item.TargetURL
+					}
+					break
+				}
+			}
+			if !strings.HasPrefix(thread, "https://") && !strings.HasPrefix(thread, "http://") {
+				http.Error(w, "mute_thread must be a post URL or feed item ID", http.StatusBadRequest)
+				return
+			}
+			marked, err = cm.MuteThread(thread)
+		case req.UnmuteThread != "":
+			err = cm.UnmuteThread(req.UnmuteThread)
+		case req.MutedThreads != nil || req.MutedKeywords != nil:
+			f, loadErr := cm.LoadFilters()
+			if loadErr != nil {
+				http.Error(w, loadErr.Error(), http.StatusInternalServerError)
+				return
+			}
+			if req.MutedThreads != nil {
+				f.MutedThreads = *req.MutedThreads
+			}
+			if req.MutedKeywords != nil {
+				f.MutedKeywords = *req.MutedKeywords
+			}
+			marked, err = cm.SaveFilters(f)
+		default:
+			http.Error(w, "Missing muted_threads, muted_keywords, mute_thread, or unmute_thread", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			s.LogError("feed filters update failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		f, _ := cm.LoadFilters()
+		s.LogInfo("Feed filters updated: %d muted threads, %d muted keywords", len(f.MutedThreads), len(f.MutedKeywords))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(filtersResponse(f, marked))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func filtersResponse(f feed.Filters, markedRead int) map[string]interface{} {
+	threads, keywords := f.MutedThreads, f.MutedKeywords
+	if threads == nil {
+		threads = []string{}
+	}
+	if keywords == nil {
+		keywords = []string{}
+	}
+	return map[string]interface{}{
+		"muted_threads":  threads,
+		"muted_keywords": keywords,
+		"marked_read":    markedRead,
+	}
+}
+
+// handleFeedBookmark bookmarks a feed item or remote post and saves a
+// snapshot of its content, or removes a bookmark.
+// POST /api/feed/bookmark
+// Body: {"id":"x"} | {"url":"https://...","note":"..."} | {"id":"x","remove":true}
+func (s *Server) handleFeedBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID     string `json:"id"`
+		URL    string `json:"url"`
+		Note   string `json:"note"`
+		Remove bool   `json:"remove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	key := req.ID
+	if key == "" {
+		key = req.URL
+	}
+	if key == "" {
+		http.Error(w, "Missing id or url", http.StatusBadRequest)
+		return
+	}
+
+	if req.Remove {
+		removed, err := bookmark.Remove(s.DataDir, key)
+		if err != nil {
+			s.LogError("bookmark remove failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if removed == nil {
+			// A feed item ID differs from its bookmark ID; retry by URL
+			if item := bookmark.FindFeedItem(s.DataDir, s.GetDiscoveryDomain(), key); item != nil {
+				removed, _ = bookmark.Remove(s.DataDir, item.URL)
+			}
+		}
+		if removed == nil {
+			http.Error(w, "Bookmark not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"removed": removed,
+		})
+		return
+	}
+
+	opts := bookmark.Options{URL: req.URL}
+	if item := bookmark.FindFeedItem(s.DataDir, s.GetDiscoveryDomain(), key); item != nil {
+		opts = bookmark.FromFeedItem(*item)
+	} else if req.URL == "" {
+		http.Error(w, "Feed item not found", http.StatusNotFound)
+		return
+	}
+	opts.Note = req.Note
+	opts.Client = remote.NewClient()
+
+	b, added, snapErr, err := bookmark.Add(s.DataDir, opts)
+	if err != nil {
+		if errors.Is(err, bookmark.ErrInsecureURL) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.LogError("bookmark failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := map[string]interface{}{
+		"success":  true,
+		"added":    added,
+		"bookmark": b,
+	}
+	if snapErr != nil {
+		s.LogWarn("bookmark snapshot of %s failed: %v", b.URL, snapErr)
+		resp["snapshot_error"] = snapErr.Error()
+	} else if added {
+		s.LogInfo("Bookmarked %s", b.URL)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleBookmarks lists bookmarks, newest first.
+// GET /api/bookmarks
+func (s *Server) handleBookmarks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	f, err := bookmark.Load(bookmark.DefaultPath(s.DataDir))
+	if err != nil {
+		s.LogError("Failed to load bookmarks: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bookmarks": f.Bookmarks,
+		"total":     f.Count(),
 	})
 }
 
-// handleFeedRead marks feed items as read/unread.
-// POST /api/feed/read
-// Body: {"id":"x"} | {"id":"x","unread":true} | {"all":true} | {"from_id":"x"}
-func (s *Server) handleFeedRead(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// handleBookmark returns one bookmark with its snapshot, rendered the same
+// way as /api/remote/post.
+// GET /api/bookmarks/{id}
+func (s *Server) handleBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/bookmarks/")
+	f, err := bookmark.Load(bookmark.DefaultPath(s.DataDir))
+	if err != nil {
+		s.LogError("Failed to load bookmarks: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b := f.Get(id)
+	if id == "" || b == nil {
+		http.Error(w, "Bookmark not found", http.StatusNotFound)
+		return
+	}
+
+	raw, err := bookmark.ReadSnapshot(s.DataDir, b.ID)
+	if err != nil {
+		s.LogError("Failed to read bookmark snapshot: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var req struct {
-		ID     string `json:"id"`
-		Unread bool   `json:"unread"`
-		All    bool   `json:"all"`
-		FromID string `json:"from_id"`
+	var body, htmlContent string
+	if looksLikeHTML(raw) {
+		htmlContent = extractHTMLBody(raw)
+		body = raw
+	} else if raw != "" {
+		body = stripFrontmatter(raw)
+		rendered, renderErr := render.MarkdownToHTML(body)
+		if renderErr != nil {
+			s.LogError("bookmark render failed: %v", renderErr)
+			http.Error(w, "Failed to render snapshot", http.StatusInternalServerError)
+			return
+		}
+		htmlContent = rendered
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+	if htmlContent != "" {
+		htmlContent = sanitize.HTML(htmlContent)
+		sourceURL := b.SnapshotURL
+		if sourceURL == "" {
+			sourceURL = b.URL
+		}
+		htmlContent = remote.ApplyMediaPolicy(htmlContent, sourceURL, s.RemoteMediaPolicy())
 	}
 
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bookmark": b,
+		"content":  htmlContent,
+		"raw":      body,
+	})
+}
 
-	var err error
-	if req.All {
-		err = cm.MarkAllRead()
-	} else if req.FromID != "" {
-		err = cm.MarkUnreadFrom(req.FromID)
-	} else if req.ID != "" {
-		if req.Unread {
-			err = cm.MarkUnread(req.ID)
-		} else {
-			err = cm.MarkRead(req.ID)
+// handleFeedNextUnread supports keyboard triage of the feed with one round-trip
+// per keystroke. GET returns the next unread item after ?after=<id>; POST marks
+// {"id"} read and returns the item that follows it.
+// GET  /api/feed/next-unread?type=post|comment&after=<id>
+// POST /api/feed/next-unread  Body: {"id":"x","type":"post"}
+func (s *Server) handleFeedNextUnread(w http.ResponseWriter, r *http.Request) {
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+
+	var (
+		next      *feed.CachedFeedItem
+		remaining int
+		err       error
+		marked    string
+	)
+	switch r.Method {
+	case http.MethodGet:
+		next, remaining, err = cm.NextUnread(feed.NextOptions{
+			Type:  r.URL.Query().Get("type"),
+			After: r.URL.Query().Get("after"),
+		})
+	case http.MethodPost:
+		var req struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
 		}
-	} else {
-		http.Error(w, "Missing id, all, or from_id", http.StatusBadRequest)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.ID == "" {
+			http.Error(w, "Missing id", http.StatusBadRequest)
+			return
+		}
+		next, remaining, err = cm.MarkReadAndNext(req.ID, feed.NextOptions{Type: req.Type})
+		if errors.Is(err, feed.ErrItemNotFound) {
+			http.Error(w, "Feed item not found", http.StatusNotFound)
+			return
+		}
+		marked = req.ID
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
 	if err != nil {
-		s.LogError("feed read failed: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.LogError("feed next-unread failed: %v", err)
+		http.Error(w, "Failed to load the next unread item", http.StatusInternalServerError)
 		return
 	}
 
+	resp := map[string]interface{}{
+		"item":      next,
+		"remaining": remaining,
+	}
+	if marked != "" {
+		resp["marked"] = marked
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-	})
+	json.NewEncoder(w).Encode(resp)
 }
 
 // handleFeedCounts returns lightweight feed counts for sidebar badge.
@@ -2981,10 +4831,7 @@ func (s *Server) handleFeedCounts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
-
-	items, err := cm.List()
+	items, err := s.cachedFeedItems()
 	if err != nil {
 		s.LogError("feed counts failed: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2998,7 +4845,7 @@ func (s *Server) handleFeedCounts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stale, _ := cm.IsStale()
+	stale, _ := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain()).IsStale()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -3017,10 +4864,7 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
-
-	items, err := cm.List()
+	items, err := s.cachedFeedItems()
 	if err != nil {
 		s.LogError("feed grouped failed: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -3028,8 +4872,7 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build followed domains set
-	followingPath := following.DefaultPath(s.DataDir)
-	f, _ := following.Load(followingPath)
+	f, _ := s.cachedFollowing()
 	followedDomains := make(map[string]bool)
 	if f != nil {
 		for _, entry := range f.Following {
@@ -3055,6 +4898,8 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 		LastActivity    string   `json:"last_activity"`
 		PostUnread      bool     `json:"post_unread"`
 		ItemIDs         []string `json:"item_ids"`
+		// Author's accounts elsewhere, from the last identity proof check
+		Verified        []identity.Result `json:"verified,omitempty"`
 	}
 
 	groups := make(map[string]*feedGroup)
@@ -3129,10 +4974,15 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Build sorted slice
+	// Build sorted slice, with verified badges for each post's author
+	identities, _ := identity.LoadCache(s.DataDir)
 	result := make([]*feedGroup, 0, len(groups))
 	for _, key := range groupOrder {
-		result = append(result, groups[key])
+		g := groups[key]
+		if identities != nil {
+			g.Verified = identities.Verified(g.PostDomain)
+		}
+		result = append(result, g)
 	}
 
 	// Sort by last_activity descending
@@ -3140,7 +4990,7 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 		return result[i].LastActivity > result[j].LastActivity
 	})
 
-	stale, _ := cm.IsStale()
+	stale, _ := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain()).IsStale()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -3152,6 +5002,9 @@ func (s *Server) handleFeedGrouped(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleRemotePost fetches a remote post and returns it as rendered HTML.
+// Fresh entries in the content cache are served without a fetch, and stale
+// ones when the fetch fails ("cached": true). Fetched posts are cached when
+// prefetch is enabled.
 // GET /api/remote/post?url=https://example.com/posts/hello.md
 func (s *Server) handleRemotePost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -3170,27 +5023,32 @@ func (s *Server) handleRemotePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := remote.NewClient()
-
-	// Try fetching the URL as-is first
-	content, err := client.FetchContent(postURL)
-	fetchedURL := postURL
-
-	if err != nil {
-		s.LogError("remote post fetch failed: %v", err)
-		http.Error(w, "Failed to fetch remote post: "+err.Error(), http.StatusBadGateway)
-		return
-	}
-
-	// If the response looks like HTML (not markdown), the host likely served
-	// the rendered page instead of the raw source. Try the alternate extension.
-	if looksLikeHTML(content) {
-		altContent, altURL, altErr := client.TryAlternateExtension(postURL)
-		if altErr == nil && !looksLikeHTML(altContent) {
-			content = altContent
-			fetchedURL = altURL
+	// Serve from the content cache when fresh; fall back to a stale entry
+	// when the author's site can't be reached
+	cache := s.ContentCache()
+	cached := cache.Get(postURL)
+	var content, fetchedURL string
+	fromCache := false
+	if cached != nil && cached.Fresh() {
+		content, fetchedURL, fromCache = cached.Content, cached.FetchedURL, true
+	} else {
+		// FetchSource prefers the markdown source when the host serves the
+		// rendered page
+		var err error
+		content, fetchedURL, err = remote.NewClient().FetchSource(postURL)
+		switch {
+		case err == nil:
+			if s.PrefetchEnabled() {
+				cache.Put(postURL, fetchedURL, content)
+			}
+		case cached != nil:
+			s.LogWarn("remote post fetch failed, serving cached copy: %v", err)
+			content, fetchedURL, fromCache = cached.Content, cached.FetchedURL, true
+		default:
+			s.LogError("remote post fetch failed: %v", err)
+			http.Error(w, "Failed to fetch remote post: "+err.Error(), http.StatusBadGateway)
+			return
 		}
-		// If both extensions return HTML, use the original content as-is
 	}
 
 	var body, htmlContent string
@@ -3210,14 +5068,73 @@ func (s *Server) handleRemotePost(w http.ResponseWriter, r *http.Request) {
 		htmlContent = rendered
 	}
 
+	// Remote content is untrusted: sanitize before it reaches the UI
+	htmlContent = sanitize.HTML(htmlContent)
+	htmlContent = remote.ApplyMediaPolicy(htmlContent, fetchedURL, s.RemoteMediaPolicy())
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"url":     fetchedURL,
 		"content": htmlContent,
 		"raw":     body,
+		"cached":  fromCache,
 	})
 }
 
+// handleRemoteImage proxies a remote image so the browser never contacts the
+// remote host directly. Images are downsized to at most w pixels wide
+// (default remote.DefaultImageMaxWidth) and cached in .polis/cache/images.
+// GET /api/remote/image?url=https://example.com/images/cat.png[&w=800]
+func (s *Server) handleRemoteImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	imageURL := r.URL.Query().Get("url")
+	if !strings.HasPrefix(imageURL, "https://") && !strings.HasPrefix(imageURL, "http://") {
+		http.Error(w, "Missing or invalid 'url' parameter", http.StatusBadRequest)
+		return
+	}
+	width := 0
+	if v := r.URL.Query().Get("w"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid 'w' parameter", http.StatusBadRequest)
+			return
+		}
+		width = n
+	}
+
+	img, err := s.ImageCache().Get(imageURL, width)
+	if err != nil {
+		if errors.Is(err, remote.ErrNotImage) {
+			http.Error(w, "Remote resource is not an image", http.StatusUnsupportedMediaType)
+			return
+		}
+		if errors.Is(err, remote.ErrImageTooLarge) {
+			http.Error(w, "Remote image is too large", http.StatusUnprocessableEntity)
+			return
+		}
+		s.LogWarn("image proxy fetch failed for %s: %v", imageURL, err)
+		http.Error(w, "Failed to fetch image", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(img.Data)))
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// SVGs opened directly must not run scripts on the webapp origin
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	if img.FromCache {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.Write(img.Data)
+}
+
 // stripFrontmatter removes YAML frontmatter (---...---) from content.
 func stripFrontmatter(content string) string {
 	if !strings.HasPrefix(content, "---") {
@@ -3296,8 +5213,7 @@ func (s *Server) handleActivityStream(w http.ResponseWriter, r *http.Request) {
 	apiKey := s.DiscoveryKey
 
 	// Load following list to get followed domains
-	followingPath := following.DefaultPath(s.DataDir)
-	f, err := following.Load(followingPath)
+	f, err := s.cachedFollowing()
 	if err != nil {
 		// No following.json yet — return empty
 		w.Header().Set("Content-Type", "application/json")
@@ -3393,6 +5309,7 @@ type ConversationsResponse struct {
 		BlessedCount int                     `json:"blessed_count"`
 		Recent       []BlessingActivityEntry `json:"recent"`
 	} `json:"on_your_posts"`
+	RepliesNearYou []thread.Reply `json:"replies_near_you"`
 }
 
 // handleConversations returns comment threads and blessing activity from local cache.
@@ -3406,9 +5323,7 @@ func (s *Server) handleConversations(w http.ResponseWriter, r *http.Request) {
 	var resp ConversationsResponse
 
 	// 1. Comment threads from feed cache (type=comment, last 30 days)
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
-	items, err := cm.List()
+	items, err := s.cachedFeedItems()
 	if err != nil {
 		items = nil
 	}
@@ -3474,7 +5389,7 @@ func (s *Server) handleConversations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 2. Blessing activity from cached state
-	store := stream.NewStore(s.DataDir, discoveryDomain)
+	store := stream.NewStore(s.DataDir, s.GetDiscoveryDomain())
 	var blessingState stream.BlessingState
 	_ = store.LoadState("polis.blessing", &blessingState)
 
@@ -3491,33 +5406,265 @@ func (s *Server) handleConversations(w http.ResponseWriter, r *http.Request) {
 	resp.OnYourPosts.PendingCount = pendingCount
 	resp.OnYourPosts.BlessedCount = blessedCount
 
-	// Recent blessing entries (up to 10, sorted by updated_at desc)
-	blessings := make([]stream.BlessingEntry, len(blessingState.Blessings))
-	copy(blessings, blessingState.Blessings)
-	sort.Slice(blessings, func(i, j int) bool {
-		return blessings[i].UpdatedAt > blessings[j].UpdatedAt
-	})
-	if len(blessings) > 10 {
-		blessings = blessings[:10]
+	// Recent blessing entries (up to 10, sorted by updated_at desc)
+	blessings := make([]stream.BlessingEntry, len(blessingState.Blessings))
+	copy(blessings, blessingState.Blessings)
+	sort.Slice(blessings, func(i, j int) bool {
+		return blessings[i].UpdatedAt > blessings[j].UpdatedAt
+	})
+	if len(blessings) > 10 {
+		blessings = blessings[:10]
+	}
+
+	recentBlessings := make([]BlessingActivityEntry, len(blessings))
+	for i, b := range blessings {
+		recentBlessings[i] = BlessingActivityEntry{
+			Domain:    b.Actor,
+			Status:    b.Status,
+			TargetURL: b.TargetURL,
+			SourceURL: b.SourceURL,
+			UpdatedAt: b.UpdatedAt,
+		}
+	}
+	resp.OnYourPosts.Recent = recentBlessings
+	if resp.OnYourPosts.Recent == nil {
+		resp.OnYourPosts.Recent = []BlessingActivityEntry{}
+	}
+
+	// 3. Replies in threads we commented on (newest first, up to 10)
+	resp.RepliesNearYou = []thread.Reply{}
+	if tf, err := thread.Load(thread.DefaultPath(s.DataDir)); err == nil {
+		for i := len(tf.Replies) - 1; i >= 0 && len(resp.RepliesNearYou) < 10; i-- {
+			resp.RepliesNearYou = append(resp.RepliesNearYou, tf.Replies[i])
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// subscribeThread starts watching a remote post's comment thread.
+func (s *Server) subscribeThread(postURL string) {
+	if postURL == "" {
+		return
+	}
+	s.threadsMu.Lock()
+	defer s.threadsMu.Unlock()
+
+	path := thread.DefaultPath(s.DataDir)
+	f, err := thread.Load(path)
+	if err != nil {
+		s.LogWarn("thread subscribe: %v", err)
+		return
+	}
+	if f.Subscribe(postURL) {
+		if err := thread.Save(path, f); err != nil {
+			s.LogWarn("thread subscribe: %v", err)
+		}
+	}
+}
+
+// handleThreads manages comment thread subscriptions.
+// GET lists watched threads and recent replies; POST {"post_url"} subscribes;
+// DELETE {"post_url"} mutes a thread so it is not re-subscribed.
+// POST /api/threads?check=true also runs a check immediately.
+func (s *Server) handleThreads(w http.ResponseWriter, r *http.Request) {
+	path := thread.DefaultPath(s.DataDir)
+
+	switch r.Method {
+	case http.MethodGet:
+		s.threadsMu.Lock()
+		f, err := thread.Load(path)
+		s.threadsMu.Unlock()
+		if err != nil {
+			s.LogError("threads load failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		threads := f.Active()
+		if threads == nil {
+			threads = []thread.Subscription{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"threads": threads,
+			"replies": f.Replies,
+		})
+
+	case http.MethodPost, http.MethodDelete:
+		if r.Method == http.MethodPost && r.URL.Query().Get("check") == "true" {
+			replies := s.checkThreadReplies()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":     true,
+				"new_replies": replies,
+			})
+			return
+		}
+
+		var req struct {
+			PostURL string `json:"post_url"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(req.PostURL, "https://") {
+			http.Error(w, "post_url must use HTTPS", http.StatusBadRequest)
+			return
+		}
+
+		s.threadsMu.Lock()
+		f, err := thread.Load(path)
+		if err != nil {
+			s.threadsMu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var changed bool
+		if r.Method == http.MethodPost {
+			changed = f.Subscribe(req.PostURL)
+		} else {
+			changed = f.Unsubscribe(req.PostURL)
+		}
+		if changed {
+			err = thread.Save(path, f)
+		}
+		s.threadsMu.Unlock()
+		if err != nil {
+			s.LogError("threads save failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"changed": changed,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleReconcile handles GET/POST /api/reconcile.
+// GET returns the last saved reconciliation report (null if none has run);
+// POST runs a reconciliation now, repairing divergence when ?repair=true.
+func (s *Server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		report, err := reconcile.LoadReport(s.DataDir)
+		if err != nil {
+			s.LogError("reconcile report load failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"report": report,
+		})
+
+	case http.MethodPost:
+		repair := r.URL.Query().Get("repair") == "true"
+		report, err := s.runReconcile(repair)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"report":  report,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleConflicts handles GET/POST /api/conflicts.
+// GET returns the last saved report of posts deployed from another copy of
+// the site (null if no check has run); POST checks the live site now.
+func (s *Server) handleConflicts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		report, err := conflict.LoadReport(s.DataDir)
+		if err != nil {
+			s.LogError("conflict report load failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"report": report,
+		})
+
+	case http.MethodPost:
+		report, err := s.checkSyncConflicts()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"report":  report,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleConflictResolve handles POST /api/conflicts/resolve with
+// {"path": "...", "resolution": "pull"|"keep"|"merge-frontmatter"}.
+func (s *Server) handleConflictResolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Path       string `json:"path"`
+		Resolution string `json:"resolution"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Path == "" {
+		http.Error(w, "path and resolution are required", http.StatusBadRequest)
+		return
+	}
+	switch req.Resolution {
+	case conflict.ResolvePull, conflict.ResolveKeep, conflict.ResolveMergeFrontmatter:
+	default:
+		http.Error(w, "resolution must be pull, keep, or merge-frontmatter", http.StatusBadRequest)
+		return
+	}
+
+	err := conflict.Resolve(conflict.ResolveOptions{
+		DataDir:    s.DataDir,
+		BaseURL:    s.GetBaseURL(),
+		Path:       req.Path,
+		How:        req.Resolution,
+		PrivateKey: s.PrivateKey,
+	}, s.conflictClient())
+	if err != nil {
+		s.LogError("failed to resolve %s: %v", req.Path, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
+	s.LogInfo("Resolved %s: %s", req.Path, req.Resolution)
 
-	recentBlessings := make([]BlessingActivityEntry, len(blessings))
-	for i, b := range blessings {
-		recentBlessings[i] = BlessingActivityEntry{
-			Domain:    b.Actor,
-			Status:    b.Status,
-			TargetURL: b.TargetURL,
-			SourceURL: b.SourceURL,
-			UpdatedAt: b.UpdatedAt,
+	if req.Resolution != conflict.ResolveKeep {
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("post-resolve render failed: %v", err)
 		}
 	}
-	resp.OnYourPosts.Recent = recentBlessings
-	if resp.OnYourPosts.Recent == nil {
-		resp.OnYourPosts.Recent = []BlessingActivityEntry{}
-	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"path":       req.Path,
+		"resolution": req.Resolution,
+	})
 }
 
 // PulseHighlight is a recent feed item for the pulse dashboard.
@@ -3574,9 +5721,7 @@ func (s *Server) handlePulse(w http.ResponseWriter, r *http.Request) {
 	resp.Site.IncomingPending = counts.IncomingPending
 
 	// Recent highlights: top 5 feed items from last 7 days
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
-	items, err := cm.List()
+	items, err := s.cachedFeedItems()
 	if err != nil {
 		items = nil
 	}
@@ -3701,6 +5846,125 @@ func (s *Server) handleFollowerCount(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// NetworkComment is a comment exchanged with a domain, in either direction.
+type NetworkComment = author.Comment
+
+// NetworkBlessingEvent is a single blessing decision between us and a domain.
+type NetworkBlessingEvent = author.BlessingEvent
+
+// NetworkDomainResponse is the JSON shape returned by GET /api/network/domain/{domain}.
+type NetworkDomainResponse = author.Summary
+
+// handleNetworkDomain returns everything known locally about one domain:
+// follow status in both directions, comments exchanged, blessing history,
+// feed activity, a trust score, and the most recent activity timestamp.
+// All data comes from local cached state — no DS queries. See
+// author.Summarize for how the trust score is tallied.
+// GET /api/network/domain/{domain}
+func (s *Server) handleNetworkDomain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/network/domain/"))
+	if !author.ValidDomain(domain) {
+		http.Error(w, "Invalid domain", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(author.Summarize(s.DataDir, s.GetDiscoveryDomain(), domain))
+}
+
+// handleAuthor returns an author's profile: their identity from
+// .well-known/polis (site title, author name, public key), follow state,
+// cached feed items (newest first), and the same interaction history as
+// /api/network/domain. ?limit caps the items (default 50); ?offline=true
+// skips fetching .well-known/polis and uses the following entry instead.
+// GET /api/authors/{domain}
+func (s *Server) handleAuthor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/authors/"))
+	if !author.ValidDomain(domain) {
+		http.Error(w, "Invalid domain", http.StatusBadRequest)
+		return
+	}
+
+	opts := author.ProfileOptions{}
+	opts.Limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if r.URL.Query().Get("offline") != "true" {
+		opts.Client = remote.NewClient()
+	}
+	profile := author.LoadProfile(s.DataDir, s.GetDiscoveryDomain(), domain, opts)
+	if profile.WellKnownError != "" {
+		s.LogWarn("author %s: %s", domain, profile.WellKnownError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// handleGraph returns the local social graph: follows in both directions and
+// comment and blessing edges.
+// GET /api/graph?format=json|dot|graphml
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = graph.FormatJSON
+	}
+	if !slices.Contains(graph.Formats, format) {
+		http.Error(w, "Unknown format (expected json, dot, or graphml)", http.StatusBadRequest)
+		return
+	}
+	self := site.GetAuthorDomain(s.DataDir)
+	if self == "" {
+		http.Error(w, "Not configured - please complete setup first", http.StatusBadRequest)
+		return
+	}
+
+	g := graph.Build(s.DataDir, s.GetDiscoveryDomain(), self)
+	w.Header().Set("Content-Type", graph.ContentType(format))
+	if err := graph.Write(w, g, format); err != nil {
+		s.LogError("Failed to write graph: %v", err)
+	}
+}
+
+// statsMaxAge is how long /api/stats serves the cached metadata/stats.json
+// before recomputing it.
+const statsMaxAge = time.Hour
+
+// handleStats returns site statistics, recomputed when the cache is older
+// than statsMaxAge or ?refresh=true.
+// GET /api/stats
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxAge := statsMaxAge
+	if r.URL.Query().Get("refresh") == "true" {
+		maxAge = 0
+	}
+
+	st, err := stats.Cached(s.DataDir, s.GetDiscoveryDomain(), site.GetAuthorDomain(s.DataDir), maxAge)
+	if err != nil {
+		s.LogError("Failed to compute stats: %v", err)
+		http.Error(w, "Failed to compute stats", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
 // handleNotifications returns a paginated list of notifications.
 // GET /api/notifications?offset=0&limit=20&include_read=false
 func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
@@ -3728,6 +5992,7 @@ func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
 	if items == nil {
 		items = []notification.StateEntry{}
 	}
+	s.addFollowerTitles(items)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -3878,7 +6143,12 @@ func (s *Server) handleWidgetPublishComment(w http.ResponseWriter, r *http.Reque
 		Content:   text,
 	}
 
-	signed, err := comment.SignComment(s.DataDir, draft, authorDomain, siteURL, s.PrivateKey)
+	privKey, err := s.signingKey("")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	signed, err := comment.SignComment(s.DataDir, draft, authorDomain, siteURL, privKey)
 	if err != nil {
 		s.LogError("widget publish comment: sign failed: %v", err)
 		http.Error(w, "Failed to sign comment", http.StatusInternalServerError)
@@ -3895,6 +6165,9 @@ func (s *Server) handleWidgetPublishComment(w http.ResponseWriter, r *http.Reque
 	if err != nil {
 		s.LogError("widget publish comment: beseech failed: %v", err)
 		blessingStatus = "error"
+		if d := comment.GetDelivery(s.DataDir, signed.Meta.ID); d != nil && d.NextAttempt != "" {
+			blessingStatus = "queued"
+		}
 	} else if result.AutoBlessed {
 		blessingStatus = "granted"
 	}
@@ -3955,7 +6228,7 @@ func (s *Server) handleWidgetFollow(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodPost:
-		result, err := following.FollowWithBlessing(followingPath, authorURL, discoveryClient, remoteClient, s.PrivateKey)
+		result, err := following.FollowWithBlessing(followingPath, authorURL, discoveryClient, remoteClient, s.PrivateKey, s.streamConfig())
 		if err != nil {
 			s.LogError("widget follow failed: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -3969,7 +6242,7 @@ func (s *Server) handleWidgetFollow(w http.ResponseWriter, r *http.Request) {
 		})
 
 	case http.MethodDelete:
-		result, err := following.UnfollowWithDenial(followingPath, authorURL, discoveryClient, remoteClient, s.PrivateKey)
+		result, err := following.UnfollowWithDenial(followingPath, authorURL, discoveryClient, remoteClient, s.PrivateKey, s.streamConfig())
 		if err != nil {
 			s.LogError("widget unfollow failed: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -3987,6 +6260,110 @@ func (s *Server) handleWidgetFollow(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GET /api/widget/state — Report this site's relationship to an author and post,
+// so widget and extension clients can render follow/comment buttons correctly.
+// Query params: author=<domain or url>, post=<post url>. At least one is
+// required; author defaults to the site the post is on.
+func (s *Server) handleWidgetState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authorURL := r.URL.Query().Get("author")
+	postURL := polisurl.NormalizeToMD(r.URL.Query().Get("post"))
+	if authorURL == "" && postURL == "" {
+		http.Error(w, "author or post is required", http.StatusBadRequest)
+		return
+	}
+	if authorURL == "" {
+		if u, err := url.Parse(postURL); err == nil && u.Host != "" {
+			authorURL = u.Scheme + "://" + u.Host
+		}
+	}
+	if authorURL != "" && !strings.HasPrefix(authorURL, "https://") && !strings.HasPrefix(authorURL, "http://") {
+		authorURL = "https://" + authorURL
+	}
+
+	state := map[string]interface{}{
+		"author":    authorURL,
+		"following": false,
+	}
+	if authorURL != "" {
+		f, err := s.cachedFollowing()
+		if err != nil {
+			s.LogError("widget state: failed to load following: %v", err)
+			http.Error(w, "Failed to load following", http.StatusInternalServerError)
+			return
+		}
+		state["following"] = f.IsFollowing(authorURL)
+	}
+
+	if postURL != "" {
+		state["post"] = postURL
+
+		// Reading progress comes from the feed cache, when the post is in it
+		readAt := ""
+		inFeed := false
+		items, err := s.cachedFeedItems()
+		if err != nil {
+			s.LogWarn("widget state: failed to read feed cache: %v", err)
+		}
+		for _, item := range items {
+			if polisurl.NormalizeToMD(item.URL) == postURL {
+				inFeed = true
+				readAt = item.ReadAt
+				break
+			}
+		}
+		state["in_feed"] = inFeed
+		state["read"] = readAt != ""
+		state["read_at"] = readAt
+
+		// Signed comments on the post, in any status past draft
+		type commentState struct {
+			ID         string `json:"id"`
+			CommentURL string `json:"comment_url,omitempty"`
+			Status     string `json:"status"`
+			Timestamp  string `json:"timestamp,omitempty"`
+		}
+		comments := []commentState{}
+		for _, status := range []string{comment.StatusPending, comment.StatusBlessed, comment.StatusDenied} {
+			metas, err := comment.ListComments(s.DataDir, status)
+			if err != nil {
+				s.LogError("widget state: failed to list %s comments: %v", status, err)
+				http.Error(w, "Failed to list comments", http.StatusInternalServerError)
+				return
+			}
+			for _, m := range metas {
+				if polisurl.NormalizeToMD(m.InReplyTo) == postURL {
+					comments = append(comments, commentState{ID: m.ID, CommentURL: m.CommentURL, Status: status, Timestamp: m.Timestamp})
+				}
+			}
+		}
+		state["commented"] = len(comments) > 0
+		state["comments"] = comments
+
+		// Unsigned comment drafts replying to the post
+		drafts, err := comment.ListDrafts(s.DataDir)
+		if err != nil {
+			s.LogError("widget state: failed to list comment drafts: %v", err)
+			http.Error(w, "Failed to list drafts", http.StatusInternalServerError)
+			return
+		}
+		matching := []*comment.CommentDraft{}
+		for _, d := range drafts {
+			if polisurl.NormalizeToMD(d.InReplyTo) == postURL {
+				matching = append(matching, d)
+			}
+		}
+		state["drafts"] = matching
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
 // GET /api/widget/connect — Issue widget token from session and redirect.
 // Query params: return=<url>
 // This endpoint is same-origin (dashboard) — session cookie auth is valid here.
@@ -4147,8 +6524,214 @@ func (s *Server) handleCounts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	counts := s.computeAllCounts()
+	writeJSONWithETag(w, r, s.computeAllCounts())
+}
+
+// handleUnpublish moves a published post to the trash and re-renders the site.
+// DELETE /api/posts/{path}
+func (s *Server) handleUnpublish(w http.ResponseWriter, r *http.Request) {
+	postPath := strings.TrimPrefix(r.URL.Path, "/api/posts/")
+	if err := validatePostPath(postPath); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := trash.Move(s.DataDir, postPath)
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			http.Error(w, "Post not found", http.StatusNotFound)
+		case errors.Is(err, trash.ErrUnsupported):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			s.LogError("failed to unpublish %s: %v", postPath, err)
+			http.Error(w, "Failed to unpublish post", http.StatusInternalServerError)
+		}
+		return
+	}
+	s.LogInfo("Unpublished %s (trash ID %s)", postPath, item.ID)
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-unpublish render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"item":    item,
+	})
+}
+
+// handleUnpublishToDraft turns a published post back into a draft; the
+// signed post goes to the trash.
+// POST /api/posts/{path}/unpublish-to-draft
+func (s *Server) handleUnpublishToDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	postPath := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/posts/"), "/unpublish-to-draft")
+	if err := validatePostPath(postPath); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := publish.UnpublishToDraft(s.DataDir, postPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		s.LogError("failed to unpublish %s to draft: %v", postPath, err)
+		http.Error(w, "Failed to unpublish post", http.StatusInternalServerError)
+		return
+	}
+	s.LogInfo("Unpublished %s to draft %s", postPath, result.DraftID)
+
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("post-unpublish render failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// trashRetentionDays returns the trash_retention_days setting.
+func (s *Server) trashRetentionDays() int {
+	if s.Settings != nil {
+		return s.Settings.Int("trash_retention_days")
+	}
+	return trash.DefaultRetentionDays
+}
+
+// handleTrash lists trashed drafts and posts, purging expired ones first.
+// GET /api/trash
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	retention := s.trashRetentionDays()
+	if n, err := trash.PurgeExpired(s.DataDir, retention); err != nil {
+		s.LogWarn("Failed to purge expired trash: %v", err)
+	} else if n > 0 {
+		s.LogInfo("Purged %d expired trash item(s)", n)
+	}
+
+	items, err := trash.List(s.DataDir)
+	if err != nil {
+		s.LogError("Failed to list trash: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type trashEntry struct {
+		trash.Item
+		ExpiresAt string `json:"expires_at"`
+	}
+	entries := make([]trashEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, trashEntry{Item: item, ExpiresAt: item.ExpiresAt(retention).UTC().Format(time.RFC3339)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":          entries,
+		"retention_days": retention,
+	})
+}
+
+// handleTrashRestore puts a trashed item back. Restored posts are
+// re-rendered onto the site.
+// POST /api/trash/restore  Body: {"id":"..."}
+func (s *Server) handleTrashRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := trash.Restore(s.DataDir, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, trash.ErrNotFound):
+			http.Error(w, "Trash item not found", http.StatusNotFound)
+		case errors.Is(err, trash.ErrExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			s.LogError("failed to restore %s: %v", req.ID, err)
+			http.Error(w, "Failed to restore", http.StatusInternalServerError)
+		}
+		return
+	}
+	s.LogInfo("Restored %s from trash", item.Path)
+
+	if item.Kind == trash.KindPost {
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("post-restore render failed: %v", err)
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(counts)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"item":    item,
+	})
+}
+
+// handleTrashPurge permanently deletes one trashed item, or all of them.
+// POST /api/trash/purge  Body: {"id":"..."} or {"all":true}
+func (s *Server) handleTrashPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID  string `json:"id"`
+		All bool   `json:"all"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.ID == "" && !req.All) {
+		http.Error(w, "id or all is required", http.StatusBadRequest)
+		return
+	}
+
+	ids := []string{req.ID}
+	if req.All {
+		items, err := trash.List(s.DataDir)
+		if err != nil {
+			s.LogError("Failed to list trash: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ids = ids[:0]
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+	}
+	for _, id := range ids {
+		if err := trash.Purge(s.DataDir, id); err != nil {
+			if errors.Is(err, trash.ErrNotFound) {
+				http.Error(w, "Trash item not found", http.StatusNotFound)
+				return
+			}
+			s.LogError("failed to purge %s: %v", id, err)
+			http.Error(w, "Failed to purge", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"purged":  len(ids),
+	})
 }
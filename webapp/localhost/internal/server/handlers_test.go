@@ -3,7 +3,9 @@ package server
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,11 +14,37 @@ import (
 	"testing"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/bookmark"
+	"github.com/vdibart/polis-cli/cli-go/pkg/client"
+	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
+	"github.com/vdibart/polis-cli/cli-go/pkg/deploy"
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/graph"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/identity"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mention"
+	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
+	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/protected"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/reconcile"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/schedule"
 	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
+	"github.com/vdibart/polis-cli/cli-go/pkg/snippet"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stats"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+	"github.com/vdibart/polis-cli/cli-go/pkg/thread"
+	"github.com/vdibart/polis-cli/cli-go/pkg/trash"
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
 )
 
 // Helper to create a test server with temp directory
@@ -379,14 +407,10 @@ func TestHandleLink_Success(t *testing.T) {
 	wellKnownData, _ := json.MarshalIndent(wellKnown, "", "  ")
 	os.WriteFile(filepath.Join(sourceDir, ".well-known", "polis"), wellKnownData, 0644)
 
-	// Create a target server with empty data dir
-	targetDir := t.TempDir()
-	targetDataDir := filepath.Join(targetDir, "data")
-	os.MkdirAll(targetDataDir, 0755)
 	_ = sourceSrv // suppress unused warning
 
-	// For this test, we need to create a mock server setup
-	// Since handleLink uses os.Executable(), we'll test the validation part
+	// The linked site is registered in ~/.polis/workspaces.json
+	t.Setenv("HOME", t.TempDir())
 	s := newTestServer(t)
 
 	body := jsonBody(t, map[string]string{"path": sourceDir})
@@ -396,14 +420,19 @@ func TestHandleLink_Success(t *testing.T) {
 
 	s.handleLink(rr, req)
 
-	// The test may fail due to symlink creation issues in test environment
-	// but we verify the validation works
-	if rr.Code == http.StatusOK {
-		var resp map[string]interface{}
-		json.Unmarshal(rr.Body.Bytes(), &resp)
-		if resp["success"] != true {
-			t.Errorf("expected success=true, got %v", resp["success"])
-		}
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["success"] != true {
+		t.Errorf("expected success=true, got %v", resp["success"])
+	}
+	if s.DataDir != sourceDir {
+		t.Errorf("DataDir = %q, want the linked site %q", s.DataDir, sourceDir)
+	}
+	if got := workspace.ActiveDir(); got != sourceDir {
+		t.Errorf("active workspace = %q, want %q", got, sourceDir)
 	}
 }
 
@@ -664,6 +693,55 @@ func TestHandleDrafts_SaveAutoGenerateID(t *testing.T) {
 	}
 }
 
+func TestHandleDrafts_GeneratedIDsDoNotCollide(t *testing.T) {
+	s := newTestServer(t)
+
+	ids := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/drafts", jsonBody(t, map[string]string{"markdown": "# Draft"}))
+		rr := httptest.NewRecorder()
+		s.handleDrafts(rr, req)
+
+		var resp map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		id, _ := resp["id"].(string)
+		if !idgen.IsULID(strings.TrimPrefix(id, "draft-")) {
+			t.Errorf("expected draft-<ULID>, got %s", id)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 distinct draft IDs, got %d", len(ids))
+	}
+}
+
+func TestMigrateDraftIDs(t *testing.T) {
+	s := newTestServer(t)
+	draftsDir := filepath.Join(s.DataDir, ".polis", "posts", "drafts")
+	os.WriteFile(filepath.Join(draftsDir, "draft-1767225600.md"), []byte("# Old"), 0644)
+	os.WriteFile(filepath.Join(draftsDir, "my-notes.md"), []byte("# Named"), 0644)
+
+	s.migrateDraftIDs()
+
+	entries, _ := os.ReadDir(draftsDir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected 2 drafts, got %v", names)
+	}
+	for _, name := range names {
+		if name == "my-notes.md" {
+			continue
+		}
+		ts, ok := idgen.Time(strings.TrimSuffix(strings.TrimPrefix(name, "draft-"), ".md"))
+		if !ok || !ts.Equal(time.Unix(1767225600, 0)) {
+			t.Errorf("draft %s not migrated with original timestamp (%v)", name, ts)
+		}
+	}
+}
+
 func TestHandleDrafts_SaveSanitizesID(t *testing.T) {
 	s := newTestServer(t)
 
@@ -802,10 +880,14 @@ func TestHandleDraft_Delete(t *testing.T) {
 		t.Errorf("expected status 200, got %d", rr.Code)
 	}
 
-	// Verify file was deleted
+	// Verify file was moved to trash
 	if _, err := os.Stat(draftPath); !os.IsNotExist(err) {
 		t.Error("draft file should be deleted")
 	}
+	items, _ := trash.List(s.DataDir)
+	if len(items) != 1 || items[0].Path != ".polis/posts/drafts/to-delete.md" {
+		t.Errorf("expected draft in trash, got %v", items)
+	}
 }
 
 func TestHandleDraft_DeleteNonexistent(t *testing.T) {
@@ -816,8 +898,8 @@ func TestHandleDraft_DeleteNonexistent(t *testing.T) {
 
 	s.handleDraft(rr, req)
 
-	if rr.Code != http.StatusInternalServerError {
-		t.Errorf("expected status 500, got %d", rr.Code)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
 	}
 }
 
@@ -1060,6 +1142,43 @@ func TestHandlePosts_WithPosts(t *testing.T) {
 	}
 }
 
+func TestHandlePosts_ConditionalGet(t *testing.T) {
+	s := newConfiguredServer(t)
+	indexPath := filepath.Join(s.DataDir, "metadata", "public.jsonl")
+	os.WriteFile(indexPath, []byte(`{"path":"posts/20260101/first.md","title":"First Post"}`+"\n"), 0644)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		s.handlePosts(rr, req)
+		return rr
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d %q", first.Code, etag)
+	}
+
+	if rr := get(etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected empty 304 for matching ETag, got %d with %d bytes", rr.Code, rr.Body.Len())
+	}
+
+	// Publishing changes the index and so the tag
+	os.WriteFile(indexPath, []byte(`{"path":"posts/20260101/first.md","title":"First Post"}`+"\n"+
+		`{"path":"posts/20260102/second.md","title":"Second Post"}`+"\n"), 0644)
+	rr := get(etag)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 after the index changed, got %d", rr.Code)
+	}
+	if rr.Header().Get("ETag") == etag {
+		t.Error("expected a new ETag after the index changed")
+	}
+}
+
 func TestHandlePosts_MethodNotAllowed(t *testing.T) {
 	s := newTestServer(t)
 
@@ -1412,6 +1531,37 @@ func TestHandleCommentSign_DraftNotFound(t *testing.T) {
 	}
 }
 
+func TestHandleCommentSign_QueuesDelivery(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	rr := httptest.NewRecorder()
+	s.handleCommentSign(rr, httptest.NewRequest(http.MethodPost, "/api/comments/sign", jsonBody(t, map[string]string{
+		"in_reply_to": "https://alice.polis.pub/posts/20260101/hello.md",
+		"content":     "Great post!",
+	})))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("sign: %d %s", rr.Code, rr.Body.String())
+	}
+	var signed struct {
+		Comment  comment.CommentMeta `json:"comment"`
+		Delivery *comment.Delivery   `json:"delivery"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &signed)
+	if signed.Delivery == nil || signed.Delivery.Status != comment.DeliveryPending || signed.Delivery.CommentID != signed.Comment.ID {
+		t.Fatalf("delivery = %+v", signed.Delivery)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleCommentDrafts(rr, httptest.NewRequest(http.MethodGet, "/api/comments/drafts", nil))
+	var list struct {
+		Deliveries []comment.Delivery `json:"deliveries"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Deliveries) != 1 || list.Deliveries[0].CommentID != signed.Comment.ID {
+		t.Errorf("deliveries = %+v", list.Deliveries)
+	}
+}
+
 // ============================================================================
 // handleCommentsPending/Blessed/Denied Tests
 // ============================================================================
@@ -1624,6 +1774,36 @@ func TestHandleBlessingGrant_MissingCommentURL(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// handleBlessingBulk Tests
+// ============================================================================
+
+func TestHandleBlessingBulk_Validation(t *testing.T) {
+	s := newConfiguredServer(t)
+	s.DiscoveryURL = "https://discovery.example.com"
+	s.DiscoveryKey = "test-key"
+
+	rr := httptest.NewRecorder()
+	s.handleBlessingBulk(rr, httptest.NewRequest(http.MethodGet, "/api/blessing/bulk", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected status 405, got %d", rr.Code)
+	}
+
+	for _, body := range []string{
+		`{}`,
+		`{"actions":[]}`,
+		`{"actions":[{"comment_version":"sha256:abc","action":"approve"}]}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/blessing/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		s.handleBlessingBulk(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+		}
+	}
+}
+
 // ============================================================================
 // handleBlessingDeny Tests
 // ============================================================================
@@ -2285,6 +2465,53 @@ func TestSaveConfig_Success(t *testing.T) {
 	}
 }
 
+func TestSaveConfig_KeepsIngestSettings(t *testing.T) {
+	s := newTestServer(t)
+	configPath := filepath.Join(s.DataDir, ".polis", "webapp-config.json")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(`{"view_mode":"list","ingest":{"allow":"me@example.com"}}`), 0644)
+
+	s.LoadConfig()
+	s.Config.ViewMode = "browser"
+	if err := s.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), `"allow": "me@example.com"`) {
+		t.Errorf("ingest settings dropped on save:\n%s", data)
+	}
+}
+
+func TestSaveConfig_KeepsUnknownKeys(t *testing.T) {
+	s := newTestServer(t)
+	configPath := filepath.Join(s.DataDir, ".polis", "webapp-config.json")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(`{"view_mode":"list","subdomain":"old","future_setting":{"on":true}}`), 0644)
+
+	s.LoadConfig()
+	s.Config.ViewMode = ""
+	if err := s.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var saved map[string]json.RawMessage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved["future_setting"]), `"on": true`) {
+		t.Errorf("unknown key dropped on save:\n%s", data)
+	}
+	// Known keys follow the struct, including ones cleared in memory
+	if _, ok := saved["view_mode"]; ok {
+		t.Errorf("cleared view_mode kept on save:\n%s", data)
+	}
+	if _, ok := saved["subdomain"]; ok {
+		t.Errorf("deprecated subdomain kept on save:\n%s", data)
+	}
+}
+
 func TestLoadKeys_NoFiles(t *testing.T) {
 	s := newTestServer(t)
 
@@ -2517,6 +2744,26 @@ func TestLoadEnv_POLIS_BASE_URL_Subdomain(t *testing.T) {
 	}
 }
 
+func TestLoadEnv_EnvironmentOverridesEnvFile(t *testing.T) {
+	s := newTestServer(t)
+
+	envContent := `DISCOVERY_SERVICE_URL=https://from-file.com`
+	os.WriteFile(filepath.Join(s.DataDir, ".env"), []byte(envContent), 0644)
+	os.MkdirAll(filepath.Join(s.DataDir, ".polis"), 0755)
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "webapp-config.json"), []byte(`{"log_level": 1}`), 0644)
+	t.Setenv("DISCOVERY_SERVICE_URL", "https://from-env.com")
+	t.Setenv("POLIS_LOG_LEVEL", "2")
+
+	s.LoadEnv()
+
+	if s.DiscoveryURL != "https://from-env.com" {
+		t.Errorf("expected environment to override .env, got %s", s.DiscoveryURL)
+	}
+	if s.Settings == nil || s.Settings.Int("log_level") != 2 {
+		t.Errorf("expected log_level 2 from environment")
+	}
+}
+
 func TestGetSubdomain_FallbackToConfig(t *testing.T) {
 	// Test backwards compat: old configs with Subdomain field but no BaseURL
 	s := newTestServer(t)
@@ -3287,6 +3534,50 @@ func TestHandleRenderPage_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestHandleRenderAudit_MethodNotAllowed(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/render-audit", nil)
+	rr := httptest.NewRecorder()
+
+	s.handleRenderAudit(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleRenderShortcodes(t *testing.T) {
+	s := newTestServer(t)
+	setupTestTheme(t, s, "turbo")
+	os.WriteFile(filepath.Join(s.DataDir, "snippets", "sig.md"), []byte("-- *the editors*"), 0644)
+
+	body := jsonBody(t, map[string]string{"markdown": "{{snippet:sig}} {{snippet:missing}}"})
+	req := httptest.NewRequest(http.MethodPost, "/api/render-shortcodes", body)
+	rr := httptest.NewRecorder()
+
+	s.handleRenderShortcodes(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Markdown string   `json:"markdown"`
+		HTML     string   `json:"html"`
+		Warnings []string `json:"warnings"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Markdown != "-- *the editors* {{snippet:missing}}" {
+		t.Errorf("unexpected markdown: %q", resp.Markdown)
+	}
+	if !strings.Contains(resp.HTML, "<em>the editors</em>") {
+		t.Errorf("unexpected html: %q", resp.HTML)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", resp.Warnings)
+	}
+}
+
 // ============================================================================
 // handleSnippet Tests - Source Tier Preservation
 // ============================================================================
@@ -3481,6 +3772,71 @@ func TestHandleSnippet_SourcePreservedInResponse(t *testing.T) {
 	}
 }
 
+func TestHandleSnippet_Versions(t *testing.T) {
+	s := newTestServer(t)
+	save := func(content string) {
+		t.Helper()
+		body := jsonBody(t, map[string]string{"content": content, "source": "global"})
+		req := httptest.NewRequest(http.MethodPut, "/api/snippets/footer.html", body)
+		rr := httptest.NewRecorder()
+		s.handleSnippet(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("save: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	save("<p>Original footer</p>")
+	save("<p>Original footer</p>") // Unchanged: no version
+	save("")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/snippets/footer.html/versions", nil)
+	rr := httptest.NewRecorder()
+	s.handleSnippet(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var list struct {
+		Versions []snippet.SnippetVersion `json:"versions"`
+	}
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list.Versions) != 1 {
+		t.Fatalf("expected 1 version, got %+v", list.Versions)
+	}
+
+	// Restore the blanked footer
+	req = httptest.NewRequest(http.MethodPost, "/api/snippets/footer.html/versions/"+list.Versions[0].Version+"/restore", nil)
+	rr = httptest.NewRecorder()
+	s.handleSnippet(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("restore: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	data, _ := os.ReadFile(filepath.Join(s.DataDir, "snippets", "footer.html"))
+	if string(data) != "<p>Original footer</p>" {
+		t.Errorf("expected footer restored, got %q", data)
+	}
+
+	// The blank version the restore replaced is kept too
+	versions, _ := snippet.ListVersions(s.DataDir, "", "", "footer.html", "global")
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions after restore, got %d", len(versions))
+	}
+
+	// Deleted snippets can be restored
+	if err := snippet.DeleteSnippet(s.DataDir, "footer.html"); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := snippet.ListVersions(s.DataDir, "", "", "footer", "global")
+	if err != nil || len(versions) != 3 {
+		t.Fatalf("expected 3 versions after delete, got %d (%v)", len(versions), err)
+	}
+	if _, err := snippet.RestoreVersion(s.DataDir, "", "", "footer", "global", versions[0].Version); err != nil {
+		t.Fatalf("restore after delete: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(s.DataDir, "snippets", "footer.html"))
+	if string(data) != "<p>Original footer</p>" {
+		t.Errorf("expected deleted footer restored, got %q", data)
+	}
+}
+
 // ============================================================================
 // Webhook Safety Regression Tests
 // ============================================================================
@@ -3805,6 +4161,10 @@ func TestValidatePostPath_Canonicalization(t *testing.T) {
 		{"not posts prefix", "comments/foo.md", true},
 		{"clean removes prefix", "../posts/hello.md", true}, // filepath.Clean("../posts/hello.md") = "../posts/hello.md"
 		{"encoded dot-dot", "posts/20260101/..%2f..%2fetc/passwd", true}, // Contains ".." substring which is blocked
+		{"windows separators", `posts\20260101\hello.md`, false},
+		{"windows traversal", `posts\..\..\.env`, true},
+		{"absolute path", "/posts/20260101/hello.md", true},
+		{"drive letter", `C:\site\posts\hello.md`, true},
 	}
 
 	for _, tt := range tests {
@@ -3832,6 +4192,9 @@ func TestValidateContentPath_Canonicalization(t *testing.T) {
 		{"null byte", "posts/hello\x00.md", true},
 		{"invalid prefix", "secrets/key.pem", true},
 		{"double dot in component", "posts/..hidden/file.md", true},
+		{"windows drafts path", `.polis\posts\drafts\my-draft.md`, false},
+		{"windows root file", `\index.md`, true},
+		{"drive-relative root file", "C:index.md", true},
 	}
 
 	for _, tt := range tests {
@@ -4449,6 +4812,100 @@ func TestHandleRemotePost_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestHandleRemotePost_ServesContentCache(t *testing.T) {
+	s := newTestServer(t)
+
+	// Unreachable host: only the cache can answer
+	postURL := "https://127.0.0.1:1/posts/offline.md"
+	s.ContentCache().Put(postURL, postURL, "---\ntitle: Offline\n---\n\n# Cached body\n")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/remote/post?url="+postURL, nil)
+	w := httptest.NewRecorder()
+	s.handleRemotePost(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["cached"] != true || !strings.Contains(resp["content"].(string), "Cached body") {
+		t.Errorf("expected cached content, got %v", resp)
+	}
+}
+
+func TestHandleRemotePost_SanitizesContent(t *testing.T) {
+	s := newTestServer(t)
+
+	postURL := "https://127.0.0.1:1/posts/evil.md"
+	s.ContentCache().Put(postURL, postURL, "# Title\n\n<script>alert(1)</script><img src=x onerror=alert(2)>\n\n[link](javascript:alert(3))\n")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/remote/post?url="+postURL, nil)
+	w := httptest.NewRecorder()
+	s.handleRemotePost(w, req)
+
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	content, _ := resp["content"].(string)
+	for _, bad := range []string{"<script", "onerror", "javascript:"} {
+		if strings.Contains(content, bad) {
+			t.Errorf("content contains %q: %s", bad, content)
+		}
+	}
+	if !strings.Contains(content, "<h1>Title</h1>") {
+		t.Errorf("expected rendered heading, got %s", content)
+	}
+}
+
+func TestHandlePrefetchSettings(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/prefetch", jsonBody(t, map[string]interface{}{"prefetch": true, "content_cache_mb": 10}))
+	w := httptest.NewRecorder()
+	s.handlePrefetchSettings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !s.PrefetchEnabled() || s.Config.ContentCacheMB != 10 || s.ContentCache().MaxBytes != 10<<20 {
+		t.Errorf("expected prefetch on with a 10MB cache, got %+v", s.Config)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/settings/prefetch", jsonBody(t, map[string]interface{}{"prefetch": true, "content_cache_mb": -1}))
+	w = httptest.NewRecorder()
+	s.handlePrefetchSettings(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative size, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.handlePrefetchSettings(w, httptest.NewRequest(http.MethodGet, "/api/settings/prefetch", nil))
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["prefetch"] != true || resp["content_cache_mb"] != float64(10) {
+		t.Errorf("unexpected settings: %v", resp)
+	}
+}
+
+func TestHandlePageSettings(t *testing.T) {
+	s := newTestServer(t)
+	defer func(saved render.SpecialPageOptions) { render.SpecialPages = saved }(render.SpecialPages)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/pages", jsonBody(t, map[string]interface{}{"about": false}))
+	w := httptest.NewRecorder()
+	s.handlePageSettings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	want := render.SpecialPageOptions{Archive: true, NotFound: true, About: false}
+	if render.SpecialPages != want {
+		t.Errorf("SpecialPages = %+v, want %+v", render.SpecialPages, want)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(s.DataDir, ".polis", "webapp-config.json"))
+	if !strings.Contains(string(data), `"about": false`) {
+		t.Errorf("expected pages.about saved, got %s", data)
+	}
+}
+
 // ============================================================================
 // stripFrontmatter Tests
 // ============================================================================
@@ -6038,6 +6495,55 @@ func TestHandleCounts_EmptySite(t *testing.T) {
 	}
 }
 
+func TestHandleCounts_ConditionalGet(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/counts", nil)
+	w := httptest.NewRecorder()
+	s.handleCounts(w, req)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/counts", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	w = httptest.NewRecorder()
+	s.handleCounts(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", w.Code)
+	}
+
+	// A new draft changes the counts
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "posts", "drafts", "d.json"), []byte(`{}`), 0644)
+	req = httptest.NewRequest(http.MethodGet, "/api/counts", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	s.handleCounts(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 after counts changed, got %d", w.Code)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"x", W/"abc"`, true},
+		{`*`, true},
+		{`W/"abcd"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `W/"abc"`); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
 // ============================================================================
 // handleSSE Tests
 // ============================================================================
@@ -6179,6 +6685,44 @@ func TestHandleFeedGrouped_PostOnly(t *testing.T) {
 	}
 }
 
+func TestHandleFeedGrouped_VerifiedBadges(t *testing.T) {
+	s := newTestServer(t)
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+	cm.MergeItems([]feed.FeedItem{{
+		Type:         "post",
+		Title:        "Hello World",
+		URL:          "https://alice.polis.pub/posts/hello.md",
+		Published:    time.Now().UTC().Format(time.RFC3339),
+		AuthorURL:    "https://alice.polis.pub",
+		AuthorDomain: "alice.polis.pub",
+	}})
+	c, _ := identity.LoadCache(s.DataDir)
+	c.Record("alice.polis.pub", []identity.Result{
+		{Service: identity.ServiceGitHub, Account: "alice", Verified: true},
+		{Service: identity.ServiceDNS, Account: "alice.polis.pub", Error: "no matching TXT record"},
+	}, nil)
+	if err := identity.SaveCache(s.DataDir, c); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/feed/grouped", nil)
+	w := httptest.NewRecorder()
+	s.handleFeedGrouped(w, req)
+
+	var resp struct {
+		Groups []struct {
+			Verified []identity.Result `json:"verified"`
+		} `json:"groups"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(resp.Groups))
+	}
+	if v := resp.Groups[0].Verified; len(v) != 1 || v[0].Account != "alice" {
+		t.Errorf("expected only the verified github badge, got %+v", v)
+	}
+}
+
 func TestHandleFeedGrouped_CommentsGroupByTarget(t *testing.T) {
 	s := newTestServer(t)
 	discoveryDomain := s.GetDiscoveryDomain()
@@ -6662,3 +7206,2497 @@ func TestWidgetUnfollowMissingAuthor(t *testing.T) {
 		t.Errorf("expected 400 for empty author, got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestWidgetStateMissingParams(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/widget/state", nil)
+	w := httptest.NewRecorder()
+	s.handleWidgetState(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestWidgetState(t *testing.T) {
+	s := newConfiguredServer(t)
+	now := time.Now().UTC()
+	postURL := "https://alice.example.com/posts/hello.md"
+
+	f := &following.FollowingFile{Version: "1", Following: []following.FollowingEntry{
+		{URL: "https://alice.example.com", AddedAt: now.Format(time.RFC3339)},
+	}}
+	if err := following.Save(following.DefaultPath(s.DataDir), f); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := "---\ntitle: Re\npublished: " + now.Format(time.RFC3339) +
+		"\nin-reply-to:\n  url: " + postURL + "\n  root-post: " + postURL + "\n---\n\nHi\n"
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "comments", "pending", "re-hello.md"), []byte(pending), 0644)
+	if err := comment.SaveDraft(s.DataDir, &comment.CommentDraft{ID: "draft-hello", InReplyTo: postURL, Content: "More"}); err != nil {
+		t.Fatal(err)
+	}
+	comment.SaveDraft(s.DataDir, &comment.CommentDraft{ID: "draft-other", InReplyTo: "https://bob.example.com/posts/x.md", Content: "Other"})
+
+	cacheFile := feed.CacheFile(s.DataDir, s.GetDiscoveryDomain())
+	os.MkdirAll(filepath.Dir(cacheFile), 0755)
+	data, _ := json.Marshal(feed.CachedFeedItem{
+		ID: "p1", Type: "post", Title: "Hello", URL: postURL, AuthorURL: "https://alice.example.com",
+		CachedAt: now.Format(time.RFC3339), ReadAt: now.Format(time.RFC3339),
+	})
+	os.WriteFile(cacheFile, append(data, '\n'), 0644)
+
+	// Author defaults to the post's site; .html post URLs are normalized
+	req := httptest.NewRequest(http.MethodGet, "/api/widget/state?post=https://alice.example.com/posts/hello.html", nil)
+	w := httptest.NewRecorder()
+	s.handleWidgetState(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Author    string `json:"author"`
+		Following bool   `json:"following"`
+		Read      bool   `json:"read"`
+		Commented bool   `json:"commented"`
+		Comments  []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"comments"`
+		Drafts []comment.CommentDraft `json:"drafts"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Author != "https://alice.example.com" || !resp.Following || !resp.Read || !resp.Commented {
+		t.Errorf("unexpected state: %s", w.Body.String())
+	}
+	if len(resp.Comments) != 1 || resp.Comments[0].Status != comment.StatusPending {
+		t.Errorf("expected one pending comment, got %+v", resp.Comments)
+	}
+	if len(resp.Drafts) != 1 || resp.Drafts[0].ID != "draft-hello" {
+		t.Errorf("expected only the matching draft, got %+v", resp.Drafts)
+	}
+
+	// An author we don't follow
+	req = httptest.NewRequest(http.MethodGet, "/api/widget/state?author=bob.example.com", nil)
+	w = httptest.NewRecorder()
+	s.handleWidgetState(w, req)
+	var bob map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&bob)
+	if bob["following"] != false || bob["author"] != "https://bob.example.com" {
+		t.Errorf("unexpected state for bob: %v", bob)
+	}
+}
+
+// ============================================================================
+// handlePrivateFeed Tests
+// ============================================================================
+
+func TestHandlePrivateFeed_Lifecycle(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	// Initially disabled
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/private-feed", nil)
+	w := httptest.NewRecorder()
+	s.handlePrivateFeed(w, req)
+
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["enabled"] != false {
+		t.Errorf("expected enabled=false before rotation, got %v", resp["enabled"])
+	}
+	if resp["public_url"] != "https://test-site.polis.pub/feed.xml" {
+		t.Errorf("unexpected public_url: %v", resp["public_url"])
+	}
+
+	// Rotate issues a token
+	req = httptest.NewRequest(http.MethodPost, "/api/settings/private-feed", nil)
+	w = httptest.NewRecorder()
+	s.handlePrivateFeed(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp = nil
+	json.NewDecoder(w.Body).Decode(&resp)
+	url, _ := resp["url"].(string)
+	if !strings.HasPrefix(url, "https://test-site.polis.pub/feeds/") || !strings.HasSuffix(url, ".xml") {
+		t.Errorf("unexpected private feed url: %s", url)
+	}
+
+	// GET now reports the same URL
+	req = httptest.NewRequest(http.MethodGet, "/api/settings/private-feed", nil)
+	w = httptest.NewRecorder()
+	s.handlePrivateFeed(w, req)
+	resp = nil
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["enabled"] != true || resp["url"] != url {
+		t.Errorf("expected enabled with url %s, got %v", url, resp)
+	}
+
+	// DELETE revokes
+	req = httptest.NewRequest(http.MethodDelete, "/api/settings/private-feed", nil)
+	w = httptest.NewRecorder()
+	s.handlePrivateFeed(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if _, err := os.Stat(filepath.Join(s.DataDir, ".polis", "feed-token.json")); !os.IsNotExist(err) {
+		t.Error("expected feed token to be removed")
+	}
+}
+
+func TestHandlePrivateFeed_MethodNotAllowed(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/settings/private-feed", nil)
+	w := httptest.NewRecorder()
+	s.handlePrivateFeed(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Email Notification Tests
+// ============================================================================
+
+func TestHandleEmailSettings_SaveAndGet(t *testing.T) {
+	s := newTestServer(t)
+	s.SMTPPassword = "secret"
+
+	body := `{"enabled":true,"host":"smtp.example.com","port":587,"username":"me","from":"polis@example.com","to":"me@example.com","batch_minutes":5}`
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/email", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.handleEmailSettings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if s.Config.Email == nil || s.Config.Email.Host != "smtp.example.com" {
+		t.Fatalf("expected email config to be saved, got %+v", s.Config.Email)
+	}
+	data, _ := os.ReadFile(filepath.Join(s.DataDir, ".polis", "webapp-config.json"))
+	if strings.Contains(string(data), "secret") {
+		t.Error("SMTP password must not be written to webapp-config.json")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/settings/email", nil)
+	w = httptest.NewRecorder()
+	s.handleEmailSettings(w, req)
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["password_set"] != true {
+		t.Errorf("expected password_set=true, got %v", resp["password_set"])
+	}
+	email, _ := resp["email"].(map[string]interface{})
+	if email["batch_minutes"] != float64(5) {
+		t.Errorf("unexpected email settings: %v", email)
+	}
+}
+
+func TestHandleEmailSettings_RejectsIncompleteConfig(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/email", strings.NewReader(`{"enabled":true,"host":"smtp.example.com"}`))
+	w := httptest.NewRecorder()
+	s.handleEmailSettings(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestEmailSyncHandler_QueuesRequestsForOwnPosts(t *testing.T) {
+	s := newConfiguredServer(t)
+	s.Config.Email = &mailer.Config{Enabled: true, Host: "smtp.example.com", From: "a@example.com", To: "b@example.com"}
+
+	h := &emailSyncHandler{server: s}
+	result := h.Process([]discovery.StreamEvent{
+		{
+			Type:  "polis.blessing.requested",
+			Actor: "bob.polis.pub",
+			Payload: map[string]interface{}{
+				"source_url":    "https://bob.polis.pub/comments/20260101/c1.md",
+				"target_url":    "https://test-site.polis.pub/posts/20260101/hello.md",
+				"target_domain": "test-site.polis.pub",
+			},
+		},
+		{
+			Type:  "polis.blessing.requested",
+			Actor: "bob.polis.pub",
+			Payload: map[string]interface{}{
+				"source_url":    "https://bob.polis.pub/comments/20260101/c2.md",
+				"target_url":    "https://other.polis.pub/posts/20260101/hi.md",
+				"target_domain": "other.polis.pub",
+			},
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.NewItems != 1 {
+		t.Errorf("expected 1 queued item, got %d", result.NewItems)
+	}
+	if got := s.emailNotifier().Pending(); got != 1 {
+		t.Errorf("expected 1 pending email item, got %d", got)
+	}
+}
+
+func TestEmailSyncHandler_DisabledIsNoop(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	h := &emailSyncHandler{server: s}
+	result := h.Process([]discovery.StreamEvent{{
+		Type:    "polis.blessing.requested",
+		Actor:   "bob.polis.pub",
+		Payload: map[string]interface{}{"source_url": "https://bob.polis.pub/c.md", "target_url": "https://test-site.polis.pub/posts/x.md"},
+	}})
+	if result.NewItems != 0 {
+		t.Errorf("expected no items when email disabled, got %d", result.NewItems)
+	}
+	if _, err := os.Stat(filepath.Join(s.DataDir, ".polis", mailer.QueueFilename)); !os.IsNotExist(err) {
+		t.Error("expected no email queue file when disabled")
+	}
+}
+
+// ============================================================================
+// handleDeploy Tests
+// ============================================================================
+
+// recordingTransport is a deploy.Transport that records uploads in memory.
+type recordingTransport struct {
+	uploaded []string
+}
+
+func (r *recordingTransport) Upload(ctx context.Context, root string, files []string, progress func(string)) error {
+	for _, f := range files {
+		r.uploaded = append(r.uploaded, f)
+		progress(f)
+	}
+	return nil
+}
+
+func (r *recordingTransport) Delete(ctx context.Context, files []string, progress func(string)) error {
+	for _, f := range files {
+		progress(f)
+	}
+	return nil
+}
+
+func TestHandleDeploy_UploadsChangedFilesWithProgress(t *testing.T) {
+	s := newTestServer(t)
+	s.sseClients = make(map[chan SSEEvent]struct{})
+	os.WriteFile(filepath.Join(s.DataDir, "index.html"), []byte("<html>"), 0644)
+
+	if err := deploy.SaveConfig(s.DataDir, &deploy.Config{
+		Targets: []deploy.Target{{Name: "prod", Type: deploy.TypeRsync, Host: "example.com", Path: "/srv/www"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tr := &recordingTransport{}
+	s.deployTransport = func(*deploy.Target) (deploy.Transport, error) { return tr, nil }
+
+	events := make(chan SSEEvent, 100)
+	s.addSSEClient(events)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/deploy", strings.NewReader(`{"target":"prod"}`))
+	w := httptest.NewRecorder()
+	s.handleDeploy(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	found := false
+	for _, f := range tr.uploaded {
+		if f == "index.html" {
+			found = true
+		}
+		if strings.HasPrefix(f, ".polis/") {
+			t.Errorf("private file deployed: %s", f)
+		}
+	}
+	if !found {
+		t.Errorf("expected index.html to be uploaded, got %v", tr.uploaded)
+	}
+
+	progress, complete := 0, 0
+	for len(events) > 0 {
+		switch (<-events).Event {
+		case "deploy-progress":
+			progress++
+		case "deploy-complete":
+			complete++
+		}
+	}
+	if progress != len(tr.uploaded) || complete != 1 {
+		t.Errorf("expected %d progress + 1 complete events, got %d + %d", len(tr.uploaded), progress, complete)
+	}
+
+	// Second deploy has nothing to upload
+	tr.uploaded = nil
+	req = httptest.NewRequest(http.MethodPost, "/api/deploy", nil)
+	w = httptest.NewRecorder()
+	s.handleDeploy(w, req)
+	if w.Code != http.StatusOK || len(tr.uploaded) != 0 {
+		t.Errorf("expected no-op redeploy, got %d uploads (status %d)", len(tr.uploaded), w.Code)
+	}
+
+	// GET reports last deploy time
+	req = httptest.NewRequest(http.MethodGet, "/api/deploy", nil)
+	w = httptest.NewRecorder()
+	s.handleDeploy(w, req)
+	var resp struct {
+		Targets []map[string]interface{} `json:"targets"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Targets) != 1 || resp.Targets[0]["deployed_at"] == nil {
+		t.Errorf("expected target with deployed_at, got %v", resp.Targets)
+	}
+}
+
+func TestHandleDeploy_NoTargets(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/deploy", nil)
+	w := httptest.NewRecorder()
+	s.handleDeploy(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without targets, got %d", w.Code)
+	}
+}
+
+func TestHandleDeploy_RejectsConcurrentDeploy(t *testing.T) {
+	s := newTestServer(t)
+	deploy.SaveConfig(s.DataDir, &deploy.Config{
+		Targets: []deploy.Target{{Name: "prod", Type: deploy.TypeS3, Bucket: "site"}},
+	})
+	s.deployTransport = func(*deploy.Target) (deploy.Transport, error) { return &recordingTransport{}, nil }
+
+	s.deployMu.Lock()
+	defer s.deployMu.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/deploy", nil)
+	w := httptest.NewRecorder()
+	s.handleDeploy(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 during running deploy, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// handleDeployStatus Tests
+// ============================================================================
+
+func TestHandleDeployStatus_ReportsPendingChanges(t *testing.T) {
+	s := newTestServer(t)
+	os.WriteFile(filepath.Join(s.DataDir, "index.html"), []byte("v1"), 0644)
+	deploy.SaveConfig(s.DataDir, &deploy.Config{
+		Targets: []deploy.Target{{Name: "prod", Type: deploy.TypeS3, Bucket: "site"}},
+	})
+	target := &deploy.Target{Name: "prod", Type: deploy.TypeS3, Bucket: "site"}
+	if _, err := deploy.Run(context.Background(), s.DataDir, target, &recordingTransport{}, deploy.Options{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(s.DataDir, "index.html"), []byte("v2"), 0644)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/site/deploy-status?live=0", nil)
+	w := httptest.NewRecorder()
+	s.handleDeployStatus(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var st deploy.Status
+	json.NewDecoder(w.Body).Decode(&st)
+	if st.Target != "prod" || st.UpToDate || st.PendingCount != 1 {
+		t.Errorf("expected 1 pending change on prod, got %+v", st)
+	}
+	if st.Live {
+		t.Error("expected live check to be skipped with live=0")
+	}
+}
+
+func TestHandleDeployStatus_UnknownTarget(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/site/deploy-status?target=nope", nil)
+	w := httptest.NewRecorder()
+	s.handleDeployStatus(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Remote Media Tests
+// ============================================================================
+
+func TestHandleRemoteMediaSettings_SaveNormalizes(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/remote-media", strings.NewReader(`{"images":"proxy","embeds":"proxy"}`))
+	w := httptest.NewRecorder()
+	s.handleRemoteMediaSettings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	policy := s.RemoteMediaPolicy()
+	if policy.Images != remote.MediaProxy {
+		t.Errorf("expected images=proxy, got %s", policy.Images)
+	}
+	if policy.Embeds != remote.MediaClick {
+		t.Errorf("expected embeds proxy to fall back to click, got %s", policy.Embeds)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/settings/remote-media", nil)
+	w = httptest.NewRecorder()
+	s.handleRemoteMediaSettings(w, req)
+	var got remote.MediaPolicy
+	json.NewDecoder(w.Body).Decode(&got)
+	if got != policy {
+		t.Errorf("GET returned %+v, want %+v", got, policy)
+	}
+}
+
+func TestRemoteMediaPolicy_DefaultsToLoad(t *testing.T) {
+	s := newTestServer(t)
+	if p := s.RemoteMediaPolicy(); p.Images != remote.MediaLoad || p.Embeds != remote.MediaLoad {
+		t.Errorf("expected load defaults, got %+v", p)
+	}
+}
+
+func TestHandleRemoteImage(t *testing.T) {
+	s := newTestServer(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cat.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<script>alert(1)</script>"))
+	}))
+	defer upstream.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/remote/image?url="+upstream.URL+"/cat.png", nil)
+	w := httptest.NewRecorder()
+	s.handleRemoteImage(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("expected proxied PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/remote/image?url="+upstream.URL+"/page.html", nil)
+	w = httptest.NewRecorder()
+	s.handleRemoteImage(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for non-image, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/remote/image?url=file:///etc/passwd", nil)
+	w = httptest.NewRecorder()
+	s.handleRemoteImage(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for non-http URL, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/remote/image?url="+upstream.URL+"/cat.png&w=abc", nil)
+	w = httptest.NewRecorder()
+	s.handleRemoteImage(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid width, got %d", w.Code)
+	}
+}
+
+func TestHandleRemoteImage_CachesOnDisk(t *testing.T) {
+	s := newTestServer(t)
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("GIF89a"))
+	}))
+	defer upstream.Close()
+
+	for i, want := range []string{"MISS", "HIT"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/remote/image?url="+upstream.URL+"/a.gif", nil)
+		w := httptest.NewRecorder()
+		s.handleRemoteImage(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, w.Code)
+		}
+		if got := w.Header().Get("X-Cache"); got != want {
+			t.Errorf("request %d: expected X-Cache %s, got %s", i, want, got)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected one upstream fetch, got %d", hits)
+	}
+	if _, err := os.Stat(remote.ImageCacheDir(s.DataDir)); err != nil {
+		t.Errorf("expected cache directory to exist: %v", err)
+	}
+}
+
+// ============================================================================
+// Feed triage (next-unread) tests
+// ============================================================================
+
+func TestHandleFeedNextUnread(t *testing.T) {
+	s := newTestServer(t)
+
+	now := time.Now().UTC()
+	cm := feed.NewCacheManager(s.DataDir, "default")
+	cm.MergeItems([]feed.FeedItem{
+		{Type: "post", Title: "Older", URL: "posts/a.md", Published: now.Add(-2 * time.Hour).Format(time.RFC3339), AuthorURL: "https://a.pub", AuthorDomain: "a.pub"},
+		{Type: "post", Title: "Newer", URL: "posts/b.md", Published: now.Add(-1 * time.Hour).Format(time.RFC3339), AuthorURL: "https://a.pub", AuthorDomain: "a.pub"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/feed/next-unread", nil)
+	w := httptest.NewRecorder()
+	s.handleFeedNextUnread(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Item      *feed.CachedFeedItem `json:"item"`
+		Remaining int                  `json:"remaining"`
+		Marked    string               `json:"marked"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Item == nil || resp.Item.Title != "Newer" || resp.Remaining != 2 {
+		t.Fatalf("expected Newer with 2 remaining, got %+v", resp)
+	}
+
+	// Mark it read and advance in one call
+	req = httptest.NewRequest(http.MethodPost, "/api/feed/next-unread", jsonBody(t, map[string]string{"id": resp.Item.ID}))
+	w = httptest.NewRecorder()
+	s.handleFeedNextUnread(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	marked := resp.Item.ID
+	resp.Item = nil
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Marked != marked || resp.Item == nil || resp.Item.Title != "Older" || resp.Remaining != 1 {
+		t.Errorf("expected Older with 1 remaining after marking, got %+v", resp)
+	}
+}
+
+func TestHandleFeedNextUnread_Errors(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/feed/next-unread", jsonBody(t, map[string]string{"id": "nope"}))
+	w := httptest.NewRecorder()
+	s.handleFeedNextUnread(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown item, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/feed/next-unread", jsonBody(t, map[string]string{}))
+	w = httptest.NewRecorder()
+	s.handleFeedNextUnread(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing id, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/feed/next-unread", nil)
+	w = httptest.NewRecorder()
+	s.handleFeedNextUnread(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Frontmatter editing tests
+// ============================================================================
+
+func TestHandlePostFrontmatter_GetAndPatch(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	result, err := publish.PublishPost(s.DataDir, "# Hello\n\nBody text.\n", "", s.PrivateKey)
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	url := "/api/posts/" + result.Path + "/frontmatter"
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+	s.handlePost(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var fm publish.PostFrontmatter
+	json.NewDecoder(w.Body).Decode(&fm)
+	if fm.Title != "Hello" || fm.Visibility != publish.VisibilityPublic || !fm.Signed {
+		t.Errorf("unexpected frontmatter: %+v", fm)
+	}
+
+	body := jsonBody(t, map[string]interface{}{"title": "Renamed", "tags": []string{"x", "y"}})
+	req = httptest.NewRequest(http.MethodPatch, url, body)
+	w = httptest.NewRecorder()
+	s.handlePost(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.NewDecoder(w.Body).Decode(&fm)
+	if fm.Title != "Renamed" || len(fm.Tags) != 2 || fm.CurrentVersion != result.Version {
+		t.Errorf("patch not applied as expected: %+v", fm)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(s.DataDir, result.Path))
+	if !strings.Contains(string(content), "Body text.") {
+		t.Error("body should be unchanged")
+	}
+}
+
+func TestHandlePostFrontmatter_Errors(t *testing.T) {
+	s := newConfiguredServer(t)
+	result, err := publish.PublishPost(s.DataDir, "# Hello\n\nBody.\n", "", s.PrivateKey)
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   interface{}
+		want   int
+	}{
+		{"invalid visibility", http.MethodPatch, "/api/posts/" + result.Path + "/frontmatter", map[string]string{"visibility": "secret"}, http.StatusBadRequest},
+		{"missing post", http.MethodGet, "/api/posts/posts/20260101/nope.md/frontmatter", nil, http.StatusNotFound},
+		{"traversal", http.MethodGet, "/api/posts/../.env/frontmatter", nil, http.StatusBadRequest},
+		{"bad method", http.MethodPost, "/api/posts/" + result.Path + "/frontmatter", nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		var req *http.Request
+		if tt.body != nil {
+			req = httptest.NewRequest(tt.method, tt.url, jsonBody(t, tt.body))
+		} else {
+			req = httptest.NewRequest(tt.method, tt.url, nil)
+		}
+		w := httptest.NewRecorder()
+		s.handlePost(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+// ============================================================================
+// Network domain dossier tests
+// ============================================================================
+
+func TestHandleNetworkDomain_InvalidDomain(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	for _, path := range []string{"/api/network/domain/", "/api/network/domain/a.com/x", "/api/network/domain/a.com:8080"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		s.handleNetworkDomain(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rr.Code)
+		}
+	}
+}
+
+func TestHandleNetworkDomain_MethodNotAllowed(t *testing.T) {
+	s := newConfiguredServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/network/domain/alice.example.com", nil)
+	rr := httptest.NewRecorder()
+	s.handleNetworkDomain(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleNetworkDomain_Unknown(t *testing.T) {
+	s := newConfiguredServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/network/domain/nobody.example.com", nil)
+	rr := httptest.NewRecorder()
+	s.handleNetworkDomain(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp NetworkDomainResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp.Follow.Following || resp.Follow.FollowedBy || resp.TrustScore != 0 || resp.LastActivity != "" {
+		t.Errorf("expected empty dossier, got %+v", resp)
+	}
+	if resp.TheirComments == nil || resp.MyComments == nil || resp.BlessingHistory == nil {
+		t.Error("expected empty arrays, not null")
+	}
+}
+
+func TestHandleNetworkDomain_WithData(t *testing.T) {
+	s := newConfiguredServer(t)
+	now := time.Now().UTC()
+	discoveryDomain := s.GetDiscoveryDomain()
+
+	// We follow alice, and alice follows us
+	f := &following.FollowingFile{Version: "1", Following: []following.FollowingEntry{
+		{URL: "https://alice.example.com", AddedAt: now.Add(-48 * time.Hour).Format(time.RFC3339), SiteTitle: "Alice"},
+	}}
+	if err := following.Save(following.DefaultPath(s.DataDir), f); err != nil {
+		t.Fatal(err)
+	}
+	store := stream.NewStore(s.DataDir, discoveryDomain)
+	store.SaveState("polis.follow", stream.FollowerState{Followers: []string{"alice.example.com"}, Count: 1})
+
+	// One of alice's comments blessed, one denied, plus one from bob
+	store.SaveState("polis.blessing", stream.BlessingState{Blessings: []stream.BlessingEntry{
+		{SourceURL: "https://alice.example.com/comments/a.md", TargetURL: "https://test-site.polis.pub/posts/one.md", Status: "granted", Actor: "alice.example.com", UpdatedAt: now.Add(-3 * time.Hour).Format(time.RFC3339)},
+		{SourceURL: "https://alice.example.com/comments/b.md", TargetURL: "https://test-site.polis.pub/posts/one.md", Status: "denied", Actor: "alice.example.com", UpdatedAt: now.Add(-2 * time.Hour).Format(time.RFC3339)},
+		{SourceURL: "https://bob.example.com/comments/c.md", TargetURL: "https://test-site.polis.pub/posts/one.md", Status: "granted", Actor: "bob.example.com", UpdatedAt: now.Format(time.RFC3339)},
+	}})
+
+	// A pending comment of ours on alice's post
+	pending := "---\ntitle: Re\npublished: " + now.Add(-time.Hour).Format(time.RFC3339) +
+		"\nin-reply-to:\n  url: https://alice.example.com/posts/hello.md\n  root-post: https://alice.example.com/posts/hello.md\n---\n\nHi\n"
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "comments", "pending", "re-hello.md"), []byte(pending), 0644)
+
+	// A feed post from alice, the most recent activity
+	latest := now.Add(-30 * time.Minute).Format(time.RFC3339)
+	cacheFile := feed.CacheFile(s.DataDir, discoveryDomain)
+	os.MkdirAll(filepath.Dir(cacheFile), 0755)
+	data, _ := json.Marshal(feed.CachedFeedItem{
+		ID: "p1", Type: "post", Title: "Hello", URL: "https://alice.example.com/posts/hello.md",
+		Published: latest, AuthorURL: "https://alice.example.com", AuthorDomain: "alice.example.com",
+		CachedAt: now.Format(time.RFC3339),
+	})
+	os.WriteFile(cacheFile, append(data, '\n'), 0644)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/network/domain/Alice.Example.com", nil)
+	rr := httptest.NewRecorder()
+	s.handleNetworkDomain(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp NetworkDomainResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if resp.Domain != "alice.example.com" {
+		t.Errorf("expected normalized domain, got %q", resp.Domain)
+	}
+	if !resp.Follow.Following || !resp.Follow.FollowedBy || resp.Follow.SiteTitle != "Alice" {
+		t.Errorf("unexpected follow status: %+v", resp.Follow)
+	}
+	if len(resp.TheirComments) != 2 {
+		t.Errorf("expected 2 comments from alice, got %d", len(resp.TheirComments))
+	}
+	if len(resp.MyComments) != 1 || resp.MyComments[0].Status != "pending" {
+		t.Errorf("expected 1 pending comment of ours, got %+v", resp.MyComments)
+	}
+	if len(resp.BlessingHistory) != 2 || resp.BlessingHistory[0].Status != "denied" {
+		t.Errorf("expected 2 blessing decisions, newest denied first, got %+v", resp.BlessingHistory)
+	}
+	// +2 granted, -3 denied, +1 following, +1 followed by
+	if resp.TrustScore != 1 {
+		t.Errorf("expected trust score 1, got %d", resp.TrustScore)
+	}
+	if resp.Feed.Posts != 1 || resp.Feed.Unread != 1 {
+		t.Errorf("unexpected feed counts: %+v", resp.Feed)
+	}
+	if resp.LastActivity != latest {
+		t.Errorf("expected last activity %s, got %s", latest, resp.LastActivity)
+	}
+}
+
+func TestHandleAuthor_Profile(t *testing.T) {
+	s := newConfiguredServer(t)
+	now := time.Now().UTC()
+	discoveryDomain := s.GetDiscoveryDomain()
+
+	wk := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/polis" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"version":"0.55.0","author":"Alice A.","public_key":"ssh-ed25519 AAAA alice","site_title":"Alice Writes","created":"2025-01-01T00:00:00Z"}`))
+	}))
+	defer wk.Close()
+	domain := discovery.ExtractDomainFromURL(wk.URL)
+
+	f := &following.FollowingFile{Version: "1", Following: []following.FollowingEntry{
+		{URL: wk.URL, AddedAt: now.Format(time.RFC3339), SiteTitle: "Alice (old title)"},
+	}}
+	if err := following.Save(following.DefaultPath(s.DataDir), f); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheFile := feed.CacheFile(s.DataDir, discoveryDomain)
+	os.MkdirAll(filepath.Dir(cacheFile), 0755)
+	var lines []byte
+	for i, title := range []string{"Older", "Newer"} {
+		data, _ := json.Marshal(feed.CachedFeedItem{
+			ID: "p" + title, Type: "post", Title: title, URL: wk.URL + "/posts/" + title + ".md",
+			Published: now.Add(time.Duration(i) * time.Hour).Format(time.RFC3339), AuthorURL: wk.URL, AuthorDomain: domain,
+			CachedAt: now.Format(time.RFC3339),
+		})
+		lines = append(append(lines, data...), '\n')
+	}
+	os.WriteFile(cacheFile, lines, 0644)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/authors/"+domain, nil)
+	rr := httptest.NewRecorder()
+	s.handleAuthor(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp["site_title"] != "Alice Writes" || resp["author"] != "Alice A." || resp["public_key"] != "ssh-ed25519 AAAA alice" {
+		t.Errorf("expected identity from .well-known/polis, got %v", resp)
+	}
+	if follow, _ := resp["follow"].(map[string]interface{}); follow["following"] != true {
+		t.Errorf("expected following, got %v", resp["follow"])
+	}
+	items, _ := resp["items"].([]interface{})
+	if len(items) != 2 || items[0].(map[string]interface{})["title"] != "Newer" {
+		t.Errorf("expected 2 items newest first, got %v", items)
+	}
+	if _, ok := resp["blessing_history"]; !ok {
+		t.Error("expected interaction history in profile")
+	}
+
+	// Offline: identity falls back to the following entry
+	req = httptest.NewRequest(http.MethodGet, "/api/authors/"+domain+"?offline=true&limit=1", nil)
+	rr = httptest.NewRecorder()
+	s.handleAuthor(rr, req)
+	resp = nil
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp["site_title"] != "Alice (old title)" || resp["public_key"] != nil {
+		t.Errorf("expected following-entry identity offline, got %v", resp)
+	}
+	if items, _ := resp["items"].([]interface{}); len(items) != 1 {
+		t.Errorf("expected limit=1 to return 1 item, got %d", len(items))
+	}
+}
+
+func TestHandleAuthor_InvalidDomain(t *testing.T) {
+	s := newConfiguredServer(t)
+	for _, path := range []string{"/api/authors/", "/api/authors/a.com/posts", "/api/authors/https:"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		s.handleAuthor(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rr.Code)
+		}
+	}
+}
+
+// ============================================================================
+// Thread subscription tests
+// ============================================================================
+
+type stubThreadFetcher struct {
+	index *metadata.BlessedComments
+}
+
+func (f *stubThreadFetcher) FetchBlessedComments(baseURL string) (*metadata.BlessedComments, error) {
+	return f.index, nil
+}
+
+func TestHandleThreads_SubscribeAndMute(t *testing.T) {
+	s := newConfiguredServer(t)
+	post := "https://alice.example.com/posts/hello.md"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/threads", jsonBody(t, map[string]string{"post_url": post}))
+	rr := httptest.NewRecorder()
+	s.handleThreads(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("subscribe: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/threads", nil)
+	rr = httptest.NewRecorder()
+	s.handleThreads(rr, req)
+	var resp struct {
+		Threads []thread.Subscription `json:"threads"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Threads) != 1 || resp.Threads[0].PostURL != post {
+		t.Fatalf("expected watched thread, got %+v", resp.Threads)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/threads", jsonBody(t, map[string]string{"post_url": post}))
+	rr = httptest.NewRecorder()
+	s.handleThreads(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("mute: expected 200, got %d", rr.Code)
+	}
+
+	f, _ := thread.Load(thread.DefaultPath(s.DataDir))
+	if len(f.Active()) != 0 || len(f.Threads) != 1 {
+		t.Errorf("expected thread kept but muted, got %+v", f.Threads)
+	}
+}
+
+func TestHandleThreads_RejectsNonHTTPS(t *testing.T) {
+	s := newConfiguredServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/threads", jsonBody(t, map[string]string{"post_url": "http://alice.example.com/posts/a.md"}))
+	rr := httptest.NewRecorder()
+	s.handleThreads(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCheckThreadReplies_NotifiesAndSurfacesInConversations(t *testing.T) {
+	s := newConfiguredServer(t)
+	post := "https://alice.example.com/posts/hello.md"
+	index := &metadata.BlessedComments{Comments: []metadata.PostComments{
+		{Post: "posts/hello.md", Blessed: []metadata.BlessedComment{{URL: "https://test-site.polis.pub/comments/mine.md"}}},
+	}}
+	s.threadFetcher = &stubThreadFetcher{index: index}
+
+	// Our pending comment on alice's post subscribes us automatically
+	pending := "---\ntitle: Re\npublished: 2026-01-01T00:00:00Z\nin-reply-to:\n  url: " + post +
+		"\n  root-post: " + post + "\n---\n\nHi\n"
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "comments", "pending", "re.md"), []byte(pending), 0644)
+
+	if n := s.checkThreadReplies(); n != 0 {
+		t.Fatalf("expected first check to only seed, got %d replies", n)
+	}
+
+	index.Comments[0].Blessed = append(index.Comments[0].Blessed,
+		metadata.BlessedComment{URL: "https://bob.example.com/comments/reply.md"})
+	if n := s.checkThreadReplies(); n != 1 {
+		t.Fatalf("expected 1 new reply, got %d", n)
+	}
+
+	mgr := notification.NewManager(s.DataDir, s.GetDiscoveryDomain())
+	entries, _ := mgr.List()
+	if len(entries) != 1 || entries[0].RuleID != "thread-reply" || entries[0].Actor != "bob.example.com" {
+		t.Errorf("expected thread-reply notification from bob, got %+v", entries)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/conversations", nil)
+	rr := httptest.NewRecorder()
+	s.handleConversations(rr, req)
+	var resp ConversationsResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.RepliesNearYou) != 1 || resp.RepliesNearYou[0].CommentURL != "https://bob.example.com/comments/reply.md" {
+		t.Errorf("expected reply in conversations, got %+v", resp.RepliesNearYou)
+	}
+}
+
+// ============================================================================
+// Reconcile tests
+// ============================================================================
+
+func TestHandleReconcile_NoReport(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/reconcile", nil)
+	rr := httptest.NewRecorder()
+	s.handleReconcile(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp["report"] != nil {
+		t.Errorf("expected null report, got %v", resp["report"])
+	}
+}
+
+func TestHandleReconcile_LastReport(t *testing.T) {
+	s := newTestServer(t)
+	reconcile.SaveReport(s.DataDir, &reconcile.Report{
+		CheckedAt: "2026-01-01T00:00:00Z",
+		Divergences: []reconcile.Divergence{
+			{Kind: reconcile.KindFollowNotAnnounced, URL: "https://alice.example.com", Local: "following", Remote: "missing", Repairable: true},
+		},
+		Errors: []string{},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/reconcile", nil)
+	rr := httptest.NewRecorder()
+	s.handleReconcile(rr, req)
+
+	var resp struct {
+		Report *reconcile.Report `json:"report"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Report == nil || len(resp.Report.Divergences) != 1 || resp.Report.Divergences[0].URL != "https://alice.example.com" {
+		t.Errorf("unexpected report: %+v", resp.Report)
+	}
+}
+
+func TestHandleReconcile_RequiresDiscovery(t *testing.T) {
+	s := newConfiguredServer(t)
+	s.DiscoveryURL = ""
+
+	req := httptest.NewRequest(http.MethodPost, "/api/reconcile", nil)
+	rr := httptest.NewRecorder()
+	s.handleReconcile(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without discovery config, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/reconcile", nil)
+	rr = httptest.NewRecorder()
+	s.handleReconcile(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rr.Code)
+	}
+}
+
+// ============================================================================
+// Sync conflict tests
+// ============================================================================
+
+// liveFiles serves a live site from memory.
+type liveFiles map[string]string
+
+func (f liveFiles) FetchContent(url string) (string, error) {
+	if c, ok := f[url]; ok {
+		return c, nil
+	}
+	return "", errors.New("404")
+}
+
+func TestHandleConflicts_CheckValidateResolve(t *testing.T) {
+	s := newConfiguredServer(t)
+	result, err := publish.PublishPost(s.DataDir, "# Hello\n\nWritten here.\n", "hello", s.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteVersion := "sha256:" + strings.Repeat("e", 64)
+	s.conflictFetcher = liveFiles{
+		"https://test-site.polis.pub/metadata/public.jsonl": `{"type":"post","path":"` + result.Path + `","title":"Hello","current_version":"` + remoteVersion + `"}` + "\n",
+		"https://test-site.polis.pub/" + result.Path:        "---\ntitle: Hello\ncurrent-version: " + remoteVersion + "\nversion-history:\n  - " + remoteVersion + " (2026-01-02T00:00:00Z)\n---\n\n# Hello\n\nWritten elsewhere.\n",
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleConflicts(rr, httptest.NewRequest(http.MethodPost, "/api/conflicts", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("check: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleValidate(rr, httptest.NewRequest(http.MethodGet, "/api/validate", nil))
+	var validate struct {
+		Status    string           `json:"status"`
+		Conflicts *conflict.Report `json:"conflicts"`
+	}
+	json.NewDecoder(rr.Body).Decode(&validate)
+	if validate.Status != "valid" || validate.Conflicts == nil || len(validate.Conflicts.Conflicts) != 1 || validate.Conflicts.Conflicts[0].Kind != conflict.KindDiverged {
+		t.Fatalf("unexpected validate response: %+v", validate)
+	}
+
+	body := `{"path":"` + result.Path + `","resolution":"overwrite"}`
+	rr = httptest.NewRecorder()
+	s.handleConflictResolve(rr, httptest.NewRequest(http.MethodPost, "/api/conflicts/resolve", strings.NewReader(body)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown resolution: expected 400, got %d", rr.Code)
+	}
+
+	body = `{"path":"` + result.Path + `","resolution":"keep"}`
+	rr = httptest.NewRecorder()
+	s.handleConflictResolve(rr, httptest.NewRequest(http.MethodPost, "/api/conflicts/resolve", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("keep: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	report, _ := conflict.LoadReport(s.DataDir)
+	if report == nil || len(report.Conflicts) != 0 {
+		t.Errorf("conflict still reported after keep: %+v", report)
+	}
+	if report, _ := s.checkSyncConflicts(); report == nil || len(report.Conflicts) != 0 {
+		t.Errorf("kept conflict reported again: %+v", report)
+	}
+}
+
+// ============================================================================
+// Logs tests
+// ============================================================================
+
+func TestHandleLogs_Disabled(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+	rr := httptest.NewRecorder()
+	s.handleLogs(rr, req)
+
+	var resp struct {
+		Enabled bool            `json:"enabled"`
+		Entries []logging.Entry `json:"entries"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if rr.Code != http.StatusOK || resp.Enabled || resp.Entries == nil || len(resp.Entries) != 0 {
+		t.Errorf("expected enabled=false with empty entries, got %d %+v", rr.Code, resp)
+	}
+}
+
+func TestHandleLogs_LevelFilter(t *testing.T) {
+	prev := logging.Default()
+	t.Cleanup(func() { logging.SetDefault(prev) })
+
+	s := newTestServer(t)
+	s.LogLevel = "debug"
+	s.initLogger()
+	t.Cleanup(s.Close)
+
+	s.LogDebug("checking %s", "alice.polis.pub")
+	s.LogWarn("render failed: %v", "boom")
+	logging.Warn("from a package", "path", "posts/x.md")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?level=warn&since=1h", nil)
+	rr := httptest.NewRecorder()
+	s.handleLogs(rr, req)
+
+	var resp struct {
+		Enabled bool            `json:"enabled"`
+		Entries []logging.Entry `json:"entries"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if !resp.Enabled {
+		t.Fatal("expected file logging to be enabled")
+	}
+	if len(resp.Entries) != 2 || resp.Entries[0].Msg != "render failed: boom" || resp.Entries[1].Attrs["path"] != "posts/x.md" {
+		t.Errorf("unexpected entries: %+v", resp.Entries)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs?level=loud", nil)
+	rr = httptest.NewRecorder()
+	s.handleLogs(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown level, got %d", rr.Code)
+	}
+}
+
+// ============================================================================
+// Metrics tests
+// ============================================================================
+
+func TestWithMetrics_CountsByRoute(t *testing.T) {
+	s := newTestServer(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/posts/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "missing") {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/api/boom", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/api/metrics", s.handleMetrics)
+	h := s.WithMetrics(mux)
+
+	for _, path := range []string{"/api/posts/a", "/api/posts/b", "/api/posts/missing", "/api/boom"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/metrics", nil))
+	body := rr.Body.String()
+	for _, want := range []string{
+		`polis_http_requests_total{route="/api/posts/",method="GET",code="2xx"} 2`,
+		`polis_http_requests_total{route="/api/posts/",method="GET",code="4xx"} 1`,
+		`polis_http_requests_total{route="/api/boom",method="GET",code="5xx"} 1`,
+		`polis_http_request_duration_seconds_count{route="/api/posts/",method="GET"} 3`,
+		`# TYPE polis_http_request_duration_seconds histogram`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestHandleDebugStats(t *testing.T) {
+	s := newTestServer(t)
+	s.metrics().Observe("/api/drafts", http.MethodGet, http.StatusOK, 3*time.Millisecond)
+	s.metrics().Observe("/api/drafts", http.MethodGet, http.StatusInternalServerError, 40*time.Millisecond)
+	s.metrics().Observe("/api/status", http.MethodGet, http.StatusOK, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	s.handleDebugStats(rr, httptest.NewRequest(http.MethodGet, "/api/debug/stats", nil))
+
+	var resp struct {
+		Requests  uint64         `json:"requests"`
+		Errors    uint64         `json:"errors"`
+		ErrorRate float64        `json:"error_rate"`
+		Routes    []RouteSummary `json:"routes"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Requests != 3 || resp.Errors != 1 {
+		t.Errorf("requests=%d errors=%d, want 3 and 1", resp.Requests, resp.Errors)
+	}
+	if len(resp.Routes) != 2 || resp.Routes[0].Route != "/api/drafts" {
+		t.Fatalf("unexpected routes: %+v", resp.Routes)
+	}
+	d := resp.Routes[0]
+	if d.ErrorRate != 0.5 || d.P95Ms != 50 || d.MaxMs < 40 {
+		t.Errorf("unexpected /api/drafts summary: %+v", d)
+	}
+}
+
+// ============================================================================
+// Health and Shutdown Tests
+// ============================================================================
+
+func TestHandleReadyz(t *testing.T) {
+	s := newTestServer(t)
+
+	check := func(wantCode int, wantStatus string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		s.handleReadyz(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp map[string]string
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if rr.Code != wantCode || resp["status"] != wantStatus {
+			t.Errorf("readyz = %d %q, want %d %q", rr.Code, resp["status"], wantCode, wantStatus)
+		}
+	}
+
+	check(http.StatusServiceUnavailable, "starting")
+	s.ready.Store(true)
+	check(http.StatusOK, "ready")
+	s.beginShutdown()
+	check(http.StatusServiceUnavailable, "shutting_down")
+
+	// Liveness stays green while shutting down
+	rr := httptest.NewRecorder()
+	s.handleHealthz(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("healthz = %d, want 200", rr.Code)
+	}
+}
+
+func TestShutdown_ClosesSSEAndStopsSync(t *testing.T) {
+	s := newTestServer(t)
+	s.sseClients = make(map[chan SSEEvent]struct{})
+	s.syncStop = make(chan struct{})
+	s.syncDone = make(chan struct{})
+	go func() {
+		<-s.syncStop
+		close(s.syncDone)
+	}()
+
+	ch := make(chan SSEEvent, 1)
+	s.addSSEClient(ch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Error("SSE client channel should be closed")
+	}
+	// The SSE handler's deferred removal must not double-close
+	s.removeSSEClient(ch)
+	// A second Shutdown is a no-op
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown: %v", err)
+	}
+}
+
+// ============================================================================
+// Daemon Tests
+// ============================================================================
+
+func TestPublishScheduledDrafts(t *testing.T) {
+	s := newConfiguredServer(t)
+	draftsDir := filepath.Join(s.DataDir, ".polis", "posts", "drafts")
+	os.WriteFile(filepath.Join(draftsDir, "due.md"), []byte("---\npublish_at: 2020-01-01T00:00:00Z\n---\n# Scheduled Post\n\nHello.\n"), 0644)
+	os.WriteFile(filepath.Join(draftsDir, "future.md"), []byte("---\npublish_at: 2999-01-01T00:00:00Z\n---\n# Future Post\n"), 0644)
+
+	s.publishScheduledDrafts()
+
+	if _, err := os.Stat(filepath.Join(draftsDir, "due.md")); !os.IsNotExist(err) {
+		t.Error("due draft should be removed after publishing")
+	}
+	if _, err := os.Stat(filepath.Join(draftsDir, "future.md")); err != nil {
+		t.Error("future draft should be left alone")
+	}
+	index, _ := os.ReadFile(filepath.Join(s.DataDir, "metadata", "public.jsonl"))
+	if !strings.Contains(string(index), "Scheduled Post") {
+		t.Errorf("scheduled post missing from public.jsonl:\n%s", index)
+	}
+
+	st := (&daemonController{server: s}).Status()
+	if st.ScheduledPublished != 1 {
+		t.Errorf("ScheduledPublished = %d, want 1", st.ScheduledPublished)
+	}
+}
+
+// ============================================================================
+// Scheduled Automation Tests
+// ============================================================================
+
+func TestHandleAutomations_CreateScheduled(t *testing.T) {
+	s := newTestServer(t)
+
+	body := jsonBody(t, map[string]interface{}{
+		"id":       "nightly-backup",
+		"schedule": "@nightly",
+		"action":   "backup",
+		"keep":     3,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/automations", body)
+	rr := httptest.NewRecorder()
+	s.handleAutomations(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(s.Config.Schedules) != 1 || s.Config.Schedules[0].Keep != 3 {
+		t.Fatalf("schedules = %+v", s.Config.Schedules)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleAutomations(rr, httptest.NewRequest(http.MethodGet, "/api/automations", nil))
+	var resp struct {
+		Automations []Automation `json:"automations"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Automations) != 1 || resp.Automations[0].Schedule != "@nightly" || resp.Automations[0].Action != "backup" {
+		t.Errorf("automations = %+v", resp.Automations)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleAutomation(rr, httptest.NewRequest(http.MethodDelete, "/api/automations/nightly-backup", nil))
+	if rr.Code != http.StatusOK || len(s.Config.Schedules) != 0 {
+		t.Errorf("delete: status %d, schedules %+v", rr.Code, s.Config.Schedules)
+	}
+}
+
+func TestHandleAutomations_CreateScheduledInvalid(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, req := range []map[string]interface{}{
+		{"schedule": "every tuesday", "action": "sync"},
+		{"schedule": "@daily", "action": "reboot"},
+		{"schedule": "@daily", "action": "draft"},
+		{"id": "post-publish", "schedule": "@daily", "action": "sync"},
+	} {
+		rr := httptest.NewRecorder()
+		s.handleAutomations(rr, httptest.NewRequest(http.MethodPost, "/api/automations", jsonBody(t, req)))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400, got %d", req, rr.Code)
+		}
+	}
+}
+
+func TestRunScheduledJobs(t *testing.T) {
+	s := newTestServer(t)
+	os.WriteFile(filepath.Join(s.DataDir, "digest.md"), []byte("# Weekly digest {{date}}\n"), 0644)
+	s.Config = &Config{Schedules: []schedule.Job{
+		{ID: "digest", Schedule: "@weekly", Action: schedule.ActionDraft, Template: "digest.md"},
+		{ID: "broken", Schedule: "@weekly", Action: schedule.ActionDraft, Template: "missing.md"},
+	}}
+
+	now := time.Now()
+	s.runScheduledJobs(now) // first sight: schedules, doesn't run
+	drafts, _ := os.ReadDir(filepath.Join(s.DataDir, ".polis", "posts", "drafts"))
+	if len(drafts) != 0 {
+		t.Fatalf("jobs ran on first sight: %d drafts", len(drafts))
+	}
+
+	s.runScheduledJobs(now.AddDate(0, 0, 8))
+	drafts, _ = os.ReadDir(filepath.Join(s.DataDir, ".polis", "posts", "drafts"))
+	if len(drafts) != 1 {
+		t.Fatalf("expected 1 draft, got %d", len(drafts))
+	}
+
+	st, _ := schedule.LoadState(s.DataDir)
+	if st["digest"].LastStatus != schedule.StatusOK || st["digest"].LastResult == "" {
+		t.Errorf("digest state = %+v", st["digest"])
+	}
+	if st["broken"].LastStatus != schedule.StatusError || st["broken"].LastError == "" {
+		t.Errorf("broken state = %+v", st["broken"])
+	}
+}
+
+func TestRunDigestJob(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	feed.NewCacheManager(s.DataDir, "default").MergeItems([]feed.FeedItem{
+		{Type: "post", Title: "Fresh", URL: "https://a.pub/posts/fresh.html", Published: now.Add(-time.Hour).UTC().Format(time.RFC3339), AuthorURL: "https://a.pub", AuthorDomain: "a.pub"},
+	})
+
+	id, err := s.runJob(schedule.Job{ID: "digest", Action: schedule.ActionDigest, Since: "1d", Publish: true}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(s.DataDir, ".polis", "posts", "drafts", id+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft := string(data); !strings.Contains(draft, "publish_at:") || !strings.Contains(draft, "[Fresh](https://a.pub/posts/fresh.html)") {
+		t.Errorf("digest draft =\n%s", draft)
+	}
+
+	if _, err := s.runJob(schedule.Job{ID: "digest", Action: schedule.ActionDigest, Email: true}, now); err == nil {
+		t.Error("emailed digest without email settings succeeded")
+	}
+}
+
+// ============================================================================
+// Bookmark Tests
+// ============================================================================
+
+func TestHandleFeedBookmark_FromFeedItem(t *testing.T) {
+	s := newTestServer(t)
+
+	cm := feed.NewCacheManager(s.DataDir, "default")
+	cm.MergeItems([]feed.FeedItem{
+		{Type: "post", Title: "Keep Me", URL: "https://127.0.0.1:1/posts/keep.md", Published: time.Now().UTC().Format(time.RFC3339), AuthorURL: "https://a.pub", AuthorDomain: "a.pub"},
+	})
+	items, _ := cm.List()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/feed/bookmark", jsonBody(t, map[string]string{"id": items[0].ID, "note": "later"}))
+	w := httptest.NewRecorder()
+	s.handleFeedBookmark(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Added         bool              `json:"added"`
+		Bookmark      bookmark.Bookmark `json:"bookmark"`
+		SnapshotError string            `json:"snapshot_error"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.Added || resp.Bookmark.Title != "Keep Me" || resp.Bookmark.AuthorDomain != "a.pub" || resp.Bookmark.Note != "later" {
+		t.Errorf("expected bookmark filled from feed item, got %+v", resp.Bookmark)
+	}
+	if resp.SnapshotError == "" {
+		t.Error("expected snapshot_error for an unreachable post")
+	}
+
+	// Listed
+	w = httptest.NewRecorder()
+	s.handleBookmarks(w, httptest.NewRequest(http.MethodGet, "/api/bookmarks", nil))
+	var list struct {
+		Total int `json:"total"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if list.Total != 1 {
+		t.Errorf("expected 1 bookmark, got %d", list.Total)
+	}
+
+	// Removed by feed item ID
+	req = httptest.NewRequest(http.MethodPost, "/api/feed/bookmark", jsonBody(t, map[string]interface{}{"id": items[0].ID, "remove": true}))
+	w = httptest.NewRecorder()
+	s.handleFeedBookmark(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on remove, got %d: %s", w.Code, w.Body.String())
+	}
+	f, _ := bookmark.Load(bookmark.DefaultPath(s.DataDir))
+	if f.Count() != 0 {
+		t.Errorf("expected bookmark removed, got %+v", f.Bookmarks)
+	}
+}
+
+func TestHandleFeedBookmark_RejectsHTTP(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/feed/bookmark", jsonBody(t, map[string]string{"url": "http://a.pub/posts/x.md"}))
+	w := httptest.NewRecorder()
+	s.handleFeedBookmark(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleBookmark_RendersSnapshot(t *testing.T) {
+	s := newTestServer(t)
+
+	url := "https://a.pub/posts/saved.md"
+	f := &bookmark.File{}
+	f.Add(bookmark.Bookmark{ID: bookmark.ComputeID(url), URL: url, Title: "Saved", SnapshotURL: url})
+	bookmark.Save(bookmark.DefaultPath(s.DataDir), f)
+	snap := bookmark.SnapshotPath(s.DataDir, bookmark.ComputeID(url))
+	os.MkdirAll(filepath.Dir(snap), 0755)
+	os.WriteFile(snap, []byte("---\ntitle: Saved\n---\n\n# Still here\n"), 0644)
+
+	w := httptest.NewRecorder()
+	s.handleBookmark(w, httptest.NewRequest(http.MethodGet, "/api/bookmarks/"+bookmark.ComputeID(url), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !strings.Contains(resp["content"].(string), "<h1") || strings.Contains(resp["raw"].(string), "title:") {
+		t.Errorf("expected rendered snapshot without frontmatter, got %v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleBookmark(w, httptest.NewRequest(http.MethodGet, "/api/bookmarks/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown bookmark, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Feed Filter Tests
+// ============================================================================
+
+func TestHandleFeedFilters_MuteThreadByItem(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now().UTC().Format(time.RFC3339)
+	post := "https://a.pub/posts/busy.md"
+
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+	cm.MergeItems([]feed.FeedItem{
+		{Type: "comment", Title: "Re: busy", URL: "https://b.pub/comments/1.md", TargetURL: post, Published: now, AuthorURL: "https://b.pub", AuthorDomain: "b.pub"},
+	})
+	items, _ := cm.List()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/feed/filters", jsonBody(t, map[string]string{"mute_thread": items[0].ID}))
+	w := httptest.NewRecorder()
+	s.handleFeedFilters(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		MutedThreads []string `json:"muted_threads"`
+		MarkedRead   int      `json:"marked_read"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.MutedThreads) != 1 || resp.MutedThreads[0] != post || resp.MarkedRead != 1 {
+		t.Errorf("expected the comment's post muted and the comment marked read, got %+v", resp)
+	}
+
+	// Later comments on the thread never reach the cache
+	added, _ := cm.MergeItems([]feed.FeedItem{
+		{Type: "comment", Title: "Re: busy again", URL: "https://b.pub/comments/2.md", TargetURL: post, Published: now, AuthorURL: "https://b.pub", AuthorDomain: "b.pub"},
+	})
+	if added != 0 {
+		t.Errorf("expected muted comment dropped, %d added", added)
+	}
+}
+
+func TestHandleFeedFilters_Keywords(t *testing.T) {
+	s := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/feed/filters", jsonBody(t, map[string]interface{}{"muted_keywords": []string{"Politics", " "}}))
+	w := httptest.NewRecorder()
+	s.handleFeedFilters(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.handleFeedFilters(w, httptest.NewRequest(http.MethodGet, "/api/feed/filters", nil))
+	var resp struct {
+		MutedThreads  []string `json:"muted_threads"`
+		MutedKeywords []string `json:"muted_keywords"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.MutedKeywords) != 1 || resp.MutedKeywords[0] != "politics" || resp.MutedThreads == nil {
+		t.Errorf("expected normalized keywords and an empty thread list, got %v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/feed/filters", jsonBody(t, map[string]string{"mute_thread": "not-a-url"}))
+	w = httptest.NewRecorder()
+	s.handleFeedFilters(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown item, got %d", w.Code)
+	}
+}
+
+func TestHandleTrash_RestoreDraft(t *testing.T) {
+	s := newTestServer(t)
+
+	draftPath := filepath.Join(s.DataDir, ".polis", "posts", "drafts", "oops.md")
+	os.WriteFile(draftPath, []byte("# Oops"), 0644)
+
+	rr := httptest.NewRecorder()
+	s.handleDraft(rr, httptest.NewRequest(http.MethodDelete, "/api/drafts/oops", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete: expected status 200, got %d", rr.Code)
+	}
+	var deleted struct {
+		TrashID string `json:"trash_id"`
+	}
+	json.NewDecoder(rr.Body).Decode(&deleted)
+
+	rr = httptest.NewRecorder()
+	s.handleTrash(rr, httptest.NewRequest(http.MethodGet, "/api/trash", nil))
+	var list struct {
+		Items []struct {
+			ID        string `json:"id"`
+			Title     string `json:"title"`
+			ExpiresAt string `json:"expires_at"`
+		} `json:"items"`
+		RetentionDays int `json:"retention_days"`
+	}
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list.Items) != 1 || list.Items[0].ID != deleted.TrashID || list.Items[0].Title != "Oops" || list.Items[0].ExpiresAt == "" {
+		t.Fatalf("unexpected trash listing: %+v", list)
+	}
+	if list.RetentionDays != trash.DefaultRetentionDays {
+		t.Errorf("expected retention %d, got %d", trash.DefaultRetentionDays, list.RetentionDays)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleTrashRestore(rr, httptest.NewRequest(http.MethodPost, "/api/trash/restore", jsonBody(t, map[string]string{"id": deleted.TrashID})))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("restore: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, err := os.Stat(draftPath); err != nil {
+		t.Errorf("draft should be restored: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleTrashRestore(rr, httptest.NewRequest(http.MethodPost, "/api/trash/restore", jsonBody(t, map[string]string{"id": deleted.TrashID})))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("second restore: expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestHandleTrash_RestoreConflict(t *testing.T) {
+	s := newTestServer(t)
+
+	draftPath := filepath.Join(s.DataDir, ".polis", "posts", "drafts", "dup.md")
+	os.WriteFile(draftPath, []byte("# Old"), 0644)
+	item, err := trash.Move(s.DataDir, ".polis/posts/drafts/dup.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(draftPath, []byte("# New"), 0644)
+
+	rr := httptest.NewRecorder()
+	s.handleTrashRestore(rr, httptest.NewRequest(http.MethodPost, "/api/trash/restore", jsonBody(t, map[string]string{"id": item.ID})))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+}
+
+func TestHandleTrash_Purge(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, name := range []string{"a", "b"} {
+		os.WriteFile(filepath.Join(s.DataDir, ".polis", "posts", "drafts", name+".md"), []byte("# "+name), 0644)
+		if _, err := trash.Move(s.DataDir, ".polis/posts/drafts/"+name+".md"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleTrashPurge(rr, httptest.NewRequest(http.MethodPost, "/api/trash/purge", jsonBody(t, map[string]string{})))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without id or all, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleTrashPurge(rr, httptest.NewRequest(http.MethodPost, "/api/trash/purge", jsonBody(t, map[string]bool{"all": true})))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if items, _ := trash.List(s.DataDir); len(items) != 0 {
+		t.Errorf("expected empty trash, got %v", items)
+	}
+}
+
+func TestHandlePost_DeleteUnpublishes(t *testing.T) {
+	s := newTestServer(t)
+
+	postPath := filepath.Join(s.DataDir, "posts", "20260101", "hello.md")
+	os.MkdirAll(filepath.Dir(postPath), 0755)
+	os.WriteFile(postPath, []byte("---\ntitle: Hello\n---\n# Hello\n"), 0644)
+	metadata.AppendToPublicIndex(s.DataDir, &metadata.IndexEntry{Type: "post", Path: "posts/20260101/hello.md", Title: "Hello"})
+
+	rr := httptest.NewRecorder()
+	s.handlePost(rr, httptest.NewRequest(http.MethodDelete, "/api/posts/posts/20260101/hello.md", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, err := os.Stat(postPath); !os.IsNotExist(err) {
+		t.Error("post should be moved to trash")
+	}
+	if entries, _ := metadata.LoadPublicIndex(s.DataDir); len(entries) != 0 {
+		t.Errorf("post should be removed from public index, got %v", entries)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handlePost(rr, httptest.NewRequest(http.MethodDelete, "/api/posts/posts/../.polis/keys/id_ed25519", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid path, got %d", rr.Code)
+	}
+}
+
+func TestHandlePublish_FromDraftRemovesDraft(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	draftPath := filepath.Join(s.DataDir, ".polis", "posts", "drafts", "idea.md")
+	os.WriteFile(draftPath, []byte("# Saved Idea\n\nBody."), 0644)
+
+	body := jsonBody(t, map[string]string{"draft_id": "idea"})
+	rr := httptest.NewRecorder()
+	s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, err := os.Stat(draftPath); !os.IsNotExist(err) {
+		t.Error("draft should be removed after publishing")
+	}
+}
+
+func TestHandlePublish_KeepDraft(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	draftPath := filepath.Join(s.DataDir, ".polis", "posts", "drafts", "idea.md")
+	os.WriteFile(draftPath, []byte("# Saved Idea"), 0644)
+
+	body := jsonBody(t, map[string]interface{}{"draft_id": "idea", "markdown": "# Edited Idea", "keep_draft": true})
+	rr := httptest.NewRecorder()
+	s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["title"] != "Edited Idea" {
+		t.Errorf("expected editor content to be published, got title %v", resp["title"])
+	}
+	if _, err := os.Stat(draftPath); err != nil {
+		t.Error("draft should be kept")
+	}
+}
+
+func TestHandlePublish_MissingDraft(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	body := jsonBody(t, map[string]string{"draft_id": "nope"})
+	rr := httptest.NewRecorder()
+	s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", body))
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestHandlePost_UnpublishToDraft(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	result, err := publish.PublishPost(s.DataDir, "# Second Thoughts\n\nBody.\n", "", s.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handlePost(rr, httptest.NewRequest(http.MethodGet, "/api/posts/"+result.Path+"/unpublish-to-draft", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for GET, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handlePost(rr, httptest.NewRequest(http.MethodPost, "/api/posts/"+result.Path+"/unpublish-to-draft", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		DraftID string `json:"draft_id"`
+		TrashID string `json:"trash_id"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	draft, err := os.ReadFile(filepath.Join(s.DataDir, ".polis", "posts", "drafts", resp.DraftID+".md"))
+	if err != nil || !strings.HasPrefix(string(draft), "# Second Thoughts") {
+		t.Errorf("expected draft with post body, got %q (%v)", draft, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.DataDir, result.Path)); !os.IsNotExist(err) {
+		t.Error("post should be removed")
+	}
+	if _, err := trash.Get(s.DataDir, resp.TrashID); err != nil {
+		t.Errorf("signed post should be in trash: %v", err)
+	}
+}
+
+func TestHandlePublish_FrontmatterSlug(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	body := jsonBody(t, map[string]string{"markdown": "---\nslug: custom-name\n---\n# A Long Title"})
+	rr := httptest.NewRecorder()
+	s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if path, _ := resp["path"].(string); !strings.HasSuffix(path, "/custom-name.md") {
+		t.Errorf("expected slug from frontmatter, got path %q", path)
+	}
+}
+
+func TestHandlePosts_Summary(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	// One entry with a stored summary, one written before summaries existed
+	postDir := filepath.Join(s.DataDir, "posts", "20260102")
+	os.MkdirAll(postDir, 0755)
+	os.WriteFile(filepath.Join(postDir, "second.md"), []byte("---\ntitle: Second Post\n---\n# Second Post\n\nOpening line.\n"), 0644)
+	entries := []string{
+		`{"path":"posts/20260101/first.md","title":"First Post","summary":"Stored summary."}`,
+		`{"path":"posts/20260102/second.md","title":"Second Post"}`,
+	}
+	os.WriteFile(filepath.Join(s.DataDir, "metadata", "public.jsonl"), []byte(strings.Join(entries, "\n")), 0644)
+
+	rr := httptest.NewRecorder()
+	s.handlePosts(rr, httptest.NewRequest(http.MethodGet, "/api/posts", nil))
+
+	var resp struct {
+		Posts []struct {
+			Path    string `json:"path"`
+			Summary string `json:"summary"`
+		} `json:"posts"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Posts) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(resp.Posts))
+	}
+	// Newest first
+	if resp.Posts[0].Summary != "Opening line." {
+		t.Errorf("expected summary generated from the post, got %q", resp.Posts[0].Summary)
+	}
+	if resp.Posts[1].Summary != "Stored summary." {
+		t.Errorf("expected stored summary, got %q", resp.Posts[1].Summary)
+	}
+}
+
+func TestHandlePosts_LangFilter(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	entries := []string{
+		`{"path":"posts/20260101/hello.md","title":"Hello","summary":"Hi."}`,
+		`{"path":"posts/20260102/ola.md","title":"Olá","summary":"Oi.","lang":"pt-BR","translation_of":"posts/20260101/hello.md"}`,
+		`{"path":"posts/20260103/bonjour.md","title":"Bonjour","summary":"Salut.","lang":"fr"}`,
+	}
+	os.WriteFile(filepath.Join(s.DataDir, "metadata", "public.jsonl"), []byte(strings.Join(entries, "\n")), 0644)
+
+	list := func(query string) (int, []string) {
+		rr := httptest.NewRecorder()
+		s.handlePosts(rr, httptest.NewRequest(http.MethodGet, "/api/posts"+query, nil))
+		var resp struct {
+			Posts []struct {
+				Path string `json:"path"`
+				Lang string `json:"lang"`
+			} `json:"posts"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		var got []string
+		for _, p := range resp.Posts {
+			got = append(got, p.Lang+" "+p.Path)
+		}
+		return rr.Code, got
+	}
+
+	if _, got := list(""); len(got) != 3 || got[2] != "en posts/20260101/hello.md" {
+		t.Errorf("expected all posts, untagged in the site language, got %v", got)
+	}
+	if _, got := list("?lang=pt"); len(got) != 1 || got[0] != "pt-BR posts/20260102/ola.md" {
+		t.Errorf("expected pt to match pt-BR, got %v", got)
+	}
+	if _, got := list("?lang=en"); len(got) != 1 || got[0] != "en posts/20260101/hello.md" {
+		t.Errorf("expected only the untagged post for en, got %v", got)
+	}
+	if code, _ := list("?lang=not%20a%20lang"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid lang, got %d", code)
+	}
+}
+
+func TestHandlePublish_Unlisted(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	publishPost := func(req map[string]interface{}) string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", jsonBody(t, req)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		got, _ := resp["unlisted"].(bool)
+		if want, _ := req["unlisted"].(bool); got != want {
+			t.Errorf("expected unlisted=%v in response, got %v", want, got)
+		}
+		path, _ := resp["path"].(string)
+		return path
+	}
+	listed := publishPost(map[string]interface{}{"markdown": "# Listed"})
+	unlisted := publishPost(map[string]interface{}{"markdown": "# Quiet", "unlisted": true})
+
+	index, _ := os.ReadFile(filepath.Join(s.DataDir, "metadata", "public.jsonl"))
+	if strings.Contains(string(index), unlisted) || !strings.Contains(string(index), listed) {
+		t.Errorf("expected only the listed post in public.jsonl, got %s", index)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handlePosts(rr, httptest.NewRequest(http.MethodGet, "/api/posts", nil))
+	var resp struct {
+		Posts []struct {
+			Path       string `json:"path"`
+			Visibility string `json:"visibility"`
+		} `json:"posts"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	visibility := make(map[string]string)
+	for _, p := range resp.Posts {
+		visibility[p.Path] = p.Visibility
+	}
+	if len(resp.Posts) != 2 || visibility[unlisted] != "unlisted" || visibility[listed] != "" {
+		t.Errorf("expected both posts with the unlisted one marked, got %+v", resp.Posts)
+	}
+}
+
+func TestCommentRetract(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	commentID := "retract-me-20260215"
+	pendingPath := filepath.Join(s.DataDir, ".polis", "comments", "pending", commentID+".md")
+	os.WriteFile(pendingPath, []byte(`---
+title: Re: hello-world
+type: comment
+published: 2026-02-15T10:00:00Z
+in-reply-to:
+  url: https://alice.polis.pub/posts/20260215/hello-world.md
+  root-post: https://alice.polis.pub/posts/20260215/hello-world.md
+current-version: sha256:abc123
+author: test-site.polis.pub
+signature: fakesig
+---
+
+Never mind.`), 0644)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/comments/"+commentID, nil)
+	w := httptest.NewRecorder()
+	s.handleCommentRetract(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/comments/"+commentID, nil)
+	w = httptest.NewRecorder()
+	s.handleCommentRetract(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Success bool                  `json:"success"`
+		Comment comment.RetractResult `json:"comment"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.Success || resp.Comment.PreviousStatus != comment.StatusPending {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	// No discovery service in tests, so the retraction is local only
+	if resp.Comment.EventPublished || resp.Comment.EventError == "" {
+		t.Errorf("expected unannounced retraction, got %+v", resp.Comment)
+	}
+	if _, err := os.Stat(pendingPath); !os.IsNotExist(err) {
+		t.Error("expected pending comment to be removed")
+	}
+
+	w = httptest.NewRecorder()
+	s.handleCommentRetract(w, httptest.NewRequest(http.MethodDelete, "/api/comments/"+commentID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second retract: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleBlessingFeatureAndSort(t *testing.T) {
+	s := newConfiguredServer(t)
+	post := "posts/20260101/hello.md"
+	for _, c := range []metadata.BlessedComment{
+		{URL: "https://a.example.com/comments/20260102/a.md", Version: "sha256:a", BlessedAt: "2026-01-02T00:00:00Z"},
+		{URL: "https://b.example.com/comments/20260103/b.md", Version: "sha256:b", BlessedAt: "2026-01-03T00:00:00Z"},
+	} {
+		metadata.AddBlessedComment(s.DataDir, post, c)
+	}
+
+	post2 := func(handler http.HandlerFunc, body map[string]interface{}) int {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodPost, "/", jsonBody(t, body)))
+		return rr.Code
+	}
+
+	if code := post2(s.handleBlessingFeature, map[string]interface{}{"comment_url": "https://x.example.com/comments/20260101/x.md", "featured": true}); code != http.StatusNotFound {
+		t.Errorf("feature unknown comment: expected 404, got %d", code)
+	}
+	if code := post2(s.handleBlessingFeature, map[string]interface{}{"comment_url": "https://b.example.com/comments/20260103/b.html", "featured": true}); code != http.StatusOK {
+		t.Errorf("feature: expected 200, got %d", code)
+	}
+	if code := post2(s.handleBlessingSort, map[string]interface{}{"post": post, "sort": "random"}); code != http.StatusBadRequest {
+		t.Errorf("unknown sort: expected 400, got %d", code)
+	}
+	if code := post2(s.handleBlessingSort, map[string]interface{}{"post": post, "sort": "featured-first"}); code != http.StatusOK {
+		t.Errorf("sort: expected 200, got %d", code)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleBlessedComments(rr, httptest.NewRequest(http.MethodGet, "/api/blessed-comments", nil))
+	var bc metadata.BlessedComments
+	json.NewDecoder(rr.Body).Decode(&bc)
+	if len(bc.Comments) != 1 || bc.Comments[0].Sort != metadata.SortFeaturedFirst {
+		t.Fatalf("unexpected blessed comments: %+v", bc)
+	}
+	if first := bc.Comments[0].Blessed[0]; !first.Featured || first.Version != "sha256:b" {
+		t.Errorf("expected featured comment first, got %+v", bc.Comments[0].Blessed)
+	}
+}
+
+func TestHandleProtected(t *testing.T) {
+	s := newConfiguredServer(t)
+
+	rr := httptest.NewRecorder()
+	s.handlePublish(rr, httptest.NewRequest(http.MethodPost, "/api/publish", jsonBody(t, map[string]interface{}{
+		"markdown":       "# Circle\n\nFor followers.",
+		"followers_only": true,
+	})))
+	var result publish.PublishResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if !result.FollowersOnly || !strings.HasPrefix(result.Path, "protected/posts/") {
+		t.Fatalf("expected followers-only post under protected/, got %+v", result)
+	}
+
+	followerKey, followerPub, _ := signing.GenerateKeypair()
+	s.protectedKeys = func(actor string) (string, error) { return string(followerPub), nil }
+	stream.NewStore(s.DataDir, s.GetDiscoveryDomain()).SaveState("polis.follow", &stream.FollowerState{
+		Count: 1, Followers: []string{"bob.example.com"},
+	})
+
+	target := "https://test-site.polis.pub/" + result.Path
+	fetch := func(actor string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleProtected(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("unsigned: expected 401, got %d", rr.Code)
+		}
+		challenge, ok := protected.ParseChallenge(rr.Header().Get("WWW-Authenticate"))
+		if !ok {
+			t.Fatal("expected a challenge")
+		}
+		creds, _ := protected.Sign(challenge, "test-site.polis.pub", "/"+result.Path, actor, followerKey)
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", creds.Header())
+		rr = httptest.NewRecorder()
+		s.handleProtected(rr, req)
+		return rr
+	}
+
+	if rr := fetch("bob.example.com"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "For followers.") {
+		t.Errorf("follower: expected the post, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := fetch("carol.example.com"); rr.Code != http.StatusForbidden {
+		t.Errorf("non-follower: expected 403, got %d", rr.Code)
+	}
+
+	// A follower's answer signed for another site, replayed here with that
+	// site's Host header, is refused
+	rr = httptest.NewRecorder()
+	s.handleProtected(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	challenge, _ := protected.ParseChallenge(rr.Header().Get("WWW-Authenticate"))
+	creds, _ := protected.Sign(challenge, "evil.example.com", "/"+result.Path, "bob.example.com", followerKey)
+	req := httptest.NewRequest(http.MethodGet, "https://evil.example.com/"+result.Path, nil)
+	req.Header.Set("Authorization", creds.Header())
+	rr = httptest.NewRecorder()
+	s.handleProtected(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("relayed signature: expected 403, got %d", rr.Code)
+	}
+}
+
+func TestHandleFollowers(t *testing.T) {
+	s := newTestServer(t)
+	store := stream.NewStore(s.DataDir, s.GetDiscoveryDomain())
+	store.SaveState("polis.follow", &stream.FollowerState{
+		Followers: []string{"alice.example.com", "bob.example.com"},
+		Count:     2,
+		Since: map[string]string{
+			"alice.example.com": "2026-03-01T00:00:00Z",
+			"bob.example.com":   "2026-03-02T00:00:00Z",
+		},
+	})
+	now := time.Now().UTC().Format(time.RFC3339)
+	store.SaveState(author.FollowerProfilesState, author.FollowerProfiles{
+		"alice.example.com": {SiteTitle: "Alice Writes", FetchedAt: now},
+		"bob.example.com":   {SiteTitle: "Bob's Log", FetchedAt: now},
+	})
+	following.Save(following.DefaultPath(s.DataDir), &following.FollowingFile{Following: []following.FollowingEntry{
+		{URL: "https://alice.example.com"},
+	}})
+
+	rr := httptest.NewRecorder()
+	s.handleFollowers(rr, httptest.NewRequest(http.MethodGet, "/api/followers?limit=1", nil))
+	var page struct {
+		Followers []author.Follower `json:"followers"`
+		Total     int               `json:"total"`
+	}
+	json.NewDecoder(rr.Body).Decode(&page)
+	if page.Total != 2 || len(page.Followers) != 1 || page.Followers[0].Domain != "bob.example.com" || page.Followers[0].SiteTitle != "Bob's Log" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleFollower(rr, httptest.NewRequest(http.MethodGet, "/api/followers/alice.example.com", nil))
+	var detail struct {
+		Follower author.Follower `json:"follower"`
+		Summary  author.Summary  `json:"summary"`
+	}
+	json.NewDecoder(rr.Body).Decode(&detail)
+	if rr.Code != http.StatusOK || !detail.Follower.FollowingBack || !detail.Summary.Follow.FollowedBy {
+		t.Errorf("unexpected detail %d: %+v", rr.Code, detail)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/followers/carol.example.com", http.StatusNotFound},
+		{http.MethodGet, "/api/followers/Not_A_Domain", http.StatusBadRequest},
+		{http.MethodGet, "/api/followers/bob.example.com/follow-back", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/followers/bob.example.com/follow-back", http.StatusBadRequest}, // no key
+	} {
+		rr := httptest.NewRecorder()
+		s.handleFollower(rr, httptest.NewRequest(tc.method, tc.path, nil))
+		if rr.Code != tc.want {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, rr.Code)
+		}
+	}
+}
+
+func TestHandleGraph(t *testing.T) {
+	s := newConfiguredServer(t)
+	stream.NewStore(s.DataDir, s.GetDiscoveryDomain()).SaveState("polis.follow", &stream.FollowerState{
+		Followers: []string{"alice.example.com"}, Count: 1,
+	})
+
+	rr := httptest.NewRecorder()
+	s.handleGraph(rr, httptest.NewRequest(http.MethodGet, "/api/graph", nil))
+	var g graph.Graph
+	json.NewDecoder(rr.Body).Decode(&g)
+	if rr.Code != http.StatusOK || g.Self != "test-site.polis.pub" || len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Fatalf("unexpected graph %d: %+v", rr.Code, g)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleGraph(rr, httptest.NewRequest(http.MethodGet, "/api/graph?format=dot", nil))
+	if !strings.Contains(rr.Body.String(), `"alice.example.com" -> "test-site.polis.pub"`) {
+		t.Errorf("unexpected DOT: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleGraph(rr, httptest.NewRequest(http.MethodGet, "/api/graph?format=csv", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestHandleStats(t *testing.T) {
+	s := newConfiguredServer(t)
+	stream.NewStore(s.DataDir, s.GetDiscoveryDomain()).SaveState("polis.blessing", &stream.BlessingState{Blessings: []stream.BlessingEntry{
+		{SourceURL: "https://alice.example.com/comments/20260301/a.md", Status: "granted", RequestedAt: "2026-03-01T10:00:00Z", UpdatedAt: "2026-03-01T13:00:00Z"},
+	}})
+
+	rr := httptest.NewRecorder()
+	s.handleStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	var st stats.Stats
+	json.NewDecoder(rr.Body).Decode(&st)
+	if rr.Code != http.StatusOK || st.CommentsReceived != 1 || st.AvgTimeToBlessingHours != 3 {
+		t.Fatalf("unexpected stats %d: %+v", rr.Code, st)
+	}
+	if len(st.TopDomains) != 1 || st.TopDomains[0].Domain != "alice.example.com" {
+		t.Errorf("top domains = %+v", st.TopDomains)
+	}
+	if _, err := os.Stat(stats.Path(s.DataDir)); err != nil {
+		t.Errorf("stats not cached: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleStats(rr, httptest.NewRequest(http.MethodPost, "/api/stats", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", rr.Code)
+	}
+}
+
+func TestSyncMentions(t *testing.T) {
+	s := newConfiguredServer(t)
+	ds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery.StreamQueryResponse{Events: []discovery.StreamEvent{
+			{Type: "polis.post.published", Actor: "stranger.example.com", Timestamp: "2026-03-01T10:00:00Z",
+				Payload: map[string]interface{}{"url": "https://stranger.example.com/posts/a.md", "title": "On polis"}},
+		}})
+	}))
+	defer ds.Close()
+	s.DiscoveryURL = ds.URL
+	s.mentionFetch = func(url string) (string, error) {
+		return "Via https://test-site.polis.pub/posts/x.html", nil
+	}
+
+	if n := s.syncMentions("test-site.polis.pub", ""); n != 1 {
+		t.Fatalf("syncMentions added %d notifications, want 1", n)
+	}
+	// Already scanned: nothing new
+	if n := s.syncMentions("test-site.polis.pub", ""); n != 0 {
+		t.Errorf("second syncMentions added %d notifications, want 0", n)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleMentions(rr, httptest.NewRequest(http.MethodGet, "/api/mentions", nil))
+	var page struct {
+		Mentions []mention.Mention `json:"mentions"`
+		Total    int               `json:"total"`
+	}
+	json.NewDecoder(rr.Body).Decode(&page)
+	if rr.Code != http.StatusOK || page.Total != 1 || page.Mentions[0].Title != "On polis" || page.Mentions[0].Links[0] != "https://test-site.polis.pub/posts/x.html" {
+		t.Errorf("unexpected mentions %d: %+v", rr.Code, page)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleMentions(rr, httptest.NewRequest(http.MethodPost, "/api/mentions", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rr.Code)
+	}
+}
+
+func TestHandleDiscover(t *testing.T) {
+	s := newConfiguredServer(t)
+	f, _ := following.Load(following.DefaultPath(s.DataDir))
+	f.Add("https://friend.example.com")
+	following.Save(following.DefaultPath(s.DataDir), f)
+
+	events := []discovery.StreamEvent{
+		{ID: "1", Type: "polis.post.published", Actor: "stranger.example.com",
+			Payload: map[string]interface{}{"url": "https://stranger.example.com/posts/a.md", "title": "Hello"}},
+		{ID: "2", Type: "polis.post.published", Actor: "friend.example.com",
+			Payload: map[string]interface{}{"url": "https://friend.example.com/posts/b.md", "title": "Followed"}},
+		{ID: "3", Type: "polis.post.published", Actor: "test-site.polis.pub",
+			Payload: map[string]interface{}{"url": "https://test-site.polis.pub/posts/c.md", "title": "Mine"}},
+	}
+	ds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ds-stream-health" {
+			json.NewEncoder(w).Encode(discovery.StreamHealthResponse{Status: "ok", LatestCursor: "3", OldestCursor: "1", EventCount: 3})
+			return
+		}
+		json.NewEncoder(w).Encode(discovery.StreamQueryResponse{Events: events, Cursor: "3"})
+	}))
+	defer ds.Close()
+	s.LoadEnv()
+	s.DiscoveryURL = ds.URL
+
+	rr := httptest.NewRecorder()
+	s.handleDiscover(rr, httptest.NewRequest(http.MethodGet, "/api/discover?sort=newest", nil))
+	var page struct {
+		Posts []struct {
+			Title     string `json:"title"`
+			AuthorURL string `json:"author_url"`
+		} `json:"posts"`
+		Next string `json:"next"`
+	}
+	json.NewDecoder(rr.Body).Decode(&page)
+	if rr.Code != http.StatusOK || len(page.Posts) != 1 || page.Posts[0].Title != "Hello" || page.Posts[0].AuthorURL != "https://stranger.example.com" {
+		t.Errorf("unexpected discover page %d: %+v", rr.Code, page)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleDiscover(rr, httptest.NewRequest(http.MethodGet, "/api/discover?sort=oldest", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort status = %d, want 400", rr.Code)
+	}
+
+	s.DiscoveryURL = ""
+	rr = httptest.NewRecorder()
+	s.handleDiscover(rr, httptest.NewRequest(http.MethodGet, "/api/discover", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unconfigured status = %d, want 400", rr.Code)
+	}
+}
+
+func TestHandleDiscoverSuggestions(t *testing.T) {
+	s := newConfiguredServer(t)
+	f, _ := following.Load(following.DefaultPath(s.DataDir))
+	f.Add("https://friend.example.com")
+	following.Save(following.DefaultPath(s.DataDir), f)
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+	cm.MergeItems([]feed.FeedItem{{
+		Type: "comment", URL: "https://friend.example.com/comments/a.md", Published: time.Now().UTC().Format(time.RFC3339),
+		AuthorURL: "https://friend.example.com", AuthorDomain: "friend.example.com",
+		TargetURL: "https://stranger.example.com/posts/p.md",
+	}})
+
+	rr := httptest.NewRecorder()
+	s.handleDiscoverSuggestions(rr, httptest.NewRequest(http.MethodGet, "/api/discover/suggestions", nil))
+	var resp struct {
+		Suggestions []struct {
+			Domain string `json:"domain"`
+			Reason string `json:"reason"`
+		} `json:"suggestions"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if rr.Code != http.StatusOK || len(resp.Suggestions) != 1 || resp.Suggestions[0].Domain != "stranger.example.com" || resp.Suggestions[0].Reason == "" {
+		t.Errorf("unexpected suggestions %d: %+v", rr.Code, resp)
+	}
+}
+
+func TestAPIVersionedRoutes(t *testing.T) {
+	s := newTestServer(t)
+	store := stream.NewStore(s.DataDir, s.GetDiscoveryDomain())
+	store.SaveState("polis.follow", &stream.FollowerState{
+		Followers: []string{"alice.example.com"},
+		Count:     1,
+	})
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+
+	// Versioned and unversioned paths reach the same handler, which parses
+	// the unversioned form
+	for _, path := range []string{"/api/v1/followers/alice.example.com", "/api/followers/alice.example.com"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		var detail struct {
+			Follower author.Follower `json:"follower"`
+		}
+		json.NewDecoder(rr.Body).Decode(&detail)
+		if rr.Code != http.StatusOK || detail.Follower.Domain != "alice.example.com" {
+			t.Errorf("%s: %d %+v", path, rr.Code, detail)
+		}
+	}
+
+	if got := unversionedPath("/api/v1/widget/state"); got != "/api/widget/state" {
+		t.Errorf("unversionedPath = %q", got)
+	}
+}
+
+func TestHandleOpenAPI(t *testing.T) {
+	s := newTestServer(t)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openAPIVersion {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	follower := doc.Paths["/api/v1/followers/{domain}/follow-back"]["post"]
+	if follower.OperationID != "postFollowersByDomainFollowBack" || len(follower.Parameters) != 1 || follower.Parameters[0].Name != "domain" {
+		t.Errorf("follow-back operation = %+v", follower)
+	}
+
+	// Every route is documented, and every operation ID is unique
+	ids := make(map[string]bool)
+	for _, rt := range s.apiRoutes() {
+		for _, method := range rt.methods() {
+			op, ok := doc.Paths[versionedPath(rt.Path)][strings.ToLower(method)]
+			if !ok {
+				t.Errorf("%s %s missing from the document", method, rt.Path)
+				continue
+			}
+			if ids[op.OperationID] {
+				t.Errorf("duplicate operationId %s", op.OperationID)
+			}
+			ids[op.OperationID] = true
+		}
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	s := newTestServer(t)
+	os.MkdirAll(filepath.Join(s.DataDir, ".polis", "posts", "drafts"), 0755)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	c := client.New(srv.URL)
+	ctx := context.Background()
+
+	id, err := c.SaveDraft(ctx, "", "# From the SDK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := c.Draft(ctx, id)
+	if err != nil || d.Markdown != "# From the SDK" {
+		t.Fatalf("Draft = %+v, %v", d, err)
+	}
+	if _, err := c.DeleteDraft(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Draft(ctx, id); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("deleted draft error = %v, want ErrNotFound", err)
+	}
+	if _, err := c.Status(ctx); err != nil {
+		t.Errorf("Status: %v", err)
+	}
+}
+
+func TestHandleDraftAnnotations(t *testing.T) {
+	s := newTestServer(t)
+	if _, err := publish.SaveDraft(s.DataDir, "essay", "# Essay\n\nBody.\n"); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/drafts/essay/annotations",
+		strings.NewReader(`{"start_line": 3, "author": "editor", "note": "Say more"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add: %d %s", w.Code, w.Body.String())
+	}
+	var a publish.Annotation
+	json.NewDecoder(w.Body).Decode(&a)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/drafts/essay/annotations",
+		strings.NewReader(`{"start_line": 30, "note": "Past the end"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("out-of-range lines: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/drafts/essay/export", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "- line 3 (editor): Say more") {
+		t.Errorf("export: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/drafts/essay/annotations/"+a.ID,
+		strings.NewReader(`{"resolved": true}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/drafts/essay/annotations/"+a.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/drafts/essay/annotations/"+a.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+}
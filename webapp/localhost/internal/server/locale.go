@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/i18n"
+)
+
+// requestLocale returns the language to answer r in: the locale setting if
+// set, else the best match for the browser's Accept-Language among the
+// loaded translations.
+func (s *Server) requestLocale(r *http.Request) string {
+	if s.Settings != nil {
+		if lang := s.Settings.Get("locale"); lang != "" {
+			return i18n.Canonical(lang)
+		}
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// loadTranslations loads message translations for the site.
+func (s *Server) loadTranslations() {
+	var extra string
+	if s.Settings != nil {
+		extra = s.Settings.Get("translations_dir")
+	}
+	if err := i18n.LoadDirs(i18n.Dirs(s.DataDir, extra)); err != nil {
+		s.LogWarn("Failed to load translations: %v", err)
+	}
+}
+
+// WithLocale translates API error messages into the request's language.
+// Handlers keep writing English with http.Error; plain-text error responses
+// are rewritten here through i18n.Message, so a message with a translation
+// (or whose part before ": " has one) reaches the user translated.
+func (s *Server) WithLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := s.requestLocale(r)
+		if lang == i18n.Default || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		lw := &localeWriter{ResponseWriter: w, lang: lang}
+		next.ServeHTTP(lw, r)
+		lw.finish()
+	})
+}
+
+// localeWriter buffers plain-text error bodies so they can be translated
+// whole; everything else passes straight through.
+type localeWriter struct {
+	http.ResponseWriter
+	lang      string
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (lw *localeWriter) WriteHeader(code int) {
+	h := lw.Header()
+	if code >= 400 && strings.HasPrefix(h.Get("Content-Type"), "text/plain") {
+		lw.buffering = true
+		h.Del("Content-Length")
+		h.Set("Content-Language", lw.lang)
+	}
+	lw.ResponseWriter.WriteHeader(code)
+}
+
+func (lw *localeWriter) Write(b []byte) (int, error) {
+	if lw.buffering {
+		return lw.body.Write(b)
+	}
+	return lw.ResponseWriter.Write(b)
+}
+
+// finish writes the translated error body, if one was buffered.
+func (lw *localeWriter) finish() {
+	if !lw.buffering {
+		return
+	}
+	msg := strings.TrimSuffix(lw.body.String(), "\n")
+	lw.ResponseWriter.Write([]byte(i18n.Message(lw.lang, msg) + "\n"))
+}
+
+// Flush keeps SSE working through the middleware.
+func (lw *localeWriter) Flush() {
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (lw *localeWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
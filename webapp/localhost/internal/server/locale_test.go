@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/i18n"
+)
+
+func TestWithLocale_TranslatesPlainTextErrors(t *testing.T) {
+	i18n.Reset()
+	defer i18n.Reset()
+	i18n.Add("fr", map[string]string{
+		"Post not found":      "Billet introuvable",
+		"Failed to save post": "Échec de l'enregistrement du billet",
+	})
+
+	s := newTestServer(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/save", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Failed to save post: disk full", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/api/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Post not found"))
+	})
+	h := s.WithLocale(mux)
+
+	tests := []struct {
+		path, accept, want string
+	}{
+		{"/api/missing", "fr-CA,fr;q=0.9", "Billet introuvable\n"},
+		{"/api/save", "fr", "Échec de l'enregistrement du billet: disk full\n"},
+		{"/api/missing", "en-US", "Post not found\n"},
+		{"/api/missing", "de", "Post not found\n"},
+		{"/api/ok", "fr", "Post not found"}, // Not an error
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept-Language", tt.accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s (%s): got %q, want %q", tt.path, tt.accept, w.Body.String(), tt.want)
+		}
+	}
+}
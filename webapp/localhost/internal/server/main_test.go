@@ -0,0 +1,14 @@
+package server
+
+import (
+	"os"
+	"testing"
+)
+
+// Tests talk to httptest servers on plain-HTTP loopback addresses. The
+// setting is read through the config loader each time a server loads its
+// config, so it is set in the environment rather than on safehttp directly.
+func TestMain(m *testing.M) {
+	os.Setenv("POLIS_ALLOW_LOCAL_FETCH", "true")
+	os.Exit(m.Run())
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mention"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+)
+
+// maxMentionScans caps the posts and comments fetched per sync cycle when
+// looking for mentions.
+const maxMentionScans = 20
+
+// syncMentions queries the stream for posts and comments since cursor,
+// scans those by authors we don't follow for links to our site, and turns
+// new mentions into notifications. Returns the number of notifications
+// added.
+func (s *Server) syncMentions(myDomain, cursor string) int {
+	client := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, myDomain, s.PrivateKey)
+	result, err := client.StreamQuery(cursor, 1000, discovery.JoinDomains(mention.EventTypes), "", "")
+	if err != nil {
+		s.LogDebug("unified sync: mentions query failed: %v", err)
+		return 0
+	}
+	if len(result.Events) == 0 {
+		return 0
+	}
+
+	followed := make(map[string]bool)
+	if f, err := s.cachedFollowing(); err == nil {
+		for _, entry := range f.All() {
+			if d := discovery.ExtractDomainFromURL(entry.URL); d != "" {
+				followed[d] = true
+			}
+		}
+	}
+	fetch := s.mentionFetch
+	if fetch == nil {
+		fetch = remote.NewClient().FetchContent
+	}
+	detector := &mention.Detector{
+		MyDomain:        myDomain,
+		FollowedDomains: followed,
+		Fetch:           fetch,
+		MaxScans:        maxMentionScans,
+	}
+
+	discoveryDomain := s.GetDiscoveryDomain()
+	state := mention.Load(s.DataDir, discoveryDomain)
+	found := detector.Scan(result.Events, state)
+	if err := mention.Save(s.DataDir, discoveryDomain, state); err != nil {
+		s.LogWarn("unified sync: failed to save mentions: %v", err)
+	}
+	if len(found) == 0 {
+		return 0
+	}
+	s.LogInfo("Found %d new mentions", len(found))
+
+	events := make([]discovery.StreamEvent, len(found))
+	for i, m := range found {
+		events[i] = m.Event(myDomain)
+	}
+	hr := (&notificationSyncHandler{server: s}).Process(events)
+	if hr.Error != nil {
+		s.LogWarn("unified sync: mention notifications: %v", hr.Error)
+	}
+	return hr.NewItems
+}
+
+// handleMentions returns posts and comments by authors we don't follow
+// that link to our site, newest first.
+// GET /api/mentions?offset=0&limit=50
+func (s *Server) handleMentions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	all := mention.Load(s.DataDir, s.GetDiscoveryDomain()).Mentions
+	page := []mention.Mention{}
+	if offset < len(all) {
+		page = all[offset:min(offset+limit, len(all))]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mentions": page,
+		"total":    len(all),
+		"offset":   offset,
+		"limit":    limit,
+	})
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the histogram upper bounds, in seconds, for request
+// durations exported at /api/metrics.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// routeKey identifies a route by mux pattern and method. Patterns (not raw
+// paths) keep label cardinality bounded for routes like /api/posts/.
+type routeKey struct {
+	Route  string
+	Method string
+}
+
+// routeStats accumulates request counts and latencies for one route.
+type routeStats struct {
+	Count        uint64
+	ClientErrors uint64 // 4xx
+	Errors       uint64 // 5xx
+	TotalSeconds float64
+	MaxSeconds   float64
+	Buckets      []uint64 // cumulative counts per latencyBuckets entry
+}
+
+// Metrics records per-route request statistics for the serve process.
+type Metrics struct {
+	mu      sync.Mutex
+	started time.Time
+	routes  map[routeKey]*routeStats
+}
+
+// NewMetrics returns an empty metrics registry.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		started: time.Now(),
+		routes:  make(map[routeKey]*routeStats),
+	}
+}
+
+// Observe records one completed request.
+func (m *Metrics) Observe(route, method string, status int, d time.Duration) {
+	secs := d.Seconds()
+	key := routeKey{Route: route, Method: method}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.routes[key]
+	if st == nil {
+		st = &routeStats{Buckets: make([]uint64, len(latencyBuckets))}
+		m.routes[key] = st
+	}
+	st.Count++
+	switch {
+	case status >= 500:
+		st.Errors++
+	case status >= 400:
+		st.ClientErrors++
+	}
+	st.TotalSeconds += secs
+	if secs > st.MaxSeconds {
+		st.MaxSeconds = secs
+	}
+	for i, le := range latencyBuckets {
+		if secs <= le {
+			st.Buckets[i]++
+		}
+	}
+}
+
+// snapshot returns a copy of the per-route stats sorted by route and method.
+func (m *Metrics) snapshot() ([]routeKey, map[routeKey]routeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]routeKey, 0, len(m.routes))
+	stats := make(map[routeKey]routeStats, len(m.routes))
+	for k, v := range m.routes {
+		keys = append(keys, k)
+		c := *v
+		c.Buckets = append([]uint64(nil), v.Buckets...)
+		stats[k] = c
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Route != keys[j].Route {
+			return keys[i].Route < keys[j].Route
+		}
+		return keys[i].Method < keys[j].Method
+	})
+	return keys, stats
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) {
+	keys, stats := m.snapshot()
+
+	fmt.Fprintln(w, "# HELP polis_uptime_seconds Seconds since the serve process started.")
+	fmt.Fprintln(w, "# TYPE polis_uptime_seconds gauge")
+	fmt.Fprintf(w, "polis_uptime_seconds %g\n", time.Since(m.started).Seconds())
+
+	fmt.Fprintln(w, "# HELP polis_http_requests_total HTTP requests by route, method, and status class.")
+	fmt.Fprintln(w, "# TYPE polis_http_requests_total counter")
+	for _, k := range keys {
+		st := stats[k]
+		ok := st.Count - st.ClientErrors - st.Errors
+		labels := promLabels(k)
+		fmt.Fprintf(w, "polis_http_requests_total{%s,code=\"2xx\"} %d\n", labels, ok)
+		fmt.Fprintf(w, "polis_http_requests_total{%s,code=\"4xx\"} %d\n", labels, st.ClientErrors)
+		fmt.Fprintf(w, "polis_http_requests_total{%s,code=\"5xx\"} %d\n", labels, st.Errors)
+	}
+
+	fmt.Fprintln(w, "# HELP polis_http_request_duration_seconds HTTP request latency by route and method.")
+	fmt.Fprintln(w, "# TYPE polis_http_request_duration_seconds histogram")
+	for _, k := range keys {
+		st := stats[k]
+		labels := promLabels(k)
+		for i, le := range latencyBuckets {
+			fmt.Fprintf(w, "polis_http_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, st.Buckets[i])
+		}
+		fmt.Fprintf(w, "polis_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, st.Count)
+		fmt.Fprintf(w, "polis_http_request_duration_seconds_sum{%s} %g\n", labels, st.TotalSeconds)
+		fmt.Fprintf(w, "polis_http_request_duration_seconds_count{%s} %d\n", labels, st.Count)
+	}
+}
+
+func promLabels(k routeKey) string {
+	esc := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return fmt.Sprintf(`route="%s",method="%s"`, esc.Replace(k.Route), esc.Replace(k.Method))
+}
+
+// RouteSummary is the per-route entry returned by /api/debug/stats.
+type RouteSummary struct {
+	Route        string  `json:"route"`
+	Method       string  `json:"method"`
+	Count        uint64  `json:"count"`
+	ClientErrors uint64  `json:"client_errors"`
+	Errors       uint64  `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"` // 5xx / count
+	AvgMs        float64 `json:"avg_ms"`
+	P95Ms        float64 `json:"p95_ms"` // upper bound of the histogram bucket holding p95
+	MaxMs        float64 `json:"max_ms"`
+}
+
+// Summary returns per-route statistics, busiest routes first.
+func (m *Metrics) Summary() []RouteSummary {
+	keys, stats := m.snapshot()
+	out := make([]RouteSummary, 0, len(keys))
+	for _, k := range keys {
+		st := stats[k]
+		rs := RouteSummary{
+			Route:        k.Route,
+			Method:       k.Method,
+			Count:        st.Count,
+			ClientErrors: st.ClientErrors,
+			Errors:       st.Errors,
+			MaxMs:        st.MaxSeconds * 1000,
+		}
+		if st.Count > 0 {
+			rs.ErrorRate = float64(st.Errors) / float64(st.Count)
+			rs.AvgMs = st.TotalSeconds / float64(st.Count) * 1000
+			rs.P95Ms = rs.MaxMs
+			target := uint64(float64(st.Count)*0.95 + 0.5)
+			for i, le := range latencyBuckets {
+				if st.Buckets[i] >= target {
+					rs.P95Ms = le * 1000
+					break
+				}
+			}
+		}
+		out = append(out, rs)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	return out
+}
+
+// Uptime returns how long the metrics registry has been collecting.
+func (m *Metrics) Uptime() time.Duration {
+	return time.Since(m.started)
+}
+
+// metrics returns the server's metrics registry, creating it on first use.
+func (s *Server) metrics() *Metrics {
+	s.metricsOnce.Do(func() {
+		s.metricsReg = NewMetrics()
+	})
+	return s.metricsReg
+}
+
+// statusRecorder captures the response status for the metrics middleware.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps SSE working through the middleware.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// WithMetrics wraps mux so every request is counted, timed, and logged at
+// debug level. Requests are grouped by the mux pattern that served them.
+func (s *Server) WithMetrics(mux *http.ServeMux) http.Handler {
+	m := s.metrics()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Resolve the route first; the SPA fallback rewrites r.URL.Path
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		path := r.URL.Path
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		mux.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		d := time.Since(start)
+		m.Observe(route, r.Method, status, d)
+		s.LogDebug("%s %s %d %s", r.Method, path, status, d.Round(time.Microsecond))
+	})
+}
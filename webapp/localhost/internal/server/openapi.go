@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// openAPIVersion is the OpenAPI specification version of the generated
+// document.
+const openAPIVersion = "3.0.3"
+
+var pathParamPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// OpenAPI builds an OpenAPI 3 document describing the versioned API from
+// the route table. Request and response bodies are documented as free-form
+// JSON; the document pins down paths, methods, and parameters.
+func (s *Server) OpenAPI() map[string]interface{} {
+	paths := make(map[string]interface{})
+	for _, rt := range s.apiRoutes() {
+		path := versionedPath(rt.Path)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+
+		var params []interface{}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(rt.Path, -1) {
+			params = append(params, map[string]interface{}{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]string{"type": "string"},
+			})
+		}
+
+		for _, method := range rt.methods() {
+			op := map[string]interface{}{
+				"operationId": operationID(method, rt.Path),
+				"summary":     rt.Summary,
+				"tags":        []string{routeTag(rt.Path)},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Success",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]string{"type": "object"},
+							},
+						},
+					},
+					"405": map[string]string{"description": "Method not allowed"},
+				},
+			}
+			if params != nil {
+				op["parameters"] = params
+			}
+			if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+				op["requestBody"] = map[string]interface{}{
+					"required": false,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]string{"type": "object"},
+						},
+					},
+				}
+			}
+			item[strings.ToLower(method)] = op
+		}
+	}
+
+	version := s.CLIVersion
+	if version == "" {
+		version = "dev"
+	}
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "Polis local server API",
+			"version":     version,
+			"description": "JSON API of polis serve. Paths under /api/" + APIVersion + "/ are stable; the unversioned /api/ paths are aliases kept for existing clients.",
+		},
+		"paths": paths,
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document.
+// GET /api/openapi.json
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.OpenAPI())
+}
+
+// operationID derives a camel-case operation ID from a method and path:
+// GET /api/followers/{domain} becomes getFollowersByDomain.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			b.WriteString("By")
+			seg = strings.TrimSuffix(name, "}")
+		}
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// routeTag groups a route by its first path segment.
+func routeTag(path string) string {
+	tag, _, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/")
+	return tag
+}
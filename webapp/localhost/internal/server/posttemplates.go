@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/posttemplate"
+)
+
+// handlePostTemplates lists the site's post templates, or creates one.
+// GET /api/post-templates
+// POST /api/post-templates {"name": "review", "content": "---\ntitle: {{title}}\n---\n"}
+func (s *Server) handlePostTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := posttemplate.List(s.DataDir)
+		if err != nil {
+			s.LogError("failed to list post templates: %v", err)
+			http.Error(w, "Failed to list templates", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"templates": templates,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if !posttemplate.ValidName(req.Name) {
+			http.Error(w, "Invalid template name (use lowercase letters, digits, '-', '_')", http.StatusBadRequest)
+			return
+		}
+		t, err := posttemplate.Create(s.DataDir, req.Name, req.Content)
+		if errors.Is(err, posttemplate.ErrExists) {
+			http.Error(w, "A template with that name already exists", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			s.LogError("failed to save post template: %v", err)
+			http.Error(w, "Failed to save template", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePostTemplate reads, replaces, or deletes a post template, or starts
+// a draft from it.
+// GET/PUT/DELETE /api/post-templates/{name}
+// POST /api/post-templates/{name}/draft {"vars": {"title": "..."}}
+func (s *Server) handlePostTemplate(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/post-templates/")
+	name, action, _ := strings.Cut(rest, "/")
+	if !posttemplate.ValidName(name) {
+		http.Error(w, "Invalid template name", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		t, err := posttemplate.Get(s.DataDir, name)
+		if errors.Is(err, posttemplate.ErrNotFound) {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			s.LogError("failed to read post template: %v", err)
+			http.Error(w, "Failed to read template", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	case r.Method == http.MethodPut && action == "":
+		var req struct {
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		t, err := posttemplate.Save(s.DataDir, name, req.Content)
+		if err != nil {
+			s.LogError("failed to save post template: %v", err)
+			http.Error(w, "Failed to save template", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	case r.Method == http.MethodDelete && action == "":
+		err := posttemplate.Delete(s.DataDir, name)
+		if errors.Is(err, posttemplate.ErrNotFound) {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			s.LogError("failed to delete post template: %v", err)
+			http.Error(w, "Failed to delete template", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+
+	case r.Method == http.MethodPost && action == "draft":
+		var req struct {
+			Vars map[string]string `json:"vars"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request", http.StatusBadRequest)
+				return
+			}
+		}
+		id, err := posttemplate.NewDraft(s.DataDir, name, req.Vars, time.Now())
+		if errors.Is(err, posttemplate.ErrNotFound) {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			s.LogError("failed to create draft from template: %v", err)
+			http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"id":      id,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+func TestPostTemplates(t *testing.T) {
+	s := newConfiguredServer(t)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+
+	do := func(method, target string, body interface{}) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if body != nil {
+			req = httptest.NewRequest(method, target, jsonBody(t, body))
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	content := "---\ntitle: \"Review: {{title}}\"\n---\n\nRead on {{date}}. Rating: {{rating}}\n"
+	if w := do(http.MethodPost, "/api/post-templates", map[string]string{"name": "review", "content": content}); w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/post-templates", map[string]string{"name": "review", "content": "x"}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: got %d, want 409", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/post-templates", map[string]string{"name": "../x", "content": "x"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid name: got %d, want 400", w.Code)
+	}
+
+	w := do(http.MethodGet, "/api/v1/post-templates", nil)
+	var list struct {
+		Templates []struct {
+			Name      string   `json:"name"`
+			Variables []string `json:"variables"`
+		} `json:"templates"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Templates) != 1 || list.Templates[0].Name != "review" || strings.Join(list.Templates[0].Variables, ",") != "title,rating" {
+		t.Errorf("list = %s", w.Body.String())
+	}
+
+	if w := do(http.MethodPut, "/api/post-templates/review", map[string]string{"content": content + "\n## Verdict\n"}); w.Code != http.StatusOK {
+		t.Errorf("save: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/post-templates/review", nil); !strings.Contains(w.Body.String(), "Verdict") {
+		t.Errorf("get after save = %s", w.Body.String())
+	}
+
+	w = do(http.MethodPost, "/api/post-templates/review/draft", map[string]interface{}{"vars": map[string]string{"title": "Dune"}})
+	var created struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.ID == "" {
+		t.Fatalf("draft: %d %s", w.Code, w.Body.String())
+	}
+	data, err := os.ReadFile(publish.DraftPath(s.DataDir, created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft := string(data); !strings.Contains(draft, `title: "Review: Dune"`) || strings.Contains(draft, "{{date}}") || !strings.Contains(draft, "{{rating}}") {
+		t.Errorf("draft =\n%s", draft)
+	}
+	if w := do(http.MethodPost, "/api/post-templates/missing/draft", nil); w.Code != http.StatusNotFound {
+		t.Errorf("draft from a missing template: got %d, want 404", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api/post-templates/review", nil); w.Code != http.StatusOK {
+		t.Errorf("delete: %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/post-templates/review", nil); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", w.Code)
+	}
+}
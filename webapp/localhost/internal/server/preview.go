@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+)
+
+// Draft preview links: POST /api/drafts/{id}/share issues a token, and
+// /preview/<token> renders the draft with the active theme for whoever has
+// the link, until the token expires or is revoked. Nothing is published.
+
+// previewStylesheet is where preview pages load the site's stylesheet from.
+const previewStylesheet = "/preview/styles.css"
+
+// previewURL returns the absolute preview URL for a token, on the host the
+// request came in on.
+func previewURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/preview/" + token
+}
+
+// handleDraftShare serves /api/drafts/{id}/share.
+//
+//	GET    lists the draft's unexpired preview links (without their tokens)
+//	POST   issues one; {"hours": N} sets its lifetime (default 72, at most 720)
+//	DELETE revokes the link in ?id= (or ?token=), or all of the draft's links
+func (s *Server) handleDraftShare(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/drafts/"), "/share")
+	id = draftIDSanitizer.ReplaceAllString(id, "-")
+	if id == "" {
+		http.Error(w, "Draft ID required", http.StatusBadRequest)
+		return
+	}
+
+	type shareJSON struct {
+		publish.DraftShare
+		URL string `json:"url,omitempty"` // Only when issued: the token isn't kept
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		shares, err := publish.DraftShares(s.DataDir, id)
+		if err != nil {
+			s.LogError("failed to load draft shares: %v", err)
+			http.Error(w, "Failed to load preview links", http.StatusInternalServerError)
+			return
+		}
+		out := make([]shareJSON, len(shares))
+		for i, sh := range shares {
+			out[i] = shareJSON{DraftShare: sh}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"shares": out})
+
+	case http.MethodPost:
+		var req struct {
+			Hours int `json:"hours"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request", http.StatusBadRequest)
+				return
+			}
+		}
+		share, err := publish.ShareDraft(s.DataDir, id, time.Duration(req.Hours)*time.Hour)
+		if err != nil {
+			if errors.Is(err, publish.ErrDraftNotFound) {
+				http.Error(w, "Draft not found", http.StatusNotFound)
+				return
+			}
+			s.LogError("failed to share draft: %v", err)
+			http.Error(w, "Failed to create preview link", http.StatusInternalServerError)
+			return
+		}
+		s.LogInfo("Preview link for draft %s issued (expires %s)", id, share.ExpiresAt)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shareJSON{*share, previewURL(r, share.Token)})
+
+	case http.MethodDelete:
+		shareID := r.URL.Query().Get("id")
+		if token := r.URL.Query().Get("token"); token != "" {
+			shareID = publish.DraftShareID(token)
+		}
+		n, err := publish.RevokeDraftShares(s.DataDir, id, shareID)
+		if err != nil {
+			s.LogError("failed to revoke draft shares: %v", err)
+			http.Error(w, "Failed to revoke preview links", http.StatusInternalServerError)
+			return
+		}
+		s.LogInfo("Revoked %d preview link(s) for draft %s", n, id)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"revoked": n,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePreview serves GET /preview/<token>: the shared draft, rendered with
+// the active theme. Unknown, expired, and revoked tokens get 404.
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == previewStylesheet {
+		http.ServeFile(w, r, filepath.Join(s.DataDir, "styles.css"))
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	share, err := publish.FindDraftShare(s.DataDir, strings.TrimPrefix(r.URL.Path, "/preview/"))
+	if err != nil {
+		s.LogError("failed to load draft shares: %v", err)
+		http.Error(w, "Preview unavailable", http.StatusInternalServerError)
+		return
+	}
+	if share == nil {
+		http.NotFound(w, r)
+		return
+	}
+	markdown, err := os.ReadFile(publish.DraftPath(s.DataDir, share.Draft))
+	if err != nil {
+		http.NotFound(w, r) // Draft published or deleted since it was shared
+		return
+	}
+
+	renderer, err := render.NewPageRenderer(render.PageConfig{
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         s.GetBaseURL(),
+		DiscoveryDomain: s.GetDiscoveryDomain(),
+	})
+	if err != nil {
+		s.LogError("preview: failed to create renderer: %v", err)
+		http.Error(w, "Preview unavailable", http.StatusInternalServerError)
+		return
+	}
+	page, err := renderer.RenderPreview(string(markdown), previewStylesheet)
+	if err != nil {
+		s.LogError("preview: %v", err)
+		http.Error(w, "Preview unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+)
+
+func TestDraftShareAndPreview(t *testing.T) {
+	s := newConfiguredServer(t)
+	setupTestTheme(t, s, "turbo")
+	os.WriteFile(filepath.Join(s.DataDir, ".polis", "themes", "turbo", "post.html"),
+		[]byte(`<html><head>{{robots_meta}}<link rel="stylesheet" href="{{css_path}}"></head><body>{{content}}</body></html>`), 0644)
+	if _, err := publish.SaveDraft(s.DataDir, "essay", "# Work in progress\n"); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/drafts/essay/share", strings.NewReader(`{"hours": 2}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("share: %d %s", w.Code, w.Body.String())
+	}
+	var share struct {
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+	json.NewDecoder(w.Body).Decode(&share)
+	if share.URL != "http://example.com/preview/"+share.Token {
+		t.Errorf("url = %q", share.URL)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/preview/"+share.Token, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("preview: %d %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Work in progress", `content="noindex"`, `href="/preview/styles.css"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("preview missing %q:\n%s", want, body)
+		}
+	}
+
+	// Listed by ID; the token isn't kept
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/drafts/essay/share", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"`+publish.DraftShareID(share.Token)+`"`) ||
+		strings.Contains(w.Body.String(), share.Token) {
+		t.Errorf("list: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/drafts/essay/share?token="+share.Token, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/preview/"+share.Token, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("revoked preview: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/drafts/missing/share", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing draft: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/protected"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+)
+
+// Followers-only posts are published under protected/ and never rendered or
+// deployed. /protected/ serves them to followers who answer a signed
+// challenge (see package protected); everyone else gets 401 or 403.
+
+// ProtectedChallenges returns the store of outstanding /protected/
+// challenges (created on first use).
+func (s *Server) ProtectedChallenges() *protected.ChallengeStore {
+	s.protectedOnce.Do(func() {
+		s.protectedChallenges = protected.NewChallengeStore()
+	})
+	return s.protectedChallenges
+}
+
+// protectedPublicKey returns the public key published by actor's site.
+func (s *Server) protectedPublicKey(actor string) (string, error) {
+	if s.protectedKeys != nil {
+		return s.protectedKeys(actor)
+	}
+	if !author.ValidDomain(actor) {
+		return "", errors.New("actor must be a bare domain")
+	}
+	return remote.NewClient().FetchPublicKey("https://" + actor)
+}
+
+// siteHost returns the host (and port, if any) of the site's base URL, which
+// readers sign into their answer. The request's Host header is never used: a
+// site could otherwise relay a challenge from here to a reader, have it
+// signed for its own host, and replay it with that Host.
+func (s *Server) siteHost() string {
+	u, err := url.Parse(s.GetBaseURL())
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// mayReadProtected reports whether actor follows this site (or is this site).
+func (s *Server) mayReadProtected(actor string) bool {
+	if own := extractDomainFromURL(s.GetBaseURL()); own != "" && strings.EqualFold(actor, own) {
+		return true
+	}
+	var state stream.FollowerState
+	_ = stream.NewStore(s.DataDir, s.GetDiscoveryDomain()).LoadState("polis.follow", &state)
+	for _, f := range state.Followers {
+		if strings.EqualFold(f, actor) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleProtected serves GET /protected/<path> to signed follower requests.
+func (s *Server) handleProtected(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	relPath := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if !publish.IsProtectedPath(relPath) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	challenge := func(msg string) {
+		c, err := s.ProtectedChallenges().Issue()
+		if err != nil {
+			s.LogWarn("Failed to issue protected challenge: %v", err)
+			http.Error(w, "Try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", protected.ChallengeHeader(c))
+		http.Error(w, msg, http.StatusUnauthorized)
+	}
+
+	actor, err := protected.Verify(r, s.siteHost(), s.ProtectedChallenges(), s.protectedPublicKey)
+	switch {
+	case errors.Is(err, protected.ErrNoCredentials):
+		challenge("Signed request required")
+		return
+	case errors.Is(err, protected.ErrUnknownChallenge):
+		challenge("Challenge expired; sign the new one")
+		return
+	case err != nil:
+		s.LogWarn("Rejected signed request for %s: %v", relPath, err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !s.mayReadProtected(actor) {
+		s.LogInfo("Refused %s to non-follower %s", relPath, actor)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	fullPath := filepath.Join(s.DataDir, filepath.FromSlash(relPath))
+	if info, err := os.Stat(fullPath); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if strings.HasSuffix(relPath, ".md") {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	http.ServeFile(w, r, fullPath)
+}
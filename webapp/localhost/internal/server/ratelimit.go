@@ -0,0 +1,105 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit defaults for mutating API requests, per endpoint group.
+const (
+	DefaultRateLimit      = 30 // requests per minute
+	DefaultRateLimitBurst = 10
+)
+
+// RateLimiter is a token-bucket limiter with one bucket per key. Each bucket
+// holds up to burst tokens and refills at perMinute tokens per minute.
+type RateLimiter struct {
+	perMinute int
+	burst     int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter. perMinute <= 0 disables limiting.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &RateLimiter{
+		perMinute: perMinute,
+		burst:     burst,
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When none is left it returns false
+// and how long until the next token.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.perMinute <= 0 {
+		return true, 0
+	}
+	rate := float64(l.perMinute) / 60 // tokens per second
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	if b == nil {
+		b = &tokenBucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// RateLimiter returns the limiter for mutating API requests, configured
+// from the rate_limit and rate_limit_burst settings.
+func (s *Server) RateLimiter() *RateLimiter {
+	s.rateLimiterOnce.Do(func() {
+		perMinute, burst := DefaultRateLimit, DefaultRateLimitBurst
+		if s.Settings != nil {
+			perMinute = s.Settings.Int("rate_limit")
+			burst = s.Settings.Int("rate_limit_burst")
+		}
+		s.rateLimiter = NewRateLimiter(perMinute, burst)
+	})
+	return s.rateLimiter
+}
+
+// rateLimited wraps a handler so its mutating requests (anything but GET,
+// HEAD, and OPTIONS) draw from the group's token bucket. Over the limit,
+// the request gets 429 with a Retry-After header.
+func (s *Server) rateLimited(group string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			h(w, r)
+			return
+		}
+		if ok, wait := s.RateLimiter().Allow(group); !ok {
+			secs := int(math.Ceil(wait.Seconds()))
+			s.LogWarn("Rate limit exceeded for %s %s (retry in %ds)", r.Method, r.URL.Path, secs)
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			http.Error(w, "Too many requests, please slow down", http.StatusTooManyRequests)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(60, 2) // one token per second
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("publish"); !ok {
+			t.Fatalf("request %d within burst was limited", i)
+		}
+	}
+	ok, wait := l.Allow("publish")
+	if ok || wait != time.Second {
+		t.Errorf("over burst: ok=%v wait=%v, want limited for 1s", ok, wait)
+	}
+	if ok, _ := l.Allow("comments"); !ok {
+		t.Error("groups should have separate buckets")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("publish"); !ok {
+		t.Error("expected a token after one second")
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("publish"); !ok {
+			t.Fatal("limiter with rate 0 should allow everything")
+		}
+	}
+}
+
+func TestRateLimited_OnlyMutatingRequests(t *testing.T) {
+	s := newTestServer(t)
+	s.rateLimiterOnce.Do(func() { s.rateLimiter = NewRateLimiter(1, 1) })
+	h := s.rateLimited("publish", func(w http.ResponseWriter, r *http.Request) {})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodPost, "/api/publish", nil))
+		if w.Code != want {
+			t.Errorf("POST %d: got %d, want %d", i, w.Code, want)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+			t.Errorf("Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/api/publish", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET should not be limited, got %d", w.Code)
+	}
+}
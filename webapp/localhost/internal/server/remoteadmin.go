@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/remoteadmin"
+)
+
+// loopbackAddr reports whether a listen address only accepts connections
+// from this machine.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// remoteProxy passes API requests to the server at target as the API user
+// token belongs to. Only requests made to local, this proxy's listen address,
+// from its own pages are passed on: anything else, a cross-origin page or a
+// DNS-rebound host name, would act with the token. The Origin of a request
+// that passes is forwarded as the remote server's own, so its origin checks
+// still apply; cookies stay here, as they belong to this machine.
+func remoteProxy(target *url.URL, token, local string) http.Handler {
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			if pr.Out.Header.Get("Origin") != "" {
+				pr.Out.Header.Set("Origin", target.Scheme+"://"+target.Host)
+			}
+			pr.Out.Header.Del("Referer")
+			pr.Out.Header.Del("Cookie")
+			pr.Out.Header.Set("Authorization", "Bearer "+token)
+		},
+		// SSE streams are passed on as they arrive
+		FlushInterval: -1,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Host, local) {
+			http.Error(w, "Unknown host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(r, origin) {
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// RunRemote serves the web UI on this machine for the server connected with
+// polis remote-admin. API requests, SSE and WebSocket included, go to the
+// remote server, so the UI runs its site as it would locally.
+func RunRemote(webFS fs.FS, conn *remoteadmin.Connection, opts ...RunOptions) {
+	target, err := url.Parse(conn.URL)
+	if err != nil {
+		log.Fatalf("Invalid remote URL %q: %v", conn.URL, err)
+	}
+
+	addr := ""
+	if len(opts) > 0 {
+		addr = opts[0].Listen
+	}
+	if addr == "" {
+		port, err := FindAvailablePort()
+		if err != nil {
+			log.Fatal("Failed to find available port:", err)
+		}
+		addr = fmt.Sprintf("localhost:%d", port)
+	} else if !loopbackAddr(addr) {
+		// Whoever reached it would act with the connection's token
+		log.Fatalf("serve --remote only listens on this machine, not %s", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", remoteProxy(target, conn.Token, addr))
+	mux.Handle("/", spaHandler(webFS))
+
+	local := "http://" + addr
+	fmt.Printf("[i] Serving the web UI for %s (as %s, %s)\n", conn.URL, conn.User, conn.Role)
+	fmt.Printf("[i] Listening on %s\n", local)
+	if conn.Insecure() {
+		fmt.Println("[!] The token travels unencrypted over http://; put the server behind HTTPS.")
+	}
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		OpenBrowser(local)
+	}()
+
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
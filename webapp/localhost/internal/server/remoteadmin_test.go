@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRemoteProxy(t *testing.T) {
+	var got *http.Request
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"auth":true}`))
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+	proxy := httptest.NewUnstartedServer(nil)
+	proxy.Start()
+	defer proxy.Close()
+	local := strings.TrimPrefix(proxy.URL, "http://")
+	proxy.Config.Handler = remoteProxy(target, "secret", local)
+
+	send := func(host, origin string) int {
+		t.Helper()
+		got = nil
+		req, _ := http.NewRequest(http.MethodPost, proxy.URL+"/api/publish", nil)
+		req.Host = host
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		req.AddCookie(&http.Cookie{Name: AccessCookie, Value: "local"})
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := send(local, proxy.URL); code != http.StatusOK {
+		t.Fatalf("same-origin request: %d", code)
+	}
+	if got == nil || got.URL.Path != "/api/publish" || got.Method != http.MethodPost {
+		t.Fatalf("backend got %+v", got)
+	}
+	if got.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q", got.Header.Get("Authorization"))
+	}
+	if got.Header.Get("Origin") != backend.URL || got.Header.Get("Cookie") != "" {
+		t.Errorf("Origin %q and Cookie %q passed on", got.Header.Get("Origin"), got.Header.Get("Cookie"))
+	}
+
+	// Other pages, and other host names resolving here, get nothing
+	for _, tc := range []struct{ host, origin string }{
+		{local, "https://evil.example"},
+		{"evil.example:" + strings.Split(local, ":")[1], ""},
+		{"evil.example:" + strings.Split(local, ":")[1], "http://evil.example:" + strings.Split(local, ":")[1]},
+	} {
+		if code := send(tc.host, tc.origin); code != http.StatusForbidden || got != nil {
+			t.Errorf("host %q, origin %q: got %d, forwarded %v", tc.host, tc.origin, code, got != nil)
+		}
+	}
+}
+
+func TestLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"localhost:8080": true,
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"0.0.0.0:8080":   false,
+		":8080":          false,
+		"10.0.0.5:8080":  false,
+	} {
+		if got := loopbackAddr(addr); got != want {
+			t.Errorf("loopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
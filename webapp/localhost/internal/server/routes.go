@@ -1,101 +1,255 @@
 package server
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
+
+// APIVersion is the current version of the JSON API. Routes are served
+// under /api/v1/; the unversioned /api/ paths remain as aliases for
+// existing clients.
+const APIVersion = "v1"
+
+const versionedAPIPrefix = "/api/" + APIVersion + "/"
+
+// apiRoute describes one API operation. The route table drives both
+// registration and the OpenAPI document served at /api/openapi.json.
+type apiRoute struct {
+	Methods string // Slash-separated, e.g. "GET/POST"
+	// Path is the unversioned path. A {param} segment matches the rest of
+	// the path; the route is registered on the prefix before it.
+	Path    string
+	Summary string
+	Handler http.HandlerFunc
+}
 
 // SetupRoutes registers all API routes on the given ServeMux.
+// Signing and discovery operations are rate limited (see rateLimited).
 func SetupRoutes(mux *http.ServeMux, s *Server) {
-	// API routes
-	mux.HandleFunc("/api/status", s.handleStatus)
-	mux.HandleFunc("/api/validate", s.handleValidate)
-	mux.HandleFunc("/api/init", s.handleInit)
-	mux.HandleFunc("/api/link", s.handleLink)
-	mux.HandleFunc("/api/render", s.handleRender)
-	mux.HandleFunc("/api/publish", s.handlePublish)
-	mux.HandleFunc("/api/drafts", s.handleDrafts)
-	mux.HandleFunc("/api/drafts/", s.handleDraft)
-	mux.HandleFunc("/api/posts", s.handlePosts)
-	mux.HandleFunc("/api/posts/", s.handlePost)
-	mux.HandleFunc("/api/republish", s.handleRepublish)
-
-	// Comment API routes (MY comments - outgoing)
-	mux.HandleFunc("/api/comments/drafts", s.handleCommentDrafts)
-	mux.HandleFunc("/api/comments/drafts/", s.handleCommentDraft)
-	mux.HandleFunc("/api/comments/sign", s.handleCommentSign)
-	mux.HandleFunc("/api/comments/beseech", s.handleCommentBeseech)
-	mux.HandleFunc("/api/comments/pending", s.handleCommentsPending)
-	mux.HandleFunc("/api/comments/pending/", s.handleCommentByStatus)
-	mux.HandleFunc("/api/comments/blessed", s.handleCommentsBlessed)
-	mux.HandleFunc("/api/comments/blessed/", s.handleCommentByStatus)
-	mux.HandleFunc("/api/comments/denied", s.handleCommentsDenied)
-	mux.HandleFunc("/api/comments/denied/", s.handleCommentByStatus)
-	mux.HandleFunc("/api/comments/sync", s.handleCommentsSync)
-
-	// Blessing API routes (ON MY POSTS - incoming blessing requests)
-	mux.HandleFunc("/api/blessing/requests", s.handleBlessingRequests)
-	mux.HandleFunc("/api/blessing/grant", s.handleBlessingGrant)
-	mux.HandleFunc("/api/blessing/deny", s.handleBlessingDeny)
-	mux.HandleFunc("/api/blessing/revoke", s.handleBlessingRevoke)
-	mux.HandleFunc("/api/blessed-comments", s.handleBlessedComments)
-
-	// Settings and automation API routes
-	mux.HandleFunc("/api/settings", s.handleSettings)
-	mux.HandleFunc("/api/settings/view-mode", s.handleViewMode)
-	mux.HandleFunc("/api/settings/show-frontmatter", s.handleShowFrontmatter)
-	mux.HandleFunc("/api/settings/hide-read", s.handleHideRead)
-	mux.HandleFunc("/api/settings/site-title", s.handleUpdateSiteTitle)
-	mux.HandleFunc("/api/settings/theme", s.handleThemeSwitch)
-	mux.HandleFunc("/api/download-site", s.handleDownloadSite)
-	mux.HandleFunc("/api/content/", s.handleContent)
-	mux.HandleFunc("/api/automations", s.handleAutomations)
-	mux.HandleFunc("/api/automations/quick", s.handleAutomationsQuick)
-	mux.HandleFunc("/api/automations/", s.handleAutomation)
-	mux.HandleFunc("/api/templates", s.handleTemplates)
-	mux.HandleFunc("/api/hooks/generate", s.handleHooksGenerate)
-
-	// Site registration API routes
-	mux.HandleFunc("/api/site/registration-status", s.handleSiteRegistrationStatus)
-	mux.HandleFunc("/api/site/register", s.handleSiteRegister)
-	mux.HandleFunc("/api/site/unregister", s.handleSiteUnregister)
-	mux.HandleFunc("/api/site/deploy-check", s.handleDeployCheck)
-	mux.HandleFunc("/api/site/setup-wizard-dismiss", s.handleSetupWizardDismiss)
-
-	// About page API route
-	mux.HandleFunc("/api/about", s.handleAbout)
-
-	// Snippets API routes
-	mux.HandleFunc("/api/snippets", s.handleSnippets)
-	mux.HandleFunc("/api/snippets/", s.handleSnippet)
-
-	// Social API routes (following, feed, remote content)
-	mux.HandleFunc("/api/following", s.handleFollowing)
-	mux.HandleFunc("/api/feed", s.handleFeed)
-	mux.HandleFunc("/api/feed/refresh", s.handleFeedRefresh)
-	mux.HandleFunc("/api/feed/read", s.handleFeedRead)
-	mux.HandleFunc("/api/feed/counts", s.handleFeedCounts)
-	mux.HandleFunc("/api/feed/grouped", s.handleFeedGrouped)
-	mux.HandleFunc("/api/remote/post", s.handleRemotePost)
-
-	// Notification API routes
-	mux.HandleFunc("/api/notifications", s.handleNotifications)
-	mux.HandleFunc("/api/notifications/count", s.handleNotificationCount)
-	mux.HandleFunc("/api/notifications/read", s.handleNotificationRead)
-
-	// Social plugin routes
-	mux.HandleFunc("/api/pulse", s.handlePulse)
-	mux.HandleFunc("/api/activity", s.handleActivityStream)
-	mux.HandleFunc("/api/conversations", s.handleConversations)
-	mux.HandleFunc("/api/followers/count", s.handleFollowerCount)
-
-	// Render API routes (for snippet editing workflow)
-	mux.HandleFunc("/api/render-page", s.handleRenderPage)
-
-	// SSE and consolidated counts routes
-	mux.HandleFunc("/api/sse", s.handleSSE)
-	mux.HandleFunc("/api/counts", s.handleCounts)
-
-	// Widget API routes (cross-origin, widget token auth)
-	mux.HandleFunc("/api/widget/publish", s.handleWidgetPublish)
-	mux.HandleFunc("/api/widget/comment", s.handleWidgetComment)
-	mux.HandleFunc("/api/widget/follow", s.handleWidgetFollow)
-	mux.HandleFunc("/api/widget/connect", s.handleWidgetConnect)
+	registered := make(map[string]bool)
+	for _, rt := range s.apiRoutes() {
+		pattern := rt.pattern()
+		if registered[pattern] {
+			// Several operations share a prefix handler
+			continue
+		}
+		registered[pattern] = true
+		mux.HandleFunc(pattern, rt.Handler)
+		mux.HandleFunc(versionedPath(pattern), unversioned(rt.Handler))
+	}
+	mux.HandleFunc("/api/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc(versionedAPIPrefix+"openapi.json", s.handleOpenAPI)
+
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+
+	// Followers-only posts (signed follower requests)
+	mux.HandleFunc("/protected/", s.handleProtected)
+
+	// Shared draft previews (token in the URL)
+	mux.HandleFunc("/preview/", s.handlePreview)
+
+	// Post view beacon, called from rendered posts (analytics.enabled)
+	mux.HandleFunc("/beacon", s.handleBeacon)
+}
+
+// apiRoutes returns the API route table.
+func (s *Server) apiRoutes() []apiRoute {
+	return []apiRoute{
+		// Site and posts
+		{"GET", "/api/status", "Site status and identity", s.handleStatus},
+		{"GET", "/api/me", "The user the request is signed in as, and their role", s.handleMe},
+		{"GET", "/api/coauthors", "Co-authors of the site, and whose keys are here", s.handleCoauthors},
+		{"GET", "/api/validate", "Validate site structure", s.handleValidate},
+		{"GET/POST", "/api/conflicts", "Posts deployed from another copy of the site", s.handleConflicts},
+		{"POST", "/api/conflicts/resolve", "Pull, keep, or merge a conflicting post", s.handleConflictResolve},
+		{"POST", "/api/init", "Initialize a new site", s.rateLimited("init", s.handleInit)},
+		{"POST", "/api/link", "Switch to an existing site and register it as a workspace", s.handleLink},
+		{"GET/POST", "/api/workspaces", "List registered sites, or register one", s.handleWorkspaces},
+		{"POST", "/api/workspaces/{name}/use", "Switch to a registered site", s.handleWorkspace},
+		{"DELETE", "/api/workspaces/{name}", "Forget a registered site", s.handleWorkspace},
+		{"GET", "/api/sites", "Sites this process serves (serve --multi-site)", s.handleSites},
+		{"POST", "/api/render", "Render markdown to HTML and sign it (preview)", s.handleRender},
+		{"POST", "/api/publish", "Sign and publish a post", s.rateLimited("publish", s.handlePublish)},
+		{"GET/POST", "/api/drafts", "List drafts, or save one", s.handleDrafts},
+		{"GET/DELETE", "/api/drafts/{id}", "Read or trash a draft", s.handleDraft},
+		{"GET/POST/DELETE", "/api/drafts/{id}/share", "List, issue, or revoke preview links for a draft", s.handleDraft},
+		{"GET/POST", "/api/drafts/{id}/annotations", "List or add review notes on a draft", s.handleDraft},
+		{"PATCH/DELETE", "/api/drafts/{id}/annotations/{annotation_id}", "Update or delete a review note", s.handleDraft},
+		{"GET", "/api/drafts/{id}/export", "Download a draft with its open review notes", s.handleDraft},
+		{"GET/POST", "/api/post-templates", "List post templates, or create one", s.handlePostTemplates},
+		{"GET/PUT/DELETE", "/api/post-templates/{name}", "Read, save, or delete a post template", s.handlePostTemplate},
+		{"POST", "/api/post-templates/{name}/draft", "Create a draft from a post template", s.handlePostTemplate},
+		{"GET", "/api/posts", "List published posts (?lang= filters)", s.handlePosts},
+		{"GET/DELETE", "/api/posts/{path}", "Read a post, or unpublish it into the trash", s.handlePost},
+		{"POST", "/api/posts/{path}/unpublish-to-draft", "Turn a post back into a draft", s.handlePost},
+		{"GET/PATCH", "/api/posts/{path}/frontmatter", "Read or update a post's frontmatter (re-signs)", s.handlePost},
+		{"POST", "/api/republish", "Update an existing post", s.rateLimited("publish", s.handleRepublish)},
+		{"GET", "/api/trash", "List trashed drafts and posts", s.handleTrash},
+		{"POST", "/api/trash/restore", "Restore a trashed item", s.handleTrashRestore},
+		{"POST", "/api/trash/purge", "Permanently delete one trashed item, or all", s.handleTrashPurge},
+
+		// Comments (MY comments - outgoing)
+		{"GET/POST", "/api/comments/drafts", "List comment drafts, or save one", s.rateLimited("comments", s.handleCommentDrafts)},
+		{"GET/DELETE", "/api/comments/drafts/{id}", "Read or trash a comment draft", s.rateLimited("comments", s.handleCommentDraft)},
+		{"POST", "/api/comments/sign", "Sign a comment", s.rateLimited("comments", s.handleCommentSign)},
+		{"POST", "/api/comments/beseech", "Request a blessing for a comment", s.rateLimited("comments", s.handleCommentBeseech)},
+		{"GET", "/api/comments/pending", "List pending comments", s.rateLimited("comments", s.handleCommentsPending)},
+		{"GET", "/api/comments/pending/{id}", "Read a pending comment", s.rateLimited("comments", s.handleCommentByStatus)},
+		{"GET", "/api/comments/blessed", "List blessed comments", s.rateLimited("comments", s.handleCommentsBlessed)},
+		{"GET", "/api/comments/blessed/{id}", "Read a blessed comment", s.rateLimited("comments", s.handleCommentByStatus)},
+		{"GET", "/api/comments/denied", "List denied comments", s.rateLimited("comments", s.handleCommentsDenied)},
+		{"GET", "/api/comments/denied/{id}", "Read a denied comment", s.rateLimited("comments", s.handleCommentByStatus)},
+		{"POST", "/api/comments/sync", "Sync comment statuses", s.rateLimited("comments", s.handleCommentsSync)},
+		{"DELETE", "/api/comments/{id}", "Retract a signed comment", s.rateLimited("comments", s.handleCommentRetract)},
+
+		// Blessings (ON MY POSTS - incoming blessing requests)
+		{"GET", "/api/blessing/requests", "List pending blessing requests", s.rateLimited("blessing", s.handleBlessingRequests)},
+		{"POST", "/api/blessing/grant", "Bless a comment", s.rateLimited("blessing", s.handleBlessingGrant)},
+		{"POST", "/api/blessing/deny", "Deny a comment", s.rateLimited("blessing", s.handleBlessingDeny)},
+		{"POST", "/api/blessing/bulk", "Grant or deny many requests", s.rateLimited("blessing", s.handleBlessingBulk)},
+		{"POST", "/api/blessing/revoke", "Revoke a blessing", s.rateLimited("blessing", s.handleBlessingRevoke)},
+		{"POST", "/api/blessing/feature", "Mark or unmark a blessed comment as featured", s.rateLimited("blessing", s.handleBlessingFeature)},
+		{"POST", "/api/blessing/sort", "Set a post's comment order", s.rateLimited("blessing", s.handleBlessingSort)},
+		{"GET", "/api/blessed-comments", "List blessed comments on my posts", s.handleBlessedComments},
+
+		// Settings and automation
+		{"GET", "/api/settings", "Site info and webapp settings", s.handleSettings},
+		{"POST", "/api/settings/view-mode", "Switch between list and browser modes", s.handleViewMode},
+		{"POST", "/api/settings/show-frontmatter", "Toggle frontmatter visibility", s.handleShowFrontmatter},
+		{"POST", "/api/settings/hide-read", "Toggle hiding read feed items", s.handleHideRead},
+		{"POST", "/api/settings/site-title", "Update the site title", s.handleUpdateSiteTitle},
+		{"POST", "/api/settings/theme", "Switch the site theme", s.handleThemeSwitch},
+		{"GET/POST/DELETE", "/api/settings/private-feed", "Token URL for the followers/unlisted RSS feed (POST rotates)", s.handlePrivateFeed},
+		{"GET/POST", "/api/settings/email", "SMTP settings for comment notification emails", s.handleEmailSettings},
+		{"POST", "/api/settings/email/test", "Send a test notification email", s.handleEmailTest},
+		{"GET/POST", "/api/settings/remote-media", "Image and embed loading in remote posts", s.handleRemoteMediaSettings},
+		{"GET/POST", "/api/settings/prefetch", "Feed content prefetching and cache size", s.handlePrefetchSettings},
+		{"GET/POST", "/api/settings/pages", "Archive, 404, and about page generation", s.handlePageSettings},
+		{"GET/POST", "/api/settings/analytics", "Post view counting and beacon URL", s.handleAnalyticsSettings},
+		{"GET", "/api/logs", "Recent log entries (?level=, ?since=, ?limit=)", s.handleLogs},
+		{"GET", "/api/metrics", "Per-route request metrics (Prometheus text format)", s.handleMetrics},
+		{"GET", "/api/debug/stats", "Request counts, error rates, and latencies per route", s.handleDebugStats},
+		{"GET", "/api/download-site", "Download the site as a zip archive", s.handleDownloadSite},
+		{"GET", "/api/content/{path}", "Read a site content file", s.handleContent},
+		{"GET/POST", "/api/automations", "List hooks and scheduled jobs, or create a scheduled job", s.handleAutomations},
+		{"POST", "/api/automations/quick", "Auto-discover hooks", s.handleAutomationsQuick},
+		{"DELETE", "/api/automations/{id}", "Remove a hook or scheduled job", s.handleAutomation},
+		{"GET", "/api/templates", "List available hook templates", s.handleTemplates},
+		{"POST", "/api/hooks/generate", "Generate a hook script", s.handleHooksGenerate},
+
+		// Site registration and deploy
+		{"GET", "/api/site/registration-status", "Check discovery registration", s.handleSiteRegistrationStatus},
+		{"POST", "/api/site/register", "Register with the discovery service", s.handleSiteRegister},
+		{"POST", "/api/site/unregister", "Unregister from the discovery service", s.handleSiteUnregister},
+		{"GET", "/api/site/deploy-check", "Check the live site is reachable", s.handleDeployCheck},
+		{"GET", "/api/site/deploy-status", "Undeployed local changes and live-site drift", s.handleDeployStatus},
+		{"GET/POST", "/api/deploy", "List deploy targets, or upload changed files", s.handleDeploy},
+		{"POST", "/api/site/setup-wizard-dismiss", "Dismiss the setup wizard", s.handleSetupWizardDismiss},
+
+		// About page and snippets
+		{"GET/POST", "/api/about", "Read or save the about page", s.handleAbout},
+		{"GET/POST", "/api/snippets", "List snippets, or create one", s.handleSnippets},
+		{"GET/PUT/DELETE", "/api/snippets/{name}", "Read, save, or delete a snippet", s.handleSnippet},
+		{"GET", "/api/snippets/{name}/versions", "List a snippet's previous versions", s.handleSnippet},
+		{"GET", "/api/snippets/{name}/versions/{version}", "Read a previous version of a snippet", s.handleSnippet},
+		{"POST", "/api/snippets/{name}/versions/{version}/restore", "Restore a previous version of a snippet", s.handleSnippet},
+
+		// Social (following, feed, remote content)
+		{"GET/POST/DELETE", "/api/following", "Manage followed sites", s.handleFollowing},
+		{"GET", "/api/feed", "Aggregated feed from followed sites", s.handleFeed},
+		{"POST", "/api/feed/refresh", "Force a feed refresh", s.handleFeedRefresh},
+		{"POST", "/api/feed/read", "Mark a feed item as read", s.handleFeedRead},
+		{"GET", "/api/feed/counts", "Unread and total feed counts", s.handleFeedCounts},
+		{"GET/POST", "/api/feed/next-unread", "Next unread feed item; POST marks id read first", s.handleFeedNextUnread},
+		{"GET", "/api/feed/grouped", "Feed items grouped by post", s.handleFeedGrouped},
+		{"GET/POST", "/api/feed/filters", "Muted threads and keywords", s.handleFeedFilters},
+		{"POST", "/api/feed/bookmark", "Bookmark a feed item or remote post", s.handleFeedBookmark},
+		{"GET", "/api/bookmarks", "List bookmarks", s.handleBookmarks},
+		{"GET", "/api/bookmarks/{id}", "Read a bookmark with its snapshot", s.handleBookmark},
+		{"GET", "/api/remote/post", "Fetch remote post content", s.handleRemotePost},
+		{"GET", "/api/remote/image", "Fetch, cache, and downsize a remote image", s.handleRemoteImage},
+
+		// Notifications
+		{"GET", "/api/notifications", "List notifications (?offset=, ?limit=, ?include_read=)", s.handleNotifications},
+		{"GET", "/api/notifications/count", "Unread notification count", s.handleNotificationCount},
+		{"POST", "/api/notifications/read", "Mark notifications as read", s.handleNotificationRead},
+
+		// Social plugins
+		{"GET", "/api/pulse", "Community pulse dashboard", s.handlePulse},
+		{"GET", "/api/activity", "Stream events from followed authors", s.handleActivityStream},
+		{"GET", "/api/conversations", "Comment threads and blessing activity", s.handleConversations},
+		{"GET/POST/DELETE", "/api/threads", "Watched comment threads and replies near you", s.handleThreads},
+		{"GET/POST", "/api/reconcile", "Local-vs-discovery reconciliation report", s.handleReconcile},
+		{"GET", "/api/followers", "List followers (?offset=, ?limit=)", s.handleFollowers},
+		{"GET", "/api/followers/count", "Follower count and domains", s.handleFollowerCount},
+		{"GET", "/api/followers/{domain}", "One follower plus the relationship dossier", s.handleFollower},
+		{"POST", "/api/followers/{domain}/follow-back", "Follow a follower's site", s.handleFollower},
+		{"GET", "/api/mentions", "Mentions of this site by authors you don't follow", s.handleMentions},
+		{"GET", "/api/discover", "Recent posts by authors you don't follow (?sort=newest|trending, ?before=)", s.handleDiscover},
+		{"GET", "/api/discover/suggestions", "Sites to follow, from whom the authors you follow comment on (?limit=)", s.handleDiscoverSuggestions},
+		{"GET", "/api/network/domain/{domain}", "Relationship dossier for a domain", s.handleNetworkDomain},
+		{"GET", "/api/authors/{domain}", "Author profile", s.handleAuthor},
+		{"GET", "/api/graph", "Local social graph (?format=json, dot, or graphml)", s.handleGraph},
+		{"GET", "/api/stats", "Site statistics", s.handleStats},
+		{"GET", "/api/analytics", "Post views per day (?days=)", s.handleAnalytics},
+
+		// Render (snippet editing workflow)
+		{"POST", "/api/render-page", "Preview snippet changes", s.handleRenderPage},
+		{"GET", "/api/render-audit", "Accessibility audit of posts, comments, and theme", s.handleRenderAudit},
+		{"POST", "/api/render-shortcodes", "Dry-run expansion of snippet and variable shortcodes", s.handleRenderShortcodes},
+
+		// SSE and consolidated counts
+		{"GET", "/api/sse", "Server-sent events for live count updates", s.handleSSE},
+		{"GET", "/api/ws", "WebSocket with the SSE events plus mark-read and refresh-feed commands", s.handleWS},
+		{"GET", "/api/counts", "All badge counts", s.handleCounts},
+
+		// Widget (cross-origin, widget token auth)
+		{"POST", "/api/widget/publish", "Sign and beseech a comment, dispatched by type", s.handleWidgetPublish},
+		{"POST", "/api/widget/comment", "Sign and beseech a comment", s.handleWidgetComment},
+		{"POST/DELETE", "/api/widget/follow", "Follow or unfollow an author", s.handleWidgetFollow},
+		{"GET", "/api/widget/state", "Follow, read, and comment state for an author or post", s.handleWidgetState},
+		{"GET", "/api/widget/connect", "Issue a widget token and redirect", s.handleWidgetConnect},
+
+		// Browser extension handshake (cross-origin, extension_origins allowlist)
+		{"POST/DELETE", "/api/ext/handshake", "Issue or revoke an extension token", s.rateLimited("extension", s.handleExtHandshake)},
+	}
+}
+
+// pattern returns the ServeMux pattern for the route.
+func (rt apiRoute) pattern() string {
+	if i := strings.Index(rt.Path, "{"); i >= 0 {
+		return rt.Path[:i]
+	}
+	return rt.Path
+}
+
+// methods returns the route's HTTP methods.
+func (rt apiRoute) methods() []string {
+	return strings.Split(rt.Methods, "/")
+}
+
+// versionedPath maps an /api/ path to its /api/v1/ equivalent.
+func versionedPath(path string) string {
+	return versionedAPIPrefix + strings.TrimPrefix(path, "/api/")
+}
+
+// unversionedPath maps an /api/v1/ path to its /api/ alias; other paths are
+// returned unchanged.
+func unversionedPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, versionedAPIPrefix); ok {
+		return "/api/" + rest
+	}
+	return path
+}
+
+// unversioned serves a versioned request with h, which parses the
+// unversioned path.
+func unversioned(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = unversionedPath(r.URL.Path)
+		r2.URL.RawPath = ""
+		h(w, r2)
+	}
 }
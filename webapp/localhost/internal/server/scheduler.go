@@ -0,0 +1,180 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/backup"
+	"github.com/vdibart/polis-cli/cli-go/pkg/digest"
+	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
+	"github.com/vdibart/polis-cli/cli-go/pkg/schedule"
+)
+
+// scheduledJobs returns a copy of the configured scheduled automations.
+func (s *Server) scheduledJobs() []schedule.Job {
+	if s.Config == nil {
+		return nil
+	}
+	return append([]schedule.Job(nil), s.Config.Schedules...)
+}
+
+// runScheduledJobs runs every scheduled automation that is due. Called from
+// the background sync loop, so jobs never overlap each other or a sync.
+func (s *Server) runScheduledJobs(now time.Time) {
+	jobs := s.scheduledJobs()
+	if len(jobs) == 0 {
+		return
+	}
+
+	st, err := schedule.LoadState(s.DataDir)
+	if err != nil {
+		s.LogWarn("scheduler: %v", err)
+		return
+	}
+	due, changed := schedule.Due(jobs, st, now)
+	for _, j := range due {
+		result, err := s.runJob(j, now)
+		if err != nil {
+			s.LogError("scheduled automation %s failed: %v", j.ID, err)
+			s.recordSyncError(fmt.Errorf("scheduled automation %s: %w", j.ID, err))
+		} else {
+			s.LogInfo("Ran scheduled automation %s: %s", j.ID, result)
+		}
+		schedule.Record(st, j, now, result, err)
+		changed = true
+	}
+	if changed {
+		if err := schedule.SaveState(s.DataDir, st); err != nil {
+			s.LogWarn("scheduler: failed to save state: %v", err)
+		}
+	}
+}
+
+// runJob performs one scheduled automation and returns a short description
+// of what it produced.
+func (s *Server) runJob(j schedule.Job, now time.Time) (string, error) {
+	switch j.Action {
+	case schedule.ActionSync:
+		result := s.runUnifiedSync()
+		s.recordSync(result)
+		return fmt.Sprintf("%d new feed items, %d new notifications", result.NewFeedItems, result.NewNotifications), nil
+
+	case schedule.ActionBackup:
+		dir := backup.Dir(s.DataDir)
+		path, err := backup.Create(s.DataDir, dir, now)
+		if err != nil {
+			return "", err
+		}
+		if _, err := backup.Prune(dir, j.Keep); err != nil {
+			s.LogWarn("scheduled backup: prune failed: %v", err)
+		}
+		rel, _ := filepath.Rel(s.DataDir, path)
+		return filepath.ToSlash(rel), nil
+
+	case schedule.ActionDraft:
+		id, err := schedule.CreateDraft(s.DataDir, j, now)
+		if err != nil {
+			return "", err
+		}
+		return id, nil
+
+	case schedule.ActionScript:
+		res, err := hooks.RunScript(s.DataDir, j.Script, &hooks.HookPayload{
+			Event:     hooks.EventScheduled,
+			Path:      j.ID,
+			Title:     j.Name,
+			Timestamp: now.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return "", err
+		}
+		return truncateOutput(res.Output), nil
+
+	case schedule.ActionDigest:
+		return s.runDigestJob(j, now)
+	}
+	return "", fmt.Errorf("unknown action %q", j.Action)
+}
+
+// runDigestJob compiles a digest of the job's period, emails it when the
+// job asks to, and saves it as a draft (published on the next cycle with
+// Publish) unless it was only to be emailed.
+func (s *Server) runDigestJob(j schedule.Job, now time.Time) (string, error) {
+	since := j.Since
+	if since == "" {
+		since = digest.DefaultSince
+	}
+	period, err := digest.ParseSince(since)
+	if err != nil {
+		return "", err
+	}
+	d, err := digest.Build(s.DataDir, digest.Options{
+		Since:           now.Add(-period),
+		Until:           now,
+		DiscoveryDomain: s.GetDiscoveryDomain(),
+		BaseURL:         s.GetBaseURL(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var results []string
+	if j.Email {
+		if s.Config == nil || s.Config.Email == nil || !s.Config.Email.Enabled {
+			return "", fmt.Errorf("email notifications are not configured")
+		}
+		cfg := *s.Config.Email
+		cfg.Password = s.SMTPPassword
+		if err := mailer.SendSMTP(cfg, "[polis] "+d.Title(), d.Body()); err != nil {
+			return "", fmt.Errorf("failed to email digest: %w", err)
+		}
+		results = append(results, "emailed to "+cfg.To)
+	}
+	if j.Publish || !j.Email {
+		id, err := schedule.SaveDraft(s.DataDir, d.Markdown(), j.Publish, now)
+		if err != nil {
+			return "", err
+		}
+		results = append([]string{id}, results...)
+	}
+	return strings.Join(results, ", "), nil
+}
+
+// truncateOutput keeps the run status readable when a script is chatty.
+func truncateOutput(out string) string {
+	const max = 200
+	if len(out) > max {
+		return out[:max] + "..."
+	}
+	return out
+}
+
+// removeScheduledJob deletes a job, its run state, and its script if the
+// script lives in .polis/hooks. Reports whether the job existed.
+func (s *Server) removeScheduledJob(id string) (bool, error) {
+	if s.Config == nil {
+		return false, nil
+	}
+	for i, j := range s.Config.Schedules {
+		if j.ID != id {
+			continue
+		}
+		s.Config.Schedules = append(s.Config.Schedules[:i], s.Config.Schedules[i+1:]...)
+		if err := s.SaveConfig(); err != nil {
+			return true, err
+		}
+		if j.Action == schedule.ActionScript && filepath.ToSlash(filepath.Dir(j.Script)) == ".polis/hooks" {
+			os.Remove(filepath.Join(s.DataDir, j.Script))
+		}
+		if st, err := schedule.LoadState(s.DataDir); err == nil {
+			delete(st, id)
+			schedule.SaveState(s.DataDir, st)
+		}
+		return true, nil
+	}
+	return false, nil
+}
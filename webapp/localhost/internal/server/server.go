@@ -2,34 +2,57 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
 	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/fsutil"
+	"github.com/vdibart/polis-cli/cli-go/pkg/deploy"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/identity"
+	"github.com/vdibart/polis-cli/cli-go/pkg/idgen"
+	"github.com/vdibart/polis-cli/cli-go/pkg/logging"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
+	"github.com/vdibart/polis-cli/cli-go/pkg/protected"
+	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
+	"github.com/vdibart/polis-cli/cli-go/pkg/safehttp"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
 	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
 	"github.com/vdibart/polis-cli/cli-go/pkg/render"
+	"github.com/vdibart/polis-cli/cli-go/pkg/schedule"
+	"github.com/vdibart/polis-cli/cli-go/pkg/signing"
 	"github.com/vdibart/polis-cli/cli-go/pkg/site"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
 	"github.com/vdibart/polis-cli/cli-go/pkg/theme"
+	"github.com/vdibart/polis-cli/cli-go/pkg/thread"
 	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 )
 
@@ -72,6 +95,78 @@ type Config struct {
 
 	// Hide read items in feed/activity views (default false)
 	HideRead bool `json:"hide_read,omitempty"`
+
+	// SMTP settings for owner notification emails (password comes from SMTP_PASSWORD in .env)
+	Email *mailer.Config `json:"email,omitempty"`
+
+	// How images and embeds in remote posts are loaded (default: load directly)
+	RemoteMedia *remote.MediaPolicy `json:"remote_media,omitempty"`
+
+	// Prefetch new feed items' content into .polis/cache/content (default false)
+	Prefetch bool `json:"prefetch,omitempty"`
+
+	// Size limit of the content cache in MB (0 = remote.DefaultContentCacheBytes)
+	ContentCacheMB int `json:"content_cache_mb,omitempty"`
+
+	// Mutating API requests allowed per minute per endpoint group, and the
+	// burst size (nil = defaults; a rate of 0 disables limiting). Read
+	// through the rate_limit and rate_limit_burst settings.
+	RateLimit      *int `json:"rate_limit,omitempty"`
+	RateLimitBurst *int `json:"rate_limit_burst,omitempty"`
+
+	// Directory layout for new posts (publish.PathFormats; default YYYYMMDD).
+	// Read through the post_path setting.
+	PostPath string `json:"post_path,omitempty"`
+
+	// Refuse to publish posts that fail the accessibility audit. Read
+	// through the audit_block_publish setting.
+	AuditBlockPublish bool `json:"audit_block_publish,omitempty"`
+
+	// Special pages generated on render. Read through the pages.* settings.
+	Pages *PagesConfig `json:"pages,omitempty"`
+
+	// Post view counting. Read through the analytics.* settings.
+	Analytics *AnalyticsConfig `json:"analytics,omitempty"`
+
+	// Browser extension origins allowed cross-origin access to the widget
+	// APIs, comma separated. Read through the extension_origins setting.
+	ExtensionOrigins string `json:"extension_origins,omitempty"`
+
+	// Scheduled automations (run state lives in .polis/schedule.json)
+	Schedules []schedule.Job `json:"schedules,omitempty"`
+
+	// Publishing by email, read by polis ingest through the ingest.*
+	// settings. Kept as-is so saving the config doesn't drop it.
+	Ingest json.RawMessage `json:"ingest,omitempty"`
+
+	// Bluesky cross-posting, read through the bluesky.* settings (the app
+	// password comes from BLUESKY_APP_PASSWORD in .env)
+	Bluesky json.RawMessage `json:"bluesky,omitempty"`
+
+	// Nostr bridge, read through the nostr.* settings
+	Nostr json.RawMessage `json:"nostr,omitempty"`
+
+	// IPFS pinning, read through the ipfs.* settings
+	IPFS json.RawMessage `json:"ipfs,omitempty"`
+
+	// RFC 3161 timestamping, read through the timestamp.* settings
+	Timestamp json.RawMessage `json:"timestamp,omitempty"`
+}
+
+// PagesConfig turns the special pages render generates on and off; nil
+// fields are left to the defaults (on).
+type PagesConfig struct {
+	Archive  *bool `json:"archive,omitempty"`
+	NotFound *bool `json:"not_found,omitempty"`
+	About    *bool `json:"about,omitempty"`
+}
+
+// AnalyticsConfig turns post view counting on and sets where rendered
+// posts report views (see handleBeacon).
+type AnalyticsConfig struct {
+	Enabled   bool   `json:"enabled,omitempty"`
+	BeaconURL string `json:"beacon_url,omitempty"`
+	Listen    string `json:"listen,omitempty"` // polis daemon's /beacon address
 }
 
 // SSEEvent is a server-sent event pushed to connected clients.
@@ -86,12 +181,15 @@ type Server struct {
 	CLIThemesDir string // Path to CLI themes directory (fallback for theme snippets)
 	CLIVersion   string // CLI version for metadata files (set by bundled binary or from version.txt)
 	Config       *Config
+	Settings     *config.Config // layered settings resolved by LoadEnv
 	PrivateKey   []byte
 	PublicKey    []byte
-	Logger       *Logger
+	Logger       *logging.Logger
+	LogLevel     string // --log-level override (empty = use the log_level setting)
 	BaseURL      string // From POLIS_BASE_URL env var (runtime config, not stored in .well-known/polis)
 	DiscoveryURL string // From .env / env var DISCOVERY_SERVICE_URL (not stored in webapp-config.json)
 	DiscoveryKey string // From .env / env var DISCOVERY_SERVICE_KEY (not stored in webapp-config.json)
+	SMTPPassword string // From .env / env var SMTP_PASSWORD (not stored in webapp-config.json)
 
 	// Unified sync infrastructure
 	syncHandlers []stream.SyncHandler
@@ -100,127 +198,118 @@ type Server struct {
 	// SSE client registry
 	sseClients map[chan SSEEvent]struct{}
 	sseMu      sync.Mutex
-}
 
-// Logger handles logging to files organized by date
-type Logger struct {
-	level   int
-	logsDir string
-	file    *os.File
-	mu      sync.Mutex
-}
+	// Held while a deploy is running (one deploy at a time)
+	deployMu sync.Mutex
 
-// NewLogger creates a new logger with the given level and logs directory
-func NewLogger(level int, logsDir string) *Logger {
-	return &Logger{
-		level:   level,
-		logsDir: logsDir,
-	}
-}
+	// deployTransport overrides deploy.NewTransport (used by tests)
+	deployTransport func(*deploy.Target) (deploy.Transport, error)
 
-// getLogFile returns the log file for today, creating it if necessary
-func (l *Logger) getLogFile() (*os.File, error) {
-	if l.level == LogLevelOff {
-		return nil, nil
-	}
+	// Disk cache for the remote image proxy (created on first use)
+	imageCache     *remote.ImageCache
+	imageCacheOnce sync.Once
 
-	today := time.Now().Format("2006-01-02")
-	logPath := filepath.Join(l.logsDir, today+".log")
+	// Disk cache for remote post content (created on first use)
+	contentCache     *remote.ContentCache
+	contentCacheOnce sync.Once
+	prefetchMu       sync.Mutex // held while a prefetch runs
 
-	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll(l.logsDir, 0755); err != nil {
-		return nil, err
-	}
+	// Token buckets for mutating API requests (created on first use)
+	rateLimiter     *RateLimiter
+	rateLimiterOnce sync.Once
 
-	// Check if we need to open a new file (date changed)
-	if l.file != nil {
-		// Check if the current file is for today
-		info, err := l.file.Stat()
-		if err == nil && strings.HasPrefix(info.Name(), today) {
-			return l.file, nil
-		}
-		// Close old file
-		l.file.Close()
-	}
+	// Guards .polis/threads.json (background checks and API edits)
+	threadsMu       sync.Mutex
+	lastThreadCheck time.Time
 
-	// Open new log file
-	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, err
-	}
-	l.file = file
-	return file, nil
-}
+	// threadFetcher overrides the remote client for thread checks (used by tests)
+	threadFetcher thread.Fetcher
 
-// log writes a message to the log file
-func (l *Logger) log(level int, prefix string, format string, args ...interface{}) {
-	if l == nil || l.level < level {
-		return
-	}
+	// Followed-site move checks run in the sync loop only
+	lastMoveCheck time.Time
+	// moveFetcher overrides the remote client for move checks (used by tests)
+	moveFetcher following.MoveFetcher
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	// Subscribed follow lists are synced in the sync loop only
+	lastFollowListCheck time.Time
 
-	file, err := l.getLogFile()
-	if err != nil || file == nil {
-		return
-	}
+	// Per-route request metrics (created on first use)
+	metricsReg  *Metrics
+	metricsOnce sync.Once
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	message := fmt.Sprintf(format, args...)
-	fmt.Fprintf(file, "%s [%s] %s\n", timestamp, prefix, message)
-}
+	// Parsed copies of hot site files (created on first use)
+	fileCache     *fileCache
+	fileCacheOnce sync.Once
 
-// Info logs informational messages (level 1)
-func (l *Logger) Info(format string, args ...interface{}) {
-	l.log(LogLevelBasic, "INFO", format, args...)
-}
+	// Live-site conflict checks run in the sync loop and on request
+	lastConflictCheck time.Time
+	// conflictFetcher overrides the remote client for conflict checks (used by tests)
+	conflictFetcher conflict.Fetcher
 
-// Warn logs warning messages (level 1)
-func (l *Logger) Warn(format string, args ...interface{}) {
-	l.log(LogLevelBasic, "WARN", format, args...)
-}
+	// Serializes reconciliation runs (background and API)
+	reconcileMu   sync.Mutex
+	lastReconcile time.Time
 
-// Error logs error messages (level 1)
-func (l *Logger) Error(format string, args ...interface{}) {
-	l.log(LogLevelBasic, "ERROR", format, args...)
-}
+	// Resolved analytics.* settings, read by the /beacon endpoint
+	analytics atomic.Pointer[AnalyticsConfig]
+
+	// Lifecycle state for /readyz and graceful shutdown
+	ready        atomic.Bool
+	shuttingDown atomic.Bool
+	// Set when the process listens beyond loopback; WithAccess then refuses
+	// API requests while the site has no API users
+	networked atomic.Bool
+	syncStop     chan struct{}  // closed to stop the background sync loop
+	syncDone     chan struct{}  // closed when the background sync loop exits
+	crossposts   sync.WaitGroup // background cross-posting (see crosspostInBackground)
+
+	// Background sync counters reported by `polis daemon status`
+	statsMu sync.Mutex
+	stats   syncStats
+
+	// Outstanding /protected/ challenges (created on first use)
+	protectedChallenges *protected.ChallengeStore
+	protectedOnce       sync.Once
 
-// Debug logs debug messages (level 2)
-func (l *Logger) Debug(format string, args ...interface{}) {
-	l.log(LogLevelVerbose, "DEBUG", format, args...)
+	// protectedKeys overrides the public key lookup for signed /protected/
+	// requests (used by tests)
+	protectedKeys func(actor string) (string, error)
+
+	// mentionFetch overrides the remote client for mention scans (used by
+	// tests)
+	mentionFetch func(url string) (string, error)
+
+	// Serializes identity proof checks of followed authors
+	identityMu sync.Mutex
+
+	// The sites served with this one (serve --multi-site); nil otherwise
+	host *SiteHost
 }
 
-// Close closes the log file
-func (l *Logger) Close() {
-	if l != nil && l.file != nil {
-		l.file.Close()
+// Server logging helpers. Records go to the console and, when file logging
+// is enabled, to logs/polis.log as JSON lines (see handleLogs).
+func (s *Server) logf(level slog.Level, format string, args ...interface{}) {
+	l := logging.Default()
+	if s.Logger != nil {
+		l = s.Logger.Logger
 	}
+	l.Log(context.Background(), level, fmt.Sprintf(format, args...))
 }
 
-// Server logging helpers
 func (s *Server) LogInfo(format string, args ...interface{}) {
-	if s.Logger != nil {
-		s.Logger.Info(format, args...)
-	}
+	s.logf(slog.LevelInfo, format, args...)
 }
 
 func (s *Server) LogWarn(format string, args ...interface{}) {
-	if s.Logger != nil {
-		s.Logger.Warn(format, args...)
-	}
+	s.logf(slog.LevelWarn, format, args...)
 }
 
 func (s *Server) LogError(format string, args ...interface{}) {
-	if s.Logger != nil {
-		s.Logger.Error(format, args...)
-	}
+	s.logf(slog.LevelError, format, args...)
 }
 
 func (s *Server) LogDebug(format string, args ...interface{}) {
-	if s.Logger != nil {
-		s.Logger.Debug(format, args...)
-	}
+	s.logf(slog.LevelDebug, format, args...)
 }
 
 // GetBaseURL returns the site's base URL from POLIS_BASE_URL environment variable.
@@ -245,6 +334,17 @@ func (s *Server) DiscoveryConfig() *publish.DiscoveryConfig {
 	}
 }
 
+// streamConfig returns the site's discovery config for stream events, so
+// follow announcements carry this site's identity when several sites share
+// the process (see SiteHost).
+func (s *Server) streamConfig() *stream.DiscoveryConfig {
+	return &stream.DiscoveryConfig{
+		DiscoveryURL: s.DiscoveryURL,
+		DiscoveryKey: s.DiscoveryKey,
+		BaseURL:      s.BaseURL,
+	}
+}
+
 // RenderSite renders all pages after publish/republish operations.
 // This ensures HTML files are updated and hooks can act on the complete output.
 func (s *Server) RenderSite() error {
@@ -253,10 +353,11 @@ func (s *Server) RenderSite() error {
 
 	// Create page renderer
 	renderer, err := render.NewPageRenderer(render.PageConfig{
-		DataDir:       s.DataDir,
-		CLIThemesDir:  s.CLIThemesDir,
-		BaseURL:       baseURL,
-		RenderMarkers: false, // No markers needed for publish flow
+		DataDir:         s.DataDir,
+		CLIThemesDir:    s.CLIThemesDir,
+		BaseURL:         baseURL,
+		RenderMarkers:   false, // No markers needed for publish flow
+		DiscoveryDomain: s.GetDiscoveryDomain(),
 	})
 	if err != nil {
 		s.LogError("Failed to create renderer: %v", err)
@@ -288,27 +389,76 @@ func (s *Server) LoadConfig() {
 	s.Config = &config
 }
 
-// SaveConfig saves the webapp configuration to webapp-config.json
+// SaveConfig saves the webapp configuration to webapp-config.json. Keys
+// Config doesn't know (written by polis config set, or by a newer version)
+// are kept as they are in the file.
 func (s *Server) SaveConfig() error {
 	configPath := filepath.Join(s.DataDir, ".polis", "webapp-config.json")
 	// Clear deprecated fields before saving (don't persist them)
 	savedSubdomain := s.Config.Subdomain
 	s.Config.Subdomain = ""
-	data, err := json.MarshalIndent(s.Config, "", "  ")
+	data, err := json.Marshal(s.Config)
 	s.Config.Subdomain = savedSubdomain // Restore in memory for runtime use
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(configPath, data, 0644)
+
+	merged := map[string]json.RawMessage{}
+	if existing, err := os.ReadFile(configPath); err == nil {
+		json.Unmarshal(existing, &merged) // An unparseable file is replaced
+	}
+	for _, key := range configKeys() {
+		delete(merged, key) // Empty fields are omitted, so clear them first
+	}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(configPath, data, 0644)
+}
+
+// configKeys returns the top-level JSON keys of Config.
+func configKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// applyPageSettings sets which special pages render generates from the
+// pages.* settings.
+func applyPageSettings(cfg *config.Config) {
+	render.SpecialPages = render.SpecialPageOptions{
+		Archive:  cfg.Bool("pages.archive"),
+		NotFound: cfg.Bool("pages.not_found"),
+		About:    cfg.Bool("pages.about"),
+	}
 }
 
 // LoadKeys loads the private and public keys from the keys directory
 func (s *Server) LoadKeys() {
-	privPath := filepath.Join(s.DataDir, ".polis", "keys", "id_ed25519")
-	pubPath := filepath.Join(s.DataDir, ".polis", "keys", "id_ed25519.pub")
+	keysDir := filepath.Join(s.DataDir, ".polis", "keys")
+	pubPath := filepath.Join(keysDir, "id_ed25519.pub")
 
-	priv, err := os.ReadFile(privPath)
+	// With signing_backend=agent the private key stays in ssh-agent and
+	// PrivateKey only refers to it
+	var backend string
+	if cfg, err := config.Load(config.Options{DataDir: s.DataDir}); err == nil {
+		backend = cfg.Get("signing_backend")
+	}
+	priv, err := signing.LoadKey(keysDir, backend)
 	if err != nil {
+		if backend == signing.BackendAgent {
+			s.LogWarn("Failed to load the site key from ssh-agent: %v", err)
+		}
 		return
 	}
 	pub, err := os.ReadFile(pubPath)
@@ -328,81 +478,138 @@ func (s *Server) LoadKeys() {
 // 2. Current working directory .env (user's polis site)
 // 3. ~/.polis/.env (fallback for multi-site setups)
 func (s *Server) LoadEnv() {
-	var envPath string
-	var data []byte
-	var err error
-
-	// First, try the data directory (where webapp-config.json and keys live)
-	envPath = filepath.Join(s.DataDir, ".env")
-	data, err = os.ReadFile(envPath)
-	if err == nil {
-		log.Printf("[i] Loaded .env from data directory: %s", envPath)
+	// Same layered loader as the CLI: defaults < .env < webapp-config.json
+	// < environment. Only values set above the default layer are applied so
+	// ApplyDiscoveryDefaults can still fill in the discovery key.
+	cfg, err := config.Load(config.Options{DataDir: s.DataDir})
+	if err != nil {
+		s.LogWarn("Failed to load settings: %v", err)
+		return
+	}
+	s.Settings = cfg
+	if cfg.EnvFile != "" {
+		s.LogInfo("Loaded .env from %s", cfg.EnvFile)
 	}
 
-	// If not found, try current working directory
-	if err != nil {
-		cwd, cwdErr := os.Getwd()
-		if cwdErr == nil {
-			envPath = filepath.Join(cwd, ".env")
-			data, err = os.ReadFile(envPath)
-			if err == nil {
-				log.Printf("[i] Loaded .env from current directory: %s", envPath)
-			}
+	explicit := func(key string) string {
+		if v, ok := cfg.Value(key); ok && v.Source > config.LayerDefault {
+			return v.Value
 		}
+		return ""
 	}
 
-	// If still not found, try ~/.polis/.env as fallback
-	if err != nil {
-		homeDir, homeErr := os.UserHomeDir()
-		if homeErr == nil {
-			envPath = filepath.Join(homeDir, ".polis", ".env")
-			data, err = os.ReadFile(envPath)
-			if err == nil {
-				log.Printf("[i] Loaded .env from fallback: %s", envPath)
-			}
-		}
+	if url := explicit("discovery_url"); url != "" {
+		s.DiscoveryURL = url
+	}
+	if key := explicit("discovery_key"); key != "" {
+		s.DiscoveryKey = key
+	}
+	if pw := explicit("smtp_password"); pw != "" {
+		s.SMTPPassword = pw
 	}
 
-	// If still not found, that's fine - just return
-	if err != nil {
-		return
+	// Settings kept in package state are the primary site's alone
+	if s.host == nil || s.host.primary == s {
+		s.applyProcessSettings(cfg)
 	}
 
-	// Parse .env file (simple KEY=VALUE format, one per line)
-	env := make(map[string]string)
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	// Post view counting
+	s.applyAnalyticsSettings(cfg)
+
+	// Translations for API error messages (see WithLocale)
+	s.loadTranslations()
+
+	// Store POLIS_BASE_URL for runtime use (matches bash CLI behavior)
+	// This is the authoritative source for base_url - not stored in .well-known/polis
+	if baseURL := explicit("base_url"); baseURL != "" {
+		s.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// processSettings are the settings that apply to package-level state, and
+// so to every site in the process, rather than to one Server. Under serve
+// --multi-site they come from the primary site, and SiteHost.Add refuses
+// sites that set them differently. The S3 credentials in processEnvKeys
+// are shared the same way.
+var processSettings = []string{
+	"post_path", "audit_block_publish", "pages.archive", "pages.not_found", "pages.about",
+	"allow_local_fetch", "temp_dir", "fsync", "http_cache_mb",
+}
+
+// processEnvKeys are .env values pkg/deploy reads from the process environment.
+var processEnvKeys = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}
+
+// processSettingsDiffer returns the names of the process-wide settings a
+// and b resolve differently.
+func processSettingsDiffer(a, b *Server) []string {
+	ca, cb := a.resolvedSettings(), b.resolvedSettings()
+	var differ []string
+	for _, key := range processSettings {
+		va, vb := ca.Get(key), cb.Get(key)
+		if key == "temp_dir" {
+			va, vb = fsutil.ResolveTempDir(a.DataDir, va), fsutil.ResolveTempDir(b.DataDir, vb)
 		}
-		// Parse KEY=VALUE (handle quoted values)
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
+		if va != vb {
+			differ = append(differ, key)
 		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		// Remove surrounding quotes if present
-		if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
-			(value[0] == '\'' && value[len(value)-1] == '\'')) {
-			value = value[1 : len(value)-1]
+	}
+	for _, key := range processEnvKeys {
+		if ca.EnvFileValue(key) != cb.EnvFileValue(key) {
+			differ = append(differ, key)
 		}
-		env[key] = value
 	}
+	return differ
+}
 
-	// Apply discovery service settings from .env (single source of truth, like CLI)
-	if url := env["DISCOVERY_SERVICE_URL"]; url != "" {
-		s.DiscoveryURL = url
+// resolvedSettings returns s.Settings, loading them if LoadEnv hasn't.
+func (s *Server) resolvedSettings() *config.Config {
+	if s.Settings != nil {
+		return s.Settings
 	}
-	if key := env["DISCOVERY_SERVICE_KEY"]; key != "" {
-		s.DiscoveryKey = key
+	cfg, err := config.Load(config.Options{DataDir: s.DataDir})
+	if err != nil {
+		return &config.Config{}
 	}
+	return cfg
+}
 
-	// Store POLIS_BASE_URL for runtime use (matches bash CLI behavior)
-	// This is the authoritative source for base_url - not stored in .well-known/polis
-	if baseURL := env["POLIS_BASE_URL"]; baseURL != "" {
-		s.BaseURL = strings.TrimSuffix(baseURL, "/")
+// applyProcessSettings applies the processSettings and processEnvKeys.
+func (s *Server) applyProcessSettings(cfg *config.Config) {
+	// Temp location and fsync policy for durable writes (network mounts)
+	fsutil.TempDir = fsutil.ResolveTempDir(s.DataDir, cfg.Get("temp_dir"))
+	if policy, err := fsutil.ParseSyncPolicy(cfg.Get("fsync")); err == nil {
+		fsutil.Sync = policy
+	} else {
+		s.LogWarn("Ignoring invalid fsync setting: %v", err)
+	}
+
+	// Outbound requests go to public HTTPS addresses only, unless developing locally
+	safehttp.AllowLocal = cfg.Bool("allow_local_fetch")
+
+	// Directory layout for new posts
+	publish.PostPathFormat = cfg.Get("post_path")
+
+	// Refuse posts that fail the accessibility audit, if asked to
+	publish.ContentCheck = nil
+	if cfg.Bool("audit_block_publish") {
+		publish.ContentCheck = render.CheckPublish
+	}
+
+	// Special pages generated on render
+	applyPageSettings(cfg)
+
+	// Conditional requests for remote fetches, cached under .polis/cache/http
+	if mb := cfg.Int("http_cache_mb"); mb > 0 {
+		remote.DefaultHTTPCache = remote.NewHTTPCache(remote.HTTPCacheDir(s.DataDir), int64(mb)<<20)
+	} else {
+		remote.DefaultHTTPCache = nil
+	}
+
+	// S3 deploy credentials are read from the process environment by pkg/deploy
+	for _, key := range processEnvKeys {
+		if v := cfg.EnvFileValue(key); v != "" && os.Getenv(key) == "" {
+			os.Setenv(key, v)
+		}
 	}
 }
 
@@ -420,7 +627,7 @@ func (s *Server) ApplyDiscoveryDefaults() {
 // GetAuthorEmail returns the author email from .well-known/polis.
 // Deprecated: Use GetAuthorDomain instead. Email is private by default.
 func (s *Server) GetAuthorEmail() string {
-	wk, err := site.LoadWellKnown(s.DataDir)
+	wk, err := s.cachedWellKnown()
 	if err != nil {
 		return ""
 	}
@@ -430,7 +637,7 @@ func (s *Server) GetAuthorEmail() string {
 // GetAuthorDomain returns the domain identity from .well-known/polis.
 // Prefers the explicit Domain field, falls back to extracting from POLIS_BASE_URL.
 func (s *Server) GetAuthorDomain() string {
-	wk, err := site.LoadWellKnown(s.DataDir)
+	wk, err := s.cachedWellKnown()
 	if err == nil {
 		if d := wk.AuthorDomain(); d != "" {
 			return d
@@ -463,7 +670,7 @@ func (s *Server) GetSubdomain() string {
 
 // GetSiteTitle returns site_title from .well-known/polis, falling back to POLIS_BASE_URL if empty
 func (s *Server) GetSiteTitle() string {
-	wk, err := site.LoadWellKnown(s.DataDir)
+	wk, err := s.cachedWellKnown()
 	if err != nil {
 		// No .well-known/polis file - try config subdomain
 		if s.Config != nil && s.Config.Subdomain != "" {
@@ -490,14 +697,24 @@ func (s *Server) GetSiteTitle() string {
 	return ""
 }
 
-// ResolveSymlink follows symlinks to get the real path.
-func ResolveSymlink(path string) string {
-	resolved, err := filepath.EvalSymlinks(path)
+// GetSiteLang returns the site's default language from .well-known/polis,
+// falling back to publish.DefaultLang.
+func (s *Server) GetSiteLang() string {
+	wk, err := s.cachedWellKnown()
+	if err != nil || wk.Lang == "" {
+		return publish.DefaultLang
+	}
+	lang, err := publish.NormalizeLang(wk.Lang)
 	if err != nil {
-		// Path doesn't exist yet or other error - return original
-		return path
+		return publish.DefaultLang
 	}
-	return resolved
+	return lang
+}
+
+// ResolveSymlink follows symlinks (and Windows junctions or link files) to get the real path.
+func ResolveSymlink(path string) string {
+	// Paths that don't exist yet are returned unchanged
+	return fsutil.ResolveLink(path)
 }
 
 // FindAvailablePort finds an available port on localhost.
@@ -585,14 +802,36 @@ func (s *Server) Initialize() {
 		comment.Version = s.CLIVersion
 		metadata.Version = s.CLIVersion
 		following.Version = s.CLIVersion
+		thread.Version = s.CLIVersion
 		feed.Version = s.CLIVersion
 		site.Version = s.CLIVersion
 		notification.Version = s.CLIVersion
 		theme.Version = s.CLIVersion
 	}
 
+	s.loadSite()
+
+	// Propagate discovery config to packages that register with discovery.
+	// This ensures publish and comment packages handle registration internally.
+	publish.DiscoveryURL = s.DiscoveryURL
+	publish.DiscoveryKey = s.DiscoveryKey
+	publish.BaseURL = s.BaseURL
+	comment.DiscoveryURL = s.DiscoveryURL
+	comment.DiscoveryKey = s.DiscoveryKey
+	comment.BaseURL = s.BaseURL
+	stream.DiscoveryURL = s.DiscoveryURL
+	stream.DiscoveryKey = s.DiscoveryKey
+	stream.BaseURL = s.BaseURL
+
+	s.initLogger()
+	s.ready.Store(true)
+}
+
+// loadSite migrates old layouts and loads the site's keys and settings.
+func (s *Server) loadSite() {
 	// Migrate .polis/drafts -> .polis/posts/drafts if needed
 	s.migrateDraftsDir()
+	s.migrateDraftIDs()
 
 	// Validate the site first - only load keys/config if valid
 	validation := site.Validate(s.DataDir)
@@ -602,7 +841,7 @@ func (s *Server) Initialize() {
 
 		// Try to upgrade .well-known/polis if needed (adds missing canonical fields)
 		if _, err := site.UpgradeWellKnown(s.DataDir); err != nil {
-			log.Printf("[warning] Failed to upgrade .well-known/polis: %v", err)
+			s.LogWarn("Failed to upgrade .well-known/polis: %v", err)
 		}
 
 		// Load existing config if present
@@ -615,29 +854,49 @@ func (s *Server) Initialize() {
 
 	// Apply default discovery URL if not set by config or .env (matches CLI behavior)
 	s.ApplyDiscoveryDefaults()
+}
 
-	// Propagate discovery config to packages that register with discovery.
-	// This ensures publish and comment packages handle registration internally.
-	publish.DiscoveryURL = s.DiscoveryURL
-	publish.DiscoveryKey = s.DiscoveryKey
-	publish.BaseURL = s.BaseURL
-	comment.DiscoveryURL = s.DiscoveryURL
-	comment.DiscoveryKey = s.DiscoveryKey
-	comment.BaseURL = s.BaseURL
-	stream.DiscoveryURL = s.DiscoveryURL
-	stream.DiscoveryKey = s.DiscoveryKey
-	stream.BaseURL = s.BaseURL
-
-	// Initialize logger if log level is configured
+// initLogger sets up console and file logging. The file level comes from the
+// log_level setting (0 = off, 1 = info, 2 = debug) unless --log-level was
+// given, which also sets the console level.
+func (s *Server) initLogger() {
 	logLevel := 0
-	if s.Config != nil {
+	if s.Settings != nil {
+		logLevel = s.Settings.Int("log_level") // webapp-config.json or POLIS_LOG_LEVEL
+	} else if s.Config != nil {
 		logLevel = s.Config.LogLevel
 	}
-	if logLevel > 0 {
-		logsDir := filepath.Join(s.DataDir, "logs")
-		s.Logger = NewLogger(logLevel, logsDir)
-		s.Logger.Info("Server starting with log level %d", logLevel)
-		s.Logger.Info("Data directory: %s", s.DataDir)
+	fileLevel, fileOn := logging.LevelFromVerbosity(logLevel)
+	consoleLevel := slog.LevelInfo
+	if s.LogLevel != "" {
+		if lvl, err := logging.ParseLevel(s.LogLevel); err == nil {
+			fileLevel, fileOn, consoleLevel = lvl, true, lvl
+		} else {
+			s.LogWarn("%v", err)
+		}
+	}
+
+	opts := logging.Options{Console: os.Stderr, ConsoleLevel: consoleLevel, ConsoleTime: true}
+	if fileOn {
+		opts.Dir = filepath.Join(s.DataDir, "logs")
+		opts.Level = fileLevel
+	}
+	l, err := logging.New(opts)
+	if err != nil {
+		s.LogWarn("Failed to open log file: %v", err)
+		opts.Dir = ""
+		l, _ = logging.New(opts)
+	}
+	s.Logger = l
+	// Route warnings from pkg/* through the same handlers (the primary
+	// site's, when several are served)
+	if s.host == nil {
+		logging.SetDefault(l.Logger)
+	}
+
+	if l.FileEnabled() {
+		s.LogInfo("Server starting with log level %s", fileLevel)
+		s.LogInfo("Data directory: %s", s.DataDir)
 	}
 }
 
@@ -653,22 +912,160 @@ func (s *Server) migrateDraftsDir() {
 	if oldErr == nil && oldInfo.IsDir() && os.IsNotExist(newErr) {
 		// Create parent directory
 		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
-			log.Printf("[warning] Failed to create parent directory for drafts migration: %v", err)
+			s.LogWarn("Failed to create parent directory for drafts migration: %v", err)
 			return
 		}
 		if err := os.Rename(oldPath, newPath); err != nil {
-			log.Printf("[warning] Failed to migrate drafts directory: %v", err)
+			s.LogWarn("Failed to migrate drafts directory: %v", err)
 		} else {
-			log.Printf("[i] Migrated drafts: .polis/drafts -> .polis/posts/drafts")
+			s.LogInfo("Migrated drafts: .polis/drafts -> .polis/posts/drafts")
 		}
 	}
 }
 
+// legacyPostDraftID matches post draft IDs from before ULIDs ("draft-<unix seconds>").
+var legacyPostDraftID = regexp.MustCompile(`^draft-(\d+)$`)
+
+// migrateDraftIDs renames post and comment drafts that still use
+// timestamp-based IDs to ULIDs, keeping their original creation time.
+func (s *Server) migrateDraftIDs() {
+	draftsDir := filepath.Join(s.DataDir, ".polis", "posts", "drafts")
+	if entries, err := os.ReadDir(draftsDir); err == nil {
+		for _, entry := range entries {
+			m := legacyPostDraftID.FindStringSubmatch(strings.TrimSuffix(entry.Name(), ".md"))
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") || m == nil {
+				continue
+			}
+			secs, err := strconv.ParseInt(m[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			newName := "draft-" + idgen.NewAt(time.Unix(secs, 0)) + ".md"
+			if err := os.Rename(filepath.Join(draftsDir, entry.Name()), filepath.Join(draftsDir, newName)); err != nil {
+				s.LogWarn("Failed to migrate draft %s: %v", entry.Name(), err)
+			}
+		}
+	}
+
+	if n, err := comment.MigrateDraftIDs(s.DataDir); err != nil {
+		s.LogWarn("Failed to migrate comment draft IDs: %v", err)
+	} else if n > 0 {
+		s.LogInfo("Migrated %d comment draft IDs", n)
+	}
+}
+
 // Close cleans up server resources.
 func (s *Server) Close() {
-	if s.Logger != nil {
-		s.Logger.Close()
+	s.Logger.Close()
+}
+
+// Ready reports whether the server has finished initializing and is not
+// shutting down (see /readyz).
+func (s *Server) Ready() bool {
+	return s.ready.Load() && !s.shuttingDown.Load()
+}
+
+// beginShutdown marks the server not ready and disconnects SSE clients.
+// Safe to call more than once.
+func (s *Server) beginShutdown() {
+	s.shuttingDown.Store(true)
+	if s.sseClients != nil {
+		s.closeSSEClients()
+	}
+}
+
+// Shutdown stops background work before the process exits: it marks the
+// server not ready, disconnects SSE clients, waits for the current sync
+// cycle and any cross-posting to finish, and flushes the email outbox.
+// Returns an error if ctx expires first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.beginShutdown()
+
+	var err error
+	if s.syncStop != nil {
+		select {
+		case <-s.syncStop:
+		default:
+			close(s.syncStop)
+		}
+		select {
+		case <-s.syncDone:
+		case <-ctx.Done():
+			err = fmt.Errorf("background sync did not stop: %w", ctx.Err())
+		}
+	}
+	crossposted := make(chan struct{})
+	go func() {
+		s.crossposts.Wait()
+		close(crossposted)
+	}()
+	select {
+	case <-crossposted:
+	case <-ctx.Done():
+		if err == nil {
+			err = fmt.Errorf("cross-posting did not finish: %w", ctx.Err())
+		}
+	}
+	s.flushEmailNotifications()
+	return err
+}
+
+// RemoteMediaPolicy returns the configured media policy for remote content.
+func (s *Server) RemoteMediaPolicy() remote.MediaPolicy {
+	if s.Config == nil || s.Config.RemoteMedia == nil {
+		return remote.DefaultMediaPolicy()
+	}
+	return remote.MediaPolicy{
+		Images: remote.NormalizeMediaMode(s.Config.RemoteMedia.Images, false),
+		Embeds: remote.NormalizeMediaMode(s.Config.RemoteMedia.Embeds, true),
+	}
+}
+
+// ImageCache returns the remote image proxy cache (.polis/cache/images).
+func (s *Server) ImageCache() *remote.ImageCache {
+	s.imageCacheOnce.Do(func() {
+		s.imageCache = remote.NewImageCache(remote.ImageCacheDir(s.DataDir))
+	})
+	return s.imageCache
+}
+
+// ContentCache returns the remote content cache (.polis/cache/content),
+// sized by the content_cache_mb setting.
+func (s *Server) ContentCache() *remote.ContentCache {
+	s.contentCacheOnce.Do(func() {
+		s.contentCache = remote.NewContentCache(remote.ContentCacheDir(s.DataDir))
+		if s.Config != nil {
+			s.contentCache.MaxBytes = int64(s.Config.ContentCacheMB) << 20
+		}
+	})
+	return s.contentCache
+}
+
+// PrefetchEnabled reports whether feed refreshes prefetch item content.
+func (s *Server) PrefetchEnabled() bool {
+	return s.Config != nil && s.Config.Prefetch
+}
+
+// prefetchFeed fetches unread feed items into the content cache. It is a
+// no-op when prefetch is off or another prefetch is already running.
+func (s *Server) prefetchFeed(discoveryDomain string) {
+	if !s.PrefetchEnabled() || !s.prefetchMu.TryLock() {
+		return
 	}
+	defer s.prefetchMu.Unlock()
+
+	urls := feed.NewCacheManager(s.DataDir, discoveryDomain).UnreadURLs()
+	if n := s.ContentCache().Prefetch(urls); n > 0 {
+		s.LogInfo("Prefetched %d feed item(s)", n)
+	}
+}
+
+// newDeployTransport returns the transport for a deploy target.
+func (s *Server) newDeployTransport(t *deploy.Target) (deploy.Transport, error) {
+	if s.deployTransport != nil {
+		return s.deployTransport(t)
+	}
+	return deploy.NewTransport(t)
 }
 
 // RegisterSyncHandler adds a handler to the unified sync loop.
@@ -693,6 +1090,8 @@ func (s *Server) StartBackgroundSync() {
 	// Initialize infrastructure
 	s.syncTrigger = make(chan struct{}, 1)
 	s.sseClients = make(map[chan SSEEvent]struct{})
+	s.syncStop = make(chan struct{})
+	s.syncDone = make(chan struct{})
 
 	// Register handlers
 	s.syncHandlers = nil
@@ -701,23 +1100,52 @@ func (s *Server) StartBackgroundSync() {
 	s.RegisterSyncHandler(&followSyncHandler{server: s})
 	s.RegisterSyncHandler(&commentStatusSyncHandler{server: s})
 	s.RegisterSyncHandler(&blessingSyncHandler{server: s})
+	s.RegisterSyncHandler(&emailSyncHandler{server: s})
 
 	go func() {
+		defer close(s.syncDone)
+
 		// Initial catch-up: run legacy comment sync for pre-existing pending comments
 		s.syncCommentStatuses()
 
 		// Initial unified sync
-		s.runUnifiedSync()
+		s.recordSync(s.runUnifiedSync())
+		s.flushEmailNotifications()
+		s.runScheduledJobs(time.Now())
+		s.publishScheduledDrafts()
+		s.retryCommentDeliveries()
+		s.checkThreadReplies()
 
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
 
 		for {
 			select {
+			case <-s.syncStop:
+				return
 			case <-ticker.C:
-				s.runUnifiedSync()
+				s.recordSync(s.runUnifiedSync())
 			case <-s.syncTrigger:
-				s.runUnifiedSync()
+				s.recordSync(s.runUnifiedSync())
+			}
+			s.flushEmailNotifications()
+			s.runScheduledJobs(time.Now())
+			s.publishScheduledDrafts()
+			s.retryCommentDeliveries()
+			if time.Since(s.lastThreadCheck) >= threadCheckInterval {
+				s.checkThreadReplies()
+			}
+			if time.Since(s.lastMoveCheck) >= moveCheckInterval {
+				s.checkFollowedMoves()
+			}
+			if time.Since(s.lastFollowListCheck) >= followListCheckInterval {
+				s.syncFollowLists()
+			}
+			if time.Since(s.lastReconcile) >= reconcileInterval {
+				s.runReconcile(false)
+			}
+			if time.Since(s.lastConflictCheck) >= conflictCheckInterval {
+				s.checkSyncConflicts()
 			}
 		}
 	}()
@@ -730,12 +1158,26 @@ func (s *Server) addSSEClient(ch chan SSEEvent) {
 	s.sseClients[ch] = struct{}{}
 }
 
-// removeSSEClient unregisters a client channel.
+// removeSSEClient unregisters a client channel. Channels already closed by
+// closeSSEClients are left alone.
 func (s *Server) removeSSEClient(ch chan SSEEvent) {
 	s.sseMu.Lock()
 	defer s.sseMu.Unlock()
-	delete(s.sseClients, ch)
-	close(ch)
+	if _, ok := s.sseClients[ch]; ok {
+		delete(s.sseClients, ch)
+		close(ch)
+	}
+}
+
+// closeSSEClients disconnects every SSE client. Each stream's handler sees
+// its channel close and returns, so the HTTP server can finish draining.
+func (s *Server) closeSSEClients() {
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	for ch := range s.sseClients {
+		delete(s.sseClients, ch)
+		close(ch)
+	}
 }
 
 // broadcastSSE sends an event to all connected SSE clients.
@@ -772,157 +1214,18 @@ func (s *Server) broadcastCounts(syncResult SyncResult) {
 }
 
 // CountsPayload contains all badge counts for the frontend.
-type CountsPayload struct {
-	Posts            int `json:"posts"`
-	Drafts           int `json:"drafts"`
-	MyPending        int `json:"my_pending"`
-	MyBlessed        int `json:"my_blessed"`
-	MyDenied         int `json:"my_denied"`
-	MyCommentDrafts  int `json:"my_comment_drafts"`
-	IncomingPending  int `json:"incoming_pending"`
-	IncomingBlessed  int `json:"incoming_blessed"`
-	Feed             int `json:"feed"`
-	FeedUnread       int `json:"feed_unread"`
-	Following        int `json:"following"`
-	Followers        int `json:"followers"`
-	NotificationsUnread int `json:"notifications_unread"`
-	BlessingRequests int `json:"blessing_requests"`
-}
+type CountsPayload = site.Counts
 
 // computeAllCounts reads all badge counts from local state/filesystem.
 // No DS queries — everything comes from cached state.
 func (s *Server) computeAllCounts() CountsPayload {
-	counts := CountsPayload{}
-
-	// Posts — read from public.jsonl index (handles date-based subdirectories)
-	indexPath := filepath.Join(s.DataDir, "metadata", "public.jsonl")
-	if data, err := os.ReadFile(indexPath); err == nil {
-		for _, line := range strings.Split(string(data), "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			// Skip comment entries in public.jsonl
-			if !strings.Contains(line, `"comments/`) {
-				counts.Posts++
-			}
-		}
-	}
-
-	// Drafts
-	draftsDir := filepath.Join(s.DataDir, ".polis", "posts", "drafts")
-	if entries, err := os.ReadDir(draftsDir); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
-				counts.Drafts++
-			}
-		}
-	}
-
-	// My comments by status (pending/denied are flat in .polis/comments/<status>/)
-	for _, status := range []struct {
-		dir  string
-		dest *int
-	}{
-		{"pending", &counts.MyPending},
-		{"denied", &counts.MyDenied},
-	} {
-		dir := filepath.Join(s.DataDir, ".polis", "comments", status.dir)
-		if entries, err := os.ReadDir(dir); err == nil {
-			for _, e := range entries {
-				if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
-					*status.dest++
-				}
-			}
-		}
-	}
-
-	// My blessed comments live in public comments/YYYYMMDD/ (date-based subdirs)
-	blessedDir := filepath.Join(s.DataDir, "comments")
-	if dateDirs, err := os.ReadDir(blessedDir); err == nil {
-		for _, dd := range dateDirs {
-			if !dd.IsDir() {
-				continue
-			}
-			subDir := filepath.Join(blessedDir, dd.Name())
-			if files, err := os.ReadDir(subDir); err == nil {
-				for _, f := range files {
-					if !f.IsDir() && strings.HasSuffix(f.Name(), ".md") {
-						counts.MyBlessed++
-					}
-				}
-			}
-		}
-	}
-
-	// Comment drafts
-	commentDraftsDir := filepath.Join(s.DataDir, ".polis", "comments", "drafts")
-	if entries, err := os.ReadDir(commentDraftsDir); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
-				counts.MyCommentDrafts++
-			}
-		}
-	}
-
-	// Incoming blessed comments (on our posts)
-	blessedIndex := filepath.Join(s.DataDir, "metadata", "blessed-comments.json")
-	if data, err := os.ReadFile(blessedIndex); err == nil {
-		var idx map[string]interface{}
-		if json.Unmarshal(data, &idx) == nil {
-			if posts, ok := idx["posts"].(map[string]interface{}); ok {
-				for _, v := range posts {
-					if comments, ok := v.([]interface{}); ok {
-						counts.IncomingBlessed += len(comments)
-					}
-				}
-			}
-		}
-	}
-
-	// Following
-	followingPath := following.DefaultPath(s.DataDir)
-	if f, err := following.Load(followingPath); err == nil {
-		counts.Following = f.Count()
-	}
-
-	// Feed counts
-	discoveryDomain := s.GetDiscoveryDomain()
-	cm := feed.NewCacheManager(s.DataDir, discoveryDomain)
-	if items, err := cm.List(); err == nil {
-		counts.Feed = len(items)
-		for _, item := range items {
-			if item.ReadAt == "" {
-				counts.FeedUnread++
-			}
-		}
-	}
-
-	// Followers (from cached state)
-	store := stream.NewStore(s.DataDir, discoveryDomain)
-	var followerState stream.FollowerState
-	if store.LoadState("polis.follow", &followerState) == nil {
-		counts.Followers = followerState.Count
-	}
-
-	// Notification unread count
-	mgr := notification.NewManager(s.DataDir, discoveryDomain)
-	if unread, err := mgr.CountUnread(); err == nil {
-		counts.NotificationsUnread = unread
-	}
-
-	// Incoming pending blessing requests — read from DS-cached blessing state
-	var blessingState stream.BlessingState
-	if store.LoadState("polis.blessing", &blessingState) == nil {
-		for _, b := range blessingState.Blessings {
-			if b.Status == "pending" {
-				counts.BlessingRequests++
-				counts.IncomingPending++
-			}
-		}
-	}
-
-	return counts
+	// The hot files come from the file cache; a failed read leaves the
+	// source nil, so CountAllFrom reads it itself.
+	var src site.CountSources
+	src.PublicIndex, _ = s.cachedIndexFile(false)
+	src.Following, _ = s.cachedFollowing()
+	src.FeedItems, _ = s.cachedFeedItems()
+	return site.CountAllFrom(s.DataDir, s.GetDiscoveryDomain(), src)
 }
 
 // syncCommentStatuses checks pending comments against the discovery service
@@ -961,7 +1264,7 @@ func (s *Server) syncCommentStatuses() {
 	// Re-render if any statuses changed
 	if len(result.Blessed) > 0 || len(result.Denied) > 0 {
 		if err := s.RenderSite(); err != nil {
-			log.Printf("[warning] background comment sync render failed: %v", err)
+			s.LogWarn("background comment sync render failed: %v", err)
 		}
 		s.LogInfo("background comment sync: %d blessed, %d denied", len(result.Blessed), len(result.Denied))
 	}
@@ -1026,10 +1329,14 @@ func (s *Server) syncNotifications() {
 		mutedDomains[d] = true
 	}
 
+	mutedThreads, mutedKeywords := s.feedMutes(discoveryDomain)
 	handler := &stream.NotificationHandler{
-		MyDomain:     myDomain,
-		Rules:        rules,
-		MutedDomains: mutedDomains,
+		MyDomain:      myDomain,
+		Rules:         rules,
+		MutedDomains:  mutedDomains,
+		MutedThreads:  mutedThreads,
+		MutedKeywords: mutedKeywords,
+		SiteTitles:    author.LoadFollowerProfiles(s.DataDir, discoveryDomain).SiteTitles(),
 	}
 
 	// Get shared cursor
@@ -1162,18 +1469,18 @@ func cursorGreater(a, b string) bool {
 // from followed authors and merges them into the feed cache.
 func (s *Server) syncFeed() {
 	if s.DiscoveryURL == "" || s.DiscoveryKey == "" {
-		log.Printf("[feed-sync] skip: no discovery config (url=%q key=%d chars)", s.DiscoveryURL, len(s.DiscoveryKey))
+		s.LogDebug("feed-sync: skip: no discovery config (url=%q key=%d chars)", s.DiscoveryURL, len(s.DiscoveryKey))
 		return
 	}
 	baseURL := s.GetBaseURL()
 	if baseURL == "" {
-		log.Printf("[feed-sync] skip: empty BaseURL (dataDir=%s)", s.DataDir)
+		s.LogDebug("feed-sync: skip: empty BaseURL (dataDir=%s)", s.DataDir)
 		return
 	}
 
 	myDomain := extractDomainFromURL(baseURL)
 	if myDomain == "" {
-		log.Printf("[feed-sync] skip: could not extract domain from %s", baseURL)
+		s.LogDebug("feed-sync: skip: could not extract domain from %s", baseURL)
 		return
 	}
 
@@ -1183,11 +1490,11 @@ func (s *Server) syncFeed() {
 	followingPath := following.DefaultPath(s.DataDir)
 	f, err := following.Load(followingPath)
 	if err != nil {
-		log.Printf("[feed-sync] skip: following load error: %v", err)
+		s.LogDebug("feed-sync: skip: following load error: %v", err)
 		return
 	}
 	if f.Count() == 0 {
-		log.Printf("[feed-sync] skip: following list is empty (path=%s)", followingPath)
+		s.LogDebug("feed-sync: skip: following list is empty (path=%s)", followingPath)
 		return
 	}
 
@@ -1199,7 +1506,7 @@ func (s *Server) syncFeed() {
 		}
 	}
 	if len(domains) == 0 {
-		log.Printf("[feed-sync] skip: no valid domains extracted from %d following entries", f.Count())
+		s.LogDebug("feed-sync: skip: no valid domains extracted from %d following entries", f.Count())
 		return
 	}
 
@@ -1212,15 +1519,15 @@ func (s *Server) syncFeed() {
 	typeFilter := "polis.post.published,polis.post.republished,polis.comment.published,polis.comment.republished"
 	actorFilter := discovery.JoinDomains(domains)
 
-	log.Printf("[feed-sync] querying stream: myDomain=%s actors=%s cursor=%q dsURL=%s", myDomain, actorFilter, cursor, s.DiscoveryURL)
+	s.LogDebug("feed-sync: querying stream: myDomain=%s actors=%s cursor=%q dsURL=%s", myDomain, actorFilter, cursor, s.DiscoveryURL)
 
 	result, err := client.StreamQuery(cursor, 1000, typeFilter, actorFilter, "")
 	if err != nil {
-		log.Printf("[feed-sync] stream query failed: %v", err)
+		s.LogWarn("feed-sync: stream query failed: %v", err)
 		return
 	}
 
-	log.Printf("[feed-sync] stream returned %d events, cursor=%q, hasMore=%v", len(result.Events), result.Cursor, result.HasMore)
+	s.LogDebug("feed-sync: stream returned %d events, cursor=%q, hasMore=%v", len(result.Events), result.Cursor, result.HasMore)
 
 	// Transform events to feed items
 	handler := &feed.FeedHandler{
@@ -1232,15 +1539,18 @@ func (s *Server) syncFeed() {
 	}
 
 	items := handler.Process(result.Events)
-	log.Printf("[feed-sync] processed %d events -> %d feed items", len(result.Events), len(items))
+	s.LogDebug("feed-sync: processed %d events -> %d feed items", len(result.Events), len(items))
 
 	// Merge into cache
 	if len(items) > 0 {
 		newCount, err := cm.MergeItems(items)
 		if err != nil {
-			log.Printf("[feed-sync] merge failed: %v", err)
+			s.LogWarn("feed-sync: merge failed: %v", err)
 		} else {
-			log.Printf("[feed-sync] merged %d new items (total cached: check JSONL)", newCount)
+			s.LogDebug("feed-sync: merged %d new items (total cached: check JSONL)", newCount)
+			if newCount > 0 {
+				go s.prefetchFeed(s.GetDiscoveryDomain())
+			}
 		}
 	}
 
@@ -1248,6 +1558,23 @@ func (s *Server) syncFeed() {
 	if result.Cursor != "" {
 		_ = cm.SetCursor(result.Cursor)
 	}
+
+	go s.refreshIdentities(domains)
+}
+
+// refreshIdentities re-checks the identity proofs of followed authors not
+// checked within a day, for the feed's verified badges.
+func (s *Server) refreshIdentities(domains []string) {
+	s.identityMu.Lock()
+	defer s.identityMu.Unlock()
+	checked, err := identity.Refresh(s.DataDir, domains)
+	if err != nil {
+		s.LogWarn("identity refresh failed: %v", err)
+		return
+	}
+	if checked > 0 {
+		s.LogDebug("identity: checked proofs of %d sites", checked)
+	}
 }
 
 // Handler returns an http.Handler for this Server's API routes.
@@ -1259,9 +1586,28 @@ func (s *Server) Handler() http.Handler {
 	return mux
 }
 
+// siteHandler returns everything Run serves for the site: the API and the
+// web UI, behind the locale, CORS, access, and metrics middleware.
+func (s *Server) siteHandler(webFS fs.FS) http.Handler {
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+
+	// Static files from embedded filesystem with SPA fallback
+	mux.Handle("/", spaHandler(webFS))
+
+	return s.WithLocale(s.WithCORS(s.WithAccess(s.WithMetrics(mux))))
+}
+
+// ShutdownTimeout bounds how long Run waits for in-flight requests and the
+// background sync loop after SIGINT or SIGTERM.
+const ShutdownTimeout = 15 * time.Second
+
 // RunOptions contains optional configuration for the server.
 type RunOptions struct {
 	CLIVersion string // CLI version for metadata (empty = use package default)
+	LogLevel   string // --log-level (debug, info, warn, error); empty = use log_level setting
+	Listen     string // --listen address; empty = a free port on localhost
+	MultiSite  bool   // --multi-site: also serve every registered workspace
 }
 
 // Run starts the HTTP server with the given embedded filesystem.
@@ -1274,7 +1620,7 @@ func Run(webFS fs.FS, dataDir string, opts ...RunOptions) {
 	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
 		// Create just the data directory (not the full structure)
 		if err := os.MkdirAll(dataDir, 0755); err != nil {
-			log.Printf("[warning] Failed to create data directory: %v", err)
+			logging.Warn("Failed to create data directory", "err", err)
 		}
 	}
 
@@ -1293,41 +1639,110 @@ func Run(webFS fs.FS, dataDir string, opts ...RunOptions) {
 	if len(opts) > 0 && opts[0].CLIVersion != "" {
 		server.CLIVersion = opts[0].CLIVersion
 	}
+	if len(opts) > 0 {
+		server.LogLevel = opts[0].LogLevel
+	}
 	server.Initialize()
 	defer server.Close()
 
 	// Start background sync (notifications + feed)
 	server.StartBackgroundSync()
 
-	// Find available port
-	port, err := FindAvailablePort()
-	if err != nil {
-		log.Fatal("Failed to find available port:", err)
+	var handler http.Handler = server.siteHandler(webFS)
+	var host *SiteHost
+	if len(opts) > 0 && opts[0].MultiSite {
+		host = NewSiteHost(server, webFS)
+		if err := host.LoadWorkspaces(); err != nil {
+			log.Fatal("Failed to load workspaces:", err)
+		}
+		handler = host
 	}
 
-	// Setup routes
-	mux := http.NewServeMux()
-	SetupRoutes(mux, server)
-
-	// Static files from embedded filesystem with SPA fallback
-	mux.Handle("/", spaHandler(webFS))
+	// Listen where asked, or on a free port on localhost. Anyone who can
+	// reach a network address could run the site, so one needs API users.
+	var listen string
+	if len(opts) > 0 {
+		listen = opts[0].Listen
+	}
+	if listen == "" && server.Settings != nil {
+		listen = server.Settings.Get("listen") // .env or POLIS_LISTEN
+	}
+	addr := listen
+	if addr == "" {
+		port, err := FindAvailablePort()
+		if err != nil {
+			log.Fatal("Failed to find available port:", err)
+		}
+		addr = fmt.Sprintf("localhost:%d", port)
+	} else if !loopbackAddr(addr) && !server.accessEnabled() {
+		log.Fatalf("Listening on %s needs API users first: polis access add <name> --role admin (or set POLIS_ADMIN_TOKEN)", addr)
+	} else if !loopbackAddr(addr) && host != nil {
+		if id := host.openSite(); id != "" {
+			log.Fatalf("Listening on %s needs API users for every site first: site %s has none (or set POLIS_ADMIN_TOKEN)", addr, id)
+		}
+		host.setNetworked()
+	} else if !loopbackAddr(addr) {
+		server.networked.Store(true)
+	}
 
-	addr := fmt.Sprintf("localhost:%d", port)
 	url := fmt.Sprintf("http://%s", addr)
 
 	fmt.Printf("[i] Starting polis server...\n")
 	fmt.Printf("[i] Listening on %s\n", url)
 	fmt.Printf("[i] Data directory: %s\n", dataDir)
+	if host != nil {
+		for _, site := range host.Sites() {
+			fmt.Printf("[i] Site %s: %s\n", site.ID, site.Path)
+		}
+	}
 
 	// Open browser after a short delay
+	if listen == "" {
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			OpenBrowser(url)
+		}()
+	}
+
+	httpServer := &http.Server{Addr: addr, Handler: handler}
+	// SSE streams never finish on their own; close them as soon as
+	// Shutdown starts so draining only waits on ordinary requests
+	httpServer.RegisterOnShutdown(server.beginShutdown)
+	if host != nil {
+		httpServer.RegisterOnShutdown(host.beginShutdown)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
 	go func() {
-		time.Sleep(500 * time.Millisecond)
-		OpenBrowser(url)
+		errCh <- httpServer.ListenAndServe()
 	}()
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		log.Fatal("Server error:", err)
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server error:", err)
+		}
+	case <-ctx.Done():
+	}
+	// A second signal during shutdown kills the process immediately
+	stop()
+
+	fmt.Printf("[i] Shutting down...\n")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		server.LogWarn("HTTP shutdown: %v", err)
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.LogWarn("Shutdown: %v", err)
+	}
+	if host != nil {
+		host.Shutdown(shutdownCtx)
 	}
+	fmt.Printf("[✓] Server stopped\n")
 }
 
 // spaHandler serves static files from the embedded filesystem, falling back
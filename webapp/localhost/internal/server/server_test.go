@@ -295,6 +295,31 @@ func TestGetSiteTitle_NoWellKnownPolis_NoConfig(t *testing.T) {
 // Sync Helper Tests
 // ============================================================================
 
+func TestGetSiteTitle_ReloadsChangedWellKnownPolis(t *testing.T) {
+	dataDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, ".well-known"), 0755)
+	wellKnownPath := filepath.Join(dataDir, ".well-known", "polis")
+	os.WriteFile(wellKnownPath, []byte(`{"site_title": "First"}`), 0644)
+
+	server := &Server{DataDir: dataDir}
+	if title := server.GetSiteTitle(); title != "First" {
+		t.Fatalf("Expected 'First', got '%s'", title)
+	}
+	if title := server.GetSiteTitle(); title != "First" {
+		t.Fatalf("Expected cached 'First', got '%s'", title)
+	}
+	if hits := server.files().hits.Load(); hits != 1 {
+		t.Errorf("Expected 1 cache hit, got %d", hits)
+	}
+
+	// A write from outside the server (e.g. the CLI) is picked up on the
+	// next read.
+	os.WriteFile(wellKnownPath, []byte(`{"site_title": "Second title"}`), 0644)
+	if title := server.GetSiteTitle(); title != "Second title" {
+		t.Errorf("Expected reloaded 'Second title', got '%s'", title)
+	}
+}
+
 func TestFirstNonEmptyString(t *testing.T) {
 	payload := map[string]interface{}{
 		"comment_url": "https://example.com/comments/20260222/abc.md",
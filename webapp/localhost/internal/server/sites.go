@@ -0,0 +1,294 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
+)
+
+// Multi-site serving (serve --multi-site) runs every registered workspace
+// in one process. Each site is its own Server, with its own keys, settings,
+// API users, sync loop, and SSE clients; a request picks one with the
+// X-Polis-Site header or an /api/sites/{id}/ path prefix, and goes to the
+// site serve was started on otherwise. The remote post and image caches are
+// shared, so an author followed from several sites is fetched once.
+
+// SiteHeader names the site a request is for, by workspace name.
+const SiteHeader = "X-Polis-Site"
+
+// SiteHost serves several sites from one process.
+type SiteHost struct {
+	primary   *Server
+	primaryID string
+	webFS     fs.FS
+
+	mu    sync.RWMutex
+	sites map[string]*hostedSite
+	order []string // site IDs, the primary first
+
+	// networked is set once the process listens beyond loopback; every site
+	// then needs API users, as Run checks at startup
+	networked bool
+
+	// startSync overrides StartBackgroundSync for added sites (used by tests)
+	startSync func(*Server)
+}
+
+// errOpenSite is returned by Add for a site anyone on the network could use.
+var errOpenSite = errors.New("the site has no API users, and the server listens on a network address (polis access add <name> --role admin)")
+
+// errSharedSettings is returned by Add for a site whose process-wide
+// settings (see processSettings) differ from the primary site's.
+var errSharedSettings = errors.New("the site's settings differ from the primary site's, and are shared by every site in the process")
+
+type hostedSite struct {
+	server  *Server
+	handler http.Handler
+}
+
+// SiteInfo describes a site served by the process.
+type SiteInfo struct {
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Primary bool   `json:"primary"`
+}
+
+// NewSiteHost returns a host serving primary, an initialized server, under
+// its workspace name (or one derived from its directory).
+func NewSiteHost(primary *Server, webFS fs.FS) *SiteHost {
+	h := &SiteHost{primary: primary, webFS: webFS, sites: map[string]*hostedSite{}}
+	primary.host = h
+
+	id := workspace.NameFor(primary.DataDir)
+	if reg, err := workspace.Load(); err == nil {
+		if ws, ok := reg.Find(primary.DataDir); ok {
+			id = ws.Name
+		} else {
+			for base, i := id, 2; ; i++ {
+				if _, taken := reg.Get(id); !taken {
+					break
+				}
+				id = fmt.Sprintf("%s-%d", base, i)
+			}
+		}
+	}
+	h.primaryID = id
+	h.sites[id] = &hostedSite{server: primary, handler: primary.siteHandler(webFS)}
+	h.order = append(h.order, id)
+	return h
+}
+
+// LoadWorkspaces starts serving every registered workspace besides the
+// primary site. Workspaces that aren't valid sites are skipped.
+func (h *SiteHost) LoadWorkspaces() error {
+	reg, err := workspace.Load()
+	if err != nil {
+		return err
+	}
+	for _, ws := range reg.Workspaces {
+		if ws.Path == h.primary.DataDir {
+			continue
+		}
+		if err := h.Add(ws.Name, ws.Path); err != nil {
+			h.primary.LogWarn("Not serving workspace %s: %v", ws.Name, err)
+		}
+	}
+	return nil
+}
+
+// Add starts serving the site in dir as id, with its own sync loop. Sites
+// that set process-wide settings differently from the primary are refused,
+// and once the host is networked, so are sites without API users.
+func (h *SiteHost) Add(id, dir string) error {
+	if err := validSite(dir); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.sites[id]; ok {
+		return fmt.Errorf("site %s is already served", id)
+	}
+
+	s := NewServer(dir, h.primary.CLIThemesDir)
+	s.CLIVersion = h.primary.CLIVersion
+	s.LogLevel = h.primary.LogLevel
+	s.host = h
+	s.loadSite()
+	if differ := processSettingsDiffer(h.primary, s); len(differ) > 0 {
+		return fmt.Errorf("%w: %s", errSharedSettings, strings.Join(differ, ", "))
+	}
+	if h.networked && !s.accessEnabled() {
+		return errOpenSite
+	}
+	s.networked.Store(h.networked)
+	s.initLogger()
+	// Remote posts and images are the same whichever site follows them
+	s.imageCacheOnce.Do(func() { s.imageCache = h.primary.ImageCache() })
+	s.contentCacheOnce.Do(func() { s.contentCache = h.primary.ContentCache() })
+	s.ready.Store(true)
+	if h.startSync != nil {
+		h.startSync(s)
+	} else {
+		s.StartBackgroundSync()
+	}
+
+	h.sites[id] = &hostedSite{server: s, handler: s.siteHandler(h.webFS)}
+	h.order = append(h.order, id)
+	h.primary.LogInfo("Serving site %s: %s", id, dir)
+	return nil
+}
+
+// Remove stops serving the site id. The primary site can't be removed.
+func (h *SiteHost) Remove(ctx context.Context, id string) {
+	h.mu.Lock()
+	site, ok := h.sites[id]
+	if !ok || site.server == h.primary {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.sites, id)
+	for i, o := range h.order {
+		if o == id {
+			h.order = append(h.order[:i:i], h.order[i+1:]...)
+			break
+		}
+	}
+	h.mu.Unlock()
+
+	if err := site.server.Shutdown(ctx); err != nil {
+		site.server.LogWarn("Shutdown: %v", err)
+	}
+	site.server.Close()
+}
+
+// Sites lists the sites served, the primary first.
+func (h *SiteHost) Sites() []SiteInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	infos := make([]SiteInfo, 0, len(h.order))
+	for _, id := range h.order {
+		s := h.sites[id].server
+		infos = append(infos, SiteInfo{ID: id, Path: s.DataDir, Title: s.GetSiteTitle(), Primary: s == h.primary})
+	}
+	return infos
+}
+
+// setNetworked records that the process listens on a network address.
+func (h *SiteHost) setNetworked() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.networked = true
+	for _, site := range h.sites {
+		site.server.networked.Store(true)
+	}
+}
+
+// openSite returns the ID of a site without API users, or "" if every site
+// has them.
+func (h *SiteHost) openSite() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, id := range h.order {
+		if !h.sites[id].server.accessEnabled() {
+			return id
+		}
+	}
+	return ""
+}
+
+func (h *SiteHost) site(id string) *hostedSite {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.sites[id]
+}
+
+// others returns the servers besides the primary.
+func (h *SiteHost) others() []*Server {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var servers []*Server
+	for _, id := range h.order {
+		if s := h.sites[id].server; s != h.primary {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// beginShutdown disconnects the SSE clients of every site but the primary,
+// which Run shuts down itself.
+func (h *SiteHost) beginShutdown() {
+	for _, s := range h.others() {
+		s.beginShutdown()
+	}
+}
+
+// Shutdown stops the background work of every site but the primary.
+func (h *SiteHost) Shutdown(ctx context.Context) {
+	for _, s := range h.others() {
+		if err := s.Shutdown(ctx); err != nil {
+			s.LogWarn("Shutdown: %v", err)
+		}
+		s.Close()
+	}
+}
+
+// siteFromRequest returns the site a request names, and the path to serve
+// it at: /api/sites/{id}/posts is /api/posts of site id (likewise under
+// /api/v1/), and the X-Polis-Site header names one for any path.
+func siteFromRequest(r *http.Request) (id, path string) {
+	for _, prefix := range []string{"/api/", versionedAPIPrefix} {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix+"sites/")
+		if !ok {
+			continue
+		}
+		if id, tail, ok := strings.Cut(rest, "/"); ok && id != "" {
+			return id, prefix + tail
+		}
+	}
+	return r.Header.Get(SiteHeader), r.URL.Path
+}
+
+// ServeHTTP passes the request to the site it names, or the primary site.
+func (h *SiteHost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, path := siteFromRequest(r)
+	if id == "" {
+		id = h.primaryID
+	}
+	site := h.site(id)
+	if site == nil {
+		http.Error(w, "Unknown site: "+id, http.StatusNotFound)
+		return
+	}
+	if path != r.URL.Path {
+		r = r.Clone(r.Context())
+		r.URL.Path, r.URL.RawPath = path, ""
+	}
+	site.handler.ServeHTTP(w, r)
+}
+
+// handleSites lists the sites this process serves.
+// GET /api/sites
+func (s *Server) handleSites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sites := []SiteInfo{{Path: s.DataDir, Title: s.GetSiteTitle(), Primary: true}}
+	if s.host != nil {
+		sites = s.host.Sites()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"multi_site": s.host != nil,
+		"sites":      sites,
+	})
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/access"
+	"github.com/vdibart/polis-cli/cli-go/pkg/config"
+	"github.com/vdibart/polis-cli/cli-go/pkg/publish"
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
+)
+
+func TestSiteFromRequest(t *testing.T) {
+	tests := []struct {
+		path, header     string
+		wantID, wantPath string
+	}{
+		{"/api/posts", "", "", "/api/posts"},
+		{"/api/posts", "notes", "notes", "/api/posts"},
+		{"/api/sites/notes/posts", "", "notes", "/api/posts"},
+		{"/api/v1/sites/notes/feed/counts", "", "notes", "/api/v1/feed/counts"},
+		{"/api/sites/notes/posts", "blog", "notes", "/api/posts"},
+		{"/api/sites", "", "", "/api/sites"},
+		{"/api/sites/notes", "", "", "/api/sites/notes"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set(SiteHeader, tt.header)
+		}
+		id, path := siteFromRequest(req)
+		if id != tt.wantID || path != tt.wantPath {
+			t.Errorf("siteFromRequest(%s, %q) = %q, %q; want %q, %q", tt.path, tt.header, id, path, tt.wantID, tt.wantPath)
+		}
+	}
+}
+
+func TestSiteHost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	primary := newConfiguredServer(t)
+	other := newConfiguredServer(t)
+	if _, err := workspace.Add("blog", primary.DataDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workspace.Add("notes", other.DataDir); err != nil {
+		t.Fatal(err)
+	}
+	token, err := access.Add(other.DataDir, "alex", access.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewSiteHost(primary, fstest.MapFS{"index.html": {Data: []byte("ui")}})
+	h.startSync = func(*Server) {}
+	if err := h.LoadWorkspaces(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+
+	sites := h.Sites()
+	if len(sites) != 2 || sites[0].ID != "blog" || !sites[0].Primary || sites[1].ID != "notes" || sites[1].Path != other.DataDir {
+		t.Fatalf("Sites() = %+v", sites)
+	}
+
+	do := func(target, site, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if site != "" {
+			req.Header.Set(SiteHeader, site)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Each site has its own API users: notes has one, blog none
+	if w := do("/api/validate", "", ""); w.Code != http.StatusOK {
+		t.Errorf("primary site: got %d, want 200", w.Code)
+	}
+	if w := do("/api/sites/notes/validate", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("notes without a token: got %d, want 401", w.Code)
+	}
+	if w := do("/api/validate", "notes", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("notes by header without a token: got %d, want 401", w.Code)
+	}
+	if w := do("/api/v1/sites/notes/validate", "", token); w.Code != http.StatusOK {
+		t.Errorf("notes with its token: got %d, want 200", w.Code)
+	}
+	if w := do("/api/sites/nowhere/validate", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown site: got %d, want 404", w.Code)
+	}
+
+	w := do("/api/sites", "", "")
+	var list struct {
+		MultiSite bool       `json:"multi_site"`
+		Sites     []SiteInfo `json:"sites"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if !list.MultiSite || len(list.Sites) != 2 {
+		t.Errorf("GET /api/sites = %s", w.Body.String())
+	}
+
+	// Switching sites makes no sense when all of them are served
+	if w := do("/api/sites/blog/validate", "", ""); w.Code != http.StatusOK {
+		t.Errorf("primary by id: got %d, want 200", w.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/workspaces/notes/use", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict || primary.DataDir == other.DataDir {
+		t.Errorf("use in multi-site mode: got %d, want 409", rr.Code)
+	}
+
+	h.Remove(context.Background(), "notes")
+	if w := do("/api/sites/notes/validate", "", token); w.Code != http.StatusNotFound {
+		t.Errorf("removed site: got %d, want 404", w.Code)
+	}
+}
+
+func TestSiteHost_NetworkedRefusesOpenSites(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	primary := newConfiguredServer(t)
+	open := newConfiguredServer(t)
+	locked := newConfiguredServer(t)
+	if _, err := access.Add(locked.DataDir, "alex", access.Admin); err != nil {
+		t.Fatal(err)
+	}
+	token, err := access.Add(primary.DataDir, "alex", access.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewSiteHost(primary, fstest.MapFS{"index.html": {Data: []byte("ui")}})
+	h.startSync = func(*Server) {}
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+	h.setNetworked()
+
+	add := func(name, dir string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/workspaces", jsonBody(t, map[string]string{"name": name, "path": dir}))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := add("open", open.DataDir); code != http.StatusConflict {
+		t.Errorf("site without API users: got %d, want 409", code)
+	}
+	if reg, _ := workspace.Load(); len(reg.Workspaces) != 0 {
+		t.Errorf("refused site stayed registered: %+v", reg.Workspaces)
+	}
+	if code := add("locked", locked.DataDir); code != http.StatusCreated {
+		t.Errorf("site with API users: got %d, want 201", code)
+	}
+	if sites := h.Sites(); len(sites) != 2 || sites[1].ID != "locked" {
+		t.Errorf("Sites() = %+v", sites)
+	}
+}
+
+func TestSiteHost_RefusesDifferentSharedSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	format := publish.PostPathFormat
+	t.Cleanup(func() { publish.PostPathFormat = format })
+
+	configure := func(postPath, awsKey string) *Server {
+		s := newConfiguredServer(t)
+		if _, err := config.Set(s.DataDir, "post_path", postPath); err != nil {
+			t.Fatal(err)
+		}
+		env := "AWS_ACCESS_KEY_ID=" + awsKey + "\nAWS_SECRET_ACCESS_KEY=secret\n"
+		if err := os.WriteFile(filepath.Join(s.DataDir, ".env"), []byte(env), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	primary := configure("YYYY/MM", "AKIAPRIMARY")
+	primary.LoadEnv()
+	otherPath := configure("flat", "AKIAPRIMARY")
+	otherKeys := configure("YYYY/MM", "AKIAOTHER")
+	same := configure("YYYY/MM", "AKIAPRIMARY")
+
+	h := NewSiteHost(primary, fstest.MapFS{"index.html": {Data: []byte("ui")}})
+	h.startSync = func(*Server) {}
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+
+	add := func(name, dir string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces", jsonBody(t, map[string]string{"name": name, "path": dir})))
+		return rr
+	}
+	if rr := add("flat", otherPath.DataDir); rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "post_path") {
+		t.Errorf("site with another post path: got %d %q, want 409 naming post_path", rr.Code, rr.Body.String())
+	}
+	if rr := add("keys", otherKeys.DataDir); rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "AWS_ACCESS_KEY_ID") {
+		t.Errorf("site with other S3 credentials: got %d %q, want 409 naming AWS_ACCESS_KEY_ID", rr.Code, rr.Body.String())
+	}
+	if rr := add("keys", otherKeys.DataDir); strings.Contains(rr.Body.String(), "AKIA") {
+		t.Errorf("refusal leaks credentials: %q", rr.Body.String())
+	}
+	if publish.PostPathFormat != "YYYY/MM" || os.Getenv("AWS_ACCESS_KEY_ID") != "AKIAPRIMARY" {
+		t.Errorf("refused sites changed the process settings: post path %q, key %q", publish.PostPathFormat, os.Getenv("AWS_ACCESS_KEY_ID"))
+	}
+	if rr := add("same", same.DataDir); rr.Code != http.StatusCreated {
+		t.Errorf("site with the primary's settings: got %d %q, want 201", rr.Code, rr.Body.String())
+	}
+	if sites := h.Sites(); len(sites) != 2 || sites[1].ID != "same" {
+		t.Errorf("Sites() = %+v", sites)
+	}
+
+	// Special pages are process-wide too, so only the primary sets them
+	req := httptest.NewRequest(http.MethodPost, "/api/sites/same/settings/pages", jsonBody(t, map[string]bool{"archive": false}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("pages on a secondary site: got %d, want 409", rr.Code)
+	}
+}
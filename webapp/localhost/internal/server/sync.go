@@ -2,21 +2,28 @@ package server
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/vdibart/polis-cli/cli-go/pkg/author"
+	"github.com/vdibart/polis-cli/cli-go/pkg/blessing"
 	"github.com/vdibart/polis-cli/cli-go/pkg/comment"
+	"github.com/vdibart/polis-cli/cli-go/pkg/conflict"
 	"github.com/vdibart/polis-cli/cli-go/pkg/discovery"
 	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
 	"github.com/vdibart/polis-cli/cli-go/pkg/following"
+	"github.com/vdibart/polis-cli/cli-go/pkg/followlist"
 	"github.com/vdibart/polis-cli/cli-go/pkg/hooks"
+	"github.com/vdibart/polis-cli/cli-go/pkg/mailer"
 	"github.com/vdibart/polis-cli/cli-go/pkg/metadata"
 	"github.com/vdibart/polis-cli/cli-go/pkg/notification"
+	"github.com/vdibart/polis-cli/cli-go/pkg/reconcile"
 	"github.com/vdibart/polis-cli/cli-go/pkg/remote"
 	"github.com/vdibart/polis-cli/cli-go/pkg/stream"
+	"github.com/vdibart/polis-cli/cli-go/pkg/thread"
 	polisurl "github.com/vdibart/polis-cli/cli-go/pkg/url"
 )
 
@@ -56,11 +63,19 @@ func (s *Server) runUnifiedSync() SyncResult {
 
 	// Collect events from targeted queries (2-3 DS calls with a shared cursor)
 	allEvents, newCursor := s.queryStreamEvents(myDomain, cursor)
+
+	// Scan posts and comments by authors we don't follow for links to us.
+	// Best effort: this query doesn't move the cursor.
+	result.NewNotifications += s.syncMentions(myDomain, cursor)
+
 	if len(allEvents) == 0 {
 		// Still update cursor timestamp even if no new events
 		if newCursor != "" && cursorGreater(newCursor, cursor) {
 			_ = store.SetCursor("polis.sync", newCursor)
 		}
+		if result.NewNotifications > 0 {
+			s.broadcastCounts(result)
+		}
 		return result
 	}
 
@@ -83,7 +98,7 @@ func (s *Server) runUnifiedSync() SyncResult {
 
 		switch h.Name() {
 		case "notifications":
-			result.NewNotifications = hr.NewItems
+			result.NewNotifications += hr.NewItems
 		case "feed":
 			result.NewFeedItems = hr.NewItems
 		case "followers":
@@ -101,7 +116,7 @@ func (s *Server) runUnifiedSync() SyncResult {
 	// Single RenderSite if any files changed
 	if result.FilesChanged {
 		if err := s.RenderSite(); err != nil {
-			log.Printf("[warning] unified sync render failed: %v", err)
+			s.LogWarn("unified sync render failed: %v", err)
 		}
 	}
 
@@ -287,11 +302,15 @@ func (h *notificationSyncHandler) Process(events []discovery.StreamEvent) stream
 		}
 	}
 
+	mutedThreads, mutedKeywords := s.feedMutes(discoveryDomain)
 	handler := &stream.NotificationHandler{
 		MyDomain:        myDomain,
 		Rules:           rules,
 		MutedDomains:    mutedDomains,
+		MutedThreads:    mutedThreads,
+		MutedKeywords:   mutedKeywords,
 		FollowedDomains: followedDomains,
+		SiteTitles:      author.LoadFollowerProfiles(s.DataDir, discoveryDomain).SiteTitles(),
 	}
 
 	entries := handler.Process(events)
@@ -381,10 +400,27 @@ func (h *feedSyncHandler) Process(events []discovery.StreamEvent) stream.Handler
 	if err != nil {
 		return stream.HandlerResult{Error: err}
 	}
+	if newCount > 0 {
+		go s.prefetchFeed(discoveryDomain)
+	}
 
 	return stream.HandlerResult{NewItems: newCount}
 }
 
+// feedMutes returns the feed's muted threads and keywords, which also
+// silence notifications.
+func (s *Server) feedMutes(discoveryDomain string) (map[string]bool, []string) {
+	f, err := feed.NewCacheManager(s.DataDir, discoveryDomain).LoadFilters()
+	if err != nil {
+		return nil, nil
+	}
+	threads := make(map[string]bool, len(f.MutedThreads))
+	for _, u := range f.MutedThreads {
+		threads[u] = true
+	}
+	return threads, f.MutedKeywords
+}
+
 // --- Follow Sync Handler ---
 
 type followSyncHandler struct {
@@ -528,6 +564,33 @@ func (h *blessingSyncHandler) Process(events []discovery.StreamEvent) stream.Han
 		s.LogInfo("blessing sync: stored auto-blessed comment %s", commentURL)
 	}
 
+	// Honor retractions of comments on our posts. HonorRetraction verifies
+	// the event against the commenter's published key before anything is removed.
+	for _, evt := range events {
+		if evt.Type != blessing.EventRetracted || evt.Actor == myDomain {
+			continue
+		}
+		targetDomain, _ := evt.Payload["target_domain"].(string)
+		if targetDomain != myDomain {
+			continue
+		}
+
+		commentURL, err := blessing.HonorRetraction(s.DataDir, evt, remote.NewClient())
+		if err != nil {
+			s.LogWarn("blessing sync: ignoring retraction from %s: %v", evt.Actor, err)
+			continue
+		}
+		if commentRelPath := extractCommentRelPath(commentURL); commentRelPath != "" {
+			localPath := filepath.Join(s.DataDir, commentRelPath)
+			if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+				s.LogWarn("blessing sync: failed to remove %s: %v", localPath, err)
+			}
+		}
+
+		filesChanged = true
+		s.LogInfo("blessing sync: removed retracted comment %s", commentURL)
+	}
+
 	return stream.HandlerResult{FilesChanged: filesChanged}
 }
 
@@ -552,6 +615,90 @@ func extractPostPathFromURL(u string) string {
 	return u
 }
 
+// --- Email Notification Sync Handler ---
+
+// emailSyncHandler queues owner notification emails for new blessing requests
+// (pending comments on our posts). Delivery is batched by flushEmailNotifications.
+type emailSyncHandler struct {
+	server *Server
+}
+
+func (h *emailSyncHandler) Name() string { return "email" }
+
+func (h *emailSyncHandler) EventTypes() []string {
+	return []string{"polis.blessing.requested"}
+}
+
+func (h *emailSyncHandler) Process(events []discovery.StreamEvent) stream.HandlerResult {
+	s := h.server
+	notifier := s.emailNotifier()
+	if notifier == nil {
+		return stream.HandlerResult{}
+	}
+
+	myDomain := extractDomainFromURL(s.GetBaseURL())
+	if myDomain == "" {
+		return stream.HandlerResult{}
+	}
+
+	var items []mailer.Item
+	for _, evt := range events {
+		commentURL := firstNonEmptyString(evt.Payload, "comment_url", "source_url")
+		targetURL := firstNonEmptyString(evt.Payload, "in_reply_to", "target_url")
+		if commentURL == "" {
+			continue
+		}
+		targetDomain, _ := evt.Payload["target_domain"].(string)
+		if targetDomain == "" {
+			targetDomain = discovery.ExtractDomainFromURL(targetURL)
+		}
+		if targetDomain != myDomain || evt.Actor == myDomain {
+			continue
+		}
+		items = append(items, mailer.Item{
+			Kind:      "blessing_request",
+			Actor:     evt.Actor,
+			URL:       commentURL,
+			TargetURL: targetURL,
+			At:        evt.Timestamp,
+		})
+	}
+
+	added, err := notifier.Enqueue(items)
+	if err != nil {
+		return stream.HandlerResult{Error: err}
+	}
+	return stream.HandlerResult{NewItems: added}
+}
+
+// emailNotifier returns a notifier for the configured SMTP settings, or nil
+// if email notifications are not enabled.
+func (s *Server) emailNotifier() *mailer.Notifier {
+	if s.Config == nil || s.Config.Email == nil || !s.Config.Email.Enabled {
+		return nil
+	}
+	cfg := *s.Config.Email
+	cfg.Password = s.SMTPPassword
+	return mailer.NewNotifier(s.DataDir, cfg)
+}
+
+// flushEmailNotifications sends queued notifications once the batch window
+// has elapsed. Called after every sync cycle.
+func (s *Server) flushEmailNotifications() {
+	notifier := s.emailNotifier()
+	if notifier == nil {
+		return
+	}
+	sent, err := notifier.Flush(time.Now())
+	if err != nil {
+		s.LogWarn("email notification failed: %v", err)
+		return
+	}
+	if sent > 0 {
+		s.LogInfo("email notification sent (%d items)", sent)
+	}
+}
+
 // --- Comment Status Sync Handler ---
 
 type commentStatusSyncHandler struct {
@@ -591,3 +738,320 @@ func (h *commentStatusSyncHandler) Process(events []discovery.StreamEvent) strea
 		NewItems:     len(result.Blessed) + len(result.Denied),
 	}
 }
+
+// --- Thread Replies ---
+
+// threadCheckInterval is how often watched comment threads are re-fetched.
+// Each check fetches blessed-comments.json from every site with a watched
+// thread, so this is much slower than the stream sync.
+const threadCheckInterval = 15 * time.Minute
+
+// checkThreadReplies subscribes to the threads of any comments we have
+// written, fetches each watched thread, and raises a "replies near you"
+// notification for every newly blessed reply. Returns the number of replies.
+func (s *Server) checkThreadReplies() int {
+	s.threadsMu.Lock()
+	defer s.threadsMu.Unlock()
+	s.lastThreadCheck = time.Now()
+
+	path := thread.DefaultPath(s.DataDir)
+	f, err := thread.Load(path)
+	if err != nil {
+		s.LogWarn("thread check: %v", err)
+		return 0
+	}
+	f.SubscribeFromComments(s.DataDir)
+	if len(f.Active()) == 0 {
+		_ = thread.Save(path, f)
+		return 0
+	}
+
+	fetcher := s.threadFetcher
+	if fetcher == nil {
+		fetcher = remote.NewClient()
+	}
+	myDomain := extractDomainFromURL(s.GetBaseURL())
+
+	replies, err := f.Check(fetcher, myDomain)
+	if err != nil {
+		s.LogDebug("thread check: %v", err)
+	}
+	if err := thread.Save(path, f); err != nil {
+		s.LogWarn("thread check: %v", err)
+		return 0
+	}
+	if len(replies) == 0 {
+		return 0
+	}
+
+	entries := make([]notification.StateEntry, 0, len(replies))
+	for _, r := range replies {
+		entries = append(entries, notification.StateEntry{
+			ID:        notification.DedupeKey("thread-reply", map[string]interface{}{"source_url": r.CommentURL}),
+			RuleID:    "thread-reply",
+			Actor:     r.Author,
+			Icon:      "\U0001F4AC",
+			Message:   fmt.Sprintf("%s replied near you on %s", r.Author, extractPostPathFromURL(r.PostURL)),
+			Link:      r.CommentURL,
+			Payload:   map[string]interface{}{"post_url": r.PostURL, "comment_url": r.CommentURL},
+			EventIDs:  []int{},
+			CreatedAt: r.FoundAt,
+		})
+	}
+	mgr := notification.NewManager(s.DataDir, s.GetDiscoveryDomain())
+	added, err := mgr.Append(entries)
+	if err != nil {
+		s.LogWarn("thread check: failed to write notifications: %v", err)
+	}
+	s.LogInfo("thread check: %d new replies in watched threads", len(replies))
+
+	s.broadcastCounts(SyncResult{NewNotifications: added})
+	return len(replies)
+}
+
+// --- Followed Site Moves ---
+
+// moveCheckInterval is how often followed sites are checked for moves.
+// Each check fetches a moved statement from every followed site.
+const moveCheckInterval = 6 * time.Hour
+
+// checkFollowedMoves follows authors who moved their site to the new
+// address, once the signed moved statement verifies, and raises a
+// notification for each. Returns the number of moves applied.
+func (s *Server) checkFollowedMoves() int {
+	s.lastMoveCheck = time.Now()
+
+	path := following.DefaultPath(s.DataDir)
+	f, err := following.Load(path)
+	if err != nil || f.Count() == 0 {
+		return 0
+	}
+
+	opts := following.MoveOptions{Probe: true}
+	if s.DiscoveryURL != "" && s.DiscoveryKey != "" {
+		var domains []string
+		for _, entry := range f.All() {
+			if d := discovery.ExtractDomainFromURL(entry.URL); d != "" {
+				domains = append(domains, d)
+			}
+		}
+		resp, err := discovery.NewClient(s.DiscoveryURL, s.DiscoveryKey).QueryMigrations(domains)
+		if err == nil {
+			opts.Announced = resp.Migrations
+		}
+	}
+
+	fetcher := s.moveFetcher
+	if fetcher == nil {
+		fetcher = remote.NewClient()
+	}
+	moves, errs := f.FindMoves(fetcher, opts)
+	for _, err := range errs {
+		s.LogWarn("move check: ignored moved statement: %v", err)
+	}
+	applied, err := following.ApplyMoves(path, moves)
+	if err != nil {
+		s.LogWarn("move check: %v", err)
+		return 0
+	}
+	if len(applied) == 0 {
+		return 0
+	}
+
+	mgr := notification.NewManager(s.DataDir, s.GetDiscoveryDomain())
+	added, err := mgr.Append(following.MoveNotifications(applied))
+	if err != nil {
+		s.LogWarn("move check: failed to write notifications: %v", err)
+	}
+	for _, m := range applied {
+		s.LogInfo("move check: %s moved to %s; following the new address", m.From, m.To)
+	}
+	s.broadcastCounts(SyncResult{NewNotifications: added})
+	return len(applied)
+}
+
+// --- Follow Lists ---
+
+// followListCheckInterval is how often subscribed follow lists are checked
+// for new members.
+const followListCheckInterval = 6 * time.Hour
+
+// syncFollowLists follows the members added to subscribed follow lists
+// since the last check. Returns the number of sites followed.
+func (s *Server) syncFollowLists() int {
+	s.lastFollowListCheck = time.Now()
+	if s.PrivateKey == nil {
+		return 0
+	}
+
+	path := following.DefaultPath(s.DataDir)
+	ownDomain := discovery.ExtractDomainFromURL(s.GetBaseURL())
+	discoveryClient := discovery.NewAuthenticatedClient(s.DiscoveryURL, s.DiscoveryKey, ownDomain, s.PrivateKey)
+	follow := func(url string) (bool, error) {
+		if f, err := following.Load(path); err == nil && f.IsFollowing(url) {
+			return true, nil
+		}
+		result, err := following.FollowWithBlessing(path, url, discoveryClient, remote.NewClient(), s.PrivateKey, s.streamConfig())
+		if err != nil {
+			return false, err
+		}
+		return result.AlreadyFollowed, nil
+	}
+
+	results, err := followlist.Sync(s.DataDir, remote.NewClient(), follow, s.GetBaseURL())
+	if err != nil {
+		s.LogWarn("follow lists: %v", err)
+		return 0
+	}
+	followed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			s.LogWarn("follow lists: %s: %s", r.URL, r.Error)
+		}
+		for u, msg := range r.Failed {
+			s.LogWarn("follow lists: failed to follow %s: %s", u, msg)
+		}
+		for _, u := range r.Followed {
+			s.LogInfo("follow lists: following %s from %s", u, r.Title)
+		}
+		followed += len(r.Followed)
+	}
+	if followed > 0 {
+		go s.syncFeed()
+	}
+	return followed
+}
+
+// --- Sync Conflicts ---
+
+// conflictCheckInterval is how often the live site is checked for posts
+// deployed from another copy of the site.
+const conflictCheckInterval = time.Hour
+
+// conflictClient returns the fetcher for conflict checks.
+func (s *Server) conflictClient() conflict.Fetcher {
+	if s.conflictFetcher != nil {
+		return s.conflictFetcher
+	}
+	return remote.NewClient()
+}
+
+// checkSyncConflicts compares the live site's index with local posts and
+// saves the report to .polis/conflicts.json, where /api/validate picks it up.
+func (s *Server) checkSyncConflicts() (*conflict.Report, error) {
+	s.lastConflictCheck = time.Now()
+	report, err := conflict.Check(s.DataDir, s.GetBaseURL(), s.conflictClient())
+	if err != nil {
+		s.LogDebug("conflict check: %v", err)
+		return nil, err
+	}
+	if err := conflict.SaveReport(s.DataDir, report); err != nil {
+		s.LogWarn("conflict check: failed to save report: %v", err)
+	}
+	for _, e := range report.Errors {
+		s.LogDebug("conflict check: %s", e)
+	}
+	if len(report.Conflicts) > 0 {
+		s.LogInfo("conflict check: %d posts changed on the live site from elsewhere", len(report.Conflicts))
+	}
+	return report, nil
+}
+
+// reconcileInterval is how often local state is compared with the discovery service.
+const reconcileInterval = 6 * time.Hour
+
+// runReconcile compares local blessing, comment, and follow state with the
+// discovery service and saves the report to .polis/reconcile.json. The
+// background run only reports; repairs are applied on request.
+func (s *Server) runReconcile(repair bool) (*reconcile.Report, error) {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+	s.lastReconcile = time.Now()
+
+	var hookConfig *hooks.HookConfig
+	if s.Config != nil {
+		hookConfig = s.Config.Hooks
+	}
+	report, err := reconcile.Run(reconcile.Options{
+		DataDir:      s.DataDir,
+		BaseURL:      s.GetBaseURL(),
+		DiscoveryURL: s.DiscoveryURL,
+		DiscoveryKey: s.DiscoveryKey,
+		PrivateKey:   s.PrivateKey,
+		HookConfig:   hookConfig,
+		Repair:       repair,
+	})
+	if err != nil {
+		s.LogDebug("reconcile: %v", err)
+		return nil, err
+	}
+	if err := reconcile.SaveReport(s.DataDir, report); err != nil {
+		s.LogWarn("reconcile: failed to save report: %v", err)
+	}
+
+	for _, e := range report.Errors {
+		s.LogDebug("reconcile: %s", e)
+	}
+	if len(report.Divergences) > 0 {
+		s.LogInfo("reconcile: %d divergences, %d repaired", len(report.Divergences), report.Repaired)
+	}
+
+	if report.Repaired > 0 {
+		if err := s.RenderSite(); err != nil {
+			s.LogWarn("reconcile render failed: %v", err)
+		}
+		s.broadcastCounts(SyncResult{CommentsChanged: true})
+	}
+	return report, nil
+}
+
+// retryCommentDeliveries resends the blessing requests in the comment
+// outbox that are due: ones that failed (the discovery service or our site
+// was unreachable) and ones never sent. Called after every sync cycle.
+func (s *Server) retryCommentDeliveries() {
+	if s.DiscoveryURL == "" || s.DiscoveryKey == "" || s.PrivateKey == nil || s.GetBaseURL() == "" {
+		return
+	}
+	result, err := comment.RetryDeliveries(s.DataDir, s.PrivateKey, time.Now(), &comment.DiscoveryConfig{
+		DiscoveryURL: s.DiscoveryURL,
+		DiscoveryKey: s.DiscoveryKey,
+		BaseURL:      s.GetBaseURL(),
+	})
+	if err != nil {
+		s.LogWarn("comment delivery retry: %v", err)
+		return
+	}
+	if len(result.Failed) > 0 {
+		s.LogDebug("comment delivery retry: %d failed, will retry later", len(result.Failed))
+	}
+	sent := len(result.Delivered) + len(result.Blessed)
+	if sent == 0 {
+		return
+	}
+	s.LogInfo("comment delivery retry: %d blessing requests sent (%d auto-blessed)", sent, len(result.Blessed))
+
+	var hc *hooks.HookConfig
+	if s.Config != nil {
+		hc = s.Config.Hooks
+	}
+	for _, id := range result.Blessed {
+		signed, err := comment.GetComment(s.DataDir, id, comment.StatusBlessed)
+		if err != nil {
+			continue
+		}
+		hooks.RunHook(s.DataDir, hc, &hooks.HookPayload{
+			Event:         hooks.EventPostComment,
+			Path:          fmt.Sprintf("comments/blessed/%s.md", id),
+			Title:         signed.Meta.InReplyTo,
+			Version:       signed.Meta.CommentVersion,
+			Timestamp:     time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+			CommitMessage: hooks.GenerateCommitMessage(hooks.EventPostComment, signed.Meta.InReplyTo),
+		})
+	}
+
+	// Beseeching publishes the comment's public copy
+	if err := s.RenderSite(); err != nil {
+		s.LogWarn("comment delivery retry: render failed: %v", err)
+	}
+	s.broadcastCounts(SyncResult{FilesChanged: true})
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/site"
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
+)
+
+// errMultiSite answers requests to switch sites when the server serves every
+// workspace at once.
+const errMultiSite = "This server serves every workspace; pick one with the " + SiteHeader + " header or /api/sites/{id}/"
+
+// useSite switches the server to the site in dir and reloads its keys and
+// settings. The site must already be valid.
+func (s *Server) useSite(dir string) {
+	s.DataDir = dir
+	s.LoadKeys()
+	s.LoadConfig()
+	s.LoadEnv()
+	s.ApplyDiscoveryDefaults()
+	s.LogInfo("Switched to site: %s", dir)
+}
+
+// expandHome expands a leading ~/ (or ~\ on Windows) to the home directory
+// and makes the path absolute.
+func expandHome(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return filepath.Abs(path)
+}
+
+// validSite checks that dir holds a polis site; the error lists what is
+// wrong with it.
+func validSite(dir string) error {
+	validation := site.Validate(dir)
+	if validation.Status == site.StatusValid {
+		return nil
+	}
+	msgs := []string{}
+	for _, e := range validation.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// registerSite adds dir to the workspace registry, unless it is there
+// already, and makes it the active workspace. name may be empty, for one
+// derived from the directory.
+func registerSite(name, dir string) (*workspace.Workspace, error) {
+	reg, err := workspace.Load()
+	if err != nil {
+		return nil, err
+	}
+	if ws, ok := reg.Find(dir); ok {
+		return workspace.Use(ws.Name)
+	}
+	if name == "" {
+		name = workspace.NameFor(dir)
+		for base, i := name, 2; ; i++ {
+			if _, taken := reg.Get(name); !taken {
+				break
+			}
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+	}
+	if _, err := workspace.Add(name, dir); err != nil {
+		return nil, err
+	}
+	return workspace.Use(name)
+}
+
+// handleWorkspaces lists the registered sites, or registers one.
+// GET /api/workspaces
+// POST /api/workspaces {"name": "...", "path": "...", "use": true}
+func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		reg, err := workspace.Load()
+		if err != nil {
+			s.LogError("failed to load workspaces: %v", err)
+			http.Error(w, "Failed to load workspaces", http.StatusInternalServerError)
+			return
+		}
+		type entry struct {
+			workspace.Workspace
+			Valid   bool `json:"valid"`
+			Current bool `json:"current"`
+		}
+		entries := []entry{}
+		for _, ws := range reg.Workspaces {
+			entries = append(entries, entry{ws, site.Validate(ws.Path).Status == site.StatusValid, ws.Path == s.DataDir})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active":     reg.Active,
+			"current":    s.DataDir,
+			"workspaces": entries,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+			Use  bool   `json:"use"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" || req.Path == "" {
+			http.Error(w, "Name and path are required", http.StatusBadRequest)
+			return
+		}
+		if req.Use && s.host != nil {
+			http.Error(w, errMultiSite, http.StatusConflict)
+			return
+		}
+		dir, err := expandHome(req.Path)
+		if err != nil {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		if err := validSite(dir); err != nil {
+			http.Error(w, "Target is not a valid polis site: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		ws, err := workspace.Add(req.Name, dir)
+		if errors.Is(err, workspace.ErrExists) {
+			http.Error(w, "A workspace with that name already exists", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Use {
+			if _, err := workspace.Use(ws.Name); err != nil {
+				s.LogError("failed to switch workspace: %v", err)
+				http.Error(w, "Failed to switch workspace", http.StatusInternalServerError)
+				return
+			}
+			s.useSite(ws.Path)
+		}
+		if s.host != nil {
+			err := s.host.Add(ws.Name, ws.Path)
+			if errors.Is(err, errOpenSite) || errors.Is(err, errSharedSettings) {
+				if err := workspace.Remove(ws.Name); err != nil {
+					s.LogError("failed to remove workspace: %v", err)
+				}
+				http.Error(w, "Not serving this site: "+err.Error(), http.StatusConflict)
+				return
+			}
+			if err != nil {
+				s.LogWarn("Not serving workspace %s: %v", ws.Name, err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ws)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWorkspace switches to a registered site, or forgets one.
+// POST /api/workspaces/{name}/use
+// DELETE /api/workspaces/{name}
+func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	name, action, _ := strings.Cut(rest, "/")
+	if name == "" {
+		http.Error(w, "Workspace name required", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && action == "use":
+		if s.host != nil {
+			http.Error(w, errMultiSite, http.StatusConflict)
+			return
+		}
+		reg, err := workspace.Load()
+		if err != nil {
+			s.LogError("failed to load workspaces: %v", err)
+			http.Error(w, "Failed to load workspaces", http.StatusInternalServerError)
+			return
+		}
+		ws, ok := reg.Get(name)
+		if !ok {
+			http.Error(w, "Workspace not found", http.StatusNotFound)
+			return
+		}
+		if err := validSite(ws.Path); err != nil {
+			http.Error(w, "Workspace is not a valid polis site: "+err.Error(), http.StatusConflict)
+			return
+		}
+		if _, err := workspace.Use(name); err != nil {
+			s.LogError("failed to switch workspace: %v", err)
+			http.Error(w, "Failed to switch workspace", http.StatusInternalServerError)
+			return
+		}
+		s.useSite(ws.Path)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"workspace":  ws,
+			"site_title": s.GetSiteTitle(),
+		})
+
+	case r.Method == http.MethodDelete && action == "":
+		err := workspace.Remove(name)
+		if errors.Is(err, workspace.ErrNotFound) {
+			http.Error(w, "Workspace not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			s.LogError("failed to remove workspace: %v", err)
+			http.Error(w, "Failed to remove workspace", http.StatusInternalServerError)
+			return
+		}
+		if s.host != nil {
+			s.host.Remove(r.Context(), name)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
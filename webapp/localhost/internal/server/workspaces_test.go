@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/workspace"
+)
+
+func TestWorkspaces(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := newConfiguredServer(t)
+	other := newConfiguredServer(t)
+	mux := http.NewServeMux()
+	SetupRoutes(mux, s)
+
+	do := func(method, target string, body interface{}) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if body != nil {
+			req = httptest.NewRequest(method, target, jsonBody(t, body))
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/api/workspaces", map[string]string{"name": "other", "path": other.DataDir}); w.Code != http.StatusCreated {
+		t.Fatalf("add: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/workspaces", map[string]string{"name": "other", "path": other.DataDir}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate add: got %d, want 409", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/workspaces", map[string]string{"name": "nowhere", "path": t.TempDir()}); w.Code != http.StatusBadRequest {
+		t.Errorf("add a directory without a site: got %d, want 400", w.Code)
+	}
+	if s.DataDir == other.DataDir {
+		t.Fatal("adding a workspace switched to it")
+	}
+
+	w := do(http.MethodGet, "/api/v1/workspaces", nil)
+	var list struct {
+		Active     string `json:"active"`
+		Workspaces []struct {
+			Name  string `json:"name"`
+			Valid bool   `json:"valid"`
+		} `json:"workspaces"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if list.Active != "other" || len(list.Workspaces) != 1 || !list.Workspaces[0].Valid {
+		t.Errorf("list = %s", w.Body.String())
+	}
+
+	if w := do(http.MethodPost, "/api/workspaces/other/use", nil); w.Code != http.StatusOK {
+		t.Fatalf("use: %d %s", w.Code, w.Body.String())
+	}
+	if s.DataDir != other.DataDir {
+		t.Errorf("DataDir = %q after switching, want %q", s.DataDir, other.DataDir)
+	}
+	if w := do(http.MethodPost, "/api/workspaces/missing/use", nil); w.Code != http.StatusNotFound {
+		t.Errorf("use missing: got %d, want 404", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api/workspaces/other", nil); w.Code != http.StatusOK {
+		t.Fatalf("remove: %d %s", w.Code, w.Body.String())
+	}
+	if workspace.ActiveDir() != "" {
+		t.Error("the removed workspace is still active")
+	}
+	if w := do(http.MethodDelete, "/api/workspaces/other", nil); w.Code != http.StatusNotFound {
+		t.Errorf("second remove: got %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/websocket"
+)
+
+// wsMaxMessage bounds a client message on /api/ws; commands are a few
+// hundred bytes.
+const wsMaxMessage = 64 << 10
+
+// wsCommand is a client message on /api/ws. Ref is echoed in the reply so
+// the client can match it to the request; Args is the body the command's
+// HTTP endpoint takes.
+type wsCommand struct {
+	Ref     string          `json:"ref,omitempty"`
+	Command string          `json:"command"`
+	Args    json.RawMessage `json:"args,omitempty"`
+}
+
+// wsReply answers a wsCommand.
+type wsReply struct {
+	Event string      `json:"event"` // Always "result"
+	Ref   string      `json:"ref,omitempty"`
+	OK    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// handleWS is the WebSocket counterpart of handleSSE: it pushes the same
+// events, as {"event": ..., "data": ...} text messages, and accepts
+// commands on the same connection:
+//
+//	{"ref":"1","command":"mark-read","args":{"id":"x"}} — as POST /api/feed/read
+//	{"ref":"2","command":"refresh-feed"}                — as POST /api/feed/refresh
+//
+// and answers each with {"event":"result","ref":...,"ok":...}.
+//
+// GET /api/ws
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.sseClients == nil {
+		http.Error(w, "Event stream not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ws, err := websocket.Upgrade(w, r, wsMaxMessage)
+	if err != nil {
+		s.LogDebug("websocket upgrade failed: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	ch := make(chan SSEEvent, 10)
+	s.addSSEClient(ch)
+	defer s.removeSSEClient(ch)
+
+	// Commands are read on their own goroutine; done closes when the
+	// client goes away.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msg, err := ws.ReadMessage()
+			if err != nil {
+				if !errors.Is(err, websocket.ErrClosed) && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+					s.LogDebug("websocket read: %v", err)
+				}
+				return
+			}
+			reply := s.runWSCommand(msg)
+			if data, err := json.Marshal(reply); err == nil {
+				ws.WriteText(data)
+			}
+		}
+	}()
+
+	send := func(evt SSEEvent) error {
+		data, err := json.Marshal(wsEvent(evt))
+		if err != nil {
+			return err
+		}
+		return ws.WriteText(data)
+	}
+
+	// Send initial counts immediately, as the SSE stream does
+	if data, err := json.Marshal(s.computeAllCounts()); err == nil {
+		send(SSEEvent{Event: "counts", Data: string(data)})
+	}
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				// Server shutting down
+				ws.WriteClose(websocket.CloseGoingAway, "server shutting down")
+				return
+			}
+			if err := send(evt); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if err := ws.Ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsEvent is an SSEEvent as a WebSocket message. Data that is JSON (counts,
+// sync results) is embedded as is rather than as a string.
+func wsEvent(evt SSEEvent) map[string]interface{} {
+	var data interface{} = evt.Data
+	if json.Valid([]byte(evt.Data)) {
+		data = json.RawMessage(evt.Data)
+	}
+	return map[string]interface{}{"event": evt.Event, "data": data}
+}
+
+// runWSCommand carries out one client command and builds its reply.
+func (s *Server) runWSCommand(msg []byte) wsReply {
+	var cmd wsCommand
+	if err := json.Unmarshal(msg, &cmd); err != nil {
+		return wsReply{Event: "result", Error: "invalid command: " + err.Error()}
+	}
+	reply := wsReply{Event: "result", Ref: cmd.Ref}
+
+	switch cmd.Command {
+	case "mark-read":
+		var req feedReadRequest
+		if len(cmd.Args) > 0 {
+			if err := json.Unmarshal(cmd.Args, &req); err != nil {
+				reply.Error = "invalid args: " + err.Error()
+				return reply
+			}
+		}
+		if err := s.markFeedRead(req); err != nil {
+			if errors.Is(err, errFeedReadTarget) {
+				reply.Error = err.Error()
+				return reply
+			}
+			if errors.Is(err, feed.ErrItemNotFound) {
+				reply.Error = "Feed item not found"
+				return reply
+			}
+			s.LogError("feed read failed: %v", err)
+			reply.Error = "Failed to mark feed items"
+			return reply
+		}
+		// Other open dashboards see the new unread count
+		go s.broadcastCounts(SyncResult{})
+	case "refresh-feed":
+		reply.Data = s.refreshFeed()
+		go s.broadcastCounts(SyncResult{})
+	default:
+		reply.Error = fmt.Sprintf("unknown command %q", cmd.Command)
+		return reply
+	}
+	reply.OK = true
+	return reply
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vdibart/polis-cli/cli-go/pkg/feed"
+	"github.com/vdibart/polis-cli/cli-go/pkg/websocket"
+)
+
+// dialWS opens a WebSocket to the test server's /api/ws.
+func dialWS(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+	u, _ := url.Parse(ts.URL + "/api/ws")
+	conn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	ws, err := websocket.Handshake(conn, u, wsMaxMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// readServerMessage reads one text message into v.
+func readServerMessage(t *testing.T, ws *websocket.Conn, v interface{}) {
+	t.Helper()
+	payload, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		t.Fatalf("bad message %s: %v", payload, err)
+	}
+}
+
+func TestHandleWS_CountsEventsAndMarkRead(t *testing.T) {
+	s := newTestServer(t)
+	s.sseClients = make(map[chan SSEEvent]struct{})
+
+	cm := feed.NewCacheManager(s.DataDir, s.GetDiscoveryDomain())
+	if _, err := cm.MergeItems([]feed.FeedItem{{
+		Type: "post", Title: "Hello", URL: "https://alice.polis.pub/posts/hello.html",
+		Published: time.Now().UTC().Format(time.RFC3339), AuthorURL: "https://alice.polis.pub",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	items, _ := cm.List()
+	if len(items) != 1 {
+		t.Fatalf("expected 1 cached feed item, got %d", len(items))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleWS))
+	defer ts.Close()
+	ws := dialWS(t, ts)
+
+	var first struct {
+		Event string        `json:"event"`
+		Data  CountsPayload `json:"data"`
+	}
+	readServerMessage(t, ws, &first)
+	if first.Event != "counts" || first.Data.FeedUnread != 1 {
+		t.Fatalf("expected initial counts with 1 unread, got %+v", first)
+	}
+
+	ws.WriteText([]byte(`{"ref":"r1","command":"mark-read","args":{"id":"` + items[0].ID + `"}}`))
+	var reply wsReply
+	readServerMessage(t, ws, &reply)
+	if !reply.OK || reply.Ref != "r1" {
+		t.Fatalf("expected ok reply to r1, got %+v", reply)
+	}
+	items, _ = cm.List()
+	if items[0].ReadAt == "" {
+		t.Error("expected the item to be marked read")
+	}
+
+	ws.WriteText([]byte(`{"ref":"r2","command":"explode"}`))
+	for {
+		// The mark-read broadcast may arrive first
+		var msg wsReply
+		readServerMessage(t, ws, &msg)
+		if msg.Event != "result" {
+			continue
+		}
+		if msg.OK || msg.Ref != "r2" || !strings.Contains(msg.Error, "unknown command") {
+			t.Errorf("expected unknown command error for r2, got %+v", msg)
+		}
+		break
+	}
+
+	// Server-side events reach the socket as they do SSE clients
+	s.broadcastSSE(SSEEvent{Event: "sync", Data: `{"feed":2}`})
+	for {
+		var msg struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		}
+		readServerMessage(t, ws, &msg)
+		if msg.Event != "sync" {
+			continue
+		}
+		if string(msg.Data) != `{"feed":2}` {
+			t.Errorf("expected JSON data embedded as is, got %s", msg.Data)
+		}
+		break
+	}
+}
+
+func TestRunWSCommand_Errors(t *testing.T) {
+	s := newTestServer(t)
+	tests := []struct {
+		name, msg, want string
+	}{
+		{"invalid args", `{"command":"mark-read","args":{"id":7}}`, "invalid args: "},
+		{"no target", `{"command":"mark-read","args":{}}`, errFeedReadTarget.Error()},
+		{"missing item", `{"command":"mark-read","args":{"id":"nope"}}`, "Feed item not found"},
+	}
+	for _, tt := range tests {
+		if reply := s.runWSCommand([]byte(tt.msg)); reply.OK || !strings.HasPrefix(reply.Error, tt.want) {
+			t.Errorf("%s: got %+v, want an error starting %q", tt.name, reply, tt.want)
+		}
+	}
+
+	// Internal failures are logged, not sent to the client
+	cache := filepath.Join(s.DataDir, ".polis", "ds", s.GetDiscoveryDomain(), "state", "polis.feed.jsonl")
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		t.Fatal(err)
+	}
+	reply := s.runWSCommand([]byte(`{"command":"mark-read","args":{"all":true}}`))
+	if reply.Error != "Failed to mark feed items" {
+		t.Errorf("internal failure: got %q", reply.Error)
+	}
+}
+
+func TestHandleWS_RejectsPlainGet(t *testing.T) {
+	s := newTestServer(t)
+	s.sseClients = make(map[chan SSEEvent]struct{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ws", nil)
+	w := httptest.NewRecorder()
+	s.handleWS(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without an upgrade, got %d", w.Code)
+	}
+}
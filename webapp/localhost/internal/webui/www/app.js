@@ -61,6 +61,7 @@ const App = {
     screens: {
         welcome: document.getElementById('welcome-screen'),
         error: document.getElementById('error-screen'),
+        signin: document.getElementById('signin-screen'),
         dashboard: document.getElementById('dashboard-screen'),
         editor: document.getElementById('editor-screen'),
         comment: document.getElementById('comment-screen'),
@@ -77,6 +78,39 @@ const App = {
         if (this.screens[name]) {
             this.screens[name].classList.remove('hidden');
         }
+        if (name === 'editor' || name === 'comment') {
+            this.loadSignAs();
+        }
+    },
+
+    // Fill the "sign as" selectors with the co-authors whose keys are here.
+    // They stay hidden on sites signed only with the site key.
+    async loadSignAs() {
+        let data;
+        try {
+            data = await this.api('GET', '/api/coauthors');
+        } catch (err) {
+            return;
+        }
+        const names = (data.authors || [])
+            .filter(a => a.key_here && !a.removed)
+            .map(a => a.name);
+        for (const id of ['sign-as-select', 'comment-sign-as-select']) {
+            const select = document.getElementById(id);
+            if (!select) continue;
+            const current = select.dataset.loaded ? select.value : (data.sign_as || '');
+            select.innerHTML = '<option value="">Sign as site</option>' +
+                names.map(n => `<option value="${this.escapeHtml(n)}">Sign as ${this.escapeHtml(n)}</option>`).join('');
+            select.value = names.includes(current) ? current : '';
+            select.dataset.loaded = '1';
+            select.classList.toggle('hidden', names.length === 0);
+        }
+    },
+
+    // The co-author chosen in a "sign as" selector, or '' for the site key
+    signAsFrom(id) {
+        const select = document.getElementById(id);
+        return select && !select.classList.contains('hidden') ? select.value : '';
     },
 
     // Toast notification system
@@ -227,12 +261,38 @@ const App = {
                 window.location.href = '//' + window.__POLIS_BASE_DOMAIN;
                 return;
             }
+            // Sites with API users ask for a token
+            if (response.status === 401) {
+                this.showSignIn();
+            }
             const text = await response.text();
             throw new Error(text || response.statusText);
         }
         return response.json();
     },
 
+    // Show the sign-in screen. Signing in stores the token in the cookie the
+    // server reads, so every request (SSE and WebSocket included) carries it.
+    showSignIn() {
+        if (this._signingIn) return;
+        this._signingIn = true;
+        this.showScreen('signin');
+        const form = document.getElementById('signin-form');
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            const token = document.getElementById('signin-token').value.trim();
+            if (!token) return;
+            document.cookie = 'polis_token=' + encodeURIComponent(token) +
+                '; path=/; max-age=31536000; SameSite=Strict';
+            const response = await fetch('/api/me');
+            if (response.ok) {
+                window.location.reload();
+            } else {
+                this.showToast('That token is not valid', 'error');
+            }
+        });
+    },
+
     // Intent state (set by URL params, consumed after dashboard loads)
     _pendingIntent: null,
 
@@ -287,6 +347,7 @@ const App = {
     SOCIAL_PLUGINS: [
         { id: 'pulse',         label: 'Pulse',         path: '/social/pulse',         title: 'Community Pulse',  actions: '',                                                                                                                                                              render: 'renderPulse',                autoRefresh: true  },
         { id: 'conversations', label: 'Conversations', path: '/social/conversations', title: 'Conversations',    actions: '<button class="secondary sync-btn" onclick="App.markAllConversationsRead()">Mark All Read</button> <button class="secondary sync-btn" onclick="App.refreshConversations()">Refresh</button>', render: 'renderConversationsTabbed',   autoRefresh: true  },
+        { id: 'discover',      label: 'Discover',      path: '/social/discover',      title: 'Discover',         actions: '<button class="secondary sync-btn" onclick="App.setDiscoverSort(App._discoverSort)">Refresh</button>',                                                          render: 'renderDiscover',             autoRefresh: false },
     ],
 
     // Resolve a pathname against the route table.
@@ -489,6 +550,12 @@ const App = {
         // Register social plugins before anything else (must precede bindEvents)
         this._registerPlugins();
 
+        // Click-to-load placeholders in remote content
+        document.addEventListener('click', (e) => {
+            const btn = e.target.closest && e.target.closest('.remote-media-placeholder');
+            if (btn) this.loadRemoteMedia(btn);
+        });
+
         // Parse intent params from URL before anything else
         this._pendingIntent = this.parseIntentParams();
 
@@ -583,7 +650,9 @@ const App = {
             }
         } catch (err) {
             console.error('Failed to check status:', err);
-            this.showScreen('welcome');
+            if (!this._signingIn) {
+                this.showScreen('welcome');
+            }
         }
 
         this.bindEvents();
@@ -937,6 +1006,7 @@ const App = {
             case 'posts-drafts':
                 contentTitle.textContent = 'Post Drafts';
                 contentActions.innerHTML = '<button id="new-post-btn" class="primary" onclick="App.newPost()">New Post</button>';
+                this.addTemplatePicker(contentActions);
                 await this.renderDraftsList(contentList);
                 break;
 
@@ -1079,6 +1149,10 @@ const App = {
             await this.saveDraft();
         });
 
+        document.getElementById('unpublish-btn').addEventListener('click', async () => {
+            await this.unpublishToDraft();
+        });
+
         // Publish button
         document.getElementById('publish-btn').addEventListener('click', async () => {
             await this.publish();
@@ -1317,6 +1391,7 @@ const App = {
         document.getElementById('markdown-input').value = '';
         document.getElementById('filename-input').value = '';
         document.getElementById('filename-input').disabled = false;
+        document.getElementById('unlisted-input').checked = false;
         document.getElementById('preview-content').innerHTML =
             '<p class="empty-state">Start writing to see a preview.</p>';
 
@@ -1328,6 +1403,37 @@ const App = {
         this.showScreen('editor');
     },
 
+    // Offer the site's post templates (.polis/templates/posts) next to the
+    // New Post button, when it has any
+    async addTemplatePicker(container) {
+        let templates = [];
+        try {
+            templates = (await this.api('GET', '/api/post-templates')).templates || [];
+        } catch (err) {
+            return;
+        }
+        if (templates.length === 0) return;
+        const select = document.createElement('select');
+        select.className = 'template-picker';
+        select.innerHTML = '<option value="">New from template…</option>' + templates.map(t =>
+            `<option value="${this.escapeHtml(t.name)}">${this.escapeHtml(t.name)}</option>`).join('');
+        select.onchange = () => {
+            if (select.value) this.newPostFromTemplate(select.value);
+        };
+        container.prepend(select);
+    },
+
+    // Create a draft from a post template and open it. {{date}} and
+    // {{datetime}} are filled in; other placeholders are left to edit.
+    async newPostFromTemplate(name) {
+        try {
+            const result = await this.api('POST', `/api/post-templates/${encodeURIComponent(name)}/draft`, { vars: {} });
+            await this.openDraft(result.id);
+        } catch (err) {
+            this.showToast('Failed to create draft: ' + err.message, 'error');
+        }
+    },
+
     // New comment action
     newComment(opts = {}) {
         this.currentCommentDraftId = null;
@@ -1383,7 +1489,8 @@ const App = {
                     ${posts.map(post => `
                         <div class="content-item" data-path="${this.escapeHtml(post.path)}" onclick="App.openPost('${this.escapeHtml(post.path)}')">
                             <div class="item-info">
-                                <div class="item-title">${this.escapeHtml(post.title)}</div>
+                                <div class="item-title">${this.escapeHtml(post.title)}${post.visibility === 'unlisted' ? '<span class="item-unlisted" title="Not on index pages, feeds, or discovery">unlisted</span>' : ''}${post.visibility === 'followers' ? '<span class="item-unlisted" title="Served only to followers who sign the request">followers</span>' : ''}</div>
+                                ${post.summary ? `<div class="item-summary">${this.escapeHtml(post.summary)}</div>` : ''}
                                 <div class="item-path">${this.escapeHtml(post.path)}</div>
                             </div>
                             <div class="item-date-group">
@@ -1462,12 +1569,25 @@ const App = {
     },
 
     // Render combined "My Comments" view with pill tabs (All/Drafts/Pending/Blessed/Denied)
+    // Note on a pending comment whose blessing request hasn't reached the
+    // post's author yet.
+    deliveryLabel(delivery) {
+        if (!delivery) return '';
+        if (delivery.status === 'failed') {
+            const label = delivery.next_attempt ? 'Delivery failed, retrying' : 'Delivery failed';
+            return `<span class="comment-delivery failed" title="${this.escapeHtml(delivery.last_error || '')}">${label}</span>`;
+        }
+        if (delivery.status === 'pending') return '<span class="comment-delivery">Queued</span>';
+        return '';
+    },
+
     async renderCommentsPublished(container, filter) {
         if (filter) this._commentsPublishedFilter = filter;
         const currentFilter = this._commentsPublishedFilter;
 
         // Fetch all 4 statuses in parallel
         let drafts = [], pending = [], blessed = [], denied = [];
+        const deliveries = {};
         try {
             const [draftsRes, pendingRes, blessedRes, deniedRes] = await Promise.all([
                 this.api('GET', '/api/comments/drafts').catch(() => ({ drafts: [] })),
@@ -1482,9 +1602,11 @@ const App = {
                 _title: d.content ? d.content.substring(0, 60) : d.id,
                 _domain: this.extractDomainFromUrl(d.in_reply_to),
             }));
+            (draftsRes.deliveries || []).forEach(d => { deliveries[d.comment_id] = d; });
             pending = (pendingRes.comments || []).map(c => ({
                 ...c,
                 _status: 'pending',
+                _delivery: deliveries[c.id],
                 _sortDate: c.timestamp || '',
                 _title: c.title || c.id,
                 _domain: this.extractDomainFromUrl(c.in_reply_to),
@@ -1562,6 +1684,7 @@ const App = {
                         <div class="item-title">${this.escapeHtml(item._title)}</div>
                         <div class="item-path">
                             <span class="comment-status-badge ${item._status}">${item._status}</span>
+                            ${this.deliveryLabel(item._delivery)}
                             ${item._domain ? this.escapeHtml(item._domain) : ''}
                         </div>
                     </div>
@@ -1729,7 +1852,7 @@ const App = {
             requests = reqResult.requests || [];
             for (const pc of (blessedResult.comments || [])) {
                 for (const c of (pc.blessed || [])) {
-                    allBlessed.push({ ...c, post: pc.post });
+                    allBlessed.push({ ...c, post: pc.post, sort: pc.sort || 'chronological' });
                 }
             }
         } catch (err) {
@@ -1788,6 +1911,7 @@ const App = {
                         <div class="item-title">${this.escapeHtml(c.url ? c.url.split('/').pop() : 'comment')}</div>
                         <div class="item-path">
                             <span class="comment-status-badge blessed">blessed</span>
+                            ${c.featured ? '<span class="comment-status-badge blessed">featured</span>' : ''}
                             ${domain ? this.escapeHtml(domain) : ''}
                         </div>
                     </div>
@@ -1835,6 +1959,15 @@ const App = {
                     <span class="comment-detail-label">Blessed:</span>
                     <span class="comment-detail-value">${this.formatDate(comment.blessed_at)}</span>
                 </div>
+                <div class="comment-detail-row">
+                    <span class="comment-detail-label">Post order:</span>
+                    <span class="comment-detail-value">
+                        <select id="blessed-comment-sort" onchange="App.setCommentSort('${this.escapeHtml(comment.post)}', this.value)">
+                            ${['chronological', 'newest-first', 'featured-first'].map(o =>
+                                `<option value="${o}"${o === comment.sort ? ' selected' : ''}>${o}</option>`).join('')}
+                        </select>
+                    </span>
+                </div>
             </div>
             <div class="comment-detail-preview">
                 <div class="comment-detail-preview-label">Comment</div>
@@ -1846,6 +1979,7 @@ const App = {
 
         footer.innerHTML = `
             <button class="secondary danger" onclick="App.revokeBlessing('${this.escapeHtml(comment.url)}'); App.closeCommentDetail();">Revoke Blessing</button>
+            <button class="secondary" onclick="App.setCommentFeatured('${this.escapeHtml(comment.url)}', ${!comment.featured}); App.closeCommentDetail();">${comment.featured ? 'Unfeature' : 'Feature'}</button>
             <button class="secondary" onclick="App.closeCommentDetail()">Close</button>
         `;
 
@@ -1899,6 +2033,31 @@ const App = {
         }
     },
 
+    // Mark or unmark a blessed comment as featured
+    async setCommentFeatured(commentUrl, featured) {
+        try {
+            await this.api('POST', '/api/blessing/feature', {
+                comment_url: commentUrl,
+                featured: featured
+            });
+            this.showToast(featured ? 'Comment featured' : 'Comment no longer featured', 'success');
+            await this.loadViewContent();
+        } catch (err) {
+            this.showToast('Failed to update comment: ' + err.message, 'error');
+        }
+    },
+
+    // Set the comment order of a post (chronological, newest-first, featured-first)
+    async setCommentSort(post, sort) {
+        try {
+            await this.api('POST', '/api/blessing/sort', { post: post, sort: sort });
+            this.showToast('Comment order updated', 'success');
+            await this.loadViewContent();
+        } catch (err) {
+            this.showToast('Failed to set comment order: ' + err.message, 'error');
+        }
+    },
+
     // Render settings page
     async renderSettings(container) {
         try {
@@ -1910,6 +2069,7 @@ const App = {
             this.existingHooks = settings.existing_hooks || [];
 
             const themes = settings.themes || [];
+            const remoteMedia = settings.remote_media || {};
 
             let automationsHtml = '';
             if (automations.length === 0) {
@@ -1968,6 +2128,17 @@ const App = {
                         </div>
                     </div>
 
+                    ${!this.isHosted ? `
+                    <div class="settings-section">
+                        <div class="settings-section-label">Sites</div>
+                        <div class="settings-card" id="workspaces-card">
+                            <div class="settings-row">
+                                <span class="settings-row-value" style="color: var(--text-muted);">Loading...</span>
+                            </div>
+                        </div>
+                    </div>
+                    ` : ''}
+
                     ${themes.length > 0 ? `
                     <div class="settings-section">
                         <div class="settings-section-label">Theme</div>
@@ -1993,6 +2164,24 @@ const App = {
                     </div>
                     ` : ''}
 
+                    <div class="settings-section">
+                        <div class="settings-section-label">Remote Media</div>
+                        <div class="settings-card">
+                            <div class="settings-row">
+                                <span class="settings-row-label">Images:</span>
+                                <select id="remote-media-images" class="theme-select" onchange="App.saveRemoteMedia()">
+                                    ${this._remoteMediaOptions(remoteMedia.images, true)}
+                                </select>
+                            </div>
+                            <div class="settings-row">
+                                <span class="settings-row-label">Embeds:</span>
+                                <select id="remote-media-embeds" class="theme-select" onchange="App.saveRemoteMedia()">
+                                    ${this._remoteMediaOptions(remoteMedia.embeds, false)}
+                                </select>
+                            </div>
+                        </div>
+                    </div>
+
                     ${!this.isHosted ? `
                     <div class="settings-section">
                         <div class="settings-section-label">Discovery Service</div>
@@ -2070,6 +2259,8 @@ const App = {
                 </div>
             `;
 
+            if (!this.isHosted) this.loadWorkspaces();
+
             // Fetch registration status after rendering
             if (site.discovery_configured) {
                 this.fetchRegistrationStatus();
@@ -2215,6 +2406,49 @@ const App = {
         }
     },
 
+    // Build <option> list for a remote media mode select
+    _remoteMediaOptions(current, allowProxy) {
+        const modes = [
+            ['load', 'Load directly'],
+            ['proxy', 'Load through local proxy'],
+            ['click', 'Click to load'],
+            ['block', 'Block'],
+        ].filter(([value]) => allowProxy || value !== 'proxy');
+        return modes.map(([value, label]) =>
+            `<option value="${value}" ${value === (current || 'load') ? 'selected' : ''}>${label}</option>`
+        ).join('');
+    },
+
+    // Save remote media embedding rules
+    async saveRemoteMedia() {
+        const images = document.getElementById('remote-media-images');
+        const embeds = document.getElementById('remote-media-embeds');
+        if (!images || !embeds) return;
+        try {
+            await this.api('POST', '/api/settings/remote-media', { images: images.value, embeds: embeds.value });
+            this.showToast('Remote media settings saved', 'success');
+        } catch (err) {
+            this.showToast('Failed to save remote media settings: ' + err.message, 'error');
+        }
+    },
+
+    // Replace a click-to-load placeholder with the real media element
+    loadRemoteMedia(btn) {
+        const tag = btn.dataset.tag === 'source' ? 'img' : btn.dataset.tag;
+        const src = btn.dataset.src || '';
+        if (!/^https?:\/\//.test(src)) return;
+        const el = document.createElement(tag === 'embed' || tag === 'object' ? 'iframe' : tag);
+        el.setAttribute('src', src);
+        if (el.tagName === 'IFRAME') {
+            el.setAttribute('sandbox', 'allow-scripts allow-same-origin allow-popups');
+            el.setAttribute('loading', 'lazy');
+        }
+        if (el.tagName === 'VIDEO' || el.tagName === 'AUDIO') {
+            el.setAttribute('controls', '');
+        }
+        btn.replaceWith(el);
+    },
+
     // Open the site in a background tab
     viewSite() {
         if (this.siteBaseUrl) {
@@ -2235,6 +2469,62 @@ const App = {
         }
     },
 
+    // List the registered sites (workspaces) in settings, with a switch
+    // button for each one other than the current site
+    async loadWorkspaces() {
+        const card = document.getElementById('workspaces-card');
+        if (!card) return;
+        try {
+            const result = await this.api('GET', '/api/workspaces');
+            const rows = (result.workspaces || []).map(ws => `
+                <div class="settings-row">
+                    <span class="settings-row-label">${this.escapeHtml(ws.name)}</span>
+                    <span class="settings-row-value" title="${this.escapeHtml(ws.path)}">${this.escapeHtml(ws.path)}${ws.valid ? '' : ' (missing)'}</span>
+                    <div class="settings-row-actions">
+                        ${ws.current
+                            ? '<span style="color: var(--success-color);">Current</span>'
+                            : `<button class="btn-copy" ${ws.valid ? '' : 'disabled'} onclick="App.switchWorkspace('${this.escapeHtml(ws.name)}')">Switch</button>
+                               <button class="btn-copy" onclick="App.removeWorkspace('${this.escapeHtml(ws.name)}')">Forget</button>`}
+                    </div>
+                </div>
+            `).join('');
+            card.innerHTML = (rows || `
+                <div class="settings-row">
+                    <span class="settings-row-value" style="white-space: normal; color: var(--text-muted); font-family: inherit;">Only this site. Link another to switch between them without restarting.</span>
+                </div>
+            `) + `
+                <div class="settings-row">
+                    <button class="secondary" onclick="App.showLinkFlow()">Link another site</button>
+                </div>
+            `;
+        } catch (err) {
+            card.innerHTML = `<div class="settings-row"><span class="settings-row-value">${this.escapeHtml(err.message)}</span></div>`;
+        }
+    },
+
+    // Switch the server to another registered site. Everything on screen
+    // belongs to the old site, so the page is reloaded.
+    async switchWorkspace(name) {
+        try {
+            await this.api('POST', `/api/workspaces/${encodeURIComponent(name)}/use`);
+            window.location.href = '/';
+        } catch (err) {
+            this.showToast('Failed to switch site: ' + err.message, 'error');
+        }
+    },
+
+    // Forget a registered site; its directory is untouched
+    async removeWorkspace(name) {
+        const confirmed = await this.showConfirmModal('Forget Site', `Forget the site "${name}"? Its files are not deleted.`, 'Forget', 'Cancel', 'danger');
+        if (!confirmed) return;
+        try {
+            await this.api('DELETE', `/api/workspaces/${encodeURIComponent(name)}`);
+            this.loadWorkspaces();
+        } catch (err) {
+            this.showToast('Failed to forget site: ' + err.message, 'error');
+        }
+    },
+
     // Fetch site registration status from discovery service
     async fetchRegistrationStatus() {
         const statusEl = document.getElementById('registration-status');
@@ -2984,20 +3274,29 @@ echo "File: $POLIS_PATH"</code>
             if (isRepublish) {
                 result = await this.api('POST', '/api/republish', {
                     path: this.currentPostPath,
-                    markdown
+                    markdown,
+                    as: this.signAsFrom('sign-as-select')
                 });
             } else {
                 // Use filename from input, fall back to auto-generated from title
                 const filenameInput = document.getElementById('filename-input').value.trim();
+                // Publishing from a draft removes the draft once the post is written
                 result = await this.api('POST', '/api/publish', {
                     markdown,
-                    filename: filenameInput || ''
+                    filename: filenameInput || '',
+                    draft_id: this.currentDraftId || '',
+                    unlisted: document.getElementById('unlisted-input').checked,
+                    as: this.signAsFrom('sign-as-select')
                 });
             }
 
             if (result.success) {
                 const action = isRepublish ? 'Republished' : 'Published';
                 this.showToast(`${action}: ${result.title}`, 'success');
+                if (result.unresolved_links && result.unresolved_links.length > 0) {
+                    const links = result.unresolved_links.map(t => `[[${t}]]`).join(', ');
+                    this.showToast(`No published post matches ${links}`, 'warning', 8000);
+                }
 
                 // Clear editor and return to dashboard
                 this.currentDraftId = null;
@@ -3034,6 +3333,27 @@ echo "File: $POLIS_PATH"</code>
         }
     },
 
+    // Take the open post down and reopen it as a draft. The signed post
+    // goes to the trash.
+    async unpublishToDraft() {
+        if (!this.currentPostPath) return;
+        const confirmed = await this.showConfirmModal(
+            'Unpublish to Draft',
+            'This post will be removed from your site and reopened as a draft. The published version is kept in the trash.',
+            'Unpublish'
+        );
+        if (!confirmed) return;
+
+        try {
+            const result = await this.api('POST', `/api/posts/${encodeURIComponent(this.currentPostPath)}/unpublish-to-draft`);
+            this.showToast('Unpublished to draft', 'success');
+            await this.loadAllCounts();
+            await this.openDraft(result.draft_id);
+        } catch (err) {
+            this.showToast('Failed to unpublish: ' + err.message, 'error');
+        }
+    },
+
     // Open a draft for editing
     async openDraft(id, opts = {}) {
         try {
@@ -3045,6 +3365,7 @@ echo "File: $POLIS_PATH"</code>
             document.getElementById('markdown-input').value = result.markdown;
             document.getElementById('filename-input').value = id;  // Draft ID is the filename
             document.getElementById('filename-input').disabled = false;
+            document.getElementById('unlisted-input').checked = false;
 
             this.updateEditorFmToggle();
             this.updatePublishButton();
@@ -3104,6 +3425,7 @@ echo "File: $POLIS_PATH"</code>
         const filenameContainer = document.getElementById('filename-container');
         const filenameInput = document.getElementById('filename-input');
 
+        document.getElementById('unpublish-btn').classList.toggle('hidden', !this.currentPostPath);
         if (this.currentPostPath) {
             // Republishing - filename is locked
             btn.textContent = 'Republish';
@@ -3182,7 +3504,8 @@ echo "File: $POLIS_PATH"</code>
             const signResult = await this.api('POST', '/api/comments/sign', {
                 draft_id: this.currentCommentDraftId || '',
                 in_reply_to: inReplyTo,
-                content: content
+                content: content,
+                as: this.signAsFrom('comment-sign-as-select')
             });
 
             if (!signResult.success) {
@@ -3508,6 +3831,12 @@ echo "File: $POLIS_PATH"</code>
             }
         }
 
+        // Accounts elsewhere the author has proven (polis identity prove)
+        const verifiedHtml = (group.verified || []).map(v => {
+            const label = v.service === 'url' ? v.account.replace(/^https:\/\//, '') : `${v.service}: ${v.account}`;
+            return `<span class="verified-badge" title="Verified identity proof">&#10003; ${this.escapeHtml(label)}</span>`;
+        }).join('');
+
         return `
             <a href="${this.escapeHtml(linkUrl)}" target="_blank" rel="noopener"
                class="content-item feed-item${unreadClass}"
@@ -3516,7 +3845,7 @@ echo "File: $POLIS_PATH"</code>
                     <div class="item-title">${unreadDot}${this.escapeHtml(title)}</div>
                     <div class="item-path">
                         <span class="${badgeClass}">${typeLabel}</span>
-                        ${this.escapeHtml(group.post_domain || '')}
+                        ${this.escapeHtml(group.post_domain || '')}${verifiedHtml}
                     </div>
                     ${summaryHtml}
                 </div>
@@ -4240,14 +4569,135 @@ echo "File: $POLIS_PATH"</code>
         }
     },
 
+    // ==================== Discover ====================
+
+    // Recent posts by authors you don't follow, from the discovery
+    // service's stream. "More" appends the next, older page.
+    setDiscoverSort(sort) {
+        this._discoverSort = sort || 'newest';
+        this._discoverPosts = null;
+        const contentList = document.getElementById('content-list');
+        if (contentList) this.renderDiscover(contentList);
+    },
+
+    async renderDiscover(container) {
+        const sort = this._discoverSort || 'newest';
+        const tabsHtml = `
+            <div class="feed-filter-tabs">
+                <button class="feed-filter-tab ${sort === 'newest' ? 'active' : ''}" onclick="App.setDiscoverSort('newest')">Newest</button>
+                <button class="feed-filter-tab ${sort === 'trending' ? 'active' : ''}" onclick="App.setDiscoverSort('trending')">Trending</button>
+            </div>
+        `;
+        try {
+            if (!this._discoverPosts) {
+                container.innerHTML = tabsHtml + '<div class="content-list"><div class="empty-state"><p>Loading...</p></div></div>';
+                const [page, suggested] = await Promise.all([
+                    this.api('GET', '/api/discover?sort=' + sort),
+                    this.api('GET', '/api/discover/suggestions?limit=5').catch(() => ({})),
+                ]);
+                this._discoverPosts = page.posts || [];
+                this._discoverNext = page.next || '';
+                this._discoverSuggestions = suggested.suggestions || [];
+            }
+            const posts = this._discoverPosts;
+            const suggestionsHtml = this._discoverSuggestions.length === 0 ? '' : `
+                <div class="content-list discover-suggestions">
+                    <div class="pulse-card-title">Suggested for you</div>
+                    ${this._discoverSuggestions.map(sg => `
+                        <div class="content-item following-item">
+                            <div class="item-info">
+                                <div class="item-title">${this.escapeHtml(sg.domain)}</div>
+                                <div class="item-path">${this.escapeHtml(sg.reason)}</div>
+                            </div>
+                            <div class="following-item-actions">
+                                <button class="primary" onclick="App.followFromDiscover('${this.escapeHtml(sg.url)}')">Follow</button>
+                            </div>
+                        </div>
+                    `).join('')}
+                </div>
+            `;
+            if (posts.length === 0) {
+                container.innerHTML = tabsHtml + suggestionsHtml + `<div class="content-list"><div class="empty-state">
+                    <h3>Nothing new</h3>
+                    <p>No recent posts from authors you don't follow.</p>
+                </div></div>`;
+                return;
+            }
+            container.innerHTML = tabsHtml + suggestionsHtml + `
+                <div class="content-list">
+                    ${posts.map((p, i) => {
+                        const comments = sort === 'trending' && p.comments > 0
+                            ? ` &middot; ${p.comments} ${p.comments === 1 ? 'site' : 'sites'} commenting`
+                            : '';
+                        return `
+                            <div class="content-item following-item discover-item">
+                                <div class="item-info" onclick="App.openDiscoverPost(${i})">
+                                    <div class="item-title">${this.escapeHtml(p.title || this._titleFromUrl(p.url))}</div>
+                                    <div class="item-path">${this.escapeHtml(p.author_domain)}${comments}</div>
+                                </div>
+                                <div class="following-item-actions">
+                                    <span class="item-date">${this.formatDate(p.published)}</span>
+                                    <button class="primary" onclick="App.followFromDiscover('${this.escapeHtml(p.author_url)}')">Follow</button>
+                                </div>
+                            </div>
+                        `;
+                    }).join('')}
+                </div>
+                ${this._discoverNext ? '<div class="activity-load-more"><button class="secondary" onclick="App.loadMoreDiscover()">Load More</button></div>' : ''}
+            `;
+        } catch (err) {
+            container.innerHTML = tabsHtml + `<div class="content-list"><div class="empty-state"><h3>Failed to load</h3><p>${this.escapeHtml(err.message)}</p></div></div>`;
+        }
+    },
+
+    openDiscoverPost(i) {
+        const p = this._discoverPosts && this._discoverPosts[i];
+        if (p) this.openRemotePost(p.url, p.author_url, p.title);
+    },
+
+    async loadMoreDiscover() {
+        try {
+            const sort = this._discoverSort || 'newest';
+            const page = await this.api('GET', '/api/discover?sort=' + sort + '&before=' + encodeURIComponent(this._discoverNext));
+            const seen = new Set(this._discoverPosts.map(p => p.url));
+            this._discoverPosts = this._discoverPosts.concat((page.posts || []).filter(p => !seen.has(p.url)));
+            this._discoverNext = page.next || '';
+            const contentList = document.getElementById('content-list');
+            if (contentList) this.renderDiscover(contentList);
+        } catch (err) {
+            this.showToast('Failed to load more: ' + err.message, 'error');
+        }
+    },
+
+    // One-click follow: the author's posts leave the list, since it only
+    // shows authors you don't follow.
+    async followFromDiscover(authorUrl) {
+        const domain = authorUrl.replace(/^https?:\/\//, '').replace(/\/$/, '');
+        try {
+            const result = await this.api('POST', '/api/following', { url: authorUrl });
+            if (result.data && result.data.already_followed) {
+                this.showToast('Already following ' + domain, 'info');
+            } else {
+                this.showToast('Now following ' + domain, 'success');
+            }
+            this._discoverPosts = (this._discoverPosts || []).filter(p => p.author_url !== authorUrl);
+            this._discoverSuggestions = (this._discoverSuggestions || []).filter(sg => sg.url !== authorUrl);
+            this.loadAllCounts();
+            const contentList = document.getElementById('content-list');
+            if (contentList) this.renderDiscover(contentList);
+        } catch (err) {
+            this.showToast('Failed to follow: ' + err.message, 'error');
+        }
+    },
+
     // ==================== Followers ====================
 
     async renderFollowersList(container) {
         try {
             container.innerHTML = '<div class="content-list"><div class="empty-state"><p>Loading followers...</p></div></div>';
-            const result = await this.api('GET', '/api/followers/count');
+            const result = await this.api('GET', '/api/followers?limit=200');
             const followers = result.followers || [];
-            const count = result.count || 0;
+            const count = result.total || 0;
 
             this.counts.followers = count;
             this.updateBadge('followers-count', count);
@@ -4263,11 +4713,15 @@ echo "File: $POLIS_PATH"</code>
             container.innerHTML = `
                 <div class="content-list">
                     <div class="followers-summary">${count} follower${count !== 1 ? 's' : ''}</div>
-                    ${followers.map(domain => `
+                    ${followers.map(f => `
                         <div class="content-item follower-item">
                             <div class="item-info">
-                                <div class="item-title">${this.escapeHtml(domain)}</div>
+                                <div class="item-title">${this.escapeHtml(f.site_title || f.domain)}</div>
+                                <div class="item-path">${f.site_title ? this.escapeHtml(f.domain) + ' &middot; ' : ''}${f.followed_at ? 'followed ' + this.formatRelativeTime(f.followed_at) : ''}</div>
                             </div>
+                            ${f.following_back
+                                ? '<span class="follower-mutual">following back</span>'
+                                : `<button class="secondary" onclick="App.followBack('${this.escapeHtml(f.domain)}')">Follow back</button>`}
                         </div>
                     `).join('')}
                 </div>
@@ -4277,6 +4731,16 @@ echo "File: $POLIS_PATH"</code>
         }
     },
 
+    async followBack(domain) {
+        try {
+            await this.api('POST', `/api/followers/${encodeURIComponent(domain)}/follow-back`);
+            this.showToast(`Following ${domain}`, 'success');
+            await this.loadViewContent();
+        } catch (err) {
+            this.showToast('Failed to follow back: ' + err.message, 'error');
+        }
+    },
+
     async refreshFollowers(fullRefresh) {
         if (fullRefresh) {
             const contentList = document.getElementById('content-list');
@@ -4375,6 +4839,30 @@ echo "File: $POLIS_PATH"</code>
             }
         });
 
+        this._eventSource.addEventListener('deploy-progress', (e) => {
+            try {
+                const p = JSON.parse(e.data);
+                if (p.done === 1) {
+                    this.showToast(`Deploying to ${p.target}: ${p.total} file(s)...`, 'info');
+                }
+            } catch (err) {
+                console.error('SSE deploy-progress parse error:', err);
+            }
+        });
+
+        this._eventSource.addEventListener('deploy-complete', (e) => {
+            try {
+                const r = JSON.parse(e.data);
+                if (r.error) {
+                    this.showToast(`Deploy to ${r.target} failed: ${r.error}`, 'error', 8000);
+                } else {
+                    this.showToast(`Deployed to ${r.target}: ${r.uploaded.length} uploaded, ${r.deleted.length} deleted`, 'success');
+                }
+            } catch (err) {
+                console.error('SSE deploy-complete parse error:', err);
+            }
+        });
+
         this._eventSource.onerror = () => {
             // Reconnect with backoff. EventSource auto-reconnects,
             // but if it fails repeatedly we close and retry manually.
@@ -4519,6 +5007,7 @@ echo "File: $POLIS_PATH"</code>
             <div class="notification-type-badge">${icon}</div>
             <div class="notification-body">
                 <div class="notification-message">${this.escapeHtml(n.message || '')}</div>
+                ${n.payload && n.payload.site_title ? `<div class="notification-meta">${this.escapeHtml(n.payload.site_title)}</div>` : ''}
                 <div class="notification-meta">${this.formatRelativeTime(n.created_at)}</div>
             </div>
         `;